@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/deployments/postgres/migrations"
+	"github.com/yourusername/whey-price-compare/internal/awsv4"
+	"github.com/yourusername/whey-price-compare/internal/backup"
+	"github.com/yourusername/whey-price-compare/internal/database"
+)
+
+// backupCatalog writes the catalog, recent price history and accounts to
+// an archive file, and uploads it to S3 if asked.
+func backupCatalog(ctx context.Context, log *zap.Logger, args []string) int {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	out := fs.String("o", "", "archive file to write; defaults to whey-backup-<time>.jsonl.gz")
+	days := fs.Int("days", 90, "days of price history to keep; 0 keeps all")
+	dest := fs.String("s3", "", "also upload to s3://bucket/key, or under s3://bucket/prefix/")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	now := time.Now().UTC()
+	if *out == "" {
+		*out = "whey-backup-" + now.Format("20060102T150405Z") + ".jsonl.gz"
+	}
+	var store *backup.S3
+	var key string
+	if *dest != "" {
+		bucket, k, err := backup.ParseS3URL(*dest)
+		if err == nil {
+			store, err = newS3(bucket)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "admin:", err)
+			return 2
+		}
+		key = k
+		if key == "" || strings.HasSuffix(key, "/") {
+			key += filepath.Base(*out)
+		}
+	}
+
+	db, dialect, err := openDB(ctx, log)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "admin:", err)
+		return 1
+	}
+	defer db.Close()
+	opts := backup.Options{HistoryDays: *days, Now: now}
+	if dialect == database.Postgres {
+		if all, err := database.LoadMigrations(migrations.FS); err == nil {
+			opts.SchemaVersion, _ = database.NewMigrator(db, all, log).Version(ctx)
+		}
+	}
+
+	f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "admin:", err)
+		return 1
+	}
+	stats, err := backup.Dump(ctx, db, dialect, f, opts)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		// A partial archive would fail to restore anyway.
+		_ = os.Remove(*out)
+		fmt.Fprintln(os.Stderr, "admin:", err)
+		return 1
+	}
+	log.Info("Backup written",
+		zap.String("dialect", dialect.String()),
+		zap.String("file", *out),
+		zap.Int("products", stats["products"]),
+		zap.Int("price_points", stats["price_history"]),
+		zap.Int("accounts", stats["users"]),
+	)
+	fmt.Printf("backed up %d products, %d listings, %d price points and %d accounts to %s\n",
+		stats["products"], stats["product_listings"], stats["price_history"], stats["users"], *out)
+
+	if store == nil {
+		return 0
+	}
+	f, err = os.Open(*out)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "admin:", err)
+		return 1
+	}
+	defer f.Close()
+	if err := store.Put(ctx, key, f); err != nil {
+		fmt.Fprintln(os.Stderr, "admin:", err)
+		return 1
+	}
+	log.Info("Backup uploaded", zap.String("key", key))
+	fmt.Printf("uploaded to %s\n", key)
+	return 0
+}
+
+// restoreCatalog loads an archive file, or one in S3, into the database.
+func restoreCatalog(ctx context.Context, log *zap.Logger, args []string) int {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	replace := fs.Bool("replace", false, "delete the products, listings, price history, synonyms and, if the archive has them, accounts already there first")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: admin restore [-replace] FILE|s3://bucket/key")
+		return 2
+	}
+	src := fs.Arg(0)
+
+	var in io.ReadCloser
+	if strings.HasPrefix(src, "s3://") {
+		bucket, key, err := backup.ParseS3URL(src)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "admin:", err)
+			return 2
+		}
+		store, err := newS3(bucket)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "admin:", err)
+			return 2
+		}
+		if in, err = store.Get(ctx, key); err != nil {
+			fmt.Fprintln(os.Stderr, "admin:", err)
+			return 1
+		}
+	} else {
+		f, err := os.Open(src)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "admin:", err)
+			return 1
+		}
+		in = f
+	}
+	defer in.Close()
+
+	db, dialect, err := openDB(ctx, log)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "admin:", err)
+		return 1
+	}
+	defer db.Close()
+	stats, err := backup.Restore(ctx, db, dialect, in, *replace)
+	if errors.Is(err, backup.ErrCatalogNotEmpty) || errors.Is(err, backup.ErrAccountsNotEmpty) {
+		fmt.Fprintln(os.Stderr, "admin:", err, "- pass -replace to overwrite it")
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "admin:", err)
+		return 1
+	}
+	log.Info("Backup restored",
+		zap.String("dialect", dialect.String()),
+		zap.String("source", src),
+		zap.Int("rows", stats.Total()),
+	)
+	fmt.Printf("restored %d products, %d listings, %d price points and %d accounts\n",
+		stats["products"], stats["product_listings"], stats["price_history"], stats["users"])
+	return 0
+}
+
+// newS3 creates a client for bucket from the AWS_* variables the API
+// reads, and S3_ENDPOINT for S3-compatible stores.
+func newS3(bucket string) (*backup.S3, error) {
+	cfg := backup.DefaultS3Config()
+	cfg.Bucket = bucket
+	cfg.Region = os.Getenv("AWS_REGION")
+	cfg.Endpoint = os.Getenv("S3_ENDPOINT")
+	cfg.Credentials = awsv4.Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if cfg.Region == "" || cfg.Credentials.AccessKeyID == "" || cfg.Credentials.SecretAccessKey == "" {
+		return nil, errors.New("AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required for S3")
+	}
+	return backup.NewS3(cfg), nil
+}
@@ -0,0 +1,130 @@
+// Command admin runs operator tasks against the database.
+//
+//	admin migrate up [-to N]      apply pending schema migrations
+//	admin migrate status          list migrations and when each was applied
+//	admin migrate version         print the schema version
+//	admin migrate create NAME     add deployments/postgres/migrations/NNN_NAME.sql
+//	admin seed [-reset] [-days N] fill a development database with a demo catalog
+//	admin backup [-o FILE] [-days N] [-s3 s3://bucket/prefix/]
+//	                              archive the catalog, recent price history
+//	                              and accounts
+//	admin restore [-replace] FILE|s3://bucket/key
+//	                              load an archive back into the database
+//	admin ingest [-batch N] [FILE]
+//	                              write scraped prices, a JSON price point a
+//	                              line, in bulk
+//
+// The database is read from DATABASE_URL, as the API reads it; S3 uses
+// AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, and S3_ENDPOINT
+// for S3-compatible stores.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	// The database/sql drivers: Postgres as "pgx", SQLite as "sqlite".
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite"
+
+	"github.com/yourusername/whey-price-compare/internal/database"
+	"github.com/yourusername/whey-price-compare/pkg/logger"
+)
+
+// commands are the tasks by name; each gets the arguments after its name
+// and returns the exit code.
+var commands = map[string]func(ctx context.Context, log *zap.Logger, args []string) int{
+	"migrate": migrate,
+	"seed":    seedCatalog,
+	"backup":  backupCatalog,
+	"restore": restoreCatalog,
+	"ingest":  ingestPrices,
+}
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	if len(os.Args) < 2 || commands[os.Args[1]] == nil {
+		fmt.Fprintln(os.Stderr, "usage: admin <migrate|seed|backup|restore|ingest> [flags]")
+		return 2
+	}
+	log, err := logger.New(logger.Config{
+		Environment: envOr("APP_ENV", "development"),
+		Level:       os.Getenv("LOG_LEVEL"),
+		Service:     "admin",
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "admin:", err)
+		return 1
+	}
+	defer func() { _ = log.Sync() }()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return commands[os.Args[1]](ctx, log, os.Args[2:])
+}
+
+// openDB opens the pool DATABASE_URL names, checking it connects. A
+// SQLite database is checked to run with the tuning the URL asks for; one
+// replicated by Litestream must, as its WAL is otherwise not shipped. The
+// pool's statistics are logged every minute until ctx is done.
+func openDB(ctx context.Context, log *zap.Logger) (*sql.DB, database.Dialect, error) {
+	cfg, err := database.ParseURL(os.Getenv("DATABASE_URL"), database.DefaultPoolConfig())
+	if err != nil {
+		return nil, database.Dialect{}, err
+	}
+	db, err := database.Open(cfg.Dialect.Driver, cfg)
+	if err != nil {
+		return nil, cfg.Dialect, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, cfg.Dialect, fmt.Errorf("connect to database: %w", err)
+	}
+	if cfg.Dialect == database.SQLite {
+		if err := checkSQLite(ctx, db, cfg.SQLite, log); err != nil {
+			_ = db.Close()
+			return nil, cfg.Dialect, err
+		}
+	}
+	go database.MonitorPool(ctx, db, time.Minute, log.With(zap.String("pool", "primary")))
+	return db, cfg.Dialect, nil
+}
+
+// checkSQLite logs the tuning a SQLite database runs with, failing only if
+// Litestream is to replicate it and cannot.
+func checkSQLite(ctx context.Context, db *sql.DB, cfg database.SQLiteConfig, log *zap.Logger) error {
+	status, err := database.CheckSQLite(ctx, db, cfg)
+	fields := []zap.Field{
+		zap.String("journal_mode", status.JournalMode),
+		zap.Duration("busy_timeout", status.BusyTimeout),
+		zap.Bool("litestream", cfg.Litestream),
+		zap.Bool("replicated", status.Replicated),
+	}
+	switch {
+	case err != nil && cfg.Litestream:
+		return fmt.Errorf("litestream: %w", err)
+	case err != nil:
+		log.Warn("SQLite database is not tuned as configured", append(fields, zap.Error(err))...)
+	case cfg.Litestream && !status.Replicated:
+		log.Warn("Litestream has not synced this database yet; run litestream replicate beside it, as its WAL is only checkpointed by Litestream", fields...)
+	default:
+		log.Debug("SQLite database ready", fields...)
+	}
+	return nil
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"text/tabwriter"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/deployments/postgres/migrations"
+	"github.com/yourusername/whey-price-compare/internal/database"
+)
+
+// migrationName is what create accepts as NAME.
+var migrationName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// migrate applies, lists and creates schema migrations. up, status and
+// version use the migrations built into the binary; create writes to the
+// source tree.
+func migrate(ctx context.Context, log *zap.Logger, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: admin migrate <up|status|version|create> [flags]")
+		return 2
+	}
+	fs := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	to := fs.Int("to", 0, "apply migrations up to this version only; 0 applies all")
+	dir := fs.String("dir", "deployments/postgres/migrations", "directory create writes to")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if args[0] == "create" {
+		return createMigration(*dir, fs.Args())
+	}
+
+	all, err := database.LoadMigrations(migrations.FS)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "admin:", err)
+		return 1
+	}
+	db, dialect, err := openDB(ctx, log)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "admin:", err)
+		return 1
+	}
+	defer db.Close()
+	if dialect != database.Postgres {
+		fmt.Fprintln(os.Stderr, "admin: migrations are for Postgres; create a SQLite database with make setup-sqlite")
+		return 1
+	}
+	m := database.NewMigrator(db, all, log)
+
+	switch args[0] {
+	case "up":
+		n, err := m.Up(ctx, *to)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "admin:", err)
+			return 1
+		}
+		fmt.Printf("applied %d migration(s)\n", n)
+	case "status":
+		status, err := m.Status(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "admin:", err)
+			return 1
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tMIGRATION\tAPPLIED")
+		for _, s := range status {
+			applied := "pending"
+			switch {
+			case s.Unknown:
+				applied = s.AppliedAt.Format(time.RFC3339) + " (not in this build)"
+			case s.Modified:
+				applied = s.AppliedAt.Format(time.RFC3339) + " (modified since)"
+			case !s.AppliedAt.IsZero():
+				applied = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		_ = w.Flush()
+		if err := m.Check(ctx); err != nil {
+			fmt.Fprintln(os.Stderr, "admin:", err)
+			return 1
+		}
+	case "version":
+		v, err := m.Version(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "admin:", err)
+			return 1
+		}
+		fmt.Printf("%d of %d\n", v, m.Latest())
+	default:
+		fmt.Fprintf(os.Stderr, "admin: unknown migrate command %q\n", args[0])
+		return 2
+	}
+	return 0
+}
+
+// createMigration writes an empty migration after the newest in dir, with
+// the header the others carry.
+func createMigration(dir string, args []string) int {
+	if len(args) != 1 || !migrationName.MatchString(args[0]) {
+		fmt.Fprintln(os.Stderr, "usage: admin migrate create name_in_snake_case")
+		return 2
+	}
+	existing, err := database.LoadMigrations(os.DirFS(dir))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "admin:", err)
+		return 1
+	}
+	name := fmt.Sprintf("%03d_%s.sql", len(existing)+1, args[0])
+	header := fmt.Sprintf("-- <TITLE>\n-- Migration: %s\n-- Created: %s\n-- Description: <DESCRIPTION>\n\n",
+		name, time.Now().Format(time.DateOnly))
+	path := filepath.Join(dir, name)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "admin:", err)
+		return 1
+	}
+	if _, err := f.WriteString(header); err != nil {
+		_ = f.Close()
+		fmt.Fprintln(os.Stderr, "admin:", err)
+		return 1
+	}
+	if err := f.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, "admin:", err)
+		return 1
+	}
+	fmt.Println(path)
+	return 0
+}
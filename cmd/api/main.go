@@ -0,0 +1,1571 @@
+// Command api runs the public HTTP API server.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	// The database/sql drivers: Postgres as "pgx", SQLite as "sqlite".
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	_ "modernc.org/sqlite"
+
+	"github.com/yourusername/whey-price-compare/deployments/postgres/migrations"
+	"github.com/yourusername/whey-price-compare/internal/alerts"
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/cache"
+	"github.com/yourusername/whey-price-compare/internal/cdn"
+	"github.com/yourusername/whey-price-compare/internal/config"
+	"github.com/yourusername/whey-price-compare/internal/database"
+	"github.com/yourusername/whey-price-compare/internal/diagnostics"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/errtrack"
+	"github.com/yourusername/whey-price-compare/internal/events"
+	"github.com/yourusername/whey-price-compare/internal/flags"
+	"github.com/yourusername/whey-price-compare/internal/fragments"
+	"github.com/yourusername/whey-price-compare/internal/handlers"
+	"github.com/yourusername/whey-price-compare/internal/health"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/images"
+	"github.com/yourusername/whey-price-compare/internal/metrics"
+	"github.com/yourusername/whey-price-compare/internal/middleware"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+	"github.com/yourusername/whey-price-compare/internal/notify/discord"
+	"github.com/yourusername/whey-price-compare/internal/notify/email"
+	"github.com/yourusername/whey-price-compare/internal/notify/slack"
+	"github.com/yourusername/whey-price-compare/internal/notify/sms"
+	"github.com/yourusername/whey-price-compare/internal/notify/telegram"
+	"github.com/yourusername/whey-price-compare/internal/notify/templates"
+	"github.com/yourusername/whey-price-compare/internal/notify/webpush"
+	"github.com/yourusername/whey-price-compare/internal/pool"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/repositories/postgres"
+	"github.com/yourusername/whey-price-compare/internal/repositories/sqlstore"
+	"github.com/yourusername/whey-price-compare/internal/search"
+	"github.com/yourusername/whey-price-compare/internal/search/meilisearch"
+	"github.com/yourusername/whey-price-compare/internal/seed"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/sitemap"
+	"github.com/yourusername/whey-price-compare/internal/static"
+	"github.com/yourusername/whey-price-compare/internal/storage/blob"
+	"github.com/yourusername/whey-price-compare/internal/tracing"
+	"github.com/yourusername/whey-price-compare/pkg/logger"
+)
+
+func main() {
+	// Settings come from the YAML file at CONFIG_FILE, when set, with the
+	// environment overriding it; the file is reloaded while the API runs.
+	configPath := os.Getenv("CONFIG_FILE")
+	settings, settingsErr := config.Load(configPath, os.Getenv)
+	appEnv := envOr("APP_ENV", "development")
+	logLevel := zap.NewAtomicLevel()
+	log, err := logger.New(logger.Config{
+		Environment: appEnv,
+		Level:       settings.LogLevel,
+		Service:     "api",
+		AtomicLevel: &logLevel,
+	})
+	if err != nil {
+		panic(err)
+	}
+	defer func() { _ = log.Sync() }()
+	if settingsErr != nil {
+		log.Fatal("Invalid configuration", zap.String("path", configPath), zap.Error(settingsErr))
+	}
+	settingsWatcher := config.NewWatcher(configPath, os.Getenv, settings, log)
+	settingsWatcher.OnChange(func(_ context.Context, _, settings config.Config) error {
+		logLevel.SetLevel(settings.Level(logger.DefaultLevel(appEnv)))
+		return nil
+	})
+
+	// Errors and panics logged from here on are reported to the
+	// Sentry-compatible service at SENTRY_DSN.
+	reporterCfg, reportingOn, err := errtrackConfig()
+	if err != nil {
+		log.Fatal("Invalid error tracking configuration", zap.Error(err))
+	}
+	var reporter *errtrack.Reporter
+	// Reports are sent until everything else has shut down, so errors
+	// while draining are reported too.
+	reportingCtx, stopReporting := context.WithCancel(context.Background())
+	reportingDone := make(chan struct{})
+	if !reportingOn {
+		close(reportingDone)
+	} else {
+		if reporter, err = errtrack.NewReporter(reporterCfg, log); err != nil {
+			log.Fatal("Invalid error tracking configuration", zap.Error(err))
+		}
+		go func() {
+			defer close(reportingDone)
+			reporter.Run(reportingCtx)
+		}()
+		log = log.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(c, reporter.Core(zapcore.ErrorLevel))
+		}))
+		log.Info("Error tracking enabled",
+			zap.String("release", reporterCfg.Release),
+			zap.String("environment", reporterCfg.Environment),
+			zap.Float64("sample_rate", reporterCfg.SampleRate),
+		)
+	}
+
+	// Prometheus metrics, served on METRICS_ADDR.
+	reg := metrics.NewRegistry()
+	dbMetrics := database.NewMetrics(reg)
+
+	// With DATABASE_URL set, the catalog, accounts, alerts and the tables
+	// that stand apart from them, such as feature flags and the audit log,
+	// are kept in the database, so they outlive restarts and every instance
+	// shares them; what the database does not hold stays in memory. A
+	// Postgres database must carry the schema this build migrates to, with
+	// the indexes its queries need. Catalog reads, and search log and audit
+	// log reads, go to the Postgres replicas in DATABASE_REPLICA_URLS while
+	// they keep up. Emails and names are sealed with PII_ENCRYPTION_KEY, 32
+	// random bytes in base64, which must never change or be lost.
+	var db *sqlDatabase
+	if raw := os.Getenv("DATABASE_URL"); raw != "" {
+		db, err = openDatabase(context.Background(), raw, os.Getenv("DATABASE_REPLICA_URLS"), dbMetrics, log)
+		if err != nil {
+			log.Fatal("Database unusable", zap.Error(err))
+		}
+		defer func() { _ = db.Close() }()
+	}
+	store := memory.NewStore()
+	repos := memoryRepos(store)
+	if db != nil {
+		piiKey, err := sqlstore.ParsePIIKey(os.Getenv("PII_ENCRYPTION_KEY"))
+		if err != nil {
+			log.Fatal("DATABASE_URL requires a valid PII_ENCRYPTION_KEY", zap.Error(err))
+		}
+		repos = db.repos(store, piiKey)
+	}
+	// DEMO_CATALOG fills an empty catalog with the one admin seed writes,
+	// for development and demos. A database that has products keeps them.
+	if os.Getenv("DEMO_CATALOG") == "true" {
+		demo := seed.Generate(seed.DefaultConfig())
+		var err error
+		if db == nil {
+			demo.Load(store)
+		} else {
+			err = demo.Insert(context.Background(), db.router.Writer(), db.dialect, false)
+		}
+		switch {
+		case errors.Is(err, seed.ErrCatalogNotEmpty):
+			log.Info("Demo catalog not loaded: the database has a catalog", zap.Error(err))
+		case err != nil:
+			log.Fatal("Loading demo catalog failed", zap.Error(err))
+		default:
+			log.Info("Loaded demo catalog", zap.Int("products", len(demo.Products)), zap.Int("listings", len(demo.Listings)))
+		}
+	}
+	// The scraper paces and reads retailers by these settings; a bad one
+	// is fixed now rather than found failing scrapes.
+	if err := services.CheckRetailers(context.Background(), repos.retailers, repos.selectors); err != nil {
+		log.Fatal("Invalid retailer configuration", zap.Error(err))
+	}
+
+	// Hot reads go through an in-process LRU, backed by Redis when
+	// REDIS_URL is set.
+	var (
+		redis  *cache.Redis
+		remote cache.Cache
+	)
+	if raw := os.Getenv("REDIS_URL"); raw != "" {
+		redisCfg, err := cache.ParseRedisURL(raw, cache.DefaultRedisConfig())
+		if err != nil {
+			log.Fatal("Invalid REDIS_URL", zap.Error(err))
+		}
+		redis = cache.NewRedis(redisCfg)
+		defer func() { _ = redis.Close() }()
+		remote = redis
+		log.Info("Redis read cache enabled", zap.String("addr", redisCfg.Addr))
+	}
+	// Traces go to the OpenTelemetry collector at OTEL_EXPORTER_OTLP_ENDPOINT.
+	tracerCfg, tracingOn, err := tracerConfig()
+	if err != nil {
+		log.Fatal("Invalid tracing configuration", zap.Error(err))
+	}
+	var tracer *tracing.Tracer
+	// Spans are exported until the last workers have drained, so shutdown
+	// is traced too.
+	tracingCtx, stopTracing := context.WithCancel(context.Background())
+	tracingDone := make(chan struct{})
+	if !tracingOn {
+		close(tracingDone)
+	} else {
+		tracer = tracing.NewTracer(tracerCfg, log)
+		go func() {
+			defer close(tracingDone)
+			tracer.Run(tracingCtx)
+		}()
+		log.Info("Tracing enabled",
+			zap.String("endpoint", tracerCfg.Endpoint),
+			zap.Float64("sample_ratio", tracerCfg.SampleRatio),
+		)
+	}
+	cacheMetrics := cache.NewMetrics(reg)
+	readCache := cache.NewTiered(cache.NewLRU(cache.DefaultLRUConfig()), remote).WithMetrics(cacheMetrics, "read")
+
+	bus := events.NewBus(log)
+
+	// Comparisons go stale fastest but are the latency-critical read, so
+	// they are served stale for a while as they refresh. Product details
+	// change rarely and are evicted explicitly on edit.
+	comparisonPolicy, err := cachePolicy("COMPARISON", cache.Policy{TTL: time.Minute, StaleWhileRevalidate: 4 * time.Minute})
+	if err != nil {
+		log.Fatal("Invalid comparison cache policy", zap.Error(err))
+	}
+	productPolicy, err := cachePolicy("PRODUCT", cache.DefaultPolicy())
+	if err != nil {
+		log.Fatal("Invalid product cache policy", zap.Error(err))
+	}
+
+	prices := services.NewPriceService(services.PriceRepos{
+		Products:  repos.products,
+		Retailers: repos.retailers,
+		Listings:  repos.listings,
+		Prices:    repos.prices,
+	}, log).WithCache(readCache, comparisonPolicy).WithViews(store.Views()).
+		WithDailyHistory(repos.dailyPrices, services.DefaultDailyHistoryAfter)
+
+	bulkCfg := pool.DefaultConfig("bulk")
+	if raw := os.Getenv("BULK_WORKERS"); raw != "" {
+		if bulkCfg.Size, err = strconv.Atoi(raw); err != nil || bulkCfg.Size < 1 {
+			log.Fatal("Invalid BULK_WORKERS", zap.String("value", raw))
+		}
+	}
+	bulk := pool.New(bulkCfg, log)
+
+	// Notifications go out on workers of their own, as deliveries spend
+	// their time waiting on providers and would hold bulk workers.
+	notifyCfg := pool.Config{Name: "notify", Size: 16, QueueDepth: 100}
+	if raw := os.Getenv("NOTIFY_WORKERS"); raw != "" {
+		if notifyCfg.Size, err = strconv.Atoi(raw); err != nil || notifyCfg.Size < 1 {
+			log.Fatal("Invalid NOTIFY_WORKERS", zap.String("value", raw))
+		}
+	}
+	notifyPool := pool.New(notifyCfg, log)
+	pool.ExportMetrics(reg, bulk, notifyPool)
+
+	// Lookups of IDs that were never products stop at a Bloom filter. It
+	// learns new products first, before anything reads them back.
+	known := services.NewKnownProducts(repos.products, log)
+	if err := known.Rebuild(context.Background()); err != nil {
+		log.Error("Initial known products rebuild failed; lookups are unfiltered", zap.Error(err))
+	}
+	bus.Subscribe(known.Handle, known.EventTypes()...)
+	popularity := services.NewPopularity()
+	prices.WithKnownProducts(known).WithPopularity(popularity)
+
+	// Comparisons and deals are served from precomputed rows. They are
+	// refreshed before the cache is invalidated, so a read racing the
+	// eviction cannot cache the old row again.
+	views := services.NewViewMaintainer(prices, store.Views(), log).WithPool(bulk)
+	if err := views.Rebuild(context.Background()); err != nil {
+		log.Error("Initial view rebuild incomplete", zap.Error(err))
+	}
+	bus.Subscribe(views.Handle, views.EventTypes()...)
+	invalidator := cache.NewInvalidator(readCache)
+	bus.Subscribe(invalidator.Handle, invalidator.EventTypes()...)
+
+	catalog := services.NewCatalogService(services.CatalogRepos{
+		Products:  repos.products,
+		Retailers: repos.retailers,
+		Listings:  repos.listings,
+	}, log).WithCache(readCache, productPolicy).WithKnownProducts(known).WithPopularity(popularity)
+
+	// After a burst of price changes has evicted them, the most-viewed
+	// products are reloaded before visitors ask for them.
+	warmCfg := services.DefaultCacheWarmerConfig()
+	if raw := os.Getenv("CACHE_WARM_TOP_N"); raw != "" {
+		if warmCfg.TopN, err = strconv.Atoi(raw); err != nil || warmCfg.TopN < 0 {
+			log.Fatal("Invalid CACHE_WARM_TOP_N", zap.String("value", raw))
+		}
+	}
+	warmer := services.NewCacheWarmer(warmCfg, prices, catalog, popularity, log).WithPool(bulk)
+	bus.Subscribe(warmer.Handle, warmer.EventTypes()...)
+
+	alertSvc := alerts.NewService(alerts.Repos{
+		Alerts:        repos.alerts,
+		Notifications: store.Notifications(),
+		Searches:      store.SavedSearches(),
+	}, prices, log)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	baseURL := envOr("PUBLIC_BASE_URL", "http://localhost:8080")
+	feed := handlers.DefaultFeedConfig()
+	feed.BaseURL = baseURL
+
+	widget := handlers.DefaultWidgetConfig()
+	widget.BaseURL = baseURL
+	for _, d := range strings.Split(os.Getenv("WIDGET_ALLOWED_DOMAINS"), ",") {
+		if d = strings.TrimSpace(d); d != "" {
+			widget.AllowedDomains = append(widget.AllowedDomains, d)
+		}
+	}
+
+	pages := handlers.DefaultPageConfig()
+	pages.BaseURL = baseURL
+
+	sitemapCfg := sitemap.DefaultConfig()
+	sitemapCfg.BaseURL = baseURL
+	sitemaps := sitemap.NewGenerator(sitemapCfg, sitemap.Source{
+		Products: repos.products,
+		Listings: repos.listings,
+	}, log)
+	go sitemaps.Run(ctx)
+
+	affiliateTags, err := services.ParseAffiliateTags(os.Getenv("AFFILIATE_TAGS"))
+	if err != nil {
+		log.Fatal("Invalid AFFILIATE_TAGS", zap.Error(err))
+	}
+	// Clicks outlive ctx so redirects served during shutdown are still
+	// written; the tracker is stopped after the server has drained.
+	clicks := services.NewClickTracker(services.DefaultClickTrackerConfig(), store.Clicks(), log)
+	// Edge caches are purged by tag when Cloudflare credentials are set.
+	cacheHeadersCfg := middleware.DefaultCacheHeadersConfig()
+	purgeCtx, stopPurges := context.WithCancel(context.Background())
+	purgesDone := make(chan struct{})
+	if zone, token := os.Getenv("CLOUDFLARE_ZONE_ID"), os.Getenv("CLOUDFLARE_API_TOKEN"); zone != "" && token != "" {
+		purgeCfg := cdn.DefaultQueueConfig()
+		purgeCfg.Mode = cdn.Mode(envOr("CDN_PURGE_MODE", string(cdn.ModeKeys)))
+		if purgeCfg.Mode != cdn.ModeKeys && purgeCfg.Mode != cdn.ModeURLs {
+			log.Fatal("Invalid CDN_PURGE_MODE", zap.String("mode", string(purgeCfg.Mode)))
+		}
+		purgeCfg.PublicBaseURL = baseURL
+		cfCfg := cdn.DefaultCloudflareConfig()
+		cfCfg.ZoneID, cfCfg.APIToken = zone, token
+		purges := cdn.NewQueue(purgeCfg, cdn.NewCloudflare(cfCfg), log)
+		// Subscribed after the cache invalidator: the origin evicts first.
+		bus.Subscribe(purges.Handle, purges.EventTypes()...)
+		cacheHeadersCfg.KeyHeader = middleware.CacheTagHeader
+		go func() {
+			defer close(purgesDone)
+			purges.Run(purgeCtx)
+		}()
+		log.Info("CDN purging enabled", zap.String("mode", string(purgeCfg.Mode)))
+	} else {
+		close(purgesDone)
+	}
+
+	// Deal statistics cover a rolling 30 days, so rows drift even without
+	// new prices.
+	viewRebuildInterval, err := time.ParseDuration(envOr("VIEW_REBUILD_INTERVAL", "1h"))
+	if err != nil || viewRebuildInterval <= 0 {
+		log.Fatal("Invalid VIEW_REBUILD_INTERVAL", zap.String("value", os.Getenv("VIEW_REBUILD_INTERVAL")))
+	}
+	viewsCtx, stopViews := context.WithCancel(context.Background())
+	viewsDone := make(chan struct{})
+	go func() {
+		defer close(viewsDone)
+		views.Run(viewsCtx, viewRebuildInterval)
+	}()
+
+	knownRebuildInterval, err := time.ParseDuration(envOr("KNOWN_PRODUCTS_REBUILD_INTERVAL", "15m"))
+	if err != nil || knownRebuildInterval <= 0 {
+		log.Fatal("Invalid KNOWN_PRODUCTS_REBUILD_INTERVAL", zap.String("value", os.Getenv("KNOWN_PRODUCTS_REBUILD_INTERVAL")))
+	}
+	knownCtx, stopKnown := context.WithCancel(context.Background())
+	knownDone := make(chan struct{})
+	go func() {
+		defer close(knownDone)
+		known.Run(knownCtx, knownRebuildInterval)
+	}()
+
+	// Type-ahead reads only its in-memory index, rebuilt when products
+	// change and every interval for anything no event reported.
+	suggester := search.NewSuggester(repos.products, log)
+	bus.Subscribe(suggester.Handle, suggester.EventTypes()...)
+	if err := suggester.Rebuild(context.Background()); err != nil {
+		log.Error("Initial suggestion index build failed; suggestions are empty", zap.Error(err))
+	}
+	suggestRebuildInterval, err := time.ParseDuration(envOr("SUGGEST_REBUILD_INTERVAL", "1h"))
+	if err != nil || suggestRebuildInterval <= 0 {
+		log.Fatal("Invalid SUGGEST_REBUILD_INTERVAL", zap.String("value", os.Getenv("SUGGEST_REBUILD_INTERVAL")))
+	}
+	suggestCtx, stopSuggest := context.WithCancel(context.Background())
+	suggestDone := make(chan struct{})
+	go func() {
+		defer close(suggestDone)
+		suggester.Run(suggestCtx, suggestRebuildInterval)
+	}()
+
+	warmCtx, stopWarm := context.WithCancel(context.Background())
+	warmDone := make(chan struct{})
+	go func() {
+		defer close(warmDone)
+		warmer.Run(warmCtx)
+	}()
+
+	clicksCtx, stopClicks := context.WithCancel(context.Background())
+	clicksDone := make(chan struct{})
+	go func() {
+		defer close(clicksDone)
+		clicks.Run(clicksCtx)
+	}()
+
+	checker := health.NewChecker(health.Config{
+		Version:     envOr("APP_VERSION", "dev"),
+		Environment: envOr("APP_ENV", "development"),
+		Timeout:     health.DefaultConfig().Timeout,
+	}, log)
+	if db != nil {
+		// Only the primary is critical: reads fall back to it when no
+		// replica is usable, so a lost replica only degrades.
+		checker.Add(health.Check{Name: "database", Critical: true, Probe: db.router.Writer().PingContext})
+		for i := range db.router.Replicas() {
+			checker.Add(health.Check{
+				Name:  fmt.Sprintf("database_replica_%d", i),
+				Probe: func(ctx context.Context) error { return db.router.ProbeReplica(ctx, i) },
+			})
+		}
+	}
+	if redis != nil {
+		// Reads fall back to the store, so a cache outage only degrades.
+		checker.Add(health.Check{Name: "cache", Probe: redis.Ping})
+	}
+
+	trustProxy := settings.TrustProxy
+	deps := handlers.Deps{
+		Logger:  log,
+		Batch:   handlers.DefaultBatchConfig(),
+		Feed:    feed,
+		Widget:  widget,
+		Pages:   pages,
+		Catalog: catalog,
+		Prices:  prices,
+		Sitemap: sitemaps,
+		Stats:   services.NewStatsService(repos.stats, services.DefaultStatsTTL, log),
+		Health:  checker,
+		Redirects: services.NewRedirectService(services.RedirectRepos{
+			Retailers: repos.retailers,
+			Listings:  repos.listings,
+		}, affiliateTags, log),
+		Clicks:     clicks,
+		TrustProxy: trustProxy,
+	}
+	rankWeights, err := search.ParseRankWeights(os.Getenv("SEARCH_RANK_WEIGHTS"), search.DefaultRankWeights())
+	if err != nil {
+		log.Fatal("Invalid SEARCH_RANK_WEIGHTS", zap.Error(err))
+	}
+	synonyms := search.NewSynonyms(repos.synonyms, log)
+	if err := synonyms.Reload(context.Background()); err != nil {
+		log.Error("Initial synonym load failed; queries are not expanded", zap.Error(err))
+	}
+	bus.Subscribe(synonyms.Handle, synonyms.EventTypes()...)
+	// Feature flags default from FEATURE_FLAGS and are overridden through
+	// the admin API. Instances reload them on a change and on a timer, so
+	// changes made through another instance arrive too.
+	flagsCfg := flags.DefaultConfig()
+	if flagsCfg.Defaults, err = flags.Parse(os.Getenv("FEATURE_FLAGS"), flagsCfg.Defaults); err != nil {
+		log.Fatal("Invalid FEATURE_FLAGS", zap.Error(err))
+	}
+	featureFlags := flags.New(flagsCfg, repos.flags, log)
+	if err := featureFlags.Reload(context.Background()); err != nil {
+		log.Error("Initial feature flag load failed; using defaults", zap.Error(err))
+	}
+	bus.Subscribe(featureFlags.Handle, featureFlags.EventTypes()...)
+	flagsCtx, stopFlags := context.WithCancel(context.Background())
+	flagsDone := make(chan struct{})
+	go func() {
+		defer close(flagsDone)
+		featureFlags.Run(flagsCtx)
+	}()
+	searchIndex, indexSync, err := newSearchIndex(store, db, repos, log)
+	if err != nil {
+		log.Fatal("Invalid search backend", zap.Error(err))
+	}
+	indexCtx, stopIndex := context.WithCancel(context.Background())
+	indexDone := make(chan struct{})
+	if indexSync == nil {
+		close(indexDone)
+	} else {
+		// Product edits reach the index within a flush interval; the full
+		// reindex only catches what no event reported.
+		indexInterval, err := time.ParseDuration(envOr("SEARCH_REINDEX_INTERVAL", "24h"))
+		if err != nil || indexInterval <= 0 {
+			log.Fatal("Invalid SEARCH_REINDEX_INTERVAL", zap.String("value", os.Getenv("SEARCH_REINDEX_INTERVAL")))
+		}
+		bus.Subscribe(indexSync.Handle, indexSync.EventTypes()...)
+		go func() {
+			defer close(indexDone)
+			// The engine keeps its documents across restarts, so searches
+			// are served while the first reindex runs.
+			_ = indexSync.Reindex(indexCtx)
+			indexSync.Run(indexCtx, search.DefaultIndexFlushInterval, indexInterval)
+		}()
+	}
+	// Searches and result clicks are logged off the request path.
+	searchAnalytics := search.NewAnalytics(search.DefaultAnalyticsConfig(), repos.searchLogs, log)
+	analyticsCtx, stopAnalytics := context.WithCancel(context.Background())
+	analyticsDone := make(chan struct{})
+	go func() {
+		defer close(analyticsDone)
+		searchAnalytics.Run(analyticsCtx)
+	}()
+	deps.SearchAnalytics = searchAnalytics
+	// Search matches are cached in process: the brand generations that
+	// invalidate them are, too. Prices are looked up per request, so
+	// scrape bursts leave cached matches alone.
+	searchPolicy, err := cachePolicy("SEARCH", search.DefaultCachePolicy())
+	if err != nil {
+		log.Fatal("Invalid search cache policy", zap.Error(err))
+	}
+	searchCache := search.NewCachedIndex(searchIndex, cache.NewTiered(cache.NewLRU(cache.LRUConfig{
+		MaxEntries: 2000,
+		MaxTTL:     searchPolicy.TTL + searchPolicy.StaleWhileRevalidate,
+	}), nil).WithMetrics(cacheMetrics, "search"), searchPolicy, log)
+	bus.Subscribe(searchCache.Handle, searchCache.EventTypes()...)
+	deps.Search = search.NewService(searchCache, prices, log).
+		WithRanking(rankWeights, popularity).
+		WithFlags(featureFlags).
+		WithSynonyms(synonyms).
+		WithAnalytics(searchAnalytics).
+		WithRespelling(suggester).
+		WithCorrections(suggester)
+	deps.Suggest = suggester
+	// Fragments are keyed by the version of the data they render, so they
+	// share the read cache without needing invalidation.
+	deps.Fragments = fragments.New(readCache, fragments.DefaultTTL, log)
+
+	// Session cookies are Secure unless explicitly turned off for local
+	// development over plain HTTP.
+	authCfg := auth.DefaultConfig()
+	authCfg.Cookie.Secure = os.Getenv("SESSION_COOKIE_SECURE") != "false"
+	authCfg.TrustProxy = trustProxy
+	authCfg.OAuthCallbackURL = strings.TrimRight(baseURL, "/") + "/api/v1/auth/oauth/{provider}/callback"
+	deps.Auth = auth.NewService(authCfg, auth.Repos{
+		Users:         repos.users,
+		Sessions:      repos.sessions,
+		Verifications: repos.verifications,
+		Identities:    repos.identities,
+		APITokens:     repos.apiTokens,
+	}, log)
+	if id, secret := os.Getenv("GOOGLE_CLIENT_ID"), os.Getenv("GOOGLE_CLIENT_SECRET"); id != "" && secret != "" {
+		deps.Auth.WithProvider(auth.NewOIDCProvider(auth.GoogleConfig(id, secret)))
+		log.Info("Google sign-in enabled")
+	}
+	deps.Alerts = alertSvc
+	deps.Watchlist = services.NewWatchlistService(store.Watchlists(), prices, log)
+	deps.Presets = services.NewPresetService(store.FilterPresets(), log)
+
+	// Users choose their channels, topics and digest frequency; emails
+	// carry links signed with EMAIL_LINK_SECRET that change them without
+	// signing in.
+	linkSecret := []byte(os.Getenv("EMAIL_LINK_SECRET"))
+	prefs := notify.NewPreferences(store.Preferences())
+	if len(linkSecret) > 0 {
+		prefs.WithSigningKey(linkSecret)
+	}
+	deps.Preferences = prefs
+
+	// Deliveries, and opens and click-throughs of messages, are counted
+	// per channel; their tracking links are signed with the same secret.
+	engagement := notify.NewEngagement(store.Engagement(), linkSecret, log)
+	deps.Engagement = engagement
+
+	// Deals can be shared as links signed with the same secret; the
+	// outbound clicks they bring in are attributed to them.
+	if len(linkSecret) > 0 {
+		deps.Share = handlers.DefaultShareConfig()
+		deps.Share.BaseURL = baseURL
+		deps.Shares = services.NewShareService(linkSecret, prices, log)
+	}
+
+	// Every channel words its messages from the same templates, at the
+	// versions NOTIFY_TEMPLATE_VERSIONS pins or else the latest.
+	tmplCfg, err := templatesConfig()
+	if err != nil {
+		log.Fatal("Invalid NOTIFY_TEMPLATE_VERSIONS", zap.Error(err))
+	}
+	tmpl, err := templates.New(tmplCfg)
+	if err != nil {
+		log.Fatal("Invalid NOTIFY_TEMPLATE_VERSIONS", zap.Error(err))
+	}
+	deps.Templates = tmpl
+
+	// Verification links and alerts are emailed through whichever provider
+	// EMAIL_PROVIDER names; without one they are not delivered.
+	var channels []notify.Channel
+	emailProvider, err := newEmailProvider()
+	if err != nil {
+		log.Fatal("Invalid email configuration", zap.Error(err))
+	}
+	if emailProvider != nil {
+		emailCfg := email.DefaultConfig()
+		emailCfg.From = os.Getenv("EMAIL_FROM")
+		emailCfg.BaseURL = baseURL
+		if emailCfg.From == "" {
+			log.Fatal("EMAIL_PROVIDER requires EMAIL_FROM")
+		}
+		// Bulk senders must offer one-click unsubscribe.
+		if len(linkSecret) == 0 {
+			log.Fatal("EMAIL_PROVIDER requires EMAIL_LINK_SECRET")
+		}
+		sender := email.NewSender(emailCfg, emailProvider, store.Suppressions(), log).WithUnsubscribe(prefs).WithTemplates(tmpl)
+		deps.Auth.WithVerificationSender(sender)
+		// Visitors may set alerts with just an email address; the emailed
+		// link confirms and later manages them.
+		guestCfg := alerts.DefaultGuestConfig()
+		guestCfg.Key = linkSecret
+		guestCfg.BaseURL = baseURL
+		alertSvc.WithGuests(guestCfg, repos.users, sender)
+		channels = append(channels, sender)
+		log.Info("Email delivery enabled", zap.String("provider", os.Getenv("EMAIL_PROVIDER")))
+	}
+	// SES reports bounces and complaints through these SNS topics.
+	if raw := os.Getenv("SES_EVENT_TOPIC_ARNS"); raw != "" {
+		deps.Bounces = email.NewBounceHandler(email.BounceConfig{TopicARNs: strings.Split(raw, ",")}, store.Suppressions(), log)
+	}
+	// Browsers subscribed to push notifications get alerts too. The VAPID
+	// key must not change while subscriptions exist.
+	var reachable []alerts.ReachableFunc
+	if raw := os.Getenv("WEBPUSH_VAPID_PRIVATE_KEY"); raw != "" {
+		key, err := webpush.ParseVAPIDKey(raw)
+		if err != nil {
+			log.Fatal("Invalid WEBPUSH_VAPID_PRIVATE_KEY", zap.Error(err))
+		}
+		pushCfg := webpush.DefaultConfig()
+		pushCfg.Subject = os.Getenv("WEBPUSH_SUBJECT")
+		pushCfg.BaseURL = baseURL
+		if pushCfg.Subject == "" {
+			log.Fatal("WEBPUSH_VAPID_PRIVATE_KEY requires WEBPUSH_SUBJECT, e.g. mailto:<YOUR_CONTACT_EMAIL_HERE>")
+		}
+		push := webpush.NewSender(pushCfg, key, store.PushSubscriptions(), log).WithTemplates(tmpl)
+		deps.Push = push
+		channels = append(channels, push)
+		reachable = append(reachable, push.Subscribed)
+		log.Info("Web Push enabled")
+	}
+	// Alerts also go to Telegram chats linked to the bot, which answers
+	// /price questions too.
+	if token := os.Getenv("TELEGRAM_BOT_TOKEN"); token != "" {
+		tgCfg := telegram.DefaultConfig()
+		tgCfg.Token = token
+		tgCfg.Username = os.Getenv("TELEGRAM_BOT_USERNAME")
+		tgCfg.SiteURL = baseURL
+		if tgCfg.Username == "" {
+			log.Fatal("TELEGRAM_BOT_TOKEN requires TELEGRAM_BOT_USERNAME")
+		}
+		bot := telegram.NewBot(tgCfg, store.Telegram(), telegram.Catalog{Products: repos.products, Prices: prices}, log).WithTemplates(tmpl)
+		deps.Telegram = bot
+		channels = append(channels, bot)
+		reachable = append(reachable, bot.Linked)
+		go bot.Run(ctx)
+		log.Info("Telegram bot enabled", zap.String("username", tgCfg.Username))
+	}
+	// Users who verify a phone number can get alerts by SMS, within the
+	// spending caps.
+	smsProvider, err := newSMSProvider()
+	if err != nil {
+		log.Fatal("Invalid SMS configuration", zap.Error(err))
+	}
+	if smsProvider != nil {
+		smsCfg, err := smsConfig()
+		if err != nil {
+			log.Fatal("Invalid SMS configuration", zap.Error(err))
+		}
+		smsCfg.SiteURL = baseURL
+		sender := sms.NewSender(smsCfg, smsProvider, store.SMS(), log)
+		deps.SMS = sender
+		channels = append(channels, sender)
+		reachable = append(reachable, sender.Subscribed)
+		log.Info("SMS alerts enabled", zap.String("provider", smsProvider.Name()))
+	}
+	// Discord webhooks need no account of our own, so they are always
+	// available; DISCORD_ALERTS=false turns them off.
+	if os.Getenv("DISCORD_ALERTS") != "false" {
+		discordCfg := discord.DefaultConfig()
+		discordCfg.SiteURL = baseURL
+		discordCfg.AvatarURL = os.Getenv("DISCORD_AVATAR_URL")
+		sender := discord.NewSender(discordCfg, store.Discord(), log).WithTemplates(tmpl)
+		deps.Discord = sender
+		channels = append(channels, sender)
+		reachable = append(reachable, sender.Connected)
+	}
+	// Slack incoming webhooks likewise need no account; SLACK_ALERTS=false
+	// turns them off. The /whey slash command needs the Slack app's
+	// signing secret.
+	if os.Getenv("SLACK_ALERTS") != "false" {
+		slackCfg := slack.DefaultConfig()
+		slackCfg.SiteURL = baseURL
+		slackCfg.SigningSecret = os.Getenv("SLACK_SIGNING_SECRET")
+		app := slack.NewApp(slackCfg, store.Slack(), slack.Catalog{Products: repos.products, Prices: prices}, log).WithTemplates(tmpl)
+		deps.Slack = app
+		channels = append(channels, app)
+		reachable = append(reachable, app.Connected)
+		log.Info("Slack alerts enabled", zap.Bool("commands", app.CommandsEnabled()))
+	}
+	// Users reachable without email may create alerts before verifying.
+	if len(reachable) > 0 {
+		alertSvc.WithReachable(anyReachable(reachable...))
+	}
+	if len(channels) > 0 {
+		dispatcher := notify.NewDispatcher(notify.DefaultDispatcherConfig(), store.Notifications(), repos.users, log, channels...).
+			WithPreferences(prefs, notify.DefaultDigestSchedule()).
+			WithRetries(store.Deliveries(), notify.DefaultRetryPolicy()).
+			WithEngagement(engagement).
+			WithPool(notifyPool).
+			WithMetrics(reg)
+		deps.Deliveries = dispatcher
+		go dispatcher.Run(ctx)
+		go notify.NewDigester(notify.DefaultDigesterConfig(), store.Notifications(), log).Run(ctx)
+	}
+	privacy := services.NewPrivacyService(services.DefaultPrivacyConfig(), services.PrivacyRepos{
+		Data:     repos.userData,
+		Requests: store.DataRequests(),
+		Audit:    repos.audit,
+	}, log)
+	deps.Privacy = privacy
+	go privacy.Run(ctx)
+
+	// Events are written to the outbox with the changes that raise them and
+	// published from there once every subscriber is registered, so none is
+	// lost to a crash between the two.
+	// With a database, every change that raises events is saved there, so
+	// its outbox is the only one.
+	relay := events.NewRelay(events.DefaultRelayConfig(), repos.outbox, bus, log)
+	go relay.Run(ctx)
+	if db != nil {
+		go db.router.Run(ctx)
+		db.MonitorPools(ctx, log)
+	}
+	// A stuck relay leaves caches, the search index and alerts stale but
+	// reads still work, so it only degrades readiness.
+	checker.Add(health.Check{Name: "outbox", Probe: relay.Check})
+
+	// Price alerts consume the price change log instead of the bus, so
+	// after a bug in them is fixed they can be rewound to replay the
+	// changes they mishandled. They read the comparison, so each change
+	// settles until the relay has invalidated the cache for it.
+	alertLogCfg := events.DefaultLogConsumerConfig()
+	alertLogCfg.Settle = 2 * time.Second
+	go events.NewLogConsumer(alertLogCfg, "alerts", repos.priceLog, alertSvc.Handle, log).Run(ctx)
+
+	// Row counts are those of wherever the catalog is kept.
+	go database.NewRowCountExporter(database.DefaultRowCountConfig(), repos.rowCounter, reg, log).Run(ctx)
+
+	// Postgres keeps price history in monthly partitions, created ahead of
+	// the prices that fill them and dropped once past
+	// PRICE_HISTORY_RETENTION_DAYS.
+	if raw := os.Getenv("DATABASE_URL"); raw != "" {
+		partitions, err := partitionMaintainer(ctx, raw, dbMetrics, log)
+		if err != nil {
+			log.Fatal("Invalid price history partitioning", zap.Error(err))
+		}
+		if partitions != nil {
+			go partitions.Run(ctx)
+		}
+	}
+
+	// Data integrity is checked on a schedule, and findings exported as
+	// metrics and served to administrators.
+	integrityCfg := services.DefaultIntegrityConfig()
+	if v := os.Getenv("INTEGRITY_STALE_DAYS"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days <= 0 {
+			log.Fatal("Invalid INTEGRITY_STALE_DAYS", zap.String("value", v))
+		}
+		integrityCfg.StaleAfter = time.Duration(days) * 24 * time.Hour
+	}
+	integrity := services.NewIntegrityChecker(integrityCfg, repos.integrity, log).WithMetrics(reg)
+	go integrity.Run(ctx)
+
+	// Product images and page snapshots go to object storage when it is
+	// configured; a local directory's images are served by the API.
+	var productImages *images.Pipeline
+	if os.Getenv("BLOB_URL") != "" {
+		blobCfg := blob.ConfigFromEnv(os.Getenv)
+		blobs, err := blob.Open(blobCfg)
+		if err != nil {
+			log.Fatal("Invalid BLOB_URL", zap.Error(err))
+		}
+		if strings.HasPrefix(blobCfg.URL, "file:") {
+			deps.Media = blob.NewHandler(blobs, log)
+		}
+		productImages = images.NewPipeline(images.DefaultConfig(), blobs, log)
+		log.Info("Object storage configured", zap.String("url", blobCfg.URL), zap.String("public_url", blobCfg.PublicURL))
+	}
+
+	// Admin routes are only served when at least one token is configured.
+	adminTokens, err := middleware.ParseTokens(os.Getenv("ADMIN_TOKENS"))
+	if err != nil {
+		log.Fatal("Invalid ADMIN_TOKENS", zap.Error(err))
+	}
+	admin := services.NewAdminService(services.AdminRepos{
+		Catalog:   repos.catalogAdmin,
+		Selectors: repos.selectors,
+		Prices:    repos.priceWriter,
+		Audit:     repos.audit,
+		Synonyms:  repos.synonyms,
+		Flags:     repos.flags,
+		Alerts:    repos.alerts,
+		Tx:        repos.tx,
+	}, log).WithRelay(relay).WithPriceLog(repos.priceLog)
+	if productImages != nil {
+		admin.WithImages(productImages)
+	}
+	// Selectors in the configuration file are saved, and audited, like
+	// an admin's: all at startup, then each retailer's when it changes.
+	applySelectors := func(ctx context.Context, old, settings config.Config) error {
+		changed := settings.SelectorsChangedFrom(old)
+		if len(changed) == 0 {
+			return nil
+		}
+		actor := domain.Actor{ID: "config", Reason: "Configuration file " + configPath}
+		if err := admin.ApplySelectors(ctx, actor, changed); err != nil {
+			return fmt.Errorf("apply selectors: %w", err)
+		}
+		return nil
+	}
+	if err := applySelectors(context.Background(), config.Config{}, settings); err != nil {
+		log.Error("Failed to apply configured selectors", zap.Error(err))
+	}
+	settingsWatcher.OnChange(applySelectors)
+	// Retried writes carrying an Idempotency-Key replay the first response,
+	// per account, or per address for guests.
+	idemStore := middleware.NewMemoryIdempotencyStore()
+	userIdemCfg := middleware.DefaultIdempotencyConfig()
+	userIdemCfg.Scope = func(r *http.Request) string {
+		if id := httpx.Principal(r.Context()); id != "" {
+			return id
+		}
+		return "ip:" + httpx.ClientIP(r, trustProxy)
+	}
+	deps.Idempotency = middleware.NewIdempotency(userIdemCfg, idemStore, log).Handler
+	if len(adminTokens) > 0 {
+		deps.Admin = admin
+		deps.Integrity = integrity
+		auth := middleware.NewBearerAuth(middleware.BearerAuthConfig{Realm: "admin", Tokens: adminTokens}, log)
+		idemCfg := middleware.DefaultIdempotencyConfig()
+		idemCfg.Scope = func(r *http.Request) string { return httpx.Principal(r.Context()) }
+		idempotency := middleware.NewIdempotency(idemCfg, idemStore, log)
+		deps.AdminAuth = func(next http.Handler) http.Handler {
+			return auth.Handler(idempotency.Handler(next))
+		}
+		log.Info("Admin API enabled", zap.Int("principals", len(adminTokens)))
+	}
+	// Frontend assets are served when a build is present; the API runs
+	// without one in development.
+	if dir := envOr("STATIC_DIR", "web/static/dist"); isDir(dir) {
+		deps.Static = static.NewHandler(static.DefaultConfig(), os.DirFS(dir), log)
+		log.Info("Serving static assets", zap.String("dir", dir))
+	}
+	// Signed-in accounts and API tokens are limited on top of their IP, by
+	// their tier. Limits follow the configuration file as it changes.
+	rateLimitStore := middleware.NewMemoryRateLimitStore()
+	accountLimitCfg := settings.RateLimits.Account
+	accountLimitCfg.Key, accountLimitCfg.Tier = auth.RateLimitKey, auth.RateLimitTier
+	accountLimiter := middleware.NewRateLimiter(accountLimitCfg, rateLimitStore, log)
+	deps.AccountRateLimit = accountLimiter.Handler
+	rateLimitCfg := settings.RateLimits.IP
+	rateLimitCfg.TrustProxy = trustProxy
+	rateLimiter := middleware.NewRateLimiter(rateLimitCfg, rateLimitStore, log)
+	deps.RateLimit = rateLimiter.Handler
+	router := handlers.NewRouter(deps)
+
+	locale := middleware.NewLocaleNegotiator(middleware.DefaultLocaleConfig())
+	compressor := middleware.NewCompressor(middleware.DefaultCompressConfig(), log)
+	settingsWatcher.OnChange(func(_ context.Context, old, settings config.Config) error {
+		if err := rateLimiter.SetPolicies(settings.RateLimits.IP); err != nil {
+			return fmt.Errorf("ip rate limits: %w", err)
+		}
+		if err := accountLimiter.SetPolicies(settings.RateLimits.Account); err != nil {
+			// The IP limits changed already; go back with the rest.
+			_ = rateLimiter.SetPolicies(old.RateLimits.IP)
+			return fmt.Errorf("account rate limits: %w", err)
+		}
+		return nil
+	})
+	settingsCtx, stopSettings := context.WithCancel(context.Background())
+	settingsDone := make(chan struct{})
+	go func() {
+		defer close(settingsDone)
+		settingsWatcher.Run(settingsCtx)
+	}()
+	cacheHeaders := middleware.NewCacheHeaders(cacheHeadersCfg)
+	latencyCfg, err := middleware.ParseLatencyBudgets(os.Getenv("LATENCY_BUDGETS"), middleware.DefaultLatencyBudgetConfig())
+	if err != nil {
+		log.Fatal("Invalid LATENCY_BUDGETS", zap.Error(err))
+	}
+	latency := middleware.NewLatencyBudget(latencyCfg, log)
+	// Request rate, errors and duration per route, with duration buckets
+	// at the latency budgets.
+	httpMetrics := middleware.NewHTTPMetrics(reg)
+	// One line per request, under an X-Request-ID every line logged while
+	// serving it carries, inside tracing so the lines carry trace IDs too.
+	accessLog := middleware.NewAccessLog(middleware.DefaultAccessLogConfig(), log)
+	recoverer := middleware.NewRecover(log)
+	handler := accessLog.Handler(recoverer.Handler(latency.Handler(locale.Handler(compressor.Handler(rateLimiter.Handler(cacheHeaders.Handler(router)))))))
+	// Requests are traced when an OTLP endpoint is set, through the cache
+	// and repository calls behind them.
+	if tracer != nil {
+		handler = middleware.NewTracing(tracer).Handler(handler)
+	}
+
+	srv := &http.Server{
+		Addr:              ":" + envOr("PORT", "8080"),
+		Handler:           httpMetrics.Handler(handler),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       120 * time.Second,
+	}
+
+	// Profiling runs on its own listener, never the public one, and only
+	// behind the admin tokens.
+	var diagSrv *http.Server
+	if addr := os.Getenv("DIAGNOSTICS_ADDR"); addr != "" {
+		if len(adminTokens) == 0 {
+			log.Fatal("DIAGNOSTICS_ADDR requires ADMIN_TOKENS")
+		}
+		diagnostics.Publish("latency_budgets", func() any { return latency.Stats() })
+		diagnostics.Publish("bulk_pool", func() any { return bulk.Stats() })
+		diagnostics.Publish("notify_pool", func() any { return notifyPool.Stats() })
+		diagnostics.Publish("known_products_rejected", func() any { return known.Rejected() })
+		diagnostics.Publish("clicks_dropped", func() any { return clicks.Dropped() })
+		diagnostics.Publish("fragment_cache", func() any { return deps.Fragments.Stats() })
+		diagnostics.Publish("feature_flags", func() any { return featureFlags.All() })
+		if db != nil {
+			diagnostics.Publish("prepared_statements", func() any { return db.stmts.Stats() })
+			diagnostics.Publish("healthy_replicas", func() any { return db.router.HealthyReplicas() })
+		}
+		if tracer != nil {
+			diagnostics.Publish("spans_dropped", func() any { return tracer.Dropped() })
+		}
+		if reporter != nil {
+			diagnostics.Publish("error_reports_dropped", func() any { return reporter.Dropped() })
+		}
+		diagCfg := diagnostics.DefaultConfig()
+		if dir := os.Getenv("DIAGNOSTICS_DUMP_DIR"); dir != "" {
+			diagCfg.DumpDir = dir
+		}
+		auth := middleware.NewBearerAuth(middleware.BearerAuthConfig{Realm: "diagnostics", Tokens: adminTokens}, log)
+		diagSrv = &http.Server{
+			Addr:              addr,
+			Handler:           auth.Handler(diagnostics.NewHandler(diagCfg, log)),
+			ReadHeaderTimeout: 5 * time.Second,
+			// CPU profiles and traces stream for as long as ?seconds= asks.
+			WriteTimeout: 5 * time.Minute,
+		}
+		go func() {
+			log.Info("Diagnostics server listening", zap.String("addr", addr))
+			if err := diagSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Error("Diagnostics server failed", zap.Error(err))
+			}
+		}()
+	}
+
+	// Metrics are scraped from a private listener, like diagnostics, but
+	// without tokens: Prometheus scrapes anonymously and the series carry
+	// no user data.
+	var metricsSrv *http.Server
+	if addr := os.Getenv("METRICS_ADDR"); addr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", reg.Handler())
+		metricsSrv = &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      10 * time.Second,
+		}
+		go func() {
+			log.Info("Metrics server listening", zap.String("addr", addr))
+			if err := metricsSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Error("Metrics server failed", zap.Error(err))
+			}
+		}()
+	}
+
+	go func() {
+		log.Info("API server listening", zap.String("addr", srv.Addr))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("API server failed", zap.Error(err))
+		}
+	}()
+
+	<-ctx.Done()
+	log.Info("Shutting down API server")
+
+	// Fail readiness first and give the load balancer a probe interval to
+	// notice before we stop accepting connections.
+	checker.SetDraining(true)
+	time.Sleep(settings.ShutdownDrainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Error("Graceful shutdown failed", zap.Error(err))
+	}
+	if diagSrv != nil {
+		// A profile in progress is not worth delaying exit for.
+		_ = diagSrv.Close()
+	}
+	if metricsSrv != nil {
+		_ = metricsSrv.Close()
+	}
+	stopClicks()
+	<-clicksDone
+	stopPurges()
+	<-purgesDone
+	stopViews()
+	<-viewsDone
+	stopKnown()
+	<-knownDone
+	stopSuggest()
+	<-suggestDone
+	stopIndex()
+	<-indexDone
+	stopAnalytics()
+	<-analyticsDone
+	stopFlags()
+	<-flagsDone
+	stopSettings()
+	<-settingsDone
+	stopWarm()
+	<-warmDone
+	if err := bulk.Close(shutdownCtx); err != nil {
+		log.Error("Bulk workers did not drain", zap.Error(err))
+	}
+	if err := notifyPool.Close(shutdownCtx); err != nil {
+		log.Error("Notification workers did not drain", zap.Error(err))
+	}
+	stopTracing()
+	<-tracingDone
+	stopReporting()
+	<-reportingDone
+}
+
+// newSearchIndex configures the search backend SEARCH_BACKEND names:
+// "database", the default, searches the catalog where it is kept, with
+// Postgres full-text search if db is set, and "meilisearch" searches a
+// Meilisearch index, which the returned IndexSync must keep filled. SQLite
+// has no full-text search of the catalog, so needs Meilisearch.
+func newSearchIndex(store *memory.Store, db *sqlDatabase, repos storeRepos, log *zap.Logger) (search.Index, *search.IndexSync, error) {
+	switch name := os.Getenv("SEARCH_BACKEND"); name {
+	case "", "database":
+		switch {
+		case db == nil:
+			return store.ProductSearch(), nil, nil
+		case db.dialect == database.Postgres:
+			return postgres.NewSearchRepository(db.router, db.stmts), nil, nil
+		default:
+			return nil, nil, fmt.Errorf("the database search backend needs Postgres; set SEARCH_BACKEND=meilisearch to search a %s catalog", db.dialect)
+		}
+	case "meilisearch":
+		url := os.Getenv("MEILISEARCH_URL")
+		if url == "" {
+			return nil, nil, errors.New("MEILISEARCH_URL is required")
+		}
+		client := meilisearch.New(meilisearch.Config{
+			URL:    url,
+			APIKey: os.Getenv("MEILISEARCH_API_KEY"),
+			Index:  os.Getenv("MEILISEARCH_INDEX"),
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := client.Configure(ctx); err != nil {
+			// The index may already be configured; searches say if not.
+			log.Error("Meilisearch index settings not applied", zap.Error(err))
+		}
+		log.Info("Searching with Meilisearch", zap.String("url", url))
+		sync := search.NewIndexSync(client, search.IndexRepos{
+			Products:  repos.products,
+			Listings:  repos.listings,
+			Retailers: repos.retailers,
+		}, log)
+		return client, sync, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown SEARCH_BACKEND %q", name)
+	}
+}
+
+// newEmailProvider configures the provider EMAIL_PROVIDER names, "smtp" or
+// "ses". It returns nil when none is set.
+func newEmailProvider() (email.Provider, error) {
+	switch name := os.Getenv("EMAIL_PROVIDER"); name {
+	case "":
+		return nil, nil
+	case "smtp":
+		cfg := email.DefaultSMTPConfig()
+		cfg.Host = os.Getenv("SMTP_HOST")
+		if cfg.Host == "" {
+			return nil, errors.New("SMTP_HOST is required")
+		}
+		if raw := os.Getenv("SMTP_PORT"); raw != "" {
+			port, err := strconv.Atoi(raw)
+			if err != nil || port <= 0 {
+				return nil, fmt.Errorf("invalid SMTP_PORT %q", raw)
+			}
+			cfg.Port = port
+		}
+		cfg.Username, cfg.Password = os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD")
+		cfg.ImplicitTLS = os.Getenv("SMTP_IMPLICIT_TLS") == "true"
+		return email.NewSMTP(cfg), nil
+	case "ses":
+		cfg := email.DefaultSESConfig()
+		cfg.Region = os.Getenv("AWS_REGION")
+		cfg.Credentials = email.AWSCredentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+		if cfg.Region == "" || cfg.Credentials.AccessKeyID == "" || cfg.Credentials.SecretAccessKey == "" {
+			return nil, errors.New("AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required")
+		}
+		cfg.ConfigurationSet = os.Getenv("SES_CONFIGURATION_SET")
+		return email.NewSES(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown EMAIL_PROVIDER %q", name)
+	}
+}
+
+// newSMSProvider configures the provider SMS_PROVIDER names, "twilio" or
+// "msg91". It returns nil when none is set.
+func newSMSProvider() (sms.Provider, error) {
+	switch name := os.Getenv("SMS_PROVIDER"); name {
+	case "":
+		return nil, nil
+	case "twilio":
+		cfg := sms.TwilioConfig{
+			AccountSID: os.Getenv("TWILIO_ACCOUNT_SID"),
+			AuthToken:  os.Getenv("TWILIO_AUTH_TOKEN"),
+			From:       os.Getenv("TWILIO_FROM"),
+		}
+		if cfg.AccountSID == "" || cfg.AuthToken == "" || cfg.From == "" {
+			return nil, errors.New("TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN and TWILIO_FROM are required")
+		}
+		return sms.NewTwilio(cfg), nil
+	case "msg91":
+		cfg := sms.MSG91Config{
+			AuthKey:         os.Getenv("MSG91_AUTH_KEY"),
+			AlertTemplateID: os.Getenv("MSG91_ALERT_TEMPLATE_ID"),
+			CodeTemplateID:  os.Getenv("MSG91_CODE_TEMPLATE_ID"),
+		}
+		if cfg.AuthKey == "" || cfg.AlertTemplateID == "" || cfg.CodeTemplateID == "" {
+			return nil, errors.New("MSG91_AUTH_KEY, MSG91_ALERT_TEMPLATE_ID and MSG91_CODE_TEMPLATE_ID are required")
+		}
+		return sms.NewMSG91(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown SMS_PROVIDER %q", name)
+	}
+}
+
+// smsConfig reads the SMS country and spending limits over the defaults.
+func smsConfig() (sms.Config, error) {
+	cfg := sms.DefaultConfig()
+	if raw := os.Getenv("SMS_COUNTRY_CODES"); raw != "" {
+		cfg.Countries = strings.Split(strings.ReplaceAll(raw, "+", ""), ",")
+		for i, code := range cfg.Countries {
+			code = strings.TrimSpace(code)
+			if _, err := strconv.Atoi(code); err != nil || code[0] == '-' {
+				return cfg, fmt.Errorf("invalid SMS_COUNTRY_CODES %q: want calling codes such as 91,971", raw)
+			}
+			cfg.Countries[i] = code
+		}
+		cfg.DefaultCountry = cfg.Countries[0]
+	}
+	if raw := os.Getenv("SMS_MAX_PER_USER_PER_DAY"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			return cfg, fmt.Errorf("invalid SMS_MAX_PER_USER_PER_DAY %q", raw)
+		}
+		cfg.MaxPerUserPerDay = n
+	}
+	for key, dst := range map[string]*float64{
+		"SMS_COST_PER_MESSAGE": &cfg.CostPerMessage,
+		"SMS_DAILY_BUDGET":     &cfg.DailyBudget,
+	} {
+		raw := os.Getenv(key)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 {
+			return cfg, fmt.Errorf("invalid %s %q", key, raw)
+		}
+		*dst = v
+	}
+	// A zero budget turns the cap off, but one below a single text's cost
+	// would silently stop every text.
+	if cfg.CostPerMessage <= 0 {
+		return cfg, errors.New("SMS_COST_PER_MESSAGE must be positive")
+	}
+	if cfg.DailyBudget > 0 && cfg.DailyBudget < cfg.CostPerMessage {
+		return cfg, fmt.Errorf("SMS_DAILY_BUDGET %g is below SMS_COST_PER_MESSAGE %g, so no text could be sent; set 0 for no budget", cfg.DailyBudget, cfg.CostPerMessage)
+	}
+	return cfg, nil
+}
+
+// templatesConfig reads template version pins from
+// NOTIFY_TEMPLATE_VERSIONS, e.g. "price_alert=1,price_alert_digest=2".
+func templatesConfig() (templates.Config, error) {
+	var cfg templates.Config
+	raw := os.Getenv("NOTIFY_TEMPLATE_VERSIONS")
+	if raw == "" {
+		return cfg, nil
+	}
+	cfg.Versions = make(map[string]int)
+	for _, pin := range strings.Split(raw, ",") {
+		name, v, ok := strings.Cut(strings.TrimSpace(pin), "=")
+		n, err := strconv.Atoi(v)
+		if !ok || name == "" || err != nil || n <= 0 {
+			return cfg, fmt.Errorf("want name=version pairs, got %q", pin)
+		}
+		cfg.Versions[name] = n
+	}
+	return cfg, nil
+}
+
+// cachePolicy reads CACHE_<name>_TTL and CACHE_<name>_STALE over def.
+func cachePolicy(name string, def cache.Policy) (cache.Policy, error) {
+	p := def
+	for key, dst := range map[string]*time.Duration{
+		"CACHE_" + name + "_TTL":   &p.TTL,
+		"CACHE_" + name + "_STALE": &p.StaleWhileRevalidate,
+	} {
+		raw := os.Getenv(key)
+		if raw == "" {
+			continue
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			return def, fmt.Errorf("%s: want a non-negative duration such as 90s, got %q", key, raw)
+		}
+		*dst = d
+	}
+	if p.TTL <= 0 {
+		return def, fmt.Errorf("CACHE_%s_TTL must be positive", name)
+	}
+	return p, nil
+}
+
+// tracerConfig reads the OpenTelemetry exporter variables. It returns
+// false when no OTLP endpoint is set, leaving tracing off.
+func tracerConfig() (tracing.Config, bool, error) {
+	cfg := tracing.DefaultConfig()
+	cfg.Endpoint = os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); cfg.Endpoint == "" && base != "" {
+		cfg.Endpoint = strings.TrimSuffix(base, "/") + "/v1/traces"
+	}
+	if cfg.Endpoint == "" {
+		return cfg, false, nil
+	}
+	cfg.ServiceName = envOr("OTEL_SERVICE_NAME", cfg.ServiceName)
+	if raw := os.Getenv("OTEL_TRACES_SAMPLER_ARG"); raw != "" {
+		ratio, err := strconv.ParseFloat(raw, 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			return cfg, false, fmt.Errorf("OTEL_TRACES_SAMPLER_ARG: want a ratio from 0 to 1, got %q", raw)
+		}
+		cfg.SampleRatio = ratio
+	}
+	headers, err := tracing.ParseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	if err != nil {
+		return cfg, false, fmt.Errorf("OTEL_EXPORTER_OTLP_HEADERS: %w", err)
+	}
+	cfg.Headers = headers
+	return cfg, true, nil
+}
+
+// errtrackConfig reads the error tracking variables. It returns false when
+// SENTRY_DSN is unset, leaving error tracking off.
+func errtrackConfig() (errtrack.Config, bool, error) {
+	cfg := errtrack.DefaultConfig()
+	cfg.DSN = os.Getenv("SENTRY_DSN")
+	if cfg.DSN == "" {
+		return cfg, false, nil
+	}
+	cfg.Release = envOr("SENTRY_RELEASE", envOr("APP_VERSION", "dev"))
+	cfg.Environment = envOr("SENTRY_ENVIRONMENT", envOr("APP_ENV", "development"))
+	cfg.ServerName, _ = os.Hostname()
+	if raw := os.Getenv("SENTRY_SAMPLE_RATE"); raw != "" {
+		rate, err := strconv.ParseFloat(raw, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return cfg, false, fmt.Errorf("SENTRY_SAMPLE_RATE: want a rate from 0 to 1, got %q", raw)
+		}
+		cfg.SampleRate = rate
+	}
+	return cfg, true, nil
+}
+
+// poolLogInterval is how often database pool statistics are logged.
+const poolLogInterval = time.Minute
+
+// storeRepos are the repositories of everything the database holds when
+// there is one: the catalog and its price history, accounts and their
+// alerts, and the tables that stand apart from them. Without a database
+// the memory store holds them, as it holds the rest in either case.
+type storeRepos struct {
+	products     repositories.ProductRepository
+	retailers    repositories.RetailerRepository
+	listings     repositories.ListingRepository
+	prices       repositories.PriceRepository
+	dailyPrices  repositories.DailyPriceRepository
+	catalogAdmin repositories.CatalogAdminRepository
+	selectors    repositories.SelectorRepository
+	priceWriter  repositories.PriceWriter
+	stats        repositories.StatsRepository
+	integrity    repositories.IntegrityRepository
+	priceLog     repositories.PriceLogRepository
+	outbox       repositories.OutboxRepository
+	rowCounter   database.RowCounter
+	// tx groups calls to the repositories above, and writes the events
+	// they raise to outbox with the changes that raise them.
+	tx repositories.Transactor
+
+	users         repositories.UserRepository
+	sessions      repositories.SessionRepository
+	verifications repositories.VerificationRepository
+	identities    repositories.IdentityRepository
+	apiTokens     repositories.APITokenRepository
+	alerts        repositories.AlertRepository
+	userData      repositories.UserDataRepository
+
+	audit      repositories.AuditRepository
+	searchLogs repositories.SearchLogRepository
+	synonyms   repositories.SynonymRepository
+	flags      repositories.FlagRepository
+}
+
+// memoryRepos returns the storeRepos of store.
+func memoryRepos(store *memory.Store) storeRepos {
+	return storeRepos{
+		products:      store.Products(),
+		retailers:     store.Retailers(),
+		listings:      store.Listings(),
+		prices:        store.Prices(),
+		dailyPrices:   store.DailyPrices(),
+		catalogAdmin:  store.CatalogAdmin(),
+		selectors:     store.Selectors(),
+		priceWriter:   store.PriceWriter(),
+		stats:         store.Stats(),
+		integrity:     store.Integrity(),
+		priceLog:      store.PriceLog(),
+		outbox:        store.Outbox(),
+		rowCounter:    store,
+		tx:            store.Transactor(),
+		users:         store.Users(),
+		sessions:      store.Sessions(),
+		verifications: store.Verifications(),
+		identities:    store.Identities(),
+		apiTokens:     store.APITokens(),
+		alerts:        store.Alerts(),
+		userData:      store.UserData(),
+		audit:         store.Audit(),
+		searchLogs:    store.SearchLogs(),
+		synonyms:      store.Synonyms(),
+		flags:         store.Flags(),
+	}
+}
+
+// repos returns the storeRepos of db, which seals personal data with key.
+// A user's records that only store holds are exported and erased with the
+// rest of their data.
+func (db *sqlDatabase) repos(store *memory.Store, key *sqlstore.PIIKey) storeRepos {
+	d, r, stmts := db.dialect, db.router, db.stmts
+	users := sqlstore.NewUserRepository(d, r, stmts, key)
+	prices := sqlstore.NewPriceRepository(d, r, stmts)
+	var dailyPrices repositories.DailyPriceRepository = prices
+	if d == database.Postgres {
+		// Postgres keeps the days rolled up, in migration 008's view.
+		dailyPrices = postgres.NewDailyPriceRepository(r, stmts)
+	}
+	return storeRepos{
+		products:      sqlstore.NewProductRepository(d, r, stmts),
+		retailers:     sqlstore.NewRetailerRepository(d, r, stmts),
+		listings:      sqlstore.NewListingRepository(d, r, stmts),
+		prices:        prices,
+		dailyPrices:   dailyPrices,
+		catalogAdmin:  sqlstore.NewCatalogAdminRepository(d, r, stmts),
+		selectors:     sqlstore.NewSelectorRepository(d, r, stmts),
+		priceWriter:   sqlstore.NewPriceIngestRepository(d, r),
+		stats:         sqlstore.NewStatsRepository(r, stmts),
+		integrity:     sqlstore.NewIntegrityRepository(d, r),
+		priceLog:      sqlstore.NewPriceLogRepository(d, r, stmts),
+		outbox:        sqlstore.NewOutboxRepository(d, r, stmts),
+		rowCounter:    database.NewTableCounter(d, r.Writer()),
+		tx:            database.NewTxManager(r.Writer()),
+		users:         users,
+		sessions:      sqlstore.NewSessionRepository(d, r, stmts),
+		verifications: sqlstore.NewVerificationRepository(d, r, stmts, key),
+		identities:    sqlstore.NewIdentityRepository(d, r, stmts),
+		apiTokens:     sqlstore.NewAPITokenRepository(d, r, stmts),
+		alerts:        sqlstore.NewAlertRepository(d, r, stmts),
+		userData:      sqlstore.NewUserDataRepository(d, r, stmts, users, store.UserDataExceptAccounts()),
+		audit:         sqlstore.NewAuditRepository(d, r, stmts),
+		searchLogs:    sqlstore.NewSearchLogRepository(d, r, stmts),
+		synonyms:      sqlstore.NewSynonymRepository(d, r, stmts),
+		flags:         sqlstore.NewFlagRepository(d, r, stmts),
+	}
+}
+
+// sqlDatabase is the database at DATABASE_URL: its pools, behind a
+// Router, and the statements prepared on them.
+type sqlDatabase struct {
+	dialect database.Dialect
+	router  *database.Router
+	stmts   *database.StatementCache
+	// pools are the primary's and replicas' pools, by the name they
+	// report metrics under.
+	pools map[string]*sql.DB
+}
+
+// openDatabase opens the database at raw, and the read replicas at
+// replicaURLs, with their statements and pools reported to m. It checks
+// the primary connects and, if Postgres, carries this build's schema.
+// Replicas that are unreachable or lagging are logged and left out of
+// reads until Router.Run finds them caught up.
+func openDatabase(ctx context.Context, raw, replicaURLs string, m *database.Metrics, log *zap.Logger) (*sqlDatabase, error) {
+	cfg, err := database.ParseURL(raw, database.DefaultPoolConfig())
+	if err != nil {
+		return nil, err
+	}
+	replicaCfgs, err := database.ParseReplicaURLs(replicaURLs, database.DefaultPoolConfig())
+	if err != nil {
+		return nil, fmt.Errorf("DATABASE_REPLICA_URLS: %w", err)
+	}
+	for i, rc := range replicaCfgs {
+		if cfg.Dialect != database.Postgres || rc.Dialect != database.Postgres {
+			return nil, fmt.Errorf("DATABASE_REPLICA_URLS: replica %d: read replicas are only supported for Postgres", i)
+		}
+	}
+	primary, err := database.OpenObserved(cfg.Dialect.Driver, cfg, m.Observe)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := primary.PingContext(ctx); err != nil {
+		_ = primary.Close()
+		return nil, fmt.Errorf("connect to database: %w", err)
+	}
+	if cfg.Dialect == database.Postgres {
+		if err := checkSchema(ctx, primary, log); err != nil {
+			_ = primary.Close()
+			return nil, fmt.Errorf("schema is not current: %w", err)
+		}
+	}
+	m.WatchPool("primary", primary)
+	pools := map[string]*sql.DB{"primary": primary}
+
+	var replicas []*sql.DB
+	for i, rc := range replicaCfgs {
+		replica, err := database.OpenObserved(rc.Dialect.Driver, rc, m.Observe)
+		if err != nil {
+			for _, r := range replicas {
+				_ = r.Close()
+			}
+			_ = primary.Close()
+			return nil, fmt.Errorf("open replica %d: %w", i, err)
+		}
+		name := fmt.Sprintf("replica-%d", i)
+		m.WatchPool(name, replica)
+		pools[name] = replica
+		replicas = append(replicas, replica)
+	}
+	router := database.NewRouter(database.DefaultRouterConfig(), primary, replicas, log)
+	if err := router.CheckReplicas(ctx); err != nil {
+		log.Warn("Read replicas unusable", zap.Int("replicas", len(replicas)), zap.Error(err))
+	}
+	return &sqlDatabase{
+		dialect: cfg.Dialect,
+		router:  router,
+		stmts:   database.NewStatementCache(cfg.Pool, 0),
+		pools:   pools,
+	}, nil
+}
+
+// MonitorPools logs each pool's statistics every poolLogInterval until ctx
+// is done.
+func (d *sqlDatabase) MonitorPools(ctx context.Context, log *zap.Logger) {
+	for name, pool := range d.pools {
+		go database.MonitorPool(ctx, pool, poolLogInterval, log.With(zap.String("pool", name)))
+	}
+}
+
+// Close closes the prepared statements, then the pools.
+func (d *sqlDatabase) Close() error {
+	return errors.Join(d.stmts.Close(), d.router.Close())
+}
+
+// checkSchema returns why db does not have exactly this build's
+// migrations applied, if it does not.
+func checkSchema(ctx context.Context, db *sql.DB, log *zap.Logger) error {
+	all, err := database.LoadMigrations(migrations.FS)
+	if err != nil {
+		return err
+	}
+	m := database.NewMigrator(db, all, log)
+	if err := m.Check(ctx); err != nil {
+		return err
+	}
+	if err := m.CheckIndexes(ctx, migrations.RequiredIndexes); err != nil {
+		return err
+	}
+	log.Info("Database schema is current", zap.Int("version", m.Latest()))
+	return nil
+}
+
+// partitionMaintainer returns the maintainer of price_history's partitions
+// in the database at raw, or nil if it is not Postgres. Its pool reports
+// to m as "maintenance", and is logged until ctx is done.
+func partitionMaintainer(ctx context.Context, raw string, m *database.Metrics, log *zap.Logger) (*postgres.PartitionMaintainer, error) {
+	cfg, err := database.ParseURL(raw, database.DefaultPoolConfig())
+	if err != nil || cfg.Dialect != database.Postgres {
+		return nil, err
+	}
+	partCfg := postgres.DefaultPartitionConfig()
+	if v := os.Getenv("PRICE_HISTORY_RETENTION_DAYS"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days < 0 {
+			return nil, fmt.Errorf("PRICE_HISTORY_RETENTION_DAYS: want a number of days, 0 to keep all, got %q", v)
+		}
+		partCfg.Retention = time.Duration(days) * 24 * time.Hour
+	}
+	// Maintenance runs a statement at a time, every few hours.
+	cfg.Pool.MaxOpenConns, cfg.Pool.MaxIdleConns = 1, 0
+	db, err := database.OpenObserved(cfg.Dialect.Driver, cfg, m.Observe)
+	if err != nil {
+		return nil, err
+	}
+	m.WatchPool("maintenance", db)
+	go database.MonitorPool(ctx, db, poolLogInterval, log.With(zap.String("pool", "maintenance")))
+	return postgres.NewPartitionMaintainer(partCfg, db, log), nil
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// anyReachable reports a user reachable if any of fns does.
+func anyReachable(fns ...alerts.ReachableFunc) alerts.ReachableFunc {
+	return func(ctx context.Context, userID string) (bool, error) {
+		for _, fn := range fns {
+			if ok, err := fn(ctx, userID); ok || err != nil {
+				return ok, err
+			}
+		}
+		return false, nil
+	}
+}
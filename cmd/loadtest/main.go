@@ -0,0 +1,104 @@
+// Command loadtest replays a weighted mix of API reads against a running
+// server and reports p50/p95/p99 latency per scenario against the route
+// budgets the API enforces.
+//
+// Latencies are measured by the client, so they include the network; run it
+// close to the target when checking budgets. The API rate-limits per client
+// IP, so raise RATE_LIMIT_* on the target or expect 429s in the report.
+//
+//	loadtest -target http://localhost:8080 -duration 30s -concurrency 20
+//	loadtest -target https://staging.example.com -mix search=10 -fail-on-budget
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/loadtest"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	cfg := loadtest.DefaultConfig("http://localhost:8080")
+	var (
+		mix          = flag.String("mix", "", "scenario weights as name=weight pairs, such as prices=50,search=10")
+		products     = flag.String("products", "", "comma-separated product IDs; discovered from /api/v1/deals when empty")
+		asJSON       = flag.Bool("json", false, "write the report as JSON")
+		failOnBudget = flag.Bool("fail-on-budget", false, "exit 1 when a scenario's latency exceeds its budget")
+		percentile   = flag.Int("percentile", 95, "percentile checked against budgets: 50, 95 or 99")
+		maxErrorRate = flag.Float64("max-error-rate", 0.01, "exit 1 when more than this fraction of requests fail")
+	)
+	flag.StringVar(&cfg.Target, "target", cfg.Target, "API origin to load")
+	flag.DurationVar(&cfg.Duration, "duration", cfg.Duration, "how long to send traffic")
+	flag.IntVar(&cfg.Concurrency, "concurrency", cfg.Concurrency, "parallel workers")
+	flag.Float64Var(&cfg.Rate, "rate", 0, "requests per second across all workers; 0 is unlimited")
+	flag.DurationVar(&cfg.Timeout, "timeout", cfg.Timeout, "per-request timeout")
+	flag.Uint64Var(&cfg.Seed, "seed", cfg.Seed, "random seed for a reproducible request sequence")
+	flag.Parse()
+
+	scenarios, err := loadtest.ParseMix(*mix, loadtest.DefaultScenarios())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	for _, id := range strings.Split(*products, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			cfg.ProductIDs = append(cfg.ProductIDs, id)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	client := &http.Client{Transport: &http.Transport{
+		MaxIdleConnsPerHost: cfg.Concurrency,
+		IdleConnTimeout:     90 * time.Second,
+	}}
+	report, err := loadtest.Run(ctx, cfg, scenarios, client)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "loadtest:", err)
+		return 1
+	}
+	over := report.CheckBudgets(*percentile)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "loadtest:", err)
+		return 1
+	}
+
+	failed := false
+	if report.Requests == 0 {
+		fmt.Fprintln(os.Stderr, "loadtest: no requests completed")
+		failed = true
+	} else if rate := float64(report.Errors) / float64(report.Requests); rate > *maxErrorRate {
+		fmt.Fprintf(os.Stderr, "loadtest: error rate %.2f%% exceeds %.2f%%\n", rate*100, *maxErrorRate*100)
+		failed = true
+	}
+	if *failOnBudget && len(over) > 0 {
+		for _, s := range over {
+			fmt.Fprintf(os.Stderr, "loadtest: %s p%d over its %s budget\n", s.Name, *percentile, s.Budget)
+		}
+		failed = true
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
@@ -2,6 +2,7 @@
 -- Migration: 001_auth_schema.sql
 -- Created: 2024-01-15
 -- Description: Initial authentication system with GDPR compliance
+-- Transaction: none (creates indexes CONCURRENTLY)
 
 -- Enable required extensions
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
@@ -0,0 +1,59 @@
+-- Product Full-Text Search
+-- Migration: 003_product_search.sql
+-- Created: 2026-10-16
+-- Description: Weighted tsvector over products (brand > name > category and description), kept current by triggers
+
+-- Products carry their own search document so a search is one indexed scan.
+-- The 'simple' configuration does no stemming: brand and product names are
+-- proper nouns, and stemmers mangle them ("isolate" -> "isol").
+ALTER TABLE products ADD COLUMN search_vector tsvector;
+
+-- Builds a product's search document. Weights rank matches in the brand (A)
+-- over the product name (B) over the category and description (C).
+CREATE OR REPLACE FUNCTION product_search_vector(p products) RETURNS tsvector AS $$
+    SELECT
+        setweight(to_tsvector('simple', coalesce((SELECT name FROM brands WHERE id = p.brand_id), '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(p.name, '')), 'B') ||
+        setweight(to_tsvector('simple', coalesce((SELECT name FROM categories WHERE id = p.category_id), '')), 'C') ||
+        setweight(to_tsvector('simple', coalesce(p.description, '')), 'C');
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION update_product_search_vector() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := product_search_vector(NEW);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER update_products_search_vector
+    BEFORE INSERT OR UPDATE OF name, description, brand_id, category_id ON products
+    FOR EACH ROW EXECUTE FUNCTION update_product_search_vector();
+
+-- Renaming a brand or category rewrites the documents of its products.
+CREATE OR REPLACE FUNCTION refresh_product_search_vectors() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_TABLE_NAME = 'brands' THEN
+        UPDATE products p SET search_vector = product_search_vector(p) WHERE p.brand_id = NEW.id;
+    ELSE
+        UPDATE products p SET search_vector = product_search_vector(p) WHERE p.category_id = NEW.id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER refresh_search_on_brand_rename
+    AFTER UPDATE OF name ON brands
+    FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name)
+    EXECUTE FUNCTION refresh_product_search_vectors();
+
+CREATE TRIGGER refresh_search_on_category_rename
+    AFTER UPDATE OF name ON categories
+    FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name)
+    EXECUTE FUNCTION refresh_product_search_vectors();
+
+-- Backfill existing products, then index the documents.
+UPDATE products p SET search_vector = product_search_vector(p);
+
+CREATE INDEX idx_products_search_vector ON products USING GIN (search_vector) WHERE is_active;
+
+COMMENT ON COLUMN products.search_vector IS 'Weighted full-text document: brand (A), name (B), category and description (C)';
@@ -0,0 +1,46 @@
+-- Typo-Tolerant Product Search
+-- Migration: 004_product_search_trigram.sql
+-- Created: 2026-10-16
+-- Description: Trigram index over product text for searches the tsvector finds nothing for
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- The same text search_vector is built from, lower-cased and flattened, so
+-- word_similarity can match a misspelt term against any word in it.
+ALTER TABLE products ADD COLUMN search_text text;
+
+CREATE OR REPLACE FUNCTION product_search_text(p products) RETURNS text AS $$
+    SELECT lower(concat_ws(' ',
+        (SELECT name FROM brands WHERE id = p.brand_id),
+        p.name,
+        (SELECT name FROM categories WHERE id = p.category_id),
+        p.description));
+$$ LANGUAGE sql STABLE;
+
+-- Both documents are rebuilt by the triggers from migration 003.
+CREATE OR REPLACE FUNCTION update_product_search_vector() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := product_search_vector(NEW);
+    NEW.search_text := product_search_text(NEW);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION refresh_product_search_vectors() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_TABLE_NAME = 'brands' THEN
+        UPDATE products p SET search_vector = product_search_vector(p), search_text = product_search_text(p)
+        WHERE p.brand_id = NEW.id;
+    ELSE
+        UPDATE products p SET search_vector = product_search_vector(p), search_text = product_search_text(p)
+        WHERE p.category_id = NEW.id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+UPDATE products p SET search_text = product_search_text(p);
+
+CREATE INDEX idx_products_search_text_trgm ON products USING GIN (search_text gin_trgm_ops) WHERE is_active;
+
+COMMENT ON COLUMN products.search_text IS 'Lower-cased brand, name, category and description for trigram (typo-tolerant) matching';
@@ -0,0 +1,19 @@
+-- Search Synonyms
+-- Migration: 005_search_synonyms.sql
+-- Created: 2026-10-16
+-- Description: Groups of equivalent search terms (WPI = whey protein isolate), applied at query time
+
+CREATE TABLE search_synonyms (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    -- The group's terms as a JSON array of strings, as the admin entered them.
+    terms JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    CONSTRAINT chk_search_synonyms_terms CHECK (jsonb_typeof(terms) = 'array' AND jsonb_array_length(terms) >= 2)
+);
+
+CREATE TRIGGER update_search_synonyms_updated_at BEFORE UPDATE ON search_synonyms
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE search_synonyms IS 'Groups of search terms treated as equivalent, managed through the admin API';
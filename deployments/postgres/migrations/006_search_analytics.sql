@@ -0,0 +1,34 @@
+-- Search Analytics
+-- Migration: 006_search_analytics.sql
+-- Created: 2026-10-16
+-- Description: Anonymized search log and result clicks, for top and zero-result query reports
+
+-- One row per page of results served. Nothing identifies who searched:
+-- queries are reduced to their terms with emails and long numbers
+-- replaced by '#', and times are truncated to the hour.
+CREATE TABLE search_logs (
+    id VARCHAR(64) PRIMARY KEY,          -- Quoted back by result clicks
+    query VARCHAR(255) NOT NULL,
+    page INTEGER NOT NULL DEFAULT 1,
+    results INTEGER NOT NULL,
+    fuzzy BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_search_logs_created_at ON search_logs(created_at);
+CREATE INDEX idx_search_logs_zero_results ON search_logs(query, created_at) WHERE results = 0 AND page = 1;
+
+-- Clicks may name searches already purged or never stored, so there is
+-- no foreign key.
+CREATE TABLE search_clicks (
+    search_id VARCHAR(64) NOT NULL,
+    product_id VARCHAR(255) NOT NULL,
+    position INTEGER NOT NULL CHECK (position > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_search_clicks_created_at ON search_clicks(created_at);
+CREATE INDEX idx_search_clicks_search_id ON search_clicks(search_id);
+
+COMMENT ON TABLE search_logs IS 'Anonymized searches, kept 90 days';
+COMMENT ON TABLE search_clicks IS 'Positions of the search results opened, kept 90 days';
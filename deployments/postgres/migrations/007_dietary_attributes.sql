@@ -0,0 +1,50 @@
+-- Dietary Attributes
+-- Migration: 007_dietary_attributes.sql
+-- Created: 2026-10-16
+-- Description: Vegan, lactose-free, gluten-free and sugar-free claims read from product text, kept current by a trigger
+
+ALTER TABLE products ADD COLUMN dietary TEXT[] NOT NULL DEFAULT '{}';
+
+-- Reads the attributes a product's name and description claim. It mirrors
+-- domain.ExtractDietary: text is reduced to space-separated words, a
+-- claim right after "not" or "non" is ignored, and vegan implies
+-- lactose-free. Change both together.
+CREATE OR REPLACE FUNCTION product_dietary(name TEXT, description TEXT) RETURNS TEXT[] AS $$
+    WITH t AS (
+        SELECT regexp_replace(
+            ' ' || regexp_replace(lower(coalesce(name, '') || ' ' || coalesce(description, '')), '[^[:alnum:]]+', ' ', 'g') || ' ',
+            ' (not|non) [[:alnum:]]+', ' ', 'g') AS s
+    ), claims AS (
+        SELECT
+            s ~ ' (vegan|plant based|plant protein) ' AS vegan,
+            s ~ ' (lactose free|no lactose|zero lactose|without lactose|dairy free|no dairy) ' AS lactose_free,
+            s ~ ' (gluten free|no gluten|zero gluten|without gluten) ' AS gluten_free,
+            s ~ ' (sugar free|no sugar|zero sugar|without sugar|no added sugar|0g sugar|0 sugar) ' AS sugar_free
+        FROM t
+    )
+    SELECT array_remove(ARRAY[
+        CASE WHEN vegan THEN 'vegan' END,
+        CASE WHEN vegan OR lactose_free THEN 'lactose-free' END,
+        CASE WHEN gluten_free THEN 'gluten-free' END,
+        CASE WHEN sugar_free THEN 'sugar-free' END
+    ], NULL)
+    FROM claims;
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION update_product_dietary() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.dietary := product_dietary(NEW.name, NEW.description);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER update_products_dietary
+    BEFORE INSERT OR UPDATE OF name, description ON products
+    FOR EACH ROW EXECUTE FUNCTION update_product_dietary();
+
+-- Backfill existing products, then index the attributes for filtering.
+UPDATE products SET dietary = product_dietary(name, description);
+
+CREATE INDEX idx_products_dietary ON products USING GIN (dietary) WHERE is_active;
+
+COMMENT ON COLUMN products.dietary IS 'Dietary attributes claimed by the name and description: vegan, lactose-free, gluten-free, sugar-free';
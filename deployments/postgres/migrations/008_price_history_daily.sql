@@ -0,0 +1,81 @@
+-- Daily Price History
+-- Migration: 008_price_history_daily.sql
+-- Created: 2026-10-16
+-- Description: Stock flag on price observations and the price_history_daily rollup, on TimescaleDB where the server loads it
+
+-- PricePoint.InStock, which the scraper has always reported.
+ALTER TABLE price_history ADD COLUMN in_stock BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE price_history ALTER COLUMN recorded_at SET NOT NULL;
+
+-- TimescaleDB is used when the server preloads it (shared_preload_libraries),
+-- as the timescale/timescaledb images do; it cannot be loaded otherwise.
+-- Then price_history becomes a hypertable of weekly chunks, compressed
+-- after 90 days, and price_history_daily a continuous aggregate refreshed
+-- hourly that also rolls up the hour not yet refreshed on read. Without it
+-- price_history_daily is a plain view with the same columns, so the API
+-- reads it the same way on either.
+--
+-- The aggregate starts empty and the policy only refreshes the last three
+-- days, so after enabling TimescaleDB on a database with history, backfill
+-- once, outside a transaction:
+--   CALL refresh_continuous_aggregate('price_history_daily', NULL, now() - INTERVAL '1 hour');
+DO $$
+BEGIN
+    IF current_setting('shared_preload_libraries', true) NOT LIKE '%timescaledb%' THEN
+        RAISE NOTICE 'timescaledb is not preloaded; price_history_daily is a plain view';
+        EXECUTE $view$
+            CREATE VIEW price_history_daily AS
+            SELECT product_listing_id,
+                date_trunc('day', recorded_at, 'UTC') AS day,
+                min(price) AS min_price,
+                avg(price) AS avg_price,
+                max(price) AS max_price,
+                (array_agg(price ORDER BY recorded_at DESC))[1] AS close_price,
+                bool_or(in_stock) AS in_stock,
+                count(*) AS samples,
+                min(currency) AS currency
+            FROM price_history
+            GROUP BY product_listing_id, date_trunc('day', recorded_at, 'UTC')
+        $view$;
+        RETURN;
+    END IF;
+
+    CREATE EXTENSION IF NOT EXISTS timescaledb;
+
+    -- A hypertable's unique keys must include its time column.
+    ALTER TABLE price_history DROP CONSTRAINT price_history_pkey;
+    ALTER TABLE price_history ADD PRIMARY KEY (id, recorded_at);
+    PERFORM create_hypertable('price_history', 'recorded_at',
+        chunk_time_interval => INTERVAL '7 days', migrate_data => true);
+
+    ALTER TABLE price_history SET (
+        timescaledb.compress,
+        timescaledb.compress_segmentby = 'product_listing_id',
+        timescaledb.compress_orderby = 'recorded_at DESC'
+    );
+    PERFORM add_compression_policy('price_history', INTERVAL '90 days');
+
+    EXECUTE $view$
+        CREATE MATERIALIZED VIEW price_history_daily
+        WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+        SELECT product_listing_id,
+            time_bucket(INTERVAL '1 day', recorded_at) AS day,
+            min(price) AS min_price,
+            avg(price) AS avg_price,
+            max(price) AS max_price,
+            last(price, recorded_at) AS close_price,
+            bool_or(in_stock) AS in_stock,
+            count(*) AS samples,
+            min(currency) AS currency
+        FROM price_history
+        GROUP BY product_listing_id, time_bucket(INTERVAL '1 day', recorded_at)
+        WITH NO DATA
+    $view$;
+    PERFORM add_continuous_aggregate_policy('price_history_daily',
+        start_offset => INTERVAL '3 days',
+        end_offset => INTERVAL '1 hour',
+        schedule_interval => INTERVAL '1 hour');
+END
+$$;
+
+COMMENT ON COLUMN price_history.in_stock IS 'Whether the listing was in stock when the price was recorded';
@@ -0,0 +1,26 @@
+-- Soft Deletes
+-- Migration: 009_soft_deletes.sql
+-- Created: 2026-10-16
+-- Description: deleted_at on products, product_variants and price_alerts, so deletions can be restored
+
+-- A deleted row is kept, with everything referring to it, until restored.
+-- Reads skip rows with deleted_at set; only the admin API returns them.
+ALTER TABLE products ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE product_variants ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE price_alerts ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Searches filter on is_active and deleted_at together; the partial search
+-- indexes from migrations 003, 004 and 007 cover only live products.
+DROP INDEX idx_products_search_vector;
+CREATE INDEX idx_products_search_vector ON products USING GIN (search_vector) WHERE is_active AND deleted_at IS NULL;
+DROP INDEX idx_products_search_text_trgm;
+CREATE INDEX idx_products_search_text_trgm ON products USING GIN (search_text gin_trgm_ops) WHERE is_active AND deleted_at IS NULL;
+DROP INDEX idx_products_dietary;
+CREATE INDEX idx_products_dietary ON products USING GIN (dietary) WHERE is_active AND deleted_at IS NULL;
+
+-- The admin API lists a user's deleted alerts to restore one.
+CREATE INDEX idx_alerts_deleted ON price_alerts(user_id, deleted_at) WHERE deleted_at IS NOT NULL;
+
+COMMENT ON COLUMN products.deleted_at IS 'When the product was deleted; NULL while it is live';
+COMMENT ON COLUMN product_variants.deleted_at IS 'When the variant was deleted; NULL while it is live';
+COMMENT ON COLUMN price_alerts.deleted_at IS 'When the alert was deleted; NULL while it is live';
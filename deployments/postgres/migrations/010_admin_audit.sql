@@ -0,0 +1,19 @@
+-- Admin Audit Log
+-- Migration: 010_admin_audit.sql
+-- Created: 2026-10-16
+-- Description: Actor, reason and changed fields on audit_logs, for admin API changes and manual price corrections
+
+-- Admin callers are bearer-token principals as well as users, so the actor
+-- is kept as text; user_id stays for what users do to their own account.
+ALTER TABLE audit_logs ADD COLUMN actor_id VARCHAR(255);
+ALTER TABLE audit_logs ADD COLUMN reason TEXT;
+ALTER TABLE audit_logs ADD COLUMN changes JSONB;
+UPDATE audit_logs SET actor_id = user_id::text WHERE user_id IS NOT NULL;
+
+-- The admin audit endpoint filters by actor or action, newest first.
+CREATE INDEX idx_audit_logs_actor ON audit_logs(actor_id, created_at DESC);
+CREATE INDEX idx_audit_logs_action_created ON audit_logs(action, created_at DESC);
+
+COMMENT ON COLUMN audit_logs.actor_id IS 'Who acted: a user ID or an admin principal';
+COMMENT ON COLUMN audit_logs.reason IS 'Why, as the actor gave it; required for manual price corrections';
+COMMENT ON COLUMN audit_logs.changes IS 'Fields an update changed, as [{"field", "old", "new"}]';
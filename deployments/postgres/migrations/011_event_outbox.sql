@@ -0,0 +1,26 @@
+-- Event Outbox
+-- Migration: 011_event_outbox.sql
+-- Created: 2026-10-16
+-- Description: Domain events written in the same transaction as the change that raised them, for the relay to publish
+
+-- A row is inserted with its change, so an event exists if and only if the
+-- change was committed. The relay publishes rows in id order and sets
+-- published_at; after too many failed attempts it sets failed_at instead.
+CREATE TABLE event_outbox (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    published_at TIMESTAMPTZ,
+    failed_at TIMESTAMPTZ
+);
+
+-- The relay polls for pending rows; published rows are purged by age.
+CREATE INDEX idx_event_outbox_pending ON event_outbox(id) WHERE published_at IS NULL AND failed_at IS NULL;
+CREATE INDEX idx_event_outbox_published ON event_outbox(published_at) WHERE published_at IS NOT NULL;
+
+COMMENT ON TABLE event_outbox IS 'Domain events awaiting publication, written with the change that raised them';
+COMMENT ON COLUMN event_outbox.attempts IS 'Failed deliveries so far';
+COMMENT ON COLUMN event_outbox.failed_at IS 'When the relay gave up; the row is kept for inspection';
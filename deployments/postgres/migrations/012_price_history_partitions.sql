@@ -0,0 +1,93 @@
+-- Price History Partitions
+-- Migration: 012_price_history_partitions.sql
+-- Created: 2026-10-16
+-- Description: price_history partitioned by month, unless TimescaleDB already chunks it
+
+-- price_history_partition creates the partition for the UTC month holding
+-- ts, moving in the rows the default partition holds for it, and returns
+-- its name, price_history_YYYY_MM. It does nothing if the partition
+-- exists. The API calls it ahead of time for the coming months, and for
+-- any month that rows landed in the default partition for, such as a
+-- backfill of old prices.
+CREATE OR REPLACE FUNCTION price_history_partition(ts TIMESTAMPTZ) RETURNS TEXT
+LANGUAGE plpgsql AS $fn$
+DECLARE
+    -- Months are counted in UTC, whatever the session time zone.
+    month_start TIMESTAMP := date_trunc('month', ts AT TIME ZONE 'UTC');
+    lo TIMESTAMPTZ := month_start AT TIME ZONE 'UTC';
+    hi TIMESTAMPTZ := (month_start + INTERVAL '1 month') AT TIME ZONE 'UTC';
+    part TEXT := 'price_history_' || to_char(month_start, 'YYYY_MM');
+BEGIN
+    -- Two callers creating the same month would race on the name.
+    PERFORM pg_advisory_xact_lock(hashtext('price_history_partition'));
+    IF to_regclass(part) IS NOT NULL THEN
+        RETURN part;
+    END IF;
+    EXECUTE format('CREATE TABLE %I (LIKE price_history INCLUDING DEFAULTS)', part);
+    -- Attaching fails while the default partition holds rows in range.
+    EXECUTE format('WITH moved AS (DELETE FROM price_history_default WHERE recorded_at >= $1 AND recorded_at < $2 RETURNING *)
+        INSERT INTO %I SELECT * FROM moved', part) USING lo, hi;
+    EXECUTE format('ALTER TABLE price_history ATTACH PARTITION %I FOR VALUES FROM (%L) TO (%L)', part, lo, hi);
+    RETURN part;
+END
+$fn$;
+
+-- A TimescaleDB hypertable (migration 008) is already chunked by time, and
+-- its chunks are dropped with drop_chunks instead. Otherwise price_history
+-- is rebuilt as a table partitioned by month of recorded_at, with a
+-- default partition so no insert fails for want of a partition. Its
+-- primary key takes recorded_at, as partition keys must be part of every
+-- unique key. Partitions from the oldest row to three months ahead are
+-- created here; the API keeps creating them ahead and drops those past
+-- PRICE_HISTORY_RETENTION_DAYS.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'timescaledb') THEN
+        RAISE NOTICE 'price_history is a TimescaleDB hypertable; not partitioning it';
+        RETURN;
+    END IF;
+
+    CREATE TABLE price_history_new (
+        LIKE price_history INCLUDING DEFAULTS INCLUDING COMMENTS,
+        CONSTRAINT price_history_new_pkey PRIMARY KEY (id, recorded_at)
+    ) PARTITION BY RANGE (recorded_at);
+    CREATE TABLE price_history_default PARTITION OF price_history_new DEFAULT;
+    INSERT INTO price_history_new SELECT * FROM price_history;
+
+    DROP VIEW price_history_daily;
+    DROP TABLE price_history;
+    ALTER TABLE price_history_new RENAME TO price_history;
+    ALTER TABLE price_history RENAME CONSTRAINT price_history_new_pkey TO price_history_pkey;
+    ALTER TABLE price_history ADD CONSTRAINT price_history_product_listing_id_fkey
+        FOREIGN KEY (product_listing_id) REFERENCES product_listings(id) ON DELETE CASCADE;
+
+    -- Indexes on the parent are created on every partition, present and
+    -- future. History is read per listing over a time range.
+    CREATE INDEX idx_price_history_listing ON price_history(product_listing_id, recorded_at);
+    CREATE INDEX idx_price_history_recorded ON price_history(recorded_at);
+    CREATE INDEX idx_price_history_price ON price_history(price);
+
+    PERFORM price_history_partition(m AT TIME ZONE 'UTC')
+    FROM generate_series(
+        (SELECT date_trunc('month', coalesce(min(recorded_at), now()) AT TIME ZONE 'UTC') FROM price_history),
+        now() AT TIME ZONE 'UTC' + INTERVAL '3 months',
+        INTERVAL '1 month'
+    ) AS m;
+
+    -- As in migration 008.
+    CREATE VIEW price_history_daily AS
+    SELECT product_listing_id,
+        date_trunc('day', recorded_at, 'UTC') AS day,
+        min(price) AS min_price,
+        avg(price) AS avg_price,
+        max(price) AS max_price,
+        (array_agg(price ORDER BY recorded_at DESC))[1] AS close_price,
+        bool_or(in_stock) AS in_stock,
+        count(*) AS samples,
+        min(currency) AS currency
+    FROM price_history
+    GROUP BY product_listing_id, date_trunc('day', recorded_at, 'UTC');
+
+    COMMENT ON TABLE price_history IS 'Complete price change history with datetime tracking, partitioned by month';
+END
+$$;
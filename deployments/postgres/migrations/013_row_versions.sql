@@ -0,0 +1,14 @@
+-- Row Versions
+-- Migration: 013_row_versions.sql
+-- Created: 2026-10-16
+-- Description: version on products and product_variants, for optimistic concurrency control of edits
+
+-- A save names the version it read and bumps it in the same statement:
+--   UPDATE products SET ..., version = version + 1 WHERE id = $1 AND version = $2
+-- and touches no row if another save came first, which the application
+-- reports as a conflict instead of overwriting the other edit.
+ALTER TABLE products ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE product_variants ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN products.version IS 'Number of saves; a save must name the current version';
+COMMENT ON COLUMN product_variants.version IS 'Number of saves; a save must name the current version';
@@ -0,0 +1,41 @@
+-- Price Change Log
+-- Migration: 014_price_change_log.sql
+-- Created: 2026-10-16
+-- Description: Append-only log of price change events, read by consumers from the offsets they commit
+
+-- A row is inserted with the price observation that caused it, in the same
+-- transaction, and is never updated or deleted. Unlike price_history it
+-- records changes as the events consumers were told of, so rewinding a
+-- consumer's cursor replays exactly what it saw. Appends take an advisory
+-- lock, so seq order is commit order and a consumer cannot read past an
+-- entry still being written.
+CREATE TABLE price_change_log (
+    seq BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    event_type VARCHAR(100) NOT NULL,
+    product_id VARCHAR(100) NOT NULL,
+    variant_id VARCHAR(100) NOT NULL,
+    listing_id VARCHAR(100) NOT NULL,
+    retailer_id VARCHAR(100) NOT NULL,
+    old_price DECIMAL(10,2),
+    new_price DECIMAL(10,2) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    in_stock BOOLEAN NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Consumers read by seq; a product's history is read by product, and a
+-- replay from a point in time starts from the last entry before it.
+CREATE INDEX idx_price_change_log_product ON price_change_log(product_id, seq);
+CREATE INDEX idx_price_change_log_recorded ON price_change_log(recorded_at);
+
+-- One row per consumer: it has handled every entry up to position.
+CREATE TABLE price_log_cursors (
+    consumer VARCHAR(100) PRIMARY KEY,
+    position BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE price_change_log IS 'Append-only price change events, numbered by seq';
+COMMENT ON COLUMN price_change_log.old_price IS 'Price before the change; NULL for a listing''s first observation';
+COMMENT ON TABLE price_log_cursors IS 'How far each price change log consumer has read; lowered to replay';
@@ -0,0 +1,20 @@
+-- Feature Flags
+-- Migration: 015_feature_flags.sql
+-- Created: 2026-10-16
+-- Description: Flags rolling risky changes out to a share of traffic, turned off without a deploy
+
+-- Instances reload the table when an admin changes a flag and on a timer,
+-- so a change made on one instance reaches the others within a minute.
+CREATE TABLE feature_flags (
+    name VARCHAR(64) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    -- The kill switch: while false the flag is off whatever percent says.
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    percent SMALLINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT chk_feature_flags_percent CHECK (percent BETWEEN 0 AND 100)
+);
+
+COMMENT ON TABLE feature_flags IS 'Feature flags, managed through the admin API; they override FEATURE_FLAGS defaults';
+COMMENT ON COLUMN feature_flags.percent IS 'Share of subjects the flag is on for, bucketed by a hash of flag name and subject';
@@ -0,0 +1,55 @@
+-- Relational Store
+-- Migration: 016_relational_store.sql
+-- Created: 2026-10-16
+-- Description: Columns and tables the API needs to serve the catalog, accounts and alerts from the database
+
+-- The struck-through price a retailer shows beside the current one.
+ALTER TABLE product_listings ADD COLUMN original_price DECIMAL(10,2);
+
+-- Passwords are stored as encoded argon2id hashes, which carry their own
+-- salt and parameters.
+ALTER TABLE user_passwords ALTER COLUMN salt DROP NOT NULL;
+
+-- A session is one cookie token; there is no refresh token.
+ALTER TABLE user_sessions ALTER COLUMN refresh_token_hash DROP NOT NULL;
+
+-- Personal access tokens are shown once and kept only as their hash, with
+-- the prefix users tell them apart by and the scopes they grant.
+ALTER TABLE api_keys ALTER COLUMN key_encrypted DROP NOT NULL;
+ALTER TABLE api_keys ADD COLUMN prefix VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE api_keys ADD COLUMN scopes JSONB NOT NULL DEFAULT '[]';
+
+-- The address a verification token was sent to is sealed like
+-- users.email_encrypted. Pending tokens, which expire within a day, are
+-- dropped rather than sealed; their users can ask for another.
+DELETE FROM email_verification_tokens;
+ALTER TABLE email_verification_tokens ALTER COLUMN email_to_verify TYPE BYTEA USING convert_to(email_to_verify, 'UTF8');
+CREATE UNIQUE INDEX idx_email_verification_token_unique ON email_verification_tokens(token_hash);
+
+-- An alert fires on one of several conditions, each with its own
+-- parameter; only the condition's parameter is set. Alerts set without
+-- signing in stay pending until confirmed.
+ALTER TABLE price_alerts ALTER COLUMN target_price DROP NOT NULL;
+ALTER TABLE price_alerts ADD COLUMN condition VARCHAR(32) NOT NULL DEFAULT 'price';
+ALTER TABLE price_alerts ADD COLUMN target_price_per_gram DECIMAL(10,4);
+ALTER TABLE price_alerts ADD COLUMN drop_percent DECIMAL(5,2);
+ALTER TABLE price_alerts ADD COLUMN base_price DECIMAL(10,2);
+ALTER TABLE price_alerts ADD COLUMN low_days INTEGER;
+ALTER TABLE price_alerts ADD COLUMN pending BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- A user has at most one live alert per product.
+CREATE UNIQUE INDEX idx_alerts_user_product_live ON price_alerts(user_id, product_id) WHERE deleted_at IS NULL;
+
+-- The CSS selectors the scraper reads each retailer's pages with, as the
+-- JSON the admin API takes.
+CREATE TABLE retailer_selectors (
+    retailer_id UUID PRIMARY KEY REFERENCES retailers(id) ON DELETE CASCADE,
+    config JSONB NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_by VARCHAR(255)
+);
+
+COMMENT ON COLUMN product_listings.original_price IS 'Price before discount as the retailer shows it; NULL when there is none';
+COMMENT ON COLUMN api_keys.scopes IS 'JSON array of the scopes the token grants';
+COMMENT ON COLUMN price_alerts.condition IS 'price, price_per_gram_protein, percent_drop or low; only its parameter is set';
+COMMENT ON TABLE retailer_selectors IS 'Scraper selector configs by retailer, managed through the admin API';
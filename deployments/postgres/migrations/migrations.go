@@ -0,0 +1,26 @@
+// Package migrations embeds the PostgreSQL schema migrations, so the
+// binaries that apply and check them carry their own copy.
+package migrations
+
+import "embed"
+
+// FS holds the NNN_description.sql files, for database.LoadMigrations.
+//
+//go:embed *.sql
+var FS embed.FS
+
+// RequiredIndexes are the indexes the hot queries rely on; the API refuses
+// to start on a database without them. A query missing its index still
+// answers, by reading the whole table, so nothing else would notice.
+var RequiredIndexes = []string{
+	"idx_products_search_vector",
+	"idx_products_search_text_trgm",
+	"idx_listings_variant",
+	"idx_listings_retailer",
+	"idx_price_history_listing",
+	"idx_price_history_recorded",
+	"idx_event_outbox_pending",
+	"idx_price_change_log_product",
+	"idx_audit_logs_actor",
+	"idx_search_logs_created_at",
+}
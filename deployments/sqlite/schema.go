@@ -0,0 +1,10 @@
+// Package sqlite embeds the SQLite development schema, so tests and tools
+// can create a database without the sqlite3 shell.
+package sqlite
+
+import _ "embed"
+
+// Schema is schema.sql, statements separated by semicolons.
+//
+//go:embed schema.sql
+var Schema string
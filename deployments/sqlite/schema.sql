@@ -26,6 +26,56 @@ CREATE TABLE users (
     updated_by TEXT
 );
 
+-- Accounts' passwords, sign-in providers, sessions, API tokens and pending
+-- email verifications (migrations 001 and 016)
+CREATE TABLE user_passwords (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    user_id TEXT NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    password_hash TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    last_changed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE user_oauth_providers (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider TEXT NOT NULL,
+    provider_user_id TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, provider_user_id)
+);
+
+CREATE TABLE user_sessions (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    session_token_hash TEXT UNIQUE NOT NULL,
+    user_agent TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    expires_at DATETIME NOT NULL
+);
+
+CREATE TABLE api_keys (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    key_hash TEXT UNIQUE NOT NULL,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL DEFAULT '',
+    scopes TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(scopes)),
+    last_used_at DATETIME,
+    is_active INTEGER DEFAULT 1,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    expires_at DATETIME
+);
+
+CREATE TABLE email_verification_tokens (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT UNIQUE NOT NULL,
+    email_to_verify BLOB NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
 -- Brands table
 CREATE TABLE brands (
     id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
@@ -70,7 +120,9 @@ CREATE TABLE products (
     manufacturer TEXT,
     is_active INTEGER DEFAULT 1,
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME,
+    version INTEGER NOT NULL DEFAULT 1
 );
 
 -- Product variants table
@@ -83,7 +135,9 @@ CREATE TABLE product_variants (
     sku TEXT,
     is_active INTEGER DEFAULT 1,
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME,
+    version INTEGER NOT NULL DEFAULT 1
 );
 
 -- Retailers table
@@ -117,6 +171,7 @@ CREATE TABLE product_listings (
     retailer_url TEXT NOT NULL,
     retailer_sku TEXT,
     current_price REAL,
+    original_price REAL,
     currency TEXT DEFAULT 'INR',
     is_available INTEGER DEFAULT 1,
     stock_status TEXT,
@@ -131,6 +186,14 @@ CREATE TABLE product_listings (
     updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Scraper selector configs by retailer, as JSON (migration 016)
+CREATE TABLE retailer_selectors (
+    retailer_id TEXT PRIMARY KEY REFERENCES retailers(id) ON DELETE CASCADE,
+    config TEXT NOT NULL CHECK (json_valid(config)),
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by TEXT
+);
+
 -- Price history table
 CREATE TABLE price_history (
     id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
@@ -143,9 +206,26 @@ CREATE TABLE price_history (
     recorded_at DATETIME DEFAULT CURRENT_TIMESTAMP,
     effective_from DATETIME DEFAULT CURRENT_TIMESTAMP,
     source TEXT DEFAULT 'scraper',
-    confidence_score REAL DEFAULT 1.0
+    confidence_score REAL DEFAULT 1.0,
+    in_stock INTEGER NOT NULL DEFAULT 1
 );
 
+-- Prices rolled up by listing and UTC day (migration 008)
+CREATE VIEW price_history_daily AS
+SELECT h.product_listing_id,
+    date(h.recorded_at) AS day,
+    min(h.price) AS min_price,
+    avg(h.price) AS avg_price,
+    max(h.price) AS max_price,
+    (SELECT c.price FROM price_history c
+     WHERE c.product_listing_id = h.product_listing_id AND date(c.recorded_at) = date(h.recorded_at)
+     ORDER BY c.recorded_at DESC LIMIT 1) AS close_price,
+    max(h.in_stock) AS in_stock,
+    count(*) AS samples,
+    min(h.currency) AS currency
+FROM price_history h
+GROUP BY h.product_listing_id, date(h.recorded_at);
+
 -- User-driven product discovery queue
 CREATE TABLE product_discovery_queue (
     id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
@@ -189,7 +269,13 @@ CREATE TABLE price_alerts (
     id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
     user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     product_id TEXT NOT NULL,
-    target_price REAL NOT NULL,
+    condition TEXT NOT NULL DEFAULT 'price',
+    target_price REAL,
+    target_price_per_gram REAL,
+    drop_percent REAL,
+    base_price REAL,
+    low_days INTEGER,
+    pending INTEGER NOT NULL DEFAULT 0,
     retailer_ids TEXT, -- JSON array as TEXT
     notification_methods TEXT DEFAULT '["email"]', -- JSON array
     notification_frequency TEXT DEFAULT 'immediate',
@@ -197,7 +283,8 @@ CREATE TABLE price_alerts (
     last_triggered_at DATETIME,
     trigger_count INTEGER DEFAULT 0,
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME
 );
 
 -- User favorites table
@@ -251,6 +338,94 @@ CREATE TABLE price_validation_config (
     updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Search synonym groups, terms as a JSON array (migration 005)
+CREATE TABLE search_synonyms (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    terms TEXT NOT NULL CHECK (json_valid(terms) AND json_array_length(terms) >= 2),
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Anonymized searches and result clicks (migration 006)
+CREATE TABLE search_logs (
+    id TEXT PRIMARY KEY,
+    query TEXT NOT NULL,
+    page INTEGER NOT NULL DEFAULT 1,
+    results INTEGER NOT NULL,
+    fuzzy INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL
+);
+
+CREATE TABLE search_clicks (
+    search_id TEXT NOT NULL,
+    product_id TEXT NOT NULL,
+    position INTEGER NOT NULL CHECK (position > 0),
+    created_at DATETIME NOT NULL
+);
+
+-- Admin and price-correction audit trail (migrations 001 and 010)
+CREATE TABLE audit_logs (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    actor_id TEXT,
+    action TEXT NOT NULL,
+    resource_type TEXT,
+    resource_id TEXT,
+    reason TEXT,
+    changes TEXT CHECK (changes IS NULL OR json_valid(changes)),
+    ip_address TEXT,
+    user_agent TEXT,
+    http_method TEXT,
+    endpoint TEXT,
+    request_id TEXT,
+    success INTEGER NOT NULL,
+    error_message TEXT,
+    metadata TEXT CHECK (metadata IS NULL OR json_valid(metadata)),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Domain events awaiting publication (migration 011)
+CREATE TABLE event_outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_type TEXT NOT NULL,
+    payload TEXT NOT NULL CHECK (json_valid(payload)),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    published_at DATETIME,
+    failed_at DATETIME
+);
+
+-- Append-only price change events and their consumers' cursors (migration 014)
+CREATE TABLE price_change_log (
+    seq INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_type TEXT NOT NULL,
+    product_id TEXT NOT NULL,
+    variant_id TEXT NOT NULL,
+    listing_id TEXT NOT NULL,
+    retailer_id TEXT NOT NULL,
+    old_price DECIMAL(10,2),
+    new_price DECIMAL(10,2) NOT NULL,
+    currency TEXT NOT NULL,
+    in_stock BOOLEAN NOT NULL,
+    occurred_at DATETIME NOT NULL,
+    recorded_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE price_log_cursors (
+    consumer TEXT PRIMARY KEY,
+    position INTEGER NOT NULL DEFAULT 0,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Feature flags, managed through the admin API (migration 015)
+CREATE TABLE feature_flags (
+    name TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    enabled INTEGER NOT NULL DEFAULT 0,
+    percent INTEGER NOT NULL DEFAULT 0 CHECK (percent BETWEEN 0 AND 100),
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
 -- Create indexes for performance
 CREATE INDEX idx_brands_slug ON brands(slug);
 CREATE INDEX idx_categories_parent ON categories(parent_id);
@@ -260,6 +435,16 @@ CREATE INDEX idx_products_category ON products(category_id);
 CREATE INDEX idx_products_slug ON products(slug);
 CREATE INDEX idx_variants_product ON product_variants(product_id);
 CREATE INDEX idx_retailers_slug ON retailers(slug);
+CREATE INDEX idx_search_logs_created_at ON search_logs(created_at);
+CREATE INDEX idx_search_clicks_created_at ON search_clicks(created_at);
+CREATE INDEX idx_search_clicks_search_id ON search_clicks(search_id);
+CREATE INDEX idx_audit_logs_actor ON audit_logs(actor_id, created_at);
+CREATE INDEX idx_audit_logs_action ON audit_logs(action, created_at);
+CREATE INDEX idx_audit_logs_resource ON audit_logs(resource_type, resource_id);
+CREATE INDEX idx_event_outbox_pending ON event_outbox(id) WHERE published_at IS NULL AND failed_at IS NULL;
+CREATE INDEX idx_event_outbox_published ON event_outbox(published_at);
+CREATE INDEX idx_price_change_log_product ON price_change_log(product_id, seq);
+CREATE INDEX idx_price_change_log_recorded ON price_change_log(recorded_at);
 CREATE INDEX idx_listings_variant ON product_listings(product_variant_id);
 CREATE INDEX idx_listings_retailer ON product_listings(retailer_id);
 CREATE INDEX idx_listings_price ON product_listings(current_price);
@@ -269,6 +454,12 @@ CREATE INDEX idx_discovery_queue_status ON product_discovery_queue(status);
 CREATE INDEX idx_scraping_queue_status ON scraping_queue(status);
 CREATE INDEX idx_scraping_queue_scheduled ON scraping_queue(scheduled_for);
 CREATE INDEX idx_alert_thresholds_user ON user_alert_thresholds(user_id);
+CREATE INDEX idx_oauth_user_id ON user_oauth_providers(user_id);
+CREATE INDEX idx_user_sessions_user_id ON user_sessions(user_id);
+CREATE INDEX idx_api_keys_user_id ON api_keys(user_id);
+CREATE INDEX idx_alerts_user_id ON price_alerts(user_id);
+CREATE INDEX idx_alerts_product_id ON price_alerts(product_id);
+CREATE UNIQUE INDEX idx_alerts_user_product_live ON price_alerts(user_id, product_id) WHERE deleted_at IS NULL;
 
 -- Insert initial test data
 INSERT INTO brands (name, slug, country_origin, is_active) VALUES
@@ -0,0 +1,318 @@
+// Package alerts lets users subscribe to price conditions on products and
+// turns price events that meet a condition into queued notifications.
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/events"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/services"
+)
+
+// ErrEmailUnverified is returned when a user who can only be reached by
+// email creates an alert before proving their address.
+var ErrEmailUnverified = errors.New("verify your email address or turn on notifications before creating alerts")
+
+// ReachableFunc reports whether a user can receive alerts through a channel
+// other than email, such as a browser push subscription.
+type ReachableFunc func(ctx context.Context, userID string) (bool, error)
+
+// DefaultMaxPerUser bounds how many alerts one user may hold.
+const DefaultMaxPerUser = 50
+
+// Repos groups the repositories the Service reads and writes.
+type Repos struct {
+	Alerts        repositories.AlertRepository
+	Notifications repositories.NotificationQueue
+	// Searches is only needed for saved searches.
+	Searches repositories.SavedSearchRepository
+}
+
+// Service manages alerts and evaluates them as prices change.
+type Service struct {
+	repos      Repos
+	prices     *services.PriceService
+	maxPerUser int
+	reachable  ReachableFunc
+	guests     *guests
+	logger     *zap.Logger
+	now        func() time.Time
+
+	// evalMu serialises evaluation, so two events for one product cannot
+	// both fire the same alert.
+	evalMu sync.Mutex
+}
+
+// NewService creates a Service reading current offers from prices.
+func NewService(repos Repos, prices *services.PriceService, logger *zap.Logger) *Service {
+	return &Service{repos: repos, prices: prices, maxPerUser: DefaultMaxPerUser, logger: logger, now: time.Now}
+}
+
+// WithMaxPerUser overrides DefaultMaxPerUser. It returns s.
+func (s *Service) WithMaxPerUser(n int) *Service {
+	s.maxPerUser = n
+	return s
+}
+
+// WithReachable lets users that fn reports reachable create alerts without
+// a verified email address. It returns s.
+func (s *Service) WithReachable(fn ReachableFunc) *Service {
+	s.reachable = fn
+	return s
+}
+
+// Create adds an alert for u on spec.ProductID with spec's condition and
+// its parameter. Without a condition, spec is read as the original form:
+// whichever of TargetPrice and TargetPricePerGram is set.
+func (s *Service) Create(ctx context.Context, u domain.User, spec domain.PriceAlert) (*domain.PriceAlert, error) {
+	if err := s.checkReachable(ctx, u); err != nil {
+		return nil, err
+	}
+	return s.create(ctx, u.ID, spec, false)
+}
+
+// checkReachable returns ErrEmailUnverified unless u can be notified.
+func (s *Service) checkReachable(ctx context.Context, u domain.User) error {
+	if u.EmailVerified {
+		return nil
+	}
+	ok := false
+	if s.reachable != nil {
+		var err error
+		if ok, err = s.reachable(ctx, u.ID); err != nil {
+			return fmt.Errorf("check reachability: %w", err)
+		}
+	}
+	if !ok {
+		return ErrEmailUnverified
+	}
+	return nil
+}
+
+// create validates spec and stores it as an alert for userID.
+func (s *Service) create(ctx context.Context, userID string, spec domain.PriceAlert, pending bool) (*domain.PriceAlert, error) {
+	a := domain.PriceAlert{
+		UserID:             userID,
+		ProductID:          spec.ProductID,
+		Condition:          spec.Condition,
+		TargetPrice:        domain.Round2(spec.TargetPrice),
+		TargetPricePerGram: spec.TargetPricePerGram,
+		DropPercent:        spec.DropPercent,
+		LowDays:            spec.LowDays,
+		Pending:            pending,
+		CreatedAt:          s.now().UTC(),
+	}
+	if a.Condition == "" {
+		a.Condition = domain.ConditionPrice
+		if a.TargetPrice == 0 && a.TargetPricePerGram > 0 {
+			a.Condition = domain.ConditionPricePerGram
+		}
+	}
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	// Also rejects unknown and inactive products.
+	c, err := s.prices.Compare(ctx, a.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if a.Condition == domain.ConditionPercentDrop {
+		if len(c.Prices) == 0 || !c.Prices[0].InStock {
+			return nil, fmt.Errorf("a percentage drop needs a current price, and the product is out of stock: %w", domain.ErrInvalid)
+		}
+		a.BasePrice = c.Prices[0].Price
+	}
+	existing, err := s.repos.Alerts.UserAlerts(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("load alerts: %w", err)
+	}
+	if len(existing) >= s.maxPerUser {
+		return nil, fmt.Errorf("you can have at most %d alerts: %w", s.maxPerUser, domain.ErrConflict)
+	}
+	a, err = s.repos.Alerts.CreateAlert(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("Price alert created",
+		zap.String("operation", "CreateAlert"),
+		zap.String("user_id", userID),
+		zap.String("alert_id", a.ID),
+		zap.String("product_id", a.ProductID),
+		zap.String("condition", a.Condition),
+		zap.Bool("pending", pending),
+	)
+	return &a, nil
+}
+
+// List returns userID's alerts, newest first.
+func (s *Service) List(ctx context.Context, userID string) ([]domain.PriceAlert, error) {
+	alerts, err := s.repos.Alerts.UserAlerts(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("load alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+// Delete removes one of userID's alerts. Other users' alerts are reported
+// as not found, so IDs cannot be probed.
+func (s *Service) Delete(ctx context.Context, userID, alertID string) error {
+	a, err := s.repos.Alerts.Alert(ctx, alertID)
+	if err != nil {
+		return err
+	}
+	if a.UserID != userID {
+		return fmt.Errorf("alert %q: %w", alertID, domain.ErrNotFound)
+	}
+	return s.repos.Alerts.DeleteAlert(ctx, alertID, s.now().UTC())
+}
+
+// EventTypes lists the events Handle understands, for subscribing. Price
+// rises matter too: they re-arm alerts that have fired.
+func (s *Service) EventTypes() []string {
+	return []string{domain.EventPriceDropped, domain.EventPriceChanged}
+}
+
+// Handle evaluates the alerts on the product e concerns, and the saved
+// searches, against its current in-stock offers, queueing a notification
+// for each alert whose condition is met and each search it newly matches.
+// It must be subscribed after the cache invalidator, so it reads the new
+// prices.
+func (s *Service) Handle(ctx context.Context, e domain.Event) error {
+	var change domain.PriceChange
+	switch ev := e.(type) {
+	case domain.PriceDropped:
+		change = ev.PriceChange
+	case domain.PriceChanged:
+		change = ev.PriceChange
+	default:
+		return nil
+	}
+	productID := change.ProductID
+
+	s.evalMu.Lock()
+	defer s.evalMu.Unlock()
+
+	alerts, err := s.repos.Alerts.ProductAlerts(ctx, productID)
+	if err != nil {
+		return err
+	}
+	var searches []domain.SavedSearch
+	if s.repos.Searches != nil {
+		if searches, err = s.repos.Searches.AllSavedSearches(ctx); err != nil {
+			return fmt.Errorf("load saved searches: %w", err)
+		}
+	}
+	if len(alerts) == 0 && len(searches) == 0 {
+		return nil
+	}
+	c, err := s.prices.Compare(ctx, productID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("compare %s: %w", productID, err)
+	}
+
+	now := s.now().UTC()
+	if err := s.evaluateAlerts(ctx, c, change, alerts, now); err != nil {
+		return err
+	}
+	return s.evaluateSearches(ctx, c, change, searches, now)
+}
+
+// evaluateAlerts fires and re-arms alerts on c's product.
+func (s *Service) evaluateAlerts(ctx context.Context, c *domain.Comparison, change domain.PriceChange, alerts []domain.PriceAlert, now time.Time) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+	productID := c.Product.ID
+	rules := NewRules(s.prices, c)
+	var changed []domain.PriceAlert
+	var queued []domain.Notification
+	for _, a := range alerts {
+		if a.Pending {
+			continue
+		}
+		offer, met, err := rules.Match(ctx, a)
+		if err != nil {
+			return fmt.Errorf("evaluate alert %s: %w", a.ID, err)
+		}
+		switch {
+		case met && a.TriggeredAt == nil:
+			a.TriggeredAt = &now
+			queued = append(queued, domain.Notification{
+				Type:          domain.NotificationPriceAlert,
+				UserID:        a.UserID,
+				AlertID:       a.ID,
+				ProductID:     productID,
+				ProductName:   c.Product.Name,
+				ImageURL:      c.Product.ImageURL,
+				RetailerID:    offer.RetailerID,
+				RetailerName:  offer.RetailerName,
+				Price:         offer.Price,
+				PreviousPrice: previousPrice(change, offer),
+				PricePerGram:  offer.PricePerGramProtein,
+				URL:           offer.BuyURL,
+				CreatedAt:     now,
+				DedupeKey:     dedupeKey(ctx, "alert", a.ID),
+			})
+		case !met && a.TriggeredAt != nil:
+			a.TriggeredAt = nil
+		default:
+			continue
+		}
+		changed = append(changed, a)
+	}
+
+	// Notifications are queued before alerts are marked, so a failure
+	// leaves the alerts armed for the next event rather than silently
+	// fired.
+	if len(queued) > 0 {
+		if err := s.repos.Notifications.Enqueue(ctx, queued...); err != nil {
+			return fmt.Errorf("queue notifications: %w", err)
+		}
+		s.logger.Info("Price alerts fired",
+			zap.String("operation", "EvaluateAlerts"),
+			zap.String("product_id", productID),
+			zap.Int("notifications", len(queued)),
+		)
+	}
+	for _, a := range changed {
+		// Deleted while being evaluated.
+		if err := s.repos.Alerts.SaveAlert(ctx, a); err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("save alert %s: %w", a.ID, err)
+		}
+	}
+	return nil
+}
+
+// dedupeKey identifies a notification for the alert or search id raised
+// by the outbox message or price log entry being delivered, so a
+// redelivery after a crash between queueing the notification and
+// recording that the alert fired, or a replay of the price log, does not
+// queue it twice. Events published directly have no message and get no
+// key.
+func dedupeKey(ctx context.Context, kind, id string) string {
+	msg := events.MessageID(ctx)
+	if msg == "" {
+		return ""
+	}
+	return msg + "/" + kind + "/" + id
+}
+
+// previousPrice returns what o cost before change, when change made it
+// cheaper.
+func previousPrice(change domain.PriceChange, o domain.Offer) float64 {
+	if change.ListingID == o.ListingID && change.OldPrice > o.Price {
+		return change.OldPrice
+	}
+	return 0
+}
@@ -0,0 +1,326 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/events"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+var verified = domain.User{ID: "user_1", Email: "asha@example.com", EmailVerified: true}
+
+func newTestService(t *testing.T, now time.Time) (*Service, *memory.Store) {
+	t.Helper()
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, now)
+	logger := testhelpers.SetupTestLogger(t)
+	prices := services.NewPriceService(services.PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+	svc := NewService(Repos{Alerts: store.Alerts(), Notifications: store.Notifications()}, prices, logger)
+	svc.now = func() time.Time { return now }
+	return svc, store
+}
+
+// setPrice records a new Flipkart price for the fixture product and returns
+// the event the scraper would publish.
+func setPrice(store *memory.Store, now time.Time, price float64) domain.Event {
+	store.AddPricePoint(domain.PricePoint{
+		ListingID:  testhelpers.FixtureListingFlipkart,
+		Price:      price,
+		Currency:   domain.DefaultCurrency,
+		InStock:    true,
+		RecordedAt: now,
+		Source:     "scraper",
+	})
+	return domain.PriceChanged{PriceChange: domain.PriceChange{ProductID: testhelpers.FixtureProductID, NewPrice: price}}
+}
+
+func TestService_CreateValidation(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_CreateValidation", "internal/alerts")
+
+	svc, _ := newTestService(t, time.Now())
+	svc.WithMaxPerUser(3).WithReachable(func(_ context.Context, userID string) (bool, error) {
+		return userID == "user_5", nil
+	})
+
+	gsw := func(a domain.PriceAlert) domain.PriceAlert {
+		a.ProductID = testhelpers.FixtureProductID
+		return a
+	}
+	testCases := []struct {
+		name    string
+		user    domain.User
+		spec    domain.PriceAlert
+		wantErr error
+	}{
+		{"Price target", verified, gsw(domain.PriceAlert{TargetPrice: 3000}), nil},
+		{"Per-gram target", verified, domain.PriceAlert{ProductID: testhelpers.FixtureSecondProductID, TargetPricePerGram: 1.5}, nil},
+		{"Unverified email", domain.User{ID: "user_2"}, gsw(domain.PriceAlert{TargetPrice: 3000}), ErrEmailUnverified},
+		{"Unverified but reachable by push", domain.User{ID: "user_5"}, gsw(domain.PriceAlert{TargetPrice: 3000}), nil},
+		{"No target", domain.User{ID: "user_3", EmailVerified: true}, gsw(domain.PriceAlert{}), domain.ErrInvalid},
+		{"Both targets", domain.User{ID: "user_3", EmailVerified: true}, gsw(domain.PriceAlert{TargetPrice: 3000, TargetPricePerGram: 1.5}), domain.ErrInvalid},
+		{"Negative target", domain.User{ID: "user_3", EmailVerified: true}, gsw(domain.PriceAlert{TargetPrice: -1, TargetPricePerGram: 1.5}), domain.ErrInvalid},
+		{"Unknown condition", domain.User{ID: "user_3", EmailVerified: true}, gsw(domain.PriceAlert{Condition: "rising"}), domain.ErrInvalid},
+		{"Drop too large", domain.User{ID: "user_3", EmailVerified: true}, gsw(domain.PriceAlert{Condition: domain.ConditionPercentDrop, DropPercent: 100}), domain.ErrInvalid},
+		{"Unknown product", domain.User{ID: "user_3", EmailVerified: true}, domain.PriceAlert{ProductID: "prod_missing", TargetPrice: 3000}, domain.ErrNotFound},
+		{"Same product again", verified, gsw(domain.PriceAlert{TargetPrice: 2900}), domain.ErrConflict},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := svc.Create(t.Context(), tc.user, tc.spec)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if tc.wantErr == nil && err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Errorf("Create error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "act", "A second alert for a user at the limit of one")
+	svc.WithMaxPerUser(1)
+	full := domain.User{ID: "user_4", EmailVerified: true}
+	if _, err := svc.Create(t.Context(), full, domain.PriceAlert{ProductID: testhelpers.FixtureProductID, TargetPrice: 3000}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	_, err := svc.Create(t.Context(), full, domain.PriceAlert{ProductID: testhelpers.FixtureSecondProductID, TargetPrice: 2000})
+	testhelpers.LogTestAssertion(logger, "over the limit", domain.ErrConflict, err)
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Create over the limit = %v, want ErrConflict", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_CreateValidation", true)
+}
+
+func TestService_HandleFiresOnceAndRearms(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_HandleFiresOnceAndRearms", "internal/alerts")
+
+	testhelpers.LogTestStep(logger, "arrange", "An alert below the current best price of ₹3,199")
+	now := time.Now()
+	svc, store := newTestService(t, now)
+	ctx := t.Context()
+	a, err := svc.Create(ctx, verified, domain.PriceAlert{ProductID: testhelpers.FixtureProductID, TargetPrice: 3000})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	pending := func() []domain.Notification {
+		t.Helper()
+		n, err := store.Notifications().Pending(ctx, 0)
+		if err != nil {
+			t.Fatalf("Pending: %v", err)
+		}
+		return n
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Prices move: 3,099, 2,999, 2,949, 3,199, 2,899")
+	steps := []struct {
+		price     float64
+		wantQueue int
+	}{
+		{3099, 0}, // still above target
+		{2999, 1}, // fires
+		{2949, 1}, // already fired
+		{3199, 1}, // re-arms
+		{2899, 2}, // fires again
+	}
+	for _, step := range steps {
+		if err := svc.Handle(ctx, setPrice(store, now, step.price)); err != nil {
+			t.Fatalf("Handle(%v): %v", step.price, err)
+		}
+		testhelpers.LogTestAssertion(logger, "queued notifications", step.wantQueue, len(pending()))
+		if got := len(pending()); got != step.wantQueue {
+			t.Fatalf("After ₹%v: %d notifications queued, want %d", step.price, got, step.wantQueue)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The notification describes the offer")
+	n := pending()[1]
+	if n.UserID != verified.ID || n.AlertID != a.ID || n.RetailerID != "flipkart" || n.Price != 2899 {
+		t.Errorf("Notification = %+v, want Flipkart at ₹2,899 for %s", n, a.ID)
+	}
+	if n.ProductName != "Gold Standard 100% Whey" || n.URL == "" {
+		t.Errorf("Notification product = %q, URL = %q", n.ProductName, n.URL)
+	}
+	got, err := store.Alerts().Alert(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("Alert: %v", err)
+	}
+	if got.TriggeredAt == nil {
+		t.Error("Alert not marked triggered")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_HandleFiresOnceAndRearms", true)
+}
+
+func TestService_HandleRedeliveryQueuesOnce(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_HandleRedeliveryQueuesOnce", "internal/alerts")
+
+	testhelpers.LogTestStep(logger, "arrange", "An alert that the next drop fires")
+	now := time.Now()
+	svc, store := newTestService(t, now)
+	a, err := svc.Create(t.Context(), verified, domain.PriceAlert{ProductID: testhelpers.FixtureProductID, TargetPrice: 3000})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	ctx := events.WithMessageID(t.Context(), "outbox_7")
+
+	testhelpers.LogTestStep(logger, "act", "Delivering the drop, losing the fired mark as a crash would, and redelivering")
+	drop := setPrice(store, now, 2999)
+	if err := svc.Handle(ctx, drop); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	fired, err := store.Alerts().Alert(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("Alert: %v", err)
+	}
+	fired.TriggeredAt = nil
+	if err := store.Alerts().SaveAlert(ctx, *fired); err != nil {
+		t.Fatalf("SaveAlert: %v", err)
+	}
+	if err := svc.Handle(ctx, drop); err != nil {
+		t.Fatalf("Handle again: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "One notification is queued and the alert is fired")
+	queued, err := store.Notifications().Pending(ctx, 0)
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	testhelpers.LogTestAssertion(logger, "queued notifications", 1, len(queued))
+	if len(queued) != 1 || queued[0].DedupeKey != "outbox_7/alert/"+a.ID {
+		t.Errorf("Queued = %+v, want one keyed by the message", queued)
+	}
+	if got, _ := store.Alerts().Alert(ctx, a.ID); got == nil || got.TriggeredAt == nil {
+		t.Error("Alert not marked triggered after redelivery")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_HandleRedeliveryQueuesOnce", true)
+}
+
+func TestService_HandlePerGramTarget(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_HandlePerGramTarget", "internal/alerts")
+
+	// 2,270 g at 24 g protein per 30.4 g serving is about 1,792 g of
+	// protein: ₹3,199 is ₹1.78/g and ₹2,999 is ₹1.67/g.
+	now := time.Now()
+	svc, store := newTestService(t, now)
+	ctx := t.Context()
+	if _, err := svc.Create(ctx, verified, domain.PriceAlert{ProductID: testhelpers.FixtureProductID, TargetPricePerGram: 1.7}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := svc.Handle(ctx, setPrice(store, now, 3149)); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if n, _ := store.Notifications().Pending(ctx, 0); len(n) != 0 {
+		t.Fatalf("Fired at ₹3,149: %+v", n)
+	}
+	if err := svc.Handle(ctx, setPrice(store, now, 2999)); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	n, _ := store.Notifications().Pending(ctx, 0)
+	testhelpers.LogTestAssertion(logger, "queued notifications", 1, len(n))
+	if len(n) != 1 || n[0].PricePerGram > 1.7 {
+		t.Errorf("Notifications = %+v, want one at or below ₹1.70/g", n)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_HandlePerGramTarget", true)
+}
+
+func TestService_HandleConditions(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_HandleConditions", "internal/alerts")
+
+	// The fixture's lowest in-stock price over the last week is Flipkart's
+	// ₹3,199, which is also the current best.
+	testCases := []struct {
+		name      string
+		spec      domain.PriceAlert
+		prices    []float64
+		wantFired []bool // after each price
+	}{
+		{
+			name:      "10% below ₹3,199",
+			spec:      domain.PriceAlert{Condition: domain.ConditionPercentDrop, DropPercent: 10},
+			prices:    []float64{2900, 2879, 3199, 2800},
+			wantFired: []bool{false, true, false, true},
+		},
+		{
+			name:      "Below the 7 day low",
+			spec:      domain.PriceAlert{Condition: domain.ConditionLow, LowDays: 7},
+			prices:    []float64{3199, 3150, 3100, 3300, 3000},
+			wantFired: []bool{false, true, true, false, true},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Now()
+			svc, store := newTestService(t, now)
+			ctx := t.Context()
+			tc.spec.ProductID = testhelpers.FixtureProductID
+			a, err := svc.Create(ctx, verified, tc.spec)
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if tc.spec.Condition == domain.ConditionPercentDrop && a.BasePrice != 3199 {
+				t.Errorf("BasePrice = %v, want the current ₹3,199", a.BasePrice)
+			}
+			for i, price := range tc.prices {
+				if err := svc.Handle(ctx, setPrice(store, now, price)); err != nil {
+					t.Fatalf("Handle(%v): %v", price, err)
+				}
+				got, _ := store.Alerts().Alert(ctx, a.ID)
+				fired := got.TriggeredAt != nil
+				testhelpers.LogTestAssertion(logger, tc.name, tc.wantFired[i], fired)
+				if fired != tc.wantFired[i] {
+					t.Errorf("After ₹%v: fired = %v, want %v", price, fired, tc.wantFired[i])
+				}
+			}
+			if n, _ := store.Notifications().Pending(ctx, 0); len(n) != 2 {
+				t.Errorf("Queued %d notifications, want 2", len(n))
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_HandleConditions", true)
+}
+
+func TestService_DeleteOwnership(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_DeleteOwnership", "internal/alerts")
+
+	svc, _ := newTestService(t, time.Now())
+	ctx := t.Context()
+	a, err := svc.Create(ctx, verified, domain.PriceAlert{ProductID: testhelpers.FixtureProductID, TargetPrice: 3000})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	err = svc.Delete(ctx, "user_other", a.ID)
+	testhelpers.LogTestAssertion(logger, "other user's delete", domain.ErrNotFound, err)
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Delete by another user = %v, want ErrNotFound", err)
+	}
+	if err := svc.Delete(ctx, verified.ID, a.ID); err != nil {
+		t.Fatalf("Delete by owner: %v", err)
+	}
+	if list, _ := svc.List(ctx, verified.ID); len(list) != 0 {
+		t.Errorf("Alerts after delete = %+v, want none", list)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_DeleteOwnership", true)
+}
@@ -0,0 +1,342 @@
+package alerts
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// ErrBadManageToken is returned for alert links that were not signed with
+// the current key.
+var ErrBadManageToken = fmt.Errorf("invalid alert link: %w", domain.ErrInvalid)
+
+// ErrGuestsDisabled is returned by CreateGuest without WithGuests.
+var ErrGuestsDisabled = errors.New("alerts without an account are not enabled")
+
+// ConfirmationSender delivers the link that confirms an alert set without
+// signing in.
+type ConfirmationSender interface {
+	SendAlertConfirmation(ctx context.Context, u domain.User, productName, condition, link string) error
+}
+
+// GuestConfig configures alerts set with only an email address.
+type GuestConfig struct {
+	// Key signs the links that confirm and manage guest alerts. Rotating
+	// it breaks the links in emails already sent.
+	Key []byte
+	// BaseURL is the public site root that links point at.
+	BaseURL string
+	// ConfirmTTL is how long an unconfirmed alert waits before it is
+	// discarded.
+	ConfirmTTL time.Duration
+	// MaxPending bounds unconfirmed alerts per address, so the form cannot
+	// be used to flood someone else's inbox.
+	MaxPending int
+	// ResendCooldown is how long after a confirmation is sent to an
+	// address, or for an alert, that asking again sends nothing more.
+	ResendCooldown time.Duration
+}
+
+// DefaultGuestConfig keeps unconfirmed alerts for two days, allows three
+// per address at a time and resends a link at most every ten minutes.
+func DefaultGuestConfig() GuestConfig {
+	return GuestConfig{ConfirmTTL: 48 * time.Hour, MaxPending: 3, ResendCooldown: 10 * time.Minute}
+}
+
+type guests struct {
+	cfg    GuestConfig
+	users  repositories.UserRepository
+	sender ConfirmationSender
+
+	mu sync.Mutex
+	// sent holds when a confirmation last went to "user:<id>" or
+	// "alert:<id>"; entries older than ResendCooldown are dropped.
+	sent map[string]time.Time
+}
+
+// coolingDown reports whether a confirmation went to userID, or for
+// alertID, within the last ResendCooldown.
+func (g *guests) coolingDown(userID, alertID string, now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, key := range []string{"user:" + userID, "alert:" + alertID} {
+		if at, ok := g.sent[key]; ok && now.Sub(at) < g.cfg.ResendCooldown {
+			return true
+		}
+	}
+	return false
+}
+
+// markSent starts the cooldown for userID and alertID.
+func (g *guests) markSent(userID, alertID string, now time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for key, at := range g.sent {
+		if now.Sub(at) >= g.cfg.ResendCooldown {
+			delete(g.sent, key)
+		}
+	}
+	g.sent["user:"+userID] = now
+	g.sent["alert:"+alertID] = now
+}
+
+// WithGuests lets visitors set alerts with only an email address. Each
+// alert stays pending until the visitor opens the signed link emailed to
+// them, which later manages the alert too. It returns s.
+func (s *Service) WithGuests(cfg GuestConfig, users repositories.UserRepository, sender ConfirmationSender) *Service {
+	def := DefaultGuestConfig()
+	if cfg.ConfirmTTL <= 0 {
+		cfg.ConfirmTTL = def.ConfirmTTL
+	}
+	if cfg.MaxPending <= 0 {
+		cfg.MaxPending = def.MaxPending
+	}
+	if cfg.ResendCooldown <= 0 {
+		cfg.ResendCooldown = def.ResendCooldown
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	s.guests = &guests{cfg: cfg, users: users, sender: sender, sent: make(map[string]time.Time)}
+	return s
+}
+
+// GuestsEnabled reports whether WithGuests was called.
+func (s *Service) GuestsEnabled() bool { return s.guests != nil }
+
+// CreateGuest sets a pending alert for the owner of email and sends them
+// the link that confirms it. An address without an account gets one that
+// has no password. Asking again for a product the address already has an
+// alert on resends that alert's link, so the outcome never reveals whether
+// an address is registered. A resend within ResendCooldown of the last
+// confirmation to the address or for the alert returns the same response
+// without sending anything.
+func (s *Service) CreateGuest(ctx context.Context, email string, spec domain.PriceAlert) (*domain.PriceAlert, error) {
+	g := s.guests
+	if g == nil {
+		return nil, ErrGuestsDisabled
+	}
+	email, err := auth.NormalizeEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.guestUser(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.repos.Alerts.UserAlerts(ctx, u.ID)
+	if err != nil {
+		return nil, fmt.Errorf("load alerts: %w", err)
+	}
+	now := s.now().UTC()
+	pending := 0
+	var a *domain.PriceAlert
+	for _, e := range existing {
+		if e.Pending && now.Sub(e.CreatedAt) >= g.cfg.ConfirmTTL {
+			if err := s.repos.Alerts.DeleteAlert(ctx, e.ID, now); err != nil && !errors.Is(err, domain.ErrNotFound) {
+				return nil, fmt.Errorf("discard expired alert: %w", err)
+			}
+			continue
+		}
+		if e.ProductID == spec.ProductID {
+			a = &e
+		}
+		if e.Pending {
+			pending++
+		}
+	}
+	if a == nil {
+		if pending >= g.cfg.MaxPending {
+			return nil, fmt.Errorf("confirm the alerts already emailed to this address first: %w", domain.ErrConflict)
+		}
+		if a, err = s.create(ctx, u.ID, spec, true); err != nil {
+			return nil, err
+		}
+	} else if g.coolingDown(u.ID, a.ID, now) {
+		s.logger.Debug("Guest alert confirmation not resent",
+			zap.String("operation", "CreateGuestAlert"),
+			zap.String("alert_id", a.ID),
+		)
+		return a, nil
+	}
+
+	c, err := s.prices.Compare(ctx, a.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.sender.SendAlertConfirmation(ctx, *u, c.Product.Name, Describe(*a), s.ManageURL(u.ID, a.ID)); err != nil {
+		return nil, fmt.Errorf("send confirmation: %w", err)
+	}
+	g.markSent(u.ID, a.ID, now)
+	return a, nil
+}
+
+// guestUser returns the account for email, creating a passwordless one if
+// there is none.
+func (s *Service) guestUser(ctx context.Context, email string) (*domain.User, error) {
+	users := s.guests.users
+	u, err := users.UserByEmail(ctx, email)
+	if !errors.Is(err, domain.ErrNotFound) {
+		return u, err
+	}
+	created, err := users.CreateUser(ctx, domain.User{Email: email, CreatedAt: s.now().UTC()})
+	if errors.Is(err, domain.ErrConflict) {
+		// Registered between the lookup and the insert.
+		return users.UserByEmail(ctx, email)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("Guest user created",
+		zap.String("operation", "CreateGuestAlert"),
+		zap.String("user_id", created.ID),
+	)
+	return &created, nil
+}
+
+// ManageURL returns the signed page that confirms and manages one of
+// userID's alerts, or "" without WithGuests.
+func (s *Service) ManageURL(userID, alertID string) string {
+	if s.guests == nil || len(s.guests.cfg.Key) == 0 {
+		return ""
+	}
+	payload := base64.RawURLEncoding.EncodeToString([]byte(userID + "\x00" + alertID))
+	token := payload + "." + base64.RawURLEncoding.EncodeToString(s.sign(payload))
+	return s.guests.cfg.BaseURL + "/alerts/manage?token=" + url.QueryEscape(token)
+}
+
+// ParseManageToken returns the user and alert token was issued for, or
+// ErrBadManageToken.
+func (s *Service) ParseManageToken(token string) (userID, alertID string, err error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok || s.guests == nil || len(s.guests.cfg.Key) == 0 {
+		return "", "", ErrBadManageToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, s.sign(payload)) {
+		return "", "", ErrBadManageToken
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", "", ErrBadManageToken
+	}
+	userID, alertID, ok = strings.Cut(string(raw), "\x00")
+	if !ok || userID == "" || alertID == "" {
+		return "", "", ErrBadManageToken
+	}
+	return userID, alertID, nil
+}
+
+// ManagedAlert is an alert opened from its signed link, with the product
+// name to show for it.
+type ManagedAlert struct {
+	domain.PriceAlert
+	ProductName string
+}
+
+// Managed returns the alert token was issued for. Deleted alerts are
+// reported as not found.
+func (s *Service) Managed(ctx context.Context, token string) (*ManagedAlert, error) {
+	userID, alertID, err := s.ParseManageToken(token)
+	if err != nil {
+		return nil, err
+	}
+	a, err := s.repos.Alerts.Alert(ctx, alertID)
+	if err != nil {
+		return nil, err
+	}
+	if a.UserID != userID {
+		return nil, fmt.Errorf("alert %q: %w", alertID, domain.ErrNotFound)
+	}
+	m := &ManagedAlert{PriceAlert: *a}
+	// A delisted product's alert can still be deleted.
+	c, err := s.prices.Compare(ctx, a.ProductID)
+	switch {
+	case err == nil:
+		m.ProductName = c.Product.Name
+	case !errors.Is(err, domain.ErrNotFound):
+		return nil, err
+	}
+	return m, nil
+}
+
+// Confirm arms the pending alert token was issued for and marks its
+// owner's address verified, since only its inbox received the link.
+// Confirming twice is harmless; an alert left unconfirmed past ConfirmTTL
+// is discarded instead.
+func (s *Service) Confirm(ctx context.Context, token string) (*ManagedAlert, error) {
+	m, err := s.Managed(ctx, token)
+	if err != nil || !m.Pending {
+		return m, err
+	}
+	if s.now().UTC().Sub(m.CreatedAt) >= s.guests.cfg.ConfirmTTL {
+		if err := s.repos.Alerts.DeleteAlert(ctx, m.ID, s.now().UTC()); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("this link has expired, set the alert again: %w", domain.ErrInvalid)
+	}
+	u, err := s.guests.users.UserByID(ctx, m.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !u.EmailVerified {
+		u.EmailVerified = true
+		if err := s.guests.users.SaveUser(ctx, *u); err != nil {
+			return nil, err
+		}
+	}
+	m.Pending = false
+	if err := s.repos.Alerts.SaveAlert(ctx, m.PriceAlert); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Guest alert confirmed",
+		zap.String("operation", "ConfirmAlert"),
+		zap.String("user_id", m.UserID),
+		zap.String("alert_id", m.ID),
+	)
+	return m, nil
+}
+
+// DeleteManaged removes the alert token was issued for.
+func (s *Service) DeleteManaged(ctx context.Context, token string) error {
+	a, err := s.Managed(ctx, token)
+	if err != nil {
+		return err
+	}
+	return s.repos.Alerts.DeleteAlert(ctx, a.ID, s.now().UTC())
+}
+
+func (s *Service) sign(payload string) []byte {
+	h := hmac.New(sha256.New, s.guests.cfg.Key)
+	h.Write([]byte("alert\x00" + payload))
+	return h.Sum(nil)
+}
+
+// Describe states a's condition for people, e.g. "drops to ₹2,999 or
+// less".
+func Describe(a domain.PriceAlert) string {
+	price := func(v float64) string { return i18n.Default().FormatPrice(domain.DefaultCurrency, v) }
+	switch a.Condition {
+	case domain.ConditionPricePerGram:
+		return "drops to " + price(a.TargetPricePerGram) + " per gram of protein or less"
+	case domain.ConditionPercentDrop:
+		return "drops " + strconv.FormatFloat(a.DropPercent, 'f', -1, 64) + "% below " + price(a.BasePrice)
+	case domain.ConditionLow:
+		return "falls below its " + strconv.Itoa(a.LowDays) + "-day low"
+	default:
+		return "drops to " + price(a.TargetPrice) + " or less"
+	}
+}
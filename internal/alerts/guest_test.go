@@ -0,0 +1,264 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+type sentConfirmation struct {
+	user        domain.User
+	productName string
+	condition   string
+	link        string
+}
+
+type fakeConfirmations struct {
+	sent []sentConfirmation
+}
+
+func (f *fakeConfirmations) SendAlertConfirmation(_ context.Context, u domain.User, productName, condition, link string) error {
+	f.sent = append(f.sent, sentConfirmation{u, productName, condition, link})
+	return nil
+}
+
+func newGuestService(t *testing.T, now *time.Time) (*Service, *memory.Store, *fakeConfirmations) {
+	t.Helper()
+	svc, store := newTestService(t, *now)
+	svc.now = func() time.Time { return *now }
+	sender := &fakeConfirmations{}
+	svc.WithGuests(GuestConfig{Key: []byte("test-only-secret"), BaseURL: "https://wheyprices.example/"}, store.Users(), sender)
+	return svc, store, sender
+}
+
+func i18nPrice(v float64) string { return i18n.Default().FormatPrice(domain.DefaultCurrency, v) }
+
+// linkToken returns the token in a manage link.
+func linkToken(t *testing.T, link string) string {
+	t.Helper()
+	u, err := url.Parse(link)
+	if err != nil || !strings.HasPrefix(link, "https://wheyprices.example/alerts/manage?") {
+		t.Fatalf("Link = %q", link)
+	}
+	return u.Query().Get("token")
+}
+
+func TestService_GuestAlertLifecycle(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_GuestAlertLifecycle", "internal/alerts")
+
+	now := time.Now()
+	svc, store, sender := newGuestService(t, &now)
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "A visitor sets an alert with only their email")
+	a, err := svc.CreateGuest(ctx, " Asha@Example.com ", domain.PriceAlert{ProductID: testhelpers.FixtureProductID, TargetPrice: 3000})
+	if err != nil {
+		t.Fatalf("CreateGuest: %v", err)
+	}
+	if !a.Pending {
+		t.Error("Guest alert is not pending")
+	}
+	u, err := store.Users().UserByEmail(ctx, "asha@example.com")
+	if err != nil {
+		t.Fatalf("UserByEmail: %v", err)
+	}
+	if u.EmailVerified || u.PasswordHash != "" || a.UserID != u.ID {
+		t.Errorf("Guest user = %+v, alert user = %s", u, a.UserID)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("Sent %d confirmations, want 1", len(sender.sent))
+	}
+	sent := sender.sent[0]
+	if sent.productName != "Gold Standard 100% Whey" || sent.condition != "drops to "+i18nPrice(3000)+" or less" {
+		t.Errorf("Confirmation = %+v", sent)
+	}
+	token := linkToken(t, sent.link)
+
+	testhelpers.LogTestStep(logger, "assert", "Pending alerts do not fire")
+	if err := svc.Handle(ctx, setPrice(store, now, 2899)); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if n, _ := store.Notifications().Pending(ctx, 0); len(n) != 0 {
+		t.Fatalf("Pending alert queued %d notifications", len(n))
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Asking again at once sends nothing more")
+	again, err := svc.CreateGuest(ctx, "asha@example.com", domain.PriceAlert{ProductID: testhelpers.FixtureProductID, TargetPrice: 2500})
+	if err != nil || again.ID != a.ID || len(sender.sent) != 1 {
+		t.Fatalf("Second CreateGuest = %+v, %v; sent %d", again, err, len(sender.sent))
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Asking after the cooldown resends the link rather than adding an alert")
+	now = now.Add(DefaultGuestConfig().ResendCooldown)
+	again, err = svc.CreateGuest(ctx, "asha@example.com", domain.PriceAlert{ProductID: testhelpers.FixtureProductID, TargetPrice: 2500})
+	if err != nil || again.ID != a.ID || len(sender.sent) != 2 || sender.sent[1].link != sent.link {
+		t.Fatalf("Third CreateGuest = %+v, %v; sent %d", again, err, len(sender.sent))
+	}
+
+	testhelpers.LogTestStep(logger, "act", "The link confirms the alert and verifies the address")
+	m, err := svc.Confirm(ctx, token)
+	if err != nil {
+		t.Fatalf("Confirm: %v", err)
+	}
+	testhelpers.LogTestAssertion(logger, "pending after confirm", false, m.Pending)
+	if m.Pending || m.ProductName != "Gold Standard 100% Whey" {
+		t.Errorf("Confirmed alert = %+v", m)
+	}
+	if u, _ := store.Users().UserByID(ctx, a.UserID); !u.EmailVerified {
+		t.Error("Confirming did not verify the address")
+	}
+	if _, err := svc.Confirm(ctx, token); err != nil {
+		t.Errorf("Second Confirm: %v", err)
+	}
+	if err := svc.Handle(ctx, setPrice(store, now, 2799)); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if n, _ := store.Notifications().Pending(ctx, 0); len(n) != 1 {
+		t.Fatalf("Confirmed alert queued %d notifications, want 1", len(n))
+	}
+
+	testhelpers.LogTestStep(logger, "act", "The same link deletes the alert")
+	if err := svc.DeleteManaged(ctx, token); err != nil {
+		t.Fatalf("DeleteManaged: %v", err)
+	}
+	if _, err := svc.Managed(ctx, token); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Managed after delete error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_GuestAlertLifecycle", true)
+}
+
+func TestService_GuestAlertLimits(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_GuestAlertLimits", "internal/alerts")
+
+	now := time.Now()
+	svc, store, sender := newGuestService(t, &now)
+	svc.guests.cfg.MaxPending = 1
+	ctx := t.Context()
+	gsw := domain.PriceAlert{ProductID: testhelpers.FixtureProductID, TargetPrice: 3000}
+	biozyme := domain.PriceAlert{ProductID: testhelpers.FixtureSecondProductID, TargetPrice: 3000}
+
+	testCases := []struct {
+		name    string
+		email   string
+		spec    domain.PriceAlert
+		wantErr error
+	}{
+		{"Invalid email", "not an address", gsw, domain.ErrInvalid},
+		{"Invalid condition", "asha@example.com", domain.PriceAlert{ProductID: testhelpers.FixtureProductID}, domain.ErrInvalid},
+		{"First pending alert", "asha@example.com", gsw, nil},
+		{"Second pending alert over the cap", "asha@example.com", biozyme, domain.ErrConflict},
+		{"Another address", "ravi@example.com", biozyme, nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := svc.CreateGuest(ctx, tc.email, tc.spec)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("CreateGuest error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "act", "After the confirmation window, the stale alert is discarded")
+	token := linkToken(t, sender.sent[0].link)
+	now = now.Add(DefaultGuestConfig().ConfirmTTL)
+	if _, err := svc.Confirm(ctx, token); !errors.Is(err, domain.ErrInvalid) {
+		t.Errorf("Confirm after expiry error = %v, want ErrInvalid", err)
+	}
+	if _, err := svc.CreateGuest(ctx, "asha@example.com", biozyme); err != nil {
+		t.Errorf("CreateGuest after expiry: %v", err)
+	}
+	u, _ := store.Users().UserByEmail(ctx, "asha@example.com")
+	list, _ := svc.List(ctx, u.ID)
+	if len(list) != 1 || list[0].ProductID != testhelpers.FixtureSecondProductID {
+		t.Errorf("Alerts after expiry = %+v", list)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_GuestAlertLimits", true)
+}
+
+func TestService_ManageToken(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_ManageToken", "internal/alerts")
+
+	now := time.Now()
+	svc, _, _ := newGuestService(t, &now)
+	token := linkToken(t, svc.ManageURL("user_1", "alert_1"))
+	other, _, _ := newGuestService(t, &now)
+	other.guests.cfg.Key = []byte("test-only-other-secret")
+	payload, sig, _ := strings.Cut(token, ".")
+	forged := linkToken(t, other.ManageURL("user_2", "alert_1"))
+
+	testCases := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{"Valid", token, nil},
+		{"Empty", "", ErrBadManageToken},
+		{"No signature", payload, ErrBadManageToken},
+		{"Tampered signature", payload + "." + sig[1:], ErrBadManageToken},
+		{"Other key", forged, ErrBadManageToken},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			userID, alertID, err := svc.ParseManageToken(tc.token)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("ParseManageToken error = %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && (userID != "user_1" || alertID != "alert_1") {
+				t.Errorf("ParseManageToken = %q, %q", userID, alertID)
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Without guests there are no links")
+	plain, _ := newTestService(t, now)
+	if got := plain.ManageURL("user_1", "alert_1"); got != "" {
+		t.Errorf("ManageURL without guests = %q", got)
+	}
+	if _, err := plain.CreateGuest(t.Context(), "asha@example.com", domain.PriceAlert{}); !errors.Is(err, ErrGuestsDisabled) {
+		t.Errorf("CreateGuest without guests error = %v", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_ManageToken", true)
+}
+
+func TestDescribe(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestDescribe", "internal/alerts")
+
+	testCases := []struct {
+		name  string
+		alert domain.PriceAlert
+		want  string
+	}{
+		{"Price", domain.PriceAlert{Condition: domain.ConditionPrice, TargetPrice: 2999}, "drops to " + i18nPrice(2999) + " or less"},
+		{"Per gram", domain.PriceAlert{Condition: domain.ConditionPricePerGram, TargetPricePerGram: 1.5}, "drops to " + i18nPrice(1.5) + " per gram of protein or less"},
+		{"Percent drop", domain.PriceAlert{Condition: domain.ConditionPercentDrop, DropPercent: 12.5, BasePrice: 3199}, "drops 12.5% below " + i18nPrice(3199)},
+		{"Low", domain.PriceAlert{Condition: domain.ConditionLow, LowDays: 30}, "falls below its 30-day low"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Describe(tc.alert)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.want, got)
+			if got != tc.want {
+				t.Errorf("Describe = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestDescribe", true)
+}
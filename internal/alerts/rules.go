@@ -0,0 +1,96 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/services"
+)
+
+// Rules evaluates alert conditions against one product's current offers.
+// Conditions that depend on price history load it once per window, so many
+// alerts on a popular product cost one history read.
+type Rules struct {
+	prices     *services.PriceService
+	comparison *domain.Comparison
+	lows       map[int]float64 // by window in days; 0 when nothing was recorded
+}
+
+// NewRules creates Rules for the product c compares.
+func NewRules(prices *services.PriceService, c *domain.Comparison) *Rules {
+	return &Rules{prices: prices, comparison: c, lows: map[int]float64{}}
+}
+
+// Threshold reduces a's condition to a domain.Threshold. ok is false when
+// the condition cannot be met yet, such as a low with no earlier prices.
+func (r *Rules) Threshold(ctx context.Context, a domain.PriceAlert) (t domain.Threshold, ok bool, err error) {
+	switch a.Condition {
+	case domain.ConditionPrice:
+		return domain.Threshold{Limit: a.TargetPrice}, true, nil
+	case domain.ConditionPricePerGram:
+		return domain.Threshold{Limit: a.TargetPricePerGram, PerGram: true}, true, nil
+	case domain.ConditionPercentDrop:
+		return domain.Threshold{Limit: domain.Round2(a.BasePrice * (1 - a.DropPercent/100))}, a.BasePrice > 0, nil
+	case domain.ConditionLow:
+		low, err := r.low(ctx, a.LowDays)
+		if err != nil {
+			return domain.Threshold{}, false, err
+		}
+		return domain.Threshold{Limit: low, Strict: true}, low > 0, nil
+	}
+	return domain.Threshold{}, false, fmt.Errorf("unknown alert condition %q", a.Condition)
+}
+
+// Match returns the offer that best meets a's condition: the cheapest, or
+// for per-gram conditions the cheapest per gram of protein.
+func (r *Rules) Match(ctx context.Context, a domain.PriceAlert) (domain.Offer, bool, error) {
+	t, ok, err := r.Threshold(ctx, a)
+	if err != nil || !ok {
+		return domain.Offer{}, false, err
+	}
+	var best domain.Offer
+	found := false
+	for _, o := range r.comparison.Prices {
+		if !t.Matches(o) {
+			continue
+		}
+		better := o.Price < best.Price
+		if t.PerGram {
+			better = o.PricePerGramProtein < best.PricePerGramProtein
+		}
+		if !found || better {
+			best, found = o, true
+		}
+	}
+	return best, found, nil
+}
+
+// low returns the lowest in-stock price recorded over the previous days,
+// leaving out each listing's latest point: that is the price being judged.
+func (r *Rules) low(ctx context.Context, days int) (float64, error) {
+	if low, ok := r.lows[days]; ok {
+		return low, nil
+	}
+	h, err := r.prices.History(ctx, r.comparison.Product.ID, services.HistoryQuery{Days: days})
+	if err != nil {
+		return 0, fmt.Errorf("load %d day history: %w", days, err)
+	}
+	// Points are oldest first, so a listing's latest is its last.
+	latest := make(map[string]int, len(r.comparison.Prices))
+	for i, p := range h.Points {
+		latest[p.ListingID] = i
+	}
+	low := math.Inf(1)
+	for i, p := range h.Points {
+		if p.InStock && p.Price > 0 && latest[p.ListingID] != i {
+			low = min(low, p.Price)
+		}
+	}
+	if math.IsInf(low, 1) {
+		low = 0
+	}
+	r.lows[days] = low
+	return low, nil
+}
@@ -0,0 +1,116 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// Test queues a test notification for an alert as though its condition had
+// just been met, so its owner can check their channels without waiting for
+// a real drop. The notification goes through the usual queue and channels,
+// but the alert is left as it was. The price is the product's best offer,
+// lowered to the alert's threshold if it has not reached it. userID must
+// own the alert; support staff pass "" to test any alert.
+func (s *Service) Test(ctx context.Context, userID, alertID string) (*domain.Notification, error) {
+	a, err := s.repos.Alerts.Alert(ctx, alertID)
+	if err != nil {
+		return nil, err
+	}
+	if userID != "" && a.UserID != userID {
+		return nil, fmt.Errorf("alert %q: %w", alertID, domain.ErrNotFound)
+	}
+	if a.Pending {
+		return nil, fmt.Errorf("alert %q has not been confirmed: %w", alertID, domain.ErrConflict)
+	}
+	c, err := s.prices.Compare(ctx, a.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("compare %s: %w", a.ProductID, err)
+	}
+	t, ok, err := NewRules(s.prices, c).Threshold(ctx, *a)
+	if err != nil {
+		return nil, fmt.Errorf("evaluate alert %s: %w", a.ID, err)
+	}
+	offer, was, found := simulatedOffer(c.Prices, t, ok)
+	if !found {
+		return nil, fmt.Errorf("product %q has no prices to test with: %w", a.ProductID, domain.ErrConflict)
+	}
+
+	n := domain.Notification{
+		Type:          domain.NotificationPriceAlert,
+		UserID:        a.UserID,
+		AlertID:       a.ID,
+		ProductID:     a.ProductID,
+		ProductName:   c.Product.Name,
+		ImageURL:      c.Product.ImageURL,
+		RetailerID:    offer.RetailerID,
+		RetailerName:  offer.RetailerName,
+		Price:         offer.Price,
+		PreviousPrice: was,
+		PricePerGram:  offer.PricePerGramProtein,
+		URL:           offer.BuyURL,
+		CreatedAt:     s.now().UTC(),
+		Test:          true,
+	}
+	if err := s.repos.Notifications.Enqueue(ctx, n); err != nil {
+		return nil, fmt.Errorf("queue notification: %w", err)
+	}
+	s.logger.Info("Test alert queued",
+		zap.String("operation", "TestAlert"),
+		zap.String("alert_id", a.ID),
+		zap.String("user_id", a.UserID),
+		zap.Bool("support", userID == ""),
+	)
+	return &n, nil
+}
+
+// simulatedOffer picks the offer a test notification describes: the
+// cheapest, preferring ones in stock, by price per gram for per-gram
+// thresholds. When the threshold is known and the offer does not meet it,
+// its price is lowered to just meet it, keeping the per-gram price in
+// proportion, and was is its real price.
+func simulatedOffer(offers []domain.Offer, t domain.Threshold, known bool) (o domain.Offer, was float64, ok bool) {
+	value := func(o domain.Offer) float64 {
+		if t.PerGram {
+			return o.PricePerGramProtein
+		}
+		return o.Price
+	}
+	var best domain.Offer
+	found := false
+	for _, o := range offers {
+		if value(o) <= 0 {
+			continue
+		}
+		if !found || o.InStock && !best.InStock || o.InStock == best.InStock && value(o) < value(best) {
+			best, found = o, true
+		}
+	}
+	if !found {
+		return best, 0, false
+	}
+	best.InStock = true
+	if !known || t.Matches(best) {
+		return best, 0, true
+	}
+	target := t.Limit
+	if t.Strict {
+		target--
+	}
+	if target <= 0 {
+		return best, 0, true
+	}
+	was = best.Price
+	scale := target / value(best)
+	if t.PerGram {
+		best.PricePerGramProtein = target
+		best.Price = domain.Round2(best.Price * scale)
+	} else {
+		best.Price = target
+		best.PricePerGramProtein = domain.Round2(best.PricePerGramProtein * scale)
+	}
+	return best, was, true
+}
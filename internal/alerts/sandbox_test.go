@@ -0,0 +1,81 @@
+package alerts
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestService_Test(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_Test", "internal/alerts")
+
+	testhelpers.LogTestStep(logger, "arrange", "Gold Standard's best offer is Flipkart at ₹3,199")
+	svc, store := newTestService(t, time.Now())
+	ctx := t.Context()
+	create := func(userID string, spec domain.PriceAlert) *domain.PriceAlert {
+		t.Helper()
+		spec.ProductID = testhelpers.FixtureProductID
+		a, err := svc.Create(ctx, domain.User{ID: userID, EmailVerified: true}, spec)
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		return a
+	}
+	below := create("user_1", domain.PriceAlert{TargetPrice: 3000})
+	above := create("user_2", domain.PriceAlert{TargetPrice: 3500})
+	low := create("user_3", domain.PriceAlert{Condition: domain.ConditionLow, LowDays: 30})
+
+	testCases := []struct {
+		name      string
+		userID    string
+		alertID   string
+		wantPrice float64
+		wantErr   error
+	}{
+		{"Target not reached is simulated", "user_1", below.ID, 3000, nil},
+		{"Target already reached uses the offer", "user_2", above.ID, 3199, nil},
+		{"Low is undercut", "user_3", low.ID, 3198, nil},
+		{"Support may test any alert", "", below.ID, 3000, nil},
+		{"Another user's alert", "user_2", below.ID, 0, domain.ErrNotFound},
+		{"Unknown alert", "user_1", "alert_missing", 0, domain.ErrNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			n, err := svc.Test(ctx, tc.userID, tc.alertID)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("Test error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Test: %v", err)
+			}
+			if !n.Test || n.AlertID != tc.alertID || n.RetailerID != "flipkart" || n.Price != tc.wantPrice {
+				t.Errorf("Notification = %+v, want a test from Flipkart at ₹%v", n, tc.wantPrice)
+			}
+			if n.PricePerGram <= 0 || n.URL == "" {
+				t.Errorf("Notification per-gram price = %v, URL = %q", n.PricePerGram, n.URL)
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Tests are queued but leave the alerts armed")
+	pending, err := store.Notifications().Pending(ctx, 0)
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(pending) != 4 || pending[0].UserID != "user_1" || !pending[0].Test {
+		t.Errorf("Pending = %+v, want 4 test notifications", pending)
+	}
+	if a, _ := store.Alerts().Alert(ctx, below.ID); a.TriggeredAt != nil {
+		t.Error("Testing an alert marked it triggered")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_Test", true)
+}
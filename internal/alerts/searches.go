@@ -0,0 +1,179 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// DefaultMaxSearchesPerUser bounds how many saved searches one user may
+// hold.
+const DefaultMaxSearchesPerUser = 20
+
+var errSearchesDisabled = errors.New("saved searches are not configured")
+
+// SearchesEnabled reports whether the Service was given a saved search
+// repository.
+func (s *Service) SearchesEnabled() bool { return s.repos.Searches != nil }
+
+// CreateSearch saves spec's criteria for u. Products that match already
+// are recorded without notifying, so only products that match later, or
+// get cheaper, do.
+func (s *Service) CreateSearch(ctx context.Context, u domain.User, spec domain.SavedSearch) (*domain.SavedSearch, error) {
+	if s.repos.Searches == nil {
+		return nil, errSearchesDisabled
+	}
+	if err := s.checkReachable(ctx, u); err != nil {
+		return nil, err
+	}
+	search := domain.SavedSearch{
+		UserID:          u.ID,
+		Name:            strings.TrimSpace(spec.Name),
+		Query:           strings.TrimSpace(spec.Query),
+		CategoryID:      spec.CategoryID,
+		BrandID:         spec.BrandID,
+		MaxPrice:        domain.Round2(spec.MaxPrice),
+		MaxPricePerGram: spec.MaxPricePerGram,
+		MinSizeGrams:    spec.MinSizeGrams,
+		CreatedAt:       s.now().UTC(),
+		Notified:        make(map[string]float64),
+	}
+	if err := search.Validate(); err != nil {
+		return nil, err
+	}
+	existing, err := s.repos.Searches.UserSavedSearches(ctx, u.ID)
+	if err != nil {
+		return nil, fmt.Errorf("load saved searches: %w", err)
+	}
+	if len(existing) >= DefaultMaxSearchesPerUser {
+		return nil, fmt.Errorf("you can have at most %d saved searches: %w", DefaultMaxSearchesPerUser, domain.ErrConflict)
+	}
+
+	// Held while scanning, so no price event slips between the scan and
+	// the search being stored.
+	s.evalMu.Lock()
+	defer s.evalMu.Unlock()
+	filter := repositories.ProductFilter{CategoryID: search.CategoryID, BrandID: search.BrandID}
+	err = s.prices.EachComparison(ctx, filter, func(c *domain.Comparison) {
+		if o, ok := search.Match(*c); ok {
+			search.Notified[c.Product.ID] = o.Price
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("match saved search: %w", err)
+	}
+	search.Matches = len(search.Notified)
+	search, err = s.repos.Searches.CreateSavedSearch(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("Saved search created",
+		zap.String("operation", "CreateSavedSearch"),
+		zap.String("user_id", u.ID),
+		zap.String("search_id", search.ID),
+		zap.Int("matches", search.Matches),
+	)
+	return &search, nil
+}
+
+// Searches returns userID's saved searches, newest first.
+func (s *Service) Searches(ctx context.Context, userID string) ([]domain.SavedSearch, error) {
+	if s.repos.Searches == nil {
+		return nil, errSearchesDisabled
+	}
+	searches, err := s.repos.Searches.UserSavedSearches(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("load saved searches: %w", err)
+	}
+	return searches, nil
+}
+
+// DeleteSearch removes one of userID's saved searches. Other users'
+// searches are reported as not found.
+func (s *Service) DeleteSearch(ctx context.Context, userID, searchID string) error {
+	if s.repos.Searches == nil {
+		return errSearchesDisabled
+	}
+	search, err := s.repos.Searches.SavedSearch(ctx, searchID)
+	if err != nil {
+		return err
+	}
+	if search.UserID != userID {
+		return fmt.Errorf("saved search %q: %w", searchID, domain.ErrNotFound)
+	}
+	return s.repos.Searches.DeleteSavedSearch(ctx, searchID)
+}
+
+// evaluateSearches notifies the owners of searches that c's product has
+// started to match, or whose match got cheaper than they were last told,
+// and forgets the product for searches it no longer matches.
+func (s *Service) evaluateSearches(ctx context.Context, c *domain.Comparison, change domain.PriceChange, searches []domain.SavedSearch, now time.Time) error {
+	productID := c.Product.ID
+	var changed []domain.SavedSearch
+	var queued []domain.Notification
+	for _, search := range searches {
+		offer, met := search.Match(*c)
+		last, seen := search.Notified[productID]
+		switch {
+		case met && (!seen || offer.Price < last):
+			if search.Notified == nil {
+				search.Notified = make(map[string]float64)
+			}
+			search.Notified[productID] = offer.Price
+			previous := previousPrice(change, offer)
+			if seen {
+				// The price the owner was last told about.
+				previous = last
+			}
+			queued = append(queued, domain.Notification{
+				Type:          domain.NotificationPriceAlert,
+				UserID:        search.UserID,
+				SearchID:      search.ID,
+				SearchName:    search.Name,
+				ProductID:     productID,
+				ProductName:   c.Product.Name,
+				ImageURL:      c.Product.ImageURL,
+				RetailerID:    offer.RetailerID,
+				RetailerName:  offer.RetailerName,
+				Price:         offer.Price,
+				PreviousPrice: previous,
+				PricePerGram:  offer.PricePerGramProtein,
+				URL:           offer.BuyURL,
+				CreatedAt:     now,
+				DedupeKey:     dedupeKey(ctx, "search", search.ID),
+			})
+		case !met && seen:
+			delete(search.Notified, productID)
+		default:
+			continue
+		}
+		search.Matches = len(search.Notified)
+		changed = append(changed, search)
+	}
+
+	// As with alerts, notifications are queued first, so a failure leaves
+	// the match to be notified on the next event.
+	if len(queued) > 0 {
+		if err := s.repos.Notifications.Enqueue(ctx, queued...); err != nil {
+			return fmt.Errorf("queue notifications: %w", err)
+		}
+		s.logger.Info("Saved searches matched",
+			zap.String("operation", "EvaluateSavedSearches"),
+			zap.String("product_id", productID),
+			zap.Int("notifications", len(queued)),
+		)
+	}
+	for _, search := range changed {
+		if err := s.repos.Searches.SaveSavedSearch(ctx, search); err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("save saved search %s: %w", search.ID, err)
+		}
+	}
+	return nil
+}
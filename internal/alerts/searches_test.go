@@ -0,0 +1,171 @@
+package alerts
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func newSearchService(t *testing.T, now time.Time) (*Service, *memory.Store) {
+	t.Helper()
+	svc, store := newTestService(t, now)
+	svc.repos.Searches = store.SavedSearches()
+	return svc, store
+}
+
+func TestService_CreateSearchValidation(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_CreateSearchValidation", "internal/alerts")
+
+	svc, _ := newSearchService(t, time.Now())
+	testCases := []struct {
+		name        string
+		user        domain.User
+		spec        domain.SavedSearch
+		wantMatches int
+		wantErr     error
+	}{
+		{"Query", verified, domain.SavedSearch{Name: "Whey", Query: "whey"}, 2, nil},
+		{"Brand and size", verified, domain.SavedSearch{Name: "Big ON", BrandID: "optimum-nutrition", MinSizeGrams: 2000}, 1, nil},
+		{"Nothing matches yet", verified, domain.SavedSearch{Name: "Cheap", MaxPrice: 1000}, 0, nil},
+		{"Unverified email", domain.User{ID: "user_2"}, domain.SavedSearch{Name: "Whey", Query: "whey"}, 0, ErrEmailUnverified},
+		{"No criteria", verified, domain.SavedSearch{Name: "Everything"}, 0, domain.ErrInvalid},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := svc.CreateSearch(t.Context(), tc.user, tc.spec)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("CreateSearch error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateSearch: %v", err)
+			}
+			if s.Matches != tc.wantMatches || s.UserID != tc.user.ID {
+				t.Errorf("Search = %+v, want %d matches for %s", s, tc.wantMatches, tc.user.ID)
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Saving one search too many")
+	full := domain.User{ID: "user_4", EmailVerified: true}
+	for range DefaultMaxSearchesPerUser {
+		if _, err := svc.CreateSearch(t.Context(), full, domain.SavedSearch{Name: "Whey", Query: "whey"}); err != nil {
+			t.Fatalf("CreateSearch: %v", err)
+		}
+	}
+	_, err := svc.CreateSearch(t.Context(), full, domain.SavedSearch{Name: "Whey", Query: "whey"})
+	testhelpers.LogTestAssertion(logger, "over the limit", domain.ErrConflict, err)
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("CreateSearch over the limit = %v, want ErrConflict", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_CreateSearchValidation", true)
+}
+
+func TestService_HandleNotifiesSearchMatches(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_HandleNotifiesSearchMatches", "internal/alerts")
+
+	testhelpers.LogTestStep(logger, "arrange", "Whey under ₹3,150: Biozyme matches at ₹2,099, Gold Standard does not at ₹3,199")
+	now := time.Now()
+	svc, store := newSearchService(t, now)
+	ctx := t.Context()
+	s, err := svc.CreateSearch(ctx, verified, domain.SavedSearch{Name: "Whey under 3150", Query: "whey", MaxPrice: 3150})
+	if err != nil {
+		t.Fatalf("CreateSearch: %v", err)
+	}
+	if s.Matches != 1 {
+		t.Fatalf("Matches = %d, want 1", s.Matches)
+	}
+	pending := func() []domain.Notification {
+		t.Helper()
+		n, err := store.Notifications().Pending(ctx, 0)
+		if err != nil {
+			t.Fatalf("Pending: %v", err)
+		}
+		return n
+	}
+
+	testhelpers.LogTestStep(logger, "act", "An event for the match already known does not notify")
+	biozyme := domain.PriceChanged{PriceChange: domain.PriceChange{ProductID: testhelpers.FixtureSecondProductID, NewPrice: 2099}}
+	if err := svc.Handle(ctx, biozyme); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if got := len(pending()); got != 0 {
+		t.Fatalf("%d notifications queued for a known match, want 0", got)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Gold Standard moves: 3,099, 3,099, 3,049, 3,299, 3,099")
+	steps := []struct {
+		price       float64
+		wantQueue   int
+		wantMatches int
+	}{
+		{3099, 1, 2}, // new match
+		{3099, 1, 2}, // unchanged
+		{3049, 2, 2}, // cheaper
+		{3299, 2, 1}, // no longer matches
+		{3099, 3, 2}, // matches again
+	}
+	for _, step := range steps {
+		if err := svc.Handle(ctx, setPrice(store, now, step.price)); err != nil {
+			t.Fatalf("Handle(%v): %v", step.price, err)
+		}
+		got, err := store.SavedSearches().SavedSearch(ctx, s.ID)
+		if err != nil {
+			t.Fatalf("SavedSearch: %v", err)
+		}
+		testhelpers.LogTestAssertion(logger, "queued notifications", step.wantQueue, len(pending()))
+		if n := len(pending()); n != step.wantQueue || got.Matches != step.wantMatches {
+			t.Fatalf("After ₹%v: %d notifications queued and %d matches, want %d and %d", step.price, n, got.Matches, step.wantQueue, step.wantMatches)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The notification names the search")
+	n := pending()[1]
+	if n.UserID != verified.ID || n.SearchID != s.ID || n.SearchName != s.Name || n.AlertID != "" {
+		t.Errorf("Notification = %+v, want one for search %s", n, s.ID)
+	}
+	if n.Type != domain.NotificationPriceAlert || n.ProductID != testhelpers.FixtureProductID || n.RetailerID != "flipkart" || n.Price != 3049 {
+		t.Errorf("Notification = %+v, want Flipkart at ₹3,049", n)
+	}
+	if n.PreviousPrice != 3099 {
+		t.Errorf("PreviousPrice = %v, want the ₹3,099 last notified", n.PreviousPrice)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_HandleNotifiesSearchMatches", true)
+}
+
+func TestService_DeleteSearchOwnership(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_DeleteSearchOwnership", "internal/alerts")
+
+	svc, _ := newSearchService(t, time.Now())
+	ctx := t.Context()
+	s, err := svc.CreateSearch(ctx, verified, domain.SavedSearch{Name: "Whey", Query: "whey"})
+	if err != nil {
+		t.Fatalf("CreateSearch: %v", err)
+	}
+
+	err = svc.DeleteSearch(ctx, "user_other", s.ID)
+	testhelpers.LogTestAssertion(logger, "other user's delete", domain.ErrNotFound, err)
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("DeleteSearch by another user = %v, want ErrNotFound", err)
+	}
+	if err := svc.DeleteSearch(ctx, verified.ID, s.ID); err != nil {
+		t.Fatalf("DeleteSearch by owner: %v", err)
+	}
+	if list, _ := svc.Searches(ctx, verified.ID); len(list) != 0 {
+		t.Errorf("Searches after delete = %+v, want none", list)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_DeleteSearchOwnership", true)
+}
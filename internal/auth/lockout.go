@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+)
+
+// ErrLockedOut is returned while sign-ins are refused after too many
+// failures. Errors matching it are *LockoutError.
+var ErrLockedOut = errors.New("too many failed sign-in attempts")
+
+// LockoutError reports how long sign-ins stay locked.
+type LockoutError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockoutError) Error() string {
+	return fmt.Sprintf("%v, try again in %v", ErrLockedOut, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LockoutError) Unwrap() error { return ErrLockedOut }
+
+// LockoutConfig configures temporary lockouts after repeated failed
+// sign-ins. Failures are counted per account and, more generously, per
+// client address, which also covers guessed API tokens.
+type LockoutConfig struct {
+	// MaxFailures within Window locks an account for Duration. Zero
+	// disables lockouts.
+	MaxFailures int
+	// MaxFailuresPerIP within Window locks a client address for Duration.
+	// Zero disables them.
+	MaxFailuresPerIP int
+	Window           time.Duration
+	Duration         time.Duration
+}
+
+// DefaultLockoutConfig locks an account for 15 minutes after 5 failed
+// sign-ins in 15 minutes, and an address after 20.
+func DefaultLockoutConfig() LockoutConfig {
+	return LockoutConfig{MaxFailures: 5, MaxFailuresPerIP: 20, Window: 15 * time.Minute, Duration: 15 * time.Minute}
+}
+
+// lockouts counts recent failures per key in a fixed window starting at
+// the first one.
+type lockouts struct {
+	mu        sync.Mutex
+	entries   map[string]*lockoutEntry
+	nextSweep time.Time
+}
+
+type lockoutEntry struct {
+	failures    int
+	windowStart time.Time
+	lockedUntil time.Time
+}
+
+func newLockouts() *lockouts {
+	return &lockouts{entries: make(map[string]*lockoutEntry)}
+}
+
+// lockedFor returns how long key stays locked, or zero.
+func (l *lockouts) lockedFor(key string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if e, ok := l.entries[key]; ok && now.Before(e.lockedUntil) {
+		return e.lockedUntil.Sub(now)
+	}
+	return 0
+}
+
+// fail counts a failure for key, locking it once it reaches limit failures
+// within cfg.Window, and returns how long it is now locked for.
+func (l *lockouts) fail(key string, limit int, cfg LockoutConfig, now time.Time) time.Duration {
+	if limit <= 0 {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now, cfg)
+	e, ok := l.entries[key]
+	if !ok || now.Sub(e.windowStart) >= cfg.Window {
+		e = &lockoutEntry{windowStart: now}
+		l.entries[key] = e
+	}
+	e.failures++
+	if e.failures >= limit {
+		e.lockedUntil = now.Add(cfg.Duration)
+		e.failures, e.windowStart = 0, now
+		return cfg.Duration
+	}
+	return 0
+}
+
+// reset forgets key's failures, e.g. after a successful sign-in.
+func (l *lockouts) reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.entries, key)
+}
+
+func (l *lockouts) sweep(now time.Time, cfg LockoutConfig) {
+	if now.Before(l.nextSweep) {
+		return
+	}
+	l.nextSweep = now.Add(time.Minute)
+	for key, e := range l.entries {
+		if now.Sub(e.windowStart) >= cfg.Window && !now.Before(e.lockedUntil) {
+			delete(l.entries, key)
+		}
+	}
+}
+
+// ClientIP returns r's client address as lockouts count it.
+func (s *Service) ClientIP(r *http.Request) string {
+	return httpx.ClientIP(r, s.cfg.TrustProxy)
+}
+
+// checkLockout returns a *LockoutError if the account or address is
+// locked.
+func (s *Service) checkLockout(email, ip string) error {
+	now := s.now()
+	var wait time.Duration
+	if email != "" {
+		wait = s.lockouts.lockedFor("email:"+email, now)
+	}
+	if ip != "" {
+		wait = max(wait, s.lockouts.lockedFor("ip:"+ip, now))
+	}
+	if wait > 0 {
+		return &LockoutError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// recordFailure counts a failed sign-in for the account, if known, and the
+// address, logging when either gets locked.
+func (s *Service) recordFailure(operation, email, ip string) {
+	now := s.now()
+	cfg := s.cfg.Lockout
+	if email != "" && s.lockouts.fail("email:"+email, cfg.MaxFailures, cfg, now) > 0 {
+		s.logger.Warn("Account sign-ins locked",
+			zap.String("operation", operation),
+			zap.String("scope", "account"),
+			zap.Duration("duration", cfg.Duration),
+		)
+	}
+	if ip != "" && s.lockouts.fail("ip:"+ip, cfg.MaxFailuresPerIP, cfg, now) > 0 {
+		s.logger.Warn("Client address sign-ins locked",
+			zap.String("operation", operation),
+			zap.String("scope", "ip"),
+			zap.Duration("duration", cfg.Duration),
+		)
+	}
+}
+
+// Rate limit tiers reported by RateLimitTier.
+const (
+	TierUnverified = "unverified"
+	TierVerified   = "verified"
+	TierAPIToken   = "api_token"
+)
+
+// RateLimitKey identifies the account, or the API token, r is made with,
+// for per-account rate limits; it is "" for anonymous requests. Each API
+// token has a budget of its own.
+func RateLimitKey(r *http.Request) string {
+	if t := APITokenFromContext(r.Context()); t != nil {
+		return "token:" + t.ID
+	}
+	if u := UserFromContext(r.Context()); u != nil {
+		return "user:" + u.ID
+	}
+	return ""
+}
+
+// RateLimitTier names the rate limit tier of r's caller.
+func RateLimitTier(r *http.Request) string {
+	switch u := UserFromContext(r.Context()); {
+	case APITokenFromContext(r.Context()) != nil:
+		return TierAPIToken
+	case u != nil && u.EmailVerified:
+		return TierVerified
+	default:
+		return TierUnverified
+	}
+}
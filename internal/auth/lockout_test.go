@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestService_LoginLockout(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_LoginLockout", "internal/auth")
+
+	svc, _ := newTestService(t)
+	now := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := t.Context()
+	for _, email := range []string{"asha@example.com", "ravi@example.com"} {
+		if _, err := svc.Register(ctx, email, "test-only-password", ""); err != nil {
+			t.Fatalf("Register: %v", err)
+		}
+	}
+	meta := SessionMeta{IPAddress: "203.0.113.7"}
+	cfg := svc.cfg.Lockout
+
+	testhelpers.LogTestStep(logger, "act", "Failing until the account locks")
+	for i := range cfg.MaxFailures {
+		if _, _, err := svc.Login(ctx, "asha@example.com", "wrong-password", meta); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("Failure %d error = %v, want ErrInvalidCredentials", i+1, err)
+		}
+	}
+	_, _, err := svc.Login(ctx, "ASHA@example.com", "test-only-password", SessionMeta{IPAddress: "198.51.100.1"})
+	testhelpers.LogTestAssertion(logger, "locked", ErrLockedOut, err)
+	var lockout *LockoutError
+	if !errors.As(err, &lockout) || lockout.RetryAfter != cfg.Duration {
+		t.Fatalf("Login while locked error = %v, want a %v lockout", err, cfg.Duration)
+	}
+	if _, _, err := svc.Login(ctx, "ravi@example.com", "test-only-password", meta); err != nil {
+		t.Errorf("Other account from the same address: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Signing in once the lockout ends resets the count")
+	now = now.Add(cfg.Duration)
+	if _, _, err := svc.Login(ctx, "asha@example.com", "test-only-password", meta); err != nil {
+		t.Fatalf("Login after lockout: %v", err)
+	}
+	for range cfg.MaxFailures - 1 {
+		_, _, _ = svc.Login(ctx, "asha@example.com", "wrong-password", meta)
+	}
+	if _, _, err := svc.Login(ctx, "asha@example.com", "test-only-password", meta); err != nil {
+		t.Errorf("Login below the limit: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Spraying many accounts from one address")
+	now = now.Add(cfg.Window)
+	for i := range cfg.MaxFailuresPerIP {
+		_, _, _ = svc.Login(ctx, "nobody"+string(rune('a'+i))+"@example.com", "wrong-password", SessionMeta{IPAddress: "192.0.2.9"})
+	}
+	if _, _, err := svc.Login(ctx, "ravi@example.com", "test-only-password", SessionMeta{IPAddress: "192.0.2.9"}); !errors.Is(err, ErrLockedOut) {
+		t.Errorf("Login from a locked address error = %v, want ErrLockedOut", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_LoginLockout", true)
+}
+
+func TestService_HandlerLocksOutTokenGuessing(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_HandlerLocksOutTokenGuessing", "internal/auth")
+
+	svc, _ := newTestService(t)
+	svc.cfg.Lockout.MaxFailuresPerIP = 3
+	h := svc.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	send := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
+		req.RemoteAddr = ip + ":51234"
+		req.Header.Set("Authorization", "Bearer "+APITokenPrefix+"guess")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Guessing tokens from one address")
+	for range 3 {
+		if rec := send("203.0.113.7"); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("Guess status = %d, want 401", rec.Code)
+		}
+	}
+	rec := send("203.0.113.7")
+	testhelpers.LogTestAssertion(logger, "status", http.StatusTooManyRequests, rec.Code)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "900" {
+		t.Errorf("Locked status = %d, Retry-After %q; want 429, 900", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if rec := send("198.51.100.1"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Other address status = %d, want 401", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_HandlerLocksOutTokenGuessing", true)
+}
+
+func TestRateLimitKeyAndTier(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestRateLimitKeyAndTier", "internal/auth")
+
+	user := &domain.User{ID: "user_1"}
+	verified := &domain.User{ID: "user_2", EmailVerified: true}
+	token := &domain.APIToken{ID: "token_3"}
+	testCases := []struct {
+		name     string
+		ctx      context.Context
+		wantKey  string
+		wantTier string
+	}{
+		{"Anonymous", context.Background(), "", TierUnverified},
+		{"Unverified user", context.WithValue(context.Background(), userKey{}, user), "user:user_1", TierUnverified},
+		{"Verified user", context.WithValue(context.Background(), userKey{}, verified), "user:user_2", TierVerified},
+		{"API token", context.WithValue(context.WithValue(context.Background(), userKey{}, verified), tokenKey{}, token), "token:token_3", TierAPIToken},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequestWithContext(tc.ctx, http.MethodGet, "/api/v1/deals", nil)
+			key, tier := RateLimitKey(r), RateLimitTier(r)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantKey, key)
+			if key != tc.wantKey || tier != tc.wantTier {
+				t.Errorf("Key, tier = %q, %q; want %q, %q", key, tier, tc.wantKey, tc.wantTier)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestRateLimitKeyAndTier", true)
+}
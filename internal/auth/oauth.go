@@ -0,0 +1,369 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// Identity is a user as an OAuth provider reports them.
+type Identity struct {
+	Provider string
+	// Subject is the provider's stable ID for the user; emails can change.
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider signs users in with an account elsewhere using the OAuth2
+// authorization code flow with PKCE.
+type Provider interface {
+	Name() string
+	// AuthCodeURL returns the provider's consent page URL.
+	AuthCodeURL(state, codeChallenge, redirectURL string) string
+	// Exchange trades an authorization code for the user's identity.
+	Exchange(ctx context.Context, code, codeVerifier, redirectURL string) (*Identity, error)
+}
+
+// OIDCConfig describes an OAuth2 provider whose user info endpoint returns
+// the OpenID Connect standard claims (sub, email, email_verified, name).
+type OIDCConfig struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	AuthURL      string
+	TokenURL     string
+	UserInfoURL  string
+	Scopes       []string
+	Timeout      time.Duration
+}
+
+// GoogleConfig returns Google's endpoints. Credentials come from a Google
+// Cloud OAuth client, e.g. GOOGLE_CLIENT_ID=<YOUR_GOOGLE_CLIENT_ID_HERE>.
+func GoogleConfig(clientID, clientSecret string) OIDCConfig {
+	return OIDCConfig{
+		Name:         "google",
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		AuthURL:      "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL:     "https://oauth2.googleapis.com/token",
+		UserInfoURL:  "https://openidconnect.googleapis.com/v1/userinfo",
+		Scopes:       []string{"openid", "email", "profile"},
+		Timeout:      10 * time.Second,
+	}
+}
+
+// OIDCProvider is a Provider for OIDCConfig providers.
+type OIDCProvider struct {
+	cfg    OIDCConfig
+	client *http.Client
+}
+
+// NewOIDCProvider creates an OIDCProvider.
+func NewOIDCProvider(cfg OIDCConfig) *OIDCProvider {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &OIDCProvider{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}
+}
+
+// Name implements Provider.
+func (p *OIDCProvider) Name() string { return p.cfg.Name }
+
+// AuthCodeURL implements Provider.
+func (p *OIDCProvider) AuthCodeURL(state, codeChallenge, redirectURL string) string {
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {redirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {state},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(p.cfg.AuthURL, "?") {
+		sep = "&"
+	}
+	return p.cfg.AuthURL + sep + q.Encode()
+}
+
+// Exchange implements Provider.
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier, redirectURL string) (*Identity, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURL},
+		"client_id":     {p.cfg.ClientID},
+		"client_secret": {p.cfg.ClientSecret},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var token struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+	}
+	if err := p.do(req, &token); err != nil {
+		return nil, fmt.Errorf("%s token exchange: %w", p.cfg.Name, err)
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("%s token exchange: no access token", p.cfg.Name)
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.UserInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	var claims struct {
+		Subject       string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := p.do(req, &claims); err != nil {
+		return nil, fmt.Errorf("%s user info: %w", p.cfg.Name, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%s user info: no subject", p.cfg.Name)
+	}
+	return &Identity{
+		Provider:      p.cfg.Name,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+	}, nil
+}
+
+func (p *OIDCProvider) do(req *http.Request, v any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		// Error bodies carry an error code, never the client secret.
+		var body struct {
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal(raw, &body)
+		return fmt.Errorf("status %d %s", resp.StatusCode, body.Error)
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// oauthStateCookie holds the state and PKCE verifier between BeginOAuth and
+// CompleteOAuth. It is scoped to the OAuth routes and lives ten minutes.
+const (
+	oauthStateCookie = "oauth_state"
+	oauthStatePath   = "/api/v1/auth/oauth/"
+	oauthStateTTL    = 10 * time.Minute
+)
+
+// WithProvider enables sign-in with p. Providers need Repos.Identities and
+// Config.OAuthCallbackURL.
+func (s *Service) WithProvider(p Provider) *Service {
+	if s.providers == nil {
+		s.providers = make(map[string]Provider)
+	}
+	s.providers[p.Name()] = p
+	return s
+}
+
+func (s *Service) provider(name string) (Provider, error) {
+	p, ok := s.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("sign-in provider %q: %w", name, domain.ErrNotFound)
+	}
+	return p, nil
+}
+
+func (s *Service) callbackURL(provider string) string {
+	return strings.ReplaceAll(s.cfg.OAuthCallbackURL, "{provider}", provider)
+}
+
+// BeginOAuth sets the state cookie and returns the provider's consent page
+// URL to redirect the browser to.
+func (s *Service) BeginOAuth(w http.ResponseWriter, provider string) (string, error) {
+	p, err := s.provider(provider)
+	if err != nil {
+		return "", err
+	}
+	state, verifier := rand.Text(), rand.Text()+rand.Text()
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    state + "." + verifier,
+		Path:     oauthStatePath,
+		MaxAge:   int(oauthStateTTL / time.Second),
+		HttpOnly: true,
+		Secure:   s.cfg.Cookie.Secure,
+		// Lax, so the cookie comes back on the provider's redirect.
+		SameSite: http.SameSiteLaxMode,
+	})
+	challenge := sha256.Sum256([]byte(verifier))
+	return p.AuthCodeURL(state, base64.RawURLEncoding.EncodeToString(challenge[:]), s.callbackURL(provider)), nil
+}
+
+// CompleteOAuth handles the provider's redirect back: it checks the state
+// against the cookie BeginOAuth set, exchanges the code and signs the user
+// in with SignInWithIdentity, returning the session token. current is the
+// already signed-in user, if any.
+func (s *Service) CompleteOAuth(w http.ResponseWriter, r *http.Request, provider string, current *domain.User) (*domain.User, string, error) {
+	p, err := s.provider(provider)
+	if err != nil {
+		return nil, "", err
+	}
+	http.SetCookie(w, &http.Cookie{Name: oauthStateCookie, Path: oauthStatePath, MaxAge: -1, HttpOnly: true, Secure: s.cfg.Cookie.Secure})
+
+	q := r.URL.Query()
+	if q.Get("error") != "" {
+		return nil, "", fmt.Errorf("sign-in was cancelled: %w", domain.ErrInvalid)
+	}
+	expired := fmt.Errorf("sign-in has expired, please try again: %w", domain.ErrInvalid)
+	c, err := r.Cookie(oauthStateCookie)
+	if err != nil {
+		return nil, "", expired
+	}
+	state, verifier, ok := strings.Cut(c.Value, ".")
+	if !ok || q.Get("code") == "" || subtle.ConstantTimeCompare([]byte(state), []byte(q.Get("state"))) != 1 {
+		return nil, "", expired
+	}
+	id, err := p.Exchange(r.Context(), q.Get("code"), verifier, s.callbackURL(provider))
+	if err != nil {
+		return nil, "", err
+	}
+	return s.SignInWithIdentity(r.Context(), *id, current, SessionMeta{UserAgent: r.UserAgent()})
+}
+
+// errEmailTaken is returned when a provider's unverified email belongs to an
+// existing account, which must sign in with its password to link instead.
+var errEmailTaken = fmt.Errorf("an account with this email already exists; sign in with your password first: %w", domain.ErrConflict)
+
+// SignInWithIdentity starts a session for the user an external identity
+// belongs to, linking it on first use:
+//
+//   - an identity already linked signs in its user;
+//   - with a signed-in user, the identity is linked to them;
+//   - otherwise it links to the account with the same email, but only if
+//     the provider has verified that email;
+//   - otherwise a new account without a password is created.
+func (s *Service) SignInWithIdentity(ctx context.Context, id Identity, current *domain.User, meta SessionMeta) (*domain.User, string, error) {
+	if s.repos.Identities == nil {
+		return nil, "", errors.New("identity repository is not configured")
+	}
+	userID, err := s.repos.Identities.IdentityUser(ctx, id.Provider, id.Subject)
+	var u *domain.User
+	switch {
+	case err == nil:
+		if current != nil && current.ID != userID {
+			return nil, "", fmt.Errorf("this %s account is linked to another user: %w", id.Provider, domain.ErrConflict)
+		}
+		if u, err = s.repos.Users.UserByID(ctx, userID); err != nil {
+			return nil, "", fmt.Errorf("load user: %w", err)
+		}
+	case errors.Is(err, domain.ErrNotFound):
+		if u, err = s.accountFor(ctx, id, current); err != nil {
+			return nil, "", err
+		}
+		if err := s.repos.Identities.LinkIdentity(ctx, id.Provider, id.Subject, u.ID); err != nil {
+			return nil, "", err
+		}
+		s.logger.Info("Identity linked",
+			zap.String("operation", "SignInWithIdentity"),
+			zap.String("user_id", u.ID),
+			zap.String("provider", id.Provider),
+		)
+	default:
+		return nil, "", fmt.Errorf("load identity: %w", err)
+	}
+	token, err := s.startSession(ctx, u, meta)
+	if err != nil {
+		return nil, "", err
+	}
+	return u, token, nil
+}
+
+// accountFor picks or creates the user a new identity is linked to.
+func (s *Service) accountFor(ctx context.Context, id Identity, current *domain.User) (*domain.User, error) {
+	if current != nil {
+		return current, nil
+	}
+	email, err := NormalizeEmail(id.Email)
+	if err != nil {
+		return nil, fmt.Errorf("%s did not share a usable email address: %w", id.Provider, domain.ErrInvalid)
+	}
+	u, err := s.repos.Users.UserByEmail(ctx, email)
+	switch {
+	case err == nil:
+		if !id.EmailVerified {
+			return nil, errEmailTaken
+		}
+		if !u.EmailVerified {
+			// Whoever chose this account's password never proved they own
+			// the address and may have registered it to hijack the real
+			// owner, whom the provider has now verified. The password and
+			// its sessions are revoked.
+			u.EmailVerified = true
+			u.PasswordHash = ""
+			if err := s.repos.Users.SaveUser(ctx, *u); err != nil {
+				return nil, fmt.Errorf("save user: %w", err)
+			}
+			if err := s.repos.Sessions.DeleteUserSessions(ctx, u.ID); err != nil {
+				return nil, fmt.Errorf("revoke sessions: %w", err)
+			}
+		}
+		return u, nil
+	case errors.Is(err, domain.ErrNotFound):
+		created, err := s.repos.Users.CreateUser(ctx, domain.User{
+			Email:         email,
+			Name:          strings.TrimSpace(id.Name),
+			EmailVerified: id.EmailVerified,
+			CreatedAt:     s.now().UTC(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		s.logger.Info("User registered",
+			zap.String("operation", "SignInWithIdentity"),
+			zap.String("user_id", created.ID),
+			zap.String("provider", id.Provider),
+		)
+		if !created.EmailVerified {
+			if err := s.sendVerification(ctx, created); err != nil {
+				s.logger.Warn("Failed to send verification email",
+					zap.String("operation", "SignInWithIdentity"),
+					zap.String("user_id", created.ID),
+					zap.Error(err),
+				)
+			}
+		}
+		return &created, nil
+	default:
+		return nil, fmt.Errorf("load user: %w", err)
+	}
+}
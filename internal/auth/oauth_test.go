@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// fakeOIDC is a provider that issues one code for a fixed set of claims and
+// checks the PKCE verifier against the challenge it was given.
+func fakeOIDC(t *testing.T, claims map[string]any) *httptest.Server {
+	t.Helper()
+	var challenge string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /authorize", func(w http.ResponseWriter, r *http.Request) {
+		challenge = r.URL.Query().Get("code_challenge")
+		q := url.Values{"code": {"auth-code"}, "state": {r.URL.Query().Get("state")}}
+		http.Redirect(w, r, r.URL.Query().Get("redirect_uri")+"?"+q.Encode(), http.StatusFound)
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+		if r.FormValue("code") != "auth-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token":"access","token_type":"Bearer"}`))
+	})
+	mux.HandleFunc("GET /userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(claims)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestService_OAuthFlow(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_OAuthFlow", "internal/auth")
+
+	srv := fakeOIDC(t, map[string]any{"sub": "g-123", "email": "Asha@Example.com", "email_verified": true, "name": "Asha"})
+	svc, _ := newTestService(t)
+	svc.cfg.OAuthCallbackURL = "https://proteinprices.example/api/v1/auth/oauth/{provider}/callback"
+	svc.WithProvider(NewOIDCProvider(OIDCConfig{
+		Name: "fake", ClientID: "client", ClientSecret: "test-only-secret",
+		AuthURL: srv.URL + "/authorize", TokenURL: srv.URL + "/token", UserInfoURL: srv.URL + "/userinfo",
+	}))
+
+	testhelpers.LogTestStep(logger, "act", "Starting sign-in and following the consent redirect")
+	begin := httptest.NewRecorder()
+	consent, err := svc.BeginOAuth(begin, "fake")
+	if err != nil {
+		t.Fatalf("BeginOAuth: %v", err)
+	}
+	noFollow := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := noFollow.Get(consent)
+	if err != nil {
+		t.Fatalf("consent: %v", err)
+	}
+	resp.Body.Close()
+	callback := resp.Header.Get("Location")
+	if u, _ := url.Parse(callback); u.Path != "/api/v1/auth/oauth/fake/callback" {
+		t.Fatalf("Provider redirected to %q", callback)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Completing sign-in with and without the state cookie")
+	req := httptest.NewRequest(http.MethodGet, callback, nil)
+	if _, _, err := svc.CompleteOAuth(httptest.NewRecorder(), req, "fake", nil); !errors.Is(err, domain.ErrInvalid) {
+		t.Errorf("Callback without state cookie error = %v, want ErrInvalid", err)
+	}
+	for _, c := range begin.Result().Cookies() {
+		req.AddCookie(c)
+	}
+	u, token, err := svc.CompleteOAuth(httptest.NewRecorder(), req, "fake", nil)
+	if err != nil {
+		t.Fatalf("CompleteOAuth: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "A verified account without a password was created")
+	testhelpers.LogTestAssertion(logger, "email", "asha@example.com", u.Email)
+	if u.Email != "asha@example.com" || !u.EmailVerified || u.PasswordHash != "" || u.Name != "Asha" {
+		t.Errorf("User = %+v", u)
+	}
+	if got, err := svc.Authenticate(t.Context(), token); err != nil || got.ID != u.ID {
+		t.Errorf("Authenticate = %v, %v", got, err)
+	}
+	if _, _, err := svc.Login(t.Context(), "asha@example.com", "", SessionMeta{}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Password login to an OAuth-only account error = %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Tampering with state and asking for an unknown provider")
+	forged := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oauth/fake/callback?code=auth-code&state=forged", nil)
+	for _, c := range begin.Result().Cookies() {
+		forged.AddCookie(c)
+	}
+	if _, _, err := svc.CompleteOAuth(httptest.NewRecorder(), forged, "fake", nil); !errors.Is(err, domain.ErrInvalid) {
+		t.Errorf("Forged state error = %v, want ErrInvalid", err)
+	}
+	if _, err := svc.BeginOAuth(httptest.NewRecorder(), "myspace"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Unknown provider error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_OAuthFlow", true)
+}
+
+func TestService_SignInWithIdentity(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_SignInWithIdentity", "internal/auth")
+
+	svc, _ := newTestService(t)
+	ctx := t.Context()
+	verified, _ := svc.Register(ctx, "verified@example.com", "test-only-password", "")
+	verified.EmailVerified = true
+	if err := svc.repos.Users.SaveUser(ctx, *verified); err != nil {
+		t.Fatalf("SaveUser: %v", err)
+	}
+	squatted, _ := svc.Register(ctx, "squatted@example.com", "test-only-password", "")
+	_, squatterToken, _ := svc.Login(ctx, "squatted@example.com", "test-only-password", SessionMeta{})
+	other, _ := svc.Register(ctx, "other@example.com", "test-only-password", "")
+
+	testCases := []struct {
+		name     string
+		identity Identity
+		current  *domain.User
+		wantUser string
+		wantErr  error
+	}{
+		{"Verified email links to existing account",
+			Identity{Provider: "google", Subject: "1", Email: "verified@example.com", EmailVerified: true}, nil, verified.ID, nil},
+		{"Linked identity signs in again",
+			Identity{Provider: "google", Subject: "1", Email: "changed@example.com"}, nil, verified.ID, nil},
+		{"Linked identity cannot move to another user",
+			Identity{Provider: "google", Subject: "1"}, other, "", domain.ErrConflict},
+		{"Unverified email cannot claim an account",
+			Identity{Provider: "google", Subject: "2", Email: "other@example.com"}, nil, "", domain.ErrConflict},
+		{"Signed-in user links a new identity",
+			Identity{Provider: "google", Subject: "3", Email: "elsewhere@example.com"}, other, other.ID, nil},
+		{"Verified email takes over an unverified account",
+			Identity{Provider: "google", Subject: "4", Email: "squatted@example.com", EmailVerified: true}, nil, squatted.ID, nil},
+		{"No usable email",
+			Identity{Provider: "google", Subject: "5"}, nil, "", domain.ErrInvalid},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			u, _, err := svc.SignInWithIdentity(ctx, tc.identity, tc.current, SessionMeta{})
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil || u.ID != tc.wantUser {
+				t.Errorf("SignInWithIdentity = %v, %v; want user %s", u, err, tc.wantUser)
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The squatter's password and session were revoked")
+	if _, err := svc.Authenticate(ctx, squatterToken); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Squatter session error = %v, want ErrNoSession", err)
+	}
+	if _, _, err := svc.Login(ctx, "squatted@example.com", "test-only-password", SessionMeta{}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Squatter login error = %v, want ErrInvalidCredentials", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_SignInWithIdentity", true)
+}
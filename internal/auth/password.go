@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// PasswordParams tunes argon2id. Hashes record their parameters, so these
+// can be raised at any time; older hashes are upgraded at the next login.
+type PasswordParams struct {
+	Time      uint32 // passes over memory
+	MemoryKiB uint32
+	Threads   uint8
+	KeyLen    uint32
+	SaltLen   uint32
+}
+
+// DefaultPasswordParams returns the parameters the user_passwords table
+// defaults to: 3 passes over 64 MiB with 4 lanes.
+func DefaultPasswordParams() PasswordParams {
+	return PasswordParams{Time: 3, MemoryKiB: 64 * 1024, Threads: 4, KeyLen: 32, SaltLen: 16}
+}
+
+var errMalformedHash = errors.New("malformed password hash")
+
+// HashPassword returns password's argon2id hash in the PHC string format,
+// $argon2id$v=19$m=<KiB>,t=<passes>,p=<lanes>$<salt>$<hash>.
+func HashPassword(password string, p PasswordParams) (string, error) {
+	salt := make([]byte, p.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generate salt: %w", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, p.Time, p.MemoryKiB, p.Threads, p.KeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, p.MemoryKiB, p.Time, p.Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// VerifyPassword reports whether password matches an encoded hash. The
+// comparison takes the same time wherever the first difference is.
+func VerifyPassword(encoded, password string) (bool, error) {
+	p, salt, key, err := decodeHash(encoded)
+	if err != nil {
+		return false, err
+	}
+	got := argon2.IDKey([]byte(password), salt, p.Time, p.MemoryKiB, p.Threads, p.KeyLen)
+	return subtle.ConstantTimeCompare(got, key) == 1, nil
+}
+
+// needsRehash reports whether encoded was made with parameters other than p.
+func needsRehash(encoded string, p PasswordParams) bool {
+	have, salt, _, err := decodeHash(encoded)
+	if err != nil {
+		return true
+	}
+	have.SaltLen = uint32(len(salt))
+	return have != p
+}
+
+func decodeHash(encoded string) (PasswordParams, []byte, []byte, error) {
+	var p PasswordParams
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return p, nil, nil, errMalformedHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, fmt.Errorf("%w: unsupported version", errMalformedHash)
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.MemoryKiB, &p.Time, &p.Threads); err != nil {
+		return p, nil, nil, fmt.Errorf("%w: %v", errMalformedHash, err)
+	}
+	// Anything argon2.IDKey would reject, or that could exhaust memory, is
+	// treated as corrupt rather than trusted from storage.
+	if p.Time < 1 || p.Threads < 1 || p.MemoryKiB < 8*uint32(p.Threads) || p.MemoryKiB > 1<<21 {
+		return p, nil, nil, fmt.Errorf("%w: parameters out of range", errMalformedHash)
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("%w: salt: %v", errMalformedHash, err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, fmt.Errorf("%w: hash", errMalformedHash)
+	}
+	p.KeyLen = uint32(len(key))
+	return p, salt, key, nil
+}
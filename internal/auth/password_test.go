@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+var fastParams = PasswordParams{Time: 1, MemoryKiB: 64, Threads: 1, KeyLen: 32, SaltLen: 16}
+
+func TestHashPassword_RoundTrip(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestHashPassword_RoundTrip", "internal/auth")
+
+	testhelpers.LogTestStep(logger, "act", "Hashing the same password twice")
+	first, err := HashPassword("test-only-password", fastParams)
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	second, _ := HashPassword("test-only-password", fastParams)
+
+	testhelpers.LogTestStep(logger, "assert", "Hashes are salted and self-describing")
+	if !strings.HasPrefix(first, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Errorf("Encoded hash = %q", first)
+	}
+	if first == second {
+		t.Error("Two hashes of one password are equal; salt is not random")
+	}
+
+	testCases := []struct {
+		name     string
+		password string
+		want     bool
+	}{
+		{"Correct password", "test-only-password", true},
+		{"Wrong password", "test-only-passwore", false},
+		{"Empty password", "", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ok, err := VerifyPassword(first, tc.password)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.want, ok)
+			if err != nil || ok != tc.want {
+				t.Errorf("VerifyPassword = %v, %v; want %v", ok, err, tc.want)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestHashPassword_RoundTrip", true)
+}
+
+func TestVerifyPassword_MalformedHashes(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestVerifyPassword_MalformedHashes", "internal/auth")
+
+	testCases := []struct {
+		name    string
+		encoded string
+	}{
+		{"Empty", ""},
+		{"Other algorithm", "$argon2i$v=19$m=64,t=1,p=1$c2FsdHNhbHRzYWx0$aGFzaA"},
+		{"Old version", "$argon2id$v=16$m=64,t=1,p=1$c2FsdHNhbHRzYWx0$aGFzaA"},
+		{"Too little memory", "$argon2id$v=19$m=4,t=1,p=1$c2FsdHNhbHRzYWx0$aGFzaA"},
+		{"Too much memory", "$argon2id$v=19$m=99999999,t=1,p=1$c2FsdHNhbHRzYWx0$aGFzaA"},
+		{"Zero passes", "$argon2id$v=19$m=64,t=0,p=1$c2FsdHNhbHRzYWx0$aGFzaA"},
+		{"Bad salt", "$argon2id$v=19$m=64,t=1,p=1$!!$aGFzaA"},
+		{"Missing hash", "$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHRzYWx0$"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := VerifyPassword(tc.encoded, "test-only-password")
+			testhelpers.LogTestAssertion(logger, tc.name, "malformed", err)
+			if !errors.Is(err, errMalformedHash) {
+				t.Errorf("VerifyPassword error = %v, want errMalformedHash", err)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestVerifyPassword_MalformedHashes", true)
+}
+
+func TestNeedsRehash(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestNeedsRehash", "internal/auth")
+
+	encoded, _ := HashPassword("test-only-password", fastParams)
+	stronger := fastParams
+	stronger.Time = 2
+
+	testhelpers.LogTestAssertion(logger, "same params", false, needsRehash(encoded, fastParams))
+	if needsRehash(encoded, fastParams) {
+		t.Error("Hash with current parameters needs rehash")
+	}
+	if !needsRehash(encoded, stronger) {
+		t.Error("Hash with weaker parameters does not need rehash")
+	}
+	if !needsRehash("garbage", fastParams) {
+		t.Error("Malformed hash does not need rehash")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestNeedsRehash", true)
+}
+
+func BenchmarkHashPassword_Default(b *testing.B) {
+	p := DefaultPasswordParams()
+	for b.Loop() {
+		if _, err := HashPassword("test-only-password", p); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,427 @@
+// Package auth manages user accounts: registration, password login, cookie
+// sessions, email verification and personal API tokens. Passwords are
+// hashed with argon2id and session, verification and API tokens are only
+// stored as SHA-256 hashes.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"runtime"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+var (
+	// ErrInvalidCredentials is returned for a wrong password and for an
+	// unknown email alike, so logins cannot probe for accounts.
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	// ErrNoSession is returned for a missing, unknown or expired session.
+	ErrNoSession = errors.New("no valid session")
+)
+
+const (
+	maxPasswordLength = 128 // bytes; bounds the work an attacker can ask for
+	maxNameLength     = 100 // characters
+)
+
+// Config configures the Service.
+type Config struct {
+	Password PasswordParams
+	// MinPasswordLength is counted in characters.
+	MinPasswordLength int
+	SessionTTL        time.Duration
+	VerificationTTL   time.Duration
+	// MaxConcurrentHashes bounds simultaneous password hashes, each of which
+	// holds Password.MemoryKiB of memory.
+	MaxConcurrentHashes int
+	Cookie              CookieConfig
+	// OAuthCallbackURL is the absolute URL providers redirect back to, with
+	// "{provider}" standing for the provider name.
+	OAuthCallbackURL string
+	// MaxAPITokens bounds the API tokens one user can hold.
+	MaxAPITokens int
+	// Lockout temporarily refuses sign-ins after repeated failures.
+	Lockout LockoutConfig
+	// TrustProxy takes client addresses from X-Forwarded-For.
+	TrustProxy bool
+}
+
+// DefaultConfig returns 30-day sessions and 48-hour verification links.
+func DefaultConfig() Config {
+	return Config{
+		Password:            DefaultPasswordParams(),
+		MinPasswordLength:   8,
+		SessionTTL:          30 * 24 * time.Hour,
+		VerificationTTL:     48 * time.Hour,
+		MaxConcurrentHashes: runtime.GOMAXPROCS(0),
+		Cookie:              DefaultCookieConfig(),
+		MaxAPITokens:        10,
+		Lockout:             DefaultLockoutConfig(),
+	}
+}
+
+// Repos groups the repositories the Service reads and writes.
+type Repos struct {
+	Users         repositories.UserRepository
+	Sessions      repositories.SessionRepository
+	Verifications repositories.VerificationRepository
+	// Identities is only needed with OAuth providers.
+	Identities repositories.IdentityRepository
+	// APITokens is only needed for personal API tokens.
+	APITokens repositories.APITokenRepository
+}
+
+// VerificationSender delivers an email verification token to a user, e.g.
+// as a link in an email.
+type VerificationSender interface {
+	SendVerification(ctx context.Context, u domain.User, token string) error
+}
+
+// SessionMeta describes the client a session is created for.
+type SessionMeta struct {
+	UserAgent string
+	// IPAddress is the client address failed logins are counted against.
+	IPAddress string
+}
+
+// Service registers users and manages their sessions.
+type Service struct {
+	cfg       Config
+	repos     Repos
+	sender    VerificationSender
+	providers map[string]Provider
+	hashSlots chan struct{}
+	lockouts  *lockouts
+	// dummyHash is verified against when an email is unknown, so a failed
+	// login takes as long whether or not the account exists.
+	dummyHash string
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewService creates a Service. Until WithVerificationSender is called,
+// verification tokens are issued but not delivered.
+func NewService(cfg Config, repos Repos, logger *zap.Logger) *Service {
+	if cfg.MaxConcurrentHashes < 1 {
+		cfg.MaxConcurrentHashes = 1
+	}
+	if cfg.MaxAPITokens <= 0 {
+		cfg.MaxAPITokens = DefaultConfig().MaxAPITokens
+	}
+	if def := DefaultLockoutConfig(); cfg.Lockout.Window <= 0 || cfg.Lockout.Duration <= 0 {
+		cfg.Lockout.Window, cfg.Lockout.Duration = def.Window, def.Duration
+	}
+	s := &Service{
+		cfg:       cfg,
+		repos:     repos,
+		hashSlots: make(chan struct{}, cfg.MaxConcurrentHashes),
+		lockouts:  newLockouts(),
+		logger:    logger,
+		now:       time.Now,
+	}
+	// A random password no one knows; only its cost matters.
+	s.dummyHash, _ = HashPassword(rand.Text(), cfg.Password)
+	return s
+}
+
+// WithVerificationSender sets how verification tokens are delivered.
+func (s *Service) WithVerificationSender(sender VerificationSender) *Service {
+	s.sender = sender
+	return s
+}
+
+// Register creates an account and sends its verification email. Delivery
+// failures are logged rather than returned, since the user can ask for
+// another email once signed in.
+func (s *Service) Register(ctx context.Context, email, password, name string) (*domain.User, error) {
+	email, err := NormalizeEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validatePassword(password); err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if utf8.RuneCountInString(name) > maxNameLength {
+		return nil, fmt.Errorf("name is longer than %d characters: %w", maxNameLength, domain.ErrInvalid)
+	}
+
+	hash, err := s.hash(ctx, password)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.repos.Users.CreateUser(ctx, domain.User{
+		Email:        email,
+		Name:         name,
+		CreatedAt:    s.now().UTC(),
+		PasswordHash: hash,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("User registered",
+		zap.String("operation", "Register"),
+		zap.String("user_id", u.ID),
+	)
+	if err := s.sendVerification(ctx, u); err != nil {
+		s.logger.Warn("Failed to send verification email",
+			zap.String("operation", "Register"),
+			zap.String("user_id", u.ID),
+			zap.Error(err),
+		)
+	}
+	return &u, nil
+}
+
+// Login checks a password and starts a session, returning the session token
+// for the cookie. Hashes made with older parameters are upgraded. After
+// repeated failures for the account or client address it returns a
+// *LockoutError without checking the password.
+func (s *Service) Login(ctx context.Context, email, password string, meta SessionMeta) (*domain.User, string, error) {
+	email, err := NormalizeEmail(email)
+	if err != nil || len(password) > maxPasswordLength {
+		s.recordFailure("Login", "", meta.IPAddress)
+		return nil, "", ErrInvalidCredentials
+	}
+	if err := s.checkLockout(email, meta.IPAddress); err != nil {
+		return nil, "", err
+	}
+	u, err := s.repos.Users.UserByEmail(ctx, email)
+	if errors.Is(err, domain.ErrNotFound) {
+		u = &domain.User{PasswordHash: s.dummyHash}
+	} else if err != nil {
+		return nil, "", fmt.Errorf("load user: %w", err)
+	}
+	encoded := u.PasswordHash
+	if encoded == "" {
+		encoded = s.dummyHash
+	}
+	ok, err := s.verify(ctx, encoded, password)
+	if err != nil {
+		return nil, "", err
+	}
+	if !ok || u.ID == "" || u.PasswordHash == "" {
+		s.logger.Info("Login failed",
+			zap.String("operation", "Login"),
+			zap.String("user_id", u.ID),
+		)
+		s.recordFailure("Login", email, meta.IPAddress)
+		return nil, "", ErrInvalidCredentials
+	}
+	s.lockouts.reset("email:" + email)
+
+	if needsRehash(u.PasswordHash, s.cfg.Password) {
+		if hash, err := s.hash(ctx, password); err == nil {
+			u.PasswordHash = hash
+		}
+	}
+	token, err := s.startSession(ctx, u, meta)
+	if err != nil {
+		return nil, "", err
+	}
+	return u, token, nil
+}
+
+// startSession records a login for u and returns the new session's token.
+func (s *Service) startSession(ctx context.Context, u *domain.User, meta SessionMeta) (string, error) {
+	now := s.now().UTC()
+	u.LastLoginAt = &now
+	if err := s.repos.Users.SaveUser(ctx, *u); err != nil {
+		return "", fmt.Errorf("record login: %w", err)
+	}
+	token := rand.Text()
+	if err := s.repos.Sessions.CreateSession(ctx, domain.Session{
+		TokenHash: hashToken(token),
+		UserID:    u.ID,
+		UserAgent: meta.UserAgent,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.cfg.SessionTTL),
+	}); err != nil {
+		return "", fmt.Errorf("create session: %w", err)
+	}
+	s.logger.Info("User logged in",
+		zap.String("operation", "Login"),
+		zap.String("user_id", u.ID),
+	)
+	return token, nil
+}
+
+// Authenticate returns the user a session token belongs to, or ErrNoSession.
+// Expired sessions are deleted as they are found.
+func (s *Service) Authenticate(ctx context.Context, token string) (*domain.User, error) {
+	if token == "" {
+		return nil, ErrNoSession
+	}
+	hash := hashToken(token)
+	sess, err := s.repos.Sessions.Session(ctx, hash)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, ErrNoSession
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load session: %w", err)
+	}
+	if !s.now().Before(sess.ExpiresAt) {
+		if err := s.repos.Sessions.DeleteSession(ctx, hash); err != nil {
+			return nil, fmt.Errorf("delete expired session: %w", err)
+		}
+		return nil, ErrNoSession
+	}
+	u, err := s.repos.Users.UserByID(ctx, sess.UserID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, ErrNoSession
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load user: %w", err)
+	}
+	return u, nil
+}
+
+// Logout ends the session with token. Unknown tokens are not an error.
+func (s *Service) Logout(ctx context.Context, token string) error {
+	if token == "" {
+		return nil
+	}
+	return s.repos.Sessions.DeleteSession(ctx, hashToken(token))
+}
+
+// VerifyEmail marks the address a verification token was sent to as
+// verified. Each token works once; unknown, used and expired tokens return
+// domain.ErrInvalid.
+func (s *Service) VerifyEmail(ctx context.Context, token string) (*domain.User, error) {
+	invalid := fmt.Errorf("verification link is invalid or has expired: %w", domain.ErrInvalid)
+	if token == "" {
+		return nil, invalid
+	}
+	v, err := s.repos.Verifications.ConsumeVerification(ctx, hashToken(token))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, invalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("consume verification: %w", err)
+	}
+	if !s.now().Before(v.ExpiresAt) {
+		return nil, invalid
+	}
+	u, err := s.repos.Users.UserByID(ctx, v.UserID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, invalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load user: %w", err)
+	}
+	if u.Email != v.Email {
+		return nil, invalid
+	}
+	if !u.EmailVerified {
+		u.EmailVerified = true
+		if err := s.repos.Users.SaveUser(ctx, *u); err != nil {
+			return nil, fmt.Errorf("save user: %w", err)
+		}
+		s.logger.Info("Email verified",
+			zap.String("operation", "VerifyEmail"),
+			zap.String("user_id", u.ID),
+		)
+	}
+	return u, nil
+}
+
+// ResendVerification issues a new verification token for u. Earlier tokens
+// stay valid until they expire.
+func (s *Service) ResendVerification(ctx context.Context, u domain.User) error {
+	if u.EmailVerified {
+		return fmt.Errorf("email is already verified: %w", domain.ErrConflict)
+	}
+	return s.sendVerification(ctx, u)
+}
+
+func (s *Service) sendVerification(ctx context.Context, u domain.User) error {
+	token := rand.Text()
+	now := s.now().UTC()
+	if err := s.repos.Verifications.CreateVerification(ctx, domain.EmailVerification{
+		TokenHash: hashToken(token),
+		UserID:    u.ID,
+		Email:     u.Email,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.cfg.VerificationTTL),
+	}); err != nil {
+		return fmt.Errorf("create verification: %w", err)
+	}
+	if s.sender == nil {
+		s.logger.Warn("No verification sender configured, email not sent",
+			zap.String("operation", "SendVerification"),
+			zap.String("user_id", u.ID),
+		)
+		return nil
+	}
+	if err := s.sender.SendVerification(ctx, u, token); err != nil {
+		return fmt.Errorf("send verification: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) validatePassword(password string) error {
+	if n := utf8.RuneCountInString(password); n < s.cfg.MinPasswordLength {
+		return fmt.Errorf("password must be at least %d characters: %w", s.cfg.MinPasswordLength, domain.ErrInvalid)
+	}
+	if len(password) > maxPasswordLength {
+		return fmt.Errorf("password must be at most %d bytes: %w", maxPasswordLength, domain.ErrInvalid)
+	}
+	return nil
+}
+
+// hash and verify run argon2id in one of the bounded slots, waiting for a
+// free one or for ctx to end.
+func (s *Service) hash(ctx context.Context, password string) (string, error) {
+	if err := s.acquire(ctx); err != nil {
+		return "", err
+	}
+	defer s.release()
+	return HashPassword(password, s.cfg.Password)
+}
+
+func (s *Service) verify(ctx context.Context, encoded, password string) (bool, error) {
+	if err := s.acquire(ctx); err != nil {
+		return false, err
+	}
+	defer s.release()
+	return VerifyPassword(encoded, password)
+}
+
+func (s *Service) acquire(ctx context.Context) error {
+	select {
+	case s.hashSlots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Service) release() { <-s.hashSlots }
+
+// NormalizeEmail accepts a bare address such as "Name@Example.com" and
+// returns it trimmed and lower-cased.
+func NormalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || len(email) > 254 {
+		return "", fmt.Errorf("email address is invalid: %w", domain.ErrInvalid)
+	}
+	return strings.ToLower(email), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,246 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// recordingSender keeps the last verification token sent to each user.
+type recordingSender struct {
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+func (s *recordingSender) SendVerification(_ context.Context, u domain.User, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tokens == nil {
+		s.tokens = make(map[string]string)
+	}
+	s.tokens[u.ID] = token
+	return nil
+}
+
+func (s *recordingSender) token(userID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens[userID]
+}
+
+func newTestService(t *testing.T) (*Service, *recordingSender) {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.Password = fastParams
+	store := memory.NewStore()
+	sender := &recordingSender{}
+	svc := NewService(cfg, Repos{
+		Users:         store.Users(),
+		Sessions:      store.Sessions(),
+		Verifications: store.Verifications(),
+		Identities:    store.Identities(),
+		APITokens:     store.APITokens(),
+	}, testhelpers.SetupTestLogger(t)).WithVerificationSender(sender)
+	return svc, sender
+}
+
+func TestService_RegisterValidation(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_RegisterValidation", "internal/auth")
+
+	svc, _ := newTestService(t)
+	if _, err := svc.Register(t.Context(), "taken@example.com", "test-only-password", ""); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		email    string
+		password string
+		userName string
+		wantErr  error
+	}{
+		{"Valid", "Asha@Example.com", "test-only-password", "Asha", nil},
+		{"Taken email, other case", " TAKEN@example.com ", "test-only-password", "", domain.ErrConflict},
+		{"Display name form", "Asha <asha2@example.com>", "test-only-password", "", domain.ErrInvalid},
+		{"Not an email", "asha", "test-only-password", "", domain.ErrInvalid},
+		{"Short password", "short@example.com", "2short", "", domain.ErrInvalid},
+		{"Long password", "long@example.com", string(make([]byte, maxPasswordLength+1)), "", domain.ErrInvalid},
+		{"Long name", "name@example.com", "test-only-password", string(make([]rune, maxNameLength+1)), domain.ErrInvalid},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			u, err := svc.Register(t.Context(), tc.email, tc.password, tc.userName)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if tc.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Register: %v", err)
+				}
+				if u.Email != "asha@example.com" || u.EmailVerified || u.ID == "" {
+					t.Errorf("User = %+v", u)
+				}
+				return
+			}
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Register error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_RegisterValidation", true)
+}
+
+func TestService_LoginAndSessions(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_LoginAndSessions", "internal/auth")
+
+	svc, _ := newTestService(t)
+	now := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := t.Context()
+	registered, err := svc.Register(ctx, "asha@example.com", "test-only-password", "Asha")
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Logging in with bad credentials")
+	for _, tc := range []struct{ email, password string }{
+		{"asha@example.com", "wrong-password"},
+		{"nobody@example.com", "test-only-password"},
+		{"not an email", "test-only-password"},
+	} {
+		if _, _, err := svc.Login(ctx, tc.email, tc.password, SessionMeta{}); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("Login(%q) error = %v, want ErrInvalidCredentials", tc.email, err)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Logging in and authenticating")
+	u, token, err := svc.Login(ctx, "ASHA@example.com", "test-only-password", SessionMeta{UserAgent: "test"})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if u.ID != registered.ID || u.LastLoginAt == nil || !u.LastLoginAt.Equal(now) {
+		t.Errorf("Logged in user = %+v", u)
+	}
+	got, err := svc.Authenticate(ctx, token)
+	testhelpers.LogTestAssertion(logger, "authenticated user", registered.ID, got.ID)
+	if err != nil || got.ID != registered.ID {
+		t.Fatalf("Authenticate = %v, %v", got, err)
+	}
+	if _, err := svc.Authenticate(ctx, "forged"); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Authenticate(forged) error = %v, want ErrNoSession", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Expiring one session and logging out another")
+	now = now.Add(svc.cfg.SessionTTL)
+	if _, err := svc.Authenticate(ctx, token); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Expired session error = %v, want ErrNoSession", err)
+	}
+	_, token, _ = svc.Login(ctx, "asha@example.com", "test-only-password", SessionMeta{})
+	if err := svc.Logout(ctx, token); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, token); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Logged out session error = %v, want ErrNoSession", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_LoginAndSessions", true)
+}
+
+func TestService_LoginUpgradesHash(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_LoginUpgradesHash", "internal/auth")
+
+	svc, _ := newTestService(t)
+	ctx := t.Context()
+	if _, err := svc.Register(ctx, "asha@example.com", "test-only-password", ""); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Raising the cost and logging in")
+	svc.cfg.Password.Time = 2
+	u, _, err := svc.Login(ctx, "asha@example.com", "test-only-password", SessionMeta{})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	testhelpers.LogTestAssertion(logger, "needs rehash", false, needsRehash(u.PasswordHash, svc.cfg.Password))
+	if needsRehash(u.PasswordHash, svc.cfg.Password) {
+		t.Errorf("Hash was not upgraded: %s", u.PasswordHash)
+	}
+	if _, _, err := svc.Login(ctx, "asha@example.com", "test-only-password", SessionMeta{}); err != nil {
+		t.Errorf("Login with upgraded hash: %v", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_LoginUpgradesHash", true)
+}
+
+func TestService_VerifyEmail(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_VerifyEmail", "internal/auth")
+
+	svc, sender := newTestService(t)
+	ctx := t.Context()
+	u, err := svc.Register(ctx, "asha@example.com", "test-only-password", "")
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	token := sender.token(u.ID)
+	if token == "" {
+		t.Fatal("No verification token was sent")
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Verifying with the emailed token")
+	verified, err := svc.VerifyEmail(ctx, token)
+	testhelpers.LogTestAssertion(logger, "verified", true, verified != nil && verified.EmailVerified)
+	if err != nil || !verified.EmailVerified {
+		t.Fatalf("VerifyEmail = %+v, %v", verified, err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Tokens work once and resends stop after verifying")
+	if _, err := svc.VerifyEmail(ctx, token); !errors.Is(err, domain.ErrInvalid) {
+		t.Errorf("Reused token error = %v, want ErrInvalid", err)
+	}
+	if _, err := svc.VerifyEmail(ctx, ""); !errors.Is(err, domain.ErrInvalid) {
+		t.Errorf("Empty token error = %v, want ErrInvalid", err)
+	}
+	if err := svc.ResendVerification(ctx, *verified); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("ResendVerification error = %v, want ErrConflict", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Verifying with an expired token")
+	other, _ := svc.Register(ctx, "ravi@example.com", "test-only-password", "")
+	expired := sender.token(other.ID)
+	svc.now = func() time.Time { return time.Now().Add(svc.cfg.VerificationTTL) }
+	if _, err := svc.VerifyEmail(ctx, expired); !errors.Is(err, domain.ErrInvalid) {
+		t.Errorf("Expired token error = %v, want ErrInvalid", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_VerifyEmail", true)
+}
+
+func TestService_HashSlotsHonourContext(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_HashSlotsHonourContext", "internal/auth")
+
+	svc, _ := newTestService(t)
+	for range cap(svc.hashSlots) {
+		svc.hashSlots <- struct{}{}
+	}
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := svc.Register(ctx, "asha@example.com", "test-only-password", "")
+	testhelpers.LogTestAssertion(logger, "error", context.DeadlineExceeded, err)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Register with no free slot error = %v, want DeadlineExceeded", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_HashSlotsHonourContext", true)
+}
@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+	"github.com/yourusername/whey-price-compare/internal/logctx"
+)
+
+// CookieConfig configures the session cookie.
+type CookieConfig struct {
+	Name string
+	// Secure restricts the cookie to HTTPS; only local development over
+	// plain HTTP should turn it off.
+	Secure bool
+}
+
+// DefaultCookieConfig returns a Secure cookie named "session".
+func DefaultCookieConfig() CookieConfig {
+	return CookieConfig{Name: "session", Secure: true}
+}
+
+type (
+	userKey  struct{}
+	tokenKey struct{}
+)
+
+// UserFromContext returns the signed-in user, or nil for anonymous requests.
+func UserFromContext(ctx context.Context) *domain.User {
+	u, _ := ctx.Value(userKey{}).(*domain.User)
+	return u
+}
+
+// APITokenFromContext returns the API token the request was made with, or
+// nil for session and anonymous requests.
+func APITokenFromContext(ctx context.Context) *domain.APIToken {
+	t, _ := ctx.Value(tokenKey{}).(*domain.APIToken)
+	return t
+}
+
+// SetCookie starts a browser session with token. The cookie is HttpOnly and
+// SameSite=Lax, so scripts cannot read it and cross-site forms cannot send
+// it.
+func (s *Service) SetCookie(w http.ResponseWriter, token string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     s.cfg.Cookie.Name,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(s.cfg.SessionTTL / time.Second),
+		HttpOnly: true,
+		Secure:   s.cfg.Cookie.Secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// ClearCookie ends the browser session.
+func (s *Service) ClearCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     s.cfg.Cookie.Name,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   s.cfg.Cookie.Secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// Token returns the session token r carries, or "".
+func (s *Service) Token(r *http.Request) string {
+	c, err := r.Cookie(s.cfg.Cookie.Name)
+	if err != nil {
+		return ""
+	}
+	return c.Value
+}
+
+// Handler is middleware that attaches the user of a valid session cookie to
+// the request context, also as its httpx principal. Requests without one
+// pass through anonymously; an invalid cookie is cleared. Requests bearing
+// an API token act as its user too, but are refused outright if the token
+// is invalid or its scopes do not cover them.
+func (s *Service) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token, ok := bearerAPIToken(r); ok {
+			s.serveAPIToken(w, r, next, token)
+			return
+		}
+		token := s.Token(r)
+		if token == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		u, err := s.Authenticate(r.Context(), token)
+		switch {
+		case err == nil:
+			ctx := context.WithValue(r.Context(), userKey{}, u)
+			r = r.WithContext(httpx.WithPrincipal(ctx, u.ID))
+		case errors.Is(err, ErrNoSession):
+			s.ClearCookie(w)
+		default:
+			// A session store outage leaves the request anonymous rather
+			// than failing pages that do not need a user.
+			logctx.Logger(r.Context(), s.logger).Error("Failed to authenticate session",
+				zap.String("operation", "Authenticate"),
+				zap.Error(err),
+			)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serveAPIToken serves r as the user of an API token.
+func (s *Service) serveAPIToken(w http.ResponseWriter, r *http.Request, next http.Handler, token string) {
+	msg := i18n.FromContext(r.Context())
+	ip := s.ClientIP(r)
+	if err := s.checkLockout("", ip); err != nil {
+		WriteLockout(w, r, err)
+		return
+	}
+	u, t, err := s.AuthenticateAPIToken(r.Context(), token)
+	switch {
+	case errors.Is(err, ErrBadAPIToken):
+		s.recordFailure("AuthenticateAPIToken", "", ip)
+		w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+		httpx.WriteError(w, r, http.StatusUnauthorized, httpx.CodeUnauthorized, msg.T(i18n.MsgUnauthorized), nil)
+		return
+	case err != nil:
+		logctx.Logger(r.Context(), s.logger).Error("Failed to authenticate API token",
+			zap.String("operation", "AuthenticateAPIToken"),
+			zap.Error(err),
+		)
+		httpx.WriteError(w, r, http.StatusInternalServerError, httpx.CodeInternal, msg.T(i18n.MsgInternalError), nil)
+		return
+	}
+	if !TokenAllows(*t, r.Method, r.URL.Path) {
+		httpx.WriteError(w, r, http.StatusForbidden, httpx.CodeForbidden, msg.T(i18n.MsgTokenScope), nil)
+		return
+	}
+	ctx := context.WithValue(context.WithValue(r.Context(), userKey{}, u), tokenKey{}, t)
+	next.ServeHTTP(w, r.WithContext(httpx.WithPrincipal(ctx, u.ID)))
+}
+
+// WriteLockout responds 429 to a request refused with a *LockoutError.
+func WriteLockout(w http.ResponseWriter, r *http.Request, err error) {
+	var lockout *LockoutError
+	if errors.As(err, &lockout) {
+		w.Header().Set("Retry-After", strconv.Itoa(max(1, int(math.Ceil(lockout.RetryAfter.Seconds())))))
+	}
+	httpx.WriteError(w, r, http.StatusTooManyRequests, httpx.CodeRateLimitExceeded, err.Error(), nil)
+}
+
+// RequireUser responds 401 unless Handler attached a user to the request.
+func RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if UserFromContext(r.Context()) == nil {
+			httpx.WriteError(w, r, http.StatusUnauthorized, httpx.CodeUnauthorized,
+				i18n.FromContext(r.Context()).T(i18n.MsgSignInRequired), nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestService_Handler(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_Handler", "internal/auth")
+
+	svc, _ := newTestService(t)
+	u, _ := svc.Register(t.Context(), "asha@example.com", "test-only-password", "")
+	_, token, err := svc.Login(t.Context(), "asha@example.com", "test-only-password", SessionMeta{})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	h := svc.Handler(RequireUser(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(UserFromContext(r.Context()).ID + " " + httpx.Principal(r.Context())))
+	})))
+
+	testCases := []struct {
+		name        string
+		cookie      string
+		wantStatus  int
+		wantBody    string
+		wantCleared bool
+	}{
+		{"Valid session", token, http.StatusOK, u.ID + " " + u.ID, false},
+		{"No cookie", "", http.StatusUnauthorized, "", false},
+		{"Unknown session", "forged", http.StatusUnauthorized, "", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/me", nil)
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "session", Value: tc.cookie})
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantStatus, rec.Code)
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("Status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			if tc.wantBody != "" && rec.Body.String() != tc.wantBody {
+				t.Errorf("Body = %q, want %q", rec.Body.String(), tc.wantBody)
+			}
+			cleared := len(rec.Result().Cookies()) == 1 && rec.Result().Cookies()[0].MaxAge < 0
+			if cleared != tc.wantCleared {
+				t.Errorf("Cookie cleared = %v, want %v", cleared, tc.wantCleared)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_Handler", true)
+}
+
+func TestService_SetCookie(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_SetCookie", "internal/auth")
+
+	svc, _ := newTestService(t)
+	rec := httptest.NewRecorder()
+	svc.SetCookie(rec, "token")
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Set %d cookies, want 1", len(cookies))
+	}
+	c := cookies[0]
+	testhelpers.LogTestAssertion(logger, "cookie", "session", c.Name)
+	if c.Name != "session" || !c.HttpOnly || !c.Secure || c.SameSite != http.SameSiteLaxMode || c.Path != "/" {
+		t.Errorf("Cookie = %+v", c)
+	}
+	if c.MaxAge != int(svc.cfg.SessionTTL.Seconds()) {
+		t.Errorf("MaxAge = %d, want the session TTL", c.MaxAge)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_SetCookie", true)
+}
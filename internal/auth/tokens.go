@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// APITokenPrefix starts every personal API token, telling them apart from
+// other bearer tokens and making leaked ones easy to scan for.
+const APITokenPrefix = "wpc_"
+
+// ErrBadAPIToken is returned for an unknown, revoked or expired API token.
+var ErrBadAPIToken = errors.New("invalid API token")
+
+// errAPITokensDisabled is returned by the token methods without an
+// APITokenRepository.
+var errAPITokensDisabled = fmt.Errorf("API tokens are not enabled: %w", domain.ErrNotFound)
+
+const (
+	maxAPITokenName = 100 // characters
+	// apiTokenTouchInterval bounds how often a token's last use is saved.
+	apiTokenTouchInterval = time.Minute
+)
+
+// tokenForbiddenPaths are never reachable with an API token, so a leaked
+// one cannot take over or erase the account, or mint more tokens.
+var tokenForbiddenPaths = []string{"/api/v1/auth", "/api/v1/tokens", "/api/v1/account"}
+
+// APITokensEnabled reports whether users can create API tokens.
+func (s *Service) APITokensEnabled() bool { return s.repos.APITokens != nil }
+
+// CreateAPIToken issues userID a token with scopes, valid for ttl or until
+// revoked if ttl is zero. The token itself is only ever returned here.
+func (s *Service) CreateAPIToken(ctx context.Context, userID, name string, scopes []string, ttl time.Duration) (*domain.APIToken, string, error) {
+	if !s.APITokensEnabled() {
+		return nil, "", errAPITokensDisabled
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxAPITokenName {
+		return nil, "", fmt.Errorf("token name must be 1 to %d characters: %w", maxAPITokenName, domain.ErrInvalid)
+	}
+	if len(scopes) == 0 {
+		return nil, "", fmt.Errorf("token needs at least one scope: %w", domain.ErrInvalid)
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(domain.APITokenScopes, scope) {
+			return nil, "", fmt.Errorf("unknown scope %q, want one of %s: %w",
+				scope, strings.Join(domain.APITokenScopes, ", "), domain.ErrInvalid)
+		}
+	}
+	if ttl < 0 {
+		return nil, "", fmt.Errorf("token lifetime cannot be negative: %w", domain.ErrInvalid)
+	}
+	existing, err := s.repos.APITokens.UserAPITokens(ctx, userID)
+	if err != nil {
+		return nil, "", fmt.Errorf("list api tokens: %w", err)
+	}
+	if len(existing) >= s.cfg.MaxAPITokens {
+		return nil, "", fmt.Errorf("at most %d API tokens, revoke one first: %w", s.cfg.MaxAPITokens, domain.ErrConflict)
+	}
+
+	token := APITokenPrefix + rand.Text()
+	now := s.now().UTC()
+	t := domain.APIToken{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    token[:len(APITokenPrefix)+4],
+		TokenHash: hashToken(token),
+		Scopes:    slices.Compact(slices.Sorted(slices.Values(scopes))),
+		CreatedAt: now,
+	}
+	if ttl > 0 {
+		expires := now.Add(ttl)
+		t.ExpiresAt = &expires
+	}
+	t, err = s.repos.APITokens.CreateAPIToken(ctx, t)
+	if err != nil {
+		return nil, "", fmt.Errorf("create api token: %w", err)
+	}
+	s.logger.Info("API token created",
+		zap.String("operation", "CreateAPIToken"),
+		zap.String("user_id", userID),
+		zap.String("token_id", t.ID),
+		zap.Strings("scopes", t.Scopes),
+	)
+	return &t, token, nil
+}
+
+// APITokens returns userID's tokens, oldest first.
+func (s *Service) APITokens(ctx context.Context, userID string) ([]domain.APIToken, error) {
+	if !s.APITokensEnabled() {
+		return nil, errAPITokensDisabled
+	}
+	return s.repos.APITokens.UserAPITokens(ctx, userID)
+}
+
+// RevokeAPIToken deletes userID's token with id.
+func (s *Service) RevokeAPIToken(ctx context.Context, userID, id string) error {
+	if !s.APITokensEnabled() {
+		return errAPITokensDisabled
+	}
+	if err := s.repos.APITokens.DeleteAPIToken(ctx, userID, id); err != nil {
+		return err
+	}
+	s.logger.Info("API token revoked",
+		zap.String("operation", "RevokeAPIToken"),
+		zap.String("user_id", userID),
+		zap.String("token_id", id),
+	)
+	return nil
+}
+
+// AuthenticateAPIToken returns the user and token for an API token, or
+// ErrBadAPIToken, and records its use.
+func (s *Service) AuthenticateAPIToken(ctx context.Context, token string) (*domain.User, *domain.APIToken, error) {
+	if !s.APITokensEnabled() || !strings.HasPrefix(token, APITokenPrefix) {
+		return nil, nil, ErrBadAPIToken
+	}
+	t, err := s.repos.APITokens.APITokenByHash(ctx, hashToken(token))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil, ErrBadAPIToken
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("load api token: %w", err)
+	}
+	now := s.now().UTC()
+	if t.ExpiresAt != nil && !now.Before(*t.ExpiresAt) {
+		return nil, nil, ErrBadAPIToken
+	}
+	u, err := s.repos.Users.UserByID(ctx, t.UserID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil, ErrBadAPIToken
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("load user: %w", err)
+	}
+	if t.LastUsedAt == nil || now.Sub(*t.LastUsedAt) >= apiTokenTouchInterval {
+		// Last use is informational; failing to save it fails no request.
+		if err := s.repos.APITokens.TouchAPIToken(ctx, t.ID, now); err != nil {
+			s.logger.Warn("Recording API token use failed",
+				zap.String("operation", "AuthenticateAPIToken"),
+				zap.String("token_id", t.ID),
+				zap.Error(err),
+			)
+		}
+		t.LastUsedAt = &now
+	}
+	return u, t, nil
+}
+
+// TokenAllows reports whether t may make a method request to urlPath.
+// ScopeRead allows safe requests; ScopeAlerts allows any request to the
+// alert routes. Account and token routes are always refused.
+func TokenAllows(t domain.APIToken, method, urlPath string) bool {
+	p := path.Clean("/" + urlPath)
+	for _, forbidden := range tokenForbiddenPaths {
+		if p == forbidden || strings.HasPrefix(p, forbidden+"/") {
+			return false
+		}
+	}
+	safe := method == http.MethodGet || method == http.MethodHead
+	if p == "/api/v1/alerts" || strings.HasPrefix(p, "/api/v1/alerts/") {
+		return t.HasScope(domain.ScopeAlerts) || safe && t.HasScope(domain.ScopeRead)
+	}
+	return safe && t.HasScope(domain.ScopeRead)
+}
+
+// bearerAPIToken returns the API token in r's Authorization header. Other
+// bearer tokens, such as the admin API's, are left alone.
+func bearerAPIToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	token = strings.TrimSpace(token)
+	if !ok || !strings.EqualFold(scheme, "Bearer") || !strings.HasPrefix(token, APITokenPrefix) {
+		return "", false
+	}
+	return token, true
+}
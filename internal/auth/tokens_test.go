@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestService_APITokenLifecycle(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_APITokenLifecycle", "internal/auth")
+
+	svc, _ := newTestService(t)
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := t.Context()
+	u, _ := svc.Register(ctx, "asha@example.com", "test-only-password", "")
+
+	testhelpers.LogTestStep(logger, "act", "Creating tokens with invalid and valid settings")
+	svc.cfg.MaxAPITokens = 2
+	testCases := []struct {
+		name    string
+		tname   string
+		scopes  []string
+		ttl     time.Duration
+		wantErr error
+	}{
+		{"No name", " ", []string{domain.ScopeRead}, 0, domain.ErrInvalid},
+		{"No scopes", "ci", nil, 0, domain.ErrInvalid},
+		{"Unknown scope", "ci", []string{"admin"}, 0, domain.ErrInvalid},
+		{"Negative lifetime", "ci", []string{domain.ScopeRead}, -time.Hour, domain.ErrInvalid},
+		{"Read-only", "dashboard", []string{domain.ScopeRead, domain.ScopeRead}, 0, nil},
+		{"Expiring alerts token", "script", []string{domain.ScopeAlerts}, time.Hour, nil},
+		{"Over the limit", "third", []string{domain.ScopeRead}, 0, domain.ErrConflict},
+	}
+	tokens := map[string]string{}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			created, token, err := svc.CreateAPIToken(ctx, u.ID, tc.tname, tc.scopes, tc.ttl)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("CreateAPIToken error = %v, want %v", err, tc.wantErr)
+			}
+			if err == nil {
+				if !strings.HasPrefix(token, APITokenPrefix) || !strings.HasPrefix(token, created.Prefix) || created.TokenHash == token {
+					t.Errorf("Token %q, created %+v", token, created)
+				}
+				tokens[tc.tname] = token
+			}
+		})
+	}
+	list, _ := svc.APITokens(ctx, u.ID)
+	if len(list) != 2 || len(list[0].Scopes) != 1 || list[1].ExpiresAt == nil {
+		t.Fatalf("APITokens = %+v", list)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Using tokens records their last use, at most once a minute")
+	got, tok, err := svc.AuthenticateAPIToken(ctx, tokens["dashboard"])
+	if err != nil || got.ID != u.ID || tok.LastUsedAt == nil || !tok.LastUsedAt.Equal(now) {
+		t.Fatalf("AuthenticateAPIToken = %+v, %+v, %v", got, tok, err)
+	}
+	now = now.Add(30 * time.Second)
+	_, _, _ = svc.AuthenticateAPIToken(ctx, tokens["dashboard"])
+	if list, _ := svc.APITokens(ctx, u.ID); !list[0].LastUsedAt.Equal(now.Add(-30 * time.Second)) {
+		t.Errorf("LastUsedAt after a quick reuse = %v", list[0].LastUsedAt)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Unknown, expired and revoked tokens are refused")
+	now = now.Add(time.Hour)
+	if _, _, err := svc.AuthenticateAPIToken(ctx, tokens["script"]); !errors.Is(err, ErrBadAPIToken) {
+		t.Errorf("Expired token error = %v, want ErrBadAPIToken", err)
+	}
+	if _, _, err := svc.AuthenticateAPIToken(ctx, APITokenPrefix+"forged"); !errors.Is(err, ErrBadAPIToken) {
+		t.Errorf("Unknown token error = %v, want ErrBadAPIToken", err)
+	}
+	if err := svc.RevokeAPIToken(ctx, "user_other", list[0].ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Revoking another user's token error = %v, want ErrNotFound", err)
+	}
+	if err := svc.RevokeAPIToken(ctx, u.ID, list[0].ID); err != nil {
+		t.Fatalf("RevokeAPIToken: %v", err)
+	}
+	if _, _, err := svc.AuthenticateAPIToken(ctx, tokens["dashboard"]); !errors.Is(err, ErrBadAPIToken) {
+		t.Errorf("Revoked token error = %v, want ErrBadAPIToken", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_APITokenLifecycle", true)
+}
+
+func TestTokenAllows(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestTokenAllows", "internal/auth")
+
+	read := domain.APIToken{Scopes: []string{domain.ScopeRead}}
+	alerts := domain.APIToken{Scopes: []string{domain.ScopeAlerts}}
+	testCases := []struct {
+		name   string
+		token  domain.APIToken
+		method string
+		path   string
+		want   bool
+	}{
+		{"Read a product", read, http.MethodGet, "/api/v1/products/prod_1", true},
+		{"Read alerts", read, http.MethodGet, "/api/v1/alerts", true},
+		{"Read-only create alert", read, http.MethodPost, "/api/v1/alerts", false},
+		{"Read-only other write", read, http.MethodPut, "/api/v1/watchlist/prod_1", false},
+		{"Create alert", alerts, http.MethodPost, "/api/v1/alerts", true},
+		{"Delete alert", alerts, http.MethodDelete, "/api/v1/alerts/alert_1", true},
+		{"Alerts token reading elsewhere", alerts, http.MethodGet, "/api/v1/products", false},
+		{"Alerts token batch", alerts, http.MethodPost, "/api/v1/batch", false},
+		{"Account routes", read, http.MethodGet, "/api/v1/auth/me", false},
+		{"Token routes", alerts, http.MethodGet, "/api/v1/tokens", false},
+		{"Data rights routes", read, http.MethodGet, "/api/v1/account/export/datareq_1", false},
+		{"Dot segments", alerts, http.MethodPost, "/api/v1/alerts/../tokens", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := TokenAllows(tc.token, tc.method, tc.path)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.want, got)
+			if got != tc.want {
+				t.Errorf("TokenAllows(%s %s) = %v, want %v", tc.method, tc.path, got, tc.want)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestTokenAllows", true)
+}
+
+func TestService_HandlerAPIToken(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_HandlerAPIToken", "internal/auth")
+
+	svc, _ := newTestService(t)
+	ctx := t.Context()
+	u, _ := svc.Register(ctx, "asha@example.com", "test-only-password", "")
+	_, token, err := svc.CreateAPIToken(ctx, u.ID, "dashboard", []string{domain.ScopeRead}, 0)
+	if err != nil {
+		t.Fatalf("CreateAPIToken: %v", err)
+	}
+	h := svc.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u := UserFromContext(r.Context()); u != nil {
+			_, _ = w.Write([]byte(u.ID))
+		}
+	}))
+
+	testCases := []struct {
+		name       string
+		method     string
+		auth       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"Valid token", http.MethodGet, "Bearer " + token, http.StatusOK, u.ID},
+		{"Outside scope", http.MethodPost, "Bearer " + token, http.StatusForbidden, ""},
+		{"Invalid token", http.MethodGet, "Bearer " + APITokenPrefix + "forged", http.StatusUnauthorized, ""},
+		{"Other bearer tokens pass through", http.MethodGet, "Bearer admin-token", http.StatusOK, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/api/v1/products", nil)
+			req.Header.Set("Authorization", tc.auth)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantStatus, rec.Code)
+			if rec.Code != tc.wantStatus || tc.wantStatus == http.StatusOK && rec.Body.String() != tc.wantBody {
+				t.Errorf("Status = %d, body %q; want %d, %q", rec.Code, rec.Body, tc.wantStatus, tc.wantBody)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_HandlerAPIToken", true)
+}
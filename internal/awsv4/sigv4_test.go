@@ -0,0 +1,47 @@
+package awsv4
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// The credentials and expected signatures are from the AWS Signature
+// Version 4 test suite, which uses a published example key.
+var suiteCreds = Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+
+func TestSign_TestSuite(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSign_TestSuite", "internal/awsv4")
+
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	testCases := []struct {
+		name      string
+		method    string
+		target    string
+		signature string
+	}{
+		{"get-vanilla", http.MethodGet, "https://example.amazonaws.com/", "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"},
+		{"get-vanilla-query-order-key-case", http.MethodGet, "https://example.amazonaws.com/?Param2=value2&Param1=value1", "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500"},
+		{"post-vanilla", http.MethodPost, "https://example.amazonaws.com/", "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tc.method, tc.target, nil)
+			Sign(req, PayloadHash(nil), suiteCreds, "us-east-1", "service", now)
+			auth := req.Header.Get("Authorization")
+			testhelpers.LogTestAssertion(logger, tc.name, tc.signature, auth)
+			if !strings.HasSuffix(auth, "Signature="+tc.signature) {
+				t.Errorf("Authorization = %q, want signature %s", auth, tc.signature)
+			}
+			if !strings.Contains(auth, "Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date,") {
+				t.Errorf("Authorization = %q, wrong credential scope or signed headers", auth)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSign_TestSuite", true)
+}
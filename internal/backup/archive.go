@@ -0,0 +1,227 @@
+// Package backup dumps the catalog and recent price history to a portable
+// archive and restores it, for self-hosters to recover from.
+//
+// An archive is gzipped JSON lines: a Header, then for each table a
+// TableHeader followed by its rows as JSON arrays, then an end marker
+// that tells a complete archive from a truncated one.
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Format and Version identify the archive format.
+const (
+	Format  = "whey-price-compare/backup"
+	Version = 1
+)
+
+// ErrTruncated is returned when an archive ends before its end marker.
+var ErrTruncated = errors.New("backup archive is truncated")
+
+// Header opens an archive.
+type Header struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	// Dialect is the database the archive was taken from; it restores
+	// into the same kind only.
+	Dialect string `json:"dialect"`
+	// SchemaVersion is the last migration applied to a Postgres source.
+	SchemaVersion int `json:"schema_version,omitempty"`
+	// HistorySince is the oldest price history kept; zero means all.
+	HistorySince time.Time `json:"history_since,omitzero"`
+	// Accounts reports the archive holds users and their sessions, API
+	// tokens and alerts; older archives hold the catalog only.
+	Accounts bool `json:"accounts,omitempty"`
+}
+
+// Column kinds that JSON does not carry.
+const (
+	kindTime  = "time"
+	kindBytes = "bytes"
+)
+
+// TableHeader starts a table's rows.
+type TableHeader struct {
+	Table   string   `json:"table"`
+	Columns []string `json:"columns"`
+	// Kinds gives each column's kind where JSON loses it: "time" for
+	// times, written as RFC 3339 text, and "bytes" for binary, written as
+	// base64. Other columns are "".
+	Kinds []string `json:"kinds"`
+}
+
+type trailer struct {
+	End  bool `json:"end"`
+	Rows int  `json:"rows"`
+}
+
+// Writer writes an archive.
+type Writer struct {
+	gz   *gzip.Writer
+	buf  *bufio.Writer
+	enc  *json.Encoder
+	rows int
+}
+
+// NewWriter starts an archive on w with h, filling in its format and
+// version.
+func NewWriter(w io.Writer, h Header) (*Writer, error) {
+	gz := gzip.NewWriter(w)
+	buf := bufio.NewWriter(gz)
+	aw := &Writer{gz: gz, buf: buf, enc: json.NewEncoder(buf)}
+	h.Format, h.Version = Format, Version
+	if err := aw.enc.Encode(h); err != nil {
+		return nil, fmt.Errorf("write backup header: %w", err)
+	}
+	return aw, nil
+}
+
+// Table starts a table.
+func (w *Writer) Table(t TableHeader) error {
+	if err := w.enc.Encode(t); err != nil {
+		return fmt.Errorf("write backup table %s: %w", t.Table, err)
+	}
+	return nil
+}
+
+// Row writes one row of the current table.
+func (w *Writer) Row(values []any) error {
+	w.rows++
+	return w.enc.Encode(values)
+}
+
+// Close writes the end marker and flushes the archive. It does not close
+// the underlying writer.
+func (w *Writer) Close() error {
+	if err := w.enc.Encode(trailer{End: true, Rows: w.rows}); err != nil {
+		return fmt.Errorf("write backup trailer: %w", err)
+	}
+	if err := w.buf.Flush(); err != nil {
+		return fmt.Errorf("write backup: %w", err)
+	}
+	return w.gz.Close()
+}
+
+// Reader reads an archive.
+type Reader struct {
+	dec   *json.Decoder
+	table *TableHeader
+	rows  int
+	done  bool
+}
+
+// NewReader opens an archive, returning its header.
+func NewReader(r io.Reader) (*Reader, Header, error) {
+	var h Header
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, h, fmt.Errorf("open backup archive: %w", err)
+	}
+	dec := json.NewDecoder(gz)
+	dec.UseNumber()
+	if err := dec.Decode(&h); err != nil {
+		return nil, h, fmt.Errorf("read backup header: %w", err)
+	}
+	if h.Format != Format {
+		return nil, h, fmt.Errorf("not a backup archive (format %q)", h.Format)
+	}
+	if h.Version != Version {
+		return nil, h, fmt.Errorf("unsupported backup archive version %d", h.Version)
+	}
+	return &Reader{dec: dec}, h, nil
+}
+
+// Next returns the next row and the table it belongs to. Times and binary
+// are decoded as their kinds say and whole numbers as int64. At the end
+// marker it returns io.EOF; if the archive ends before it, ErrTruncated.
+func (r *Reader) Next() (*TableHeader, []any, error) {
+	for {
+		if r.done {
+			return nil, nil, io.EOF
+		}
+		var line json.RawMessage
+		if err := r.dec.Decode(&line); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil, nil, ErrTruncated
+			}
+			return nil, nil, fmt.Errorf("read backup archive: %w", err)
+		}
+		if len(line) > 0 && line[0] == '[' {
+			if r.table == nil {
+				return nil, nil, errors.New("backup archive has a row before any table")
+			}
+			row, err := r.row(line)
+			if err != nil {
+				return nil, nil, err
+			}
+			r.rows++
+			return r.table, row, nil
+		}
+
+		var t struct {
+			TableHeader
+			trailer
+		}
+		if err := json.Unmarshal(line, &t); err != nil {
+			return nil, nil, fmt.Errorf("read backup archive: %w", err)
+		}
+		switch {
+		case t.End:
+			if t.Rows != r.rows {
+				return nil, nil, fmt.Errorf("backup archive has %d rows, its trailer says %d", r.rows, t.Rows)
+			}
+			r.done = true
+		case t.Table != "" && len(t.Kinds) == len(t.Columns):
+			r.table = &t.TableHeader
+		default:
+			return nil, nil, fmt.Errorf("backup archive has an unexpected line %.80s", line)
+		}
+	}
+}
+
+func (r *Reader) row(line json.RawMessage) ([]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var row []any
+	if err := dec.Decode(&row); err != nil {
+		return nil, fmt.Errorf("read backup %s row: %w", r.table.Table, err)
+	}
+	if len(row) != len(r.table.Columns) {
+		return nil, fmt.Errorf("backup %s row has %d values for %d columns", r.table.Table, len(row), len(r.table.Columns))
+	}
+	for i, v := range row {
+		switch v := v.(type) {
+		case json.Number:
+			if n, err := v.Int64(); err == nil {
+				row[i] = n
+			} else if f, err := v.Float64(); err == nil {
+				row[i] = f
+			}
+		case string:
+			switch r.table.Kinds[i] {
+			case kindTime:
+				// Text the source database did not parse stays text.
+				if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+					row[i] = t
+				}
+			case kindBytes:
+				b, err := base64.StdEncoding.DecodeString(v)
+				if err != nil {
+					return nil, fmt.Errorf("backup %s.%s: %w", r.table.Table, r.table.Columns[i], err)
+				}
+				row[i] = b
+			}
+		}
+	}
+	return row, nil
+}
@@ -0,0 +1,101 @@
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestArchive_RoundTrip(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestArchive_RoundTrip", "internal/backup")
+
+	testhelpers.LogTestStep(logger, "arrange", "An archive of one table with every kind of value")
+	at := time.Date(2026, 10, 16, 9, 30, 0, 123000000, time.UTC)
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, Header{CreatedAt: at, Dialect: "sqlite", HistorySince: at.AddDate(0, 0, -90)})
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	table := TableHeader{
+		Table:   "price_history",
+		Columns: []string{"id", "price", "servings", "in_stock", "recorded_at", "raw", "previous_price"},
+		Kinds:   []string{"", "", "", "", kindTime, kindBytes, ""},
+	}
+	rows := [][]any{
+		{"ph_1", 3199.5, int64(33), true, at, []byte{0, 1, 2}, nil},
+		{"ph_2", 2999.0, int64(0), false, "not a time", []byte{}, 3199.5},
+	}
+	if err := w.Table(table); err != nil {
+		t.Fatalf("Table: %v", err)
+	}
+	for _, row := range rows {
+		if err := w.Row(row); err != nil {
+			t.Fatalf("Row: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Reading it back")
+	r, h, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	var got [][]any
+	for {
+		th, row, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		if th.Table != "price_history" {
+			t.Errorf("Table = %q", th.Table)
+		}
+		got = append(got, row)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Header and values come back with their types")
+	if h.Format != Format || h.Version != Version || h.Dialect != "sqlite" || !h.CreatedAt.Equal(at) {
+		t.Errorf("Header = %+v", h)
+	}
+	// 2999.0 is written as 2999, so it comes back whole.
+	rows[1][1] = int64(2999)
+	testhelpers.LogTestAssertion(logger, "rows", rows, got)
+	if !reflect.DeepEqual(got, rows) {
+		t.Errorf("rows = %#v, want %#v", got, rows)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Reading an archive whose writer stopped before the end marker, and something else")
+	var short bytes.Buffer
+	w, _ = NewWriter(&short, Header{CreatedAt: at, Dialect: "sqlite"})
+	_ = w.Table(table)
+	_ = w.Row(rows[0])
+	_ = w.buf.Flush()
+	_ = w.gz.Close()
+	r, _, err = NewReader(&short)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	if _, _, err := r.Next(); err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	_, _, err = r.Next()
+	testhelpers.LogTestAssertion(logger, "truncated", ErrTruncated, err)
+	if !errors.Is(err, ErrTruncated) {
+		t.Errorf("Next at the cut = %v, want ErrTruncated", err)
+	}
+	if _, _, err := NewReader(bytes.NewReader([]byte("not gzip"))); err == nil {
+		t.Error("NewReader accepted a file that is not an archive")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestArchive_RoundTrip", true)
+}
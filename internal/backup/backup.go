@@ -0,0 +1,407 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/database"
+)
+
+// ErrCatalogNotEmpty is returned by Restore when the database already has
+// products and it was not asked to replace them.
+var ErrCatalogNotEmpty = errors.New("catalog already has products")
+
+// ErrAccountsNotEmpty is returned by Restore when an archive holds
+// accounts, the database already has some and it was not asked to replace
+// them.
+var ErrAccountsNotEmpty = errors.New("database already has accounts")
+
+// table is one table an archive holds, in the order they are dumped and
+// restored: parents before children.
+type table struct {
+	name string
+	// order sorts the dump; categories put parents first.
+	order string
+	// matchBy names the unique column by which a restore matches rows the
+	// database already has, such as the brands, categories and retailers
+	// its migrations insert. Matched rows are kept and references to the
+	// archive's row are pointed at them.
+	matchBy string
+	// refs maps columns to the table whose matched rows they reference.
+	refs map[string]string
+	// skip lists columns the database maintains itself.
+	skip []string
+	// history marks price history, which a dump can limit to recent rows.
+	history bool
+	// account marks the tables of users' accounts.
+	account bool
+	// sealed lists columns of sealed personal data, binary even where the
+	// SQLite schema declares them text.
+	sealed []string
+}
+
+var tables = []table{
+	{name: "brands", order: "id", matchBy: "slug"},
+	{name: "categories", order: "parent_id IS NOT NULL, id", matchBy: "slug", refs: map[string]string{"parent_id": "categories"}},
+	{name: "retailers", order: "id", matchBy: "slug"},
+	{name: "products", order: "id", refs: map[string]string{"brand_id": "brands", "category_id": "categories"}, skip: []string{"search_vector"}},
+	{name: "product_variants", order: "id"},
+	{name: "product_listings", order: "id", refs: map[string]string{"retailer_id": "retailers"}},
+	{name: "price_history", order: "recorded_at, id", history: true},
+	{name: "search_synonyms", order: "id"},
+	{name: "users", order: "id", account: true, sealed: []string{"email_encrypted", "name_encrypted"}},
+	{name: "user_passwords", order: "id", account: true},
+	{name: "user_oauth_providers", order: "id", account: true},
+	{name: "email_verification_tokens", order: "id", account: true, sealed: []string{"email_to_verify"}},
+	{name: "user_sessions", order: "id", account: true},
+	{name: "api_keys", order: "id", account: true},
+	{name: "price_alerts", order: "id", account: true},
+}
+
+// replaced are the tables Restore empties, children first. Brands,
+// categories and retailers stay, as other tables reference them. The
+// scraping queue holds listings without cascading, and is refilled by the
+// scheduler.
+var replaced = []string{"scraping_queue", "price_history", "product_listings", "product_variants", "products", "search_synonyms"}
+
+// replacedAccounts are the account tables Restore empties, children first,
+// when the archive holds accounts.
+var replacedAccounts = []string{"price_alerts", "api_keys", "user_sessions", "email_verification_tokens",
+	"user_oauth_providers", "user_passwords", "users"}
+
+// Options configure Dump.
+type Options struct {
+	// HistoryDays limits the price history to this many days back; 0
+	// keeps all of it.
+	HistoryDays int
+	// SchemaVersion is recorded in the header.
+	SchemaVersion int
+	// Now is when the archive is taken; zero means the current time.
+	Now time.Time
+}
+
+// Stats counts the rows a dump or restore handled, by table.
+type Stats map[string]int
+
+// Total returns the rows across every table.
+func (s Stats) Total() int {
+	n := 0
+	for _, rows := range s {
+		n += rows
+	}
+	return n
+}
+
+// Dump writes the catalog, price history and accounts in db, a database
+// of dialect d, to w as an archive. It reads in one transaction, so the
+// archive is a consistent snapshot. Personal data stays sealed with the
+// key it was written with, which a database restored from the archive
+// must be read with too.
+func Dump(ctx context.Context, db *sql.DB, d database.Dialect, w io.Writer, opts Options) (Stats, error) {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	h := Header{CreatedAt: now.UTC(), Dialect: d.String(), SchemaVersion: opts.SchemaVersion, Accounts: true}
+	if opts.HistoryDays > 0 {
+		h.HistorySince = now.UTC().AddDate(0, 0, -opts.HistoryDays)
+	}
+
+	// SQLite transactions are snapshots already and take no options.
+	var txOpts *sql.TxOptions
+	if d == database.Postgres {
+		txOpts = &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	}
+	tx, err := db.BeginTx(ctx, txOpts)
+	if err != nil {
+		return nil, fmt.Errorf("dump: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	aw, err := NewWriter(w, h)
+	if err != nil {
+		return nil, err
+	}
+	stats := make(Stats)
+	for _, t := range tables {
+		b := d.Build().Append("SELECT * FROM " + t.name)
+		if t.history && !h.HistorySince.IsZero() {
+			b.Append(" WHERE recorded_at >= ?", h.HistorySince)
+		}
+		query, args := b.Append(" ORDER BY " + t.order).Query()
+		n, err := dumpTable(ctx, tx, t, query, args, aw)
+		if err != nil {
+			return nil, err
+		}
+		stats[t.name] = n
+	}
+	if err := aw.Close(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func dumpTable(ctx context.Context, tx *sql.Tx, t table, query string, args []any, aw *Writer) (int, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("dump %s: %w", t.name, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, fmt.Errorf("dump %s: %w", t.name, err)
+	}
+	header := TableHeader{Table: t.name}
+	var keep []int
+	for i, ct := range types {
+		if slices.Contains(t.skip, ct.Name()) {
+			continue
+		}
+		kind := columnKind(ct.DatabaseTypeName())
+		if slices.Contains(t.sealed, ct.Name()) {
+			kind = kindBytes
+		}
+		keep = append(keep, i)
+		header.Columns = append(header.Columns, ct.Name())
+		header.Kinds = append(header.Kinds, kind)
+	}
+	if err := aw.Table(header); err != nil {
+		return 0, err
+	}
+
+	values := make([]any, len(types))
+	ptrs := make([]any, len(types))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	row := make([]any, len(keep))
+	n := 0
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return n, fmt.Errorf("dump %s: %w", t.name, err)
+		}
+		for i, col := range keep {
+			row[i] = values[col]
+			if s, ok := row[i].(string); ok && header.Kinds[i] == kindBytes {
+				row[i] = []byte(s)
+			}
+		}
+		if err := aw.Row(row); err != nil {
+			return n, fmt.Errorf("dump %s: %w", t.name, err)
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return n, fmt.Errorf("dump %s: %w", t.name, err)
+	}
+	return n, nil
+}
+
+// columnKind classifies a column by its database type name.
+func columnKind(typeName string) string {
+	typeName = strings.ToUpper(typeName)
+	switch {
+	case strings.Contains(typeName, "TIME") || typeName == "DATE":
+		return kindTime
+	case typeName == "BYTEA" || typeName == "BLOB":
+		return kindBytes
+	default:
+		return ""
+	}
+}
+
+// identifier is what Restore accepts as a column name from an archive,
+// since names are written into its statements.
+var identifier = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// Restore loads an archive from r into db, a database of dialect d, in one
+// transaction. Brands, categories and retailers already there are kept and
+// matched by slug. With replace, the products, variants, listings, price
+// history and synonyms already there are deleted first, and the accounts
+// too if the archive holds them; without it, Restore refuses to add to a
+// catalog that has products, or accounts to a database that has them.
+func Restore(ctx context.Context, db *sql.DB, d database.Dialect, r io.Reader, replace bool) (Stats, error) {
+	ar, h, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	if h.Dialect != d.String() {
+		return nil, fmt.Errorf("restore: the archive is from %s, not %s", h.Dialect, d)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("restore: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if replace {
+		clear := replaced
+		if h.Accounts {
+			clear = append(slices.Clone(replacedAccounts), replaced...)
+		}
+		for _, name := range clear {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM "+name); err != nil {
+				return nil, fmt.Errorf("restore: clear %s: %w", name, err)
+			}
+		}
+	} else {
+		var n int
+		if err := tx.QueryRowContext(ctx, "SELECT count(*) FROM products").Scan(&n); err != nil {
+			return nil, fmt.Errorf("restore: %w", err)
+		}
+		if n > 0 {
+			return nil, fmt.Errorf("restore: %w (%d)", ErrCatalogNotEmpty, n)
+		}
+		if h.Accounts {
+			if err := tx.QueryRowContext(ctx, "SELECT count(*) FROM users").Scan(&n); err != nil {
+				return nil, fmt.Errorf("restore: %w", err)
+			}
+			if n > 0 {
+				return nil, fmt.Errorf("restore: %w (%d)", ErrAccountsNotEmpty, n)
+			}
+		}
+	}
+
+	rs := &restorer{ctx: ctx, tx: tx, d: d, ids: make(map[string]map[string]string), stats: make(Stats)}
+	for {
+		th, row, err := ar.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("restore: %w", err)
+		}
+		if err := rs.add(th, row); err != nil {
+			return nil, err
+		}
+	}
+	if err := rs.flush(); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("restore: %w", err)
+	}
+	return rs.stats, nil
+}
+
+// restorer batches an archive's rows into multi-row inserts.
+type restorer struct {
+	ctx context.Context
+	tx  *sql.Tx
+	d   database.Dialect
+
+	current *TableHeader
+	spec    table
+	// existing maps the matchBy values of the current table's rows
+	// already in the database to their IDs.
+	existing map[string]string
+	batch    []any
+	rows     int
+	// ids maps, by table, the archive's IDs of matched rows to the
+	// database's.
+	ids   map[string]map[string]string
+	stats Stats
+}
+
+func (rs *restorer) add(th *TableHeader, row []any) error {
+	if th != rs.current {
+		if err := rs.start(th); err != nil {
+			return err
+		}
+	}
+	t := rs.spec
+	for i, col := range th.Columns {
+		if ref, ok := t.refs[col]; ok {
+			if id, ok := row[i].(string); ok {
+				if have, ok := rs.ids[ref][id]; ok {
+					row[i] = have
+				}
+			}
+		}
+	}
+	if t.matchBy != "" {
+		id, _ := row[slices.Index(th.Columns, "id")].(string)
+		key, _ := row[slices.Index(th.Columns, t.matchBy)].(string)
+		have, ok := rs.existing[key]
+		if !ok {
+			have, ok = rs.existing["id:"+id]
+		}
+		if ok {
+			rs.ids[t.name][id] = have
+			return nil
+		}
+	}
+	rs.batch = append(rs.batch, row...)
+	rs.rows++
+	if (rs.rows+1)*len(th.Columns) > rs.d.MaxArgs() {
+		return rs.flush()
+	}
+	return nil
+}
+
+// start flushes the previous table and checks th.
+func (rs *restorer) start(th *TableHeader) error {
+	if err := rs.flush(); err != nil {
+		return err
+	}
+	i := slices.IndexFunc(tables, func(t table) bool { return t.name == th.Table })
+	if i < 0 {
+		return fmt.Errorf("restore: the archive has unknown table %q", th.Table)
+	}
+	for _, col := range th.Columns {
+		if !identifier.MatchString(col) {
+			return fmt.Errorf("restore: the archive has invalid column %q in %s", col, th.Table)
+		}
+	}
+	rs.current, rs.spec, rs.existing = th, tables[i], nil
+	if rs.spec.matchBy == "" {
+		return nil
+	}
+	if !slices.Contains(th.Columns, "id") || !slices.Contains(th.Columns, rs.spec.matchBy) {
+		return fmt.Errorf("restore: the archive's %s lack id or %s", th.Table, rs.spec.matchBy)
+	}
+
+	rows, err := rs.tx.QueryContext(rs.ctx, "SELECT "+rs.d.Text("id")+", "+rs.spec.matchBy+" FROM "+th.Table)
+	if err != nil {
+		return fmt.Errorf("restore: read %s: %w", th.Table, err)
+	}
+	defer func() { _ = rows.Close() }()
+	rs.existing = make(map[string]string)
+	rs.ids[th.Table] = make(map[string]string)
+	for rows.Next() {
+		var id, key string
+		if err := rows.Scan(&id, &key); err != nil {
+			return fmt.Errorf("restore: scan %s: %w", th.Table, err)
+		}
+		rs.existing[key] = id
+		rs.existing["id:"+id] = id
+	}
+	return rows.Err()
+}
+
+// flush inserts the rows batched for the current table.
+func (rs *restorer) flush() error {
+	if rs.rows == 0 {
+		return nil
+	}
+	th := rs.current
+	query, args := rs.d.Build().
+		Append("INSERT INTO "+th.Table+" ("+strings.Join(th.Columns, ", ")+") VALUES ").
+		Values(rs.rows, rs.batch...).
+		Query()
+	if _, err := rs.tx.ExecContext(rs.ctx, query, args...); err != nil {
+		return fmt.Errorf("restore: insert %s: %w", th.Table, err)
+	}
+	rs.stats[th.Table] += rs.rows
+	rs.batch, rs.rows = rs.batch[:0], 0
+	return nil
+}
@@ -0,0 +1,179 @@
+package backup_test
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/yourusername/whey-price-compare/deployments/sqlite"
+	"github.com/yourusername/whey-price-compare/internal/backup"
+	"github.com/yourusername/whey-price-compare/internal/database"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/sqlstore"
+	"github.com/yourusername/whey-price-compare/internal/seed"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// openSQLite returns an in-memory SQLite database with the current schema.
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	cfg, err := database.ParseURL("sqlite::memory:", database.DefaultPoolConfig())
+	if err != nil {
+		t.Fatalf("ParseURL: %v", err)
+	}
+	db, err := database.Open(cfg.Dialect.Driver, cfg)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	for _, stmt := range database.SplitStatements(sqlite.Schema) {
+		if _, err := db.ExecContext(t.Context(), stmt); err != nil {
+			t.Fatalf("Create SQLite schema failed: %v\n%s", err, stmt)
+		}
+	}
+	return db
+}
+
+func count(t *testing.T, db *sql.DB, table string) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRowContext(t.Context(), "SELECT count(*) FROM "+table).Scan(&n); err != nil {
+		t.Fatalf("count %s: %v", table, err)
+	}
+	return n
+}
+
+// accountRepos are the repositories a test writes and reads accounts in db
+// through, sealing personal data with one fixed key.
+type accountRepos struct {
+	users    *sqlstore.UserRepository
+	sessions *sqlstore.SessionRepository
+	alerts   *sqlstore.AlertRepository
+}
+
+func newAccountRepos(t *testing.T, db *sql.DB) accountRepos {
+	t.Helper()
+	key, err := sqlstore.NewPIIKey(bytes.Repeat([]byte{3}, 32))
+	if err != nil {
+		t.Fatalf("NewPIIKey: %v", err)
+	}
+	router := database.NewRouter(database.DefaultRouterConfig(), db, nil, testhelpers.SetupTestLogger(t))
+	stmts := database.NewStatementCache(database.DefaultPoolConfig(), 0)
+	t.Cleanup(func() { _ = stmts.Close() })
+	return accountRepos{
+		users:    sqlstore.NewUserRepository(database.SQLite, router, stmts, key),
+		sessions: sqlstore.NewSessionRepository(database.SQLite, router, stmts),
+		alerts:   sqlstore.NewAlertRepository(database.SQLite, router, stmts),
+	}
+}
+
+func TestDumpRestore(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestDumpRestore", "internal/backup")
+
+	testhelpers.LogTestStep(logger, "arrange", "A seeded source database and a fresh target")
+	ctx := t.Context()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	src, dst := openSQLite(t), openSQLite(t)
+	c := seed.Generate(seed.Config{Products: 2, Days: 10, Seed: 1, Now: now})
+	if err := c.Insert(ctx, src, database.SQLite, true); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	srcAccounts := newAccountRepos(t, src)
+	u, err := srcAccounts.users.CreateUser(ctx, domain.User{Email: "neha@example.com", Name: "Neha", CreatedAt: now})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	session := domain.Session{TokenHash: "session-hash", UserID: u.ID, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	if err := srcAccounts.sessions.CreateSession(ctx, session); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if _, err := srcAccounts.alerts.CreateAlert(ctx, domain.PriceAlert{UserID: u.ID, ProductID: c.Products[0].ID, Condition: domain.ConditionPrice, TargetPrice: 1999, CreatedAt: now}); err != nil {
+		t.Fatalf("CreateAlert: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Dumping five days of history")
+	var archive bytes.Buffer
+	stats, err := backup.Dump(ctx, src, database.SQLite, &archive, backup.Options{HistoryDays: 5, Now: now})
+	if err != nil {
+		t.Fatalf("Dump: %v", err)
+	}
+	var recent int
+	if err := src.QueryRowContext(ctx, "SELECT count(*) FROM price_history WHERE recorded_at >= ?",
+		now.AddDate(0, 0, -5)).Scan(&recent); err != nil {
+		t.Fatalf("count recent history: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Only the recent history is dumped")
+	testhelpers.LogTestAssertion(logger, "price points", recent, stats["price_history"])
+	if stats["price_history"] != recent || stats["products"] != 2 || stats["users"] != 1 || stats["price_alerts"] != 1 {
+		t.Fatalf("Dump stats = %v, want 2 products, %d price points, a user and an alert", stats, recent)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Restoring over the target's sample products")
+	_, err = backup.Restore(ctx, dst, database.SQLite, bytes.NewReader(archive.Bytes()), false)
+
+	testhelpers.LogTestStep(logger, "assert", "Restore refuses without replace")
+	if !errors.Is(err, backup.ErrCatalogNotEmpty) {
+		t.Fatalf("Restore = %v, want ErrCatalogNotEmpty", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Restoring with replace")
+	brands := count(t, dst, "brands")
+	restored, err := backup.Restore(ctx, dst, database.SQLite, bytes.NewReader(archive.Bytes()), true)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The catalog matches and seeded brands are not duplicated")
+	for _, table := range []string{"products", "product_variants", "product_listings", "price_history"} {
+		testhelpers.LogTestAssertion(logger, table, stats[table], count(t, dst, table))
+		if got := count(t, dst, table); got != stats[table] {
+			t.Errorf("%s has %d rows, want %d", table, got, stats[table])
+		}
+	}
+	if got := count(t, dst, "brands"); got != brands+restored["brands"] {
+		t.Errorf("brands = %d, want %d kept plus %d restored", got, brands, restored["brands"])
+	}
+	var orphans int
+	if err := dst.QueryRowContext(ctx,
+		"SELECT count(*) FROM products p LEFT JOIN brands b ON b.id = p.brand_id WHERE b.id IS NULL").Scan(&orphans); err != nil {
+		t.Fatalf("count orphans: %v", err)
+	}
+	if orphans != 0 {
+		t.Errorf("%d restored products reference a missing brand", orphans)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The account is restored and its sealed email still opens")
+	dstAccounts := newAccountRepos(t, dst)
+	if got, err := dstAccounts.users.UserByEmail(ctx, "neha@example.com"); err != nil || got.ID != u.ID || got.Name != "Neha" {
+		t.Errorf("UserByEmail = %+v, %v; want the restored user", got, err)
+	}
+	if got, err := dstAccounts.sessions.Session(ctx, session.TokenHash); err != nil || got.UserID != u.ID {
+		t.Errorf("Session = %+v, %v; want the restored session", got, err)
+	}
+	if alerts, err := dstAccounts.alerts.UserAlerts(ctx, u.ID); err != nil || len(alerts) != 1 || alerts[0].ProductID != c.Products[0].ID {
+		t.Errorf("UserAlerts = %+v, %v; want the restored alert", alerts, err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Restoring into a database with no products but an account")
+	other := openSQLite(t)
+	if _, err := other.ExecContext(ctx, "DELETE FROM products"); err != nil {
+		t.Fatalf("clear products: %v", err)
+	}
+	if _, err := newAccountRepos(t, other).users.CreateUser(ctx, domain.User{Email: "arjun@example.com", CreatedAt: now}); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	_, err = backup.Restore(ctx, other, database.SQLite, bytes.NewReader(archive.Bytes()), false)
+
+	testhelpers.LogTestStep(logger, "assert", "Restore refuses to mix accounts without replace")
+	if !errors.Is(err, backup.ErrAccountsNotEmpty) {
+		t.Errorf("Restore = %v, want ErrAccountsNotEmpty", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestDumpRestore", true)
+}
@@ -0,0 +1,122 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/awsv4"
+)
+
+// S3Config configures the S3 client.
+type S3Config struct {
+	Bucket      string
+	Region      string
+	Credentials awsv4.Credentials
+	// Endpoint is the API root of an S3-compatible store, such as MinIO or
+	// Cloudflare R2, addressed path-style. Empty means AWS, addressed
+	// virtual-hosted style.
+	Endpoint string
+	// Timeout bounds each request, body included.
+	Timeout time.Duration
+}
+
+// DefaultS3Config returns a thirty minute timeout, long enough to move a
+// large archive; Bucket, Region and Credentials must be set.
+func DefaultS3Config() S3Config {
+	return S3Config{Timeout: 30 * time.Minute}
+}
+
+// S3 stores archives in an S3 bucket.
+type S3 struct {
+	cfg    S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3 creates an S3 client.
+func NewS3(cfg S3Config) *S3 {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultS3Config().Timeout
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	return &S3{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}, now: time.Now}
+}
+
+// ParseS3URL splits an s3://bucket/key URL.
+func ParseS3URL(raw string) (bucket, key string, err error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "s3" || u.Host == "" {
+		return "", "", fmt.Errorf("invalid S3 URL %q: want s3://bucket/key", raw)
+	}
+	return u.Host, strings.TrimPrefix(u.Path, "/"), nil
+}
+
+// objectURL returns where key is stored.
+func (s *S3) objectURL(key string) *url.URL {
+	if s.cfg.Endpoint != "" {
+		u, _ := url.Parse(s.cfg.Endpoint)
+		u.Path = strings.TrimRight(u.Path, "/") + "/" + s.cfg.Bucket + "/" + key
+		return u
+	}
+	return &url.URL{Scheme: "https", Host: s.cfg.Bucket + ".s3." + s.cfg.Region + ".amazonaws.com", Path: "/" + key}
+}
+
+// Put uploads body as key. body is read twice, once to sign it.
+func (s *S3) Put(ctx context.Context, key string, body io.ReadSeeker) error {
+	h := sha256.New()
+	size, err := io.Copy(h, body)
+	if err != nil {
+		return fmt.Errorf("s3 put %s: %w", key, err)
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("s3 put %s: %w", key, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key).String(), io.NopCloser(body))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/gzip")
+	resp, err := s.do(req, hex.EncodeToString(h.Sum(nil)))
+	if err != nil {
+		return fmt.Errorf("s3 put %s: %w", key, err)
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+// Get downloads key. The caller closes the body.
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req, awsv4.PayloadHash(nil))
+	if err != nil {
+		return nil, fmt.Errorf("s3 get %s: %w", key, err)
+	}
+	return resp.Body, nil
+}
+
+// do signs and sends req, returning the response if it succeeded.
+func (s *S3) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	awsv4.Sign(req, payloadHash, s.cfg.Credentials, s.cfg.Region, "s3", s.now())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer func() { _ = resp.Body.Close() }()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
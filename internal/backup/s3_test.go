@@ -0,0 +1,100 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/awsv4"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestS3_PutGet(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestS3_PutGet", "internal/backup")
+
+	testhelpers.LogTestStep(logger, "arrange", "An S3-compatible store that checks signing")
+	objects := make(map[string][]byte)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDTEST/") ||
+			r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+			http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			objects[r.URL.Path] = body
+		case http.MethodGet:
+			obj, ok := objects[r.URL.Path]
+			if !ok {
+				http.Error(w, "NoSuchKey", http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(obj)
+		}
+	}))
+	defer srv.Close()
+	store := NewS3(S3Config{
+		Bucket:      "backups",
+		Region:      "ap-south-1",
+		Endpoint:    srv.URL + "/",
+		Credentials: awsv4.Credentials{AccessKeyID: "AKIDTEST", SecretAccessKey: "test-only-secret"},
+	})
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Uploading an archive and downloading it again")
+	archive := []byte("archive bytes")
+	if err := store.Put(ctx, "nightly/whey.jsonl.gz", bytes.NewReader(archive)); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	body, err := store.Get(ctx, "nightly/whey.jsonl.gz")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, _ := io.ReadAll(body)
+	_ = body.Close()
+
+	testhelpers.LogTestStep(logger, "assert", "The object is stored path-style and comes back whole")
+	testhelpers.LogTestAssertion(logger, "object", string(archive), string(got))
+	if string(got) != string(archive) || objects["/backups/nightly/whey.jsonl.gz"] == nil {
+		t.Errorf("Get = %q, stored %v", got, objects)
+	}
+	if _, err := store.Get(ctx, "missing"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Get missing = %v, want a 404 error", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestS3_PutGet", true)
+}
+
+func TestParseS3URL(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestParseS3URL", "internal/backup")
+
+	testCases := []struct {
+		raw, bucket, key string
+		wantErr          bool
+	}{
+		{"s3://backups/nightly/", "backups", "nightly/", false},
+		{"s3://backups", "backups", "", false},
+		{"https://backups/key", "", "", true},
+		{"s3:///key", "", "", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.raw, func(t *testing.T) {
+			bucket, key, err := ParseS3URL(tc.raw)
+			testhelpers.LogTestAssertion(logger, tc.raw, tc.bucket+"|"+tc.key, bucket+"|"+key)
+			if (err != nil) != tc.wantErr || bucket != tc.bucket || key != tc.key {
+				t.Errorf("ParseS3URL(%q) = %q, %q, %v", tc.raw, bucket, key, err)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestParseS3URL", true)
+}
@@ -0,0 +1,77 @@
+// Package bloom implements a Bloom filter over strings: a compact set that
+// can answer "definitely absent" with certainty and "maybe present" with a
+// configurable false-positive rate.
+package bloom
+
+import (
+	"hash/fnv"
+	"math"
+	"sync/atomic"
+)
+
+// Filter is a fixed-size Bloom filter. Add and Test are safe for concurrent
+// use without locking. Items cannot be removed; rebuild a new filter to
+// forget them.
+type Filter struct {
+	bits  []atomic.Uint64
+	m     uint64
+	k     uint64
+	count atomic.Int64
+}
+
+// New sizes a filter for expected items at false-positive rate fpRate. The
+// rate is only met while the filter holds at most expected items; adding
+// more degrades it gradually.
+func New(expected int, fpRate float64) *Filter {
+	expected = max(expected, 1)
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = 0.01
+	}
+	m := uint64(math.Ceil(-float64(expected) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	m = max(m, 64)
+	k := uint64(math.Round(float64(m) / float64(expected) * math.Ln2))
+	k = max(k, 1)
+	return &Filter{bits: make([]atomic.Uint64, (m+63)/64), m: m, k: k}
+}
+
+// Add inserts s.
+func (f *Filter) Add(s string) {
+	h1, h2 := hashes(s)
+	for i := range f.k {
+		bit := (h1 + i*h2) % f.m
+		f.bits[bit/64].Or(1 << (bit % 64))
+	}
+	f.count.Add(1)
+}
+
+// Test reports whether s may have been added. False means it certainly was
+// not.
+func (f *Filter) Test(s string) bool {
+	h1, h2 := hashes(s)
+	for i := range f.k {
+		bit := (h1 + i*h2) % f.m
+		if f.bits[bit/64].Load()&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Len returns how many items were added, counting repeats.
+func (f *Filter) Len() int { return int(f.count.Load()) }
+
+// FalsePositiveRate estimates the current false-positive rate from the
+// number of items added.
+func (f *Filter) FalsePositiveRate() float64 {
+	n := float64(f.count.Load())
+	return math.Pow(1-math.Exp(-float64(f.k)*n/float64(f.m)), float64(f.k))
+}
+
+// hashes derives the two base hashes for double hashing from one 64-bit FNV
+// hash; the second is forced odd so the probe sequence never collapses.
+func hashes(s string) (uint64, uint64) {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	sum := h.Sum64()
+	return sum, (sum>>32 | sum<<32) | 1
+}
@@ -0,0 +1,72 @@
+package bloom
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestFilter(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestFilter", "internal/bloom")
+
+	tests := []struct {
+		name     string
+		expected int
+		fpRate   float64
+	}{
+		{"One percent", 10000, 0.01},
+		{"Tenth of a percent", 10000, 0.001},
+		{"Invalid rate falls back", 2000, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testhelpers.LogTestStep(logger, "arrange", "Adding the expected number of IDs concurrently")
+			f := New(tt.expected, tt.fpRate)
+			var wg sync.WaitGroup
+			for w := range 4 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := w; i < tt.expected; i += 4 {
+						f.Add("prod_" + strconv.Itoa(i))
+					}
+				}()
+			}
+			wg.Wait()
+
+			testhelpers.LogTestStep(logger, "assert", "No false negatives and false positives near the target")
+			for i := range tt.expected {
+				if !f.Test("prod_" + strconv.Itoa(i)) {
+					t.Fatalf("Added item prod_%d tested absent", i)
+				}
+			}
+			rate := tt.fpRate
+			if rate == 0 {
+				rate = 0.01
+			}
+			positives := 0
+			const probes = 100000
+			for i := range probes {
+				if f.Test("missing_" + strconv.Itoa(i)) {
+					positives++
+				}
+			}
+			got := float64(positives) / probes
+			testhelpers.LogTestAssertion(logger, "false positive rate", rate, got)
+			if got > 2*rate {
+				t.Errorf("False positive rate %.4f, want about %.4f", got, rate)
+			}
+			if f.Len() != tt.expected {
+				t.Errorf("Len = %d, want %d", f.Len(), tt.expected)
+			}
+			if est := f.FalsePositiveRate(); est > 1.5*rate {
+				t.Errorf("Estimated rate %.4f, want about %.4f", est, rate)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestFilter", true)
+}
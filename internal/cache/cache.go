@@ -0,0 +1,172 @@
+// Package cache provides the read-through cache in front of the hot product
+// and comparison reads. Entries expire after a TTL and are also deleted
+// explicitly when the data behind them changes. Reads may opt into
+// stale-while-revalidate, answering from a recently expired entry while it
+// is refreshed in the background.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultTTL bounds how long an entry can outlive a missed invalidation.
+const DefaultTTL = 5 * time.Minute
+
+// refreshTimeout bounds a background revalidation, which has no caller
+// deadline to inherit.
+const refreshTimeout = 10 * time.Second
+
+// Policy says how long a cached read may be served.
+type Policy struct {
+	// TTL is how long an entry is fresh.
+	TTL time.Duration
+	// StaleWhileRevalidate is how long after TTL an entry is still served,
+	// immediately, while one caller refreshes it in the background. Zero
+	// means expired entries are always reloaded in line.
+	StaleWhileRevalidate time.Duration
+}
+
+// DefaultPolicy is DefaultTTL with no stale serving.
+func DefaultPolicy() Policy { return Policy{TTL: DefaultTTL} }
+
+// ErrMiss is returned by Get when the key is not cached.
+var ErrMiss = errors.New("cache miss")
+
+// Cache stores opaque values by key.
+type Cache interface {
+	// Get returns the value for key, or ErrMiss.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores value for ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes keys; missing keys are ignored.
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Key builders. Keys are versioned so a change to a cached type's shape can
+// be rolled out by bumping the version instead of flushing the cache.
+func ProductKey(productID string) string    { return "v2:product:" + productID }
+func ComparisonKey(productID string) string { return "v2:comparison:" + productID }
+
+// ProductKeys returns every key derived from a product's data.
+func ProductKeys(productID string) []string {
+	return []string{ProductKey(productID), ComparisonKey(productID)}
+}
+
+// now is the clock used to judge freshness; tests replace it.
+var now = time.Now
+
+// envelope is the stored form of a cached value.
+type envelope struct {
+	FreshUntil time.Time       `json:"fresh_until"`
+	Value      json.RawMessage `json:"value"`
+}
+
+// Fetch returns the cached value for key, or calls load and caches its
+// result. Cache failures are logged and treated as misses: a cache outage
+// makes reads slower, never unavailable. A nil c disables caching.
+//
+// When c is a Coalescer, concurrent misses share one load. That load is
+// detached from the first caller's cancellation so a client hanging up does
+// not fail everyone waiting on it.
+func Fetch[T any](ctx context.Context, c Cache, key string, p Policy, logger *zap.Logger, load func(context.Context) (T, error)) (T, error) {
+	if c == nil {
+		return load(ctx)
+	}
+	logger = logger.With(zap.String("operation", "CacheFetch"), zap.String("key", key))
+
+	if data, err := c.Get(ctx, key); err == nil {
+		var env envelope
+		var v T
+		if err := decode(data, &env, &v); err != nil {
+			logger.Warn("Discarding undecodable cache entry", zap.Error(err))
+		} else if now().Before(env.FreshUntil) {
+			return v, nil
+		} else if p.StaleWhileRevalidate > 0 {
+			// Stale entries only survive in the cache while they are
+			// within the window, so serving one is always allowed.
+			go revalidate(ctx, c, key, p, logger, load)
+			return v, nil
+		}
+	} else if !errors.Is(err, ErrMiss) {
+		logger.Warn("Cache read failed", zap.Error(err))
+	}
+
+	co, ok := c.(Coalescer)
+	if !ok {
+		v, err := load(ctx)
+		if err == nil {
+			store(ctx, c, key, p, logger, v)
+		}
+		return v, err
+	}
+
+	var v T
+	data, leader, err := co.Coalesce(ctx, key, func() ([]byte, error) {
+		var err error
+		if v, err = load(context.WithoutCancel(ctx)); err != nil {
+			return nil, err
+		}
+		return store(ctx, c, key, p, logger, v), nil
+	})
+	if err != nil || leader {
+		return v, err
+	}
+	if err := decode(data, &envelope{}, &v); err != nil {
+		return load(ctx)
+	}
+	return v, nil
+}
+
+// revalidate reloads a stale entry. Through a Coalescer, stale readers
+// arriving together trigger a single reload.
+func revalidate[T any](ctx context.Context, c Cache, key string, p Policy, logger *zap.Logger, load func(context.Context) (T, error)) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), refreshTimeout)
+	defer cancel()
+	refresh := func() ([]byte, error) {
+		v, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return store(ctx, c, key, p, logger, v), nil
+	}
+	var err error
+	if co, ok := c.(Coalescer); ok {
+		_, _, err = co.Coalesce(ctx, key, refresh)
+	} else {
+		_, err = refresh()
+	}
+	if err != nil {
+		logger.Warn("Background revalidation failed, serving stale entry until it expires", zap.Error(err))
+	}
+}
+
+func decode[T any](data []byte, env *envelope, v *T) error {
+	if err := json.Unmarshal(data, env); err != nil {
+		return err
+	}
+	return json.Unmarshal(env.Value, v)
+}
+
+// store writes v to c and returns the stored bytes, or nil if v could not
+// be encoded. The entry lives through its stale window.
+func store[T any](ctx context.Context, c Cache, key string, p Policy, logger *zap.Logger, v T) []byte {
+	value, err := json.Marshal(v)
+	if err != nil {
+		logger.Warn("Cache encode failed", zap.Error(err))
+		return nil
+	}
+	data, err := json.Marshal(envelope{FreshUntil: now().Add(p.TTL), Value: value})
+	if err != nil {
+		logger.Warn("Cache encode failed", zap.Error(err))
+		return nil
+	}
+	if err := c.Set(context.WithoutCancel(ctx), key, data, p.TTL+p.StaleWhileRevalidate); err != nil {
+		logger.Warn("Cache write failed", zap.Error(err))
+	}
+	return data
+}
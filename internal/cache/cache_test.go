@@ -0,0 +1,180 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// mapCache is an in-memory Cache whose reads and writes can be made to fail.
+type mapCache struct {
+	data    map[string][]byte
+	failGet bool
+	failSet bool
+}
+
+func (m *mapCache) Get(_ context.Context, key string) ([]byte, error) {
+	if m.failGet {
+		return nil, errors.New("connection reset")
+	}
+	v, ok := m.data[key]
+	if !ok {
+		return nil, ErrMiss
+	}
+	return v, nil
+}
+
+func (m *mapCache) Set(_ context.Context, key string, value []byte, _ time.Duration) error {
+	if m.failSet {
+		return errors.New("connection reset")
+	}
+	m.data[key] = value
+	return nil
+}
+
+func (m *mapCache) Delete(_ context.Context, keys ...string) error {
+	for _, k := range keys {
+		delete(m.data, k)
+	}
+	return nil
+}
+
+type payload struct {
+	Name  string  `json:"name"`
+	Price float64 `json:"price"`
+}
+
+func TestFetch(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestFetch", "internal/cache")
+
+	tests := []struct {
+		name      string
+		cache     *mapCache
+		seed      []byte
+		loadErr   error
+		wantLoads int
+		wantName  string
+		wantErr   bool
+	}{
+		{name: "miss loads and stores", cache: &mapCache{}, wantLoads: 1, wantName: "loaded"},
+		{name: "hit skips load", cache: &mapCache{}, seed: []byte(`{"fresh_until":"2999-01-01T00:00:00Z","value":{"name":"cached","price":1}}`), wantName: "cached"},
+		{name: "corrupt entry reloads", cache: &mapCache{}, seed: []byte(`{`), wantLoads: 1, wantName: "loaded"},
+		{name: "read failure falls through", cache: &mapCache{failGet: true}, wantLoads: 1, wantName: "loaded"},
+		{name: "write failure still returns", cache: &mapCache{failSet: true}, wantLoads: 1, wantName: "loaded"},
+		{name: "load error is returned", cache: &mapCache{}, loadErr: errors.New("db down"), wantLoads: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testhelpers.LogTestStep(logger, "arrange", tt.name)
+			tt.cache.data = map[string][]byte{}
+			if tt.seed != nil {
+				tt.cache.data["k"] = tt.seed
+			}
+			loads := 0
+			load := func(context.Context) (*payload, error) {
+				loads++
+				if tt.loadErr != nil {
+					return nil, tt.loadErr
+				}
+				return &payload{Name: "loaded", Price: 3199}, nil
+			}
+
+			testhelpers.LogTestStep(logger, "act", "Fetching")
+			got, err := Fetch(t.Context(), Cache(tt.cache), "k", Policy{TTL: time.Minute}, logger, load)
+
+			testhelpers.LogTestStep(logger, "assert", "Loads and result")
+			testhelpers.LogTestAssertion(logger, "loads", tt.wantLoads, loads)
+			if loads != tt.wantLoads {
+				t.Errorf("loads = %d, want %d", loads, tt.wantLoads)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v", err)
+			}
+			if !tt.wantErr && got.Name != tt.wantName {
+				t.Errorf("Name = %q, want %q", got.Name, tt.wantName)
+			}
+			if tt.wantErr {
+				if _, cached := tt.cache.data["k"]; cached {
+					t.Error("Failed load was cached")
+				}
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Nil cache loads directly")
+	got, err := Fetch(t.Context(), nil, "k", Policy{TTL: time.Minute}, logger, func(context.Context) (int, error) { return 7, nil })
+	if err != nil || got != 7 {
+		t.Errorf("Fetch with nil cache = %d, %v", got, err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestFetch", true)
+}
+
+func TestFetch_StaleWhileRevalidate(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestFetch_StaleWhileRevalidate", "internal/cache")
+
+	testhelpers.LogTestStep(logger, "arrange", "A fake clock and a load that counts versions")
+	var clock atomic.Int64
+	clock.Store(time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC).UnixNano())
+	now = func() time.Time { return time.Unix(0, clock.Load()) }
+	t.Cleanup(func() { now = time.Now })
+	advance := func(d time.Duration) { clock.Add(int64(d)) }
+
+	var version atomic.Int32
+	loaded := make(chan struct{}, 10)
+	load := func(context.Context) (int32, error) {
+		defer func() { loaded <- struct{}{} }()
+		return version.Add(1), nil
+	}
+	c := NewTiered(NewLRU(DefaultLRUConfig()), nil)
+	swr := Policy{TTL: time.Minute, StaleWhileRevalidate: 5 * time.Minute}
+	fetch := func(p Policy) int32 {
+		t.Helper()
+		v, err := Fetch(t.Context(), Cache(c), "k", p, logger, load)
+		if err != nil {
+			t.Fatalf("Fetch: %v", err)
+		}
+		return v
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Warming, then reading inside the stale window")
+	if v := fetch(swr); v != 1 {
+		t.Fatalf("first fetch = %d, want 1", v)
+	}
+	<-loaded
+	advance(2 * time.Minute)
+	stale := fetch(swr)
+
+	testhelpers.LogTestStep(logger, "assert", "The stale value is served and refreshed in the background")
+	testhelpers.LogTestAssertion(logger, "stale value", int32(1), stale)
+	if stale != 1 {
+		t.Errorf("stale fetch = %d, want the cached 1", stale)
+	}
+	select {
+	case <-loaded:
+	case <-time.After(time.Second):
+		t.Fatal("background revalidation did not run")
+	}
+	deadline := time.Now().Add(time.Second)
+	for fetch(swr) != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("revalidated value never served")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Without a stale window expired entries reload in line")
+	advance(2 * time.Minute)
+	if v := fetch(Policy{TTL: time.Minute}); v != 3 {
+		t.Errorf("fetch after expiry = %d, want a fresh load", v)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestFetch_StaleWhileRevalidate", true)
+}
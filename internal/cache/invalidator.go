@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// Invalidator evicts the cached reads a domain event makes wrong.
+type Invalidator struct {
+	cache Cache
+}
+
+// NewInvalidator creates an Invalidator for c.
+func NewInvalidator(c Cache) *Invalidator {
+	return &Invalidator{cache: c}
+}
+
+// EventTypes lists the events Handle understands, for subscribing.
+func (i *Invalidator) EventTypes() []string {
+	return []string{domain.EventPriceDropped, domain.EventPriceChanged, domain.EventProductUpdated}
+}
+
+// Handle evicts the keys affected by e. Comparisons embed the product, so
+// product edits evict both; price changes leave the product detail alone.
+func (i *Invalidator) Handle(ctx context.Context, e domain.Event) error {
+	var keys []string
+	switch ev := e.(type) {
+	case domain.PriceDropped:
+		keys = []string{ComparisonKey(ev.ProductID)}
+	case domain.PriceChanged:
+		keys = []string{ComparisonKey(ev.ProductID)}
+	case domain.ProductUpdated:
+		keys = ProductKeys(ev.ProductID)
+	default:
+		return nil
+	}
+	// The change is already saved; finish evicting even if the request
+	// that made it has gone away.
+	if err := i.cache.Delete(context.WithoutCancel(ctx), keys...); err != nil {
+		return fmt.Errorf("evict %v: %w", keys, err)
+	}
+	return nil
+}
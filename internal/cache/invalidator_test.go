@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestInvalidator_Handle(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestInvalidator_Handle", "internal/cache")
+
+	tests := []struct {
+		name  string
+		event domain.Event
+		want  []string // keys left behind
+	}{
+		{
+			name:  "price drop evicts the comparison only",
+			event: domain.PriceDropped{PriceChange: domain.PriceChange{ProductID: "p1"}},
+			want:  []string{ComparisonKey("p2"), ProductKey("p1"), ProductKey("p2")},
+		},
+		{
+			name:  "price rise evicts the comparison only",
+			event: domain.PriceChanged{PriceChange: domain.PriceChange{ProductID: "p1"}},
+			want:  []string{ComparisonKey("p2"), ProductKey("p1"), ProductKey("p2")},
+		},
+		{
+			name:  "product update evicts product and comparison",
+			event: domain.ProductUpdated{ProductID: "p1"},
+			want:  []string{ComparisonKey("p2"), ProductKey("p2")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testhelpers.LogTestStep(logger, "arrange", "Cached reads for two products")
+			c := &mapCache{data: map[string][]byte{}}
+			for _, k := range append(ProductKeys("p1"), ProductKeys("p2")...) {
+				c.data[k] = []byte("{}")
+			}
+
+			testhelpers.LogTestStep(logger, "act", tt.name)
+			if err := NewInvalidator(c).Handle(t.Context(), tt.event); err != nil {
+				t.Fatalf("Handle: %v", err)
+			}
+
+			testhelpers.LogTestStep(logger, "assert", "Only the affected keys are gone")
+			var left []string
+			for k := range c.data {
+				left = append(left, k)
+			}
+			sort.Strings(left)
+			testhelpers.LogTestAssertion(logger, "remaining", tt.want, left)
+			if strings.Join(left, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("remaining = %v, want %v", left, tt.want)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestInvalidator_Handle", true)
+}
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// LRUConfig configures the in-process cache.
+type LRUConfig struct {
+	// MaxEntries bounds memory; the least recently used entry is evicted
+	// beyond it.
+	MaxEntries int
+	// MaxTTL caps how long any entry lives. Other instances cannot evict
+	// our local entries, so this bounds cross-instance staleness.
+	MaxTTL time.Duration
+}
+
+// DefaultLRUConfig keeps up to 10k entries for at most ten seconds.
+func DefaultLRUConfig() LRUConfig {
+	return LRUConfig{MaxEntries: 10000, MaxTTL: 10 * time.Second}
+}
+
+// LRU is a bounded in-memory Cache. It is safe for concurrent use.
+type LRU struct {
+	cfg LRUConfig
+	now func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List // front is most recently used
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewLRU creates an empty LRU.
+func NewLRU(cfg LRUConfig) *LRU {
+	def := DefaultLRUConfig()
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = def.MaxEntries
+	}
+	if cfg.MaxTTL <= 0 {
+		cfg.MaxTTL = def.MaxTTL
+	}
+	return &LRU{cfg: cfg, now: time.Now, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// Get implements Cache.
+func (c *LRU) Get(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, ErrMiss
+	}
+	e := el.Value.(*lruEntry)
+	if !c.now().Before(e.expiresAt) {
+		c.remove(el)
+		return nil, ErrMiss
+	}
+	c.order.MoveToFront(el)
+	return e.value, nil
+}
+
+// Set implements Cache. ttl is capped at MaxTTL.
+func (c *LRU) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl <= 0 || ttl > c.cfg.MaxTTL {
+		ttl = c.cfg.MaxTTL
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expiresAt := c.now().Add(ttl)
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*lruEntry)
+		e.value, e.expiresAt = value, expiresAt
+		c.order.MoveToFront(el)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.cfg.MaxEntries {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+// Delete implements Cache.
+func (c *LRU) Delete(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, k := range keys {
+		if el, ok := c.entries[k]; ok {
+			c.remove(el)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of entries, including expired ones not yet evicted.
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*lruEntry).key)
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestLRU(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestLRU", "internal/cache")
+
+	testhelpers.LogTestStep(logger, "arrange", "Two-entry LRU on a fake clock")
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	c := NewLRU(LRUConfig{MaxEntries: 2, MaxTTL: 10 * time.Second})
+	c.now = func() time.Time { return now }
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Filling past capacity after touching the oldest entry")
+	_ = c.Set(ctx, "a", []byte("1"), time.Minute)
+	_ = c.Set(ctx, "b", []byte("2"), time.Second)
+	if _, err := c.Get(ctx, "a"); err != nil {
+		t.Fatalf("Get a: %v", err)
+	}
+	_ = c.Set(ctx, "c", []byte("3"), time.Minute)
+
+	testhelpers.LogTestStep(logger, "assert", "The least recently used entry is evicted")
+	testhelpers.LogTestAssertion(logger, "len", 2, c.Len())
+	if _, err := c.Get(ctx, "b"); !errors.Is(err, ErrMiss) {
+		t.Errorf("b should have been evicted, got %v", err)
+	}
+	if v, err := c.Get(ctx, "a"); err != nil || string(v) != "1" {
+		t.Errorf("Get a = %q, %v", v, err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "TTLs are capped at MaxTTL")
+	now = now.Add(10 * time.Second)
+	if _, err := c.Get(ctx, "a"); !errors.Is(err, ErrMiss) {
+		t.Errorf("a should have expired at MaxTTL, got %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Delete and overwrite")
+	_ = c.Set(ctx, "d", []byte("4"), 0)
+	_ = c.Set(ctx, "d", []byte("5"), 0)
+	if v, _ := c.Get(ctx, "d"); string(v) != "5" {
+		t.Errorf("Get d = %q, want overwritten value", v)
+	}
+	_ = c.Delete(ctx, "d", "missing")
+	if _, err := c.Get(ctx, "d"); !errors.Is(err, ErrMiss) {
+		t.Errorf("d should be deleted, got %v", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestLRU", true)
+}
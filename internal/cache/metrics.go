@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/metrics"
+)
+
+// RemoteBuckets are the remote tier's latency buckets in seconds, from a
+// half-millisecond Redis round trip to the 250ms of one timing out.
+var RemoteBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25}
+
+// Metrics exports the hits and misses of Tiered caches per tier, the
+// remote tier's latency and the local tier's size. Pass it to each
+// cache's WithMetrics under a name of its own.
+type Metrics struct {
+	requests *metrics.Counter
+	remote   *metrics.Histogram
+
+	mu     sync.Mutex
+	locals map[string]*LRU // by cache name
+}
+
+// NewMetrics registers the cache metrics with reg.
+func NewMetrics(reg *metrics.Registry) *Metrics {
+	m := &Metrics{
+		requests: reg.Counter("cache_requests_total", "Cache reads by cache, tier and result: hit, miss or error.", "cache", "tier", "result"),
+		remote:   reg.Histogram("cache_remote_duration_seconds", "Remote tier latency by cache and operation.", RemoteBuckets, "cache", "op"),
+		locals:   make(map[string]*LRU),
+	}
+	reg.GaugeFunc("cache_local_entries", "Entries in the local tier, including expired ones not yet evicted.", []string{"cache"}, func(emit func(float64, ...string)) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for name, lru := range m.locals {
+			emit(float64(lru.Len()), name)
+		}
+	})
+	return m
+}
+
+// WithMetrics exports t's reads and sizes to m labelled cache=name, such
+// as "read" or "search". It returns t.
+func (t *Tiered) WithMetrics(m *Metrics, name string) *Tiered {
+	m.mu.Lock()
+	m.locals[name] = t.local
+	m.mu.Unlock()
+	t.metrics, t.name = m, name
+	return t
+}
+
+// read counts a read of tier.
+func (t *Tiered) read(tier string, err error) {
+	if t.metrics == nil {
+		return
+	}
+	result := "hit"
+	switch {
+	case errors.Is(err, ErrMiss):
+		result = "miss"
+	case err != nil:
+		result = "error"
+	}
+	t.metrics.requests.Inc(t.name, tier, result)
+}
+
+// timeRemote records the latency of a remote operation begun at start.
+func (t *Tiered) timeRemote(op string, start time.Time) {
+	if t.metrics != nil {
+		t.metrics.remote.Observe(time.Since(start).Seconds(), t.name, op)
+	}
+}
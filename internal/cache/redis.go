@@ -0,0 +1,264 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RedisConfig configures the Redis client.
+type RedisConfig struct {
+	Addr     string
+	Password string
+	DB       int
+	// Prefix namespaces every key so several services can share a server.
+	Prefix string
+	// PoolSize caps idle connections kept for reuse.
+	PoolSize    int
+	DialTimeout time.Duration
+	// IOTimeout bounds each command round trip. It is kept short: a slow
+	// cache is worse than none when the target is a sub-50ms response.
+	IOTimeout time.Duration
+}
+
+// DefaultRedisConfig returns settings for a local Redis.
+func DefaultRedisConfig() RedisConfig {
+	return RedisConfig{
+		Addr:        "localhost:6379",
+		Prefix:      "wpc:",
+		PoolSize:    16,
+		DialTimeout: time.Second,
+		IOTimeout:   100 * time.Millisecond,
+	}
+}
+
+// ParseRedisURL reads a redis://[:password@]host:port[/db] URL, the format
+// of the REDIS_URL environment variable, into cfg.
+func ParseRedisURL(raw string, cfg RedisConfig) (RedisConfig, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return cfg, fmt.Errorf("parse redis url: %w", err)
+	}
+	if u.Scheme != "redis" || u.Host == "" {
+		return cfg, errors.New("redis url must look like redis://host:port/db")
+	}
+	cfg.Addr = u.Host
+	if u.Port() == "" {
+		cfg.Addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if pw, ok := u.User.Password(); ok {
+		cfg.Password = pw
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if cfg.DB, err = strconv.Atoi(db); err != nil || cfg.DB < 0 {
+			return cfg, fmt.Errorf("redis url: invalid database %q", db)
+		}
+	}
+	return cfg, nil
+}
+
+// Redis is a Cache backed by a Redis server. It speaks RESP directly over a
+// small connection pool and is safe for concurrent use.
+type Redis struct {
+	cfg  RedisConfig
+	idle chan *redisConn
+
+	mu     sync.Mutex
+	closed bool
+}
+
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+// NewRedis creates a client. Connections are opened lazily, so a Redis that
+// is down at startup does not stop the API from serving.
+func NewRedis(cfg RedisConfig) *Redis {
+	def := DefaultRedisConfig()
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = def.PoolSize
+	}
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = def.DialTimeout
+	}
+	if cfg.IOTimeout <= 0 {
+		cfg.IOTimeout = def.IOTimeout
+	}
+	return &Redis{cfg: cfg, idle: make(chan *redisConn, cfg.PoolSize)}
+}
+
+// Get implements Cache.
+func (c *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	reply, err := c.do(ctx, "GET", c.cfg.Prefix+key)
+	if err != nil {
+		return nil, err
+	}
+	switch v := reply.(type) {
+	case nil:
+		return nil, ErrMiss
+	case []byte:
+		return v, nil
+	}
+	return nil, fmt.Errorf("%w: GET returned %T", errProtocol, reply)
+}
+
+// Set implements Cache.
+func (c *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	ms := max(ttl.Milliseconds(), 1)
+	_, err := c.do(ctx, "SET", c.cfg.Prefix+key, value, "PX", strconv.FormatInt(ms, 10))
+	return err
+}
+
+// Delete implements Cache.
+func (c *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	args := make([]any, 0, len(keys)+1)
+	args = append(args, "DEL")
+	for _, k := range keys {
+		args = append(args, c.cfg.Prefix+k)
+	}
+	_, err := c.do(ctx, args...)
+	return err
+}
+
+// Ping checks connectivity, for readiness probes.
+func (c *Redis) Ping(ctx context.Context) error {
+	_, err := c.do(ctx, "PING")
+	return err
+}
+
+// Close closes idle connections. Commands issued afterwards fail.
+func (c *Redis) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	close(c.idle)
+	for conn := range c.idle {
+		_ = conn.Close()
+	}
+	return nil
+}
+
+var errClosed = errors.New("redis: client closed")
+
+// do runs one command. A connection that saw any error is discarded rather
+// than returned to the pool, since its stream position is unknown.
+func (c *Redis) do(ctx context.Context, args ...any) (any, error) {
+	conn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := c.roundTrip(ctx, conn, args...)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	c.put(conn)
+	if rerr, ok := reply.(redisError); ok {
+		return nil, rerr
+	}
+	return reply, nil
+}
+
+func (c *Redis) roundTrip(ctx context.Context, conn *redisConn, args ...any) (any, error) {
+	deadline := time.Now().Add(c.cfg.IOTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	raw := make([][]byte, len(args))
+	for i, a := range args {
+		switch v := a.(type) {
+		case string:
+			raw[i] = []byte(v)
+		case []byte:
+			raw[i] = v
+		}
+	}
+	if err := writeCommand(conn.w, raw...); err != nil {
+		return nil, fmt.Errorf("redis %s: %w", args[0], err)
+	}
+	reply, err := readReply(conn.r)
+	if err != nil {
+		return nil, fmt.Errorf("redis %s: %w", args[0], err)
+	}
+	return reply, nil
+}
+
+func (c *Redis) get(ctx context.Context) (*redisConn, error) {
+	c.mu.Lock()
+	closed := c.closed
+	c.mu.Unlock()
+	if closed {
+		return nil, errClosed
+	}
+	select {
+	case conn, ok := <-c.idle:
+		if ok {
+			return conn, nil
+		}
+		return nil, errClosed
+	default:
+	}
+	return c.dial(ctx)
+}
+
+func (c *Redis) put(conn *redisConn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		_ = conn.Close()
+		return
+	}
+	select {
+	case c.idle <- conn:
+	default:
+		_ = conn.Close()
+	}
+}
+
+func (c *Redis) dial(ctx context.Context) (*redisConn, error) {
+	d := net.Dialer{Timeout: c.cfg.DialTimeout}
+	nc, err := d.DialContext(ctx, "tcp", c.cfg.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("redis dial: %w", err)
+	}
+	conn := &redisConn{Conn: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}
+	setup := [][]any{}
+	if c.cfg.Password != "" {
+		setup = append(setup, []any{"AUTH", c.cfg.Password})
+	}
+	if c.cfg.DB != 0 {
+		setup = append(setup, []any{"SELECT", strconv.Itoa(c.cfg.DB)})
+	}
+	for _, cmd := range setup {
+		reply, err := c.roundTrip(ctx, conn, cmd...)
+		if err == nil {
+			if rerr, ok := reply.(redisError); ok {
+				err = rerr
+			}
+		}
+		if err != nil {
+			_ = nc.Close()
+			// Never echo AUTH arguments back.
+			return nil, fmt.Errorf("redis %s failed: %w", cmd[0], err)
+		}
+	}
+	return conn, nil
+}
@@ -0,0 +1,242 @@
+package cache
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// fakeRedis serves the handful of commands the client sends.
+type fakeRedis struct {
+	ln       net.Listener
+	password string
+
+	mu      sync.Mutex
+	data    map[string]string
+	ttls    map[string]time.Duration
+	dials   int
+	authed  int
+	hangGet bool
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	f := &fakeRedis{ln: ln, password: password, data: map[string]string{}, ttls: map[string]time.Duration{}}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			f.mu.Lock()
+			f.dials++
+			f.mu.Unlock()
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r, w := bufio.NewReader(conn), bufio.NewWriter(conn)
+	for {
+		req, err := readReply(r)
+		if err != nil {
+			return
+		}
+		items, _ := req.([]any)
+		args := make([]string, len(items))
+		for i, it := range items {
+			b, _ := it.([]byte)
+			args[i] = string(b)
+		}
+		if len(args) == 0 {
+			return
+		}
+		f.mu.Lock()
+		reply := f.exec(args)
+		hang := f.hangGet && args[0] == "GET"
+		f.mu.Unlock()
+		if hang {
+			continue
+		}
+		w.WriteString(reply)
+		w.Flush()
+	}
+}
+
+func (f *fakeRedis) exec(args []string) string {
+	switch strings.ToUpper(args[0]) {
+	case "AUTH":
+		if args[1] != f.password {
+			return "-WRONGPASS invalid password\r\n"
+		}
+		f.authed++
+		return "+OK\r\n"
+	case "PING":
+		return "+PONG\r\n"
+	case "SET":
+		f.data[args[1]] = args[2]
+		if ms, err := strconv.Atoi(args[4]); err == nil {
+			f.ttls[args[1]] = time.Duration(ms) * time.Millisecond
+		}
+		return "+OK\r\n"
+	case "GET":
+		v, ok := f.data[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return "$" + strconv.Itoa(len(v)) + "\r\n" + v + "\r\n"
+	case "DEL":
+		n := 0
+		for _, k := range args[1:] {
+			if _, ok := f.data[k]; ok {
+				delete(f.data, k)
+				n++
+			}
+		}
+		return ":" + strconv.Itoa(n) + "\r\n"
+	}
+	return "-ERR unknown command\r\n"
+}
+
+func TestRedis_Commands(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestRedis_Commands", "internal/cache")
+
+	testhelpers.LogTestStep(logger, "arrange", "Client against a fake server requiring AUTH")
+	srv := newFakeRedis(t, "test-only-password")
+	cfg := DefaultRedisConfig()
+	cfg.Addr = srv.ln.Addr().String()
+	cfg.Password = srv.password
+	c := NewRedis(cfg)
+	defer c.Close()
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Round-tripping a value")
+	if _, err := c.Get(ctx, "k"); !errors.Is(err, ErrMiss) {
+		t.Fatalf("Get before Set = %v, want ErrMiss", err)
+	}
+	if err := c.Set(ctx, "k", []byte("value\r\nwith crlf"), 90*time.Second); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	got, err := c.Get(ctx, "k")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if err := c.Ping(ctx); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Values, prefix, TTL and pooling")
+	testhelpers.LogTestAssertion(logger, "value", "value\r\nwith crlf", string(got))
+	if string(got) != "value\r\nwith crlf" {
+		t.Errorf("Get = %q", got)
+	}
+	srv.mu.Lock()
+	ttl, stored := srv.ttls["wpc:k"], srv.data["wpc:k"] != ""
+	dials, authed := srv.dials, srv.authed
+	srv.mu.Unlock()
+	if !stored || ttl != 90*time.Second {
+		t.Errorf("stored=%v ttl=%v, want prefixed key with 90s TTL", stored, ttl)
+	}
+	if dials != 1 || authed != 1 {
+		t.Errorf("dials=%d authed=%d, want the connection reused", dials, authed)
+	}
+
+	if err := c.Delete(ctx, "k", "missing"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := c.Get(ctx, "k"); !errors.Is(err, ErrMiss) {
+		t.Errorf("Get after Delete = %v, want ErrMiss", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestRedis_Commands", true)
+}
+
+func TestRedis_Failures(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestRedis_Failures", "internal/cache")
+
+	srv := newFakeRedis(t, "right-password-123")
+	cfg := DefaultRedisConfig()
+	cfg.Addr = srv.ln.Addr().String()
+	cfg.IOTimeout = 30 * time.Millisecond
+
+	testhelpers.LogTestStep(logger, "act", "Wrong password")
+	cfg.Password = "wrong-password-456"
+	bad := NewRedis(cfg)
+	defer bad.Close()
+	err := bad.Ping(t.Context())
+	testhelpers.LogTestAssertion(logger, "auth error", "AUTH failed", err)
+	if err == nil || !strings.Contains(err.Error(), "AUTH failed") || strings.Contains(err.Error(), cfg.Password) {
+		t.Errorf("Ping with wrong password = %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Server stops answering")
+	cfg.Password = srv.password
+	c := NewRedis(cfg)
+	srv.mu.Lock()
+	srv.hangGet = true
+	srv.mu.Unlock()
+	start := time.Now()
+	if _, err := c.Get(t.Context(), "k"); err == nil || errors.Is(err, ErrMiss) {
+		t.Errorf("Get on hung server = %v, want timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Get took %v, want it bounded by IOTimeout", elapsed)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Closed client")
+	_ = c.Close()
+	if err := c.Ping(t.Context()); !errors.Is(err, errClosed) {
+		t.Errorf("Ping after Close = %v", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestRedis_Failures", true)
+}
+
+func TestParseRedisURL(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestParseRedisURL", "internal/cache")
+
+	tests := []struct {
+		raw     string
+		addr    string
+		db      int
+		pw      string
+		wantErr bool
+	}{
+		{raw: "redis://cache:6380", addr: "cache:6380"},
+		{raw: "redis://cache", addr: "cache:6379"},
+		{raw: "redis://:test-only-password@cache:6379/2", addr: "cache:6379", db: 2, pw: "test-only-password"},
+		{raw: "http://cache:6379", wantErr: true},
+		{raw: "redis://cache/x", wantErr: true},
+	}
+	for _, tt := range tests {
+		cfg, err := ParseRedisURL(tt.raw, DefaultRedisConfig())
+		testhelpers.LogTestAssertion(logger, tt.raw, tt.addr, cfg.Addr)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRedisURL(%q) error = %v", tt.raw, err)
+			continue
+		}
+		if !tt.wantErr && (cfg.Addr != tt.addr || cfg.DB != tt.db || cfg.Password != tt.pw) {
+			t.Errorf("ParseRedisURL(%q) = %+v", tt.raw, cfg)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestParseRedisURL", true)
+}
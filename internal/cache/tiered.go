@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/tracing"
+)
+
+// Coalescer is implemented by caches that collapse concurrent loads of the
+// same key into one. Fetch uses it when available.
+type Coalescer interface {
+	// Coalesce runs fn once for all concurrent callers with the same key and
+	// gives each the result. leader reports whether this caller ran fn.
+	Coalesce(ctx context.Context, key string, fn func() ([]byte, error)) (value []byte, leader bool, err error)
+}
+
+// Tiered checks an in-process LRU before a shared remote cache, and
+// coalesces concurrent misses so a stampede on one product costs a single
+// database load per instance.
+type Tiered struct {
+	local  *LRU
+	remote Cache // may be nil
+	flight group
+
+	metrics *Metrics // may be nil
+	name    string
+}
+
+// NewTiered layers local in front of remote. A nil remote leaves a
+// local-only cache, which still coalesces loads.
+func NewTiered(local *LRU, remote Cache) *Tiered {
+	return &Tiered{local: local, remote: remote}
+}
+
+// Get implements Cache. Remote hits are copied into the local tier.
+func (t *Tiered) Get(ctx context.Context, key string) ([]byte, error) {
+	v, err := t.local.Get(ctx, key)
+	t.read("local", err)
+	if err == nil {
+		return v, nil
+	}
+	if t.remote == nil {
+		return nil, ErrMiss
+	}
+	ctx, span := tracing.StartClient(ctx, "cache.get", tracing.String("cache.name", t.name))
+	defer span.End()
+	start := time.Now()
+	v, err = t.remote.Get(ctx, key)
+	t.timeRemote("get", start)
+	t.read("remote", err)
+	span.SetAttributes(tracing.Bool("cache.hit", err == nil))
+	if err != nil {
+		if !errors.Is(err, ErrMiss) {
+			span.RecordError(err)
+		}
+		return nil, err
+	}
+	_ = t.local.Set(ctx, key, v, 0)
+	return v, nil
+}
+
+// Set implements Cache. The local copy never outlives the LRU's MaxTTL.
+func (t *Tiered) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	_ = t.local.Set(ctx, key, value, ttl)
+	if t.remote == nil {
+		return nil
+	}
+	defer t.timeRemote("set", time.Now())
+	return t.remote.Set(ctx, key, value, ttl)
+}
+
+// Delete implements Cache.
+func (t *Tiered) Delete(ctx context.Context, keys ...string) error {
+	_ = t.local.Delete(ctx, keys...)
+	if t.remote == nil {
+		return nil
+	}
+	defer t.timeRemote("delete", time.Now())
+	return t.remote.Delete(ctx, keys...)
+}
+
+// Coalesce implements Coalescer.
+func (t *Tiered) Coalesce(ctx context.Context, key string, fn func() ([]byte, error)) ([]byte, bool, error) {
+	return t.flight.do(ctx, key, fn)
+}
+
+// group is a minimal singleflight.
+type group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+type call struct {
+	done  chan struct{}
+	value []byte
+	err   error
+}
+
+var errLeaderPanicked = errors.New("cache: coalesced load panicked")
+
+// do runs fn for the first caller of key; later callers wait for its result
+// or for their own context to end, whichever comes first.
+func (g *group) do(ctx context.Context, key string, fn func() ([]byte, error)) ([]byte, bool, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-c.done:
+			return c.value, false, c.err
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+	c := &call{done: make(chan struct{}), err: errLeaderPanicked}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+	c.value, c.err = fn()
+	return c.value, true, c.err
+}
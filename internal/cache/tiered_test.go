@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/metrics"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestTiered_Layers(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestTiered_Layers", "internal/cache")
+
+	testhelpers.LogTestStep(logger, "arrange", "LRU in front of a remote cache")
+	remote := &mapCache{data: map[string][]byte{"k": []byte("remote")}}
+	local := NewLRU(DefaultLRUConfig())
+	c := NewTiered(local, remote)
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Reading a remote-only key")
+	v, err := c.Get(ctx, "k")
+
+	testhelpers.LogTestStep(logger, "assert", "Remote hits are copied locally")
+	testhelpers.LogTestAssertion(logger, "value", "remote", string(v))
+	if err != nil || string(v) != "remote" {
+		t.Fatalf("Get = %q, %v", v, err)
+	}
+	remote.failGet = true
+	if v, err := c.Get(ctx, "k"); err != nil || string(v) != "remote" {
+		t.Errorf("Local tier not populated: %q, %v", v, err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Writes and deletes reach both tiers")
+	remote.failGet = false
+	_ = c.Set(ctx, "n", []byte("new"), time.Minute)
+	if string(remote.data["n"]) != "new" || local.Len() != 2 {
+		t.Errorf("Set did not reach both tiers: remote=%q local=%d", remote.data["n"], local.Len())
+	}
+	_ = c.Delete(ctx, "k", "n")
+	if _, err := c.Get(ctx, "k"); !errors.Is(err, ErrMiss) {
+		t.Errorf("Get after Delete = %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Without a remote the local tier still works")
+	solo := NewTiered(NewLRU(DefaultLRUConfig()), nil)
+	_ = solo.Set(ctx, "k", []byte("v"), time.Minute)
+	if v, err := solo.Get(ctx, "k"); err != nil || string(v) != "v" {
+		t.Errorf("Local-only Get = %q, %v", v, err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestTiered_Layers", true)
+}
+
+func TestFetch_CoalescesMisses(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestFetch_CoalescesMisses", "internal/cache")
+
+	testhelpers.LogTestStep(logger, "arrange", "A slow load behind a cold cache")
+	c := NewTiered(NewLRU(DefaultLRUConfig()), nil)
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) (*payload, error) {
+		loads.Add(1)
+		<-release
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return &payload{Name: "loaded"}, nil
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Fifty concurrent fetches, the first of which is cancelled")
+	const callers = 50
+	leaderCtx, cancelLeader := context.WithCancel(t.Context())
+	results := make([]*payload, callers)
+	errs := make([]error, callers)
+	var wg sync.WaitGroup
+	for i := range callers {
+		ctx := t.Context()
+		if i == 0 {
+			ctx = leaderCtx
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = Fetch(ctx, Cache(c), "k", Policy{TTL: time.Minute}, logger, load)
+		}()
+	}
+	for loads.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	cancelLeader()
+	close(release)
+	wg.Wait()
+
+	testhelpers.LogTestStep(logger, "assert", "One load served everyone")
+	testhelpers.LogTestAssertion(logger, "loads", int32(1), loads.Load())
+	if n := loads.Load(); n != 1 {
+		t.Errorf("loads = %d, want 1", n)
+	}
+	for i := range callers {
+		if i == 0 && errors.Is(errs[i], context.Canceled) {
+			continue // a cancelled follower may give up
+		}
+		if errs[i] != nil || results[i] == nil || results[i].Name != "loaded" {
+			t.Errorf("caller %d = %+v, %v", i, results[i], errs[i])
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestFetch_CoalescesMisses", true)
+}
+
+func TestTiered_Metrics(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestTiered_Metrics", "internal/cache")
+
+	testhelpers.LogTestStep(logger, "arrange", "A metered cache whose remote tier holds one key")
+	reg := metrics.NewRegistry()
+	remote := &mapCache{data: map[string][]byte{"k": []byte("remote")}}
+	c := NewTiered(NewLRU(DefaultLRUConfig()), remote).WithMetrics(NewMetrics(reg), "read")
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Reading the key twice, a missing key once, and once with the remote down")
+	_, _ = c.Get(ctx, "k")
+	_, _ = c.Get(ctx, "k")
+	_, _ = c.Get(ctx, "absent")
+	remote.failGet = true
+	_, _ = c.Get(ctx, "other")
+	var b strings.Builder
+	if err := reg.WriteText(&b); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Each tier's hits, misses and errors are counted")
+	for _, line := range []string{
+		`cache_requests_total{cache="read",tier="local",result="hit"} 1`,
+		`cache_requests_total{cache="read",tier="local",result="miss"} 3`,
+		`cache_requests_total{cache="read",tier="remote",result="hit"} 1`,
+		`cache_requests_total{cache="read",tier="remote",result="miss"} 1`,
+		`cache_requests_total{cache="read",tier="remote",result="error"} 1`,
+		`cache_remote_duration_seconds_count{cache="read",op="get"} 3`,
+		`cache_local_entries{cache="read"} 1`,
+	} {
+		testhelpers.LogTestAssertion(logger, "series", line, strings.Contains(b.String(), line))
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("Metrics lack %q:\n%s", line, b.String())
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestTiered_Metrics", true)
+}
@@ -0,0 +1,112 @@
+package cdn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CloudflareConfig configures the Cloudflare purge client.
+type CloudflareConfig struct {
+	ZoneID string
+	// APIToken needs the Zone > Cache Purge permission only.
+	APIToken string
+	// BaseURL is the API root, overridable for tests.
+	BaseURL string
+	Timeout time.Duration
+}
+
+// DefaultCloudflareConfig returns the public API endpoint.
+func DefaultCloudflareConfig() CloudflareConfig {
+	return CloudflareConfig{BaseURL: "https://api.cloudflare.com/client/v4", Timeout: 10 * time.Second}
+}
+
+// cloudflareBatch is the most tags or files one purge call may carry.
+const cloudflareBatch = 30
+
+// Cloudflare purges the Cloudflare edge cache by cache tag or URL.
+type Cloudflare struct {
+	cfg    CloudflareConfig
+	client *http.Client
+}
+
+// NewCloudflare creates a Cloudflare client.
+func NewCloudflare(cfg CloudflareConfig) *Cloudflare {
+	def := DefaultCloudflareConfig()
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = def.BaseURL
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &Cloudflare{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}
+}
+
+// PurgeKeys implements Purger using Cloudflare cache tags.
+func (c *Cloudflare) PurgeKeys(ctx context.Context, keys ...string) error {
+	return c.purge(ctx, "tags", keys)
+}
+
+// PurgeURLs implements Purger.
+func (c *Cloudflare) PurgeURLs(ctx context.Context, urls ...string) error {
+	return c.purge(ctx, "files", urls)
+}
+
+func (c *Cloudflare) purge(ctx context.Context, field string, values []string) error {
+	for start := 0; start < len(values); start += cloudflareBatch {
+		batch := values[start:min(start+cloudflareBatch, len(values))]
+		if err := c.call(ctx, map[string][]string{field: batch}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type cloudflareResponse struct {
+	Success bool `json:"success"`
+	Errors  []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func (c *Cloudflare) call(ctx context.Context, body any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	url := c.cfg.BaseURL + "/zones/" + c.cfg.ZoneID + "/purge_cache"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.cfg.APIToken)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return &PurgeError{Err: err, Retryable: true}
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	var parsed cloudflareResponse
+	_ = json.Unmarshal(raw, &parsed)
+	if resp.StatusCode == http.StatusOK && parsed.Success {
+		return nil
+	}
+	msg := http.StatusText(resp.StatusCode)
+	if len(parsed.Errors) > 0 {
+		msg = fmt.Sprintf("%d %s", parsed.Errors[0].Code, parsed.Errors[0].Message)
+	}
+	return &PurgeError{
+		Err:       fmt.Errorf("cloudflare purge: status %d: %s", resp.StatusCode, msg),
+		Retryable: resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500,
+	}
+}
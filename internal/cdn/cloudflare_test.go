@@ -0,0 +1,97 @@
+package cdn
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestCloudflare_Purge(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCloudflare_Purge", "internal/cdn")
+
+	testhelpers.LogTestStep(logger, "arrange", "Fake purge API recording calls")
+	var mu sync.Mutex
+	var bodies []map[string][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/zones/zone123/purge_cache" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var body map[string][]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		bodies = append(bodies, body)
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"success":true,"errors":[]}`))
+	}))
+	defer srv.Close()
+	cf := NewCloudflare(CloudflareConfig{ZoneID: "zone123", APIToken: "test-token", BaseURL: srv.URL})
+
+	testhelpers.LogTestStep(logger, "act", "Purging 45 tags and one URL")
+	tags := make([]string, 45)
+	for i := range tags {
+		tags[i] = fmt.Sprintf("product-%d", i)
+	}
+	if err := cf.PurgeKeys(t.Context(), tags...); err != nil {
+		t.Fatalf("PurgeKeys: %v", err)
+	}
+	if err := cf.PurgeURLs(t.Context(), "https://example.com/api/v1/deals"); err != nil {
+		t.Fatalf("PurgeURLs: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Tags are split into batches of 30")
+	testhelpers.LogTestAssertion(logger, "calls", 3, len(bodies))
+	if len(bodies) != 3 || len(bodies[0]["tags"]) != 30 || len(bodies[1]["tags"]) != 15 || len(bodies[2]["files"]) != 1 {
+		t.Errorf("Calls = %v", bodies)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCloudflare_Purge", true)
+}
+
+func TestCloudflare_Errors(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCloudflare_Errors", "internal/cdn")
+
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		wantRetryable bool
+	}{
+		{"Throttled", http.StatusTooManyRequests, `{"success":false,"errors":[{"code":971,"message":"Please wait"}]}`, true},
+		{"Server error", http.StatusBadGateway, ``, true},
+		{"Bad token", http.StatusForbidden, `{"success":false,"errors":[{"code":10000,"message":"Authentication error"}]}`, false},
+		{"Unsuccessful 200", http.StatusOK, `{"success":false,"errors":[{"code":1012,"message":"Request must contain one of tags"}]}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+			cf := NewCloudflare(CloudflareConfig{ZoneID: "z", APIToken: "test-token", BaseURL: srv.URL})
+
+			err := cf.PurgeKeys(t.Context(), "product-1")
+			var perr *PurgeError
+			if !errors.As(err, &perr) {
+				t.Fatalf("err = %v, want *PurgeError", err)
+			}
+			testhelpers.LogTestAssertion(logger, tt.name, tt.wantRetryable, perr.Retryable)
+			if perr.Retryable != tt.wantRetryable {
+				t.Errorf("Retryable = %v, want %v (%v)", perr.Retryable, tt.wantRetryable, err)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCloudflare_Errors", true)
+}
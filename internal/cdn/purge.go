@@ -0,0 +1,217 @@
+// Package cdn keeps edge-cached pages accurate by purging them when the
+// data behind them changes.
+package cdn
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+)
+
+// Purger removes cached responses from a CDN.
+type Purger interface {
+	// PurgeKeys purges every response tagged with any of keys.
+	PurgeKeys(ctx context.Context, keys ...string) error
+	// PurgeURLs purges the given absolute URLs.
+	PurgeURLs(ctx context.Context, urls ...string) error
+}
+
+// PurgeError is a failed purge call. Retryable failures (network errors,
+// throttling, server errors) are retried by the Queue.
+type PurgeError struct {
+	Err       error
+	Retryable bool
+}
+
+func (e *PurgeError) Error() string { return e.Err.Error() }
+func (e *PurgeError) Unwrap() error { return e.Err }
+
+// Mode selects how the Queue addresses cached responses.
+type Mode string
+
+// Purge modes. Keys purge every variant of every response in one call and
+// are preferred; URLs suit CDN plans without tag purging but only clear the
+// bare URL, not query-string or Vary variants.
+const (
+	ModeKeys Mode = "keys"
+	ModeURLs Mode = "urls"
+)
+
+// QueueConfig configures the purge Queue.
+type QueueConfig struct {
+	Mode Mode
+	// PublicBaseURL is the CDN-facing origin, used to build URLs to purge.
+	PublicBaseURL string
+	// FlushInterval batches purges: a burst of price updates during a
+	// scrape collapses into one call per interval.
+	FlushInterval time.Duration
+	// MaxAttempts bounds retries of a target after retryable failures.
+	MaxAttempts int
+}
+
+// DefaultQueueConfig purges by key once a second, trying each target up to
+// five times.
+func DefaultQueueConfig() QueueConfig {
+	return QueueConfig{Mode: ModeKeys, FlushInterval: time.Second, MaxAttempts: 5}
+}
+
+// Queue turns domain events into batched CDN purges. Subscribe Handle to
+// the event bus after the application cache invalidator, so the CDN never
+// refetches an entry the origin is about to evict.
+type Queue struct {
+	cfg    QueueConfig
+	purger Purger
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	pending map[string]int // target -> failed attempts so far
+}
+
+// NewQueue creates a Queue. Call Run to start purging.
+func NewQueue(cfg QueueConfig, purger Purger, logger *zap.Logger) *Queue {
+	def := DefaultQueueConfig()
+	if cfg.Mode == "" {
+		cfg.Mode = def.Mode
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = def.FlushInterval
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = def.MaxAttempts
+	}
+	cfg.PublicBaseURL = strings.TrimRight(cfg.PublicBaseURL, "/")
+	return &Queue{cfg: cfg, purger: purger, logger: logger, pending: make(map[string]int)}
+}
+
+// EventTypes lists the events Handle understands, for subscribing.
+func (q *Queue) EventTypes() []string {
+	return []string{domain.EventPriceDropped, domain.EventPriceChanged, domain.EventProductUpdated}
+}
+
+// Handle queues the purges e calls for. It never blocks on the CDN.
+func (q *Queue) Handle(_ context.Context, e domain.Event) error {
+	var productID string
+	switch ev := e.(type) {
+	case domain.PriceDropped:
+		productID = ev.ProductID
+	case domain.PriceChanged:
+		productID = ev.ProductID
+	case domain.ProductUpdated:
+		productID = ev.ProductID
+	}
+	if productID == "" {
+		return nil
+	}
+	q.Enqueue(q.targets(productID)...)
+	return nil
+}
+
+// targets lists what to purge for a product in the configured mode.
+func (q *Queue) targets(productID string) []string {
+	if q.cfg.Mode == ModeKeys {
+		return []string{httpx.ProductSurrogateKey(productID), httpx.DealsSurrogateKey}
+	}
+	id := url.PathEscape(productID)
+	base := q.cfg.PublicBaseURL
+	return []string{
+		base + "/api/v1/products/" + id,
+		base + "/api/v1/products/" + id + "/prices",
+		base + "/api/v1/products/" + id + "/price-history",
+		base + "/widget/" + id,
+		base + "/api/v1/deals",
+	}
+}
+
+// Enqueue adds targets, keys or URLs depending on the mode, to the next
+// batch.
+func (q *Queue) Enqueue(targets ...string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, t := range targets {
+		if _, ok := q.pending[t]; !ok {
+			q.pending[t] = 0
+		}
+	}
+}
+
+// Pending returns how many targets await purging.
+func (q *Queue) Pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// Run purges queued targets every FlushInterval until ctx is done, then
+// makes one last attempt at whatever is left.
+func (q *Queue) Run(ctx context.Context) {
+	ticker := time.NewTicker(q.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			q.Flush(ctx)
+		case <-ctx.Done():
+			final, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+			q.Flush(final)
+			cancel()
+			return
+		}
+	}
+}
+
+// Flush purges everything queued now. Targets that fail retryably are
+// queued again until they run out of attempts.
+func (q *Queue) Flush(ctx context.Context) {
+	q.mu.Lock()
+	batch := q.pending
+	q.pending = make(map[string]int)
+	q.mu.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+
+	targets := make([]string, 0, len(batch))
+	for t := range batch {
+		targets = append(targets, t)
+	}
+	var err error
+	if q.cfg.Mode == ModeKeys {
+		err = q.purger.PurgeKeys(ctx, targets...)
+	} else {
+		err = q.purger.PurgeURLs(ctx, targets...)
+	}
+	if err == nil {
+		q.logger.Debug("CDN purge complete", zap.String("operation", "CDNPurge"), zap.Int("targets", len(targets)))
+		return
+	}
+
+	var perr *PurgeError
+	retryable := errors.As(err, &perr) && perr.Retryable
+	var requeued, dropped int
+	q.mu.Lock()
+	for t, attempts := range batch {
+		if !retryable || attempts+1 >= q.cfg.MaxAttempts {
+			dropped++
+			continue
+		}
+		if prev, ok := q.pending[t]; !ok || prev < attempts+1 {
+			q.pending[t] = attempts + 1
+		}
+		requeued++
+	}
+	q.mu.Unlock()
+	q.logger.Error("CDN purge failed",
+		zap.String("operation", "CDNPurge"),
+		zap.Int("requeued", requeued),
+		zap.Int("dropped", dropped),
+		zap.Error(err),
+	)
+}
@@ -0,0 +1,135 @@
+package cdn
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+type fakePurger struct {
+	mu    sync.Mutex
+	keys  [][]string
+	urls  [][]string
+	fails []error
+}
+
+func (f *fakePurger) record(dst *[][]string, targets []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sorted := append([]string(nil), targets...)
+	sort.Strings(sorted)
+	*dst = append(*dst, sorted)
+	if len(f.fails) > 0 {
+		err := f.fails[0]
+		f.fails = f.fails[1:]
+		return err
+	}
+	return nil
+}
+
+func (f *fakePurger) PurgeKeys(_ context.Context, keys ...string) error {
+	return f.record(&f.keys, keys)
+}
+func (f *fakePurger) PurgeURLs(_ context.Context, urls ...string) error {
+	return f.record(&f.urls, urls)
+}
+
+func TestQueue_BatchesEvents(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestQueue_BatchesEvents", "internal/cdn")
+
+	ctx := t.Context()
+	drop := domain.PriceDropped{PriceChange: domain.PriceChange{ProductID: "prod_on_gsw"}}
+	rise := domain.PriceChanged{PriceChange: domain.PriceChange{ProductID: "prod_on_gsw"}}
+	edit := domain.ProductUpdated{ProductID: "prod_mb_biozyme"}
+
+	testhelpers.LogTestStep(logger, "act", "Three events in key mode")
+	p := &fakePurger{}
+	q := NewQueue(QueueConfig{Mode: ModeKeys}, p, logger)
+	for _, e := range []domain.Event{drop, rise, edit} {
+		_ = q.Handle(ctx, e)
+	}
+	q.Flush(ctx)
+
+	testhelpers.LogTestStep(logger, "assert", "One call with deduplicated keys")
+	want := "deals,product-prod_mb_biozyme,product-prod_on_gsw"
+	testhelpers.LogTestAssertion(logger, "keys", want, p.keys)
+	if len(p.keys) != 1 || strings.Join(p.keys[0], ",") != want {
+		t.Errorf("PurgeKeys calls = %v, want one with %s", p.keys, want)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "One event in URL mode")
+	p = &fakePurger{}
+	q = NewQueue(QueueConfig{Mode: ModeURLs, PublicBaseURL: "https://wheyprice.in/"}, p, logger)
+	_ = q.Handle(ctx, edit)
+	q.Flush(ctx)
+	q.Flush(ctx)
+
+	testhelpers.LogTestStep(logger, "assert", "The product's public URLs are purged once")
+	if len(p.urls) != 1 || len(p.urls[0]) != 5 || p.urls[0][0] != "https://wheyprice.in/api/v1/deals" {
+		t.Errorf("PurgeURLs calls = %v", p.urls)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestQueue_BatchesEvents", true)
+}
+
+func TestQueue_Retries(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestQueue_Retries", "internal/cdn")
+
+	ctx := t.Context()
+	retryable := &PurgeError{Err: errors.New("status 429"), Retryable: true}
+	fatal := &PurgeError{Err: errors.New("status 403")}
+
+	tests := []struct {
+		name        string
+		fails       []error
+		flushes     int
+		wantCalls   int
+		wantPending int
+	}{
+		{name: "retryable failure is requeued", fails: []error{retryable}, flushes: 2, wantCalls: 2},
+		{name: "gives up after max attempts", fails: []error{retryable, retryable, retryable}, flushes: 4, wantCalls: 3},
+		{name: "permanent failure is dropped", fails: []error{fatal}, flushes: 2, wantCalls: 1},
+		{name: "failure pending until next flush", fails: []error{retryable}, flushes: 1, wantCalls: 1, wantPending: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &fakePurger{fails: tt.fails}
+			q := NewQueue(QueueConfig{Mode: ModeKeys, MaxAttempts: 3}, p, logger)
+			q.Enqueue("product-prod_on_gsw")
+			for range tt.flushes {
+				q.Flush(ctx)
+			}
+			testhelpers.LogTestAssertion(logger, tt.name, tt.wantCalls, len(p.keys))
+			if len(p.keys) != tt.wantCalls || q.Pending() != tt.wantPending {
+				t.Errorf("calls = %d, pending = %d, want %d and %d", len(p.keys), q.Pending(), tt.wantCalls, tt.wantPending)
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Run flushes what is left on shutdown")
+	p := &fakePurger{}
+	q := NewQueue(QueueConfig{Mode: ModeKeys, FlushInterval: time.Hour}, p, logger)
+	q.Enqueue("deals")
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		q.Run(runCtx)
+	}()
+	cancel()
+	<-done
+	if len(p.keys) != 1 {
+		t.Errorf("calls after shutdown = %d, want 1", len(p.keys))
+	}
+
+	testhelpers.LogTestComplete(logger, "TestQueue_Retries", true)
+}
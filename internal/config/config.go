@@ -0,0 +1,214 @@
+// Package config loads the API's settings from a YAML file, overridden by
+// environment variables, and validates them before any is used. A Watcher
+// reloads the file while the API runs, applying the settings that are safe
+// to change live, such as rate limits and scraper selectors:
+//
+//	cfg, err := config.Load(os.Getenv("CONFIG_FILE"), os.Getenv)
+//	...
+//	w := config.NewWatcher(os.Getenv("CONFIG_FILE"), os.Getenv, cfg, logger)
+//	w.OnChange(func(ctx context.Context, old, cfg config.Config) error {
+//		return limiter.SetPolicies(cfg.RateLimits.IP)
+//	})
+//	go w.Run(ctx)
+//
+// Most settings are still read from the environment by cmd/api; they move
+// here as they gain a reason to live in the file.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/middleware"
+)
+
+// Config is the API's settings. Fields tagged reload:"hot" take effect
+// when the file changes; the rest need a restart. Fields tagged env are
+// overridden by that environment variable when it is set.
+type Config struct {
+	// LogLevel is a zap level name; empty uses the environment's default.
+	LogLevel string `yaml:"log_level" env:"LOG_LEVEL" reload:"hot"`
+	// ShutdownDrainDelay is how long the API keeps serving after SIGTERM,
+	// while load balancers stop sending it requests.
+	ShutdownDrainDelay time.Duration `yaml:"shutdown_drain_delay" env:"SHUTDOWN_DRAIN_DELAY"`
+	// TrustProxy takes client addresses from X-Forwarded-For.
+	TrustProxy bool `yaml:"trust_proxy" env:"TRUST_PROXY"`
+	// RateLimits override the built-in limits route by route.
+	RateLimits RateLimits `yaml:"rate_limits" reload:"hot"`
+	// Selectors are scraper selector configs by retailer ID, saved over
+	// the stored ones at startup and when a retailer's entry changes.
+	Selectors map[string]Selectors `yaml:"selectors" reload:"hot"`
+}
+
+// RateLimits are the API's request limits.
+type RateLimits struct {
+	// IP limits each client address.
+	IP middleware.RateLimitConfig `yaml:"ip"`
+	// Account limits signed-in users and API tokens on top of their IP;
+	// its tiers scale the limits per account tier.
+	Account middleware.RateLimitConfig `yaml:"account"`
+}
+
+// Selectors is a retailer's scraper selector config; see
+// domain.SelectorConfig.
+type Selectors struct {
+	Title             []string `yaml:"title"`
+	Price             []string `yaml:"price"`
+	OriginalPrice     []string `yaml:"original_price"`
+	Stock             []string `yaml:"stock"`
+	SearchURLTemplate string   `yaml:"search_url_template"`
+}
+
+// SelectorConfig returns s as retailerID's selector config.
+func (s Selectors) SelectorConfig(retailerID string) domain.SelectorConfig {
+	return domain.SelectorConfig{
+		RetailerID:             retailerID,
+		TitleSelectors:         s.Title,
+		PriceSelectors:         s.Price,
+		OriginalPriceSelectors: s.OriginalPrice,
+		StockSelectors:         s.Stock,
+		SearchURLTemplate:      s.SearchURLTemplate,
+	}
+}
+
+// SelectorsChangedFrom returns the selector configs in c that are missing
+// or different in old, by retailer ID; from a zero old, all of them.
+func (c Config) SelectorsChangedFrom(old Config) map[string]domain.SelectorConfig {
+	changed := make(map[string]domain.SelectorConfig)
+	for id, s := range c.Selectors {
+		if prev, ok := old.Selectors[id]; !ok || !reflect.DeepEqual(prev, s) {
+			changed[id] = s.SelectorConfig(id)
+		}
+	}
+	return changed
+}
+
+// Default returns the settings in force without a file or environment:
+// the rate limits from the API specification, with verified accounts on
+// twice the account budget, and a 5 second drain.
+func Default() Config {
+	account := middleware.DefaultAccountRateLimitConfig()
+	account.Tiers = map[string]float64{auth.TierVerified: 2}
+	return Config{
+		ShutdownDrainDelay: 5 * time.Second,
+		RateLimits: RateLimits{
+			IP:      middleware.DefaultRateLimitConfig(),
+			Account: account,
+		},
+	}
+}
+
+// Load reads the YAML file at path over Default, when path is set, then
+// applies the environment variables getenv returns and validates the
+// result. Routes and tiers in the file are merged into the built-in ones;
+// unknown keys are errors. On error it returns Default with the error.
+func Load(path string, getenv func(string) string) (Config, error) {
+	var raw []byte
+	if path != "" {
+		var err error
+		if raw, err = os.ReadFile(path); err != nil {
+			return Default(), fmt.Errorf("read config: %w", err)
+		}
+	}
+	return parse(raw, getenv)
+}
+
+// parse is Load for the file contents raw.
+func parse(raw []byte, getenv func(string) string) (Config, error) {
+	cfg := Default()
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return Default(), fmt.Errorf("parse config: %w", err)
+	}
+	if err := applyEnv(&cfg, getenv); err != nil {
+		return Default(), err
+	}
+	if err := cfg.Validate(); err != nil {
+		return Default(), err
+	}
+	return cfg, nil
+}
+
+// applyEnv sets the fields tagged env from the environment.
+func applyEnv(cfg *Config, getenv func(string) string) error {
+	v := reflect.ValueOf(cfg).Elem()
+	var errs []error
+	for _, f := range reflect.VisibleFields(v.Type()) {
+		key := f.Tag.Get("env")
+		raw := getenv(key)
+		if key == "" || raw == "" {
+			continue
+		}
+		field := v.FieldByIndex(f.Index)
+		var err error
+		switch {
+		case f.Type == reflect.TypeFor[time.Duration]():
+			var d time.Duration
+			d, err = time.ParseDuration(raw)
+			field.SetInt(int64(d))
+		case f.Type.Kind() == reflect.String:
+			field.SetString(raw)
+		case f.Type.Kind() == reflect.Bool:
+			var b bool
+			b, err = strconv.ParseBool(raw)
+			field.SetBool(b)
+		case f.Type.Kind() == reflect.Int:
+			var n int64
+			n, err = strconv.ParseInt(raw, 10, 0)
+			field.SetInt(n)
+		default:
+			err = fmt.Errorf("%s settings cannot be set from the environment", f.Type)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate reports every invalid setting, named by its key in the file.
+func (c Config) Validate() error {
+	var errs []error
+	if c.LogLevel != "" {
+		if _, err := zapcore.ParseLevel(c.LogLevel); err != nil {
+			errs = append(errs, fmt.Errorf("log_level: %w", err))
+		}
+	}
+	if c.ShutdownDrainDelay < 0 {
+		errs = append(errs, errors.New("shutdown_drain_delay: must not be negative"))
+	}
+	if err := c.RateLimits.IP.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("rate_limits.ip: %w", err))
+	}
+	if err := c.RateLimits.Account.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("rate_limits.account: %w", err))
+	}
+	for _, id := range slices.Sorted(maps.Keys(c.Selectors)) {
+		if err := c.Selectors[id].SelectorConfig(id).Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("selectors.%s: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Level returns the log level in force, or fallback when none is set.
+func (c Config) Level(fallback zapcore.Level) zapcore.Level {
+	if level, err := zapcore.ParseLevel(c.LogLevel); c.LogLevel != "" && err == nil {
+		return level
+	}
+	return fallback
+}
@@ -0,0 +1,203 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+const searchRoute = "GET /api/v1/products/search"
+
+func env(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+func TestLoad(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestLoad", "internal/config")
+
+	testCases := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		check   func(c Config) bool
+		wantErr string
+	}{
+		{"No file keeps the defaults", "", nil, func(c Config) bool {
+			return c.ShutdownDrainDelay == 5*time.Second && c.RateLimits.IP.Routes[searchRoute].Limit == 100 &&
+				c.RateLimits.Account.Tiers["verified"] == 2
+		}, ""},
+		{"File routes merge into the defaults", `
+rate_limits:
+  ip:
+    routes:
+      "GET /api/v1/products/search": {limit: 300, window: 1m}
+selectors:
+  amazon:
+    price: [".a-price-whole"]
+`, nil, func(c Config) bool {
+			return c.RateLimits.IP.Routes[searchRoute].Limit == 300 && c.RateLimits.IP.Routes["GET /api/v1/deals"].Limit == 100 &&
+				c.SelectorsChangedFrom(Config{})["amazon"].PriceSelectors[0] == ".a-price-whole"
+		}, ""},
+		{"Environment overrides the file", "log_level: info\ntrust_proxy: false\n",
+			map[string]string{"LOG_LEVEL": "warn", "TRUST_PROXY": "true", "SHUTDOWN_DRAIN_DELAY": "0s"},
+			func(c Config) bool { return c.LogLevel == "warn" && c.TrustProxy && c.ShutdownDrainDelay == 0 }, ""},
+		{"Unknown keys", "rate_limit: {}\n", nil, nil, "rate_limit"},
+		{"Bad environment value", "", map[string]string{"TRUST_PROXY": "yes please"}, nil, "TRUST_PROXY"},
+		{"Every invalid setting is named", `
+log_level: loud
+rate_limits:
+  account:
+    routes:
+      "GET /api/v1/{id": {limit: 5, window: 1m}
+    tiers: {verified: 0}
+selectors:
+  amazon:
+    search_url_template: "https://www.amazon.in/s"
+`, nil, nil, "log_level"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var path string
+			if tc.file != "" {
+				path = filepath.Join(t.TempDir(), "config.yaml")
+				if err := os.WriteFile(path, []byte(tc.file), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cfg, err := Load(path, env(tc.env))
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("Load() error = %v, want it to name %s", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil || !tc.check(cfg) {
+				t.Errorf("Load() = %+v, %v", cfg, err)
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The example file loads")
+	if _, err := Load("../../deployments/api.example.yaml", env(nil)); err != nil {
+		t.Errorf("Example config: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Validation names every problem at once")
+	_, err := parse([]byte(testCases[len(testCases)-1].file), env(nil))
+	for _, want := range []string{"log_level", `rate_limits.account: route "GET /api/v1/{id"`, `tier "verified"`, "selectors.amazon: at least one price selector"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Error %v does not name %s", err, want)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestLoad", true)
+}
+
+func TestWatcher_Reload(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestWatcher_Reload", "internal/config")
+
+	testhelpers.LogTestStep(logger, "arrange", "A watcher over a file, recording the changes it hands on")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(body string) {
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("trust_proxy: false\n")
+	cfg, err := Load(path, env(nil))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	core, logs := observer.New(zap.InfoLevel)
+	w := NewWatcher(path, env(nil), cfg, zap.New(core))
+	var changes []Config
+	w.OnChange(func(_ context.Context, old, cfg Config) error {
+		changes = append(changes, cfg)
+		return nil
+	})
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Reloading an unchanged file, then a new search limit with a restart-only change")
+	if err := w.Reload(ctx); err != nil || len(changes) != 0 {
+		t.Fatalf("Unchanged reload = %v with %d changes", err, len(changes))
+	}
+	write(`
+trust_proxy: true
+rate_limits:
+  ip:
+    routes:
+      "GET /api/v1/products/search": {limit: 20, window: 1m}
+`)
+	if err := w.Reload(ctx); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The limit applies; trust_proxy waits for a restart")
+	testhelpers.LogTestAssertion(logger, "changes", 1, len(changes))
+	if len(changes) != 1 || changes[0].RateLimits.IP.Routes[searchRoute].Limit != 20 || changes[0].TrustProxy {
+		t.Fatalf("Changes = %+v", changes)
+	}
+	if w.Current().TrustProxy || logs.FilterMessage("Configuration changes need a restart to apply").Len() != 1 {
+		t.Error("trust_proxy should be kept and its change logged")
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Writing an invalid file")
+	write("rate_limits: {ip: {default: {limit: -1}}}\n")
+	err = w.Reload(ctx)
+
+	testhelpers.LogTestStep(logger, "assert", "The settings in force are kept")
+	if err == nil || len(changes) != 1 || w.Current().RateLimits.IP.Routes[searchRoute].Limit != 20 {
+		t.Errorf("Invalid reload = %v, changes = %d, current = %+v", err, len(changes), w.Current().RateLimits.IP)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Writing conflicting routes, then a limit a later hook fails to apply")
+	write(`
+rate_limits:
+  ip:
+    routes:
+      "GET /api/v1/products/{id}": {limit: 5, window: 1m}
+      "GET /api/v1/products/{slug}": {limit: 5, window: 1m}
+`)
+	conflictErr := w.Reload(ctx)
+	w.OnChange(func(_ context.Context, old, cfg Config) error {
+		if cfg.RateLimits.IP.Routes[searchRoute].Limit == 30 {
+			return errors.New("limiter refused the change")
+		}
+		return nil
+	})
+	write(`
+trust_proxy: true
+rate_limits:
+  ip:
+    routes:
+      "GET /api/v1/products/search": {limit: 30, window: 1m}
+`)
+	applyErr := w.Reload(ctx)
+
+	testhelpers.LogTestStep(logger, "assert", "Neither applies; the earlier hook is handed the old settings back")
+	if conflictErr == nil || !strings.Contains(conflictErr.Error(), "conflicts") {
+		t.Errorf("Conflicting reload = %v", conflictErr)
+	}
+	testhelpers.LogTestAssertion(logger, "changes", 3, len(changes))
+	if applyErr == nil || len(changes) != 3 || changes[1].RateLimits.IP.Routes[searchRoute].Limit != 30 ||
+		changes[2].RateLimits.IP.Routes[searchRoute].Limit != 20 {
+		t.Errorf("Failed apply = %v, changes = %+v", applyErr, changes)
+	}
+	if w.Current().RateLimits.IP.Routes[searchRoute].Limit != 20 {
+		t.Errorf("Current = %+v, want the settings in force kept", w.Current().RateLimits.IP)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestWatcher_Reload", true)
+}
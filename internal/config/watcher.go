@@ -0,0 +1,177 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ReloadInterval is how often Watcher.Run checks the file for changes.
+const ReloadInterval = 10 * time.Second
+
+// Watcher reloads the configuration file when it changes and hands the
+// settings that may change live to the functions given to OnChange.
+// Changes to the others are logged and left for the next restart, and a
+// file that fails to load, validate or apply leaves the settings in force.
+type Watcher struct {
+	path   string
+	getenv func(string) string
+	logger *zap.Logger
+
+	// reloading serializes Reload, which owns raw.
+	reloading sync.Mutex
+	raw       []byte
+
+	mu      sync.Mutex
+	current Config
+	hooks   []func(ctx context.Context, old, cfg Config) error
+}
+
+// NewWatcher creates a Watcher for the file at path, which current was
+// loaded from with getenv.
+func NewWatcher(path string, getenv func(string) string, current Config, logger *zap.Logger) *Watcher {
+	w := &Watcher{path: path, getenv: getenv, current: current, logger: logger}
+	if path != "" {
+		w.raw, _ = os.ReadFile(path)
+	}
+	return w
+}
+
+// Current returns the settings in force.
+func (w *Watcher) Current() Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// OnChange adds fn to the functions called, in order, after a reload
+// changes a hot setting. old and cfg are the settings before and after.
+// If fn fails or panics, the functions called before it are called again
+// to go back from cfg to old, and old stays in force.
+func (w *Watcher) OnChange(fn func(ctx context.Context, old, cfg Config) error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.hooks = append(w.hooks, fn)
+}
+
+// Reload reads the file and applies its hot settings if it changed since
+// the last read.
+func (w *Watcher) Reload(ctx context.Context) error {
+	if w.path == "" {
+		return nil
+	}
+	w.reloading.Lock()
+	defer w.reloading.Unlock()
+
+	raw, err := os.ReadFile(w.path)
+	if err != nil {
+		w.logger.Error("Failed to read configuration; keeping the settings in force",
+			zap.String("operation", "ReloadConfig"),
+			zap.String("path", w.path),
+			zap.Error(err),
+		)
+		return fmt.Errorf("read config: %w", err)
+	}
+	if bytes.Equal(raw, w.raw) {
+		return nil
+	}
+	w.raw = raw
+	next, err := parse(raw, w.getenv)
+	if err != nil {
+		w.logger.Error("Invalid configuration; keeping the settings in force",
+			zap.String("operation", "ReloadConfig"),
+			zap.String("path", w.path),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	// Restart-only settings keep their values, so what is in force is
+	// always what Current says.
+	old := w.Current()
+	var hot, cold []string
+	cur, nv := reflect.ValueOf(&old).Elem(), reflect.ValueOf(&next).Elem()
+	for _, f := range reflect.VisibleFields(cur.Type()) {
+		a, b := cur.FieldByIndex(f.Index), nv.FieldByIndex(f.Index)
+		if reflect.DeepEqual(a.Interface(), b.Interface()) {
+			continue
+		}
+		name := f.Tag.Get("yaml")
+		if f.Tag.Get("reload") == "hot" {
+			hot = append(hot, name)
+		} else {
+			cold = append(cold, name)
+			b.Set(a)
+		}
+	}
+	if len(cold) > 0 {
+		w.logger.Warn("Configuration changes need a restart to apply",
+			zap.String("operation", "ReloadConfig"),
+			zap.Strings("settings", cold),
+		)
+	}
+	if len(hot) == 0 {
+		return nil
+	}
+	w.mu.Lock()
+	hooks := w.hooks
+	w.mu.Unlock()
+	for i, fn := range hooks {
+		if err := callHook(ctx, fn, old, next); err != nil {
+			w.logger.Error("Failed to apply configuration; keeping the settings in force",
+				zap.String("operation", "ReloadConfig"),
+				zap.String("path", w.path),
+				zap.Strings("settings", hot),
+				zap.Error(err),
+			)
+			for j := i - 1; j >= 0; j-- {
+				if err := callHook(ctx, hooks[j], next, old); err != nil {
+					w.logger.Error("Failed to restore configuration",
+						zap.String("operation", "ReloadConfig"),
+						zap.Error(err),
+					)
+				}
+			}
+			return fmt.Errorf("apply config: %w", err)
+		}
+	}
+	w.mu.Lock()
+	w.current = next
+	w.mu.Unlock()
+	w.logger.Info("Configuration reloaded",
+		zap.String("operation", "ReloadConfig"),
+		zap.Strings("settings", hot),
+	)
+	return nil
+}
+
+// callHook calls fn, turning a panic into an error so a bad setting cannot
+// take the Run goroutine, and the process, down with it.
+func callHook(ctx context.Context, fn func(ctx context.Context, old, cfg Config) error, old, cfg Config) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return fn(ctx, old, cfg)
+}
+
+// Run reloads the file every ReloadInterval until ctx is cancelled.
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(ReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = w.Reload(ctx)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package database
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SQLiteDriverName is the database/sql driver used for SQLite; the pure-Go
+// driver registers itself as "sqlite".
+const SQLiteDriverName = "sqlite"
+
+// Dialect is the SQL one kind of database speaks, where Postgres and
+// SQLite differ. Repositories shared by both write their queries with a
+// Builder, and the rest of the SQL in the portable subset both accept:
+// RETURNING, ON CONFLICT, CTEs and CAST.
+type Dialect struct {
+	// Name is "postgres" or "sqlite".
+	Name string
+	// Driver is the database/sql driver to open it with.
+	Driver string
+}
+
+var (
+	// Postgres is the production database.
+	Postgres = Dialect{Name: "postgres", Driver: DriverName}
+	// SQLite is the development and test database.
+	SQLite = Dialect{Name: "sqlite", Driver: SQLiteDriverName}
+)
+
+// String returns d's name.
+func (d Dialect) String() string { return d.Name }
+
+// Placeholder returns the marker for the nth argument, counted from 1:
+// $n for Postgres, ?n for SQLite.
+func (d Dialect) Placeholder(n int) string {
+	if d == SQLite {
+		return "?" + strconv.Itoa(n)
+	}
+	return "$" + strconv.Itoa(n)
+}
+
+// Text returns expr as text. Postgres keys are UUIDs, compared and scanned
+// as text; SQLite keys already are text.
+func (d Dialect) Text(expr string) string {
+	if d == SQLite {
+		return expr
+	}
+	return expr + "::text"
+}
+
+// Now returns the current transaction time.
+func (d Dialect) Now() string {
+	if d == SQLite {
+		return "CURRENT_TIMESTAMP"
+	}
+	return "now()"
+}
+
+// MaxArgs is how many arguments one statement may bind: 65535 on
+// Postgres, 32766 on SQLite.
+func (d Dialect) MaxArgs() int {
+	if d == SQLite {
+		return 32766
+	}
+	return 65535
+}
+
+// Arg converts v for binding. SQLite stores times as text, which compares
+// in time order only when every time has the same zone, so times are bound
+// in UTC.
+func (d Dialect) Arg(v any) any {
+	if t, ok := v.(time.Time); ok && d == SQLite {
+		return t.UTC()
+	}
+	return v
+}
+
+// Args converts each of args with Arg.
+func (d Dialect) Args(args ...any) []any {
+	for i, v := range args {
+		args[i] = d.Arg(v)
+	}
+	return args
+}
+
+// Build starts a query in d.
+func (d Dialect) Build() *Builder {
+	return &Builder{d: d}
+}
+
+// Builder writes a query and its arguments together, numbering
+// placeholders as the dialect wants them. It is not safe for concurrent
+// use.
+type Builder struct {
+	d    Dialect
+	sql  strings.Builder
+	args []any
+}
+
+// Append writes fragment, replacing each ? in it with a placeholder for
+// the next of args in turn. Fragments must not contain a literal ?.
+func (b *Builder) Append(fragment string, args ...any) *Builder {
+	for {
+		i := strings.IndexByte(fragment, '?')
+		if i < 0 || len(args) == 0 {
+			break
+		}
+		b.sql.WriteString(fragment[:i])
+		b.arg(args[0])
+		fragment, args = fragment[i+1:], args[1:]
+	}
+	b.sql.WriteString(fragment)
+	return b
+}
+
+// Values writes the rows of a multi-row VALUES list, "(?, ?), (?, ?)",
+// binding args row by row; each row takes len(args)/rows of them.
+func (b *Builder) Values(rows int, args ...any) *Builder {
+	if rows == 0 {
+		return b
+	}
+	width := len(args) / rows
+	for r := range rows {
+		if r > 0 {
+			b.sql.WriteString(", ")
+		}
+		b.sql.WriteByte('(')
+		for c := range width {
+			if c > 0 {
+				b.sql.WriteString(", ")
+			}
+			b.arg(args[r*width+c])
+		}
+		b.sql.WriteByte(')')
+	}
+	return b
+}
+
+func (b *Builder) arg(v any) {
+	b.args = append(b.args, b.d.Arg(v))
+	b.sql.WriteString(b.d.Placeholder(len(b.args)))
+}
+
+// Query returns the query and its arguments.
+func (b *Builder) Query() (string, []any) {
+	return b.sql.String(), b.args
+}
+
+// Rebind rewrites a query written with Postgres' $n placeholders for d,
+// leaving quoted text alone, so a fixed query can be declared once as a
+// constant and prepared per dialect.
+func (d Dialect) Rebind(query string) string {
+	if d == Postgres {
+		return query
+	}
+	var out strings.Builder
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '$' && i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9':
+			c = '?'
+		}
+		out.WriteByte(c)
+	}
+	return out.String()
+}
@@ -0,0 +1,146 @@
+package database
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestBuilder(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestBuilder", "internal/database")
+
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	at := time.Date(2026, 10, 16, 9, 0, 0, 0, ist)
+	tests := []struct {
+		dialect   Dialect
+		wantQuery string
+		wantAt    time.Time
+	}{
+		{
+			dialect:   Postgres,
+			wantQuery: "INSERT INTO search_clicks (search_id, position, created_at) VALUES ($1, $2, $3), ($4, $5, $6) ON CONFLICT DO NOTHING RETURNING id::text",
+			wantAt:    at,
+		},
+		{
+			dialect:   SQLite,
+			wantQuery: "INSERT INTO search_clicks (search_id, position, created_at) VALUES (?1, ?2, ?3), (?4, ?5, ?6) ON CONFLICT DO NOTHING RETURNING id",
+			wantAt:    at.UTC(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect.String(), func(t *testing.T) {
+			query, args := tt.dialect.Build().
+				Append("INSERT INTO search_clicks (search_id, position, created_at) VALUES ").
+				Values(2, "srch_1", 1, at, "srch_1", 2, at).
+				Append(" ON CONFLICT DO NOTHING RETURNING " + tt.dialect.Text("id")).
+				Query()
+			testhelpers.LogTestAssertion(logger, "query", tt.wantQuery, query)
+			if query != tt.wantQuery {
+				t.Errorf("Query = %q, want %q", query, tt.wantQuery)
+			}
+			if len(args) != 6 || args[0] != "srch_1" || args[4] != 2 {
+				t.Fatalf("Args = %v, want the six values in order", args)
+			}
+			if got := args[2].(time.Time); !got.Equal(at) || got.Location() != tt.wantAt.Location() {
+				t.Errorf("Time arg = %v, want %v", got, tt.wantAt)
+			}
+		})
+	}
+
+	t.Run("Append binds in order", func(t *testing.T) {
+		query, args := SQLite.Build().
+			Append("SELECT id FROM search_logs WHERE query = ? AND page > ?", "whey", 1).
+			Append(" LIMIT ?", 10).
+			Query()
+		if want := "SELECT id FROM search_logs WHERE query = ?1 AND page > ?2 LIMIT ?3"; query != want {
+			t.Errorf("Query = %q, want %q", query, want)
+		}
+		if !slices.Equal(args, []any{"whey", 1, 10}) {
+			t.Errorf("Args = %v", args)
+		}
+	})
+
+	testhelpers.LogTestComplete(logger, "TestBuilder", true)
+}
+
+func TestRebind(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestRebind", "internal/database")
+
+	const query = `UPDATE search_synonyms SET terms = $2, note = 'costs $5' WHERE id = $1 AND "$3" = $3`
+	for _, tc := range []struct {
+		dialect Dialect
+		want    string
+	}{
+		{Postgres, query},
+		{SQLite, `UPDATE search_synonyms SET terms = ?2, note = 'costs $5' WHERE id = ?1 AND "$3" = ?3`},
+	} {
+		got := tc.dialect.Rebind(query)
+		testhelpers.LogTestAssertion(logger, tc.dialect.String(), tc.want, got)
+		if got != tc.want {
+			t.Errorf("%s Rebind = %q, want %q", tc.dialect, got, tc.want)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestRebind", true)
+}
+
+func TestParseURLSQLite(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestParseURLSQLite", "internal/database")
+
+	tuning := "?_pragma=busy_timeout%285000%29&_pragma=journal_mode%28wal%29&_pragma=synchronous%28normal%29"
+	for _, tc := range []struct {
+		raw, wantDSN string
+		want         SQLiteConfig
+	}{
+		{"data/sqlite/dev.db", "data/sqlite/dev.db" + tuning, DefaultSQLiteConfig()},
+		{"sqlite:data/sqlite/dev.db", "data/sqlite/dev.db" + tuning, DefaultSQLiteConfig()},
+		{"sqlite:///var/lib/whey/dev.db", "/var/lib/whey/dev.db" + tuning, DefaultSQLiteConfig()},
+		{
+			"sqlite::memory:",
+			":memory:?_pragma=busy_timeout%285000%29&_pragma=synchronous%28normal%29",
+			SQLiteConfig{JournalMode: "memory", BusyTimeout: 5 * time.Second, Synchronous: "normal"},
+		},
+		{
+			"data/sqlite/dev.db?busy_timeout=250ms&synchronous=FULL&_txlock=immediate",
+			"data/sqlite/dev.db?_pragma=busy_timeout%28250%29&_pragma=journal_mode%28wal%29&_pragma=synchronous%28full%29&_txlock=immediate",
+			SQLiteConfig{JournalMode: "wal", BusyTimeout: 250 * time.Millisecond, Synchronous: "full"},
+		},
+		{
+			"/var/lib/whey/whey.db?litestream=true",
+			"/var/lib/whey/whey.db" + tuning + "&_pragma=wal_autocheckpoint%280%29",
+			SQLiteConfig{JournalMode: "wal", BusyTimeout: 5 * time.Second, Synchronous: "normal", Litestream: true},
+		},
+	} {
+		cfg, err := ParseURL(tc.raw, DefaultPoolConfig())
+		if err != nil {
+			t.Fatalf("ParseURL(%q) failed: %v", tc.raw, err)
+		}
+		testhelpers.LogTestAssertion(logger, tc.raw, tc.wantDSN, cfg.DSN)
+		if cfg.Dialect != SQLite || cfg.DSN != tc.wantDSN || cfg.Pool != SQLitePoolConfig() || cfg.SQLite != tc.want {
+			t.Errorf("ParseURL(%q) = %+v, want SQLite at %q with %+v", tc.raw, cfg, tc.wantDSN, tc.want)
+		}
+	}
+	for _, raw := range []string{
+		"sqlite:",
+		"dev.db?journal_mode=off",
+		"dev.db?busy_timeout=soon",
+		"dev.db?synchronous=sometimes",
+		"dev.db?litestream=true&journal_mode=delete",
+		"sqlite::memory:?litestream=true",
+	} {
+		if _, err := ParseURL(raw, DefaultPoolConfig()); err == nil {
+			t.Errorf("ParseURL accepted %q", raw)
+		}
+	}
+	cfg, err := ParseURL("postgres://db/whey", DefaultPoolConfig())
+	if err != nil || cfg.Dialect != Postgres {
+		t.Errorf("ParseURL(postgres) = %v, %v; want the Postgres dialect", cfg.Dialect, err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestParseURLSQLite", true)
+}
@@ -0,0 +1,286 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/metrics"
+)
+
+// QueryBuckets are the statement latency buckets in seconds, from 1ms for
+// a cached key lookup to 5s for a history scan gone wrong.
+var QueryBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5}
+
+// Metrics exports the connection pools' statistics and statement latency
+// by QueryFamily. Pass its Observe to OpenObserved and each pool to
+// WatchPool.
+type Metrics struct {
+	latency *metrics.Histogram
+	errors  *metrics.Counter
+
+	mu    sync.Mutex
+	pools map[string]*sql.DB // by name
+}
+
+// NewMetrics registers the database metrics with reg.
+func NewMetrics(reg *metrics.Registry) *Metrics {
+	m := &Metrics{
+		latency: reg.Histogram("db_query_duration_seconds", "Statement latency until the first rows are ready, by query family.", QueryBuckets, "family"),
+		errors:  reg.Counter("db_query_errors_total", "Statements that failed, by query family.", "family"),
+		pools:   make(map[string]*sql.DB),
+	}
+	for _, g := range []struct {
+		name, help string
+		value      func(PoolStats) float64
+	}{
+		{"db_pool_max_open_connections", "Connections the pool may open.", func(s PoolStats) float64 { return float64(s.MaxOpen) }},
+		{"db_pool_open_connections", "Connections open, in use or idle.", func(s PoolStats) float64 { return float64(s.Open) }},
+		{"db_pool_in_use_connections", "Connections running a statement or transaction.", func(s PoolStats) float64 { return float64(s.InUse) }},
+		{"db_pool_idle_connections", "Connections open and idle.", func(s PoolStats) float64 { return float64(s.Idle) }},
+	} {
+		reg.GaugeFunc(g.name, g.help, []string{"pool"}, m.collectPools(g.value))
+	}
+	for _, c := range []struct {
+		name, help string
+		value      func(PoolStats) float64
+	}{
+		{"db_pool_waits_total", "Requests that waited for a free connection.", func(s PoolStats) float64 { return float64(s.WaitCount) }},
+		{"db_pool_wait_seconds_total", "Time spent waiting for a free connection.", func(s PoolStats) float64 { return s.WaitDuration.Seconds() }},
+		{"db_pool_closed_idle_total", "Connections closed for MaxIdleConns or ConnMaxIdleTime.", func(s PoolStats) float64 { return float64(s.MaxIdleClosed + s.MaxIdleTimeClosed) }},
+		{"db_pool_closed_lifetime_total", "Connections closed for ConnMaxLifetime.", func(s PoolStats) float64 { return float64(s.MaxLifetimeClosed) }},
+	} {
+		reg.CounterFunc(c.name, c.help, []string{"pool"}, m.collectPools(c.value))
+	}
+	return m
+}
+
+func (m *Metrics) collectPools(value func(PoolStats) float64) func(emit func(float64, ...string)) {
+	return func(emit func(float64, ...string)) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for name, db := range m.pools {
+			emit(value(Stats(db)), name)
+		}
+	}
+}
+
+// WatchPool exports db's statistics labelled pool=name, such as "primary"
+// or "replica-1".
+func (m *Metrics) WatchPool(name string, db *sql.DB) {
+	m.mu.Lock()
+	m.pools[name] = db
+	m.mu.Unlock()
+}
+
+// Observe records a statement; it is a QueryObserver.
+func (m *Metrics) Observe(_ context.Context, query string, elapsed time.Duration, err error) {
+	family := QueryFamily(query)
+	m.latency.Observe(elapsed.Seconds(), family)
+	if err != nil {
+		m.errors.Inc(family)
+	}
+}
+
+// KeyTables are the tables whose row counts are exported: the catalog,
+// the history that grows with every scrape, and the queues that grow when
+// their consumers stall.
+var KeyTables = []string{
+	"products", "product_variants", "retailers", "product_listings",
+	"price_history", "price_change_log", "event_outbox", "search_logs", "audit_logs",
+}
+
+// RowCounter counts the rows of tables, leaving out those it does not
+// have. Large tables may be estimated.
+type RowCounter interface {
+	CountRows(ctx context.Context, tables []string) (map[string]int64, error)
+}
+
+// RowCountConfig configures the RowCountExporter.
+type RowCountConfig struct {
+	// Interval is how often rows are counted; scrapes read the last count.
+	Interval time.Duration
+	// Tables are the tables counted.
+	Tables []string
+}
+
+// DefaultRowCountConfig counts the KeyTables every five minutes.
+func DefaultRowCountConfig() RowCountConfig {
+	return RowCountConfig{Interval: 5 * time.Minute, Tables: KeyTables}
+}
+
+// RowCountExporter exports the row counts of tables as db_table_rows.
+// Counting runs on its own schedule rather than per scrape, so a slow
+// count never times a scrape out.
+type RowCountExporter struct {
+	cfg     RowCountConfig
+	counter RowCounter
+	rows    *metrics.Gauge
+	logger  *zap.Logger
+}
+
+// NewRowCountExporter registers db_table_rows with reg, counted by counter.
+func NewRowCountExporter(cfg RowCountConfig, counter RowCounter, reg *metrics.Registry, logger *zap.Logger) *RowCountExporter {
+	def := DefaultRowCountConfig()
+	if cfg.Interval <= 0 {
+		cfg.Interval = def.Interval
+	}
+	if len(cfg.Tables) == 0 {
+		cfg.Tables = def.Tables
+	}
+	return &RowCountExporter{
+		cfg:     cfg,
+		counter: counter,
+		rows:    reg.Gauge("db_table_rows", "Rows per key table, estimated for large Postgres tables.", "table"),
+		logger:  logger,
+	}
+}
+
+// Run counts rows at start and every Interval until ctx is done.
+func (e *RowCountExporter) Run(ctx context.Context) {
+	e.count(ctx)
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.count(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Count counts rows once.
+func (e *RowCountExporter) Count(ctx context.Context) error {
+	counts, err := e.counter.CountRows(ctx, e.cfg.Tables)
+	if err != nil {
+		return err
+	}
+	for table, n := range counts {
+		e.rows.Set(float64(n), table)
+	}
+	return nil
+}
+
+func (e *RowCountExporter) count(ctx context.Context) {
+	if err := e.Count(ctx); err != nil && ctx.Err() == nil {
+		e.logger.Warn("Counting table rows failed", zap.String("operation", "CountRows"), zap.Error(err))
+	}
+}
+
+// tableIdent matches the table names TableCounter puts in SQL.
+var tableIdent = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// TableCounter counts table rows in a SQL database. On Postgres it reads
+// the planner's estimates, summed over partitions and TimescaleDB chunks,
+// which cost nothing however large price_history grows; they are as fresh
+// as the last ANALYZE or autovacuum. On SQLite it counts.
+type TableCounter struct {
+	d  Dialect
+	db *sql.DB
+}
+
+// NewTableCounter creates a TableCounter on db.
+func NewTableCounter(d Dialect, db *sql.DB) *TableCounter {
+	return &TableCounter{d: d, db: db}
+}
+
+// CountRows implements RowCounter.
+func (c *TableCounter) CountRows(ctx context.Context, tables []string) (map[string]int64, error) {
+	for _, t := range tables {
+		if !tableIdent.MatchString(t) {
+			return nil, fmt.Errorf("count rows: invalid table name %q", t)
+		}
+	}
+	if c.d == Postgres {
+		return c.estimate(ctx, tables)
+	}
+	existing, err := c.names(ctx, c.d.Rebind(`SELECT name FROM sqlite_master WHERE type = 'table' AND name IN (`+placeholders(len(tables))+`)`), tables)
+	if err != nil {
+		return nil, fmt.Errorf("count rows: %w", err)
+	}
+	out := make(map[string]int64, len(existing))
+	for _, t := range existing {
+		var n int64
+		// t matched tableIdent, so it needs no quoting.
+		if err := c.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+t).Scan(&n); err != nil {
+			return nil, fmt.Errorf("count rows of %s: %w", t, err)
+		}
+		out[t] = n
+	}
+	return out, nil
+}
+
+// estimate reads Postgres' row estimates. A table never analyzed reports
+// -1 per partition, counted as none.
+func (c *TableCounter) estimate(ctx context.Context, tables []string) (map[string]int64, error) {
+	query := c.d.Rebind(`
+SELECT p.relname::text, SUM(GREATEST(c.reltuples, 0))::bigint
+FROM pg_class p
+LEFT JOIN pg_inherits i ON i.inhparent = p.oid
+JOIN pg_class c ON c.oid = COALESCE(i.inhrelid, p.oid)
+WHERE p.relnamespace = current_schema()::regnamespace AND p.relkind IN ('r', 'p')
+  AND p.relname IN (` + placeholders(len(tables)) + `)
+GROUP BY p.relname`)
+	rows, err := c.db.QueryContext(ctx, query, anySlice(tables)...)
+	if err != nil {
+		return nil, fmt.Errorf("estimate rows: %w", err)
+	}
+	defer rows.Close()
+	out := make(map[string]int64, len(tables))
+	for rows.Next() {
+		var (
+			name string
+			n    int64
+		)
+		if err := rows.Scan(&name, &n); err != nil {
+			return nil, fmt.Errorf("estimate rows: %w", err)
+		}
+		out[name] = n
+	}
+	return out, rows.Err()
+}
+
+func (c *TableCounter) names(ctx context.Context, query string, args []string) ([]string, error) {
+	rows, err := c.db.QueryContext(ctx, query, anySlice(args)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// placeholders returns $1, ..., $n, for Rebind.
+func placeholders(n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		if i > 1 {
+			b.WriteString(", ")
+		}
+		b.WriteString(Postgres.Placeholder(i))
+	}
+	return b.String()
+}
+
+func anySlice(ss []string) []any {
+	out := make([]any, len(ss))
+	for i, s := range ss {
+		out[i] = s
+	}
+	return out
+}
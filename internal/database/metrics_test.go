@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/metrics"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestQueryFamily(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestQueryFamily", "internal/database")
+
+	for query, want := range map[string]string{
+		"SELECT id, name FROM products WHERE id = $1":                 "select products",
+		"select count(*) from\n\t\"public\".\"price_history\"":        "select price_history",
+		"INSERT INTO price_history (listing_id, price) VALUES (?, ?)": "insert price_history",
+		"UPDATE product_listings SET price = $1":                      "update product_listings",
+		"DELETE FROM search_logs WHERE created_at < $1":               "delete search_logs",
+		"WITH recent AS (SELECT 1) SELECT * FROM recent":              "with",
+		"PRAGMA journal_mode":                                         "pragma",
+		"SELECT 1":                                                    "select",
+		"  ":                                                          "other",
+	} {
+		got := QueryFamily(query)
+		testhelpers.LogTestAssertion(logger, query, want, got)
+		if got != want {
+			t.Errorf("QueryFamily(%q) = %q, want %q", query, got, want)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestQueryFamily", true)
+}
+
+// fixedCounter counts rows from a map.
+type fixedCounter map[string]int64
+
+func (c fixedCounter) CountRows(_ context.Context, tables []string) (map[string]int64, error) {
+	out := make(map[string]int64)
+	for _, t := range tables {
+		if n, ok := c[t]; ok {
+			out[t] = n
+		}
+	}
+	return out, nil
+}
+
+func TestMetrics(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestMetrics", "internal/database")
+
+	testhelpers.LogTestStep(logger, "arrange", "An observed pool over a logging driver, watched by the metrics")
+	reg := metrics.NewRegistry()
+	m := NewMetrics(reg)
+	db, err := OpenObserved("database_test_tx", Config{DSN: t.Name(), Pool: DefaultPoolConfig()}, m.Observe)
+	if err != nil {
+		t.Fatalf("OpenObserved failed: %v", err)
+	}
+	defer db.Close()
+	m.WatchPool("primary", db)
+	rows := NewRowCountExporter(RowCountConfig{Tables: []string{"products", "price_history"}},
+		fixedCounter{"products": 3, "price_history": 1200, "audit_logs": 9}, reg, logger)
+
+	testhelpers.LogTestStep(logger, "act", "Running statements directly, cached and in a transaction, and counting rows")
+	ctx := t.Context()
+	if _, err := db.ExecContext(ctx, "UPDATE products SET name = ?", "Gold Standard"); err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	stmts := NewStatementCache(DefaultPoolConfig(), 0)
+	err = InTx(ctx, db, nil, func(ctx context.Context) error {
+		_, err := stmts.ExecContext(ctx, db, "INSERT INTO price_history (listing_id) VALUES (?)", "l1")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("InTx failed: %v", err)
+	}
+	if err := rows.Count(ctx); err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	var b strings.Builder
+	if err := reg.WriteText(&b); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Statements reached the driver and every metric is exported")
+	wantLog := []string{"UPDATE products SET name = ?", "begin", "tx: INSERT INTO price_history (listing_id) VALUES (?)", "commit"}
+	if got := testTxDriver.entries(t.Name()); !slices.Equal(got, wantLog) {
+		t.Errorf("driver log = %q, want %q", got, wantLog)
+	}
+	for _, line := range []string{
+		`db_query_duration_seconds_count{family="update products"} 1`,
+		`db_query_duration_seconds_count{family="insert price_history"} 1`,
+		`db_pool_open_connections{pool="primary"} 1`,
+		`db_pool_max_open_connections{pool="primary"} 20`,
+		`db_pool_waits_total{pool="primary"} 0`,
+		`db_table_rows{table="price_history"} 1200`,
+		`db_table_rows{table="products"} 3`,
+	} {
+		testhelpers.LogTestAssertion(logger, "exposition line", line, strings.Contains(b.String(), line+"\n"))
+		if !strings.Contains(b.String(), line+"\n") {
+			t.Errorf("exposition lacks %q:\n%s", line, b.String())
+		}
+	}
+	if strings.Contains(b.String(), "audit_logs") || strings.Contains(b.String(), "db_query_errors_total") {
+		t.Errorf("exposition has uncounted tables or errors:\n%s", b.String())
+	}
+
+	testhelpers.LogTestComplete(logger, "TestMetrics", true)
+}
@@ -0,0 +1,482 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Schema version errors, returned by Migrator.Check and Migrator.Up.
+var (
+	// ErrSchemaBehind means migrations are pending.
+	ErrSchemaBehind = errors.New("database schema is behind the migrations")
+	// ErrSchemaAhead means the database has migrations this build does not
+	// know: the build is older than the schema.
+	ErrSchemaAhead = errors.New("database schema is ahead of the migrations")
+	// ErrMigrationModified means an applied migration's file changed since.
+	ErrMigrationModified = errors.New("applied migration was modified")
+	// ErrIndexMissing means an index the queries rely on is missing, or
+	// invalid after a CREATE INDEX CONCURRENTLY that failed part way.
+	ErrIndexMissing = errors.New("database index is missing or invalid")
+)
+
+// migrationLockID is the Postgres advisory lock held while migrating, so
+// instances starting together apply each migration once.
+const migrationLockID = 0x77686579 // "whey"
+
+const (
+	createMigrationsTable = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    checksum TEXT NOT NULL,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`
+	migrationsTableExists = `SELECT to_regclass('schema_migrations') IS NOT NULL`
+	appliedMigrations     = `SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version`
+	recordMigration       = `INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`
+	// schemaIndexes lists the indexes of the tables in the search path's
+	// first schema, and whether queries may use them.
+	schemaIndexes = `
+SELECT c.relname::text, i.indisvalid FROM pg_index i
+JOIN pg_class c ON c.oid = i.indexrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = current_schema()`
+)
+
+// Migration is one schema change, the file NNN_description.sql.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+	// Checksum is the hex SHA-256 of SQL, recorded when it is applied.
+	Checksum string
+	// NoTransaction is set by a "-- Transaction: none" header line, for
+	// statements Postgres refuses inside a transaction such as CREATE
+	// INDEX CONCURRENTLY. A failure part way leaves the statements before
+	// it applied, so such migrations must be safe to run again.
+	NoTransaction bool
+}
+
+var migrationFile = regexp.MustCompile(`^(\d+)_[a-z0-9_]+\.sql$`)
+
+// LoadMigrations reads the .sql files at the root of fsys, which must be
+// numbered from 1 without gaps, in version order.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("list migrations: %w", err)
+	}
+	out := make([]Migration, 0, len(names))
+	for _, name := range names {
+		m := migrationFile.FindStringSubmatch(name)
+		if m == nil {
+			return nil, fmt.Errorf("migration %s: name must look like 008_add_things.sql", name)
+		}
+		version, _ := strconv.Atoi(m[1])
+		body, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", name, err)
+		}
+		sum := sha256.Sum256(body)
+		out = append(out, Migration{
+			Version:       version,
+			Name:          name,
+			SQL:           string(body),
+			Checksum:      hex.EncodeToString(sum[:]),
+			NoTransaction: noTransaction(string(body)),
+		})
+	}
+	slices.SortFunc(out, func(a, b Migration) int { return a.Version - b.Version })
+	for i, m := range out {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migration %s: expected version %d; versions must run from 1 without gaps or repeats", m.Name, i+1)
+		}
+	}
+	return out, nil
+}
+
+// noTransaction reports whether the header comment, the leading comment
+// lines, holds "-- Transaction: none", optionally followed by the reason.
+func noTransaction(body string) bool {
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "--") {
+			return false
+		}
+		k, v, ok := strings.Cut(strings.TrimSpace(strings.TrimPrefix(line, "--")), ":")
+		if f := strings.Fields(v); ok && strings.EqualFold(k, "Transaction") && len(f) > 0 && strings.EqualFold(f[0], "none") {
+			return true
+		}
+	}
+	return false
+}
+
+// MigrationStatus is a migration and whether it is applied.
+type MigrationStatus struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	// AppliedAt is zero for pending migrations.
+	AppliedAt time.Time `json:"applied_at,omitzero"`
+	// Modified marks an applied migration whose file changed since.
+	Modified bool `json:"modified,omitempty"`
+	// Unknown marks an applied migration this build has no file for.
+	Unknown bool `json:"unknown,omitempty"`
+}
+
+// Migrator applies migrations to a Postgres database, recording each in
+// the schema_migrations table.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	logger     *zap.Logger
+}
+
+// NewMigrator creates a Migrator applying migrations, as LoadMigrations
+// returns them, to db.
+func NewMigrator(db *sql.DB, migrations []Migration, logger *zap.Logger) *Migrator {
+	return &Migrator{db: db, migrations: migrations, logger: logger}
+}
+
+// Latest returns the version of the newest migration.
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// applied returns the recorded migrations by version, none if the table
+// is missing.
+func applied(ctx context.Context, q queryer) (map[int]MigrationStatus, map[int]string, error) {
+	var exists bool
+	if err := q.QueryRowContext(ctx, migrationsTableExists).Scan(&exists); err != nil {
+		return nil, nil, fmt.Errorf("check schema_migrations: %w", err)
+	}
+	done, sums := make(map[int]MigrationStatus), make(map[int]string)
+	if !exists {
+		return done, sums, nil
+	}
+	rows, err := q.QueryContext(ctx, appliedMigrations)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			s   MigrationStatus
+			sum string
+		)
+		if err := rows.Scan(&s.Version, &s.Name, &sum, &s.AppliedAt); err != nil {
+			return nil, nil, fmt.Errorf("read schema_migrations: %w", err)
+		}
+		done[s.Version], sums[s.Version] = s, sum
+	}
+	return done, sums, rows.Err()
+}
+
+// Status lists every migration, followed by any applied migrations this
+// build does not know.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	done, sums, err := applied(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := MigrationStatus{Version: mig.Version, Name: mig.Name}
+		if a, ok := done[mig.Version]; ok {
+			s.AppliedAt = a.AppliedAt
+			s.Modified = sums[mig.Version] != mig.Checksum
+			delete(done, mig.Version)
+		}
+		out = append(out, s)
+	}
+	for _, v := range slices.Sorted(maps.Keys(done)) {
+		s := done[v]
+		s.Unknown = true
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// Version returns the newest applied migration's version, 0 on a database
+// never migrated.
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	done, _, err := applied(ctx, m.db)
+	if err != nil {
+		return 0, err
+	}
+	version := 0
+	for v := range done {
+		version = max(version, v)
+	}
+	return version, nil
+}
+
+// Check returns ErrSchemaBehind, ErrSchemaAhead or ErrMigrationModified,
+// wrapped with what to do about it, unless the database has exactly the
+// migrations of this build applied.
+func (m *Migrator) Check(ctx context.Context) error {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	return checkStatus(status, m.Latest())
+}
+
+func checkStatus(status []MigrationStatus, latest int) error {
+	var pending []string
+	for _, s := range status {
+		switch {
+		case s.Unknown:
+			return fmt.Errorf("%w: migration %d is applied but this build only has up to %d; deploy a newer build", ErrSchemaAhead, s.Version, latest)
+		case s.Modified:
+			return fmt.Errorf("%w: %s changed after it was applied; restore the file and add a new migration instead", ErrMigrationModified, s.Name)
+		case s.AppliedAt.IsZero():
+			pending = append(pending, s.Name)
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %d pending (%s); run `admin migrate up`", ErrSchemaBehind, len(pending), strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// CheckIndexes returns ErrIndexMissing, wrapped with what to do about it,
+// if an index named in required is missing or any index is invalid. An
+// index dropped by hand, or left invalid by a failed concurrent build,
+// does not fail a query; it turns it into a scan of the whole table.
+func (m *Migrator) CheckIndexes(ctx context.Context, required []string) error {
+	rows, err := m.db.QueryContext(ctx, schemaIndexes)
+	if err != nil {
+		return fmt.Errorf("list indexes: %w", err)
+	}
+	defer rows.Close()
+	valid := make(map[string]bool)
+	for rows.Next() {
+		var (
+			name string
+			ok   bool
+		)
+		if err := rows.Scan(&name, &ok); err != nil {
+			return fmt.Errorf("list indexes: %w", err)
+		}
+		valid[name] = ok
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("list indexes: %w", err)
+	}
+	return checkIndexes(valid, required)
+}
+
+// checkIndexes checks the indexes found, by name with whether each is
+// valid, against required.
+func checkIndexes(valid map[string]bool, required []string) error {
+	var missing, invalid []string
+	for _, name := range required {
+		if _, ok := valid[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	for name, ok := range valid {
+		if !ok {
+			invalid = append(invalid, name)
+		}
+	}
+	slices.Sort(invalid)
+	var errs []error
+	if len(missing) > 0 {
+		errs = append(errs, fmt.Errorf("%w: %s missing; recreate each as the migration that adds it does", ErrIndexMissing, strings.Join(missing, ", ")))
+	}
+	for _, name := range invalid {
+		errs = append(errs, fmt.Errorf("%w: %s is invalid; run REINDEX INDEX CONCURRENTLY %s", ErrIndexMissing, name, name))
+	}
+	return errors.Join(errs...)
+}
+
+// Up applies the pending migrations up to and including version to, or
+// all of them if to is 0, each in its own transaction unless marked
+// otherwise, and returns how many it applied. It holds an advisory lock
+// throughout, so concurrent runs wait rather than race. It refuses to run
+// on a database ahead of the migrations or with a modified one.
+func (m *Migrator) Up(ctx context.Context, to int) (int, error) {
+	if to == 0 {
+		to = m.Latest()
+	}
+	logger := m.logger.With(zap.String("operation", "MigrateUp"))
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("connect: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return 0, fmt.Errorf("lock migrations: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrationLockID); err != nil {
+			logger.Warn("Failed to release the migration lock", zap.Error(err))
+		}
+	}()
+	if _, err := conn.ExecContext(ctx, createMigrationsTable); err != nil {
+		return 0, fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	done, sums, err := applied(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+	for v := range done {
+		if v > m.Latest() {
+			return 0, fmt.Errorf("%w: migration %d is applied but this build only has up to %d", ErrSchemaAhead, v, m.Latest())
+		}
+	}
+	count := 0
+	for _, mig := range m.migrations {
+		if mig.Version > to {
+			break
+		}
+		if _, ok := done[mig.Version]; ok {
+			if sums[mig.Version] != mig.Checksum {
+				return count, fmt.Errorf("%w: %s", ErrMigrationModified, mig.Name)
+			}
+			continue
+		}
+		start := time.Now()
+		if err := m.apply(ctx, conn, mig); err != nil {
+			logger.Error("Migration failed", zap.String("migration", mig.Name), zap.Error(err))
+			return count, fmt.Errorf("apply %s: %w", mig.Name, err)
+		}
+		count++
+		logger.Info("Migration applied",
+			zap.String("migration", mig.Name),
+			zap.Duration("duration", time.Since(start)),
+		)
+	}
+	return count, nil
+}
+
+// apply runs mig's statements one by one and records it.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mig Migration) error {
+	stmts := SplitStatements(mig.SQL)
+	if mig.NoTransaction {
+		for _, s := range stmts {
+			if _, err := conn.ExecContext(ctx, s); err != nil {
+				return err
+			}
+		}
+		_, err := conn.ExecContext(ctx, recordMigration, mig.Version, mig.Name, mig.Checksum)
+		return err
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Rolling back after Commit does nothing.
+	defer func() { _ = tx.Rollback() }()
+	for _, s := range stmts {
+		if _, err := tx.ExecContext(ctx, s); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, recordMigration, mig.Version, mig.Name, mig.Checksum); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SplitStatements splits a SQL script into its statements at the
+// semicolons outside quotes, dollar-quoted bodies and comments, dropping
+// statements that are only comments. Running statements one at a time
+// works with any driver, and lets Postgres run the ones it refuses in a
+// multi-statement query.
+func SplitStatements(script string) []string {
+	var (
+		out     []string
+		start   int
+		hasCode bool
+	)
+	flush := func(end int) {
+		if hasCode {
+			out = append(out, strings.TrimSpace(script[start:end]))
+		}
+		start, hasCode = end+1, false
+	}
+	for i := 0; i < len(script); i++ {
+		switch c := script[i]; {
+		case c == '-' && strings.HasPrefix(script[i:], "--"):
+			if j := strings.IndexByte(script[i:], '\n'); j >= 0 {
+				i += j
+			} else {
+				i = len(script)
+			}
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			if j := strings.Index(script[i+2:], "*/"); j >= 0 {
+				i += j + 3
+			} else {
+				i = len(script)
+			}
+		case c == '\'' || c == '"':
+			hasCode = true
+			// A doubled quote escapes itself, and leaves the scan inside.
+			for i++; i < len(script); i++ {
+				if script[i] == c {
+					if i+1 < len(script) && script[i+1] == c {
+						i++
+						continue
+					}
+					break
+				}
+			}
+		case c == '$':
+			hasCode = true
+			if tag := dollarTag(script[i:]); tag != "" {
+				if j := strings.Index(script[i+len(tag):], tag); j >= 0 {
+					i += len(tag) + j + len(tag) - 1
+				} else {
+					i = len(script)
+				}
+			}
+		case c == ';':
+			flush(i)
+		case c != ' ' && c != '\t' && c != '\n' && c != '\r':
+			hasCode = true
+		}
+	}
+	if start < len(script) {
+		flush(len(script))
+	}
+	return out
+}
+
+// dollarTag returns the $tag$ or $$ opening s, or "" if s starts with a
+// positional parameter such as $1 instead.
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '$':
+			return s[:i+1]
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 1 && c >= '0' && c <= '9':
+		default:
+			return ""
+		}
+	}
+	return ""
+}
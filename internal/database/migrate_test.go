@@ -0,0 +1,251 @@
+package database
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/deployments/postgres/migrations"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestLoadMigrations", "internal/database")
+
+	file := func(body string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(body)} }
+	tests := []struct {
+		name      string
+		fsys      fstest.MapFS
+		wantNames []string
+		wantNoTx  []bool
+		wantErr   bool
+	}{
+		{
+			name: "Orders by version",
+			fsys: fstest.MapFS{
+				"002_add_prices.sql": file("-- Prices\n-- Transaction: none (creates indexes CONCURRENTLY)\nCREATE INDEX CONCURRENTLY x ON y (z);"),
+				"001_init.sql":       file("-- Init\nCREATE TABLE y (z INT);"),
+				"README.md":          file("not a migration"),
+			},
+			wantNames: []string{"001_init.sql", "002_add_prices.sql"},
+			wantNoTx:  []bool{false, true},
+		},
+		{
+			name:    "Gap",
+			fsys:    fstest.MapFS{"001_init.sql": file(""), "003_later.sql": file("")},
+			wantErr: true,
+		},
+		{
+			name:    "Repeated version",
+			fsys:    fstest.MapFS{"001_init.sql": file(""), "01_again.sql": file("")},
+			wantErr: true,
+		},
+		{
+			name:    "Badly named",
+			fsys:    fstest.MapFS{"001-Init.sql": file("")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadMigrations(tt.fsys)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("LoadMigrations succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadMigrations failed: %v", err)
+			}
+			var names []string
+			var noTx []bool
+			for _, m := range got {
+				names = append(names, m.Name)
+				noTx = append(noTx, m.NoTransaction)
+				if len(m.Checksum) != 64 {
+					t.Errorf("%s checksum = %q, want hex SHA-256", m.Name, m.Checksum)
+				}
+			}
+			testhelpers.LogTestAssertion(logger, "names", tt.wantNames, names)
+			if !slices.Equal(names, tt.wantNames) || !slices.Equal(noTx, tt.wantNoTx) {
+				t.Errorf("Loaded %v %v, want %v %v", names, noTx, tt.wantNames, tt.wantNoTx)
+			}
+		})
+	}
+
+	t.Run("Embedded migrations", func(t *testing.T) {
+		got, err := LoadMigrations(migrations.FS)
+		if err != nil {
+			t.Fatalf("LoadMigrations failed: %v", err)
+		}
+		if len(got) == 0 || !got[0].NoTransaction {
+			t.Errorf("Want the auth schema first, outside a transaction; got %d migrations", len(got))
+		}
+		for _, m := range got {
+			if len(SplitStatements(m.SQL)) == 0 {
+				t.Errorf("%s has no statements", m.Name)
+			}
+		}
+	})
+
+	testhelpers.LogTestComplete(logger, "TestLoadMigrations", true)
+}
+
+func TestSplitStatements(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSplitStatements", "internal/database")
+
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "Statements and comments",
+			script: "-- header; not a statement\nCREATE TABLE a (x INT);\n/* block; comment */\nINSERT INTO a VALUES (1);\n-- trailing",
+			want:   []string{"-- header; not a statement\nCREATE TABLE a (x INT)", "/* block; comment */\nINSERT INTO a VALUES (1)"},
+		},
+		{
+			name:   "Quoted semicolons",
+			script: `INSERT INTO a VALUES ('x;y', 'it''s; fine'); SELECT "odd;name" FROM a`,
+			want:   []string{`INSERT INTO a VALUES ('x;y', 'it''s; fine')`, `SELECT "odd;name" FROM a`},
+		},
+		{
+			name: "Dollar-quoted body",
+			script: "CREATE FUNCTION f() RETURNS trigger AS $$\nBEGIN\n  NEW.x := 1;\n  RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql;\n" +
+				"DO $body$ BEGIN PERFORM 1; END $body$;",
+			want: []string{
+				"CREATE FUNCTION f() RETURNS trigger AS $$\nBEGIN\n  NEW.x := 1;\n  RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql",
+				"DO $body$ BEGIN PERFORM 1; END $body$",
+			},
+		},
+		{
+			name:   "Positional parameters",
+			script: "SELECT $1; SELECT $2",
+			want:   []string{"SELECT $1", "SELECT $2"},
+		},
+		{
+			name:   "Only comments",
+			script: "-- nothing here;\n/* nor; here */",
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitStatements(tt.script)
+			testhelpers.LogTestAssertion(logger, "statements", tt.want, got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SplitStatements = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSplitStatements", true)
+}
+
+func TestCheckStatus(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCheckStatus", "internal/database")
+
+	at := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		status  []MigrationStatus
+		wantErr error
+	}{
+		{
+			name:   "Current",
+			status: []MigrationStatus{{Version: 1, AppliedAt: at}, {Version: 2, AppliedAt: at}},
+		},
+		{
+			name:    "Behind",
+			status:  []MigrationStatus{{Version: 1, AppliedAt: at}, {Version: 2}},
+			wantErr: ErrSchemaBehind,
+		},
+		{
+			name:    "Ahead",
+			status:  []MigrationStatus{{Version: 1, AppliedAt: at}, {Version: 2, AppliedAt: at}, {Version: 3, AppliedAt: at, Unknown: true}},
+			wantErr: ErrSchemaAhead,
+		},
+		{
+			name:    "Modified",
+			status:  []MigrationStatus{{Version: 1, AppliedAt: at, Modified: true}, {Version: 2}},
+			wantErr: ErrMigrationModified,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkStatus(tt.status, 2)
+			testhelpers.LogTestAssertion(logger, "error", tt.wantErr, err)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Errorf("checkStatus = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCheckStatus", true)
+}
+
+func TestCheckIndexes(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCheckIndexes", "internal/database")
+
+	required := []string{"idx_listings_variant", "idx_event_outbox_pending"}
+	tests := []struct {
+		name      string
+		valid     map[string]bool
+		wantNames []string
+	}{
+		{
+			name:  "All present",
+			valid: map[string]bool{"idx_listings_variant": true, "idx_event_outbox_pending": true, "idx_brands_slug": true},
+		},
+		{
+			name:      "Dropped by hand",
+			valid:     map[string]bool{"idx_listings_variant": true},
+			wantNames: []string{"idx_event_outbox_pending missing"},
+		},
+		{
+			name:      "Failed concurrent build",
+			valid:     map[string]bool{"idx_listings_variant": true, "idx_event_outbox_pending": true, "idx_users_gdpr_retention": false},
+			wantNames: []string{"REINDEX INDEX CONCURRENTLY idx_users_gdpr_retention"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkIndexes(tt.valid, required)
+			testhelpers.LogTestAssertion(logger, "error", tt.wantNames, err)
+			if (err == nil) != (len(tt.wantNames) == 0) || (err != nil && !errors.Is(err, ErrIndexMissing)) {
+				t.Fatalf("checkIndexes = %v, want ErrIndexMissing naming %v", err, tt.wantNames)
+			}
+			for _, want := range tt.wantNames {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("checkIndexes = %v, want it to say %q", err, want)
+				}
+			}
+		})
+	}
+
+	t.Run("Required indexes are created by migrations", func(t *testing.T) {
+		all, err := LoadMigrations(migrations.FS)
+		if err != nil {
+			t.Fatalf("LoadMigrations failed: %v", err)
+		}
+		var schema strings.Builder
+		for _, m := range all {
+			schema.WriteString(m.SQL)
+		}
+		for _, name := range migrations.RequiredIndexes {
+			if !strings.Contains(schema.String(), "INDEX "+name+" ON") {
+				t.Errorf("No migration creates required index %s", name)
+			}
+		}
+	})
+
+	testhelpers.LogTestComplete(logger, "TestCheckIndexes", true)
+}
@@ -0,0 +1,284 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// QueryObserver is told of every statement a pool runs: its SQL, how long
+// it took, and its error. A query's time runs until its first rows are
+// ready, not until they are read.
+type QueryObserver func(ctx context.Context, query string, elapsed time.Duration, err error)
+
+// OpenObserved is Open with every statement run on the pool, through
+// database/sql directly, a cached statement or a transaction, reported to
+// observe. A nil observe is plain Open.
+func OpenObserved(driverName string, cfg Config, observe QueryObserver) (*sql.DB, error) {
+	if observe == nil {
+		return Open(driverName, cfg)
+	}
+	// sql.Open only looks the driver up; nothing connects.
+	probe, err := sql.Open(driverName, cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	drv := probe.Driver()
+	_ = probe.Close()
+
+	var connector driver.Connector = dsnConnector{dsn: cfg.DSN, drv: drv}
+	if dc, ok := drv.(driver.DriverContext); ok {
+		if connector, err = dc.OpenConnector(cfg.DSN); err != nil {
+			return nil, fmt.Errorf("open database: %w", err)
+		}
+	}
+	db := sql.OpenDB(observedConnector{Connector: connector, observe: observe})
+	cfg.Pool.Apply(db)
+	return db, nil
+}
+
+// dsnConnector connects through a driver without a Connector of its own.
+type dsnConnector struct {
+	dsn string
+	drv driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) { return c.drv.Open(c.dsn) }
+func (c dsnConnector) Driver() driver.Driver                        { return c.drv }
+
+type observedConnector struct {
+	driver.Connector
+	observe QueryObserver
+}
+
+func (c observedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &observedConn{Conn: conn, observe: c.observe}, nil
+}
+
+// Close closes the wrapped connector if it holds resources, as sql.DB.Close
+// would have.
+func (c observedConnector) Close() error {
+	if closer, ok := c.Connector.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// observedConn times the statements run on a driver connection. It offers
+// every optional interface database/sql looks for, falling back to what
+// database/sql itself does when the wrapped connection lacks one.
+type observedConn struct {
+	driver.Conn
+	observe QueryObserver
+}
+
+func (c *observedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *observedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = p.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &observedStmt{Stmt: stmt, conn: c, query: query}, nil
+}
+
+func (c *observedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		return nil, errors.New("sql: driver does not support non-default isolation level")
+	}
+	if opts.ReadOnly {
+		return nil, errors.New("sql: driver does not support read-only transactions")
+	}
+	return c.Conn.Begin()
+}
+
+func (c *observedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		// database/sql prepares the query instead, which is observed.
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := q.QueryContext(ctx, query, args)
+	c.report(ctx, query, start, err)
+	return rows, err
+}
+
+func (c *observedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	res, err := e.ExecContext(ctx, query, args)
+	c.report(ctx, query, start, err)
+	return res, err
+}
+
+// report observes a statement, unless the driver skipped it: database/sql
+// then runs it another way, which is observed instead.
+func (c *observedConn) report(ctx context.Context, query string, start time.Time, err error) {
+	if !errors.Is(err, driver.ErrSkip) {
+		c.observe(ctx, query, time.Since(start), err)
+	}
+}
+
+func (c *observedConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *observedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *observedConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *observedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	// database/sql converts the value as it would for this driver.
+	return driver.ErrSkip
+}
+
+// observedStmt times the executions of a prepared statement.
+type observedStmt struct {
+	driver.Stmt
+	conn  *observedConn
+	query string
+}
+
+func (s *observedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var (
+		res driver.Result
+		err error
+	)
+	if e, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = e.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = plainValues(args); err == nil {
+			res, err = s.Stmt.Exec(values)
+		}
+	}
+	s.conn.report(ctx, s.query, start, err)
+	return res, err
+}
+
+func (s *observedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var (
+		rows driver.Rows
+		err  error
+	)
+	if q, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = q.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = plainValues(args); err == nil {
+			rows, err = s.Stmt.Query(values)
+		}
+	}
+	s.conn.report(ctx, s.query, start, err)
+	return rows, err
+}
+
+// CheckNamedValue defers to the statement's checker, then the
+// connection's: database/sql asks only the statement when it has one.
+func (s *observedStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return s.conn.CheckNamedValue(nv)
+}
+
+// plainValues converts arguments for a driver without context methods,
+// which cannot take named ones.
+func plainValues(named []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(named))
+	for i, nv := range named {
+		if nv.Name != "" {
+			return nil, errors.New("sql: driver does not support the use of Named Parameters")
+		}
+		values[i] = nv.Value
+	}
+	return values, nil
+}
+
+// QueryFamily names the kind of statement query is for metrics and logs:
+// its verb and the table it acts on, such as "select product_listings" or
+// "insert price_history". Queries built with different numbers of
+// placeholders fall in one family, so the names stay few.
+func QueryFamily(query string) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return r == ' ' || r == '\n' || r == '\t' || r == '\r' || r == '(' || r == ')' || r == ',' || r == ';'
+	})
+	if len(words) == 0 {
+		return "other"
+	}
+	verb := words[0]
+	var after string
+	switch verb {
+	case "select", "delete":
+		after = "from"
+	case "insert":
+		after = "into"
+	case "update":
+		if len(words) > 1 {
+			return verb + " " + tableName(words[1])
+		}
+		return verb
+	default:
+		// WITH, PRAGMA, DDL and the like.
+		return verb
+	}
+	for i, w := range words[:len(words)-1] {
+		if w == after {
+			return verb + " " + tableName(words[i+1])
+		}
+	}
+	return verb
+}
+
+// tableName strips quotes and the schema from a table reference.
+func tableName(ref string) string {
+	ref = strings.Trim(ref, `"`+"`")
+	if _, name, ok := strings.Cut(ref, "."); ok {
+		return strings.Trim(name, `"`+"`")
+	}
+	return ref
+}
@@ -0,0 +1,161 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestParseURL(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestParseURL", "internal/database")
+
+	def := DefaultPoolConfig()
+	tests := []struct {
+		name     string
+		raw      string
+		wantPool PoolConfig
+		wantDSN  map[string]string // query parameters expected in the DSN
+		wantErr  string
+	}{
+		{
+			name:     "Defaults",
+			raw:      "postgres://whey:test-only-password@db:5432/whey?sslmode=disable",
+			wantPool: def,
+			wantDSN:  map[string]string{"sslmode": "disable", paramExecMode: ""},
+		},
+		{
+			name: "Pool parameters are stripped",
+			raw:  "postgres://db/whey?pool_max_conns=50&pool_max_idle_conns=5&pool_max_conn_idle_time=1m&pool_max_conn_lifetime=1h",
+			wantPool: PoolConfig{
+				MaxOpenConns: 50, MaxIdleConns: 5, ConnMaxIdleTime: time.Minute, ConnMaxLifetime: time.Hour,
+			},
+			wantDSN: map[string]string{paramMaxConns: "", paramMaxConnLifetime: ""},
+		},
+		{
+			name:     "Idle capped at open",
+			raw:      "postgres://db/whey?pool_max_conns=4",
+			wantPool: PoolConfig{MaxOpenConns: 4, MaxIdleConns: 4, ConnMaxIdleTime: def.ConnMaxIdleTime, ConnMaxLifetime: def.ConnMaxLifetime},
+		},
+		{
+			name:     "PgBouncer disables named prepared statements",
+			raw:      "postgres://pgbouncer:6432/whey?pgbouncer=true",
+			wantPool: PoolConfig{MaxOpenConns: 20, MaxIdleConns: 10, ConnMaxIdleTime: def.ConnMaxIdleTime, ConnMaxLifetime: def.ConnMaxLifetime, PgBouncer: true},
+			wantDSN:  map[string]string{paramExecMode: "exec", paramPgBouncer: ""},
+		},
+		{
+			name:     "PgBouncer keeps the simple protocol",
+			raw:      "postgres://pgbouncer:6432/whey?pgbouncer=true&default_query_exec_mode=simple_protocol",
+			wantPool: PoolConfig{MaxOpenConns: 20, MaxIdleConns: 10, ConnMaxIdleTime: def.ConnMaxIdleTime, ConnMaxLifetime: def.ConnMaxLifetime, PgBouncer: true},
+			wantDSN:  map[string]string{paramExecMode: "simple_protocol"},
+		},
+		{
+			name:    "PgBouncer rejects statement caching",
+			raw:     "postgres://pgbouncer:6432/whey?pgbouncer=true&default_query_exec_mode=cache_statement",
+			wantErr: "break under PgBouncer",
+		},
+		{name: "Wrong scheme", raw: "mysql://db/whey", wantErr: "must look like"},
+		{name: "Bad size", raw: "postgres://db/whey?pool_max_conns=0", wantErr: paramMaxConns},
+		{name: "Bad duration", raw: "postgres://db/whey?pool_max_conn_lifetime=forever", wantErr: paramMaxConnLifetime},
+		{name: "Bad flag", raw: "postgres://db/whey?pgbouncer=maybe", wantErr: paramPgBouncer},
+		{name: "Malformed URL hides password", raw: "postgres://whey:test-only-password@db:bad/whey", wantErr: "malformed URL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ParseURL(tt.raw, def)
+			if tt.wantErr != "" {
+				testhelpers.LogTestAssertion(logger, tt.name, tt.wantErr, err)
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want one containing %q", err, tt.wantErr)
+				}
+				if strings.Contains(err.Error(), "test-only-password") {
+					t.Errorf("Error leaks the password: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseURL failed: %v", err)
+			}
+			testhelpers.LogTestAssertion(logger, tt.name, tt.wantPool, cfg.Pool)
+			if cfg.Pool != tt.wantPool {
+				t.Errorf("Pool = %+v, want %+v", cfg.Pool, tt.wantPool)
+			}
+			u, _ := url.Parse(cfg.DSN)
+			for name, want := range tt.wantDSN {
+				if got := u.Query().Get(name); got != want {
+					t.Errorf("DSN %s = %q, want %q (%s)", name, got, want, cfg.DSN)
+				}
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestParseURL", true)
+}
+
+// fakeDriver opens connections that accept nothing, enough to exercise the
+// pool without a database.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func init() { sql.Register("database_test_fake", fakeDriver{}) }
+
+func TestOpen_AppliesPool(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestOpen_AppliesPool", "internal/database")
+
+	testhelpers.LogTestStep(logger, "arrange", "A three-connection pool over a fake driver")
+	cfg, err := ParseURL("postgres://db/whey?pool_max_conns=3&pool_max_idle_conns=1", DefaultPoolConfig())
+	if err != nil {
+		t.Fatalf("ParseURL failed: %v", err)
+	}
+	db, err := Open("database_test_fake", cfg)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	testhelpers.LogTestStep(logger, "act", "Holding every connection, then asking for one more")
+	ctx := t.Context()
+	var conns []*sql.Conn
+	for range 3 {
+		c, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatalf("Conn failed: %v", err)
+		}
+		conns = append(conns, c)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := db.Conn(waitCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Fourth Conn error = %v, want a timeout waiting for the pool", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Stats show the cap and the wait")
+	stats := Stats(db)
+	testhelpers.LogTestAssertion(logger, "stats", "3 in use, 1 wait", stats)
+	if stats.MaxOpen != 3 || stats.InUse != 3 || stats.WaitCount != 1 {
+		t.Errorf("Stats = %+v", stats)
+	}
+	for _, c := range conns {
+		_ = c.Close()
+	}
+	if stats := Stats(db); stats.Idle != 1 {
+		t.Errorf("Idle after release = %d, want MaxIdleConns 1", stats.Idle)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestOpen_AppliesPool", true)
+}
@@ -0,0 +1,247 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// LagProbe reports how far a replica is behind the primary.
+type LagProbe func(ctx context.Context, replica *sql.DB) (time.Duration, error)
+
+// PostgresLag measures lag as the age of the last replayed transaction. A
+// replica that has replayed everything it received is caught up and
+// reports zero, so an idle primary does not make its replicas look stale.
+func PostgresLag(ctx context.Context, replica *sql.DB) (time.Duration, error) {
+	var seconds float64
+	err := replica.QueryRowContext(ctx, `SELECT CASE
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END`).Scan(&seconds)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// RouterConfig configures read routing.
+type RouterConfig struct {
+	// MaxLag is the most a replica may trail the primary and still serve
+	// reads. Price data shown a few seconds late is fine; minutes is not.
+	MaxLag time.Duration
+	// CheckInterval is how often replica lag is probed.
+	CheckInterval time.Duration
+	// CheckTimeout bounds one probe.
+	CheckTimeout time.Duration
+	// Probe measures lag; it defaults to PostgresLag.
+	Probe LagProbe
+}
+
+// DefaultRouterConfig tolerates five seconds of lag, checked every two.
+func DefaultRouterConfig() RouterConfig {
+	return RouterConfig{
+		MaxLag:        5 * time.Second,
+		CheckInterval: 2 * time.Second,
+		CheckTimeout:  time.Second,
+		Probe:         PostgresLag,
+	}
+}
+
+// Router sends writes to the primary and reads to replicas that are within
+// MaxLag, so ingest bursts on the primary do not slow API reads. With no
+// usable replica, reads fall back to the primary. It is safe for concurrent
+// use.
+type Router struct {
+	cfg      RouterConfig
+	primary  *sql.DB
+	replicas []*replica
+	logger   *zap.Logger
+	next     atomic.Uint64
+}
+
+type replica struct {
+	db      *sql.DB
+	index   int
+	healthy atomic.Bool
+}
+
+// NewRouter creates a Router. Replicas start out unused until the first
+// CheckReplicas confirms them, so a lagging replica never serves a read.
+func NewRouter(cfg RouterConfig, primary *sql.DB, replicas []*sql.DB, logger *zap.Logger) *Router {
+	def := DefaultRouterConfig()
+	if cfg.MaxLag <= 0 {
+		cfg.MaxLag = def.MaxLag
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = def.CheckInterval
+	}
+	if cfg.CheckTimeout <= 0 {
+		cfg.CheckTimeout = def.CheckTimeout
+	}
+	if cfg.Probe == nil {
+		cfg.Probe = def.Probe
+	}
+	r := &Router{cfg: cfg, primary: primary, logger: logger}
+	for i, db := range replicas {
+		r.replicas = append(r.replicas, &replica{db: db, index: i})
+	}
+	return r
+}
+
+// ParseReplicaURLs reads the comma-separated DATABASE_REPLICA_URLS list,
+// each entry a URL as accepted by ParseURL.
+func ParseReplicaURLs(raw string, pool PoolConfig) ([]Config, error) {
+	var out []Config
+	for i, entry := range strings.Split(raw, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		cfg, err := ParseURL(entry, pool)
+		if err != nil {
+			return nil, fmt.Errorf("replica %d: %w", i, err)
+		}
+		out = append(out, cfg)
+	}
+	return out, nil
+}
+
+type primaryKey struct{}
+
+// WithPrimary marks ctx so reads made with it go to the primary. Use it
+// after a write whose result must be read back at once, such as an admin
+// edit followed by the re-fetch shown in its response.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// Writer returns the primary.
+func (r *Router) Writer() *sql.DB { return r.primary }
+
+// Reader returns a healthy replica, round-robin, or the primary when none
+// is healthy, ctx asks for it or ctx carries a transaction (see InTx), so
+// a unit of work reads its own writes.
+func (r *Router) Reader(ctx context.Context) *sql.DB {
+	if pinned, _ := ctx.Value(primaryKey{}).(bool); pinned || len(r.replicas) == 0 || inTx(ctx) {
+		return r.primary
+	}
+	start := r.next.Add(1)
+	for i := range len(r.replicas) {
+		rep := r.replicas[(start+uint64(i))%uint64(len(r.replicas))]
+		if rep.healthy.Load() {
+			return rep.db
+		}
+	}
+	return r.primary
+}
+
+// HealthyReplicas returns how many replicas currently serve reads.
+func (r *Router) HealthyReplicas() int {
+	n := 0
+	for _, rep := range r.replicas {
+		if rep.healthy.Load() {
+			n++
+		}
+	}
+	return n
+}
+
+// Replicas returns how many replicas the Router was given.
+func (r *Router) Replicas() int { return len(r.replicas) }
+
+// ProbeReplica measures replica i's lag once and reports an error when it
+// cannot be measured or exceeds MaxLag. It does not change which replicas
+// serve reads; Run does that.
+func (r *Router) ProbeReplica(ctx context.Context, i int) error {
+	if i < 0 || i >= len(r.replicas) {
+		return fmt.Errorf("replica %d: no such replica", i)
+	}
+	lag, err := r.cfg.Probe(ctx, r.replicas[i].db)
+	switch {
+	case err != nil:
+		return fmt.Errorf("replica %d: %w", i, err)
+	case lag > r.cfg.MaxLag:
+		return fmt.Errorf("replica %d: %s behind, more than %s", i, lag.Round(time.Millisecond), r.cfg.MaxLag)
+	}
+	return nil
+}
+
+// CheckReplicas probes every replica once, concurrently, and updates which
+// ones serve reads. It returns an error only when replicas exist and none
+// is usable.
+func (r *Router) CheckReplicas(ctx context.Context) error {
+	if len(r.replicas) == 0 {
+		return nil
+	}
+	var wg sync.WaitGroup
+	for _, rep := range r.replicas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.check(ctx, rep)
+		}()
+	}
+	wg.Wait()
+	if r.HealthyReplicas() == 0 {
+		return errors.New("no replica within max lag; reads are on the primary")
+	}
+	return nil
+}
+
+func (r *Router) check(ctx context.Context, rep *replica) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.CheckTimeout)
+	defer cancel()
+	lag, err := r.cfg.Probe(ctx, rep.db)
+	healthy := err == nil && lag <= r.cfg.MaxLag
+	if was := rep.healthy.Swap(healthy); was == healthy {
+		return
+	}
+	fields := []zap.Field{
+		zap.String("operation", "ReplicaCheck"),
+		zap.Int("replica", rep.index),
+		zap.Duration("lag", lag),
+		zap.Duration("max_lag", r.cfg.MaxLag),
+	}
+	switch {
+	case healthy:
+		r.logger.Info("Replica serving reads", fields...)
+	case err != nil:
+		r.logger.Warn("Replica check failed, reads moved off it", append(fields, zap.Error(err))...)
+	default:
+		r.logger.Warn("Replica lagging, reads moved off it", fields...)
+	}
+}
+
+// Run probes replicas every CheckInterval until ctx is done.
+func (r *Router) Run(ctx context.Context) {
+	if len(r.replicas) == 0 {
+		return
+	}
+	ticker := time.NewTicker(r.cfg.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = r.CheckReplicas(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Close closes the primary and every replica.
+func (r *Router) Close() error {
+	errs := []error{r.primary.Close()}
+	for _, rep := range r.replicas {
+		if err := rep.db.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("replica %d: %w", rep.index, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// lagTable is a LagProbe answering from a table the test edits.
+type lagTable struct {
+	mu   sync.Mutex
+	lag  map[*sql.DB]time.Duration
+	errs map[*sql.DB]error
+}
+
+func (l *lagTable) probe(_ context.Context, db *sql.DB) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lag[db], l.errs[db]
+}
+
+func (l *lagTable) set(db *sql.DB, lag time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lag[db], l.errs[db] = lag, err
+}
+
+func openFake(t *testing.T, name string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("database_test_fake", name)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	return db
+}
+
+func TestRouter_RoutesReads(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestRouter_RoutesReads", "internal/database")
+
+	testhelpers.LogTestStep(logger, "arrange", "A primary and two replicas")
+	ctx := t.Context()
+	primary, r1, r2 := openFake(t, "primary"), openFake(t, "r1"), openFake(t, "r2")
+	lags := &lagTable{lag: map[*sql.DB]time.Duration{}, errs: map[*sql.DB]error{}}
+	router := NewRouter(RouterConfig{MaxLag: time.Second, Probe: lags.probe}, primary, []*sql.DB{r1, r2}, logger)
+	defer router.Close()
+
+	testhelpers.LogTestStep(logger, "assert", "Unchecked replicas serve nothing")
+	if router.Reader(ctx) != primary {
+		t.Errorf("Reader before the first check is not the primary")
+	}
+
+	tests := []struct {
+		name      string
+		r1Lag     time.Duration
+		r2Err     error
+		wantReads []*sql.DB
+		wantErr   bool
+	}{
+		{name: "Both replicas healthy", wantReads: []*sql.DB{r1, r2}},
+		{name: "Lagging replica skipped", r1Lag: 3 * time.Second, wantReads: []*sql.DB{r2}},
+		{name: "Failing replica skipped", r2Err: errors.New("connection refused"), wantReads: []*sql.DB{r1}},
+		{name: "Falls back to primary", r1Lag: time.Minute, r2Err: errors.New("connection refused"), wantReads: []*sql.DB{primary}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lags.set(r1, tt.r1Lag, nil)
+			lags.set(r2, 0, tt.r2Err)
+			if err := router.CheckReplicas(ctx); (err != nil) != tt.wantErr {
+				t.Errorf("CheckReplicas error = %v, want error %v", err, tt.wantErr)
+			}
+			seen := map[*sql.DB]bool{}
+			for range 4 {
+				seen[router.Reader(ctx)] = true
+			}
+			testhelpers.LogTestAssertion(logger, tt.name, len(tt.wantReads), len(seen))
+			if len(seen) != len(tt.wantReads) {
+				t.Errorf("Reads went to %d databases, want %d", len(seen), len(tt.wantReads))
+			}
+			for _, db := range tt.wantReads {
+				if !seen[db] {
+					t.Errorf("Expected database never served a read")
+				}
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Pinning a read to the primary and writing")
+	lags.set(r1, 0, nil)
+	lags.set(r2, 0, nil)
+	_ = router.CheckReplicas(ctx)
+	if router.Reader(WithPrimary(ctx)) != primary {
+		t.Errorf("WithPrimary read did not go to the primary")
+	}
+	if router.Writer() != primary {
+		t.Errorf("Writer is not the primary")
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Probing each replica for readiness")
+	lags.set(r1, time.Minute, nil)
+	if router.Replicas() != 2 {
+		t.Errorf("Replicas = %d, want 2", router.Replicas())
+	}
+	if err := router.ProbeReplica(ctx, 0); err == nil {
+		t.Error("ProbeReplica of a lagging replica succeeded")
+	}
+	if err := router.ProbeReplica(ctx, 1); err != nil {
+		t.Errorf("ProbeReplica of a healthy replica: %v", err)
+	}
+	if err := router.ProbeReplica(ctx, 2); err == nil {
+		t.Error("ProbeReplica of a missing replica succeeded")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestRouter_RoutesReads", true)
+}
+
+func TestParseReplicaURLs(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestParseReplicaURLs", "internal/database")
+
+	cfgs, err := ParseReplicaURLs(" postgres://r1/whey?pool_max_conns=5, ,postgres://r2/whey ", DefaultPoolConfig())
+	if err != nil {
+		t.Fatalf("ParseReplicaURLs failed: %v", err)
+	}
+	testhelpers.LogTestAssertion(logger, "replicas", 2, len(cfgs))
+	if len(cfgs) != 2 || cfgs[0].Pool.MaxOpenConns != 5 || cfgs[1].Pool.MaxOpenConns != DefaultPoolConfig().MaxOpenConns {
+		t.Errorf("Replicas = %+v", cfgs)
+	}
+	if _, err := ParseReplicaURLs("postgres://r1/whey,redis://r2", DefaultPoolConfig()); err == nil {
+		t.Error("Invalid replica URL accepted")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestParseReplicaURLs", true)
+}
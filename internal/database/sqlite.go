@@ -0,0 +1,168 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SQLiteConfig tunes a SQLite database for a small deployment serving
+// reads while the scraper writes.
+type SQLiteConfig struct {
+	// JournalMode is the journal_mode pragma. WAL lets readers carry on
+	// while a write commits, and is what Litestream replicates.
+	JournalMode string
+	// BusyTimeout is how long a statement waits for another connection's
+	// lock, such as Litestream's during a checkpoint, before failing with
+	// SQLITE_BUSY.
+	BusyTimeout time.Duration
+	// Synchronous is the synchronous pragma. NORMAL is durable in WAL mode
+	// against application crashes; a power loss can drop the last commits.
+	Synchronous string
+	// Litestream marks a database replicated by litestream replicate.
+	// Automatic checkpoints are then turned off so that Litestream, which
+	// checkpoints after copying the WAL, never misses a page, and the
+	// database must be in WAL mode.
+	Litestream bool
+}
+
+// DefaultSQLiteConfig is WAL mode with NORMAL sync and a five second busy
+// timeout, the settings Litestream recommends.
+func DefaultSQLiteConfig() SQLiteConfig {
+	return SQLiteConfig{JournalMode: "wal", BusyTimeout: 5 * time.Second, Synchronous: "normal"}
+}
+
+// SQLite parameters accepted in DATABASE_URL's query string, named after
+// the pragmas they set.
+const (
+	paramJournalMode = "journal_mode"
+	paramBusyTimeout = "busy_timeout"
+	paramSynchronous = "synchronous"
+	paramLitestream  = "litestream"
+)
+
+var (
+	sqliteJournalModes = map[string]bool{"wal": true, "delete": true, "truncate": true, "persist": true}
+	sqliteSyncModes    = map[string]bool{"off": true, "normal": true, "full": true, "extra": true}
+)
+
+// parseSQLite reads a SQLite path with its tuning parameters, such as
+// data/sqlite/dev.db?busy_timeout=10s&litestream=true, into a Config
+// whose DSN sets the pragmas on every connection. Other parameters go to
+// the driver as they are.
+func parseSQLite(raw string) (Config, error) {
+	path, rawQuery, _ := strings.Cut(raw, "?")
+	if path == "" {
+		return Config{}, errors.New("database url: sqlite: needs a path, or :memory:")
+	}
+	q, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return Config{}, errors.New("database url: malformed sqlite parameters")
+	}
+
+	lite := DefaultSQLiteConfig()
+	if v := q.Get(paramJournalMode); v != "" {
+		lite.JournalMode = strings.ToLower(v)
+		if !sqliteJournalModes[lite.JournalMode] {
+			return Config{}, fmt.Errorf("database url: %s must be wal, delete, truncate or persist, got %q", paramJournalMode, v)
+		}
+	}
+	if v := q.Get(paramBusyTimeout); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return Config{}, fmt.Errorf("database url: %s must be a duration such as 5s, got %q", paramBusyTimeout, v)
+		}
+		lite.BusyTimeout = d
+	}
+	if v := q.Get(paramSynchronous); v != "" {
+		lite.Synchronous = strings.ToLower(v)
+		if !sqliteSyncModes[lite.Synchronous] {
+			return Config{}, fmt.Errorf("database url: %s must be off, normal, full or extra, got %q", paramSynchronous, v)
+		}
+	}
+	if v := q.Get(paramLitestream); v != "" {
+		if lite.Litestream, err = strconv.ParseBool(v); err != nil {
+			return Config{}, fmt.Errorf("database url: %s must be true or false, got %q", paramLitestream, v)
+		}
+	}
+	for _, name := range []string{paramJournalMode, paramBusyTimeout, paramSynchronous, paramLitestream} {
+		q.Del(name)
+	}
+
+	memory := strings.Contains(path, ":memory:") || q.Get("mode") == "memory"
+	if lite.Litestream && (memory || lite.JournalMode != "wal") {
+		return Config{}, errors.New("database url: litestream replicates only a database file in wal journal mode")
+	}
+
+	// busy_timeout goes first so that switching the journal mode waits out
+	// a lock instead of failing.
+	pragmas := []string{fmt.Sprintf("busy_timeout(%d)", lite.BusyTimeout.Milliseconds())}
+	if memory {
+		// An in-memory database has no file to journal beside.
+		lite.JournalMode = "memory"
+	} else {
+		pragmas = append(pragmas, "journal_mode("+lite.JournalMode+")")
+	}
+	pragmas = append(pragmas, "synchronous("+lite.Synchronous+")")
+	if lite.Litestream {
+		pragmas = append(pragmas, "wal_autocheckpoint(0)")
+	}
+	params := make([]string, 0, len(pragmas)+1)
+	for _, p := range pragmas {
+		params = append(params, "_pragma="+url.QueryEscape(p))
+	}
+	if rest := q.Encode(); rest != "" {
+		params = append(params, rest)
+	}
+	return Config{
+		Dialect: SQLite,
+		DSN:     path + "?" + strings.Join(params, "&"),
+		Pool:    SQLitePoolConfig(),
+		SQLite:  lite,
+	}, nil
+}
+
+// SQLiteStatus is what a SQLite database reports of its tuning.
+type SQLiteStatus struct {
+	JournalMode string        `json:"journal_mode"`
+	BusyTimeout time.Duration `json:"busy_timeout_ns"`
+	// Replicated reports that Litestream has attached to the database: it
+	// creates _litestream_seq on its first sync.
+	Replicated bool `json:"replicated"`
+}
+
+// CheckSQLite reads the tuning db runs with and returns why it does not
+// match cfg, if it does not. A database on a filesystem without shared
+// memory, such as some network mounts, cannot enter WAL mode and keeps
+// its old journal; Litestream cannot replicate it.
+func CheckSQLite(ctx context.Context, db *sql.DB, cfg SQLiteConfig) (SQLiteStatus, error) {
+	var (
+		status SQLiteStatus
+		ms     int64
+	)
+	if err := db.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&status.JournalMode); err != nil {
+		return status, fmt.Errorf("read journal_mode: %w", err)
+	}
+	status.JournalMode = strings.ToLower(status.JournalMode)
+	if err := db.QueryRowContext(ctx, "PRAGMA busy_timeout").Scan(&ms); err != nil {
+		return status, fmt.Errorf("read busy_timeout: %w", err)
+	}
+	status.BusyTimeout = time.Duration(ms) * time.Millisecond
+	err := db.QueryRowContext(ctx, "SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = '_litestream_seq'").Scan(new(int))
+	switch {
+	case err == nil:
+		status.Replicated = true
+	case !errors.Is(err, sql.ErrNoRows):
+		return status, fmt.Errorf("look for litestream: %w", err)
+	}
+
+	if cfg.JournalMode != "" && status.JournalMode != cfg.JournalMode {
+		return status, fmt.Errorf("sqlite journal_mode is %s, want %s; is the database on a filesystem without shared memory support?", status.JournalMode, cfg.JournalMode)
+	}
+	return status, nil
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// pragmaDriver answers PRAGMA journal_mode and busy_timeout with the DSN's
+// fields, journal_mode;busy_timeout;replicated, and the Litestream lookup
+// with a row if replicated is "yes".
+type pragmaDriver struct{}
+
+func (pragmaDriver) Open(dsn string) (driver.Conn, error) { return pragmaConn(dsn), nil }
+
+type pragmaConn string
+
+func (c pragmaConn) Prepare(query string) (driver.Stmt, error) { return pragmaStmt{c, query}, nil }
+func (pragmaConn) Close() error                                { return nil }
+func (pragmaConn) Begin() (driver.Tx, error)                   { return nil, io.EOF }
+
+type pragmaStmt struct {
+	c     pragmaConn
+	query string
+}
+
+func (pragmaStmt) Close() error                               { return nil }
+func (pragmaStmt) NumInput() int                              { return -1 }
+func (pragmaStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(0), nil }
+func (s pragmaStmt) Query([]driver.Value) (driver.Rows, error) {
+	fields := strings.Split(string(s.c), ";")
+	switch {
+	case s.query == "PRAGMA journal_mode":
+		return &valueRows{values: []driver.Value{fields[0]}}, nil
+	case s.query == "PRAGMA busy_timeout":
+		return &valueRows{values: []driver.Value{fields[1]}}, nil
+	case fields[2] == "yes":
+		return &valueRows{values: []driver.Value{int64(1)}}, nil
+	}
+	return &valueRows{}, nil
+}
+
+// valueRows is one single-column row per value.
+type valueRows struct{ values []driver.Value }
+
+func (r *valueRows) Columns() []string { return []string{"value"} }
+func (r *valueRows) Close() error      { return nil }
+func (r *valueRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0], r.values = r.values[0], r.values[1:]
+	return nil
+}
+
+func init() { sql.Register("database_test_pragma", pragmaDriver{}) }
+
+func TestCheckSQLite(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCheckSQLite", "internal/database")
+
+	for _, tc := range []struct {
+		name    string
+		dsn     string
+		cfg     SQLiteConfig
+		want    SQLiteStatus
+		wantErr string
+	}{
+		{
+			name: "WAL replicated by Litestream",
+			dsn:  "WAL;5000;yes",
+			cfg:  SQLiteConfig{JournalMode: "wal", Litestream: true},
+			want: SQLiteStatus{JournalMode: "wal", BusyTimeout: 5 * time.Second, Replicated: true},
+		},
+		{
+			name: "WAL not yet replicated",
+			dsn:  "wal;250;no",
+			cfg:  DefaultSQLiteConfig(),
+			want: SQLiteStatus{JournalMode: "wal", BusyTimeout: 250 * time.Millisecond},
+		},
+		{
+			name:    "WAL refused by the filesystem",
+			dsn:     "delete;5000;no",
+			cfg:     DefaultSQLiteConfig(),
+			want:    SQLiteStatus{JournalMode: "delete", BusyTimeout: 5 * time.Second},
+			wantErr: "journal_mode is delete, want wal",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			testhelpers.LogTestStep(logger, "arrange", "A database reporting "+tc.dsn)
+			db, err := sql.Open("database_test_pragma", tc.dsn)
+			if err != nil {
+				t.Fatalf("Open failed: %v", err)
+			}
+			defer db.Close()
+
+			testhelpers.LogTestStep(logger, "act", "Checking its tuning")
+			status, err := CheckSQLite(t.Context(), db, tc.cfg)
+
+			testhelpers.LogTestStep(logger, "assert", "The status is read and a mismatched journal reported")
+			testhelpers.LogTestAssertion(logger, "status", tc.want, status)
+			if status != tc.want {
+				t.Errorf("status = %+v, want %+v", status, tc.want)
+			}
+			if tc.wantErr == "" && err != nil {
+				t.Errorf("CheckSQLite failed: %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("err = %v, want one containing %q", err, tc.wantErr)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCheckSQLite", true)
+}
@@ -0,0 +1,208 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultMaxStatements bounds the statement cache. The hot queries are a
+// fixed set of constants; hitting the bound means a caller is caching
+// queries built from input, which would only churn server memory.
+const DefaultMaxStatements = 128
+
+// StatementCache prepares each query once per pool and reuses it, so the hot
+// comparison and history queries skip parsing on every request. Postgres
+// also switches a prepared statement to a cached generic plan after a few
+// executions when that plan is no worse, which ad-hoc queries never get.
+//
+// Behind PgBouncer in transaction pooling a named statement prepared on one
+// server connection is missing on the next, so with PoolConfig.PgBouncer set
+// the cache is bypassed and every query runs directly. It is safe for
+// concurrent use.
+type StatementCache struct {
+	bypass bool
+	max    int
+
+	mu    sync.Mutex
+	stmts map[stmtKey]*sql.Stmt
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	failures  atomic.Int64
+	bypassed  atomic.Int64
+	overflows atomic.Int64
+}
+
+// stmtKey identifies a statement: database/sql statements belong to one
+// pool, so the primary and each replica have their own.
+type stmtKey struct {
+	db    *sql.DB
+	query string
+}
+
+// StatementStats counts statement cache use.
+type StatementStats struct {
+	// Prepared is the number of statements currently cached.
+	Prepared int `json:"prepared"`
+	// Hits and Misses count lookups; a miss prepares the statement.
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+	// Failures counts prepares that failed; the query then ran directly.
+	Failures int64 `json:"failures"`
+	// Bypassed counts queries run directly because of PgBouncer.
+	Bypassed int64 `json:"bypassed"`
+	// Overflows counts queries run directly because the cache was full.
+	Overflows int64 `json:"overflows"`
+}
+
+// HitRatio returns hits over lookups, or zero before any lookup.
+func (s StatementStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// NewStatementCache creates a cache for pools configured by pool, holding at
+// most max statements; max <= 0 uses DefaultMaxStatements.
+func NewStatementCache(pool PoolConfig, max int) *StatementCache {
+	if max <= 0 {
+		max = DefaultMaxStatements
+	}
+	return &StatementCache{bypass: pool.PgBouncer, max: max, stmts: make(map[stmtKey]*sql.Stmt)}
+}
+
+// QueryContext runs query on db through its cached statement, in the
+// transaction ctx carries on db if any (see InTx).
+func (c *StatementCache) QueryContext(ctx context.Context, db *sql.DB, query string, args ...any) (*sql.Rows, error) {
+	if tx := txFor(ctx, db); tx != nil {
+		if stmt := c.cached(db, query); stmt != nil {
+			return tx.StmtContext(ctx, stmt).QueryContext(ctx, args...)
+		}
+		return tx.QueryContext(ctx, query, args...)
+	}
+	if stmt := c.stmt(ctx, db, query); stmt != nil {
+		return stmt.QueryContext(ctx, args...)
+	}
+	return db.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext runs a single-row query on db through its cached
+// statement, in the transaction ctx carries on db if any.
+func (c *StatementCache) QueryRowContext(ctx context.Context, db *sql.DB, query string, args ...any) *sql.Row {
+	if tx := txFor(ctx, db); tx != nil {
+		if stmt := c.cached(db, query); stmt != nil {
+			return tx.StmtContext(ctx, stmt).QueryRowContext(ctx, args...)
+		}
+		return tx.QueryRowContext(ctx, query, args...)
+	}
+	if stmt := c.stmt(ctx, db, query); stmt != nil {
+		return stmt.QueryRowContext(ctx, args...)
+	}
+	return db.QueryRowContext(ctx, query, args...)
+}
+
+// ExecContext runs a statement on db through its cached statement, in the
+// transaction ctx carries on db if any.
+func (c *StatementCache) ExecContext(ctx context.Context, db *sql.DB, query string, args ...any) (sql.Result, error) {
+	if tx := txFor(ctx, db); tx != nil {
+		if stmt := c.cached(db, query); stmt != nil {
+			return tx.StmtContext(ctx, stmt).ExecContext(ctx, args...)
+		}
+		return tx.ExecContext(ctx, query, args...)
+	}
+	if stmt := c.stmt(ctx, db, query); stmt != nil {
+		return stmt.ExecContext(ctx, args...)
+	}
+	return db.ExecContext(ctx, query, args...)
+}
+
+// cached returns the statement already cached for query on db, or nil. A
+// transaction uses the statements cached but does not prepare more: that
+// would take a second connection from the pool while the transaction holds
+// one, which a pool of one, like an in-memory SQLite database, does not
+// have.
+func (c *StatementCache) cached(db *sql.DB, query string) *sql.Stmt {
+	if c.bypass {
+		c.bypassed.Add(1)
+		return nil
+	}
+	c.mu.Lock()
+	stmt := c.stmts[stmtKey{db: db, query: query}]
+	c.mu.Unlock()
+	if stmt != nil {
+		c.hits.Add(1)
+	}
+	return stmt
+}
+
+// stmt returns the cached statement for query on db, preparing it on a
+// miss. It returns nil when the query should run directly.
+func (c *StatementCache) stmt(ctx context.Context, db *sql.DB, query string) *sql.Stmt {
+	if c.bypass {
+		c.bypassed.Add(1)
+		return nil
+	}
+	key := stmtKey{db: db, query: query}
+	c.mu.Lock()
+	stmt, ok := c.stmts[key]
+	full := len(c.stmts) >= c.max
+	c.mu.Unlock()
+	if ok {
+		c.hits.Add(1)
+		return stmt
+	}
+	if full {
+		c.overflows.Add(1)
+		return nil
+	}
+	c.misses.Add(1)
+
+	// Prepare outside the lock so a slow prepare does not stall lookups of
+	// other statements.
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		c.failures.Add(1)
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if existing, ok := c.stmts[key]; ok {
+		// A concurrent miss prepared it first.
+		_ = stmt.Close()
+		return existing
+	}
+	c.stmts[key] = stmt
+	return stmt
+}
+
+// Stats returns the cache counters.
+func (c *StatementCache) Stats() StatementStats {
+	c.mu.Lock()
+	prepared := len(c.stmts)
+	c.mu.Unlock()
+	return StatementStats{
+		Prepared:  prepared,
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Failures:  c.failures.Load(),
+		Bypassed:  c.bypassed.Load(),
+		Overflows: c.overflows.Load(),
+	}
+}
+
+// Close closes every cached statement. Close it before the pools it
+// prepared on.
+func (c *StatementCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var errs []error
+	for key, stmt := range c.stmts {
+		errs = append(errs, stmt.Close())
+		delete(c.stmts, key)
+	}
+	return errors.Join(errs...)
+}
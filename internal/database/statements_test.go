@@ -0,0 +1,164 @@
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// stmtDriver counts server-side prepares per query. Queries containing
+// "broken" fail to prepare.
+type stmtDriver struct {
+	mu       sync.Mutex
+	prepares map[string]int
+}
+
+func (d *stmtDriver) Open(string) (driver.Conn, error) { return stmtConn{d}, nil }
+
+func (d *stmtDriver) count(query string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.prepares[query]
+}
+
+type stmtConn struct{ d *stmtDriver }
+
+func (c stmtConn) Prepare(query string) (driver.Stmt, error) {
+	if strings.Contains(query, "broken") {
+		return nil, errors.New("syntax error")
+	}
+	c.d.mu.Lock()
+	c.d.prepares[query]++
+	c.d.mu.Unlock()
+	return stmtStmt{}, nil
+}
+func (stmtConn) Close() error              { return nil }
+func (stmtConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type stmtStmt struct{}
+
+func (stmtStmt) Close() error                               { return nil }
+func (stmtStmt) NumInput() int                              { return -1 }
+func (stmtStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (stmtStmt) Query([]driver.Value) (driver.Rows, error)  { return emptyRows{}, nil }
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string         { return []string{"id"} }
+func (emptyRows) Close() error              { return nil }
+func (emptyRows) Next([]driver.Value) error { return io.EOF }
+
+var testStmtDriver = &stmtDriver{prepares: make(map[string]int)}
+
+func init() { sql.Register("database_test_stmt", testStmtDriver) }
+
+func TestStatementCache(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStatementCache", "internal/database")
+
+	const (
+		history = "SELECT id FROM price_history WHERE listing_id = $1 AND recorded_at >= $2"
+		broken  = "SELECT broken FROM"
+	)
+	tests := []struct {
+		name                string
+		pool                PoolConfig
+		max                 int
+		queries             []string
+		wantHistoryPrepares int
+		wantStats           StatementStats
+		wantHitRatio        float64
+	}{
+		{
+			name:                "Prepares once and reuses",
+			pool:                DefaultPoolConfig(),
+			queries:             []string{history, history, history, history},
+			wantHistoryPrepares: 1,
+			wantStats:           StatementStats{Prepared: 1, Hits: 3, Misses: 1},
+			wantHitRatio:        0.75,
+		},
+		{
+			name:                "PgBouncer runs queries directly",
+			pool:                PoolConfig{MaxOpenConns: 1, MaxIdleConns: 1, PgBouncer: true},
+			queries:             []string{history, history},
+			wantHistoryPrepares: 2,
+			wantStats:           StatementStats{Bypassed: 2},
+		},
+		{
+			name:      "Failed prepare falls back",
+			pool:      DefaultPoolConfig(),
+			queries:   []string{broken, broken},
+			wantStats: StatementStats{Misses: 2, Failures: 2},
+		},
+		{
+			name:                "Full cache runs new queries directly",
+			pool:                DefaultPoolConfig(),
+			max:                 1,
+			queries:             []string{"SELECT 1", history, history},
+			wantHistoryPrepares: 2,
+			wantStats:           StatementStats{Prepared: 1, Misses: 1, Overflows: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testhelpers.LogTestStep(logger, "arrange", "A one-connection pool over a counting driver")
+			db, err := sql.Open("database_test_stmt", tt.name)
+			if err != nil {
+				t.Fatalf("Open failed: %v", err)
+			}
+			defer db.Close()
+			db.SetMaxOpenConns(1)
+			before := testStmtDriver.count(history)
+			c := NewStatementCache(tt.pool, tt.max)
+
+			testhelpers.LogTestStep(logger, "act", "Running the queries")
+			for i, q := range tt.queries {
+				var rows *sql.Rows
+				if i%2 == 0 {
+					rows, err = c.QueryContext(t.Context(), db, q, "lst_amazon_gsw_2270")
+				} else {
+					_, err = c.ExecContext(t.Context(), db, q)
+				}
+				if q == broken {
+					if err == nil {
+						t.Error("Broken query succeeded")
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("Query %q failed: %v", q, err)
+				}
+				if rows != nil {
+					_ = rows.Close()
+				}
+			}
+
+			testhelpers.LogTestStep(logger, "assert", "Prepare count and stats")
+			stats := c.Stats()
+			testhelpers.LogTestAssertion(logger, "stats", tt.wantStats, stats)
+			if stats != tt.wantStats {
+				t.Errorf("Stats = %+v, want %+v", stats, tt.wantStats)
+			}
+			if got := stats.HitRatio(); got != tt.wantHitRatio {
+				t.Errorf("HitRatio = %v, want %v", got, tt.wantHitRatio)
+			}
+			if got := testStmtDriver.count(history) - before; got != tt.wantHistoryPrepares {
+				t.Errorf("History prepares = %d, want %d", got, tt.wantHistoryPrepares)
+			}
+			if err := c.Close(); err != nil {
+				t.Errorf("Close failed: %v", err)
+			}
+			if c.Stats().Prepared != 0 {
+				t.Error("Close left statements cached")
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStatementCache", true)
+}
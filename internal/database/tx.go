@@ -0,0 +1,94 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Querier is what *sql.DB and *sql.Tx have in common.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type txKey struct{}
+
+// boundTx is a transaction and the pool it was begun on.
+type boundTx struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+// InTx runs fn in a transaction on db, committing it if fn returns nil and
+// rolling it back if fn fails or panics. The context fn gets carries the
+// transaction: statements made with it on db through Conn, a
+// StatementCache or a Router join the transaction, so repositories take
+// part without being handed a *sql.Tx. If ctx already carries a
+// transaction on db, fn joins it and the outermost InTx commits; opts then
+// are ignored.
+func InTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions, fn func(ctx context.Context) error) (err error) {
+	if txFor(ctx, db) != nil {
+		return fn(ctx)
+	}
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	if err := fn(context.WithValue(ctx, txKey{}, boundTx{db: db, tx: tx})); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// Conn returns the transaction ctx carries on db, or db itself.
+func Conn(ctx context.Context, db *sql.DB) Querier {
+	if tx := txFor(ctx, db); tx != nil {
+		return tx
+	}
+	return db
+}
+
+// txFor returns the transaction ctx carries on db, if any.
+func txFor(ctx context.Context, db *sql.DB) *sql.Tx {
+	if b, ok := ctx.Value(txKey{}).(boundTx); ok && b.db == db {
+		return b.tx
+	}
+	return nil
+}
+
+// inTx reports whether ctx carries a transaction on any pool.
+func inTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(boundTx)
+	return ok
+}
+
+// TxManager runs units of work in transactions on a primary. It
+// implements repositories.Transactor for the SQL repositories.
+type TxManager struct {
+	db *sql.DB
+}
+
+// NewTxManager creates a TxManager on db, the pool the repositories write
+// to: a Router's Writer.
+func NewTxManager(db *sql.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// InTx runs fn in a transaction; see the package function InTx.
+func (m *TxManager) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return InTx(ctx, m.db, nil, fn)
+}
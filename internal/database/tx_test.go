@@ -0,0 +1,199 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// txDriver logs begins, commits, rollbacks and statements, marking those
+// run inside a transaction.
+type txDriver struct {
+	mu  sync.Mutex
+	log map[string][]string // by DSN
+}
+
+func (d *txDriver) Open(dsn string) (driver.Conn, error) { return &txConn{d: d, dsn: dsn}, nil }
+
+func (d *txDriver) record(dsn, entry string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.log[dsn] = append(d.log[dsn], entry)
+}
+
+func (d *txDriver) entries(dsn string) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return slices.Clone(d.log[dsn])
+}
+
+type txConn struct {
+	d    *txDriver
+	dsn  string
+	inTx bool
+}
+
+func (c *txConn) Prepare(query string) (driver.Stmt, error) { return txStmt{c, query}, nil }
+func (c *txConn) Close() error                              { return nil }
+func (c *txConn) Begin() (driver.Tx, error) {
+	c.inTx = true
+	c.d.record(c.dsn, "begin")
+	return txTx{c}, nil
+}
+
+type txTx struct{ c *txConn }
+
+func (t txTx) Commit() error {
+	t.c.inTx = false
+	t.c.d.record(t.c.dsn, "commit")
+	return nil
+}
+
+func (t txTx) Rollback() error {
+	t.c.inTx = false
+	t.c.d.record(t.c.dsn, "rollback")
+	return nil
+}
+
+type txStmt struct {
+	c     *txConn
+	query string
+}
+
+func (s txStmt) Close() error  { return nil }
+func (s txStmt) NumInput() int { return -1 }
+func (s txStmt) Exec([]driver.Value) (driver.Result, error) {
+	entry := s.query
+	if s.c.inTx {
+		entry = "tx: " + entry
+	}
+	s.c.d.record(s.c.dsn, entry)
+	return driver.RowsAffected(1), nil
+}
+func (s txStmt) Query([]driver.Value) (driver.Rows, error) { return emptyRows{}, nil }
+
+var testTxDriver = &txDriver{log: make(map[string][]string)}
+
+func init() { sql.Register("database_test_tx", testTxDriver) }
+
+func TestInTx(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestInTx", "internal/database")
+
+	errFailed := errors.New("out of stock")
+	tests := []struct {
+		name    string
+		unit    func(ctx context.Context, db *sql.DB, stmts *StatementCache) error
+		wantErr error
+		wantLog []string
+	}{
+		{
+			name: "Commits when the unit succeeds",
+			unit: func(ctx context.Context, db *sql.DB, stmts *StatementCache) error {
+				return InTx(ctx, db, nil, func(ctx context.Context) error {
+					if _, err := Conn(ctx, db).ExecContext(ctx, "UPDATE products"); err != nil {
+						return err
+					}
+					_, err := stmts.ExecContext(ctx, db, "INSERT INTO event_outbox")
+					return err
+				})
+			},
+			wantLog: []string{"begin", "tx: UPDATE products", "tx: INSERT INTO event_outbox", "commit"},
+		},
+		{
+			name: "Rolls back when the unit fails",
+			unit: func(ctx context.Context, db *sql.DB, _ *StatementCache) error {
+				return InTx(ctx, db, nil, func(ctx context.Context) error {
+					if _, err := Conn(ctx, db).ExecContext(ctx, "UPDATE products"); err != nil {
+						return err
+					}
+					return errFailed
+				})
+			},
+			wantErr: errFailed,
+			wantLog: []string{"begin", "tx: UPDATE products", "rollback"},
+		},
+		{
+			name: "Nested units join the outer transaction",
+			unit: func(ctx context.Context, db *sql.DB, _ *StatementCache) error {
+				return NewTxManager(db).InTx(ctx, func(ctx context.Context) error {
+					return InTx(ctx, db, nil, func(ctx context.Context) error {
+						_, err := Conn(ctx, db).ExecContext(ctx, "UPDATE variants")
+						return err
+					})
+				})
+			},
+			wantLog: []string{"begin", "tx: UPDATE variants", "commit"},
+		},
+		{
+			name: "A failed nested unit rolls back the outer one",
+			unit: func(ctx context.Context, db *sql.DB, _ *StatementCache) error {
+				return InTx(ctx, db, nil, func(ctx context.Context) error {
+					if _, err := Conn(ctx, db).ExecContext(ctx, "UPDATE products"); err != nil {
+						return err
+					}
+					return InTx(ctx, db, nil, func(context.Context) error { return errFailed })
+				})
+			},
+			wantErr: errFailed,
+			wantLog: []string{"begin", "tx: UPDATE products", "rollback"},
+		},
+		{
+			name: "Statements outside a unit run on the pool",
+			unit: func(ctx context.Context, db *sql.DB, stmts *StatementCache) error {
+				_, err := stmts.ExecContext(ctx, db, "DELETE FROM search_logs")
+				return err
+			},
+			wantLog: []string{"DELETE FROM search_logs"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testhelpers.LogTestStep(logger, "arrange", "A one-connection pool over a logging driver")
+			db, err := sql.Open("database_test_tx", tt.name)
+			if err != nil {
+				t.Fatalf("Open failed: %v", err)
+			}
+			defer db.Close()
+			db.SetMaxOpenConns(1)
+
+			testhelpers.LogTestStep(logger, "act", "Running the unit of work")
+			err = tt.unit(t.Context(), db, NewStatementCache(DefaultPoolConfig(), 0))
+
+			testhelpers.LogTestStep(logger, "assert", "The statements ran in one transaction, committed or rolled back")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			got := testTxDriver.entries(tt.name)
+			testhelpers.LogTestAssertion(logger, "driver log", tt.wantLog, got)
+			if !slices.Equal(got, tt.wantLog) {
+				t.Errorf("log = %q, want %q", got, tt.wantLog)
+			}
+		})
+	}
+
+	t.Run("Rolls back and repanics when the unit panics", func(t *testing.T) {
+		db, err := sql.Open("database_test_tx", t.Name())
+		if err != nil {
+			t.Fatalf("Open failed: %v", err)
+		}
+		defer db.Close()
+		defer func() {
+			if recover() == nil {
+				t.Error("InTx swallowed the panic")
+			}
+			if got, want := testTxDriver.entries(t.Name()), []string{"begin", "rollback"}; !slices.Equal(got, want) {
+				t.Errorf("log = %q, want %q", got, want)
+			}
+		}()
+		_ = InTx(t.Context(), db, nil, func(context.Context) error { panic("boom") })
+	})
+
+	testhelpers.LogTestComplete(logger, "TestInTx", true)
+}
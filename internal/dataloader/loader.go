@@ -0,0 +1,129 @@
+// Package dataloader batches and caches repository lookups for the lifetime
+// of one request, turning N lookups by ID into a single query.
+//
+// Batching is explicit rather than timer-based: code that is about to look
+// up many keys announces them with Prefetch, and the first Load that misses
+// the cache fetches every announced key in one call. Code that only ever
+// calls Load still gets per-request caching.
+package dataloader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// BatchFunc fetches the values for keys. Keys it has no value for are left
+// out of the result.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader caches values by key and fetches misses in batches. It is safe for
+// concurrent use. Errors are not cached, so a failed key is retried by the
+// next Load.
+type Loader[K comparable, V any] struct {
+	fetch BatchFunc[K, V]
+
+	mu      sync.Mutex
+	values  map[K]V
+	missing map[K]bool
+	queued  []K
+	batches int
+}
+
+// New creates a Loader fetching through fetch.
+func New[K comparable, V any](fetch BatchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{fetch: fetch, values: make(map[K]V), missing: make(map[K]bool)}
+}
+
+// Prefetch queues keys to be fetched with the next batch. It never queries.
+func (l *Loader[K, V]) Prefetch(keys ...K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, k := range keys {
+		if !l.known(k) {
+			l.queued = append(l.queued, k)
+		}
+	}
+}
+
+// Prime stores a value obtained elsewhere, such as from a list query, so
+// loading it costs nothing.
+func (l *Loader[K, V]) Prime(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.values[key] = value
+	delete(l.missing, key)
+}
+
+// Load returns the value for key, fetching it together with every queued
+// key on a miss. A key the batch has no value for is domain.ErrNotFound.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	values, err := l.LoadMany(ctx, []K{key})
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	v, ok := values[key]
+	if !ok {
+		var zero V
+		return zero, fmt.Errorf("%v: %w", key, domain.ErrNotFound)
+	}
+	return v, nil
+}
+
+// LoadMany returns the values for keys, fetching misses and queued keys in
+// one batch. Keys without a value are absent from the result.
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) (map[K]V, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var batch []K
+	seen := make(map[K]bool)
+	for _, k := range append(l.queued, keys...) {
+		if !seen[k] && !l.known(k) {
+			seen[k] = true
+			batch = append(batch, k)
+		}
+	}
+	if len(batch) > 0 {
+		// The lock is held across the fetch so concurrent callers wait for
+		// this batch instead of issuing an overlapping one.
+		fetched, err := l.fetch(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		l.queued = nil
+		l.batches++
+		for _, k := range batch {
+			if v, ok := fetched[k]; ok {
+				l.values[k] = v
+			} else {
+				l.missing[k] = true
+			}
+		}
+	}
+
+	out := make(map[K]V, len(keys))
+	for _, k := range keys {
+		if v, ok := l.values[k]; ok {
+			out[k] = v
+		}
+	}
+	return out, nil
+}
+
+// Batches reports how many fetches the Loader has made.
+func (l *Loader[K, V]) Batches() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.batches
+}
+
+func (l *Loader[K, V]) known(k K) bool {
+	if _, ok := l.values[k]; ok {
+		return true
+	}
+	return l.missing[k]
+}
@@ -0,0 +1,106 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// squares is a batch function over ints that records every batch and has no
+// value for negative keys.
+type squares struct {
+	batches [][]int
+	fail    error
+}
+
+func (s *squares) fetch(_ context.Context, keys []int) (map[int]int, error) {
+	s.batches = append(s.batches, slices.Clone(keys))
+	if s.fail != nil {
+		return nil, s.fail
+	}
+	out := make(map[int]int)
+	for _, k := range keys {
+		if k >= 0 {
+			out[k] = k * k
+		}
+	}
+	return out, nil
+}
+
+func TestLoader_Batches(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestLoader_Batches", "internal/dataloader")
+
+	ctx := t.Context()
+	src := &squares{}
+	l := New(src.fetch)
+
+	testhelpers.LogTestStep(logger, "act", "Prefetching three keys, then loading them one by one")
+	l.Prefetch(1, 2, 3)
+	for _, k := range []int{2, 1, 3} {
+		v, err := l.Load(ctx, k)
+		if err != nil || v != k*k {
+			t.Errorf("Load(%d) = %d, %v", k, v, err)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "All three came from one batch")
+	testhelpers.LogTestAssertion(logger, "batches", 1, l.Batches())
+	if len(src.batches) != 1 || !slices.Equal(src.batches[0], []int{1, 2, 3}) {
+		t.Errorf("Batches = %v, want [[1 2 3]]", src.batches)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Loading cached, missing and primed keys")
+	l.Prime(7, 50)
+	l.Prefetch(1, 4)
+	values, err := l.LoadMany(ctx, []int{3, 7, -1, 4})
+	if err != nil {
+		t.Fatalf("LoadMany failed: %v", err)
+	}
+	if len(values) != 3 || values[7] != 50 || values[4] != 16 {
+		t.Errorf("LoadMany = %v", values)
+	}
+	if len(src.batches) != 2 || !slices.Equal(src.batches[1], []int{4, -1}) {
+		t.Errorf("Second batch = %v, want only the unknown keys", src.batches)
+	}
+	if _, err := l.Load(ctx, -1); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Load(missing) error = %v, want ErrNotFound", err)
+	}
+	if len(src.batches) != 2 {
+		t.Errorf("Known-missing key was fetched again")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestLoader_Batches", true)
+}
+
+func TestLoader_ErrorsAreNotCached(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestLoader_ErrorsAreNotCached", "internal/dataloader")
+
+	ctx := t.Context()
+	src := &squares{fail: errors.New("connection reset")}
+	l := New(src.fetch)
+	l.Prefetch(1, 2)
+
+	testhelpers.LogTestStep(logger, "act", "Loading through a failing batch, then a healthy one")
+	if _, err := l.Load(ctx, 1); err == nil {
+		t.Fatal("Load succeeded through a failing batch")
+	}
+	src.fail = nil
+	v, err := l.Load(ctx, 2)
+
+	testhelpers.LogTestStep(logger, "assert", "The queued keys are retried together")
+	testhelpers.LogTestAssertion(logger, "value", 4, v)
+	if err != nil || v != 4 {
+		t.Errorf("Load(2) = %d, %v", v, err)
+	}
+	if len(src.batches) != 2 || !slices.Equal(src.batches[1], []int{1, 2}) {
+		t.Errorf("Batches = %v", src.batches)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestLoader_ErrorsAreNotCached", true)
+}
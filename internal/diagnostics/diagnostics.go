@@ -0,0 +1,157 @@
+// Package diagnostics serves runtime profiling and introspection for
+// operators: net/http/pprof, expvar and on-demand heap and goroutine dumps.
+// It belongs on its own port behind authentication, never on the public
+// listener: profiles expose memory contents and a CPU profile costs a core
+// for its duration.
+package diagnostics
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"path/filepath"
+	"runtime"
+	rpprof "runtime/pprof"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+)
+
+// Config configures the diagnostics handler.
+type Config struct {
+	// DumpDir is where POST /debug/dump writes profiles. Dumps are written
+	// to disk so they survive the incident that prompted them.
+	DumpDir string
+}
+
+// DefaultConfig writes dumps to the system temporary directory.
+func DefaultConfig() Config {
+	return Config{DumpDir: os.TempDir()}
+}
+
+// dumpKinds maps the kinds accepted by POST /debug/dump to runtime/pprof
+// profiles and their WriteTo debug levels. Goroutine dumps use level 2, the
+// full stack format of an unrecovered panic, which is what incident
+// responders read.
+var dumpKinds = map[string]struct {
+	profile string
+	debug   int
+}{
+	"heap":      {"heap", 0},
+	"goroutine": {"goroutine", 2},
+	"allocs":    {"allocs", 0},
+}
+
+// Handler serves the diagnostics endpoints.
+type Handler struct {
+	cfg    Config
+	logger *zap.Logger
+	mux    *http.ServeMux
+	now    func() time.Time
+	// dumping serialises dumps; two concurrent heap dumps double the pause.
+	dumping sync.Mutex
+}
+
+// NewHandler creates the handler, mounting:
+//
+//	GET  /debug/pprof/...        net/http/pprof
+//	GET  /debug/vars             expvar
+//	POST /debug/dump?kind=heap   write a profile to DumpDir
+func NewHandler(cfg Config, logger *zap.Logger) *Handler {
+	if cfg.DumpDir == "" {
+		cfg.DumpDir = DefaultConfig().DumpDir
+	}
+	h := &Handler{cfg: cfg, logger: logger, mux: http.NewServeMux(), now: time.Now}
+	h.mux.HandleFunc("GET /debug/pprof/", pprof.Index)
+	h.mux.HandleFunc("GET /debug/pprof/cmdline", pprof.Cmdline)
+	h.mux.HandleFunc("GET /debug/pprof/profile", pprof.Profile)
+	h.mux.HandleFunc("GET /debug/pprof/symbol", pprof.Symbol)
+	h.mux.HandleFunc("POST /debug/pprof/symbol", pprof.Symbol)
+	h.mux.HandleFunc("GET /debug/pprof/trace", pprof.Trace)
+	h.mux.Handle("GET /debug/vars", expvar.Handler())
+	h.mux.HandleFunc("POST /debug/dump", h.Dump)
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// DumpResult describes a written dump.
+type DumpResult struct {
+	Kind  string `json:"kind"`
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+}
+
+// Dump writes the profile named by the kind query parameter to DumpDir.
+func (h *Handler) Dump(w http.ResponseWriter, r *http.Request) {
+	kind := r.URL.Query().Get("kind")
+	spec, ok := dumpKinds[kind]
+	if !ok {
+		httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest,
+			"kind must be one of heap, goroutine or allocs", nil)
+		return
+	}
+
+	h.dumping.Lock()
+	defer h.dumping.Unlock()
+	if kind == "heap" {
+		// The heap profile reflects the last GC; collect so it is current.
+		runtime.GC()
+	}
+	ext := ".pprof"
+	if spec.debug > 0 {
+		ext = ".txt"
+	}
+	name := fmt.Sprintf("%s-%s-%d%s", kind, h.now().UTC().Format("20060102T150405.000Z"), os.Getpid(), ext)
+	path := filepath.Join(h.cfg.DumpDir, name)
+	n, err := writeProfile(path, spec.profile, spec.debug)
+	if err != nil {
+		h.logger.Error("Failed to write diagnostics dump",
+			zap.String("operation", "DiagnosticsDump"),
+			zap.String("kind", kind),
+			zap.Error(err),
+		)
+		httpx.WriteError(w, r, http.StatusInternalServerError, httpx.CodeInternal, "failed to write dump", nil)
+		return
+	}
+	h.logger.Info("Diagnostics dump written",
+		zap.String("operation", "DiagnosticsDump"),
+		zap.String("principal", httpx.Principal(r.Context())),
+		zap.String("kind", kind),
+		zap.String("path", path),
+		zap.Int64("bytes", n),
+	)
+	httpx.WriteJSON(w, http.StatusCreated, DumpResult{Kind: kind, Path: path, Bytes: n})
+}
+
+func writeProfile(path, profile string, debug int) (int64, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	if err := rpprof.Lookup(profile).WriteTo(f, debug); err != nil {
+		_ = f.Close()
+		return 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return 0, err
+	}
+	return info.Size(), f.Close()
+}
+
+// Publish exposes fn's result under name in /debug/vars. It is evaluated on
+// every read, so fn should be cheap, such as a Stats method. Publishing a
+// name twice panics, as with expvar.
+func Publish(name string, fn func() any) {
+	expvar.Publish(name, expvar.Func(fn))
+}
@@ -0,0 +1,91 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestHandler_Dump(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestHandler_Dump", "internal/diagnostics")
+
+	h := NewHandler(Config{DumpDir: t.TempDir()}, zap.NewNop())
+	tests := []struct {
+		name       string
+		kind       string
+		wantStatus int
+		wantSuffix string
+		wantText   string
+	}{
+		{"Goroutine dump is readable text", "goroutine", http.StatusCreated, ".txt", "goroutine "},
+		{"Heap dump is a pprof profile", "heap", http.StatusCreated, ".pprof", ""},
+		{"Unknown kind", "threads", http.StatusBadRequest, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testhelpers.LogTestStep(logger, "act", "Requesting a "+tt.kind+" dump")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/dump?kind="+tt.kind, nil))
+
+			testhelpers.LogTestAssertion(logger, "status", tt.wantStatus, rec.Code)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+			var res DumpResult
+			if err := json.NewDecoder(rec.Body).Decode(&res); err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			data, err := os.ReadFile(res.Path)
+			if err != nil {
+				t.Fatalf("Dump not written: %v", err)
+			}
+			if !strings.HasSuffix(res.Path, tt.wantSuffix) || res.Bytes != int64(len(data)) || len(data) == 0 {
+				t.Errorf("Result = %+v, file has %d bytes", res, len(data))
+			}
+			if tt.wantText != "" && !strings.Contains(string(data), tt.wantText) {
+				t.Errorf("Dump lacks %q", tt.wantText)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestHandler_Dump", true)
+}
+
+func TestHandler_Routes(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestHandler_Routes", "internal/diagnostics")
+
+	Publish("diagnostics_test_pool", func() any { return map[string]int{"queued": 3} })
+	h := NewHandler(DefaultConfig(), zap.NewNop())
+	tests := []struct {
+		method, target string
+		wantStatus     int
+		wantBody       string
+	}{
+		{http.MethodGet, "/debug/pprof/", http.StatusOK, "goroutine"},
+		{http.MethodGet, "/debug/pprof/goroutine?debug=1", http.StatusOK, "goroutine profile"},
+		{http.MethodGet, "/debug/vars", http.StatusOK, `"diagnostics_test_pool": {"queued":3}`},
+		{http.MethodGet, "/debug/dump?kind=heap", http.StatusMethodNotAllowed, ""},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+		testhelpers.LogTestAssertion(logger, tt.method+" "+tt.target, tt.wantStatus, rec.Code)
+		if rec.Code != tt.wantStatus || !strings.Contains(rec.Body.String(), tt.wantBody) {
+			t.Errorf("%s %s = %d, want %d containing %q", tt.method, tt.target, rec.Code, tt.wantStatus, tt.wantBody)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestHandler_Routes", true)
+}
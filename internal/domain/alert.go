@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Alert conditions.
+const (
+	// ConditionPrice is met at or below TargetPrice.
+	ConditionPrice = "price"
+	// ConditionPricePerGram is met at or below TargetPricePerGram rupees
+	// per gram of protein.
+	ConditionPricePerGram = "price_per_gram_protein"
+	// ConditionPercentDrop is met once the price is DropPercent below
+	// BasePrice, the best price when the alert was created.
+	ConditionPercentDrop = "percent_drop"
+	// ConditionLow is met below the lowest price recorded over the previous
+	// LowDays days.
+	ConditionLow = "low"
+)
+
+// MaxLowDays bounds the window of a ConditionLow alert.
+const MaxLowDays = 365
+
+// PriceAlert asks to notify a user when a product's best in-stock offer
+// meets a condition; only the condition's own parameter is set. An alert
+// fires once when its condition is met and re-arms when it no longer is.
+type PriceAlert struct {
+	ID                 string  `json:"id"`
+	UserID             string  `json:"-"`
+	ProductID          string  `json:"product_id"`
+	Condition          string  `json:"condition"`
+	TargetPrice        float64 `json:"target_price,omitempty"`
+	TargetPricePerGram float64 `json:"target_price_per_gram_protein,omitempty"`
+	DropPercent        float64 `json:"drop_percent,omitempty"`
+	BasePrice          float64 `json:"base_price,omitempty"`
+	LowDays            int     `json:"low_days,omitempty"`
+	// Pending alerts were set without signing in and do not fire until
+	// confirmed from the link emailed to the address.
+	Pending     bool       `json:"pending,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	TriggeredAt *time.Time `json:"triggered_at,omitempty"`
+	// DeletedAt is set while the alert is deleted. A deleted alert does
+	// not fire and is only returned by AlertRepository.DeletedAlerts until
+	// restored.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// Validate checks the condition and its parameter, reporting problems as
+// ErrInvalid. BasePrice is not checked: it is recorded, not chosen.
+func (a PriceAlert) Validate() error {
+	params := []struct {
+		condition string
+		field     string
+		value     float64
+	}{
+		{ConditionPrice, "target_price", a.TargetPrice},
+		{ConditionPricePerGram, "target_price_per_gram_protein", a.TargetPricePerGram},
+		{ConditionPercentDrop, "drop_percent", a.DropPercent},
+		{ConditionLow, "low_days", float64(a.LowDays)},
+	}
+	known := false
+	for _, p := range params {
+		if p.condition == a.Condition {
+			known = true
+			continue
+		}
+		if p.value != 0 {
+			return fmt.Errorf("%s does not apply to %q alerts: %w", p.field, a.Condition, ErrInvalid)
+		}
+	}
+	switch {
+	case !known:
+		return fmt.Errorf("unknown alert condition %q: %w", a.Condition, ErrInvalid)
+	case a.Condition == ConditionPercentDrop && (a.DropPercent <= 0 || a.DropPercent >= 100):
+		return fmt.Errorf("drop_percent must be between 0 and 100: %w", ErrInvalid)
+	case a.Condition == ConditionLow && (a.LowDays < 1 || a.LowDays > MaxLowDays):
+		return fmt.Errorf("low_days must be between 1 and %d: %w", MaxLowDays, ErrInvalid)
+	case a.Condition == ConditionPrice && a.TargetPrice <= 0,
+		a.Condition == ConditionPricePerGram && a.TargetPricePerGram <= 0:
+		return fmt.Errorf("targets must be positive: %w", ErrInvalid)
+	}
+	return nil
+}
+
+// Threshold is the bound an alert condition comes down to for the current
+// prices: an in-stock offer meets it when its price, or its price per gram
+// of protein, is at or below Limit, or strictly below when Strict is set.
+type Threshold struct {
+	Limit   float64
+	PerGram bool
+	Strict  bool
+}
+
+// Matches reports whether o meets the threshold.
+func (t Threshold) Matches(o Offer) bool {
+	if !o.InStock {
+		return false
+	}
+	v := o.Price
+	if t.PerGram {
+		v = o.PricePerGramProtein
+	}
+	if v <= 0 {
+		return false
+	}
+	if t.Strict {
+		return v < t.Limit
+	}
+	return v <= t.Limit
+}
+
+// Notification types.
+const (
+	NotificationPriceAlert = "price_alert"
+	// NotificationPriceAlertDigest carries several price alerts in Items.
+	NotificationPriceAlertDigest = "price_alert_digest"
+)
+
+// Notification is a message queued for delivery to a user. The fields
+// describe the offer that caused it, so channels can render it without
+// reading the catalog again. Price alerts from a saved search name it
+// instead of an alert.
+type Notification struct {
+	ID           string  `json:"id"`
+	Type         string  `json:"type"`
+	UserID       string  `json:"user_id"`
+	AlertID      string  `json:"alert_id,omitempty"`
+	SearchID     string  `json:"search_id,omitempty"`
+	SearchName   string  `json:"search_name,omitempty"`
+	ProductID    string  `json:"product_id"`
+	ProductName  string  `json:"product_name"`
+	ImageURL     string  `json:"image_url,omitempty"`
+	RetailerID   string  `json:"retailer_id"`
+	RetailerName string  `json:"retailer_name"`
+	Price        float64 `json:"price"`
+	// PreviousPrice is what the offer cost before the change that caused
+	// the notification, when that was more.
+	PreviousPrice float64    `json:"previous_price,omitempty"`
+	PricePerGram  float64    `json:"price_per_gram_protein"`
+	URL           string     `json:"url"`
+	CreatedAt     time.Time  `json:"created_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	// HeldUntil is set while the notification waits for the user's digest.
+	HeldUntil *time.Time `json:"held_until,omitempty"`
+	// DeferredUntil is set while the notification waits out the user's
+	// quiet hours.
+	DeferredUntil *time.Time `json:"deferred_until,omitempty"`
+	// Items are the alerts a digest summarises.
+	Items []Notification `json:"items,omitempty"`
+	// Test marks a simulated alert sent so the user can check their
+	// channels. It is delivered at once, whatever the user's digest and
+	// topic settings.
+	Test bool `json:"test,omitempty"`
+	// DedupeKey, when set, identifies what caused the notification, so
+	// queueing it again for the same cause, as when an event is
+	// redelivered, does nothing.
+	DedupeKey string `json:"-"`
+	// Pixel is a site-relative image that records the message was
+	// opened, for channels that can embed one. It is set per channel at
+	// delivery and not stored.
+	Pixel string `json:"-"`
+}
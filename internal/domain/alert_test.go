@@ -0,0 +1,79 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestThreshold_Matches(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestThreshold_Matches", "internal/domain")
+
+	byPrice := domain.Threshold{Limit: 3000}
+	byGram := domain.Threshold{Limit: 1.7, PerGram: true}
+	below := domain.Threshold{Limit: 3000, Strict: true}
+	testCases := []struct {
+		name      string
+		threshold domain.Threshold
+		offer     domain.Offer
+		expect    bool
+	}{
+		{"Price below target", byPrice, domain.Offer{Price: 2999, InStock: true}, true},
+		{"Price at target", byPrice, domain.Offer{Price: 3000, InStock: true}, true},
+		{"Price above target", byPrice, domain.Offer{Price: 3001, InStock: true}, false},
+		{"Out of stock", byPrice, domain.Offer{Price: 2000}, false},
+		{"Per gram below target", byGram, domain.Offer{Price: 2999, PricePerGramProtein: 1.67, InStock: true}, true},
+		{"Per gram above target", byGram, domain.Offer{Price: 2999, PricePerGramProtein: 1.78, InStock: true}, false},
+		{"Per gram unknown", byGram, domain.Offer{Price: 2999, InStock: true}, false},
+		{"Strictly below", below, domain.Offer{Price: 2999, InStock: true}, true},
+		{"Strict at limit", below, domain.Offer{Price: 3000, InStock: true}, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.threshold.Matches(tc.offer)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.expect, got)
+			if got != tc.expect {
+				t.Errorf("Matches = %v, want %v", got, tc.expect)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestThreshold_Matches", true)
+}
+
+func TestPriceAlert_Validate(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPriceAlert_Validate", "internal/domain")
+
+	testCases := []struct {
+		name    string
+		alert   domain.PriceAlert
+		wantErr error
+	}{
+		{"Price", domain.PriceAlert{Condition: domain.ConditionPrice, TargetPrice: 3000}, nil},
+		{"Per gram", domain.PriceAlert{Condition: domain.ConditionPricePerGram, TargetPricePerGram: 1.5}, nil},
+		{"Percent drop", domain.PriceAlert{Condition: domain.ConditionPercentDrop, DropPercent: 10, BasePrice: 3199}, nil},
+		{"Low", domain.PriceAlert{Condition: domain.ConditionLow, LowDays: 30}, nil},
+		{"Unknown condition", domain.PriceAlert{Condition: "rising", TargetPrice: 3000}, domain.ErrInvalid},
+		{"Missing target", domain.PriceAlert{Condition: domain.ConditionPrice}, domain.ErrInvalid},
+		{"Negative target", domain.PriceAlert{Condition: domain.ConditionPricePerGram, TargetPricePerGram: -1}, domain.ErrInvalid},
+		{"Another condition's parameter", domain.PriceAlert{Condition: domain.ConditionPrice, TargetPrice: 3000, LowDays: 7}, domain.ErrInvalid},
+		{"Drop of 100%", domain.PriceAlert{Condition: domain.ConditionPercentDrop, DropPercent: 100}, domain.ErrInvalid},
+		{"Low over no days", domain.PriceAlert{Condition: domain.ConditionLow}, domain.ErrInvalid},
+		{"Low over too many days", domain.PriceAlert{Condition: domain.ConditionLow, LowDays: domain.MaxLowDays + 1}, domain.ErrInvalid},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.alert.Validate()
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Validate = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPriceAlert_Validate", true)
+}
@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// Actor identifies who performed an audited action, from where, and why.
+type Actor struct {
+	ID        string
+	IPAddress string
+	UserAgent string
+	RequestID string
+	Method    string
+	Endpoint  string
+	// Reason is the actor's own explanation of the change, if given.
+	Reason string
+}
+
+// AuditEntry is one row of the audit_logs table.
+type AuditEntry struct {
+	ID           string `json:"id"`
+	ActorID      string `json:"actor_id"`
+	Action       string `json:"action"`
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+	// Changes lists the fields an update changed; creations and removals
+	// carry the whole record in Metadata instead.
+	Changes      []AuditChange  `json:"changes,omitempty"`
+	IPAddress    string         `json:"ip_address,omitempty"`
+	UserAgent    string         `json:"user_agent,omitempty"`
+	HTTPMethod   string         `json:"http_method,omitempty"`
+	Endpoint     string         `json:"endpoint,omitempty"`
+	RequestID    string         `json:"request_id,omitempty"`
+	Success      bool           `json:"success"`
+	ErrorMessage string         `json:"error_message,omitempty"`
+	Metadata     map[string]any `json:"metadata,omitempty"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+// AuditChange is one field's value before and after an audited change,
+// as the record's JSON has it. Old or New is nil when the field was
+// absent.
+type AuditChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
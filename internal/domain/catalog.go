@@ -0,0 +1,130 @@
+// Package domain defines the core business types shared by services,
+// repositories and handlers. The types mirror the catalog schema in
+// deployments/postgres/migrations and deployments/sqlite/schema.sql.
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Sentinel errors returned by repositories and services.
+var (
+	ErrNotFound = errors.New("not found")
+	ErrInvalid  = errors.New("invalid input")
+	ErrConflict = errors.New("conflict")
+)
+
+// Brand is a supplement manufacturer.
+type Brand struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	Country  string `json:"country,omitempty"`
+	IsActive bool   `json:"active"`
+}
+
+// Category groups products, e.g. whey-isolate.
+type Category struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	ParentID string `json:"parent_id,omitempty"`
+}
+
+// Product is a protein product independent of flavour and pack size.
+type Product struct {
+	ID                   string  `json:"id"`
+	BrandID              string  `json:"brand_id"`
+	Brand                string  `json:"brand"`
+	CategoryID           string  `json:"category_id"`
+	Category             string  `json:"category"`
+	Name                 string  `json:"name"`
+	Slug                 string  `json:"slug"`
+	Description          string  `json:"description,omitempty"`
+	ProteinPerServing    float64 `json:"protein_per_serving"`
+	ServingsPerContainer int     `json:"servings"`
+	ServingSizeGrams     float64 `json:"serving_size_grams"`
+	ImageURL             string  `json:"image_url,omitempty"`
+	// Dietary lists the DietaryAttributes the name and description claim,
+	// shown as badges. Stores derive it on write.
+	Dietary   []string  `json:"dietary,omitempty"`
+	IsActive  bool      `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// DeletedAt is set while the product is deleted. A deleted product
+	// keeps its variants, listings and history until restored; only the
+	// admin repository returns it.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// Version counts the saves of the product. A save names the version it
+	// replaces and fails with ErrConflict if another save came first.
+	Version int `json:"version,omitempty"`
+}
+
+// Live reports whether p is shown: active and not deleted.
+func (p Product) Live() bool {
+	return p.IsActive && p.DeletedAt == nil
+}
+
+// ProteinGrams returns the total protein in a pack of sizeGrams, falling back
+// to the per-container figures when the serving size is unknown.
+func (p Product) ProteinGrams(sizeGrams int) float64 {
+	if p.ServingSizeGrams > 0 && sizeGrams > 0 {
+		return float64(sizeGrams) / p.ServingSizeGrams * p.ProteinPerServing
+	}
+	return p.ProteinPerServing * float64(p.ServingsPerContainer)
+}
+
+// Variant is a purchasable flavour/size combination of a product.
+type Variant struct {
+	ID        string `json:"id"`
+	ProductID string `json:"product_id"`
+	Flavor    string `json:"flavor,omitempty"`
+	Size      string `json:"size,omitempty"`
+	SizeGrams int    `json:"weight_grams"`
+	SKU       string `json:"sku,omitempty"`
+	IsActive  bool   `json:"-"`
+	// DeletedAt is set while the variant is deleted, as on Product.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// Version counts the saves of the variant, as on Product.
+	Version int `json:"version,omitempty"`
+}
+
+// Live reports whether v is shown: active and not deleted. Its product
+// must be live too.
+func (v Variant) Live() bool {
+	return v.IsActive && v.DeletedAt == nil
+}
+
+// Retailer is an online store we track prices at.
+type Retailer struct {
+	ID                string `json:"id"`
+	Name              string `json:"name"`
+	Slug              string `json:"slug"`
+	WebsiteURL        string `json:"website_url"`
+	LogoURL           string `json:"logo_url,omitempty"`
+	RequestsPerMinute int    `json:"-"`
+	IsActive          bool   `json:"active"`
+}
+
+// Listing is a variant sold by a specific retailer.
+type Listing struct {
+	ID                string    `json:"id"`
+	VariantID         string    `json:"variant_id"`
+	RetailerID        string    `json:"retailer_id"`
+	RetailerProductID string    `json:"retailer_product_id,omitempty"`
+	URL               string    `json:"url"`
+	CurrentPrice      float64   `json:"current_price"`
+	OriginalPrice     float64   `json:"original_price,omitempty"`
+	Currency          string    `json:"currency"`
+	InStock           bool      `json:"in_stock"`
+	LastScrapedAt     time.Time `json:"last_scraped_at"`
+	IsActive          bool      `json:"-"`
+}
+
+// ProductDetail is a product with its variants and the retailers listing it.
+type ProductDetail struct {
+	Product     Product   `json:"product"`
+	Variants    []Variant `json:"variants"`
+	RetailerIDs []string  `json:"retailer_ids"`
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// ClickEvent records one outbound click to a retailer for affiliate
+// attribution. The visitor's address is only stored as a salted hash;
+// UserID is set when they were signed in, and Referral when the click came
+// from a page such as a shared deal.
+type ClickEvent struct {
+	ID         string    `json:"id"`
+	ProductID  string    `json:"product_id"`
+	RetailerID string    `json:"retailer_id"`
+	ListingID  string    `json:"listing_id"`
+	UserID     string    `json:"user_id,omitempty"`
+	Price      float64   `json:"price"`
+	Referer    string    `json:"referer,omitempty"`
+	Referral   string    `json:"referral,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	IPHash     string    `json:"ip_hash,omitempty"`
+	RequestID  string    `json:"request_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
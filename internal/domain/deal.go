@@ -0,0 +1,38 @@
+package domain
+
+import "math"
+
+// Deal is a product's best current offer scored against its recent history.
+type Deal struct {
+	Product  Product `json:"product"`
+	Offer    Offer   `json:"deal"`
+	Score    float64 `json:"deal_score"`
+	Avg30d   float64 `json:"avg_30d"`
+	Low30d   float64 `json:"lowest_30d"`
+	High30d  float64 `json:"highest_30d"`
+	BelowAvg float64 `json:"below_avg_percent"`
+	// PricePerServing is what a serving costs at Offer, 0 if unknown.
+	PricePerServing float64 `json:"price_per_serving,omitempty"`
+}
+
+// BelowAveragePercent reports how far price sits below avg, or 0 if it doesn't.
+func BelowAveragePercent(price, avg float64) float64 {
+	if avg <= 0 || price >= avg {
+		return 0
+	}
+	return Round2((avg - price) / avg * 100)
+}
+
+// DealScore rates an offer from 0 to 100. The discount against MRP only counts
+// for half because retailers inflate MRP; every percent below the product's own
+// 30-day average counts in full, and matching the 30-day low earns a bonus.
+func DealScore(o Offer, avg30d, low30d float64) float64 {
+	if !o.InStock || o.Price <= 0 {
+		return 0
+	}
+	score := 0.5*o.DiscountPercent + BelowAveragePercent(o.Price, avg30d)
+	if low30d > 0 && o.Price <= low30d {
+		score += 10
+	}
+	return Round2(math.Max(0, math.Min(100, score)))
+}
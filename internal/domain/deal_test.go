@@ -0,0 +1,40 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestDealScore(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestDealScore", "internal/domain")
+
+	testCases := []struct {
+		name   string
+		offer  domain.Offer
+		avg    float64
+		low    float64
+		expect float64
+	}{
+		{"Out of stock scores zero", domain.Offer{Price: 2000, DiscountPercent: 50}, 3000, 2000, 0},
+		{"MRP discount only counts half", domain.Offer{Price: 3000, DiscountPercent: 20, InStock: true}, 3000, 2900, 10},
+		{"Below average counts in full", domain.Offer{Price: 2700, InStock: true}, 3000, 2500, 10},
+		{"Matching the 30-day low earns a bonus", domain.Offer{Price: 2700, InStock: true}, 3000, 2700, 20},
+		{"Capped at 100", domain.Offer{Price: 100, DiscountPercent: 95, InStock: true}, 3000, 100, 100},
+		{"No history", domain.Offer{Price: 3000, DiscountPercent: 10, InStock: true}, 0, 0, 5},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := domain.DealScore(tc.offer, tc.avg, tc.low)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.expect, got)
+			if got != tc.expect {
+				t.Errorf("DealScore = %v, want %v", got, tc.expect)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestDealScore", true)
+}
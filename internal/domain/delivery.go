@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// Failed delivery statuses.
+const (
+	// DeliveryRetrying deliveries are tried again at NextAttemptAt.
+	DeliveryRetrying = "retrying"
+	// DeliveryDead deliveries ran out of attempts and wait for an
+	// administrator to replay them.
+	DeliveryDead = "dead"
+)
+
+// FailedDelivery is a notification that one channel failed to deliver. It
+// is retried with backoff until it succeeds or runs out of attempts, and is
+// then dead-lettered.
+type FailedDelivery struct {
+	ID           string       `json:"id"`
+	Notification Notification `json:"notification"`
+	Channel      string       `json:"channel"`
+	Status       string       `json:"status"`
+	// Attempts counts failed tries since the first failure or the last
+	// replay.
+	Attempts      int       `json:"attempts"`
+	LastError     string    `json:"last_error"`
+	FailedAt      time.Time `json:"failed_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	NextAttemptAt time.Time `json:"next_attempt_at,omitzero"`
+}
@@ -0,0 +1,89 @@
+package domain
+
+import "slices"
+
+// Dietary attributes, read from what a product's name and description
+// claim.
+const (
+	DietVegan       = "vegan"
+	DietLactoseFree = "lactose-free"
+	DietGlutenFree  = "gluten-free"
+	DietSugarFree   = "sugar-free"
+)
+
+// DietaryAttributes lists every attribute in display order.
+var DietaryAttributes = []string{DietVegan, DietLactoseFree, DietGlutenFree, DietSugarFree}
+
+// dietaryLabels are the attributes as badges and facet values show them.
+var dietaryLabels = map[string]string{
+	DietVegan:       "Vegan",
+	DietLactoseFree: "Lactose-free",
+	DietGlutenFree:  "Gluten-free",
+	DietSugarFree:   "Sugar-free",
+}
+
+// DietaryLabel returns how attribute is shown, or attribute itself if it is
+// not one of DietaryAttributes.
+func DietaryLabel(attribute string) string {
+	if l, ok := dietaryLabels[attribute]; ok {
+		return l
+	}
+	return attribute
+}
+
+// IsDietary reports whether s is one of DietaryAttributes.
+func IsDietary(s string) bool {
+	_, ok := dietaryLabels[s]
+	return ok
+}
+
+// dietaryClaims are the phrases, as search terms, that claim each
+// attribute.
+var dietaryClaims = map[string][][]string{
+	DietVegan: {{"vegan"}, {"plant", "based"}, {"plant", "protein"}},
+	DietLactoseFree: {
+		{"lactose", "free"}, {"no", "lactose"}, {"zero", "lactose"}, {"without", "lactose"},
+		{"dairy", "free"}, {"no", "dairy"},
+	},
+	DietGlutenFree: {{"gluten", "free"}, {"no", "gluten"}, {"zero", "gluten"}, {"without", "gluten"}},
+	DietSugarFree: {
+		{"sugar", "free"}, {"no", "sugar"}, {"zero", "sugar"}, {"without", "sugar"},
+		{"no", "added", "sugar"}, {"0g", "sugar"}, {"0", "sugar"},
+	},
+}
+
+// negations turn a claim that follows them around: "not gluten free",
+// "non vegan".
+var negations = []string{"not", "non"}
+
+// ExtractDietary returns the dietary attributes text claims, in the order
+// of DietaryAttributes. A vegan product is also lactose-free. Claims right
+// after a negation are ignored.
+func ExtractDietary(text string) []string {
+	terms := SearchTerms(text)
+	found := make(map[string]bool)
+	for attr, claims := range dietaryClaims {
+	scan:
+		for i := range terms {
+			if i > 0 && slices.Contains(negations, terms[i-1]) {
+				continue
+			}
+			for _, claim := range claims {
+				if i+len(claim) <= len(terms) && slices.Equal(terms[i:i+len(claim)], claim) {
+					found[attr] = true
+					break scan
+				}
+			}
+		}
+	}
+	if found[DietVegan] {
+		found[DietLactoseFree] = true
+	}
+	var out []string
+	for _, attr := range DietaryAttributes {
+		if found[attr] {
+			out = append(out, attr)
+		}
+	}
+	return out
+}
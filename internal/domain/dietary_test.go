@@ -0,0 +1,34 @@
+package domain_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestExtractDietary(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestExtractDietary", "internal/domain")
+
+	testCases := []struct {
+		text   string
+		expect []string
+	}{
+		{"Plant-based pea protein", []string{domain.DietVegan, domain.DietLactoseFree}},
+		{"Whey isolate. Lactose free, gluten free, 0g sugar", []string{domain.DietLactoseFree, domain.DietGlutenFree, domain.DietSugarFree}},
+		{"Sweetened with stevia, NO ADDED SUGAR", []string{domain.DietSugarFree}},
+		{"Non-vegan formula, not gluten-free", nil},
+		{"Contains milk and soy", nil},
+	}
+	for _, tc := range testCases {
+		got := domain.ExtractDietary(tc.text)
+		testhelpers.LogTestAssertion(logger, tc.text, tc.expect, got)
+		if !slices.Equal(got, tc.expect) {
+			t.Errorf("ExtractDietary(%q) = %v, want %v", tc.text, got, tc.expect)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestExtractDietary", true)
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// DiscordWebhook is the Discord channel webhook a user's alerts are posted
+// to, typically one in a community server.
+type DiscordWebhook struct {
+	UserID string `json:"-"`
+	// URL carries the webhook's token, which lets anyone post to the
+	// channel, so it is never shown or logged after it is saved.
+	URL       string `json:"-"`
+	WebhookID string `json:"webhook_id"`
+	// Name, GuildID and ChannelID are as Discord reported them when the
+	// webhook was saved.
+	Name      string    `json:"name,omitempty"`
+	GuildID   string    `json:"guild_id,omitempty"`
+	ChannelID string    `json:"channel_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// Engagement event types, in the order a message goes through them.
+const (
+	EngagementDelivered = "delivered"
+	EngagementOpened    = "opened"
+	EngagementClicked   = "clicked"
+)
+
+// EngagementEvent records that a notification was delivered, opened or
+// clicked through on one channel. It names no user, so once a user's
+// notifications are erased it cannot be traced back to them.
+type EngagementEvent struct {
+	NotificationID string    `json:"notification_id"`
+	Channel        string    `json:"channel"`
+	Type           string    `json:"type"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// ChannelEngagement sums up how recipients engaged with one channel's
+// messages. Only channels that can carry a tracking pixel, i.e. email,
+// report opens.
+type ChannelEngagement struct {
+	Channel   string `json:"channel"`
+	Delivered int    `json:"delivered"`
+	Opened    int    `json:"opened"`
+	Clicked   int    `json:"clicked"`
+	// OpenRate and ClickRate are Opened and Clicked over Delivered.
+	OpenRate  float64 `json:"open_rate"`
+	ClickRate float64 `json:"click_rate"`
+}
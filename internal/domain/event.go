@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// Event is a change to catalog or price data that other components react
+// to, e.g. by evicting caches or evaluating price alerts.
+type Event interface {
+	EventType() string
+}
+
+// Event types.
+const (
+	EventPriceDropped    = "price.dropped"
+	EventPriceChanged    = "price.changed"
+	EventProductUpdated  = "product.updated"
+	EventSynonymsChanged = "search.synonyms_changed"
+	EventFlagsChanged    = "flags.changed"
+)
+
+// PriceChange describes a new price observation for a listing.
+type PriceChange struct {
+	ProductID  string    `json:"product_id"`
+	VariantID  string    `json:"variant_id"`
+	ListingID  string    `json:"listing_id"`
+	RetailerID string    `json:"retailer_id"`
+	OldPrice   float64   `json:"old_price"`
+	NewPrice   float64   `json:"new_price"`
+	Currency   string    `json:"currency"`
+	InStock    bool      `json:"in_stock"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// PriceDropped is published when a listing becomes cheaper.
+type PriceDropped struct{ PriceChange }
+
+// PriceChanged is published for any other price or stock change.
+type PriceChanged struct{ PriceChange }
+
+// NewPriceEvent classifies a price observation as a drop or other change.
+func NewPriceEvent(c PriceChange) Event {
+	if c.OldPrice > 0 && c.NewPrice < c.OldPrice {
+		return PriceDropped{c}
+	}
+	return PriceChanged{c}
+}
+
+// Parts of a product a ProductUpdated reports changed.
+const (
+	ProductChangeText      = "text"      // name, slug, description or image
+	ProductChangeNutrition = "nutrition" // protein and serving figures
+	ProductChangeBrand     = "brand"     // brand or category
+	ProductChangeVariants  = "variants"  // a variant added, edited or removed
+	ProductChangeStatus    = "status"    // created, activated or deactivated
+)
+
+// ProductUpdated is published when a product or one of its variants is
+// created, edited or removed.
+type ProductUpdated struct {
+	ProductID string `json:"product_id"`
+	BrandID   string `json:"brand_id"`
+	// Changes lists the parts that changed; empty means any may have.
+	Changes    []string  `json:"changes,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Changed reports whether any of parts may have changed.
+func (e ProductUpdated) Changed(parts ...string) bool {
+	if len(e.Changes) == 0 {
+		return true
+	}
+	for _, c := range e.Changes {
+		if slices.Contains(parts, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// EventType implements Event.
+func (PriceDropped) EventType() string { return EventPriceDropped }
+
+// EventType implements Event.
+func (PriceChanged) EventType() string { return EventPriceChanged }
+
+// EventType implements Event.
+func (ProductUpdated) EventType() string { return EventProductUpdated }
+
+// SynonymsChanged is published when a search synonym is added, changed or
+// removed.
+type SynonymsChanged struct {
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// EventType implements Event.
+func (SynonymsChanged) EventType() string { return EventSynonymsChanged }
+
+// FlagsChanged is published when a feature flag is set or removed.
+type FlagsChanged struct {
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// EventType implements Event.
+func (FlagsChanged) EventType() string { return EventFlagsChanged }
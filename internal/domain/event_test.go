@@ -0,0 +1,90 @@
+package domain_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestNewPriceEvent(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestNewPriceEvent", "internal/domain")
+
+	tests := []struct {
+		name     string
+		old, new float64
+		want     string
+	}{
+		{"cheaper", 3299, 3199, domain.EventPriceDropped},
+		{"dearer", 3199, 3299, domain.EventPriceChanged},
+		{"unchanged", 3199, 3199, domain.EventPriceChanged},
+		{"first observation", 0, 3199, domain.EventPriceChanged},
+	}
+	for _, tt := range tests {
+		testhelpers.LogTestStep(logger, "act", tt.name)
+		got := domain.NewPriceEvent(domain.PriceChange{ProductID: "prod_on_gsw", OldPrice: tt.old, NewPrice: tt.new}).EventType()
+		testhelpers.LogTestAssertion(logger, tt.name, tt.want, got)
+		if got != tt.want {
+			t.Errorf("%s: EventType = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestNewPriceEvent", true)
+}
+
+func TestProductUpdated_Changed(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestProductUpdated_Changed", "internal/domain")
+
+	unknown := domain.ProductUpdated{ProductID: "prod_on_gsw"}
+	nutrition := domain.ProductUpdated{ProductID: "prod_on_gsw", Changes: []string{domain.ProductChangeNutrition}}
+
+	testhelpers.LogTestAssertion(logger, "unknown changes", true, unknown.Changed(domain.ProductChangeText))
+	if !unknown.Changed(domain.ProductChangeText) {
+		t.Error("An event without changes should report any part changed")
+	}
+	if nutrition.Changed(domain.ProductChangeText, domain.ProductChangeBrand) || !nutrition.Changed(domain.ProductChangeText, domain.ProductChangeNutrition) {
+		t.Errorf("Changed misreports %v", nutrition.Changes)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestProductUpdated_Changed", true)
+}
+
+func TestOutboxMessage_RoundTrip(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestOutboxMessage_RoundTrip", "internal/domain")
+
+	at := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	sent := []domain.Event{
+		domain.NewPriceEvent(domain.PriceChange{ProductID: "prod_on_gsw", ListingID: "lst_1", OldPrice: 3299, NewPrice: 3199, OccurredAt: at}),
+		domain.ProductUpdated{ProductID: "prod_on_gsw", Changes: []string{domain.ProductChangeText}, OccurredAt: at},
+		domain.SynonymsChanged{OccurredAt: at},
+		domain.FlagsChanged{OccurredAt: at},
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Encoding events and decoding them back")
+	messages, err := domain.NewOutboxMessages(at, sent...)
+	if err != nil {
+		t.Fatalf("NewOutboxMessages: %v", err)
+	}
+	for i, m := range messages {
+		got, err := m.Event()
+		if err != nil {
+			t.Fatalf("Event: %v", err)
+		}
+		testhelpers.LogTestAssertion(logger, m.EventType, sent[i], got)
+		if !reflect.DeepEqual(got, sent[i]) || !m.CreatedAt.Equal(at) {
+			t.Errorf("message %d decoded to %#v, want %#v", i, got, sent[i])
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "An unknown type is an error")
+	if _, err := (domain.OutboxMessage{ID: "1", EventType: "price.vanished", Payload: []byte(`{}`)}).Event(); err == nil {
+		t.Error("Decoding an unknown event type succeeded")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestOutboxMessage_RoundTrip", true)
+}
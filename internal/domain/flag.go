@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxFlagName bounds a feature flag's name.
+const MaxFlagName = 64 // characters
+
+// Flag is a feature flag guarding a risky change, such as a new search
+// ranking, so it can be rolled out to a share of traffic and turned off
+// without a deploy. A flag is on for Percent percent of subjects while
+// Enabled; a boolean flag is one at 0 or 100.
+type Flag struct {
+	// Name is what code checks, such as "search.rerank": lowercase
+	// letters, digits and ._- only.
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Enabled is the kill switch: while false the flag is off for
+	// everyone, whatever Percent says.
+	Enabled bool `json:"enabled"`
+	// Percent is the share of subjects the flag is on for, from 0 to 100.
+	Percent   int       `json:"percent"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks the name and percentage, reporting problems as
+// ErrInvalid.
+func (f Flag) Validate() error {
+	if f.Name == "" || len(f.Name) > MaxFlagName {
+		return fmt.Errorf("flag name must be 1 to %d characters: %w", MaxFlagName, ErrInvalid)
+	}
+	for _, c := range f.Name {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '.' || c == '_' || c == '-') {
+			return fmt.Errorf("flag name %q may hold only lowercase letters, digits and ._-: %w", f.Name, ErrInvalid)
+		}
+	}
+	if f.Percent < 0 || f.Percent > 100 {
+		return fmt.Errorf("flag percent must be from 0 to 100: %w", ErrInvalid)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+// Integrity checks, the Check of an IntegrityFinding. Each looks for rows
+// breaking an invariant the schema cannot enforce.
+const (
+	// IntegrityOrphanVariant is a live variant whose product is missing or
+	// deleted, so its listings are scraped but never shown.
+	IntegrityOrphanVariant = "orphan_variant"
+	// IntegrityUnpricedProduct is a live product with no price recorded
+	// for longer than the checker allows, its offers going stale.
+	IntegrityUnpricedProduct = "unpriced_product"
+	// IntegrityCurrencyMismatch is a recorded price in a currency other
+	// than its listing's, which comparisons would mix up.
+	IntegrityCurrencyMismatch = "currency_mismatch"
+)
+
+// IntegrityChecks lists the checks in the order they run and are reported.
+var IntegrityChecks = []string{IntegrityOrphanVariant, IntegrityUnpricedProduct, IntegrityCurrencyMismatch}
+
+// IntegrityFinding is one row failing an integrity check, with the IDs
+// that locate it.
+type IntegrityFinding struct {
+	Check     string `json:"check"`
+	ProductID string `json:"product_id,omitempty"`
+	VariantID string `json:"variant_id,omitempty"`
+	ListingID string `json:"listing_id,omitempty"`
+	PriceID   string `json:"price_id,omitempty"`
+	// Currency and ListingCurrency are a mismatched price's currency and
+	// its listing's.
+	Currency        string `json:"currency,omitempty"`
+	ListingCurrency string `json:"listing_currency,omitempty"`
+}
+
+// IntegrityReport is the outcome of one run of the integrity checks.
+type IntegrityReport struct {
+	CheckedAt time.Time `json:"checked_at"`
+	// Counts is the number of findings per check, every check included.
+	Counts   map[string]int     `json:"counts"`
+	Findings []IntegrityFinding `json:"findings"`
+	// Truncated lists the checks that stopped at the finding limit, whose
+	// counts are a floor.
+	Truncated []string `json:"truncated,omitempty"`
+}
+
+// Clean reports whether the run found nothing.
+func (r IntegrityReport) Clean() bool {
+	return len(r.Findings) == 0
+}
@@ -0,0 +1,165 @@
+package domain
+
+import "github.com/yourusername/whey-price-compare/internal/jsonx"
+
+// Hand-written encoders for the comparison responses, the hottest payloads
+// the API serves. They must produce exactly what the struct tags would under
+// encoding/json; json_test.go checks that field by field, so a field added
+// to one of these types without updating its encoder fails the tests.
+//
+// Only Comparison implements jsonx.Appender: the others are embedded in
+// types with fields of their own, which a promoted AppendJSON would drop.
+
+func (p Product) appendJSON(b []byte) []byte {
+	b = jsonx.Key(append(b, '{'), "id", true)
+	b = jsonx.String(b, p.ID)
+	b = jsonx.Key(b, "brand_id", false)
+	b = jsonx.String(b, p.BrandID)
+	b = jsonx.Key(b, "brand", false)
+	b = jsonx.String(b, p.Brand)
+	b = jsonx.Key(b, "category_id", false)
+	b = jsonx.String(b, p.CategoryID)
+	b = jsonx.Key(b, "category", false)
+	b = jsonx.String(b, p.Category)
+	b = jsonx.Key(b, "name", false)
+	b = jsonx.String(b, p.Name)
+	b = jsonx.Key(b, "slug", false)
+	b = jsonx.String(b, p.Slug)
+	if p.Description != "" {
+		b = jsonx.Key(b, "description", false)
+		b = jsonx.String(b, p.Description)
+	}
+	b = jsonx.Key(b, "protein_per_serving", false)
+	b = jsonx.Float(b, p.ProteinPerServing)
+	b = jsonx.Key(b, "servings", false)
+	b = jsonx.Int(b, p.ServingsPerContainer)
+	b = jsonx.Key(b, "serving_size_grams", false)
+	b = jsonx.Float(b, p.ServingSizeGrams)
+	if p.ImageURL != "" {
+		b = jsonx.Key(b, "image_url", false)
+		b = jsonx.String(b, p.ImageURL)
+	}
+	if len(p.Dietary) > 0 {
+		b = jsonx.Key(b, "dietary", false)
+		b = append(b, '[')
+		for i, d := range p.Dietary {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = jsonx.String(b, d)
+		}
+		b = append(b, ']')
+	}
+	b = jsonx.Key(b, "created_at", false)
+	b = jsonx.Time(b, p.CreatedAt)
+	b = jsonx.Key(b, "updated_at", false)
+	b = jsonx.Time(b, p.UpdatedAt)
+	if p.DeletedAt != nil {
+		b = jsonx.Key(b, "deleted_at", false)
+		b = jsonx.Time(b, *p.DeletedAt)
+	}
+	if p.Version != 0 {
+		b = jsonx.Key(b, "version", false)
+		b = jsonx.Int(b, p.Version)
+	}
+	return append(b, '}')
+}
+
+func (o Offer) appendJSON(b []byte) []byte {
+	b = jsonx.Key(append(b, '{'), "listing_id", true)
+	b = jsonx.String(b, o.ListingID)
+	b = jsonx.Key(b, "retailer_id", false)
+	b = jsonx.String(b, o.RetailerID)
+	b = jsonx.Key(b, "retailer_name", false)
+	b = jsonx.String(b, o.RetailerName)
+	b = jsonx.Key(b, "variant_id", false)
+	b = jsonx.String(b, o.VariantID)
+	if o.Flavor != "" {
+		b = jsonx.Key(b, "flavor", false)
+		b = jsonx.String(b, o.Flavor)
+	}
+	b = jsonx.Key(b, "weight_grams", false)
+	b = jsonx.Int(b, o.SizeGrams)
+	b = jsonx.Key(b, "price", false)
+	b = jsonx.Float(b, o.Price)
+	if o.PriceDisplay != "" {
+		b = jsonx.Key(b, "price_display", false)
+		b = jsonx.String(b, o.PriceDisplay)
+	}
+	if o.WeightDisplay != "" {
+		b = jsonx.Key(b, "weight_display", false)
+		b = jsonx.String(b, o.WeightDisplay)
+	}
+	if o.OriginalPrice != 0 {
+		b = jsonx.Key(b, "original_price", false)
+		b = jsonx.Float(b, o.OriginalPrice)
+	}
+	b = jsonx.Key(b, "discount_percent", false)
+	b = jsonx.Float(b, o.DiscountPercent)
+	b = jsonx.Key(b, "currency", false)
+	b = jsonx.String(b, o.Currency)
+	b = jsonx.Key(b, "in_stock", false)
+	b = jsonx.Bool(b, o.InStock)
+	b = jsonx.Key(b, "url", false)
+	b = jsonx.String(b, o.URL)
+	b = jsonx.Key(b, "buy_url", false)
+	b = jsonx.String(b, o.BuyURL)
+	b = jsonx.Key(b, "price_per_gram_protein", false)
+	b = jsonx.Float(b, o.PricePerGramProtein)
+	b = jsonx.Key(b, "last_updated", false)
+	b = jsonx.Time(b, o.LastUpdated)
+	return append(b, '}')
+}
+
+func (s PriceStats) appendJSON(b []byte) []byte {
+	b = jsonx.Key(append(b, '{'), "lowest_price", true)
+	b = jsonx.Float(b, s.LowestPrice)
+	b = jsonx.Key(b, "highest_price", false)
+	b = jsonx.Float(b, s.HighestPrice)
+	b = jsonx.Key(b, "average_price", false)
+	b = jsonx.Float(b, s.AveragePrice)
+	b = jsonx.Key(b, "price_range", false)
+	b = jsonx.Float(b, s.PriceRange)
+	b = jsonx.Key(b, "retailers_in_stock", false)
+	b = jsonx.Int(b, s.RetailersInStock)
+	b = jsonx.Key(b, "total_retailers", false)
+	b = jsonx.Int(b, s.TotalRetailers)
+	b = jsonx.Key(b, "best_price_per_gram_protein", false)
+	b = jsonx.Float(b, s.BestPricePerGram)
+	b = jsonx.Key(b, "savings_vs_highest", false)
+	b = jsonx.Float(b, s.SavingsVsHighest)
+	b = jsonx.Key(b, "savings_percent", false)
+	b = jsonx.Float(b, s.SavingsPercentage)
+	return append(b, '}')
+}
+
+// AppendJSON implements jsonx.Appender.
+func (c *Comparison) AppendJSON(b []byte) []byte {
+	if c == nil {
+		return append(b, "null"...)
+	}
+	b = jsonx.Key(append(b, '{'), "product", true)
+	b = c.Product.appendJSON(b)
+	b = jsonx.Key(b, "prices", false)
+	if c.Prices == nil {
+		b = append(b, "null"...)
+	} else {
+		b = append(b, '[')
+		for i, o := range c.Prices {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = o.appendJSON(b)
+		}
+		b = append(b, ']')
+	}
+	b = jsonx.Key(b, "price_stats", false)
+	b = c.Stats.appendJSON(b)
+	if c.BestDeal != nil {
+		b = jsonx.Key(b, "best_deal", false)
+		b = c.BestDeal.appendJSON(b)
+	}
+	b = jsonx.Key(b, "last_updated", false)
+	b = jsonx.Time(b, c.LastUpdated)
+	return append(b, '}')
+}
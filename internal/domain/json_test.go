@@ -0,0 +1,114 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// fill sets every field reachable from v to a distinct non-zero value, so a
+// field the hand-written encoder misses shows up as a difference from
+// encoding/json.
+func fill(v reflect.Value, seed *int) {
+	*seed++
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(fmt.Sprintf("value <%d> \"quoted\"", *seed))
+	case reflect.Int:
+		v.SetInt(int64(*seed * 100))
+	case reflect.Float64:
+		v.SetFloat(float64(*seed) + 0.25)
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Pointer:
+		v.Set(reflect.New(v.Type().Elem()))
+		fill(v.Elem(), seed)
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 2, 2))
+		for i := range v.Len() {
+			fill(v.Index(i), seed)
+		}
+	case reflect.Struct:
+		if v.Type() == reflect.TypeFor[time.Time]() {
+			v.Set(reflect.ValueOf(time.Date(2024, 1, 15, 14, 30, *seed, 0, time.UTC)))
+			return
+		}
+		for i := range v.NumField() {
+			fill(v.Field(i), seed)
+		}
+	}
+}
+
+func sampleComparison() *domain.Comparison {
+	now := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	product := domain.Product{ID: "prod_on_gsw", Brand: "Optimum Nutrition", Name: "Gold Standard 100% Whey", ProteinPerServing: 24, ServingSizeGrams: 30.4}
+	var offers []domain.Offer
+	for i, r := range []string{"amazon", "flipkart", "healthkart", "nutrabay", "myprotein"} {
+		offers = append(offers, domain.Offer{
+			ListingID: "lst_" + r, RetailerID: r, RetailerName: r, VariantID: "var_2kg",
+			Flavor: "Double Rich Chocolate", SizeGrams: 2000, Price: 5999 + float64(i*100),
+			OriginalPrice: 6999, DiscountPercent: 14.3, Currency: domain.DefaultCurrency, InStock: i != 2,
+			URL: "https://example.com/" + r, BuyURL: "/go/lst_" + r, PricePerGramProtein: 3.8, LastUpdated: now,
+		})
+	}
+	return domain.NewComparison(product, offers)
+}
+
+func TestComparison_AppendJSONMatchesEncodingJSON(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestComparison_AppendJSONMatchesEncodingJSON", "internal/domain")
+
+	full := &domain.Comparison{}
+	seed := 0
+	fill(reflect.ValueOf(full).Elem(), &seed)
+
+	tests := []struct {
+		name string
+		c    *domain.Comparison
+	}{
+		{"every field set", full},
+		{"zero value", &domain.Comparison{}},
+		{"no offers", &domain.Comparison{Product: domain.Product{ID: "prod_on_gsw"}, Prices: []domain.Offer{}}},
+		{"built comparison", sampleComparison()},
+		{"nil", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := json.Marshal(tt.c)
+			if err != nil {
+				t.Fatalf("json.Marshal: %v", err)
+			}
+			got := tt.c.AppendJSON(nil)
+			testhelpers.LogTestAssertion(logger, tt.name, len(want), len(got))
+			if string(got) != string(want) {
+				t.Errorf("AppendJSON =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestComparison_AppendJSONMatchesEncodingJSON", true)
+}
+
+func BenchmarkComparisonJSON(b *testing.B) {
+	c := sampleComparison()
+	b.Run("encoding/json", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := json.Marshal(c); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("AppendJSON", func(b *testing.B) {
+		b.ReportAllocs()
+		buf := make([]byte, 0, 4096)
+		for b.Loop() {
+			buf = c.AppendJSON(buf[:0])
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// OutboxMessage is an event stored with the change that caused it, in the
+// same transaction, until the relay publishes it. An event is therefore
+// published if and only if its change was saved, even across crashes.
+type OutboxMessage struct {
+	ID        string          `json:"id"`
+	EventType string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+	// Attempts counts failed deliveries; LastError is the latest failure.
+	Attempts  int    `json:"attempts,omitempty"`
+	LastError string `json:"last_error,omitempty"`
+	// PublishedAt is set once every subscriber has handled the event.
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	// FailedAt is set when the relay gave up on the message.
+	FailedAt *time.Time `json:"failed_at,omitempty"`
+}
+
+// NewOutboxMessages encodes events for the outbox, created at at.
+func NewOutboxMessages(at time.Time, events ...Event) ([]OutboxMessage, error) {
+	out := make([]OutboxMessage, 0, len(events))
+	for _, e := range events {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return nil, fmt.Errorf("encode %s event: %w", e.EventType(), err)
+		}
+		out = append(out, OutboxMessage{EventType: e.EventType(), Payload: payload, CreatedAt: at})
+	}
+	return out, nil
+}
+
+// Event decodes m's payload into the event it was written from.
+func (m OutboxMessage) Event() (Event, error) {
+	var e Event
+	var err error
+	switch m.EventType {
+	case EventPriceDropped:
+		e, err = decodeEvent[PriceDropped](m.Payload)
+	case EventPriceChanged:
+		e, err = decodeEvent[PriceChanged](m.Payload)
+	case EventProductUpdated:
+		e, err = decodeEvent[ProductUpdated](m.Payload)
+	case EventSynonymsChanged:
+		e, err = decodeEvent[SynonymsChanged](m.Payload)
+	case EventFlagsChanged:
+		e, err = decodeEvent[FlagsChanged](m.Payload)
+	default:
+		return nil, fmt.Errorf("outbox message %s: unknown event type %q", m.ID, m.EventType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("outbox message %s: decode %s: %w", m.ID, m.EventType, err)
+	}
+	return e, nil
+}
+
+func decodeEvent[E Event](payload []byte) (Event, error) {
+	var e E
+	err := json.Unmarshal(payload, &e)
+	return e, err
+}
@@ -0,0 +1,252 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"time"
+	// Timezones are validated and applied wherever the service runs,
+	// whatever tzdata the host has.
+	_ "time/tzdata"
+)
+
+// Alert delivery frequencies.
+const (
+	FrequencyInstant = "instant"
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+)
+
+// Notification channels, named as the channels name themselves.
+const (
+	ChannelEmail    = "email"
+	ChannelTelegram = "telegram"
+	ChannelWebPush  = "webpush"
+	ChannelSMS      = "sms"
+	ChannelDiscord  = "discord"
+	ChannelSlack    = "slack"
+)
+
+// Channels lists every notification channel.
+var Channels = []string{ChannelEmail, ChannelTelegram, ChannelWebPush, ChannelSMS, ChannelDiscord, ChannelSlack}
+
+// Channel fanout modes: how many of a user's channels each notification
+// goes out on.
+const (
+	// FanoutFirst sends each notification once, on the first channel in
+	// the user's priority order that delivers it.
+	FanoutFirst = "first"
+	// FanoutAll sends each notification on every channel.
+	FanoutAll = "all"
+)
+
+// Notification topics a user can opt out of.
+const (
+	TopicPriceAlerts = "price_alerts"
+)
+
+// Topics lists every notification topic.
+var Topics = []string{TopicPriceAlerts}
+
+// TopicOf returns the topic notifications of type typ belong to.
+func TopicOf(typ string) string {
+	switch typ {
+	case NotificationPriceAlert, NotificationPriceAlertDigest:
+		return TopicPriceAlerts
+	}
+	return typ
+}
+
+// NotificationPreferences is how a user wants to be notified.
+type NotificationPreferences struct {
+	UserID string `json:"-"`
+	// Frequency is when price alerts are delivered: as they fire, or
+	// gathered into a daily or weekly digest.
+	Frequency string `json:"frequency"`
+	// Channels and Topics switch channels and topics on or off; those
+	// missing are on.
+	Channels map[string]bool `json:"channels"`
+	Topics   map[string]bool `json:"topics"`
+	// Fanout is FanoutFirst or FanoutAll; empty means FanoutFirst.
+	Fanout string `json:"fanout"`
+	// Priority orders channels for FanoutFirst, and the order they are
+	// tried in for FanoutAll. Channels it leaves out follow, in the order
+	// of Channels.
+	Priority []string `json:"priority"`
+	// Timezone is the user's IANA timezone, e.g. "Asia/Kolkata", which
+	// quiet hours and digests are timed in. Empty means the service's.
+	Timezone   string     `json:"timezone,omitempty"`
+	QuietHours QuietHours `json:"quiet_hours"`
+	UpdatedAt  time.Time  `json:"updated_at,omitzero"`
+}
+
+// QuietHours is a daily window, in the user's timezone, in which
+// notifications are held back until it ends. Start and End are "15:04"
+// clock times; a window ending before it starts runs past midnight.
+type QuietHours struct {
+	Enabled bool   `json:"enabled"`
+	Start   string `json:"start,omitempty"`
+	End     string `json:"end,omitempty"`
+}
+
+// Until returns when the quiet hours t falls in end, in t's location, or
+// the zero time if they are off or t is outside them.
+func (q QuietHours) Until(t time.Time) time.Time {
+	if !q.Enabled {
+		return time.Time{}
+	}
+	start, err1 := clockMinutes(q.Start)
+	end, err2 := clockMinutes(q.End)
+	if err1 != nil || err2 != nil || start == end {
+		return time.Time{}
+	}
+	m := t.Hour()*60 + t.Minute()
+	quiet := m >= start && m < end
+	if start > end {
+		quiet = m >= start || m < end
+	}
+	if !quiet {
+		return time.Time{}
+	}
+	until := time.Date(t.Year(), t.Month(), t.Day(), end/60, end%60, 0, 0, t.Location())
+	if !until.After(t) {
+		until = time.Date(t.Year(), t.Month(), t.Day()+1, end/60, end%60, 0, 0, t.Location())
+	}
+	return until
+}
+
+// Validate reports malformed or empty windows, if enabled, as ErrInvalid.
+func (q QuietHours) Validate() error {
+	if !q.Enabled {
+		return nil
+	}
+	start, err := clockMinutes(q.Start)
+	if err != nil {
+		return fmt.Errorf("quiet_hours.start must be a time like \"22:00\": %w", ErrInvalid)
+	}
+	end, err := clockMinutes(q.End)
+	if err != nil {
+		return fmt.Errorf("quiet_hours.end must be a time like \"07:00\": %w", ErrInvalid)
+	}
+	if start == end {
+		return fmt.Errorf("quiet_hours must start and end at different times: %w", ErrInvalid)
+	}
+	return nil
+}
+
+func clockMinutes(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Location returns the user's timezone, or fallback if they have not set
+// one.
+func (p NotificationPreferences) Location(fallback *time.Location) *time.Location {
+	if p.Timezone != "" {
+		if loc, err := time.LoadLocation(p.Timezone); err == nil {
+			return loc
+		}
+	}
+	if fallback == nil {
+		return time.UTC
+	}
+	return fallback
+}
+
+// DefaultNotificationPreferences applies to users who have not chosen.
+func DefaultNotificationPreferences(userID string) NotificationPreferences {
+	return NotificationPreferences{UserID: userID, Frequency: FrequencyInstant}.WithDefaults()
+}
+
+// WithDefaults returns p with every channel and topic it does not mention
+// switched on, so all of them are listed, and its fanout and full channel
+// priority filled in.
+func (p NotificationPreferences) WithDefaults() NotificationPreferences {
+	channels := make(map[string]bool, len(Channels))
+	for _, c := range Channels {
+		channels[c] = p.ChannelEnabled(c)
+	}
+	topics := make(map[string]bool, len(Topics))
+	for _, t := range Topics {
+		topics[t] = p.TopicEnabled(t)
+	}
+	p.Channels, p.Topics = channels, topics
+	if p.Fanout == "" {
+		p.Fanout = FanoutFirst
+	}
+	p.Priority = p.ChannelOrder()
+	return p
+}
+
+// ChannelOrder returns every channel, those in Priority first.
+func (p NotificationPreferences) ChannelOrder() []string {
+	order := make([]string, 0, len(Channels))
+	for _, c := range p.Priority {
+		if slices.Contains(Channels, c) && !slices.Contains(order, c) {
+			order = append(order, c)
+		}
+	}
+	for _, c := range Channels {
+		if !slices.Contains(order, c) {
+			order = append(order, c)
+		}
+	}
+	return order
+}
+
+// ChannelEnabled reports whether the user accepts notifications on channel.
+func (p NotificationPreferences) ChannelEnabled(channel string) bool {
+	on, ok := p.Channels[channel]
+	return on || !ok
+}
+
+// TopicEnabled reports whether the user accepts notifications on topic.
+func (p NotificationPreferences) TopicEnabled(topic string) bool {
+	on, ok := p.Topics[topic]
+	return on || !ok
+}
+
+// Validate reports unknown values as ErrInvalid.
+func (p NotificationPreferences) Validate() error {
+	switch p.Frequency {
+	case FrequencyInstant, FrequencyDaily, FrequencyWeekly:
+	default:
+		return fmt.Errorf("frequency must be %q, %q or %q: %w", FrequencyInstant, FrequencyDaily, FrequencyWeekly, ErrInvalid)
+	}
+	if p.Timezone != "" {
+		// LoadLocation also accepts "" and "Local", which name the
+		// host's zone rather than the user's.
+		if _, err := time.LoadLocation(p.Timezone); err != nil || p.Timezone == "Local" {
+			return fmt.Errorf("unknown timezone %q: %w", p.Timezone, ErrInvalid)
+		}
+	}
+	if err := p.QuietHours.Validate(); err != nil {
+		return err
+	}
+	switch p.Fanout {
+	case "", FanoutFirst, FanoutAll:
+	default:
+		return fmt.Errorf("fanout must be %q or %q: %w", FanoutFirst, FanoutAll, ErrInvalid)
+	}
+	for i, c := range p.Priority {
+		if !slices.Contains(Channels, c) {
+			return fmt.Errorf("unknown channel %q in priority: %w", c, ErrInvalid)
+		}
+		if slices.Contains(p.Priority[:i], c) {
+			return fmt.Errorf("channel %q is listed twice in priority: %w", c, ErrInvalid)
+		}
+	}
+	for c := range p.Channels {
+		if !slices.Contains(Channels, c) {
+			return fmt.Errorf("unknown channel %q: %w", c, ErrInvalid)
+		}
+	}
+	for t := range p.Topics {
+		if !slices.Contains(Topics, t) {
+			return fmt.Errorf("unknown topic %q: %w", t, ErrInvalid)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package domain_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestNotificationPreferences_Validate(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestNotificationPreferences_Validate", "internal/domain")
+
+	testCases := []struct {
+		name    string
+		prefs   domain.NotificationPreferences
+		wantErr error
+	}{
+		{"Default", domain.DefaultNotificationPreferences("user_1"), nil},
+		{"Daily", domain.NotificationPreferences{Frequency: domain.FrequencyDaily}, nil},
+		{"Weekly without email", domain.NotificationPreferences{Frequency: domain.FrequencyWeekly, Channels: map[string]bool{domain.ChannelEmail: false}}, nil},
+		{"Unknown frequency", domain.NotificationPreferences{Frequency: "hourly"}, domain.ErrInvalid},
+		{"Empty frequency", domain.NotificationPreferences{}, domain.ErrInvalid},
+		{"Unknown channel", domain.NotificationPreferences{Frequency: domain.FrequencyDaily, Channels: map[string]bool{"fax": true}}, domain.ErrInvalid},
+		{"Unknown topic", domain.NotificationPreferences{Frequency: domain.FrequencyDaily, Topics: map[string]bool{"newsletter": false}}, domain.ErrInvalid},
+		{"Fanout to all", domain.NotificationPreferences{Frequency: domain.FrequencyInstant, Fanout: domain.FanoutAll, Priority: []string{domain.ChannelSMS, domain.ChannelEmail}}, nil},
+		{"Unknown fanout", domain.NotificationPreferences{Frequency: domain.FrequencyInstant, Fanout: "some"}, domain.ErrInvalid},
+		{"Unknown channel in priority", domain.NotificationPreferences{Frequency: domain.FrequencyInstant, Priority: []string{"fax"}}, domain.ErrInvalid},
+		{"Repeated channel in priority", domain.NotificationPreferences{Frequency: domain.FrequencyInstant, Priority: []string{domain.ChannelSMS, domain.ChannelSMS}}, domain.ErrInvalid},
+		{"Timezone", domain.NotificationPreferences{Frequency: domain.FrequencyInstant, Timezone: "Asia/Kolkata"}, nil},
+		{"Unknown timezone", domain.NotificationPreferences{Frequency: domain.FrequencyInstant, Timezone: "Mars/Olympus"}, domain.ErrInvalid},
+		{"Host timezone", domain.NotificationPreferences{Frequency: domain.FrequencyInstant, Timezone: "Local"}, domain.ErrInvalid},
+		{"Quiet hours", domain.NotificationPreferences{Frequency: domain.FrequencyInstant, QuietHours: domain.QuietHours{Enabled: true, Start: "22:00", End: "07:00"}}, nil},
+		{"Quiet hours off", domain.NotificationPreferences{Frequency: domain.FrequencyInstant, QuietHours: domain.QuietHours{Start: "bedtime"}}, nil},
+		{"Malformed quiet hours", domain.NotificationPreferences{Frequency: domain.FrequencyInstant, QuietHours: domain.QuietHours{Enabled: true, Start: "10pm", End: "07:00"}}, domain.ErrInvalid},
+		{"Empty quiet hours", domain.NotificationPreferences{Frequency: domain.FrequencyInstant, QuietHours: domain.QuietHours{Enabled: true, Start: "07:00", End: "07:00"}}, domain.ErrInvalid},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.prefs.Validate()
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Validate(%+v) = %v, want %v", tc.prefs, err, tc.wantErr)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestNotificationPreferences_Validate", true)
+}
+
+func TestNotificationPreferences_WithDefaults(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestNotificationPreferences_WithDefaults", "internal/domain")
+
+	p := domain.NotificationPreferences{
+		Frequency: domain.FrequencyDaily,
+		Channels:  map[string]bool{domain.ChannelEmail: false},
+	}.WithDefaults()
+
+	testhelpers.LogTestAssertion(logger, "channels", len(domain.Channels), len(p.Channels))
+	if len(p.Channels) != len(domain.Channels) || len(p.Topics) != len(domain.Topics) {
+		t.Errorf("WithDefaults = %+v, want every channel and topic listed", p)
+	}
+	if p.ChannelEnabled(domain.ChannelEmail) || !p.ChannelEnabled(domain.ChannelTelegram) || !p.TopicEnabled(domain.TopicPriceAlerts) {
+		t.Errorf("WithDefaults = %+v, want only email off", p)
+	}
+	if p.Fanout != domain.FanoutFirst || !slices.Equal(p.Priority, domain.Channels) {
+		t.Errorf("WithDefaults fanout %q, priority %v; want %q and the channels in order", p.Fanout, p.Priority, domain.FanoutFirst)
+	}
+	if got := (domain.NotificationPreferences{Priority: []string{domain.ChannelSlack, domain.ChannelSMS}}).ChannelOrder(); got[0] != domain.ChannelSlack || got[1] != domain.ChannelSMS || got[2] != domain.ChannelEmail || len(got) != len(domain.Channels) {
+		t.Errorf("ChannelOrder = %v, want slack, sms, then the rest in order", got)
+	}
+	if got := domain.TopicOf(domain.NotificationPriceAlertDigest); got != domain.TopicPriceAlerts {
+		t.Errorf("TopicOf(digest) = %q, want %q", got, domain.TopicPriceAlerts)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestNotificationPreferences_WithDefaults", true)
+}
+
+func TestQuietHours_Until(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestQuietHours_Until", "internal/domain")
+
+	loc, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+	at := func(day, hour, minute int) time.Time { return time.Date(2026, 3, day, hour, minute, 0, 0, loc) }
+	overnight := domain.QuietHours{Enabled: true, Start: "22:00", End: "07:00"}
+	afternoon := domain.QuietHours{Enabled: true, Start: "13:00", End: "15:30"}
+
+	testCases := []struct {
+		name  string
+		quiet domain.QuietHours
+		t     time.Time
+		want  time.Time
+	}{
+		{"Before overnight window", overnight, at(10, 21, 59), time.Time{}},
+		{"Start of overnight window", overnight, at(10, 22, 0), at(11, 7, 0)},
+		{"After midnight", overnight, at(11, 3, 0), at(11, 7, 0)},
+		{"End of overnight window", overnight, at(11, 7, 0), time.Time{}},
+		{"In afternoon window", afternoon, at(10, 14, 0), at(10, 15, 30)},
+		{"After afternoon window", afternoon, at(10, 16, 0), time.Time{}},
+		{"Off", domain.QuietHours{Start: "22:00", End: "07:00"}, at(11, 3, 0), time.Time{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.quiet.Until(tc.t)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.want, got)
+			if !got.Equal(tc.want) {
+				t.Errorf("Until(%s) = %s, want %s", tc.t, got, tc.want)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestQuietHours_Until", true)
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Filter preset limits.
+const (
+	MaxPresetName = 60 // characters
+	// MaxFilterValues bounds how many values of one facet a search or
+	// preset may select.
+	MaxFilterValues = 20
+)
+
+// FilterPreset is a named set of filters and an order, such as "budget
+// isolate 2kg+", that its owner applies to searches and the deals listing
+// by ID instead of selecting them again.
+type FilterPreset struct {
+	ID        string        `json:"id"`
+	UserID    string        `json:"-"`
+	Name      string        `json:"name"`
+	Filters   SearchFilters `json:"filters"`
+	Sort      string        `json:"sort,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// Validate checks the name, filters and order, reporting problems as
+// ErrInvalid.
+func (p FilterPreset) Validate() error {
+	switch {
+	case strings.TrimSpace(p.Name) == "":
+		return fmt.Errorf("name is required: %w", ErrInvalid)
+	case utf8.RuneCountInString(p.Name) > MaxPresetName:
+		return fmt.Errorf("name must be at most %d characters: %w", MaxPresetName, ErrInvalid)
+	case p.Sort != "" && !IsResultSort(p.Sort):
+		return fmt.Errorf("sort must be one of %s: %w", strings.Join(ResultSorts, ", "), ErrInvalid)
+	case p.Filters.Empty() && p.Sort == "":
+		return fmt.Errorf("a preset needs at least one filter or a sort: %w", ErrInvalid)
+	}
+	return p.Filters.Validate()
+}
+
+// Validate bounds the values f may select, reporting problems as
+// ErrInvalid.
+func (f SearchFilters) Validate() error {
+	for _, facet := range []struct {
+		name string
+		n    int
+	}{
+		{"brand", len(f.BrandIDs)},
+		{"category", len(f.CategoryIDs)},
+		{"weight", len(f.Weights)},
+		{"retailer", len(f.RetailerIDs)},
+		{"dietary", len(f.Dietary)},
+	} {
+		if facet.n > MaxFilterValues {
+			return fmt.Errorf("at most %d %s filters are allowed: %w", MaxFilterValues, facet.name, ErrInvalid)
+		}
+	}
+	for _, g := range f.Weights {
+		if g <= 0 {
+			return fmt.Errorf("weight must be a positive number of grams: %w", ErrInvalid)
+		}
+	}
+	for _, d := range f.Dietary {
+		if !IsDietary(d) {
+			return fmt.Errorf("dietary must be one of %s, not %q: %w", strings.Join(DietaryAttributes, ", "), d, ErrInvalid)
+		}
+	}
+	return nil
+}
+
+// Apply returns the filters and order of a request made with the preset:
+// each facet f selects, and sort if set, replace the preset's.
+func (p FilterPreset) Apply(f SearchFilters, sort string) (SearchFilters, string) {
+	out := p.Filters
+	if len(f.BrandIDs) > 0 {
+		out.BrandIDs = f.BrandIDs
+	}
+	if len(f.CategoryIDs) > 0 {
+		out.CategoryIDs = f.CategoryIDs
+	}
+	if len(f.Weights) > 0 {
+		out.Weights = f.Weights
+	}
+	if len(f.RetailerIDs) > 0 {
+		out.RetailerIDs = f.RetailerIDs
+	}
+	if len(f.Dietary) > 0 {
+		out.Dietary = f.Dietary
+	}
+	if sort == "" {
+		sort = p.Sort
+	}
+	return out, sort
+}
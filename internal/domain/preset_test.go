@@ -0,0 +1,54 @@
+package domain_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestFilterPreset(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestFilterPreset", "internal/domain")
+
+	p := domain.FilterPreset{
+		Name:    "Budget isolate 2kg+",
+		Filters: domain.SearchFilters{CategoryIDs: []string{"isolate"}, Weights: []int{2000, 2270}},
+		Sort:    domain.SortPricePerProtein,
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Presets need a name and known values")
+	if err := p.Validate(); err != nil {
+		t.Errorf("Validate = %v", err)
+	}
+	for _, bad := range []domain.FilterPreset{
+		{Name: " ", Sort: domain.SortDiscount},
+		{Name: "No criteria"},
+		{Name: "Bad sort", Sort: "cheapest"},
+		{Name: "Bad weight", Filters: domain.SearchFilters{Weights: []int{-1}}},
+	} {
+		if err := bad.Validate(); !errors.Is(err, domain.ErrInvalid) {
+			t.Errorf("Validate(%+v) = %v, want ErrInvalid", bad, err)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Facets and a sort given with the preset replace its own")
+	f, sort := p.Apply(domain.SearchFilters{Weights: []int{1000}}, "")
+	testhelpers.LogTestAssertion(logger, "weights", []int{1000}, f.Weights)
+	if !slices.Equal(f.Weights, []int{1000}) || !slices.Equal(f.CategoryIDs, []string{"isolate"}) || sort != domain.SortPricePerProtein {
+		t.Errorf("Apply = %+v, %q", f, sort)
+	}
+	if _, sort := p.Apply(domain.SearchFilters{}, domain.SortDiscount); sort != domain.SortDiscount {
+		t.Errorf("Apply sort = %q, want discount", sort)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Offers are judged by their own retailer and size")
+	prod := domain.Product{CategoryID: "isolate"}
+	if !p.Filters.MatchesOffer(prod, domain.Offer{SizeGrams: 2270}) || p.Filters.MatchesOffer(prod, domain.Offer{SizeGrams: 1000}) {
+		t.Error("MatchesOffer did not judge the offer's size")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestFilterPreset", true)
+}
@@ -0,0 +1,269 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// DefaultCurrency is the currency every Indian retailer reports in.
+const DefaultCurrency = "INR"
+
+// PricePoint is one observed price for a listing (a price_history row).
+type PricePoint struct {
+	ID            string    `json:"id"`
+	ListingID     string    `json:"listing_id"`
+	Price         float64   `json:"price"`
+	PreviousPrice float64   `json:"previous_price,omitempty"`
+	Currency      string    `json:"currency"`
+	InStock       bool      `json:"in_stock"`
+	RecordedAt    time.Time `json:"recorded_at"`
+	Source        string    `json:"source"`
+}
+
+// Offer is the current price of one listing, flattened with the retailer and
+// variant details a shopper needs to compare it.
+type Offer struct {
+	ListingID           string    `json:"listing_id"`
+	RetailerID          string    `json:"retailer_id"`
+	RetailerName        string    `json:"retailer_name"`
+	VariantID           string    `json:"variant_id"`
+	Flavor              string    `json:"flavor,omitempty"`
+	SizeGrams           int       `json:"weight_grams"`
+	Price               float64   `json:"price"`
+	PriceDisplay        string    `json:"price_display,omitempty"`
+	WeightDisplay       string    `json:"weight_display,omitempty"`
+	OriginalPrice       float64   `json:"original_price,omitempty"`
+	DiscountPercent     float64   `json:"discount_percent"`
+	Currency            string    `json:"currency"`
+	InStock             bool      `json:"in_stock"`
+	URL                 string    `json:"url"`
+	BuyURL              string    `json:"buy_url"`
+	PricePerGramProtein float64   `json:"price_per_gram_protein"`
+	LastUpdated         time.Time `json:"last_updated"`
+}
+
+// PriceStats summarises the offers for a product.
+type PriceStats struct {
+	LowestPrice       float64 `json:"lowest_price"`
+	HighestPrice      float64 `json:"highest_price"`
+	AveragePrice      float64 `json:"average_price"`
+	PriceRange        float64 `json:"price_range"`
+	RetailersInStock  int     `json:"retailers_in_stock"`
+	TotalRetailers    int     `json:"total_retailers"`
+	BestPricePerGram  float64 `json:"best_price_per_gram_protein"`
+	SavingsVsHighest  float64 `json:"savings_vs_highest"`
+	SavingsPercentage float64 `json:"savings_percent"`
+}
+
+// Comparison is the cross-retailer price view of a product.
+type Comparison struct {
+	Product     Product    `json:"product"`
+	Prices      []Offer    `json:"prices"`
+	Stats       PriceStats `json:"price_stats"`
+	BestDeal    *Offer     `json:"best_deal,omitempty"`
+	LastUpdated time.Time  `json:"last_updated"`
+}
+
+// NewComparison sorts offers cheapest first (in-stock before out-of-stock)
+// and computes the summary statistics and best deal.
+func NewComparison(product Product, offers []Offer) *Comparison {
+	sort.SliceStable(offers, func(i, j int) bool {
+		if offers[i].InStock != offers[j].InStock {
+			return offers[i].InStock
+		}
+		return offers[i].Price < offers[j].Price
+	})
+
+	c := &Comparison{Product: product, Prices: offers}
+	retailers := make(map[string]bool)
+	inStock := make(map[string]bool)
+	var sum float64
+	for i, o := range offers {
+		retailers[o.RetailerID] = true
+		if o.InStock {
+			inStock[o.RetailerID] = true
+		}
+		if i == 0 || o.Price < c.Stats.LowestPrice {
+			c.Stats.LowestPrice = o.Price
+		}
+		if o.Price > c.Stats.HighestPrice {
+			c.Stats.HighestPrice = o.Price
+		}
+		if o.PricePerGramProtein > 0 && (c.Stats.BestPricePerGram == 0 || o.PricePerGramProtein < c.Stats.BestPricePerGram) {
+			c.Stats.BestPricePerGram = o.PricePerGramProtein
+		}
+		if o.LastUpdated.After(c.LastUpdated) {
+			c.LastUpdated = o.LastUpdated
+		}
+		sum += o.Price
+	}
+	c.Stats.TotalRetailers = len(retailers)
+	c.Stats.RetailersInStock = len(inStock)
+	if len(offers) > 0 {
+		c.Stats.AveragePrice = Round2(sum / float64(len(offers)))
+		c.Stats.PriceRange = Round2(c.Stats.HighestPrice - c.Stats.LowestPrice)
+	}
+	if len(offers) > 0 && offers[0].InStock {
+		best := offers[0]
+		c.BestDeal = &best
+		c.Stats.SavingsVsHighest = Round2(c.Stats.HighestPrice - best.Price)
+		if c.Stats.HighestPrice > 0 {
+			c.Stats.SavingsPercentage = Round2(c.Stats.SavingsVsHighest / c.Stats.HighestPrice * 100)
+		}
+	}
+	return c
+}
+
+// History intervals: every observation, or one point per listing and day.
+const (
+	IntervalRaw = "raw"
+	IntervalDay = "day"
+)
+
+// PriceHistory is the price series of a product, optionally for one retailer.
+type PriceHistory struct {
+	ProductID  string `json:"product_id"`
+	RetailerID string `json:"retailer_id,omitempty"`
+	Days       int    `json:"days"`
+	// Interval is IntervalRaw or IntervalDay.
+	Interval string         `json:"interval"`
+	Points   []HistoryPoint `json:"price_history"`
+	Stats    HistoryStats   `json:"statistics"`
+}
+
+// HistoryPoint is a PricePoint annotated with its retailer and variant. A
+// daily point is recorded at the start of its UTC day, with the day's last
+// price as Price and its range in Low and High.
+type HistoryPoint struct {
+	RecordedAt time.Time `json:"recorded_at"`
+	RetailerID string    `json:"retailer_id"`
+	VariantID  string    `json:"variant_id"`
+	ListingID  string    `json:"listing_id"`
+	Price      float64   `json:"price"`
+	Low        float64   `json:"low,omitempty"`
+	High       float64   `json:"high,omitempty"`
+	Currency   string    `json:"currency"`
+	InStock    bool      `json:"in_stock"`
+}
+
+// DailyPrice rolls up one listing's prices over one UTC day.
+type DailyPrice struct {
+	ListingID string    `json:"listing_id"`
+	Day       time.Time `json:"day"`
+	MinPrice  float64   `json:"min_price"`
+	AvgPrice  float64   `json:"avg_price"`
+	MaxPrice  float64   `json:"max_price"`
+	// ClosePrice is the last price recorded that day.
+	ClosePrice float64 `json:"close_price"`
+	// InStock is whether any observation that day was in stock.
+	InStock  bool   `json:"in_stock"`
+	Samples  int    `json:"samples"`
+	Currency string `json:"currency"`
+}
+
+// RollUpDaily rolls points, ordered by RecordedAt, up by listing and UTC
+// day, ordered by day then listing: what the price_history_daily view
+// holds.
+func RollUpDaily(points []PricePoint) []DailyPrice {
+	type key struct {
+		listing string
+		day     time.Time
+	}
+	index := make(map[key]int)
+	var out []DailyPrice
+	for _, p := range points {
+		k := key{p.ListingID, p.RecordedAt.UTC().Truncate(24 * time.Hour)}
+		i, ok := index[k]
+		if !ok {
+			i = len(out)
+			index[k] = i
+			out = append(out, DailyPrice{ListingID: p.ListingID, Day: k.day, MinPrice: p.Price, MaxPrice: p.Price, Currency: p.Currency})
+		}
+		d := &out[i]
+		d.MinPrice = min(d.MinPrice, p.Price)
+		d.MaxPrice = max(d.MaxPrice, p.Price)
+		d.AvgPrice += p.Price
+		d.ClosePrice = p.Price
+		d.InStock = d.InStock || p.InStock
+		d.Samples++
+	}
+	for i := range out {
+		out[i].AvgPrice = Round2(out[i].AvgPrice / float64(out[i].Samples))
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].Day.Equal(out[j].Day) {
+			return out[i].Day.Before(out[j].Day)
+		}
+		return out[i].ListingID < out[j].ListingID
+	})
+	return out
+}
+
+// HistoryStats summarises a price series.
+type HistoryStats struct {
+	MinPrice     float64 `json:"min_price"`
+	MaxPrice     float64 `json:"max_price"`
+	AvgPrice     float64 `json:"avg_price"`
+	PriceChanges int     `json:"price_changes"`
+	DaysTracked  int     `json:"days_tracked"`
+}
+
+// NewHistoryStats computes statistics for points ordered by time. The
+// range of daily points counts towards the minimum and maximum; their
+// averages are of the days' closing prices.
+func NewHistoryStats(points []HistoryPoint) HistoryStats {
+	var s HistoryStats
+	if len(points) == 0 {
+		return s
+	}
+	last := make(map[string]float64)
+	days := make(map[string]bool)
+	var sum float64
+	for i, p := range points {
+		low, high := p.Price, p.Price
+		if p.Low > 0 {
+			low = p.Low
+		}
+		if p.High > 0 {
+			high = p.High
+		}
+		if i == 0 || low < s.MinPrice {
+			s.MinPrice = low
+		}
+		if high > s.MaxPrice {
+			s.MaxPrice = high
+		}
+		if prev, ok := last[p.ListingID]; ok && prev != p.Price {
+			s.PriceChanges++
+		}
+		last[p.ListingID] = p.Price
+		days[p.RecordedAt.UTC().Format(time.DateOnly)] = true
+		sum += p.Price
+	}
+	s.AvgPrice = Round2(sum / float64(len(points)))
+	s.DaysTracked = len(days)
+	return s
+}
+
+// DiscountPercent returns how far price is below original, in percent.
+func DiscountPercent(price, original float64) float64 {
+	if original <= 0 || price >= original {
+		return 0
+	}
+	return Round2((original - price) / original * 100)
+}
+
+// PricePerGramProtein returns the price of one gram of protein, or 0 when the
+// protein content is unknown.
+func PricePerGramProtein(price, proteinGrams float64) float64 {
+	if proteinGrams <= 0 {
+		return 0
+	}
+	return math.Round(price/proteinGrams*1000) / 1000
+}
+
+// Round2 rounds to two decimal places (paise).
+func Round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,168 @@
+package domain_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestNewComparison(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestNewComparison", "internal/domain")
+
+	now := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	offers := []domain.Offer{
+		{RetailerID: "amazon", Price: 3299, InStock: true, PricePerGramProtein: 1.84, LastUpdated: now.Add(-time.Hour)},
+		{RetailerID: "healthkart", Price: 2999, InStock: false, PricePerGramProtein: 1.67, LastUpdated: now.Add(-2 * time.Hour)},
+		{RetailerID: "flipkart", Price: 3199, InStock: true, PricePerGramProtein: 1.78, LastUpdated: now},
+		{RetailerID: "nutrabay", Price: 3999, InStock: true, PricePerGramProtein: 2.23, LastUpdated: now.Add(-3 * time.Hour)},
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Building comparison")
+	c := domain.NewComparison(domain.Product{ID: "prod_123"}, offers)
+
+	testhelpers.LogTestStep(logger, "assert", "In-stock offers first, cheapest first")
+	order := []string{"flipkart", "amazon", "nutrabay", "healthkart"}
+	for i, id := range order {
+		if c.Prices[i].RetailerID != id {
+			t.Errorf("Prices[%d] = %s, want %s", i, c.Prices[i].RetailerID, id)
+		}
+	}
+	if c.BestDeal == nil || c.BestDeal.RetailerID != "flipkart" {
+		t.Fatalf("BestDeal = %+v, want flipkart", c.BestDeal)
+	}
+
+	testhelpers.LogTestAssertion(logger, "lowest price", 2999.0, c.Stats.LowestPrice)
+	if c.Stats.LowestPrice != 2999 || c.Stats.HighestPrice != 3999 {
+		t.Errorf("Stats range = %v-%v", c.Stats.LowestPrice, c.Stats.HighestPrice)
+	}
+	if c.Stats.RetailersInStock != 3 || c.Stats.TotalRetailers != 4 {
+		t.Errorf("Retailer counts = %d/%d", c.Stats.RetailersInStock, c.Stats.TotalRetailers)
+	}
+	if c.Stats.SavingsVsHighest != 800 {
+		t.Errorf("SavingsVsHighest = %v, want 800", c.Stats.SavingsVsHighest)
+	}
+	if c.Stats.BestPricePerGram != 1.67 {
+		t.Errorf("BestPricePerGram = %v, want 1.67", c.Stats.BestPricePerGram)
+	}
+	if !c.LastUpdated.Equal(now) {
+		t.Errorf("LastUpdated = %v, want %v", c.LastUpdated, now)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestNewComparison", true)
+}
+
+func TestNewComparison_NoInStockOffers(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestNewComparison_NoInStockOffers", "internal/domain")
+
+	c := domain.NewComparison(domain.Product{ID: "prod_123"}, []domain.Offer{{RetailerID: "amazon", Price: 3299}})
+	if c.BestDeal != nil {
+		t.Errorf("BestDeal = %+v, want nil when nothing is in stock", c.BestDeal)
+	}
+
+	empty := domain.NewComparison(domain.Product{ID: "prod_123"}, nil)
+	if empty.BestDeal != nil || empty.Stats.TotalRetailers != 0 {
+		t.Errorf("Empty comparison = %+v", empty)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestNewComparison_NoInStockOffers", true)
+}
+
+func TestNewHistoryStats(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestNewHistoryStats", "internal/domain")
+
+	day := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	points := []domain.HistoryPoint{
+		{ListingID: "a", Price: 3399, RecordedAt: day},
+		{ListingID: "b", Price: 3449, RecordedAt: day},
+		{ListingID: "a", Price: 3299, RecordedAt: day.AddDate(0, 0, 1)},
+		{ListingID: "b", Price: 3449, RecordedAt: day.AddDate(0, 0, 1)},
+		{ListingID: "a", Price: 3399, RecordedAt: day.AddDate(0, 0, 2)},
+	}
+
+	s := domain.NewHistoryStats(points)
+	testhelpers.LogTestAssertion(logger, "history stats", "2 changes over 3 days", s)
+	if s.MinPrice != 3299 || s.MaxPrice != 3449 {
+		t.Errorf("Range = %v-%v", s.MinPrice, s.MaxPrice)
+	}
+	if s.PriceChanges != 2 {
+		t.Errorf("PriceChanges = %d, want 2", s.PriceChanges)
+	}
+	if s.DaysTracked != 3 {
+		t.Errorf("DaysTracked = %d, want 3", s.DaysTracked)
+	}
+	if s.AvgPrice != 3399 {
+		t.Errorf("AvgPrice = %v, want 3399", s.AvgPrice)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestNewHistoryStats", true)
+}
+
+func TestRollUpDaily(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestRollUpDaily", "internal/domain")
+
+	// 23:30 in India is still the same UTC day as the morning before it.
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	day := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	points := []domain.PricePoint{
+		{ListingID: "b", Price: 2199, InStock: true, RecordedAt: day.Add(2 * time.Hour), Currency: domain.DefaultCurrency},
+		{ListingID: "a", Price: 3399, RecordedAt: day.Add(3 * time.Hour), Currency: domain.DefaultCurrency},
+		{ListingID: "a", Price: 3299, InStock: true, RecordedAt: time.Date(2026, 10, 15, 23, 30, 0, 0, ist), Currency: domain.DefaultCurrency},
+		{ListingID: "a", Price: 3349, InStock: false, RecordedAt: day.Add(25 * time.Hour), Currency: domain.DefaultCurrency},
+	}
+
+	got := domain.RollUpDaily(points)
+	want := []domain.DailyPrice{
+		{ListingID: "a", Day: day, MinPrice: 3299, AvgPrice: 3349, MaxPrice: 3399, ClosePrice: 3299, InStock: true, Samples: 2, Currency: domain.DefaultCurrency},
+		{ListingID: "b", Day: day, MinPrice: 2199, AvgPrice: 2199, MaxPrice: 2199, ClosePrice: 2199, InStock: true, Samples: 1, Currency: domain.DefaultCurrency},
+		{ListingID: "a", Day: day.AddDate(0, 0, 1), MinPrice: 3349, AvgPrice: 3349, MaxPrice: 3349, ClosePrice: 3349, Samples: 1, Currency: domain.DefaultCurrency},
+	}
+	testhelpers.LogTestAssertion(logger, "daily rollups", want, got)
+	if !slices.Equal(got, want) {
+		t.Errorf("RollUpDaily = %+v\nwant %+v", got, want)
+	}
+
+	s := domain.NewHistoryStats([]domain.HistoryPoint{
+		{ListingID: "a", Price: 3299, Low: 3299, High: 3399, RecordedAt: day},
+		{ListingID: "a", Price: 3349, Low: 3349, High: 3349, RecordedAt: day.AddDate(0, 0, 1)},
+	})
+	if s.MinPrice != 3299 || s.MaxPrice != 3399 || s.PriceChanges != 1 {
+		t.Errorf("Daily stats = %+v, want 3299-3399 with one change", s)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestRollUpDaily", true)
+}
+
+func TestPriceCalculations(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPriceCalculations", "internal/domain")
+
+	if got := domain.DiscountPercent(3299, 3999); got != 17.5 {
+		t.Errorf("DiscountPercent = %v, want 17.5", got)
+	}
+	if got := domain.DiscountPercent(3999, 3299); got != 0 {
+		t.Errorf("DiscountPercent above MRP = %v, want 0", got)
+	}
+
+	p := domain.Product{ProteinPerServing: 24, ServingsPerContainer: 74, ServingSizeGrams: 30}
+	if got := p.ProteinGrams(2270); got != 1816 {
+		t.Errorf("ProteinGrams(2270) = %v, want 1816", got)
+	}
+	if got := p.ProteinGrams(0); got != 1776 {
+		t.Errorf("ProteinGrams(0) = %v, want 1776 from servings", got)
+	}
+	if got := domain.PricePerGramProtein(3299, 1816); got != 1.817 {
+		t.Errorf("PricePerGramProtein = %v, want 1.817", got)
+	}
+	if got := domain.PricePerGramProtein(3299, 0); got != 0 {
+		t.Errorf("PricePerGramProtein with unknown protein = %v, want 0", got)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPriceCalculations", true)
+}
@@ -0,0 +1,51 @@
+package domain
+
+import "time"
+
+// PriceLogEntry is one price change in the price change log: an
+// append-only record of every PriceDropped and PriceChanged event, kept
+// apart from the observations in price history. Entries are numbered by
+// Offset in the order they were written, and consumers read the log from
+// the offset they last committed, so rewinding a consumer replays the
+// changes it has seen.
+type PriceLogEntry struct {
+	Offset    int64  `json:"offset"`
+	EventType string `json:"event_type"`
+	PriceChange
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// NewPriceLogEntries returns the entries for the price events among
+// events, recorded at at. Other events are not logged.
+func NewPriceLogEntries(at time.Time, events ...Event) []PriceLogEntry {
+	var out []PriceLogEntry
+	for _, e := range events {
+		var c PriceChange
+		switch e := e.(type) {
+		case PriceDropped:
+			c = e.PriceChange
+		case PriceChanged:
+			c = e.PriceChange
+		default:
+			continue
+		}
+		out = append(out, PriceLogEntry{EventType: e.EventType(), PriceChange: c, RecordedAt: at})
+	}
+	return out
+}
+
+// Event returns the event the entry was written from.
+func (e PriceLogEntry) Event() Event {
+	if e.EventType == EventPriceDropped {
+		return PriceDropped{e.PriceChange}
+	}
+	return PriceChanged{e.PriceChange}
+}
+
+// PriceLogCursor is where a consumer of the price change log has read up
+// to: it has handled every entry up to and including Offset.
+type PriceLogCursor struct {
+	Consumer  string    `json:"consumer"`
+	Offset    int64     `json:"offset"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,51 @@
+package domain
+
+import "time"
+
+// UserData is everything stored about a user, as exported to them. Secrets
+// such as password and token hashes are left out by the types' JSON tags.
+type UserData struct {
+	Account User `json:"account"`
+	// LinkedAccounts names the sign-in providers linked to the account.
+	LinkedAccounts    []string                 `json:"linked_accounts"`
+	Alerts            []PriceAlert             `json:"alerts"`
+	SavedSearches     []SavedSearch            `json:"saved_searches"`
+	FilterPresets     []FilterPreset           `json:"filter_presets"`
+	Notifications     []Notification           `json:"notifications"`
+	Preferences       *NotificationPreferences `json:"notification_preferences,omitempty"`
+	Watchlist         []WatchlistItem          `json:"watchlist"`
+	TelegramChatID    int64                    `json:"telegram_chat_id,omitempty"`
+	PushSubscriptions []PushSubscription       `json:"push_subscriptions"`
+	SMS               *SMSSubscription         `json:"sms,omitempty"`
+	Discord           *DiscordWebhook          `json:"discord,omitempty"`
+	Slack             *SlackWebhook            `json:"slack,omitempty"`
+	APITokens         []APIToken               `json:"api_tokens"`
+	Clicks            []ClickEvent             `json:"clicks"`
+	ExportedAt        time.Time                `json:"exported_at"`
+}
+
+// Data request kinds and statuses.
+const (
+	DataRequestExport = "export"
+	DataRequestDelete = "delete"
+
+	DataRequestPending = "pending"
+	DataRequestDone    = "done"
+	DataRequestFailed  = "failed"
+)
+
+// DataRequest is a user's request to export or delete their data, carried
+// out in the background.
+type DataRequest struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"-"`
+	Kind        string     `json:"kind"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	// ExpiresAt is when a finished export stops being downloadable.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// Result is the finished export, as JSON.
+	Result []byte `json:"-"`
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// PushSubscription is a browser's Web Push subscription. P256DH and Auth
+// are the keys its payloads are encrypted to (RFC 8291), base64url
+// encoded as the browser reports them.
+type PushSubscription struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"-"`
+	Endpoint  string    `json:"endpoint"`
+	P256DH    string    `json:"-"`
+	Auth      string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Saved search limits.
+const (
+	MaxSavedSearchName  = 100 // characters
+	MaxSavedSearchQuery = 100 // characters
+)
+
+// SavedSearch is a filter over the catalog, such as "isolate under ₹2 per
+// gram of protein, 2kg or more", whose owner is notified as products start
+// to match it or its matches get cheaper. Unset criteria match anything.
+type SavedSearch struct {
+	ID     string `json:"id"`
+	UserID string `json:"-"`
+	Name   string `json:"name"`
+	// Query matches products whose name, brand or category contains every
+	// one of its words, ignoring case.
+	Query           string  `json:"query,omitempty"`
+	CategoryID      string  `json:"category_id,omitempty"`
+	BrandID         string  `json:"brand_id,omitempty"`
+	MaxPrice        float64 `json:"max_price,omitempty"`
+	MaxPricePerGram float64 `json:"max_price_per_gram_protein,omitempty"`
+	MinSizeGrams    int     `json:"min_weight_grams,omitempty"`
+	// Matches counts the products matching now.
+	Matches   int       `json:"matches"`
+	CreatedAt time.Time `json:"created_at"`
+	// Notified maps each matching product to the price its owner was last
+	// told about, so only new matches and lower prices notify again.
+	Notified map[string]float64 `json:"-"`
+}
+
+// Validate checks the name and criteria, reporting problems as ErrInvalid.
+func (s SavedSearch) Validate() error {
+	switch {
+	case strings.TrimSpace(s.Name) == "":
+		return fmt.Errorf("name is required: %w", ErrInvalid)
+	case utf8.RuneCountInString(s.Name) > MaxSavedSearchName:
+		return fmt.Errorf("name must be at most %d characters: %w", MaxSavedSearchName, ErrInvalid)
+	case utf8.RuneCountInString(s.Query) > MaxSavedSearchQuery:
+		return fmt.Errorf("query must be at most %d characters: %w", MaxSavedSearchQuery, ErrInvalid)
+	case s.MaxPrice < 0 || s.MaxPricePerGram < 0 || s.MinSizeGrams < 0:
+		return fmt.Errorf("limits cannot be negative: %w", ErrInvalid)
+	case strings.TrimSpace(s.Query) == "" && s.CategoryID == "" && s.BrandID == "" &&
+		s.MaxPrice == 0 && s.MaxPricePerGram == 0 && s.MinSizeGrams == 0:
+		return fmt.Errorf("a saved search needs at least one criterion: %w", ErrInvalid)
+	}
+	return nil
+}
+
+// Match returns the cheapest in-stock offer in c that meets the search, if
+// the product matches it at all.
+func (s SavedSearch) Match(c Comparison) (Offer, bool) {
+	p := c.Product
+	if s.CategoryID != "" && p.CategoryID != s.CategoryID || s.BrandID != "" && p.BrandID != s.BrandID {
+		return Offer{}, false
+	}
+	text := strings.ToLower(p.Name + " " + p.Brand + " " + p.Category)
+	for _, word := range strings.Fields(strings.ToLower(s.Query)) {
+		if !strings.Contains(text, word) {
+			return Offer{}, false
+		}
+	}
+	var best Offer
+	found := false
+	for _, o := range c.Prices {
+		switch {
+		case !o.InStock, o.SizeGrams < s.MinSizeGrams,
+			s.MaxPrice > 0 && o.Price > s.MaxPrice,
+			s.MaxPricePerGram > 0 && (o.PricePerGramProtein <= 0 || o.PricePerGramProtein > s.MaxPricePerGram):
+			continue
+		}
+		if !found || o.Price < best.Price {
+			best, found = o, true
+		}
+	}
+	return best, found
+}
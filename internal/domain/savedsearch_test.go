@@ -0,0 +1,78 @@
+package domain_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestSavedSearch_Validate(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSavedSearch_Validate", "internal/domain")
+
+	testCases := []struct {
+		name    string
+		search  domain.SavedSearch
+		wantErr error
+	}{
+		{"Query", domain.SavedSearch{Name: "Isolates", Query: "isolate"}, nil},
+		{"Limits only", domain.SavedSearch{Name: "Cheap", MaxPricePerGram: 2, MinSizeGrams: 2000}, nil},
+		{"No name", domain.SavedSearch{Name: " ", Query: "isolate"}, domain.ErrInvalid},
+		{"Long name", domain.SavedSearch{Name: strings.Repeat("a", domain.MaxSavedSearchName+1), Query: "isolate"}, domain.ErrInvalid},
+		{"Long query", domain.SavedSearch{Name: "Long", Query: strings.Repeat("a", domain.MaxSavedSearchQuery+1)}, domain.ErrInvalid},
+		{"Negative limit", domain.SavedSearch{Name: "Negative", MaxPrice: -1}, domain.ErrInvalid},
+		{"No criteria", domain.SavedSearch{Name: "Everything"}, domain.ErrInvalid},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.search.Validate()
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if tc.wantErr == nil && err != nil || tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Errorf("Validate error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSavedSearch_Validate", true)
+}
+
+func TestSavedSearch_Match(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSavedSearch_Match", "internal/domain")
+
+	c := domain.Comparison{
+		Product: domain.Product{Name: "Gold Standard Isolate", Brand: "Optimum Nutrition", BrandID: "brand_on", CategoryID: "cat_isolate"},
+		Prices: []domain.Offer{
+			{ListingID: "small", Price: 2400, SizeGrams: 1000, PricePerGramProtein: 2.9, InStock: true},
+			{ListingID: "big", Price: 7200, SizeGrams: 2270, PricePerGramProtein: 1.9, InStock: true},
+			{ListingID: "gone", Price: 6500, SizeGrams: 2270, PricePerGramProtein: 1.7},
+		},
+	}
+	testCases := []struct {
+		name        string
+		search      domain.SavedSearch
+		wantListing string
+	}{
+		{"Query words in any order and case", domain.SavedSearch{Query: "ISOLATE optimum"}, "small"},
+		{"Query word missing", domain.SavedSearch{Query: "isolate casein"}, ""},
+		{"Other brand", domain.SavedSearch{BrandID: "brand_mb"}, ""},
+		{"Category", domain.SavedSearch{CategoryID: "cat_isolate"}, "small"},
+		{"Per gram and size", domain.SavedSearch{Query: "isolate", MaxPricePerGram: 2, MinSizeGrams: 2000}, "big"},
+		{"Price cap", domain.SavedSearch{MaxPrice: 2000}, ""},
+		{"Only out of stock meets it", domain.SavedSearch{MaxPricePerGram: 1.8}, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o, ok := tc.search.Match(c)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantListing, o.ListingID)
+			if ok != (tc.wantListing != "") || o.ListingID != tc.wantListing {
+				t.Errorf("Match = %q, %v; want %q", o.ListingID, ok, tc.wantListing)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSavedSearch_Match", true)
+}
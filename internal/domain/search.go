@@ -0,0 +1,200 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxSearchQuery bounds the length of a search query, in characters.
+const MaxSearchQuery = 100
+
+// SearchHit is a product matching a search, with its text relevance.
+type SearchHit struct {
+	ProductID string  `json:"product_id"`
+	Rank      float64 `json:"rank"`
+}
+
+// SearchFilters narrows a search to products with any of the listed values
+// of each facet. An empty list does not filter.
+type SearchFilters struct {
+	BrandIDs    []string `json:"brands,omitempty"`
+	CategoryIDs []string `json:"categories,omitempty"` // protein type
+	Weights     []int    `json:"weights,omitempty"`    // pack sizes in grams
+	RetailerIDs []string `json:"retailers,omitempty"`
+	// Dietary, unlike the other facets, keeps only products with every
+	// listed attribute.
+	Dietary []string `json:"dietary,omitempty"`
+}
+
+// Empty reports whether f filters nothing.
+func (f SearchFilters) Empty() bool {
+	return len(f.BrandIDs) == 0 && len(f.CategoryIDs) == 0 && len(f.Weights) == 0 && len(f.RetailerIDs) == 0 &&
+		len(f.Dietary) == 0
+}
+
+// MatchesOffer reports whether p, sold as o, passes f. Unlike a search,
+// which keeps a product listed at any of the retailers or sizes, it judges
+// the one offer.
+func (f SearchFilters) MatchesOffer(p Product, o Offer) bool {
+	switch {
+	case len(f.BrandIDs) > 0 && !slices.Contains(f.BrandIDs, p.BrandID),
+		len(f.CategoryIDs) > 0 && !slices.Contains(f.CategoryIDs, p.CategoryID),
+		len(f.Weights) > 0 && !slices.Contains(f.Weights, o.SizeGrams),
+		len(f.RetailerIDs) > 0 && !slices.Contains(f.RetailerIDs, o.RetailerID):
+		return false
+	}
+	for _, d := range f.Dietary {
+		if !slices.Contains(p.Dietary, d) {
+			return false
+		}
+	}
+	return true
+}
+
+// FacetValue is one value of a facet and how many matches have it.
+type FacetValue struct {
+	Value    string `json:"value"`
+	Label    string `json:"label"`
+	Count    int    `json:"count"`
+	Selected bool   `json:"selected,omitempty"`
+}
+
+// SearchFacets counts a search's matches by brand, protein type, pack size,
+// retailer and dietary attribute. Each facet is counted with every filter
+// but its own applied, so its counts are what choosing one more of its
+// values adds; dietary counts are how many matches have each attribute.
+type SearchFacets struct {
+	Brands     []FacetValue `json:"brands"`
+	Categories []FacetValue `json:"categories"`
+	Weights    []FacetValue `json:"weights"`
+	Retailers  []FacetValue `json:"retailers"`
+	// Dietary is in the order of DietaryAttributes.
+	Dietary []FacetValue `json:"dietary"`
+}
+
+// SearchMatches is what a search finds: the best matches and the facet
+// counts over all of them.
+type SearchMatches struct {
+	Hits []SearchHit
+	// Total counts every match, including those beyond the hits returned.
+	Total  int
+	Facets SearchFacets
+}
+
+// Suggestion kinds.
+const (
+	SuggestBrand   = "brand"
+	SuggestProduct = "product"
+)
+
+// Suggestion completes a partly typed search: a brand, or a product by its
+// brand and name.
+type Suggestion struct {
+	Kind string `json:"kind"`
+	Text string `json:"text"`
+	// ID is the brand or product ID.
+	ID   string `json:"id"`
+	Slug string `json:"slug,omitempty"`
+}
+
+// SearchResult is a matching product with its current prices.
+type SearchResult struct {
+	Product Product `json:"product"`
+	// BestDeal is the cheapest in-stock offer, nil when out of stock
+	// everywhere.
+	BestDeal      *Offer  `json:"best_deal,omitempty"`
+	MinPrice      float64 `json:"min_price"`
+	MaxPrice      float64 `json:"max_price"`
+	RetailerCount int     `json:"retailer_count"`
+	// PricePerServing is what a serving costs at BestDeal, 0 if unknown.
+	PricePerServing float64 `json:"price_per_serving,omitempty"`
+}
+
+// SearchResults is one page of search results.
+type SearchResults struct {
+	Query      string         `json:"query"`
+	Products   []SearchResult `json:"products"`
+	TotalCount int            `json:"total_count"`
+	Page       int            `json:"page"`
+	PerPage    int            `json:"per_page"`
+	TotalPages int            `json:"total_pages"`
+	Filters    SearchFilters  `json:"filters"`
+	Facets     SearchFacets   `json:"facets"`
+	// Fuzzy reports that nothing matched the query exactly and these are
+	// products matching it with a few typos forgiven.
+	Fuzzy bool `json:"fuzzy,omitempty"`
+	// SearchedAs is the query as it was searched when it found nothing as
+	// typed but did once respelled, such as "whey protein" for "वे प्रोटीन".
+	SearchedAs string `json:"searched_as,omitempty"`
+	// DidYouMean is the query with its typos corrected, offered when it
+	// finds many more products than the few the query did.
+	DidYouMean string `json:"did_you_mean,omitempty"`
+	// Sort is the order the products are in.
+	Sort string `json:"sort"`
+	// SearchID identifies the search when searches are logged, for
+	// reporting which of its results is opened.
+	SearchID string `json:"search_id,omitempty"`
+}
+
+// NewSearchResult summarises c for a list of results.
+func NewSearchResult(c Comparison) SearchResult {
+	r := SearchResult{
+		Product:       c.Product,
+		BestDeal:      c.BestDeal,
+		MinPrice:      c.Stats.LowestPrice,
+		MaxPrice:      c.Stats.HighestPrice,
+		RetailerCount: c.Stats.TotalRetailers,
+	}
+	if c.BestDeal != nil {
+		r.PricePerServing = PricePerServing(c.Product, *c.BestDeal)
+	}
+	return r
+}
+
+// SearchTerms splits text into lower-case words of letters and digits,
+// the terms both the query and the indexed text are reduced to.
+func SearchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+	})
+}
+
+// MaxTypos is how many edits a search term may be away from a word and
+// still match it in a fuzzy search: none for very short terms, where one
+// edit makes a different word, and at most two for long ones.
+func MaxTypos(term string) int {
+	switch n := utf8.RuneCountInString(term); {
+	case n < 3:
+		return 0
+	case n <= 5:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// EditDistance returns the Levenshtein distance between a and b: the
+// fewest single-character insertions, deletions and substitutions that
+// turn one into the other.
+func EditDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
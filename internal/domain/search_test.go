@@ -0,0 +1,57 @@
+package domain_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestSearchTerms(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSearchTerms", "internal/domain")
+
+	got := domain.SearchTerms("Gold Standard 100% Whey, Double-Rich!")
+	want := []string{"gold", "standard", "100", "whey", "double", "rich"}
+	testhelpers.LogTestAssertion(logger, "terms", want, got)
+	if !slices.Equal(got, want) {
+		t.Errorf("SearchTerms = %q, want %q", got, want)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSearchTerms", true)
+}
+
+func TestEditDistance(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestEditDistance", "internal/domain")
+
+	testCases := []struct {
+		a, b   string
+		expect int
+		typos  int
+	}{
+		{"whey", "whey", 0, 1},
+		{"optmum", "optimum", 1, 2},
+		{"nutriton", "nutrition", 1, 2},
+		{"wehy", "whey", 2, 1},
+		{"", "gold", 4, 0},
+		{"ब्रांड", "ब्रैंड", 1, 2},
+		{"on", "ob", 1, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.a+"/"+tc.b, func(t *testing.T) {
+			got := domain.EditDistance(tc.a, tc.b)
+			testhelpers.LogTestAssertion(logger, "distance", tc.expect, got)
+			if got != tc.expect || domain.EditDistance(tc.b, tc.a) != got {
+				t.Errorf("EditDistance(%q, %q) = %d, want %d both ways", tc.a, tc.b, got, tc.expect)
+			}
+			if typos := domain.MaxTypos(tc.a); typos != tc.typos {
+				t.Errorf("MaxTypos(%q) = %d, want %d", tc.a, typos, tc.typos)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestEditDistance", true)
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// minRedactedDigits is the length from which a number in a query is taken
+// for a phone, order or card number rather than a pack size or a price.
+const minRedactedDigits = 7
+
+// SearchLog records one page of search results served. Nothing about who
+// searched is kept, and the query is anonymized (see AnonymizeQuery).
+type SearchLog struct {
+	// ID identifies the search to the clicks on its results.
+	ID      string `json:"id"`
+	Query   string `json:"query"`
+	Page    int    `json:"page"`
+	Results int    `json:"results"`
+	Fuzzy   bool   `json:"fuzzy,omitempty"`
+	// CreatedAt is truncated to the hour, so logs cannot be matched to
+	// requests.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SearchClick records a search result being opened.
+type SearchClick struct {
+	SearchID  string `json:"search_id"`
+	ProductID string `json:"product_id"`
+	// Position is the result's 1-based rank across all pages.
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// QueryStats sums up the searches for one query.
+type QueryStats struct {
+	Query       string `json:"query"`
+	Searches    int    `json:"searches"`
+	ZeroResults int    `json:"zero_results"`
+	Clicks      int    `json:"clicks"`
+	// ClickRate is Clicks over Searches.
+	ClickRate float64 `json:"click_rate"`
+	// MeanClickPosition is the average Position clicked, 0 without clicks.
+	MeanClickPosition float64   `json:"mean_click_position"`
+	LastSearchedAt    time.Time `json:"last_searched_at"`
+}
+
+// SearchReport sums up the searches made since a time: how many found
+// nothing, the most frequent queries, and the most frequent of those that
+// found nothing, which point at gaps in the catalog or its synonyms.
+// Only first pages count as searches.
+type SearchReport struct {
+	Since          time.Time    `json:"since"`
+	Searches       int          `json:"searches"`
+	ZeroResults    int          `json:"zero_results"`
+	ZeroResultRate float64      `json:"zero_result_rate"`
+	Clicks         int          `json:"clicks"`
+	TopQueries     []QueryStats `json:"top_queries"`
+	ZeroQueries    []QueryStats `json:"zero_result_queries"`
+}
+
+// AnonymizeQuery reduces a query to its search terms, replacing email
+// addresses and long numbers, which could identify someone, with "#". A
+// number typed in groups, as phone numbers often are, counts as one.
+func AnonymizeQuery(text string) string {
+	var terms []string
+	for _, field := range strings.Fields(text) {
+		if at := strings.IndexByte(field, '@'); at > 0 && strings.Contains(field[at:], ".") {
+			terms = append(terms, "#")
+			continue
+		}
+		terms = append(terms, SearchTerms(field)...)
+	}
+	out := make([]string, 0, len(terms))
+	for i := 0; i < len(terms); {
+		// Redact a run of numbers, or a word with many digits, whole.
+		j, digits := i, 0
+		for j < len(terms) && isNumber(terms[j]) {
+			digits += len(terms[j])
+			j++
+		}
+		if j == i {
+			j, digits = i+1, countDigits(terms[i])
+		}
+		if digits >= minRedactedDigits {
+			out = append(out, "#")
+		} else {
+			out = append(out, terms[i:j]...)
+		}
+		i = j
+	}
+	return strings.Join(out, " ")
+}
+
+func isNumber(term string) bool {
+	return term != "" && countDigits(term) == len(term)
+}
+
+func countDigits(term string) int {
+	n := 0
+	for _, r := range term {
+		if r >= '0' && r <= '9' {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,34 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestAnonymizeQuery(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAnonymizeQuery", "internal/domain")
+
+	testCases := []struct {
+		query  string
+		expect string
+	}{
+		{"Gold Standard 2.27 kg", "gold standard 2 27 kg"},
+		{"whey 1000g", "whey 1000g"},
+		{"order 4031234567 refund", "order # refund"},
+		{"call +91 98123 45678 now", "call # now"},
+		{"me@example.com isolate", "# isolate"},
+		{"@on whey", "on whey"},
+	}
+	for _, tc := range testCases {
+		got := domain.AnonymizeQuery(tc.query)
+		testhelpers.LogTestAssertion(logger, tc.query, tc.expect, got)
+		if got != tc.expect {
+			t.Errorf("AnonymizeQuery(%q) = %q, want %q", tc.query, got, tc.expect)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAnonymizeQuery", true)
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SelectorConfig holds the CSS selectors the scraper uses to read a
+// retailer's product pages. Several selectors may be listed per field; the
+// first that matches wins, which lets a config survive A/B page layouts.
+type SelectorConfig struct {
+	RetailerID             string    `json:"retailer_id"`
+	TitleSelectors         []string  `json:"title_selectors,omitempty"`
+	PriceSelectors         []string  `json:"price_selectors"`
+	OriginalPriceSelectors []string  `json:"original_price_selectors,omitempty"`
+	StockSelectors         []string  `json:"stock_selectors,omitempty"`
+	SearchURLTemplate      string    `json:"search_url_template,omitempty"`
+	UpdatedAt              time.Time `json:"updated_at"`
+	UpdatedBy              string    `json:"updated_by,omitempty"`
+}
+
+// Validate checks that a price selector is listed and that a search URL
+// template has its {query} placeholder, reporting problems as ErrInvalid.
+func (c SelectorConfig) Validate() error {
+	var problems []string
+	if !slices.ContainsFunc(c.PriceSelectors, func(s string) bool { return strings.TrimSpace(s) != "" }) {
+		problems = append(problems, "at least one price selector is required")
+	}
+	if c.SearchURLTemplate != "" && !strings.Contains(c.SearchURLTemplate, "{query}") {
+		problems = append(problems, "search_url_template must contain {query}")
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", strings.Join(problems, "; "), ErrInvalid)
+}
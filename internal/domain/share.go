@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// ReferralShare marks outbound clicks that came through a deal share link.
+const ReferralShare = "share"
+
+// DealShare is a deal as it stood when someone shared it: one listing's
+// price at a moment. Share links carry it signed, so it needs no storage
+// and cannot be edited to advertise a better price.
+type DealShare struct {
+	ProductID  string    `json:"product_id"`
+	RetailerID string    `json:"retailer_id"`
+	ListingID  string    `json:"listing_id"`
+	Price      float64   `json:"price"`
+	Currency   string    `json:"currency"`
+	SharedAt   time.Time `json:"shared_at"`
+}
+
+// SharedDeal is a share opened later: the snapshot beside the product's
+// comparison now.
+type SharedDeal struct {
+	Share      DealShare   `json:"share"`
+	Comparison *Comparison `json:"comparison"`
+	// Current is the shared listing's offer now, nil once it is no longer
+	// listed.
+	Current *Offer `json:"current,omitempty"`
+}
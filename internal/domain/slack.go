@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// SlackWebhook is the Slack incoming webhook a user's alerts are posted
+// to, which Slack ties to one channel in one workspace.
+type SlackWebhook struct {
+	UserID string `json:"-"`
+	// URL is itself the credential for posting to the channel, so it is
+	// never shown or logged after it is saved.
+	URL string `json:"-"`
+	// TeamID is the workspace, as the URL names it.
+	TeamID    string    `json:"team_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
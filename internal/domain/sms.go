@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// SMSSubscription is the phone number a user's SMS alerts go to. Texts are
+// only sent once the number is verified with a code texted to it.
+type SMSSubscription struct {
+	UserID string `json:"-"`
+	// Phone is in E.164 form, e.g. "+919812345678".
+	Phone      string     `json:"phone"`
+	Verified   bool       `json:"verified"`
+	CreatedAt  time.Time  `json:"created_at"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	// CodeHash is a hash of the pending verification code; the code itself
+	// is only ever texted.
+	CodeHash      string    `json:"-"`
+	CodeExpiresAt time.Time `json:"-"`
+	CodeAttempts  int       `json:"-"`
+}
+
+// SMSMessage records a sent text, for spending caps.
+type SMSMessage struct {
+	UserID string
+	Cost   float64
+	SentAt time.Time
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Result orders for search results and deal listings.
+const (
+	// SortRelevance keeps search results in relevance order.
+	SortRelevance = "relevance"
+	// SortPricePerServing puts the cheapest serving first.
+	SortPricePerServing = "price_per_serving"
+	// SortPricePerProtein puts the cheapest gram of protein first.
+	SortPricePerProtein = "price_per_protein"
+	// SortDiscount puts the largest saving against MRP, in rupees, first.
+	SortDiscount = "discount"
+	// SortDealScore puts the highest DealScore first.
+	SortDealScore = "deal_score"
+)
+
+// ResultSorts lists every result order.
+var ResultSorts = []string{SortRelevance, SortPricePerServing, SortPricePerProtein, SortDiscount, SortDealScore}
+
+// IsResultSort reports whether s is one of ResultSorts.
+func IsResultSort(s string) bool {
+	return slices.Contains(ResultSorts, s)
+}
+
+// PricePerServing returns what one serving of p costs at o, or 0 when the
+// servings in o's pack are unknown.
+func PricePerServing(p Product, o Offer) float64 {
+	var servings float64
+	switch {
+	case p.ServingSizeGrams > 0 && o.SizeGrams > 0:
+		servings = float64(o.SizeGrams) / p.ServingSizeGrams
+	case p.ServingsPerContainer > 0:
+		servings = float64(p.ServingsPerContainer)
+	}
+	if servings <= 0 || o.Price <= 0 {
+		return 0
+	}
+	return Round2(o.Price / servings)
+}
+
+// DiscountAmount returns how much o saves against its MRP, or 0 without
+// one.
+func DiscountAmount(o Offer) float64 {
+	if o.OriginalPrice <= o.Price {
+		return 0
+	}
+	return Round2(o.OriginalPrice - o.Price)
+}
+
+// CompareDeals orders a before b, by returning a negative number, when a
+// sorts first under by. Deals without the value sorted by, such as a price
+// per serving for a product with no known serving size, sort last. Under
+// SortRelevance, and between equal deals, it returns 0.
+func CompareDeals(a, b Deal, by string) int {
+	switch by {
+	case SortPricePerServing:
+		return ascending(PricePerServing(a.Product, a.Offer), PricePerServing(b.Product, b.Offer))
+	case SortPricePerProtein:
+		return ascending(a.Offer.PricePerGramProtein, b.Offer.PricePerGramProtein)
+	case SortDiscount:
+		return cmp.Compare(DiscountAmount(b.Offer), DiscountAmount(a.Offer))
+	case SortDealScore:
+		return cmp.Compare(b.Score, a.Score)
+	default:
+		return 0
+	}
+}
+
+// ascending compares prices lowest first, unknown (zero) ones last.
+func ascending(a, b float64) int {
+	switch {
+	case a == b:
+		return 0
+	case a <= 0:
+		return 1
+	case b <= 0:
+		return -1
+	default:
+		return cmp.Compare(a, b)
+	}
+}
+
+// SortDeals orders deals by, keeping the order of equal ones.
+func SortDeals(deals []Deal, by string) {
+	slices.SortStableFunc(deals, func(a, b Deal) int { return CompareDeals(a, b, by) })
+}
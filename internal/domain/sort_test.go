@@ -0,0 +1,60 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestSortDeals(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSortDeals", "internal/domain")
+
+	// 1kg at 2000 in 30g servings, 2kg at 3000 without a serving size,
+	// and 1kg at 1800 in 25g servings.
+	deals := []domain.Deal{
+		{
+			Product: domain.Product{ID: "a", ServingSizeGrams: 30},
+			Offer:   domain.Offer{Price: 2000, OriginalPrice: 2500, SizeGrams: 1000, PricePerGramProtein: 2.5},
+			Score:   40,
+		},
+		{
+			Product: domain.Product{ID: "b"},
+			Offer:   domain.Offer{Price: 3000, SizeGrams: 2000, PricePerGramProtein: 1.9},
+			Score:   70,
+		},
+		{
+			Product: domain.Product{ID: "c", ServingSizeGrams: 25},
+			Offer:   domain.Offer{Price: 1800, OriginalPrice: 2000, SizeGrams: 1000},
+			Score:   40,
+		},
+	}
+	testCases := []struct {
+		by     string
+		expect string
+	}{
+		{domain.SortPricePerServing, "cab"},
+		{domain.SortPricePerProtein, "bac"},
+		{domain.SortDiscount, "acb"},
+		{domain.SortDealScore, "bac"},
+		{domain.SortRelevance, "abc"},
+	}
+	for _, tc := range testCases {
+		sorted := append([]domain.Deal(nil), deals...)
+		domain.SortDeals(sorted, tc.by)
+		var got string
+		for _, d := range sorted {
+			got += d.Product.ID
+		}
+		testhelpers.LogTestAssertion(logger, tc.by, tc.expect, got)
+		if got != tc.expect {
+			t.Errorf("SortDeals(%s) = %s, want %s", tc.by, got, tc.expect)
+		}
+	}
+	if got := domain.PricePerServing(deals[0].Product, deals[0].Offer); got != 60 {
+		t.Errorf("PricePerServing = %v, want 60", got)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSortDeals", true)
+}
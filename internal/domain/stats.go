@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// CatalogStats summarises how much data the platform tracks.
+type CatalogStats struct {
+	Products    int   `json:"products"`
+	Retailers   int   `json:"retailers"`
+	Listings    int   `json:"listings"`
+	PricePoints int64 `json:"price_points"`
+	// LastUpdatedAt is the newest price observation; nil before the first
+	// scrape.
+	LastUpdatedAt *time.Time `json:"last_updated_at"`
+	GeneratedAt   time.Time  `json:"generated_at"`
+}
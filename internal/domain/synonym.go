@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Synonym limits.
+const (
+	MaxSynonymTerms = 10
+	// MaxSynonymWords bounds the words in one term, and so how far ahead
+	// query expansion looks for a phrase.
+	MaxSynonymWords = 4
+)
+
+// Synonym is a group of terms a search treats as the same, such as "WPI"
+// and "whey protein isolate", "ON" and "Optimum Nutrition", or "5lb" and
+// "2.27kg". Searching for any one finds products mentioning any other.
+type Synonym struct {
+	ID        string    `json:"id"`
+	Terms     []string  `json:"terms"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks that s groups at least two different terms of at most
+// MaxSynonymWords words each, and trims them.
+func (s *Synonym) Validate() error {
+	if len(s.Terms) < 2 || len(s.Terms) > MaxSynonymTerms {
+		return fmt.Errorf("a synonym groups 2 to %d terms: %w", MaxSynonymTerms, ErrInvalid)
+	}
+	seen := make(map[string]bool, len(s.Terms))
+	for i, t := range s.Terms {
+		s.Terms[i] = strings.TrimSpace(t)
+		words := SearchTerms(t)
+		switch key := strings.Join(words, " "); {
+		case len(words) == 0:
+			return fmt.Errorf("synonym term %q has no words: %w", t, ErrInvalid)
+		case len(words) > MaxSynonymWords:
+			return fmt.Errorf("synonym term %q has more than %d words: %w", t, MaxSynonymWords, ErrInvalid)
+		case seen[key]:
+			return fmt.Errorf("synonym term %q is listed twice: %w", t, ErrInvalid)
+		default:
+			seen[key] = true
+		}
+	}
+	return nil
+}
+
+// SearchClause is a part of a query every match must contain: one of its
+// alternative phrases, each of one or more search terms. The first
+// alternative is what was typed.
+type SearchClause struct {
+	Alternatives [][]string
+	// Prefix lets the last word of the typed phrase match as a prefix, as
+	// it may still be being typed.
+	Prefix bool
+}
+
+// Synonyms expands queries with groups of equivalent terms.
+type Synonyms struct {
+	groups  [][][]string     // each group's terms, as search terms
+	byTerms map[string][]int // phrase -> groups containing it
+	longest int              // words in the longest phrase
+}
+
+// NewSynonyms indexes groups for expansion; groups should be valid.
+func NewSynonyms(groups []Synonym) *Synonyms {
+	s := &Synonyms{byTerms: make(map[string][]int)}
+	for _, g := range groups {
+		var phrases [][]string
+		for _, t := range g.Terms {
+			words := SearchTerms(t)
+			if len(words) == 0 {
+				continue
+			}
+			key := strings.Join(words, " ")
+			s.byTerms[key] = append(s.byTerms[key], len(s.groups))
+			s.longest = max(s.longest, len(words))
+			phrases = append(phrases, words)
+		}
+		s.groups = append(s.groups, phrases)
+	}
+	return s
+}
+
+// Expand turns query terms into clauses, each word its own clause unless
+// it starts a phrase some synonym group lists; then the longest such
+// phrase becomes one clause with the group's other terms as alternatives.
+// The last clause is a prefix clause. A nil Synonyms expands nothing.
+func (s *Synonyms) Expand(terms []string) []SearchClause {
+	var clauses []SearchClause
+	for i := 0; i < len(terms); {
+		n, groups := s.match(terms[i:])
+		if n == 0 {
+			clauses = append(clauses, SearchClause{Alternatives: [][]string{{terms[i]}}})
+			i++
+			continue
+		}
+		typed := terms[i : i+n]
+		c := SearchClause{Alternatives: [][]string{typed}}
+		key := strings.Join(typed, " ")
+		for _, g := range groups {
+			for _, p := range s.groups[g] {
+				if strings.Join(p, " ") != key {
+					c.Alternatives = append(c.Alternatives, p)
+				}
+			}
+		}
+		clauses = append(clauses, c)
+		i += n
+	}
+	if len(clauses) > 0 {
+		clauses[len(clauses)-1].Prefix = true
+	}
+	return clauses
+}
+
+// match returns the length of the longest phrase starting terms that a
+// group lists, and the groups listing it.
+func (s *Synonyms) match(terms []string) (int, []int) {
+	if s == nil {
+		return 0, nil
+	}
+	for n := min(s.longest, len(terms)); n > 0; n-- {
+		if groups, ok := s.byTerms[strings.Join(terms[:n], " ")]; ok {
+			return n, groups
+		}
+	}
+	return 0, nil
+}
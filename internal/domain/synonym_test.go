@@ -0,0 +1,70 @@
+package domain_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestSynonym_Validate(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSynonym_Validate", "internal/domain")
+
+	testCases := []struct {
+		name  string
+		terms []string
+		valid bool
+	}{
+		{"Abbreviation", []string{" WPI ", "whey protein isolate"}, true},
+		{"Single term", []string{"WPI"}, false},
+		{"Same words twice", []string{"5 lb", "5-LB"}, false},
+		{"Punctuation only", []string{"ON", "!!"}, false},
+		{"Phrase too long", []string{"MB", "muscle blaze whey protein gold"}, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := domain.Synonym{Terms: tc.terms}
+			err := s.Validate()
+			testhelpers.LogTestAssertion(logger, tc.name, tc.valid, err == nil)
+			if (err == nil) != tc.valid || err != nil && !errors.Is(err, domain.ErrInvalid) {
+				t.Errorf("Validate(%q) = %v, want valid %v", tc.terms, err, tc.valid)
+			}
+			if tc.valid && s.Terms[0] != "WPI" {
+				t.Errorf("Terms not trimmed: %q", s.Terms)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSynonym_Validate", true)
+}
+
+func TestSynonyms_Expand(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSynonyms_Expand", "internal/domain")
+
+	syn := domain.NewSynonyms([]domain.Synonym{
+		{Terms: []string{"ON", "Optimum Nutrition"}},
+		{Terms: []string{"WPI", "whey protein isolate", "isolate"}},
+	})
+
+	testhelpers.LogTestStep(logger, "assert", "Longest phrases expand, other words stay single clauses")
+	got := syn.Expand(domain.SearchTerms("optimum nutrition WPI choc"))
+	want := []domain.SearchClause{
+		{Alternatives: [][]string{{"optimum", "nutrition"}, {"on"}}},
+		{Alternatives: [][]string{{"wpi"}, {"whey", "protein", "isolate"}, {"isolate"}}},
+		{Alternatives: [][]string{{"choc"}}, Prefix: true},
+	}
+	testhelpers.LogTestAssertion(logger, "clauses", want, got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expand = %+v, want %+v", got, want)
+	}
+	var none *domain.Synonyms
+	if got := none.Expand([]string{"on", "gold"}); len(got) != 2 || len(got[0].Alternatives) != 1 || !got[1].Prefix {
+		t.Errorf("nil Expand = %+v", got)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSynonyms_Expand", true)
+}
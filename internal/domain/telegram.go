@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// TelegramLink connects a user to the Telegram chat their alerts are sent
+// to. A user has at most one chat and a chat belongs to at most one user.
+type TelegramLink struct {
+	UserID   string
+	ChatID   int64
+	LinkedAt time.Time
+}
+
+// TelegramLinkToken is a pending link, carried in a t.me deep link. Only a
+// hash of the token is stored.
+type TelegramLinkToken struct {
+	TokenHash string
+	UserID    string
+	ExpiresAt time.Time
+}
@@ -0,0 +1,146 @@
+package domain
+
+import "strings"
+
+// Devanagari signs that change the letter before them.
+const (
+	virama = '्' // drops a consonant's inherent vowel
+	nukta  = '़' // turns a consonant into one borrowed from Persian or English
+)
+
+// devanagariConsonants romanizes consonants the way Hindi is commonly typed
+// in Latin letters, without diacritics.
+var devanagariConsonants = map[rune]string{
+	'क': "k", 'ख': "kh", 'ग': "g", 'घ': "gh", 'ङ': "n",
+	'च': "ch", 'छ': "chh", 'ज': "j", 'झ': "jh", 'ञ': "n",
+	'ट': "t", 'ठ': "th", 'ड': "d", 'ढ': "dh", 'ण': "n",
+	'त': "t", 'थ': "th", 'द': "d", 'ध': "dh", 'न': "n",
+	'प': "p", 'फ': "ph", 'ब': "b", 'भ': "bh", 'म': "m",
+	'य': "y", 'र': "r", 'ल': "l", 'ळ': "l", 'व': "v",
+	'श': "sh", 'ष': "sh", 'स': "s", 'ह': "h",
+	// The precomposed nukta letters.
+	'\u0958': "q", '\u0959': "kh", '\u095A': "g", '\u095B': "z", '\u095C': "r", '\u095D': "rh", '\u095E': "f", '\u095F': "y",
+}
+
+// nuktaForms are the consonants a following nukta changes.
+var nuktaForms = map[rune]string{
+	'क': "q", 'ज': "z", 'ड': "r", 'ढ': "rh", 'फ': "f", 'य': "y",
+}
+
+// devanagariSigns romanizes independent vowels, vowel signs, nasal signs
+// and digits.
+var devanagariSigns = map[rune]string{
+	'अ': "a", 'आ': "aa", 'इ': "i", 'ई': "ee", 'उ': "u", 'ऊ': "oo", 'ऋ': "ri",
+	'ए': "e", 'ऐ': "ai", 'ओ': "o", 'औ': "au", 'ऑ': "o", 'ऍ': "e",
+	'ा': "aa", 'ि': "i", 'ी': "ee", 'ु': "u", 'ू': "oo", 'ृ': "ri",
+	'े': "e", 'ै': "ai", 'ो': "o", 'ौ': "au", 'ॉ': "o", 'ॅ': "e",
+	'ं': "n", 'ँ': "n", 'ः': "h",
+	'०': "0", '१': "1", '२': "2", '३': "3", '४': "4",
+	'५': "5", '६': "6", '७': "7", '८': "8", '९': "9",
+	'।': " ", '॥': " ",
+}
+
+// Transliterate romanizes the Devanagari in text as Hindi is typed in
+// Latin letters, so "वे प्रोटीन" becomes "ve proteen"; other text is kept.
+// A consonant's inherent "a" is dropped at the end of a word, as Hindi
+// speakers drop it.
+func Transliterate(text string) string {
+	if !strings.ContainsFunc(text, func(r rune) bool { return r >= 0x0900 && r <= 0x097F }) {
+		return text
+	}
+	rs := []rune(text)
+	var b strings.Builder
+	for i := 0; i < len(rs); i++ {
+		c, ok := devanagariConsonants[rs[i]]
+		if !ok {
+			if s, ok := devanagariSigns[rs[i]]; ok {
+				b.WriteString(s)
+			} else if rs[i] != virama && rs[i] != nukta {
+				b.WriteRune(rs[i])
+			}
+			continue
+		}
+		if i+1 < len(rs) && rs[i+1] == nukta {
+			if n, ok := nuktaForms[rs[i]]; ok {
+				c = n
+			}
+			i++
+		}
+		b.WriteString(c)
+		if i+1 >= len(rs) {
+			break
+		}
+		// Another letter or a nasal sign follows: the inherent vowel is
+		// sounded.
+		if next := rs[i+1]; next >= 'ँ' && next < 'ा' {
+			b.WriteByte('a')
+		}
+	}
+	return b.String()
+}
+
+// PhoneticKey reduces a search term to how it sounds, so spellings of the
+// same word in English, romanized Hindi and Devanagari share a key:
+// "whey", "vhey" and "वे" are all "v", "protein" and "प्रोटीन" both "prtn".
+// It keeps consonants only, merging those often swapped (w and v, c and k
+// or s, z and j) and silent or aspirating h's, and marks a leading vowel
+// as "a". Terms with digits or other scripts have no key.
+func PhoneticKey(term string) string {
+	s := strings.ToLower(Transliterate(term))
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'a' || s[i] > 'z' {
+			return ""
+		}
+	}
+	vowel := func(i int) bool { return i < len(s) && strings.IndexByte("aeiou", s[i]) >= 0 }
+	at := func(i int) byte {
+		if i < len(s) {
+			return s[i]
+		}
+		return 0
+	}
+	var key []byte
+	emit := func(c byte) {
+		if len(key) == 0 || key[len(key)-1] != c {
+			key = append(key, c)
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case vowel(i):
+			if i == 0 {
+				emit('a')
+			}
+		case c == 'h':
+		case c == 'y':
+			if i == 0 {
+				emit('y')
+			}
+		case c == 'w':
+			if i == 0 || !vowel(i-1) {
+				emit('v')
+			}
+		case c == 'c' && at(i+1) == 'h':
+			emit('c')
+			i++
+		case c == 'c' && strings.IndexByte("eiy", at(i+1)) >= 0:
+			emit('s')
+		case c == 'c', c == 'q':
+			emit('k')
+		case c == 'p' && at(i+1) == 'h':
+			emit('f')
+			i++
+		case c == 't' && at(i+1) == 'i' && (at(i+2) == 'a' || at(i+2) == 'o'):
+			// The "sh" of "nutrition".
+			emit('s')
+		case c == 'x':
+			emit('k')
+			emit('s')
+		case c == 'z':
+			emit('j')
+		default:
+			emit(c)
+		}
+	}
+	return string(key)
+}
@@ -0,0 +1,68 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestTransliterate(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestTransliterate", "internal/domain")
+
+	testCases := []struct {
+		text   string
+		expect string
+	}{
+		{"वे प्रोटीन", "ve proteen"},
+		{"ऑप्टिमम न्यूट्रिशन", "optimam nyootrishan"},
+		{"मसल ब्लेज़", "masal blez"},
+		{"प्रोटीन पाउडर १ किलो", "proteen paaudar 1 kilo"},
+		{"whey 1kg", "whey 1kg"},
+	}
+	for _, tc := range testCases {
+		got := domain.Transliterate(tc.text)
+		testhelpers.LogTestAssertion(logger, tc.text, tc.expect, got)
+		if got != tc.expect {
+			t.Errorf("Transliterate(%q) = %q, want %q", tc.text, got, tc.expect)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestTransliterate", true)
+}
+
+func TestPhoneticKey(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPhoneticKey", "internal/domain")
+
+	testhelpers.LogTestStep(logger, "assert", "Spellings of one word share a key")
+	for _, spellings := range [][]string{
+		{"whey", "vhey", "wey", "वे"},
+		{"protein", "protin", "प्रोटीन"},
+		{"optimum", "ऑप्टिमम"},
+		{"nutrition", "nutrishan", "न्यूट्रिशन"},
+		{"isolate", "आइसोलेट"},
+		{"powder", "पाउडर"},
+		{"chocolate", "चॉकलेट"},
+	} {
+		want := domain.PhoneticKey(spellings[0])
+		for _, s := range spellings[1:] {
+			got := domain.PhoneticKey(s)
+			testhelpers.LogTestAssertion(logger, s, want, got)
+			if got != want {
+				t.Errorf("PhoneticKey(%q) = %q, want %q as for %q", s, got, want, spellings[0])
+			}
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Different words and numbers do not")
+	if domain.PhoneticKey("whey") == domain.PhoneticKey("casein") {
+		t.Error("whey and casein share a key")
+	}
+	if k := domain.PhoneticKey("5lb"); k != "" {
+		t.Errorf("PhoneticKey(5lb) = %q, want none", k)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPhoneticKey", true)
+}
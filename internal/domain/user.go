@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// User is a registered account. Email is stored normalised (trimmed and
+// lower-cased) so it can be matched exactly.
+type User struct {
+	ID            string     `json:"id"`
+	Email         string     `json:"email"`
+	Name          string     `json:"name,omitempty"`
+	EmailVerified bool       `json:"email_verified"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastLoginAt   *time.Time `json:"last_login_at,omitempty"`
+	// PasswordHash is an encoded argon2id hash; empty for accounts that
+	// can only sign in another way.
+	PasswordHash string `json:"-"`
+}
+
+// Session is a signed-in browser. Only a hash of the cookie token is stored,
+// so a leaked table cannot be replayed.
+type Session struct {
+	TokenHash string
+	UserID    string
+	UserAgent string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// API token scopes. Read-only tokens can make GET requests; alert tokens
+// can also create, change and delete the user's price alerts.
+const (
+	ScopeRead   = "read"
+	ScopeAlerts = "alerts:manage"
+)
+
+// APITokenScopes lists the valid scopes.
+var APITokenScopes = []string{ScopeRead, ScopeAlerts}
+
+// APIToken is a personal access token for calling the API without a
+// browser session. Like sessions, only a hash of the token is stored.
+type APIToken struct {
+	ID     string `json:"id"`
+	UserID string `json:"-"`
+	Name   string `json:"name"`
+	// Prefix is the start of the token, shown so users can tell their
+	// tokens apart.
+	Prefix     string     `json:"prefix"`
+	TokenHash  string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// HasScope reports whether t grants scope.
+func (t APIToken) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, scope)
+}
+
+// EmailVerification is a pending confirmation of a user's address. Email is
+// the address the token was sent to, so a token issued before an address
+// change cannot verify the new one.
+type EmailVerification struct {
+	TokenHash string
+	UserID    string
+	Email     string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Suppression reasons.
+const (
+	SuppressionBounce    = "bounce"
+	SuppressionComplaint = "complaint"
+)
+
+// Suppression stops email to an address that hard-bounced or reported a
+// message as spam; sending to it again harms the sender's reputation.
+type Suppression struct {
+	Email     string
+	Reason    string
+	CreatedAt time.Time
+}
@@ -0,0 +1,70 @@
+package domain
+
+import "time"
+
+// WatchlistItem is a product a user saved to follow its price.
+type WatchlistItem struct {
+	UserID    string    `json:"-"`
+	ProductID string    `json:"product_id"`
+	AddedAt   time.Time `json:"added_at"`
+	// AddedPrice is the best in-stock price when the product was saved, or
+	// 0 if nothing was in stock.
+	AddedPrice float64 `json:"added_price,omitempty"`
+}
+
+// WatchlistEntry is a watchlist item priced now.
+type WatchlistEntry struct {
+	WatchlistItem
+	// Product is nil once the product is no longer listed.
+	Product *Product `json:"product,omitempty"`
+	// Best is the cheapest in-stock offer, nil when out of stock everywhere.
+	Best *Offer `json:"best_offer,omitempty"`
+	// Change and ChangePercent compare Best with AddedPrice; they are 0
+	// unless both are known.
+	Change        float64 `json:"change"`
+	ChangePercent float64 `json:"change_percent"`
+}
+
+// Portfolio is a user's watchlist priced now, newest item first.
+type Portfolio struct {
+	Items []WatchlistEntry `json:"items"`
+	// BasketTotal is what buying the best offer of every available item
+	// costs now; Unavailable items are left out of it.
+	BasketTotal        float64 `json:"basket_total"`
+	BasketTotalDisplay string  `json:"basket_total_display,omitempty"`
+	// BasketChange is the sum of the items' Change.
+	BasketChange float64 `json:"basket_change"`
+	Unavailable  int     `json:"unavailable"`
+	Currency     string  `json:"currency"`
+}
+
+// NewPortfolio prices items with comparisons, keyed by product ID. Items
+// without a comparison are unlisted products.
+func NewPortfolio(items []WatchlistItem, comparisons map[string]*Comparison) *Portfolio {
+	p := &Portfolio{Items: make([]WatchlistEntry, 0, len(items)), Currency: DefaultCurrency}
+	for _, item := range items {
+		e := WatchlistEntry{WatchlistItem: item}
+		if c := comparisons[item.ProductID]; c != nil {
+			e.Product = &c.Product
+			if len(c.Prices) > 0 && c.Prices[0].InStock {
+				best := c.Prices[0]
+				e.Best = &best
+			}
+		}
+		if e.Best == nil {
+			p.Unavailable++
+			p.Items = append(p.Items, e)
+			continue
+		}
+		p.BasketTotal += e.Best.Price
+		if item.AddedPrice > 0 {
+			e.Change = Round2(e.Best.Price - item.AddedPrice)
+			e.ChangePercent = Round2(e.Change / item.AddedPrice * 100)
+			p.BasketChange += e.Change
+		}
+		p.Items = append(p.Items, e)
+	}
+	p.BasketTotal = Round2(p.BasketTotal)
+	p.BasketChange = Round2(p.BasketChange)
+	return p
+}
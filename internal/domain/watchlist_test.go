@@ -0,0 +1,63 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestNewPortfolio(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestNewPortfolio", "internal/domain")
+
+	now := time.Now()
+	items := []domain.WatchlistItem{
+		{ProductID: "prod_up", AddedAt: now, AddedPrice: 2000},
+		{ProductID: "prod_new", AddedAt: now},
+		{ProductID: "prod_oos", AddedAt: now, AddedPrice: 1500},
+		{ProductID: "prod_gone", AddedAt: now, AddedPrice: 999},
+	}
+	comparisons := map[string]*domain.Comparison{
+		"prod_up":  {Product: domain.Product{ID: "prod_up"}, Prices: []domain.Offer{{Price: 2150.5, InStock: true}, {Price: 2400, InStock: true}}},
+		"prod_new": {Product: domain.Product{ID: "prod_new"}, Prices: []domain.Offer{{Price: 1000, InStock: true}}},
+		"prod_oos": {Product: domain.Product{ID: "prod_oos"}, Prices: []domain.Offer{{Price: 1200, InStock: false}}},
+	}
+
+	p := domain.NewPortfolio(items, comparisons)
+
+	testCases := []struct {
+		name        string
+		entry       domain.WatchlistEntry
+		wantBest    float64 // 0 for none
+		wantChange  float64
+		wantPercent float64
+		wantProduct bool
+	}{
+		{"Price went up", p.Items[0], 2150.5, 150.5, 7.53, true},
+		{"Added while out of stock", p.Items[1], 1000, 0, 0, true},
+		{"Out of stock now", p.Items[2], 0, 0, 0, true},
+		{"No longer listed", p.Items[3], 0, 0, 0, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var best float64
+			if tc.entry.Best != nil {
+				best = tc.entry.Best.Price
+			}
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantChange, tc.entry.Change)
+			if best != tc.wantBest || tc.entry.Change != tc.wantChange || tc.entry.ChangePercent != tc.wantPercent || (tc.entry.Product != nil) != tc.wantProduct {
+				t.Errorf("Entry = %+v", tc.entry)
+			}
+		})
+	}
+	if p.BasketTotal != 3150.5 || p.BasketChange != 150.5 || p.Unavailable != 2 || p.Currency != domain.DefaultCurrency {
+		t.Errorf("Portfolio totals = %v, change %v, unavailable %d", p.BasketTotal, p.BasketChange, p.Unavailable)
+	}
+	if empty := domain.NewPortfolio(nil, nil); empty.Items == nil || empty.BasketTotal != 0 {
+		t.Errorf("Empty portfolio = %+v, want an empty item list", empty)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestNewPortfolio", true)
+}
@@ -0,0 +1,258 @@
+// Package errtrack reports errors and panics to a Sentry-compatible
+// service, such as Sentry or GlitchTip, so failures are grouped, counted
+// and alerted on instead of grepped for in logs. Like internal/tracing it
+// speaks the wire protocol directly rather than pulling in the SDK.
+//
+// Reports come from the logs: a Reporter's Core is teed into the
+// service's logger, so every line logged at Error or above becomes an
+// event with the caller's stack trace, and the line's string fields, such
+// as the request_id and trace_id logctx adds, become its tags:
+//
+//	log = log.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+//		return zapcore.NewTee(c, reporter.Core(zapcore.ErrorLevel))
+//	}))
+package errtrack
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// clientName identifies this reporter to the service.
+const clientName = "whey-price-compare/1.0"
+
+// Config configures a Reporter.
+type Config struct {
+	// DSN is the project's client key URL,
+	// scheme://<public key>@host[/path]/<project id>.
+	DSN string
+	// Release and Environment tag every event, so a regression can be
+	// traced to the deploy that introduced it.
+	Release     string
+	Environment string
+	// ServerName tags events with the instance that sent them.
+	ServerName string
+	// SampleRate is the share of errors reported, from 0 to 1. Panics
+	// and fatal errors are always reported.
+	SampleRate float64
+	// Buffer is how many events may wait to be sent. Events beyond it
+	// are dropped rather than slowing the caller down.
+	Buffer int
+	// Timeout bounds one send.
+	Timeout time.Duration
+}
+
+// DefaultConfig reports every error.
+func DefaultConfig() Config {
+	return Config{
+		SampleRate: 1,
+		Buffer:     256,
+		Timeout:    5 * time.Second,
+	}
+}
+
+// dsn is a parsed client key URL.
+type dsn struct {
+	endpoint  string
+	publicKey string
+}
+
+// parseDSN reads a client key URL. Errors never repeat the DSN, which
+// holds the key.
+func parseDSN(raw string) (dsn, error) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return dsn{}, errors.New("errtrack: DSN wants scheme://key@host/project")
+	}
+	key := u.User.Username()
+	if key == "" {
+		return dsn{}, errors.New("errtrack: DSN has no public key")
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	i := strings.LastIndex(path, "/")
+	project := path[i+1:]
+	if _, err := strconv.ParseUint(project, 10, 64); err != nil {
+		return dsn{}, errors.New("errtrack: DSN has no numeric project ID")
+	}
+	return dsn{
+		endpoint:  fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, path[:i], project),
+		publicKey: key,
+	}, nil
+}
+
+// Reporter sends events to the service. Events are queued without
+// blocking; Run sends them until its context ends. A nil *Reporter
+// reports nothing.
+type Reporter struct {
+	cfg     Config
+	dsn     dsn
+	client  *http.Client
+	logger  *zap.Logger
+	events  chan *event
+	dropped atomic.Int64
+	now     func() time.Time
+
+	mu sync.Mutex
+	// pausedUntil is when the service's rate limit ends.
+	pausedUntil time.Time
+}
+
+// NewReporter creates a Reporter for cfg.DSN. Call Run to start sending.
+// Pass a logger not teed into the Reporter, so its own failures are not
+// reported back to it.
+func NewReporter(cfg Config, logger *zap.Logger) (*Reporter, error) {
+	d, err := parseDSN(cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+	def := DefaultConfig()
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = def.Buffer
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	cfg.SampleRate = min(max(cfg.SampleRate, 0), 1)
+	return &Reporter{
+		cfg:    cfg,
+		dsn:    d,
+		client: &http.Client{Timeout: cfg.Timeout},
+		logger: logger,
+		events: make(chan *event, cfg.Buffer),
+		now:    time.Now,
+	}, nil
+}
+
+// Dropped returns how many events were discarded because the buffer was
+// full or the service was rate limiting.
+func (r *Reporter) Dropped() int64 {
+	if r == nil {
+		return 0
+	}
+	return r.dropped.Load()
+}
+
+func (r *Reporter) enqueue(ev *event) {
+	select {
+	case r.events <- ev:
+	default:
+		if r.dropped.Add(1)%100 == 1 {
+			r.logger.Warn("Error report buffer full, dropping events",
+				zap.String("operation", "ReportErrors"),
+				zap.Int64("dropped_total", r.dropped.Load()),
+			)
+		}
+	}
+}
+
+// Run sends queued events until ctx is cancelled, then sends whatever is
+// still buffered. Sends are bounded by Config.Timeout rather than ctx, so
+// cancelling does not abort one already in flight.
+func (r *Reporter) Run(ctx context.Context) {
+	sendCtx := context.WithoutCancel(ctx)
+	for {
+		select {
+		case ev := <-r.events:
+			r.deliver(sendCtx, ev)
+		case <-ctx.Done():
+			for {
+				select {
+				case ev := <-r.events:
+					r.deliver(sendCtx, ev)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (r *Reporter) deliver(ctx context.Context, ev *event) {
+	if err := r.send(ctx, ev); err != nil {
+		r.logger.Warn("Error report failed",
+			zap.String("operation", "ReportErrors"),
+			zap.String("event_id", ev.EventID),
+			zap.Error(err),
+		)
+	}
+}
+
+// errRateLimited is returned while the service asks for a pause.
+var errRateLimited = errors.New("errtrack: rate limited by the service")
+
+// send posts ev as an envelope. While the service is rate limiting, events
+// are dropped without a request.
+func (r *Reporter) send(ctx context.Context, ev *event) error {
+	r.mu.Lock()
+	paused := r.now().Before(r.pausedUntil)
+	r.mu.Unlock()
+	if paused {
+		r.dropped.Add(1)
+		return nil
+	}
+
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+	var body bytes.Buffer
+	_ = json.NewEncoder(&body).Encode(map[string]string{
+		"event_id": ev.EventID,
+		"sent_at":  r.now().UTC().Format(time.RFC3339Nano),
+	})
+	_ = json.NewEncoder(&body).Encode(map[string]any{"type": "event", "length": len(payload)})
+	body.Write(payload)
+	body.WriteByte('\n')
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.dsn.endpoint, &body)
+	if err != nil {
+		return fmt.Errorf("send event: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s, sentry_key=%s", clientName, r.dsn.publicKey))
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send event: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode == http.StatusTooManyRequests {
+		wait, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+		if err != nil || wait <= 0 {
+			wait = 60
+		}
+		r.mu.Lock()
+		r.pausedUntil = r.now().Add(time.Duration(wait) * time.Second)
+		r.mu.Unlock()
+		r.dropped.Add(1)
+		return errRateLimited
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("send event: service answered %s", resp.Status)
+	}
+	return nil
+}
+
+// newEventID returns a random ID in the service's format, 32 hex digits.
+func newEventID() string {
+	var id [16]byte
+	binary.BigEndian.PutUint64(id[:8], rand.Uint64())
+	binary.BigEndian.PutUint64(id[8:], rand.Uint64())
+	return hex.EncodeToString(id[:])
+}
@@ -0,0 +1,186 @@
+package errtrack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/yourusername/whey-price-compare/internal/logctx"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestParseDSN(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestParseDSN", "internal/errtrack")
+
+	testCases := []struct {
+		name         string
+		raw          string
+		wantEndpoint string
+		wantErr      bool
+	}{
+		{"Sentry", "https://abc123@o1.ingest.sentry.io/42", "https://o1.ingest.sentry.io/api/42/envelope/", false},
+		{"Self-hosted under a path", "http://abc123@errors.internal:9000/glitchtip/7/", "http://errors.internal:9000/glitchtip/api/7/envelope/", false},
+		{"No key", "https://o1.ingest.sentry.io/42", "", true},
+		{"No project", "https://abc123@o1.ingest.sentry.io/", "", true},
+		{"Not a URL", "abc123", "", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := parseDSN(tc.raw)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantEndpoint, d.endpoint)
+			if (err != nil) != tc.wantErr || d.endpoint != tc.wantEndpoint {
+				t.Errorf("parseDSN(%q) = %q, %v", tc.raw, d.endpoint, err)
+			}
+			if err != nil && strings.Contains(err.Error(), "abc123") {
+				t.Errorf("Error %q repeats the key", err)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestParseDSN", true)
+}
+
+// service is a Sentry-compatible envelope endpoint keeping the events it
+// receives.
+type service struct {
+	mu       sync.Mutex
+	events   []event
+	auth     []string
+	requests int
+	// status answers every request when set.
+	status int
+}
+
+func (s *service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	s.auth = append(s.auth, r.Header.Get("X-Sentry-Auth"))
+	if s.status != 0 {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(s.status)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	lines := bytes.Split(bytes.TrimSpace(body), []byte("\n"))
+	if len(lines) != 3 {
+		http.Error(w, "want header, item header and item", http.StatusBadRequest)
+		return
+	}
+	var ev event
+	if err := json.Unmarshal(lines[2], &ev); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.events = append(s.events, ev)
+}
+
+// runReporter runs r until the returned stop function is called, which
+// waits for queued events to be sent.
+func runReporter(t *testing.T, r *Reporter) (stop func()) {
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(ctx)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func TestReporter_ReportsErrorLines(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestReporter_ReportsErrorLines", "internal/errtrack")
+
+	testhelpers.LogTestStep(logger, "arrange", "A reporter teed into a logger, and a request's log fields")
+	svc := &service{}
+	srv := httptest.NewServer(svc)
+	defer srv.Close()
+	dsn := strings.Replace(srv.URL, "http://", "http://pubkey@", 1) + "/42"
+	reporter, err := NewReporter(Config{DSN: dsn, Release: "v1.4.2", Environment: "production", SampleRate: 1}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewReporter: %v", err)
+	}
+	stop := runReporter(t, reporter)
+	appLogger := zap.New(reporter.Core(zapcore.ErrorLevel))
+	ctx := logctx.With(t.Context(),
+		zap.String("request_id", "req_abc123"),
+		zap.String("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736"),
+	)
+
+	testhelpers.LogTestStep(logger, "act", "Logging a warning and a failed request")
+	logctx.Logger(ctx, appLogger).Warn("Comparison view read failed, aggregating instead")
+	logctx.Logger(ctx, appLogger).Error("Request failed",
+		zap.String("path", "/api/v1/compare/prod_on_gsw"),
+		zap.Error(fmt.Errorf("load listings: %w", io.ErrUnexpectedEOF)),
+	)
+	stop()
+
+	testhelpers.LogTestStep(logger, "assert", "Only the error is reported, tagged with the request and release")
+	testhelpers.LogTestAssertion(logger, "events", 1, len(svc.events))
+	if len(svc.events) != 1 {
+		t.Fatalf("Reported %d events, want 1", len(svc.events))
+	}
+	ev := svc.events[0]
+	if ev.Level != "error" || ev.Release != "v1.4.2" || ev.Environment != "production" ||
+		ev.Tags["request_id"] != "req_abc123" || ev.Tags["path"] != "/api/v1/compare/prod_on_gsw" {
+		t.Errorf("Event = %+v", ev)
+	}
+	if trace, _ := ev.Contexts["trace"].(map[string]any); trace["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Trace context = %v", ev.Contexts)
+	}
+	exc := ev.Exception.Values[0]
+	if exc.Type != "Request failed: *errors.errorString" || exc.Value != "load listings: unexpected EOF" ||
+		len(exc.Stacktrace.Frames) == 0 || exc.Mechanism != nil {
+		t.Errorf("Exception = %+v", exc)
+	}
+	if !strings.Contains(svc.auth[0], "sentry_key=pubkey") {
+		t.Errorf("X-Sentry-Auth = %q", svc.auth[0])
+	}
+
+	testhelpers.LogTestComplete(logger, "TestReporter_ReportsErrorLines", true)
+}
+
+func TestReporter_SamplesErrorsAndBacksOff(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestReporter_SamplesErrorsAndBacksOff", "internal/errtrack")
+
+	testhelpers.LogTestStep(logger, "arrange", "A reporter sampling no errors, and a service that is rate limiting")
+	svc := &service{status: http.StatusTooManyRequests}
+	srv := httptest.NewServer(svc)
+	defer srv.Close()
+	dsn := strings.Replace(srv.URL, "http://", "http://pubkey@", 1) + "/42"
+	reporter, err := NewReporter(Config{DSN: dsn, SampleRate: 0}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewReporter: %v", err)
+	}
+	stop := runReporter(t, reporter)
+	appLogger := zap.New(reporter.Core(zapcore.ErrorLevel))
+
+	testhelpers.LogTestStep(logger, "act", "Logging an error and two panics")
+	appLogger.Error("Request failed", zap.Error(io.ErrUnexpectedEOF))
+	appLogger.Error("Task panicked", zap.Any("panic", "index out of range"))
+	appLogger.Error("Task panicked", zap.Any("panic", "index out of range"))
+	stop()
+
+	testhelpers.LogTestStep(logger, "assert", "Panics skip sampling; after a 429 the next is dropped unsent")
+	testhelpers.LogTestAssertion(logger, "requests", 1, svc.requests)
+	if svc.requests != 1 || reporter.Dropped() != 2 {
+		t.Errorf("Requests = %d, dropped = %d; want 1 and 2", svc.requests, reporter.Dropped())
+	}
+
+	testhelpers.LogTestComplete(logger, "TestReporter_SamplesErrorsAndBacksOff", true)
+}
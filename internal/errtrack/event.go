@@ -0,0 +1,255 @@
+package errtrack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// maxTagLength is the longest value the service accepts as a tag; longer
+// string fields are sent as extra data.
+const maxTagLength = 200
+
+// modulePath marks this service's stack frames as in-app, so the service
+// groups events by our code rather than the libraries under it.
+const modulePath = "github.com/yourusername/whey-price-compare/"
+
+// event is one error report, in the service's JSON event format.
+type event struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Logger      string            `json:"logger,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       map[string]any    `json:"extra,omitempty"`
+	Contexts    map[string]any    `json:"contexts,omitempty"`
+	Exception   struct {
+		Values []exception `json:"values"`
+	} `json:"exception"`
+}
+
+type exception struct {
+	Type       string     `json:"type"`
+	Value      string     `json:"value"`
+	Mechanism  *mechanism `json:"mechanism,omitempty"`
+	Stacktrace stacktrace `json:"stacktrace"`
+}
+
+type mechanism struct {
+	Type    string `json:"type"`
+	Handled bool   `json:"handled"`
+}
+
+type stacktrace struct {
+	Frames []frame `json:"frames"`
+}
+
+type frame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+// Core returns a zapcore.Core reporting every entry at level or above.
+// Entries with a "panic" field, as recover sites log them, are reported
+// as unhandled panics.
+func (r *Reporter) Core(level zapcore.LevelEnabler) zapcore.Core {
+	if r == nil {
+		return zapcore.NewNopCore()
+	}
+	return &core{LevelEnabler: level, r: r}
+}
+
+type core struct {
+	zapcore.LevelEnabler
+	r      *Reporter
+	fields []zapcore.Field
+}
+
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	return &core{LevelEnabler: c.LevelEnabler, r: c.r, fields: append(slices.Clip(c.fields), fields...)}
+}
+
+func (c *core) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(e.Level) {
+		return ce.AddCore(e, c)
+	}
+	return ce
+}
+
+func (c *core) Write(e zapcore.Entry, fields []zapcore.Field) error {
+	ev := c.r.newEvent(e, append(slices.Clip(c.fields), fields...))
+	if ev == nil {
+		return nil
+	}
+	if e.Level >= zapcore.FatalLevel {
+		// The process exits once the entry is written, before Run could
+		// send it.
+		ctx, cancel := context.WithTimeout(context.Background(), c.r.cfg.Timeout)
+		defer cancel()
+		c.r.deliver(ctx, ev)
+		return nil
+	}
+	c.r.enqueue(ev)
+	return nil
+}
+
+func (c *core) Sync() error { return nil }
+
+// newEvent builds the event for a log entry, or returns nil when the
+// entry is sampled out.
+func (r *Reporter) newEvent(e zapcore.Entry, fields []zapcore.Field) *event {
+	enc := zapcore.NewMapObjectEncoder()
+	var err error
+	var panicked bool
+	for _, f := range fields {
+		switch {
+		case f.Key == "panic":
+			panicked = true
+			f.AddTo(enc)
+		case f.Type == zapcore.ErrorType && err == nil:
+			err, _ = f.Interface.(error)
+		case f.Key == "stack":
+			// Replaced by the event's own stack trace.
+		default:
+			f.AddTo(enc)
+		}
+	}
+	unhandled := panicked || e.Level >= zapcore.DPanicLevel
+	if !unhandled && rand.Float64() >= r.cfg.SampleRate {
+		return nil
+	}
+
+	ev := &event{
+		EventID:     newEventID(),
+		Timestamp:   e.Time.UTC(),
+		Level:       eventLevel(e.Level),
+		Platform:    "go",
+		Logger:      e.LoggerName,
+		Release:     r.cfg.Release,
+		Environment: r.cfg.Environment,
+		ServerName:  r.cfg.ServerName,
+	}
+	for k, v := range enc.Fields {
+		if s, ok := v.(string); ok && len(s) <= maxTagLength {
+			if ev.Tags == nil {
+				ev.Tags = make(map[string]string)
+			}
+			ev.Tags[k] = s
+			continue
+		}
+		if ev.Extra == nil {
+			ev.Extra = make(map[string]any)
+		}
+		ev.Extra[k] = v
+	}
+	if traceID := ev.Tags["trace_id"]; traceID != "" {
+		ev.Contexts = map[string]any{"trace": map[string]string{"trace_id": traceID}}
+	}
+
+	exc := exception{Type: e.Message, Stacktrace: callerStack()}
+	switch {
+	case panicked:
+		exc.Value = fmt.Sprint(enc.Fields["panic"])
+		exc.Mechanism = &mechanism{Type: "panic", Handled: false}
+	case err != nil:
+		exc.Value = err.Error()
+		exc.Type = e.Message + ": " + errorType(err)
+	default:
+		exc.Value = e.Message
+	}
+	ev.Exception.Values = []exception{exc}
+	return ev
+}
+
+// errorType names the innermost error err wraps, so errors from the same
+// cause group together whatever context was added on the way up.
+func errorType(err error) string {
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			return fmt.Sprintf("%T", err)
+		}
+		err = next
+	}
+}
+
+func eventLevel(l zapcore.Level) string {
+	switch {
+	case l >= zapcore.DPanicLevel:
+		return "fatal"
+	case l >= zapcore.ErrorLevel:
+		return "error"
+	case l >= zapcore.WarnLevel:
+		return "warning"
+	case l >= zapcore.InfoLevel:
+		return "info"
+	default:
+		return "debug"
+	}
+}
+
+// callerStack returns the stack that logged the entry, oldest frame
+// first as the service expects, without the logging and runtime frames
+// above it. Logged from a deferred recover, it still holds the frames
+// that panicked.
+func callerStack() stacktrace {
+	pcs := make([]uintptr, 128)
+	n := runtime.Callers(1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var out []frame
+	for {
+		f, more := frames.Next()
+		if !skipFrame(f.Function) {
+			out = append(out, frame{
+				Function: shortFunction(f.Function),
+				Module:   module(f.Function),
+				AbsPath:  f.File,
+				Lineno:   f.Line,
+				InApp:    strings.HasPrefix(f.Function, modulePath),
+			})
+		}
+		if !more {
+			break
+		}
+	}
+	slices.Reverse(out)
+	return stacktrace{Frames: out}
+}
+
+func skipFrame(fn string) bool {
+	return strings.HasPrefix(fn, "runtime.") ||
+		strings.HasPrefix(fn, "go.uber.org/zap") ||
+		strings.HasPrefix(fn, modulePath+"internal/errtrack.")
+}
+
+// module returns the package path of a qualified function name, such as
+// github.com/x/y/internal/pool for github.com/x/y/internal/pool.(*Pool).run.
+func module(fn string) string {
+	slash := strings.LastIndex(fn, "/")
+	if dot := strings.Index(fn[slash+1:], "."); dot >= 0 {
+		return fn[:slash+1+dot]
+	}
+	return ""
+}
+
+// shortFunction returns a qualified function name without its package.
+func shortFunction(fn string) string {
+	if m := module(fn); m != "" {
+		return fn[len(m)+1:]
+	}
+	return fn
+}
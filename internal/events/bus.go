@@ -0,0 +1,101 @@
+// Package events delivers domain events to in-process subscribers.
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// Handler reacts to one event.
+type Handler func(ctx context.Context, e domain.Event) error
+
+// Publisher is the side of the Bus that services depend on.
+type Publisher interface {
+	Publish(ctx context.Context, events ...domain.Event)
+}
+
+// Bus fans events out to subscribers. Delivery is synchronous, so by the
+// time Publish returns every subscriber has run; a cache eviction
+// subscriber has therefore finished before the write that caused it is
+// acknowledged.
+type Bus struct {
+	logger *zap.Logger
+
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+// NewBus creates a Bus with no subscribers.
+func NewBus(logger *zap.Logger) *Bus {
+	return &Bus{logger: logger, handlers: make(map[string][]Handler)}
+}
+
+// Subscribe registers h for the given event types.
+func (b *Bus) Subscribe(h Handler, eventTypes ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, t := range eventTypes {
+		b.handlers[t] = append(b.handlers[t], h)
+	}
+}
+
+// Publish delivers events in order. A failing or panicking subscriber is
+// logged and does not stop delivery to the others.
+func (b *Bus) Publish(ctx context.Context, events ...domain.Event) {
+	for _, e := range events {
+		if err := b.Deliver(ctx, e); err != nil {
+			b.logger.Error("Event handler failed",
+				zap.String("operation", "Publish"),
+				zap.String("event_type", e.EventType()),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// Deliver hands e to every subscriber, even after one fails, and returns
+// their failures joined.
+func (b *Bus) Deliver(ctx context.Context, e domain.Event) error {
+	b.mu.RLock()
+	handlers := b.handlers[e.EventType()]
+	b.mu.RUnlock()
+	var errs []error
+	for _, h := range handlers {
+		if err := deliver(ctx, h, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type messageIDKey struct{}
+
+// WithMessageID returns ctx carrying the ID of the outbox message being
+// delivered.
+func WithMessageID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, messageIDKey{}, id)
+}
+
+// MessageID returns the ID of the outbox message whose event ctx is
+// delivering, or "" for an event published directly. A message can be
+// delivered more than once, so subscribers with effects that must not
+// repeat use it to recognise a redelivery.
+func MessageID(ctx context.Context) string {
+	id, _ := ctx.Value(messageIDKey{}).(string)
+	return id
+}
+
+func deliver(ctx context.Context, h Handler, e domain.Event) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("handler panicked: %v", p)
+		}
+	}()
+	return h(ctx, e)
+}
@@ -0,0 +1,42 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestBus_Publish(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestBus_Publish", "internal/events")
+
+	testhelpers.LogTestStep(logger, "arrange", "Subscribers that record, fail and panic")
+	bus := NewBus(logger)
+	var got []string
+	record := func(_ context.Context, e domain.Event) error {
+		got = append(got, e.EventType())
+		return nil
+	}
+	bus.Subscribe(func(context.Context, domain.Event) error { return errors.New("boom") }, domain.EventProductUpdated)
+	bus.Subscribe(func(context.Context, domain.Event) error { panic("boom") }, domain.EventPriceDropped)
+	bus.Subscribe(record, domain.EventPriceDropped, domain.EventProductUpdated)
+
+	testhelpers.LogTestStep(logger, "act", "Publishing three events")
+	bus.Publish(t.Context(),
+		domain.ProductUpdated{ProductID: "prod_on_gsw"},
+		domain.PriceChanged{},
+		domain.PriceDropped{},
+	)
+
+	testhelpers.LogTestStep(logger, "assert", "Subscribed types are delivered in order despite failures")
+	want := []string{domain.EventProductUpdated, domain.EventPriceDropped}
+	testhelpers.LogTestAssertion(logger, "delivered", want, got)
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("delivered = %v, want %v", got, want)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestBus_Publish", true)
+}
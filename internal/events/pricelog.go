@@ -0,0 +1,180 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// LogConsumerConfig configures a LogConsumer.
+type LogConsumerConfig struct {
+	// Interval is how often the log is polled for new entries.
+	Interval time.Duration
+	// BatchSize is how many entries are read, and committed, at a time.
+	BatchSize int
+	// MaxAttempts is how many deliveries an entry gets before the
+	// consumer skips it.
+	MaxAttempts int
+	// Settle leaves entries younger than this for a later poll, so
+	// subscribers of the same event on the Bus, such as cache
+	// invalidators, have handled it first.
+	Settle time.Duration
+}
+
+// DefaultLogConsumerConfig polls every five seconds and gives an entry
+// ten attempts, delivering it as soon as it is read.
+func DefaultLogConsumerConfig() LogConsumerConfig {
+	return LogConsumerConfig{Interval: 5 * time.Second, BatchSize: 100, MaxAttempts: 10}
+}
+
+// LogConsumer delivers the price change log to one handler, in offset
+// order, from the offset its consumer name last committed. The offset is
+// committed after the handler has run, so an entry can be delivered again
+// after a crash; the handler sees the same MessageID each time, and
+// again when the consumer is rewound to replay the log.
+type LogConsumer struct {
+	cfg    LogConsumerConfig
+	name   string
+	log    repositories.PriceLogRepository
+	handle Handler
+	logger *zap.Logger
+	now    func() time.Time
+
+	// mu serialises polls. failing and attempts count the failed
+	// deliveries of the entry at offset failing.
+	mu       sync.Mutex
+	failing  int64
+	attempts int
+}
+
+// NewLogConsumer creates a LogConsumer that commits its offsets as name.
+// Call Run to deliver entries as they are appended.
+func NewLogConsumer(cfg LogConsumerConfig, name string, log repositories.PriceLogRepository, h Handler, logger *zap.Logger) *LogConsumer {
+	def := DefaultLogConsumerConfig()
+	if cfg.Interval <= 0 {
+		cfg.Interval = def.Interval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = def.BatchSize
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = def.MaxAttempts
+	}
+	return &LogConsumer{cfg: cfg, name: name, log: log, handle: h, logger: logger.With(zap.String("consumer", name)), now: time.Now}
+}
+
+// Name returns the name the consumer commits its offsets as.
+func (c *LogConsumer) Name() string { return c.name }
+
+// Run polls the log every Interval until ctx is done, starting at once
+// with whatever was appended while no process was consuming.
+func (c *LogConsumer) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := c.Poll(ctx); err != nil && ctx.Err() == nil {
+			c.logger.Error("Price log poll failed", zap.String("operation", "PollPriceLog"), zap.Error(err))
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Poll delivers the entries after the committed offset and returns how
+// many it delivered. It stops at the first failed delivery, committing
+// the entries before it, so entries stay in order; an entry that has used
+// up its attempts is logged and skipped instead. A rewind during the poll
+// wins: the poll's commit is dropped and the next poll starts from the
+// rewound offset.
+func (c *LogConsumer) Poll(ctx context.Context) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cursor, err := c.log.Cursor(ctx, c.name)
+	if err != nil {
+		return 0, err
+	}
+	offset := cursor.Offset
+	delivered := 0
+	for {
+		entries, err := c.log.Read(ctx, repositories.PriceLogFilter{After: offset, Limit: c.cfg.BatchSize})
+		if err != nil {
+			return delivered, err
+		}
+		handled := offset
+		var failure error
+		for _, e := range entries {
+			if c.cfg.Settle > 0 && c.now().Sub(e.RecordedAt) < c.cfg.Settle {
+				break
+			}
+			if err := deliver(WithMessageID(ctx, PriceLogMessageID(e.Offset)), c.handle, e.Event()); err != nil && !c.skip(e, err) {
+				failure = err
+				break
+			}
+			c.failing, c.attempts = 0, 0
+			handled = e.Offset
+			delivered++
+		}
+		if handled > offset {
+			if err := c.log.Commit(ctx, c.name, offset, handled, c.now().UTC()); errors.Is(err, domain.ErrConflict) {
+				c.logger.Info("Price log consumer was rewound during a poll",
+					zap.String("operation", "PollPriceLog"),
+					zap.Int64("offset", handled),
+				)
+				return delivered, nil
+			} else if err != nil {
+				return delivered, err
+			}
+			offset = handled
+		}
+		if failure != nil {
+			return delivered, fmt.Errorf("deliver price log entry %d: %w", handled+1, failure)
+		}
+		if len(entries) < c.cfg.BatchSize || handled < entries[len(entries)-1].Offset {
+			return delivered, nil
+		}
+	}
+}
+
+// skip counts a failed delivery of e and reports whether e has used up
+// its attempts and is to be skipped.
+func (c *LogConsumer) skip(e domain.PriceLogEntry, err error) bool {
+	if c.failing != e.Offset {
+		c.failing, c.attempts = e.Offset, 0
+	}
+	c.attempts++
+	if c.attempts < c.cfg.MaxAttempts {
+		c.logger.Warn("Price log delivery failed; will retry",
+			zap.String("operation", "PollPriceLog"),
+			zap.Int64("offset", e.Offset),
+			zap.String("product_id", e.ProductID),
+			zap.Int("attempt", c.attempts),
+			zap.Error(err),
+		)
+		return false
+	}
+	c.logger.Error("Giving up on price log entry",
+		zap.String("operation", "PollPriceLog"),
+		zap.Int64("offset", e.Offset),
+		zap.String("product_id", e.ProductID),
+		zap.Int("attempts", c.attempts),
+		zap.Error(err),
+	)
+	return true
+}
+
+// PriceLogMessageID is the MessageID a LogConsumer delivers the entry at
+// offset with.
+func PriceLogMessageID(offset int64) string {
+	return fmt.Sprintf("pricelog_%d", offset)
+}
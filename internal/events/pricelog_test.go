@@ -0,0 +1,120 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestLogConsumer_Poll(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestLogConsumer_Poll", "internal/events")
+
+	testhelpers.LogTestStep(logger, "arrange", "Three price changes, a product edit, and a handler that fails on the second change")
+	ctx := t.Context()
+	at := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	store := memory.NewStore()
+	var written []domain.Event
+	for _, listing := range []string{"lst_a", "lst_b", "lst_c"} {
+		written = append(written, domain.NewPriceEvent(domain.PriceChange{ProductID: "prod_on_gsw", ListingID: listing, OldPrice: 3299, NewPrice: 3199}))
+	}
+	written = append(written[:1], append([]domain.Event{domain.ProductUpdated{ProductID: "prod_on_gsw"}}, written[1:]...)...)
+	if err := store.PriceIngester().IngestPrices(ctx, nil, written...); err != nil {
+		t.Fatalf("IngestPrices: %v", err)
+	}
+	var got, ids []string
+	failing := "lst_b"
+	handler := func(ctx context.Context, e domain.Event) error {
+		listing := e.(domain.PriceDropped).ListingID
+		if listing == failing {
+			return errors.New("notification queue down")
+		}
+		got = append(got, listing)
+		ids = append(ids, MessageID(ctx))
+		return nil
+	}
+	consumer := NewLogConsumer(LogConsumerConfig{BatchSize: 2, MaxAttempts: 2}, "alerts", store.PriceLog(), handler, logger)
+
+	testhelpers.LogTestStep(logger, "act", "Polling while the second change fails")
+	n, err := consumer.Poll(ctx)
+
+	testhelpers.LogTestStep(logger, "assert", "Delivery stops at the failure and commits what came before")
+	testhelpers.LogTestAssertion(logger, "delivered", 1, n)
+	if n != 1 || err == nil || len(got) != 1 || got[0] != "lst_a" || ids[0] != PriceLogMessageID(1) {
+		t.Fatalf("Poll = %d, %v; delivered %v with IDs %v", n, err, got, ids)
+	}
+	if c, _ := store.PriceLog().Cursor(ctx, "alerts"); c.Offset != 1 {
+		t.Fatalf("Cursor = %+v, want offset 1", c)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Polling again, using up the failing change's attempts")
+	n, err = consumer.Poll(ctx)
+
+	testhelpers.LogTestStep(logger, "assert", "The failing change is skipped, the product edit never logged")
+	testhelpers.LogTestAssertion(logger, "delivered", 2, n)
+	if n != 2 || err != nil || len(got) != 2 || got[1] != "lst_c" {
+		t.Fatalf("Poll = %d, %v; delivered %v", n, err, got)
+	}
+	if c, _ := store.PriceLog().Cursor(ctx, "alerts"); c.Offset != 3 {
+		t.Fatalf("Cursor = %+v, want offset 3", c)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Rewinding the consumer to the start, once the handler is fixed")
+	failing = ""
+	if err := store.PriceLog().Commit(ctx, "alerts", 3, 0, at); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	n, err = consumer.Poll(ctx)
+
+	testhelpers.LogTestStep(logger, "assert", "Every change is replayed, in order, with the IDs it had")
+	testhelpers.LogTestAssertion(logger, "delivered", 3, n)
+	if n != 3 || err != nil || len(got) != 5 || got[2] != "lst_a" || got[3] != "lst_b" || ids[2] != ids[0] {
+		t.Fatalf("Poll = %d, %v; delivered %v with IDs %v", n, err, got, ids)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestLogConsumer_Poll", true)
+}
+
+func TestLogConsumer_RewindDuringPoll(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestLogConsumer_RewindDuringPoll", "internal/events")
+
+	testhelpers.LogTestStep(logger, "arrange", "Two price changes and a handler that rewinds its own consumer")
+	ctx := t.Context()
+	at := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	store := memory.NewStore()
+	log := store.PriceLog()
+	change := domain.NewPriceEvent(domain.PriceChange{ProductID: "prod_on_gsw", NewPrice: 3199})
+	if err := store.PriceIngester().IngestPrices(ctx, nil, change, change); err != nil {
+		t.Fatalf("IngestPrices: %v", err)
+	}
+	if err := log.Commit(ctx, "analytics", 0, 1, at); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	rewound := false
+	handler := func(ctx context.Context, _ domain.Event) error {
+		if !rewound {
+			rewound = true
+			return log.Commit(ctx, "analytics", 1, 0, at)
+		}
+		return nil
+	}
+	consumer := NewLogConsumer(LogConsumerConfig{}, "analytics", log, handler, logger)
+
+	testhelpers.LogTestStep(logger, "act", "Polling")
+	_, err := consumer.Poll(ctx)
+
+	testhelpers.LogTestStep(logger, "assert", "The rewind wins over the poll's commit")
+	c, _ := log.Cursor(ctx, "analytics")
+	testhelpers.LogTestAssertion(logger, "offset", int64(0), c.Offset)
+	if err != nil || c.Offset != 0 {
+		t.Errorf("Poll err = %v, cursor = %+v, want offset 0", err, c)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestLogConsumer_RewindDuringPoll", true)
+}
@@ -0,0 +1,210 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// RelayConfig configures the Relay.
+type RelayConfig struct {
+	// Interval is how often the outbox is polled for messages a flush
+	// after the write did not publish: those left by a crash or a failed
+	// delivery.
+	Interval time.Duration
+	// BatchSize is how many messages are read from the outbox at a time.
+	BatchSize int
+	// MaxAttempts is how many deliveries a message gets before the relay
+	// gives up on it.
+	MaxAttempts int
+	// Retention is how long published messages are kept.
+	Retention time.Duration
+	// MaxBacklogAge is how old the oldest pending message may be before
+	// Check reports the relay as stuck.
+	MaxBacklogAge time.Duration
+}
+
+// DefaultRelayConfig polls every ten seconds, gives a message ten
+// attempts, keeps published messages for a week and calls a backlog five
+// minutes old stuck.
+func DefaultRelayConfig() RelayConfig {
+	return RelayConfig{
+		Interval:      10 * time.Second,
+		BatchSize:     100,
+		MaxAttempts:   10,
+		Retention:     7 * 24 * time.Hour,
+		MaxBacklogAge: 5 * time.Minute,
+	}
+}
+
+// Relay publishes the events in the outbox to the Bus, in the order they
+// were written. A message is marked published only after every subscriber
+// has handled it, so no event is lost to a crash; one can be delivered
+// again after a crash or a failing subscriber, and subscribers whose
+// effects must not repeat deduplicate on MessageID.
+type Relay struct {
+	cfg    RelayConfig
+	outbox repositories.OutboxRepository
+	bus    *Bus
+	logger *zap.Logger
+	now    func() time.Time
+
+	// mu serialises flushes, which would otherwise deliver a message
+	// twice.
+	mu sync.Mutex
+}
+
+// NewRelay creates a Relay. Services call Flush after their writes; call
+// Run to publish whatever that misses.
+func NewRelay(cfg RelayConfig, outbox repositories.OutboxRepository, bus *Bus, logger *zap.Logger) *Relay {
+	def := DefaultRelayConfig()
+	if cfg.Interval <= 0 {
+		cfg.Interval = def.Interval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = def.BatchSize
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = def.MaxAttempts
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = def.Retention
+	}
+	if cfg.MaxBacklogAge <= 0 {
+		cfg.MaxBacklogAge = def.MaxBacklogAge
+	}
+	return &Relay{cfg: cfg, outbox: outbox, bus: bus, logger: logger, now: time.Now}
+}
+
+// Run flushes the outbox every Interval, and purges published messages
+// past their retention hourly, until ctx is done. It flushes once at
+// start for messages a previous process left behind.
+func (r *Relay) Run(ctx context.Context) {
+	r.Flush(ctx)
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+	var purged time.Time
+	for {
+		select {
+		case <-ticker.C:
+			r.Flush(ctx)
+			if now := r.now(); now.Sub(purged) >= time.Hour {
+				r.Purge(ctx)
+				purged = now
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Flush publishes pending messages and returns how many it published. It
+// stops at the first failed delivery, leaving that message and those
+// after it for a later flush so events stay in order, unless the message
+// has used up its attempts; then it is marked failed and skipped.
+func (r *Relay) Flush(ctx context.Context) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	published := 0
+	for {
+		pending, err := r.outbox.Pending(ctx, r.cfg.BatchSize)
+		if err != nil {
+			r.logger.Error("Reading outbox failed", zap.String("operation", "FlushOutbox"), zap.Error(err))
+			return published
+		}
+		for _, m := range pending {
+			e, err := m.Event()
+			if err != nil {
+				// Retrying cannot decode it.
+				if !r.fail(ctx, m.ID, m.EventType, err.Error()) {
+					return published
+				}
+				continue
+			}
+			if err := r.bus.Deliver(WithMessageID(ctx, m.ID), e); err != nil {
+				if m.Attempts+1 >= r.cfg.MaxAttempts {
+					if !r.fail(ctx, m.ID, m.EventType, err.Error()) {
+						return published
+					}
+					continue
+				}
+				r.logger.Warn("Outbox message delivery failed; will retry",
+					zap.String("operation", "FlushOutbox"),
+					zap.String("message_id", m.ID),
+					zap.String("event_type", m.EventType),
+					zap.Int("attempt", m.Attempts+1),
+					zap.Error(err),
+				)
+				if err := r.outbox.MarkRetry(ctx, m.ID, err.Error()); err != nil {
+					r.logger.Error("Recording outbox retry failed", zap.String("operation", "FlushOutbox"), zap.Error(err))
+				}
+				return published
+			}
+			if err := r.outbox.MarkPublished(ctx, r.now().UTC(), m.ID); err != nil {
+				// Stop rather than deliver the message again next batch.
+				r.logger.Error("Marking outbox message published failed",
+					zap.String("operation", "FlushOutbox"),
+					zap.String("message_id", m.ID),
+					zap.Error(err),
+				)
+				return published
+			}
+			published++
+		}
+		if len(pending) < r.cfg.BatchSize {
+			return published
+		}
+	}
+}
+
+// Check reports an error when the outbox cannot be read or its oldest
+// pending message has waited longer than MaxBacklogAge, meaning events
+// are not reaching subscribers. It suits a readiness check.
+func (r *Relay) Check(ctx context.Context) error {
+	pending, err := r.outbox.Pending(ctx, 1)
+	if err != nil {
+		return fmt.Errorf("read outbox: %w", err)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if age := r.now().Sub(pending[0].CreatedAt); age > r.cfg.MaxBacklogAge {
+		return fmt.Errorf("oldest pending event is %s old, more than %s", age.Round(time.Second), r.cfg.MaxBacklogAge)
+	}
+	return nil
+}
+
+// Purge removes published messages older than Retention and returns how
+// many it removed.
+func (r *Relay) Purge(ctx context.Context) int {
+	n, err := r.outbox.DeletePublishedBefore(ctx, r.now().Add(-r.cfg.Retention))
+	if err != nil {
+		r.logger.Error("Purging outbox failed", zap.String("operation", "PurgeOutbox"), zap.Error(err))
+		return 0
+	}
+	if n > 0 {
+		r.logger.Info("Outbox purged", zap.String("operation", "PurgeOutbox"), zap.Int("messages", n))
+	}
+	return n
+}
+
+// fail gives up on a message, reporting whether that was recorded.
+func (r *Relay) fail(ctx context.Context, id, eventType, reason string) bool {
+	r.logger.Error("Giving up on outbox message",
+		zap.String("operation", "FlushOutbox"),
+		zap.String("message_id", id),
+		zap.String("event_type", eventType),
+		zap.String("reason", reason),
+	)
+	if err := r.outbox.MarkFailed(ctx, r.now().UTC(), id, reason); err != nil {
+		r.logger.Error("Recording outbox failure failed", zap.String("operation", "FlushOutbox"), zap.Error(err))
+		return false
+	}
+	return true
+}
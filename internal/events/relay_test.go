@@ -0,0 +1,109 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestRelay_Flush(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestRelay_Flush", "internal/events")
+
+	testhelpers.LogTestStep(logger, "arrange", "Three writes with events and a subscriber that fails on the second")
+	ctx := t.Context()
+	store := memory.NewStore()
+	synonyms := store.Synonyms()
+	for _, name := range []string{"prod_a", "prod_b", "prod_c"} {
+		if _, err := synonyms.CreateSynonym(ctx, domain.Synonym{Terms: []string{name, name + "-x"}},
+			domain.ProductUpdated{ProductID: name}); err != nil {
+			t.Fatalf("CreateSynonym: %v", err)
+		}
+	}
+	bus := NewBus(logger)
+	var got, ids []string
+	failing := "prod_b"
+	bus.Subscribe(func(ctx context.Context, e domain.Event) error {
+		id := e.(domain.ProductUpdated).ProductID
+		if id == failing {
+			return errors.New("search index down")
+		}
+		got = append(got, id)
+		ids = append(ids, MessageID(ctx))
+		return nil
+	}, domain.EventProductUpdated)
+	relay := NewRelay(RelayConfig{BatchSize: 2, MaxAttempts: 2}, store.Outbox(), bus, logger)
+
+	testhelpers.LogTestStep(logger, "act", "Flushing while the second message fails")
+	n := relay.Flush(ctx)
+
+	testhelpers.LogTestStep(logger, "assert", "Delivery stops at the failure to keep order")
+	testhelpers.LogTestAssertion(logger, "published", 1, n)
+	if n != 1 || len(got) != 1 || got[0] != "prod_a" || ids[0] == "" {
+		t.Fatalf("Flush = %d, delivered %v with IDs %v", n, got, ids)
+	}
+	pending, _ := store.Outbox().Pending(ctx, 0)
+	if len(pending) != 2 || pending[0].Attempts != 1 || pending[0].LastError != "search index down" {
+		t.Fatalf("Pending = %+v", pending)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Flushing again, using up the failing message's attempts")
+	n = relay.Flush(ctx)
+
+	testhelpers.LogTestStep(logger, "assert", "The failing message is given up on and the rest delivered")
+	testhelpers.LogTestAssertion(logger, "published", 1, n)
+	if n != 1 || len(got) != 2 || got[1] != "prod_c" {
+		t.Fatalf("Flush = %d, delivered %v", n, got)
+	}
+	if pending, _ := store.Outbox().Pending(ctx, 0); len(pending) != 0 {
+		t.Errorf("Pending after give-up = %+v", pending)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Purging a week later")
+	relay.now = func() time.Time { return time.Now().Add(8 * 24 * time.Hour) }
+	purged := relay.Purge(ctx)
+	testhelpers.LogTestAssertion(logger, "purged", 2, purged)
+	if purged != 2 {
+		t.Errorf("Purge = %d, want the 2 published messages", purged)
+	}
+	if again := relay.Flush(ctx); again != 0 || len(got) != 2 {
+		t.Errorf("Flush after purge = %d, delivered %v; want nothing redelivered", again, got)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestRelay_Flush", true)
+}
+
+func TestRelay_Check(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestRelay_Check", "internal/events")
+
+	testhelpers.LogTestStep(logger, "arrange", "A write whose event no subscriber accepts")
+	ctx := t.Context()
+	store := memory.NewStore()
+	if _, err := store.Synonyms().CreateSynonym(ctx, domain.Synonym{Terms: []string{"whey", "wpc"}},
+		domain.ProductUpdated{ProductID: "prod_a"}); err != nil {
+		t.Fatalf("CreateSynonym: %v", err)
+	}
+	bus := NewBus(logger)
+	bus.Subscribe(func(context.Context, domain.Event) error { return errors.New("search index down") }, domain.EventProductUpdated)
+	relay := NewRelay(RelayConfig{MaxBacklogAge: time.Minute}, store.Outbox(), bus, logger)
+	relay.Flush(ctx)
+
+	testhelpers.LogTestStep(logger, "assert", "A fresh backlog is fine and a stale one is not")
+	if err := relay.Check(ctx); err != nil {
+		t.Errorf("Check with a fresh backlog: %v", err)
+	}
+	relay.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	err := relay.Check(ctx)
+	testhelpers.LogTestAssertion(logger, "stale backlog error", true, err != nil)
+	if err == nil {
+		t.Error("Check with a stale backlog succeeded")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestRelay_Check", true)
+}
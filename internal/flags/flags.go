@@ -0,0 +1,207 @@
+// Package flags decides whether feature flags are on, so a risky change,
+// such as a new search ranking, reaches a share of traffic first and can
+// be turned off without a deploy. Flags start from defaults, usually
+// FEATURE_FLAGS, overridden by those stored in the repository, which
+// admins change at runtime:
+//
+//	if s.flags.On(flags.SearchRerank, q.Text) {
+//		hits = rerank(hits, ...)
+//	}
+package flags
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Flags checked in this service.
+const (
+	// SearchRerank orders search results by the blend of relevance, value,
+	// stock and popularity rather than by text relevance alone.
+	SearchRerank = "search.rerank"
+)
+
+// Config configures Flags.
+type Config struct {
+	// Defaults are in force for flags the repository does not hold.
+	Defaults []domain.Flag
+	// RefreshInterval is how often Run reloads the repository, bounding
+	// how long a change made through another instance takes to apply.
+	RefreshInterval time.Duration
+}
+
+// DefaultConfig turns on every change that has finished rolling out and
+// reloads every 30 seconds.
+func DefaultConfig() Config {
+	return Config{
+		Defaults: []domain.Flag{
+			{Name: SearchRerank, Description: "Blended search ranking", Enabled: true, Percent: 100},
+		},
+		RefreshInterval: 30 * time.Second,
+	}
+}
+
+// Parse overrides the flags in base from a comma-separated list of
+// name=value pairs, such as "search.rerank=25%,chat.fuzzy-match=off".
+// Values are on, off or the percentage of subjects a flag is on for.
+func Parse(raw string, base []domain.Flag) ([]domain.Flag, error) {
+	out := slices.Clone(base)
+	for _, entry := range strings.Split(raw, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(entry, "=")
+		f := domain.Flag{Name: strings.TrimSpace(name), Enabled: true}
+		switch value = strings.TrimSpace(value); value {
+		case "on":
+			f.Percent = 100
+		case "off":
+			f.Enabled = false
+		default:
+			n, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+			if err != nil {
+				return base, fmt.Errorf("feature flags: %s must be on, off or a percentage, got %q", f.Name, value)
+			}
+			f.Percent = n
+		}
+		if err := f.Validate(); err != nil {
+			return base, fmt.Errorf("feature flags: %w", err)
+		}
+		if i := slices.IndexFunc(out, func(d domain.Flag) bool { return d.Name == f.Name }); i >= 0 {
+			f.Description = out[i].Description
+			out[i] = f
+		} else {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+// Flags holds the flags in force, replacing them when Reload reads a
+// change. A nil *Flags has every flag off.
+type Flags struct {
+	cfg     Config
+	repo    repositories.FlagRepository
+	logger  *zap.Logger
+	current atomic.Pointer[map[string]domain.Flag]
+}
+
+// New creates Flags with cfg.Defaults in force; call Reload to read repo,
+// which may be nil to use the defaults alone.
+func New(cfg Config, repo repositories.FlagRepository, logger *zap.Logger) *Flags {
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = DefaultConfig().RefreshInterval
+	}
+	f := &Flags{cfg: cfg, repo: repo, logger: logger}
+	defaults := make(map[string]domain.Flag, len(cfg.Defaults))
+	for _, d := range cfg.Defaults {
+		defaults[d.Name] = d
+	}
+	f.current.Store(&defaults)
+	return f
+}
+
+// On reports whether flag name is on for subject, such as a user ID. A
+// flag at a percentage is on for the subjects whose hash with the flag's
+// name falls under it, so a subject keeps its answer while the percentage
+// rises and flags roll out to independent shares. Unknown flags are off.
+func (f *Flags) On(name, subject string) bool {
+	if f == nil {
+		return false
+	}
+	flag, ok := (*f.current.Load())[name]
+	if !ok || !flag.Enabled {
+		return false
+	}
+	return bucket(name, subject) < flag.Percent
+}
+
+// bucket places subject in one of 100 buckets for flag name.
+func bucket(name, subject string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(subject))
+	return int(h.Sum32() % 100)
+}
+
+// All returns the flags in force, by name.
+func (f *Flags) All() []domain.Flag {
+	if f == nil {
+		return nil
+	}
+	return slices.SortedFunc(maps.Values(*f.current.Load()), func(a, b domain.Flag) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+}
+
+// Reload reads the repository's flags over the defaults and swaps them in.
+// On failure the flags in force are kept.
+func (f *Flags) Reload(ctx context.Context) error {
+	if f.repo == nil {
+		return nil
+	}
+	stored, err := f.repo.Flags(ctx)
+	if err != nil {
+		f.logger.Warn("Failed to reload feature flags",
+			zap.String("operation", "ReloadFlags"),
+			zap.Error(err),
+		)
+		return fmt.Errorf("list flags: %w", err)
+	}
+	next := make(map[string]domain.Flag, len(f.cfg.Defaults)+len(stored))
+	for _, d := range f.cfg.Defaults {
+		next[d.Name] = d
+	}
+	for _, s := range stored {
+		next[s.Name] = s
+	}
+	if prev := f.current.Swap(&next); !maps.Equal(*prev, next) {
+		f.logger.Info("Feature flags reloaded",
+			zap.String("operation", "ReloadFlags"),
+			zap.Int("flags", len(next)),
+		)
+	}
+	return nil
+}
+
+// Run reloads the flags every RefreshInterval until ctx is cancelled, so
+// changes made through other instances apply here too.
+func (f *Flags) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.cfg.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = f.Reload(ctx)
+		}
+	}
+}
+
+// EventTypes lists the events Handle understands, for subscribing.
+func (f *Flags) EventTypes() []string {
+	return []string{domain.EventFlagsChanged}
+}
+
+// Handle reloads the flags after a change, so a flag turned off through
+// this instance is off at once.
+func (f *Flags) Handle(ctx context.Context, e domain.Event) error {
+	if _, ok := e.(domain.FlagsChanged); !ok {
+		return nil
+	}
+	return f.Reload(ctx)
+}
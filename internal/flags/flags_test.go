@@ -0,0 +1,110 @@
+package flags
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestParse(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestParse", "internal/flags")
+
+	testCases := []struct {
+		name        string
+		raw         string
+		wantEnabled bool
+		wantPercent int
+		wantErr     bool
+	}{
+		{"Empty keeps the default", "", true, 100, false},
+		{"Off", "search.rerank=off", false, 0, false},
+		{"Percentage", " search.rerank = 25% ", true, 25, false},
+		{"Bare number", "search.rerank=5", true, 5, false},
+		{"Over 100", "search.rerank=101", false, 0, true},
+		{"Not a value", "search.rerank=maybe", false, 0, true},
+		{"Bad name", "Search Rerank=on", false, 0, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Parse(tc.raw, DefaultConfig().Defaults)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err != nil)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Parse(%q) err = %v", tc.raw, err)
+			}
+			if err == nil && (len(got) != 1 || got[0].Enabled != tc.wantEnabled || got[0].Percent != tc.wantPercent ||
+				got[0].Description == "") {
+				t.Errorf("Parse(%q) = %+v", tc.raw, got)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestParse", true)
+}
+
+func TestFlags_RollOutAndKill(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestFlags_RollOutAndKill", "internal/flags")
+
+	testhelpers.LogTestStep(logger, "arrange", "Flags over a store, defaulting the ranking to a tenth of users")
+	store := memory.NewStore()
+	ctx := t.Context()
+	f := New(Config{Defaults: []domain.Flag{{Name: SearchRerank, Enabled: true, Percent: 10}}}, store.Flags(), logger)
+	on := func() map[string]bool {
+		users := make(map[string]bool)
+		for i := range 1000 {
+			user := fmt.Sprintf("user_%d", i)
+			if f.On(SearchRerank, user) {
+				users[user] = true
+			}
+		}
+		return users
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Rolling out from the default to a half, then killing the flag")
+	tenth := on()
+	if err := store.Flags().SaveFlag(ctx, domain.Flag{Name: SearchRerank, Enabled: true, Percent: 50}); err != nil {
+		t.Fatalf("SaveFlag: %v", err)
+	}
+	if err := f.Handle(ctx, domain.FlagsChanged{}); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	half := on()
+	if err := store.Flags().SaveFlag(ctx, domain.Flag{Name: SearchRerank, Enabled: false, Percent: 50}); err != nil {
+		t.Fatalf("SaveFlag: %v", err)
+	}
+	if err := f.Reload(ctx); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	killed := on()
+
+	testhelpers.LogTestStep(logger, "assert", "Shares match the percentages, and users keep the flag as it widens")
+	testhelpers.LogTestAssertion(logger, "users at 50%", 500, len(half))
+	if len(tenth) < 70 || len(tenth) > 130 || len(half) < 450 || len(half) > 550 || len(killed) != 0 {
+		t.Errorf("Users on = %d at 10%%, %d at 50%%, %d killed", len(tenth), len(half), len(killed))
+	}
+	for user := range tenth {
+		if !half[user] {
+			t.Errorf("%s lost the flag when it widened", user)
+		}
+	}
+	if f.On("search.unknown", "user_1") || (*Flags)(nil).On(SearchRerank, "user_1") {
+		t.Error("Unknown flags and nil Flags should be off")
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Deleting the stored flag restores the default")
+	if err := store.Flags().DeleteFlag(ctx, SearchRerank); err != nil {
+		t.Fatalf("DeleteFlag: %v", err)
+	}
+	if err := f.Reload(ctx); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if all := f.All(); len(all) != 1 || all[0].Percent != 10 || !all[0].Enabled {
+		t.Errorf("All = %+v, want the default back", all)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestFlags_RollOutAndKill", true)
+}
@@ -0,0 +1,147 @@
+// Package fragments caches rendered HTML fragments, such as a product's
+// price table, so server-rendered pages can be assembled per request from
+// mostly cached parts. Pages themselves are never cached: they carry
+// per-visitor content, while a price table is the same for everyone who
+// reads it in the same locale.
+//
+// Fragments are keyed by a version of the data they render instead of being
+// invalidated. A price change produces a new version and therefore a new
+// key; the old fragment is never read again and ages out of the cache.
+package fragments
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"hash/fnv"
+	"html/template"
+	"io"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/cache"
+	"github.com/yourusername/whey-price-compare/internal/jsonx"
+)
+
+// DefaultTTL bounds how long an unread fragment stays cached. Versioned keys
+// make a longer TTL safe; it only costs memory.
+const DefaultTTL = time.Hour
+
+// Key identifies one rendered fragment.
+type Key struct {
+	// Name is the fragment, such as "price-table".
+	Name string
+	// ProductID is the product the fragment renders.
+	ProductID string
+	// Version changes whenever the data behind the fragment does.
+	Version string
+	// Variant distinguishes renderings of the same data, such as locales.
+	Variant string
+}
+
+// String returns the cache key.
+func (k Key) String() string {
+	return "v1:fragment:" + k.Name + ":" + k.ProductID + ":" + k.Variant + ":" + k.Version
+}
+
+// Version derives a version from a value's JSON encoding, so any change to
+// the value, whether a price, a stock flag or a product name, gives a new
+// version. Hashing the hand-written encoding costs a few microseconds,
+// much less than the rendering it saves.
+func Version(v jsonx.Appender) string {
+	h := fnv.New64a()
+	_, _ = h.Write(v.AppendJSON(nil))
+	return strconv.FormatUint(h.Sum64(), 36)
+}
+
+// Cache renders fragments through a cache.Cache.
+type Cache struct {
+	cache  cache.Cache
+	ttl    time.Duration
+	logger *zap.Logger
+
+	hits     atomic.Int64
+	misses   atomic.Int64
+	failures atomic.Int64
+}
+
+// New creates a Cache storing fragments in c for ttl, or DefaultTTL when ttl
+// is not positive.
+func New(c cache.Cache, ttl time.Duration, logger *zap.Logger) *Cache {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Cache{cache: c, ttl: ttl, logger: logger}
+}
+
+// Render returns the fragment for key, calling render to produce and cache
+// it on a miss. Cache errors are logged and fall back to rendering, so a
+// cache outage slows pages down without breaking them. A nil Cache always
+// renders.
+func (c *Cache) Render(ctx context.Context, key Key, render func(w io.Writer) error) (template.HTML, error) {
+	if c == nil {
+		return renderHTML(render)
+	}
+	k := key.String()
+	data, err := c.cache.Get(ctx, k)
+	if err == nil {
+		c.hits.Add(1)
+		return template.HTML(data), nil
+	}
+	if !errors.Is(err, cache.ErrMiss) {
+		c.failures.Add(1)
+		c.logger.Warn("Failed to read cached fragment",
+			zap.String("operation", "RenderFragment"),
+			zap.String("fragment", key.Name),
+			zap.Error(err),
+		)
+	}
+	c.misses.Add(1)
+
+	html, err := renderHTML(render)
+	if err != nil {
+		return "", err
+	}
+	if err := c.cache.Set(ctx, k, []byte(html), c.ttl); err != nil {
+		c.failures.Add(1)
+		c.logger.Warn("Failed to cache fragment",
+			zap.String("operation", "RenderFragment"),
+			zap.String("fragment", key.Name),
+			zap.Error(err),
+		)
+	}
+	return html, nil
+}
+
+// renderHTML runs render into a buffer. The renderers are html/template
+// executions, so their output is already escaped.
+func renderHTML(render func(w io.Writer) error) (template.HTML, error) {
+	var buf bytes.Buffer
+	if err := render(&buf); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// Stats reports fragment cache effectiveness.
+type Stats struct {
+	Hits     int64 `json:"hits"`
+	Misses   int64 `json:"misses"`
+	Failures int64 `json:"failures"`
+}
+
+// HitRatio returns the share of renders served from the cache.
+func (s Stats) HitRatio() float64 {
+	if total := s.Hits + s.Misses; total > 0 {
+		return float64(s.Hits) / float64(total)
+	}
+	return 0
+}
+
+// Stats returns the counters since the Cache was created.
+func (c *Cache) Stats() Stats {
+	return Stats{Hits: c.hits.Load(), Misses: c.misses.Load(), Failures: c.failures.Load()}
+}
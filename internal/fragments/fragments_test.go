@@ -0,0 +1,124 @@
+package fragments
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/cache"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// brokenCache fails every operation, like an unreachable Redis.
+type brokenCache struct{}
+
+func (brokenCache) Get(context.Context, string) ([]byte, error) {
+	return nil, errors.New("connection refused")
+}
+func (brokenCache) Set(context.Context, string, []byte, time.Duration) error {
+	return errors.New("connection refused")
+}
+func (brokenCache) Delete(context.Context, ...string) error { return errors.New("connection refused") }
+
+func TestCache_RendersOncePerVersion(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCache_RendersOncePerVersion", "internal/fragments")
+
+	c := New(cache.NewLRU(cache.DefaultLRUConfig()), 0, logger)
+	renders := 0
+	render := func(html string) func(io.Writer) error {
+		return func(w io.Writer) error {
+			renders++
+			_, err := io.WriteString(w, html)
+			return err
+		}
+	}
+	key := Key{Name: "price-table", ProductID: "prod_on_gsw", Version: "1", Variant: "en-IN"}
+
+	testhelpers.LogTestStep(logger, "act", "Rendering the same version twice, then a new version")
+	first, err := c.Render(t.Context(), key, render("<table>v1</table>"))
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	second, _ := c.Render(t.Context(), key, render("<table>ignored</table>"))
+	key.Version = "2"
+	third, _ := c.Render(t.Context(), key, render("<table>v2</table>"))
+
+	testhelpers.LogTestStep(logger, "assert", "Cached until the version changes")
+	testhelpers.LogTestAssertion(logger, "renders", 2, renders)
+	if renders != 2 {
+		t.Errorf("renders = %d, want 2", renders)
+	}
+	if first != "<table>v1</table>" || second != first || third != "<table>v2</table>" {
+		t.Errorf("fragments = %q, %q, %q", first, second, third)
+	}
+	if s := c.Stats(); s.Hits != 1 || s.Misses != 2 || s.HitRatio() < 0.33 || s.HitRatio() > 0.34 {
+		t.Errorf("Stats = %+v, ratio %.2f", s, s.HitRatio())
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCache_RendersOncePerVersion", true)
+}
+
+func TestCache_FallsBackToRendering(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCache_FallsBackToRendering", "internal/fragments")
+
+	key := Key{Name: "price-table", ProductID: "prod_on_gsw", Version: "1"}
+	render := func(w io.Writer) error {
+		_, err := io.WriteString(w, "<p>fresh</p>")
+		return err
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Rendering through a failing cache and a nil cache")
+	c := New(brokenCache{}, time.Minute, logger)
+	got, err := c.Render(t.Context(), key, render)
+	var none *Cache
+	direct, directErr := none.Render(t.Context(), key, render)
+
+	testhelpers.LogTestStep(logger, "assert", "Both render without the cache")
+	if err != nil || got != "<p>fresh</p>" {
+		t.Errorf("Render through broken cache = %q, %v", got, err)
+	}
+	if directErr != nil || direct != "<p>fresh</p>" {
+		t.Errorf("Render through nil cache = %q, %v", direct, directErr)
+	}
+	if s := c.Stats(); s.Failures != 2 || s.Misses != 1 {
+		t.Errorf("Stats = %+v, want 2 failures and 1 miss", s)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Render errors are returned and not cached")
+	lru := New(cache.NewLRU(cache.DefaultLRUConfig()), time.Minute, logger)
+	if _, err := lru.Render(t.Context(), key, func(io.Writer) error { return errors.New("template failed") }); err == nil {
+		t.Error("Render error was swallowed")
+	}
+	if got, _ := lru.Render(t.Context(), key, render); got != "<p>fresh</p>" {
+		t.Errorf("Render after failure = %q", got)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCache_FallsBackToRendering", true)
+}
+
+func TestVersion(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestVersion", "internal/fragments")
+
+	now := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	offers := []domain.Offer{{RetailerID: "amazon", Price: 3299, InStock: true, LastUpdated: now}}
+	base := domain.NewComparison(domain.Product{ID: "prod_on_gsw"}, offers)
+	same := domain.NewComparison(domain.Product{ID: "prod_on_gsw"}, offers)
+	offers[0].InStock = false
+	restocked := domain.NewComparison(domain.Product{ID: "prod_on_gsw"}, offers)
+
+	testhelpers.LogTestAssertion(logger, "equal data", Version(base), Version(same))
+	if Version(base) != Version(same) {
+		t.Error("Equal comparisons have different versions")
+	}
+	if Version(base) == Version(restocked) {
+		t.Error("A stock change kept the version")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestVersion", true)
+}
@@ -0,0 +1,475 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/services"
+)
+
+// AdminPrefix is the path prefix of every admin route. Requests under it
+// must be authenticated before they reach AdminHandler.
+const AdminPrefix = "/api/v1/admin/"
+
+const maxAdminBodyBytes = 64 << 10
+
+// AuditReasonHeader carries an administrator's reason for a change, which
+// the audit log records with it.
+const AuditReasonHeader = "X-Audit-Reason"
+
+// maxAuditReason caps the reason recorded from AuditReasonHeader.
+const maxAuditReason = 500
+
+// AdminHandler serves catalog management for administrators.
+type AdminHandler struct {
+	admin      *services.AdminService
+	trustProxy bool
+	logger     *zap.Logger
+}
+
+// NewAdminHandler creates an AdminHandler. trustProxy controls whether the
+// audited client address is taken from X-Forwarded-For.
+func NewAdminHandler(admin *services.AdminService, trustProxy bool, logger *zap.Logger) *AdminHandler {
+	return &AdminHandler{admin: admin, trustProxy: trustProxy, logger: logger}
+}
+
+// Register mounts the admin routes on mux.
+func (h *AdminHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v1/admin/products", h.CreateProduct)
+	mux.HandleFunc("GET /api/v1/admin/products/{id}", h.Product)
+	mux.HandleFunc("PUT /api/v1/admin/products/{id}", h.UpdateProduct)
+	mux.HandleFunc("DELETE /api/v1/admin/products/{id}", h.DeleteProduct)
+	mux.HandleFunc("POST /api/v1/admin/products/{id}/restore", h.RestoreProduct)
+	mux.HandleFunc("POST /api/v1/admin/products/{id}/variants", h.CreateVariant)
+	mux.HandleFunc("PUT /api/v1/admin/variants/{id}", h.UpdateVariant)
+	mux.HandleFunc("DELETE /api/v1/admin/variants/{id}", h.DeleteVariant)
+	mux.HandleFunc("POST /api/v1/admin/variants/{id}/restore", h.RestoreVariant)
+	mux.HandleFunc("GET /api/v1/admin/users/{id}/deleted-alerts", h.DeletedAlerts)
+	mux.HandleFunc("POST /api/v1/admin/alerts/{id}/restore", h.RestoreAlert)
+	mux.HandleFunc("POST /api/v1/admin/retailers", h.CreateRetailer)
+	mux.HandleFunc("GET /api/v1/admin/retailers/{id}", h.Retailer)
+	mux.HandleFunc("PUT /api/v1/admin/retailers/{id}", h.UpdateRetailer)
+	mux.HandleFunc("DELETE /api/v1/admin/retailers/{id}", h.DeleteRetailer)
+	mux.HandleFunc("GET /api/v1/admin/retailers/{id}/selectors", h.Selectors)
+	mux.HandleFunc("PUT /api/v1/admin/retailers/{id}/selectors", h.SaveSelectors)
+	mux.HandleFunc("POST /api/v1/admin/listings/{id}/price-corrections", h.CorrectPrice)
+	mux.HandleFunc("GET /api/v1/admin/audit-log", h.AuditLog)
+	mux.HandleFunc("GET /api/v1/admin/synonyms", h.Synonyms)
+	mux.HandleFunc("POST /api/v1/admin/synonyms", h.CreateSynonym)
+	mux.HandleFunc("PUT /api/v1/admin/synonyms/{id}", h.UpdateSynonym)
+	mux.HandleFunc("DELETE /api/v1/admin/synonyms/{id}", h.DeleteSynonym)
+	mux.HandleFunc("GET /api/v1/admin/flags", h.Flags)
+	mux.HandleFunc("PUT /api/v1/admin/flags/{name}", h.SaveFlag)
+	mux.HandleFunc("DELETE /api/v1/admin/flags/{name}", h.DeleteFlag)
+	if h.admin.ImagesEnabled() {
+		mux.HandleFunc("POST /api/v1/admin/products/{id}/image", h.IngestProductImage)
+	}
+	if h.admin.PriceLogEnabled() {
+		mux.HandleFunc("GET /api/v1/admin/price-log", h.PriceLog)
+		mux.HandleFunc("GET /api/v1/admin/price-log/consumers", h.PriceLogConsumers)
+		mux.HandleFunc("POST /api/v1/admin/price-log/consumers/{name}/seek", h.SeekPriceLogConsumer)
+	}
+}
+
+// Product serves a product, including inactive and deleted ones, tagged
+// with its version.
+func (h *AdminHandler) Product(w http.ResponseWriter, r *http.Request) {
+	p, err := h.admin.Product(r.Context(), r.PathValue("id"))
+	if err == nil {
+		setVersionTag(w, p.Version)
+	}
+	h.respond(w, r, http.StatusOK, p, err)
+}
+
+// CreateProduct adds a product.
+func (h *AdminHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
+	var in services.AdminProduct
+	if !decodeJSON(w, r, maxAdminBodyBytes, &in) {
+		return
+	}
+	p, err := h.admin.CreateProduct(r.Context(), h.actor(r), in)
+	if err == nil {
+		w.Header().Set("Location", AdminPrefix+"products/"+url.PathEscape(p.ID))
+	}
+	h.respond(w, r, http.StatusCreated, p, err)
+}
+
+// UpdateProduct replaces a product. The edit names the version it was
+// made to in its body or an If-Match header.
+func (h *AdminHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
+	var in services.AdminProduct
+	if !decodeJSON(w, r, maxAdminBodyBytes, &in) || !editedVersion(w, r, &in.Version) {
+		return
+	}
+	p, err := h.admin.UpdateProduct(r.Context(), h.actor(r), r.PathValue("id"), in)
+	if err == nil {
+		setVersionTag(w, p.Version)
+	}
+	h.respond(w, r, http.StatusOK, p, err)
+}
+
+// DeleteProduct soft-deletes a product.
+func (h *AdminHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
+	err := h.admin.DeleteProduct(r.Context(), h.actor(r), r.PathValue("id"))
+	h.respond(w, r, http.StatusNoContent, nil, err)
+}
+
+// productImageRequest names the image to store for a product.
+type productImageRequest struct {
+	SourceURL string `json:"source_url"`
+}
+
+// IngestProductImage stores an image from a retailer's site as a
+// product's image.
+func (h *AdminHandler) IngestProductImage(w http.ResponseWriter, r *http.Request) {
+	var in productImageRequest
+	if !decodeJSON(w, r, maxAdminBodyBytes, &in) {
+		return
+	}
+	p, err := h.admin.IngestProductImage(r.Context(), h.actor(r), r.PathValue("id"), in.SourceURL)
+	h.respond(w, r, http.StatusOK, p, err)
+}
+
+// RestoreProduct brings back a deleted product.
+func (h *AdminHandler) RestoreProduct(w http.ResponseWriter, r *http.Request) {
+	p, err := h.admin.RestoreProduct(r.Context(), h.actor(r), r.PathValue("id"))
+	h.respond(w, r, http.StatusOK, p, err)
+}
+
+// CreateVariant adds a variant to a product.
+func (h *AdminHandler) CreateVariant(w http.ResponseWriter, r *http.Request) {
+	var in services.AdminVariant
+	if !decodeJSON(w, r, maxAdminBodyBytes, &in) {
+		return
+	}
+	v, err := h.admin.CreateVariant(r.Context(), h.actor(r), r.PathValue("id"), in)
+	h.respond(w, r, http.StatusCreated, v, err)
+}
+
+// UpdateVariant replaces a variant.
+func (h *AdminHandler) UpdateVariant(w http.ResponseWriter, r *http.Request) {
+	var in services.AdminVariant
+	if !decodeJSON(w, r, maxAdminBodyBytes, &in) || !editedVersion(w, r, &in.Version) {
+		return
+	}
+	v, err := h.admin.UpdateVariant(r.Context(), h.actor(r), r.PathValue("id"), in)
+	if err == nil {
+		setVersionTag(w, v.Version)
+	}
+	h.respond(w, r, http.StatusOK, v, err)
+}
+
+// DeleteVariant soft-deletes a variant.
+func (h *AdminHandler) DeleteVariant(w http.ResponseWriter, r *http.Request) {
+	err := h.admin.DeleteVariant(r.Context(), h.actor(r), r.PathValue("id"))
+	h.respond(w, r, http.StatusNoContent, nil, err)
+}
+
+// RestoreVariant brings back a deleted variant.
+func (h *AdminHandler) RestoreVariant(w http.ResponseWriter, r *http.Request) {
+	v, err := h.admin.RestoreVariant(r.Context(), h.actor(r), r.PathValue("id"))
+	h.respond(w, r, http.StatusOK, v, err)
+}
+
+// DeletedAlerts serves the alerts a user deleted.
+func (h *AdminHandler) DeletedAlerts(w http.ResponseWriter, r *http.Request) {
+	alerts, err := h.admin.DeletedAlerts(r.Context(), r.PathValue("id"))
+	h.respond(w, r, http.StatusOK, map[string]any{"alerts": alerts}, err)
+}
+
+// RestoreAlert brings back a user's deleted alert.
+func (h *AdminHandler) RestoreAlert(w http.ResponseWriter, r *http.Request) {
+	a, err := h.admin.RestoreAlert(r.Context(), h.actor(r), r.PathValue("id"))
+	h.respond(w, r, http.StatusOK, a, err)
+}
+
+// Retailer serves a retailer, including inactive ones.
+func (h *AdminHandler) Retailer(w http.ResponseWriter, r *http.Request) {
+	ret, err := h.admin.Retailer(r.Context(), r.PathValue("id"))
+	h.respond(w, r, http.StatusOK, ret, err)
+}
+
+// CreateRetailer adds a retailer.
+func (h *AdminHandler) CreateRetailer(w http.ResponseWriter, r *http.Request) {
+	var in services.AdminRetailer
+	if !decodeJSON(w, r, maxAdminBodyBytes, &in) {
+		return
+	}
+	ret, err := h.admin.CreateRetailer(r.Context(), h.actor(r), in)
+	if err == nil {
+		w.Header().Set("Location", AdminPrefix+"retailers/"+url.PathEscape(ret.ID))
+	}
+	h.respond(w, r, http.StatusCreated, ret, err)
+}
+
+// UpdateRetailer replaces a retailer.
+func (h *AdminHandler) UpdateRetailer(w http.ResponseWriter, r *http.Request) {
+	var in services.AdminRetailer
+	if !decodeJSON(w, r, maxAdminBodyBytes, &in) {
+		return
+	}
+	ret, err := h.admin.UpdateRetailer(r.Context(), h.actor(r), r.PathValue("id"), in)
+	h.respond(w, r, http.StatusOK, ret, err)
+}
+
+// DeleteRetailer deactivates a retailer.
+func (h *AdminHandler) DeleteRetailer(w http.ResponseWriter, r *http.Request) {
+	err := h.admin.DeleteRetailer(r.Context(), h.actor(r), r.PathValue("id"))
+	h.respond(w, r, http.StatusNoContent, nil, err)
+}
+
+// Selectors serves a retailer's scraper selectors.
+func (h *AdminHandler) Selectors(w http.ResponseWriter, r *http.Request) {
+	cfg, err := h.admin.Selectors(r.Context(), r.PathValue("id"))
+	h.respond(w, r, http.StatusOK, cfg, err)
+}
+
+// SaveSelectors replaces a retailer's scraper selectors.
+func (h *AdminHandler) SaveSelectors(w http.ResponseWriter, r *http.Request) {
+	var in domain.SelectorConfig
+	if !decodeJSON(w, r, maxAdminBodyBytes, &in) {
+		return
+	}
+	cfg, err := h.admin.SaveSelectors(r.Context(), h.actor(r), r.PathValue("id"), in)
+	h.respond(w, r, http.StatusOK, cfg, err)
+}
+
+// CorrectPrice records a manual price for a listing.
+func (h *AdminHandler) CorrectPrice(w http.ResponseWriter, r *http.Request) {
+	var in services.PriceCorrection
+	if !decodeJSON(w, r, maxAdminBodyBytes, &in) {
+		return
+	}
+	point, err := h.admin.CorrectPrice(r.Context(), h.actor(r), r.PathValue("id"), in)
+	h.respond(w, r, http.StatusCreated, point, err)
+}
+
+// Synonyms serves every search synonym group.
+func (h *AdminHandler) Synonyms(w http.ResponseWriter, r *http.Request) {
+	synonyms, err := h.admin.Synonyms(r.Context())
+	h.respond(w, r, http.StatusOK, map[string]any{"synonyms": synonyms}, err)
+}
+
+// CreateSynonym adds a search synonym group.
+func (h *AdminHandler) CreateSynonym(w http.ResponseWriter, r *http.Request) {
+	var in domain.Synonym
+	if !decodeJSON(w, r, maxAdminBodyBytes, &in) {
+		return
+	}
+	syn, err := h.admin.CreateSynonym(r.Context(), h.actor(r), in)
+	if err == nil {
+		w.Header().Set("Location", AdminPrefix+"synonyms/"+url.PathEscape(syn.ID))
+	}
+	h.respond(w, r, http.StatusCreated, syn, err)
+}
+
+// UpdateSynonym replaces a search synonym group's terms.
+func (h *AdminHandler) UpdateSynonym(w http.ResponseWriter, r *http.Request) {
+	var in domain.Synonym
+	if !decodeJSON(w, r, maxAdminBodyBytes, &in) {
+		return
+	}
+	syn, err := h.admin.UpdateSynonym(r.Context(), h.actor(r), r.PathValue("id"), in)
+	h.respond(w, r, http.StatusOK, syn, err)
+}
+
+// DeleteSynonym removes a search synonym group.
+func (h *AdminHandler) DeleteSynonym(w http.ResponseWriter, r *http.Request) {
+	err := h.admin.DeleteSynonym(r.Context(), h.actor(r), r.PathValue("id"))
+	h.respond(w, r, http.StatusNoContent, nil, err)
+}
+
+// Flags serves every stored feature flag.
+func (h *AdminHandler) Flags(w http.ResponseWriter, r *http.Request) {
+	flags, err := h.admin.Flags(r.Context())
+	h.respond(w, r, http.StatusOK, map[string]any{"flags": flags}, err)
+}
+
+// SaveFlag creates or replaces a feature flag; setting enabled to false
+// turns it off everywhere.
+func (h *AdminHandler) SaveFlag(w http.ResponseWriter, r *http.Request) {
+	var in domain.Flag
+	if !decodeJSON(w, r, maxAdminBodyBytes, &in) {
+		return
+	}
+	flag, err := h.admin.SaveFlag(r.Context(), h.actor(r), r.PathValue("name"), in)
+	h.respond(w, r, http.StatusOK, flag, err)
+}
+
+// DeleteFlag removes a stored feature flag, returning it to its default.
+func (h *AdminHandler) DeleteFlag(w http.ResponseWriter, r *http.Request) {
+	err := h.admin.DeleteFlag(r.Context(), h.actor(r), r.PathValue("name"))
+	h.respond(w, r, http.StatusNoContent, nil, err)
+}
+
+// AuditLog serves audit entries (?actor_id=, ?action=, ?resource_type=,
+// ?resource_id=, ?since=, ?until=, ?limit=, ?offset=). since and until are
+// RFC 3339 times.
+func (h *AdminHandler) AuditLog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := repositories.AuditFilter{
+		ActorID:      query.Get("actor_id"),
+		Action:       query.Get("action"),
+		ResourceType: query.Get("resource_type"),
+		ResourceID:   query.Get("resource_id"),
+	}
+	for name, dst := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		raw := query.Get(name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, name+" must be an RFC 3339 time",
+				map[string]any{"received": raw})
+			return
+		}
+		*dst = t
+	}
+	for name, dst := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		raw := query.Get(name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, name+" must be a non-negative integer",
+				map[string]any{"received": raw})
+			return
+		}
+		*dst = n
+	}
+
+	entries, err := h.admin.AuditLog(r.Context(), filter)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{"entries": entries, "count": len(entries)})
+}
+
+// PriceLog serves the price change log after an offset, optionally for
+// one product.
+func (h *AdminHandler) PriceLog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := repositories.PriceLogFilter{ProductID: query.Get("product_id")}
+	if raw := query.Get("after"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 0 {
+			httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, "after must be a non-negative integer",
+				map[string]any{"received": raw})
+			return
+		}
+		filter.After = n
+	}
+	if raw := query.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, "limit must be a non-negative integer",
+				map[string]any{"received": raw})
+			return
+		}
+		filter.Limit = n
+	}
+
+	entries, err := h.admin.PriceLog(r.Context(), filter)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{"entries": entries, "count": len(entries)})
+}
+
+// PriceLogConsumers serves the price change log's consumers and their lag.
+func (h *AdminHandler) PriceLogConsumers(w http.ResponseWriter, r *http.Request) {
+	status, err := h.admin.PriceLogStatus(r.Context())
+	h.respond(w, r, http.StatusOK, status, err)
+}
+
+// SeekPriceLogConsumer moves a price change log consumer, to replay
+// entries or skip them.
+func (h *AdminHandler) SeekPriceLogConsumer(w http.ResponseWriter, r *http.Request) {
+	var in services.PriceLogSeek
+	if !decodeJSON(w, r, maxAdminBodyBytes, &in) {
+		return
+	}
+	c, err := h.admin.SeekPriceLogConsumer(r.Context(), h.actor(r), r.PathValue("name"), in)
+	h.respond(w, r, http.StatusOK, c, err)
+}
+
+// actor describes the authenticated caller for the audit log.
+func (h *AdminHandler) actor(r *http.Request) domain.Actor {
+	return requestActor(r, h.trustProxy)
+}
+
+// requestActor describes r's authenticated principal for the audit log,
+// with the reason it gave in AuditReasonHeader.
+func requestActor(r *http.Request, trustProxy bool) domain.Actor {
+	reason := strings.TrimSpace(r.Header.Get(AuditReasonHeader))
+	if len(reason) > maxAuditReason {
+		reason = strings.ToValidUTF8(reason[:maxAuditReason], "")
+	}
+	return domain.Actor{
+		ID:        httpx.Principal(r.Context()),
+		IPAddress: httpx.ClientIP(r, trustProxy),
+		UserAgent: r.UserAgent(),
+		RequestID: httpx.RequestID(r),
+		Method:    r.Method,
+		Endpoint:  r.URL.Path,
+		Reason:    reason,
+	}
+}
+
+// decode reads a JSON body strictly so misspelt fields are rejected rather
+// than silently zeroing catalog data.
+func (h *AdminHandler) respond(w http.ResponseWriter, r *http.Request, status int, v any, err error) {
+	if errors.Is(err, services.ErrVersionRequired) {
+		httpx.WriteError(w, r, http.StatusPreconditionRequired, httpx.CodePreconditionRequired, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	if status == http.StatusNoContent {
+		w.WriteHeader(status)
+		return
+	}
+	httpx.WriteJSON(w, status, v)
+}
+
+// setVersionTag sends version as the ETag that If-Match names it by.
+func setVersionTag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// editedVersion takes the version an edit was made to from the If-Match
+// header into version, which the body may have set already. It answers
+// 400 Bad Request, and returns false, if the header is not a single
+// version tag or names another version than the body.
+func editedVersion(w http.ResponseWriter, r *http.Request, version *int) bool {
+	tag := r.Header.Get("If-Match")
+	if tag == "" {
+		return true
+	}
+	n, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(tag, "W/"), `"`))
+	switch {
+	case err != nil || n <= 0:
+		httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, "If-Match must be the version edited, e.g. \"3\"", nil)
+		return false
+	case *version != 0 && *version != n:
+		httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, "If-Match and the body name different versions", nil)
+		return false
+	}
+	*version = n
+	return true
+}
@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/middleware"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+const testAdminToken = "test-admin-token-0123456789"
+
+func newAdminTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+	logger := testhelpers.SetupTestLogger(t)
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	auth := middleware.NewBearerAuth(middleware.BearerAuthConfig{
+		Realm:  "admin",
+		Tokens: map[string]string{"ops": testAdminToken},
+	}, logger)
+	return NewRouter(Deps{
+		Logger: logger,
+		Batch:  DefaultBatchConfig(),
+		Catalog: services.NewCatalogService(services.CatalogRepos{
+			Products:  store.Products(),
+			Retailers: store.Retailers(),
+			Listings:  store.Listings(),
+		}, logger),
+		Admin: services.NewAdminService(services.AdminRepos{
+			Catalog:   store.CatalogAdmin(),
+			Selectors: store.Selectors(),
+			Prices:    store.PriceWriter(),
+			Audit:     store.Audit(),
+			Synonyms:  store.Synonyms(),
+			Flags:     store.Flags(),
+			Alerts:    store.Alerts(),
+		}, logger),
+		AdminAuth: auth.Handler,
+	})
+}
+
+func adminRequest(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdminHandler_Routes(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAdminHandler_Routes", "internal/handlers")
+
+	h := newAdminTestRouter(t)
+
+	testCases := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+	}{
+		{"Create product", http.MethodPost, "/api/v1/admin/products",
+			`{"id":"prod_dym_iso100","name":"ISO100","brand_id":"dymatize","category_id":"whey-isolate","protein_per_serving":25,"serving_size_grams":32}`,
+			http.StatusCreated},
+		{"Duplicate product", http.MethodPost, "/api/v1/admin/products",
+			`{"id":"prod_dym_iso100","name":"ISO100","brand_id":"dymatize","category_id":"whey-isolate"}`,
+			http.StatusConflict},
+		{"Unknown field", http.MethodPost, "/api/v1/admin/products", `{"nmae":"typo"}`, http.StatusBadRequest},
+		{"Empty body", http.MethodPost, "/api/v1/admin/products", ``, http.StatusBadRequest},
+		{"Add variant", http.MethodPost, "/api/v1/admin/products/prod_dym_iso100/variants",
+			`{"id":"var_iso100_2270","flavor":"Gourmet Vanilla","weight_grams":2270}`, http.StatusCreated},
+		{"Update retailer", http.MethodPut, "/api/v1/admin/retailers/flipkart",
+			`{"name":"Flipkart","website_url":"https://www.flipkart.com","requests_per_minute":6,"active":true}`, http.StatusOK},
+		{"Save selectors", http.MethodPut, "/api/v1/admin/retailers/amazon/selectors",
+			`{"price_selectors":[".a-price-whole"],"search_url_template":"https://www.amazon.in/s?k={query}"}`, http.StatusOK},
+		{"Correct price", http.MethodPost, "/api/v1/admin/listings/" + testhelpers.FixtureListingAmazon + "/price-corrections",
+			`{"price":3249,"reason":"Coupon price scraped as list price"}`, http.StatusCreated},
+		{"Correct missing listing", http.MethodPost, "/api/v1/admin/listings/lst_missing/price-corrections",
+			`{"price":3249,"reason":"x"}`, http.StatusNotFound},
+		{"Delete product", http.MethodDelete, "/api/v1/admin/products/prod_dym_iso100", ``, http.StatusNoContent},
+		{"Read deleted product", http.MethodGet, "/api/v1/admin/products/prod_dym_iso100", ``, http.StatusOK},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := adminRequest(h, tc.method, tc.target, tc.body)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantStatus, rec.Code)
+			if rec.Code != tc.wantStatus {
+				t.Errorf("Status = %d, want %d (body %s)", rec.Code, tc.wantStatus, rec.Body.String())
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Deleted product is gone from the public API")
+	if rec := get(h, "/api/v1/products/prod_dym_iso100"); rec.Code != http.StatusNotFound {
+		t.Errorf("Public status = %d, want 404", rec.Code)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Audit log lists every write, newest first")
+	rec := adminRequest(h, http.MethodGet, "/api/v1/admin/audit-log?actor_id=ops&limit=50", ``)
+	var body struct {
+		Entries []domain.AuditEntry `json:"entries"`
+		Count   int                 `json:"count"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Decode audit log: %v", err)
+	}
+	testhelpers.LogTestAssertion(logger, "audit entries", 8, body.Count)
+	if body.Count != 8 {
+		t.Fatalf("Got %d audit entries, want 8", body.Count)
+	}
+	if e := body.Entries[0]; e.Action != "delete_product" || e.HTTPMethod != http.MethodDelete || e.Endpoint != "/api/v1/admin/products/prod_dym_iso100" {
+		t.Errorf("Newest entry = %+v", e)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Restoring the deleted product")
+	if rec := adminRequest(h, http.MethodPost, "/api/v1/admin/products/prod_dym_iso100/restore", ``); rec.Code != http.StatusOK {
+		t.Errorf("Restore status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := get(h, "/api/v1/products/prod_dym_iso100"); rec.Code != http.StatusOK {
+		t.Errorf("Public status after restore = %d, want 200", rec.Code)
+	}
+	if rec := adminRequest(h, http.MethodPost, "/api/v1/admin/products/prod_dym_iso100/restore", ``); rec.Code != http.StatusConflict {
+		t.Errorf("Second restore status = %d, want 409", rec.Code)
+	}
+	if rec := adminRequest(h, http.MethodPost, "/api/v1/admin/alerts/alert_missing/restore", ``); rec.Code != http.StatusNotFound {
+		t.Errorf("Restore missing alert status = %d, want 404", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAdminHandler_Routes", true)
+}
+
+func TestAdminHandler_EditVersions(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAdminHandler_EditVersions", "internal/handlers")
+
+	h := newAdminTestRouter(t)
+	target := "/api/v1/admin/products/" + testhelpers.FixtureProductID
+	edit := `{"name":"Gold Standard Whey","brand_id":"optimum-nutrition","category_id":"whey-blend","protein_per_serving":24,"serving_size_grams":30}`
+	put := func(ifMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, target, strings.NewReader(edit))
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	testhelpers.LogTestStep(logger, "arrange", "Reading the product's version tag")
+	tag := adminRequest(h, http.MethodGet, target, ``).Header().Get("ETag")
+	if tag == "" {
+		t.Fatal("Product has no ETag")
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Saving without a version, with a bad tag, with the tag, then with it again")
+	unversioned, bad, saved, stale := put(""), put("latest"), put(tag), put(tag)
+
+	testhelpers.LogTestStep(logger, "assert", "Only the first edit of the tagged version is saved")
+	testhelpers.LogTestAssertion(logger, "unversioned status", http.StatusPreconditionRequired, unversioned.Code)
+	if unversioned.Code != http.StatusPreconditionRequired {
+		t.Errorf("Unversioned status = %d, want 428 (body %s)", unversioned.Code, unversioned.Body.String())
+	}
+	if bad.Code != http.StatusBadRequest {
+		t.Errorf("Bad If-Match status = %d, want 400", bad.Code)
+	}
+	if saved.Code != http.StatusOK || saved.Header().Get("ETag") == tag {
+		t.Errorf("Saved status = %d, ETag %s; want 200 and a new tag (body %s)", saved.Code, saved.Header().Get("ETag"), saved.Body.String())
+	}
+	if stale.Code != http.StatusConflict {
+		t.Errorf("Stale status = %d, want 409", stale.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAdminHandler_EditVersions", true)
+}
+
+func TestAdminHandler_AuditLog(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAdminHandler_AuditLog", "internal/handlers")
+
+	h := newAdminTestRouter(t)
+	start := time.Now().UTC().Add(-time.Second).Format(time.RFC3339)
+
+	testhelpers.LogTestStep(logger, "act", "Throttling a retailer with a reason")
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/retailers/flipkart",
+		strings.NewReader(`{"name":"Flipkart","website_url":"https://www.flipkart.com","requests_per_minute":4,"active":true}`))
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	req.Header.Set(AuditReasonHeader, "  Blocked for scraping too fast ")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Update status = %d: %s", rec.Code, rec.Body.String())
+	}
+	adminRequest(h, http.MethodDelete, "/api/v1/admin/products/"+testhelpers.FixtureProductID, ``)
+
+	testhelpers.LogTestStep(logger, "assert", "The entry records the reason and the changed field")
+	rec = adminRequest(h, http.MethodGet, "/api/v1/admin/audit-log?action=update_retailer&since="+start, ``)
+	var body struct {
+		Entries []domain.AuditEntry `json:"entries"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Decode audit log: %v", err)
+	}
+	testhelpers.LogTestAssertion(logger, "matching entries", 1, len(body.Entries))
+	if len(body.Entries) != 1 {
+		t.Fatalf("Entries = %+v", body.Entries)
+	}
+	e := body.Entries[0]
+	if e.Reason != "Blocked for scraping too fast" || len(e.Changes) != 1 || e.Changes[0].Field != "requests_per_minute" ||
+		e.Changes[0].New != 4.0 {
+		t.Errorf("Entry = %+v", e)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "A window before the changes is empty and a bad time is rejected")
+	rec = adminRequest(h, http.MethodGet, "/api/v1/admin/audit-log?until="+start, ``)
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || len(body.Entries) != 0 {
+		t.Errorf("Entries before the changes = %+v, %v", body.Entries, err)
+	}
+	if rec := adminRequest(h, http.MethodGet, "/api/v1/admin/audit-log?since=yesterday", ``); rec.Code != http.StatusBadRequest {
+		t.Errorf("Bad since status = %d, want 400", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAdminHandler_AuditLog", true)
+}
+
+func TestAdminHandler_Synonyms(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAdminHandler_Synonyms", "internal/handlers")
+
+	h := newAdminTestRouter(t)
+
+	testhelpers.LogTestStep(logger, "act", "Creating, renaming and listing a synonym group")
+	rec := adminRequest(h, http.MethodPost, "/api/v1/admin/synonyms", `{"terms":["WPI","whey protein isolate"]}`)
+	testhelpers.LogTestAssertion(logger, "create status", http.StatusCreated, rec.Code)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Create status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var created domain.Synonym
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if rec.Header().Get("Location") != "/api/v1/admin/synonyms/"+created.ID {
+		t.Errorf("Location = %q", rec.Header().Get("Location"))
+	}
+	if rec := adminRequest(h, http.MethodPut, "/api/v1/admin/synonyms/"+created.ID, `{"terms":["WPI","isolate","whey isolate"]}`); rec.Code != http.StatusOK {
+		t.Errorf("Update status = %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = adminRequest(h, http.MethodGet, "/api/v1/admin/synonyms", ``)
+	var list struct {
+		Synonyms []domain.Synonym `json:"synonyms"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Decode list: %v", err)
+	}
+	if len(list.Synonyms) != 1 || len(list.Synonyms[0].Terms) != 3 {
+		t.Errorf("Synonyms = %+v", list.Synonyms)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Invalid groups are 400s; missing ones 404s")
+	for _, tc := range []struct {
+		method, target, body string
+		want                 int
+	}{
+		{http.MethodPost, "/api/v1/admin/synonyms", `{"terms":["WPI"]}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/v1/admin/synonyms", `{"terms":["ON","on"]}`, http.StatusBadRequest},
+		{http.MethodPut, "/api/v1/admin/synonyms/syn_missing", `{"terms":["a b","c"]}`, http.StatusNotFound},
+		{http.MethodDelete, "/api/v1/admin/synonyms/" + created.ID, ``, http.StatusNoContent},
+		{http.MethodDelete, "/api/v1/admin/synonyms/" + created.ID, ``, http.StatusNotFound},
+	} {
+		if rec := adminRequest(h, tc.method, tc.target, tc.body); rec.Code != tc.want {
+			t.Errorf("%s %s %s status = %d, want %d", tc.method, tc.target, tc.body, rec.Code, tc.want)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAdminHandler_Synonyms", true)
+}
+
+func TestAdminHandler_Flags(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAdminHandler_Flags", "internal/handlers")
+
+	h := newAdminTestRouter(t)
+
+	testhelpers.LogTestStep(logger, "act", "Rolling a flag out to a quarter of users, then listing")
+	rec := adminRequest(h, http.MethodPut, "/api/v1/admin/flags/search.rerank", `{"enabled":true,"percent":25}`)
+	testhelpers.LogTestAssertion(logger, "save status", http.StatusOK, rec.Code)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Save status = %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = adminRequest(h, http.MethodGet, "/api/v1/admin/flags", ``)
+	var list struct {
+		Flags []domain.Flag `json:"flags"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Decode list: %v", err)
+	}
+	if len(list.Flags) != 1 || list.Flags[0].Name != "search.rerank" || list.Flags[0].Percent != 25 {
+		t.Errorf("Flags = %+v", list.Flags)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Invalid flags are 400s; missing ones 404s")
+	for _, tc := range []struct {
+		method, target, body string
+		want                 int
+	}{
+		{http.MethodPut, "/api/v1/admin/flags/Search.Rerank", `{"enabled":true,"percent":25}`, http.StatusBadRequest},
+		{http.MethodPut, "/api/v1/admin/flags/search.rerank", `{"enabled":true,"percent":101}`, http.StatusBadRequest},
+		{http.MethodDelete, "/api/v1/admin/flags/search.rerank", ``, http.StatusNoContent},
+		{http.MethodDelete, "/api/v1/admin/flags/search.rerank", ``, http.StatusNotFound},
+	} {
+		if rec := adminRequest(h, tc.method, tc.target, tc.body); rec.Code != tc.want {
+			t.Errorf("%s %s %s status = %d, want %d", tc.method, tc.target, tc.body, rec.Code, tc.want)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAdminHandler_Flags", true)
+}
+
+func TestAdminHandler_RequiresAuth(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAdminHandler_RequiresAuth", "internal/handlers")
+
+	h := newAdminTestRouter(t)
+
+	testhelpers.LogTestStep(logger, "act", "Calling admin routes without a token")
+	for _, target := range []string{"/api/v1/admin/audit-log", "/api/v1/admin/products/" + testhelpers.FixtureProductID, "/api/v1/admin/nowhere"} {
+		rec := get(h, target)
+		testhelpers.LogTestAssertion(logger, target, http.StatusUnauthorized, rec.Code)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s: status = %d, want 401", target, rec.Code)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Admin routes are absent without an authenticator")
+	unauthenticated := NewRouter(Deps{Logger: logger, Admin: &services.AdminService{}})
+	if rec := get(unauthenticated, "/api/v1/admin/audit-log"); rec.Code != http.StatusNotFound {
+		t.Errorf("Status without AdminAuth = %d, want 404", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAdminHandler_RequiresAuth", true)
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/alerts"
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+)
+
+const maxAlertBodyBytes = 1 << 10
+
+// AlertHandler serves the signed-in user's price alerts.
+type AlertHandler struct {
+	alerts *alerts.Service
+	logger *zap.Logger
+}
+
+// NewAlertHandler creates an AlertHandler.
+func NewAlertHandler(svc *alerts.Service, logger *zap.Logger) *AlertHandler {
+	return &AlertHandler{alerts: svc, logger: logger}
+}
+
+// Register mounts the alert routes on mux. They all require a signed-in
+// user.
+func (h *AlertHandler) Register(mux *http.ServeMux) {
+	mux.Handle("GET /api/v1/alerts", auth.RequireUser(http.HandlerFunc(h.List)))
+	mux.Handle("POST /api/v1/alerts", auth.RequireUser(http.HandlerFunc(h.Create)))
+	mux.Handle("DELETE /api/v1/alerts/{id}", auth.RequireUser(http.HandlerFunc(h.Delete)))
+	mux.Handle("POST /api/v1/alerts/{id}/test", auth.RequireUser(http.HandlerFunc(h.Test)))
+}
+
+// RegisterAdmin mounts the support routes for alerts on mux, which is
+// mounted under AdminPrefix.
+func (h *AlertHandler) RegisterAdmin(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v1/admin/alerts/{id}/test", h.AdminTest)
+}
+
+type alertsResponse struct {
+	Alerts []domain.PriceAlert `json:"alerts"`
+}
+
+// List returns the user's alerts, newest first.
+func (h *AlertHandler) List(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	list, err := h.alerts.List(r.Context(), u.ID)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	if list == nil {
+		list = []domain.PriceAlert{}
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusOK, alertsResponse{Alerts: list})
+}
+
+// Create adds an alert on a product. The condition is a target price, a
+// target price per gram of protein, a percentage drop from the current
+// price, or a fall below the lowest price of the last N days; without one,
+// the target that is set decides.
+func (h *AlertHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		ProductID          string  `json:"product_id"`
+		Condition          string  `json:"condition"`
+		TargetPrice        float64 `json:"target_price"`
+		TargetPricePerGram float64 `json:"target_price_per_gram_protein"`
+		DropPercent        float64 `json:"drop_percent"`
+		LowDays            int     `json:"low_days"`
+	}
+	if !decodeJSON(w, r, maxAlertBodyBytes, &in) {
+		return
+	}
+	u := auth.UserFromContext(r.Context())
+	a, err := h.alerts.Create(r.Context(), *u, domain.PriceAlert{
+		ProductID:          in.ProductID,
+		Condition:          in.Condition,
+		TargetPrice:        in.TargetPrice,
+		TargetPricePerGram: in.TargetPricePerGram,
+		DropPercent:        in.DropPercent,
+		LowDays:            in.LowDays,
+	})
+	if errors.Is(err, alerts.ErrEmailUnverified) {
+		httpx.WriteError(w, r, http.StatusForbidden, httpx.CodeForbidden, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusCreated, a)
+}
+
+// Delete removes one of the user's alerts.
+func (h *AlertHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	if err := h.alerts.Delete(r.Context(), u.ID, r.PathValue("id")); err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Test queues a notification for one of the user's alerts as though it had
+// just fired, so they can check it reaches them.
+func (h *AlertHandler) Test(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	n, err := h.alerts.Test(r.Context(), u.ID, r.PathValue("id"))
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusAccepted, n)
+}
+
+// AdminTest queues a test notification for any user's alert, for support
+// checking a user's channels.
+func (h *AlertHandler) AdminTest(w http.ResponseWriter, r *http.Request) {
+	n, err := h.alerts.Test(r.Context(), "", r.PathValue("id"))
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	h.logger.Info("Admin sent test alert",
+		zap.String("operation", "TestAlert"),
+		zap.String("alert_id", n.AlertID),
+		zap.String("principal", httpx.Principal(r.Context())),
+	)
+	httpx.WriteJSON(w, http.StatusAccepted, n)
+}
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/alerts"
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/middleware"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestAlertHandler_Lifecycle(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAlertHandler_Lifecycle", "internal/handlers")
+
+	testhelpers.LogTestStep(logger, "arrange", "Accounts, a seeded catalog and a signed-in, unverified user")
+	cfg := auth.DefaultConfig()
+	cfg.Password = auth.PasswordParams{Time: 1, MemoryKiB: 64, Threads: 1, KeyLen: 32, SaltLen: 16}
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	sender := &lastTokenSender{}
+	authSvc := auth.NewService(cfg, auth.Repos{
+		Users:         store.Users(),
+		Sessions:      store.Sessions(),
+		Verifications: store.Verifications(),
+	}, logger).WithVerificationSender(sender)
+	prices := services.NewPriceService(services.PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+	alertSvc := alerts.NewService(alerts.Repos{Alerts: store.Alerts(), Notifications: store.Notifications()}, prices, logger)
+	h := NewRouter(Deps{Logger: logger, Auth: authSvc, Alerts: alertSvc})
+
+	sendAuth(h, http.MethodPost, "/api/v1/auth/register", `{"email":"asha@example.com","password":"test-only-password"}`)
+	rec := sendAuth(h, http.MethodPost, "/api/v1/auth/login", `{"email":"asha@example.com","password":"test-only-password"}`)
+	session := rec.Result().Cookies()[0]
+	body := `{"product_id":"` + testhelpers.FixtureProductID + `","target_price":3000}`
+
+	testhelpers.LogTestStep(logger, "act", "Creating alerts signed out and unverified")
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/alerts", body); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Signed-out create status = %d, want 401", rec.Code)
+	}
+	rec = sendAuth(h, http.MethodPost, "/api/v1/alerts", body, session)
+	testhelpers.LogTestAssertion(logger, "unverified status", http.StatusForbidden, rec.Code)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Unverified create status = %d, want 403: %s", rec.Code, rec.Body)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Verifying, then creating, listing and deleting")
+	if rec := sendAuth(h, http.MethodGet, "/api/v1/auth/verify?token="+url.QueryEscape(sender.token), ""); rec.Code != http.StatusOK {
+		t.Fatalf("Verify status = %d: %s", rec.Code, rec.Body)
+	}
+	rec = sendAuth(h, http.MethodPost, "/api/v1/alerts", body, session)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Create status = %d: %s", rec.Code, rec.Body)
+	}
+	var created struct {
+		ID     string `json:"id"`
+		UserID string `json:"user_id"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil || created.ID == "" {
+		t.Fatalf("Create body %s: %v", rec.Body, err)
+	}
+	if created.UserID != "" {
+		t.Error("Create response exposes the user ID")
+	}
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/alerts", `{"product_id":"prod_missing","target_price":3000}`, session); rec.Code != http.StatusNotFound {
+		t.Errorf("Unknown product status = %d, want 404", rec.Code)
+	}
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/alerts", body, session); rec.Code != http.StatusConflict {
+		t.Errorf("Duplicate alert status = %d, want 409", rec.Code)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Creating a percentage drop alert and an invalid low alert")
+	drop := `{"product_id":"` + testhelpers.FixtureSecondProductID + `","condition":"percent_drop","drop_percent":15}`
+	rec = sendAuth(h, http.MethodPost, "/api/v1/alerts", drop, session)
+	var dropped domain.PriceAlert
+	if err := json.Unmarshal(rec.Body.Bytes(), &dropped); rec.Code != http.StatusCreated || err != nil ||
+		dropped.Condition != domain.ConditionPercentDrop || dropped.DropPercent != 15 || dropped.BasePrice == 0 {
+		t.Errorf("Percent drop create %d: %s", rec.Code, rec.Body)
+	}
+	low := `{"product_id":"` + testhelpers.FixtureSecondProductID + `","condition":"low","low_days":0}`
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/alerts", low, session); rec.Code != http.StatusBadRequest {
+		t.Errorf("Invalid low alert status = %d, want 400", rec.Code)
+	}
+	sendAuth(h, http.MethodDelete, "/api/v1/alerts/"+dropped.ID, "", session)
+
+	rec = sendAuth(h, http.MethodGet, "/api/v1/alerts", "", session)
+	var list struct {
+		Alerts []struct {
+			ID string `json:"id"`
+		} `json:"alerts"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list.Alerts) != 1 || list.Alerts[0].ID != created.ID {
+		t.Errorf("List body %s: %v", rec.Body, err)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "private, no-store" {
+		t.Errorf("List Cache-Control = %q", cc)
+	}
+
+	rec = sendAuth(h, http.MethodDelete, "/api/v1/alerts/"+created.ID, "", session)
+	testhelpers.LogTestAssertion(logger, "delete status", http.StatusNoContent, rec.Code)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("Delete status = %d: %s", rec.Code, rec.Body)
+	}
+	if rec := sendAuth(h, http.MethodDelete, "/api/v1/alerts/"+created.ID, "", session); rec.Code != http.StatusNotFound {
+		t.Errorf("Second delete status = %d, want 404", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAlertHandler_Lifecycle", true)
+}
+
+func TestAlertHandler_AccountRateLimit(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAlertHandler_AccountRateLimit", "internal/handlers")
+
+	testhelpers.LogTestStep(logger, "arrange", "Two signed-in users under a per-account limit")
+	cfg := auth.DefaultConfig()
+	cfg.Password = auth.PasswordParams{Time: 1, MemoryKiB: 64, Threads: 1, KeyLen: 32, SaltLen: 16}
+	store := memory.NewStore()
+	authSvc := auth.NewService(cfg, auth.Repos{
+		Users:         store.Users(),
+		Sessions:      store.Sessions(),
+		Verifications: store.Verifications(),
+	}, logger)
+	alertSvc := alerts.NewService(alerts.Repos{Alerts: store.Alerts(), Notifications: store.Notifications()}, nil, logger)
+	limitCfg := middleware.RateLimitConfig{
+		Routes: map[string]middleware.RateLimitPolicy{"GET /api/v1/alerts": {Limit: 2, Window: time.Hour}},
+		Key:    auth.RateLimitKey,
+	}
+	limiter := middleware.NewRateLimiter(limitCfg, middleware.NewMemoryRateLimitStore(), logger)
+	h := NewRouter(Deps{Logger: logger, Batch: DefaultBatchConfig(), Auth: authSvc, Alerts: alertSvc, AccountRateLimit: limiter.Handler})
+
+	var sessions []*http.Cookie
+	for _, email := range []string{"asha@example.com", "ravi@example.com"} {
+		sendAuth(h, http.MethodPost, "/api/v1/auth/register", `{"email":"`+email+`","password":"test-only-password"}`)
+		rec := sendAuth(h, http.MethodPost, "/api/v1/auth/login", `{"email":"`+email+`","password":"test-only-password"}`)
+		sessions = append(sessions, rec.Result().Cookies()[0])
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Spending one account's budget, partly through a batch")
+	if rec := sendAuth(h, http.MethodGet, "/api/v1/alerts", "", sessions[0]); rec.Code != http.StatusOK {
+		t.Fatalf("First list status = %d: %s", rec.Code, rec.Body)
+	}
+	rec := sendAuth(h, http.MethodPost, BatchPath, `[
+		{"id":"1","method":"GET","path":"/api/v1/alerts"},
+		{"id":"2","method":"GET","path":"/api/v1/alerts"}
+	]`, sessions[0])
+	var out struct {
+		Responses []BatchResponse `json:"responses"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil || len(out.Responses) != 2 {
+		t.Fatalf("Batch body %s: %v", rec.Body, err)
+	}
+	batch := out.Responses
+	testhelpers.LogTestAssertion(logger, "second sub-request", http.StatusTooManyRequests, batch[1].Status)
+	if batch[0].Status != http.StatusOK || batch[1].Status != http.StatusTooManyRequests {
+		t.Errorf("Batch statuses = %+v, want 200 then 429", batch)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Other accounts and anonymous routes are unaffected")
+	if rec := sendAuth(h, http.MethodGet, "/api/v1/alerts", "", sessions[1]); rec.Code != http.StatusOK {
+		t.Errorf("Other account status = %d, want 200", rec.Code)
+	}
+	if rec := sendAuth(h, http.MethodGet, "/api/v1/alerts", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Anonymous status = %d, want 401", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAlertHandler_AccountRateLimit", true)
+}
+
+func TestAlertHandler_Test(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAlertHandler_Test", "internal/handlers")
+
+	testhelpers.LogTestStep(logger, "arrange", "Two signed-in users, one with an alert, and support access")
+	cfg := auth.DefaultConfig()
+	cfg.Password = auth.PasswordParams{Time: 1, MemoryKiB: 64, Threads: 1, KeyLen: 32, SaltLen: 16}
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	authSvc := auth.NewService(cfg, auth.Repos{
+		Users:         store.Users(),
+		Sessions:      store.Sessions(),
+		Verifications: store.Verifications(),
+	}, logger)
+	prices := services.NewPriceService(services.PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+	alertSvc := alerts.NewService(alerts.Repos{Alerts: store.Alerts(), Notifications: store.Notifications()}, prices, logger)
+	admin := middleware.NewBearerAuth(middleware.BearerAuthConfig{Realm: "admin", Tokens: map[string]string{"ops": testAdminToken}}, logger)
+	h := NewRouter(Deps{Logger: logger, Batch: DefaultBatchConfig(), Auth: authSvc, Alerts: alertSvc, AdminAuth: admin.Handler})
+
+	var sessions []*http.Cookie
+	for _, email := range []string{"asha@example.com", "ravi@example.com"} {
+		sendAuth(h, http.MethodPost, "/api/v1/auth/register", `{"email":"`+email+`","password":"test-only-password"}`)
+		rec := sendAuth(h, http.MethodPost, "/api/v1/auth/login", `{"email":"`+email+`","password":"test-only-password"}`)
+		sessions = append(sessions, rec.Result().Cookies()[0])
+	}
+	owner, err := store.Users().UserByEmail(t.Context(), "asha@example.com")
+	if err != nil {
+		t.Fatalf("UserByEmail: %v", err)
+	}
+	owner.EmailVerified = true
+	a, err := alertSvc.Create(t.Context(), *owner, domain.PriceAlert{ProductID: testhelpers.FixtureProductID, TargetPrice: 3000})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	target := "/api/v1/alerts/" + a.ID + "/test"
+
+	testhelpers.LogTestStep(logger, "act", "Testing the alert as its owner, another user, signed out and as support")
+	rec := sendAuth(h, http.MethodPost, target, "", sessions[0])
+	testhelpers.LogTestAssertion(logger, "owner status", http.StatusAccepted, rec.Code)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Owner status = %d: %s", rec.Code, rec.Body)
+	}
+	var n domain.Notification
+	if err := json.Unmarshal(rec.Body.Bytes(), &n); err != nil || !n.Test || n.Price != 3000 {
+		t.Errorf("Owner body = %s, want a test notification at ₹3,000", rec.Body)
+	}
+	if rec := sendAuth(h, http.MethodPost, target, "", sessions[1]); rec.Code != http.StatusNotFound {
+		t.Errorf("Other user status = %d, want 404", rec.Code)
+	}
+	if rec := sendAuth(h, http.MethodPost, target, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Signed-out status = %d, want 401", rec.Code)
+	}
+	if rec := adminRequest(h, http.MethodPost, "/api/v1/admin/alerts/"+a.ID+"/test", ""); rec.Code != http.StatusAccepted {
+		t.Errorf("Support status = %d: %s", rec.Code, rec.Body)
+	}
+	if pending, _ := store.Notifications().Pending(t.Context(), 0); len(pending) != 2 {
+		t.Errorf("Pending = %+v, want the owner's and support's tests", pending)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAlertHandler_Test", true)
+}
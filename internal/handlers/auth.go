@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+)
+
+const maxAuthBodyBytes = 4 << 10
+
+// AuthHandler serves registration, login and email verification. It relies
+// on the auth.Service middleware having run, so signed-in requests carry
+// their user.
+type AuthHandler struct {
+	auth   *auth.Service
+	logger *zap.Logger
+}
+
+// NewAuthHandler creates an AuthHandler.
+func NewAuthHandler(svc *auth.Service, logger *zap.Logger) *AuthHandler {
+	return &AuthHandler{auth: svc, logger: logger}
+}
+
+// Register mounts the auth routes on mux.
+func (h *AuthHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v1/auth/register", h.SignUp)
+	mux.HandleFunc("POST /api/v1/auth/login", h.Login)
+	mux.HandleFunc("POST /api/v1/auth/logout", h.Logout)
+	mux.HandleFunc("GET /api/v1/auth/verify", h.Verify)
+	mux.Handle("POST /api/v1/auth/verify/resend", auth.RequireUser(http.HandlerFunc(h.ResendVerification)))
+	mux.Handle("GET /api/v1/auth/me", auth.RequireUser(http.HandlerFunc(h.Me)))
+	mux.HandleFunc("GET /api/v1/auth/oauth/{provider}", h.BeginOAuth)
+	mux.HandleFunc("GET /api/v1/auth/oauth/{provider}/callback", h.CompleteOAuth)
+}
+
+type userResponse struct {
+	User    *domain.User `json:"user"`
+	Message string       `json:"message,omitempty"`
+}
+
+// SignUp creates an account and emails a verification link. The user signs
+// in separately.
+func (h *AuthHandler) SignUp(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+		Name     string `json:"name"`
+	}
+	if !decodeJSON(w, r, maxAuthBodyBytes, &in) {
+		return
+	}
+	u, err := h.auth.Register(r.Context(), in.Email, in.Password, in.Name)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, http.StatusCreated, userResponse{
+		User:    u,
+		Message: "Account created. Check your email to verify your address.",
+	})
+}
+
+// Login checks the password and sets the session cookie.
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if !decodeJSON(w, r, maxAuthBodyBytes, &in) {
+		return
+	}
+	u, token, err := h.auth.Login(r.Context(), in.Email, in.Password, auth.SessionMeta{
+		UserAgent: r.UserAgent(),
+		IPAddress: h.auth.ClientIP(r),
+	})
+	if errors.Is(err, auth.ErrInvalidCredentials) {
+		httpx.WriteError(w, r, http.StatusUnauthorized, httpx.CodeUnauthorized, err.Error(), nil)
+		return
+	}
+	if errors.Is(err, auth.ErrLockedOut) {
+		auth.WriteLockout(w, r, err)
+		return
+	}
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	h.auth.SetCookie(w, token)
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, http.StatusOK, userResponse{User: u})
+}
+
+// Logout ends the current session, if any, and clears the cookie.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if err := h.auth.Logout(r.Context(), h.auth.Token(r)); err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	h.auth.ClearCookie(w)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Verify confirms an email address with the ?token= from a verification
+// link.
+func (h *AuthHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	u, err := h.auth.VerifyEmail(r.Context(), r.URL.Query().Get("token"))
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	httpx.WriteJSON(w, http.StatusOK, userResponse{User: u, Message: "Email address verified."})
+}
+
+// ResendVerification emails the signed-in user a new verification link.
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	if err := h.auth.ResendVerification(r.Context(), *auth.UserFromContext(r.Context())); err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// BeginOAuth redirects to the provider's consent page.
+func (h *AuthHandler) BeginOAuth(w http.ResponseWriter, r *http.Request) {
+	target, err := h.auth.BeginOAuth(w, r.PathValue("provider"))
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// CompleteOAuth is where the provider sends the browser back. Signing in
+// while already signed in links the provider account to the current user.
+func (h *AuthHandler) CompleteOAuth(w http.ResponseWriter, r *http.Request) {
+	_, token, err := h.auth.CompleteOAuth(w, r, r.PathValue("provider"), auth.UserFromContext(r.Context()))
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	h.auth.SetCookie(w, token)
+	w.Header().Set("Cache-Control", "no-store")
+	// The callback URL carries the authorization code; keep it out of
+	// Referer headers sent from the next page.
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// Me returns the signed-in user.
+func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusOK, userResponse{User: auth.UserFromContext(r.Context())})
+}
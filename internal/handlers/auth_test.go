@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// lastTokenSender keeps the most recent verification token it was asked to
+// deliver.
+type lastTokenSender struct{ token string }
+
+func (s *lastTokenSender) SendVerification(_ context.Context, _ domain.User, token string) error {
+	s.token = token
+	return nil
+}
+
+func sendAuth(h http.Handler, method, target, body string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuthHandler_AccountLifecycle(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAuthHandler_AccountLifecycle", "internal/handlers")
+
+	cfg := auth.DefaultConfig()
+	cfg.Password = auth.PasswordParams{Time: 1, MemoryKiB: 64, Threads: 1, KeyLen: 32, SaltLen: 16}
+	store := memory.NewStore()
+	sender := &lastTokenSender{}
+	svc := auth.NewService(cfg, auth.Repos{
+		Users:         store.Users(),
+		Sessions:      store.Sessions(),
+		Verifications: store.Verifications(),
+	}, logger).WithVerificationSender(sender)
+	h := NewRouter(Deps{Logger: logger, Auth: svc})
+
+	testhelpers.LogTestStep(logger, "act", "Registering")
+	rec := sendAuth(h, http.MethodPost, "/api/v1/auth/register",
+		`{"email":"asha@example.com","password":"test-only-password","name":"Asha"}`)
+	testhelpers.LogTestAssertion(logger, "register status", http.StatusCreated, rec.Code)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Register status = %d: %s", rec.Code, rec.Body)
+	}
+	if strings.Contains(rec.Body.String(), "argon2") {
+		t.Error("Register response leaks the password hash")
+	}
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/auth/register",
+		`{"email":"asha@example.com","password":"test-only-password"}`); rec.Code != http.StatusConflict {
+		t.Errorf("Duplicate register status = %d, want 409", rec.Code)
+	}
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/auth/register",
+		`{"email":"ravi@example.com","password":"test-only-password","role":"admin"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Unknown field status = %d, want 400", rec.Code)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Logging in")
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/auth/login",
+		`{"email":"asha@example.com","password":"wrong-password"}`); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Wrong password status = %d, want 401", rec.Code)
+	}
+	rec = sendAuth(h, http.MethodPost, "/api/v1/auth/login", `{"email":"asha@example.com","password":"test-only-password"}`)
+	if rec.Code != http.StatusOK || len(rec.Result().Cookies()) != 1 {
+		t.Fatalf("Login status = %d, cookies %v", rec.Code, rec.Result().Cookies())
+	}
+	session := rec.Result().Cookies()[0]
+	if rec.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("Login Cache-Control = %q", rec.Header().Get("Cache-Control"))
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Verifying the email address")
+	if rec := sendAuth(h, http.MethodGet, "/api/v1/auth/verify?token=forged", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("Forged token status = %d, want 400", rec.Code)
+	}
+	rec = sendAuth(h, http.MethodGet, "/api/v1/auth/verify?token="+url.QueryEscape(sender.token), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Verify status = %d: %s", rec.Code, rec.Body)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Reading the profile")
+	rec = sendAuth(h, http.MethodGet, "/api/v1/auth/me", "", session)
+	var me struct {
+		User domain.User `json:"user"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &me); err != nil {
+		t.Fatalf("Decode /me: %v", err)
+	}
+	testhelpers.LogTestAssertion(logger, "verified", true, me.User.EmailVerified)
+	if me.User.Email != "asha@example.com" || !me.User.EmailVerified {
+		t.Errorf("/me user = %+v", me.User)
+	}
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/auth/verify/resend", "", session); rec.Code != http.StatusConflict {
+		t.Errorf("Resend after verifying status = %d, want 409", rec.Code)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Logging out")
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/auth/logout", "", session); rec.Code != http.StatusNoContent {
+		t.Errorf("Logout status = %d, want 204", rec.Code)
+	}
+	if rec := sendAuth(h, http.MethodGet, "/api/v1/auth/me", "", session); rec.Code != http.StatusUnauthorized {
+		t.Errorf("/me after logout status = %d, want 401", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAuthHandler_AccountLifecycle", true)
+}
+
+func TestAuthHandler_LoginLockout(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAuthHandler_LoginLockout", "internal/handlers")
+
+	cfg := auth.DefaultConfig()
+	cfg.Password = auth.PasswordParams{Time: 1, MemoryKiB: 64, Threads: 1, KeyLen: 32, SaltLen: 16}
+	cfg.Lockout.MaxFailures = 2
+	store := memory.NewStore()
+	svc := auth.NewService(cfg, auth.Repos{
+		Users:         store.Users(),
+		Sessions:      store.Sessions(),
+		Verifications: store.Verifications(),
+	}, logger)
+	h := NewRouter(Deps{Logger: logger, Auth: svc})
+	sendAuth(h, http.MethodPost, "/api/v1/auth/register", `{"email":"asha@example.com","password":"test-only-password"}`)
+
+	testhelpers.LogTestStep(logger, "act", "Failing twice, then using the right password")
+	for range 2 {
+		if rec := sendAuth(h, http.MethodPost, "/api/v1/auth/login", `{"email":"asha@example.com","password":"wrong-password"}`); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("Failed login status = %d, want 401", rec.Code)
+		}
+	}
+	rec := sendAuth(h, http.MethodPost, "/api/v1/auth/login", `{"email":"asha@example.com","password":"test-only-password"}`)
+	testhelpers.LogTestAssertion(logger, "status", http.StatusTooManyRequests, rec.Code)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("Locked login status = %d, Retry-After %q; want 429 with Retry-After", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if len(rec.Result().Cookies()) != 0 {
+		t.Error("A locked login started a session")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAuthHandler_LoginLockout", true)
+}
+
+func TestAuthHandler_OAuthRoutes(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAuthHandler_OAuthRoutes", "internal/handlers")
+
+	cfg := auth.DefaultConfig()
+	cfg.Password = auth.PasswordParams{Time: 1, MemoryKiB: 64, Threads: 1, KeyLen: 32, SaltLen: 16}
+	cfg.OAuthCallbackURL = "https://proteinprices.example/api/v1/auth/oauth/{provider}/callback"
+	store := memory.NewStore()
+	svc := auth.NewService(cfg, auth.Repos{
+		Users:         store.Users(),
+		Sessions:      store.Sessions(),
+		Verifications: store.Verifications(),
+		Identities:    store.Identities(),
+	}, logger).WithProvider(auth.NewOIDCProvider(auth.GoogleConfig("client-id", "test-only-secret")))
+	h := NewRouter(Deps{Logger: logger, Auth: svc})
+
+	testhelpers.LogTestStep(logger, "act", "Starting Google sign-in")
+	rec := get(h, "/api/v1/auth/oauth/google")
+	testhelpers.LogTestAssertion(logger, "status", http.StatusFound, rec.Code)
+	if rec.Code != http.StatusFound {
+		t.Fatalf("Status = %d, want 302", rec.Code)
+	}
+	consent, _ := url.Parse(rec.Header().Get("Location"))
+	q := consent.Query()
+	if consent.Host != "accounts.google.com" || q.Get("client_id") != "client-id" || q.Get("code_challenge_method") != "S256" ||
+		q.Get("redirect_uri") != "https://proteinprices.example/api/v1/auth/oauth/google/callback" {
+		t.Errorf("Consent URL = %s", consent)
+	}
+	if strings.Contains(consent.String(), "test-only-secret") {
+		t.Error("Consent URL leaks the client secret")
+	}
+	if len(rec.Result().Cookies()) != 1 || !rec.Result().Cookies()[0].HttpOnly {
+		t.Errorf("State cookie = %v", rec.Result().Cookies())
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Using unknown providers and denied consent")
+	if rec := get(h, "/api/v1/auth/oauth/myspace"); rec.Code != http.StatusNotFound {
+		t.Errorf("Unknown provider status = %d, want 404", rec.Code)
+	}
+	if rec := get(h, "/api/v1/auth/oauth/google/callback?error=access_denied"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Denied consent status = %d, want 400", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAuthHandler_OAuthRoutes", true)
+}
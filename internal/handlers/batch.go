@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/logctx"
+)
+
+// BatchPath is the route of the batch endpoint; sub-requests may not target it.
+const BatchPath = "/api/v1/batch"
+
+// batchHeader marks sub-requests so downstream middleware can recognise them.
+const batchHeader = "X-Batch-Request"
+
+// forwardedBatchHeaders are copied from the outer request into every
+// sub-request so auth, localisation, tracing and rate limiting behave as if
+// the client had made the call directly.
+var forwardedBatchHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Accept-Language",
+	httpx.RequestIDHeader,
+	"X-Forwarded-For",
+	"X-Real-IP",
+}
+
+// reservedBatchHeaders may not be set by a sub-request: they name the
+// client, which rate limits are keyed on, or mark the sub-request itself.
+var reservedBatchHeaders = []string{
+	"X-Forwarded-For",
+	"X-Real-IP",
+	"Forwarded",
+	batchHeader,
+}
+
+// BatchConfig limits the batch endpoint.
+type BatchConfig struct {
+	MaxRequests  int
+	MaxBodyBytes int64
+}
+
+// DefaultBatchConfig returns the standard batch limits.
+func DefaultBatchConfig() BatchConfig {
+	return BatchConfig{
+		MaxRequests:  20,
+		MaxBodyBytes: 1 << 20,
+	}
+}
+
+// BatchRequest is a single sub-request in a batch.
+type BatchRequest struct {
+	ID      string            `json:"id,omitempty"`
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// BatchResponse is the result of a single sub-request. JSON bodies are
+// embedded as-is; anything else is embedded as a JSON string.
+type BatchResponse struct {
+	ID      string            `json:"id,omitempty"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// BatchHandler serves POST /api/v1/batch by executing each sub-request
+// against the API router in order, letting low-bandwidth clients and the
+// widget fetch several resources in one round trip.
+type BatchHandler struct {
+	cfg    BatchConfig
+	target http.Handler
+	logger *zap.Logger
+}
+
+// NewBatchHandler creates a BatchHandler dispatching into target.
+func NewBatchHandler(cfg BatchConfig, target http.Handler, logger *zap.Logger) *BatchHandler {
+	return &BatchHandler{cfg: cfg, target: target, logger: logger}
+}
+
+func (h *BatchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logctx.Logger(r.Context(), h.logger).With(
+		zap.String("operation", "Batch"),
+	)
+
+	if r.Header.Get(batchHeader) != "" {
+		httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, "Batch requests cannot be nested", nil)
+		return
+	}
+
+	var requests []BatchRequest
+	dec := json.NewDecoder(io.LimitReader(r.Body, h.cfg.MaxBodyBytes))
+	if err := dec.Decode(&requests); err != nil {
+		httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest,
+			"Request body must be a JSON array of sub-requests", nil)
+		return
+	}
+	if len(requests) == 0 || len(requests) > h.cfg.MaxRequests {
+		httpx.WriteError(w, r, http.StatusUnprocessableEntity, httpx.CodeUnprocessableEntity,
+			fmt.Sprintf("Batch must contain between 1 and %d requests", h.cfg.MaxRequests),
+			map[string]any{"received": len(requests), "max": h.cfg.MaxRequests})
+		return
+	}
+
+	for i, sub := range requests {
+		if err := validateBatchRequest(sub); err != nil {
+			httpx.WriteError(w, r, http.StatusUnprocessableEntity, httpx.CodeUnprocessableEntity, err.Error(),
+				map[string]any{"index": i})
+			return
+		}
+	}
+
+	logger.Debug("Executing batch", zap.Int("requests", len(requests)))
+
+	responses := make([]BatchResponse, 0, len(requests))
+	for _, sub := range requests {
+		if err := r.Context().Err(); err != nil {
+			logger.Info("Batch cancelled by client", zap.Int("completed", len(responses)))
+			return
+		}
+		responses = append(responses, h.execute(r, sub))
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{"responses": responses})
+}
+
+func validateBatchRequest(sub BatchRequest) error {
+	switch strings.ToUpper(sub.Method) {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return fmt.Errorf("unsupported method %q", sub.Method)
+	}
+	if !strings.HasPrefix(sub.Path, "/api/") {
+		return fmt.Errorf("path %q must be an API path", sub.Path)
+	}
+	if strings.HasPrefix(sub.Path, BatchPath) {
+		return fmt.Errorf("batch requests cannot be nested")
+	}
+	for name := range sub.Headers {
+		for _, reserved := range reservedBatchHeaders {
+			if strings.EqualFold(name, reserved) {
+				return fmt.Errorf("header %q cannot be set on a sub-request", name)
+			}
+		}
+	}
+	return nil
+}
+
+func (h *BatchHandler) execute(parent *http.Request, sub BatchRequest) BatchResponse {
+	var body io.Reader
+	if len(sub.Body) > 0 {
+		body = bytes.NewReader(sub.Body)
+	}
+
+	req, err := http.NewRequestWithContext(httpx.TrackSubrequestRoute(parent.Context()), strings.ToUpper(sub.Method), sub.Path, body)
+	if err != nil {
+		return batchErrorResponse(sub.ID, http.StatusBadRequest, err.Error())
+	}
+	req.RemoteAddr = parent.RemoteAddr
+	req.Host = parent.Host
+	for _, name := range forwardedBatchHeaders {
+		if v := parent.Header.Get(name); v != "" {
+			req.Header.Set(name, v)
+		}
+	}
+	for name, value := range sub.Headers {
+		req.Header.Set(name, value)
+	}
+	if len(sub.Body) > 0 && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set(batchHeader, "1")
+
+	rec := newBufferedResponse()
+	h.target.ServeHTTP(rec, req)
+
+	resp := BatchResponse{ID: sub.ID, Status: rec.status}
+	if ct := rec.header.Get("Content-Type"); ct != "" {
+		resp.Headers = map[string]string{"Content-Type": ct}
+	}
+	if loc := rec.header.Get("Location"); loc != "" {
+		if resp.Headers == nil {
+			resp.Headers = map[string]string{}
+		}
+		resp.Headers["Location"] = loc
+	}
+	resp.Body = embedBody(rec.header.Get("Content-Type"), rec.body.Bytes())
+	return resp
+}
+
+func embedBody(contentType string, body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) && json.Valid(body) {
+		return json.RawMessage(bytes.TrimSpace(body))
+	}
+	quoted, _ := json.Marshal(string(body))
+	return quoted
+}
+
+func batchErrorResponse(id string, status int, message string) BatchResponse {
+	body, _ := json.Marshal(httpx.ErrorResponse{Error: httpx.APIError{
+		Code:    httpx.CodeBadRequest,
+		Message: message,
+	}})
+	return BatchResponse{ID: id, Status: status, Body: body}
+}
+
+// bufferedResponse captures a sub-request's response in memory.
+type bufferedResponse struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func newBufferedResponse() *bufferedResponse {
+	return &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.wroteHeader {
+		return
+	}
+	b.status = status
+	b.wroteHeader = true
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.wroteHeader = true
+	return b.body.Write(p)
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/middleware"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func newBatchTestHandler(t *testing.T) http.Handler {
+	t.Helper()
+	logger := testhelpers.SetupTestLogger(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/products/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"id":"`+r.PathValue("id")+`","lang":"`+r.Header.Get("Accept-Language")+`"}`)
+	})
+	mux.HandleFunc("POST /api/v1/alerts", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/api/v1/alerts/alert_1")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(body)
+	})
+	mux.HandleFunc("GET /api/v1/plain", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, "pong")
+	})
+	mux.Handle("POST "+BatchPath, NewBatchHandler(DefaultBatchConfig(), mux, logger))
+	return mux
+}
+
+func doBatch(h http.Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, BatchPath, strings.NewReader(body))
+	req.Header.Set("Accept-Language", "hi-IN")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestBatchHandler_ExecutesSubRequestsInOrder(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestBatchHandler_ExecutesSubRequestsInOrder", "internal/handlers")
+
+	h := newBatchTestHandler(t)
+
+	testhelpers.LogTestStep(logger, "act", "Posting a mixed batch")
+	rec := doBatch(h, `[
+		{"id":"p","method":"GET","path":"/api/v1/products/prod_123"},
+		{"id":"a","method":"POST","path":"/api/v1/alerts","body":{"target_price":2999}},
+		{"id":"t","method":"GET","path":"/api/v1/plain"},
+		{"id":"m","method":"GET","path":"/api/v1/missing"}
+	]`)
+
+	testhelpers.LogTestStep(logger, "assert", "Validating each sub-response")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	var out struct {
+		Responses []BatchResponse `json:"responses"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&out); err != nil {
+		t.Fatalf("Failed to decode batch response: %v", err)
+	}
+	if len(out.Responses) != 4 {
+		t.Fatalf("Got %d responses, want 4", len(out.Responses))
+	}
+
+	product := out.Responses[0]
+	if product.ID != "p" || product.Status != http.StatusOK {
+		t.Errorf("Product response = %+v", product)
+	}
+	if string(product.Body) != `{"id":"prod_123","lang":"hi-IN"}` {
+		t.Errorf("Product body = %s (Accept-Language must be forwarded)", product.Body)
+	}
+
+	alert := out.Responses[1]
+	if alert.Status != http.StatusCreated || alert.Headers["Location"] != "/api/v1/alerts/alert_1" {
+		t.Errorf("Alert response = %+v", alert)
+	}
+	if string(alert.Body) != `{"target_price":2999}` {
+		t.Errorf("Alert body = %s", alert.Body)
+	}
+
+	if string(out.Responses[2].Body) != `"pong"` {
+		t.Errorf("Plain body = %s, want JSON string", out.Responses[2].Body)
+	}
+	if out.Responses[3].Status != http.StatusNotFound {
+		t.Errorf("Missing route status = %d, want 404", out.Responses[3].Status)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestBatchHandler_ExecutesSubRequestsInOrder", true)
+}
+
+func TestBatchHandler_Validation(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestBatchHandler_Validation", "internal/handlers")
+
+	h := newBatchTestHandler(t)
+	tooMany := "[" + strings.TrimSuffix(strings.Repeat(`{"method":"GET","path":"/api/v1/plain"},`, 21), ",") + "]"
+
+	testCases := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"Not an array", `{"method":"GET"}`, http.StatusBadRequest},
+		{"Empty batch", `[]`, http.StatusUnprocessableEntity},
+		{"Too many requests", tooMany, http.StatusUnprocessableEntity},
+		{"Nested batch", `[{"method":"POST","path":"/api/v1/batch","body":[]}]`, http.StatusUnprocessableEntity},
+		{"Non-API path", `[{"method":"GET","path":"/admin"}]`, http.StatusUnprocessableEntity},
+		{"Bad method", `[{"method":"TRACE","path":"/api/v1/plain"}]`, http.StatusUnprocessableEntity},
+		{"Spoofed client address", `[{"method":"GET","path":"/api/v1/plain","headers":{"x-forwarded-for":"198.51.100.9"}}]`, http.StatusUnprocessableEntity},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := doBatch(h, tc.body)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.status, rec.Code)
+			if rec.Code != tc.status {
+				t.Errorf("Status = %d, want %d (body %s)", rec.Code, tc.status, rec.Body.String())
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestBatchHandler_Validation", true)
+}
+
+func TestBatchHandler_RateLimitsSubRequests(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestBatchHandler_RateLimitsSubRequests", "internal/handlers")
+
+	testhelpers.LogTestStep(logger, "arrange", "A router whose client limit allows two guest alerts")
+	limiter := middleware.NewRateLimiter(middleware.RateLimitConfig{
+		Routes: map[string]middleware.RateLimitPolicy{"POST /api/v1/alerts/guest": {Limit: 2, Window: time.Hour}},
+	}, middleware.NewMemoryRateLimitStore(), logger)
+	h := NewRouter(Deps{Logger: logger, Batch: DefaultBatchConfig(), RateLimit: limiter.Handler})
+
+	testhelpers.LogTestStep(logger, "act", "Creating three guest alerts in one batch")
+	sub := `{"method":"POST","path":"/api/v1/alerts/guest","body":{}}`
+	rec := doBatch(h, "["+strings.Repeat(sub+",", 2)+sub+"]")
+
+	testhelpers.LogTestStep(logger, "assert", "The third is charged to the guest bucket and refused")
+	var out struct {
+		Responses []BatchResponse `json:"responses"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil || len(out.Responses) != 3 {
+		t.Fatalf("Batch body %s: %v", rec.Body, err)
+	}
+	testhelpers.LogTestAssertion(logger, "third sub-request", http.StatusTooManyRequests, out.Responses[2].Status)
+	for i, resp := range out.Responses {
+		if limited := resp.Status == http.StatusTooManyRequests; limited != (i == 2) {
+			t.Errorf("Sub-request %d status = %d", i, resp.Status)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestBatchHandler_RateLimitsSubRequests", true)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/services"
+)
+
+// CatalogHandler serves product details and retailers.
+type CatalogHandler struct {
+	catalog *services.CatalogService
+	logger  *zap.Logger
+}
+
+// NewCatalogHandler creates a CatalogHandler.
+func NewCatalogHandler(catalog *services.CatalogService, logger *zap.Logger) *CatalogHandler {
+	return &CatalogHandler{catalog: catalog, logger: logger}
+}
+
+// Register mounts the catalog routes on mux.
+func (h *CatalogHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/products/{id}", h.Product)
+	mux.HandleFunc("GET /api/v1/retailers", h.Retailers)
+	mux.HandleFunc("GET /api/v1/retailers/{id}", h.Retailer)
+}
+
+// Product serves one product with its variants.
+func (h *CatalogHandler) Product(w http.ResponseWriter, r *http.Request) {
+	detail, err := h.catalog.Product(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	if httpx.WantsHAL(w, r) {
+		httpx.WriteHAL(w, http.StatusOK, productResource(detail))
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, detail)
+}
+
+// Retailers serves the active retailers.
+func (h *CatalogHandler) Retailers(w http.ResponseWriter, r *http.Request) {
+	retailers, err := h.catalog.Retailers(r.Context())
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	if httpx.WantsHAL(w, r) {
+		embedded := make([]*httpx.Resource, 0, len(retailers))
+		for _, ret := range retailers {
+			embedded = append(embedded, retailerResource(ret))
+		}
+		res := httpx.NewResource(map[string]any{"total_count": len(retailers)}, r.URL.RequestURI()).
+			Embed("retailers", embedded)
+		httpx.WriteHAL(w, http.StatusOK, res)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{"retailers": retailers, "total_count": len(retailers)})
+}
+
+// Retailer serves one retailer.
+func (h *CatalogHandler) Retailer(w http.ResponseWriter, r *http.Request) {
+	retailer, err := h.catalog.Retailer(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	if httpx.WantsHAL(w, r) {
+		httpx.WriteHAL(w, http.StatusOK, retailerResource(*retailer))
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, retailer)
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+type halLinks struct {
+	Links    map[string]json.RawMessage `json:"_links"`
+	Embedded map[string]json.RawMessage `json:"_embedded"`
+}
+
+func getHAL(h http.Handler, target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set("Accept", httpx.MediaTypeHAL)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCatalogHandler_JSON(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCatalogHandler_JSON", "internal/handlers")
+
+	h := newTestRouter(t)
+
+	testCases := []struct {
+		name       string
+		target     string
+		wantStatus int
+	}{
+		{"Product detail", "/api/v1/products/" + testhelpers.FixtureProductID, http.StatusOK},
+		{"Missing product", "/api/v1/products/prod_missing", http.StatusNotFound},
+		{"Retailers", "/api/v1/retailers", http.StatusOK},
+		{"Retailer", "/api/v1/retailers/flipkart", http.StatusOK},
+		{"Missing retailer", "/api/v1/retailers/nowhere", http.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := get(h, tc.target)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantStatus, rec.Code)
+			if rec.Code != tc.wantStatus {
+				t.Errorf("Status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+				t.Errorf("Content-Type = %q", ct)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCatalogHandler_JSON", true)
+}
+
+func TestHALNegotiation(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestHALNegotiation", "internal/handlers")
+
+	h := newTestRouter(t)
+	id := testhelpers.FixtureProductID
+
+	testCases := []struct {
+		name      string
+		target    string
+		wantLinks []string
+		embedded  string
+	}{
+		{"Product", "/api/v1/products/" + id, []string{"self", "prices", "history", "compare", "retailers"}, ""},
+		{"Prices", "/api/v1/products/" + id + "/prices", []string{"self", "product", "history", "compare", "retailers", "alternate"}, ""},
+		{"History", "/api/v1/products/" + id + "/price-history?retailer_id=amazon", []string{"self", "prices", "retailer"}, ""},
+		{"Compare", "/api/v1/compare?ids=" + id, []string{"self"}, "comparisons"},
+		{"Deals", "/api/v1/deals", []string{"self"}, "deals"},
+		{"Retailers", "/api/v1/retailers", []string{"self"}, "retailers"},
+		{"Retailer", "/api/v1/retailers/amazon", []string{"self", "collection"}, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := getHAL(h, tc.target)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Status = %d, body = %s", rec.Code, rec.Body.String())
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/hal+json; charset=utf-8" {
+				t.Errorf("Content-Type = %q", ct)
+			}
+			if rec.Header().Get("Vary") != "Accept" {
+				t.Errorf("Vary = %q, want Accept", rec.Header().Get("Vary"))
+			}
+
+			var doc halLinks
+			if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+				t.Fatalf("Invalid HAL document: %v", err)
+			}
+			testhelpers.LogTestAssertion(logger, tc.name+" links", len(tc.wantLinks), len(doc.Links))
+			for _, rel := range tc.wantLinks {
+				if _, ok := doc.Links[rel]; !ok {
+					t.Errorf("Missing %q link in %v", rel, doc.Links)
+				}
+			}
+			if tc.embedded != "" {
+				if _, ok := doc.Embedded[tc.embedded]; !ok {
+					t.Errorf("Missing embedded %q", tc.embedded)
+				}
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Self link reflects the request")
+	var doc struct {
+		Links struct {
+			Self httpx.Link `json:"self"`
+		} `json:"_links"`
+	}
+	_ = json.NewDecoder(getHAL(h, "/api/v1/products/"+id+"/price-history?days=7").Body).Decode(&doc)
+	if doc.Links.Self.Href != "/api/v1/products/"+id+"/price-history?days=7" {
+		t.Errorf("Self = %q", doc.Links.Self.Href)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestHALNegotiation", true)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+	"github.com/yourusername/whey-price-compare/internal/services"
+)
+
+// DealHandler serves the scored deal listing.
+type DealHandler struct {
+	prices  *services.PriceService
+	presets *services.PresetService
+	logger  *zap.Logger
+}
+
+// NewDealHandler creates a DealHandler.
+func NewDealHandler(prices *services.PriceService, logger *zap.Logger) *DealHandler {
+	return &DealHandler{prices: prices, logger: logger}
+}
+
+// WithPresets lets signed-in users list deals with one of their filter
+// presets as ?preset=. It returns h.
+func (h *DealHandler) WithPresets(p *services.PresetService) *DealHandler {
+	h.presets = p
+	return h
+}
+
+// Register mounts the deal routes on mux.
+func (h *DealHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/deals", h.List)
+}
+
+// List serves the top deals (?category=, ?limit=, ?min_score=), highest
+// scoring first or ordered by ?sort=: price_per_serving, price_per_protein,
+// discount or deal_score. ?preset= applies the filters and sort of a filter
+// preset of the signed-in user, judging each deal's offer; ?sort= replaces
+// the preset's.
+func (h *DealHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := services.DealQuery{CategoryID: query.Get("category"), Sort: query.Get("sort")}
+
+	var err error
+	if limit := query.Get("limit"); limit != "" {
+		if q.Limit, err = strconv.Atoi(limit); err != nil {
+			httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, "limit must be an integer",
+				map[string]any{"received": limit})
+			return
+		}
+	}
+	if minScore := query.Get("min_score"); minScore != "" {
+		if q.MinScore, err = strconv.ParseFloat(minScore, 64); err != nil {
+			httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, "min_score must be a number",
+				map[string]any{"received": minScore})
+			return
+		}
+	}
+
+	preset, ok := requestPreset(w, r, h.presets, h.logger)
+	if !ok {
+		return
+	}
+	if preset != nil {
+		q.Filters, q.Sort = preset.Apply(domain.SearchFilters{}, q.Sort)
+	}
+
+	deals, err := h.prices.TopDeals(r.Context(), q)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	loc := i18n.FromContext(r.Context())
+	for i := range deals {
+		localizeOffer(loc, &deals[i].Offer)
+	}
+	if httpx.WantsHAL(w, r) {
+		embedded := make([]*httpx.Resource, 0, len(deals))
+		for _, d := range deals {
+			embedded = append(embedded, dealResource(d))
+		}
+		res := httpx.NewResource(map[string]any{"count": len(deals)}, r.URL.RequestURI()).Embed("deals", embedded)
+		httpx.WriteHAL(w, http.StatusOK, res)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{"deals": deals, "count": len(deals)})
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestDealHandler_List(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestDealHandler_List", "internal/handlers")
+
+	h := newTestRouter(t)
+
+	testCases := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantCount  int
+	}{
+		{"All deals", "/api/v1/deals", http.StatusOK, 2},
+		{"Limited", "/api/v1/deals?limit=1", http.StatusOK, 1},
+		{"Unknown category", "/api/v1/deals?category=casein", http.StatusOK, 0},
+		{"Bad limit", "/api/v1/deals?limit=many", http.StatusBadRequest, 0},
+		{"Limit out of range", "/api/v1/deals?limit=1000", http.StatusBadRequest, 0},
+		{"Bad min_score", "/api/v1/deals?min_score=high", http.StatusBadRequest, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := get(h, tc.target)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantStatus, rec.Code)
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("Status = %d, want %d, body = %s", rec.Code, tc.wantStatus, rec.Body.String())
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			var body struct {
+				Deals []domain.Deal `json:"deals"`
+				Count int           `json:"count"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("Failed to decode deals: %v", err)
+			}
+			if body.Count != tc.wantCount || len(body.Deals) != tc.wantCount {
+				t.Errorf("Got %d deals, want %d", len(body.Deals), tc.wantCount)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestDealHandler_List", true)
+}
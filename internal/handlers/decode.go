@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+)
+
+// decodeJSON strictly decodes a request body of at most maxBytes into v:
+// unknown fields and trailing data are rejected. On failure it writes the
+// error response and returns false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, maxBytes int64, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBytes))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err == nil && dec.More() {
+		err = errors.New("unexpected data after the JSON object")
+	}
+	if err == nil {
+		return true
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		httpx.WriteError(w, r, http.StatusRequestEntityTooLarge, httpx.CodeBadRequest, "Request body is too large",
+			map[string]any{"max_bytes": tooLarge.Limit})
+		return false
+	}
+	if errors.Is(err, io.EOF) {
+		err = errors.New("request body is empty")
+	}
+	httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, "Invalid JSON body: "+err.Error(), nil)
+	return false
+}
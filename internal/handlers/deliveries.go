@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+)
+
+const defaultDeliveryLimit = 100
+
+// DeliveryHandler lets administrators inspect notifications that failed to
+// deliver and replay dead-lettered ones. It is mounted under AdminPrefix.
+type DeliveryHandler struct {
+	dispatcher *notify.Dispatcher
+	logger     *zap.Logger
+}
+
+// NewDeliveryHandler creates a DeliveryHandler.
+func NewDeliveryHandler(dispatcher *notify.Dispatcher, logger *zap.Logger) *DeliveryHandler {
+	return &DeliveryHandler{dispatcher: dispatcher, logger: logger}
+}
+
+// Register mounts the failed delivery routes on mux.
+func (h *DeliveryHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/admin/notifications/failures", h.List)
+	mux.HandleFunc("GET /api/v1/admin/notifications/failures/{id}", h.Get)
+	mux.HandleFunc("POST /api/v1/admin/notifications/failures/{id}/replay", h.Replay)
+}
+
+// List serves failed deliveries, most recently updated first
+// (?status=dead|retrying, default dead; ?status=all; ?limit=).
+func (h *DeliveryHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	status := query.Get("status")
+	switch status {
+	case "":
+		status = domain.DeliveryDead
+	case "all":
+		status = ""
+	}
+	limit := defaultDeliveryLimit
+	if raw := query.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, "limit must be a positive integer",
+				map[string]any{"received": raw})
+			return
+		}
+		limit = n
+	}
+	failures, err := h.dispatcher.FailedDeliveries(r.Context(), status, limit)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	if failures == nil {
+		failures = []domain.FailedDelivery{}
+	}
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{"failures": failures, "count": len(failures)})
+}
+
+// Get serves one failed delivery.
+func (h *DeliveryHandler) Get(w http.ResponseWriter, r *http.Request) {
+	f, err := h.dispatcher.FailedDelivery(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, f)
+}
+
+// Replay schedules a failed delivery for the next dispatch with a fresh set
+// of attempts.
+func (h *DeliveryHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	f, err := h.dispatcher.Replay(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	h.logger.Info("Admin replayed notification delivery",
+		zap.String("operation", "ReplayNotification"),
+		zap.String("delivery_id", f.ID),
+		zap.String("principal", httpx.Principal(r.Context())),
+	)
+	httpx.WriteJSON(w, http.StatusAccepted, f)
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/middleware"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestDeliveryHandler(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestDeliveryHandler", "internal/handlers")
+
+	testhelpers.LogTestStep(logger, "arrange", "One dead-lettered and one retrying delivery, without the catalog admin")
+	store := memory.NewStore()
+	ctx := t.Context()
+	now := time.Now().UTC()
+	var dead domain.FailedDelivery
+	for _, status := range []string{domain.DeliveryDead, domain.DeliveryRetrying} {
+		f, err := store.Deliveries().SaveFailedDelivery(ctx, domain.FailedDelivery{
+			Notification:  domain.Notification{ID: "notif_1", Type: domain.NotificationPriceAlert, UserID: "user_1"},
+			Channel:       domain.ChannelEmail,
+			Status:        status,
+			Attempts:      5,
+			LastError:     "smtp: 421 try again later",
+			UpdatedAt:     now,
+			NextAttemptAt: now.Add(time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("SaveFailedDelivery: %v", err)
+		}
+		if status == domain.DeliveryDead {
+			dead = f
+		}
+	}
+	dispatcher := notify.NewDispatcher(notify.DefaultDispatcherConfig(), store.Notifications(), store.Users(), logger).
+		WithRetries(store.Deliveries(), notify.DefaultRetryPolicy())
+	auth := middleware.NewBearerAuth(middleware.BearerAuthConfig{Realm: "admin", Tokens: map[string]string{"ops": testAdminToken}}, logger)
+	h := NewRouter(Deps{Logger: logger, Batch: DefaultBatchConfig(), AdminAuth: auth.Handler, Deliveries: dispatcher})
+
+	testCases := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+		wantCount  int // -1 for responses that are not a list
+	}{
+		{"Dead letters by default", http.MethodGet, "/api/v1/admin/notifications/failures", http.StatusOK, 1},
+		{"All failures", http.MethodGet, "/api/v1/admin/notifications/failures?status=all", http.StatusOK, 2},
+		{"Limited", http.MethodGet, "/api/v1/admin/notifications/failures?status=all&limit=1", http.StatusOK, 1},
+		{"Bad limit", http.MethodGet, "/api/v1/admin/notifications/failures?limit=0", http.StatusBadRequest, -1},
+		{"Bad status", http.MethodGet, "/api/v1/admin/notifications/failures?status=sent", http.StatusBadRequest, -1},
+		{"One failure", http.MethodGet, "/api/v1/admin/notifications/failures/" + dead.ID, http.StatusOK, -1},
+		{"Unknown failure", http.MethodGet, "/api/v1/admin/notifications/failures/delivery_404", http.StatusNotFound, -1},
+		{"Replay", http.MethodPost, "/api/v1/admin/notifications/failures/" + dead.ID + "/replay", http.StatusAccepted, -1},
+		{"No dead letters after replay", http.MethodGet, "/api/v1/admin/notifications/failures", http.StatusOK, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := adminRequest(h, tc.method, tc.target, "")
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantStatus, rec.Code)
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("Status = %d, want %d: %s", rec.Code, tc.wantStatus, rec.Body)
+			}
+			if tc.wantCount < 0 {
+				return
+			}
+			var body struct {
+				Failures []domain.FailedDelivery `json:"failures"`
+				Count    int                     `json:"count"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Count != tc.wantCount || len(body.Failures) != tc.wantCount {
+				t.Errorf("Body = %s, want %d failures", rec.Body, tc.wantCount)
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Failures require the admin token")
+	if rec := sendAuth(h, http.MethodGet, "/api/v1/admin/notifications/failures", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Unauthenticated status = %d, want 401", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestDeliveryHandler", true)
+}
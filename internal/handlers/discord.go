@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/notify/discord"
+)
+
+const maxDiscordBodyBytes = 1 << 10
+
+// DiscordHandler manages the Discord webhook the signed-in user's alerts
+// are posted to.
+type DiscordHandler struct {
+	discord *discord.Sender
+	logger  *zap.Logger
+}
+
+// NewDiscordHandler creates a DiscordHandler.
+func NewDiscordHandler(sender *discord.Sender, logger *zap.Logger) *DiscordHandler {
+	return &DiscordHandler{discord: sender, logger: logger}
+}
+
+// Register mounts the Discord routes on mux. They all require a signed-in
+// user.
+func (h *DiscordHandler) Register(mux *http.ServeMux) {
+	mux.Handle("GET /api/v1/discord", auth.RequireUser(http.HandlerFunc(h.Status)))
+	mux.Handle("PUT /api/v1/discord", auth.RequireUser(http.HandlerFunc(h.Connect)))
+	mux.Handle("DELETE /api/v1/discord", auth.RequireUser(http.HandlerFunc(h.Disconnect)))
+}
+
+type discordStatusResponse struct {
+	Connected bool `json:"connected"`
+	*domain.DiscordWebhook
+}
+
+// Status returns the user's webhook, without its URL.
+func (h *DiscordHandler) Status(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	hook, err := h.discord.Webhook(r.Context(), u.ID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusOK, discordStatusResponse{Connected: hook != nil, DiscordWebhook: hook})
+}
+
+// Connect sets the webhook the user's alerts are posted to, after checking
+// it with Discord.
+func (h *DiscordHandler) Connect(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		WebhookURL string `json:"webhook_url"`
+	}
+	if !decodeJSON(w, r, maxDiscordBodyBytes, &in) {
+		return
+	}
+	u := auth.UserFromContext(r.Context())
+	hook, err := h.discord.Connect(r.Context(), u.ID, in.WebhookURL)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusOK, discordStatusResponse{Connected: true, DiscordWebhook: hook})
+}
+
+// Disconnect forgets the user's webhook.
+func (h *DiscordHandler) Disconnect(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	if err := h.discord.Disconnect(r.Context(), u.ID); err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+)
+
+const defaultEngagementDays = 30
+
+// pixelGIF is a transparent 1x1 GIF.
+var pixelGIF = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// EngagementHandler serves the pixel that records notification opens and
+// lets administrators see how each channel's messages are engaged with.
+type EngagementHandler struct {
+	engagement *notify.Engagement
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewEngagementHandler creates an EngagementHandler.
+func NewEngagementHandler(engagement *notify.Engagement, logger *zap.Logger) *EngagementHandler {
+	return &EngagementHandler{engagement: engagement, logger: logger, now: time.Now}
+}
+
+// Register mounts the open tracking pixel on mux.
+func (h *EngagementHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+notify.PixelPath, h.Pixel)
+}
+
+// RegisterAdmin mounts the engagement stats on mux, which must be mounted
+// under AdminPrefix.
+func (h *EngagementHandler) RegisterAdmin(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/admin/notifications/engagement", h.Stats)
+}
+
+// Pixel records an open and serves a transparent image. Bad tokens get
+// the image too, so a mail client never shows a broken one.
+func (h *EngagementHandler) Pixel(w http.ResponseWriter, r *http.Request) {
+	if err := h.engagement.Opened(r.Context(), r.URL.Query().Get(notify.TrackingParam)); err != nil {
+		h.logger.Debug("Ignoring notification open", zap.String("operation", "NotificationOpened"), zap.Error(err))
+	}
+	// Opens are counted per request; caches would hide them.
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "image/gif")
+	w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+	_, _ = w.Write(pixelGIF)
+}
+
+type engagementResponse struct {
+	Since    time.Time                  `json:"since"`
+	Channels []domain.ChannelEngagement `json:"channels"`
+}
+
+// Stats serves delivery, open and click-through counts and rates for
+// every channel over the last 30 days (?days=).
+func (h *EngagementHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	days := defaultEngagementDays
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, "days must be a positive integer",
+				map[string]any{"received": raw})
+			return
+		}
+		days = n
+	}
+	since := h.now().UTC().AddDate(0, 0, -days)
+	stats, err := h.engagement.Stats(r.Context(), since)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, engagementResponse{Since: since, Channels: stats})
+}
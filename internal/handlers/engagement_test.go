@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/middleware"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestEngagementHandler(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestEngagementHandler", "internal/handlers")
+
+	testhelpers.LogTestStep(logger, "arrange", "An email alert delivered with tracked links")
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	engagement := notify.NewEngagement(store.Engagement(), []byte("test-only-signing-secret"), logger)
+	auth := middleware.NewBearerAuth(middleware.BearerAuthConfig{Realm: "admin", Tokens: map[string]string{"ops": testAdminToken}}, logger)
+	h := NewRouter(Deps{
+		Logger: logger,
+		Batch:  DefaultBatchConfig(),
+		Redirects: services.NewRedirectService(services.RedirectRepos{
+			Retailers: store.Retailers(),
+			Listings:  store.Listings(),
+		}, nil, logger),
+		Clicks:     services.NewClickTracker(services.DefaultClickTrackerConfig(), store.Clicks(), logger),
+		AdminAuth:  auth.Handler,
+		Engagement: engagement,
+	})
+	n := domain.Notification{ID: "notif_1", URL: "/go/amazon/" + testhelpers.FixtureProductID}
+	engagement.Delivered(t.Context(), n, domain.ChannelEmail)
+	tagged := engagement.Tag(n, domain.ChannelEmail)
+
+	testhelpers.LogTestStep(logger, "act", "Opening the email twice, then following its link, with and without a valid token")
+	for _, target := range []string{tagged.Pixel, tagged.Pixel, notify.PixelPath + "?nt=forged"} {
+		rec := get(h, target)
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/gif" || !bytes.HasPrefix(rec.Body.Bytes(), []byte("GIF89a")) {
+			t.Errorf("GET %s = %d %q, want a GIF", target, rec.Code, rec.Header().Get("Content-Type"))
+		}
+		if rec.Header().Get("Cache-Control") != "no-store" {
+			t.Errorf("Pixel Cache-Control = %q, want no-store", rec.Header().Get("Cache-Control"))
+		}
+	}
+	for _, target := range []string{tagged.URL, n.URL + "?nt=forged"} {
+		if rec := get(h, target); rec.Code != http.StatusFound {
+			t.Errorf("GET %s = %d, want 302", target, rec.Code)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Stats count the open and click once")
+	testCases := []struct {
+		name       string
+		target     string
+		wantStatus int
+	}{
+		{"Default window", "/api/v1/admin/notifications/engagement", http.StatusOK},
+		{"Last week", "/api/v1/admin/notifications/engagement?days=7", http.StatusOK},
+		{"Bad days", "/api/v1/admin/notifications/engagement?days=-1", http.StatusBadRequest},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := adminRequest(h, http.MethodGet, tc.target, "")
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantStatus, rec.Code)
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("Status = %d, want %d: %s", rec.Code, tc.wantStatus, rec.Body)
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			var got engagementResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("Body %s: %v", rec.Body, err)
+			}
+			want := domain.ChannelEngagement{Channel: domain.ChannelEmail, Delivered: 1, Opened: 1, Clicked: 1, OpenRate: 1, ClickRate: 1}
+			if len(got.Channels) != len(domain.Channels) || got.Channels[0] != want {
+				t.Errorf("Channels = %+v, want email first as %+v", got.Channels, want)
+			}
+		})
+	}
+	if rec := get(h, "/api/v1/admin/notifications/engagement"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Unauthenticated status = %d, want 401", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestEngagementHandler", true)
+}
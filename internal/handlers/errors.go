@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+	"github.com/yourusername/whey-price-compare/internal/logctx"
+)
+
+// writeServiceError maps service errors onto the standard error envelope.
+// Unexpected errors are logged and reported as 500 without leaking details.
+func writeServiceError(w http.ResponseWriter, r *http.Request, logger *zap.Logger, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		httpx.WriteError(w, r, http.StatusNotFound, httpx.CodeNotFound, err.Error(), nil)
+	case errors.Is(err, domain.ErrInvalid):
+		httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, err.Error(), nil)
+	case errors.Is(err, domain.ErrConflict):
+		httpx.WriteError(w, r, http.StatusConflict, httpx.CodeConflict, err.Error(), nil)
+	case errors.Is(err, context.Canceled):
+		// Client went away; nothing useful to send.
+	case errors.Is(err, context.DeadlineExceeded):
+		httpx.WriteError(w, r, http.StatusGatewayTimeout, httpx.CodeGatewayTimeout,
+			i18n.FromContext(r.Context()).T(i18n.MsgTimeout), nil)
+	default:
+		logctx.Logger(r.Context(), logger).Error("Request failed",
+			zap.String("path", r.URL.Path),
+			zap.Error(err),
+		)
+		httpx.WriteError(w, r, http.StatusInternalServerError, httpx.CodeInternal,
+			i18n.FromContext(r.Context()).T(i18n.MsgInternalError), nil)
+	}
+}
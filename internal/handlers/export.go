@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// Response formats selectable with ?format=.
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+// csvFlushEvery bounds how many rows are buffered before being flushed to the
+// client, so long histories stream instead of accumulating in memory.
+const csvFlushEvery = 500
+
+// responseFormat reads the ?format= parameter, defaulting to JSON.
+func responseFormat(r *http.Request) (string, error) {
+	switch f := r.URL.Query().Get("format"); f {
+	case "", formatJSON:
+		return formatJSON, nil
+	case formatCSV:
+		return formatCSV, nil
+	default:
+		return "", fmt.Errorf("unsupported format %q, expected json or csv", f)
+	}
+}
+
+// csvStream writes CSV rows straight to the response, flushing periodically.
+type csvStream struct {
+	w    *csv.Writer
+	rows int
+}
+
+func newCSVStream(w http.ResponseWriter, filename string, header []string) (*csvStream, error) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	s := &csvStream{w: csv.NewWriter(w)}
+	return s, s.w.Write(header)
+}
+
+func (s *csvStream) write(record []string) error {
+	if err := s.w.Write(record); err != nil {
+		return err
+	}
+	s.rows++
+	if s.rows%csvFlushEvery == 0 {
+		s.w.Flush()
+		return s.w.Error()
+	}
+	return nil
+}
+
+func (s *csvStream) close() error {
+	s.w.Flush()
+	return s.w.Error()
+}
+
+var comparisonCSVHeader = []string{
+	"product_id", "product_name", "brand", "retailer_id", "retailer_name", "variant_id", "flavor",
+	"weight_grams", "price", "original_price", "discount_percent", "price_per_gram_protein",
+	"currency", "in_stock", "url", "last_updated",
+}
+
+func writeComparisonRows(s *csvStream, c *domain.Comparison) error {
+	for _, o := range c.Prices {
+		err := s.write([]string{
+			csvText(c.Product.ID), csvText(c.Product.Name), csvText(c.Product.Brand), csvText(o.RetailerID),
+			csvText(o.RetailerName), csvText(o.VariantID), csvText(o.Flavor),
+			strconv.Itoa(o.SizeGrams), formatMoney(o.Price), formatMoney(o.OriginalPrice),
+			formatMoney(o.DiscountPercent), strconv.FormatFloat(o.PricePerGramProtein, 'f', 3, 64),
+			csvText(o.Currency), strconv.FormatBool(o.InStock), csvText(o.URL), formatTime(o.LastUpdated),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var historyCSVHeader = []string{
+	"recorded_at", "product_id", "retailer_id", "variant_id", "listing_id", "price", "currency", "in_stock",
+}
+
+func writeHistoryRows(s *csvStream, h *domain.PriceHistory) error {
+	for _, p := range h.Points {
+		err := s.write([]string{
+			formatTime(p.RecordedAt), csvText(h.ProductID), csvText(p.RetailerID), csvText(p.VariantID),
+			csvText(p.ListingID), formatMoney(p.Price), csvText(p.Currency), strconv.FormatBool(p.InStock),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// csvText guards a text cell against formula injection: names and URLs
+// come from scraped pages, and a spreadsheet runs a cell starting with =,
+// +, -, @, tab or carriage return as a formula. Such cells get a leading
+// quote, which spreadsheets show as text. Numbers are formatted here and
+// left alone, so a negative price still reads as a number.
+func csvText(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+func formatMoney(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
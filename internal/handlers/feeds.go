@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+	"github.com/yourusername/whey-price-compare/internal/services"
+)
+
+// DealsFeedPath is where the RSS feed of top deals is served.
+const DealsFeedPath = "/feeds/deals.xml"
+
+// FeedConfig configures the deals feed.
+type FeedConfig struct {
+	// BaseURL is the public site origin used for item links, e.g.
+	// https://proteinprices.example.
+	BaseURL     string
+	Title       string
+	Description string
+	Limit       int
+	// TTL is how long a rendered feed is served before it is rebuilt.
+	TTL time.Duration
+}
+
+// DefaultFeedConfig returns the production defaults: 25 items, rebuilt hourly.
+func DefaultFeedConfig() FeedConfig {
+	return FeedConfig{
+		BaseURL:     "http://localhost:8080",
+		Title:       "Top Whey Protein Deals",
+		Description: "The best-scoring whey protein offers across Indian retailers, updated hourly.",
+		Limit:       25,
+		TTL:         time.Hour,
+	}
+}
+
+// FeedHandler serves an RSS 2.0 feed of the highest scoring deals. The feed
+// is rendered at most once per TTL and shared by all readers; if a rebuild
+// fails the previous copy keeps being served.
+type FeedHandler struct {
+	cfg    FeedConfig
+	prices *services.PriceService
+	logger *zap.Logger
+	now    func() time.Time
+
+	mu        sync.Mutex
+	body      []byte
+	builtAt   time.Time
+	expiresAt time.Time
+}
+
+// NewFeedHandler creates a FeedHandler.
+func NewFeedHandler(cfg FeedConfig, prices *services.PriceService, logger *zap.Logger) *FeedHandler {
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	if cfg.Limit <= 0 || cfg.Limit > services.MaxDealLimit {
+		cfg.Limit = DefaultFeedConfig().Limit
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultFeedConfig().TTL
+	}
+	return &FeedHandler{cfg: cfg, prices: prices, logger: logger, now: time.Now}
+}
+
+// Register mounts the feed routes on mux.
+func (h *FeedHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+DealsFeedPath, h.Deals)
+}
+
+// Deals serves the cached RSS document, honouring conditional requests.
+func (h *FeedHandler) Deals(w http.ResponseWriter, r *http.Request) {
+	body, builtAt, expiresAt, err := h.feed(r.Context())
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+
+	maxAge := max(0, int(expiresAt.Sub(h.now()).Seconds()))
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(maxAge))
+	w.Header().Set("ETag", fmt.Sprintf(`"%x"`, builtAt.UnixNano()))
+	http.ServeContent(w, r, "", builtAt, bytes.NewReader(body))
+}
+
+func (h *FeedHandler) feed(ctx context.Context) ([]byte, time.Time, time.Time, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	if h.body != nil && now.Before(h.expiresAt) {
+		return h.body, h.builtAt, h.expiresAt, nil
+	}
+
+	logger := h.logger.With(zap.String("operation", "BuildDealsFeed"))
+	body, err := h.build(ctx, now)
+	if err != nil {
+		if h.body == nil {
+			return nil, time.Time{}, time.Time{}, err
+		}
+		logger.Warn("Deals feed rebuild failed, serving previous copy",
+			zap.Time("built_at", h.builtAt),
+			zap.Error(err),
+		)
+		return h.body, h.builtAt, h.expiresAt, nil
+	}
+
+	// Last-Modified has second precision; truncate so conditional requests match.
+	h.body, h.builtAt, h.expiresAt = body, now.UTC().Truncate(time.Second), now.Add(h.cfg.TTL)
+	logger.Info("Deals feed built", zap.Int("bytes", len(body)))
+	return h.body, h.builtAt, h.expiresAt, nil
+}
+
+func (h *FeedHandler) build(ctx context.Context, now time.Time) ([]byte, error) {
+	deals, err := h.prices.TopDeals(ctx, services.DealQuery{Limit: h.cfg.Limit})
+	if err != nil {
+		return nil, err
+	}
+
+	ch := rssChannel{
+		Title:         h.cfg.Title,
+		Link:          h.cfg.BaseURL + "/",
+		Description:   h.cfg.Description,
+		Language:      "en-in",
+		LastBuildDate: now.UTC().Format(time.RFC1123Z),
+		TTL:           int(h.cfg.TTL.Minutes()),
+		AtomLink:      rssAtomLink{Href: h.cfg.BaseURL + DealsFeedPath, Rel: "self", Type: "application/rss+xml"},
+		Items:         make([]rssItem, 0, len(deals)),
+	}
+	for _, d := range deals {
+		ch.Items = append(ch.Items, h.item(d))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(rss{Version: "2.0", AtomNS: "http://www.w3.org/2005/Atom", Channel: ch}); err != nil {
+		return nil, fmt.Errorf("encode rss: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (h *FeedHandler) item(d domain.Deal) rssItem {
+	o := d.Offer
+	title := fmt.Sprintf("%s %s %s – %s at %s", d.Product.Brand, d.Product.Name, o.Flavor,
+		formatPrice(o.Currency, o.Price), o.RetailerName)
+	if o.DiscountPercent >= 1 {
+		title += fmt.Sprintf(" (%.0f%% off)", o.DiscountPercent)
+	}
+
+	var desc strings.Builder
+	fmt.Fprintf(&desc, "%s at %s", formatPrice(o.Currency, o.Price), o.RetailerName)
+	if o.OriginalPrice > o.Price {
+		fmt.Fprintf(&desc, " (MRP %s)", formatPrice(o.Currency, o.OriginalPrice))
+	}
+	desc.WriteString(".")
+	if d.BelowAvg > 0 {
+		fmt.Fprintf(&desc, " %.1f%% below its 30-day average of %s.", d.BelowAvg, formatPrice(o.Currency, d.Avg30d))
+	}
+	if d.Low30d > 0 && o.Price <= d.Low30d {
+		desc.WriteString(" Lowest price in 30 days.")
+	}
+	if o.PricePerGramProtein > 0 {
+		fmt.Fprintf(&desc, " %s per gram of protein.", formatPrice(o.Currency, o.PricePerGramProtein))
+	}
+	fmt.Fprintf(&desc, " Deal score %.0f/100.", d.Score)
+
+	pub := o.LastUpdated
+	if pub.IsZero() {
+		pub = h.now()
+	}
+	return rssItem{
+		Title:       title,
+		Link:        h.cfg.BaseURL + httpx.ProductPagePath(d.Product.ID),
+		Description: desc.String(),
+		Category:    d.Product.Brand,
+		// The GUID changes with the price so readers surface each new drop.
+		GUID:    rssGUID{Value: fmt.Sprintf("deal:%s:%s", o.ListingID, formatMoney(o.Price))},
+		PubDate: pub.UTC().Format(time.RFC1123Z),
+	}
+}
+
+// formatPrice renders an amount for the feed's en-IN audience.
+func formatPrice(currency string, v float64) string {
+	return i18n.Default().FormatPrice(currency, v)
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string      `xml:"title"`
+	Link          string      `xml:"link"`
+	Description   string      `xml:"description"`
+	Language      string      `xml:"language"`
+	LastBuildDate string      `xml:"lastBuildDate"`
+	TTL           int         `xml:"ttl"`
+	AtomLink      rssAtomLink `xml:"atom:link"`
+	Items         []rssItem   `xml:"item"`
+}
+
+type rssAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	Category    string  `xml:"category,omitempty"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
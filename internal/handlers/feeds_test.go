@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestFeedHandler_Deals(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestFeedHandler_Deals", "internal/handlers")
+
+	now := time.Now()
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, now)
+	prices := services.NewPriceService(services.PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+
+	cfg := DefaultFeedConfig()
+	cfg.BaseURL = "https://deals.example/"
+	h := NewFeedHandler(cfg, prices, logger)
+	clock := now
+	h.now = func() time.Time { return clock }
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	testhelpers.LogTestStep(logger, "act", "Fetching the feed")
+	rec := get(mux, DealsFeedPath)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/rss+xml; charset=utf-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "public, max-age=3600" {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+
+	var doc rss
+	if err := xml.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Invalid RSS: %v", err)
+	}
+	testhelpers.LogTestAssertion(logger, "feed items", 2, len(doc.Channel.Items))
+	if doc.Version != "2.0" || len(doc.Channel.Items) != 2 {
+		t.Fatalf("Feed = version %q with %d items", doc.Version, len(doc.Channel.Items))
+	}
+	top := doc.Channel.Items[0]
+	if top.Link != "https://deals.example/products/"+testhelpers.FixtureProductID {
+		t.Errorf("Item link = %q", top.Link)
+	}
+	if !strings.Contains(top.Title, "₹3,199") || !strings.Contains(top.Title, "Flipkart") {
+		t.Errorf("Item title = %q", top.Title)
+	}
+	if !strings.Contains(top.Description, "Lowest price in 30 days") {
+		t.Errorf("Item description = %q", top.Description)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Price drop within the hour is not visible yet")
+	store.AddPricePoint(domain.PricePoint{ListingID: testhelpers.FixtureListingAmazon, Price: 2899, InStock: true, RecordedAt: now})
+	first := rec.Body.String()
+	clock = now.Add(30 * time.Minute)
+	if rec := get(mux, DealsFeedPath); rec.Body.String() != first {
+		t.Error("Feed rebuilt before its TTL expired")
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Conditional request")
+	req := httptest.NewRequest(http.MethodGet, DealsFeedPath, nil)
+	req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+	cond := httptest.NewRecorder()
+	mux.ServeHTTP(cond, req)
+	if cond.Code != http.StatusNotModified {
+		t.Errorf("Conditional status = %d, want 304", cond.Code)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Feed is rebuilt after an hour")
+	clock = now.Add(61 * time.Minute)
+	rec = get(mux, DealsFeedPath)
+	if !strings.Contains(rec.Body.String(), "₹2,899") {
+		t.Error("Rebuilt feed does not include the new Amazon price")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestFeedHandler_Deals", true)
+}
+
+func TestFormatPrice(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestFormatPrice", "internal/handlers")
+
+	testCases := []struct {
+		currency string
+		amount   float64
+		expect   string
+	}{
+		{"INR", 3199, "₹3,199"},
+		{"INR", 123456.4, "₹1,23,456"},
+		{"INR", 999, "₹999"},
+		{"", 1.817, "₹1.82"},
+		{"USD", 59.99, "USD 59.99"},
+	}
+	for _, tc := range testCases {
+		got := formatPrice(tc.currency, tc.amount)
+		testhelpers.LogTestAssertion(logger, tc.expect, tc.expect, got)
+		if got != tc.expect {
+			t.Errorf("formatPrice(%q, %v) = %q, want %q", tc.currency, tc.amount, got, tc.expect)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestFormatPrice", true)
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/alerts"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+)
+
+const maxGuestAlertBodyBytes = 1 << 10
+
+// GuestAlertHandler lets visitors set price alerts with only an email
+// address, and serves the pages the emailed link opens. The signed token in
+// the link stands in for a session.
+type GuestAlertHandler struct {
+	alerts *alerts.Service
+	logger *zap.Logger
+}
+
+// NewGuestAlertHandler creates a GuestAlertHandler.
+func NewGuestAlertHandler(svc *alerts.Service, logger *zap.Logger) *GuestAlertHandler {
+	return &GuestAlertHandler{alerts: svc, logger: logger}
+}
+
+// Register mounts the guest alert routes on mux.
+func (h *GuestAlertHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v1/alerts/guest", h.Create)
+	mux.HandleFunc("GET /alerts/manage", h.Manage)
+	mux.HandleFunc("POST /alerts/manage/confirm", h.Confirm)
+	mux.HandleFunc("POST /alerts/manage/delete", h.Delete)
+}
+
+// Create sets a pending alert for an email address and emails it the
+// confirmation link. It takes the same conditions as the signed-in API. The
+// response is the same whether or not the address has an account.
+func (h *GuestAlertHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Email              string  `json:"email"`
+		ProductID          string  `json:"product_id"`
+		Condition          string  `json:"condition"`
+		TargetPrice        float64 `json:"target_price"`
+		TargetPricePerGram float64 `json:"target_price_per_gram_protein"`
+		DropPercent        float64 `json:"drop_percent"`
+		LowDays            int     `json:"low_days"`
+	}
+	if !decodeJSON(w, r, maxGuestAlertBodyBytes, &in) {
+		return
+	}
+	_, err := h.alerts.CreateGuest(r.Context(), in.Email, domain.PriceAlert{
+		ProductID:          in.ProductID,
+		Condition:          in.Condition,
+		TargetPrice:        in.TargetPrice,
+		TargetPricePerGram: in.TargetPricePerGram,
+		DropPercent:        in.DropPercent,
+		LowDays:            in.LowDays,
+	})
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, http.StatusAccepted, map[string]string{
+		"message": "Check your inbox for a link to turn on the alert.",
+	})
+}
+
+type guestAlertPage struct {
+	Token       string
+	ProductName string
+	Condition   string
+	Pending     bool
+	Confirmed   bool
+	Deleted     bool
+	Gone        bool
+	Expired     bool
+	Invalid     bool
+}
+
+// Manage shows the link's alert, offering to confirm it if pending and to
+// delete it otherwise. It changes nothing, since mail scanners follow
+// links.
+func (h *GuestAlertHandler) Manage(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	a, err := h.alerts.Managed(r.Context(), token)
+	if err != nil {
+		h.renderError(w, r, err)
+		return
+	}
+	h.render(w, http.StatusOK, alertPage(token, a))
+}
+
+// Confirm turns on the link's pending alert.
+func (h *GuestAlertHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	a, err := h.alerts.Confirm(r.Context(), token)
+	if err != nil {
+		h.renderError(w, r, err)
+		return
+	}
+	page := alertPage(token, a)
+	page.Confirmed = true
+	h.render(w, http.StatusOK, page)
+}
+
+// Delete removes the link's alert.
+func (h *GuestAlertHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.alerts.DeleteManaged(r.Context(), r.URL.Query().Get("token")); err != nil {
+		h.renderError(w, r, err)
+		return
+	}
+	h.render(w, http.StatusOK, guestAlertPage{Deleted: true})
+}
+
+func alertPage(token string, a *alerts.ManagedAlert) guestAlertPage {
+	name := a.ProductName
+	if name == "" {
+		name = "This product"
+	}
+	return guestAlertPage{Token: token, ProductName: name, Condition: alerts.Describe(a.PriceAlert), Pending: a.Pending}
+}
+
+func (h *GuestAlertHandler) renderError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, alerts.ErrBadManageToken):
+		h.render(w, http.StatusBadRequest, guestAlertPage{Invalid: true})
+	case errors.Is(err, domain.ErrNotFound):
+		h.render(w, http.StatusNotFound, guestAlertPage{Gone: true})
+	case errors.Is(err, domain.ErrInvalid):
+		h.render(w, http.StatusGone, guestAlertPage{Expired: true})
+	default:
+		writeServiceError(w, r, h.logger, err)
+	}
+}
+
+func (h *GuestAlertHandler) render(w http.ResponseWriter, status int, page guestAlertPage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, no-store")
+	// The token in the URL must not leak to other sites.
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	w.WriteHeader(status)
+	if err := guestAlertPageTemplate.Execute(w, page); err != nil {
+		h.logger.Error("Rendering alert page failed", zap.String("operation", "ManageAlert"), zap.Error(err))
+	}
+}
+
+var guestAlertPageTemplate = template.Must(template.New("alert").Parse(`<!doctype html>
+<html lang="en"><head><meta charset="utf-8"><meta name="viewport" content="width=device-width,initial-scale=1">
+<title>Price alert</title></head>
+<body><main>
+{{if .Invalid}}<h1>This link is not valid</h1>
+<p>Set the alert again to get a new link.</p>
+{{else if .Gone}}<h1>This alert no longer exists</h1>
+<p>It may have been deleted already.</p>
+{{else if .Expired}}<h1>This link has expired</h1>
+<p>Alerts that are not confirmed within two days are discarded. Set the alert again to get a new link.</p>
+{{else if .Deleted}}<h1>Alert deleted</h1>
+<p>We won't email you about this product any more.</p>
+{{else}}<h1>{{if .Confirmed}}Your alert is on{{else if .Pending}}Turn on this alert?{{else}}Your price alert{{end}}</h1>
+<p>{{.ProductName}}: we'll email you when it {{.Condition}}.</p>
+{{if .Pending}}<form method="post" action="/alerts/manage/confirm?token={{.Token}}"><button type="submit">Turn on alert</button></form>
+{{end}}<form method="post" action="/alerts/manage/delete?token={{.Token}}"><button type="submit">Delete alert</button></form>
+<p>Keep the email with this link to change the alert later.</p>
+{{end}}</main></body></html>
+`))
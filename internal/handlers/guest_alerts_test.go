@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/alerts"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/middleware"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+type lastLinkSender struct{ link string }
+
+func (s *lastLinkSender) SendAlertConfirmation(_ context.Context, _ domain.User, _, _, link string) error {
+	s.link = link
+	return nil
+}
+
+func TestGuestAlertHandler(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestGuestAlertHandler", "internal/handlers")
+
+	testhelpers.LogTestStep(logger, "arrange", "A seeded catalog and alerts open to guests, without accounts")
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	prices := services.NewPriceService(services.PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+	sender := &lastLinkSender{}
+	alertSvc := alerts.NewService(alerts.Repos{Alerts: store.Alerts(), Notifications: store.Notifications()}, prices, logger).
+		WithGuests(alerts.GuestConfig{Key: []byte("test-only-secret"), BaseURL: "https://wheyprices.example"}, store.Users(), sender)
+	h := NewRouter(Deps{Logger: logger, Alerts: alertSvc})
+
+	testhelpers.LogTestStep(logger, "act", "Setting an alert by email")
+	body := `{"email":"asha@example.com","product_id":"` + testhelpers.FixtureProductID + `","target_price":3000}`
+	rec := sendAuth(h, http.MethodPost, "/api/v1/alerts/guest", body)
+	testhelpers.LogTestAssertion(logger, "create status", http.StatusAccepted, rec.Code)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Create status = %d, want 202: %s", rec.Code, rec.Body)
+	}
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/alerts/guest", `{"email":"nobody","product_id":"`+testhelpers.FixtureProductID+`","target_price":3000}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Invalid email status = %d, want 400", rec.Code)
+	}
+	link, err := url.Parse(sender.link)
+	if err != nil || link.Path != "/alerts/manage" {
+		t.Fatalf("Link = %q", sender.link)
+	}
+	token := url.QueryEscape(link.Query().Get("token"))
+
+	testCases := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+		wantBody   string
+	}{
+		{"Forged link", http.MethodGet, "/alerts/manage?token=dXNlcl8x.AAAA", http.StatusBadRequest, "This link is not valid"},
+		{"Pending alert page", http.MethodGet, "/alerts/manage?token=" + token, http.StatusOK, "Turn on this alert?"},
+		{"Confirming", http.MethodPost, "/alerts/manage/confirm?token=" + token, http.StatusOK, "Your alert is on"},
+		{"Active alert page", http.MethodGet, "/alerts/manage?token=" + token, http.StatusOK, "Gold Standard 100% Whey: we'll email you when it drops to"},
+		{"Deleting", http.MethodPost, "/alerts/manage/delete?token=" + token, http.StatusOK, "Alert deleted"},
+		{"Deleted alert page", http.MethodGet, "/alerts/manage?token=" + token, http.StatusNotFound, "This alert no longer exists"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := sendAuth(h, tc.method, tc.target, "")
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantStatus, rec.Code)
+			if rec.Code != tc.wantStatus || !strings.Contains(rec.Body.String(), tc.wantBody) {
+				t.Fatalf("Status = %d, want %d containing %q:\n%s", rec.Code, tc.wantStatus, tc.wantBody, rec.Body)
+			}
+			if rec.Header().Get("Referrer-Policy") != "no-referrer" || rec.Header().Get("Cache-Control") != "private, no-store" {
+				t.Errorf("Headers = %v", rec.Header())
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Without guests the routes are not mounted")
+	plain := NewRouter(Deps{Logger: logger, Alerts: alerts.NewService(alerts.Repos{Alerts: store.Alerts(), Notifications: store.Notifications()}, prices, logger)})
+	if rec := sendAuth(plain, http.MethodPost, "/api/v1/alerts/guest", body); rec.Code == http.StatusAccepted {
+		t.Errorf("Guest create without guests status = %d", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestGuestAlertHandler", true)
+}
+
+type countingLinkSender struct{ sent int }
+
+func (s *countingLinkSender) SendAlertConfirmation(context.Context, domain.User, string, string, string) error {
+	s.sent++
+	return nil
+}
+
+func TestGuestAlertHandler_IdempotentRetry(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestGuestAlertHandler_IdempotentRetry", "internal/handlers")
+
+	testhelpers.LogTestStep(logger, "arrange", "Guest alerts behind the idempotency middleware")
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	prices := services.NewPriceService(services.PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+	sender := &countingLinkSender{}
+	alertSvc := alerts.NewService(alerts.Repos{Alerts: store.Alerts(), Notifications: store.Notifications()}, prices, logger).
+		WithGuests(alerts.GuestConfig{Key: []byte("test-only-secret"), BaseURL: "https://wheyprices.example"}, store.Users(), sender)
+	idempotency := middleware.NewIdempotency(middleware.DefaultIdempotencyConfig(), middleware.NewMemoryIdempotencyStore(), logger)
+	h := NewRouter(Deps{Logger: logger, Alerts: alertSvc, Idempotency: idempotency.Handler})
+
+	testhelpers.LogTestStep(logger, "act", "Setting an alert, then retrying it with the same key")
+	body := `{"email":"asha@example.com","product_id":"` + testhelpers.FixtureProductID + `","target_price":3000}`
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/alerts/guest", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.IdempotencyKeyHeader, "retry-1")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	first, retry := send(), send()
+
+	testhelpers.LogTestAssertion(logger, "confirmations sent", 1, sender.sent)
+	if first.Code != http.StatusAccepted || retry.Code != first.Code || retry.Body.String() != first.Body.String() {
+		t.Fatalf("Statuses = %d, %d; bodies %q, %q", first.Code, retry.Code, first.Body, retry.Body)
+	}
+	if sender.sent != 1 {
+		t.Errorf("Confirmations sent = %d, want 1", sender.sent)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestGuestAlertHandler_IdempotentRetry", true)
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+)
+
+// API resource paths used for HAL link relations.
+const apiBase = "/api/v1"
+
+func productPath(id string) string  { return apiBase + "/products/" + url.PathEscape(id) }
+func pricesPath(id string) string   { return productPath(id) + "/prices" }
+func historyPath(id string) string  { return productPath(id) + "/price-history" }
+func retailerPath(id string) string { return apiBase + "/retailers/" + url.PathEscape(id) }
+
+func comparePath(ids ...string) string {
+	return apiBase + "/compare?ids=" + url.QueryEscape(strings.Join(ids, ","))
+}
+
+func link(href string) httpx.Link { return httpx.Link{Href: href} }
+
+// addProductLinks adds the relations every product-scoped resource shares.
+func addProductLinks(res *httpx.Resource, productID string) *httpx.Resource {
+	return res.
+		Link("product", link(productPath(productID))).
+		Link("prices", link(pricesPath(productID))).
+		Link("history", httpx.Link{Href: historyPath(productID) + "{?days,retailer_id}", Templated: true}).
+		Link("compare", link(comparePath(productID)))
+}
+
+func comparisonResource(c *domain.Comparison, self string) *httpx.Resource {
+	res := addProductLinks(httpx.NewResource(c, self), c.Product.ID)
+	res.Link("alternate", httpx.Link{Href: pricesPath(c.Product.ID) + "?format=csv", Type: "text/csv"})
+	seen := make(map[string]bool)
+	for _, o := range c.Prices {
+		if seen[o.RetailerID] {
+			continue
+		}
+		seen[o.RetailerID] = true
+		res.AddLink("retailers", httpx.Link{Href: retailerPath(o.RetailerID), Name: o.RetailerID, Title: o.RetailerName})
+	}
+	return res
+}
+
+func historyResource(h *domain.PriceHistory, self string) *httpx.Resource {
+	res := addProductLinks(httpx.NewResource(h, self), h.ProductID)
+	if h.RetailerID != "" {
+		res.Link("retailer", link(retailerPath(h.RetailerID)))
+	}
+	return res
+}
+
+func productResource(d *domain.ProductDetail) *httpx.Resource {
+	res := addProductLinks(httpx.NewResource(d, productPath(d.Product.ID)), d.Product.ID)
+	for _, id := range d.RetailerIDs {
+		res.AddLink("retailers", httpx.Link{Href: retailerPath(id), Name: id})
+	}
+	return res
+}
+
+func retailerResource(r domain.Retailer) *httpx.Resource {
+	return httpx.NewResource(r, retailerPath(r.ID)).Link("collection", link(apiBase+"/retailers"))
+}
+
+func dealResource(d domain.Deal) *httpx.Resource {
+	res := addProductLinks(httpx.NewResource(d, pricesPath(d.Product.ID)), d.Product.ID)
+	return res.Link("retailer", link(retailerPath(d.Offer.RetailerID)))
+}
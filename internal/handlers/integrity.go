@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/services"
+)
+
+// IntegrityHandler lets administrators read the integrity checker's last
+// report and run the checks on demand. It is mounted under AdminPrefix.
+type IntegrityHandler struct {
+	checker *services.IntegrityChecker
+	logger  *zap.Logger
+}
+
+// NewIntegrityHandler creates an IntegrityHandler.
+func NewIntegrityHandler(checker *services.IntegrityChecker, logger *zap.Logger) *IntegrityHandler {
+	return &IntegrityHandler{checker: checker, logger: logger}
+}
+
+// Register mounts the integrity routes on mux.
+func (h *IntegrityHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/admin/integrity", h.Last)
+	mux.HandleFunc("POST /api/v1/admin/integrity/check", h.Check)
+}
+
+// Last serves the report of the last scheduled or requested run; 404 until
+// one has finished.
+func (h *IntegrityHandler) Last(w http.ResponseWriter, r *http.Request) {
+	report, err := h.checker.Last()
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, report)
+}
+
+// Check runs the checks now and serves their report. A check that fails
+// fails the request; the others' findings are still kept as the last
+// report.
+func (h *IntegrityHandler) Check(w http.ResponseWriter, r *http.Request) {
+	report, err := h.checker.Check(r.Context())
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, report)
+}
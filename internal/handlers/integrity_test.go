@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/middleware"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestIntegrityHandler(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestIntegrityHandler", "internal/handlers")
+
+	testhelpers.LogTestStep(logger, "arrange", "A variant whose product is missing, and no run yet")
+	store := memory.NewStore()
+	store.PutVariant(domain.Variant{ID: "v_orphan", ProductID: "p_missing", IsActive: true})
+	checker := services.NewIntegrityChecker(services.DefaultIntegrityConfig(), store.Integrity(), logger)
+	auth := middleware.NewBearerAuth(middleware.BearerAuthConfig{Realm: "admin", Tokens: map[string]string{"ops": testAdminToken}}, logger)
+	h := NewRouter(Deps{Logger: logger, Batch: DefaultBatchConfig(), AdminAuth: auth.Handler, Integrity: checker})
+
+	testhelpers.LogTestStep(logger, "act", "Reading the report before and after a requested run")
+	before := adminRequest(h, http.MethodGet, "/api/v1/admin/integrity", "")
+	run := adminRequest(h, http.MethodPost, "/api/v1/admin/integrity/check", "")
+	after := adminRequest(h, http.MethodGet, "/api/v1/admin/integrity", "")
+
+	testhelpers.LogTestStep(logger, "assert", "There is no report until the run, which finds the orphan")
+	testhelpers.LogTestAssertion(logger, "statuses", []int{404, 200, 200}, []int{before.Code, run.Code, after.Code})
+	if before.Code != http.StatusNotFound || run.Code != http.StatusOK || after.Code != http.StatusOK {
+		t.Fatalf("Statuses = %d, %d, %d; want 404, 200, 200", before.Code, run.Code, after.Code)
+	}
+	var report domain.IntegrityReport
+	if err := json.Unmarshal(after.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(report.Findings) != 1 || report.Findings[0].VariantID != "v_orphan" || report.Counts[domain.IntegrityOrphanVariant] != 1 {
+		t.Errorf("Report = %s", after.Body)
+	}
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/admin/integrity/check", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Unauthenticated status = %d, want 401", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestIntegrityHandler", true)
+}
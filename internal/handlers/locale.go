@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+)
+
+// localizeComparison fills the display strings of every offer for the
+// request's locale. Raw numeric fields are left untouched for machines.
+func localizeComparison(r *http.Request, c *domain.Comparison) {
+	loc := i18n.FromContext(r.Context())
+	for i := range c.Prices {
+		localizeOffer(loc, &c.Prices[i])
+	}
+	if c.BestDeal != nil {
+		localizeOffer(loc, c.BestDeal)
+	}
+}
+
+func localizeOffer(loc *i18n.Locale, o *domain.Offer) {
+	o.PriceDisplay = loc.FormatPrice(o.Currency, o.Price)
+	o.WeightDisplay = loc.FormatWeight(o.SizeGrams)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestLocalizedOffers(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestLocalizedOffers", "internal/handlers")
+
+	h := newTestRouter(t)
+
+	testCases := []struct {
+		locale     string
+		wantPrice  string
+		wantWeight string
+	}{
+		{"en-IN", "₹3,199", "2.27 kg"},
+		{"en-US", "₹3,199", "5 lb"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.locale, func(t *testing.T) {
+			loc, _ := i18n.Lookup(tc.locale)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/products/"+testhelpers.FixtureProductID+"/prices", nil)
+			req = req.WithContext(i18n.WithLocale(req.Context(), loc))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			var c domain.Comparison
+			if err := json.Unmarshal(rec.Body.Bytes(), &c); err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if c.BestDeal == nil {
+				t.Fatal("Expected a best deal")
+			}
+			testhelpers.LogTestAssertion(logger, tc.locale, tc.wantWeight, c.BestDeal.WeightDisplay)
+			if c.BestDeal.PriceDisplay != tc.wantPrice || c.BestDeal.WeightDisplay != tc.wantWeight {
+				t.Errorf("Display = %q / %q, want %q / %q", c.BestDeal.PriceDisplay, c.BestDeal.WeightDisplay, tc.wantPrice, tc.wantWeight)
+			}
+			if c.Prices[0].PriceDisplay == "" {
+				t.Error("Offers must carry display strings")
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestLocalizedOffers", true)
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/fragments"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+	"github.com/yourusername/whey-price-compare/internal/services"
+)
+
+// PageConfig configures the server-rendered pages.
+type PageConfig struct {
+	// BaseURL is the public origin canonical links point at.
+	BaseURL string
+	// HistoryDays is the span of the price history chart.
+	HistoryDays int
+}
+
+// DefaultPageConfig charts 90 days of history.
+func DefaultPageConfig() PageConfig {
+	return PageConfig{BaseURL: "http://localhost:8080", HistoryDays: 90}
+}
+
+// PageHandler serves the server-rendered comparison page. The page is
+// assembled per request around cached fragments, so it can carry
+// per-visitor content without giving up the cache for the expensive parts.
+type PageHandler struct {
+	cfg       PageConfig
+	prices    *services.PriceService
+	fragments *fragments.Cache
+	logger    *zap.Logger
+}
+
+// NewPageHandler creates a PageHandler. A nil fragment cache renders every
+// fragment on every request.
+func NewPageHandler(cfg PageConfig, prices *services.PriceService, frags *fragments.Cache, logger *zap.Logger) *PageHandler {
+	def := DefaultPageConfig()
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	if cfg.HistoryDays <= 0 || cfg.HistoryDays > services.MaxHistoryDays {
+		cfg.HistoryDays = def.HistoryDays
+	}
+	return &PageHandler{cfg: cfg, prices: prices, fragments: frags, logger: logger}
+}
+
+// Register mounts the page routes on mux.
+func (h *PageHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /compare/{productID}", h.Compare)
+}
+
+// Compare serves the comparison page for one product: the price table and
+// the price history chart, each a cached fragment.
+func (h *PageHandler) Compare(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	loc := i18n.FromContext(ctx)
+	productID := r.PathValue("productID")
+	comparison, err := h.prices.Compare(ctx, productID)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	localizeComparison(r, comparison)
+
+	table, err := h.fragments.Render(ctx, fragments.Key{
+		Name: "price-table", ProductID: productID, Version: fragments.Version(comparison), Variant: loc.Tag,
+	}, func(w io.Writer) error {
+		return priceTableTemplate.Execute(w, priceTableView(loc, comparison))
+	})
+	if err != nil {
+		writeServiceError(w, r, h.logger, fmt.Errorf("render price table: %w", err))
+		return
+	}
+
+	// The chart is secondary; a page without it beats no page.
+	var chart template.HTML
+	history, err := h.prices.History(ctx, productID, services.HistoryQuery{Days: h.cfg.HistoryDays})
+	if err == nil {
+		chart, err = h.fragments.Render(ctx, fragments.Key{
+			Name: "price-history", ProductID: productID, Version: historyVersion(history), Variant: loc.Tag,
+		}, func(w io.Writer) error {
+			return historyChartTemplate.Execute(w, historyChartView(loc, history))
+		})
+	}
+	if err != nil {
+		h.logger.Warn("Rendering comparison page without price history",
+			zap.String("operation", "ComparePage"),
+			zap.String("product_id", productID),
+			zap.Error(err),
+		)
+	}
+
+	var buf strings.Builder
+	err = comparePageTemplate.Execute(&buf, comparePageView{
+		Lang:         loc.Tag,
+		Title:        loc.T(i18n.MsgPageTitle, productName(comparison.Product)),
+		Name:         productName(comparison.Product),
+		Canonical:    h.cfg.BaseURL + httpx.ComparePagePath(productID),
+		PriceTable:   table,
+		HistoryChart: chart,
+	})
+	if err != nil {
+		writeServiceError(w, r, h.logger, fmt.Errorf("render comparison page: %w", err))
+		return
+	}
+	hdr := w.Header()
+	hdr.Set("Content-Type", "text/html; charset=utf-8")
+	hdr.Set("Cache-Control", "private, no-cache")
+	_, _ = io.WriteString(w, buf.String())
+}
+
+func productName(p domain.Product) string {
+	return strings.TrimSpace(p.Brand + " " + p.Name)
+}
+
+// historyVersion changes whenever a point enters or leaves the window or
+// the latest point changes.
+func historyVersion(h *domain.PriceHistory) string {
+	v := strconv.Itoa(h.Days) + "-" + strconv.Itoa(len(h.Points))
+	if n := len(h.Points); n > 0 {
+		last := h.Points[n-1]
+		v += "-" + strconv.FormatInt(last.RecordedAt.UnixNano(), 36) + "-" + strconv.FormatFloat(last.Price, 'f', -1, 64)
+	}
+	return v
+}
+
+type comparePageView struct {
+	Lang         string
+	Title        string
+	Name         string
+	Canonical    string
+	PriceTable   template.HTML
+	HistoryChart template.HTML
+}
+
+type priceRow struct {
+	Retailer string
+	Flavor   string
+	Pack     string
+	Price    string
+	PerGram  string
+	InStock  bool
+	Stock    string
+	BuyURL   string
+}
+
+type priceTable struct {
+	Retailer string
+	Pack     string
+	Price    string
+	PerGram  string
+	ViewDeal string
+	Rows     []priceRow
+}
+
+func priceTableView(loc *i18n.Locale, c *domain.Comparison) priceTable {
+	t := priceTable{
+		Retailer: loc.T(i18n.MsgPageRetailer),
+		Pack:     loc.T(i18n.MsgPagePack),
+		Price:    loc.T(i18n.MsgPagePrice),
+		PerGram:  loc.T(i18n.MsgPagePerGramProtein),
+		ViewDeal: loc.T(i18n.MsgWidgetViewDeal),
+	}
+	for _, o := range c.Prices {
+		row := priceRow{
+			Retailer: o.RetailerName,
+			Flavor:   o.Flavor,
+			Pack:     o.WeightDisplay,
+			Price:    o.PriceDisplay,
+			PerGram:  loc.FormatPrice(o.Currency, o.PricePerGramProtein),
+			InStock:  o.InStock,
+			BuyURL:   o.BuyURL,
+		}
+		if !o.InStock {
+			row.Stock = loc.T(i18n.MsgPageOutOfStock)
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
+
+// Chart geometry, in SVG user units.
+const (
+	chartWidth  = 600
+	chartHeight = 160
+	chartPad    = 8
+)
+
+type historyChart struct {
+	Caption string
+	Low     string
+	High    string
+	Points  string
+	Width   int
+	Height  int
+}
+
+// historyChartView plots the lowest price across retailers for each day.
+func historyChartView(loc *i18n.Locale, h *domain.PriceHistory) historyChart {
+	chart := historyChart{Caption: loc.T(i18n.MsgPageHistory, h.Days), Width: chartWidth, Height: chartHeight}
+	var days []time.Time
+	lowest := make(map[time.Time]float64)
+	currency := domain.DefaultCurrency
+	for _, p := range h.Points {
+		day := p.RecordedAt.UTC().Truncate(24 * time.Hour)
+		if low, ok := lowest[day]; !ok {
+			days = append(days, day)
+			lowest[day] = p.Price
+		} else if p.Price < low {
+			lowest[day] = p.Price
+		}
+		currency = p.Currency
+	}
+	if len(days) == 0 {
+		return chart
+	}
+	chart.Low = loc.FormatPrice(currency, h.Stats.MinPrice)
+	chart.High = loc.FormatPrice(currency, h.Stats.MaxPrice)
+
+	span := h.Stats.MaxPrice - h.Stats.MinPrice
+	points := make([]string, 0, len(days))
+	for i, day := range days {
+		x := float64(chartPad)
+		if len(days) > 1 {
+			x += float64(i) * float64(chartWidth-2*chartPad) / float64(len(days)-1)
+		}
+		y := float64(chartHeight) / 2
+		if span > 0 {
+			y = float64(chartPad) + (h.Stats.MaxPrice-lowest[day])/span*float64(chartHeight-2*chartPad)
+		}
+		points = append(points, strconv.FormatFloat(x, 'f', 1, 64)+","+strconv.FormatFloat(y, 'f', 1, 64))
+	}
+	chart.Points = strings.Join(points, " ")
+	return chart
+}
+
+var comparePageTemplate = template.Must(template.New("compare").Parse(`<!doctype html>
+<html lang="{{.Lang}}"><head><meta charset="utf-8"><meta name="viewport" content="width=device-width,initial-scale=1">
+<title>{{.Title}}</title><link rel="canonical" href="{{.Canonical}}"></head>
+<body><main><h1>{{.Name}}</h1>
+{{.PriceTable}}
+{{.HistoryChart}}
+</main></body></html>
+`))
+
+var priceTableTemplate = template.Must(template.New("price-table").Parse(`<table class="prices">
+<thead><tr><th>{{.Retailer}}</th><th>{{.Pack}}</th><th>{{.Price}}</th><th>{{.PerGram}}</th><th></th></tr></thead>
+<tbody>{{range .Rows}}<tr{{if not .InStock}} class="oos"{{end}}><td>{{.Retailer}}</td><td>{{.Pack}}{{with .Flavor}} · {{.}}{{end}}</td><td>{{.Price}}</td><td>{{.PerGram}}</td>
+<td>{{if .InStock}}<a href="{{.BuyURL}}" rel="sponsored noopener">{{$.ViewDeal}}</a>{{else}}{{.Stock}}{{end}}</td></tr>
+{{end}}</tbody></table>
+`))
+
+var historyChartTemplate = template.Must(template.New("price-history").Parse(`{{if .Points}}<figure class="history">
+<svg viewBox="0 0 {{.Width}} {{.Height}}" role="img" aria-label="{{.Caption}}"><polyline fill="none" stroke="#0a58ca" stroke-width="2" points="{{.Points}}"/></svg>
+<figcaption>{{.Caption}}: {{.Low}} – {{.High}}</figcaption></figure>
+{{end}}`))
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/cache"
+	"github.com/yourusername/whey-price-compare/internal/fragments"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestPageHandler_CompareAssemblesCachedFragments(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPageHandler_CompareAssemblesCachedFragments", "internal/handlers")
+
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	frags := fragments.New(cache.NewLRU(cache.DefaultLRUConfig()), time.Minute, logger)
+	cfg := DefaultPageConfig()
+	cfg.BaseURL = "https://proteinprices.example/"
+	h := NewRouter(Deps{
+		Logger:    logger,
+		Pages:     cfg,
+		Fragments: frags,
+		Prices: services.NewPriceService(services.PriceRepos{
+			Products:  store.Products(),
+			Retailers: store.Retailers(),
+			Listings:  store.Listings(),
+			Prices:    store.Prices(),
+		}, logger),
+	})
+	path := "/compare/" + testhelpers.FixtureProductID
+
+	testhelpers.LogTestStep(logger, "act", "Rendering the page twice")
+	first := get(h, path)
+	second := get(h, path)
+
+	testhelpers.LogTestStep(logger, "assert", "Page carries the price table and history chart")
+	testhelpers.LogTestAssertion(logger, "status", http.StatusOK, first.Code)
+	if first.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want 200", first.Code)
+	}
+	body := first.Body.String()
+	for _, want := range []string{
+		`<html lang="en-IN">`,
+		`<link rel="canonical" href="https://proteinprices.example/compare/prod_on_gsw">`,
+		"<th>Per g protein</th>",
+		"Flipkart",
+		"₹3,199",
+		`rel="sponsored noopener"`,
+		"<polyline",
+		"Lowest price, last 90 days",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Page missing %q", want)
+		}
+	}
+	if second.Body.String() != body {
+		t.Error("Page from cached fragments differs from the first render")
+	}
+	if cc := first.Header().Get("Cache-Control"); cc != "private, no-cache" {
+		t.Errorf("Cache-Control = %q, want private, no-cache", cc)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Second render reused both fragments")
+	stats := frags.Stats()
+	testhelpers.LogTestAssertion(logger, "fragment hits", int64(2), stats.Hits)
+	if stats.Hits != 2 || stats.Misses != 2 {
+		t.Errorf("Stats = %+v, want 2 hits and 2 misses", stats)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Rendering in Hindi")
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	hindi, _ := i18n.Lookup("hi-IN")
+	req = req.WithContext(i18n.WithLocale(req.Context(), hindi))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if !strings.Contains(rec.Body.String(), "<th>विक्रेता</th>") {
+		t.Error("Hindi page reused the English fragment")
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Requesting an unknown product")
+	if rec := get(h, "/compare/prod_missing"); rec.Code != http.StatusNotFound {
+		t.Errorf("Unknown product status = %d, want 404", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPageHandler_CompareAssemblesCachedFragments", true)
+}
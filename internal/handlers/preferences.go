@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"maps"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+)
+
+const maxPreferencesBodyBytes = 1 << 10
+
+// PreferencesHandler serves the signed-in user's notification preferences.
+type PreferencesHandler struct {
+	prefs  *notify.Preferences
+	logger *zap.Logger
+}
+
+// NewPreferencesHandler creates a PreferencesHandler.
+func NewPreferencesHandler(prefs *notify.Preferences, logger *zap.Logger) *PreferencesHandler {
+	return &PreferencesHandler{prefs: prefs, logger: logger}
+}
+
+// Register mounts the preference routes on mux. They all require a
+// signed-in user.
+func (h *PreferencesHandler) Register(mux *http.ServeMux) {
+	mux.Handle("GET /api/v1/notifications/preferences", auth.RequireUser(http.HandlerFunc(h.Get)))
+	mux.Handle("PUT /api/v1/notifications/preferences", auth.RequireUser(http.HandlerFunc(h.Put)))
+}
+
+// Get returns the user's preferences, or the defaults.
+func (h *PreferencesHandler) Get(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	prefs, err := h.prefs.Get(r.Context(), u.ID)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusOK, prefs)
+}
+
+// Put updates the user's preferences; fields, channels and topics left
+// out keep their values. An empty timezone reverts to the service's, and
+// quiet_hours and priority replace the whole window or order.
+func (h *PreferencesHandler) Put(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Frequency  string             `json:"frequency"`
+		Channels   map[string]bool    `json:"channels"`
+		Topics     map[string]bool    `json:"topics"`
+		Timezone   *string            `json:"timezone"`
+		QuietHours *domain.QuietHours `json:"quiet_hours"`
+		Fanout     string             `json:"fanout"`
+		Priority   []string           `json:"priority"`
+	}
+	if !decodeJSON(w, r, maxPreferencesBodyBytes, &in) {
+		return
+	}
+	u := auth.UserFromContext(r.Context())
+	prefs, err := h.prefs.Get(r.Context(), u.ID)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	if in.Frequency != "" {
+		prefs.Frequency = in.Frequency
+	}
+	maps.Copy(prefs.Channels, in.Channels)
+	maps.Copy(prefs.Topics, in.Topics)
+	if in.Timezone != nil {
+		prefs.Timezone = *in.Timezone
+	}
+	if in.QuietHours != nil {
+		prefs.QuietHours = *in.QuietHours
+	}
+	if in.Fanout != "" {
+		prefs.Fanout = in.Fanout
+	}
+	if in.Priority != nil {
+		prefs.Priority = in.Priority
+	}
+	if prefs, err = h.prefs.Save(r.Context(), prefs); err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusOK, prefs)
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestPreferencesHandler(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPreferencesHandler", "internal/handlers")
+
+	testhelpers.LogTestStep(logger, "arrange", "Accounts, preferences and a signed-in user")
+	cfg := auth.DefaultConfig()
+	cfg.Password = auth.PasswordParams{Time: 1, MemoryKiB: 64, Threads: 1, KeyLen: 32, SaltLen: 16}
+	store := memory.NewStore()
+	authSvc := auth.NewService(cfg, auth.Repos{
+		Users:         store.Users(),
+		Sessions:      store.Sessions(),
+		Verifications: store.Verifications(),
+	}, logger)
+	h := NewRouter(Deps{Logger: logger, Auth: authSvc, Preferences: notify.NewPreferences(store.Preferences())})
+
+	sendAuth(h, http.MethodPost, "/api/v1/auth/register", `{"email":"asha@example.com","password":"test-only-password"}`)
+	rec := sendAuth(h, http.MethodPost, "/api/v1/auth/login", `{"email":"asha@example.com","password":"test-only-password"}`)
+	session := rec.Result().Cookies()[0]
+
+	testCases := []struct {
+		name          string
+		method        string
+		body          string
+		signedOut     bool
+		wantStatus    int
+		wantFrequency string
+		wantEmailOff  bool
+		wantTimezone  string
+		wantQuiet     bool
+		wantFirst     string
+	}{
+		{name: "Signed out", method: http.MethodGet, signedOut: true, wantStatus: http.StatusUnauthorized},
+		{name: "Defaults", method: http.MethodGet, wantStatus: http.StatusOK, wantFrequency: domain.FrequencyInstant},
+		{name: "Daily digest", method: http.MethodPut, body: `{"frequency":"daily"}`, wantStatus: http.StatusOK, wantFrequency: domain.FrequencyDaily},
+		{name: "Unknown frequency", method: http.MethodPut, body: `{"frequency":"hourly"}`, wantStatus: http.StatusBadRequest},
+		{name: "Saved", method: http.MethodGet, wantStatus: http.StatusOK, wantFrequency: domain.FrequencyDaily},
+		{name: "Email off", method: http.MethodPut, body: `{"channels":{"email":false}}`, wantStatus: http.StatusOK, wantFrequency: domain.FrequencyDaily, wantEmailOff: true},
+		{name: "Unknown channel", method: http.MethodPut, body: `{"channels":{"fax":true}}`, wantStatus: http.StatusBadRequest},
+		{name: "Quiet hours", method: http.MethodPut, body: `{"timezone":"Asia/Kolkata","quiet_hours":{"enabled":true,"start":"22:00","end":"07:00"}}`, wantStatus: http.StatusOK, wantFrequency: domain.FrequencyDaily, wantEmailOff: true, wantTimezone: "Asia/Kolkata", wantQuiet: true},
+		{name: "Unknown timezone", method: http.MethodPut, body: `{"timezone":"Mars/Olympus"}`, wantStatus: http.StatusBadRequest},
+		{name: "Malformed quiet hours", method: http.MethodPut, body: `{"quiet_hours":{"enabled":true,"start":"10pm","end":"7am"}}`, wantStatus: http.StatusBadRequest},
+		{name: "Quiet hours kept", method: http.MethodPut, body: `{"frequency":"daily"}`, wantStatus: http.StatusOK, wantFrequency: domain.FrequencyDaily, wantEmailOff: true, wantTimezone: "Asia/Kolkata", wantQuiet: true},
+		{name: "Telegram first", method: http.MethodPut, body: `{"fanout":"first","priority":["telegram"]}`, wantStatus: http.StatusOK, wantFrequency: domain.FrequencyDaily, wantEmailOff: true, wantTimezone: "Asia/Kolkata", wantQuiet: true, wantFirst: domain.ChannelTelegram},
+		{name: "Unknown fanout", method: http.MethodPut, body: `{"fanout":"some"}`, wantStatus: http.StatusBadRequest},
+		{name: "Repeated priority", method: http.MethodPut, body: `{"priority":["sms","sms"]}`, wantStatus: http.StatusBadRequest},
+		{name: "Quiet hours off", method: http.MethodPut, body: `{"timezone":"","quiet_hours":{"enabled":false}}`, wantStatus: http.StatusOK, wantFrequency: domain.FrequencyDaily, wantEmailOff: true, wantFirst: domain.ChannelTelegram},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var cookies []*http.Cookie
+			if !tc.signedOut {
+				cookies = append(cookies, session)
+			}
+			rec := sendAuth(h, tc.method, "/api/v1/notifications/preferences", tc.body, cookies...)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantStatus, rec.Code)
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("Status = %d, want %d: %s", rec.Code, tc.wantStatus, rec.Body)
+			}
+			if tc.wantFrequency == "" {
+				return
+			}
+			var got domain.NotificationPreferences
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || got.Frequency != tc.wantFrequency {
+				t.Errorf("Body %s: %v, want frequency %q", rec.Body, err, tc.wantFrequency)
+			}
+			if got.ChannelEnabled(domain.ChannelEmail) == tc.wantEmailOff || !got.ChannelEnabled(domain.ChannelTelegram) {
+				t.Errorf("Channels = %v, want email off %v", got.Channels, tc.wantEmailOff)
+			}
+			if got.Fanout != domain.FanoutFirst || len(got.Priority) != len(domain.Channels) {
+				t.Errorf("Fanout %q, priority %v, want %q and every channel", got.Fanout, got.Priority, domain.FanoutFirst)
+			}
+			if tc.wantFirst != "" && got.Priority[0] != tc.wantFirst {
+				t.Errorf("Priority = %v, want %s first", got.Priority, tc.wantFirst)
+			}
+			if got.Timezone != tc.wantTimezone || got.QuietHours.Enabled != tc.wantQuiet {
+				t.Errorf("Timezone %q, quiet hours %+v, want %q and enabled %v", got.Timezone, got.QuietHours, tc.wantTimezone, tc.wantQuiet)
+			}
+			if cc := rec.Header().Get("Cache-Control"); cc != "private, no-store" {
+				t.Errorf("Cache-Control = %q", cc)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPreferencesHandler", true)
+}
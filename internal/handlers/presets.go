@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+	"github.com/yourusername/whey-price-compare/internal/services"
+)
+
+const maxPresetBodyBytes = 4 << 10
+
+// PresetHandler serves the signed-in user's filter presets.
+type PresetHandler struct {
+	presets *services.PresetService
+	logger  *zap.Logger
+}
+
+// NewPresetHandler creates a PresetHandler.
+func NewPresetHandler(svc *services.PresetService, logger *zap.Logger) *PresetHandler {
+	return &PresetHandler{presets: svc, logger: logger}
+}
+
+// Register mounts the preset routes on mux. They all require a signed-in
+// user.
+func (h *PresetHandler) Register(mux *http.ServeMux) {
+	mux.Handle("GET /api/v1/presets", auth.RequireUser(http.HandlerFunc(h.List)))
+	mux.Handle("POST /api/v1/presets", auth.RequireUser(http.HandlerFunc(h.Create)))
+	mux.Handle("GET /api/v1/presets/{id}", auth.RequireUser(http.HandlerFunc(h.Get)))
+	mux.Handle("PUT /api/v1/presets/{id}", auth.RequireUser(http.HandlerFunc(h.Update)))
+	mux.Handle("DELETE /api/v1/presets/{id}", auth.RequireUser(http.HandlerFunc(h.Delete)))
+}
+
+type presetsResponse struct {
+	Presets []domain.FilterPreset `json:"presets"`
+}
+
+// presetRequest is the body of Create and Update.
+type presetRequest struct {
+	Name    string               `json:"name"`
+	Filters domain.SearchFilters `json:"filters"`
+	Sort    string               `json:"sort"`
+}
+
+func (in presetRequest) preset() domain.FilterPreset {
+	return domain.FilterPreset{Name: in.Name, Filters: in.Filters, Sort: in.Sort}
+}
+
+// List returns the user's presets, oldest first.
+func (h *PresetHandler) List(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	list, err := h.presets.List(r.Context(), u.ID)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	if list == nil {
+		list = []domain.FilterPreset{}
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusOK, presetsResponse{Presets: list})
+}
+
+// Get returns one of the user's presets.
+func (h *PresetHandler) Get(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	p, err := h.presets.Get(r.Context(), u.ID, r.PathValue("id"))
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusOK, p)
+}
+
+// Create saves a preset. Its ID can then be passed as ?preset= to product
+// search and the deals listing.
+func (h *PresetHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var in presetRequest
+	if !decodeJSON(w, r, maxPresetBodyBytes, &in) {
+		return
+	}
+	u := auth.UserFromContext(r.Context())
+	p, err := h.presets.Create(r.Context(), u.ID, in.preset())
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusCreated, p)
+}
+
+// Update replaces a preset's name, filters and sort.
+func (h *PresetHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var in presetRequest
+	if !decodeJSON(w, r, maxPresetBodyBytes, &in) {
+		return
+	}
+	u := auth.UserFromContext(r.Context())
+	p, err := h.presets.Update(r.Context(), u.ID, r.PathValue("id"), in.preset())
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusOK, p)
+}
+
+// Delete removes one of the user's presets.
+func (h *PresetHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	if err := h.presets.Delete(r.Context(), u.ID, r.PathValue("id")); err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// requestPreset returns the signed-in user's preset named by ?preset=, or
+// nil without one. It writes the error response itself, returning false,
+// for anonymous requests and presets the user does not have.
+func requestPreset(w http.ResponseWriter, r *http.Request, presets *services.PresetService, logger *zap.Logger) (*domain.FilterPreset, bool) {
+	id := r.URL.Query().Get("preset")
+	if id == "" || presets == nil {
+		return nil, true
+	}
+	u := auth.UserFromContext(r.Context())
+	if u == nil {
+		httpx.WriteError(w, r, http.StatusUnauthorized, httpx.CodeUnauthorized,
+			i18n.FromContext(r.Context()).T(i18n.MsgSignInRequired), nil)
+		return nil, false
+	}
+	p, err := presets.Get(r.Context(), u.ID, id)
+	if err != nil {
+		writeServiceError(w, r, logger, err)
+		return nil, false
+	}
+	return p, true
+}
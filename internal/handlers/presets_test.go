@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/search"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestPresetHandler_Lifecycle(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPresetHandler_Lifecycle", "internal/handlers")
+
+	testhelpers.LogTestStep(logger, "arrange", "Accounts, a seeded catalog, search and a signed-in user")
+	cfg := auth.DefaultConfig()
+	cfg.Password = auth.PasswordParams{Time: 1, MemoryKiB: 64, Threads: 1, KeyLen: 32, SaltLen: 16}
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	authSvc := auth.NewService(cfg, auth.Repos{
+		Users:         store.Users(),
+		Sessions:      store.Sessions(),
+		Verifications: store.Verifications(),
+	}, logger)
+	prices := services.NewPriceService(services.PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+	h := NewRouter(Deps{
+		Logger:  logger,
+		Auth:    authSvc,
+		Prices:  prices,
+		Search:  search.NewService(store.ProductSearch(), prices, logger),
+		Presets: services.NewPresetService(store.FilterPresets(), logger),
+	})
+
+	sendAuth(h, http.MethodPost, "/api/v1/auth/register", `{"email":"asha@example.com","password":"test-only-password"}`)
+	rec := sendAuth(h, http.MethodPost, "/api/v1/auth/login", `{"email":"asha@example.com","password":"test-only-password"}`)
+	session := rec.Result().Cookies()[0]
+
+	testhelpers.LogTestStep(logger, "act", "Saving a MuscleBlaze preset, signed out then signed in")
+	body := `{"name":"MuscleBlaze by discount","filters":{"brands":["muscleblaze"]},"sort":"discount"}`
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/presets", body); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Signed-out create status = %d, want 401", rec.Code)
+	}
+	rec = sendAuth(h, http.MethodPost, "/api/v1/presets", body, session)
+	var preset struct {
+		ID      string `json:"id"`
+		Filters struct {
+			Brands []string `json:"brands"`
+		} `json:"filters"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &preset); err != nil || rec.Code != http.StatusCreated {
+		t.Fatalf("Create %d %s: %v", rec.Code, rec.Body, err)
+	}
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/presets", `{"name":"Bad","sort":"cheapest"}`, session); rec.Code != http.StatusBadRequest {
+		t.Errorf("Invalid create status = %d, want 400", rec.Code)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The preset narrows search and the deals listing")
+	rec = sendAuth(h, http.MethodGet, "/api/v1/products/search?q=whey&preset="+preset.ID, "", session)
+	var results struct {
+		TotalCount int    `json:"total_count"`
+		Sort       string `json:"sort"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("Search %d %s: %v", rec.Code, rec.Body, err)
+	}
+	testhelpers.LogTestAssertion(logger, "preset search total", 1, results.TotalCount)
+	if results.TotalCount != 1 || results.Sort != "discount" {
+		t.Errorf("Preset search = %+v, want one MuscleBlaze product by discount", results)
+	}
+	rec = sendAuth(h, http.MethodGet, "/api/v1/deals?preset="+preset.ID, "", session)
+	var deals struct {
+		Count int `json:"count"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &deals); err != nil || rec.Code != http.StatusOK || deals.Count != 1 {
+		t.Errorf("Preset deals %d %s: %v", rec.Code, rec.Body, err)
+	}
+	if rec := sendAuth(h, http.MethodGet, "/api/v1/deals?preset="+preset.ID, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Signed-out preset deals status = %d, want 401", rec.Code)
+	}
+	if rec := sendAuth(h, http.MethodGet, "/api/v1/deals?preset=preset_missing", "", session); rec.Code != http.StatusNotFound {
+		t.Errorf("Unknown preset status = %d, want 404", rec.Code)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Renaming, listing and deleting")
+	target := "/api/v1/presets/" + preset.ID
+	if rec := sendAuth(h, http.MethodPut, target, `{"name":"MuscleBlaze","filters":{"brands":["muscleblaze"]}}`, session); rec.Code != http.StatusOK {
+		t.Errorf("Update status = %d: %s", rec.Code, rec.Body)
+	}
+	rec = sendAuth(h, http.MethodGet, "/api/v1/presets", "", session)
+	var list presetsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list.Presets) != 1 || list.Presets[0].Name != "MuscleBlaze" {
+		t.Errorf("List %d %s: %v", rec.Code, rec.Body, err)
+	}
+	if rec := sendAuth(h, http.MethodDelete, target, "", session); rec.Code != http.StatusNoContent {
+		t.Errorf("Delete status = %d, want 204", rec.Code)
+	}
+	if rec := sendAuth(h, http.MethodGet, target, "", session); rec.Code != http.StatusNotFound {
+		t.Errorf("Get after delete status = %d, want 404", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPresetHandler_Lifecycle", true)
+}
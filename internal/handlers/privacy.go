@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/services"
+)
+
+const maxPrivacyBodyBytes = 1 << 10
+
+// PrivacyHandler lets the signed-in user export their data and delete
+// their account.
+type PrivacyHandler struct {
+	privacy    *services.PrivacyService
+	trustProxy bool
+	logger     *zap.Logger
+}
+
+// NewPrivacyHandler creates a PrivacyHandler.
+func NewPrivacyHandler(svc *services.PrivacyService, trustProxy bool, logger *zap.Logger) *PrivacyHandler {
+	return &PrivacyHandler{privacy: svc, trustProxy: trustProxy, logger: logger}
+}
+
+// Register mounts the data-rights routes on mux. They all require a
+// signed-in user.
+func (h *PrivacyHandler) Register(mux *http.ServeMux) {
+	mux.Handle("GET /api/v1/account/data-requests", auth.RequireUser(http.HandlerFunc(h.List)))
+	mux.Handle("POST /api/v1/account/export", auth.RequireUser(http.HandlerFunc(h.RequestExport)))
+	mux.Handle("GET /api/v1/account/export/{id}", auth.RequireUser(http.HandlerFunc(h.Download)))
+	mux.Handle("POST /api/v1/account/delete", auth.RequireUser(http.HandlerFunc(h.RequestDeletion)))
+}
+
+type dataRequestsResponse struct {
+	Requests []domain.DataRequest `json:"requests"`
+}
+
+// List returns the user's export and deletion requests, newest first.
+func (h *PrivacyHandler) List(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	reqs, err := h.privacy.Requests(r.Context(), u.ID)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	if reqs == nil {
+		reqs = []domain.DataRequest{}
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusOK, dataRequestsResponse{Requests: reqs})
+}
+
+// RequestExport queues an export; download it once the request is done.
+func (h *PrivacyHandler) RequestExport(w http.ResponseWriter, r *http.Request) {
+	req, err := h.privacy.RequestExport(r.Context(), requestActor(r, h.trustProxy))
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusAccepted, req)
+}
+
+// Download serves a finished export as a JSON file.
+func (h *PrivacyHandler) Download(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	data, err := h.privacy.Export(r.Context(), u.ID, r.PathValue("id"))
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="whey-price-compare-data.json"`)
+	w.Header().Set("Cache-Control", "private, no-store")
+	_, _ = w.Write(data)
+}
+
+// RequestDeletion queues the erasure of the user's account and everything
+// tied to it. The body must repeat the account's email address, so it is
+// not done by accident.
+func (h *PrivacyHandler) RequestDeletion(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		ConfirmEmail string `json:"confirm_email"`
+	}
+	if !decodeJSON(w, r, maxPrivacyBodyBytes, &in) {
+		return
+	}
+	u := auth.UserFromContext(r.Context())
+	if !strings.EqualFold(strings.TrimSpace(in.ConfirmEmail), u.Email) {
+		httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, "confirm_email must match the account's email address", nil)
+		return
+	}
+	req, err := h.privacy.RequestDeletion(r.Context(), requestActor(r, h.trustProxy))
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusAccepted, req)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestPrivacyHandler(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPrivacyHandler", "internal/handlers")
+
+	testhelpers.LogTestStep(logger, "arrange", "An account, the privacy service and a signed-in user")
+	cfg := auth.DefaultConfig()
+	cfg.Password = auth.PasswordParams{Time: 1, MemoryKiB: 64, Threads: 1, KeyLen: 32, SaltLen: 16}
+	store := memory.NewStore()
+	authSvc := auth.NewService(cfg, auth.Repos{
+		Users:         store.Users(),
+		Sessions:      store.Sessions(),
+		Verifications: store.Verifications(),
+	}, logger)
+	privacy := services.NewPrivacyService(services.DefaultPrivacyConfig(), services.PrivacyRepos{
+		Data:     store.UserData(),
+		Requests: store.DataRequests(),
+		Audit:    store.Audit(),
+	}, logger)
+	h := NewRouter(Deps{Logger: logger, Auth: authSvc, Privacy: privacy})
+
+	sendAuth(h, http.MethodPost, "/api/v1/auth/register", `{"email":"asha@example.com","password":"test-only-password"}`)
+	rec := sendAuth(h, http.MethodPost, "/api/v1/auth/login", `{"email":"asha@example.com","password":"test-only-password"}`)
+	session := rec.Result().Cookies()[0]
+
+	testhelpers.LogTestStep(logger, "act", "Requesting an export")
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/account/export", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Signed-out export status = %d, want 401", rec.Code)
+	}
+	rec = sendAuth(h, http.MethodPost, "/api/v1/account/export", "", session)
+	testhelpers.LogTestAssertion(logger, "export status", http.StatusAccepted, rec.Code)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Export status = %d: %s", rec.Code, rec.Body)
+	}
+	var req domain.DataRequest
+	if err := json.Unmarshal(rec.Body.Bytes(), &req); err != nil || req.ID == "" {
+		t.Fatalf("Export body %s: %v", rec.Body, err)
+	}
+	if rec := sendAuth(h, http.MethodGet, "/api/v1/account/export/"+req.ID, "", session); rec.Code != http.StatusConflict {
+		t.Errorf("Download before processing status = %d, want 409", rec.Code)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Processing and downloading the export")
+	privacy.Process(t.Context())
+	rec = sendAuth(h, http.MethodGet, "/api/v1/account/export/"+req.ID, "", session)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Download status = %d: %s", rec.Code, rec.Body)
+	}
+	var data domain.UserData
+	if err := json.Unmarshal(rec.Body.Bytes(), &data); err != nil || data.Account.Email != "asha@example.com" {
+		t.Errorf("Download body %s: %v", rec.Body, err)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd == "" {
+		t.Error("Download is not an attachment")
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "private, no-store" {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Requesting deletion")
+	testCases := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"Missing confirmation", `{}`, http.StatusBadRequest},
+		{"Wrong email", `{"confirm_email":"ravi@example.com"}`, http.StatusBadRequest},
+		{"Confirmed", `{"confirm_email":"Asha@Example.com"}`, http.StatusAccepted},
+		{"Already pending", `{"confirm_email":"asha@example.com"}`, http.StatusConflict},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := sendAuth(h, http.MethodPost, "/api/v1/account/delete", tc.body, session)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantStatus, rec.Code)
+			if rec.Code != tc.wantStatus {
+				t.Errorf("Status = %d, want %d: %s", rec.Code, tc.wantStatus, rec.Body)
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Listing shows both requests, and the session ends once deleted")
+	rec = sendAuth(h, http.MethodGet, "/api/v1/account/data-requests", "", session)
+	var list dataRequestsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list.Requests) != 2 || list.Requests[0].Kind != domain.DataRequestDelete {
+		t.Errorf("List body %s: %v", rec.Body, err)
+	}
+	privacy.Process(t.Context())
+	if rec := sendAuth(h, http.MethodGet, "/api/v1/account/data-requests", "", session); rec.Code != http.StatusUnauthorized {
+		t.Errorf("List after deletion status = %d, want 401", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPrivacyHandler", true)
+}
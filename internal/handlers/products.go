@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/logctx"
+	"github.com/yourusername/whey-price-compare/internal/services"
+)
+
+// maxCompareProducts caps how many products /compare accepts at once.
+const maxCompareProducts = 10
+
+// ProductHandler serves product price comparisons and histories.
+type ProductHandler struct {
+	prices *services.PriceService
+	logger *zap.Logger
+}
+
+// NewProductHandler creates a ProductHandler.
+func NewProductHandler(prices *services.PriceService, logger *zap.Logger) *ProductHandler {
+	return &ProductHandler{prices: prices, logger: logger}
+}
+
+// Register mounts the product routes on mux.
+func (h *ProductHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/products/{id}/prices", h.Prices)
+	mux.HandleFunc("GET /api/v1/products/{id}/price-history", h.PriceHistory)
+	mux.HandleFunc("GET /api/v1/compare", h.Compare)
+}
+
+// Prices serves the cross-retailer comparison for one product.
+func (h *ProductHandler) Prices(w http.ResponseWriter, r *http.Request) {
+	format, err := responseFormat(r)
+	if err != nil {
+		httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, err.Error(), nil)
+		return
+	}
+
+	productID := r.PathValue("id")
+	comparison, err := h.prices.Compare(r.Context(), productID)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	if r.URL.Query().Get("include_out_of_stock") != "true" {
+		comparison = inStockOnly(comparison)
+	}
+	localizeComparison(r, comparison)
+
+	if format == formatCSV {
+		h.streamCSV(w, r, productID+"-prices.csv", comparisonCSVHeader, func(s *csvStream) error {
+			return writeComparisonRows(s, comparison)
+		})
+		return
+	}
+	if httpx.WantsHAL(w, r) {
+		httpx.WriteHAL(w, http.StatusOK, comparisonResource(comparison, r.URL.RequestURI()))
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, comparison)
+}
+
+// PriceHistory serves the price series for one product.
+func (h *ProductHandler) PriceHistory(w http.ResponseWriter, r *http.Request) {
+	format, err := responseFormat(r)
+	if err != nil {
+		httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, err.Error(), nil)
+		return
+	}
+
+	q := services.HistoryQuery{RetailerID: r.URL.Query().Get("retailer_id")}
+	if days := r.URL.Query().Get("days"); days != "" {
+		q.Days, err = strconv.Atoi(days)
+		if err != nil {
+			httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, "days must be an integer",
+				map[string]any{"received": days})
+			return
+		}
+	}
+
+	productID := r.PathValue("id")
+	history, err := h.prices.History(r.Context(), productID, q)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+
+	if format == formatCSV {
+		h.streamCSV(w, r, productID+"-price-history.csv", historyCSVHeader, func(s *csvStream) error {
+			return writeHistoryRows(s, history)
+		})
+		return
+	}
+	if httpx.WantsHAL(w, r) {
+		httpx.WriteHAL(w, http.StatusOK, historyResource(history, r.URL.RequestURI()))
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, history)
+}
+
+// Compare serves side-by-side comparisons for several products
+// (?ids=a,b,c).
+func (h *ProductHandler) Compare(w http.ResponseWriter, r *http.Request) {
+	format, err := responseFormat(r)
+	if err != nil {
+		httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, err.Error(), nil)
+		return
+	}
+
+	ids := splitIDs(r.URL.Query().Get("ids"))
+	if len(ids) == 0 || len(ids) > maxCompareProducts {
+		httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest,
+			"ids must list between 1 and 10 product IDs", map[string]any{"received": len(ids)})
+		return
+	}
+
+	comparisons, err := h.prices.CompareMany(r.Context(), ids)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	for i, c := range comparisons {
+		c = inStockOnly(c)
+		localizeComparison(r, c)
+		comparisons[i] = c
+	}
+
+	if format == formatCSV {
+		h.streamCSV(w, r, "comparison.csv", comparisonCSVHeader, func(s *csvStream) error {
+			for _, c := range comparisons {
+				if err := writeComparisonRows(s, c); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return
+	}
+	if httpx.WantsHAL(w, r) {
+		embedded := make([]*httpx.Resource, 0, len(comparisons))
+		for _, c := range comparisons {
+			embedded = append(embedded, comparisonResource(c, pricesPath(c.Product.ID)))
+		}
+		res := httpx.NewResource(map[string]any{"count": len(comparisons)}, r.URL.RequestURI()).
+			Embed("comparisons", embedded)
+		httpx.WriteHAL(w, http.StatusOK, res)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, comparisonList(comparisons))
+}
+
+// comparisonList is the {"comparisons": [...]} body of Compare, encoded
+// without reflection.
+type comparisonList []*domain.Comparison
+
+// AppendJSON implements jsonx.Appender.
+func (l comparisonList) AppendJSON(b []byte) []byte {
+	b = append(b, `{"comparisons":`...)
+	if l == nil {
+		return append(b, "null}"...)
+	}
+	b = append(b, '[')
+	for i, c := range l {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = c.AppendJSON(b)
+	}
+	return append(b, ']', '}')
+}
+
+func (h *ProductHandler) streamCSV(w http.ResponseWriter, r *http.Request, filename string, header []string, rows func(*csvStream) error) {
+	s, err := newCSVStream(w, filename, header)
+	if err == nil {
+		err = rows(s)
+	}
+	if err == nil {
+		err = s.close()
+	}
+	if err != nil {
+		// Headers are already sent; all we can do is log the truncation.
+		logctx.Logger(r.Context(), h.logger).Warn("CSV export interrupted",
+			zap.String("path", r.URL.Path),
+			zap.Error(err),
+		)
+	}
+}
+
+// inStockOnly drops out-of-stock offers, recomputing the statistics.
+func inStockOnly(c *domain.Comparison) *domain.Comparison {
+	offers := make([]domain.Offer, 0, len(c.Prices))
+	for _, o := range c.Prices {
+		if o.InStock {
+			offers = append(offers, o)
+		}
+	}
+	if len(offers) == len(c.Prices) {
+		return c
+	}
+	return domain.NewComparison(c.Product, offers)
+}
+
+func splitIDs(raw string) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, id := range strings.Split(raw, ",") {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func newTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+	logger := testhelpers.SetupTestLogger(t)
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	prices := services.NewPriceService(services.PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+	catalog := services.NewCatalogService(services.CatalogRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+	}, logger)
+	return NewRouter(Deps{
+		Logger:  logger,
+		Batch:   DefaultBatchConfig(),
+		Feed:    DefaultFeedConfig(),
+		Catalog: catalog,
+		Prices:  prices,
+	})
+}
+
+func get(h http.Handler, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestProductHandler_PricesJSON(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestProductHandler_PricesJSON", "internal/handlers")
+
+	h := newTestRouter(t)
+
+	testhelpers.LogTestStep(logger, "act", "Fetching in-stock prices")
+	rec := get(h, "/api/v1/products/"+testhelpers.FixtureProductID+"/prices")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var c domain.Comparison
+	if err := json.NewDecoder(rec.Body).Decode(&c); err != nil {
+		t.Fatalf("Failed to decode comparison: %v", err)
+	}
+	testhelpers.LogTestAssertion(logger, "in-stock offers", 2, len(c.Prices))
+	if len(c.Prices) != 2 {
+		t.Errorf("Got %d offers, want 2 in-stock offers", len(c.Prices))
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Including out-of-stock offers")
+	rec = get(h, "/api/v1/products/"+testhelpers.FixtureProductID+"/prices?include_out_of_stock=true")
+	_ = json.NewDecoder(rec.Body).Decode(&c)
+	if len(c.Prices) != 3 {
+		t.Errorf("Got %d offers, want 3", len(c.Prices))
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Unknown product")
+	if rec := get(h, "/api/v1/products/prod_missing/prices"); rec.Code != http.StatusNotFound {
+		t.Errorf("Missing product status = %d, want 404", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestProductHandler_PricesJSON", true)
+}
+
+func TestProductHandler_CSVExport(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestProductHandler_CSVExport", "internal/handlers")
+
+	h := newTestRouter(t)
+
+	testCases := []struct {
+		name     string
+		target   string
+		filename string
+		header   []string
+		rows     int
+	}{
+		{"Comparison", "/api/v1/products/" + testhelpers.FixtureProductID + "/prices?format=csv&include_out_of_stock=true",
+			testhelpers.FixtureProductID + "-prices.csv", comparisonCSVHeader, 3},
+		{"History", "/api/v1/products/" + testhelpers.FixtureProductID + "/price-history?format=csv&retailer_id=amazon",
+			testhelpers.FixtureProductID + "-price-history.csv", historyCSVHeader, 7},
+		{"Multi-product compare", "/api/v1/compare?format=csv&ids=" + testhelpers.FixtureProductID + "," + testhelpers.FixtureSecondProductID,
+			"comparison.csv", comparisonCSVHeader, 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := get(h, tc.target)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Status = %d, body = %s", rec.Code, rec.Body.String())
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "text/csv; charset=utf-8" {
+				t.Errorf("Content-Type = %q", ct)
+			}
+			if cd := rec.Header().Get("Content-Disposition"); !strings.Contains(cd, tc.filename) {
+				t.Errorf("Content-Disposition = %q, want filename %s", cd, tc.filename)
+			}
+
+			records, err := csv.NewReader(rec.Body).ReadAll()
+			if err != nil {
+				t.Fatalf("Invalid CSV: %v", err)
+			}
+			testhelpers.LogTestAssertion(logger, tc.name+" rows", tc.rows, len(records)-1)
+			if strings.Join(records[0], ",") != strings.Join(tc.header, ",") {
+				t.Errorf("Header = %v", records[0])
+			}
+			if len(records)-1 != tc.rows {
+				t.Errorf("Got %d data rows, want %d", len(records)-1, tc.rows)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestProductHandler_CSVExport", true)
+}
+
+func TestWriteComparisonRows_EscapesFormulas(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestWriteComparisonRows_EscapesFormulas", "internal/handlers")
+
+	testhelpers.LogTestStep(logger, "arrange", "Scraped names that a spreadsheet would run as formulas")
+	c := &domain.Comparison{
+		Product: domain.Product{ID: "p1", Name: `=HYPERLINK("https://evil.example","Whey")`, Brand: "+Brand"},
+		Prices: []domain.Offer{{
+			RetailerID: "r1", RetailerName: "@Store", Flavor: "-Vanilla", URL: "\tshop", Price: 1999, Currency: "INR",
+		}},
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Writing the comparison as CSV")
+	rec := httptest.NewRecorder()
+	s, err := newCSVStream(rec, "comparison.csv", comparisonCSVHeader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeComparisonRows(s, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.close(); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %v", err)
+	}
+
+	row := records[1]
+	testCases := []struct {
+		column int
+		want   string
+	}{
+		{1, `'=HYPERLINK("https://evil.example","Whey")`},
+		{2, "'+Brand"},
+		{4, "'@Store"},
+		{6, "'-Vanilla"},
+		{8, "1999.00"},
+		{14, "'\tshop"},
+	}
+	for _, tc := range testCases {
+		testhelpers.LogTestAssertion(logger, comparisonCSVHeader[tc.column], tc.want, row[tc.column])
+		if row[tc.column] != tc.want {
+			t.Errorf("%s = %q, want %q", comparisonCSVHeader[tc.column], row[tc.column], tc.want)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestWriteComparisonRows_EscapesFormulas", true)
+}
+
+func TestProductHandler_BadRequests(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestProductHandler_BadRequests", "internal/handlers")
+
+	h := newTestRouter(t)
+	for _, target := range []string{
+		"/api/v1/products/" + testhelpers.FixtureProductID + "/prices?format=xml",
+		"/api/v1/products/" + testhelpers.FixtureProductID + "/price-history?days=abc",
+		"/api/v1/products/" + testhelpers.FixtureProductID + "/price-history?days=500",
+		"/api/v1/compare",
+	} {
+		rec := get(h, target)
+		testhelpers.LogTestAssertion(logger, target, http.StatusBadRequest, rec.Code)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s status = %d, want 400", target, rec.Code)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestProductHandler_BadRequests", true)
+}
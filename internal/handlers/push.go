@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/notify/webpush"
+)
+
+const maxPushBodyBytes = 4 << 10
+
+// PushHandler manages the signed-in user's browser push subscriptions.
+type PushHandler struct {
+	push   *webpush.Sender
+	logger *zap.Logger
+}
+
+// NewPushHandler creates a PushHandler.
+func NewPushHandler(push *webpush.Sender, logger *zap.Logger) *PushHandler {
+	return &PushHandler{push: push, logger: logger}
+}
+
+// Register mounts the push routes on mux. All but the public key require a
+// signed-in user.
+func (h *PushHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/push/key", h.Key)
+	mux.Handle("POST /api/v1/push/subscriptions", auth.RequireUser(http.HandlerFunc(h.Subscribe)))
+	mux.Handle("DELETE /api/v1/push/subscriptions/{id}", auth.RequireUser(http.HandlerFunc(h.Unsubscribe)))
+}
+
+type pushKeyResponse struct {
+	PublicKey string `json:"public_key"`
+}
+
+// Key returns the VAPID public key to pass to pushManager.subscribe.
+func (h *PushHandler) Key(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	httpx.WriteJSON(w, http.StatusOK, pushKeyResponse{PublicKey: h.push.PublicKey()})
+}
+
+// Subscribe stores the browser subscription in the body, in the shape
+// PushSubscription.toJSON produces.
+func (h *PushHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Endpoint string `json:"endpoint"`
+		Keys     struct {
+			P256DH string `json:"p256dh"`
+			Auth   string `json:"auth"`
+		} `json:"keys"`
+	}
+	if !decodeJSON(w, r, maxPushBodyBytes, &in) {
+		return
+	}
+	u := auth.UserFromContext(r.Context())
+	sub, err := h.push.Subscribe(r.Context(), u.ID, in.Endpoint, in.Keys.P256DH, in.Keys.Auth)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusCreated, sub)
+}
+
+// Unsubscribe removes one of the user's subscriptions.
+func (h *PushHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	if err := h.push.Unsubscribe(r.Context(), u.ID, r.PathValue("id")); err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/notify/webpush"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestPushHandler_Subscriptions(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPushHandler_Subscriptions", "internal/handlers")
+
+	testhelpers.LogTestStep(logger, "arrange", "Accounts, a push sender and a signed-in user")
+	cfg := auth.DefaultConfig()
+	cfg.Password = auth.PasswordParams{Time: 1, MemoryKiB: 64, Threads: 1, KeyLen: 32, SaltLen: 16}
+	store := memory.NewStore()
+	authSvc := auth.NewService(cfg, auth.Repos{
+		Users:         store.Users(),
+		Sessions:      store.Sessions(),
+		Verifications: store.Verifications(),
+	}, logger)
+	key, _, err := webpush.GenerateVAPIDKey()
+	if err != nil {
+		t.Fatalf("GenerateVAPIDKey: %v", err)
+	}
+	push := webpush.NewSender(webpush.Config{Subject: "mailto:alerts@example.com"}, key, store.PushSubscriptions(), logger)
+	h := NewRouter(Deps{Logger: logger, Auth: authSvc, Push: push})
+
+	sendAuth(h, http.MethodPost, "/api/v1/auth/register", `{"email":"asha@example.com","password":"test-only-password"}`)
+	rec := sendAuth(h, http.MethodPost, "/api/v1/auth/login", `{"email":"asha@example.com","password":"test-only-password"}`)
+	session := rec.Result().Cookies()[0]
+	// Keys from RFC 8291, Appendix A.
+	body := `{"endpoint":"https://fcm.googleapis.com/fcm/send/abc","keys":{` +
+		`"p256dh":"BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4",` +
+		`"auth":"BTBZMqHH6r4Tts7J_aSIgg"}}`
+
+	testhelpers.LogTestStep(logger, "act", "Fetching the key and subscribing")
+	rec = sendAuth(h, http.MethodGet, "/api/v1/push/key", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), key.PublicKey()) {
+		t.Errorf("Key = %d %s", rec.Code, rec.Body)
+	}
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/push/subscriptions", body); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Signed-out subscribe status = %d, want 401", rec.Code)
+	}
+	rec = sendAuth(h, http.MethodPost, "/api/v1/push/subscriptions", body, session)
+	testhelpers.LogTestAssertion(logger, "subscribe status", http.StatusCreated, rec.Code)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Subscribe status = %d: %s", rec.Code, rec.Body)
+	}
+	var sub map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &sub); err != nil || sub["id"] == "" {
+		t.Fatalf("Subscribe body %s: %v", rec.Body, err)
+	}
+	if _, leaked := sub["p256dh"]; leaked {
+		t.Error("Subscribe response echoes the keys")
+	}
+	bad := strings.Replace(body, "https://fcm.googleapis.com", "https://evil.example", 1)
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/push/subscriptions", bad, session); rec.Code != http.StatusBadRequest {
+		t.Errorf("Unknown push service status = %d, want 400", rec.Code)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Unsubscribing removes it once")
+	target := "/api/v1/push/subscriptions/" + sub["id"].(string)
+	if rec := sendAuth(h, http.MethodDelete, target, "", session); rec.Code != http.StatusNoContent {
+		t.Errorf("Unsubscribe status = %d, want 204", rec.Code)
+	}
+	if rec := sendAuth(h, http.MethodDelete, target, "", session); rec.Code != http.StatusNotFound {
+		t.Errorf("Second unsubscribe status = %d, want 404", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPushHandler_Subscriptions", true)
+}
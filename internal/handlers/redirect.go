@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+	"github.com/yourusername/whey-price-compare/internal/services"
+)
+
+// RedirectHandler sends shoppers to retailers through /go/ links so every
+// outbound click carries our affiliate tag and is counted.
+type RedirectHandler struct {
+	redirects  *services.RedirectService
+	clicks     *services.ClickTracker
+	trustProxy bool
+	salt       []byte
+	engagement *notify.Engagement
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewRedirectHandler creates a RedirectHandler. Visitor addresses are hashed
+// with a per-process salt, so stored hashes cannot be reversed by
+// enumerating the address space.
+func NewRedirectHandler(redirects *services.RedirectService, clicks *services.ClickTracker, trustProxy bool, logger *zap.Logger) *RedirectHandler {
+	salt := make([]byte, 16)
+	_, _ = rand.Read(salt)
+	return &RedirectHandler{
+		redirects:  redirects,
+		clicks:     clicks,
+		trustProxy: trustProxy,
+		salt:       salt,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// WithEngagement records click-throughs on links in notifications, which
+// carry a tracking token. It returns h.
+func (h *RedirectHandler) WithEngagement(e *notify.Engagement) *RedirectHandler {
+	h.engagement = e
+	return h
+}
+
+// Register mounts the redirect route on mux.
+func (h *RedirectHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /go/{retailer}/{productID}", h.Redirect)
+}
+
+// Redirect responds 302 to the retailer's product page (?listing= picks a
+// specific listing, ?ref= attributes the click to a referral).
+func (h *RedirectHandler) Redirect(w http.ResponseWriter, r *http.Request) {
+	retailerID, productID := r.PathValue("retailer"), r.PathValue("productID")
+	out, err := h.redirects.Resolve(r.Context(), retailerID, productID, r.URL.Query().Get("listing"))
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+
+	var userID string
+	if u := auth.UserFromContext(r.Context()); u != nil {
+		userID = u.ID
+	}
+	h.clicks.Track(domain.ClickEvent{
+		ProductID:  productID,
+		RetailerID: retailerID,
+		ListingID:  out.Listing.ID,
+		UserID:     userID,
+		Price:      out.Listing.CurrentPrice,
+		Referer:    r.Referer(),
+		Referral:   referral(r),
+		UserAgent:  r.UserAgent(),
+		IPHash:     h.hashIP(httpx.ClientIP(r, h.trustProxy)),
+		RequestID:  httpx.RequestID(r),
+		CreatedAt:  h.now().UTC(),
+	})
+	if token := r.URL.Query().Get(notify.TrackingParam); token != "" && h.engagement != nil {
+		// A bad token must not cost the shopper their redirect.
+		if err := h.engagement.Clicked(r.Context(), token); err != nil {
+			h.logger.Debug("Ignoring notification click", zap.String("operation", "Redirect"), zap.Error(err))
+		}
+	}
+
+	// Redirects are per-click; caching them would hide clicks from us.
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+	w.Header().Set("Referrer-Policy", "no-referrer-when-downgrade")
+	http.Redirect(w, r, out.URL, http.StatusFound)
+}
+
+// referral returns the known referral a link was tagged with, if any.
+// Unknown values are dropped so arbitrary strings are not stored.
+func referral(r *http.Request) string {
+	if ref := r.URL.Query().Get(httpx.ReferralParam); ref == domain.ReferralShare {
+		return ref
+	}
+	return ""
+}
+
+func (h *RedirectHandler) hashIP(ip string) string {
+	sum := sha256.Sum256(append(append([]byte{}, h.salt...), ip...))
+	return hex.EncodeToString(sum[:8])
+}
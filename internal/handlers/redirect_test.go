@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestRedirectHandler(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestRedirectHandler", "internal/handlers")
+
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	clicks := services.NewClickTracker(services.DefaultClickTrackerConfig(), store.Clicks(), logger)
+	h := NewRouter(Deps{
+		Logger: logger,
+		Redirects: services.NewRedirectService(services.RedirectRepos{
+			Retailers: store.Retailers(),
+			Listings:  store.Listings(),
+		}, map[string]services.AffiliateTag{"amazon": {Param: "tag", Value: "wheycompare-21"}}, logger),
+		Clicks: clicks,
+	})
+
+	testhelpers.LogTestStep(logger, "act", "Following tracked links")
+	req := httptest.NewRequest(http.MethodGet, "/go/amazon/"+testhelpers.FixtureProductID, nil)
+	req.Header.Set("Referer", "https://proteinprices.example/compare/prod_on_gsw")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	missing := get(h, "/go/amazon/prod_missing")
+
+	testhelpers.LogTestStep(logger, "assert", "302 to tagged retailer URL, 404 for unknown product")
+	testhelpers.LogTestAssertion(logger, "redirect status", http.StatusFound, rec.Code)
+	if rec.Code != http.StatusFound {
+		t.Fatalf("Status = %d, want 302", rec.Code)
+	}
+	if loc := rec.Header().Get("Location"); !strings.HasPrefix(loc, "https://www.amazon.in/dp/B000QSNYGI") || !strings.Contains(loc, "tag=wheycompare-21") {
+		t.Errorf("Location = %q", loc)
+	}
+	if rec.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", rec.Header().Get("Cache-Control"))
+	}
+	if missing.Code != http.StatusNotFound {
+		t.Errorf("Missing product status = %d, want 404", missing.Code)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Click recorded once the tracker flushes")
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	clicks.Run(ctx)
+	n, _ := store.Clicks().CountClicks(t.Context(), repositories.ClickFilter{RetailerID: "amazon"})
+	if n != 1 {
+		t.Errorf("Recorded %d clicks, want 1", n)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestRedirectHandler", true)
+}
@@ -0,0 +1,291 @@
+// Package handlers contains the HTTP handlers for the public API and the
+// router that mounts them.
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/alerts"
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/fragments"
+	"github.com/yourusername/whey-price-compare/internal/health"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+	"github.com/yourusername/whey-price-compare/internal/notify/discord"
+	"github.com/yourusername/whey-price-compare/internal/notify/email"
+	"github.com/yourusername/whey-price-compare/internal/notify/slack"
+	"github.com/yourusername/whey-price-compare/internal/notify/sms"
+	"github.com/yourusername/whey-price-compare/internal/notify/telegram"
+	"github.com/yourusername/whey-price-compare/internal/notify/templates"
+	"github.com/yourusername/whey-price-compare/internal/notify/webpush"
+	"github.com/yourusername/whey-price-compare/internal/search"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/sitemap"
+	"github.com/yourusername/whey-price-compare/internal/static"
+	"github.com/yourusername/whey-price-compare/internal/storage/blob"
+)
+
+// Deps bundles everything the handlers need. Optional dependencies may be
+// nil, in which case their routes are not mounted.
+type Deps struct {
+	Logger *zap.Logger
+	Batch  BatchConfig
+	Feed   FeedConfig
+	Widget WidgetConfig
+	Pages  PageConfig
+	// Fragments caches the rendered parts of pages; nil renders them on
+	// every request.
+	Fragments *fragments.Cache
+	Catalog   *services.CatalogService
+	Prices    *services.PriceService
+	Sitemap   *sitemap.Generator
+	Static    *static.Handler
+	// Media serves a local blob store's product images under /media/;
+	// buckets serve their own.
+	Media  *blob.Handler
+	Stats  *services.StatsService
+	Health *health.Checker
+	// Redirects and Clicks together enable the /go/ affiliate links.
+	Redirects *services.RedirectService
+	Clicks    *services.ClickTracker
+	// Admin routes are mounted only when both Admin and AdminAuth are set,
+	// so a missing authenticator can never expose them.
+	Admin     *services.AdminService
+	AdminAuth func(http.Handler) http.Handler
+	// TrustProxy takes audited client addresses from X-Forwarded-For.
+	TrustProxy bool
+	// Auth enables accounts; its session middleware then runs on every
+	// route.
+	Auth *auth.Service
+	// Alerts serves price alerts; it needs Auth for the signed-in user.
+	// With guests enabled, visitors can also set alerts by email alone.
+	Alerts *alerts.Service
+	// Bounces receives email bounce and complaint reports.
+	Bounces *email.BounceHandler
+	// Telegram links chats for alerts; it needs Auth for the signed-in user.
+	Telegram *telegram.Bot
+	// Push stores browser push subscriptions; it needs Auth for the
+	// signed-in user.
+	Push *webpush.Sender
+	// Watchlist serves users' watchlists; it needs Auth for the signed-in
+	// user.
+	Watchlist *services.WatchlistService
+	// Presets serves users' filter presets and applies them to search and
+	// the deals listing; it needs Auth for the signed-in user.
+	Presets *services.PresetService
+	// Preferences serves notification preferences: to the signed-in user
+	// with Auth, and to holders of unsubscribe links without.
+	Preferences *notify.Preferences
+	// Deliveries exposes failed notification deliveries to administrators;
+	// it needs AdminAuth.
+	Deliveries *notify.Dispatcher
+	// Integrity serves the data integrity checker's reports under
+	// AdminPrefix; it needs AdminAuth.
+	Integrity *services.IntegrityChecker
+	// SMS verifies numbers for SMS alerts; it needs Auth for the signed-in
+	// user.
+	SMS *sms.Sender
+	// Privacy handles data export and account deletion requests; it needs
+	// Auth for the signed-in user.
+	Privacy *services.PrivacyService
+	// AccountRateLimit limits each signed-in account and API token, inside
+	// Auth's session middleware so the caller is known. It also applies to
+	// every batch sub-request.
+	AccountRateLimit func(http.Handler) http.Handler
+	// RateLimit limits each client address. The server applies it around
+	// the router; the router applies it again to every batch sub-request,
+	// so each is charged to its own route's bucket like a direct call.
+	RateLimit func(http.Handler) http.Handler
+	// Idempotency replays the response to a write retried with the same
+	// Idempotency-Key, so a retried alert is not set twice. It runs inside
+	// Auth's session middleware so keys are scoped to the caller; routes
+	// under AdminPrefix skip it, as AdminAuth brings its own.
+	Idempotency func(http.Handler) http.Handler
+	// Discord connects webhooks for Discord alerts; it needs Auth for the
+	// signed-in user.
+	Discord *discord.Sender
+	// Slack connects webhooks for Slack alerts, which needs Auth for the
+	// signed-in user, and answers the /whey slash command when it has a
+	// signing secret.
+	Slack *slack.App
+	// Templates lists and previews notification templates under
+	// AdminPrefix; it needs AdminAuth.
+	Templates *templates.Engine
+	// Engagement records opens of and click-throughs on notifications,
+	// and serves per-channel engagement stats under AdminPrefix when
+	// AdminAuth is set.
+	Engagement *notify.Engagement
+	// Share and Shares together enable deal share links.
+	Share  ShareConfig
+	Shares *services.ShareService
+	// Search serves product search.
+	Search *search.Service
+	// Suggest serves search type-ahead.
+	Suggest *search.Suggester
+	// SearchAnalytics records clicks on search results, and serves the
+	// search report under AdminPrefix when AdminAuth is set.
+	SearchAnalytics *search.Analytics
+}
+
+// NewRouter builds the API router.
+func NewRouter(deps Deps) http.Handler {
+	mux := http.NewServeMux()
+
+	if deps.Auth != nil {
+		NewAuthHandler(deps.Auth, deps.Logger).Register(mux)
+	}
+	if deps.Auth != nil && deps.Auth.APITokensEnabled() {
+		NewTokenHandler(deps.Auth, deps.Logger).Register(mux)
+	}
+	if deps.Auth != nil && deps.Alerts != nil {
+		NewAlertHandler(deps.Alerts, deps.Logger).Register(mux)
+	}
+	if deps.Auth != nil && deps.Alerts != nil && deps.Alerts.SearchesEnabled() {
+		NewSavedSearchHandler(deps.Alerts, deps.Logger).Register(mux)
+	}
+	if deps.Alerts != nil && deps.Alerts.GuestsEnabled() {
+		NewGuestAlertHandler(deps.Alerts, deps.Logger).Register(mux)
+	}
+	if deps.Auth != nil && deps.Telegram != nil {
+		NewTelegramHandler(deps.Telegram, deps.Logger).Register(mux)
+	}
+	if deps.Auth != nil && deps.Push != nil {
+		NewPushHandler(deps.Push, deps.Logger).Register(mux)
+	}
+	if deps.Auth != nil && deps.Privacy != nil {
+		NewPrivacyHandler(deps.Privacy, deps.TrustProxy, deps.Logger).Register(mux)
+	}
+	if deps.Auth != nil && deps.SMS != nil {
+		NewSMSHandler(deps.SMS, deps.Logger).Register(mux)
+	}
+	if deps.Auth != nil && deps.Discord != nil {
+		NewDiscordHandler(deps.Discord, deps.Logger).Register(mux)
+	}
+	if deps.Auth != nil && deps.Slack != nil {
+		NewSlackHandler(deps.Slack, deps.Logger).Register(mux)
+	}
+	if deps.Auth != nil && deps.Watchlist != nil {
+		NewWatchlistHandler(deps.Watchlist, deps.Logger).Register(mux)
+	}
+	var presets *services.PresetService
+	if deps.Auth != nil && deps.Presets != nil {
+		presets = deps.Presets
+		NewPresetHandler(presets, deps.Logger).Register(mux)
+	}
+	if deps.Auth != nil && deps.Preferences != nil {
+		NewPreferencesHandler(deps.Preferences, deps.Logger).Register(mux)
+	}
+	if deps.Preferences != nil {
+		NewUnsubscribeHandler(deps.Preferences, deps.Logger).Register(mux)
+	}
+	if deps.Catalog != nil {
+		NewCatalogHandler(deps.Catalog, deps.Logger).Register(mux)
+	}
+	if deps.Prices != nil {
+		NewProductHandler(deps.Prices, deps.Logger).Register(mux)
+		NewDealHandler(deps.Prices, deps.Logger).WithPresets(presets).Register(mux)
+		NewFeedHandler(deps.Feed, deps.Prices, deps.Logger).Register(mux)
+		NewWidgetHandler(deps.Widget, deps.Prices, deps.Logger).Register(mux)
+		NewPageHandler(deps.Pages, deps.Prices, deps.Fragments, deps.Logger).Register(mux)
+	}
+	if deps.Search != nil {
+		NewSearchHandler(deps.Search, deps.Logger).WithPresets(presets).Register(mux)
+	}
+	if deps.Suggest != nil {
+		NewSuggestHandler(deps.Suggest, deps.Logger).Register(mux)
+	}
+	if deps.SearchAnalytics != nil {
+		NewSearchAnalyticsHandler(deps.SearchAnalytics, deps.Logger).Register(mux)
+	}
+	if deps.Shares != nil {
+		NewShareHandler(deps.Share, deps.Shares, deps.Logger).Register(mux)
+	}
+	if deps.Stats != nil {
+		NewStatsHandler(deps.Stats, deps.Logger).Register(mux)
+	}
+	if deps.Redirects != nil && deps.Clicks != nil {
+		redirects := NewRedirectHandler(deps.Redirects, deps.Clicks, deps.TrustProxy, deps.Logger)
+		if deps.Engagement != nil {
+			redirects.WithEngagement(deps.Engagement)
+		}
+		redirects.Register(mux)
+	}
+	if deps.Health != nil {
+		deps.Health.Register(mux)
+	}
+	if deps.Sitemap != nil {
+		deps.Sitemap.Register(mux)
+	}
+	if deps.Static != nil {
+		deps.Static.Register(mux)
+	}
+	if deps.Media != nil {
+		deps.Media.Register(mux)
+	}
+	if deps.Bounces != nil {
+		deps.Bounces.Register(mux)
+	}
+	if deps.Slack != nil && deps.Slack.CommandsEnabled() {
+		deps.Slack.Register(mux)
+	}
+	if deps.Engagement != nil {
+		NewEngagementHandler(deps.Engagement, deps.Logger).Register(mux)
+	}
+	if deps.AdminAuth != nil && (deps.Admin != nil || deps.Deliveries != nil || deps.Alerts != nil || deps.Templates != nil || deps.Engagement != nil || deps.SearchAnalytics != nil || deps.Integrity != nil) {
+		admin := http.NewServeMux()
+		if deps.Admin != nil {
+			NewAdminHandler(deps.Admin, deps.TrustProxy, deps.Logger).Register(admin)
+		}
+		if deps.Deliveries != nil {
+			NewDeliveryHandler(deps.Deliveries, deps.Logger).Register(admin)
+		}
+		if deps.Alerts != nil {
+			NewAlertHandler(deps.Alerts, deps.Logger).RegisterAdmin(admin)
+		}
+		if deps.Templates != nil {
+			NewTemplateHandler(deps.Templates, deps.Logger).Register(admin)
+		}
+		if deps.Engagement != nil {
+			NewEngagementHandler(deps.Engagement, deps.Logger).RegisterAdmin(admin)
+		}
+		if deps.SearchAnalytics != nil {
+			NewSearchAnalyticsHandler(deps.SearchAnalytics, deps.Logger).RegisterAdmin(admin)
+		}
+		if deps.Integrity != nil {
+			NewIntegrityHandler(deps.Integrity, deps.Logger).Register(admin)
+		}
+		mux.Handle(AdminPrefix, deps.AdminAuth(httpx.RecordRoute(admin)))
+	}
+	h := httpx.RecordRoute(mux)
+	if deps.Idempotency != nil {
+		h = exceptAdmin(deps.Idempotency, h)
+	}
+	if deps.AccountRateLimit != nil {
+		h = deps.AccountRateLimit(h)
+	}
+	sub := h
+	if deps.RateLimit != nil {
+		sub = deps.RateLimit(h)
+	}
+	mux.Handle("POST "+BatchPath, NewBatchHandler(deps.Batch, sub, deps.Logger))
+
+	if deps.Auth != nil {
+		return deps.Auth.Handler(h)
+	}
+	return h
+}
+
+// exceptAdmin applies mw to h for every request outside AdminPrefix.
+func exceptAdmin(mw func(http.Handler) http.Handler, h http.Handler) http.Handler {
+	wrapped := mw(h)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, AdminPrefix) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		wrapped.ServeHTTP(w, r)
+	})
+}
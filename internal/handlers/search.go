@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+	"github.com/yourusername/whey-price-compare/internal/search"
+	"github.com/yourusername/whey-price-compare/internal/services"
+)
+
+// SearchHandler serves product search.
+type SearchHandler struct {
+	search  *search.Service
+	presets *services.PresetService
+	logger  *zap.Logger
+}
+
+// NewSearchHandler creates a SearchHandler.
+func NewSearchHandler(svc *search.Service, logger *zap.Logger) *SearchHandler {
+	return &SearchHandler{search: svc, logger: logger}
+}
+
+// WithPresets lets signed-in users search with one of their filter presets
+// as ?preset=. It returns h.
+func (h *SearchHandler) WithPresets(p *services.PresetService) *SearchHandler {
+	h.presets = p
+	return h
+}
+
+// Register mounts the search routes on mux.
+func (h *SearchHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/products/search", h.Search)
+}
+
+// Search serves a page of products matching ?q= (?page=, ?per_page=) with
+// facet counts. ?brand=, ?category=, ?weight= (grams), ?retailer= and
+// ?dietary= take comma-separated or repeated values; a product matches a
+// facet if it has any of them, or all of them for ?dietary=. ?sort= orders
+// every match before paging: relevance (the default), price_per_serving,
+// price_per_protein, discount or deal_score. ?preset= applies a filter
+// preset of the signed-in user; filters and a sort given alongside replace
+// its own.
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := search.Query{
+		Text: query.Get("q"),
+		Sort: query.Get("sort"),
+		Filters: domain.SearchFilters{
+			BrandIDs:    splitIDs(strings.Join(query["brand"], ",")),
+			CategoryIDs: splitIDs(strings.Join(query["category"], ",")),
+			RetailerIDs: splitIDs(strings.Join(query["retailer"], ",")),
+			Dietary:     splitIDs(strings.Join(query["dietary"], ",")),
+		},
+	}
+	for _, raw := range splitIDs(strings.Join(query["weight"], ",")) {
+		g, err := strconv.Atoi(raw)
+		if err != nil {
+			httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, "weight must be a number of grams",
+				map[string]any{"received": raw})
+			return
+		}
+		q.Filters.Weights = append(q.Filters.Weights, g)
+	}
+	for param, dst := range map[string]*int{"page": &q.Page, "per_page": &q.PerPage} {
+		raw := query.Get(param)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, param+" must be an integer",
+				map[string]any{"received": raw})
+			return
+		}
+		*dst = n
+	}
+	preset, ok := requestPreset(w, r, h.presets, h.logger)
+	if !ok {
+		return
+	}
+	if preset != nil {
+		q.Filters, q.Sort = preset.Apply(q.Filters, q.Sort)
+	}
+
+	results, err := h.search.Search(r.Context(), q)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	loc := i18n.FromContext(r.Context())
+	for i := range results.Products {
+		if best := results.Products[i].BestDeal; best != nil {
+			localizeOffer(loc, best)
+		}
+	}
+	for i, f := range results.Facets.Weights {
+		if g, err := strconv.Atoi(f.Value); err == nil {
+			results.Facets.Weights[i].Label = loc.FormatWeight(g)
+		}
+	}
+	httpx.WriteJSON(w, http.StatusOK, results)
+}
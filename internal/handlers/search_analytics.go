@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/search"
+)
+
+const (
+	defaultSearchReportDays = 30
+	maxSearchClickBytes     = 1 << 10
+)
+
+// SearchAnalyticsHandler records which search results are opened and lets
+// administrators see what is searched for and what finds nothing.
+type SearchAnalyticsHandler struct {
+	analytics *search.Analytics
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewSearchAnalyticsHandler creates a SearchAnalyticsHandler.
+func NewSearchAnalyticsHandler(a *search.Analytics, logger *zap.Logger) *SearchAnalyticsHandler {
+	return &SearchAnalyticsHandler{analytics: a, logger: logger, now: time.Now}
+}
+
+// Register mounts the click beacon on mux.
+func (h *SearchAnalyticsHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v1/search/clicks", h.Click)
+}
+
+// RegisterAdmin mounts the search report on mux, which must be mounted
+// under AdminPrefix.
+func (h *SearchAnalyticsHandler) RegisterAdmin(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/admin/search/analytics", h.Report)
+}
+
+type searchClickRequest struct {
+	SearchID  string `json:"search_id"`
+	ProductID string `json:"product_id"`
+	Position  int    `json:"position"`
+}
+
+// Click records that the result at position of the search with search_id
+// was opened. It answers 202 before the click is stored.
+func (h *SearchAnalyticsHandler) Click(w http.ResponseWriter, r *http.Request) {
+	var in searchClickRequest
+	if !decodeJSON(w, r, maxSearchClickBytes, &in) {
+		return
+	}
+	if err := h.analytics.Clicked(in.SearchID, in.ProductID, in.Position); err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Report serves search and zero-result counts with the top queries and
+// the top queries finding nothing over the last 30 days (?days=), up to
+// ?limit= of each.
+func (h *SearchAnalyticsHandler) Report(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	params := map[string]int{"days": defaultSearchReportDays, "limit": search.DefaultReportQueries}
+	for name := range params {
+		raw := query.Get(name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || name == "limit" && n > search.MaxReportQueries {
+			httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, name+" must be a positive integer",
+				map[string]any{"received": raw, "max_limit": search.MaxReportQueries})
+			return
+		}
+		params[name] = n
+	}
+	since := h.now().UTC().AddDate(0, 0, -params["days"]).Truncate(time.Hour)
+	report, err := h.analytics.Report(r.Context(), since, params["limit"])
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/middleware"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/search"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestSearchAnalyticsHandler(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSearchAnalyticsHandler", "internal/handlers")
+
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	prices := services.NewPriceService(services.PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+	analytics := search.NewAnalytics(search.DefaultAnalyticsConfig(), store.SearchLogs(), logger)
+	auth := middleware.NewBearerAuth(middleware.BearerAuthConfig{Realm: "admin", Tokens: map[string]string{"ops": testAdminToken}}, logger)
+	h := NewRouter(Deps{
+		Logger:          logger,
+		Batch:           DefaultBatchConfig(),
+		AdminAuth:       auth.Handler,
+		Search:          search.NewService(store.ProductSearch(), prices, logger).WithAnalytics(analytics),
+		SearchAnalytics: analytics,
+	})
+
+	testhelpers.LogTestStep(logger, "act", "Searching, opening the first result and searching for a missing product")
+	rec := get(h, "/api/v1/products/search?q=whey")
+	var results domain.SearchResults
+	if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil || results.SearchID == "" {
+		t.Fatalf("Search = %d %s", rec.Code, rec.Body)
+	}
+	click := `{"search_id":"` + results.SearchID + `","product_id":"` + results.Products[0].Product.ID + `","position":1}`
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/search/clicks", click); rec.Code != http.StatusAccepted {
+		t.Errorf("Click status = %d: %s", rec.Code, rec.Body)
+	}
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/search/clicks", `{"search_id":"x","product_id":"y","position":0}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Bad click status = %d, want 400", rec.Code)
+	}
+	get(h, "/api/v1/products/search?q=casein")
+	done, cancel := context.WithCancel(t.Context())
+	cancel()
+	analytics.Run(done)
+
+	testhelpers.LogTestStep(logger, "assert", "The report lists both queries and the one that found nothing")
+	rec = adminRequest(h, http.MethodGet, "/api/v1/admin/search/analytics?days=7&limit=5", "")
+	testhelpers.LogTestAssertion(logger, "status", http.StatusOK, rec.Code)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Report status = %d: %s", rec.Code, rec.Body)
+	}
+	var report domain.SearchReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if report.Searches != 2 || report.Clicks != 1 || len(report.TopQueries) != 2 ||
+		len(report.ZeroQueries) != 1 || report.ZeroQueries[0].Query != "casein" {
+		t.Errorf("Report = %+v", report)
+	}
+	if rec := adminRequest(h, http.MethodGet, "/api/v1/admin/search/analytics?limit=1000", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("limit=1000 status = %d, want 400", rec.Code)
+	}
+	if rec := get(h, "/api/v1/admin/search/analytics"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Unauthenticated status = %d, want 401", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSearchAnalyticsHandler", true)
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/yourusername/whey-price-compare/deployments/postgres/migrations"
+	"github.com/yourusername/whey-price-compare/internal/database"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/repositories/postgres"
+	"github.com/yourusername/whey-price-compare/internal/repositories/sqlstore"
+	"github.com/yourusername/whey-price-compare/internal/search"
+	"github.com/yourusername/whey-price-compare/internal/seed"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestSearchHandler(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSearchHandler", "internal/handlers")
+
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	prices := services.NewPriceService(services.PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+	h := NewRouter(Deps{
+		Logger:  logger,
+		Prices:  prices,
+		Catalog: services.NewCatalogService(services.CatalogRepos{Products: store.Products(), Retailers: store.Retailers(), Listings: store.Listings()}, logger),
+		Search:  search.NewService(store.ProductSearch(), prices, logger),
+	})
+
+	testhelpers.LogTestStep(logger, "act", "Searching by brand")
+	rec := get(h, "/api/v1/products/search?q=optimum&per_page=5")
+
+	testhelpers.LogTestStep(logger, "assert", "The product is found with a localized best deal")
+	testhelpers.LogTestAssertion(logger, "status", http.StatusOK, rec.Code)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var results domain.SearchResults
+	if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if results.TotalCount != 1 || results.PerPage != 5 || len(results.Products) != 1 ||
+		results.Products[0].Product.ID != testhelpers.FixtureProductID {
+		t.Fatalf("Results = %+v", results)
+	}
+	if best := results.Products[0].BestDeal; best == nil || best.PriceDisplay != "₹3,199" {
+		t.Errorf("Best deal = %+v", best)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Filtering by retailer and pack size")
+	rec = get(h, "/api/v1/products/search?q=whey&retailer=flipkart,healthkart&weight=2270&weight=1000")
+	results = domain.SearchResults{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+		t.Fatalf("Decode filtered: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Filters are echoed and weight facets labelled")
+	if results.TotalCount != 1 || len(results.Filters.RetailerIDs) != 2 || len(results.Filters.Weights) != 2 {
+		t.Errorf("Filtered results = %+v", results)
+	}
+	if w := results.Facets.Weights; len(w) != 1 || w[0].Label != "2.27 kg" || !w[0].Selected {
+		t.Errorf("Weight facet = %+v", w)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Bad parameters are 400s; the product route still works")
+	for _, target := range []string{"/api/v1/products/search", "/api/v1/products/search?q=whey&page=two", "/api/v1/products/search?q=whey&weight=5lb"} {
+		if rec := get(h, target); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s status = %d, want 400", target, rec.Code)
+		}
+	}
+	if rec := get(h, "/api/v1/products/"+testhelpers.FixtureProductID); rec.Code != http.StatusOK {
+		t.Errorf("Product route status = %d", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSearchHandler", true)
+}
+
+// TestSearchHandler_Postgres searches the catalog the way the API does
+// with a database, through Postgres full-text search. It needs a
+// Postgres at TEST_POSTGRES_URL, whose catalog it replaces.
+func TestSearchHandler_Postgres(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSearchHandler_Postgres", "internal/handlers")
+
+	raw := os.Getenv("TEST_POSTGRES_URL")
+	if raw == "" {
+		t.Skip("TEST_POSTGRES_URL is not set")
+	}
+	ctx := t.Context()
+	cfg, err := database.ParseURL(raw, database.DefaultPoolConfig())
+	if err != nil {
+		t.Fatalf("Invalid TEST_POSTGRES_URL: %v", err)
+	}
+	db, err := database.Open(cfg.Dialect.Driver, cfg)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	all, err := database.LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatalf("LoadMigrations failed: %v", err)
+	}
+	if _, err := database.NewMigrator(db, all, logger).Up(ctx, 0); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	stmts := database.NewStatementCache(cfg.Pool, 0)
+	t.Cleanup(func() { _ = stmts.Close() })
+	router := database.NewRouter(database.DefaultRouterConfig(), db, nil, logger)
+
+	testhelpers.LogTestStep(logger, "arrange", "A generated catalog in Postgres")
+	c := seed.Generate(seed.Config{Products: 5, Days: 3, Seed: 8, Now: time.Now()})
+	if err := c.Insert(ctx, db, cfg.Dialect, true); err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+	want := c.Products[0]
+	prices := services.NewPriceService(services.PriceRepos{
+		Products:  sqlstore.NewProductRepository(cfg.Dialect, router, stmts),
+		Retailers: sqlstore.NewRetailerRepository(cfg.Dialect, router, stmts),
+		Listings:  sqlstore.NewListingRepository(cfg.Dialect, router, stmts),
+		Prices:    sqlstore.NewPriceRepository(cfg.Dialect, router, stmts),
+	}, logger)
+	h := NewRouter(Deps{
+		Logger: logger,
+		Prices: prices,
+		Search: search.NewService(postgres.NewSearchRepository(router, stmts), prices, logger),
+	})
+
+	testhelpers.LogTestStep(logger, "act", "Searching by the first product's brand and name")
+	rec := get(h, "/api/v1/products/search?q="+url.QueryEscape(want.Brand+" "+want.Name))
+
+	testhelpers.LogTestStep(logger, "assert", "The product is found")
+	testhelpers.LogTestAssertion(logger, "status", http.StatusOK, rec.Code)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var results domain.SearchResults
+	if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if len(results.Products) == 0 || results.Products[0].Product.ID != want.ID {
+		t.Errorf("Results = %+v, want %s first", results, want.ID)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSearchHandler_Postgres", true)
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/alerts"
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+)
+
+// SavedSearchHandler serves the signed-in user's saved searches.
+type SavedSearchHandler struct {
+	alerts *alerts.Service
+	logger *zap.Logger
+}
+
+// NewSavedSearchHandler creates a SavedSearchHandler.
+func NewSavedSearchHandler(svc *alerts.Service, logger *zap.Logger) *SavedSearchHandler {
+	return &SavedSearchHandler{alerts: svc, logger: logger}
+}
+
+// Register mounts the saved search routes on mux, next to the alerts they
+// are a kind of. They all require a signed-in user.
+func (h *SavedSearchHandler) Register(mux *http.ServeMux) {
+	mux.Handle("GET /api/v1/alerts/searches", auth.RequireUser(http.HandlerFunc(h.List)))
+	mux.Handle("POST /api/v1/alerts/searches", auth.RequireUser(http.HandlerFunc(h.Create)))
+	mux.Handle("DELETE /api/v1/alerts/searches/{id}", auth.RequireUser(http.HandlerFunc(h.Delete)))
+}
+
+type savedSearchesResponse struct {
+	Searches []domain.SavedSearch `json:"searches"`
+}
+
+// List returns the user's saved searches, newest first.
+func (h *SavedSearchHandler) List(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	list, err := h.alerts.Searches(r.Context(), u.ID)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	if list == nil {
+		list = []domain.SavedSearch{}
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusOK, savedSearchesResponse{Searches: list})
+}
+
+// Create saves a search. The user is notified when a product starts to
+// match it or a match gets cheaper; the response counts the products that
+// match already.
+func (h *SavedSearchHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Name            string  `json:"name"`
+		Query           string  `json:"query"`
+		CategoryID      string  `json:"category_id"`
+		BrandID         string  `json:"brand_id"`
+		MaxPrice        float64 `json:"max_price"`
+		MaxPricePerGram float64 `json:"max_price_per_gram_protein"`
+		MinSizeGrams    int     `json:"min_weight_grams"`
+	}
+	if !decodeJSON(w, r, maxAlertBodyBytes, &in) {
+		return
+	}
+	u := auth.UserFromContext(r.Context())
+	search, err := h.alerts.CreateSearch(r.Context(), *u, domain.SavedSearch{
+		Name:            in.Name,
+		Query:           in.Query,
+		CategoryID:      in.CategoryID,
+		BrandID:         in.BrandID,
+		MaxPrice:        in.MaxPrice,
+		MaxPricePerGram: in.MaxPricePerGram,
+		MinSizeGrams:    in.MinSizeGrams,
+	})
+	if errors.Is(err, alerts.ErrEmailUnverified) {
+		httpx.WriteError(w, r, http.StatusForbidden, httpx.CodeForbidden, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusCreated, search)
+}
+
+// Delete removes one of the user's saved searches.
+func (h *SavedSearchHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	if err := h.alerts.DeleteSearch(r.Context(), u.ID, r.PathValue("id")); err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/alerts"
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestSavedSearchHandler_Lifecycle(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSavedSearchHandler_Lifecycle", "internal/handlers")
+
+	testhelpers.LogTestStep(logger, "arrange", "Accounts, a seeded catalog and a signed-in, unverified user")
+	cfg := auth.DefaultConfig()
+	cfg.Password = auth.PasswordParams{Time: 1, MemoryKiB: 64, Threads: 1, KeyLen: 32, SaltLen: 16}
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	sender := &lastTokenSender{}
+	authSvc := auth.NewService(cfg, auth.Repos{
+		Users:         store.Users(),
+		Sessions:      store.Sessions(),
+		Verifications: store.Verifications(),
+	}, logger).WithVerificationSender(sender)
+	prices := services.NewPriceService(services.PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+	alertSvc := alerts.NewService(alerts.Repos{
+		Alerts:        store.Alerts(),
+		Notifications: store.Notifications(),
+		Searches:      store.SavedSearches(),
+	}, prices, logger)
+	h := NewRouter(Deps{Logger: logger, Auth: authSvc, Alerts: alertSvc})
+
+	sendAuth(h, http.MethodPost, "/api/v1/auth/register", `{"email":"asha@example.com","password":"test-only-password"}`)
+	rec := sendAuth(h, http.MethodPost, "/api/v1/auth/login", `{"email":"asha@example.com","password":"test-only-password"}`)
+	session := rec.Result().Cookies()[0]
+	body := `{"name":"Whey under 2,500","query":"whey","max_price":2500}`
+
+	testhelpers.LogTestStep(logger, "act", "Saving a search signed out and unverified")
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/alerts/searches", body); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Signed-out create status = %d, want 401", rec.Code)
+	}
+	rec = sendAuth(h, http.MethodPost, "/api/v1/alerts/searches", body, session)
+	testhelpers.LogTestAssertion(logger, "unverified status", http.StatusForbidden, rec.Code)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Unverified create status = %d, want 403: %s", rec.Code, rec.Body)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Verifying, then saving, listing and deleting")
+	if rec := sendAuth(h, http.MethodGet, "/api/v1/auth/verify?token="+url.QueryEscape(sender.token), ""); rec.Code != http.StatusOK {
+		t.Fatalf("Verify status = %d: %s", rec.Code, rec.Body)
+	}
+	rec = sendAuth(h, http.MethodPost, "/api/v1/alerts/searches", body, session)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Create status = %d: %s", rec.Code, rec.Body)
+	}
+	var created struct {
+		ID      string `json:"id"`
+		UserID  string `json:"user_id"`
+		Matches int    `json:"matches"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil || created.ID == "" {
+		t.Fatalf("Create body %s: %v", rec.Body, err)
+	}
+	if created.UserID != "" || created.Matches != 1 {
+		t.Errorf("Create response = %s, want 1 match and no user ID", rec.Body)
+	}
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/alerts/searches", `{"name":"Everything"}`, session); rec.Code != http.StatusBadRequest {
+		t.Errorf("No criteria status = %d, want 400", rec.Code)
+	}
+
+	rec = sendAuth(h, http.MethodGet, "/api/v1/alerts/searches", "", session)
+	var list savedSearchesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list.Searches) != 1 {
+		t.Fatalf("List = %s, want one search", rec.Body)
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "private, no-store" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+	if rec := sendAuth(h, http.MethodDelete, "/api/v1/alerts/searches/"+created.ID, "", session); rec.Code != http.StatusNoContent {
+		t.Errorf("Delete status = %d: %s", rec.Code, rec.Body)
+	}
+	rec = sendAuth(h, http.MethodDelete, "/api/v1/alerts/searches/"+created.ID, "", session)
+	testhelpers.LogTestAssertion(logger, "second delete", http.StatusNotFound, rec.Code)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Second delete status = %d, want 404", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSavedSearchHandler_Lifecycle", true)
+}
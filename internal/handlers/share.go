@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+	"github.com/yourusername/whey-price-compare/internal/services"
+)
+
+const maxShareBodyBytes = 1 << 10
+
+// ShareConfig configures deal share links.
+type ShareConfig struct {
+	// BaseURL is the public origin share links and their pages point at.
+	BaseURL string
+	// MaxAge is how long browsers and CDNs may cache a share page.
+	MaxAge time.Duration
+}
+
+// DefaultShareConfig returns a config for local development.
+func DefaultShareConfig() ShareConfig {
+	return ShareConfig{BaseURL: "http://localhost:8080", MaxAge: 5 * time.Minute}
+}
+
+// ShareHandler creates deal share links and serves the pages they open.
+// Outbound links on the page are tagged so clicks count as referrals.
+type ShareHandler struct {
+	cfg    ShareConfig
+	shares *services.ShareService
+	logger *zap.Logger
+}
+
+// NewShareHandler creates a ShareHandler.
+func NewShareHandler(cfg ShareConfig, shares *services.ShareService, logger *zap.Logger) *ShareHandler {
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = DefaultShareConfig().MaxAge
+	}
+	return &ShareHandler{cfg: cfg, shares: shares, logger: logger}
+}
+
+// Register mounts the share routes on mux. Anyone may share a deal.
+func (h *ShareHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v1/shares", h.Create)
+	mux.HandleFunc("GET /s/{token}", h.Page)
+}
+
+type shareResponse struct {
+	URL   string           `json:"url"`
+	Token string           `json:"token"`
+	Share domain.DealShare `json:"share"`
+}
+
+// Create snapshots a product's best deal, or its best at retailer_id, and
+// returns a link to it.
+func (h *ShareHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		ProductID  string `json:"product_id"`
+		RetailerID string `json:"retailer_id"`
+	}
+	if !decodeJSON(w, r, maxShareBodyBytes, &in) {
+		return
+	}
+	share, token, err := h.shares.Share(r.Context(), in.ProductID, in.RetailerID)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, http.StatusCreated, shareResponse{
+		URL:   h.cfg.BaseURL + httpx.SharePath(token),
+		Token: token,
+		Share: *share,
+	})
+}
+
+// Page renders a shared deal: the price when shared beside the price now.
+func (h *ShareHandler) Page(w http.ResponseWriter, r *http.Request) {
+	deal, err := h.shares.Open(r.Context(), r.PathValue("token"))
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := sharePageTemplate.Execute(&buf, h.view(i18n.FromContext(r.Context()), r.PathValue("token"), deal)); err != nil {
+		writeServiceError(w, r, h.logger, fmt.Errorf("render share page: %w", err))
+		return
+	}
+	hdr := w.Header()
+	hdr.Set("Content-Type", "text/html; charset=utf-8")
+	hdr.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.cfg.MaxAge.Seconds())))
+	hdr.Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	// The comparison page is canonical; share pages are one per share.
+	hdr.Set("X-Robots-Tag", "noindex")
+	_, _ = w.Write(buf.Bytes())
+}
+
+type sharePageView struct {
+	Lang       string
+	Title      string
+	Name       string
+	Shared     string
+	Now        string
+	Gone       string
+	URL        string
+	Canonical  string
+	ViewDeal   string
+	Compare    string
+	BuyURL     string
+	CompareURL string
+}
+
+func (h *ShareHandler) view(loc *i18n.Locale, token string, d *domain.SharedDeal) sharePageView {
+	s := d.Share
+	name := productName(d.Comparison.Product)
+	retailer := s.RetailerID
+	if d.Current != nil {
+		retailer = d.Current.RetailerName
+	}
+	price := loc.FormatPrice(s.Currency, s.Price)
+	v := sharePageView{
+		Lang:       loc.Tag,
+		Title:      loc.T(i18n.MsgShareTitle, name, price, retailer),
+		Name:       name,
+		Shared:     loc.T(i18n.MsgShareShared, price, s.SharedAt.Format("2 Jan 2006")),
+		URL:        h.cfg.BaseURL + httpx.SharePath(token),
+		Canonical:  h.cfg.BaseURL + httpx.ComparePagePath(s.ProductID),
+		Compare:    loc.T(i18n.MsgWidgetCompare),
+		CompareURL: h.cfg.BaseURL + httpx.WithReferral(httpx.ComparePagePath(s.ProductID), domain.ReferralShare),
+	}
+	if d.Current == nil || !d.Current.InStock {
+		v.Gone = loc.T(i18n.MsgShareGone, retailer)
+		return v
+	}
+	v.Now = loc.T(i18n.MsgShareNow, loc.FormatPrice(d.Current.Currency, d.Current.Price))
+	v.ViewDeal = loc.T(i18n.MsgWidgetViewDeal)
+	v.BuyURL = h.cfg.BaseURL + httpx.WithReferral(httpx.OutboundPath(s.RetailerID, s.ProductID, s.ListingID), domain.ReferralShare)
+	return v
+}
+
+var sharePageTemplate = template.Must(template.New("share").Parse(`<!doctype html>
+<html lang="{{.Lang}}"><head><meta charset="utf-8"><meta name="viewport" content="width=device-width,initial-scale=1">
+<title>{{.Title}}</title><link rel="canonical" href="{{.Canonical}}">
+<meta property="og:type" content="website"><meta property="og:title" content="{{.Title}}"><meta property="og:description" content="{{.Shared}}"><meta property="og:url" content="{{.URL}}">
+<style>body{margin:0;font:15px/1.4 system-ui,sans-serif;color:#1a1a1a}main{max-width:28em;margin:2em auto;padding:0 1em}
+.s{color:#555}.p{font-size:24px;font-weight:700}a{color:#0a58ca}.b{display:inline-block;background:#ff6a00;color:#fff;
+padding:8px 14px;border-radius:4px;text-decoration:none;margin-right:8px}</style></head>
+<body><main><h1>{{.Name}}</h1><p class="s">{{.Shared}}</p>
+{{if .Gone}}<p>{{.Gone}}</p>{{else}}<p class="p">{{.Now}}</p>
+<a class="b" href="{{.BuyURL}}" rel="sponsored noopener">{{.ViewDeal}}</a>{{end}}<a href="{{.CompareURL}}">{{.Compare}}</a>
+</main></body></html>
+`))
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestShareHandler(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestShareHandler", "internal/handlers")
+
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	prices := services.NewPriceService(services.PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+	clicks := services.NewClickTracker(services.DefaultClickTrackerConfig(), store.Clicks(), logger)
+	cfg := DefaultShareConfig()
+	cfg.BaseURL = "https://proteinprices.example/"
+	h := NewRouter(Deps{
+		Logger: logger,
+		Share:  cfg,
+		Shares: services.NewShareService([]byte("test-key"), prices, logger),
+		Redirects: services.NewRedirectService(services.RedirectRepos{
+			Retailers: store.Retailers(),
+			Listings:  store.Listings(),
+		}, nil, logger),
+		Clicks: clicks,
+	})
+
+	testhelpers.LogTestStep(logger, "act", "Sharing the best deal")
+	rec := sendAuth(h, http.MethodPost, "/api/v1/shares", `{"product_id":"`+testhelpers.FixtureProductID+`"}`)
+	testhelpers.LogTestAssertion(logger, "create status", http.StatusCreated, rec.Code)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Create status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var created struct {
+		URL   string           `json:"url"`
+		Token string           `json:"token"`
+		Share domain.DealShare `json:"share"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if created.URL != "https://proteinprices.example/s/"+created.Token || created.Share.Price != 3199 {
+		t.Errorf("Created = %+v", created)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Opening the share page")
+	link, _ := url.Parse(created.URL)
+	page := get(h, link.Path)
+
+	testhelpers.LogTestStep(logger, "assert", "Page shows the deal with referral-tagged links")
+	if page.Code != http.StatusOK {
+		t.Fatalf("Page status = %d", page.Code)
+	}
+	body := page.Body.String()
+	for _, want := range []string{
+		"Optimum Nutrition Gold Standard 100% Whey for ₹3,199 at Flipkart",
+		`<meta property="og:url" content="https://proteinprices.example/s/`,
+		"Now ₹3,199",
+		`href="https://proteinprices.example/go/flipkart/prod_on_gsw?listing=lst_flipkart_gsw_2270&amp;ref=share"`,
+		`href="https://proteinprices.example/compare/prod_on_gsw?ref=share"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Share page missing %q", want)
+		}
+	}
+	if page.Header().Get("X-Robots-Tag") != "noindex" {
+		t.Errorf("X-Robots-Tag = %q", page.Header().Get("X-Robots-Tag"))
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Clicks through the page are attributed to the share")
+	get(h, "/go/flipkart/prod_on_gsw?listing=lst_flipkart_gsw_2270&ref=share")
+	get(h, "/go/flipkart/prod_on_gsw?ref=spam")
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	clicks.Run(ctx)
+	n, _ := store.Clicks().CountClicks(t.Context(), repositories.ClickFilter{Referral: domain.ReferralShare})
+	if n != 1 {
+		t.Errorf("Share referral clicks = %d, want 1", n)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Bad tokens and unknown products")
+	if rec := get(h, "/s/"+created.Token+"x"); rec.Code != http.StatusNotFound {
+		t.Errorf("Tampered token status = %d, want 404", rec.Code)
+	}
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/shares", `{"product_id":"prod_missing"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("Unknown product status = %d, want 404", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestShareHandler", true)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/notify/slack"
+)
+
+const maxSlackBodyBytes = 1 << 10
+
+// SlackHandler manages the Slack webhook the signed-in user's alerts
+// are posted to.
+type SlackHandler struct {
+	slack  *slack.App
+	logger *zap.Logger
+}
+
+// NewSlackHandler creates a SlackHandler.
+func NewSlackHandler(app *slack.App, logger *zap.Logger) *SlackHandler {
+	return &SlackHandler{slack: app, logger: logger}
+}
+
+// Register mounts the Slack routes on mux. They all require a signed-in
+// user.
+func (h *SlackHandler) Register(mux *http.ServeMux) {
+	mux.Handle("GET /api/v1/slack", auth.RequireUser(http.HandlerFunc(h.Status)))
+	mux.Handle("PUT /api/v1/slack", auth.RequireUser(http.HandlerFunc(h.Connect)))
+	mux.Handle("DELETE /api/v1/slack", auth.RequireUser(http.HandlerFunc(h.Disconnect)))
+}
+
+type slackStatusResponse struct {
+	Connected bool `json:"connected"`
+	*domain.SlackWebhook
+}
+
+// Status returns the user's webhook, without its URL.
+func (h *SlackHandler) Status(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	hook, err := h.slack.Webhook(r.Context(), u.ID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusOK, slackStatusResponse{Connected: hook != nil, SlackWebhook: hook})
+}
+
+// Connect sets the webhook the user's alerts are posted to, after checking
+// it with a post to the channel.
+func (h *SlackHandler) Connect(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		WebhookURL string `json:"webhook_url"`
+	}
+	if !decodeJSON(w, r, maxSlackBodyBytes, &in) {
+		return
+	}
+	u := auth.UserFromContext(r.Context())
+	hook, err := h.slack.Connect(r.Context(), u.ID, in.WebhookURL)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusOK, slackStatusResponse{Connected: true, SlackWebhook: hook})
+}
+
+// Disconnect forgets the user's webhook.
+func (h *SlackHandler) Disconnect(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	if err := h.slack.Disconnect(r.Context(), u.ID); err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
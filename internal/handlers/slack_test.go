@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/notify/slack"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestSlackHandler(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSlackHandler", "internal/handlers")
+
+	testhelpers.LogTestStep(logger, "arrange", "Accounts, a Slack app and a signed-in user")
+	cfg := auth.DefaultConfig()
+	cfg.Password = auth.PasswordParams{Time: 1, MemoryKiB: 64, Threads: 1, KeyLen: 32, SaltLen: 16}
+	store := memory.NewStore()
+	authSvc := auth.NewService(cfg, auth.Repos{
+		Users:         store.Users(),
+		Sessions:      store.Sessions(),
+		Verifications: store.Verifications(),
+	}, logger)
+	app := slack.NewApp(slack.DefaultConfig(), store.Slack(), slack.Catalog{}, logger)
+	h := NewRouter(Deps{Logger: logger, Auth: authSvc, Slack: app})
+
+	sendAuth(h, http.MethodPost, "/api/v1/auth/register", `{"email":"asha@example.com","password":"test-only-password"}`)
+	rec := sendAuth(h, http.MethodPost, "/api/v1/auth/login", `{"email":"asha@example.com","password":"test-only-password"}`)
+	session := rec.Result().Cookies()[0]
+	user, err := store.Users().UserByEmail(t.Context(), "asha@example.com")
+	if err != nil {
+		t.Fatalf("UserByEmail: %v", err)
+	}
+	connect := func() {
+		err := store.Slack().SaveSlackWebhook(t.Context(), domain.SlackWebhook{
+			UserID: user.ID, URL: "https://hooks.slack.com/services/T0001/B0001/test-only-token",
+			TeamID: "T0001", CreatedAt: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("SaveSlackWebhook: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name       string
+		method     string
+		body       string
+		arrange    func()
+		signedOut  bool
+		wantStatus int
+		wantBody   string
+	}{
+		{"Signed out", http.MethodGet, "", nil, true, http.StatusUnauthorized, ""},
+		{"No webhook yet", http.MethodGet, "", nil, false, http.StatusOK, `{"connected":false}`},
+		{"Not a Slack webhook", http.MethodPut, `{"webhook_url":"https://example.com/services/T1/B1/x"}`, nil, false, http.StatusBadRequest, "not a Slack incoming webhook"},
+		{"Connected, URL hidden", http.MethodGet, "", connect, false, http.StatusOK, `"connected":true,"team_id":"T0001"`},
+		{"Disconnecting", http.MethodDelete, "", nil, false, http.StatusNoContent, ""},
+		{"Disconnected", http.MethodGet, "", nil, false, http.StatusOK, `{"connected":false}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.arrange != nil {
+				tc.arrange()
+			}
+			var cookies []*http.Cookie
+			if !tc.signedOut {
+				cookies = append(cookies, session)
+			}
+			rec := sendAuth(h, tc.method, "/api/v1/slack", tc.body, cookies...)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantStatus, rec.Code)
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("Status = %d, want %d: %s", rec.Code, tc.wantStatus, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tc.wantBody) {
+				t.Errorf("Body = %s, want it to contain %s", rec.Body, tc.wantBody)
+			}
+			if strings.Contains(rec.Body.String(), "test-only-token") {
+				t.Errorf("Body = %s exposes the webhook token", rec.Body)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSlackHandler", true)
+}
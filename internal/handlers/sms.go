@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/notify/sms"
+)
+
+const maxSMSBodyBytes = 1 << 10
+
+// SMSHandler manages the signed-in user's number for SMS alerts.
+type SMSHandler struct {
+	sms    *sms.Sender
+	logger *zap.Logger
+}
+
+// NewSMSHandler creates an SMSHandler.
+func NewSMSHandler(sender *sms.Sender, logger *zap.Logger) *SMSHandler {
+	return &SMSHandler{sms: sender, logger: logger}
+}
+
+// Register mounts the SMS routes on mux. They all require a signed-in user.
+func (h *SMSHandler) Register(mux *http.ServeMux) {
+	mux.Handle("GET /api/v1/sms", auth.RequireUser(http.HandlerFunc(h.Status)))
+	mux.Handle("PUT /api/v1/sms", auth.RequireUser(http.HandlerFunc(h.Subscribe)))
+	mux.Handle("POST /api/v1/sms/verify", auth.RequireUser(http.HandlerFunc(h.Verify)))
+	mux.Handle("DELETE /api/v1/sms", auth.RequireUser(http.HandlerFunc(h.Unsubscribe)))
+}
+
+type smsStatusResponse struct {
+	Subscribed bool `json:"subscribed"`
+	*domain.SMSSubscription
+}
+
+// Status returns the user's number and whether it is verified.
+func (h *SMSHandler) Status(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	sub, err := h.sms.Subscription(r.Context(), u.ID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusOK, smsStatusResponse{Subscribed: sub != nil, SMSSubscription: sub})
+}
+
+// Subscribe sets the user's number and texts it a verification code.
+func (h *SMSHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Phone string `json:"phone"`
+	}
+	if !decodeJSON(w, r, maxSMSBodyBytes, &in) {
+		return
+	}
+	u := auth.UserFromContext(r.Context())
+	sub, err := h.sms.Subscribe(r.Context(), u.ID, in.Phone)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusAccepted, smsStatusResponse{Subscribed: true, SMSSubscription: sub})
+}
+
+// Verify confirms the user's number with the texted code.
+func (h *SMSHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Code string `json:"code"`
+	}
+	if !decodeJSON(w, r, maxSMSBodyBytes, &in) {
+		return
+	}
+	u := auth.UserFromContext(r.Context())
+	sub, err := h.sms.Verify(r.Context(), u.ID, in.Code)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusOK, smsStatusResponse{Subscribed: true, SMSSubscription: sub})
+}
+
+// Unsubscribe removes the user's number.
+func (h *SMSHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	if err := h.sms.Unsubscribe(r.Context(), u.ID); err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
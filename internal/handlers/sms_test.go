@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/notify/sms"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+type lastSMSProvider struct{ last sms.Message }
+
+func (p *lastSMSProvider) Name() string { return "fake" }
+
+func (p *lastSMSProvider) Send(_ context.Context, m sms.Message) error {
+	p.last = m
+	return nil
+}
+
+func TestSMSHandler(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSMSHandler", "internal/handlers")
+
+	testhelpers.LogTestStep(logger, "arrange", "Accounts, an SMS sender and a signed-in user")
+	cfg := auth.DefaultConfig()
+	cfg.Password = auth.PasswordParams{Time: 1, MemoryKiB: 64, Threads: 1, KeyLen: 32, SaltLen: 16}
+	store := memory.NewStore()
+	authSvc := auth.NewService(cfg, auth.Repos{
+		Users:         store.Users(),
+		Sessions:      store.Sessions(),
+		Verifications: store.Verifications(),
+	}, logger)
+	provider := &lastSMSProvider{}
+	sender := sms.NewSender(sms.DefaultConfig(), provider, store.SMS(), logger)
+	h := NewRouter(Deps{Logger: logger, Auth: authSvc, SMS: sender})
+
+	sendAuth(h, http.MethodPost, "/api/v1/auth/register", `{"email":"asha@example.com","password":"test-only-password"}`)
+	rec := sendAuth(h, http.MethodPost, "/api/v1/auth/login", `{"email":"asha@example.com","password":"test-only-password"}`)
+	session := rec.Result().Cookies()[0]
+
+	testhelpers.LogTestStep(logger, "act", "Adding and verifying a number")
+	code := func() string { return provider.last.Vars["code"] }
+	testCases := []struct {
+		name       string
+		method     string
+		target     string
+		body       func() string
+		signedOut  bool
+		wantStatus int
+		wantBody   string
+	}{
+		{"Signed out", http.MethodGet, "/api/v1/sms", nil, true, http.StatusUnauthorized, ""},
+		{"No number yet", http.MethodGet, "/api/v1/sms", nil, false, http.StatusOK, `{"subscribed":false}`},
+		{"Unsupported country", http.MethodPut, "/api/v1/sms", func() string { return `{"phone":"+14155550100"}` }, false, http.StatusBadRequest, "+91"},
+		{"Adding a number", http.MethodPut, "/api/v1/sms", func() string { return `{"phone":"98123 45678"}` }, false, http.StatusAccepted, `"phone":"+919812345678","verified":false`},
+		{"Wrong code", http.MethodPost, "/api/v1/sms/verify", func() string { return `{"code":"x"}` }, false, http.StatusBadRequest, "not correct"},
+		{"Right code", http.MethodPost, "/api/v1/sms/verify", func() string { return `{"code":"` + code() + `"}` }, false, http.StatusOK, `"verified":true`},
+		{"Verified status", http.MethodGet, "/api/v1/sms", nil, false, http.StatusOK, `"subscribed":true`},
+		{"Removing", http.MethodDelete, "/api/v1/sms", nil, false, http.StatusNoContent, ""},
+		{"Removed status", http.MethodGet, "/api/v1/sms", nil, false, http.StatusOK, `{"subscribed":false}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body := ""
+			if tc.body != nil {
+				body = tc.body()
+			}
+			var rec = sendAuth(h, tc.method, tc.target, body, session)
+			if tc.signedOut {
+				rec = sendAuth(h, tc.method, tc.target, body)
+			}
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantStatus, rec.Code)
+			if rec.Code != tc.wantStatus || !strings.Contains(rec.Body.String(), tc.wantBody) {
+				t.Fatalf("Status = %d, want %d containing %q: %s", rec.Code, tc.wantStatus, tc.wantBody, rec.Body)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSMSHandler", true)
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/services"
+)
+
+// StatsHandler serves the public platform statistics.
+type StatsHandler struct {
+	stats  *services.StatsService
+	logger *zap.Logger
+}
+
+// NewStatsHandler creates a StatsHandler.
+func NewStatsHandler(stats *services.StatsService, logger *zap.Logger) *StatsHandler {
+	return &StatsHandler{stats: stats, logger: logger}
+}
+
+// Register mounts the stats route on mux.
+func (h *StatsHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/stats", h.Stats)
+}
+
+// Stats serves product, retailer and price point counts.
+func (h *StatsHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.stats.Stats(r.Context())
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.stats.TTL().Seconds())))
+	if httpx.WantsHAL(w, r) {
+		httpx.WriteHAL(w, http.StatusOK, httpx.NewResource(stats, apiBase+"/stats").
+			Link("retailers", link(apiBase+"/retailers")).
+			Link("deals", link(apiBase+"/deals")))
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, stats)
+}
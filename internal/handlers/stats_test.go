@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestStatsHandler(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStatsHandler", "internal/handlers")
+
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	h := NewRouter(Deps{
+		Logger: logger,
+		Stats:  services.NewStatsService(store.Stats(), time.Minute, logger),
+	})
+
+	testhelpers.LogTestStep(logger, "act", "Requesting stats")
+	rec := get(h, "/api/v1/stats")
+
+	testhelpers.LogTestStep(logger, "assert", "Counts and cache headers")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want 200", rec.Code)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "public, max-age=60" {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+	var stats domain.CatalogStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	testhelpers.LogTestAssertion(logger, "products", 2, stats.Products)
+	if stats.Products != 2 || stats.Retailers != 3 || stats.PricePoints != 28 || stats.LastUpdatedAt == nil {
+		t.Errorf("Stats = %+v", stats)
+	}
+
+	rec = getHAL(h, "/api/v1/stats")
+	var hal halLinks
+	if err := json.Unmarshal(rec.Body.Bytes(), &hal); err != nil || hal.Links["self"] == nil {
+		t.Errorf("HAL response missing self link: %s", rec.Body.String())
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStatsHandler", true)
+}
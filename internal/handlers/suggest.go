@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/search"
+)
+
+// suggestMaxAge is how long browsers and CDNs may reuse a suggestion list;
+// the index behind it is rebuilt every few minutes at most.
+const suggestMaxAge = 300
+
+// SuggestHandler serves search type-ahead.
+type SuggestHandler struct {
+	suggester *search.Suggester
+	logger    *zap.Logger
+}
+
+// NewSuggestHandler creates a SuggestHandler.
+func NewSuggestHandler(s *search.Suggester, logger *zap.Logger) *SuggestHandler {
+	return &SuggestHandler{suggester: s, logger: logger}
+}
+
+// Register mounts the suggestion route on mux.
+func (h *SuggestHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/suggest", h.Suggest)
+}
+
+// Suggest serves brand and product completions of ?q= (?limit=), within
+// search.SuggestBudget.
+func (h *SuggestHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > search.MaxSuggestions {
+			httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest,
+				"limit must be between 1 and "+strconv.Itoa(search.MaxSuggestions), map[string]any{"received": raw})
+			return
+		}
+		limit = n
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), search.SuggestBudget)
+	defer cancel()
+	suggestions := h.suggester.Suggest(ctx, r.URL.Query().Get("q"), limit)
+	if ctx.Err() != nil {
+		h.logger.Warn("Suggestions exceeded their budget",
+			zap.String("operation", "Suggest"),
+			zap.Duration("budget", search.SuggestBudget),
+		)
+		// A partial list is not worth caching.
+		w.Header().Set("Cache-Control", "no-store")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(suggestMaxAge))
+	}
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{"suggestions": suggestions})
+}
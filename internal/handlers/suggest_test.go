@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/search"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestSuggestHandler(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSuggestHandler", "internal/handlers")
+
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	suggester := search.NewSuggester(store.Products(), logger)
+	if err := suggester.Rebuild(t.Context()); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+	h := NewRouter(Deps{Logger: logger, Suggest: suggester})
+
+	testhelpers.LogTestStep(logger, "act", "Asking for completions of a brand prefix")
+	rec := get(h, "/api/v1/suggest?q=muscle&limit=5")
+
+	testhelpers.LogTestStep(logger, "assert", "Brand and product are suggested and cacheable")
+	testhelpers.LogTestAssertion(logger, "status", http.StatusOK, rec.Code)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Suggestions []domain.Suggestion `json:"suggestions"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if len(body.Suggestions) != 2 || body.Suggestions[0].Kind != domain.SuggestBrand || body.Suggestions[1].ID != testhelpers.FixtureSecondProductID {
+		t.Errorf("Suggestions = %+v", body.Suggestions)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "public, max-age=300" {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "An empty query suggests nothing; a bad limit is a 400")
+	if rec := get(h, "/api/v1/suggest"); rec.Code != http.StatusOK || rec.Body.String() != "{\"suggestions\":[]}\n" {
+		t.Errorf("Empty query = %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := get(h, "/api/v1/suggest?q=whey&limit=0"); rec.Code != http.StatusBadRequest {
+		t.Errorf("limit=0 status = %d, want 400", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSuggestHandler", true)
+}
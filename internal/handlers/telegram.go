@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/notify/telegram"
+)
+
+// TelegramHandler links the signed-in user's Telegram chat for alerts.
+type TelegramHandler struct {
+	bot    *telegram.Bot
+	logger *zap.Logger
+}
+
+// NewTelegramHandler creates a TelegramHandler.
+func NewTelegramHandler(bot *telegram.Bot, logger *zap.Logger) *TelegramHandler {
+	return &TelegramHandler{bot: bot, logger: logger}
+}
+
+// Register mounts the Telegram routes on mux. They all require a signed-in
+// user.
+func (h *TelegramHandler) Register(mux *http.ServeMux) {
+	mux.Handle("GET /api/v1/telegram", auth.RequireUser(http.HandlerFunc(h.Status)))
+	mux.Handle("POST /api/v1/telegram/link", auth.RequireUser(http.HandlerFunc(h.Link)))
+	mux.Handle("DELETE /api/v1/telegram/link", auth.RequireUser(http.HandlerFunc(h.Unlink)))
+}
+
+type telegramStatusResponse struct {
+	Linked bool `json:"linked"`
+}
+
+// Status reports whether the user has a linked chat.
+func (h *TelegramHandler) Status(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	linked, err := h.bot.Linked(r.Context(), u.ID)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusOK, telegramStatusResponse{Linked: linked})
+}
+
+type telegramLinkResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Link returns a one-time t.me link that connects the chat opening it.
+func (h *TelegramHandler) Link(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	link, expires, err := h.bot.LinkURL(r.Context(), u.ID)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusCreated, telegramLinkResponse{URL: link, ExpiresAt: expires})
+}
+
+// Unlink disconnects the user's chat.
+func (h *TelegramHandler) Unlink(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	if err := h.bot.Unlink(r.Context(), u.ID); err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
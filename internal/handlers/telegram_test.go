@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/notify/telegram"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestTelegramHandler_Link(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestTelegramHandler_Link", "internal/handlers")
+
+	testhelpers.LogTestStep(logger, "arrange", "Accounts, a bot and a signed-in user")
+	cfg := auth.DefaultConfig()
+	cfg.Password = auth.PasswordParams{Time: 1, MemoryKiB: 64, Threads: 1, KeyLen: 32, SaltLen: 16}
+	store := memory.NewStore()
+	authSvc := auth.NewService(cfg, auth.Repos{
+		Users:         store.Users(),
+		Sessions:      store.Sessions(),
+		Verifications: store.Verifications(),
+	}, logger)
+	prices := services.NewPriceService(services.PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+	bot := telegram.NewBot(telegram.Config{Token: "123456:test-only-secret", Username: "whey_test_bot"},
+		store.Telegram(), telegram.Catalog{Products: store.Products(), Prices: prices}, logger)
+	h := NewRouter(Deps{Logger: logger, Auth: authSvc, Telegram: bot})
+
+	sendAuth(h, http.MethodPost, "/api/v1/auth/register", `{"email":"asha@example.com","password":"test-only-password"}`)
+	rec := sendAuth(h, http.MethodPost, "/api/v1/auth/login", `{"email":"asha@example.com","password":"test-only-password"}`)
+	session := rec.Result().Cookies()[0]
+
+	testhelpers.LogTestStep(logger, "act", "Requesting a link signed out and signed in")
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/telegram/link", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Signed-out link status = %d, want 401", rec.Code)
+	}
+	rec = sendAuth(h, http.MethodPost, "/api/v1/telegram/link", "", session)
+	testhelpers.LogTestAssertion(logger, "link status", http.StatusCreated, rec.Code)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Link status = %d: %s", rec.Code, rec.Body)
+	}
+	var link struct {
+		URL       string    `json:"url"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &link); err != nil || !strings.HasPrefix(link.URL, "https://t.me/whey_test_bot?start=") || link.ExpiresAt.IsZero() {
+		t.Errorf("Link body %s: %v", rec.Body, err)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "private, no-store" {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Status reflects the link, and unlinking clears it")
+	if rec := sendAuth(h, http.MethodGet, "/api/v1/telegram", "", session); !strings.Contains(rec.Body.String(), `"linked":false`) {
+		t.Errorf("Status before linking = %s", rec.Body)
+	}
+	u, _ := store.Users().UserByEmail(t.Context(), "asha@example.com")
+	_ = store.Telegram().LinkChat(t.Context(), domain.TelegramLink{UserID: u.ID, ChatID: 42, LinkedAt: time.Now()})
+	if rec := sendAuth(h, http.MethodGet, "/api/v1/telegram", "", session); !strings.Contains(rec.Body.String(), `"linked":true`) {
+		t.Errorf("Status after linking = %s", rec.Body)
+	}
+	if rec := sendAuth(h, http.MethodDelete, "/api/v1/telegram/link", "", session); rec.Code != http.StatusNoContent {
+		t.Errorf("Unlink status = %d, want 204", rec.Code)
+	}
+	if rec := sendAuth(h, http.MethodGet, "/api/v1/telegram", "", session); !strings.Contains(rec.Body.String(), `"linked":false`) {
+		t.Errorf("Status after unlinking = %s", rec.Body)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestTelegramHandler_Link", true)
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/notify/templates"
+)
+
+const maxPreviewBodyBytes = 16 << 10
+
+// TemplateHandler lets administrators list notification templates and
+// preview any version in any format. It is mounted under AdminPrefix.
+type TemplateHandler struct {
+	templates *templates.Engine
+	logger    *zap.Logger
+}
+
+// NewTemplateHandler creates a TemplateHandler.
+func NewTemplateHandler(engine *templates.Engine, logger *zap.Logger) *TemplateHandler {
+	return &TemplateHandler{templates: engine, logger: logger}
+}
+
+// Register mounts the template routes on mux.
+func (h *TemplateHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/admin/templates", h.List)
+	mux.HandleFunc("POST /api/v1/admin/templates/{name}/preview", h.Preview)
+}
+
+type templatesResponse struct {
+	Templates []templates.Template `json:"templates"`
+}
+
+// List serves every template with its versions and the one in use.
+func (h *TemplateHandler) List(w http.ResponseWriter, r *http.Request) {
+	httpx.WriteJSON(w, http.StatusOK, templatesResponse{Templates: h.templates.Templates()})
+}
+
+// Preview renders a template with example data, which the request's data
+// can partly replace. Without a version the one in use is rendered.
+func (h *TemplateHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Format  templates.Format `json:"format"`
+		Version int              `json:"version"`
+		Data    json.RawMessage  `json:"data"`
+	}
+	if !decodeJSON(w, r, maxPreviewBodyBytes, &in) {
+		return
+	}
+	if in.Format == "" {
+		in.Format = templates.Text
+	}
+	if !slices.Contains(templates.Formats, in.Format) {
+		httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, "Unknown format",
+			map[string]any{"received": in.Format, "allowed": templates.Formats})
+		return
+	}
+	name := r.PathValue("name")
+	data, err := templates.Sample(name, in.Data)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	var m templates.Message
+	if in.Version == 0 {
+		m, err = h.templates.Render(name, in.Format, data)
+	} else {
+		m, err = h.templates.RenderVersion(name, in.Format, in.Version, data)
+	}
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, m)
+}
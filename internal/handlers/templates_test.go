@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/middleware"
+	"github.com/yourusername/whey-price-compare/internal/notify/templates"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestTemplateHandler(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestTemplateHandler", "internal/handlers")
+
+	auth := middleware.NewBearerAuth(middleware.BearerAuthConfig{Realm: "admin", Tokens: map[string]string{"ops": testAdminToken}}, logger)
+	h := NewRouter(Deps{Logger: logger, Batch: DefaultBatchConfig(), AdminAuth: auth.Handler, Templates: templates.Default()})
+
+	testCases := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"List", http.MethodGet, "/api/v1/admin/templates", "", http.StatusOK, `"name":"price_alert","versions":[1],"active":1`},
+		{"Preview text by default", http.MethodPost, "/api/v1/admin/templates/price_alert/preview", `{}`, http.StatusOK, `"title":"Price alert: Optimum Nutrition`},
+		{"Preview Slack with data", http.MethodPost, "/api/v1/admin/templates/price_alert/preview",
+			`{"format":"slack","data":{"product_name":"Biozyme","test":true}}`, http.StatusOK, `"title":"Test alert: the price is simulated"`},
+		{"Preview a version", http.MethodPost, "/api/v1/admin/templates/verification/preview", `{"format":"html","version":1}`, http.StatusOK, `<!DOCTYPE html>`},
+		{"Unknown version", http.MethodPost, "/api/v1/admin/templates/verification/preview", `{"version":7}`, http.StatusNotFound, ""},
+		{"Unknown format", http.MethodPost, "/api/v1/admin/templates/price_alert/preview", `{"format":"pdf"}`, http.StatusBadRequest, ""},
+		{"Unknown template", http.MethodPost, "/api/v1/admin/templates/welcome/preview", `{}`, http.StatusNotFound, ""},
+		{"Ill-typed data", http.MethodPost, "/api/v1/admin/templates/price_alert/preview", `{"data":{"price":"cheap"}}`, http.StatusBadRequest, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := adminRequest(h, tc.method, tc.target, tc.body)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantStatus, rec.Code)
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("Status = %d, want %d: %s", rec.Code, tc.wantStatus, rec.Body)
+			}
+			var body any
+			_ = json.Unmarshal(rec.Body.Bytes(), &body)
+			var buf strings.Builder
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(false)
+			_ = enc.Encode(body)
+			if !strings.Contains(rec.Body.String(), tc.wantBody) && !strings.Contains(buf.String(), tc.wantBody) {
+				t.Errorf("Body = %s, want it to contain %s", rec.Body, tc.wantBody)
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Templates require the admin token")
+	if rec := sendAuth(h, http.MethodGet, "/api/v1/admin/templates", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Unauthenticated status = %d, want 401", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestTemplateHandler", true)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+)
+
+const (
+	maxTokenBodyBytes = 1 << 10
+	maxTokenDays      = 365
+)
+
+// TokenHandler manages the signed-in user's personal API tokens. Tokens
+// cannot reach these routes themselves, only browser sessions can.
+type TokenHandler struct {
+	auth   *auth.Service
+	logger *zap.Logger
+}
+
+// NewTokenHandler creates a TokenHandler.
+func NewTokenHandler(svc *auth.Service, logger *zap.Logger) *TokenHandler {
+	return &TokenHandler{auth: svc, logger: logger}
+}
+
+// Register mounts the token routes on mux. They all require a signed-in
+// user.
+func (h *TokenHandler) Register(mux *http.ServeMux) {
+	mux.Handle("GET /api/v1/tokens", auth.RequireUser(http.HandlerFunc(h.List)))
+	mux.Handle("POST /api/v1/tokens", auth.RequireUser(http.HandlerFunc(h.Create)))
+	mux.Handle("DELETE /api/v1/tokens/{id}", auth.RequireUser(http.HandlerFunc(h.Revoke)))
+}
+
+type tokenListResponse struct {
+	Tokens []domain.APIToken `json:"tokens"`
+}
+
+// List returns the user's tokens, without the secrets.
+func (h *TokenHandler) List(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	tokens, err := h.auth.APITokens(r.Context(), u.ID)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	if tokens == nil {
+		tokens = []domain.APIToken{}
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusOK, tokenListResponse{Tokens: tokens})
+}
+
+type tokenCreateResponse struct {
+	// Token is shown once; only its hash is kept.
+	Token string `json:"token"`
+	domain.APIToken
+}
+
+// Create issues a token with the requested scopes, expiring after
+// expires_in_days or never if that is zero.
+func (h *TokenHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Name          string   `json:"name"`
+		Scopes        []string `json:"scopes"`
+		ExpiresInDays int      `json:"expires_in_days"`
+	}
+	if !decodeJSON(w, r, maxTokenBodyBytes, &in) {
+		return
+	}
+	if in.ExpiresInDays < 0 || in.ExpiresInDays > maxTokenDays {
+		httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, "expires_in_days must be 0 to 365", nil)
+		return
+	}
+	u := auth.UserFromContext(r.Context())
+	t, token, err := h.auth.CreateAPIToken(r.Context(), u.ID, in.Name, in.Scopes, time.Duration(in.ExpiresInDays)*24*time.Hour)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusCreated, tokenCreateResponse{Token: token, APIToken: *t})
+}
+
+// Revoke deletes one of the user's tokens.
+func (h *TokenHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	if err := h.auth.RevokeAPIToken(r.Context(), u.ID, r.PathValue("id")); err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
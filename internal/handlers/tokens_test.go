@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/alerts"
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestTokenHandler(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestTokenHandler", "internal/handlers")
+
+	testhelpers.LogTestStep(logger, "arrange", "Accounts with API tokens, alerts and a signed-in user")
+	cfg := auth.DefaultConfig()
+	cfg.Password = auth.PasswordParams{Time: 1, MemoryKiB: 64, Threads: 1, KeyLen: 32, SaltLen: 16}
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	authSvc := auth.NewService(cfg, auth.Repos{
+		Users:         store.Users(),
+		Sessions:      store.Sessions(),
+		Verifications: store.Verifications(),
+		APITokens:     store.APITokens(),
+	}, logger)
+	prices := services.NewPriceService(services.PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+	alertSvc := alerts.NewService(alerts.Repos{Alerts: store.Alerts(), Notifications: store.Notifications()}, prices, logger)
+	h := NewRouter(Deps{Logger: logger, Auth: authSvc, Alerts: alertSvc})
+
+	sendAuth(h, http.MethodPost, "/api/v1/auth/register", `{"email":"asha@example.com","password":"test-only-password"}`)
+	rec := sendAuth(h, http.MethodPost, "/api/v1/auth/login", `{"email":"asha@example.com","password":"test-only-password"}`)
+	session := rec.Result().Cookies()[0]
+
+	testhelpers.LogTestStep(logger, "act", "Creating a read-only token")
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/tokens", `{"name":"ci","scopes":["read"],"expires_in_days":400}`, session); rec.Code != http.StatusBadRequest {
+		t.Errorf("Over-long expiry status = %d, want 400", rec.Code)
+	}
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/tokens", `{"name":"ci","scopes":["everything"]}`, session); rec.Code != http.StatusBadRequest {
+		t.Errorf("Unknown scope status = %d, want 400", rec.Code)
+	}
+	rec = sendAuth(h, http.MethodPost, "/api/v1/tokens", `{"name":"dashboard","scopes":["read"],"expires_in_days":30}`, session)
+	testhelpers.LogTestAssertion(logger, "create status", http.StatusCreated, rec.Code)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Create status = %d: %s", rec.Code, rec.Body)
+	}
+	var created struct {
+		Token     string     `json:"token"`
+		ID        string     `json:"id"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil || !strings.HasPrefix(created.Token, auth.APITokenPrefix) || created.ExpiresAt == nil {
+		t.Fatalf("Create body %s: %v", rec.Body, err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Calling the API with the token")
+	bearer := func(method, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(`{}`))
+		req.Header.Set("Authorization", "Bearer "+created.Token)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	testCases := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+	}{
+		{"Reading alerts", http.MethodGet, "/api/v1/alerts", http.StatusOK},
+		{"Creating an alert", http.MethodPost, "/api/v1/alerts", http.StatusForbidden},
+		{"Listing tokens", http.MethodGet, "/api/v1/tokens", http.StatusForbidden},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := bearer(tc.method, tc.target)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantStatus, rec.Code)
+			if rec.Code != tc.wantStatus {
+				t.Errorf("Status = %d, want %d: %s", rec.Code, tc.wantStatus, rec.Body)
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The list shows the last use but never the token")
+	rec = sendAuth(h, http.MethodGet, "/api/v1/tokens", "", session)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"last_used_at"`) || strings.Contains(rec.Body.String(), created.Token) {
+		t.Errorf("List status = %d: %s", rec.Code, rec.Body)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Revoking the token")
+	if rec := sendAuth(h, http.MethodDelete, "/api/v1/tokens/"+created.ID, "", session); rec.Code != http.StatusNoContent {
+		t.Fatalf("Revoke status = %d: %s", rec.Code, rec.Body)
+	}
+	if rec := sendAuth(h, http.MethodDelete, "/api/v1/tokens/"+created.ID, "", session); rec.Code != http.StatusNotFound {
+		t.Errorf("Second revoke status = %d, want 404", rec.Code)
+	}
+	if rec := bearer(http.MethodGet, "/api/v1/alerts"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Revoked token status = %d, want 401", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestTokenHandler", true)
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+)
+
+const maxUnsubscribeFormBytes = 1 << 10
+
+// UnsubscribeHandler serves the pages that unsubscribe links in
+// notification emails open. The signed token in the link stands in for a
+// session, so recipients can opt out without signing in.
+type UnsubscribeHandler struct {
+	prefs  *notify.Preferences
+	logger *zap.Logger
+}
+
+// NewUnsubscribeHandler creates an UnsubscribeHandler.
+func NewUnsubscribeHandler(prefs *notify.Preferences, logger *zap.Logger) *UnsubscribeHandler {
+	return &UnsubscribeHandler{prefs: prefs, logger: logger}
+}
+
+// Register mounts the unsubscribe and preference center pages on mux.
+func (h *UnsubscribeHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /unsubscribe", h.Confirm)
+	mux.HandleFunc("POST /unsubscribe", h.Unsubscribe)
+	mux.HandleFunc("GET /notifications/preferences", h.Manage)
+	mux.HandleFunc("POST /notifications/preferences", h.Save)
+}
+
+var channelLabels = map[string]string{
+	domain.ChannelEmail:    "Email",
+	domain.ChannelTelegram: "Telegram",
+	domain.ChannelWebPush:  "Browser notifications",
+	domain.ChannelSMS:      "SMS",
+	domain.ChannelDiscord:  "Discord",
+	domain.ChannelSlack:    "Slack",
+}
+
+var topicLabels = map[string]string{
+	domain.TopicPriceAlerts: "Price alerts",
+}
+
+var frequencyLabels = []struct{ Value, Label string }{
+	{domain.FrequencyInstant, "As soon as they fire"},
+	{domain.FrequencyDaily, "In a daily digest"},
+	{domain.FrequencyWeekly, "In a weekly digest"},
+}
+
+type preferenceOption struct {
+	Field, Label string
+	On           bool
+}
+
+type frequencyOption struct {
+	Value, Label string
+	Selected     bool
+}
+
+type preferencePage struct {
+	Token        string
+	Channel      string
+	Confirm      bool
+	Unsubscribed bool
+	Manage       bool
+	Saved        bool
+	Invalid      bool
+	Channels     []preferenceOption
+	Topics       []preferenceOption
+	Frequencies  []frequencyOption
+}
+
+// Confirm asks before unsubscribing, since mail scanners follow links.
+func (h *UnsubscribeHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	_, channel, err := h.prefs.ParseUnsubscribeToken(token)
+	if err != nil {
+		h.render(w, http.StatusBadRequest, preferencePage{Invalid: true})
+		return
+	}
+	h.render(w, http.StatusOK, preferencePage{Token: token, Channel: channelLabels[channel], Confirm: true})
+}
+
+// Unsubscribe turns off the channel the link was sent on. Mail providers
+// post here directly for one-click unsubscribes (RFC 8058).
+func (h *UnsubscribeHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	_, channel, err := h.prefs.ParseUnsubscribeToken(token)
+	if err != nil {
+		h.render(w, http.StatusBadRequest, preferencePage{Invalid: true})
+		return
+	}
+	prefs, err := h.prefs.Unsubscribe(r.Context(), token)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	h.logger.Info("Unsubscribed from notifications",
+		zap.String("operation", "Unsubscribe"),
+		zap.String("user_id", prefs.UserID),
+		zap.String("channel", channel),
+	)
+	h.render(w, http.StatusOK, preferencePage{Token: token, Channel: channelLabels[channel], Unsubscribed: true})
+}
+
+// Manage shows every preference for the link's user.
+func (h *UnsubscribeHandler) Manage(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	userID, _, err := h.prefs.ParseUnsubscribeToken(token)
+	if err != nil {
+		h.render(w, http.StatusBadRequest, preferencePage{Invalid: true})
+		return
+	}
+	prefs, err := h.prefs.Get(r.Context(), userID)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	h.render(w, http.StatusOK, managePage(token, prefs, false))
+}
+
+// Save replaces the link's user's preferences with the submitted form.
+// Unchecked boxes are not submitted, so every option is read as off unless
+// present.
+func (h *UnsubscribeHandler) Save(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	userID, _, err := h.prefs.ParseUnsubscribeToken(token)
+	if err != nil {
+		h.render(w, http.StatusBadRequest, preferencePage{Invalid: true})
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxUnsubscribeFormBytes)
+	if err := r.ParseForm(); err != nil {
+		writeServiceError(w, r, h.logger, fmt.Errorf("read form: %v: %w", err, domain.ErrInvalid))
+		return
+	}
+	prefs, err := h.prefs.Get(r.Context(), userID)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	prefs.Frequency = r.PostForm.Get("frequency")
+	for _, c := range domain.Channels {
+		prefs.Channels[c] = r.PostForm.Has("channel_" + c)
+	}
+	for _, t := range domain.Topics {
+		prefs.Topics[t] = r.PostForm.Has("topic_" + t)
+	}
+	if prefs, err = h.prefs.Save(r.Context(), prefs); err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	h.render(w, http.StatusOK, managePage(token, prefs, true))
+}
+
+func managePage(token string, prefs domain.NotificationPreferences, saved bool) preferencePage {
+	page := preferencePage{Token: token, Manage: true, Saved: saved}
+	for _, c := range domain.Channels {
+		page.Channels = append(page.Channels, preferenceOption{Field: "channel_" + c, Label: channelLabels[c], On: prefs.ChannelEnabled(c)})
+	}
+	for _, t := range domain.Topics {
+		page.Topics = append(page.Topics, preferenceOption{Field: "topic_" + t, Label: topicLabels[t], On: prefs.TopicEnabled(t)})
+	}
+	for _, f := range frequencyLabels {
+		page.Frequencies = append(page.Frequencies, frequencyOption{Value: f.Value, Label: f.Label, Selected: f.Value == prefs.Frequency})
+	}
+	return page
+}
+
+func (h *UnsubscribeHandler) render(w http.ResponseWriter, status int, page preferencePage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, no-store")
+	// The token in the URL must not leak to other sites.
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	w.WriteHeader(status)
+	if err := preferencePageTemplate.Execute(w, page); err != nil {
+		h.logger.Error("Rendering preference page failed", zap.String("operation", "Unsubscribe"), zap.Error(err))
+	}
+}
+
+var preferencePageTemplate = template.Must(template.New("preferences").Parse(`<!doctype html>
+<html lang="en"><head><meta charset="utf-8"><meta name="viewport" content="width=device-width,initial-scale=1">
+<title>Notification preferences</title></head>
+<body><main>
+{{if .Invalid}}<h1>This link is not valid</h1>
+<p>Sign in to change your notification preferences.</p>
+{{else if .Confirm}}<h1>Unsubscribe?</h1>
+<p>You will stop getting {{.Channel}} notifications.</p>
+<form method="post" action="/unsubscribe?token={{.Token}}"><button type="submit">Unsubscribe</button></form>
+<p><a href="/notifications/preferences?token={{.Token}}">Change how often alerts arrive instead</a></p>
+{{else if .Unsubscribed}}<h1>You are unsubscribed</h1>
+<p>We won't send you {{.Channel}} notifications any more.</p>
+<p><a href="/notifications/preferences?token={{.Token}}">Manage notification preferences</a></p>
+{{else}}<h1>Notification preferences</h1>
+{{if .Saved}}<p role="status">Your preferences are saved.</p>{{end}}
+<form method="post" action="/notifications/preferences?token={{.Token}}">
+<fieldset><legend>Send notifications by</legend>
+{{range .Channels}}<label><input type="checkbox" name="{{.Field}}"{{if .On}} checked{{end}}> {{.Label}}</label><br>
+{{end}}</fieldset>
+<fieldset><legend>Notify me about</legend>
+{{range .Topics}}<label><input type="checkbox" name="{{.Field}}"{{if .On}} checked{{end}}> {{.Label}}</label><br>
+{{end}}</fieldset>
+<label>Price alerts <select name="frequency">
+{{range .Frequencies}}<option value="{{.Value}}"{{if .Selected}} selected{{end}}>{{.Label}}</option>
+{{end}}</select></label>
+<p><button type="submit">Save</button></p>
+</form>
+{{end}}</main></body></html>
+`))
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestUnsubscribeHandler(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestUnsubscribeHandler", "internal/handlers")
+
+	store := memory.NewStore()
+	prefs := notify.NewPreferences(store.Preferences()).WithSigningKey([]byte("test-only-secret"))
+	h := NewRouter(Deps{Logger: logger, Preferences: prefs})
+	token := url.QueryEscape(prefs.UnsubscribeToken("user_1", domain.ChannelEmail))
+	send := func(method, target, form string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	testCases := []struct {
+		name         string
+		method       string
+		target       string
+		form         string
+		wantStatus   int
+		wantBody     string
+		wantEmail    bool
+		wantTelegram bool
+		wantFreq     string
+	}{
+		{"Forged link", http.MethodGet, "/unsubscribe?token=dXNlcl8x.AAAA", "", http.StatusBadRequest, "This link is not valid", true, true, domain.FrequencyInstant},
+		{"Confirmation page", http.MethodGet, "/unsubscribe?token=" + token, "", http.StatusOK, "You will stop getting Email notifications", true, true, domain.FrequencyInstant},
+		{"One-click unsubscribe", http.MethodPost, "/unsubscribe?token=" + token, "List-Unsubscribe=One-Click", http.StatusOK, "You are unsubscribed", false, true, domain.FrequencyInstant},
+		{"Preference center", http.MethodGet, "/notifications/preferences?token=" + token, "", http.StatusOK, `name="channel_telegram" checked`, false, true, domain.FrequencyInstant},
+		{"Saving the form", http.MethodPost, "/notifications/preferences?token=" + token, "channel_email=on&topic_price_alerts=on&frequency=weekly", http.StatusOK, "Your preferences are saved", true, false, domain.FrequencyWeekly},
+		{"Invalid frequency", http.MethodPost, "/notifications/preferences?token=" + token, "frequency=hourly", http.StatusBadRequest, "frequency", true, false, domain.FrequencyWeekly},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := send(tc.method, tc.target, tc.form)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantStatus, rec.Code)
+			if rec.Code != tc.wantStatus || !strings.Contains(rec.Body.String(), tc.wantBody) {
+				t.Fatalf("Status = %d, want %d containing %q:\n%s", rec.Code, tc.wantStatus, tc.wantBody, rec.Body)
+			}
+			if rec.Code == http.StatusOK && (rec.Header().Get("Referrer-Policy") != "no-referrer" || rec.Header().Get("Cache-Control") != "private, no-store") {
+				t.Errorf("Headers = %v", rec.Header())
+			}
+			got, _ := prefs.Get(t.Context(), "user_1")
+			if got.ChannelEnabled(domain.ChannelEmail) != tc.wantEmail || got.ChannelEnabled(domain.ChannelTelegram) != tc.wantTelegram || got.Frequency != tc.wantFreq {
+				t.Errorf("Preferences = %+v", got)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestUnsubscribeHandler", true)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+	"github.com/yourusername/whey-price-compare/internal/services"
+)
+
+const maxWatchlistBodyBytes = 1 << 10
+
+// WatchlistHandler serves the signed-in user's watchlist.
+type WatchlistHandler struct {
+	watchlist *services.WatchlistService
+	logger    *zap.Logger
+}
+
+// NewWatchlistHandler creates a WatchlistHandler.
+func NewWatchlistHandler(svc *services.WatchlistService, logger *zap.Logger) *WatchlistHandler {
+	return &WatchlistHandler{watchlist: svc, logger: logger}
+}
+
+// Register mounts the watchlist routes on mux. They all require a
+// signed-in user.
+func (h *WatchlistHandler) Register(mux *http.ServeMux) {
+	mux.Handle("GET /api/v1/watchlist", auth.RequireUser(http.HandlerFunc(h.Portfolio)))
+	mux.Handle("POST /api/v1/watchlist", auth.RequireUser(http.HandlerFunc(h.Add)))
+	mux.Handle("DELETE /api/v1/watchlist/{productID}", auth.RequireUser(http.HandlerFunc(h.Remove)))
+}
+
+// Portfolio returns the watchlist with each product's best price now, its
+// change since it was added, and the basket total.
+func (h *WatchlistHandler) Portfolio(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	p, err := h.watchlist.Portfolio(r.Context(), u.ID)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	loc := i18n.FromContext(r.Context())
+	for _, e := range p.Items {
+		if e.Best != nil {
+			localizeOffer(loc, e.Best)
+		}
+	}
+	p.BasketTotalDisplay = loc.FormatPrice(p.Currency, p.BasketTotal)
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusOK, p)
+}
+
+// Add saves a product to the watchlist.
+func (h *WatchlistHandler) Add(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		ProductID string `json:"product_id"`
+	}
+	if !decodeJSON(w, r, maxWatchlistBodyBytes, &in) {
+		return
+	}
+	u := auth.UserFromContext(r.Context())
+	item, err := h.watchlist.Add(r.Context(), u.ID, in.ProductID)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	httpx.WriteJSON(w, http.StatusCreated, item)
+}
+
+// Remove deletes a product from the watchlist.
+func (h *WatchlistHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	u := auth.UserFromContext(r.Context())
+	if err := h.watchlist.Remove(r.Context(), u.ID, r.PathValue("productID")); err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
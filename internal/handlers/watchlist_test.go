@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/auth"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestWatchlistHandler_Lifecycle(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestWatchlistHandler_Lifecycle", "internal/handlers")
+
+	testhelpers.LogTestStep(logger, "arrange", "Accounts, a seeded catalog and a signed-in user")
+	cfg := auth.DefaultConfig()
+	cfg.Password = auth.PasswordParams{Time: 1, MemoryKiB: 64, Threads: 1, KeyLen: 32, SaltLen: 16}
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	authSvc := auth.NewService(cfg, auth.Repos{
+		Users:         store.Users(),
+		Sessions:      store.Sessions(),
+		Verifications: store.Verifications(),
+	}, logger)
+	prices := services.NewPriceService(services.PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+	watchlist := services.NewWatchlistService(store.Watchlists(), prices, logger)
+	h := NewRouter(Deps{Logger: logger, Auth: authSvc, Watchlist: watchlist})
+
+	sendAuth(h, http.MethodPost, "/api/v1/auth/register", `{"email":"asha@example.com","password":"test-only-password"}`)
+	rec := sendAuth(h, http.MethodPost, "/api/v1/auth/login", `{"email":"asha@example.com","password":"test-only-password"}`)
+	session := rec.Result().Cookies()[0]
+	body := `{"product_id":"` + testhelpers.FixtureProductID + `"}`
+
+	testhelpers.LogTestStep(logger, "act", "Adding signed out, then signed in, twice")
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/watchlist", body); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Signed-out add status = %d, want 401", rec.Code)
+	}
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/watchlist", body, session); rec.Code != http.StatusCreated {
+		t.Fatalf("Add status = %d: %s", rec.Code, rec.Body)
+	}
+	if rec := sendAuth(h, http.MethodPost, "/api/v1/watchlist", body, session); rec.Code != http.StatusConflict {
+		t.Errorf("Duplicate add status = %d, want 409", rec.Code)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The portfolio prices the product and totals the basket")
+	rec = sendAuth(h, http.MethodGet, "/api/v1/watchlist", "", session)
+	testhelpers.LogTestAssertion(logger, "portfolio status", http.StatusOK, rec.Code)
+	var p struct {
+		Items []struct {
+			ProductID string `json:"product_id"`
+			Best      struct {
+				Price        float64 `json:"price"`
+				PriceDisplay string  `json:"price_display"`
+			} `json:"best_offer"`
+		} `json:"items"`
+		BasketTotal        float64 `json:"basket_total"`
+		BasketTotalDisplay string  `json:"basket_total_display"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("Portfolio %d %s: %v", rec.Code, rec.Body, err)
+	}
+	if len(p.Items) != 1 || p.Items[0].Best.Price != 3199 || p.Items[0].Best.PriceDisplay != "₹3,199" ||
+		p.BasketTotal != 3199 || p.BasketTotalDisplay != "₹3,199" {
+		t.Errorf("Portfolio = %+v", p)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "private, no-store" {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+	target := "/api/v1/watchlist/" + testhelpers.FixtureProductID
+	if rec := sendAuth(h, http.MethodDelete, target, "", session); rec.Code != http.StatusNoContent {
+		t.Errorf("Remove status = %d, want 204", rec.Code)
+	}
+	if rec := sendAuth(h, http.MethodDelete, target, "", session); rec.Code != http.StatusNotFound {
+		t.Errorf("Second remove status = %d, want 404", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestWatchlistHandler_Lifecycle", true)
+}
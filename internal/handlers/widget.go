@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+	"github.com/yourusername/whey-price-compare/internal/services"
+)
+
+// Widget paths. Publishers include WidgetScriptPath once and mark up
+// placeholders; the script swaps each for an iframe of the widget page.
+const (
+	WidgetScriptPath = "/widget.js"
+	widgetPagePrefix = "/widget/"
+)
+
+// WidgetConfig configures the embeddable price widget.
+type WidgetConfig struct {
+	// BaseURL is the public origin the script points iframes and links at.
+	BaseURL string
+	// AllowedDomains may frame the widget. Subdomains are included, so
+	// "fitblog.in" also allows "www.fitblog.in". The site itself is always
+	// allowed.
+	AllowedDomains []string
+	// MaxAge is how long browsers and CDNs may cache a rendered widget.
+	MaxAge time.Duration
+}
+
+// DefaultWidgetConfig returns a config that renders for the site itself only.
+func DefaultWidgetConfig() WidgetConfig {
+	return WidgetConfig{BaseURL: "http://localhost:8080", MaxAge: 5 * time.Minute}
+}
+
+// WidgetHandler serves the embeddable best-price widget.
+type WidgetHandler struct {
+	cfg            WidgetConfig
+	prices         *services.PriceService
+	frameAncestors string
+	script         []byte
+	logger         *zap.Logger
+}
+
+// NewWidgetHandler creates a WidgetHandler.
+func NewWidgetHandler(cfg WidgetConfig, prices *services.PriceService, logger *zap.Logger) *WidgetHandler {
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = DefaultWidgetConfig().MaxAge
+	}
+	return &WidgetHandler{
+		cfg:            cfg,
+		prices:         prices,
+		frameAncestors: frameAncestors(cfg.AllowedDomains),
+		script:         []byte(strings.ReplaceAll(widgetScript, "{{BASE}}", template.JSEscapeString(cfg.BaseURL))),
+		logger:         logger,
+	}
+}
+
+// frameAncestors builds the CSP directive that lets browsers enforce the
+// allowlist. Enforcing it in the browser keeps the response identical for
+// every embedder, so CDNs can cache it.
+func frameAncestors(domains []string) string {
+	sources := []string{"'self'"}
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d == "" {
+			continue
+		}
+		sources = append(sources, "https://"+d, "https://*."+d)
+	}
+	return "frame-ancestors " + strings.Join(sources, " ")
+}
+
+// Register mounts the widget routes on mux.
+func (h *WidgetHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+WidgetScriptPath, h.Script)
+	mux.HandleFunc("GET "+widgetPagePrefix+"{productID}", h.Widget)
+}
+
+// Script serves the loader that turns placeholders into iframes.
+func (h *WidgetHandler) Script(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	_, _ = w.Write(h.script)
+}
+
+// Widget serves the iframe document for one product.
+func (h *WidgetHandler) Widget(w http.ResponseWriter, r *http.Request) {
+	productID := r.PathValue("productID")
+	comparison, err := h.prices.Compare(r.Context(), productID)
+	if err != nil {
+		writeServiceError(w, r, h.logger, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := widgetTemplate.Execute(&buf, h.view(i18n.FromContext(r.Context()), comparison)); err != nil {
+		writeServiceError(w, r, h.logger, fmt.Errorf("render widget: %w", err))
+		return
+	}
+
+	hdr := w.Header()
+	hdr.Set("Content-Type", "text/html; charset=utf-8")
+	hdr.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.cfg.MaxAge.Seconds())))
+	hdr.Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; "+h.frameAncestors)
+	hdr.Set("X-Robots-Tag", "noindex")
+	hdr.Set("Referrer-Policy", "strict-origin-when-cross-origin")
+	_, _ = w.Write(buf.Bytes())
+}
+
+type widgetView struct {
+	Lang        string
+	Name        string
+	Price       string
+	MRP         string
+	Discount    string
+	BestAt      string
+	MoreInStock string
+	ViewDeal    string
+	Compare     string
+	Unavailable string
+	BuyURL      string
+	CompareURL  string
+}
+
+func (h *WidgetHandler) view(loc *i18n.Locale, c *domain.Comparison) widgetView {
+	v := widgetView{
+		Lang:       loc.Tag,
+		Name:       strings.TrimSpace(c.Product.Brand + " " + c.Product.Name),
+		Compare:    loc.T(i18n.MsgWidgetCompare),
+		CompareURL: h.cfg.BaseURL + httpx.ComparePagePath(c.Product.ID),
+	}
+	best := c.BestDeal
+	if best == nil {
+		v.Unavailable = loc.T(i18n.MsgWidgetUnavailable)
+		return v
+	}
+	v.Price = loc.FormatPrice(best.Currency, best.Price)
+	if best.OriginalPrice > best.Price {
+		v.MRP = loc.FormatPrice(best.Currency, best.OriginalPrice)
+	}
+	if best.DiscountPercent >= 1 {
+		v.Discount = fmt.Sprintf("-%.0f%%", best.DiscountPercent)
+	}
+	v.BestAt = loc.T(i18n.MsgWidgetBestAt, best.RetailerName)
+	if others := c.Stats.RetailersInStock - 1; others > 0 {
+		v.MoreInStock = loc.T(i18n.MsgWidgetMoreInStock, others)
+	}
+	v.ViewDeal = loc.T(i18n.MsgWidgetViewDeal)
+	v.BuyURL = h.cfg.BaseURL + best.BuyURL
+	return v
+}
+
+var widgetTemplate = template.Must(template.New("widget").Parse(`<!doctype html>
+<html lang="{{.Lang}}"><head><meta charset="utf-8"><meta name="viewport" content="width=device-width,initial-scale=1">
+<title>{{.Name}} price</title>
+<style>body{margin:0;font:14px/1.4 system-ui,sans-serif;color:#1a1a1a}.w{border:1px solid #ddd;border-radius:8px;padding:12px}
+.n{font-weight:600;margin:0 0 6px}.p{font-size:22px;font-weight:700}.m{color:#777;text-decoration:line-through;margin-left:6px}
+.d{color:#0a7a33;margin-left:6px}.r{color:#555;margin:4px 0 10px}a{color:#0a58ca}.b{display:inline-block;background:#ff6a00;color:#fff;
+padding:6px 12px;border-radius:4px;text-decoration:none;margin-right:8px}</style></head>
+<body><div class="w"><p class="n">{{.Name}}</p>
+{{if .Unavailable}}<p class="r">{{.Unavailable}}</p><a href="{{.CompareURL}}" target="_blank" rel="noopener">{{.Compare}}</a>
+{{else}}<div><span class="p">{{.Price}}</span>{{with .MRP}}<span class="m">{{.}}</span>{{end}}{{with .Discount}}<span class="d">{{.}}</span>{{end}}</div>
+<p class="r">{{.BestAt}}{{with .MoreInStock}} · {{.}}{{end}}</p>
+<a class="b" href="{{.BuyURL}}" target="_blank" rel="sponsored noopener">{{.ViewDeal}}</a><a href="{{.CompareURL}}" target="_blank" rel="noopener">{{.Compare}}</a>
+{{end}}</div></body></html>
+`))
+
+// widgetScript replaces <div data-whey-widget="PRODUCT_ID"></div>
+// placeholders with widget iframes; data-lang picks the widget's locale.
+const widgetScript = `(function(){var b="{{BASE}}";
+function r(){var n=document.querySelectorAll("[data-whey-widget]:not([data-whey-loaded])");
+for(var i=0;i<n.length;i++){var e=n[i],f=document.createElement("iframe");e.setAttribute("data-whey-loaded","1");
+var l=e.getAttribute("data-lang");f.src=b+"/widget/"+encodeURIComponent(e.getAttribute("data-whey-widget"))+(l?"?lang="+encodeURIComponent(l):"");
+f.title="Whey protein price";
+f.loading="lazy";f.width=e.getAttribute("data-width")||"320";f.height=e.getAttribute("data-height")||"150";
+f.style.border="0";e.appendChild(f);}}
+if(document.readyState==="loading"){document.addEventListener("DOMContentLoaded",r);}else{r();}})();
+`
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestWidgetHandler(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestWidgetHandler", "internal/handlers")
+
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	cfg := DefaultWidgetConfig()
+	cfg.BaseURL = "https://proteinprices.example/"
+	cfg.AllowedDomains = []string{"fitblog.in", " "}
+	h := NewRouter(Deps{
+		Logger: logger,
+		Widget: cfg,
+		Prices: services.NewPriceService(services.PriceRepos{
+			Products:  store.Products(),
+			Retailers: store.Retailers(),
+			Listings:  store.Listings(),
+			Prices:    store.Prices(),
+		}, logger),
+	})
+
+	testhelpers.LogTestStep(logger, "act", "Rendering the widget for the fixture product")
+	rec := get(h, "/widget/"+testhelpers.FixtureProductID)
+
+	testhelpers.LogTestStep(logger, "assert", "Best price, tracked link and embedding policy")
+	testhelpers.LogTestAssertion(logger, "status", http.StatusOK, rec.Code)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"₹3,199",
+		"Best price at Flipkart",
+		`href="https://proteinprices.example/go/flipkart/prod_on_gsw?listing=lst_flipkart_gsw_2270"`,
+		`rel="sponsored noopener"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Widget body missing %q", want)
+		}
+	}
+	csp := rec.Header().Get("Content-Security-Policy")
+	if !strings.Contains(csp, "frame-ancestors 'self' https://fitblog.in https://*.fitblog.in") {
+		t.Errorf("CSP = %q", csp)
+	}
+	if rec.Header().Get("Cache-Control") != "public, max-age=300" {
+		t.Errorf("Cache-Control = %q", rec.Header().Get("Cache-Control"))
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Loader script and unknown products")
+	script := get(h, WidgetScriptPath)
+	if !strings.Contains(script.Body.String(), `"https://proteinprices.example"`) ||
+		!strings.HasPrefix(script.Header().Get("Content-Type"), "text/javascript") {
+		t.Errorf("Script response = %q %q", script.Header().Get("Content-Type"), script.Body.String())
+	}
+	if rec := get(h, "/widget/prod_missing"); rec.Code != http.StatusNotFound {
+		t.Errorf("Missing product status = %d, want 404", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestWidgetHandler", true)
+}
@@ -0,0 +1,243 @@
+// Package health serves liveness and readiness probes. Liveness only says
+// the process is serving HTTP; readiness actively probes every registered
+// dependency with a timeout and reports each one's status, so orchestrators
+// stop routing traffic to an instance that cannot do useful work.
+package health
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+)
+
+// Probe paths.
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+	// ReportPath serves the readiness report under the path documented in
+	// the API specification.
+	ReportPath = "/health"
+)
+
+// Status is the state of one dependency or of the whole service.
+type Status string
+
+// Statuses, from best to worst.
+const (
+	StatusHealthy   Status = "healthy"
+	StatusDegraded  Status = "degraded"
+	StatusUnhealthy Status = "unhealthy"
+)
+
+// Check probes one dependency.
+type Check struct {
+	Name string
+	// Critical dependencies make the service unready when they fail; others
+	// only degrade it.
+	Critical bool
+	// Timeout overrides Config.Timeout for this check.
+	Timeout time.Duration
+	Probe   func(ctx context.Context) error
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Status         Status  `json:"status"`
+	Critical       bool    `json:"critical"`
+	ResponseTimeMs float64 `json:"response_time_ms"`
+	Error          string  `json:"error,omitempty"`
+}
+
+// Report is the readiness response body.
+type Report struct {
+	Status        Status            `json:"status"`
+	Timestamp     time.Time         `json:"timestamp"`
+	Version       string            `json:"version,omitempty"`
+	Environment   string            `json:"environment,omitempty"`
+	UptimeSeconds int64             `json:"uptime_seconds"`
+	Draining      bool              `json:"draining,omitempty"`
+	Services      map[string]Result `json:"services"`
+}
+
+// Config configures the Checker.
+type Config struct {
+	Version     string
+	Environment string
+	// Timeout bounds each check that does not set its own.
+	Timeout time.Duration
+}
+
+// DefaultConfig returns a two second per-check timeout, comfortably below
+// the usual five second probe timeout of orchestrators.
+func DefaultConfig() Config {
+	return Config{Timeout: 2 * time.Second}
+}
+
+// Checker runs the registered checks.
+type Checker struct {
+	cfg      Config
+	logger   *zap.Logger
+	started  time.Time
+	now      func() time.Time
+	draining atomic.Bool
+
+	mu     sync.RWMutex
+	checks []Check
+}
+
+// NewChecker creates a Checker with no checks.
+func NewChecker(cfg Config, logger *zap.Logger) *Checker {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultConfig().Timeout
+	}
+	return &Checker{cfg: cfg, logger: logger, started: time.Now(), now: time.Now}
+}
+
+// Add registers a dependency check.
+func (c *Checker) Add(check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, check)
+}
+
+// SetDraining marks the service as shutting down. Readiness then fails so
+// load balancers stop sending new requests while in-flight ones finish.
+func (c *Checker) SetDraining(draining bool) { c.draining.Store(draining) }
+
+// Check runs every check concurrently and summarises the results.
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := append([]Check(nil), c.checks...)
+	c.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	now := c.now()
+	report := Report{
+		Status:        StatusHealthy,
+		Timestamp:     now.UTC(),
+		Version:       c.cfg.Version,
+		Environment:   c.cfg.Environment,
+		UptimeSeconds: int64(now.Sub(c.started).Seconds()),
+		Draining:      c.draining.Load(),
+		Services:      make(map[string]Result, len(checks)),
+	}
+	for i, check := range checks {
+		r := results[i]
+		report.Services[check.Name] = r
+		switch {
+		case r.Status == StatusHealthy:
+		case check.Critical:
+			report.Status = StatusUnhealthy
+		case report.Status == StatusHealthy:
+			report.Status = StatusDegraded
+		}
+	}
+	if report.Draining {
+		report.Status = StatusUnhealthy
+	}
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) (r Result) {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = c.cfg.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	r = Result{Status: StatusHealthy, Critical: check.Critical}
+	start := time.Now()
+	defer func() {
+		r.ResponseTimeMs = float64(time.Since(start).Microseconds()) / 1000
+	}()
+
+	// Run the probe separately so a probe that ignores its context cannot
+	// hold the readiness response past the timeout.
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- panicError{p}
+			}
+		}()
+		done <- check.Probe(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		r.Status = StatusUnhealthy
+		r.Error = err.Error()
+		if ctx.Err() == context.DeadlineExceeded {
+			r.Error = "timed out after " + timeout.String()
+		}
+	}
+	return r
+}
+
+type panicError struct{ v any }
+
+func (p panicError) Error() string { return "check panicked" }
+
+// Register mounts the probe routes on mux.
+func (c *Checker) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+LivenessPath, c.Live)
+	mux.HandleFunc("GET "+ReadinessPath, c.Ready)
+	mux.HandleFunc("GET "+ReportPath, c.Ready)
+}
+
+// Live reports that the process is up. It never touches dependencies: a
+// database outage must not get every replica restarted.
+func (c *Checker) Live(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{
+		"status":         StatusHealthy,
+		"uptime_seconds": int64(c.now().Sub(c.started).Seconds()),
+	})
+}
+
+// Ready probes the dependencies and answers 503 when a critical one fails or
+// the service is draining.
+func (c *Checker) Ready(w http.ResponseWriter, r *http.Request) {
+	report := c.Check(r.Context())
+	status := http.StatusOK
+	if report.Status == StatusUnhealthy {
+		status = http.StatusServiceUnavailable
+		failing := make([]string, 0, len(report.Services))
+		for name, res := range report.Services {
+			if res.Status != StatusHealthy {
+				failing = append(failing, name)
+			}
+		}
+		sort.Strings(failing)
+		c.logger.Warn("Readiness check failed",
+			zap.String("operation", "Readiness"),
+			zap.Strings("failing", failing),
+			zap.Bool("draining", report.Draining),
+		)
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, status, report)
+}
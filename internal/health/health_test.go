@@ -0,0 +1,166 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func ok(context.Context) error { return nil }
+
+func failing(context.Context) error { return errors.New("connection refused") }
+
+func TestChecker_Check(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestChecker_Check", "internal/health")
+
+	tests := []struct {
+		name     string
+		checks   []Check
+		draining bool
+		want     Status
+	}{
+		{name: "no checks", want: StatusHealthy},
+		{
+			name:   "all healthy",
+			checks: []Check{{Name: "database", Critical: true, Probe: ok}, {Name: "cache", Probe: ok}},
+			want:   StatusHealthy,
+		},
+		{
+			name:   "non-critical failure degrades",
+			checks: []Check{{Name: "database", Critical: true, Probe: ok}, {Name: "cache", Probe: failing}},
+			want:   StatusDegraded,
+		},
+		{
+			name:   "critical failure is unhealthy",
+			checks: []Check{{Name: "database", Critical: true, Probe: failing}, {Name: "cache", Probe: failing}},
+			want:   StatusUnhealthy,
+		},
+		{
+			name:     "draining is unhealthy",
+			checks:   []Check{{Name: "database", Critical: true, Probe: ok}},
+			draining: true,
+			want:     StatusUnhealthy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testhelpers.LogTestStep(logger, "arrange", tt.name)
+			c := NewChecker(DefaultConfig(), logger)
+			for _, check := range tt.checks {
+				c.Add(check)
+			}
+			c.SetDraining(tt.draining)
+
+			testhelpers.LogTestStep(logger, "act", "Running checks")
+			report := c.Check(t.Context())
+
+			testhelpers.LogTestStep(logger, "assert", "Overall status and per-service results")
+			testhelpers.LogTestAssertion(logger, "status", tt.want, report.Status)
+			if report.Status != tt.want {
+				t.Errorf("Status = %q, want %q", report.Status, tt.want)
+			}
+			if len(report.Services) != len(tt.checks) {
+				t.Errorf("Services = %d entries, want %d", len(report.Services), len(tt.checks))
+			}
+			for _, check := range tt.checks {
+				res := report.Services[check.Name]
+				if res.Critical != check.Critical {
+					t.Errorf("%s critical = %v", check.Name, res.Critical)
+				}
+				if (res.Status == StatusHealthy) != (res.Error == "") {
+					t.Errorf("%s result inconsistent: %+v", check.Name, res)
+				}
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestChecker_Check", true)
+}
+
+func TestChecker_Timeout(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestChecker_Timeout", "internal/health")
+
+	testhelpers.LogTestStep(logger, "arrange", "A probe that ignores its context and one that panics")
+	release := make(chan struct{})
+	defer close(release)
+	c := NewChecker(Config{Timeout: 20 * time.Millisecond}, logger)
+	c.Add(Check{Name: "queue", Critical: true, Probe: func(context.Context) error {
+		<-release
+		return nil
+	}})
+	c.Add(Check{Name: "redis", Probe: func(context.Context) error { panic("boom") }})
+
+	testhelpers.LogTestStep(logger, "act", "Running checks")
+	start := time.Now()
+	report := c.Check(t.Context())
+	elapsed := time.Since(start)
+
+	testhelpers.LogTestStep(logger, "assert", "The hung probe is abandoned at its timeout")
+	testhelpers.LogTestAssertion(logger, "status", StatusUnhealthy, report.Status)
+	if elapsed > time.Second {
+		t.Errorf("Check took %v, want it bounded by the timeout", elapsed)
+	}
+	if got := report.Services["queue"].Error; got != "timed out after 20ms" {
+		t.Errorf("queue error = %q", got)
+	}
+	if got := report.Services["redis"]; got.Status != StatusUnhealthy || got.Error != "check panicked" {
+		t.Errorf("redis = %+v", got)
+	}
+	if report.Status != StatusUnhealthy {
+		t.Errorf("Status = %q", report.Status)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestChecker_Timeout", true)
+}
+
+func TestChecker_Endpoints(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestChecker_Endpoints", "internal/health")
+
+	testhelpers.LogTestStep(logger, "arrange", "A checker with a failing critical dependency")
+	c := NewChecker(Config{Version: "1.2.3", Environment: "test"}, logger)
+	c.Add(Check{Name: "database", Critical: true, Probe: failing})
+	mux := http.NewServeMux()
+	c.Register(mux)
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantBody   Status
+	}{
+		{LivenessPath, http.StatusOK, StatusHealthy},
+		{ReadinessPath, http.StatusServiceUnavailable, StatusUnhealthy},
+		{ReportPath, http.StatusServiceUnavailable, StatusUnhealthy},
+	}
+	for _, tt := range tests {
+		testhelpers.LogTestStep(logger, "act", "GET "+tt.path)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		var body Report
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: decode: %v", tt.path, err)
+		}
+		testhelpers.LogTestAssertion(logger, tt.path+" status", tt.wantStatus, rec.Code)
+		if rec.Code != tt.wantStatus || body.Status != tt.wantBody {
+			t.Errorf("%s = %d %q, want %d %q", tt.path, rec.Code, body.Status, tt.wantStatus, tt.wantBody)
+		}
+		if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+			t.Errorf("%s Cache-Control = %q", tt.path, got)
+		}
+		if tt.path != LivenessPath && (body.Version != "1.2.3" || body.Services["database"].Error != "connection refused") {
+			t.Errorf("%s report = %+v", tt.path, body)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestChecker_Endpoints", true)
+}
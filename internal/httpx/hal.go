@@ -0,0 +1,127 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Link is a HAL link object.
+type Link struct {
+	Href      string `json:"href"`
+	Templated bool   `json:"templated,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Title     string `json:"title,omitempty"`
+}
+
+// Resource decorates a JSON body with HAL _links and _embedded members. The
+// body must marshal to a JSON object; its fields are emitted unchanged so the
+// HAL representation is a superset of the plain JSON one.
+type Resource struct {
+	body     any
+	links    map[string]any
+	order    []string
+	embedded map[string]any
+	embOrder []string
+}
+
+// NewResource creates a resource for body with a self link.
+func NewResource(body any, self string) *Resource {
+	r := &Resource{body: body, links: make(map[string]any), embedded: make(map[string]any)}
+	return r.Link("self", Link{Href: self})
+}
+
+// Link sets a relation that always points at a single target.
+func (r *Resource) Link(rel string, l Link) *Resource {
+	if _, ok := r.links[rel]; !ok {
+		r.order = append(r.order, rel)
+	}
+	r.links[rel] = l
+	return r
+}
+
+// AddLink appends to a relation that may have several targets; such
+// relations are always serialized as arrays.
+func (r *Resource) AddLink(rel string, l Link) *Resource {
+	existing, ok := r.links[rel]
+	if !ok {
+		r.order = append(r.order, rel)
+	}
+	list, _ := existing.([]Link)
+	r.links[rel] = append(list, l)
+	return r
+}
+
+// Embed attaches nested resources under rel.
+func (r *Resource) Embed(rel string, v any) *Resource {
+	if _, ok := r.embedded[rel]; !ok {
+		r.embOrder = append(r.embOrder, rel)
+	}
+	r.embedded[rel] = v
+	return r
+}
+
+// MarshalJSON renders the body followed by _links and _embedded.
+func (r *Resource) MarshalJSON() ([]byte, error) {
+	body := []byte("{}")
+	if r.body != nil {
+		var err error
+		if body, err = json.Marshal(r.body); err != nil {
+			return nil, err
+		}
+	}
+	body = bytes.TrimSpace(body)
+	if len(body) < 2 || body[0] != '{' {
+		return nil, fmt.Errorf("hal: resource body must be a JSON object, got %T", r.body)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(body[:len(body)-1])
+	sep := ","
+	if len(bytes.TrimSpace(body[1:len(body)-1])) == 0 {
+		sep = ""
+	}
+	for _, member := range []struct {
+		name  string
+		order []string
+		items map[string]any
+	}{{"_links", r.order, r.links}, {"_embedded", r.embOrder, r.embedded}} {
+		if len(member.order) == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, `%s"%s":{`, sep, member.name)
+		for i, rel := range member.order {
+			v, err := json.Marshal(member.items[rel])
+			if err != nil {
+				return nil, err
+			}
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(rel)
+			buf.Write(key)
+			buf.WriteByte(':')
+			buf.Write(v)
+		}
+		buf.WriteByte('}')
+		sep = ","
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// WriteHAL writes res as application/hal+json.
+func WriteHAL(w http.ResponseWriter, status int, res *Resource) {
+	w.Header().Set("Content-Type", MediaTypeHAL+"; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// WantsHAL reports whether the client prefers HAL over plain JSON. Either
+// way the response varies on Accept, which is recorded for caches.
+func WantsHAL(w http.ResponseWriter, r *http.Request) bool {
+	AddVary(w.Header(), "Accept")
+	return NegotiateMediaType(r.Header.Get("Accept"), MediaTypeJSON, MediaTypeHAL) == MediaTypeHAL
+}
@@ -0,0 +1,71 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestResource_MarshalJSON(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestResource_MarshalJSON", "internal/httpx")
+
+	type product struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	testhelpers.LogTestStep(logger, "arrange", "Resource with single, array and embedded members")
+	res := NewResource(product{ID: "prod_1", Name: "Whey"}, "/api/v1/products/prod_1").
+		Link("history", Link{Href: "/api/v1/products/prod_1/price-history{?days}", Templated: true}).
+		AddLink("retailers", Link{Href: "/api/v1/retailers/amazon", Name: "amazon"}).
+		Embed("variants", []map[string]string{{"id": "var_1"}})
+
+	testhelpers.LogTestStep(logger, "act", "Marshalling")
+	raw, err := json.Marshal(res)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Body fields kept, links and embedded appended")
+	want := `{"id":"prod_1","name":"Whey","_links":{"self":{"href":"/api/v1/products/prod_1"},` +
+		`"history":{"href":"/api/v1/products/prod_1/price-history{?days}","templated":true},` +
+		`"retailers":[{"href":"/api/v1/retailers/amazon","name":"amazon"}]},"_embedded":{"variants":[{"id":"var_1"}]}}`
+	testhelpers.LogTestAssertion(logger, "hal document", want, string(raw))
+	if string(raw) != want {
+		t.Errorf("Marshal =\n%s\nwant\n%s", raw, want)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Empty and non-object bodies")
+	if raw, _ := json.Marshal(NewResource(nil, "/x")); string(raw) != `{"_links":{"self":{"href":"/x"}}}` {
+		t.Errorf("Empty body = %s", raw)
+	}
+	if _, err := json.Marshal(NewResource([]int{1}, "/x")); err == nil {
+		t.Error("Array bodies must be rejected")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestResource_MarshalJSON", true)
+}
+
+func TestWantsHAL(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestWantsHAL", "internal/httpx")
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", MediaTypeHAL)
+	rec := httptest.NewRecorder()
+
+	got := WantsHAL(rec, req)
+	testhelpers.LogTestAssertion(logger, "wants hal", true, got)
+	if !got {
+		t.Error("WantsHAL = false for Accept: application/hal+json")
+	}
+	if rec.Header().Get("Vary") != "Accept" {
+		t.Errorf("Vary = %q, want Accept", rec.Header().Get("Vary"))
+	}
+
+	testhelpers.LogTestComplete(logger, "TestWantsHAL", true)
+}
@@ -0,0 +1,57 @@
+package httpx
+
+import "net/url"
+
+// Public web page paths. The API serves JSON under /api/v1; these are the
+// human-facing pages that feeds, sitemaps and share links point at.
+
+// ProductPagePath is the product detail page.
+func ProductPagePath(productID string) string {
+	return "/products/" + url.PathEscape(productID)
+}
+
+// ComparePagePath is the cross-retailer price comparison page for a product.
+func ComparePagePath(productID string) string {
+	return "/compare/" + url.PathEscape(productID)
+}
+
+// OutboundPath is the tracked redirect to a retailer's product page. The
+// listing is optional; without it the cheapest in-stock listing is used.
+func OutboundPath(retailerID, productID, listingID string) string {
+	p := "/go/" + url.PathEscape(retailerID) + "/" + url.PathEscape(productID)
+	if listingID != "" {
+		p += "?listing=" + url.QueryEscape(listingID)
+	}
+	return p
+}
+
+// SharePath is the page a deal share link opens.
+func SharePath(token string) string {
+	return "/s/" + url.PathEscape(token)
+}
+
+// ReferralParam tags links on pages such as shared deals, so outbound
+// clicks from them are attributed to the referral.
+const ReferralParam = "ref"
+
+// WithReferral adds ReferralParam to a site-relative link.
+func WithReferral(link, referral string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	q := u.Query()
+	q.Set(ReferralParam, referral)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// Surrogate keys tag CDN-cached responses so they can be purged together.
+const (
+	DealsSurrogateKey  = "deals"
+	PricesSurrogateKey = "prices"
+)
+
+// ProductSurrogateKey tags every cached response that shows a product's
+// data, so one purge refreshes all of them.
+func ProductSurrogateKey(productID string) string { return "product-" + productID }
@@ -0,0 +1,91 @@
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Media types the API can respond with.
+const (
+	MediaTypeJSON = "application/json"
+	MediaTypeHAL  = "application/hal+json"
+)
+
+// ParseQuality splits one element of an Accept-style header into its
+// lower-cased value and q parameter (1 when absent, 0 when malformed).
+func ParseQuality(part string) (string, float64) {
+	name, params, _ := strings.Cut(part, ";")
+	name = strings.ToLower(strings.TrimSpace(name))
+	q := 1.0
+	for _, param := range strings.Split(params, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || strings.TrimSpace(key) != "q" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return name, 0
+		}
+		q = parsed
+	}
+	return name, q
+}
+
+// NegotiateMediaType picks the offer the Accept header rates highest. Exact
+// matches beat type/* which beats */*; ties go to the earlier offer. It
+// returns offers[0] when the header is empty and "" when nothing is
+// acceptable.
+func NegotiateMediaType(accept string, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		q, specificity := 0.0, -1
+		for _, part := range strings.Split(accept, ",") {
+			mediaRange, rangeQ := ParseQuality(part)
+			s := matchMediaRange(mediaRange, offer)
+			if s > specificity {
+				q, specificity = rangeQ, s
+			}
+		}
+		if specificity >= 0 && q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// matchMediaRange reports how specifically mediaRange matches offer: 2 for
+// an exact match, 1 for type/*, 0 for */* and -1 for no match.
+func matchMediaRange(mediaRange, offer string) int {
+	switch {
+	case mediaRange == offer:
+		return 2
+	case mediaRange == "*/*" || mediaRange == "*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*"):
+		typ, _, _ := strings.Cut(offer, "/")
+		if strings.TrimSuffix(mediaRange, "/*") == typ {
+			return 1
+		}
+	}
+	return -1
+}
+
+// AddVary appends value to the Vary header unless it is already listed.
+func AddVary(h http.Header, value string) {
+	for _, existing := range h.Values("Vary") {
+		for _, v := range strings.Split(existing, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), value) {
+				return
+			}
+		}
+	}
+	h.Add("Vary", value)
+}
@@ -0,0 +1,59 @@
+package httpx
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestNegotiateMediaType(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestNegotiateMediaType", "internal/httpx")
+
+	testCases := []struct {
+		name   string
+		accept string
+		expect string
+	}{
+		{"No header defaults to first offer", "", MediaTypeJSON},
+		{"Exact HAL", "application/hal+json", MediaTypeHAL},
+		{"Wildcard keeps JSON", "*/*", MediaTypeJSON},
+		{"Type wildcard ties go to JSON", "application/*", MediaTypeJSON},
+		{"HAL preferred by q", "application/json;q=0.5, application/hal+json", MediaTypeHAL},
+		{"JSON preferred by q", "application/hal+json;q=0.4, application/json", MediaTypeJSON},
+		{"Specific range beats wildcard", "application/hal+json;q=0.9, */*;q=0.1", MediaTypeHAL},
+		{"Explicit refusal", "application/json;q=0, application/hal+json;q=0", ""},
+		{"Nothing acceptable", "text/html", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := NegotiateMediaType(tc.accept, MediaTypeJSON, MediaTypeHAL)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.expect, got)
+			if got != tc.expect {
+				t.Errorf("NegotiateMediaType(%q) = %q, want %q", tc.accept, got, tc.expect)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestNegotiateMediaType", true)
+}
+
+func TestAddVary(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAddVary", "internal/httpx")
+
+	h := http.Header{}
+	h.Set("Vary", "Accept-Encoding, accept")
+	AddVary(h, "Accept")
+	AddVary(h, "Origin")
+
+	got := h.Values("Vary")
+	testhelpers.LogTestAssertion(logger, "vary values", 2, len(got))
+	if len(got) != 2 || got[1] != "Origin" {
+		t.Errorf("Vary = %v, want existing value plus Origin", got)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAddVary", true)
+}
@@ -0,0 +1,17 @@
+package httpx
+
+import "context"
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the authenticated caller's ID.
+func WithPrincipal(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, principalKey{}, id)
+}
+
+// Principal returns the authenticated caller's ID, or "" for anonymous
+// requests.
+func Principal(ctx context.Context) string {
+	id, _ := ctx.Value(principalKey{}).(string)
+	return id
+}
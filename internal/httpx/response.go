@@ -0,0 +1,117 @@
+// Package httpx holds the HTTP response conventions shared by handlers and
+// middleware: JSON encoding and the standard error envelope described in
+// docs/api/api_specification_complete.md.
+package httpx
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/jsonx"
+)
+
+// Standard error codes from the API specification.
+const (
+	CodeBadRequest           = "BAD_REQUEST"
+	CodeUnauthorized         = "UNAUTHORIZED"
+	CodeForbidden            = "FORBIDDEN"
+	CodeNotFound             = "NOT_FOUND"
+	CodeConflict             = "CONFLICT"
+	CodePreconditionRequired = "PRECONDITION_REQUIRED"
+	CodeUnprocessableEntity  = "UNPROCESSABLE_ENTITY"
+	CodeRateLimitExceeded    = "RATE_LIMIT_EXCEEDED"
+	CodeInternal             = "INTERNAL_ERROR"
+	CodeServiceUnavailable   = "SERVICE_UNAVAILABLE"
+	CodeGatewayTimeout       = "GATEWAY_TIMEOUT"
+)
+
+// RequestIDHeader carries the correlation ID for a request.
+const RequestIDHeader = "X-Request-ID"
+
+// APIError is the body of every error response.
+type APIError struct {
+	Code      string         `json:"code"`
+	Message   string         `json:"message"`
+	Details   map[string]any `json:"details,omitempty"`
+	Timestamp time.Time      `json:"timestamp"`
+	RequestID string         `json:"request_id,omitempty"`
+}
+
+// ErrorResponse wraps APIError in the {"error": {...}} envelope.
+type ErrorResponse struct {
+	Error APIError `json:"error"`
+}
+
+// maxPooledBuffer keeps one huge response from pinning its buffer in the
+// pool for the life of the process.
+const maxPooledBuffer = 64 << 10
+
+var bufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// WriteJSON writes v as a JSON response with the given status. Values
+// implementing jsonx.Appender are encoded without reflection. The body is
+// built in a pooled buffer and written in one call with its length.
+func WriteJSON(w http.ResponseWriter, status int, v any) {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		if buf.Cap() <= maxPooledBuffer {
+			buf.Reset()
+			bufferPool.Put(buf)
+		}
+	}()
+	buf.Reset()
+	if a, ok := v.(jsonx.Appender); ok {
+		buf.Write(append(a.AppendJSON(buf.AvailableBuffer()), '\n'))
+	} else if err := json.NewEncoder(buf).Encode(v); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// WriteError writes a standard error envelope.
+func WriteError(w http.ResponseWriter, r *http.Request, status int, code, message string, details map[string]any) {
+	WriteJSON(w, status, ErrorResponse{Error: APIError{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		Timestamp: time.Now().UTC(),
+		RequestID: RequestID(r),
+	}})
+}
+
+// RequestID returns the correlation ID for r, or "" when none was supplied.
+func RequestID(r *http.Request) string {
+	if r == nil {
+		return ""
+	}
+	return r.Header.Get(RequestIDHeader)
+}
+
+// ClientIP returns the caller's address. With trustProxy set, the last hop
+// in X-Forwarded-For wins since that is the one our own load balancer
+// appended; earlier entries are client-controlled.
+func ClientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			parts := strings.Split(fwd, ",")
+			if ip := strings.TrimSpace(parts[len(parts)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,112 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// appenderValue encodes itself differently from its struct tags, so the tests
+// can tell which encoder WriteJSON used.
+type appenderValue struct {
+	Name string `json:"name"`
+}
+
+func (v appenderValue) AppendJSON(b []byte) []byte {
+	return append(b, `{"appended":true}`...)
+}
+
+func TestWriteJSON(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestWriteJSON", "internal/httpx")
+
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{"reflection", map[string]any{"name": "whey"}, `{"name":"whey"}` + "\n"},
+		{"appender", appenderValue{Name: "whey"}, `{"appended":true}` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			WriteJSON(rec, http.StatusCreated, tt.v)
+
+			testhelpers.LogTestAssertion(logger, tt.name, tt.want, rec.Body.String())
+			if rec.Code != http.StatusCreated {
+				t.Errorf("Status = %d, want %d", rec.Code, http.StatusCreated)
+			}
+			if rec.Body.String() != tt.want {
+				t.Errorf("Body = %q, want %q", rec.Body.String(), tt.want)
+			}
+			if cl := rec.Header().Get("Content-Length"); cl != strconv.Itoa(len(tt.want)) {
+				t.Errorf("Content-Length = %q, want %d", cl, len(tt.want))
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Unencodable values fail with 500")
+	rec := httptest.NewRecorder()
+	WriteJSON(rec, http.StatusOK, map[string]any{"bad": make(chan int)})
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestWriteJSON", true)
+}
+
+func BenchmarkWriteJSON(b *testing.B) {
+	v := map[string]any{"product_id": "prod_on_gsw", "price": 3299.0, "in_stock": true}
+	b.ReportAllocs()
+	for b.Loop() {
+		WriteJSON(httptest.NewRecorder(), http.StatusOK, v)
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestWriteError", "internal/httpx")
+
+	testhelpers.LogTestStep(logger, "arrange", "Request carrying a correlation ID")
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/products/prod_invalid", nil)
+	req.Header.Set(RequestIDHeader, "req_abc123")
+	rec := httptest.NewRecorder()
+
+	testhelpers.LogTestStep(logger, "act", "Writing not found error")
+	WriteError(rec, req, http.StatusNotFound, CodeNotFound, "Product not found", map[string]any{
+		"product_id": "prod_invalid",
+	})
+
+	testhelpers.LogTestStep(logger, "assert", "Validating error envelope")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	var body ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode error body: %v", err)
+	}
+	testhelpers.LogTestAssertion(logger, "error code", CodeNotFound, body.Error.Code)
+	if body.Error.Code != CodeNotFound {
+		t.Errorf("Code = %q, want %q", body.Error.Code, CodeNotFound)
+	}
+	if body.Error.RequestID != "req_abc123" {
+		t.Errorf("RequestID = %q, want req_abc123", body.Error.RequestID)
+	}
+	if body.Error.Details["product_id"] != "prod_invalid" {
+		t.Errorf("Details = %v", body.Error.Details)
+	}
+	if body.Error.Timestamp.IsZero() {
+		t.Error("Timestamp must be set")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestWriteError", true)
+}
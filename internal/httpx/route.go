@@ -0,0 +1,55 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+)
+
+type routeKey struct{}
+
+// routeSlot receives the pattern of the ServeMux route that served a
+// request. Muxes set r.Pattern on the request they were given, which
+// middleware outside them never sees, so the pattern travels back here.
+type routeSlot struct {
+	pattern string
+}
+
+// TrackRoute returns a copy of ctx in which RecordRoute records the route
+// serving the request, for Route to read once it is served. A ctx already
+// tracked is returned as is, so middleware reading the route share it.
+func TrackRoute(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(routeKey{}).(*routeSlot); ok {
+		return ctx
+	}
+	return TrackSubrequestRoute(ctx)
+}
+
+// TrackSubrequestRoute is TrackRoute for a request served within another,
+// such as a batch part, whose route is its own: recorded in the outer
+// request's context, it would be taken for the outer route.
+func TrackSubrequestRoute(ctx context.Context) context.Context {
+	return context.WithValue(ctx, routeKey{}, &routeSlot{})
+}
+
+// RecordRoute serves requests with mux and records the pattern that
+// matched them for TrackRoute. With muxes nested, the innermost match is
+// kept: the admin mux's "GET /api/v1/admin/audit" rather than the
+// "/api/v1/admin/" it is mounted on.
+func RecordRoute(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r)
+		if slot, ok := r.Context().Value(routeKey{}).(*routeSlot); ok && slot.pattern == "" {
+			slot.pattern = r.Pattern
+		}
+	})
+}
+
+// Route returns the pattern recorded for the request served under ctx, or
+// "" if it matched no route or ctx was not tracked.
+func Route(ctx context.Context) string {
+	slot, _ := ctx.Value(routeKey{}).(*routeSlot)
+	if slot == nil {
+		return ""
+	}
+	return slot.pattern
+}
@@ -0,0 +1,173 @@
+// Package i18n negotiates the caller's locale from Accept-Language and
+// formats prices, pack weights and user-facing messages for it. en-IN is the
+// default and the fallback for anything unsupported.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+)
+
+// WeightSystem selects the unit pack sizes are shown in.
+type WeightSystem int
+
+// Weight systems.
+const (
+	Metric WeightSystem = iota
+	Imperial
+)
+
+// Locale describes how to present values to one audience.
+type Locale struct {
+	// Tag is the BCP 47 tag sent back in Content-Language.
+	Tag string
+	// IndianGrouping groups digits 12,34,567 instead of 1,234,567.
+	IndianGrouping bool
+	Weights        WeightSystem
+	messages       map[string]string
+}
+
+const defaultTag = "en-IN"
+
+var locales = map[string]*Locale{
+	"en-IN": {Tag: "en-IN", IndianGrouping: true, Weights: Metric, messages: english},
+	"hi-IN": {Tag: "hi-IN", IndianGrouping: true, Weights: Metric, messages: hindi},
+	"en-GB": {Tag: "en-GB", Weights: Metric, messages: english},
+	"en-US": {Tag: "en-US", Weights: Imperial, messages: english},
+}
+
+// Default returns the en-IN locale.
+func Default() *Locale { return locales[defaultTag] }
+
+// Lookup returns the supported locale for tag, matched case-insensitively.
+func Lookup(tag string) (*Locale, bool) {
+	for t, l := range locales {
+		if strings.EqualFold(t, tag) {
+			return l, true
+		}
+	}
+	return nil, false
+}
+
+// Negotiate picks the best supported locale for an Accept-Language header.
+// An exact tag wins; otherwise a bare or unsupported regional tag falls back
+// to the language's primary locale ("en-AU" and "en" give en-IN, "hi" gives
+// hi-IN). Anything else gives the default.
+func Negotiate(acceptLanguage string) *Locale {
+	best, bestQ := Default(), 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, q := httpx.ParseQuality(part)
+		if tag == "" || q <= bestQ {
+			continue
+		}
+		if l := match(tag); l != nil {
+			best, bestQ = l, q
+		}
+	}
+	return best
+}
+
+func match(tag string) *Locale {
+	if l, ok := Lookup(tag); ok {
+		return l
+	}
+	lang, _, _ := strings.Cut(tag, "-")
+	switch lang {
+	case "en":
+		return Default()
+	case "hi":
+		return locales["hi-IN"]
+	}
+	return nil
+}
+
+// FormatPrice renders an amount for humans. Rupee amounts of 10 or more are
+// rounded to whole rupees and grouped for the locale; smaller ones, such as
+// per-gram prices, keep two decimals. Other currencies are shown with their
+// ISO code and two decimals.
+func (l *Locale) FormatPrice(currency string, v float64) string {
+	if currency != "" && currency != "INR" {
+		return currency + " " + strconv.FormatFloat(v, 'f', 2, 64)
+	}
+	if v < 10 {
+		return "₹" + strconv.FormatFloat(v, 'f', 2, 64)
+	}
+	return "₹" + l.group(strconv.FormatInt(int64(v+0.5), 10))
+}
+
+func (l *Locale) group(digits string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+	head, tail := digits[:len(digits)-3], digits[len(digits)-3:]
+	size := 3
+	if l.IndianGrouping {
+		size = 2
+	}
+	var groups []string
+	for len(head) > size {
+		groups = append([]string{head[len(head)-size:]}, groups...)
+		head = head[:len(head)-size]
+	}
+	groups = append([]string{head}, groups...)
+	return strings.Join(groups, ",") + "," + tail
+}
+
+const gramsPerPound = 453.59237
+
+// FormatWeight renders a pack size, e.g. "2.27 kg", "500 g" or "5 lb".
+func (l *Locale) FormatWeight(grams int) string {
+	if grams <= 0 {
+		return ""
+	}
+	if l.Weights == Imperial {
+		return trimFloat(float64(grams)/gramsPerPound, 1) + " lb"
+	}
+	if grams < 1000 {
+		return strconv.Itoa(grams) + " g"
+	}
+	return trimFloat(float64(grams)/1000, 2) + " kg"
+}
+
+// trimFloat formats v with at most prec decimals and no trailing zeros.
+func trimFloat(v float64, prec int) string {
+	s := strconv.FormatFloat(v, 'f', prec, 64)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// T returns the message for key formatted with args, falling back to
+// English and then to the key itself.
+func (l *Locale) T(key string, args ...any) string {
+	msg, ok := l.messages[key]
+	if !ok {
+		if msg, ok = english[key]; !ok {
+			msg = key
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+type localeKey struct{}
+
+// WithLocale returns a copy of ctx carrying l.
+func WithLocale(ctx context.Context, l *Locale) context.Context {
+	return context.WithValue(ctx, localeKey{}, l)
+}
+
+// FromContext returns the request's locale, or the default.
+func FromContext(ctx context.Context) *Locale {
+	if l, ok := ctx.Value(localeKey{}).(*Locale); ok {
+		return l
+	}
+	return Default()
+}
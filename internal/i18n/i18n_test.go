@@ -0,0 +1,69 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestNegotiate(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestNegotiate", "internal/i18n")
+
+	testCases := []struct {
+		header string
+		want   string
+	}{
+		{"", "en-IN"},
+		{"en-US,en;q=0.9", "en-US"},
+		{"hi", "hi-IN"},
+		{"fr-FR, en-GB;q=0.8", "en-GB"},
+		{"en-AU", "en-IN"},
+		{"de-DE,fr;q=0.5", "en-IN"},
+		{"en-US;q=0.2, hi-IN;q=0.9", "hi-IN"},
+		{"EN-us", "en-US"},
+	}
+	for _, tc := range testCases {
+		got := Negotiate(tc.header).Tag
+		testhelpers.LogTestAssertion(logger, tc.header, tc.want, got)
+		if got != tc.want {
+			t.Errorf("Negotiate(%q) = %s, want %s", tc.header, got, tc.want)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestNegotiate", true)
+}
+
+func TestLocaleFormatting(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestLocaleFormatting", "internal/i18n")
+
+	in, us := Default(), locales["en-US"]
+	testCases := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"Indian grouping", in.FormatPrice("INR", 123456.4), "₹1,23,456"},
+		{"Western grouping", us.FormatPrice("INR", 123456.4), "₹123,456"},
+		{"Per-gram price keeps decimals", us.FormatPrice("", 1.817), "₹1.82"},
+		{"Other currency", in.FormatPrice("USD", 59.99), "USD 59.99"},
+		{"Kilograms", in.FormatWeight(2270), "2.27 kg"},
+		{"Grams", in.FormatWeight(500), "500 g"},
+		{"Whole kilograms", in.FormatWeight(1000), "1 kg"},
+		{"Pounds", us.FormatWeight(2270), "5 lb"},
+		{"Fractional pounds", us.FormatWeight(1000), "2.2 lb"},
+		{"Unknown weight", us.FormatWeight(0), ""},
+		{"Hindi message", locales["hi-IN"].T(MsgWidgetBestAt, "Amazon"), "Amazon पर सबसे कम कीमत"},
+		{"English message", us.T(MsgTimeout), "Request timed out"},
+		{"Unknown key", us.T("missing.key"), "missing.key"},
+	}
+	for _, tc := range testCases {
+		testhelpers.LogTestAssertion(logger, tc.name, tc.want, tc.got)
+		if tc.got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, tc.got, tc.want)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestLocaleFormatting", true)
+}
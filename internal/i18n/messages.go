@@ -0,0 +1,86 @@
+package i18n
+
+// Message keys.
+const (
+	MsgInternalError = "error.internal"
+	MsgTimeout       = "error.timeout"
+	MsgUnauthorized  = "error.unauthorized"
+
+	MsgSignInRequired = "auth.sign_in_required"
+	MsgTokenScope     = "auth.token_scope"
+
+	MsgWidgetBestAt      = "widget.best_at"
+	MsgWidgetMoreInStock = "widget.more_in_stock"
+	MsgWidgetViewDeal    = "widget.view_deal"
+	MsgWidgetCompare     = "widget.compare"
+	MsgWidgetUnavailable = "widget.unavailable"
+
+	MsgPageTitle          = "page.title"
+	MsgPageRetailer       = "page.retailer"
+	MsgPagePack           = "page.pack"
+	MsgPagePrice          = "page.price"
+	MsgPagePerGramProtein = "page.per_gram_protein"
+	MsgPageOutOfStock     = "page.out_of_stock"
+	MsgPageHistory        = "page.history"
+
+	MsgShareTitle  = "share.title"
+	MsgShareShared = "share.shared"
+	MsgShareNow    = "share.now"
+	MsgShareGone   = "share.gone"
+)
+
+var english = map[string]string{
+	MsgInternalError: "Internal server error",
+	MsgTimeout:       "Request timed out",
+	MsgUnauthorized:  "A valid bearer token is required",
+
+	MsgSignInRequired: "You need to sign in first",
+	MsgTokenScope:     "This API token does not allow this request",
+
+	MsgWidgetBestAt:      "Best price at %s",
+	MsgWidgetMoreInStock: "%d more in stock",
+	MsgWidgetViewDeal:    "View deal",
+	MsgWidgetCompare:     "Compare prices",
+	MsgWidgetUnavailable: "Currently out of stock everywhere we track.",
+
+	MsgPageTitle:          "%s price comparison",
+	MsgPageRetailer:       "Retailer",
+	MsgPagePack:           "Pack",
+	MsgPagePrice:          "Price",
+	MsgPagePerGramProtein: "Per g protein",
+	MsgPageOutOfStock:     "Out of stock",
+	MsgPageHistory:        "Lowest price, last %d days",
+
+	MsgShareTitle:  "%s for %s at %s",
+	MsgShareShared: "Shared at %s on %s",
+	MsgShareNow:    "Now %s",
+	MsgShareGone:   "No longer listed at %s",
+}
+
+var hindi = map[string]string{
+	MsgInternalError: "आंतरिक सर्वर त्रुटि",
+	MsgTimeout:       "अनुरोध का समय समाप्त हो गया",
+	MsgUnauthorized:  "एक मान्य बेयरर टोकन आवश्यक है",
+
+	MsgSignInRequired: "पहले साइन इन करें",
+	MsgTokenScope:     "यह API टोकन इस अनुरोध की अनुमति नहीं देता",
+
+	MsgWidgetBestAt:      "%s पर सबसे कम कीमत",
+	MsgWidgetMoreInStock: "%d और स्टॉक में",
+	MsgWidgetViewDeal:    "डील देखें",
+	MsgWidgetCompare:     "कीमतों की तुलना करें",
+	MsgWidgetUnavailable: "फ़िलहाल हर जगह स्टॉक में नहीं है।",
+
+	MsgPageTitle:          "%s कीमत तुलना",
+	MsgPageRetailer:       "विक्रेता",
+	MsgPagePack:           "पैक",
+	MsgPagePrice:          "कीमत",
+	MsgPagePerGramProtein: "प्रति ग्राम प्रोटीन",
+	MsgPageOutOfStock:     "स्टॉक में नहीं",
+	MsgPageHistory:        "सबसे कम कीमत, पिछले %d दिन",
+
+	MsgShareTitle:  "%[3]s पर %[2]s में %[1]s",
+	MsgShareShared: "%[2]s को %[1]s पर साझा किया गया",
+	MsgShareNow:    "अभी %s",
+	MsgShareGone:   "%s पर अब उपलब्ध नहीं",
+}
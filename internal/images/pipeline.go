@@ -0,0 +1,279 @@
+// Package images turns product images found while scraping into the
+// WebP files the site serves: downloaded once, stored once per distinct
+// content, and scaled to each size the frontend shows.
+package images
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // decoders for the formats retailers serve
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/storage/blob"
+	"github.com/yourusername/whey-price-compare/internal/tracing"
+)
+
+// ErrInvalidImage is returned for a source that cannot be fetched, is too
+// large or is not an image this package decodes.
+var ErrInvalidImage = fmt.Errorf("images: %w", domain.ErrInvalid)
+
+// Size is a width images are stored at, named for its file.
+type Size struct {
+	Name  string
+	Width int
+}
+
+// Config configures a Pipeline.
+type Config struct {
+	// Sizes are the widths each image is stored at. An image narrower
+	// than a size is stored at its own width.
+	Sizes []Size
+	// Primary names the size products link to.
+	Primary string
+	// MaxBytes and MaxPixels refuse sources that would take too long to
+	// fetch or too much memory to decode.
+	MaxBytes  int64
+	MaxPixels int
+	Timeout   time.Duration
+	UserAgent string
+	// AllowPrivate lets sources resolve to loopback and private addresses,
+	// which are otherwise refused so an image URL cannot reach internal
+	// services.
+	AllowPrivate bool
+}
+
+// DefaultConfig returns thumbnails for listings, cards for comparisons and
+// a full size for product pages, from sources up to 10 MiB and 24 MP.
+func DefaultConfig() Config {
+	return Config{
+		Sizes: []Size{
+			{Name: "thumb", Width: 160},
+			{Name: "card", Width: 400},
+			{Name: "full", Width: 1000},
+		},
+		Primary:   "card",
+		MaxBytes:  10 << 20,
+		MaxPixels: 24_000_000,
+		Timeout:   30 * time.Second,
+		UserAgent: "WheyPriceCompare/1.0 (+https://wheypricecompare.com/bot)",
+	}
+}
+
+// Variant is one stored size of an image.
+type Variant struct {
+	Size   string `json:"size"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Bytes  int    `json:"bytes"`
+	URL    string `json:"url"`
+}
+
+// Image is a stored product image, keyed by the SHA-256 of its source.
+type Image struct {
+	Hash     string    `json:"hash"`
+	Width    int       `json:"width"`
+	Height   int       `json:"height"`
+	URL      string    `json:"url"`
+	Variants []Variant `json:"variants"`
+}
+
+// Pipeline fetches, resizes and stores product images. It is safe for
+// concurrent use.
+type Pipeline struct {
+	cfg    Config
+	store  blob.Store
+	client *http.Client
+	logger *zap.Logger
+}
+
+// NewPipeline creates a Pipeline storing images in store.
+func NewPipeline(cfg Config, store blob.Store, logger *zap.Logger) *Pipeline {
+	def := DefaultConfig()
+	if len(cfg.Sizes) == 0 {
+		cfg.Sizes, cfg.Primary = def.Sizes, def.Primary
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = def.MaxBytes
+	}
+	if cfg.MaxPixels <= 0 {
+		cfg.MaxPixels = def.MaxPixels
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if !cfg.AllowPrivate {
+		dialer.Control = refusePrivate
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &Pipeline{
+		cfg:    cfg,
+		store:  store,
+		client: &http.Client{Timeout: cfg.Timeout, Transport: transport},
+		logger: logger,
+	}
+}
+
+// Ingest stores the image at sourceURL, fetching it on every call but
+// resizing and uploading it only the first time its content is seen.
+func (p *Pipeline) Ingest(ctx context.Context, sourceURL string) (*Image, error) {
+	data, err := p.fetch(ctx, sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	img, err := p.Store(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", sourceURL, err)
+	}
+	return img, nil
+}
+
+// Store stores an image already fetched, as the scraper has for pages
+// that embed it. An image stored before, from any source, is returned
+// as it was stored.
+func (p *Pipeline) Store(ctx context.Context, data []byte) (*Image, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	manifestKey := blob.ImageKey(hash, "image.json")
+	if stored, err := p.manifest(ctx, manifestKey); err == nil {
+		return stored, nil
+	} else if !errors.Is(err, blob.ErrNotFound) {
+		return nil, err
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if cfg.Width*cfg.Height > p.cfg.MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d is over %d pixels", ErrInvalidImage, cfg.Width, cfg.Height, p.cfg.MaxPixels)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	out := &Image{Hash: hash, Width: cfg.Width, Height: cfg.Height}
+	attrs := blob.Attrs{ContentType: "image/webp", CacheControl: blob.ImmutableCacheControl}
+	for _, size := range p.cfg.Sizes {
+		scaled := Fit(src, min(size.Width, MaxWebPDimension))
+		var buf bytes.Buffer
+		if err := EncodeWebP(&buf, scaled); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+		}
+		key := blob.ImageKey(hash, size.Name+".webp")
+		if err := p.store.Put(ctx, key, buf.Bytes(), attrs); err != nil {
+			return nil, fmt.Errorf("store image: %w", err)
+		}
+		v := Variant{Size: size.Name, Width: scaled.Rect.Dx(), Height: scaled.Rect.Dy(), Bytes: buf.Len(), URL: p.store.URL(key)}
+		out.Variants = append(out.Variants, v)
+		if size.Name == p.cfg.Primary || out.URL == "" {
+			out.URL = v.URL
+		}
+	}
+	// The manifest goes last: once it exists, so does every size.
+	manifest, err := json.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.store.Put(ctx, manifestKey, manifest, blob.Attrs{ContentType: "application/json", CacheControl: blob.ImmutableCacheControl}); err != nil {
+		return nil, fmt.Errorf("store image: %w", err)
+	}
+	p.logger.Info("Stored product image",
+		zap.String("hash", hash),
+		zap.String("format", format),
+		zap.Int("source_bytes", len(data)),
+		zap.Int("variants", len(out.Variants)),
+	)
+	return out, nil
+}
+
+// manifest reads the record of an image stored before.
+func (p *Pipeline) manifest(ctx context.Context, key string) (*Image, error) {
+	body, err := p.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = body.Close() }()
+	var img Image
+	if err := json.NewDecoder(body).Decode(&img); err != nil {
+		return nil, fmt.Errorf("read image manifest %s: %w", key, err)
+	}
+	return &img, nil
+}
+
+// fetch downloads sourceURL. Servers that negotiate formats are asked for
+// ones this package decodes.
+func (p *Pipeline) fetch(ctx context.Context, sourceURL string) (_ []byte, err error) {
+	u, err := url.Parse(sourceURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: %q is not an http(s) URL", ErrInvalidImage, sourceURL)
+	}
+	// Retailers are not sent a traceparent: their servers are not ours to
+	// trace, and the header would only tell them about our traffic.
+	ctx, span := tracing.StartClient(ctx, "images.fetch", tracing.String("server.address", u.Host))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	req.Header.Set("Accept", "image/png,image/jpeg,image/gif;q=0.9")
+	if p.cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", p.cfg.UserAgent)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: fetch %s: %v", ErrInvalidImage, sourceURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: fetch %s: %s", ErrInvalidImage, sourceURL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, p.cfg.MaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: fetch %s: %v", ErrInvalidImage, sourceURL, err)
+	}
+	if int64(len(data)) > p.cfg.MaxBytes {
+		return nil, fmt.Errorf("%w: %s is over %d bytes", ErrInvalidImage, sourceURL, p.cfg.MaxBytes)
+	}
+	return data, nil
+}
+
+// refusePrivate stops connections to addresses that are not public.
+func refusePrivate(_, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	ip := ap.Addr().Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsMulticast() || ip.IsUnspecified() || ip.IsInterfaceLocalMulticast() {
+		return fmt.Errorf("refusing to fetch from non-public address %s", ip)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/storage/blob"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// countingStore counts the objects written to a store.
+type countingStore struct {
+	blob.Store
+	mu   sync.Mutex
+	puts map[string]blob.Attrs
+}
+
+func (s *countingStore) Put(ctx context.Context, key string, data []byte, attrs blob.Attrs) error {
+	s.mu.Lock()
+	s.puts[key] = attrs
+	s.mu.Unlock()
+	return s.Store.Put(ctx, key, data, attrs)
+}
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(x), uint8(y), 200, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestPipeline_Ingest(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPipeline_Ingest", "internal/images")
+
+	testhelpers.LogTestStep(logger, "arrange", "A retailer serving one image under two URLs, and a local store")
+	photo := testPNG(t, 800, 600)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a.png", "/b.png":
+			_, _ = w.Write(photo)
+		case "/page.html":
+			_, _ = w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	local, err := blob.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	store := &countingStore{Store: local, puts: make(map[string]blob.Attrs)}
+	cfg := DefaultConfig()
+	cfg.AllowPrivate = true
+	pipeline := NewPipeline(cfg, store, logger)
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Ingesting the image from its first URL")
+	img, err := pipeline.Ingest(ctx, srv.URL+"/a.png")
+	if err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Every size is stored as immutable WebP, never enlarged")
+	testhelpers.LogTestAssertion(logger, "variants", 3, len(img.Variants))
+	want := map[string][2]int{"thumb": {160, 120}, "card": {400, 300}, "full": {800, 600}}
+	for _, v := range img.Variants {
+		if dims := want[v.Size]; v.Width != dims[0] || v.Height != dims[1] {
+			t.Errorf("%s is %dx%d, want %v", v.Size, v.Width, v.Height, dims)
+		}
+	}
+	if img.URL != "/media/"+blob.ImageKey(img.Hash, "card.webp") {
+		t.Errorf("URL = %q, want the card size", img.URL)
+	}
+	if attrs := store.puts[blob.ImageKey(img.Hash, "full.webp")]; attrs.ContentType != "image/webp" || attrs.CacheControl != blob.ImmutableCacheControl {
+		t.Errorf("full size stored with %+v", attrs)
+	}
+	body, err := store.Get(ctx, blob.ImageKey(img.Hash, "thumb.webp"))
+	if err != nil {
+		t.Fatalf("Get thumb: %v", err)
+	}
+	_ = body.Close()
+
+	testhelpers.LogTestStep(logger, "act", "Ingesting the same image from its second URL")
+	written := len(store.puts)
+	again, err := pipeline.Ingest(ctx, srv.URL+"/b.png")
+	if err != nil {
+		t.Fatalf("Ingest again: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The stored image is reused without writing anything")
+	testhelpers.LogTestAssertion(logger, "writes", written, len(store.puts))
+	if again.Hash != img.Hash || again.URL != img.URL || len(store.puts) != written {
+		t.Errorf("second ingest = %+v after %d writes, want %+v after %d", again, len(store.puts), img, written)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Missing and non-image sources are invalid")
+	for _, path := range []string{"/missing.png", "/page.html"} {
+		if _, err := pipeline.Ingest(ctx, srv.URL+path); !errors.Is(err, ErrInvalidImage) || !errors.Is(err, domain.ErrInvalid) {
+			t.Errorf("Ingest(%s) = %v, want ErrInvalidImage", path, err)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPipeline_Ingest", true)
+}
+
+func TestPipeline_RefusesPrivateAddresses(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPipeline_RefusesPrivateAddresses", "internal/images")
+
+	testhelpers.LogTestStep(logger, "arrange", "An image on a loopback server, and a pipeline for public sources")
+	photo := testPNG(t, 10, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(photo)
+	}))
+	defer srv.Close()
+	local, err := blob.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	pipeline := NewPipeline(DefaultConfig(), local, logger)
+
+	testhelpers.LogTestStep(logger, "act", "Ingesting from the loopback server and from a file URL")
+	_, loopErr := pipeline.Ingest(t.Context(), srv.URL+"/a.png")
+	_, fileErr := pipeline.Ingest(t.Context(), "file:///etc/passwd")
+
+	testhelpers.LogTestStep(logger, "assert", "Both are refused")
+	testhelpers.LogTestAssertion(logger, "refused", true, loopErr != nil && fileErr != nil)
+	if !errors.Is(loopErr, ErrInvalidImage) || !errors.Is(fileErr, ErrInvalidImage) {
+		t.Errorf("loopback = %v, file = %v, want ErrInvalidImage", loopErr, fileErr)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPipeline_RefusesPrivateAddresses", true)
+}
+
+func TestFit(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestFit", "internal/images")
+
+	testhelpers.LogTestStep(logger, "arrange", "Opaque red beside transparent black")
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	src.SetNRGBA(0, 0, color.NRGBA{255, 0, 0, 255})
+
+	testhelpers.LogTestStep(logger, "act", "Halving it")
+	got := Fit(src, 1).NRGBAAt(0, 0)
+
+	testhelpers.LogTestStep(logger, "assert", "The pixel is half-transparent red, not darkened")
+	want := color.NRGBA{255, 0, 0, 128}
+	testhelpers.LogTestAssertion(logger, "pixel", want, got)
+	if got != want {
+		t.Errorf("Fit = %v, want %v", got, want)
+	}
+	if b := Fit(src, 10).Bounds(); b.Dx() != 2 || b.Dy() != 1 {
+		t.Errorf("Fit enlarged to %v", b)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestFit", true)
+}
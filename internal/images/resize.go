@@ -0,0 +1,86 @@
+package images
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Fit scales img down to width, keeping its aspect ratio. An image no
+// wider than width is returned at its own size, as enlarging only blurs.
+// Each output pixel averages the source pixels it covers, weighted by how
+// much of each it covers, in premultiplied alpha so transparent pixels
+// do not darken the edges next to them.
+func Fit(img image.Image, width int) *image.NRGBA {
+	b := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	sw, sh := b.Dx(), b.Dy()
+	dw, dh := sw, sh
+	if width > 0 && sw > width {
+		dw = width
+		dh = max(1, (sh*width+sw/2)/sw)
+	}
+
+	// Rows are narrowed first, then columns shortened.
+	cols := weights(sw, dw)
+	rows := weights(sh, dh)
+	tmp := make([]float32, 4*dw*sh)
+	for y := range sh {
+		line := src.Pix[y*src.Stride:]
+		for x, ws := range cols {
+			var acc [4]float32
+			for _, w := range ws {
+				p := line[4*w.index:]
+				acc[0] += w.weight * float32(p[0])
+				acc[1] += w.weight * float32(p[1])
+				acc[2] += w.weight * float32(p[2])
+				acc[3] += w.weight * float32(p[3])
+			}
+			copy(tmp[4*(y*dw+x):], acc[:])
+		}
+	}
+	out := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y, ws := range rows {
+		for x := range dw {
+			var acc [4]float32
+			for _, w := range ws {
+				p := tmp[4*(w.index*dw+x):]
+				acc[0] += w.weight * p[0]
+				acc[1] += w.weight * p[1]
+				acc[2] += w.weight * p[2]
+				acc[3] += w.weight * p[3]
+			}
+			o := out.Pix[y*out.Stride+4*x:]
+			alpha := min(acc[3], 255)
+			o[3] = uint8(alpha + 0.5)
+			if alpha > 0 {
+				for c := range 3 {
+					o[c] = uint8(min(acc[c]*255/alpha, 255) + 0.5)
+				}
+			}
+		}
+	}
+	return out
+}
+
+type weight struct {
+	index  int
+	weight float32
+}
+
+// weights returns, for each of the dst cells src cells are averaged into,
+// the src cells it overlaps and by what fraction of it.
+func weights(src, dst int) [][]weight {
+	scale := float64(src) / float64(dst)
+	out := make([][]weight, dst)
+	for i := range dst {
+		lo, hi := float64(i)*scale, float64(i+1)*scale
+		for j := int(lo); j < src && float64(j) < hi; j++ {
+			overlap := min(hi, float64(j+1)) - max(lo, float64(j))
+			if overlap > 0 {
+				out[i] = append(out[i], weight{index: j, weight: float32(overlap / scale)})
+			}
+		}
+	}
+	return out
+}
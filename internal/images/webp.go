@@ -0,0 +1,513 @@
+package images
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"io"
+	"slices"
+)
+
+// MaxWebPDimension is the largest width or height a WebP image can have.
+const MaxWebPDimension = 1 << 14
+
+// VP8L bitstream constants (RFC 9649).
+const (
+	vp8lSignature   = 0x2f
+	numLiterals     = 256
+	numLengthCodes  = 24
+	numDistCodes    = 40
+	maxCodeLength   = 15
+	maxCLCodeLength = 7
+	maxCopyLength   = 4096
+	minCopyLength   = 3
+	// planeCodes is how many distance codes are taken by the
+	// two-dimensional neighbourhood; a distance d is otherwise sent as
+	// d+planeCodes.
+	planeCodes = 120
+	// planeCodeUp and planeCodeLeft name the pixel above and the one to
+	// the left.
+	planeCodeUp   = 1
+	planeCodeLeft = 2
+
+	transformPredictor    = 0
+	transformSubtractGrn  = 2
+	predictorBlockBits    = 9
+	predictorClampAddFull = 12
+)
+
+// codeLengthOrder is the order code length code lengths are sent in.
+var codeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// EncodeWebP writes img to w as a lossless WebP. It subtracts green from
+// red and blue, predicts each pixel from its neighbours by the gradient
+// L+T-TL, and codes the residuals with repeats found by a hash of pixel
+// pairs, which shrinks the flat backgrounds of product shots to a few
+// bytes a row.
+func EncodeWebP(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width < 1 || height < 1 || width > MaxWebPDimension || height > MaxWebPDimension {
+		return errors.New("images: WebP dimensions must be 1 to 16384 pixels")
+	}
+	argb, opaque := toARGB(img)
+	subtractGreen(argb)
+	residuals := predictGradient(argb, width)
+
+	bw := &bitWriter{}
+	bw.write(vp8lSignature, 8)
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	if opaque {
+		bw.write(0, 1)
+	} else {
+		bw.write(1, 1)
+	}
+	bw.write(0, 3)
+	// Transforms are undone in the reverse of this order.
+	bw.write(1, 1)
+	bw.write(transformSubtractGrn, 2)
+	bw.write(1, 1)
+	bw.write(transformPredictor, 2)
+	bw.write(predictorBlockBits-2, 3)
+	blocks := subSampleSize(width, predictorBlockBits) * subSampleSize(height, predictorBlockBits)
+	modes := make([]uint32, blocks)
+	for i := range modes {
+		modes[i] = predictorClampAddFull << 8
+	}
+	writeImage(bw, modes, subSampleSize(width, predictorBlockBits), false)
+	bw.write(0, 1)
+	writeImage(bw, residuals, width, true)
+
+	data := bw.bytes()
+	chunk := len(data) + len(data)&1
+	header := make([]byte, 20)
+	copy(header, "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(4+8+chunk))
+	copy(header[8:], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(header[16:], uint32(len(data)))
+	if len(data)&1 == 1 {
+		data = append(data, 0)
+	}
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// toARGB returns img's pixels as non-premultiplied ARGB words, and whether
+// every one is opaque.
+func toARGB(img image.Image) ([]uint32, bool) {
+	b := img.Bounds()
+	nrgba, ok := img.(*image.NRGBA)
+	if !ok || nrgba.Stride != 4*b.Dx() {
+		nrgba = image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(nrgba, nrgba.Bounds(), img, b.Min, draw.Src)
+	}
+	pix := nrgba.Pix
+	argb := make([]uint32, b.Dx()*b.Dy())
+	opaque := true
+	for i := range argb {
+		p := pix[4*i : 4*i+4 : 4*i+4]
+		argb[i] = uint32(p[3])<<24 | uint32(p[0])<<16 | uint32(p[1])<<8 | uint32(p[2])
+		opaque = opaque && p[3] == 0xff
+	}
+	return argb, opaque
+}
+
+// subtractGreen replaces red and blue by their difference from green.
+func subtractGreen(argb []uint32) {
+	for i, p := range argb {
+		g := (p >> 8) & 0xff
+		r := ((p >> 16) - g) & 0xff
+		b := (p - g) & 0xff
+		argb[i] = p&0xff00ff00 | r<<16 | b
+	}
+}
+
+// predictGradient returns the residuals of predicting each pixel by
+// predictor mode 12, clamp(L+T-TL) per channel. The first pixel is
+// predicted as opaque black, the rest of the top row from the left and
+// the rest of the left column from above, as decoders do for any mode.
+func predictGradient(argb []uint32, width int) []uint32 {
+	out := make([]uint32, len(argb))
+	for i, p := range argb {
+		x, y := i%width, i/width
+		var pred uint32
+		switch {
+		case x == 0 && y == 0:
+			pred = 0xff000000
+		case y == 0:
+			pred = argb[i-1]
+		case x == 0:
+			pred = argb[i-width]
+		default:
+			pred = clampAddSubtractFull(argb[i-1], argb[i-width], argb[i-width-1])
+		}
+		out[i] = subPixels(p, pred)
+	}
+	return out
+}
+
+func clampAddSubtractFull(a, b, c uint32) uint32 {
+	var out uint32
+	for shift := 0; shift < 32; shift += 8 {
+		v := int((a>>shift)&0xff) + int((b>>shift)&0xff) - int((c>>shift)&0xff)
+		out |= uint32(min(max(v, 0), 255)) << shift
+	}
+	return out
+}
+
+// subPixels subtracts b from a channel by channel, modulo 256.
+func subPixels(a, b uint32) uint32 {
+	// The constants lend each lane a borrow from the byte below it.
+	ag := (0x00ff00ff + (a & 0xff00ff00) - (b & 0xff00ff00)) & 0xff00ff00
+	rb := (0xff00ff00 + (a & 0x00ff00ff) - (b & 0x00ff00ff)) & 0x00ff00ff
+	return ag | rb
+}
+
+func subSampleSize(size, bits int) int {
+	return (size + 1<<bits - 1) >> bits
+}
+
+// symbol is one coded element: a literal pixel, or a copy of length
+// pixels from dist codes back.
+type symbol struct {
+	pixel  uint32
+	length int
+	dist   int
+}
+
+// writeImage writes pixels as an entropy-coded image without a colour
+// cache, meta prefix codes being absent for the main image only.
+func writeImage(bw *bitWriter, pixels []uint32, width int, main bool) {
+	symbols := backwardRefs(pixels, width)
+
+	counts := [5][]int{
+		make([]int, numLiterals+numLengthCodes),
+		make([]int, numLiterals),
+		make([]int, numLiterals),
+		make([]int, numLiterals),
+		make([]int, numDistCodes),
+	}
+	for _, s := range symbols {
+		if s.length == 0 {
+			counts[0][(s.pixel>>8)&0xff]++
+			counts[1][(s.pixel>>16)&0xff]++
+			counts[2][s.pixel&0xff]++
+			counts[3][s.pixel>>24]++
+			continue
+		}
+		code, _, _ := prefixEncode(s.length)
+		counts[0][numLiterals+code]++
+		code, _, _ = prefixEncode(s.dist)
+		counts[4][code]++
+	}
+
+	bw.write(0, 1) // no colour cache
+	if main {
+		bw.write(0, 1) // one prefix code group
+	}
+	var codes [5]prefixCode
+	for i := range codes {
+		codes[i] = newPrefixCode(counts[i], maxCodeLength)
+		codes[i].writeHeader(bw)
+	}
+	for _, s := range symbols {
+		if s.length == 0 {
+			codes[0].write(bw, int((s.pixel>>8)&0xff))
+			codes[1].write(bw, int((s.pixel>>16)&0xff))
+			codes[2].write(bw, int(s.pixel&0xff))
+			codes[3].write(bw, int(s.pixel>>24))
+			continue
+		}
+		code, n, extra := prefixEncode(s.length)
+		codes[0].write(bw, numLiterals+code)
+		bw.write(extra, n)
+		code, n, extra = prefixEncode(s.dist)
+		codes[4].write(bw, code)
+		bw.write(extra, n)
+	}
+}
+
+// backwardRefs finds, greedily, the longest repeat at each pixel among
+// the pixel to the left, the one above and the last place the next two
+// pixels were seen.
+func backwardRefs(pixels []uint32, width int) []symbol {
+	const hashBits = 16
+	last := make([]int32, 1<<hashBits)
+	for i := range last {
+		last[i] = -1
+	}
+	hash := func(i int) int {
+		return int((pixels[i]*0x9e3779b1 ^ pixels[i+1]*0x85ebca6b) >> (32 - hashBits))
+	}
+	matchLen := func(i, dist int) int {
+		n := 0
+		for i+n < len(pixels) && n < maxCopyLength && pixels[i+n] == pixels[i+n-dist] {
+			n++
+		}
+		return n
+	}
+
+	symbols := make([]symbol, 0, len(pixels)/4)
+	for i := 0; i < len(pixels); {
+		bestLen, bestDist := 0, 0
+		candidates := [3]int{1, width, 0}
+		if i+1 < len(pixels) {
+			if j := last[hash(i)]; j >= 0 {
+				candidates[2] = i - int(j)
+			}
+		}
+		for _, d := range candidates {
+			if d <= 0 || d > i {
+				continue
+			}
+			if n := matchLen(i, d); n > bestLen {
+				bestLen, bestDist = n, d
+			}
+		}
+		step := 1
+		if bestLen >= minCopyLength {
+			symbols = append(symbols, symbol{length: bestLen, dist: distanceCode(bestDist, width)})
+			step = bestLen
+		} else {
+			symbols = append(symbols, symbol{pixel: pixels[i]})
+		}
+		for end := i + step; i < end; i++ {
+			if i+1 < len(pixels) {
+				last[hash(i)] = int32(i)
+			}
+		}
+	}
+	return symbols
+}
+
+// distanceCode returns the code a copy from dist pixels back is sent as.
+func distanceCode(dist, width int) int {
+	switch dist {
+	case 1:
+		return planeCodeLeft
+	case width:
+		return planeCodeUp
+	}
+	return dist + planeCodes
+}
+
+// prefixEncode splits v, at least 1, into the prefix code it is sent as
+// and the extra bits that follow it.
+func prefixEncode(v int) (code int, extraBits uint, extra uint32) {
+	d := v - 1
+	if d < 4 {
+		return d, 0, 0
+	}
+	high := 0
+	for d>>(high+1) != 0 {
+		high++
+	}
+	second := (d >> (high - 1)) & 1
+	extraBits = uint(high - 1)
+	return 2*high + second, extraBits, uint32(d) & (1<<extraBits - 1)
+}
+
+// prefixCode is a canonical Huffman code. A code of one symbol takes no
+// bits, as decoders read it.
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint32 // bit-reversed, since the stream is read LSB first
+	used    []int
+}
+
+func newPrefixCode(counts []int, limit int) prefixCode {
+	lengths := codeLengths(counts, limit)
+	c := prefixCode{lengths: lengths, codes: canonicalCodes(lengths)}
+	for s, n := range lengths {
+		if n > 0 {
+			c.used = append(c.used, s)
+		}
+	}
+	return c
+}
+
+func (c *prefixCode) write(bw *bitWriter, sym int) {
+	if len(c.used) > 1 {
+		bw.write(c.codes[sym], uint(c.lengths[sym]))
+	}
+}
+
+// writeHeader sends the code: as a simple code if it has at most two
+// symbols below 256, and otherwise as its code lengths, themselves
+// Huffman coded.
+func (c *prefixCode) writeHeader(bw *bitWriter) {
+	if len(c.used) <= 2 && (len(c.used) == 0 || c.used[len(c.used)-1] < numLiterals) {
+		used := c.used
+		if len(used) == 0 {
+			used = []int{0}
+		}
+		bw.write(1, 1)
+		bw.write(uint32(len(used)-1), 1)
+		if used[0] < 2 {
+			bw.write(0, 1)
+			bw.write(uint32(used[0]), 1)
+		} else {
+			bw.write(1, 1)
+			bw.write(uint32(used[0]), 8)
+		}
+		if len(used) == 2 {
+			bw.write(uint32(used[1]), 8)
+		}
+		return
+	}
+
+	bw.write(0, 1)
+	clCounts := make([]int, 16)
+	for _, n := range c.lengths {
+		clCounts[n]++
+	}
+	cl := newPrefixCode(clCounts, maxCLCodeLength)
+	numCodes := 4
+	for i, s := range codeLengthOrder {
+		if s < len(cl.lengths) && cl.lengths[s] > 0 {
+			numCodes = max(numCodes, i+1)
+		}
+	}
+	bw.write(uint32(numCodes-4), 4)
+	for _, s := range codeLengthOrder[:numCodes] {
+		var n uint8
+		if s < len(cl.lengths) {
+			n = cl.lengths[s]
+		}
+		bw.write(uint32(n), 3)
+	}
+	bw.write(0, 1) // every symbol's length follows
+	for _, n := range c.lengths {
+		cl.write(bw, int(n))
+	}
+}
+
+// codeLengths returns Huffman code lengths for counts no longer than
+// limit. A lone symbol gets length 1. Over the limit, small counts are
+// raised until the tree is shallow enough, which keeps it complete.
+func codeLengths(counts []int, limit int) []uint8 {
+	lengths := make([]uint8, len(counts))
+	var used []int
+	for s, n := range counts {
+		if n > 0 {
+			used = append(used, s)
+		}
+	}
+	switch len(used) {
+	case 0:
+		return lengths
+	case 1:
+		lengths[used[0]] = 1
+		return lengths
+	}
+	weights := make([]int, len(used))
+	for floor := 1; ; floor *= 2 {
+		for i, s := range used {
+			weights[i] = max(counts[s], floor)
+		}
+		depths := huffmanDepths(weights)
+		if slices.Max(depths) <= limit {
+			for i, s := range used {
+				lengths[s] = uint8(depths[i])
+			}
+			return lengths
+		}
+	}
+}
+
+// huffmanDepths returns the depth of each leaf of a Huffman tree over
+// weights, built with the two-queue method.
+func huffmanDepths(weights []int) []int {
+	n := len(weights)
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return weights[a] - weights[b] })
+
+	parent := make([]int, 2*n-1)
+	weight := make([]int, 2*n-1)
+	copy(weight, weights)
+	leaf, node, next := 0, n, n
+	pick := func() int {
+		if leaf < n && (node >= next || weight[order[leaf]] <= weight[node]) {
+			leaf++
+			return order[leaf-1]
+		}
+		node++
+		return node - 1
+	}
+	for next < 2*n-1 {
+		a, b := pick(), pick()
+		weight[next] = weight[a] + weight[b]
+		parent[a], parent[b] = next, next
+		next++
+	}
+	depths := make([]int, n)
+	depth := make([]int, 2*n-1)
+	for i := 2*n - 3; i >= 0; i-- {
+		depth[i] = depth[parent[i]] + 1
+	}
+	copy(depths, depth[:n])
+	return depths
+}
+
+// canonicalCodes assigns codes in order of length, then symbol, and
+// returns them bit-reversed.
+func canonicalCodes(lengths []uint8) []uint32 {
+	var count [maxCodeLength + 1]uint32
+	for _, n := range lengths {
+		if n > 0 {
+			count[n]++
+		}
+	}
+	var next [maxCodeLength + 2]uint32
+	for n := 1; n <= maxCodeLength; n++ {
+		next[n+1] = (next[n] + count[n]) << 1
+	}
+	codes := make([]uint32, len(lengths))
+	for s, n := range lengths {
+		if n == 0 {
+			continue
+		}
+		code := next[n]
+		next[n]++
+		var rev uint32
+		for range n {
+			rev = rev<<1 | code&1
+			code >>= 1
+		}
+		codes[s] = rev
+	}
+	return codes
+}
+
+// bitWriter packs bits least significant first.
+type bitWriter struct {
+	buf []byte
+	acc uint64
+	n   uint
+}
+
+func (w *bitWriter) write(v uint32, n uint) {
+	w.acc |= uint64(v) << w.n
+	w.n += n
+	for w.n >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.n -= 8
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	if w.n > 0 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc, w.n = 0, 0
+	}
+	return w.buf
+}
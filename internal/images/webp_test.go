@@ -0,0 +1,368 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestEncodeWebP_RoundTrip(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestEncodeWebP_RoundTrip", "internal/images")
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	noise := image.NewNRGBA(image.Rect(0, 0, 37, 23))
+	for i := range noise.Pix {
+		noise.Pix[i] = byte(rng.IntN(256))
+	}
+	product := image.NewNRGBA(image.Rect(0, 0, 600, 400))
+	for y := range 400 {
+		for x := range 600 {
+			c := color.NRGBA{255, 255, 255, 255}
+			if x > 200 && x < 400 && y > 100 && y < 300 {
+				c = color.NRGBA{uint8(x), uint8(y), uint8(x + y), 255}
+			}
+			product.SetNRGBA(x, y, c)
+		}
+	}
+	twoTone := image.NewNRGBA(image.Rect(0, 0, 9, 9))
+	for i := 0; i < len(twoTone.Pix); i += 4 {
+		if i%12 == 0 {
+			twoTone.Pix[i+3] = 0xff
+		}
+	}
+	column := image.NewNRGBA(image.Rect(0, 0, 1, 70))
+	for y := range 70 {
+		column.SetNRGBA(0, y, color.NRGBA{uint8(3 * y), 0, 0, 255})
+	}
+
+	testCases := []struct {
+		name    string
+		img     *image.NRGBA
+		maxSize int
+	}{
+		{"noise with alpha", noise, 0},
+		{"product on white", product, 8 << 10},
+		{"two tone", twoTone, 0},
+		{"one pixel wide", column, 0},
+		{"one pixel", image.NewNRGBA(image.Rect(0, 0, 1, 1)), 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testhelpers.LogTestStep(logger, "act", "Encoding and decoding "+tc.name)
+			var buf bytes.Buffer
+			if err := EncodeWebP(&buf, tc.img); err != nil {
+				t.Fatalf("EncodeWebP: %v", err)
+			}
+			got, err := decodeVP8L(buf.Bytes())
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+
+			testhelpers.LogTestStep(logger, "assert", "The decoded pixels match")
+			testhelpers.LogTestAssertion(logger, "bytes", tc.maxSize, buf.Len())
+			if got.Bounds() != tc.img.Bounds() || !bytes.Equal(got.Pix, tc.img.Pix) {
+				t.Errorf("decoded image differs from the original")
+			}
+			if tc.maxSize > 0 && buf.Len() > tc.maxSize {
+				t.Errorf("encoded to %d bytes, want at most %d", buf.Len(), tc.maxSize)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestEncodeWebP_RoundTrip", true)
+}
+
+func TestCodeLengths_Limited(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCodeLengths_Limited", "internal/images")
+
+	testhelpers.LogTestStep(logger, "arrange", "Fibonacci counts, whose Huffman tree is as deep as it can be")
+	counts := make([]int, 30)
+	counts[0], counts[1] = 1, 1
+	for i := 2; i < len(counts); i++ {
+		counts[i] = counts[i-1] + counts[i-2]
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Building lengths limited to 15 bits")
+	lengths := codeLengths(counts, maxCodeLength)
+
+	testhelpers.LogTestStep(logger, "assert", "No length is over the limit and the code is complete")
+	kraft := 0
+	deepest := uint8(0)
+	for _, n := range lengths {
+		kraft += 1 << (maxCodeLength - n)
+		deepest = max(deepest, n)
+	}
+	testhelpers.LogTestAssertion(logger, "deepest", maxCodeLength, deepest)
+	if deepest > maxCodeLength || kraft != 1<<maxCodeLength {
+		t.Errorf("lengths %v: deepest %d, Kraft sum %d/%d", lengths, deepest, kraft, 1<<maxCodeLength)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCodeLengths_Limited", true)
+}
+
+// decodeVP8L decodes the lossless WebP files EncodeWebP writes, following
+// RFC 9649 independently of the encoder. Colour caches, meta prefix codes
+// and predictor modes other than 12 are not supported.
+func decodeVP8L(data []byte) (img *image.NRGBA, err error) {
+	defer func() {
+		if recover() != nil {
+			err = errors.New("truncated stream")
+		}
+	}()
+	if len(data) < 20 || string(data[:4]) != "RIFF" || string(data[8:16]) != "WEBPVP8L" {
+		return nil, errors.New("not a VP8L file")
+	}
+	if int(binary.LittleEndian.Uint32(data[4:])) != len(data)-8 {
+		return nil, errors.New("RIFF size mismatch")
+	}
+	r := &bitReader{data: data[20 : 20+binary.LittleEndian.Uint32(data[16:])]}
+	if r.read(8) != vp8lSignature {
+		return nil, errors.New("bad signature")
+	}
+	width, height := int(r.read(14))+1, int(r.read(14))+1
+	r.read(1)
+	if r.read(3) != 0 {
+		return nil, errors.New("bad version")
+	}
+
+	type transform struct {
+		kind  uint32
+		bits  int
+		modes []uint32
+	}
+	var transforms []transform
+	for r.read(1) == 1 {
+		tr := transform{kind: r.read(2)}
+		switch tr.kind {
+		case transformSubtractGrn:
+		case transformPredictor:
+			tr.bits = int(r.read(3)) + 2
+			tr.modes, err = decodeEntropyImage(r, subSampleSize(width, tr.bits), subSampleSize(height, tr.bits), false)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported transform %d", tr.kind)
+		}
+		transforms = append(transforms, tr)
+	}
+	argb, err := decodeEntropyImage(r, width, height, true)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(transforms) - 1; i >= 0; i-- {
+		tr := transforms[i]
+		switch tr.kind {
+		case transformSubtractGrn:
+			for j, p := range argb {
+				g := (p >> 8) & 0xff
+				argb[j] = p&0xff00ff00 | ((p>>16)+g)&0xff<<16 | (p+g)&0xff
+			}
+		case transformPredictor:
+			for j := range argb {
+				x, y := j%width, j/width
+				var pred uint32
+				switch {
+				case x == 0 && y == 0:
+					pred = 0xff000000
+				case y == 0:
+					pred = argb[j-1]
+				case x == 0:
+					pred = argb[j-width]
+				default:
+					mode := (tr.modes[(y>>tr.bits)*subSampleSize(width, tr.bits)+x>>tr.bits] >> 8) & 0xff
+					if mode != predictorClampAddFull {
+						return nil, fmt.Errorf("unsupported predictor mode %d", mode)
+					}
+					pred = clampAddSubtractFull(argb[j-1], argb[j-width], argb[j-width-1])
+				}
+				var sum uint32
+				for shift := 0; shift < 32; shift += 8 {
+					sum |= ((argb[j]>>shift + pred>>shift) & 0xff) << shift
+				}
+				argb[j] = sum
+			}
+		}
+	}
+
+	img = image.NewNRGBA(image.Rect(0, 0, width, height))
+	for j, p := range argb {
+		img.Pix[4*j], img.Pix[4*j+1], img.Pix[4*j+2], img.Pix[4*j+3] = byte(p>>16), byte(p>>8), byte(p), byte(p>>24)
+	}
+	return img, nil
+}
+
+func decodeEntropyImage(r *bitReader, width, height int, main bool) ([]uint32, error) {
+	if r.read(1) == 1 {
+		return nil, errors.New("colour cache not supported")
+	}
+	if main && r.read(1) == 1 {
+		return nil, errors.New("meta prefix codes not supported")
+	}
+	var codes [5]*huffman
+	for i, size := range []int{numLiterals + numLengthCodes, numLiterals, numLiterals, numLiterals, numDistCodes} {
+		lengths, err := readCodeLengths(r, size)
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = newHuffman(lengths)
+	}
+	pixels := make([]uint32, 0, width*height)
+	for len(pixels) < width*height {
+		g := codes[0].decode(r)
+		if g < numLiterals {
+			red, blue, alpha := codes[1].decode(r), codes[2].decode(r), codes[3].decode(r)
+			pixels = append(pixels, uint32(alpha)<<24|uint32(red)<<16|uint32(g)<<8|uint32(blue))
+			continue
+		}
+		length := prefixDecode(r, g-numLiterals)
+		code := prefixDecode(r, codes[4].decode(r))
+		var dist int
+		switch {
+		case code > planeCodes:
+			dist = code - planeCodes
+		case code == planeCodeUp:
+			dist = width
+		case code == planeCodeLeft:
+			dist = 1
+		default:
+			return nil, fmt.Errorf("unsupported plane code %d", code)
+		}
+		if dist > len(pixels) || len(pixels)+length > width*height {
+			return nil, errors.New("copy out of range")
+		}
+		for range length {
+			pixels = append(pixels, pixels[len(pixels)-dist])
+		}
+	}
+	return pixels, nil
+}
+
+func prefixDecode(r *bitReader, code int) int {
+	if code < 4 {
+		return code + 1
+	}
+	extra := uint((code - 2) >> 1)
+	offset := (2 + code&1) << extra
+	return offset + int(r.read(extra)) + 1
+}
+
+func readCodeLengths(r *bitReader, size int) ([]int, error) {
+	lengths := make([]int, size)
+	if r.read(1) == 1 {
+		num := r.read(1) + 1
+		first := r.read(1 + 7*uint(r.read(1)))
+		lengths[first] = 1
+		if num == 2 {
+			lengths[r.read(8)] = 1
+		}
+		return lengths, nil
+	}
+	clLengths := make([]int, 19)
+	for i := range int(r.read(4)) + 4 {
+		clLengths[codeLengthOrder[i]] = int(r.read(3))
+	}
+	cl := newHuffman(clLengths)
+	maxSymbol := size
+	if r.read(1) == 1 {
+		maxSymbol = 2 + int(r.read(2+2*uint(r.read(3))))
+	}
+	prev := 8
+	for sym := 0; sym < size && maxSymbol > 0; maxSymbol-- {
+		c := cl.decode(r)
+		if c < 16 {
+			lengths[sym] = c
+			sym++
+			if c != 0 {
+				prev = c
+			}
+			continue
+		}
+		value, repeat := 0, 0
+		switch c {
+		case 16:
+			value, repeat = prev, 3+int(r.read(2))
+		case 17:
+			repeat = 3 + int(r.read(3))
+		case 18:
+			repeat = 11 + int(r.read(7))
+		}
+		if sym+repeat > size {
+			return nil, errors.New("code lengths overrun")
+		}
+		for range repeat {
+			lengths[sym] = value
+			sym++
+		}
+	}
+	return lengths, nil
+}
+
+// huffman decodes a canonical code a bit at a time.
+type huffman struct {
+	single int
+	codes  map[[2]int]int // {length, code} to symbol
+}
+
+func newHuffman(lengths []int) *huffman {
+	h := &huffman{single: -1, codes: make(map[[2]int]int)}
+	var used []int
+	for s, n := range lengths {
+		if n > 0 {
+			used = append(used, s)
+		}
+	}
+	if len(used) == 1 {
+		h.single = used[0]
+		return h
+	}
+	code := 0
+	for n := 1; n <= maxCodeLength; n++ {
+		for s, m := range lengths {
+			if m == n {
+				h.codes[[2]int{n, code}] = s
+				code++
+			}
+		}
+		code <<= 1
+	}
+	return h
+}
+
+func (h *huffman) decode(r *bitReader) int {
+	if h.single >= 0 {
+		return h.single
+	}
+	code := 0
+	for n := 1; n <= maxCodeLength; n++ {
+		code = code<<1 | int(r.read(1))
+		if s, ok := h.codes[[2]int{n, code}]; ok {
+			return s
+		}
+	}
+	panic("invalid code")
+}
+
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *bitReader) read(n uint) uint32 {
+	var v uint32
+	for i := range n {
+		v |= uint32(r.data[r.pos>>3]>>(r.pos&7)&1) << i
+		r.pos++
+	}
+	return v
+}
@@ -0,0 +1,111 @@
+package jsonx_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/jsonx"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestString_MatchesEncodingJSON(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestString_MatchesEncodingJSON", "internal/jsonx")
+
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"empty", ""},
+		{"plain", "Gold Standard 100% Whey"},
+		{"quotes and backslashes", `Double "Rich" \ Chocolate`},
+		{"html", "<script>alert('x') & more</script>"},
+		{"short escapes", "line\nbreak\ttab\rreturn\bback\fform"},
+		{"other control characters", "nul\x00bell\x07esc\x1bunit\x1f"},
+		{"non-ascii", "₹3,299 · Café Mocha · 蛋白"},
+		{"invalid utf-8", "bad\xffbyte\xc3"},
+		{"javascript line terminators", "a\u2028b\u2029c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := json.Marshal(tt.in)
+			if err != nil {
+				t.Fatalf("json.Marshal: %v", err)
+			}
+			got := jsonx.String(nil, tt.in)
+			testhelpers.LogTestAssertion(logger, tt.name, string(want), string(got))
+			if string(got) != string(want) {
+				t.Errorf("String(%q) = %s, want %s", tt.in, got, want)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestString_MatchesEncodingJSON", true)
+}
+
+func TestFloat_MatchesEncodingJSON(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestFloat_MatchesEncodingJSON", "internal/jsonx")
+
+	for _, f := range []float64{0, 1, -1, 3299, 1.84, 0.1 + 0.2, -17.5, 1e-6, 9.99e-7, 1e-9, 1e20, 1e21, 1.5e300, -2.5e-300, math.MaxFloat64, math.SmallestNonzeroFloat64} {
+		want, err := json.Marshal(f)
+		if err != nil {
+			t.Fatalf("json.Marshal(%v): %v", f, err)
+		}
+		if got := jsonx.Float(nil, f); string(got) != string(want) {
+			t.Errorf("Float(%v) = %s, want %s", f, got, want)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Values encoding/json rejects are written as null")
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if got := jsonx.Float(nil, f); string(got) != "null" {
+			t.Errorf("Float(%v) = %s, want null", f, got)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestFloat_MatchesEncodingJSON", true)
+}
+
+func TestTime_MatchesEncodingJSON(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestTime_MatchesEncodingJSON", "internal/jsonx")
+
+	ist := time.FixedZone("IST", 5*3600+1800)
+	for _, tm := range []time.Time{
+		{},
+		time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC),
+		time.Date(2024, 1, 15, 14, 30, 0, 123456789, ist),
+		time.Date(2024, 1, 15, 14, 30, 0, 500000000, time.UTC),
+	} {
+		want, err := json.Marshal(tm)
+		if err != nil {
+			t.Fatalf("json.Marshal(%v): %v", tm, err)
+		}
+		if got := jsonx.Time(nil, tm); string(got) != string(want) {
+			t.Errorf("Time(%v) = %s, want %s", tm, got, want)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestTime_MatchesEncodingJSON", true)
+}
+
+func TestKey(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestKey", "internal/jsonx")
+
+	b := jsonx.Key([]byte{'{'}, "price", true)
+	b = jsonx.Int(b, 3299)
+	b = jsonx.Key(b, "in_stock", false)
+	b = jsonx.Bool(b, true)
+	b = append(b, '}')
+
+	testhelpers.LogTestAssertion(logger, "object", `{"price":3299,"in_stock":true}`, string(b))
+	if string(b) != `{"price":3299,"in_stock":true}` {
+		t.Errorf("object = %s", b)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestKey", true)
+}
@@ -0,0 +1,180 @@
+package loadtest
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/middleware"
+)
+
+// ScenarioReport summarises one scenario's requests. Errors counts transport
+// failures and non-2xx responses; percentiles cover every request that got
+// a response.
+type ScenarioReport struct {
+	Name       string        `json:"name"`
+	Route      string        `json:"route"`
+	Budget     time.Duration `json:"budget_ns"`
+	Requests   int           `json:"requests"`
+	Errors     int           `json:"errors"`
+	Statuses   map[int]int   `json:"statuses"`
+	P50        time.Duration `json:"p50_ns"`
+	P95        time.Duration `json:"p95_ns"`
+	P99        time.Duration `json:"p99_ns"`
+	Max        time.Duration `json:"max_ns"`
+	OverBudget bool          `json:"over_budget"`
+}
+
+// Report is the result of a run.
+type Report struct {
+	Duration  time.Duration    `json:"duration_ns"`
+	Requests  int              `json:"requests"`
+	Errors    int              `json:"errors"`
+	Scenarios []ScenarioReport `json:"scenarios"`
+}
+
+// Throughput returns requests per second over the run.
+func (r *Report) Throughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Requests) / r.Duration.Seconds()
+}
+
+// CheckBudgets marks scenarios whose latency at percentile p (50, 95 or
+// 99) exceeds their route budget, and returns them.
+func (r *Report) CheckBudgets(p int) []ScenarioReport {
+	var over []ScenarioReport
+	for i := range r.Scenarios {
+		s := &r.Scenarios[i]
+		var at time.Duration
+		switch p {
+		case 50:
+			at = s.P50
+		case 99:
+			at = s.P99
+		default:
+			at = s.P95
+		}
+		s.OverBudget = s.Requests > 0 && s.Budget > 0 && at > s.Budget
+		if s.OverBudget {
+			over = append(over, *s)
+		}
+	}
+	return over
+}
+
+// WriteText writes the report as an aligned table.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "scenario\trequests\terrors\tp50\tp95\tp99\tmax\tbudget\t\t")
+	for _, s := range r.Scenarios {
+		verdict := "ok"
+		if s.OverBudget {
+			verdict = "OVER"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			s.Name, s.Requests, s.Errors, ms(s.P50), ms(s.P95), ms(s.P99), ms(s.Max), ms(s.Budget), verdict)
+	}
+	fmt.Fprintf(tw, "total\t%d\t%d\t\t\t\t\t\t\t\n", r.Requests, r.Errors)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%.1f req/s over %s\n", r.Throughput(), r.Duration.Round(time.Millisecond))
+	return err
+}
+
+func ms(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
+
+// recorder collects results from concurrent workers.
+type recorder struct {
+	mu        sync.Mutex
+	scenarios map[string]*samples
+	order     []Scenario
+}
+
+type samples struct {
+	latencies []time.Duration
+	errors    int
+	statuses  map[int]int
+}
+
+func newRecorder(scenarios []Scenario) *recorder {
+	r := &recorder{scenarios: make(map[string]*samples), order: scenarios}
+	for _, s := range scenarios {
+		r.scenarios[s.Name] = &samples{statuses: make(map[int]int)}
+	}
+	return r
+}
+
+func (r *recorder) add(name string, status int, took time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.scenarios[name]
+	if err != nil && status == 0 {
+		s.errors++
+		s.statuses[0]++
+		return
+	}
+	s.latencies = append(s.latencies, took)
+	s.statuses[status]++
+	if err != nil || status < 200 || status > 299 {
+		s.errors++
+	}
+}
+
+// report builds the Report, taking each scenario's budget from the API's
+// default latency budgets.
+func (r *recorder) report(elapsed time.Duration) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	budgets := middleware.DefaultLatencyBudgetConfig()
+	rep := &Report{Duration: elapsed}
+	for _, sc := range r.order {
+		s := r.scenarios[sc.Name]
+		requests := len(s.latencies) + s.statuses[0]
+		if requests == 0 {
+			continue
+		}
+		budget, ok := budgets.Routes[sc.Route]
+		if !ok {
+			budget = budgets.Default
+		}
+		sorted := slices.Clone(s.latencies)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		sr := ScenarioReport{
+			Name:     sc.Name,
+			Route:    sc.Route,
+			Budget:   budget,
+			Requests: requests,
+			Errors:   s.errors,
+			Statuses: s.statuses,
+			P50:      percentile(sorted, 50),
+			P95:      percentile(sorted, 95),
+			P99:      percentile(sorted, 99),
+		}
+		if len(sorted) > 0 {
+			sr.Max = sorted[len(sorted)-1]
+		}
+		rep.Requests += requests
+		rep.Errors += s.errors
+		rep.Scenarios = append(rep.Scenarios, sr)
+	}
+	return rep
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
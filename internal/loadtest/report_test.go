@@ -0,0 +1,91 @@
+package loadtest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestPercentile(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPercentile", "internal/loadtest")
+
+	sorted := make([]time.Duration, 100)
+	for i := range sorted {
+		sorted[i] = time.Duration(i+1) * time.Millisecond
+	}
+	tests := []struct {
+		p    float64
+		in   []time.Duration
+		want time.Duration
+	}{
+		{50, sorted, 50 * time.Millisecond},
+		{95, sorted, 95 * time.Millisecond},
+		{99, sorted, 99 * time.Millisecond},
+		{99, sorted[:1], time.Millisecond},
+		{0, sorted, time.Millisecond},
+		{95, nil, 0},
+	}
+	for _, tt := range tests {
+		got := percentile(tt.in, tt.p)
+		testhelpers.LogTestAssertion(logger, "percentile", tt.want, got)
+		if got != tt.want {
+			t.Errorf("percentile(%d samples, %v) = %s, want %s", len(tt.in), tt.p, got, tt.want)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPercentile", true)
+}
+
+func TestReport_CheckBudgets(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestReport_CheckBudgets", "internal/loadtest")
+
+	testhelpers.LogTestStep(logger, "arrange", "Recording a fast and a slow scenario")
+	rec := newRecorder(DefaultScenarios())
+	for i := range 99 {
+		took := 10 * time.Millisecond
+		if i < 2 {
+			took = 400 * time.Millisecond
+		}
+		rec.add("prices", 200, took, nil)
+		rec.add("history", 200, 100*time.Millisecond, nil)
+	}
+	rec.add("prices", 200, 10*time.Millisecond, nil)
+	rec.add("history", 0, 0, transportError{})
+	report := rec.report(time.Second)
+
+	testhelpers.LogTestStep(logger, "act", "Checking p95 and p99")
+	over95 := report.CheckBudgets(95)
+	over99 := report.CheckBudgets(99)
+
+	testhelpers.LogTestStep(logger, "assert", "Only the prices tail breaks its budget")
+	testhelpers.LogTestAssertion(logger, "p95 violations", 0, len(over95))
+	if len(over95) != 0 {
+		t.Errorf("p95 over budget = %+v, want none", over95)
+	}
+	if len(over99) != 1 || over99[0].Name != "prices" {
+		t.Errorf("p99 over budget = %+v, want prices", over99)
+	}
+	if report.Requests != 200 || report.Errors != 1 {
+		t.Errorf("totals = %d requests, %d errors, want 200 and 1", report.Requests, report.Errors)
+	}
+
+	var out bytes.Buffer
+	if err := report.WriteText(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "OVER") || !strings.Contains(out.String(), "200.0 req/s") {
+		t.Errorf("text report missing verdict or throughput:\n%s", out.String())
+	}
+
+	testhelpers.LogTestComplete(logger, "TestReport_CheckBudgets", true)
+}
+
+// transportError stands in for a transport error.
+type transportError struct{}
+
+func (transportError) Error() string { return "context deadline exceeded" }
@@ -0,0 +1,158 @@
+package loadtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config configures a run.
+type Config struct {
+	// Target is the API origin, such as http://localhost:8080.
+	Target string
+	// Duration is how long to send traffic.
+	Duration time.Duration
+	// Concurrency is the number of parallel workers.
+	Concurrency int
+	// Rate caps requests per second across all workers; zero sends as fast
+	// as the workers can.
+	Rate float64
+	// Timeout bounds each request.
+	Timeout time.Duration
+	// ProductIDs are the products to request. When empty, Run discovers them
+	// from the deals endpoint.
+	ProductIDs []string
+	// Seed makes the request sequence reproducible.
+	Seed uint64
+}
+
+// DefaultConfig runs 10 workers for 30 seconds.
+func DefaultConfig(target string) Config {
+	return Config{Target: target, Duration: 30 * time.Second, Concurrency: 10, Timeout: 10 * time.Second, Seed: 1}
+}
+
+// Run sends the scenario mix at cfg.Target until cfg.Duration elapses or
+// ctx is done, and reports latencies per scenario.
+func Run(ctx context.Context, cfg Config, scenarios []Scenario, client *http.Client) (*Report, error) {
+	if cfg.Concurrency < 1 || cfg.Duration <= 0 {
+		return nil, errors.New("concurrency and duration must be positive")
+	}
+	cfg.Target = strings.TrimRight(cfg.Target, "/")
+	if len(cfg.ProductIDs) == 0 {
+		ids, err := DiscoverProducts(ctx, client, cfg.Target)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ProductIDs = ids
+	}
+	choose := newChooser(scenarios)
+	if len(choose.scenarios) == 0 {
+		return nil, errors.New("no scenario has a positive weight")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+	var tokens <-chan time.Time
+	if cfg.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / cfg.Rate))
+		defer ticker.Stop()
+		tokens = ticker.C
+	}
+
+	rec := newRecorder(scenarios)
+	start := time.Now()
+	var wg sync.WaitGroup
+	for w := range cfg.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rng := rand.New(rand.NewPCG(cfg.Seed, uint64(w)))
+			for {
+				if tokens != nil {
+					select {
+					case <-tokens:
+					case <-ctx.Done():
+						return
+					}
+				}
+				if ctx.Err() != nil {
+					return
+				}
+				s := choose.pick(rng)
+				status, took, err := send(ctx, client, cfg.Target+s.Path(rng, cfg.ProductIDs), cfg.Timeout)
+				if ctx.Err() != nil {
+					// Requests cut off by the end of the run are not
+					// measurements.
+					return
+				}
+				rec.add(s.Name, status, took, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return rec.report(time.Since(start)), nil
+}
+
+// send issues one GET, reading the whole body so the timing covers the full
+// response.
+func send(ctx context.Context, client *http.Client, target string, timeout time.Duration) (int, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, time.Since(start), err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, time.Since(start), err
+}
+
+// DiscoverProducts reads product IDs from the target's deals endpoint, so a
+// run needs no knowledge of the environment's catalog.
+func DiscoverProducts(ctx context.Context, client *http.Client, target string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(target, "/")+"/api/v1/deals?limit=100", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("discover products: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discover products: deals returned %s", resp.Status)
+	}
+	var body struct {
+		Deals []struct {
+			Product struct {
+				ID string `json:"id"`
+			} `json:"product"`
+		} `json:"deals"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("discover products: %w", err)
+	}
+	ids := make([]string, 0, len(body.Deals))
+	for _, d := range body.Deals {
+		ids = append(ids, d.Product.ID)
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("discover products: the target lists no deals; pass product IDs explicitly")
+	}
+	return ids, nil
+}
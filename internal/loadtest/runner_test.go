@@ -0,0 +1,91 @@
+package loadtest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestRun_ReportsPerScenario(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestRun_ReportsPerScenario", "internal/loadtest")
+
+	testhelpers.LogTestStep(logger, "arrange", "A target serving deals and failing price history")
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/deals", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"deals":[{"product":{"id":%q}},{"product":{"id":%q}}]}`,
+			testhelpers.FixtureProductID, testhelpers.FixtureSecondProductID)
+	})
+	mux.HandleFunc("GET /api/v1/products/{id}/prices", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	})
+	mux.HandleFunc("GET /api/v1/products/{id}/price-history", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	scenarios, err := ParseMix("prices=3,history=1,product=0,deals=0,compare=0", DefaultScenarios())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig(srv.URL + "/")
+	cfg.Duration = 200 * time.Millisecond
+	cfg.Concurrency = 4
+
+	testhelpers.LogTestStep(logger, "act", "Running with discovered products")
+	report, err := Run(t.Context(), cfg, scenarios, srv.Client())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Each scenario reports its requests, errors and budget")
+	byName := make(map[string]ScenarioReport)
+	for _, s := range report.Scenarios {
+		byName[s.Name] = s
+	}
+	prices, history := byName["prices"], byName["history"]
+	testhelpers.LogTestAssertion(logger, "scenarios", 2, len(report.Scenarios))
+	if len(report.Scenarios) != 2 || prices.Requests == 0 || history.Requests == 0 {
+		t.Fatalf("scenarios = %+v, want prices and history with traffic", report.Scenarios)
+	}
+	if prices.Errors != 0 || prices.Statuses[http.StatusOK] != prices.Requests {
+		t.Errorf("prices = %+v, want every request 200", prices)
+	}
+	if history.Errors != history.Requests || history.Statuses[http.StatusInternalServerError] != history.Requests {
+		t.Errorf("history = %+v, want every request a 500 error", history)
+	}
+	if prices.Budget != 50*time.Millisecond || history.Budget != 200*time.Millisecond {
+		t.Errorf("budgets = %s, %s, want the cached and database budgets", prices.Budget, history.Budget)
+	}
+	if report.Requests != prices.Requests+history.Requests || prices.P50 > prices.P99 || prices.P99 > prices.Max {
+		t.Errorf("report totals or percentiles inconsistent: %+v", report)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestRun_ReportsPerScenario", true)
+}
+
+func TestDiscoverProducts_EmptyCatalog(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestDiscoverProducts_EmptyCatalog", "internal/loadtest")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"deals":[]}`)
+	}))
+	defer srv.Close()
+
+	testhelpers.LogTestStep(logger, "act", "Discovering against a target with no deals")
+	_, err := DiscoverProducts(t.Context(), srv.Client(), srv.URL)
+
+	testhelpers.LogTestAssertion(logger, "error", "pass product IDs explicitly", err)
+	if err == nil || !strings.Contains(err.Error(), "pass product IDs explicitly") {
+		t.Errorf("err = %v, want a hint to pass product IDs", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestDiscoverProducts_EmptyCatalog", true)
+}
@@ -0,0 +1,128 @@
+// Package loadtest replays a weighted mix of API reads against a running
+// server and reports latency percentiles against the route budgets the API
+// enforces in middleware.LatencyBudget.
+package loadtest
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Scenario is one kind of request in the traffic mix.
+type Scenario struct {
+	Name string
+	// Route is the ServeMux pattern the request hits, used to look up its
+	// latency budget.
+	Route string
+	// Weight is the scenario's share of traffic relative to the others.
+	Weight int
+	// Path builds a request path and query from the known product IDs.
+	Path func(rng *rand.Rand, productIDs []string) string
+}
+
+// searchTerms are typical shopper queries.
+var searchTerms = []string{"whey", "isolate", "gold standard", "biozyme", "chocolate", "1kg", "mass gainer"}
+
+// DefaultScenarios approximates production traffic: mostly price and
+// product reads from shoppers and the widget, fewer comparisons and
+// histories. Search is weighted 0 until the search endpoint ships; enable it
+// with -mix.
+func DefaultScenarios() []Scenario {
+	return []Scenario{
+		{Name: "prices", Route: "GET /api/v1/products/{id}/prices", Weight: 35, Path: func(rng *rand.Rand, ids []string) string {
+			return "/api/v1/products/" + url.PathEscape(pick(rng, ids)) + "/prices"
+		}},
+		{Name: "product", Route: "GET /api/v1/products/{id}", Weight: 20, Path: func(rng *rand.Rand, ids []string) string {
+			return "/api/v1/products/" + url.PathEscape(pick(rng, ids))
+		}},
+		{Name: "deals", Route: "GET /api/v1/deals", Weight: 15, Path: func(rng *rand.Rand, _ []string) string {
+			return "/api/v1/deals?limit=" + strconv.Itoa(10+rng.IntN(3)*10)
+		}},
+		{Name: "compare", Route: "GET /api/v1/compare", Weight: 15, Path: func(rng *rand.Rand, ids []string) string {
+			n := min(len(ids), 2+rng.IntN(3))
+			chosen := make([]string, 0, n)
+			for _, i := range rng.Perm(len(ids))[:n] {
+				chosen = append(chosen, ids[i])
+			}
+			return "/api/v1/compare?ids=" + url.QueryEscape(strings.Join(chosen, ","))
+		}},
+		{Name: "history", Route: "GET /api/v1/products/{id}/price-history", Weight: 10, Path: func(rng *rand.Rand, ids []string) string {
+			days := []int{7, 30, 90}[rng.IntN(3)]
+			return "/api/v1/products/" + url.PathEscape(pick(rng, ids)) + "/price-history?days=" + strconv.Itoa(days)
+		}},
+		{Name: "search", Route: "GET /api/v1/products/search", Weight: 0, Path: func(rng *rand.Rand, _ []string) string {
+			return "/api/v1/products/search?q=" + url.QueryEscape(pick(rng, searchTerms))
+		}},
+	}
+}
+
+// ParseMix reweights scenarios from a comma-separated list of name=weight
+// pairs, such as "prices=50,search=10". Scenarios not listed keep their
+// weight; at least one scenario must end up with a positive weight.
+func ParseMix(raw string, scenarios []Scenario) ([]Scenario, error) {
+	out := make([]Scenario, len(scenarios))
+	copy(out, scenarios)
+	byName := make(map[string]int, len(out))
+	for i, s := range out {
+		byName[s.Name] = i
+	}
+	for _, entry := range strings.Split(raw, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(entry, "=")
+		i, ok := byName[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("mix: unknown scenario %q", strings.TrimSpace(name))
+		}
+		w, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("mix: %s weight must be a non-negative integer, got %q", out[i].Name, value)
+		}
+		out[i].Weight = w
+	}
+	total := 0
+	for _, s := range out {
+		total += s.Weight
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("mix: every scenario has weight 0")
+	}
+	return out, nil
+}
+
+// chooser picks scenarios in proportion to their weights.
+type chooser struct {
+	scenarios  []Scenario
+	cumulative []int
+}
+
+func newChooser(scenarios []Scenario) *chooser {
+	c := &chooser{}
+	total := 0
+	for _, s := range scenarios {
+		if s.Weight > 0 {
+			total += s.Weight
+			c.scenarios = append(c.scenarios, s)
+			c.cumulative = append(c.cumulative, total)
+		}
+	}
+	return c
+}
+
+func (c *chooser) pick(rng *rand.Rand) Scenario {
+	n := rng.IntN(c.cumulative[len(c.cumulative)-1])
+	for i, upper := range c.cumulative {
+		if n < upper {
+			return c.scenarios[i]
+		}
+	}
+	return c.scenarios[len(c.scenarios)-1]
+}
+
+func pick(rng *rand.Rand, items []string) string {
+	return items[rng.IntN(len(items))]
+}
@@ -0,0 +1,103 @@
+package loadtest
+
+import (
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestParseMix(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestParseMix", "internal/loadtest")
+
+	tests := []struct {
+		name       string
+		raw        string
+		wantSearch int
+		wantPrices int
+		wantErr    string
+	}{
+		{name: "empty keeps defaults", raw: "", wantSearch: 0, wantPrices: 35},
+		{name: "overrides", raw: "search=10, prices=50", wantSearch: 10, wantPrices: 50},
+		{name: "unknown scenario", raw: "checkout=5", wantErr: "unknown scenario"},
+		{name: "bad weight", raw: "prices=-1", wantErr: "non-negative"},
+		{name: "all zero", raw: "prices=0,product=0,deals=0,compare=0,history=0", wantErr: "weight 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testhelpers.LogTestStep(logger, "act", "Parsing "+tt.raw)
+			got, err := ParseMix(tt.raw, DefaultScenarios())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseMix(%q) error = %v, want %q", tt.raw, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseMix(%q): %v", tt.raw, err)
+			}
+			weights := make(map[string]int)
+			for _, s := range got {
+				weights[s.Name] = s.Weight
+			}
+			testhelpers.LogTestAssertion(logger, "search weight", tt.wantSearch, weights["search"])
+			if weights["search"] != tt.wantSearch || weights["prices"] != tt.wantPrices {
+				t.Errorf("weights = %v, want search %d prices %d", weights, tt.wantSearch, tt.wantPrices)
+			}
+		})
+	}
+	if DefaultScenarios()[0].Weight != 35 {
+		t.Error("ParseMix modified the default scenarios")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestParseMix", true)
+}
+
+func TestChooser_FollowsWeights(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestChooser_FollowsWeights", "internal/loadtest")
+
+	testhelpers.LogTestStep(logger, "arrange", "A 3:1 mix with a disabled scenario")
+	c := newChooser([]Scenario{{Name: "a", Weight: 3}, {Name: "b", Weight: 1}, {Name: "off", Weight: 0}})
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	testhelpers.LogTestStep(logger, "act", "Picking 10000 scenarios")
+	counts := make(map[string]int)
+	for range 10000 {
+		counts[c.pick(rng).Name]++
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Shares match the weights")
+	testhelpers.LogTestAssertion(logger, "disabled picks", 0, counts["off"])
+	if counts["off"] != 0 {
+		t.Errorf("picked a zero-weight scenario %d times", counts["off"])
+	}
+	if share := float64(counts["a"]) / 10000; share < 0.72 || share > 0.78 {
+		t.Errorf("share of a = %.3f, want about 0.75", share)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestChooser_FollowsWeights", true)
+}
+
+func TestDefaultScenarios_Paths(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestDefaultScenarios_Paths", "internal/loadtest")
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	ids := []string{testhelpers.FixtureProductID, testhelpers.FixtureSecondProductID}
+	for _, s := range DefaultScenarios() {
+		path := s.Path(rng, ids)
+		testhelpers.LogTestAssertion(logger, s.Name+" path", "/api/v1/...", path)
+		if !strings.HasPrefix(path, "/api/v1/") {
+			t.Errorf("%s path = %q, want an /api/v1/ path", s.Name, path)
+		}
+	}
+	compare := DefaultScenarios()[3]
+	if got := compare.Path(rng, ids); got != "/api/v1/compare?ids=prod_on_gsw%2Cprod_mb_biozyme" && got != "/api/v1/compare?ids=prod_mb_biozyme%2Cprod_on_gsw" {
+		t.Errorf("compare path = %q, want both products", got)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestDefaultScenarios_Paths", true)
+}
@@ -0,0 +1,39 @@
+// Package logctx carries log fields in a context, so code deep in a
+// request logs the request's correlation ID without it being passed down:
+//
+//	logctx.Logger(ctx, s.logger).Warn("Comparison view read failed", zap.Error(err))
+package logctx
+
+import (
+	"context"
+	"slices"
+
+	"go.uber.org/zap"
+)
+
+type fieldsKey struct{}
+
+// With returns a copy of ctx carrying fields, after any ctx already
+// carries.
+func With(ctx context.Context, fields ...zap.Field) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, fieldsKey{}, append(slices.Clip(Fields(ctx)), fields...))
+}
+
+// Fields returns the fields ctx carries.
+func Fields(ctx context.Context) []zap.Field {
+	fields, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	return fields
+}
+
+// Logger returns logger with the fields ctx carries, or logger itself when
+// it carries none.
+func Logger(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	fields := Fields(ctx)
+	if len(fields) == 0 {
+		return logger
+	}
+	return logger.With(fields...)
+}
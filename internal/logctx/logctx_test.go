@@ -0,0 +1,48 @@
+package logctx
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestLogger_AddsContextFields(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestLogger_AddsContextFields", "internal/logctx")
+
+	testhelpers.LogTestStep(logger, "arrange", "A request context, and a sibling derived from the same parent")
+	core, logs := observer.New(zap.InfoLevel)
+	base := zap.New(core)
+	parent := With(t.Context(), zap.String("request_id", "req_abc123"))
+	ctx := With(parent, zap.String("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736"))
+	sibling := With(parent, zap.String("batch", "1"))
+
+	testhelpers.LogTestStep(logger, "act", "Logging through each context and through none")
+	Logger(ctx, base).Info("Loading product")
+	Logger(sibling, base).Info("Loading product")
+	Logger(t.Context(), base).Info("Loading product")
+
+	testhelpers.LogTestStep(logger, "assert", "Each line carries its own context's fields only")
+	entries := logs.All()
+	testhelpers.LogTestAssertion(logger, "lines", 3, len(entries))
+	if len(entries) != 3 {
+		t.Fatalf("Logged %d lines, want 3", len(entries))
+	}
+	if f := entries[0].ContextMap(); len(f) != 2 || f["request_id"] != "req_abc123" || f["trace_id"] == nil {
+		t.Errorf("Request line fields = %v", f)
+	}
+	if f := entries[1].ContextMap(); len(f) != 2 || f["batch"] != "1" || f["trace_id"] != nil {
+		t.Errorf("Sibling line fields = %v", f)
+	}
+	if f := entries[2].ContextMap(); len(f) != 0 {
+		t.Errorf("Bare line fields = %v", f)
+	}
+	if Logger(t.Context(), base) != base {
+		t.Error("Logger without fields should return the logger itself")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestLogger_AddsContextFields", true)
+}
@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestRegistry_WriteText", "internal/metrics")
+
+	testhelpers.LogTestStep(logger, "arrange", "A counter, a gauge, a histogram and a collected gauge")
+	reg := NewRegistry()
+	requests := reg.Counter("http_requests_total", "Requests served.", "route", "code")
+	open := reg.Gauge("pool_open", "Connections open.")
+	latency := reg.Histogram("query_seconds", "Query latency.", []float64{0.01, 0.1}, "family")
+	reg.GaugeFunc("table_rows", "Rows per table.", []string{"table"}, func(emit func(float64, ...string)) {
+		emit(3, "products")
+		emit(1200, "price_history")
+	})
+	reg.Counter("unused_total", "Never incremented.")
+
+	testhelpers.LogTestStep(logger, "act", "Recording and writing")
+	requests.Inc("GET /api/v1/deals", "200")
+	requests.Add(2, "GET /api/v1/deals", "200")
+	requests.Inc(`GET /a"b\`, "500")
+	open.Set(4)
+	open.Add(-1)
+	latency.Observe(0.004, "select products")
+	latency.Observe(0.01, "select products")
+	latency.Observe(0.5, "select products")
+	var b strings.Builder
+	if err := reg.WriteText(&b); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Families are sorted, histograms cumulative, labels escaped and empty families left out")
+	want := `# HELP http_requests_total Requests served.
+# TYPE http_requests_total counter
+http_requests_total{route="GET /a\"b\\",code="500"} 1
+http_requests_total{route="GET /api/v1/deals",code="200"} 3
+# HELP pool_open Connections open.
+# TYPE pool_open gauge
+pool_open 3
+# HELP query_seconds Query latency.
+# TYPE query_seconds histogram
+query_seconds_bucket{family="select products",le="0.01"} 2
+query_seconds_bucket{family="select products",le="0.1"} 2
+query_seconds_bucket{family="select products",le="+Inf"} 3
+query_seconds_sum{family="select products"} 0.514
+query_seconds_count{family="select products"} 3
+# HELP table_rows Rows per table.
+# TYPE table_rows gauge
+table_rows{table="price_history"} 1200
+table_rows{table="products"} 3
+`
+	testhelpers.LogTestAssertion(logger, "exposition", want, b.String())
+	if b.String() != want {
+		t.Errorf("WriteText =\n%s\nwant\n%s", b.String(), want)
+	}
+
+	rec := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != ContentType || rec.Body.String() != want {
+		t.Errorf("Handler served %q with %q", ct, rec.Body.String())
+	}
+
+	testhelpers.LogTestComplete(logger, "TestRegistry_WriteText", true)
+}
+
+func TestRegistry_Misuse(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestRegistry_Misuse", "internal/metrics")
+
+	reg := NewRegistry()
+	c := reg.Counter("jobs_total", "Jobs.", "queue")
+	for name, fn := range map[string]func(){
+		"duplicate name":     func() { reg.Gauge("jobs_total", "Again.") },
+		"invalid name":       func() { reg.Gauge("jobs-done", "Dash.") },
+		"reserved label":     func() { reg.Histogram("wait_seconds", "Wait.", nil, "le") },
+		"wrong label count":  func() { c.Inc() },
+		"decreasing counter": func() { c.Add(-1, "scrape") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				testhelpers.LogTestAssertion(logger, name, "panic", "recovered")
+				if recover() == nil {
+					t.Errorf("%s did not panic", name)
+				}
+			}()
+			fn()
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestRegistry_Misuse", true)
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/logctx"
+)
+
+// maxRequestIDLength bounds the request IDs accepted from callers.
+const maxRequestIDLength = 128
+
+// AccessLogConfig configures the access log.
+type AccessLogConfig struct {
+	// SkipRoutes are route patterns served without an access log line,
+	// such as health probes polled every few seconds. Their requests
+	// still get request IDs.
+	SkipRoutes []string
+}
+
+// DefaultAccessLogConfig leaves the health probes out.
+func DefaultAccessLogConfig() AccessLogConfig {
+	return AccessLogConfig{SkipRoutes: []string{"GET /health", "GET /healthz", "GET /readyz"}}
+}
+
+// AccessLog gives every request a correlation ID and logs one line per
+// request once it is served. The ID is the caller's X-Request-ID when it
+// sends a well-formed one, so a request can be followed from the service
+// that made it, and is otherwise generated. It is set on the request
+// header, where httpx.RequestID and error envelopes read it, on the
+// response, and in the request context's log fields, so every line logged
+// through logctx.Logger while serving the request carries it.
+//
+// Lines hold the route, status, size and duration, but not the client
+// address or query string, which may identify users.
+type AccessLog struct {
+	skip   map[string]bool
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewAccessLog creates the middleware.
+func NewAccessLog(cfg AccessLogConfig, logger *zap.Logger) *AccessLog {
+	skip := make(map[string]bool, len(cfg.SkipRoutes))
+	for _, route := range cfg.SkipRoutes {
+		skip[route] = true
+	}
+	return &AccessLog{skip: skip, logger: logger, now: time.Now}
+}
+
+// Handler returns the middleware. Place it outside every middleware that
+// logs, so their lines carry the request ID, and inside Tracing, so its
+// own lines carry the trace ID.
+func (m *AccessLog) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(httpx.RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+			r.Header.Set(httpx.RequestIDHeader, id)
+		}
+		w.Header().Set(httpx.RequestIDHeader, id)
+		ctx := logctx.With(httpx.TrackRoute(r.Context()), zap.String("request_id", id))
+
+		start := m.now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r.WithContext(ctx))
+
+		route := httpx.Route(ctx)
+		if m.skip[route] {
+			return
+		}
+		if route == "" {
+			route = unmatchedRoute
+		}
+		logger := logctx.Logger(ctx, m.logger)
+		fields := []zap.Field{
+			zap.String("method", r.Method),
+			zap.String("route", route),
+			zap.String("path", r.URL.Path),
+			zap.Int("status", sw.status),
+			zap.Int64("bytes", sw.bytes),
+			zap.Duration("duration", m.now().Sub(start)),
+		}
+		if sw.status >= http.StatusInternalServerError {
+			logger.Warn("Request served", fields...)
+			return
+		}
+		logger.Info("Request served", fields...)
+	})
+}
+
+// validRequestID reports whether a caller's ID is safe to log and echo:
+// short, and letters, digits and ._:- only.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		ok := c == '.' || c == '_' || c == ':' || c == '-' ||
+			(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "req_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/logctx"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestAccessLog_RequestIDs(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAccessLog_RequestIDs", "internal/middleware")
+
+	testhelpers.LogTestStep(logger, "arrange", "An access-logged router whose handler logs through logctx")
+	core, logs := observer.New(zap.InfoLevel)
+	appLogger := zap.New(core)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/products/{id}", func(w http.ResponseWriter, r *http.Request) {
+		logctx.Logger(r.Context(), appLogger).Info("Loading product")
+		_, _ = w.Write([]byte(`{"id":"prod_on_gsw"}`))
+	})
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {})
+	h := NewAccessLog(DefaultAccessLogConfig(), appLogger).Handler(httpx.RecordRoute(mux))
+
+	testCases := []struct {
+		name     string
+		sent     string
+		wantSame bool
+	}{
+		{"Caller's ID is propagated", "req_abc123", true},
+		{"Missing ID is generated", "", false},
+		{"Malformed ID is replaced", "abc\nforged line", false},
+		{"Overlong ID is replaced", strings.Repeat("a", maxRequestIDLength+1), false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testhelpers.LogTestStep(logger, "act", tc.name)
+			logs.TakeAll()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/products/prod_on_gsw", nil)
+			if tc.sent != "" {
+				req.Header.Set(httpx.RequestIDHeader, tc.sent)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			testhelpers.LogTestStep(logger, "assert", "The response, handler line and access line share one ID")
+			id := rec.Header().Get(httpx.RequestIDHeader)
+			testhelpers.LogTestAssertion(logger, "request ID", tc.sent, id)
+			if tc.wantSame && id != tc.sent {
+				t.Errorf("Request ID = %q, want %q", id, tc.sent)
+			}
+			if !tc.wantSame && (id == tc.sent || !strings.HasPrefix(id, "req_")) {
+				t.Errorf("Request ID = %q, want a generated one", id)
+			}
+			entries := logs.All()
+			if len(entries) != 2 {
+				t.Fatalf("Logged %d lines, want 2", len(entries))
+			}
+			for _, e := range entries {
+				if got := e.ContextMap()["request_id"]; got != id {
+					t.Errorf("%q request_id = %v, want %q", e.Message, got, id)
+				}
+			}
+			access := entries[1].ContextMap()
+			if entries[1].Message != "Request served" || access["route"] != "GET /api/v1/products/{id}" ||
+				access["status"] != int64(http.StatusOK) || access["bytes"] != int64(len(`{"id":"prod_on_gsw"}`)) {
+				t.Errorf("Access line = %s %v", entries[1].Message, access)
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Serving a health probe")
+	logs.TakeAll()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	testhelpers.LogTestStep(logger, "assert", "Probes get an ID but no access line")
+	testhelpers.LogTestAssertion(logger, "lines", 0, logs.Len())
+	if logs.Len() != 0 || rec.Header().Get(httpx.RequestIDHeader) == "" {
+		t.Errorf("Probe logged %d lines, ID %q", logs.Len(), rec.Header().Get(httpx.RequestIDHeader))
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAccessLog_RequestIDs", true)
+}
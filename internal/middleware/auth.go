@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+	"github.com/yourusername/whey-price-compare/internal/logctx"
+)
+
+// BearerAuthConfig configures static bearer token authentication.
+type BearerAuthConfig struct {
+	// Realm is reported in the WWW-Authenticate challenge.
+	Realm string
+	// Tokens maps principal IDs to their tokens.
+	Tokens map[string]string
+}
+
+// ParseTokens reads principal=token pairs separated by commas, the format
+// used by the ADMIN_TOKENS environment variable.
+func ParseTokens(s string) (map[string]string, error) {
+	tokens := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		id, token, ok := strings.Cut(pair, "=")
+		id, token = strings.TrimSpace(id), strings.TrimSpace(token)
+		if !ok || id == "" || token == "" {
+			return nil, fmt.Errorf("token entry %d: want principal=token", len(tokens)+1)
+		}
+		if len(token) < 16 {
+			return nil, fmt.Errorf("token for %q is shorter than 16 characters", id)
+		}
+		tokens[id] = token
+	}
+	return tokens, nil
+}
+
+// BearerAuth rejects requests without a known bearer token and records the
+// caller's principal ID in the request context.
+type BearerAuth struct {
+	realm  string
+	hashes map[string][sha256.Size]byte // principal -> token hash
+	logger *zap.Logger
+}
+
+// NewBearerAuth creates the middleware. Only token hashes are kept in memory.
+func NewBearerAuth(cfg BearerAuthConfig, logger *zap.Logger) *BearerAuth {
+	a := &BearerAuth{realm: cfg.Realm, hashes: make(map[string][sha256.Size]byte, len(cfg.Tokens)), logger: logger}
+	if a.realm == "" {
+		a.realm = "api"
+	}
+	for id, token := range cfg.Tokens {
+		a.hashes[id] = sha256.Sum256([]byte(token))
+	}
+	return a
+}
+
+// Handler returns the middleware.
+func (a *BearerAuth) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := a.authenticate(r.Header.Get("Authorization"))
+		if !ok {
+			logctx.Logger(r.Context(), a.logger).Warn("Authentication failed",
+				zap.String("operation", "BearerAuth"),
+				zap.String("path", r.URL.Path),
+			)
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer realm=%q", a.realm))
+			httpx.WriteError(w, r, http.StatusUnauthorized, httpx.CodeUnauthorized,
+				i18n.FromContext(r.Context()).T(i18n.MsgUnauthorized), nil)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(httpx.WithPrincipal(r.Context(), id)))
+	})
+}
+
+// authenticate compares the presented token against every principal in
+// constant time so response timing does not reveal which prefix matched.
+func (a *BearerAuth) authenticate(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	presented := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	var match string
+	for id, hash := range a.hashes {
+		if subtle.ConstantTimeCompare(presented[:], hash[:]) == 1 {
+			match = id
+		}
+	}
+	return match, match != ""
+}
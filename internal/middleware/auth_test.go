@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestBearerAuth(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestBearerAuth", "internal/middleware")
+
+	auth := NewBearerAuth(BearerAuthConfig{Realm: "admin", Tokens: map[string]string{
+		"alice": "alice-token-0123456789",
+		"bob":   "bob-token-0123456789",
+	}}, logger)
+	h := auth.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(httpx.Principal(r.Context())))
+	}))
+
+	testCases := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantPrincipal string
+	}{
+		{"Valid token", "Bearer bob-token-0123456789", http.StatusOK, "bob"},
+		{"Scheme is case-insensitive", "bearer alice-token-0123456789", http.StatusOK, "alice"},
+		{"Missing header", "", http.StatusUnauthorized, ""},
+		{"Unknown token", "Bearer nope-0123456789abcdef", http.StatusUnauthorized, ""},
+		{"Basic auth", "Basic YWxpY2U6c2VjcmV0", http.StatusUnauthorized, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit-log", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantStatus, rec.Code)
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("Status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			if tc.wantStatus == http.StatusOK && rec.Body.String() != tc.wantPrincipal {
+				t.Errorf("Principal = %q, want %q", rec.Body.String(), tc.wantPrincipal)
+			}
+			if tc.wantStatus == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") != `Bearer realm="admin"` {
+				t.Errorf("WWW-Authenticate = %q", rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestBearerAuth", true)
+}
+
+func TestParseTokens(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestParseTokens", "internal/middleware")
+
+	testCases := []struct {
+		name    string
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{"Empty", "", 0, false},
+		{"Two principals", "alice=aaaaaaaaaaaaaaaa, bob=bbbbbbbbbbbbbbbb", 2, false},
+		{"Missing separator", "alice", 0, true},
+		{"Short token", "alice=short", 0, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens, err := ParseTokens(tc.input)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err != nil)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if len(tokens) != tc.want {
+				t.Errorf("Got %d tokens, want %d", len(tokens), tc.want)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestParseTokens", true)
+}
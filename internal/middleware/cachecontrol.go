@@ -0,0 +1,242 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+)
+
+// Surrogate key header names. Fastly and most other CDNs read
+// Surrogate-Key with space-separated keys; Cloudflare reads Cache-Tag with
+// comma-separated keys.
+const (
+	SurrogateKeyHeader = "Surrogate-Key"
+	CacheTagHeader     = "Cache-Tag"
+)
+
+// CachePolicy describes how one route may be cached.
+type CachePolicy struct {
+	// MaxAge is how long browsers may reuse the response. Zero with a zero
+	// SMaxAge marks the response no-store.
+	MaxAge time.Duration
+	// SMaxAge is how long shared caches (CDNs) may reuse it. CDN copies are
+	// purged on change, so this can be much longer than MaxAge.
+	SMaxAge time.Duration
+	// StaleWhileRevalidate lets caches serve an expired copy while they
+	// refetch it.
+	StaleWhileRevalidate time.Duration
+	// Vary lists request headers the response depends on.
+	Vary []string
+	// SurrogateKeys are key templates; "{name}" is replaced with the
+	// route's path wildcard of that name.
+	SurrogateKeys []string
+}
+
+// CacheHeadersConfig configures the caching header middleware.
+type CacheHeadersConfig struct {
+	// Routes maps ServeMux patterns to their policies. Unmatched routes get
+	// no caching headers.
+	Routes map[string]CachePolicy
+	// KeyHeader names the surrogate key header; CacheTagHeader switches to
+	// Cloudflare's format.
+	KeyHeader string
+}
+
+// DefaultCacheHeadersConfig returns the policies for the public read API.
+// Price data is kept short in browsers, which cannot be purged, and long at
+// the CDN, which is purged by product key whenever prices change.
+func DefaultCacheHeadersConfig() CacheHeadersConfig {
+	productKey := httpx.ProductSurrogateKey("{id}")
+	return CacheHeadersConfig{
+		KeyHeader: SurrogateKeyHeader,
+		Routes: map[string]CachePolicy{
+			"GET /api/v1/products/{id}": {
+				MaxAge: 5 * time.Minute, SMaxAge: time.Hour, StaleWhileRevalidate: time.Minute,
+				Vary: []string{"Accept"}, SurrogateKeys: []string{productKey},
+			},
+			"GET /api/v1/products/{id}/prices": {
+				MaxAge: 30 * time.Second, SMaxAge: 10 * time.Minute, StaleWhileRevalidate: 30 * time.Second,
+				Vary: []string{"Accept", "Accept-Language"}, SurrogateKeys: []string{productKey, httpx.PricesSurrogateKey},
+			},
+			"GET /api/v1/products/{id}/price-history": {
+				MaxAge: 5 * time.Minute, SMaxAge: time.Hour,
+				Vary: []string{"Accept"}, SurrogateKeys: []string{productKey, httpx.PricesSurrogateKey},
+			},
+			"GET /api/v1/compare": {
+				MaxAge: 30 * time.Second, SMaxAge: time.Minute,
+				Vary: []string{"Accept", "Accept-Language"}, SurrogateKeys: []string{httpx.PricesSurrogateKey},
+			},
+			"GET /api/v1/deals": {
+				MaxAge: 30 * time.Second, SMaxAge: 5 * time.Minute,
+				Vary: []string{"Accept", "Accept-Language"}, SurrogateKeys: []string{"deals", httpx.PricesSurrogateKey},
+			},
+			"GET /api/v1/retailers": {
+				MaxAge: time.Hour, SMaxAge: 24 * time.Hour,
+				Vary: []string{"Accept"}, SurrogateKeys: []string{"retailers"},
+			},
+			"GET /api/v1/retailers/{id}": {
+				MaxAge: time.Hour, SMaxAge: 24 * time.Hour,
+				Vary: []string{"Accept"}, SurrogateKeys: []string{"retailers", "retailer-{id}"},
+			},
+			"GET /widget/{productID}": {
+				SurrogateKeys: []string{httpx.ProductSurrogateKey("{productID}")},
+			},
+		},
+	}
+}
+
+// CacheHeaders sets Cache-Control, Vary and surrogate key headers on
+// successful responses according to the matched route. A Cache-Control set
+// by the handler itself wins; keys and Vary are still added.
+type CacheHeaders struct {
+	cfg    CacheHeadersConfig
+	routes *http.ServeMux
+	sep    string
+}
+
+// NewCacheHeaders creates the middleware. Like NewRateLimiter, it panics on
+// malformed route patterns.
+func NewCacheHeaders(cfg CacheHeadersConfig) *CacheHeaders {
+	m := &CacheHeaders{cfg: cfg, routes: http.NewServeMux(), sep: " "}
+	if m.cfg.KeyHeader == "" {
+		m.cfg.KeyHeader = SurrogateKeyHeader
+	}
+	if http.CanonicalHeaderKey(m.cfg.KeyHeader) == CacheTagHeader {
+		m.sep = ","
+	}
+	for pattern := range cfg.Routes {
+		m.routes.Handle(pattern, http.NotFoundHandler())
+	}
+	return m
+}
+
+// Handler returns the middleware.
+func (m *CacheHeaders) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, pattern := m.routes.Handler(r)
+		policy, ok := m.cfg.Routes[pattern]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cw := &cacheHeaderWriter{ResponseWriter: w, apply: func(status int) {
+			m.apply(w.Header(), status, policy, pattern, r.URL.Path)
+		}}
+		next.ServeHTTP(cw, r)
+	})
+}
+
+func (m *CacheHeaders) apply(h http.Header, status int, p CachePolicy, pattern, path string) {
+	// Errors are left alone: caching a transient 500 at the edge would
+	// outlive the fault.
+	if status < 200 || status >= 300 && status != http.StatusNotModified {
+		return
+	}
+	if h.Get("Cache-Control") == "" {
+		h.Set("Cache-Control", cacheControl(p))
+	}
+	for _, v := range p.Vary {
+		addVary(h, v)
+	}
+	if len(p.SurrogateKeys) == 0 {
+		return
+	}
+	values := pathValues(pattern, path)
+	keys := make([]string, 0, len(p.SurrogateKeys))
+	for _, tmpl := range p.SurrogateKeys {
+		for name, v := range values {
+			tmpl = strings.ReplaceAll(tmpl, "{"+name+"}", v)
+		}
+		keys = append(keys, tmpl)
+	}
+	h.Set(m.cfg.KeyHeader, strings.Join(keys, m.sep))
+}
+
+func cacheControl(p CachePolicy) string {
+	if p.MaxAge <= 0 && p.SMaxAge <= 0 {
+		return "no-store"
+	}
+	v := fmt.Sprintf("public, max-age=%d", int(p.MaxAge.Seconds()))
+	if p.SMaxAge > 0 {
+		v += fmt.Sprintf(", s-maxage=%d", int(p.SMaxAge.Seconds()))
+	}
+	if p.StaleWhileRevalidate > 0 {
+		v += fmt.Sprintf(", stale-while-revalidate=%d", int(p.StaleWhileRevalidate.Seconds()))
+	}
+	return v
+}
+
+// addVary appends name to Vary unless it is already listed.
+func addVary(h http.Header, name string) {
+	for _, v := range h.Values("Vary") {
+		for _, token := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), name) {
+				return
+			}
+		}
+	}
+	h.Add("Vary", name)
+}
+
+// pathValues extracts the wildcard values of pattern from path. The pattern
+// is known to match, so segments line up one to one.
+func pathValues(pattern, path string) map[string]string {
+	if _, p, ok := strings.Cut(pattern, " "); ok {
+		pattern = p
+	}
+	pSegs := strings.Split(strings.Trim(pattern, "/"), "/")
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+	values := make(map[string]string)
+	for i, s := range pSegs {
+		if i >= len(segs) || !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
+			continue
+		}
+		name := strings.Trim(s, "{}")
+		if rest, ok := strings.CutSuffix(name, "..."); ok {
+			values[rest] = strings.Join(segs[i:], "/")
+			continue
+		}
+		if name != "$" {
+			values[name] = segs[i]
+		}
+	}
+	return values
+}
+
+// cacheHeaderWriter adds the headers just before they are sent.
+type cacheHeaderWriter struct {
+	http.ResponseWriter
+	apply       func(status int)
+	wroteHeader bool
+}
+
+func (cw *cacheHeaderWriter) WriteHeader(status int) {
+	if !cw.wroteHeader {
+		cw.wroteHeader = true
+		cw.apply(status)
+	}
+	cw.ResponseWriter.WriteHeader(status)
+}
+
+func (cw *cacheHeaderWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+// Flush sends the headers before flushing so streamed responses get them.
+func (cw *cacheHeaderWriter) Flush() {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	_ = http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (cw *cacheHeaderWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
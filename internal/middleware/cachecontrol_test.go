@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestCacheHeaders(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCacheHeaders", "internal/middleware")
+
+	h := NewCacheHeaders(DefaultCacheHeadersConfig()).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Vary", "Accept-Language")
+		switch r.URL.Path {
+		case "/api/v1/products/missing":
+			http.NotFound(w, r)
+		case "/widget/prod_on_gsw":
+			w.Header().Set("Cache-Control", "public, max-age=300")
+			_, _ = w.Write([]byte("<html>"))
+		default:
+			_, _ = w.Write([]byte("{}"))
+		}
+	}))
+
+	testCases := []struct {
+		name         string
+		target       string
+		wantCC       string
+		wantKeys     string
+		wantVary     []string
+		wantNoHeader bool
+	}{
+		{
+			name:     "Product detail",
+			target:   "/api/v1/products/prod_on_gsw",
+			wantCC:   "public, max-age=300, s-maxage=3600, stale-while-revalidate=60",
+			wantKeys: "product-prod_on_gsw",
+			wantVary: []string{"Accept-Language", "Accept"},
+		},
+		{
+			name:     "Prices keep existing Vary",
+			target:   "/api/v1/products/prod_on_gsw/prices",
+			wantCC:   "public, max-age=30, s-maxage=600, stale-while-revalidate=30",
+			wantKeys: "product-prod_on_gsw prices",
+			wantVary: []string{"Accept-Language", "Accept"},
+		},
+		{
+			name:     "Handler Cache-Control wins",
+			target:   "/widget/prod_on_gsw",
+			wantCC:   "public, max-age=300",
+			wantKeys: "product-prod_on_gsw",
+			wantVary: []string{"Accept-Language"},
+		},
+		{name: "Errors are untouched", target: "/api/v1/products/missing", wantNoHeader: true},
+		{name: "Unconfigured routes are untouched", target: "/api/v1/stats", wantNoHeader: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.target, nil))
+
+			cc, keys := rec.Header().Get("Cache-Control"), rec.Header().Get(SurrogateKeyHeader)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantCC, cc)
+			if tc.wantNoHeader {
+				if cc != "" || keys != "" {
+					t.Errorf("Cache-Control = %q, keys = %q, want none", cc, keys)
+				}
+				return
+			}
+			if cc != tc.wantCC {
+				t.Errorf("Cache-Control = %q, want %q", cc, tc.wantCC)
+			}
+			if keys != tc.wantKeys {
+				t.Errorf("%s = %q, want %q", SurrogateKeyHeader, keys, tc.wantKeys)
+			}
+			vary := rec.Header().Values("Vary")
+			if len(vary) != len(tc.wantVary) {
+				t.Fatalf("Vary = %v, want %v", vary, tc.wantVary)
+			}
+			for i := range vary {
+				if vary[i] != tc.wantVary[i] {
+					t.Errorf("Vary = %v, want %v", vary, tc.wantVary)
+				}
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCacheHeaders", true)
+}
+
+func TestCacheHeaders_CacheTagFormat(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCacheHeaders_CacheTagFormat", "internal/middleware")
+
+	cfg := DefaultCacheHeadersConfig()
+	cfg.KeyHeader = CacheTagHeader
+	h := NewCacheHeaders(cfg).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	testhelpers.LogTestStep(logger, "act", "Requesting a retailer")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/retailers/amazon", nil))
+
+	testhelpers.LogTestStep(logger, "assert", "Cloudflare tags are comma separated")
+	got := rec.Header().Get(CacheTagHeader)
+	testhelpers.LogTestAssertion(logger, "Cache-Tag", "retailers,retailer-amazon", got)
+	if got != "retailers,retailer-amazon" {
+		t.Errorf("Cache-Tag = %q", got)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCacheHeaders_CacheTagFormat", true)
+}
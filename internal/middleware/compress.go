@@ -0,0 +1,419 @@
+// Package middleware provides HTTP middleware shared by the API and web servers.
+//
+// Every middleware follows the standard func(http.Handler) http.Handler shape so
+// it can be chained with plain net/http handlers.
+package middleware
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+)
+
+// Encoder is a streaming compressor that can be reused across responses.
+// *gzip.Writer and *flate.Writer satisfy it, as do most third-party encoders
+// (for example brotli.Writer).
+type Encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// EncoderFactory creates a new Encoder writing to w.
+type EncoderFactory func(w io.Writer) (Encoder, error)
+
+// CompressConfig configures the response compression middleware.
+type CompressConfig struct {
+	// MinSize is the smallest body, in bytes, worth compressing. Smaller
+	// responses are sent as-is because the encoding overhead outweighs the
+	// savings on anything that already fits in a single packet.
+	MinSize int
+	// Level is the gzip/deflate compression level (1-9).
+	Level int
+	// BrotliLevel is the brotli ("br") quality (0-11). Brotli is preferred
+	// over gzip when the client accepts both.
+	BrotliLevel int
+}
+
+// DefaultCompressConfig returns the production compression settings. Brotli
+// runs at quality 4: on JSON it still beats gzip's default level in size,
+// at about the same speed, where the higher qualities cost several times
+// the CPU per response.
+func DefaultCompressConfig() CompressConfig {
+	return CompressConfig{
+		MinSize:     1024,
+		Level:       gzip.DefaultCompression,
+		BrotliLevel: 4,
+	}
+}
+
+type encoderPool struct {
+	name    string
+	factory EncoderFactory
+	pool    sync.Pool
+}
+
+func (p *encoderPool) get(w io.Writer) (Encoder, error) {
+	if enc, ok := p.pool.Get().(Encoder); ok {
+		enc.Reset(w)
+		return enc, nil
+	}
+	return p.factory(w)
+}
+
+func (p *encoderPool) put(enc Encoder) {
+	enc.Reset(io.Discard)
+	p.pool.Put(enc)
+}
+
+// Compressor negotiates Content-Encoding with the client and streams
+// compressed responses. brotli ("br"), gzip and deflate are always
+// available; other encodings can be added with Register. A compressed
+// response is a different representation of the resource, so its ETag is
+// made weak: it still matches If-None-Match, but is never taken for a
+// byte-for-byte validator of the uncompressed body.
+type Compressor struct {
+	minSize  int
+	encoders []*encoderPool // in server preference order
+	logger   *zap.Logger
+}
+
+// NewCompressor creates a Compressor with brotli, gzip and deflate
+// registered, in that order of preference.
+func NewCompressor(cfg CompressConfig, logger *zap.Logger) *Compressor {
+	level := cfg.Level
+	if level != gzip.DefaultCompression && (level < gzip.BestSpeed || level > gzip.BestCompression) {
+		logger.Warn("Invalid compression level, using default",
+			zap.Int("level", level),
+		)
+		level = gzip.DefaultCompression
+	}
+	brLevel := cfg.BrotliLevel
+	if brLevel < brotli.BestSpeed || brLevel > brotli.BestCompression {
+		logger.Warn("Invalid brotli level, using default",
+			zap.Int("level", brLevel),
+		)
+		brLevel = DefaultCompressConfig().BrotliLevel
+	}
+	if cfg.MinSize < 0 {
+		cfg.MinSize = 0
+	}
+
+	c := &Compressor{
+		minSize: cfg.MinSize,
+		logger:  logger,
+	}
+	c.Register("deflate", func(w io.Writer) (Encoder, error) {
+		return flate.NewWriter(w, level)
+	})
+	c.Register("gzip", func(w io.Writer) (Encoder, error) {
+		return gzip.NewWriterLevel(w, level)
+	})
+	c.Register("br", func(w io.Writer) (Encoder, error) {
+		return brotli.NewWriterLevel(w, brLevel), nil
+	})
+	return c
+}
+
+// Register adds an encoding. Encodings registered later take precedence when
+// the client accepts several with equal quality, so registering "br" after
+// construction makes brotli preferred over gzip.
+func (c *Compressor) Register(encoding string, factory EncoderFactory) {
+	encoding = strings.ToLower(encoding)
+	for i, p := range c.encoders {
+		if p.name == encoding {
+			c.encoders = append(c.encoders[:i], c.encoders[i+1:]...)
+			break
+		}
+	}
+	c.encoders = append([]*encoderPool{{name: encoding, factory: factory}}, c.encoders...)
+}
+
+// Handler wraps next with response compression.
+func (c *Compressor) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead || r.Header.Get("Range") != "" || r.Header.Get("Upgrade") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		pool := c.negotiate(r.Header.Get("Accept-Encoding"))
+		if pool == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, c: c, pool: pool}
+		defer func() {
+			if err := cw.Close(); err != nil {
+				c.logger.Debug("Failed to finish compressed response",
+					zap.String("path", r.URL.Path),
+					zap.String("encoding", pool.name),
+					zap.Error(err),
+				)
+			}
+		}()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiate picks the encoding with the highest client quality value,
+// breaking ties by server preference. It returns nil for identity.
+func (c *Compressor) negotiate(acceptEncoding string) *encoderPool {
+	if acceptEncoding == "" {
+		return nil
+	}
+
+	qualities := make(map[string]float64)
+	wildcard := -1.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, q := httpx.ParseQuality(part)
+		if name == "" {
+			continue
+		}
+		if name == "*" {
+			wildcard = q
+			continue
+		}
+		qualities[name] = q
+	}
+
+	var best *encoderPool
+	bestQ := 0.0
+	for _, p := range c.encoders {
+		q, ok := qualities[p.name]
+		if !ok {
+			if wildcard < 0 {
+				continue
+			}
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = p, q
+		}
+	}
+	return best
+}
+
+// compressible reports whether a Content-Type benefits from compression.
+// Images, archives and fonts are already compressed and are skipped.
+func compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case strings.HasSuffix(mediaType, "+json"), strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/javascript", "application/xml", "image/svg+xml":
+		return true
+	}
+	return false
+}
+
+// compressWriter buffers the first MinSize bytes of a response to decide
+// whether compressing it is worthwhile, then either streams through the
+// encoder or flushes the buffer uncompressed.
+type compressWriter struct {
+	http.ResponseWriter
+	c    *Compressor
+	pool *encoderPool
+
+	status  int
+	buf     []byte
+	decided bool
+	enc     Encoder
+
+	// contentType is the last Content-Type seen on the header map;
+	// http.ServeContent deletes it before writing a 304.
+	contentType string
+}
+
+// Header records the Content-Type so a 304 can still be judged by it.
+func (cw *compressWriter) Header() http.Header {
+	h := cw.ResponseWriter.Header()
+	if ct := h.Get("Content-Type"); ct != "" {
+		cw.contentType = ct
+	}
+	return h
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if status >= 100 && status < 200 && status != http.StatusSwitchingProtocols {
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if cw.status == 0 {
+		cw.status = status
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	if cw.decided {
+		if cw.enc != nil {
+			return cw.enc.Write(p)
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) < cw.c.minSize {
+		return len(p), nil
+	}
+	if err := cw.decide(true); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// decide commits the response headers. When compress is false (the body
+// ended below MinSize) the buffered bytes are written uncompressed.
+func (cw *compressWriter) decide(compress bool) error {
+	cw.decided = true
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+
+	h := cw.Header()
+	if h.Get("Content-Type") == "" && len(cw.buf) > 0 {
+		// Sniff before compressing; net/http would otherwise sniff the
+		// compressed bytes and report application/x-gzip.
+		h.Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+
+	eligible := cw.eligible()
+	notModified := cw.status == http.StatusNotModified && cw.encodable(cw.contentType)
+	if eligible || notModified {
+		httpx.AddVary(h, "Accept-Encoding")
+	}
+
+	buf := cw.buf
+	cw.buf = nil
+
+	if !compress || !eligible {
+		if notModified {
+			// A 304 carries the ETag the 200 would have, which is weak
+			// only when that 200 would have been encoded.
+			weakenETag(h)
+		}
+		cw.ResponseWriter.WriteHeader(cw.status)
+		if len(buf) == 0 {
+			return nil
+		}
+		_, err := cw.ResponseWriter.Write(buf)
+		return err
+	}
+
+	enc, err := cw.pool.get(cw.ResponseWriter)
+	if err != nil {
+		return err
+	}
+	cw.enc = enc
+	h.Del("Content-Length")
+	h.Set("Content-Encoding", cw.pool.name)
+	weakenETag(h)
+	cw.ResponseWriter.WriteHeader(cw.status)
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err = enc.Write(buf)
+	return err
+}
+
+// weakenETag marks a strong ETag in h weak.
+func weakenETag(h http.Header) {
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		h.Set("ETag", "W/"+etag)
+	}
+}
+
+func (cw *compressWriter) eligible() bool {
+	switch {
+	case cw.status < 200, cw.status == http.StatusNoContent, cw.status == http.StatusNotModified:
+		return false
+	}
+	return cw.encodable(cw.Header().Get("Content-Type"))
+}
+
+// encodable reports whether a body of contentType with the current headers
+// would be compressed, regardless of status.
+func (cw *compressWriter) encodable(contentType string) bool {
+	h := cw.ResponseWriter.Header()
+	switch {
+	case h.Get("Content-Encoding") != "":
+		return false
+	case strings.Contains(h.Get("Cache-Control"), "no-transform"):
+		return false
+	}
+	return compressible(contentType)
+}
+
+// Flush sends any buffered data to the client. Streaming responses commit to
+// compression immediately, regardless of MinSize.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		if err := cw.decide(true); err != nil {
+			return
+		}
+	}
+	if cw.enc != nil {
+		if err := cw.enc.Flush(); err != nil {
+			return
+		}
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close finishes the response and returns the encoder to its pool.
+func (cw *compressWriter) Close() error {
+	if !cw.decided {
+		if cw.status == 0 && len(cw.buf) == 0 {
+			// Handler wrote nothing; let net/http send its implicit 200.
+			return nil
+		}
+		if err := cw.decide(false); err != nil {
+			return err
+		}
+	}
+	if cw.enc == nil {
+		return nil
+	}
+	err := cw.enc.Close()
+	cw.pool.put(cw.enc)
+	cw.enc = nil
+	return err
+}
+
+// Hijack supports protocol upgrades through the wrapped writer.
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hj, ok := cw.ResponseWriter.(http.Hijacker); ok {
+		return hj.Hijack()
+	}
+	return nil, nil, errors.New("middleware: underlying ResponseWriter does not support hijacking")
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
@@ -0,0 +1,299 @@
+package middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestCompressor_Negotiate(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCompressor_Negotiate", "internal/middleware")
+
+	c := NewCompressor(DefaultCompressConfig(), logger)
+
+	testCases := []struct {
+		name           string
+		acceptEncoding string
+		expected       string
+	}{
+		{"No header", "", ""},
+		{"Gzip only", "gzip", "gzip"},
+		{"Deflate only", "deflate", "deflate"},
+		{"Server prefers gzip on tie", "deflate, gzip", "gzip"},
+		{"Server prefers brotli on tie", "gzip, deflate, br", "br"},
+		{"Brotli only", "br", "br"},
+		{"Client quality wins", "gzip;q=0.5, deflate;q=0.9", "deflate"},
+		{"Gzip refused", "gzip;q=0, deflate", "deflate"},
+		{"Wildcard", "*", "br"},
+		{"Unsupported only", "zstd", ""},
+		{"Identity only", "identity", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ""
+			if p := c.negotiate(tc.acceptEncoding); p != nil {
+				got = p.name
+			}
+			testhelpers.LogTestAssertion(logger, tc.name, tc.expected, got)
+			if got != tc.expected {
+				t.Errorf("negotiate(%q) = %q, want %q", tc.acceptEncoding, got, tc.expected)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCompressor_Negotiate", true)
+}
+
+func TestCompressor_Handler(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCompressor_Handler", "internal/middleware")
+
+	large := strings.Repeat(`{"product":"Gold Standard 100% Whey","price":3299}`, 100)
+	small := `{"status":"ok"}`
+
+	testCases := []struct {
+		name           string
+		acceptEncoding string
+		contentType    string
+		body           string
+		wantEncoding   string
+	}{
+		{"Large JSON gzip", "gzip", "application/json", large, "gzip"},
+		{"Large JSON deflate", "deflate", "application/json", large, "deflate"},
+		{"Large JSON brotli", "br, gzip", "application/json", large, "br"},
+		{"Small JSON below threshold", "gzip", "application/json", small, ""},
+		{"Large image skipped", "gzip", "image/webp", large, ""},
+		{"Client without encoding", "", "application/json", large, ""},
+		{"Sniffed HTML", "gzip", "", "<!DOCTYPE html><html>" + large, "gzip"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testhelpers.LogTestStep(logger, "act", tc.name)
+
+			handler := NewCompressor(DefaultCompressConfig(), logger).Handler(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if tc.contentType != "" {
+						w.Header().Set("Content-Type", tc.contentType)
+					}
+					// Write in chunks to exercise buffering across the threshold.
+					for i := 0; i < len(tc.body); i += 100 {
+						end := min(i+100, len(tc.body))
+						_, _ = io.WriteString(w, tc.body[i:end])
+					}
+				}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
+			if tc.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			gotEncoding := rec.Header().Get("Content-Encoding")
+			testhelpers.LogTestAssertion(logger, "content encoding", tc.wantEncoding, gotEncoding)
+			if gotEncoding != tc.wantEncoding {
+				t.Fatalf("Content-Encoding = %q, want %q", gotEncoding, tc.wantEncoding)
+			}
+
+			var reader io.Reader = rec.Body
+			switch gotEncoding {
+			case "gzip":
+				gz, err := gzip.NewReader(rec.Body)
+				if err != nil {
+					t.Fatalf("Failed to open gzip body: %v", err)
+				}
+				reader = gz
+			case "deflate":
+				reader = flate.NewReader(rec.Body)
+			case "br":
+				reader = brotli.NewReader(rec.Body)
+			}
+			decoded, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("Failed to decode body: %v", err)
+			}
+			if string(decoded) != tc.body {
+				t.Errorf("Decoded body mismatch: got %d bytes, want %d", len(decoded), len(tc.body))
+			}
+			if gotEncoding != "" {
+				if rec.Header().Get("Content-Length") != "" {
+					t.Error("Content-Length must be removed from compressed responses")
+				}
+				if rec.Header().Get("Content-Type") == "" {
+					t.Error("Content-Type must be set before compressing")
+				}
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCompressor_Handler", true)
+}
+
+func TestCompressor_VaryAndStatus(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCompressor_VaryAndStatus", "internal/middleware")
+
+	testhelpers.LogTestStep(logger, "arrange", "Handler returning 404 with JSON body below threshold")
+	handler := NewCompressor(DefaultCompressConfig(), logger).Handler(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Vary", "Accept-Language")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"error":{"code":"NOT_FOUND"}}`)
+		}))
+
+	testhelpers.LogTestStep(logger, "act", "Serving request")
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/products/missing", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	testhelpers.LogTestStep(logger, "assert", "Status preserved and Vary appended")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	vary := strings.Join(rec.Header().Values("Vary"), ",")
+	if !strings.Contains(vary, "Accept-Encoding") || !strings.Contains(vary, "Accept-Language") {
+		t.Errorf("Vary = %q, want both Accept-Language and Accept-Encoding", vary)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCompressor_VaryAndStatus", true)
+}
+
+func TestCompressor_FlushStreams(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCompressor_FlushStreams", "internal/middleware")
+
+	handler := NewCompressor(DefaultCompressConfig(), logger).Handler(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = io.WriteString(w, "first chunk\n")
+			w.(http.Flusher).Flush()
+			_, _ = io.WriteString(w, "second chunk\n")
+		}))
+
+	req := httptest.NewRequest(http.MethodGet, "/stream", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Flushed responses must be compressed, got %q", rec.Header().Get("Content-Encoding"))
+	}
+	if !rec.Flushed {
+		t.Error("Expected underlying writer to be flushed")
+	}
+	gz, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("Failed to open gzip body: %v", err)
+	}
+	decoded, _ := io.ReadAll(gz)
+	testhelpers.LogTestAssertion(logger, "streamed body", "first chunk\\nsecond chunk\\n", string(decoded))
+	if string(decoded) != "first chunk\nsecond chunk\n" {
+		t.Errorf("Decoded body = %q", decoded)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCompressor_FlushStreams", true)
+}
+
+func TestCompressor_WeakensETag(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCompressor_WeakensETag", "internal/middleware")
+
+	body := strings.Repeat(`{"product":"Gold Standard 100% Whey","price":3299}`, 100)
+	modified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	handler := NewCompressor(DefaultCompressConfig(), logger).Handler(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("ETag", `"v1"`)
+			http.ServeContent(w, r, "", modified, strings.NewReader(body))
+		}))
+	send := func(acceptEncoding, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/feed", nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	testCases := []struct {
+		name           string
+		acceptEncoding string
+		ifNoneMatch    string
+		wantStatus     int
+		wantETag       string
+	}{
+		{"Identity keeps the strong ETag", "identity", "", http.StatusOK, `"v1"`},
+		{"Compressed is weak", "gzip", "", http.StatusOK, `W/"v1"`},
+		{"Weak ETag revalidates", "br", `W/"v1"`, http.StatusNotModified, `W/"v1"`},
+		{"Changed ETag does not", "br", `W/"v0"`, http.StatusOK, `W/"v1"`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := send(tc.acceptEncoding, tc.ifNoneMatch)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantETag, rec.Header().Get("ETag"))
+			if rec.Code != tc.wantStatus || rec.Header().Get("ETag") != tc.wantETag {
+				t.Errorf("Status %d, ETag %q; want %d, %q", rec.Code, rec.Header().Get("ETag"), tc.wantStatus, tc.wantETag)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCompressor_WeakensETag", true)
+}
+
+func TestCompressor_NotModifiedETag(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCompressor_NotModifiedETag", "internal/middleware")
+
+	modified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	compressor := NewCompressor(DefaultCompressConfig(), logger)
+
+	testCases := []struct {
+		name        string
+		contentType string
+		ifNoneMatch string
+		wantETag    string
+		wantVary    bool
+	}{
+		{"Compressible 304 is weak", "application/json", `W/"x"`, `W/"x"`, true},
+		{"Image 304 stays strong", "image/png", `"x"`, `"x"`, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := compressor.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tc.contentType)
+				w.Header().Set("ETag", `"x"`)
+				http.ServeContent(w, r, "", modified, strings.NewReader(strings.Repeat("x", 4096)))
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/asset", nil)
+			req.Header.Set("Accept-Encoding", "br, gzip")
+			req.Header.Set("If-None-Match", tc.ifNoneMatch)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			vary := strings.Contains(rec.Header().Get("Vary"), "Accept-Encoding")
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantETag, rec.Header().Get("ETag"))
+			if rec.Code != http.StatusNotModified || rec.Header().Get("ETag") != tc.wantETag || vary != tc.wantVary {
+				t.Errorf("Status %d, ETag %q, Vary %v; want 304, %q, %v",
+					rec.Code, rec.Header().Get("ETag"), vary, tc.wantETag, tc.wantVary)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCompressor_NotModifiedETag", true)
+}
@@ -0,0 +1,338 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/logctx"
+)
+
+// IdempotencyKeyHeader is the request header clients use to make writes safe
+// to retry.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayHeader is set on responses served from a stored result.
+const IdempotentReplayHeader = "Idempotent-Replayed"
+
+const maxIdempotencyKeyLength = 255
+
+// ErrIdempotencyInProgress is returned by an IdempotencyStore when another
+// request holding the same key has not finished yet.
+var ErrIdempotencyInProgress = errors.New("idempotent request still in progress")
+
+// IdempotencyRecord is a stored response for a completed request.
+type IdempotencyRecord struct {
+	Fingerprint string
+	Status      int
+	Header      http.Header
+	Body        []byte
+	CreatedAt   time.Time
+}
+
+// IdempotencyStore persists request fingerprints and their responses.
+type IdempotencyStore interface {
+	// Reserve claims key for a new request. If the key already completed it
+	// returns the stored record; if it is still being processed it returns
+	// ErrIdempotencyInProgress.
+	Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (*IdempotencyRecord, error)
+	// Complete stores the response for a reserved key.
+	Complete(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error
+	// Release drops a reservation without storing a response so the client
+	// can retry, e.g. after a server error.
+	Release(ctx context.Context, key string) error
+}
+
+// IdempotencyConfig configures the idempotency middleware.
+type IdempotencyConfig struct {
+	// TTL is how long completed responses are replayed.
+	TTL time.Duration
+	// MaxBodyBytes caps the request body that is read for fingerprinting.
+	MaxBodyBytes int64
+	// Scope returns a per-caller namespace for keys so different clients
+	// cannot collide. The default scopes by method and path only.
+	Scope func(r *http.Request) string
+}
+
+// DefaultIdempotencyConfig returns the standard settings: responses are kept
+// for 24 hours, matching common client retry windows.
+func DefaultIdempotencyConfig() IdempotencyConfig {
+	return IdempotencyConfig{
+		TTL:          24 * time.Hour,
+		MaxBodyBytes: 1 << 20,
+	}
+}
+
+// Idempotency replays stored responses for retried write requests that carry
+// an Idempotency-Key header.
+type Idempotency struct {
+	cfg    IdempotencyConfig
+	store  IdempotencyStore
+	logger *zap.Logger
+}
+
+// NewIdempotency creates the idempotency middleware.
+func NewIdempotency(cfg IdempotencyConfig, store IdempotencyStore, logger *zap.Logger) *Idempotency {
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultIdempotencyConfig().TTL
+	}
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = DefaultIdempotencyConfig().MaxBodyBytes
+	}
+	return &Idempotency{cfg: cfg, store: store, logger: logger}
+}
+
+// Handler wraps next. Requests without the header, and safe methods, pass
+// straight through.
+func (m *Idempotency) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" || !isWriteMethod(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest,
+				"Idempotency-Key must be at most 255 characters", nil)
+			return
+		}
+
+		logger := logctx.Logger(r.Context(), m.logger).With(
+			zap.String("operation", "Idempotency"),
+			zap.String("path", r.URL.Path),
+		)
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, m.cfg.MaxBodyBytes+1))
+		if err != nil {
+			httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, "Failed to read request body", nil)
+			return
+		}
+		if int64(len(body)) > m.cfg.MaxBodyBytes {
+			httpx.WriteError(w, r, http.StatusRequestEntityTooLarge, httpx.CodeBadRequest, "Request body too large", nil)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		storeKey := m.storeKey(r, key)
+		fingerprint := fingerprintRequest(r, body)
+
+		existing, err := m.store.Reserve(r.Context(), storeKey, fingerprint, m.cfg.TTL)
+		switch {
+		case errors.Is(err, ErrIdempotencyInProgress):
+			logger.Info("Idempotent request already in progress")
+			httpx.WriteError(w, r, http.StatusConflict, httpx.CodeConflict,
+				"A request with this Idempotency-Key is still being processed", nil)
+			return
+		case err != nil:
+			// Fail open: losing idempotency is better than rejecting writes.
+			logger.Error("Idempotency store unavailable", zap.Error(err))
+			next.ServeHTTP(w, r)
+			return
+		case existing != nil:
+			if existing.Fingerprint != fingerprint {
+				logger.Warn("Idempotency key reused with a different payload")
+				httpx.WriteError(w, r, http.StatusUnprocessableEntity, httpx.CodeUnprocessableEntity,
+					"Idempotency-Key was already used for a different request", nil)
+				return
+			}
+			logger.Debug("Replaying stored response", zap.Int("status", existing.Status))
+			replay(w, existing)
+			return
+		}
+
+		rec := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			// Panics and server errors must not pin the key.
+			if err := m.store.Release(context.WithoutCancel(r.Context()), storeKey); err != nil {
+				logger.Error("Failed to release idempotency key", zap.Error(err))
+			}
+		}()
+
+		next.ServeHTTP(rec, r)
+
+		if rec.status >= http.StatusInternalServerError {
+			return
+		}
+		record := IdempotencyRecord{
+			Fingerprint: fingerprint,
+			Status:      rec.status,
+			Header:      replayableHeaders(rec.Header()),
+			Body:        rec.body.Bytes(),
+			CreatedAt:   time.Now(),
+		}
+		if err := m.store.Complete(context.WithoutCancel(r.Context()), storeKey, record, m.cfg.TTL); err != nil {
+			logger.Error("Failed to store idempotent response", zap.Error(err))
+			return
+		}
+		completed = true
+	})
+}
+
+func (m *Idempotency) storeKey(r *http.Request, key string) string {
+	scope := r.Method + " " + r.URL.Path
+	if m.cfg.Scope != nil {
+		scope = m.cfg.Scope(r) + "|" + scope
+	}
+	return scope + "|" + key
+}
+
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func fingerprintRequest(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method)
+	h.Write([]byte{0})
+	io.WriteString(h, r.URL.RequestURI())
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// replayableHeaders keeps the headers that describe the response itself;
+// per-request headers such as Date or Set-Cookie are not replayed.
+func replayableHeaders(h http.Header) http.Header {
+	out := make(http.Header)
+	for _, name := range []string{"Content-Type", "Location", "Content-Language"} {
+		if v := h.Values(name); len(v) > 0 {
+			out[name] = append([]string(nil), v...)
+		}
+	}
+	return out
+}
+
+func replay(w http.ResponseWriter, record *IdempotencyRecord) {
+	for name, values := range record.Header {
+		w.Header()[name] = append([]string(nil), values...)
+	}
+	w.Header().Set(IdempotentReplayHeader, "true")
+	w.WriteHeader(record.Status)
+	_, _ = w.Write(record.Body)
+}
+
+// recordingWriter tees the response so it can be stored after the handler
+// returns.
+type recordingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(status int) {
+	if !rw.wroteHeader {
+		rw.status = status
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *recordingWriter) Write(p []byte) (int, error) {
+	rw.wroteHeader = true
+	rw.body.Write(p)
+	return rw.ResponseWriter.Write(p)
+}
+
+func (rw *recordingWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// MemoryIdempotencyStore is an in-process IdempotencyStore for development
+// and single-instance deployments.
+type MemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryIdempotencyEntry
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+type memoryIdempotencyEntry struct {
+	fingerprint string
+	record      *IdempotencyRecord
+	expiresAt   time.Time
+}
+
+// NewMemoryIdempotencyStore creates an empty in-memory store.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		entries: make(map[string]*memoryIdempotencyEntry),
+		now:     time.Now,
+	}
+}
+
+// Reserve implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Reserve(_ context.Context, key, fingerprint string, ttl time.Duration) (*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.evictExpired(now)
+
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expiresAt) {
+		if entry.record == nil {
+			if entry.fingerprint != fingerprint {
+				return &IdempotencyRecord{Fingerprint: entry.fingerprint}, nil
+			}
+			return nil, ErrIdempotencyInProgress
+		}
+		record := *entry.record
+		return &record, nil
+	}
+
+	s.entries[key] = &memoryIdempotencyEntry{fingerprint: fingerprint, expiresAt: now.Add(ttl)}
+	return nil, nil
+}
+
+// Complete implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Complete(_ context.Context, key string, record IdempotencyRecord, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = &memoryIdempotencyEntry{
+		fingerprint: record.Fingerprint,
+		record:      &record,
+		expiresAt:   s.now().Add(ttl),
+	}
+	return nil
+}
+
+// Release implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok && entry.record == nil {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) evictExpired(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+	s.nextSweep = now.Add(time.Minute)
+	for key, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func newIdempotentTestHandler(t *testing.T, calls *atomic.Int32, status int) http.Handler {
+	t.Helper()
+	logger := testhelpers.SetupTestLogger(t)
+	m := NewIdempotency(DefaultIdempotencyConfig(), NewMemoryIdempotencyStore(), logger)
+	return m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/api/v1/alerts/alert_1")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"call":%d,"echo":%s}`, n, body)
+	}))
+}
+
+func postAlert(h http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/alerts", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotency_ReplaysCompletedRequest(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestIdempotency_ReplaysCompletedRequest", "internal/middleware")
+
+	var calls atomic.Int32
+	h := newIdempotentTestHandler(t, &calls, http.StatusCreated)
+
+	testhelpers.LogTestStep(logger, "act", "Sending the same alert creation twice")
+	first := postAlert(h, "key-123", `{"target_price":2999}`)
+	second := postAlert(h, "key-123", `{"target_price":2999}`)
+
+	testhelpers.LogTestStep(logger, "assert", "Handler executed once and response replayed")
+	if calls.Load() != 1 {
+		t.Fatalf("Handler called %d times, want 1", calls.Load())
+	}
+	if second.Code != http.StatusCreated {
+		t.Errorf("Replayed status = %d, want %d", second.Code, http.StatusCreated)
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Errorf("Replayed body = %q, want %q", second.Body.String(), first.Body.String())
+	}
+	if second.Header().Get(IdempotentReplayHeader) != "true" {
+		t.Error("Replayed response must carry Idempotent-Replayed header")
+	}
+	if second.Header().Get("Location") != "/api/v1/alerts/alert_1" {
+		t.Errorf("Location not replayed: %q", second.Header().Get("Location"))
+	}
+
+	testhelpers.LogTestComplete(logger, "TestIdempotency_ReplaysCompletedRequest", true)
+}
+
+func TestIdempotency_RejectsKeyReuseWithDifferentBody(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestIdempotency_RejectsKeyReuseWithDifferentBody", "internal/middleware")
+
+	var calls atomic.Int32
+	h := newIdempotentTestHandler(t, &calls, http.StatusCreated)
+
+	postAlert(h, "key-123", `{"target_price":2999}`)
+	rec := postAlert(h, "key-123", `{"target_price":1999}`)
+
+	testhelpers.LogTestAssertion(logger, "status", http.StatusUnprocessableEntity, rec.Code)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Handler called %d times, want 1", calls.Load())
+	}
+
+	testhelpers.LogTestComplete(logger, "TestIdempotency_RejectsKeyReuseWithDifferentBody", true)
+}
+
+func TestIdempotency_ServerErrorsAreRetryable(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestIdempotency_ServerErrorsAreRetryable", "internal/middleware")
+
+	var calls atomic.Int32
+	h := newIdempotentTestHandler(t, &calls, http.StatusInternalServerError)
+
+	postAlert(h, "key-500", `{}`)
+	postAlert(h, "key-500", `{}`)
+
+	testhelpers.LogTestAssertion(logger, "handler calls", 2, calls.Load())
+	if calls.Load() != 2 {
+		t.Errorf("Handler called %d times, want 2 (5xx must not be stored)", calls.Load())
+	}
+
+	testhelpers.LogTestComplete(logger, "TestIdempotency_ServerErrorsAreRetryable", true)
+}
+
+func TestIdempotency_PassThrough(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestIdempotency_PassThrough", "internal/middleware")
+
+	var calls atomic.Int32
+	h := newIdempotentTestHandler(t, &calls, http.StatusCreated)
+
+	testhelpers.LogTestStep(logger, "act", "Requests without a key are never deduplicated")
+	postAlert(h, "", `{}`)
+	postAlert(h, "", `{}`)
+	if calls.Load() != 2 {
+		t.Errorf("Handler called %d times, want 2", calls.Load())
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Oversized keys are rejected")
+	rec := postAlert(h, strings.Repeat("k", 256), `{}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestIdempotency_PassThrough", true)
+}
+
+func TestMemoryIdempotencyStore_InProgress(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestMemoryIdempotencyStore_InProgress", "internal/middleware")
+
+	store := NewMemoryIdempotencyStore()
+	ctx := t.Context()
+
+	if _, err := store.Reserve(ctx, "k", "fp", DefaultIdempotencyConfig().TTL); err != nil {
+		t.Fatalf("First reserve failed: %v", err)
+	}
+	if _, err := store.Reserve(ctx, "k", "fp", DefaultIdempotencyConfig().TTL); err != ErrIdempotencyInProgress {
+		t.Errorf("Second reserve error = %v, want ErrIdempotencyInProgress", err)
+	}
+	if err := store.Release(ctx, "k"); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if rec, err := store.Reserve(ctx, "k", "fp", DefaultIdempotencyConfig().TTL); err != nil || rec != nil {
+		t.Errorf("Reserve after release = (%v, %v), want (nil, nil)", rec, err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestMemoryIdempotencyStore_InProgress", true)
+}
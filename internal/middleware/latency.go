@@ -0,0 +1,229 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/logctx"
+)
+
+// defaultBudgetRoute is the Stats key for requests matching no route.
+const defaultBudgetRoute = "default"
+
+// Latency targets from the performance requirements.
+const (
+	CachedReadBudget = 50 * time.Millisecond
+	DBReadBudget     = 200 * time.Millisecond
+)
+
+// LatencyBudgetConfig configures the latency budget middleware.
+type LatencyBudgetConfig struct {
+	// Default applies to requests matching none of Routes. Zero leaves them
+	// unbudgeted.
+	Default time.Duration
+	// Routes sets the budget per ServeMux pattern. A zero budget exempts
+	// the route.
+	Routes map[string]time.Duration
+	// WarnInterval limits violation warnings to one per route per interval;
+	// the rest are counted and reported with the next warning.
+	WarnInterval time.Duration
+}
+
+// DefaultLatencyBudgetConfig holds routes served from the read cache to the
+// cached target and everything else to the database target. Health probes
+// are exempt: readiness runs dependency checks with their own timeouts.
+func DefaultLatencyBudgetConfig() LatencyBudgetConfig {
+	return LatencyBudgetConfig{
+		Default:      DBReadBudget,
+		WarnInterval: 10 * time.Second,
+		Routes: map[string]time.Duration{
+			"GET /api/v1/products/{id}":               CachedReadBudget,
+			"GET /api/v1/products/{id}/prices":        CachedReadBudget,
+			"GET /api/v1/compare":                     CachedReadBudget,
+			"GET /api/v1/deals":                       CachedReadBudget,
+			"GET /api/v1/retailers":                   CachedReadBudget,
+			"GET /api/v1/retailers/{id}":              CachedReadBudget,
+			"GET /widget/{productID}":                 CachedReadBudget,
+			"GET /api/v1/products/{id}/price-history": DBReadBudget,
+			"GET /api/v1/stats":                       DBReadBudget,
+			"GET /health":                             0,
+			"GET /healthz":                            0,
+			"GET /readyz":                             0,
+		},
+	}
+}
+
+// ParseLatencyBudgets reads overrides in the LATENCY_BUDGETS format, a
+// comma-separated list of pattern=duration pairs where the pattern
+// "default" sets Default:
+//
+//	default=300ms,GET /api/v1/deals=80ms,GET /api/v1/stats=0
+func ParseLatencyBudgets(raw string, cfg LatencyBudgetConfig) (LatencyBudgetConfig, error) {
+	routes := make(map[string]time.Duration, len(cfg.Routes))
+	for k, v := range cfg.Routes {
+		routes[k] = v
+	}
+	cfg.Routes = routes
+	for _, entry := range strings.Split(raw, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		pattern, value, ok := strings.Cut(entry, "=")
+		pattern = strings.TrimSpace(pattern)
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if !ok || pattern == "" || err != nil || d < 0 {
+			return cfg, fmt.Errorf("latency budget %q: want pattern=duration, such as GET /api/v1/deals=80ms", entry)
+		}
+		if pattern == defaultBudgetRoute {
+			cfg.Default = d
+			continue
+		}
+		cfg.Routes[pattern] = d
+	}
+	return cfg, nil
+}
+
+// LatencyBudgetStats counts one route's requests against its budget.
+type LatencyBudgetStats struct {
+	Budget     time.Duration `json:"budget_ns"`
+	Requests   int64         `json:"requests"`
+	Violations int64         `json:"violations"`
+	Slowest    time.Duration `json:"slowest_ns"`
+}
+
+// LatencyBudget times every request against its route's budget, counting
+// and logging the ones that overrun. It never changes a response.
+type LatencyBudget struct {
+	cfg    LatencyBudgetConfig
+	routes *http.ServeMux
+	logger *zap.Logger
+	now    func() time.Time
+
+	mu    sync.Mutex
+	stats map[string]*budgetState
+}
+
+type budgetState struct {
+	LatencyBudgetStats
+	lastWarn   time.Time
+	suppressed int64
+}
+
+// NewLatencyBudget creates the middleware. Like NewRateLimiter, it panics
+// on malformed route patterns.
+func NewLatencyBudget(cfg LatencyBudgetConfig, logger *zap.Logger) *LatencyBudget {
+	m := &LatencyBudget{
+		cfg:    cfg,
+		routes: http.NewServeMux(),
+		logger: logger,
+		now:    time.Now,
+		stats:  make(map[string]*budgetState),
+	}
+	for pattern := range cfg.Routes {
+		m.routes.Handle(pattern, http.NotFoundHandler())
+	}
+	return m
+}
+
+// Handler returns the middleware. Place it outermost so the budget covers
+// compression and the other middleware too.
+func (m *LatencyBudget) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, budget := m.budget(r)
+		if budget <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		start := m.now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+		m.record(r, route, budget, sw.status, m.now().Sub(start))
+	})
+}
+
+func (m *LatencyBudget) budget(r *http.Request) (string, time.Duration) {
+	if _, pattern := m.routes.Handler(r); pattern != "" {
+		return pattern, m.cfg.Routes[pattern]
+	}
+	return defaultBudgetRoute, m.cfg.Default
+}
+
+func (m *LatencyBudget) record(r *http.Request, route string, budget time.Duration, status int, took time.Duration) {
+	m.mu.Lock()
+	st, ok := m.stats[route]
+	if !ok {
+		st = &budgetState{LatencyBudgetStats: LatencyBudgetStats{Budget: budget}}
+		m.stats[route] = st
+	}
+	st.Requests++
+	st.Slowest = max(st.Slowest, took)
+	if took <= budget {
+		m.mu.Unlock()
+		return
+	}
+	st.Violations++
+	now := m.now()
+	if !st.lastWarn.IsZero() && now.Sub(st.lastWarn) < m.cfg.WarnInterval {
+		st.suppressed++
+		m.mu.Unlock()
+		return
+	}
+	suppressed := st.suppressed
+	st.lastWarn, st.suppressed = now, 0
+	m.mu.Unlock()
+
+	logctx.Logger(r.Context(), m.logger).Warn("Latency budget exceeded",
+		zap.String("operation", "LatencyBudget"),
+		zap.String("route", route),
+		zap.String("path", r.URL.Path),
+		zap.Int("status", status),
+		zap.Duration("duration", took),
+		zap.Duration("budget", budget),
+		zap.Int64("suppressed", suppressed),
+	)
+}
+
+// Stats returns the counters per route pattern, plus "default" for
+// unmatched requests.
+func (m *LatencyBudget) Stats() map[string]LatencyBudgetStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string]LatencyBudgetStats, len(m.stats))
+	for route, st := range m.stats {
+		out[route] = st.LatencyBudgetStats
+	}
+	return out
+}
+
+// statusWriter remembers the response status and size for logging.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	bytes       int64
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	if !sw.wroteHeader {
+		sw.status, sw.wroteHeader = status, true
+	}
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+func (sw *statusWriter) Write(b []byte) (int, error) {
+	sw.wroteHeader = true
+	n, err := sw.ResponseWriter.Write(b)
+	sw.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController, so
+// flushing and deadlines keep working.
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestLatencyBudget_CountsViolations(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestLatencyBudget_CountsViolations", "internal/middleware")
+
+	testhelpers.LogTestStep(logger, "arrange", "Handlers that take as long as ?took says on a fake clock")
+	now := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	core, warnings := observer.New(zap.WarnLevel)
+	cfg := LatencyBudgetConfig{
+		Default:      DBReadBudget,
+		WarnInterval: 10 * time.Second,
+		Routes: map[string]time.Duration{
+			"GET /api/v1/deals": CachedReadBudget,
+			"GET /readyz":       0,
+		},
+	}
+	m := NewLatencyBudget(cfg, zap.New(core))
+	m.now = func() time.Time { return now }
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		took, _ := time.ParseDuration(r.URL.Query().Get("took"))
+		now = now.Add(took)
+		w.WriteHeader(http.StatusOK)
+	}))
+	send := func(target string) {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Serving fast and slow requests")
+	send("/api/v1/deals?took=20ms")
+	send("/api/v1/deals?took=80ms")
+	send("/api/v1/deals?took=90ms")
+	send("/api/v1/stats?took=150ms")
+	send("/api/v1/stats?took=250ms")
+	send("/readyz?took=5s")
+
+	testhelpers.LogTestStep(logger, "assert", "Violations are counted per route")
+	stats := m.Stats()
+	tests := []struct {
+		route          string
+		wantRequests   int64
+		wantViolations int64
+		wantSlowest    time.Duration
+	}{
+		{"GET /api/v1/deals", 3, 2, 90 * time.Millisecond},
+		{"default", 2, 1, 250 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		got := stats[tt.route]
+		testhelpers.LogTestAssertion(logger, tt.route, tt.wantViolations, got.Violations)
+		if got.Requests != tt.wantRequests || got.Violations != tt.wantViolations || got.Slowest != tt.wantSlowest {
+			t.Errorf("%s stats = %+v", tt.route, got)
+		}
+	}
+	if _, ok := stats["GET /readyz"]; ok {
+		t.Error("Exempt route was timed")
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Repeated violations are folded into one warning per interval")
+	if n := warnings.Len(); n != 2 {
+		t.Errorf("Warnings = %d, want 2 (deals and default)", n)
+	}
+	now = now.Add(time.Minute)
+	send("/api/v1/deals?took=60ms")
+	last := warnings.All()[warnings.Len()-1]
+	if warnings.Len() != 3 || last.ContextMap()["suppressed"] != int64(1) {
+		t.Errorf("Warning after interval = %v", last.ContextMap())
+	}
+
+	testhelpers.LogTestComplete(logger, "TestLatencyBudget_CountsViolations", true)
+}
+
+func TestParseLatencyBudgets(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestParseLatencyBudgets", "internal/middleware")
+
+	def := DefaultLatencyBudgetConfig()
+	cfg, err := ParseLatencyBudgets("default=300ms, GET /api/v1/deals=80ms,GET /api/v1/stats=0", def)
+	if err != nil {
+		t.Fatalf("ParseLatencyBudgets failed: %v", err)
+	}
+	testhelpers.LogTestAssertion(logger, "deals budget", 80*time.Millisecond, cfg.Routes["GET /api/v1/deals"])
+	if cfg.Default != 300*time.Millisecond || cfg.Routes["GET /api/v1/deals"] != 80*time.Millisecond || cfg.Routes["GET /api/v1/stats"] != 0 {
+		t.Errorf("Parsed config = %+v", cfg)
+	}
+	if def.Routes["GET /api/v1/deals"] != CachedReadBudget {
+		t.Error("Parsing modified the defaults")
+	}
+	for _, bad := range []string{"GET /api/v1/deals", "GET /api/v1/deals=fast", "=50ms", "default=-1s"} {
+		if _, err := ParseLatencyBudgets(bad, def); err == nil {
+			t.Errorf("ParseLatencyBudgets(%q) accepted", bad)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestParseLatencyBudgets", true)
+}
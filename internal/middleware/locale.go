@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+)
+
+// LocaleConfig configures locale negotiation.
+type LocaleConfig struct {
+	// QueryParam, when set, lets a request pick a supported locale
+	// explicitly (e.g. ?lang=hi-IN), overriding Accept-Language. Useful for
+	// links and embeds where the caller cannot set headers.
+	QueryParam string
+}
+
+// DefaultLocaleConfig honours ?lang= as an override.
+func DefaultLocaleConfig() LocaleConfig {
+	return LocaleConfig{QueryParam: "lang"}
+}
+
+// LocaleNegotiator stores the caller's locale in the request context for
+// handlers to format prices, weights and messages with.
+type LocaleNegotiator struct {
+	cfg LocaleConfig
+}
+
+// NewLocaleNegotiator creates the locale middleware.
+func NewLocaleNegotiator(cfg LocaleConfig) *LocaleNegotiator {
+	return &LocaleNegotiator{cfg: cfg}
+}
+
+// Handler returns the middleware. Responses carry Content-Language and vary
+// on Accept-Language so shared caches keep one copy per locale.
+func (m *LocaleNegotiator) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		loc := i18n.Negotiate(r.Header.Get("Accept-Language"))
+		if m.cfg.QueryParam != "" {
+			if tag := r.URL.Query().Get(m.cfg.QueryParam); tag != "" {
+				if l, ok := i18n.Lookup(tag); ok {
+					loc = l
+				}
+			}
+		}
+		w.Header().Set("Content-Language", loc.Tag)
+		httpx.AddVary(w.Header(), "Accept-Language")
+		next.ServeHTTP(w, r.WithContext(i18n.WithLocale(r.Context(), loc)))
+	})
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestLocaleNegotiator(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestLocaleNegotiator", "internal/middleware")
+
+	h := NewLocaleNegotiator(DefaultLocaleConfig()).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(i18n.FromContext(r.Context()).Tag))
+	}))
+
+	testCases := []struct {
+		name   string
+		target string
+		accept string
+		want   string
+	}{
+		{"Default", "/api/v1/deals", "", "en-IN"},
+		{"Header", "/api/v1/deals", "en-US,en;q=0.8", "en-US"},
+		{"Query overrides header", "/api/v1/deals?lang=hi-IN", "en-US", "hi-IN"},
+		{"Unsupported query ignored", "/api/v1/deals?lang=xx", "en-GB", "en-GB"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept-Language", tc.accept)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			testhelpers.LogTestAssertion(logger, tc.name, tc.want, rec.Body.String())
+			if rec.Body.String() != tc.want || rec.Header().Get("Content-Language") != tc.want {
+				t.Errorf("Locale = %q, Content-Language = %q, want %q", rec.Body.String(), rec.Header().Get("Content-Language"), tc.want)
+			}
+			if rec.Header().Get("Vary") != "Accept-Language" {
+				t.Errorf("Vary = %q", rec.Header().Get("Vary"))
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestLocaleNegotiator", true)
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/metrics"
+)
+
+// unmatchedRoute labels requests no route served, so scanners probing
+// random paths add one series rather than one per path.
+const unmatchedRoute = "unmatched"
+
+// HTTPBuckets are request latency buckets in seconds, with bounds at the
+// cached and database read budgets so the share of requests within each
+// is read straight off the histogram.
+var HTTPBuckets = []float64{.005, .01, .025, .05, .1, .2, .5, 1, 2.5, 5, 10}
+
+// HTTPMetrics exports request rate, errors and duration per route pattern
+// as http_requests_total{route,code} and http_request_duration_seconds,
+// with the routes recorded by httpx.RecordRoute.
+type HTTPMetrics struct {
+	requests *metrics.Counter
+	duration *metrics.Histogram
+	inFlight *metrics.Gauge
+	now      func() time.Time
+}
+
+// NewHTTPMetrics registers the HTTP metrics with reg.
+func NewHTTPMetrics(reg *metrics.Registry) *HTTPMetrics {
+	return &HTTPMetrics{
+		requests: reg.Counter("http_requests_total", "Requests served, by route pattern and status code.", "route", "code"),
+		duration: reg.Histogram("http_request_duration_seconds", "Time to serve a request, by route pattern.", HTTPBuckets, "route"),
+		inFlight: reg.Gauge("http_requests_in_flight", "Requests being served."),
+		now:      time.Now,
+	}
+}
+
+// Handler returns the middleware. Place it outermost so the duration
+// covers the other middleware, and requests they reject before routing
+// are counted, as unmatched.
+func (m *HTTPMetrics) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.inFlight.Add(1)
+		defer m.inFlight.Add(-1)
+		start := m.now()
+		r = r.WithContext(httpx.TrackRoute(r.Context()))
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		route := httpx.Route(r.Context())
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.requests.Inc(route, strconv.Itoa(sw.status))
+		m.duration.Observe(m.now().Sub(start).Seconds(), route)
+	})
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/metrics"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestHTTPMetrics_CountsByRoute(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestHTTPMetrics_CountsByRoute", "internal/middleware")
+
+	testhelpers.LogTestStep(logger, "arrange", "A router with an admin mux mounted under a prefix, on a fake clock")
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	admin := http.NewServeMux()
+	admin.HandleFunc("GET /api/v1/admin/audit", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/products/{id}", func(w http.ResponseWriter, r *http.Request) {
+		now = now.Add(30 * time.Millisecond)
+		_, _ = w.Write([]byte("{}"))
+	})
+	mux.Handle("/api/v1/admin/", httpx.RecordRoute(admin))
+	reg := metrics.NewRegistry()
+	m := NewHTTPMetrics(reg)
+	m.now = func() time.Time { return now }
+	h := m.Handler(httpx.RecordRoute(mux))
+
+	testhelpers.LogTestStep(logger, "act", "Serving two product reads, an admin request and a stray path")
+	for _, target := range []string{"/api/v1/products/prod_a", "/api/v1/products/prod_b", "/api/v1/admin/audit", "/wp-login.php"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+	var b strings.Builder
+	if err := reg.WriteText(&b); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Requests are counted by pattern, the innermost for the admin mux, and strays pooled")
+	for _, line := range []string{
+		`http_requests_total{route="GET /api/v1/products/{id}",code="200"} 2`,
+		`http_requests_total{route="GET /api/v1/admin/audit",code="403"} 1`,
+		`http_requests_total{route="unmatched",code="404"} 1`,
+		`http_request_duration_seconds_bucket{route="GET /api/v1/products/{id}",le="0.025"} 0`,
+		`http_request_duration_seconds_bucket{route="GET /api/v1/products/{id}",le="0.05"} 2`,
+		`http_requests_in_flight 0`,
+	} {
+		testhelpers.LogTestAssertion(logger, "series", line, strings.Contains(b.String(), line))
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("Metrics lack %q:\n%s", line, b.String())
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestHTTPMetrics_CountsByRoute", true)
+}
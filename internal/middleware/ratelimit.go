@@ -0,0 +1,391 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/logctx"
+)
+
+// Rate limit response headers, as documented in the API specification.
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+	RateLimitWindowHeader    = "X-RateLimit-Window"
+	RateLimitPolicyHeader    = "X-RateLimit-Policy"
+	RetryAfterHeader         = "Retry-After"
+)
+
+const rateLimitPolicyName = "sliding-window"
+
+// RateLimitPolicy allows Limit requests per Window. A zero Limit disables
+// limiting.
+type RateLimitPolicy struct {
+	Limit  int           `yaml:"limit"`
+	Window time.Duration `yaml:"window"`
+}
+
+// RateLimitDecision is the outcome of counting one request.
+type RateLimitDecision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Used       int
+	Reset      time.Time
+	RetryAfter time.Duration
+}
+
+// RateLimitStore counts requests per key. Implementations must be safe for
+// concurrent use.
+type RateLimitStore interface {
+	Allow(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitDecision, error)
+}
+
+// RateLimitConfig configures the rate limiting middleware.
+type RateLimitConfig struct {
+	// Default applies to requests that match none of Routes.
+	Default RateLimitPolicy `yaml:"default"`
+	// Routes overrides the policy per ServeMux pattern, e.g.
+	// "GET /api/v1/products/{id}/prices". Each route has its own bucket.
+	Routes map[string]RateLimitPolicy `yaml:"routes"`
+	// TrustProxy takes the client address from X-Forwarded-For.
+	TrustProxy bool `yaml:"-"`
+	// Key identifies the caller. Defaults to the client IP. Requests it
+	// returns "" for are not limited.
+	Key func(r *http.Request) string `yaml:"-"`
+	// Tier names the caller's tier, and Tiers scales every Limit for it,
+	// e.g. {"verified": 2} doubles verified accounts' budgets. Callers in
+	// other tiers get the policies as configured.
+	Tier  func(r *http.Request) string `yaml:"-"`
+	Tiers map[string]float64           `yaml:"tiers"`
+}
+
+// Validate reports policies with a negative limit or a limit but no
+// window, malformed or conflicting route patterns and tiers scaling by
+// zero or less.
+func (cfg RateLimitConfig) Validate() error {
+	var errs []error
+	check := func(name string, p RateLimitPolicy) {
+		if p.Limit < 0 || p.Limit > 0 && p.Window <= 0 {
+			errs = append(errs, fmt.Errorf("%s: limit must be 0 or more, with a positive window", name))
+		}
+	}
+	check("default", cfg.Default)
+	if _, err := routeMux(cfg.Routes); err != nil {
+		errs = append(errs, err)
+	}
+	for _, pattern := range slices.Sorted(maps.Keys(cfg.Routes)) {
+		check(fmt.Sprintf("route %q", pattern), cfg.Routes[pattern])
+	}
+	for _, tier := range slices.Sorted(maps.Keys(cfg.Tiers)) {
+		if cfg.Tiers[tier] <= 0 {
+			errs = append(errs, fmt.Errorf("tier %q: scale must be positive", tier))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// routeMux registers every pattern of routes on one ServeMux, as the
+// limiter matches them, and reports each one http.ServeMux panics on:
+// malformed patterns, and patterns conflicting with one registered before
+// them, such as "GET /p/{slug}" beside "GET /p/{id}".
+func routeMux(routes map[string]RateLimitPolicy) (*http.ServeMux, error) {
+	mux := http.NewServeMux()
+	var errs []error
+	for _, pattern := range slices.Sorted(maps.Keys(routes)) {
+		func() {
+			defer func() {
+				if p := recover(); p != nil {
+					errs = append(errs, fmt.Errorf("route %q: %v", pattern, p))
+				}
+			}()
+			mux.Handle(pattern, http.NotFoundHandler())
+		}()
+	}
+	return mux, errors.Join(errs...)
+}
+
+// DefaultRateLimitConfig returns the per-IP limits from the API
+// specification.
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		Default: RateLimitPolicy{Limit: 1000, Window: time.Hour},
+		Routes: map[string]RateLimitPolicy{
+			"GET /api/v1/products/search":             {Limit: 100, Window: time.Minute},
+			"GET /api/v1/products/{id}":               {Limit: 200, Window: time.Minute},
+			"GET /api/v1/products/{id}/prices":        {Limit: 150, Window: time.Minute},
+			"GET /api/v1/products/{id}/price-history": {Limit: 50, Window: time.Minute},
+			"GET /api/v1/brands":                      {Limit: 1000, Window: time.Hour},
+			"GET /api/v1/retailers":                   {Limit: 1000, Window: time.Hour},
+			"GET /api/v1/deals":                       {Limit: 100, Window: time.Minute},
+			"GET /api/v1/stats":                       {Limit: 60, Window: time.Minute},
+			"GET /go/{retailer}/{productID}":          {Limit: 60, Window: time.Minute},
+			"POST /api/v1/alerts/guest":               {Limit: 10, Window: time.Hour},
+			"GET /health":                             {},
+			"GET /healthz":                            {},
+			"GET /readyz":                             {},
+		},
+	}
+}
+
+// DefaultAccountRateLimitConfig returns per-account limits, applied to
+// signed-in users and API tokens on top of the per-IP ones so that one
+// account cannot spread abuse over many addresses. Set Key to identify the
+// account.
+func DefaultAccountRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		Default: RateLimitPolicy{Limit: 3000, Window: time.Hour},
+		Routes: map[string]RateLimitPolicy{
+			"GET /api/v1/products/search":   {Limit: 120, Window: time.Minute},
+			"POST /api/v1/alerts":           {Limit: 30, Window: time.Hour},
+			"POST /api/v1/alerts/searches":  {Limit: 10, Window: time.Hour},
+			"POST /api/v1/alerts/{id}/test": {Limit: 5, Window: time.Hour},
+			"POST /api/v1/tokens":           {Limit: 10, Window: time.Hour},
+			"PUT /api/v1/sms":               {Limit: 5, Window: time.Hour},
+			"PUT /api/v1/discord":           {Limit: 10, Window: time.Hour},
+			"PUT /api/v1/slack":             {Limit: 10, Window: time.Hour},
+			"POST /api/v1/sms/verify":       {Limit: 20, Window: time.Hour},
+		},
+	}
+}
+
+// RateLimiter rejects callers that exceed their policy with 429 and reports
+// the remaining budget on every limited response.
+type RateLimiter struct {
+	cfg    RateLimitConfig
+	store  RateLimitStore
+	rules  atomic.Pointer[rateLimitRules]
+	logger *zap.Logger
+}
+
+// rateLimitRules are the policies in force, replaced whole by SetPolicies.
+type rateLimitRules struct {
+	def    RateLimitPolicy
+	routes map[string]RateLimitPolicy
+	mux    *http.ServeMux
+	tiers  map[string]float64
+}
+
+// NewRateLimiter creates a RateLimiter. It panics on route patterns
+// http.ServeMux would panic on; check cfg.Validate first.
+func NewRateLimiter(cfg RateLimitConfig, store RateLimitStore, logger *zap.Logger) *RateLimiter {
+	m := &RateLimiter{cfg: cfg, store: store, logger: logger}
+	if err := m.SetPolicies(cfg); err != nil {
+		panic(err)
+	}
+	if m.cfg.Key == nil {
+		m.cfg.Key = func(r *http.Request) string { return "ip:" + httpx.ClientIP(r, cfg.TrustProxy) }
+	}
+	return m
+}
+
+// SetPolicies replaces the Default, Routes and Tiers in force with cfg's,
+// keeping the rest of the configuration and the counts so far, so limits
+// can change while the server runs. If a route pattern is malformed or
+// conflicts with another, it returns why and leaves the policies in force.
+func (m *RateLimiter) SetPolicies(cfg RateLimitConfig) error {
+	mux, err := routeMux(cfg.Routes)
+	if err != nil {
+		return err
+	}
+	m.rules.Store(&rateLimitRules{
+		def:    cfg.Default,
+		routes: maps.Clone(cfg.Routes),
+		mux:    mux,
+		tiers:  maps.Clone(cfg.Tiers),
+	})
+	return nil
+}
+
+// Handler returns the middleware.
+func (m *RateLimiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rules := m.rules.Load()
+		bucket, policy := rules.policy(r)
+		if policy.Limit <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := m.cfg.Key(r)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if m.cfg.Tier != nil {
+			if scale, ok := rules.tiers[m.cfg.Tier(r)]; ok && scale > 0 {
+				policy.Limit = max(1, int(float64(policy.Limit)*scale))
+			}
+		}
+		d, err := m.store.Allow(r.Context(), key+"|"+bucket, policy)
+		if err != nil {
+			// Fail open: losing the limiter must not take the API down.
+			logctx.Logger(r.Context(), m.logger).Warn("Rate limit check failed",
+				zap.String("operation", "RateLimit"),
+				zap.String("bucket", bucket),
+				zap.Error(err),
+			)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		h.Set(RateLimitLimitHeader, strconv.Itoa(d.Limit))
+		h.Set(RateLimitRemainingHeader, strconv.Itoa(d.Remaining))
+		h.Set(RateLimitResetHeader, strconv.FormatInt(d.Reset.Unix(), 10))
+		h.Set(RateLimitWindowHeader, strconv.Itoa(int(policy.Window.Seconds())))
+		h.Set(RateLimitPolicyHeader, rateLimitPolicyName)
+
+		if d.Allowed {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		retryAfter := max(1, int(math.Ceil(d.RetryAfter.Seconds())))
+		h.Set(RetryAfterHeader, strconv.Itoa(retryAfter))
+		logctx.Logger(r.Context(), m.logger).Info("Rate limit exceeded",
+			zap.String("operation", "RateLimit"),
+			zap.String("bucket", bucket),
+			zap.Int("limit", d.Limit),
+			zap.Int("retry_after_seconds", retryAfter),
+		)
+		httpx.WriteError(w, r, http.StatusTooManyRequests, httpx.CodeRateLimitExceeded,
+			fmt.Sprintf("Rate limit exceeded. Try again in %s.", humanizeSeconds(retryAfter)),
+			map[string]any{
+				"limit":               d.Limit,
+				"window_seconds":      int(policy.Window.Seconds()),
+				"retry_after_seconds": retryAfter,
+				"current_usage":       d.Used,
+				"reset_time":          d.Reset.UTC().Format(time.RFC3339),
+			})
+	})
+}
+
+// policy resolves the bucket name and policy for r.
+func (rules *rateLimitRules) policy(r *http.Request) (string, RateLimitPolicy) {
+	if _, pattern := rules.mux.Handler(r); pattern != "" {
+		if p, ok := rules.routes[pattern]; ok {
+			return pattern, p
+		}
+	}
+	return "global", rules.def
+}
+
+func humanizeSeconds(s int) string {
+	switch {
+	case s < 60:
+		return plural(s, "second")
+	case s < 3600:
+		return plural((s+59)/60, "minute")
+	default:
+		return plural((s+3599)/3600, "hour")
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return strconv.Itoa(n) + " " + unit + "s"
+}
+
+// MemoryRateLimitStore is a single-process sliding window counter. It keeps
+// two fixed-window counts per key and weights the previous window by how much
+// of it still overlaps the sliding window, which approximates a true sliding
+// log in constant memory.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	windows   map[string]*slidingWindow
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+type slidingWindow struct {
+	start    time.Time
+	window   time.Duration
+	previous int
+	current  int
+}
+
+// NewMemoryRateLimitStore creates an empty in-memory store.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{windows: make(map[string]*slidingWindow), now: time.Now}
+}
+
+// Allow implements RateLimitStore.
+func (s *MemoryRateLimitStore) Allow(_ context.Context, key string, p RateLimitPolicy) (RateLimitDecision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.evictExpired(now)
+
+	start := now.Truncate(p.Window)
+	w, ok := s.windows[key]
+	switch {
+	case !ok || w.window != p.Window || start.Sub(w.start) >= 2*p.Window:
+		w = &slidingWindow{start: start, window: p.Window}
+		s.windows[key] = w
+	case start.After(w.start):
+		w.previous, w.current, w.start = w.current, 0, start
+	}
+	return w.take(now, p.Limit), nil
+}
+
+func (w *slidingWindow) take(now time.Time, limit int) RateLimitDecision {
+	elapsed := float64(now.Sub(w.start)) / float64(w.window)
+	estimate := float64(w.previous)*(1-elapsed) + float64(w.current)
+	d := RateLimitDecision{Limit: limit, Reset: w.start.Add(w.window)}
+
+	if estimate+1 > float64(limit) {
+		d.Used = int(math.Ceil(estimate)) + 1
+		d.RetryAfter = w.retryAfter(now, limit)
+		return d
+	}
+	w.current++
+	d.Allowed = true
+	d.Used = int(math.Ceil(estimate)) + 1
+	d.Remaining = max(0, limit-d.Used)
+	return d
+}
+
+// retryAfter is how long until the estimate leaves room for one request,
+// rounded to the millisecond to hide floating point noise.
+func (w *slidingWindow) retryAfter(now time.Time, limit int) time.Duration {
+	budget := float64(limit - 1)
+	end := w.start.Add(w.window)
+	if float64(w.current) <= budget && w.previous > 0 {
+		// The previous window's share decays within this window.
+		need := 1 - (budget-float64(w.current))/float64(w.previous)
+		at := w.start.Add(time.Duration(need * float64(w.window)))
+		return max(0, at.Sub(now).Round(time.Millisecond))
+	}
+	// This window alone is over budget; wait for it to decay in the next.
+	need := 1 - budget/float64(w.current)
+	return (end.Sub(now) + time.Duration(need*float64(w.window))).Round(time.Millisecond)
+}
+
+func (s *MemoryRateLimitStore) evictExpired(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+	s.nextSweep = now.Add(time.Minute)
+	for key, w := range s.windows {
+		if now.Sub(w.start) >= 2*w.window {
+			delete(s.windows, key)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func newTestRateLimiter(t *testing.T, cfg RateLimitConfig, now *time.Time) http.Handler {
+	t.Helper()
+	logger := testhelpers.SetupTestLogger(t)
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return *now }
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	return NewRateLimiter(cfg, store, logger).Handler(ok)
+}
+
+func sendFrom(h http.Handler, ip, target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.RemoteAddr = ip + ":51234"
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimiter_HeadersAndRejection(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestRateLimiter_HeadersAndRejection", "internal/middleware")
+
+	now := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	cfg := RateLimitConfig{
+		Default: RateLimitPolicy{Limit: 100, Window: time.Hour},
+		Routes: map[string]RateLimitPolicy{
+			"GET /api/v1/products/{id}/prices": {Limit: 3, Window: time.Minute},
+			"GET /health":                      {},
+		},
+	}
+	h := newTestRateLimiter(t, cfg, &now)
+
+	testhelpers.LogTestStep(logger, "act", "Spending the per-route budget")
+	for i := 2; i >= 0; i-- {
+		rec := sendFrom(h, "203.0.113.7", "/api/v1/products/prod_1/prices")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Request %d status = %d", 3-i, rec.Code)
+		}
+		if got := rec.Header().Get(RateLimitRemainingHeader); got != string(rune('0'+i)) {
+			t.Errorf("Remaining = %s, want %d", got, i)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Exceeding it")
+	rec := sendFrom(h, "203.0.113.7", "/api/v1/products/prod_2/prices")
+	testhelpers.LogTestAssertion(logger, "status", http.StatusTooManyRequests, rec.Code)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Status = %d, want 429", rec.Code)
+	}
+	wantHeaders := map[string]string{
+		RateLimitLimitHeader:     "3",
+		RateLimitRemainingHeader: "0",
+		RateLimitResetHeader:     "1705329060",
+		RateLimitWindowHeader:    "60",
+		RateLimitPolicyHeader:    "sliding-window",
+		RetryAfterHeader:         "80",
+	}
+	for name, want := range wantHeaders {
+		if got := rec.Header().Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	var body httpx.ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Invalid error body: %v", err)
+	}
+	if body.Error.Code != httpx.CodeRateLimitExceeded || body.Error.Details["retry_after_seconds"] != float64(80) {
+		t.Errorf("Error = %+v", body.Error)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Other buckets and callers are unaffected")
+	if rec := sendFrom(h, "203.0.113.7", "/api/v1/deals"); rec.Code != http.StatusOK || rec.Header().Get(RateLimitLimitHeader) != "100" {
+		t.Errorf("Default bucket status = %d, limit = %s", rec.Code, rec.Header().Get(RateLimitLimitHeader))
+	}
+	if rec := sendFrom(h, "198.51.100.2", "/api/v1/products/prod_1/prices"); rec.Code != http.StatusOK {
+		t.Errorf("Second caller status = %d, want 200", rec.Code)
+	}
+	if rec := sendFrom(h, "203.0.113.7", "/health"); rec.Header().Get(RateLimitLimitHeader) != "" {
+		t.Error("Exempt route must not carry rate limit headers")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestRateLimiter_HeadersAndRejection", true)
+}
+
+func TestRateLimiter_AccountKeysAndTiers(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestRateLimiter_AccountKeysAndTiers", "internal/middleware")
+
+	now := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	cfg := RateLimitConfig{
+		Default: RateLimitPolicy{Limit: 2, Window: time.Minute},
+		Key:     func(r *http.Request) string { return r.Header.Get("X-Account") },
+		Tier:    func(r *http.Request) string { return r.Header.Get("X-Tier") },
+		Tiers:   map[string]float64{"verified": 2.5, "tiny": 0.1},
+	}
+	h := newTestRateLimiter(t, cfg, &now)
+
+	testCases := []struct {
+		name        string
+		account     string
+		tier        string
+		wantAllowed int
+	}{
+		{"Anonymous callers are not limited", "", "", 10},
+		{"Base tier", "user:1", "", 2},
+		{"Unknown tier", "user:2", "gold", 2},
+		{"Scaled up", "user:3", "verified", 5},
+		{"Scaled down keeps one request", "user:4", "tiny", 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			allowed := 0
+			for range 10 {
+				req := httptest.NewRequest(http.MethodGet, "/api/v1/deals", nil)
+				req.Header.Set("X-Account", tc.account)
+				req.Header.Set("X-Tier", tc.tier)
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, req)
+				if rec.Code == http.StatusOK {
+					allowed++
+				}
+			}
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantAllowed, allowed)
+			if allowed != tc.wantAllowed {
+				t.Errorf("Allowed %d requests, want %d", allowed, tc.wantAllowed)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestRateLimiter_AccountKeysAndTiers", true)
+}
+
+func TestRateLimiter_SetPolicies(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestRateLimiter_SetPolicies", "internal/middleware")
+
+	testhelpers.LogTestStep(logger, "arrange", "A limiter allowing two searches a minute, one of them spent")
+	now := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+	cfg := RateLimitConfig{Routes: map[string]RateLimitPolicy{"GET /api/v1/products/search": {Limit: 2, Window: time.Minute}}}
+	limiter := NewRateLimiter(cfg, store, logger)
+	h := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }))
+	sendFrom(h, "203.0.113.7", "/api/v1/products/search?q=whey")
+
+	testhelpers.LogTestStep(logger, "act", "Raising the limit to three while running")
+	cfg.Routes["GET /api/v1/products/search"] = RateLimitPolicy{Limit: 3, Window: time.Minute}
+	if err := limiter.SetPolicies(cfg); err != nil {
+		t.Fatalf("SetPolicies: %v", err)
+	}
+	allowed := 0
+	for range 5 {
+		if rec := sendFrom(h, "203.0.113.7", "/api/v1/products/search?q=whey"); rec.Code == http.StatusOK {
+			allowed++
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The new limit applies to the count so far")
+	testhelpers.LogTestAssertion(logger, "allowed", 2, allowed)
+	if allowed != 2 {
+		t.Errorf("Allowed %d more requests, want 2", allowed)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Validate rejects what NewRateLimiter would panic or misbehave on")
+	bad := RateLimitConfig{
+		Default: RateLimitPolicy{Limit: 10},
+		Routes: map[string]RateLimitPolicy{
+			"GET /{id":                    {Limit: 1, Window: time.Minute},
+			"GET /api/v1/products/{id}":   {Limit: 1, Window: time.Minute},
+			"GET /api/v1/products/{slug}": {Limit: 1, Window: time.Minute},
+		},
+		Tiers: map[string]float64{"verified": 0},
+	}
+	err := bad.Validate()
+	for _, want := range []string{"default", `route "GET /{id"`, `route "GET /api/v1/products/{slug}"`, `tier "verified"`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() = %v, want a problem with %s", err, want)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "SetPolicies refuses conflicting routes and keeps the limits in force")
+	if err := limiter.SetPolicies(bad); err == nil {
+		t.Error("SetPolicies accepted conflicting routes")
+	}
+	if rec := sendFrom(h, "203.0.113.7", "/api/v1/products/search?q=whey"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Search after a refused change = %d, want the old limit's 429", rec.Code)
+	}
+	if err := DefaultRateLimitConfig().Validate(); err != nil {
+		t.Errorf("Default config invalid: %v", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestRateLimiter_SetPolicies", true)
+}
+
+func TestMemoryRateLimitStore_SlidingWindow(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestMemoryRateLimitStore_SlidingWindow", "internal/middleware")
+
+	start := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	now := start
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+	policy := RateLimitPolicy{Limit: 10, Window: time.Minute}
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Filling the first window")
+	for range 10 {
+		if d, _ := store.Allow(ctx, "k", policy); !d.Allowed {
+			t.Fatal("Request within the limit was rejected")
+		}
+	}
+	d, _ := store.Allow(ctx, "k", policy)
+	if d.Allowed || d.RetryAfter != 66*time.Second {
+		t.Errorf("Over-limit decision = %+v, want rejection with 66s retry", d)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Half way through the next window")
+	now = start.Add(90 * time.Second)
+	allowed := 0
+	for range 10 {
+		if d, _ = store.Allow(ctx, "k", policy); d.Allowed {
+			allowed++
+		}
+	}
+	testhelpers.LogTestAssertion(logger, "allowed after half a window", 5, allowed)
+	if allowed != 5 {
+		t.Errorf("Allowed %d requests, want 5 (half the previous window still counts)", allowed)
+	}
+	if d.RetryAfter != 6*time.Second {
+		t.Errorf("RetryAfter = %v, want 6s", d.RetryAfter)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "After two idle windows the budget is full again")
+	now = start.Add(5 * time.Minute)
+	if d, _ = store.Allow(ctx, "k", policy); !d.Allowed || d.Remaining != 9 {
+		t.Errorf("Fresh decision = %+v, want 9 remaining", d)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestMemoryRateLimitStore_SlidingWindow", true)
+}
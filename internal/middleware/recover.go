@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+	"github.com/yourusername/whey-price-compare/internal/logctx"
+)
+
+// Recover turns a handler panic into a 500 response and an Error line
+// holding the panic value. net/http would otherwise print the panic to
+// stderr, outside the structured logs and the error tracker, and drop the
+// connection without a response.
+type Recover struct {
+	logger *zap.Logger
+}
+
+// NewRecover creates the middleware.
+func NewRecover(logger *zap.Logger) *Recover {
+	return &Recover{logger: logger}
+}
+
+// Handler returns the middleware. Place it inside AccessLog, so the panic
+// line carries the request ID and the access line shows the 500. The line
+// is logged from the deferred recover, while the panicking frames are
+// still on the stack an error tracker records.
+func (m *Recover) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				// Handlers panic with it on purpose to abort the response.
+				panic(p)
+			}
+			logctx.Logger(r.Context(), m.logger).Error("Handler panicked",
+				zap.String("operation", "Recover"),
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.Any("panic", p),
+			)
+			if !sw.wroteHeader {
+				httpx.WriteError(sw, r, http.StatusInternalServerError, httpx.CodeInternal,
+					i18n.FromContext(r.Context()).T(i18n.MsgInternalError), nil)
+			}
+		}()
+		next.ServeHTTP(sw, r)
+	})
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestRecover_AnswersPanicsWith500(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestRecover_AnswersPanicsWith500", "internal/middleware")
+
+	testhelpers.LogTestStep(logger, "arrange", "An access-logged handler that panics")
+	core, logs := observer.New(zap.InfoLevel)
+	appLogger := zap.New(core)
+	h := NewAccessLog(DefaultAccessLogConfig(), appLogger).Handler(NewRecover(appLogger).Handler(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var offers []string
+			_ = offers[3]
+		})))
+
+	testhelpers.LogTestStep(logger, "act", "Serving a request")
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/compare/prod_on_gsw", nil)
+	req.Header.Set(httpx.RequestIDHeader, "req_abc123")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	testhelpers.LogTestStep(logger, "assert", "The client gets the error envelope; the panic is logged under the request")
+	testhelpers.LogTestAssertion(logger, "status", http.StatusInternalServerError, rec.Code)
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), httpx.CodeInternal) {
+		t.Errorf("Response = %d %s", rec.Code, rec.Body.String())
+	}
+	panics := logs.FilterMessage("Handler panicked").All()
+	if len(panics) != 1 {
+		t.Fatalf("Logged %d panics, want 1", len(panics))
+	}
+	fields := panics[0].ContextMap()
+	if fields["request_id"] != "req_abc123" || !strings.Contains(fields["panic"].(string), "index out of range") {
+		t.Errorf("Panic line = %v", fields)
+	}
+	served := logs.FilterMessage("Request served").All()
+	if len(served) != 1 || served[0].ContextMap()["status"] != int64(http.StatusInternalServerError) {
+		t.Errorf("Access lines = %v", served)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Deliberate aborts still abort")
+	abort := NewRecover(appLogger).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if p := recover(); p != http.ErrAbortHandler {
+			t.Errorf("Recovered %v, want http.ErrAbortHandler", p)
+		}
+		testhelpers.LogTestComplete(logger, "TestRecover_AnswersPanicsWith500", true)
+	}()
+	abort.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/deals", nil))
+}
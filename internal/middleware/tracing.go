@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/logctx"
+	"github.com/yourusername/whey-price-compare/internal/tracing"
+)
+
+// traceparentHeader carries W3C trace context between services.
+const traceparentHeader = "traceparent"
+
+// Tracing starts a server span for every request, continuing the caller's
+// trace when it sends a traceparent header, such as the scraper posting
+// prices to the ingest endpoint. The span is named after the route
+// pattern recorded by httpx.RecordRoute, so spans group like metrics do.
+type Tracing struct {
+	tracer *tracing.Tracer
+}
+
+// NewTracing creates the middleware.
+func NewTracing(tracer *tracing.Tracer) *Tracing {
+	return &Tracing{tracer: tracer}
+}
+
+// Handler returns the middleware. Place it outside the other middleware,
+// after HTTPMetrics, so the span covers them too. Lines logged through
+// logctx.Logger while serving a sampled request carry its trace ID.
+func (m *Tracing) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if parent, ok := tracing.ParseTraceparent(r.Header.Get(traceparentHeader)); ok {
+			ctx = tracing.ContextWithRemoteParent(ctx, parent)
+		}
+		ctx, span := m.tracer.Start(httpx.TrackRoute(ctx), tracing.KindServer, r.Method,
+			tracing.String("http.request.method", r.Method),
+			tracing.String("url.path", r.URL.Path),
+		)
+		defer span.End()
+		if span != nil {
+			ctx = logctx.With(ctx, zap.String("trace_id", span.SpanContext().TraceID.String()))
+		}
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r.WithContext(ctx))
+
+		if route := httpx.Route(ctx); route != "" {
+			span.SetName(route)
+			span.SetAttributes(tracing.String("http.route", route))
+		}
+		span.SetAttributes(tracing.Int("http.response.status_code", sw.status))
+		if sw.status >= http.StatusInternalServerError {
+			span.RecordError(errStatus(sw.status))
+		}
+	})
+}
+
+// errStatus is a server error status, as a span's error.
+type errStatus int
+
+func (e errStatus) Error() string { return http.StatusText(int(e)) }
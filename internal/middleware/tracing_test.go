@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+	"github.com/yourusername/whey-price-compare/internal/tracing"
+)
+
+func TestTracing_NamesSpansByRoute(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestTracing_NamesSpansByRoute", "internal/middleware")
+
+	testhelpers.LogTestStep(logger, "arrange", "A collector, and a traced router whose handler starts a child span")
+	var mu sync.Mutex
+	var exported []string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []struct {
+						TraceID string `json:"traceId"`
+						Name    string `json:"name"`
+					} `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		defer mu.Unlock()
+		for _, rs := range body.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, s := range ss.Spans {
+					exported = append(exported, s.TraceID+" "+s.Name)
+				}
+			}
+		}
+	}))
+	defer collector.Close()
+	tracer := tracing.NewTracer(tracing.Config{Endpoint: collector.URL, SampleRatio: 1}, zap.NewNop())
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tracer.Run(ctx)
+	}()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/products/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, span := tracing.Start(r.Context(), "ProductRepository.FindByIDs")
+		span.End()
+	})
+	h := NewTracing(tracer).Handler(httpx.RecordRoute(mux))
+
+	testhelpers.LogTestStep(logger, "act", "Serving a request from a caller that sent a traceparent")
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/products/prod_on_gsw", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	cancel()
+	<-done
+
+	testhelpers.LogTestStep(logger, "assert", "The server span is named after the route, in the caller's trace")
+	want := []string{
+		"4bf92f3577b34da6a3ce929d0e0e4736 ProductRepository.FindByIDs",
+		"4bf92f3577b34da6a3ce929d0e0e4736 GET /api/v1/products/{id}",
+	}
+	testhelpers.LogTestAssertion(logger, "spans", want, exported)
+	if strings.Join(exported, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Exported %v, want %v", exported, want)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestTracing_NamesSpansByRoute", true)
+}
@@ -0,0 +1,153 @@
+package notify
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// DigestSchedule is when digests go out: daily at Hour, and weekly on
+// Weekday at Hour, in Location.
+type DigestSchedule struct {
+	Hour     int
+	Weekday  time.Weekday
+	Location *time.Location
+}
+
+// DefaultDigestSchedule sends digests at 8am India time, weekly ones on
+// Mondays. A fixed zone avoids depending on the host's tzdata.
+func DefaultDigestSchedule() DigestSchedule {
+	return DigestSchedule{Hour: 8, Weekday: time.Monday, Location: time.FixedZone("IST", 5*60*60+30*60)}
+}
+
+// Next returns the first digest for frequency after t, or the zero time
+// for instant delivery.
+func (s DigestSchedule) Next(frequency string, t time.Time) time.Time {
+	if s.Location == nil {
+		s.Location = time.UTC
+	}
+	local := t.In(s.Location)
+	next := time.Date(local.Year(), local.Month(), local.Day(), s.Hour, 0, 0, 0, s.Location)
+	switch frequency {
+	case domain.FrequencyDaily:
+		if !next.After(local) {
+			next = next.AddDate(0, 0, 1)
+		}
+	case domain.FrequencyWeekly:
+		next = next.AddDate(0, 0, (int(s.Weekday)-int(next.Weekday())+7)%7)
+		if !next.After(local) {
+			next = next.AddDate(0, 0, 7)
+		}
+	default:
+		return time.Time{}
+	}
+	return next.UTC()
+}
+
+// DigesterConfig configures the Digester.
+type DigesterConfig struct {
+	// Interval is how often due digests are looked for.
+	Interval time.Duration
+}
+
+// DefaultDigesterConfig looks for due digests every minute.
+func DefaultDigesterConfig() DigesterConfig {
+	return DigesterConfig{Interval: time.Minute}
+}
+
+// Digester folds notifications the Dispatcher held for a digest into one
+// digest notification per user, which the Dispatcher then delivers.
+type Digester struct {
+	cfg    DigesterConfig
+	queue  repositories.NotificationQueue
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewDigester creates a Digester. Call Run to start it.
+func NewDigester(cfg DigesterConfig, queue repositories.NotificationQueue, logger *zap.Logger) *Digester {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultDigesterConfig().Interval
+	}
+	return &Digester{cfg: cfg, queue: queue, logger: logger, now: time.Now}
+}
+
+// Run generates digests every Interval until ctx is done.
+func (g *Digester) Run(ctx context.Context) {
+	ticker := time.NewTicker(g.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			g.Generate(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Generate queues a digest for every user with due notifications and
+// returns how many it queued. Of several alerts for one product only the
+// latest is kept.
+func (g *Digester) Generate(ctx context.Context) int {
+	due, err := g.queue.Due(ctx, g.now())
+	if err != nil {
+		g.logger.Error("Loading due notifications failed", zap.String("operation", "GenerateDigests"), zap.Error(err))
+		return 0
+	}
+	if len(due) == 0 {
+		return 0
+	}
+
+	var users []string
+	items := make(map[string][]domain.Notification)
+	ids := make([]string, 0, len(due))
+	for _, n := range due {
+		ids = append(ids, n.ID)
+		n.HeldUntil = nil
+		list, seen := items[n.UserID]
+		if !seen {
+			users = append(users, n.UserID)
+		}
+		replaced := false
+		for i := range list {
+			if list[i].ProductID == n.ProductID {
+				list[i], replaced = n, true
+			}
+		}
+		if !replaced {
+			list = append(list, n)
+		}
+		items[n.UserID] = list
+	}
+
+	now := g.now().UTC()
+	digests := make([]domain.Notification, 0, len(users))
+	for _, userID := range users {
+		digests = append(digests, domain.Notification{
+			Type:      domain.NotificationPriceAlertDigest,
+			UserID:    userID,
+			Items:     items[userID],
+			CreatedAt: now,
+		})
+	}
+	// Queue the digests before retiring their items: a failure in between
+	// repeats a digest rather than losing one.
+	if err := g.queue.Enqueue(ctx, digests...); err != nil {
+		g.logger.Error("Queueing digests failed", zap.String("operation", "GenerateDigests"), zap.Error(err))
+		return 0
+	}
+	if err := g.queue.MarkSent(ctx, now, ids...); err != nil {
+		g.logger.Error("Retiring digested notifications failed", zap.String("operation", "GenerateDigests"), zap.Error(err))
+	}
+	g.logger.Info("Digests queued",
+		zap.String("operation", "GenerateDigests"),
+		zap.Int("digests", len(digests)),
+		zap.Int("alerts", len(ids)),
+	)
+	return len(digests)
+}
@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestDigestSchedule_Next(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestDigestSchedule_Next", "internal/notify")
+
+	s := DefaultDigestSchedule()
+	ist := s.Location
+	// Friday 16 October 2026.
+	testCases := []struct {
+		name      string
+		frequency string
+		at        time.Time
+		want      time.Time
+	}{
+		{"Instant", domain.FrequencyInstant, time.Date(2026, 10, 16, 7, 0, 0, 0, ist), time.Time{}},
+		{"Daily, before the hour", domain.FrequencyDaily, time.Date(2026, 10, 16, 7, 59, 0, 0, ist), time.Date(2026, 10, 16, 8, 0, 0, 0, ist)},
+		{"Daily, on the hour", domain.FrequencyDaily, time.Date(2026, 10, 16, 8, 0, 0, 0, ist), time.Date(2026, 10, 17, 8, 0, 0, 0, ist)},
+		{"Daily, from UTC late evening", domain.FrequencyDaily, time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC), time.Date(2026, 10, 17, 8, 0, 0, 0, ist)},
+		{"Weekly, on Friday", domain.FrequencyWeekly, time.Date(2026, 10, 16, 9, 0, 0, 0, ist), time.Date(2026, 10, 19, 8, 0, 0, 0, ist)},
+		{"Weekly, Monday before the hour", domain.FrequencyWeekly, time.Date(2026, 10, 19, 6, 0, 0, 0, ist), time.Date(2026, 10, 19, 8, 0, 0, 0, ist)},
+		{"Weekly, Monday after the hour", domain.FrequencyWeekly, time.Date(2026, 10, 19, 8, 30, 0, 0, ist), time.Date(2026, 10, 26, 8, 0, 0, 0, ist)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := s.Next(tc.frequency, tc.at)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.want, got)
+			if !got.Equal(tc.want) {
+				t.Errorf("Next(%s, %v) = %v, want %v", tc.frequency, tc.at, got, tc.want)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestDigestSchedule_Next", true)
+}
+
+func TestDigests_EndToEnd(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestDigests_EndToEnd", "internal/notify")
+
+	testhelpers.LogTestStep(logger, "arrange", "An instant user and a daily digest user with three alerts, two for one product")
+	store := memory.NewStore()
+	ctx := t.Context()
+	instant, _ := store.Users().CreateUser(ctx, domain.User{Email: "asha@example.com"})
+	daily, _ := store.Users().CreateUser(ctx, domain.User{Email: "ravi@example.com"})
+	prefs := NewPreferences(store.Preferences())
+	if _, err := prefs.Save(ctx, domain.NotificationPreferences{UserID: daily.ID, Frequency: domain.FrequencyDaily}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := store.Notifications().Enqueue(ctx,
+		domain.Notification{Type: domain.NotificationPriceAlert, UserID: instant.ID, ProductID: "prod_a", Price: 3000},
+		domain.Notification{Type: domain.NotificationPriceAlert, UserID: daily.ID, ProductID: "prod_a", Price: 3000},
+		domain.Notification{Type: domain.NotificationPriceAlert, UserID: daily.ID, ProductID: "prod_b", Price: 2000},
+		domain.Notification{Type: domain.NotificationPriceAlert, UserID: daily.ID, ProductID: "prod_a", Price: 2900},
+	); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	now := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	channel := &fakeChannel{name: "email"}
+	d := NewDispatcher(DispatcherConfig{}, store.Notifications(), store.Users(), logger, channel).WithPreferences(prefs, DefaultDigestSchedule())
+	d.now = clock
+	g := NewDigester(DigesterConfig{}, store.Notifications(), logger)
+	g.now = clock
+
+	testhelpers.LogTestStep(logger, "act", "Dispatching before the digest is due")
+	if n := d.Dispatch(ctx); n != 4 {
+		t.Errorf("Dispatch processed %d, want 4", n)
+	}
+	if len(channel.delivered) != 1 {
+		t.Fatalf("Delivered %v, want only the instant user's alert", channel.delivered)
+	}
+	if n := g.Generate(ctx); n != 0 {
+		t.Errorf("Generate before 8am IST queued %d digests", n)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "The next morning the digest is generated and dispatched")
+	now = time.Date(2026, 10, 17, 2, 30, 0, 0, time.UTC) // 8am IST
+	if n := g.Generate(ctx); n != 1 {
+		t.Fatalf("Generate queued %d digests, want 1", n)
+	}
+	pending, _ := store.Notifications().Pending(ctx, 0)
+	testhelpers.LogTestAssertion(logger, "digest items", 2, len(pending[0].Items))
+	if len(pending) != 1 || pending[0].Type != domain.NotificationPriceAlertDigest || len(pending[0].Items) != 2 {
+		t.Fatalf("Pending = %+v, want one digest of two products", pending)
+	}
+	if items := pending[0].Items; items[0].ProductID != "prod_a" || items[0].Price != 2900 || items[1].ProductID != "prod_b" {
+		t.Errorf("Digest items = %+v, want the latest prod_a alert then prod_b", items)
+	}
+	if n := d.Dispatch(ctx); n != 1 || len(channel.delivered) != 2 {
+		t.Errorf("Dispatch processed %d, delivered %v; want the digest delivered", n, channel.delivered)
+	}
+	if n := g.Generate(ctx); n != 0 {
+		t.Errorf("Second Generate queued %d digests, want 0", n)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestDigests_EndToEnd", true)
+}
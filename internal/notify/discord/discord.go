@@ -0,0 +1,392 @@
+// Package discord posts price alerts to Discord channels through webhooks,
+// as rich embeds with the product image, the old and new price and the
+// price per gram of protein. Fitness community servers use them to share
+// deals in a channel.
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+	"github.com/yourusername/whey-price-compare/internal/notify/templates"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Config configures the Sender.
+type Config struct {
+	// SiteURL is the public site root that links in messages point at.
+	SiteURL string
+	// Hosts are the hosts webhook URLs may be on.
+	Hosts []string
+	// Username and AvatarURL override the webhook's own name and avatar
+	// on alerts when set.
+	Username  string
+	AvatarURL string
+	// Timeout bounds each request to Discord.
+	Timeout time.Duration
+}
+
+// DefaultConfig accepts webhooks on Discord's stable, PTB and Canary hosts
+// and posts as "Whey Price Compare".
+func DefaultConfig() Config {
+	return Config{
+		Hosts:    []string{"discord.com", "discordapp.com", "ptb.discord.com", "canary.discord.com"},
+		Username: "Whey Price Compare",
+		Timeout:  10 * time.Second,
+	}
+}
+
+// errGone means Discord no longer knows the webhook.
+var errGone = errors.New("discord webhook deleted")
+
+// Sender stores users' webhooks and implements notify.Channel.
+type Sender struct {
+	cfg       Config
+	repo      repositories.DiscordRepository
+	templates *templates.Engine
+	client    *http.Client
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewSender creates a Sender.
+func NewSender(cfg Config, repo repositories.DiscordRepository, logger *zap.Logger) *Sender {
+	def := DefaultConfig()
+	if len(cfg.Hosts) == 0 {
+		cfg.Hosts = def.Hosts
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	cfg.SiteURL = strings.TrimRight(cfg.SiteURL, "/")
+	return &Sender{
+		cfg:       cfg,
+		repo:      repo,
+		templates: templates.Default(),
+		client:    &http.Client{Timeout: cfg.Timeout},
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// WithTemplates words alerts with e rather than the latest built-in
+// templates. It returns s.
+func (s *Sender) WithTemplates(e *templates.Engine) *Sender {
+	s.templates = e
+	return s
+}
+
+// Connect checks rawURL is a live Discord webhook and saves it as userID's,
+// replacing any other. Bad or deleted webhooks are ErrInvalid.
+func (s *Sender) Connect(ctx context.Context, userID, rawURL string) (*domain.DiscordWebhook, error) {
+	webhookURL, err := s.checkURL(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", err, domain.ErrInvalid)
+	}
+	info, err := s.lookup(ctx, webhookURL)
+	if errors.Is(err, errGone) {
+		return nil, fmt.Errorf("discord does not know this webhook: %w", domain.ErrInvalid)
+	}
+	if err != nil {
+		return nil, err
+	}
+	w := domain.DiscordWebhook{
+		UserID:    userID,
+		URL:       webhookURL,
+		WebhookID: info.ID,
+		Name:      info.Name,
+		GuildID:   info.GuildID,
+		ChannelID: info.ChannelID,
+		CreatedAt: s.now().UTC(),
+	}
+	if err := s.repo.SaveDiscordWebhook(ctx, w); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Discord webhook connected",
+		zap.String("operation", "DiscordConnect"),
+		zap.String("user_id", userID),
+		zap.String("webhook_id", w.WebhookID),
+	)
+	return &w, nil
+}
+
+// Webhook returns userID's webhook, or domain.ErrNotFound.
+func (s *Sender) Webhook(ctx context.Context, userID string) (*domain.DiscordWebhook, error) {
+	return s.repo.DiscordWebhook(ctx, userID)
+}
+
+// Disconnect forgets userID's webhook.
+func (s *Sender) Disconnect(ctx context.Context, userID string) error {
+	return s.repo.DeleteDiscordWebhook(ctx, userID)
+}
+
+// Connected reports whether userID has a webhook. It fits
+// alerts.ReachableFunc.
+func (s *Sender) Connected(ctx context.Context, userID string) (bool, error) {
+	_, err := s.repo.DiscordWebhook(ctx, userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// checkURL accepts https webhook execute URLs on the configured hosts,
+// returning them without query or fragment.
+func (s *Sender) checkURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return "", errors.New("webhook_url must be an https URL")
+	}
+	known := false
+	for _, h := range s.cfg.Hosts {
+		if u.Hostname() == h {
+			known = true
+			break
+		}
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	// /api/webhooks/{id}/{token}, optionally with an API version after api.
+	if len(parts) == 5 && strings.HasPrefix(parts[1], "v") {
+		parts = append(parts[:1], parts[2:]...)
+	}
+	if !known || len(parts) != 4 || parts[0] != "api" || parts[1] != "webhooks" || parts[2] == "" || parts[3] == "" {
+		return "", errors.New("webhook_url is not a Discord webhook URL")
+	}
+	return u.Scheme + "://" + u.Host + "/api/webhooks/" + parts[2] + "/" + parts[3], nil
+}
+
+// webhookInfo is the part of Discord's webhook object Connect records.
+type webhookInfo struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	GuildID   string `json:"guild_id"`
+	ChannelID string `json:"channel_id"`
+}
+
+func (s *Sender) lookup(ctx context.Context, webhookURL string) (webhookInfo, error) {
+	var info webhookInfo
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, webhookURL, nil)
+	if err != nil {
+		return info, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return info, err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&info); err != nil {
+		return info, fmt.Errorf("decode discord webhook: %w", err)
+	}
+	return info, nil
+}
+
+// Name implements notify.Channel.
+func (s *Sender) Name() string { return domain.ChannelDiscord }
+
+// Deliver implements notify.Channel. Users without a webhook are
+// unreachable, as are those whose webhook was deleted in Discord; those
+// webhooks are forgotten.
+func (s *Sender) Deliver(ctx context.Context, u domain.User, n domain.Notification) error {
+	var msg message
+	var err error
+	switch n.Type {
+	case domain.NotificationPriceAlert:
+		var e embed
+		e, err = s.alertEmbed(n)
+		msg.Embeds = []embed{e}
+	case domain.NotificationPriceAlertDigest:
+		msg, err = s.digestMessage(n.Items)
+	default:
+		return fmt.Errorf("no discord message for notification type %q", n.Type)
+	}
+	if err != nil {
+		return err
+	}
+	w, err := s.repo.DiscordWebhook(ctx, u.ID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return notify.ErrUnreachable
+	}
+	if err != nil {
+		return fmt.Errorf("load discord webhook: %w", err)
+	}
+	msg.Username, msg.AvatarURL = s.cfg.Username, s.cfg.AvatarURL
+	// Alerts should not ping anyone in the server.
+	msg.AllowedMentions = &allowedMentions{Parse: []string{}}
+
+	err = s.post(ctx, w.URL, msg)
+	if errors.Is(err, errGone) {
+		if err := s.repo.DeleteDiscordWebhook(ctx, u.ID); err != nil {
+			return fmt.Errorf("forget deleted discord webhook: %w", err)
+		}
+		s.logger.Info("Discord webhook deleted, forgotten",
+			zap.String("operation", "DiscordDeliver"),
+			zap.String("user_id", u.ID),
+			zap.String("webhook_id", w.WebhookID),
+		)
+		return notify.ErrUnreachable
+	}
+	return err
+}
+
+// message is the body of Discord's execute webhook request.
+type message struct {
+	Username        string           `json:"username,omitempty"`
+	AvatarURL       string           `json:"avatar_url,omitempty"`
+	Content         string           `json:"content,omitempty"`
+	Embeds          []embed          `json:"embeds"`
+	AllowedMentions *allowedMentions `json:"allowed_mentions,omitempty"`
+}
+
+type allowedMentions struct {
+	Parse []string `json:"parse"`
+}
+
+type embed struct {
+	Title       string       `json:"title"`
+	URL         string       `json:"url,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Color       int          `json:"color"`
+	Thumbnail   *embedImage  `json:"thumbnail,omitempty"`
+	Fields      []embedField `json:"fields,omitempty"`
+	Footer      *embedFooter `json:"footer,omitempty"`
+	Timestamp   string       `json:"timestamp,omitempty"`
+}
+
+type embedImage struct {
+	URL string `json:"url"`
+}
+
+type embedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type embedFooter struct {
+	Text string `json:"text"`
+}
+
+// Embed colours: a drop is green, anything else the site's blue.
+const (
+	colorDrop  = 0x2e7d32
+	colorAlert = 0x1a73e8
+)
+
+// maxDigestEmbeds is the most embeds Discord accepts in one message.
+const maxDigestEmbeds = 10
+
+// alertEmbed describes one alert: the product, with its image, the new
+// price against the old, and the price per gram of protein. The
+// description is the alert template's title.
+func (s *Sender) alertEmbed(n domain.Notification) (embed, error) {
+	heading, err := s.templates.Render(templates.PriceAlert, templates.Markdown, templates.NewAlert(n, s.cfg.SiteURL))
+	if err != nil {
+		return embed{}, err
+	}
+	loc := i18n.Default()
+	price := func(v float64) string { return loc.FormatPrice(domain.DefaultCurrency, v) }
+
+	e := embed{
+		Title:       n.ProductName,
+		URL:         s.link(n.URL),
+		Description: heading.Title,
+		Color:       colorAlert,
+		Fields:      []embedField{{Name: "Price", Value: price(n.Price) + " at " + n.RetailerName, Inline: true}},
+		Footer:      &embedFooter{Text: "Whey Price Compare"},
+	}
+	if n.PreviousPrice > n.Price {
+		e.Color = colorDrop
+		e.Fields = append(e.Fields, embedField{Name: "Was", Value: "~~" + price(n.PreviousPrice) + "~~", Inline: true})
+	}
+	if n.PricePerGram > 0 {
+		e.Fields = append(e.Fields, embedField{Name: "₹/g protein", Value: price(n.PricePerGram), Inline: true})
+	}
+	if img := s.link(n.ImageURL); strings.HasPrefix(img, "https://") {
+		e.Thumbnail = &embedImage{URL: img}
+	}
+	if !n.CreatedAt.IsZero() {
+		e.Timestamp = n.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	return e, nil
+}
+
+// digestMessage puts each item in its own embed, up to Discord's limit.
+func (s *Sender) digestMessage(items []domain.Notification) (message, error) {
+	heading, err := s.templates.Render(templates.PriceAlertDigest, templates.Markdown, templates.NewDigest(items, maxDigestEmbeds, s.cfg.SiteURL))
+	if err != nil {
+		return message{}, err
+	}
+	msg := message{Content: heading.Title}
+	for _, item := range items[:min(len(items), maxDigestEmbeds)] {
+		e, err := s.alertEmbed(item)
+		if err != nil {
+			return message{}, err
+		}
+		msg.Embeds = append(msg.Embeds, e)
+	}
+	return msg, nil
+}
+
+func (s *Sender) link(path string) string {
+	if strings.HasPrefix(path, "/") {
+		return s.cfg.SiteURL + path
+	}
+	return path
+}
+
+func (s *Sender) post(ctx context.Context, webhookURL string, msg message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do sends req, mapping Discord's refusals to errors. Unknown or revoked
+// webhooks are errGone.
+func (s *Sender) do(req *http.Request) (*http.Response, error) {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// The webhook URL carries its token; keep it out of errors and
+		// so out of logs.
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return nil, fmt.Errorf("discord: %w", err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusNotFound:
+		return nil, errGone
+	default:
+		return nil, fmt.Errorf("discord returned %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,201 @@
+package discord
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// fakeDiscord serves the webhooks in hooks by ID, answering others with
+// 404, and records the messages posted to them.
+type fakeDiscord struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	hooks  map[string]bool
+	posted []message
+}
+
+func newFakeDiscord(t *testing.T, ids ...string) *fakeDiscord {
+	t.Helper()
+	f := &fakeDiscord{hooks: map[string]bool{}}
+	for _, id := range ids {
+		f.hooks[id] = true
+	}
+	f.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		// /api/webhooks/{id}/{token}
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if len(parts) != 4 || !f.hooks[parts[2]] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			_ = json.NewEncoder(w).Encode(webhookInfo{ID: parts[2], Name: "Deals bot", GuildID: "guild_1", ChannelID: "chan_1"})
+		case http.MethodPost:
+			body, _ := io.ReadAll(r.Body)
+			var m message
+			if err := json.Unmarshal(body, &m); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			f.posted = append(f.posted, m)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeDiscord) remove(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.hooks, id)
+}
+
+func (f *fakeDiscord) hookURL(id string) string {
+	return f.URL + "/api/webhooks/" + id + "/test-only-token"
+}
+
+func newTestSender(t *testing.T, f *fakeDiscord) (*Sender, *memory.Store) {
+	t.Helper()
+	store := memory.NewStore()
+	s := NewSender(Config{SiteURL: "https://whey.example/", Hosts: []string{"127.0.0.1"}, Username: "Whey Price Compare"},
+		store.Discord(), testhelpers.SetupTestLogger(t))
+	s.client = f.Client()
+	return s, store
+}
+
+func TestSender_Connect(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSender_Connect", "internal/notify/discord")
+
+	f := newFakeDiscord(t, "123")
+	s, _ := newTestSender(t, f)
+	testCases := []struct {
+		name    string
+		url     string
+		wantErr error
+	}{
+		{"Webhook", f.hookURL("123"), nil},
+		{"Versioned API path", strings.Replace(f.hookURL("123"), "/api/", "/api/v10/", 1), nil},
+		{"Deleted webhook", f.hookURL("456"), domain.ErrInvalid},
+		{"Not https", strings.Replace(f.hookURL("123"), "https://", "http://", 1), domain.ErrInvalid},
+		{"Other host", "https://example.com/api/webhooks/123/test-only-token", domain.ErrInvalid},
+		{"Not a webhook path", f.URL + "/api/channels/123", domain.ErrInvalid},
+		{"Empty", "", domain.ErrInvalid},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := s.Connect(t.Context(), "user_1", tc.url)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("Connect error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Connect: %v", err)
+			}
+			if w.WebhookID != "123" || w.Name != "Deals bot" || w.URL != f.hookURL("123") {
+				t.Errorf("Webhook = %+v", w)
+			}
+		})
+	}
+	if ok, err := s.Connected(t.Context(), "user_1"); !ok || err != nil {
+		t.Errorf("Connected = %v, %v; want true", ok, err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSender_Connect", true)
+}
+
+func TestSender_Deliver(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSender_Deliver", "internal/notify/discord")
+
+	testhelpers.LogTestStep(logger, "arrange", "A user with a webhook and one without")
+	f := newFakeDiscord(t, "123")
+	s, _ := newTestSender(t, f)
+	ctx := t.Context()
+	if _, err := s.Connect(ctx, "user_1", f.hookURL("123")); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	n := domain.Notification{
+		Type: domain.NotificationPriceAlert, UserID: "user_1",
+		ProductName: "Gold Standard 100% Whey", ImageURL: "https://cdn.example/gsw.jpg",
+		RetailerName: "Flipkart", Price: 2899, PreviousPrice: 3199, PricePerGram: 1.62,
+		URL: "/go/flipkart/prod_on_gsw/lst_1", CreatedAt: time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC),
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Delivering an alert")
+	if err := s.Deliver(ctx, domain.User{ID: "user_1"}, n); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	if len(f.posted) != 1 || len(f.posted[0].Embeds) != 1 {
+		t.Fatalf("Posted = %+v, want one embed", f.posted)
+	}
+	m := f.posted[0]
+	e := m.Embeds[0]
+	testhelpers.LogTestAssertion(logger, "embed title", n.ProductName, e.Title)
+	if e.Title != n.ProductName || e.URL != "https://whey.example/go/flipkart/prod_on_gsw/lst_1" || e.Color != colorDrop {
+		t.Errorf("Embed = %+v", e)
+	}
+	if e.Thumbnail == nil || e.Thumbnail.URL != n.ImageURL {
+		t.Errorf("Thumbnail = %+v, want the product image", e.Thumbnail)
+	}
+	want := []embedField{
+		{Name: "Price", Value: "₹2,899 at Flipkart", Inline: true},
+		{Name: "Was", Value: "~~₹3,199~~", Inline: true},
+		{Name: "₹/g protein", Value: "₹1.62", Inline: true},
+	}
+	if len(e.Fields) != len(want) {
+		t.Fatalf("Fields = %+v, want %+v", e.Fields, want)
+	}
+	for i := range want {
+		if e.Fields[i] != want[i] {
+			t.Errorf("Field %d = %+v, want %+v", i, e.Fields[i], want[i])
+		}
+	}
+	if m.Username != "Whey Price Compare" || m.AllowedMentions == nil || len(m.AllowedMentions.Parse) != 0 {
+		t.Errorf("Message = %+v, want our name and no mentions", m)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Delivering a digest and to a user without a webhook")
+	digest := domain.Notification{Type: domain.NotificationPriceAlertDigest, UserID: "user_1", Items: []domain.Notification{n, n}}
+	if err := s.Deliver(ctx, domain.User{ID: "user_1"}, digest); err != nil {
+		t.Fatalf("Deliver digest: %v", err)
+	}
+	if got := f.posted[1]; len(got.Embeds) != 2 || !strings.Contains(got.Content, "2 price alerts") {
+		t.Errorf("Digest = %+v, want two embeds", got)
+	}
+	if err := s.Deliver(ctx, domain.User{ID: "user_2"}, n); !errors.Is(err, notify.ErrUnreachable) {
+		t.Errorf("Deliver without webhook = %v, want ErrUnreachable", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Delivering after the webhook was deleted in Discord")
+	f.remove("123")
+	err := s.Deliver(ctx, domain.User{ID: "user_1"}, n)
+	testhelpers.LogTestAssertion(logger, "deleted webhook", notify.ErrUnreachable, err)
+	if !errors.Is(err, notify.ErrUnreachable) {
+		t.Errorf("Deliver to deleted webhook = %v, want ErrUnreachable", err)
+	}
+	if ok, _ := s.Connected(ctx, "user_1"); ok {
+		t.Error("Deleted webhook was not forgotten")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSender_Deliver", true)
+}
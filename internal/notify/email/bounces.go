@@ -0,0 +1,334 @@
+package email
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// BouncePath receives SES bounce and complaint events through Amazon SNS.
+const BouncePath = "/api/v1/email/sns"
+
+const maxSNSBodyBytes = 256 << 10
+
+// snsHost matches the hosts SNS signing certificates and subscription
+// links are served from.
+var snsHost = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// BounceConfig configures the BounceHandler.
+type BounceConfig struct {
+	// TopicARNs lists the SNS topics events are accepted from; messages
+	// from any other topic are rejected even when correctly signed.
+	TopicARNs []string
+}
+
+// BounceHandler suppresses addresses that SES reports as hard-bounced or
+// as having marked a message as spam. Every message's SNS signature is
+// verified, and subscriptions to the configured topics are confirmed
+// automatically.
+type BounceHandler struct {
+	cfg          BounceConfig
+	suppressions repositories.SuppressionRepository
+	client       *http.Client
+	logger       *zap.Logger
+	now          func() time.Time
+
+	mu    sync.Mutex
+	certs map[string]*x509.Certificate // by URL
+}
+
+// NewBounceHandler creates a BounceHandler.
+func NewBounceHandler(cfg BounceConfig, suppressions repositories.SuppressionRepository, logger *zap.Logger) *BounceHandler {
+	return &BounceHandler{
+		cfg:          cfg,
+		suppressions: suppressions,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		logger:       logger,
+		now:          time.Now,
+		certs:        make(map[string]*x509.Certificate),
+	}
+}
+
+// Register mounts the SNS endpoint on mux.
+func (h *BounceHandler) Register(mux *http.ServeMux) {
+	mux.Handle("POST "+BouncePath, h)
+}
+
+// snsMessage is an SNS HTTP(S) delivery.
+type snsMessage struct {
+	Type             string
+	MessageID        string `json:"MessageId"`
+	Token            string
+	TopicArn         string
+	Subject          string
+	Message          string
+	Timestamp        string
+	SignatureVersion string
+	Signature        string
+	SigningCertURL   string
+	SubscribeURL     string
+}
+
+// ServeHTTP handles one SNS delivery.
+func (h *BounceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSNSBodyBytes))
+	if err != nil {
+		httpx.WriteError(w, r, http.StatusRequestEntityTooLarge, httpx.CodeBadRequest, "Body too large", nil)
+		return
+	}
+	var msg snsMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, "Invalid SNS message", nil)
+		return
+	}
+	if !slices.Contains(h.cfg.TopicARNs, msg.TopicArn) {
+		httpx.WriteError(w, r, http.StatusForbidden, httpx.CodeForbidden, "Unknown topic", nil)
+		return
+	}
+	if err := h.verify(r.Context(), msg); err != nil {
+		h.logger.Warn("Rejected SNS message",
+			zap.String("operation", "EmailBounces"),
+			zap.String("message_id", msg.MessageID),
+			zap.Error(err),
+		)
+		httpx.WriteError(w, r, http.StatusForbidden, httpx.CodeForbidden, "Invalid signature", nil)
+		return
+	}
+
+	switch msg.Type {
+	case "SubscriptionConfirmation":
+		err = h.confirm(r.Context(), msg)
+	case "Notification":
+		err = h.handleEvent(r.Context(), msg)
+	case "UnsubscribeConfirmation":
+		h.logger.Warn("Unsubscribed from bounce topic", zap.String("operation", "EmailBounces"), zap.String("topic", msg.TopicArn))
+	}
+	if err != nil {
+		h.logger.Error("SNS message failed",
+			zap.String("operation", "EmailBounces"),
+			zap.String("type", msg.Type),
+			zap.String("message_id", msg.MessageID),
+			zap.Error(err),
+		)
+		// SNS redelivers on server errors.
+		httpx.WriteError(w, r, http.StatusInternalServerError, httpx.CodeInternal, "Processing failed", nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// verify checks msg's signature against its SNS signing certificate.
+func (h *BounceHandler) verify(ctx context.Context, msg snsMessage) error {
+	var hash crypto.Hash
+	switch msg.SignatureVersion {
+	case "1":
+		hash = crypto.SHA1
+	case "2":
+		hash = crypto.SHA256
+	default:
+		return fmt.Errorf("unsupported signature version %q", msg.SignatureVersion)
+	}
+	if err := checkSNSURL(msg.SigningCertURL); err != nil {
+		return fmt.Errorf("signing certificate: %w", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(msg.Signature)
+	if err != nil {
+		return fmt.Errorf("decode signature: %w", err)
+	}
+	cert, err := h.cert(ctx, msg.SigningCertURL)
+	if err != nil {
+		return err
+	}
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return errors.New("signing certificate is not RSA")
+	}
+
+	var digest []byte
+	if hash == crypto.SHA1 {
+		sum := sha1.Sum([]byte(stringToSign(msg)))
+		digest = sum[:]
+	} else {
+		sum := sha256.Sum256([]byte(stringToSign(msg)))
+		digest = sum[:]
+	}
+	return rsa.VerifyPKCS1v15(key, hash, digest, sig)
+}
+
+// stringToSign builds the canonical form SNS signs.
+func stringToSign(msg snsMessage) string {
+	fields := [][2]string{{"Message", msg.Message}, {"MessageId", msg.MessageID}}
+	if msg.Type == "Notification" {
+		if msg.Subject != "" {
+			fields = append(fields, [2]string{"Subject", msg.Subject})
+		}
+		fields = append(fields, [2]string{"Timestamp", msg.Timestamp}, [2]string{"TopicArn", msg.TopicArn})
+	} else {
+		fields = append(fields,
+			[2]string{"SubscribeURL", msg.SubscribeURL},
+			[2]string{"Timestamp", msg.Timestamp},
+			[2]string{"Token", msg.Token},
+			[2]string{"TopicArn", msg.TopicArn},
+		)
+	}
+	fields = append(fields, [2]string{"Type", msg.Type})
+	var b strings.Builder
+	for _, f := range fields {
+		b.WriteString(f[0] + "\n" + f[1] + "\n")
+	}
+	return b.String()
+}
+
+// checkSNSURL rejects URLs that are not HTTPS links to SNS, so a forged
+// message cannot point the handler at a certificate or link of its own.
+func checkSNSURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" || !snsHost.MatchString(u.Hostname()) || u.Port() != "" {
+		return fmt.Errorf("%q is not an SNS URL", raw)
+	}
+	return nil
+}
+
+// cert returns the certificate at rawURL, fetching it on first use.
+func (h *BounceHandler) cert(ctx context.Context, rawURL string) (*x509.Certificate, error) {
+	h.mu.Lock()
+	cert, ok := h.certs[rawURL]
+	h.mu.Unlock()
+	if ok {
+		return cert, nil
+	}
+
+	raw, err := h.get(ctx, rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetch signing certificate: %w", err)
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("signing certificate is not PEM")
+	}
+	cert, err = x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse signing certificate: %w", err)
+	}
+	if now := h.now(); now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return nil, errors.New("signing certificate is not currently valid")
+	}
+
+	h.mu.Lock()
+	h.certs[rawURL] = cert
+	h.mu.Unlock()
+	return cert, nil
+}
+
+// confirm subscribes to the topic by visiting the confirmation link.
+func (h *BounceHandler) confirm(ctx context.Context, msg snsMessage) error {
+	if err := checkSNSURL(msg.SubscribeURL); err != nil {
+		return fmt.Errorf("subscribe URL: %w", err)
+	}
+	if _, err := h.get(ctx, msg.SubscribeURL); err != nil {
+		return fmt.Errorf("confirm subscription: %w", err)
+	}
+	h.logger.Info("Subscribed to bounce topic", zap.String("operation", "EmailBounces"), zap.String("topic", msg.TopicArn))
+	return nil
+}
+
+func (h *BounceHandler) get(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+}
+
+type sesRecipient struct {
+	EmailAddress string `json:"emailAddress"`
+}
+
+// sesEvent is a bounce or complaint, in either the notification format
+// (notificationType) or the event publishing format (eventType).
+type sesEvent struct {
+	NotificationType string `json:"notificationType"`
+	EventType        string `json:"eventType"`
+	Bounce           struct {
+		BounceType        string         `json:"bounceType"`
+		BouncedRecipients []sesRecipient `json:"bouncedRecipients"`
+	} `json:"bounce"`
+	Complaint struct {
+		ComplainedRecipients []sesRecipient `json:"complainedRecipients"`
+	} `json:"complaint"`
+}
+
+// handleEvent suppresses the recipients of a permanent bounce or a
+// complaint. Transient bounces, such as full mailboxes, are ignored.
+func (h *BounceHandler) handleEvent(ctx context.Context, msg snsMessage) error {
+	var ev sesEvent
+	if err := json.Unmarshal([]byte(msg.Message), &ev); err != nil {
+		// Not an SES event; redelivering will not help.
+		h.logger.Warn("Ignoring unrecognised SNS notification", zap.String("operation", "EmailBounces"), zap.String("message_id", msg.MessageID))
+		return nil
+	}
+	kind := ev.NotificationType
+	if kind == "" {
+		kind = ev.EventType
+	}
+
+	var reason string
+	var recipients []sesRecipient
+	switch {
+	case kind == "Bounce" && ev.Bounce.BounceType == "Permanent":
+		reason, recipients = domain.SuppressionBounce, ev.Bounce.BouncedRecipients
+	case kind == "Complaint":
+		reason, recipients = domain.SuppressionComplaint, ev.Complaint.ComplainedRecipients
+	default:
+		return nil
+	}
+	now := h.now().UTC()
+	for _, rcpt := range recipients {
+		addr := normalizeAddress(rcpt.EmailAddress)
+		if addr == "" {
+			continue
+		}
+		if err := h.suppressions.Suppress(ctx, domain.Suppression{Email: addr, Reason: reason, CreatedAt: now}); err != nil {
+			return fmt.Errorf("suppress address: %w", err)
+		}
+	}
+	h.logger.Info("Suppressed email recipients",
+		zap.String("operation", "EmailBounces"),
+		zap.String("reason", reason),
+		zap.Int("recipients", len(recipients)),
+	)
+	return nil
+}
@@ -0,0 +1,229 @@
+package email
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+const (
+	testTopic   = "arn:aws:sns:ap-south-1:123456789012:ses-events"
+	testCertURL = "https://sns.ap-south-1.amazonaws.com/SimpleNotificationService-test.pem"
+)
+
+// fakeSNS signs messages like SNS and serves its certificate and
+// subscription links to the handler.
+type fakeSNS struct {
+	key     *rsa.PrivateKey
+	certPEM []byte
+
+	mu        sync.Mutex
+	requested []string
+}
+
+func newFakeSNS(t *testing.T) *fakeSNS {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.amazonaws.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	return &fakeSNS{key: key, certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func (f *fakeSNS) RoundTrip(r *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	f.requested = append(f.requested, r.URL.String())
+	f.mu.Unlock()
+	body := []byte("<ConfirmSubscriptionResponse/>")
+	if r.URL.String() == testCertURL {
+		body = f.certPEM
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body)), Header: http.Header{}}, nil
+}
+
+func (f *fakeSNS) sign(t *testing.T, msg snsMessage) []byte {
+	t.Helper()
+	msg.TopicArn = testTopic
+	msg.SigningCertURL = testCertURL
+	if msg.SignatureVersion == "" {
+		msg.SignatureVersion = "2"
+	}
+	var sig []byte
+	var err error
+	if msg.SignatureVersion == "1" {
+		sum := sha1.Sum([]byte(stringToSign(msg)))
+		sig, err = rsa.SignPKCS1v15(rand.Reader, f.key, crypto.SHA1, sum[:])
+	} else {
+		sum := sha256.Sum256([]byte(stringToSign(msg)))
+		sig, err = rsa.SignPKCS1v15(rand.Reader, f.key, crypto.SHA256, sum[:])
+	}
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	msg.Signature = base64.StdEncoding.EncodeToString(sig)
+	body, _ := json.Marshal(msg)
+	return body
+}
+
+func newTestBounceHandler(t *testing.T) (*BounceHandler, *fakeSNS, *memory.Store) {
+	t.Helper()
+	store := memory.NewStore()
+	sns := newFakeSNS(t)
+	h := NewBounceHandler(BounceConfig{TopicARNs: []string{testTopic}}, store.Suppressions(), testhelpers.SetupTestLogger(t))
+	h.client = &http.Client{Transport: sns}
+	return h, sns, store
+}
+
+func postSNS(h http.Handler, body []byte) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, BouncePath, bytes.NewReader(body)))
+	return rec
+}
+
+func TestBounceHandler_Events(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestBounceHandler_Events", "internal/notify/email")
+
+	testCases := []struct {
+		name       string
+		version    string
+		event      string
+		wantReason string
+	}{
+		{"Permanent bounce", "2", `{"notificationType":"Bounce","bounce":{"bounceType":"Permanent","bouncedRecipients":[{"emailAddress":"Asha@Example.com"}]}}`, domain.SuppressionBounce},
+		{"Complaint, event publishing format", "1", `{"eventType":"Complaint","complaint":{"complainedRecipients":[{"emailAddress":"asha@example.com"}]}}`, domain.SuppressionComplaint},
+		{"Transient bounce", "2", `{"notificationType":"Bounce","bounce":{"bounceType":"Transient","bouncedRecipients":[{"emailAddress":"asha@example.com"}]}}`, ""},
+		{"Delivery", "2", `{"notificationType":"Delivery"}`, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, sns, store := newTestBounceHandler(t)
+			body := sns.sign(t, snsMessage{
+				Type: "Notification", MessageID: "msg-1", Message: tc.event,
+				Timestamp: "2026-10-16T09:30:00.000Z", SignatureVersion: tc.version,
+			})
+			rec := postSNS(h, body)
+			testhelpers.LogTestAssertion(logger, tc.name, http.StatusNoContent, rec.Code)
+			if rec.Code != http.StatusNoContent {
+				t.Fatalf("Status = %d: %s", rec.Code, rec.Body)
+			}
+			sup, err := store.Suppressions().Suppression(t.Context(), "asha@example.com")
+			if tc.wantReason == "" {
+				if !errors.Is(err, domain.ErrNotFound) {
+					t.Errorf("Suppression = %+v, %v, want none", sup, err)
+				}
+				return
+			}
+			if err != nil || sup.Reason != tc.wantReason {
+				t.Errorf("Suppression = %+v, %v, want reason %q", sup, err, tc.wantReason)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestBounceHandler_Events", true)
+}
+
+func TestBounceHandler_RejectsForgeries(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestBounceHandler_RejectsForgeries", "internal/notify/email")
+
+	event := `{"notificationType":"Complaint","complaint":{"complainedRecipients":[{"emailAddress":"asha@example.com"}]}}`
+	testCases := []struct {
+		name   string
+		tamper func(h *BounceHandler, body []byte) []byte
+	}{
+		{"Altered message", func(_ *BounceHandler, body []byte) []byte {
+			return bytes.Replace(body, []byte("asha@example.com"), []byte("ravi@example.com"), 1)
+		}},
+		{"Unknown topic", func(_ *BounceHandler, body []byte) []byte {
+			return bytes.Replace(body, []byte(testTopic), []byte(testTopic+"-other"), 1)
+		}},
+		{"Certificate off SNS", func(_ *BounceHandler, body []byte) []byte {
+			return bytes.Replace(body, []byte("sns.ap-south-1.amazonaws.com"), []byte("sns.ap-south-1.amazonaws.com.evil.example"), 1)
+		}},
+		{"Signed by another key", func(h *BounceHandler, body []byte) []byte {
+			h.client = &http.Client{Transport: newFakeSNS(t)}
+			return body
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, sns, store := newTestBounceHandler(t)
+			body := sns.sign(t, snsMessage{Type: "Notification", MessageID: "msg-1", Message: event, Timestamp: "2026-10-16T09:30:00.000Z"})
+			rec := postSNS(h, tc.tamper(h, body))
+			testhelpers.LogTestAssertion(logger, tc.name, http.StatusForbidden, rec.Code)
+			if rec.Code != http.StatusForbidden {
+				t.Errorf("Status = %d, want 403", rec.Code)
+			}
+			if _, err := store.Suppressions().Suppression(t.Context(), "ravi@example.com"); !errors.Is(err, domain.ErrNotFound) {
+				t.Error("Forged message suppressed an address")
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestBounceHandler_RejectsForgeries", true)
+}
+
+func TestBounceHandler_ConfirmsSubscription(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestBounceHandler_ConfirmsSubscription", "internal/notify/email")
+
+	testCases := []struct {
+		name        string
+		subscribe   string
+		wantStatus  int
+		wantVisited bool
+	}{
+		{"SNS link", "https://sns.ap-south-1.amazonaws.com/?Action=ConfirmSubscription&Token=abc", http.StatusNoContent, true},
+		{"Foreign link", "https://evil.example/?Action=ConfirmSubscription", http.StatusInternalServerError, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, sns, _ := newTestBounceHandler(t)
+			body := sns.sign(t, snsMessage{
+				Type: "SubscriptionConfirmation", MessageID: "msg-1", Token: "abc",
+				Message: "You have chosen to subscribe", SubscribeURL: tc.subscribe,
+				Timestamp: "2026-10-16T09:30:00.000Z",
+			})
+			rec := postSNS(h, body)
+			visited := strings.Contains(strings.Join(sns.requested, " "), tc.subscribe)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantVisited, visited)
+			if rec.Code != tc.wantStatus || visited != tc.wantVisited {
+				t.Errorf("Status = %d, visited %v; want %d, %v", rec.Code, visited, tc.wantStatus, tc.wantVisited)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestBounceHandler_ConfirmsSubscription", true)
+}
@@ -0,0 +1,256 @@
+// Package email sends transactional email: account verification links,
+// alert confirmations and price alerts. Messages are rendered from
+// templates and handed to a Provider (SMTP or Amazon SES); transient
+// failures are retried, and addresses that bounce or complain are
+// suppressed.
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+	"github.com/yourusername/whey-price-compare/internal/notify/templates"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// ErrSuppressed is returned when sending to an address that has bounced or
+// complained.
+var ErrSuppressed = fmt.Errorf("address is suppressed: %w", notify.ErrUnreachable)
+
+// Message is one rendered email.
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	// Headers are extra header fields, such as List-Unsubscribe.
+	Headers map[string]string
+}
+
+// Provider hands messages to a mail service.
+type Provider interface {
+	Send(ctx context.Context, m Message) error
+}
+
+// SendError is a failed Send. Retryable failures (network errors,
+// throttling, temporary server errors) are retried by the Sender.
+type SendError struct {
+	Err       error
+	Retryable bool
+}
+
+func (e *SendError) Error() string { return e.Err.Error() }
+func (e *SendError) Unwrap() error { return e.Err }
+
+// Config configures the Sender.
+type Config struct {
+	// From is the sender address, e.g. "Whey Price Compare <alerts@example.com>".
+	From string
+	// BaseURL is the public site root that links in messages point at.
+	BaseURL string
+	// MaxAttempts bounds tries per message after retryable failures.
+	MaxAttempts int
+	// Backoff is the wait before the first retry; it doubles after each.
+	Backoff time.Duration
+}
+
+// DefaultConfig tries each message three times, waiting one then two
+// seconds between attempts.
+func DefaultConfig() Config {
+	return Config{MaxAttempts: 3, Backoff: time.Second}
+}
+
+// Sender renders and sends messages. It implements auth.VerificationSender,
+// alerts.ConfirmationSender and notify.Channel.
+type Sender struct {
+	cfg          Config
+	provider     Provider
+	suppressions repositories.SuppressionRepository
+	prefs        *notify.Preferences
+	templates    *templates.Engine
+	logger       *zap.Logger
+	sleep        func(ctx context.Context, d time.Duration) error
+}
+
+// NewSender creates a Sender delivering through provider.
+func NewSender(cfg Config, provider Provider, suppressions repositories.SuppressionRepository, logger *zap.Logger) *Sender {
+	def := DefaultConfig()
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = def.MaxAttempts
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = def.Backoff
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &Sender{
+		cfg:          cfg,
+		provider:     provider,
+		suppressions: suppressions,
+		templates:    templates.Default(),
+		logger:       logger,
+		sleep:        sleepCtx,
+	}
+}
+
+// WithTemplates renders messages with e rather than the latest built-in
+// templates. It returns s.
+func (s *Sender) WithTemplates(e *templates.Engine) *Sender {
+	s.templates = e
+	return s
+}
+
+// WithUnsubscribe adds one-click unsubscribe links signed by prefs to
+// notification emails, in the footer and as List-Unsubscribe headers
+// (RFC 8058). It returns s.
+func (s *Sender) WithUnsubscribe(prefs *notify.Preferences) *Sender {
+	s.prefs = prefs
+	return s
+}
+
+// Send delivers m, retrying retryable failures with exponential backoff.
+// Suppressed addresses return ErrSuppressed without a send.
+func (s *Sender) Send(ctx context.Context, m Message) error {
+	m.To = normalizeAddress(m.To)
+	if m.From == "" {
+		m.From = s.cfg.From
+	}
+	if _, err := s.suppressions.Suppression(ctx, m.To); err == nil {
+		return ErrSuppressed
+	} else if !errors.Is(err, domain.ErrNotFound) {
+		return fmt.Errorf("check suppression: %w", err)
+	}
+
+	wait := s.cfg.Backoff
+	for attempt := 1; ; attempt++ {
+		err := s.provider.Send(ctx, m)
+		if err == nil {
+			return nil
+		}
+		var serr *SendError
+		if !errors.As(err, &serr) || !serr.Retryable || attempt >= s.cfg.MaxAttempts {
+			return err
+		}
+		s.logger.Warn("Email send failed, retrying",
+			zap.String("operation", "SendEmail"),
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", wait),
+			zap.Error(err),
+		)
+		if err := s.sleep(ctx, wait); err != nil {
+			return err
+		}
+		wait *= 2
+	}
+}
+
+// SendVerification emails u a link that verifies their address.
+func (s *Sender) SendVerification(ctx context.Context, u domain.User, token string) error {
+	m, err := s.render(templates.Verification, templates.VerificationData{
+		Name: u.Name,
+		Link: s.cfg.BaseURL + "/api/v1/auth/verify?token=" + url.QueryEscape(token),
+	})
+	if err != nil {
+		return err
+	}
+	m.To = u.Email
+	return s.Send(ctx, m)
+}
+
+// SendAlertConfirmation emails u the link that turns on an alert they set
+// without signing in. Unverified addresses are emailed, as the link is
+// what verifies them.
+func (s *Sender) SendAlertConfirmation(ctx context.Context, u domain.User, productName, condition, link string) error {
+	m, err := s.render(templates.AlertConfirmation, templates.AlertConfirmationData{
+		Name:        u.Name,
+		ProductName: productName,
+		Condition:   condition,
+		Link:        link,
+	})
+	if err != nil {
+		return err
+	}
+	m.To = u.Email
+	return s.Send(ctx, m)
+}
+
+// Name implements notify.Channel.
+func (s *Sender) Name() string { return domain.ChannelEmail }
+
+// Deliver implements notify.Channel. Only verified addresses are emailed.
+func (s *Sender) Deliver(ctx context.Context, u domain.User, n domain.Notification) error {
+	if !u.EmailVerified {
+		return notify.ErrUnreachable
+	}
+	unsubscribe := s.unsubscribeLink(u.ID)
+	var m Message
+	var err error
+	switch n.Type {
+	case domain.NotificationPriceAlert:
+		data := templates.NewAlert(n, s.cfg.BaseURL)
+		data.Name, data.Unsubscribe = u.Name, unsubscribe
+		m, err = s.render(templates.PriceAlert, data)
+	case domain.NotificationPriceAlertDigest:
+		data := templates.NewDigest(n.Items, 0, s.cfg.BaseURL)
+		data.Name, data.Unsubscribe = u.Name, unsubscribe
+		m, err = s.render(templates.PriceAlertDigest, data)
+	default:
+		return fmt.Errorf("no email template for notification type %q", n.Type)
+	}
+	if err != nil {
+		return err
+	}
+	m.To = u.Email
+	if n.Pixel != "" {
+		// The pixel records opens; it sits outside the templates, as it is
+		// no part of the message's wording.
+		img := `<img src="` + html.EscapeString(s.cfg.BaseURL+n.Pixel) + `" width="1" height="1" alt="" style="display:block;border:0">`
+		m.HTML = strings.Replace(m.HTML, "</body>", img+"\n</body>", 1)
+	}
+	if unsubscribe != "" {
+		m.Headers = map[string]string{
+			"List-Unsubscribe":      "<" + unsubscribe + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		}
+	}
+	return s.Send(ctx, m)
+}
+
+// unsubscribeLink returns the page that turns email off for userID, or ""
+// without WithUnsubscribe.
+func (s *Sender) unsubscribeLink(userID string) string {
+	if s.prefs == nil {
+		return ""
+	}
+	token := s.prefs.UnsubscribeToken(userID, domain.ChannelEmail)
+	if token == "" {
+		return ""
+	}
+	return s.cfg.BaseURL + "/unsubscribe?token=" + url.QueryEscape(token)
+}
+
+// normalizeAddress matches how account emails are stored, so suppressions
+// recorded from bounce reports find them.
+func normalizeAddress(addr string) string {
+	return strings.ToLower(strings.TrimSpace(addr))
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,362 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// fakeProvider fails with errs in turn, then succeeds, recording every
+// message it accepts.
+type fakeProvider struct {
+	mu       sync.Mutex
+	errs     []error
+	attempts int
+	sent     []Message
+}
+
+func (p *fakeProvider) Send(_ context.Context, m Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.attempts++
+	if len(p.errs) > 0 {
+		err := p.errs[0]
+		p.errs = p.errs[1:]
+		return err
+	}
+	p.sent = append(p.sent, m)
+	return nil
+}
+
+func newTestSender(t *testing.T, provider Provider) (*Sender, *memory.Store, *[]time.Duration) {
+	t.Helper()
+	store := memory.NewStore()
+	cfg := DefaultConfig()
+	cfg.From = "Whey Price Compare <alerts@example.com>"
+	cfg.BaseURL = "https://wheyprices.example/"
+	s := NewSender(cfg, provider, store.Suppressions(), testhelpers.SetupTestLogger(t))
+	var waits []time.Duration
+	s.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return s, store, &waits
+}
+
+func TestSender_Retries(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSender_Retries", "internal/notify/email")
+
+	transient := &SendError{Err: errors.New("throttled"), Retryable: true}
+	permanent := &SendError{Err: errors.New("mailbox unavailable")}
+	testCases := []struct {
+		name         string
+		errs         []error
+		wantAttempts int
+		wantWaits    []time.Duration
+		wantErr      bool
+	}{
+		{"First try", nil, 1, nil, false},
+		{"Recovers after two transient failures", []error{transient, transient}, 3, []time.Duration{time.Second, 2 * time.Second}, false},
+		{"Gives up after MaxAttempts", []error{transient, transient, transient}, 3, []time.Duration{time.Second, 2 * time.Second}, true},
+		{"Permanent failure is not retried", []error{permanent}, 1, nil, true},
+		{"Unclassified failure is not retried", []error{errors.New("invalid address")}, 1, nil, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := &fakeProvider{errs: tc.errs}
+			s, _, waits := newTestSender(t, provider)
+			err := s.Send(t.Context(), Message{To: "asha@example.com", Subject: "Hi"})
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantAttempts, provider.attempts)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Send error = %v, want error %v", err, tc.wantErr)
+			}
+			if provider.attempts != tc.wantAttempts {
+				t.Errorf("Attempts = %d, want %d", provider.attempts, tc.wantAttempts)
+			}
+			if len(*waits) != len(tc.wantWaits) {
+				t.Fatalf("Waits = %v, want %v", *waits, tc.wantWaits)
+			}
+			for i, d := range tc.wantWaits {
+				if (*waits)[i] != d {
+					t.Errorf("Wait %d = %v, want %v", i, (*waits)[i], d)
+				}
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSender_Retries", true)
+}
+
+func TestSender_Suppressed(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSender_Suppressed", "internal/notify/email")
+
+	provider := &fakeProvider{}
+	s, store, _ := newTestSender(t, provider)
+	if err := store.Suppressions().Suppress(t.Context(), domain.Suppression{Email: "asha@example.com", Reason: domain.SuppressionBounce}); err != nil {
+		t.Fatalf("Suppress: %v", err)
+	}
+
+	err := s.Send(t.Context(), Message{To: " Asha@Example.com ", Subject: "Hi"})
+	testhelpers.LogTestAssertion(logger, "suppressed send", ErrSuppressed, err)
+	if !errors.Is(err, ErrSuppressed) || !errors.Is(err, notify.ErrUnreachable) {
+		t.Errorf("Send error = %v, want ErrSuppressed", err)
+	}
+	if provider.attempts != 0 {
+		t.Errorf("Provider called %d times for a suppressed address", provider.attempts)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSender_Suppressed", true)
+}
+
+func TestSender_Verification(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSender_Verification", "internal/notify/email")
+
+	provider := &fakeProvider{}
+	s, _, _ := newTestSender(t, provider)
+	u := domain.User{ID: "user_1", Email: "asha@example.com", Name: "Asha <b>"}
+	if err := s.SendVerification(t.Context(), u, "tok+en/1"); err != nil {
+		t.Fatalf("SendVerification: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "One message with an escaped link in both parts")
+	if len(provider.sent) != 1 {
+		t.Fatalf("Sent %d messages, want 1", len(provider.sent))
+	}
+	m := provider.sent[0]
+	link := "https://wheyprices.example/api/v1/auth/verify?token=tok%2Ben%2F1"
+	testhelpers.LogTestAssertion(logger, "text contains link", true, strings.Contains(m.Text, link))
+	if m.To != u.Email || m.From != "Whey Price Compare <alerts@example.com>" || m.Subject != "Verify your email address" {
+		t.Errorf("Message = %+v", m)
+	}
+	if !strings.Contains(m.Text, link) || !strings.Contains(m.Text, "Hi Asha <b>,") {
+		t.Errorf("Text part:\n%s", m.Text)
+	}
+	if !strings.Contains(m.HTML, `href="`+link+`"`) {
+		t.Errorf("HTML part lacks the link:\n%s", m.HTML)
+	}
+	if strings.Contains(m.HTML, "Asha <b>") {
+		t.Error("HTML part does not escape the user's name")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSender_Verification", true)
+}
+
+func TestSender_AlertConfirmation(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSender_AlertConfirmation", "internal/notify/email")
+
+	provider := &fakeProvider{}
+	s, _, _ := newTestSender(t, provider)
+	// Guests are emailed before their address is verified.
+	u := domain.User{ID: "user_1", Email: "Asha@Example.com"}
+	link := "https://wheyprices.example/alerts/manage?token=abc.def"
+	if err := s.SendAlertConfirmation(t.Context(), u, "Gold Standard\n100% Whey", "drops to ₹2,999.00 or less", link); err != nil {
+		t.Fatalf("SendAlertConfirmation: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "One message naming the product and condition, with the link")
+	if len(provider.sent) != 1 {
+		t.Fatalf("Sent %d messages, want 1", len(provider.sent))
+	}
+	m := provider.sent[0]
+	testhelpers.LogTestAssertion(logger, "subject", "Confirm your price alert for Gold Standard 100% Whey", m.Subject)
+	if m.To != "asha@example.com" || m.Subject != "Confirm your price alert for Gold Standard 100% Whey" {
+		t.Errorf("Message = %+v", m)
+	}
+	if !strings.Contains(m.Text, "drops to ₹2,999.00 or less") || !strings.Contains(m.Text, link) {
+		t.Errorf("Text part:\n%s", m.Text)
+	}
+	if !strings.Contains(m.HTML, `href="`+link+`"`) {
+		t.Errorf("HTML part lacks the link:\n%s", m.HTML)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSender_AlertConfirmation", true)
+}
+
+func TestSender_DeliverPriceAlert(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSender_DeliverPriceAlert", "internal/notify/email")
+
+	n := domain.Notification{
+		ID: "notif_1", Type: domain.NotificationPriceAlert, UserID: "user_1",
+		ProductName: "Gold Standard\n100% Whey", RetailerName: "Flipkart",
+		Price: 2899, PricePerGram: 1.62, URL: "/go/flipkart/prod_on_gsw/lst_1",
+	}
+	verified := domain.User{ID: "user_1", Email: "asha@example.com", EmailVerified: true}
+
+	testCases := []struct {
+		name    string
+		user    domain.User
+		n       domain.Notification
+		wantErr error
+	}{
+		{"Verified user", verified, n, nil},
+		{"Unverified user", domain.User{ID: "user_1", Email: "asha@example.com"}, n, notify.ErrUnreachable},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := &fakeProvider{}
+			s, _, _ := newTestSender(t, provider)
+			err := s.Deliver(t.Context(), tc.user, tc.n)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Deliver error = %v, want %v", err, tc.wantErr)
+			}
+			if tc.wantErr != nil {
+				return
+			}
+			m := provider.sent[0]
+			if m.Subject != "Price alert: Gold Standard 100% Whey is now ₹2,899" {
+				t.Errorf("Subject = %q", m.Subject)
+			}
+			for _, want := range []string{"Flipkart: ₹2,899 (₹1.62 per gram of protein)", "https://wheyprices.example/go/flipkart/prod_on_gsw/lst_1"} {
+				if !strings.Contains(m.Text, want) {
+					t.Errorf("Text part lacks %q:\n%s", want, m.Text)
+				}
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Delivering a saved search match")
+	provider := &fakeProvider{}
+	s, _, _ := newTestSender(t, provider)
+	n.SearchID, n.SearchName = "search_1", "Whey under 3,000"
+	if err := s.Deliver(t.Context(), verified, n); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	m := provider.sent[0]
+	if m.Subject != `New match for "Whey under 3,000": Gold Standard 100% Whey is now ₹2,899` {
+		t.Errorf("Subject = %q", m.Subject)
+	}
+	if want := `matches your saved search "Whey under 3,000"`; !strings.Contains(m.Text, want) {
+		t.Errorf("Text part lacks %q:\n%s", want, m.Text)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Delivering a test alert")
+	n.SearchID, n.SearchName, n.Test = "", "", true
+	if err := s.Deliver(t.Context(), verified, n); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	m = provider.sent[1]
+	if !strings.HasPrefix(m.Subject, "Test alert: ") || !strings.Contains(m.Text, "the price below is simulated") {
+		t.Errorf("Test alert subject %q, text:\n%s", m.Subject, m.Text)
+	}
+	if strings.Contains(m.HTML, "<img") {
+		t.Errorf("Untracked HTML part has an image:\n%s", m.HTML)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Delivering an alert with an open tracking pixel")
+	n.Test, n.Pixel = false, "/api/v1/notifications/opened?nt=a&b"
+	if err := s.Deliver(t.Context(), verified, n); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	m = provider.sent[2]
+	if want := `<img src="https://wheyprices.example/api/v1/notifications/opened?nt=a&amp;b" width="1" height="1" alt=""`; !strings.Contains(m.HTML, want) || strings.Contains(m.Text, "opened") {
+		t.Errorf("HTML part lacks %s, or text part has it:\n%s\n%s", want, m.HTML, m.Text)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSender_DeliverPriceAlert", true)
+}
+
+func TestSender_DeliverDigest(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSender_DeliverDigest", "internal/notify/email")
+
+	n := domain.Notification{
+		ID: "notif_d", Type: domain.NotificationPriceAlertDigest, UserID: "user_1",
+		Items: []domain.Notification{
+			{ProductName: "Gold Standard 100% Whey", RetailerName: "Flipkart", Price: 2899, URL: "/go/flipkart/prod_on_gsw/lst_1"},
+			{ProductName: "Biozyme Performance Whey", RetailerName: "Amazon", Price: 2499, PricePerGram: 1.55, URL: "/go/amazon/prod_mb_biozyme/lst_2"},
+		},
+	}
+	provider := &fakeProvider{}
+	s, _, _ := newTestSender(t, provider)
+
+	err := s.Deliver(t.Context(), domain.User{ID: "user_1", Name: "Asha", Email: "asha@example.com", EmailVerified: true}, n)
+
+	testhelpers.LogTestAssertion(logger, "deliver", nil, err)
+	if err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	m := provider.sent[0]
+	if m.Subject != "2 price alerts reached your target" {
+		t.Errorf("Subject = %q", m.Subject)
+	}
+	for _, want := range []string{
+		"Hi Asha,",
+		"Flipkart: ₹2,899\n",
+		"Amazon: ₹2,499 (₹1.55 per gram of protein)",
+		"https://wheyprices.example/go/amazon/prod_mb_biozyme/lst_2",
+	} {
+		if !strings.Contains(m.Text, want) {
+			t.Errorf("Text part lacks %q:\n%s", want, m.Text)
+		}
+	}
+	if !strings.Contains(m.HTML, "Biozyme Performance Whey") {
+		t.Errorf("HTML part lacks the second product:\n%s", m.HTML)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSender_DeliverDigest", true)
+}
+
+func TestSender_DeliverUnsubscribe(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSender_DeliverUnsubscribe", "internal/notify/email")
+
+	n := domain.Notification{
+		ID: "notif_1", Type: domain.NotificationPriceAlert, UserID: "user_1",
+		ProductName: "Gold Standard 100% Whey", RetailerName: "Flipkart", Price: 2899, URL: "/go/flipkart",
+	}
+	u := domain.User{ID: "user_1", Email: "asha@example.com", EmailVerified: true}
+	testCases := []struct {
+		name     string
+		prefs    *notify.Preferences
+		wantLink bool
+	}{
+		{"Signed links", notify.NewPreferences(memory.NewStore().Preferences()).WithSigningKey([]byte("test-only-secret")), true},
+		{"No signing key", notify.NewPreferences(memory.NewStore().Preferences()), false},
+		{"No preferences", nil, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := &fakeProvider{}
+			s, _, _ := newTestSender(t, provider)
+			s.WithUnsubscribe(tc.prefs)
+			if err := s.Deliver(t.Context(), u, n); err != nil {
+				t.Fatalf("Deliver: %v", err)
+			}
+			m := provider.sent[0]
+			link := strings.Trim(m.Headers["List-Unsubscribe"], "<>")
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantLink, link != "")
+			if !tc.wantLink {
+				if m.Headers != nil || strings.Contains(m.Text, "/unsubscribe") {
+					t.Errorf("Headers = %v, text:\n%s; want no unsubscribe link", m.Headers, m.Text)
+				}
+				return
+			}
+			if !strings.HasPrefix(link, "https://wheyprices.example/unsubscribe?token=") || m.Headers["List-Unsubscribe-Post"] != "List-Unsubscribe=One-Click" {
+				t.Errorf("Headers = %v", m.Headers)
+			}
+			if !strings.Contains(m.Text, link) || !strings.Contains(m.HTML, "/unsubscribe?token=") {
+				t.Errorf("Body lacks %s:\n%s", link, m.Text)
+			}
+			token := strings.TrimPrefix(link, "https://wheyprices.example/unsubscribe?token=")
+			if userID, channel, err := tc.prefs.ParseUnsubscribeToken(token); err != nil || userID != "user_1" || channel != domain.ChannelEmail {
+				t.Errorf("Token is for %q, %q: %v", userID, channel, err)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSender_DeliverUnsubscribe", true)
+}
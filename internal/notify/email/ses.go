@@ -0,0 +1,140 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/awsv4"
+)
+
+// AWSCredentials sign requests to SES.
+type AWSCredentials = awsv4.Credentials
+
+// SESConfig configures the Amazon SES provider.
+type SESConfig struct {
+	Region      string
+	Credentials AWSCredentials
+	// ConfigurationSet routes bounce and complaint events, e.g. to the SNS
+	// topic BounceHandler listens on. Optional.
+	ConfigurationSet string
+	// Endpoint overrides the regional API root, for tests.
+	Endpoint string
+	Timeout  time.Duration
+}
+
+// DefaultSESConfig returns a ten second timeout; Region and Credentials
+// must be set.
+func DefaultSESConfig() SESConfig {
+	return SESConfig{Timeout: 10 * time.Second}
+}
+
+// SES sends mail with the Amazon SES v2 API.
+type SES struct {
+	cfg    SESConfig
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewSES creates an SES provider.
+func NewSES(cfg SESConfig) *SES {
+	def := DefaultSESConfig()
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://email." + cfg.Region + ".amazonaws.com"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	return &SES{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}, now: time.Now}
+}
+
+type sesContent struct {
+	Data    string `json:"Data"`
+	Charset string `json:"Charset"`
+}
+
+type sesHeader struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
+type sesSendEmail struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses []string `json:"ToAddresses"`
+	} `json:"Destination"`
+	Content struct {
+		Simple struct {
+			Subject sesContent `json:"Subject"`
+			Body    struct {
+				Text *sesContent `json:"Text,omitempty"`
+				HTML *sesContent `json:"Html,omitempty"`
+			} `json:"Body"`
+			Headers []sesHeader `json:"Headers,omitempty"`
+		} `json:"Simple"`
+	} `json:"Content"`
+	ConfigurationSetName string `json:"ConfigurationSetName,omitempty"`
+}
+
+// Send implements Provider.
+func (p *SES) Send(ctx context.Context, m Message) error {
+	var in sesSendEmail
+	in.FromEmailAddress = m.From
+	in.Destination.ToAddresses = []string{m.To}
+	in.Content.Simple.Subject = sesContent{Data: m.Subject, Charset: "UTF-8"}
+	if m.Text != "" {
+		in.Content.Simple.Body.Text = &sesContent{Data: m.Text, Charset: "UTF-8"}
+	}
+	if m.HTML != "" {
+		in.Content.Simple.Body.HTML = &sesContent{Data: m.HTML, Charset: "UTF-8"}
+	}
+	for _, name := range slices.Sorted(maps.Keys(m.Headers)) {
+		in.Content.Simple.Headers = append(in.Content.Simple.Headers, sesHeader{Name: name, Value: headerValue(m.Headers[name])})
+	}
+	in.ConfigurationSetName = p.cfg.ConfigurationSet
+	payload, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.Endpoint+"/v2/email/outbound-emails", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	awsv4.Sign(req, awsv4.PayloadHash(payload), p.cfg.Credentials, p.cfg.Region, "ses", p.now())
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return &SendError{Err: fmt.Errorf("ses: %w", err), Retryable: true}
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var parsed struct {
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(raw, &parsed)
+	msg := http.StatusText(resp.StatusCode)
+	if parsed.Message != "" {
+		msg = parsed.Message
+	}
+	if errType := resp.Header.Get("X-Amzn-ErrorType"); errType != "" {
+		msg = strings.SplitN(errType, ":", 2)[0] + ": " + msg
+	}
+	return &SendError{
+		Err:       fmt.Errorf("ses: status %d: %s", resp.StatusCode, msg),
+		Retryable: resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500,
+	}
+}
@@ -0,0 +1,102 @@
+package email
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestSES_Send(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSES_Send", "internal/notify/email")
+
+	var gotAuth, gotDate string
+	var got sesSendEmail
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v2/email/outbound-emails" {
+			http.NotFound(w, r)
+			return
+		}
+		gotAuth, gotDate = r.Header.Get("Authorization"), r.Header.Get("X-Amz-Date")
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &got)
+		_, _ = w.Write([]byte(`{"MessageId":"0100018c"}`))
+	}))
+	defer srv.Close()
+
+	p := NewSES(SESConfig{
+		Region:           "ap-south-1",
+		Credentials:      AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "test-only-secret"},
+		ConfigurationSet: "transactional",
+		Endpoint:         srv.URL,
+	})
+	p.now = func() time.Time { return time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC) }
+	err := p.Send(t.Context(), Message{
+		From: "alerts@example.com", To: "asha@example.com", Subject: "Hi", Text: "Hello", HTML: "<p>Hello</p>",
+		Headers: map[string]string{"List-Unsubscribe-Post": "List-Unsubscribe=One-Click", "List-Unsubscribe": "<https://wheyprices.example/unsubscribe>"},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "A signed SendEmail call with both bodies")
+	testhelpers.LogTestAssertion(logger, "date", "20261016T093000Z", gotDate)
+	if !strings.HasPrefix(gotAuth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20261016/ap-south-1/ses/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=") {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if got.FromEmailAddress != "alerts@example.com" || len(got.Destination.ToAddresses) != 1 || got.ConfigurationSetName != "transactional" {
+		t.Errorf("Request = %+v", got)
+	}
+	if got.Content.Simple.Body.Text == nil || got.Content.Simple.Body.HTML == nil || got.Content.Simple.Body.HTML.Data != "<p>Hello</p>" {
+		t.Errorf("Body = %+v", got.Content.Simple.Body)
+	}
+	if h := got.Content.Simple.Headers; len(h) != 2 || h[0].Name != "List-Unsubscribe" || h[1].Value != "List-Unsubscribe=One-Click" {
+		t.Errorf("Headers = %+v, want both in name order", h)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSES_Send", true)
+}
+
+func TestSES_ClassifiesErrors(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSES_ClassifiesErrors", "internal/notify/email")
+
+	testCases := []struct {
+		name          string
+		status        int
+		wantRetryable bool
+	}{
+		{"Throttled", http.StatusTooManyRequests, true},
+		{"Unavailable", http.StatusServiceUnavailable, true},
+		{"Unverified sender", http.StatusBadRequest, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Amzn-ErrorType", "SomeException:http://internal.amazon.com/")
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(`{"message":"details"}`))
+			}))
+			defer srv.Close()
+			p := NewSES(SESConfig{Region: "ap-south-1", Endpoint: srv.URL})
+			err := p.Send(t.Context(), Message{From: "alerts@example.com", To: "asha@example.com", Text: "Hello"})
+			var serr *SendError
+			if !errors.As(err, &serr) {
+				t.Fatalf("Send error = %v, want a SendError", err)
+			}
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantRetryable, serr.Retryable)
+			if serr.Retryable != tc.wantRetryable || !strings.Contains(err.Error(), "SomeException: details") {
+				t.Errorf("Send error = %v (retryable %v), want retryable %v", err, serr.Retryable, tc.wantRetryable)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSES_ClassifiesErrors", true)
+}
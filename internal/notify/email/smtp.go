@@ -0,0 +1,203 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPConfig configures the SMTP provider.
+type SMTPConfig struct {
+	Host string
+	// Port is usually 587 for STARTTLS or 465 for ImplicitTLS.
+	Port     int
+	Username string
+	Password string
+	// ImplicitTLS connects over TLS from the start instead of upgrading
+	// with STARTTLS.
+	ImplicitTLS bool
+	Timeout     time.Duration
+}
+
+// DefaultSMTPConfig returns the submission port with a 30 second timeout.
+func DefaultSMTPConfig() SMTPConfig {
+	return SMTPConfig{Port: 587, Timeout: 30 * time.Second}
+}
+
+// SMTP sends mail through an SMTP relay. Connections are upgraded with
+// STARTTLS whenever the server offers it, and credentials are only sent
+// over TLS (or to localhost).
+type SMTP struct {
+	cfg SMTPConfig
+}
+
+// NewSMTP creates an SMTP provider.
+func NewSMTP(cfg SMTPConfig) *SMTP {
+	def := DefaultSMTPConfig()
+	if cfg.Port <= 0 {
+		cfg.Port = def.Port
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	return &SMTP{cfg: cfg}
+}
+
+// Send implements Provider.
+func (p *SMTP) Send(ctx context.Context, m Message) error {
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return fmt.Errorf("invalid from address: %w", err)
+	}
+	to, err := mail.ParseAddress(m.To)
+	if err != nil {
+		return fmt.Errorf("invalid to address: %w", err)
+	}
+	body, err := buildMIME(m, from, to, time.Now())
+	if err != nil {
+		return err
+	}
+	return classifySMTP(p.send(ctx, from.Address, to.Address, body))
+}
+
+func (p *SMTP) send(ctx context.Context, from, to string, body []byte) error {
+	addr := net.JoinHostPort(p.cfg.Host, strconv.Itoa(p.cfg.Port))
+	dialer := &net.Dialer{Timeout: p.cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(p.cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+	// Unblock the conversation if ctx ends first.
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	tlsConfig := &tls.Config{ServerName: p.cfg.Host, MinVersion: tls.VersionTLS12}
+	if p.cfg.ImplicitTLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+	c, err := smtp.NewClient(conn, p.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if !p.cfg.ImplicitTLS {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(tlsConfig); err != nil {
+				return err
+			}
+		}
+	}
+	if p.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", p.cfg.Username, p.cfg.Password, p.cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	// The message was accepted; a failed QUIT must not cause a resend.
+	_ = c.Quit()
+	return nil
+}
+
+// classifySMTP wraps err in a SendError. 5xx replies are permanent; 4xx
+// replies and connection failures are worth retrying.
+func classifySMTP(err error) error {
+	if err == nil {
+		return nil
+	}
+	var perr *textproto.Error
+	if errors.As(err, &perr) {
+		return &SendError{Err: fmt.Errorf("smtp: %w", err), Retryable: perr.Code < 500}
+	}
+	return &SendError{Err: fmt.Errorf("smtp: %w", err), Retryable: true}
+}
+
+// buildMIME renders m as a multipart/alternative message with text and
+// HTML parts.
+func buildMIME(m Message, from, to *mail.Address, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	_, host, _ := strings.Cut(from.Address, "@")
+	header := []string{
+		"From: " + from.String(),
+		"To: " + to.String(),
+		"Subject: " + mime.QEncoding.Encode("utf-8", m.Subject),
+		"Date: " + now.Format(time.RFC1123Z),
+		"Message-ID: <" + rand.Text() + "@" + host + ">",
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + mw.Boundary(),
+	}
+	for _, name := range slices.Sorted(maps.Keys(m.Headers)) {
+		header = append(header, name+": "+headerValue(m.Headers[name]))
+	}
+	buf.WriteString(strings.Join(header, "\r\n") + "\r\n\r\n")
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	} {
+		if part.body == "" {
+			continue
+		}
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(pw)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// headerValue keeps a header value on one line.
+func headerValue(v string) string {
+	return strings.Join(strings.Fields(v), " ")
+}
@@ -0,0 +1,171 @@
+package email
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// fakeSMTP accepts one connection and speaks just enough SMTP to take a
+// message, replying rcptReply to RCPT TO. It returns the DATA it received.
+func fakeSMTP(t *testing.T, rcptReply string) (host string, port int, data <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	out := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewConn(conn)
+		_ = tp.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			switch verb := strings.ToUpper(strings.Fields(line + " x")[0]); verb {
+			case "EHLO", "HELO":
+				_ = tp.PrintfLine("250 localhost")
+			case "MAIL":
+				_ = tp.PrintfLine("250 OK")
+			case "RCPT":
+				_ = tp.PrintfLine("%s", rcptReply)
+			case "DATA":
+				_ = tp.PrintfLine("354 Go ahead")
+				body, _ := tp.ReadDotBytes()
+				out <- string(body)
+				_ = tp.PrintfLine("250 Queued")
+			case "QUIT":
+				_ = tp.PrintfLine("221 Bye")
+				return
+			default:
+				_ = tp.PrintfLine("502 Not implemented")
+			}
+		}
+	}()
+	addr := ln.Addr().(*net.TCPAddr)
+	return "127.0.0.1", addr.Port, out
+}
+
+func TestSMTP_Send(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSMTP_Send", "internal/notify/email")
+
+	host, port, data := fakeSMTP(t, "250 OK")
+	p := NewSMTP(SMTPConfig{Host: host, Port: port, Timeout: 5 * time.Second})
+	m := Message{
+		From:    "Whey Price Compare <alerts@example.com>",
+		To:      "asha@example.com",
+		Subject: "Price alert: ₹2,899",
+		Text:    "Hello",
+		HTML:    "<p>Hello</p>",
+		Headers: map[string]string{"List-Unsubscribe": "<https://wheyprices.example/unsubscribe?token=abc>\r\nBcc: x@example.com"},
+	}
+	if err := p.Send(t.Context(), m); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The server received a multipart message")
+	var raw string
+	select {
+	case raw = <-data:
+	case <-time.After(5 * time.Second):
+		t.Fatal("No message received")
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	testhelpers.LogTestAssertion(logger, "subject", m.Subject, subject)
+	if subject != m.Subject {
+		t.Errorf("Subject = %q, want %q", subject, m.Subject)
+	}
+	if got := msg.Header.Get("List-Unsubscribe"); got != "<https://wheyprices.example/unsubscribe?token=abc> Bcc: x@example.com" || msg.Header.Get("Bcc") != "" {
+		t.Errorf("List-Unsubscribe = %q, Bcc = %q; want one folded line", got, msg.Header.Get("Bcc"))
+	}
+	if msg.Header.Get("Message-ID") == "" || !strings.HasSuffix(msg.Header.Get("Message-ID"), "@example.com>") {
+		t.Errorf("Message-ID = %q", msg.Header.Get("Message-ID"))
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q", msg.Header.Get("Content-Type"))
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	var parts []string
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart: %v", err)
+		}
+		body, _ := io.ReadAll(part)
+		parts = append(parts, part.Header.Get("Content-Type")+": "+string(body))
+	}
+	want := []string{"text/plain; charset=utf-8: Hello", "text/html; charset=utf-8: <p>Hello</p>"}
+	if strings.Join(parts, "|") != strings.Join(want, "|") {
+		t.Errorf("Parts = %q, want %q", parts, want)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSMTP_Send", true)
+}
+
+func TestSMTP_ClassifiesReplies(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSMTP_ClassifiesReplies", "internal/notify/email")
+
+	testCases := []struct {
+		name          string
+		rcptReply     string
+		wantRetryable bool
+	}{
+		{"Mailbox busy", "450 Mailbox busy", true},
+		{"No such user", "550 No such user", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			host, port, _ := fakeSMTP(t, tc.rcptReply)
+			p := NewSMTP(SMTPConfig{Host: host, Port: port, Timeout: 5 * time.Second})
+			err := p.Send(t.Context(), Message{From: "alerts@example.com", To: "asha@example.com", Text: "Hello"})
+			var serr *SendError
+			if !errors.As(err, &serr) {
+				t.Fatalf("Send error = %v, want a SendError", err)
+			}
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantRetryable, serr.Retryable)
+			if serr.Retryable != tc.wantRetryable {
+				t.Errorf("Retryable = %v, want %v (%v)", serr.Retryable, tc.wantRetryable, err)
+			}
+		})
+	}
+
+	t.Run("Connection refused", func(t *testing.T) {
+		ln, _ := net.Listen("tcp", "127.0.0.1:0")
+		port := ln.Addr().(*net.TCPAddr).Port
+		ln.Close()
+		err := NewSMTP(SMTPConfig{Host: "127.0.0.1", Port: port, Timeout: time.Second}).
+			Send(t.Context(), Message{From: "alerts@example.com", To: "asha@example.com", Text: "Hello"})
+		var serr *SendError
+		if !errors.As(err, &serr) || !serr.Retryable {
+			t.Errorf("Send error = %v, want a retryable SendError", err)
+		}
+	})
+
+	testhelpers.LogTestComplete(logger, "TestSMTP_ClassifiesReplies", true)
+}
@@ -0,0 +1,20 @@
+package email
+
+import (
+	"github.com/yourusername/whey-price-compare/internal/notify/templates"
+)
+
+// render builds the message called name from data, taking the subject
+// from the plain text template's title. To and From are left for the
+// caller.
+func (s *Sender) render(name string, data any) (Message, error) {
+	text, err := s.templates.Render(name, templates.Text, data)
+	if err != nil {
+		return Message{}, err
+	}
+	html, err := s.templates.Render(name, templates.HTML, data)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{Subject: text.Title, Text: text.Body, HTML: html.Body}, nil
+}
@@ -0,0 +1,197 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// PixelPath serves the image that records a message was opened.
+const PixelPath = "/api/v1/notifications/opened"
+
+// TrackingParam is the query parameter that carries engagement tokens on
+// pixel and /go/ links.
+const TrackingParam = "nt"
+
+// ErrBadTrackingToken is returned for engagement tokens that were not
+// signed with the current key.
+var ErrBadTrackingToken = fmt.Errorf("invalid tracking token: %w", domain.ErrInvalid)
+
+// Engagement records which notifications were delivered, opened and
+// clicked through on each channel, and sums them up per channel. Opens and
+// clicks arrive with a signed token naming the notification and channel,
+// which Tag adds to the links in each channel's copy of a message.
+type Engagement struct {
+	repo   repositories.EngagementRepository
+	key    []byte
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewEngagement creates an Engagement signing tokens with key. Without a
+// key only deliveries are recorded.
+func NewEngagement(repo repositories.EngagementRepository, key []byte, logger *zap.Logger) *Engagement {
+	return &Engagement{repo: repo, key: key, logger: logger, now: time.Now}
+}
+
+// Tag returns channel's copy of n, its /go/ links carrying a token that
+// records click-throughs and with a Pixel that records opens.
+func (e *Engagement) Tag(n domain.Notification, channel string) domain.Notification {
+	if len(e.key) == 0 || n.ID == "" {
+		return n
+	}
+	token := e.token(n.ID, channel)
+	n.URL = withToken(n.URL, token)
+	if len(n.Items) > 0 {
+		// Items are the digest's own; clicks on them count for the
+		// digest.
+		items := slices.Clone(n.Items)
+		for i := range items {
+			items[i].URL = withToken(items[i].URL, token)
+		}
+		n.Items = items
+	}
+	n.Pixel = PixelPath + "?" + TrackingParam + "=" + url.QueryEscape(token)
+	return n
+}
+
+// withToken adds token to outbound /go/ links, leaving others alone.
+func withToken(link, token string) string {
+	if !strings.HasPrefix(link, "/go/") {
+		return link
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	q := u.Query()
+	q.Set(TrackingParam, token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// Delivered records that channel delivered n. Failures are logged rather
+// than returned, as they must not fail the delivery.
+func (e *Engagement) Delivered(ctx context.Context, n domain.Notification, channel string) {
+	e.record(ctx, n.ID, channel, domain.EngagementDelivered)
+}
+
+// Opened records the open token stands for, or returns
+// ErrBadTrackingToken.
+func (e *Engagement) Opened(ctx context.Context, token string) error {
+	return e.recordToken(ctx, token, domain.EngagementOpened)
+}
+
+// Clicked records the click-through token stands for, or returns
+// ErrBadTrackingToken.
+func (e *Engagement) Clicked(ctx context.Context, token string) error {
+	return e.recordToken(ctx, token, domain.EngagementClicked)
+}
+
+func (e *Engagement) recordToken(ctx context.Context, token, typ string) error {
+	notificationID, channel, err := e.parse(token)
+	if err != nil {
+		return err
+	}
+	e.record(ctx, notificationID, channel, typ)
+	return nil
+}
+
+func (e *Engagement) record(ctx context.Context, notificationID, channel, typ string) {
+	_, err := e.repo.RecordEngagement(ctx, domain.EngagementEvent{
+		NotificationID: notificationID,
+		Channel:        channel,
+		Type:           typ,
+		CreatedAt:      e.now().UTC(),
+	})
+	if err != nil {
+		e.logger.Error("Recording notification engagement failed",
+			zap.String("operation", "RecordEngagement"),
+			zap.String("notification_id", notificationID),
+			zap.String("channel", channel),
+			zap.String("type", typ),
+			zap.Error(err),
+		)
+	}
+}
+
+// Stats sums up engagement since since for every channel, listing each
+// channel even if it delivered nothing. A message opened or clicked since
+// but delivered before counts toward the rates all the same.
+func (e *Engagement) Stats(ctx context.Context, since time.Time) ([]domain.ChannelEngagement, error) {
+	events, err := e.repo.EngagementSince(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	stats := make(map[string]*domain.ChannelEngagement, len(domain.Channels))
+	out := make([]domain.ChannelEngagement, len(domain.Channels))
+	for i, c := range domain.Channels {
+		out[i].Channel = c
+		stats[c] = &out[i]
+	}
+	for _, ev := range events {
+		s, ok := stats[ev.Channel]
+		if !ok {
+			continue
+		}
+		switch ev.Type {
+		case domain.EngagementDelivered:
+			s.Delivered++
+		case domain.EngagementOpened:
+			s.Opened++
+		case domain.EngagementClicked:
+			s.Clicked++
+		}
+	}
+	for i := range out {
+		if d := out[i].Delivered; d > 0 {
+			out[i].OpenRate = float64(out[i].Opened) / float64(d)
+			out[i].ClickRate = float64(out[i].Clicked) / float64(d)
+		}
+	}
+	return out, nil
+}
+
+// token signs notificationID and channel. It does not expire, as clicks
+// on old messages still count.
+func (e *Engagement) token(notificationID, channel string) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(notificationID + "\x00" + channel))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(e.sign(payload))
+}
+
+func (e *Engagement) parse(token string) (notificationID, channel string, err error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok || len(e.key) == 0 {
+		return "", "", ErrBadTrackingToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, e.sign(payload)) {
+		return "", "", ErrBadTrackingToken
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", "", ErrBadTrackingToken
+	}
+	notificationID, channel, ok = strings.Cut(string(raw), "\x00")
+	if !ok || notificationID == "" || !slices.Contains(domain.Channels, channel) {
+		return "", "", ErrBadTrackingToken
+	}
+	return notificationID, channel, nil
+}
+
+func (e *Engagement) sign(payload string) []byte {
+	h := hmac.New(sha256.New, e.key)
+	h.Write([]byte("engagement\x00" + payload))
+	return h.Sum(nil)
+}
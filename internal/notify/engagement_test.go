@@ -0,0 +1,174 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// trackingToken returns the token link carries.
+func trackingToken(t *testing.T, link string) string {
+	t.Helper()
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", link, err)
+	}
+	return u.Query().Get(TrackingParam)
+}
+
+func TestEngagement_Tag(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestEngagement_Tag", "internal/notify")
+
+	store := memory.NewStore()
+	e := NewEngagement(store.Engagement(), []byte("test-only-signing-secret"), logger)
+	n := domain.Notification{
+		ID:   "notif_1",
+		Type: domain.NotificationPriceAlertDigest,
+		URL:  "/go/amazon/prod_1?listing=lst_1",
+		Items: []domain.Notification{
+			{URL: "/go/flipkart/prod_2"},
+			{URL: "https://example.com/elsewhere"},
+		},
+	}
+
+	tagged := e.Tag(n, domain.ChannelEmail)
+
+	testhelpers.LogTestStep(logger, "assert", "Outbound links and the pixel carry a token; others are left alone")
+	token := trackingToken(t, tagged.URL)
+	if token == "" || !strings.Contains(tagged.URL, "listing=lst_1") {
+		t.Errorf("URL = %q, want the listing kept and a token added", tagged.URL)
+	}
+	if trackingToken(t, tagged.Items[0].URL) != token || tagged.Items[1].URL != "https://example.com/elsewhere" {
+		t.Errorf("Items = %+v, want the /go/ link tagged with the digest's token", tagged.Items)
+	}
+	if n.Items[0].URL != "/go/flipkart/prod_2" {
+		t.Errorf("Tag changed the original's items: %+v", n.Items)
+	}
+	if !strings.HasPrefix(tagged.Pixel, PixelPath+"?") || trackingToken(t, tagged.Pixel) != token {
+		t.Errorf("Pixel = %q, want %s with the token", tagged.Pixel, PixelPath)
+	}
+	if other := e.Tag(n, domain.ChannelTelegram); trackingToken(t, other.URL) == token {
+		t.Error("Channels share a token, want one each")
+	}
+	if plain := NewEngagement(store.Engagement(), nil, logger).Tag(n, domain.ChannelEmail); plain.URL != n.URL || plain.Pixel != "" {
+		t.Errorf("Tag without a key = %+v, want n unchanged", plain)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestEngagement_Tag", true)
+}
+
+func TestEngagement_Stats(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestEngagement_Stats", "internal/notify")
+
+	testhelpers.LogTestStep(logger, "arrange", "Four emails and two Telegram messages delivered")
+	store := memory.NewStore()
+	ctx := t.Context()
+	e := NewEngagement(store.Engagement(), []byte("test-only-signing-secret"), logger)
+	now := time.Now()
+	e.now = func() time.Time { return now }
+	var tokens []string
+	for _, id := range []string{"notif_1", "notif_2", "notif_3", "notif_4"} {
+		n := domain.Notification{ID: id, URL: "/go/amazon/prod_1"}
+		e.Delivered(ctx, n, domain.ChannelEmail)
+		tokens = append(tokens, trackingToken(t, e.Tag(n, domain.ChannelEmail).URL))
+	}
+	for _, id := range []string{"notif_1", "notif_2"} {
+		e.Delivered(ctx, domain.Notification{ID: id}, domain.ChannelTelegram)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Opening two emails, one twice, and clicking through one")
+	for _, token := range []string{tokens[0], tokens[1], tokens[1]} {
+		if err := e.Opened(ctx, token); err != nil {
+			t.Fatalf("Opened: %v", err)
+		}
+	}
+	if err := e.Clicked(ctx, tokens[0]); err != nil {
+		t.Fatalf("Clicked: %v", err)
+	}
+	other := NewEngagement(store.Engagement(), []byte("test-only-other-secret"), logger)
+	forged := trackingToken(t, other.Tag(domain.Notification{ID: "notif_1", URL: "/go/a/b"}, domain.ChannelEmail).URL)
+	for _, bad := range []string{forged, "notif_1", ""} {
+		if err := e.Clicked(ctx, bad); !errors.Is(err, ErrBadTrackingToken) || !errors.Is(err, domain.ErrInvalid) {
+			t.Errorf("Clicked(%q) error = %v, want ErrBadTrackingToken", bad, err)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Counts and rates per channel")
+	stats, err := e.Stats(ctx, now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	testhelpers.LogTestAssertion(logger, "channels", len(domain.Channels), len(stats))
+	if len(stats) != len(domain.Channels) {
+		t.Fatalf("Stats = %+v, want every channel", stats)
+	}
+	byChannel := make(map[string]domain.ChannelEngagement)
+	for _, s := range stats {
+		byChannel[s.Channel] = s
+	}
+	want := map[string]domain.ChannelEngagement{
+		domain.ChannelEmail:    {Channel: domain.ChannelEmail, Delivered: 4, Opened: 2, Clicked: 1, OpenRate: 0.5, ClickRate: 0.25},
+		domain.ChannelTelegram: {Channel: domain.ChannelTelegram, Delivered: 2},
+		domain.ChannelSlack:    {Channel: domain.ChannelSlack},
+	}
+	for channel, w := range want {
+		if got := byChannel[channel]; got != w {
+			t.Errorf("Stats[%s] = %+v, want %+v", channel, got, w)
+		}
+	}
+	if later, _ := e.Stats(ctx, now.Add(time.Hour)); later[0].Delivered != 0 {
+		t.Errorf("Stats since later = %+v, want nothing", later)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestEngagement_Stats", true)
+}
+
+// taggingChannel records the links it was asked to deliver.
+type taggingChannel struct {
+	fakeChannel
+	urls []string
+}
+
+func (c *taggingChannel) Deliver(ctx context.Context, u domain.User, n domain.Notification) error {
+	c.urls = append(c.urls, n.URL)
+	return c.fakeChannel.Deliver(ctx, u, n)
+}
+
+func TestDispatcher_Engagement(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestDispatcher_Engagement", "internal/notify")
+
+	store := memory.NewStore()
+	ctx := t.Context()
+	u, _ := store.Users().CreateUser(ctx, domain.User{Email: "asha@example.com"})
+	if err := store.Notifications().Enqueue(ctx, domain.Notification{Type: domain.NotificationPriceAlert, UserID: u.ID, URL: "/go/amazon/prod_1"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	email := &taggingChannel{fakeChannel: fakeChannel{name: domain.ChannelEmail}}
+	down := &fakeChannel{name: domain.ChannelTelegram, fail: map[string]error{u.ID: errors.New("bot API down")}}
+	e := NewEngagement(store.Engagement(), []byte("test-only-signing-secret"), logger)
+	d := NewDispatcher(DispatcherConfig{}, store.Notifications(), store.Users(), logger, email, down).WithEngagement(e)
+
+	d.Dispatch(ctx)
+
+	testhelpers.LogTestStep(logger, "assert", "The channel got a tagged link and only its delivery was recorded")
+	if len(email.urls) != 1 || trackingToken(t, email.urls[0]) == "" {
+		t.Errorf("Delivered URLs %v, want one tagged link", email.urls)
+	}
+	events, _ := store.Engagement().EngagementSince(ctx, time.Time{})
+	testhelpers.LogTestAssertion(logger, "events", 1, len(events))
+	if len(events) != 1 || events[0].Channel != domain.ChannelEmail || events[0].Type != domain.EngagementDelivered {
+		t.Errorf("Events = %+v, want one email delivery", events)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestDispatcher_Engagement", true)
+}
@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/pool"
+)
+
+// delivery is a notification ready to go out, with its recipient's
+// preferences.
+type delivery struct {
+	n     domain.Notification
+	prefs domain.NotificationPreferences
+}
+
+// WithPool delivers several users' notifications at once on p's workers,
+// each channel within its ChannelConcurrency. Without a pool they are
+// delivered one after another. It returns d.
+func (d *Dispatcher) WithPool(p *pool.Pool) *Dispatcher {
+	d.pool = p
+	return d
+}
+
+// fanOut delivers each user's notifications in order, users at once with
+// WithPool, and returns the IDs of those done with. A user's notifications
+// after one that must stay queued stay queued too, so they keep their
+// order.
+func (d *Dispatcher) fanOut(ctx context.Context, users []string, byUser map[string][]delivery) []string {
+	var mu sync.Mutex
+	var done []string
+	deliverUser := func(ctx context.Context, userID string) {
+		for _, p := range byUser[userID] {
+			if !d.deliver(ctx, p.n, p.prefs) {
+				return
+			}
+			mu.Lock()
+			done = append(done, p.n.ID)
+			mu.Unlock()
+		}
+	}
+	if d.pool == nil {
+		for _, userID := range users {
+			deliverUser(ctx, userID)
+		}
+		return done
+	}
+	batch := d.pool.Batch(ctx)
+	for _, userID := range users {
+		batch.Go(func(ctx context.Context) error {
+			deliverUser(ctx, userID)
+			return nil
+		})
+	}
+	_ = batch.Wait()
+	return done
+}
+
+// channelsFor returns the channels prefs accept, in the user's priority
+// order. Channels with names prefs do not know go last.
+func (d *Dispatcher) channelsFor(prefs domain.NotificationPreferences) []Channel {
+	order := prefs.ChannelOrder()
+	rank := func(c Channel) int {
+		if i := slices.Index(order, c.Name()); i >= 0 {
+			return i
+		}
+		return len(order)
+	}
+	channels := make([]Channel, 0, len(d.channels))
+	for _, c := range d.channels {
+		if prefs.ChannelEnabled(c.Name()) {
+			channels = append(channels, c)
+		}
+	}
+	slices.SortStableFunc(channels, func(a, b Channel) int { return cmp.Compare(rank(a), rank(b)) })
+	return channels
+}
+
+// send delivers channel's copy of n to u once the channel has a free
+// slot.
+func (d *Dispatcher) send(ctx context.Context, c Channel, u domain.User, n domain.Notification) error {
+	slot := d.slots[c.Name()]
+	select {
+	case slot <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-slot }()
+	start := d.now()
+	err := c.Deliver(ctx, u, d.tag(n, c.Name()))
+	d.observe(c.Name(), start, err)
+	return err
+}
@@ -0,0 +1,219 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/metrics"
+	"github.com/yourusername/whey-price-compare/internal/pool"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestDispatcher_Fanout(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestDispatcher_Fanout", "internal/notify")
+
+	testCases := []struct {
+		name         string
+		prefs        domain.NotificationPreferences
+		test         bool
+		telegramDown bool
+		emailDown    bool
+		wantEmail    int
+		wantTelegram int
+		wantFailures []string // channels recorded for retry
+	}{
+		{name: "Default sends email only", wantEmail: 1},
+		{name: "Priority", prefs: domain.NotificationPreferences{Priority: []string{domain.ChannelTelegram}}, wantTelegram: 1},
+		{name: "Falls back without a retry", prefs: domain.NotificationPreferences{Priority: []string{domain.ChannelTelegram}}, telegramDown: true, wantEmail: 1},
+		{name: "Retries only the first failure", prefs: domain.NotificationPreferences{Priority: []string{domain.ChannelTelegram}}, telegramDown: true, emailDown: true, wantFailures: []string{domain.ChannelTelegram}},
+		{name: "All channels", prefs: domain.NotificationPreferences{Fanout: domain.FanoutAll}, wantEmail: 1, wantTelegram: 1},
+		{name: "All channels retry each failure", prefs: domain.NotificationPreferences{Fanout: domain.FanoutAll}, telegramDown: true, emailDown: true, wantFailures: []string{domain.ChannelEmail, domain.ChannelTelegram}},
+		{name: "Test notifications go everywhere", test: true, wantEmail: 1, wantTelegram: 1},
+		{name: "Channel turned off", prefs: domain.NotificationPreferences{Channels: map[string]bool{domain.ChannelEmail: false}}, wantTelegram: 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := memory.NewStore()
+			ctx := t.Context()
+			u, _ := store.Users().CreateUser(ctx, domain.User{Email: "asha@example.com"})
+			prefs := NewPreferences(store.Preferences())
+			p := tc.prefs
+			p.UserID, p.Frequency = u.ID, domain.FrequencyInstant
+			if _, err := prefs.Save(ctx, p); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+			if err := store.Notifications().Enqueue(ctx, domain.Notification{Type: domain.NotificationPriceAlert, UserID: u.ID, Test: tc.test}); err != nil {
+				t.Fatalf("Enqueue: %v", err)
+			}
+			email := &fakeChannel{name: domain.ChannelEmail, fail: map[string]error{}}
+			tg := &fakeChannel{name: domain.ChannelTelegram, fail: map[string]error{}}
+			if tc.emailDown {
+				email.fail[u.ID] = errors.New("smtp: 421 try again later")
+			}
+			if tc.telegramDown {
+				tg.fail[u.ID] = errors.New("bot API down")
+			}
+			// Channels are configured in the opposite order to the default
+			// priority, which must win.
+			d := NewDispatcher(DispatcherConfig{}, store.Notifications(), store.Users(), logger, tg, email).
+				WithPreferences(prefs, DefaultDigestSchedule()).
+				WithRetries(store.Deliveries(), DefaultRetryPolicy())
+
+			d.Dispatch(ctx)
+
+			testhelpers.LogTestAssertion(logger, tc.name, []int{tc.wantEmail, tc.wantTelegram}, []int{len(email.delivered), len(tg.delivered)})
+			if len(email.delivered) != tc.wantEmail || len(tg.delivered) != tc.wantTelegram {
+				t.Errorf("Delivered by email %v and Telegram %v, want %d and %d", email.delivered, tg.delivered, tc.wantEmail, tc.wantTelegram)
+			}
+			failures, _ := d.FailedDeliveries(ctx, "", 0)
+			var channels []string
+			for _, f := range failures {
+				channels = append(channels, f.Channel)
+			}
+			slices.Sort(channels)
+			if !slices.Equal(channels, tc.wantFailures) {
+				t.Errorf("Failures on %v, want %v", channels, tc.wantFailures)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestDispatcher_Fanout", true)
+}
+
+// slowChannel takes a while over each delivery, tracking how many are in
+// flight and the order each user's arrive in.
+type slowChannel struct {
+	name              string
+	inFlight, maxSeen atomic.Int32
+	mu                sync.Mutex
+	byUser            map[string][]string
+}
+
+func (c *slowChannel) Name() string { return c.name }
+
+func (c *slowChannel) Deliver(_ context.Context, u domain.User, n domain.Notification) error {
+	now := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	for seen := c.maxSeen.Load(); now > seen && !c.maxSeen.CompareAndSwap(seen, now); seen = c.maxSeen.Load() {
+	}
+	time.Sleep(2 * time.Millisecond)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.byUser[u.ID] = append(c.byUser[u.ID], n.ID)
+	return nil
+}
+
+func TestDispatcher_FanoutConcurrency(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestDispatcher_FanoutConcurrency", "internal/notify")
+
+	testhelpers.LogTestStep(logger, "arrange", "Eight users with three alerts each, delivered on a pool of four")
+	store := memory.NewStore()
+	ctx := t.Context()
+	var queued []domain.Notification
+	for range 8 {
+		u, _ := store.Users().CreateUser(ctx, domain.User{})
+		for range 3 {
+			queued = append(queued, domain.Notification{Type: domain.NotificationPriceAlert, UserID: u.ID})
+		}
+	}
+	if err := store.Notifications().Enqueue(ctx, queued...); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	sms := &slowChannel{name: domain.ChannelSMS, byUser: make(map[string][]string)}
+	push := &slowChannel{name: domain.ChannelWebPush, byUser: make(map[string][]string)}
+	p := pool.New(pool.Config{Name: "fanout", Size: 4}, logger)
+	t.Cleanup(func() { _ = p.Close(context.Background()) })
+	cfg := DispatcherConfig{ChannelConcurrency: map[string]int{domain.ChannelSMS: 1}, DefaultChannelConcurrency: 3}
+	prefs := NewPreferences(store.Preferences())
+	d := NewDispatcher(cfg, store.Notifications(), store.Users(), logger, sms, push).
+		WithPreferences(prefs, DefaultDigestSchedule()).
+		WithPool(p)
+	for _, u := range []string{"user_1", "user_3", "user_5", "user_7"} {
+		// Half the users want every channel, so both are busy.
+		if _, err := prefs.Save(ctx, domain.NotificationPreferences{UserID: u, Frequency: domain.FrequencyInstant, Fanout: domain.FanoutAll}); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	n := d.Dispatch(ctx)
+
+	testhelpers.LogTestStep(logger, "assert", "Channels stayed within their limits and each user's alerts kept their order")
+	testhelpers.LogTestAssertion(logger, "processed", len(queued), n)
+	if n != len(queued) {
+		t.Errorf("Dispatch processed %d, want %d", n, len(queued))
+	}
+	if got := sms.maxSeen.Load(); got != 1 {
+		t.Errorf("SMS had %d deliveries in flight, want 1", got)
+	}
+	if got := push.maxSeen.Load(); got < 1 || got > 3 {
+		t.Errorf("Web Push had %d deliveries in flight, want 1 to 3", got)
+	}
+	for userID, ids := range push.byUser {
+		if !slices.IsSortedFunc(ids, compareIDs) {
+			t.Errorf("%s got %v, want queue order", userID, ids)
+		}
+	}
+	if total := len(sms.byUser) + len(push.byUser); total == 0 {
+		t.Error("Nothing was delivered")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestDispatcher_FanoutConcurrency", true)
+}
+
+func TestDispatcher_Metrics(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestDispatcher_Metrics", "internal/notify")
+
+	testhelpers.LogTestStep(logger, "arrange", "Two users preferring Telegram, one not linked to it and one whose bot is down")
+	store := memory.NewStore()
+	ctx := t.Context()
+	prefs := NewPreferences(store.Preferences())
+	email := &fakeChannel{name: domain.ChannelEmail, fail: map[string]error{}}
+	tg := &fakeChannel{name: domain.ChannelTelegram, fail: map[string]error{}}
+	for i, cause := range []error{ErrUnreachable, errors.New("bot API down")} {
+		u, _ := store.Users().CreateUser(ctx, domain.User{Email: []string{"asha@example.com", "ravi@example.com"}[i]})
+		if _, err := prefs.Save(ctx, domain.NotificationPreferences{UserID: u.ID, Frequency: domain.FrequencyInstant, Priority: []string{domain.ChannelTelegram}}); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		if err := store.Notifications().Enqueue(ctx, domain.Notification{Type: domain.NotificationPriceAlert, UserID: u.ID}); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+		tg.fail[u.ID] = cause
+	}
+	reg := metrics.NewRegistry()
+	d := NewDispatcher(DispatcherConfig{}, store.Notifications(), store.Users(), logger, tg, email).
+		WithPreferences(prefs, DefaultDigestSchedule()).
+		WithMetrics(reg)
+
+	testhelpers.LogTestStep(logger, "act", "Dispatching")
+	d.Dispatch(ctx)
+	var b strings.Builder
+	if err := reg.WriteText(&b); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Every attempt is counted by channel and result, and timed")
+	for _, line := range []string{
+		`notification_deliveries_total{channel="email",result="delivered"} 2`,
+		`notification_deliveries_total{channel="telegram",result="failed"} 1`,
+		`notification_deliveries_total{channel="telegram",result="unreachable"} 1`,
+		`notification_delivery_duration_seconds_count{channel="telegram"} 2`,
+	} {
+		testhelpers.LogTestAssertion(logger, "series", line, strings.Contains(b.String(), line))
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("Metrics lack %q:\n%s", line, b.String())
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestDispatcher_Metrics", true)
+}
@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"errors"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/metrics"
+)
+
+// WithMetrics exports every delivery attempt to reg, by channel, as
+// notification_deliveries_total{result} and
+// notification_delivery_duration_seconds. It returns d.
+func (d *Dispatcher) WithMetrics(reg *metrics.Registry) *Dispatcher {
+	d.deliveries = reg.Counter("notification_deliveries_total", "Delivery attempts by channel and result: delivered, unreachable or failed.", "channel", "result")
+	d.deliveryTime = reg.Histogram("notification_delivery_duration_seconds", "Time a channel took to accept or refuse a delivery.", metrics.DefaultBuckets, "channel")
+	return d
+}
+
+// observe records an attempt on channel begun at start.
+func (d *Dispatcher) observe(channel string, start time.Time, err error) {
+	if d.deliveries == nil {
+		return
+	}
+	result := "delivered"
+	switch {
+	case errors.Is(err, ErrUnreachable):
+		result = "unreachable"
+	case err != nil:
+		result = "failed"
+	}
+	d.deliveries.Inc(channel, result)
+	d.deliveryTime.Observe(d.now().Sub(start).Seconds(), channel)
+}
@@ -0,0 +1,366 @@
+// Package notify delivers queued notifications to users over the channels
+// they can be reached on. Channels live in subpackages.
+package notify
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/metrics"
+	"github.com/yourusername/whey-price-compare/internal/pool"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// ErrUnreachable is returned by a Channel that has no way to reach the
+// user, e.g. an unverified or suppressed email address. It is not logged as
+// a failure.
+var ErrUnreachable = errors.New("user is not reachable on this channel")
+
+// Channel delivers notifications one way, e.g. by email. The Dispatcher
+// attempts each notification once per channel and, with WithRetries,
+// retries failures per channel too, so one channel's outage cannot
+// duplicate another's messages.
+type Channel interface {
+	Name() string
+	Deliver(ctx context.Context, u domain.User, n domain.Notification) error
+}
+
+// DispatcherConfig configures the Dispatcher.
+type DispatcherConfig struct {
+	// Interval is how often the queue is polled.
+	Interval time.Duration
+	// BatchSize bounds the notifications taken per poll.
+	BatchSize int
+	// ChannelConcurrency bounds each channel's deliveries in flight, by
+	// channel name, when WithPool delivers to several users at once, e.g.
+	// to stay within a provider's rate limit. Channels it leaves out get
+	// DefaultChannelConcurrency.
+	ChannelConcurrency        map[string]int
+	DefaultChannelConcurrency int
+}
+
+// DefaultDispatcherConfig polls every ten seconds, 100 notifications at a
+// time, with four deliveries in flight per channel; two for SMS, whose
+// gateways throttle each sender ID.
+func DefaultDispatcherConfig() DispatcherConfig {
+	return DispatcherConfig{
+		Interval:                  10 * time.Second,
+		BatchSize:                 100,
+		ChannelConcurrency:        map[string]int{domain.ChannelSMS: 2},
+		DefaultChannelConcurrency: 4,
+	}
+}
+
+// Dispatcher drains the notification queue into its channels.
+type Dispatcher struct {
+	cfg      DispatcherConfig
+	queue    repositories.NotificationQueue
+	users    repositories.UserRepository
+	channels []Channel
+	logger   *zap.Logger
+	now      func() time.Time
+
+	prefs    *Preferences
+	schedule DigestSchedule
+
+	failures repositories.DeliveryRepository
+	retry    RetryPolicy
+
+	engagement *Engagement
+
+	pool  *pool.Pool
+	slots map[string]chan struct{} // by channel name
+
+	deliveries   *metrics.Counter
+	deliveryTime *metrics.Histogram
+}
+
+// NewDispatcher creates a Dispatcher delivering over channels. Call Run to
+// start it.
+func NewDispatcher(cfg DispatcherConfig, queue repositories.NotificationQueue, users repositories.UserRepository, logger *zap.Logger, channels ...Channel) *Dispatcher {
+	def := DefaultDispatcherConfig()
+	if cfg.Interval <= 0 {
+		cfg.Interval = def.Interval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = def.BatchSize
+	}
+	if cfg.DefaultChannelConcurrency <= 0 {
+		cfg.DefaultChannelConcurrency = def.DefaultChannelConcurrency
+	}
+	slots := make(map[string]chan struct{}, len(channels))
+	for _, c := range channels {
+		n := cfg.ChannelConcurrency[c.Name()]
+		if n <= 0 {
+			n = cfg.DefaultChannelConcurrency
+		}
+		slots[c.Name()] = make(chan struct{}, n)
+	}
+	return &Dispatcher{cfg: cfg, queue: queue, users: users, channels: channels, logger: logger, now: time.Now, slots: slots}
+}
+
+// WithPreferences honours users' notification preferences: channels and
+// topics they turned off are skipped, price alerts for users who prefer a
+// digest are held until their next one on schedule, for a Digester to
+// gather, and notifications due in a user's quiet hours are deferred until
+// they end. Digests and quiet hours are timed in the user's timezone, or
+// the schedule's. It returns d.
+func (d *Dispatcher) WithPreferences(prefs *Preferences, schedule DigestSchedule) *Dispatcher {
+	d.prefs, d.schedule = prefs, schedule
+	return d
+}
+
+// WithEngagement records each delivery with e, and tags every channel's
+// copy of a message so opens and click-throughs are recorded too. It
+// returns d.
+func (d *Dispatcher) WithEngagement(e *Engagement) *Dispatcher {
+	d.engagement = e
+	return d
+}
+
+// tag returns channel's copy of n, tagged for engagement tracking with
+// WithEngagement.
+func (d *Dispatcher) tag(n domain.Notification, channel string) domain.Notification {
+	if d.engagement == nil {
+		return n
+	}
+	return d.engagement.Tag(n, channel)
+}
+
+// delivered records that channel delivered n, with WithEngagement.
+func (d *Dispatcher) delivered(ctx context.Context, n domain.Notification, channel string) {
+	if d.engagement != nil {
+		d.engagement.Delivered(ctx, n, channel)
+	}
+}
+
+// Run dispatches, then retries failed deliveries, every Interval until ctx
+// is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			d.Dispatch(ctx)
+			d.Retry(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Dispatch delivers pending notifications until the queue is empty and
+// returns how many were processed. A notification is marked sent once
+// every channel the user accepts has attempted it, whether or not any
+// succeeded; failures are logged, and recorded for retry with
+// WithRetries. Alerts for digest users are held
+// instead, notifications in the user's quiet hours are deferred, and
+// notifications on topics the user turned off are dropped; test
+// notifications are always sent at once. Deferred notifications whose
+// quiet hours have ended are released first, to go out in the order they
+// were queued.
+func (d *Dispatcher) Dispatch(ctx context.Context) int {
+	if _, err := d.queue.Release(ctx, d.now()); err != nil {
+		d.logger.Error("Releasing deferred notifications failed", zap.String("operation", "DispatchNotifications"), zap.Error(err))
+		return 0
+	}
+	total := 0
+	for ctx.Err() == nil {
+		batch, err := d.queue.Pending(ctx, d.cfg.BatchSize)
+		if err != nil {
+			d.logger.Error("Loading pending notifications failed", zap.String("operation", "DispatchNotifications"), zap.Error(err))
+			return total
+		}
+		if len(batch) == 0 {
+			return total
+		}
+		ids := make([]string, 0, len(batch))
+		held := make(map[time.Time][]string)
+		deferred := make(map[time.Time][]string)
+		var users []string
+		byUser := make(map[string][]delivery)
+		for _, n := range batch {
+			prefs, ok := d.preferences(ctx, n)
+			if !ok {
+				continue
+			}
+			if !n.Test && !prefs.TopicEnabled(domain.TopicOf(n.Type)) {
+				ids = append(ids, n.ID)
+				continue
+			}
+			if until := d.digestTime(n, prefs); !until.IsZero() {
+				held[until] = append(held[until], n.ID)
+				continue
+			}
+			if until := d.quietUntil(n, prefs); !until.IsZero() {
+				deferred[until] = append(deferred[until], n.ID)
+				continue
+			}
+			if _, ok := byUser[n.UserID]; !ok {
+				users = append(users, n.UserID)
+			}
+			byUser[n.UserID] = append(byUser[n.UserID], delivery{n, prefs})
+		}
+		ids = append(ids, d.fanOut(ctx, users, byUser)...)
+		for until, heldIDs := range held {
+			if err := d.queue.Hold(ctx, until, heldIDs...); err != nil {
+				d.logger.Error("Holding notifications for a digest failed", zap.String("operation", "DispatchNotifications"), zap.Error(err))
+				return total
+			}
+			total += len(heldIDs)
+		}
+		for until, deferredIDs := range deferred {
+			if err := d.queue.Defer(ctx, until, deferredIDs...); err != nil {
+				d.logger.Error("Deferring notifications for quiet hours failed", zap.String("operation", "DispatchNotifications"), zap.Error(err))
+				return total
+			}
+			total += len(deferredIDs)
+		}
+		if len(ids) == 0 {
+			if len(held) == 0 && len(deferred) == 0 {
+				return total
+			}
+			continue
+		}
+		if err := d.queue.MarkSent(ctx, d.now().UTC(), ids...); err != nil {
+			d.logger.Error("Marking notifications sent failed", zap.String("operation", "DispatchNotifications"), zap.Error(err))
+			return total
+		}
+		total += len(ids)
+	}
+	return total
+}
+
+// preferences returns n's recipient's preferences, or the defaults
+// without WithPreferences. It reports false if they could not be read,
+// leaving n queued.
+func (d *Dispatcher) preferences(ctx context.Context, n domain.Notification) (domain.NotificationPreferences, bool) {
+	if d.prefs == nil {
+		return domain.DefaultNotificationPreferences(n.UserID), true
+	}
+	prefs, err := d.prefs.Get(ctx, n.UserID)
+	if err != nil {
+		d.logger.Error("Loading notification preferences failed",
+			zap.String("operation", "DispatchNotifications"),
+			zap.String("notification_id", n.ID),
+			zap.Error(err),
+		)
+		return prefs, false
+	}
+	return prefs, true
+}
+
+// digestTime returns when n's digest goes out, or the zero time to deliver
+// it now.
+func (d *Dispatcher) digestTime(n domain.Notification, prefs domain.NotificationPreferences) time.Time {
+	if d.prefs == nil || n.Type != domain.NotificationPriceAlert || n.Test {
+		return time.Time{}
+	}
+	schedule := d.schedule
+	schedule.Location = prefs.Location(schedule.Location)
+	return schedule.Next(prefs.Frequency, d.now())
+}
+
+// quietUntil returns when the user's quiet hours end if n falls in them,
+// or the zero time to deliver it now. Test notifications are never
+// deferred.
+func (d *Dispatcher) quietUntil(n domain.Notification, prefs domain.NotificationPreferences) time.Time {
+	if d.prefs == nil || n.Test {
+		return time.Time{}
+	}
+	until := prefs.QuietHours.Until(d.now().In(prefs.Location(d.schedule.Location)))
+	if until.IsZero() {
+		return until
+	}
+	return until.UTC()
+}
+
+// deliver fans n out over the channels prefs accept, in the user's
+// priority order. With FanoutFirst it stops at the first channel that
+// delivers, falling back past unreachable and failing ones; a failure is
+// then kept for retry only if no channel delivered, and only the first, so
+// retries cannot duplicate the message either. With FanoutAll, and for
+// test notifications, every channel is attempted. It reports false only
+// when n should stay queued: the user could not be loaded or ctx ended
+// mid-delivery.
+func (d *Dispatcher) deliver(ctx context.Context, n domain.Notification, prefs domain.NotificationPreferences) bool {
+	u, err := d.users.UserByID(ctx, n.UserID)
+	if errors.Is(err, domain.ErrNotFound) {
+		// The account is gone; drop its notifications.
+		return true
+	}
+	if err != nil {
+		d.logger.Error("Loading notification recipient failed",
+			zap.String("operation", "DispatchNotifications"),
+			zap.String("notification_id", n.ID),
+			zap.Error(err),
+		)
+		return false
+	}
+	all := prefs.Fanout == domain.FanoutAll || n.Test
+	var failed Channel
+	var cause error
+	for _, c := range d.channelsFor(prefs) {
+		err := d.send(ctx, c, *u, n)
+		switch {
+		case err == nil:
+			d.delivered(ctx, n, c.Name())
+			d.logger.Debug("Notification delivered",
+				zap.String("operation", "DispatchNotifications"),
+				zap.String("channel", c.Name()),
+				zap.String("notification_id", n.ID),
+			)
+			if all {
+				continue
+			}
+			if failed != nil {
+				d.logger.Warn("Notification delivery failed, delivered on another channel",
+					zap.String("operation", "DispatchNotifications"),
+					zap.String("channel", failed.Name()),
+					zap.String("fallback", c.Name()),
+					zap.String("notification_id", n.ID),
+					zap.Error(cause),
+				)
+			}
+			return true
+		case errors.Is(err, ErrUnreachable):
+		case ctx.Err() != nil:
+			return false
+		case all:
+			d.failed(ctx, c, n, err)
+		case failed == nil:
+			failed, cause = c, err
+		default:
+			d.logFailure(c, n, err)
+		}
+	}
+	if failed != nil {
+		d.failed(ctx, failed, n, cause)
+	}
+	return true
+}
+
+// failed records n's failure on c for retry with WithRetries, and
+// otherwise logs it.
+func (d *Dispatcher) failed(ctx context.Context, c Channel, n domain.Notification, err error) {
+	if d.failures != nil {
+		d.recordFailure(ctx, c, n, err)
+		return
+	}
+	d.logFailure(c, n, err)
+}
+
+func (d *Dispatcher) logFailure(c Channel, n domain.Notification, err error) {
+	d.logger.Error("Notification delivery failed",
+		zap.String("operation", "DispatchNotifications"),
+		zap.String("channel", c.Name()),
+		zap.String("notification_id", n.ID),
+		zap.String("user_id", n.UserID),
+		zap.Error(err),
+	)
+}
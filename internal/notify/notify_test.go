@@ -0,0 +1,246 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// fakeChannel records deliveries and fails for users in fail.
+type fakeChannel struct {
+	name string
+	fail map[string]error
+
+	mu        sync.Mutex
+	delivered []string // notification IDs
+}
+
+func (c *fakeChannel) Name() string { return c.name }
+
+func (c *fakeChannel) Deliver(_ context.Context, u domain.User, n domain.Notification) error {
+	if err := c.fail[u.ID]; err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.delivered = append(c.delivered, n.ID)
+	return nil
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestDispatcher_Dispatch", "internal/notify")
+
+	testhelpers.LogTestStep(logger, "arrange", "Three users, one unreachable by email, and a deleted account")
+	store := memory.NewStore()
+	ctx := t.Context()
+	var ids []string
+	for _, email := range []string{"asha@example.com", "ravi@example.com", "meera@example.com"} {
+		u, err := store.Users().CreateUser(ctx, domain.User{Email: email})
+		if err != nil {
+			t.Fatalf("CreateUser: %v", err)
+		}
+		ids = append(ids, u.ID)
+	}
+	var queued []domain.Notification
+	for _, userID := range append(ids, "user_deleted") {
+		queued = append(queued, domain.Notification{Type: domain.NotificationPriceAlert, UserID: userID})
+	}
+	if err := store.Notifications().Enqueue(ctx, queued...); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	email := &fakeChannel{name: "email", fail: map[string]error{
+		ids[1]: ErrUnreachable,
+		ids[2]: errors.New("provider down"),
+	}}
+	push := &fakeChannel{name: "push"}
+	d := NewDispatcher(DispatcherConfig{BatchSize: 2}, store.Notifications(), store.Users(), logger, email, push)
+
+	testhelpers.LogTestStep(logger, "act", "Dispatching in batches of two")
+	n := d.Dispatch(ctx)
+
+	testhelpers.LogTestStep(logger, "assert", "Every notification went out once, push standing in where email did not deliver")
+	testhelpers.LogTestAssertion(logger, "processed", 4, n)
+	if n != 4 {
+		t.Errorf("Dispatch processed %d, want 4", n)
+	}
+	if pending, _ := store.Notifications().Pending(ctx, 0); len(pending) != 0 {
+		t.Errorf("Still pending: %+v", pending)
+	}
+	if len(email.delivered) != 1 {
+		t.Errorf("Email delivered %v, want only the first user's", email.delivered)
+	}
+	if len(push.delivered) != 2 {
+		t.Errorf("Push delivered %v, want the two users email did not reach", push.delivered)
+	}
+	if again := d.Dispatch(ctx); again != 0 {
+		t.Errorf("Second Dispatch processed %d, want 0", again)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestDispatcher_Dispatch", true)
+}
+
+func TestDispatcher_Preferences(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestDispatcher_Preferences", "internal/notify")
+
+	testhelpers.LogTestStep(logger, "arrange", "One user without email, one without price alerts")
+	store := memory.NewStore()
+	ctx := t.Context()
+	noEmail, _ := store.Users().CreateUser(ctx, domain.User{Email: "asha@example.com"})
+	noAlerts, _ := store.Users().CreateUser(ctx, domain.User{Email: "ravi@example.com"})
+	prefs := NewPreferences(store.Preferences())
+	for _, p := range []domain.NotificationPreferences{
+		{UserID: noEmail.ID, Frequency: domain.FrequencyInstant, Channels: map[string]bool{domain.ChannelEmail: false}},
+		{UserID: noAlerts.ID, Frequency: domain.FrequencyInstant, Topics: map[string]bool{domain.TopicPriceAlerts: false}},
+	} {
+		if _, err := prefs.Save(ctx, p); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+	if err := store.Notifications().Enqueue(ctx,
+		domain.Notification{Type: domain.NotificationPriceAlert, UserID: noEmail.ID},
+		domain.Notification{Type: domain.NotificationPriceAlert, UserID: noAlerts.ID},
+	); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	email := &fakeChannel{name: domain.ChannelEmail}
+	tg := &fakeChannel{name: domain.ChannelTelegram}
+	d := NewDispatcher(DispatcherConfig{}, store.Notifications(), store.Users(), logger, email, tg).
+		WithPreferences(prefs, DefaultDigestSchedule())
+
+	n := d.Dispatch(ctx)
+
+	testhelpers.LogTestAssertion(logger, "processed", 2, n)
+	if n != 2 {
+		t.Errorf("Dispatch processed %d, want 2", n)
+	}
+	if len(email.delivered) != 0 || len(tg.delivered) != 1 {
+		t.Errorf("Delivered by email %v and Telegram %v, want one Telegram message", email.delivered, tg.delivered)
+	}
+	if pending, _ := store.Notifications().Pending(ctx, 0); len(pending) != 0 {
+		t.Errorf("Pending = %+v, want none", pending)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestDispatcher_Preferences", true)
+}
+
+func TestDispatcher_TestNotifications(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestDispatcher_TestNotifications", "internal/notify")
+
+	testhelpers.LogTestStep(logger, "arrange", "A daily digest user and one without price alerts, each with a real and a test alert")
+	store := memory.NewStore()
+	ctx := t.Context()
+	daily, _ := store.Users().CreateUser(ctx, domain.User{Email: "asha@example.com"})
+	noAlerts, _ := store.Users().CreateUser(ctx, domain.User{Email: "ravi@example.com"})
+	prefs := NewPreferences(store.Preferences())
+	for _, p := range []domain.NotificationPreferences{
+		{UserID: daily.ID, Frequency: domain.FrequencyDaily},
+		{UserID: noAlerts.ID, Frequency: domain.FrequencyInstant, Topics: map[string]bool{domain.TopicPriceAlerts: false}},
+	} {
+		if _, err := prefs.Save(ctx, p); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+	for _, userID := range []string{daily.ID, noAlerts.ID} {
+		if err := store.Notifications().Enqueue(ctx,
+			domain.Notification{Type: domain.NotificationPriceAlert, UserID: userID},
+			domain.Notification{Type: domain.NotificationPriceAlert, UserID: userID, Test: true},
+		); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+	email := &fakeChannel{name: domain.ChannelEmail}
+	d := NewDispatcher(DispatcherConfig{}, store.Notifications(), store.Users(), logger, email).
+		WithPreferences(prefs, DefaultDigestSchedule())
+
+	d.Dispatch(ctx)
+
+	testhelpers.LogTestAssertion(logger, "delivered", 2, len(email.delivered))
+	if len(email.delivered) != 2 {
+		t.Fatalf("Delivered %v, want both test alerts", email.delivered)
+	}
+	due, _ := store.Notifications().Due(ctx, time.Now().AddDate(0, 0, 8))
+	if len(due) != 1 || due[0].UserID != daily.ID || due[0].Test {
+		t.Errorf("Held = %+v, want only the daily user's real alert", due)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestDispatcher_TestNotifications", true)
+}
+
+func TestDispatcher_QuietHours(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestDispatcher_QuietHours", "internal/notify")
+
+	testhelpers.LogTestStep(logger, "arrange", "A user in New York with quiet hours overnight, and one without, at 3am New York time")
+	store := memory.NewStore()
+	ctx := t.Context()
+	quiet, _ := store.Users().CreateUser(ctx, domain.User{Email: "asha@example.com"})
+	awake, _ := store.Users().CreateUser(ctx, domain.User{Email: "ravi@example.com"})
+	prefs := NewPreferences(store.Preferences())
+	if _, err := prefs.Save(ctx, domain.NotificationPreferences{
+		UserID:     quiet.ID,
+		Frequency:  domain.FrequencyInstant,
+		Timezone:   "America/New_York",
+		QuietHours: domain.QuietHours{Enabled: true, Start: "22:00", End: "07:00"},
+	}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := store.Notifications().Enqueue(ctx,
+		domain.Notification{Type: domain.NotificationPriceAlert, UserID: quiet.ID, ProductID: "prod_a"},
+		domain.Notification{Type: domain.NotificationPriceAlert, UserID: awake.ID, ProductID: "prod_a"},
+		domain.Notification{Type: domain.NotificationPriceAlert, UserID: quiet.ID, ProductID: "prod_b", Test: true},
+		domain.Notification{Type: domain.NotificationPriceAlert, UserID: quiet.ID, ProductID: "prod_c"},
+	); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+	now := time.Date(2026, 3, 10, 3, 0, 0, 0, ny)
+	email := &fakeChannel{name: domain.ChannelEmail}
+	d := NewDispatcher(DispatcherConfig{}, store.Notifications(), store.Users(), logger, email).
+		WithPreferences(prefs, DefaultDigestSchedule())
+	d.now = func() time.Time { return now }
+
+	testhelpers.LogTestStep(logger, "act", "Dispatching during quiet hours")
+	d.Dispatch(ctx)
+	all, _ := store.Notifications().Pending(ctx, 0)
+	testhelpers.LogTestAssertion(logger, "delivered", 2, len(email.delivered))
+	if len(email.delivered) != 2 || len(all) != 0 {
+		t.Fatalf("Delivered %v with %+v pending, want the other user's alert and the test alert", email.delivered, all)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Dispatching before and when quiet hours end")
+	now = time.Date(2026, 3, 10, 6, 59, 0, 0, ny)
+	if n := d.Dispatch(ctx); n != 0 || len(email.delivered) != 2 {
+		t.Errorf("Dispatch at 6:59 processed %d, delivered %v, want nothing new", n, email.delivered)
+	}
+	now = time.Date(2026, 3, 10, 7, 0, 0, 0, ny)
+	d.Dispatch(ctx)
+
+	testhelpers.LogTestStep(logger, "assert", "Deferred alerts went out in the order they were queued")
+	testhelpers.LogTestAssertion(logger, "delivered", 4, len(email.delivered))
+	if len(email.delivered) != 4 || compareIDs(email.delivered[2], email.delivered[3]) >= 0 {
+		t.Errorf("Delivered %v, want the two deferred alerts last, oldest first", email.delivered)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestDispatcher_QuietHours", true)
+}
+
+// compareIDs orders the memory store's IDs, which count up.
+func compareIDs(a, b string) int {
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,121 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// ErrBadUnsubscribeToken is returned for unsubscribe tokens that were not
+// signed with the current key.
+var ErrBadUnsubscribeToken = fmt.Errorf("invalid unsubscribe link: %w", domain.ErrInvalid)
+
+// Preferences reads and saves users' notification preferences, and signs
+// the unsubscribe links that let recipients change them without signing
+// in.
+type Preferences struct {
+	repo repositories.PreferenceRepository
+	key  []byte
+	now  func() time.Time
+}
+
+// NewPreferences creates a Preferences.
+func NewPreferences(repo repositories.PreferenceRepository) *Preferences {
+	return &Preferences{repo: repo, now: time.Now}
+}
+
+// Get returns userID's preferences, or the defaults if they never saved
+// any.
+func (p *Preferences) Get(ctx context.Context, userID string) (domain.NotificationPreferences, error) {
+	prefs, err := p.repo.NotificationPreferences(ctx, userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.DefaultNotificationPreferences(userID), nil
+	}
+	if err != nil {
+		return domain.NotificationPreferences{}, err
+	}
+	return prefs.WithDefaults(), nil
+}
+
+// Save validates and stores prefs, with their defaults filled in.
+func (p *Preferences) Save(ctx context.Context, prefs domain.NotificationPreferences) (domain.NotificationPreferences, error) {
+	if err := prefs.Validate(); err != nil {
+		return domain.NotificationPreferences{}, err
+	}
+	prefs = prefs.WithDefaults()
+	prefs.UpdatedAt = p.now().UTC()
+	if err := p.repo.SaveNotificationPreferences(ctx, prefs); err != nil {
+		return domain.NotificationPreferences{}, err
+	}
+	return prefs, nil
+}
+
+// WithSigningKey sets the key unsubscribe tokens are signed with. Rotating
+// it breaks the links in messages already sent. It returns p.
+func (p *Preferences) WithSigningKey(key []byte) *Preferences {
+	p.key = key
+	return p
+}
+
+// UnsubscribeToken returns a token that turns channel off for userID. It
+// does not expire, as mail providers expect one-click unsubscribe links to
+// keep working. Without a signing key it returns "".
+func (p *Preferences) UnsubscribeToken(userID, channel string) string {
+	if len(p.key) == 0 {
+		return ""
+	}
+	payload := base64.RawURLEncoding.EncodeToString([]byte(userID + "\x00" + channel))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(p.sign(payload))
+}
+
+// ParseUnsubscribeToken returns the user and channel token was issued for,
+// or ErrBadUnsubscribeToken.
+func (p *Preferences) ParseUnsubscribeToken(token string) (userID, channel string, err error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok || len(p.key) == 0 {
+		return "", "", ErrBadUnsubscribeToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, p.sign(payload)) {
+		return "", "", ErrBadUnsubscribeToken
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", "", ErrBadUnsubscribeToken
+	}
+	userID, channel, ok = strings.Cut(string(raw), "\x00")
+	if !ok || userID == "" || !slices.Contains(domain.Channels, channel) {
+		return "", "", ErrBadUnsubscribeToken
+	}
+	return userID, channel, nil
+}
+
+// Unsubscribe turns off the channel token was issued for and returns the
+// saved preferences.
+func (p *Preferences) Unsubscribe(ctx context.Context, token string) (domain.NotificationPreferences, error) {
+	userID, channel, err := p.ParseUnsubscribeToken(token)
+	if err != nil {
+		return domain.NotificationPreferences{}, err
+	}
+	prefs, err := p.Get(ctx, userID)
+	if err != nil {
+		return domain.NotificationPreferences{}, err
+	}
+	prefs.Channels[channel] = false
+	return p.Save(ctx, prefs)
+}
+
+func (p *Preferences) sign(payload string) []byte {
+	h := hmac.New(sha256.New, p.key)
+	h.Write([]byte("unsubscribe\x00" + payload))
+	return h.Sum(nil)
+}
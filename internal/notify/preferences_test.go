@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestPreferences_UnsubscribeToken(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPreferences_UnsubscribeToken", "internal/notify")
+
+	store := memory.NewStore()
+	prefs := NewPreferences(store.Preferences()).WithSigningKey([]byte("test-only-secret"))
+	other := NewPreferences(store.Preferences()).WithSigningKey([]byte("test-only-other-secret"))
+	token := prefs.UnsubscribeToken("user_1", domain.ChannelEmail)
+	payload, sig, _ := strings.Cut(token, ".")
+
+	testCases := []struct {
+		name    string
+		prefs   *Preferences
+		token   string
+		wantErr error
+	}{
+		{"Valid", prefs, token, nil},
+		{"Signed with another key", other, token, ErrBadUnsubscribeToken},
+		{"No signing key", NewPreferences(store.Preferences()), token, ErrBadUnsubscribeToken},
+		{"Tampered payload", prefs, other.UnsubscribeToken("user_2", domain.ChannelEmail)[:len(payload)] + "." + sig, ErrBadUnsubscribeToken},
+		{"Unknown channel", prefs, prefs.UnsubscribeToken("user_1", "fax"), ErrBadUnsubscribeToken},
+		{"Not a token", prefs, "user_1", ErrBadUnsubscribeToken},
+		{"Empty", prefs, "", ErrBadUnsubscribeToken},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			userID, channel, err := tc.prefs.ParseUnsubscribeToken(tc.token)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("ParseUnsubscribeToken error = %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && (userID != "user_1" || channel != domain.ChannelEmail) {
+				t.Errorf("ParseUnsubscribeToken = %q, %q", userID, channel)
+			}
+		})
+	}
+	if !errors.Is(ErrBadUnsubscribeToken, domain.ErrInvalid) {
+		t.Error("ErrBadUnsubscribeToken is not ErrInvalid")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPreferences_UnsubscribeToken", true)
+}
+
+func TestPreferences_Unsubscribe(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPreferences_Unsubscribe", "internal/notify")
+
+	store := memory.NewStore()
+	ctx := t.Context()
+	prefs := NewPreferences(store.Preferences()).WithSigningKey([]byte("test-only-secret"))
+	if _, err := prefs.Save(ctx, domain.NotificationPreferences{UserID: "user_1", Frequency: domain.FrequencyWeekly}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	if _, err := prefs.Unsubscribe(ctx, prefs.UnsubscribeToken("user_1", domain.ChannelEmail)); err != nil {
+		t.Fatalf("Unsubscribe: %v", err)
+	}
+
+	got, err := prefs.Get(ctx, "user_1")
+	testhelpers.LogTestAssertion(logger, "email enabled", false, got.ChannelEnabled(domain.ChannelEmail))
+	if err != nil || got.ChannelEnabled(domain.ChannelEmail) || !got.ChannelEnabled(domain.ChannelTelegram) || got.Frequency != domain.FrequencyWeekly {
+		t.Errorf("Get = %+v, %v, want weekly with only email off", got, err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPreferences_Unsubscribe", true)
+}
@@ -0,0 +1,230 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// RetryPolicy decides when a failed delivery is tried again.
+type RetryPolicy struct {
+	// MaxAttempts bounds tries per delivery, counting the first; the last
+	// failure dead-letters it.
+	MaxAttempts int
+	// Backoff is the wait before the first retry; it doubles after each,
+	// up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy tries each delivery five times over about a quarter
+// of an hour, waiting one minute and doubling.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 5, Backoff: time.Minute, MaxBackoff: time.Hour}
+}
+
+// Delay returns the wait after the attempts'th failure.
+func (p RetryPolicy) Delay(attempts int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < attempts && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, p.MaxBackoff)
+}
+
+// WithRetries records deliveries that a channel fails in failures and
+// retries them on policy, dead-lettering those that run out of attempts.
+// It returns d.
+func (d *Dispatcher) WithRetries(failures repositories.DeliveryRepository, policy RetryPolicy) *Dispatcher {
+	def := DefaultRetryPolicy()
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = def.MaxAttempts
+	}
+	if policy.Backoff <= 0 {
+		policy.Backoff = def.Backoff
+	}
+	if policy.MaxBackoff < policy.Backoff {
+		policy.MaxBackoff = max(def.MaxBackoff, policy.Backoff)
+	}
+	d.failures, d.retry = failures, policy
+	return d
+}
+
+// recordFailure stores n's first failure on c for retry.
+func (d *Dispatcher) recordFailure(ctx context.Context, c Channel, n domain.Notification, cause error) {
+	now := d.now().UTC()
+	f := domain.FailedDelivery{Notification: n, Channel: c.Name(), FailedAt: now}
+	d.fail(ctx, &f, cause, now)
+}
+
+// fail counts an attempt on f that failed with cause, scheduling the next
+// or dead-lettering f, and saves it.
+func (d *Dispatcher) fail(ctx context.Context, f *domain.FailedDelivery, cause error, now time.Time) {
+	f.Attempts++
+	f.LastError = cause.Error()
+	f.UpdatedAt = now
+	if f.Attempts >= d.retry.MaxAttempts {
+		f.Status, f.NextAttemptAt = domain.DeliveryDead, time.Time{}
+	} else {
+		f.Status, f.NextAttemptAt = domain.DeliveryRetrying, now.Add(d.retry.Delay(f.Attempts))
+	}
+	saved, err := d.failures.SaveFailedDelivery(ctx, *f)
+	if err != nil {
+		d.logger.Error("Recording failed delivery failed",
+			zap.String("operation", "RetryNotifications"),
+			zap.String("channel", f.Channel),
+			zap.String("notification_id", f.Notification.ID),
+			zap.Error(err),
+		)
+		return
+	}
+	fields := []zap.Field{
+		zap.String("operation", "RetryNotifications"),
+		zap.String("channel", f.Channel),
+		zap.String("notification_id", f.Notification.ID),
+		zap.String("delivery_id", saved.ID),
+		zap.Int("attempts", f.Attempts),
+		zap.Error(cause),
+	}
+	if f.Status == domain.DeliveryDead {
+		d.logger.Error("Notification delivery dead-lettered", fields...)
+		return
+	}
+	d.logger.Warn("Notification delivery failed, retrying", append(fields, zap.Time("next_attempt_at", f.NextAttemptAt))...)
+}
+
+// Retry tries the failed deliveries that are due again and returns how
+// many were attempted. Run calls it after each Dispatch.
+func (d *Dispatcher) Retry(ctx context.Context) int {
+	if d.failures == nil {
+		return 0
+	}
+	now := d.now().UTC()
+	due, err := d.failures.DueDeliveries(ctx, now, d.cfg.BatchSize)
+	if err != nil {
+		d.logger.Error("Loading due deliveries failed", zap.String("operation", "RetryNotifications"), zap.Error(err))
+		return 0
+	}
+	attempted := 0
+	for _, f := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		if d.redeliver(ctx, &f, now) {
+			attempted++
+		}
+	}
+	return attempted
+}
+
+// redeliver tries f once more. It reports false if f was left untouched
+// for later.
+func (d *Dispatcher) redeliver(ctx context.Context, f *domain.FailedDelivery, now time.Time) bool {
+	c := d.channel(f.Channel)
+	prefs, ok := d.preferences(ctx, f.Notification)
+	if !ok {
+		return false
+	}
+	u, err := d.users.UserByID(ctx, f.Notification.UserID)
+	switch {
+	case errors.Is(err, domain.ErrNotFound), c == nil, !prefs.ChannelEnabled(f.Channel):
+		// The account or channel is gone, or the user turned it off.
+		d.discard(ctx, f.ID)
+		return true
+	case err != nil:
+		d.logger.Error("Loading notification recipient failed",
+			zap.String("operation", "RetryNotifications"),
+			zap.String("delivery_id", f.ID),
+			zap.Error(err),
+		)
+		return false
+	}
+	err = d.send(ctx, c, *u, f.Notification)
+	switch {
+	case err == nil:
+		d.delivered(ctx, f.Notification, f.Channel)
+		d.logger.Info("Notification delivered on retry",
+			zap.String("operation", "RetryNotifications"),
+			zap.String("channel", f.Channel),
+			zap.String("notification_id", f.Notification.ID),
+			zap.Int("attempts", f.Attempts+1),
+		)
+		d.discard(ctx, f.ID)
+	case errors.Is(err, ErrUnreachable):
+		d.discard(ctx, f.ID)
+	case ctx.Err() != nil:
+		return false
+	default:
+		d.fail(ctx, f, err, now)
+	}
+	return true
+}
+
+func (d *Dispatcher) channel(name string) Channel {
+	for _, c := range d.channels {
+		if c.Name() == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) discard(ctx context.Context, id string) {
+	if err := d.failures.DeleteFailedDelivery(ctx, id); err != nil && !errors.Is(err, domain.ErrNotFound) {
+		d.logger.Error("Removing failed delivery failed",
+			zap.String("operation", "RetryNotifications"),
+			zap.String("delivery_id", id),
+			zap.Error(err),
+		)
+	}
+}
+
+// errNoRetries is returned by the dead-letter methods without WithRetries.
+var errNoRetries = fmt.Errorf("delivery retries are not enabled: %w", domain.ErrNotFound)
+
+// FailedDeliveries returns up to limit failed deliveries with status, or
+// all of them if status is empty, most recently updated first.
+func (d *Dispatcher) FailedDeliveries(ctx context.Context, status string, limit int) ([]domain.FailedDelivery, error) {
+	if d.failures == nil {
+		return nil, errNoRetries
+	}
+	if status != "" && status != domain.DeliveryRetrying && status != domain.DeliveryDead {
+		return nil, fmt.Errorf("status must be %q or %q: %w", domain.DeliveryRetrying, domain.DeliveryDead, domain.ErrInvalid)
+	}
+	return d.failures.FailedDeliveries(ctx, status, limit)
+}
+
+// FailedDelivery returns the failed delivery with id.
+func (d *Dispatcher) FailedDelivery(ctx context.Context, id string) (*domain.FailedDelivery, error) {
+	if d.failures == nil {
+		return nil, errNoRetries
+	}
+	return d.failures.FailedDelivery(ctx, id)
+}
+
+// Replay schedules the failed delivery with id for the next dispatch,
+// with a fresh set of attempts.
+func (d *Dispatcher) Replay(ctx context.Context, id string) (*domain.FailedDelivery, error) {
+	f, err := d.FailedDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := d.now().UTC()
+	f.Status, f.Attempts, f.NextAttemptAt, f.UpdatedAt = domain.DeliveryRetrying, 0, now, now
+	saved, err := d.failures.SaveFailedDelivery(ctx, *f)
+	if err != nil {
+		return nil, err
+	}
+	d.logger.Info("Failed delivery replayed",
+		zap.String("operation", "ReplayNotification"),
+		zap.String("delivery_id", saved.ID),
+		zap.String("channel", saved.Channel),
+	)
+	return &saved, nil
+}
@@ -0,0 +1,190 @@
+package notify
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestRetryPolicy_Delay", "internal/notify")
+
+	p := RetryPolicy{MaxAttempts: 10, Backoff: time.Minute, MaxBackoff: 5 * time.Minute}
+	testCases := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{4, 5 * time.Minute},
+		{9, 5 * time.Minute},
+	}
+	for _, tc := range testCases {
+		got := p.Delay(tc.attempts)
+		testhelpers.LogTestAssertion(logger, "delay", tc.want, got)
+		if got != tc.want {
+			t.Errorf("Delay(%d) = %v, want %v", tc.attempts, got, tc.want)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestRetryPolicy_Delay", true)
+}
+
+func TestDispatcher_Retry(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestDispatcher_Retry", "internal/notify")
+
+	testhelpers.LogTestStep(logger, "arrange", "Email is down for a user who wants every channel; push works")
+	store := memory.NewStore()
+	ctx := t.Context()
+	u, _ := store.Users().CreateUser(ctx, domain.User{Email: "asha@example.com"})
+	prefs := NewPreferences(store.Preferences())
+	if _, err := prefs.Save(ctx, domain.NotificationPreferences{UserID: u.ID, Frequency: domain.FrequencyInstant, Fanout: domain.FanoutAll}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := store.Notifications().Enqueue(ctx, domain.Notification{Type: domain.NotificationPriceAlert, UserID: u.ID}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	email := &fakeChannel{name: domain.ChannelEmail, fail: map[string]error{u.ID: errors.New("smtp: 421 try again later")}}
+	push := &fakeChannel{name: domain.ChannelWebPush}
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	d := NewDispatcher(DispatcherConfig{}, store.Notifications(), store.Users(), logger, email, push).
+		WithPreferences(prefs, DefaultDigestSchedule()).
+		WithRetries(store.Deliveries(), RetryPolicy{MaxAttempts: 3, Backoff: time.Minute, MaxBackoff: time.Hour})
+	d.now = func() time.Time { return now }
+	failures := func(status string) []domain.FailedDelivery {
+		t.Helper()
+		f, err := d.FailedDeliveries(ctx, status, 0)
+		if err != nil {
+			t.Fatalf("FailedDeliveries: %v", err)
+		}
+		return f
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Dispatching records the email failure for retry")
+	d.Dispatch(ctx)
+	retrying := failures(domain.DeliveryRetrying)
+	if len(retrying) != 1 || retrying[0].Channel != domain.ChannelEmail || retrying[0].Attempts != 1 ||
+		!retrying[0].NextAttemptAt.Equal(now.Add(time.Minute)) || retrying[0].LastError != "smtp: 421 try again later" {
+		t.Fatalf("Retrying = %+v", retrying)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Retries wait for their backoff, then dead-letter after the last attempt")
+	steps := []struct {
+		advance       time.Duration
+		wantAttempted int
+		wantStatus    string
+		wantAttempts  int
+	}{
+		{0, 0, domain.DeliveryRetrying, 1},
+		{time.Minute, 1, domain.DeliveryRetrying, 2},
+		{time.Minute, 0, domain.DeliveryRetrying, 2},
+		{time.Minute, 1, domain.DeliveryDead, 3},
+		{time.Hour, 0, domain.DeliveryDead, 3},
+	}
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		attempted := d.Retry(ctx)
+		f := failures("")
+		testhelpers.LogTestAssertion(logger, "attempted", step.wantAttempted, attempted)
+		if attempted != step.wantAttempted || len(f) != 1 || f[0].Status != step.wantStatus || f[0].Attempts != step.wantAttempts {
+			t.Fatalf("At %v: attempted %d, failures %+v; want %d attempted, %s after %d attempts",
+				now, attempted, f, step.wantAttempted, step.wantStatus, step.wantAttempts)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Replaying after the outage delivers it once")
+	dead := failures(domain.DeliveryDead)[0]
+	replayed, err := d.Replay(ctx, dead.ID)
+	if err != nil || replayed.Status != domain.DeliveryRetrying || replayed.Attempts != 0 {
+		t.Fatalf("Replay = %+v, %v", replayed, err)
+	}
+	delete(email.fail, u.ID)
+	if attempted := d.Retry(ctx); attempted != 1 {
+		t.Errorf("Retry after replay attempted %d, want 1", attempted)
+	}
+	if len(email.delivered) != 1 || len(push.delivered) != 1 {
+		t.Errorf("Delivered email %v, push %v; want one each", email.delivered, push.delivered)
+	}
+	if f := failures(""); len(f) != 0 {
+		t.Errorf("Failures after delivery = %+v", f)
+	}
+	if _, err := d.Replay(ctx, dead.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Replaying a delivered failure error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestDispatcher_Retry", true)
+}
+
+func TestDispatcher_RetryDiscards(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestDispatcher_RetryDiscards", "internal/notify")
+
+	store := memory.NewStore()
+	ctx := t.Context()
+	u, _ := store.Users().CreateUser(ctx, domain.User{Email: "asha@example.com"})
+	prefs := NewPreferences(store.Preferences())
+	email := &fakeChannel{name: domain.ChannelEmail}
+	d := NewDispatcher(DispatcherConfig{}, store.Notifications(), store.Users(), logger, email).
+		WithPreferences(prefs, DefaultDigestSchedule()).
+		WithRetries(store.Deliveries(), DefaultRetryPolicy())
+
+	testCases := []struct {
+		name    string
+		userID  string
+		channel string
+		fail    error
+		turnOff bool
+	}{
+		{"Deleted account", "user_deleted", domain.ChannelEmail, nil, false},
+		{"Channel no longer configured", u.ID, domain.ChannelTelegram, nil, false},
+		{"Channel turned off", u.ID, domain.ChannelEmail, nil, true},
+		{"Now unreachable", u.ID, domain.ChannelEmail, ErrUnreachable, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			email.fail = map[string]error{u.ID: tc.fail}
+			p := domain.DefaultNotificationPreferences(u.ID)
+			p.Channels[domain.ChannelEmail] = !tc.turnOff
+			if _, err := prefs.Save(ctx, p); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+			_, err := store.Deliveries().SaveFailedDelivery(ctx, domain.FailedDelivery{
+				Notification:  domain.Notification{ID: "notif_1", Type: domain.NotificationPriceAlert, UserID: tc.userID},
+				Channel:       tc.channel,
+				Status:        domain.DeliveryRetrying,
+				Attempts:      1,
+				NextAttemptAt: time.Now().Add(-time.Minute),
+			})
+			if err != nil {
+				t.Fatalf("SaveFailedDelivery: %v", err)
+			}
+			attempted := d.Retry(ctx)
+			left, _ := d.FailedDeliveries(ctx, "", 0)
+			testhelpers.LogTestAssertion(logger, tc.name, 0, len(left))
+			if attempted != 1 || len(left) != 0 || len(email.delivered) != 0 {
+				t.Errorf("Attempted %d, left %+v, delivered %v; want it discarded undelivered", attempted, left, email.delivered)
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Status filters are validated, and need WithRetries")
+	if _, err := d.FailedDeliveries(ctx, "gone", 0); !errors.Is(err, domain.ErrInvalid) {
+		t.Errorf("Unknown status error = %v, want ErrInvalid", err)
+	}
+	plain := NewDispatcher(DispatcherConfig{}, store.Notifications(), store.Users(), logger, email)
+	if _, err := plain.FailedDeliveries(ctx, "", 0); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Without retries error = %v, want ErrNotFound", err)
+	}
+	if n := plain.Retry(ctx); n != 0 {
+		t.Errorf("Retry without retries attempted %d", n)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestDispatcher_RetryDiscards", true)
+}
@@ -0,0 +1,220 @@
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/services"
+)
+
+// CommandPath receives the /whey slash command.
+const CommandPath = "/api/v1/slack/commands"
+
+// maxCommandBodyBytes is well over the size of a slash command payload.
+const maxCommandBodyBytes = 16 << 10
+
+// maxMatches bounds the products listed when a query matches several.
+const maxMatches = 5
+
+// maxTableName bounds product and retailer names in tables, keeping rows
+// on one line in a chat column.
+const maxTableName = 44
+
+// Register mounts the slash command endpoint on mux.
+func (a *App) Register(mux *http.ServeMux) {
+	mux.Handle("POST "+CommandPath, a)
+}
+
+// ServeHTTP answers one slash command. Requests must carry a valid Slack
+// signature made within MaxRequestAge.
+func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCommandBodyBytes))
+	if err != nil {
+		httpx.WriteError(w, r, http.StatusRequestEntityTooLarge, httpx.CodeBadRequest, "Body too large", nil)
+		return
+	}
+	if err := a.verify(r.Header, body); err != nil {
+		a.logger.Warn("Rejected Slack command", zap.String("operation", "SlackCommand"), zap.Error(err))
+		httpx.WriteError(w, r, http.StatusForbidden, httpx.CodeForbidden, "Invalid signature", nil)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		httpx.WriteError(w, r, http.StatusBadRequest, httpx.CodeBadRequest, "Invalid command", nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, a.answer(r.Context(), form.Get("command"), strings.TrimSpace(form.Get("text"))))
+}
+
+// verify checks the request's signature, an HMAC-SHA256 of its timestamp
+// and body under the signing secret, and that it is fresh.
+func (a *App) verify(h http.Header, body []byte) error {
+	if a.cfg.SigningSecret == "" {
+		return errors.New("no signing secret configured")
+	}
+	ts := h.Get("X-Slack-Request-Timestamp")
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return errors.New("missing timestamp")
+	}
+	if age := a.now().Sub(time.Unix(sec, 0)); age > a.cfg.MaxRequestAge || age < -a.cfg.MaxRequestAge {
+		return fmt.Errorf("timestamp is %s old", age.Round(time.Second))
+	}
+	mac := hmac.New(sha256.New, []byte(a.cfg.SigningSecret))
+	mac.Write([]byte("v0:" + ts + ":"))
+	mac.Write(body)
+	want := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(h.Get("X-Slack-Signature")), []byte(want)) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// answer builds the response to command with query. Price tables are
+// posted in the channel; usage and errors only to the user who asked.
+func (a *App) answer(ctx context.Context, command, query string) message {
+	if command == "" {
+		command = "/whey"
+	}
+	if query == "" || query == "help" {
+		return ephemeral("Send `" + command + "` followed by a product name to see its best prices, e.g. `" + command + " gold standard`.")
+	}
+	products, err := a.catalog.Products.List(ctx, repositories.ProductFilter{})
+	if err != nil {
+		a.logger.Error("Listing products failed", zap.String("operation", "SlackCommand"), zap.Error(err))
+		return ephemeral("Something went wrong. Please try again.")
+	}
+	matches := services.MatchProducts(products, query)
+	if len(matches) == 0 {
+		return ephemeral("No product matches “" + escape(query) + "”.")
+	}
+	if len(matches) == 1 {
+		c, err := a.catalog.Prices.Compare(ctx, matches[0].ID)
+		if err != nil {
+			a.logger.Error("Comparing prices failed", zap.String("operation", "SlackCommand"), zap.Error(err))
+			return ephemeral("Something went wrong. Please try again.")
+		}
+		return a.retailerTable(c)
+	}
+
+	ids := make([]string, 0, maxMatches)
+	for _, p := range matches[:min(len(matches), maxMatches)] {
+		ids = append(ids, p.ID)
+	}
+	comparisons, err := a.catalog.Prices.CompareMany(ctx, ids)
+	if err != nil {
+		a.logger.Error("Comparing prices failed", zap.String("operation", "SlackCommand"), zap.Error(err))
+		return ephemeral("Something went wrong. Please try again.")
+	}
+	return a.productTable(query, comparisons, len(matches))
+}
+
+func ephemeral(text string) message {
+	return message{ResponseType: "ephemeral", Text: text}
+}
+
+// retailerTable lists every retailer's price for one product, best first.
+func (a *App) retailerTable(c *domain.Comparison) message {
+	loc := i18n.Default()
+	name := c.Product.Brand + " " + c.Product.Name
+	if len(c.Prices) == 0 {
+		return message{ResponseType: "in_channel", Text: name + ": no prices yet", Blocks: []block{section("*" + escape(name) + "*\nNo prices yet.")}}
+	}
+	// The title links to the best deal, as alerts do.
+	title := a.mrkdwnLink(c.Prices[0].BuyURL, name)
+	rows := [][]string{{"Retailer", "Price", "₹/g protein", ""}}
+	for _, o := range c.Prices {
+		stock := ""
+		if !o.InStock {
+			stock = "out of stock"
+		}
+		rows = append(rows, []string{truncate(o.RetailerName), loc.FormatPrice(o.Currency, o.Price), perGram(loc, o), stock})
+	}
+	text := name + ": no stock anywhere"
+	if best := c.Prices[0]; best.InStock {
+		text = name + ": best " + loc.FormatPrice(best.Currency, best.Price) + " at " + best.RetailerName
+	}
+	return message{
+		ResponseType: "in_channel",
+		Text:         text,
+		Blocks:       []block{section(title + "\n" + table(rows))},
+	}
+}
+
+// productTable lists the best price of each of several matching products.
+func (a *App) productTable(query string, comparisons []*domain.Comparison, matched int) message {
+	loc := i18n.Default()
+	rows := [][]string{{"Product", "Best price", "₹/g protein", "Retailer"}}
+	for _, c := range comparisons {
+		row := []string{truncate(c.Product.Brand + " " + c.Product.Name), "out of stock", "", ""}
+		if len(c.Prices) > 0 && c.Prices[0].InStock {
+			o := c.Prices[0]
+			row[1], row[2], row[3] = loc.FormatPrice(o.Currency, o.Price), perGram(loc, o), truncate(o.RetailerName)
+		}
+		rows = append(rows, row)
+	}
+	heading := fmt.Sprintf("Best prices for “%s”", escape(query))
+	if matched > len(comparisons) {
+		heading += fmt.Sprintf(" (%d of %d matches, narrow your search for more)", len(comparisons), matched)
+	}
+	return message{
+		ResponseType: "in_channel",
+		Text:         strings.ReplaceAll(heading, "*", ""),
+		Blocks:       []block{section("*" + heading + "*\n" + table(rows))},
+	}
+}
+
+func perGram(loc *i18n.Locale, o domain.Offer) string {
+	if o.PricePerGramProtein <= 0 {
+		return ""
+	}
+	return loc.FormatPrice(o.Currency, o.PricePerGramProtein)
+}
+
+func truncate(s string) string {
+	if r := []rune(s); len(r) > maxTableName {
+		return strings.TrimSpace(string(r[:maxTableName-1])) + "…"
+	}
+	return s
+}
+
+// table renders rows as a code block with aligned columns. Slack shows
+// code blocks in a monospace font, and does not format text inside them.
+func table(rows [][]string) string {
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len([]rune(cell)))
+		}
+	}
+	var b strings.Builder
+	b.WriteString("```\n")
+	for _, row := range rows {
+		var line strings.Builder
+		for i, cell := range row {
+			if i > 0 {
+				line.WriteString("  ")
+			}
+			line.WriteString(cell + strings.Repeat(" ", widths[i]-len([]rune(cell))))
+		}
+		b.WriteString(escape(strings.TrimRight(line.String(), " ")) + "\n")
+	}
+	b.WriteString("```")
+	return b.String()
+}
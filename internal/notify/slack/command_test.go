@@ -0,0 +1,135 @@
+package slack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+const testSigningSecret = "test-only-signing-secret"
+
+func newCommandApp(t *testing.T, now time.Time) *App {
+	t.Helper()
+	logger := testhelpers.SetupTestLogger(t)
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, now)
+	prices := services.NewPriceService(services.PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+	a := NewApp(Config{SigningSecret: testSigningSecret, SiteURL: "https://whey.example/"},
+		store.Slack(), Catalog{Products: store.Products(), Prices: prices}, logger)
+	a.now = func() time.Time { return now }
+	return a
+}
+
+// commandRequest builds a slash command request signed with secret at ts.
+func commandRequest(secret string, ts time.Time, text string) *http.Request {
+	body := url.Values{"command": {"/whey"}, "text": {text}, "team_id": {"T0001"}}.Encode()
+	stamp := strconv.FormatInt(ts.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + stamp + ":" + body))
+	r := httptest.NewRequest(http.MethodPost, CommandPath, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("X-Slack-Request-Timestamp", stamp)
+	r.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return r
+}
+
+func TestApp_Command(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestApp_Command", "internal/notify/slack")
+
+	now := time.Now()
+	a := newCommandApp(t, now)
+	mux := http.NewServeMux()
+	a.Register(mux)
+
+	testCases := []struct {
+		name         string
+		secret       string
+		ts           time.Time
+		text         string
+		wantStatus   int
+		wantType     string
+		wantContains []string
+		wantAbsent   []string
+	}{
+		{"One product, every retailer", testSigningSecret, now, "gold STANDARD", http.StatusOK, "in_channel",
+			[]string{"Gold Standard 100% Whey", "```", "Retailer", "Flipkart", "Amazon India", "₹3,199", "https://whey.example/go/"}, nil},
+		{"Several products, best prices", testSigningSecret, now, "whey", http.StatusOK, "in_channel",
+			[]string{"Best prices for “whey”", "Product", "Gold Standard 100% Whey", "Biozyme Performance Whey", "₹2,099"}, nil},
+		{"No match", testSigningSecret, now, "<casein>", http.StatusOK, "ephemeral", []string{"&lt;casein&gt;"}, []string{"<casein>"}},
+		{"No query", testSigningSecret, now, "", http.StatusOK, "ephemeral", []string{"/whey gold standard"}, nil},
+		{"Wrong secret", "test-only-other-secret", now, "whey", http.StatusForbidden, "", nil, []string{"Gold"}},
+		{"Stale request", testSigningSecret, now.Add(-10 * time.Minute), "whey", http.StatusForbidden, "", nil, []string{"Gold"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, commandRequest(tc.secret, tc.ts, tc.text))
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantStatus, rec.Code)
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("Status = %d, want %d: %s", rec.Code, tc.wantStatus, rec.Body)
+			}
+			if tc.wantType != "" {
+				var m message
+				if err := json.Unmarshal(rec.Body.Bytes(), &m); err != nil || m.ResponseType != tc.wantType {
+					t.Errorf("Response = %s, want response_type %q", rec.Body, tc.wantType)
+				}
+			}
+			// Undo JSON's HTML escaping so the checks see what Slack does.
+			var body any
+			_ = json.Unmarshal(rec.Body.Bytes(), &body)
+			var buf strings.Builder
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(false)
+			_ = enc.Encode(body)
+			plain := buf.String()
+			for _, s := range tc.wantContains {
+				if !strings.Contains(plain, s) {
+					t.Errorf("Response = %s, want it to contain %q", plain, s)
+				}
+			}
+			for _, s := range tc.wantAbsent {
+				if strings.Contains(plain, s) {
+					t.Errorf("Response = %s, want it not to contain %q", plain, s)
+				}
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestApp_Command", true)
+}
+
+func TestTable(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestTable", "internal/notify/slack")
+
+	got := table([][]string{{"Retailer", "Price", ""}, {"Flipkart", "₹3,199", ""}, {"HK", "₹12,499", "out of stock"}})
+	want := "```\n" +
+		"Retailer  Price\n" +
+		"Flipkart  ₹3,199\n" +
+		"HK        ₹12,499  out of stock\n" +
+		"```"
+	testhelpers.LogTestAssertion(logger, "table", want, got)
+	if got != want {
+		t.Errorf("table =\n%s\nwant\n%s", got, want)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestTable", true)
+}
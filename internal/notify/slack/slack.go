@@ -0,0 +1,352 @@
+// Package slack posts price alerts to Slack channels through incoming
+// webhooks and answers the /whey slash command with a table of current
+// best prices, for gym and office workspaces that share deals in a
+// channel.
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+	"github.com/yourusername/whey-price-compare/internal/notify/templates"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/services"
+)
+
+// Config configures the App.
+type Config struct {
+	// SigningSecret verifies slash command requests. Commands are not
+	// served without it.
+	SigningSecret string
+	// SiteURL is the public site root that links in messages point at.
+	SiteURL string
+	// Hosts are the hosts webhook URLs may be on.
+	Hosts []string
+	// MaxRequestAge is how old a slash command request may be, bounding
+	// replays of a captured one.
+	MaxRequestAge time.Duration
+	// Timeout bounds each request to Slack.
+	Timeout time.Duration
+}
+
+// DefaultConfig accepts webhooks on hooks.slack.com and slash commands
+// signed in the last five minutes, as Slack recommends.
+func DefaultConfig() Config {
+	return Config{
+		Hosts:         []string{"hooks.slack.com"},
+		MaxRequestAge: 5 * time.Minute,
+		Timeout:       10 * time.Second,
+	}
+}
+
+// Catalog is what the app reads to answer /whey.
+type Catalog struct {
+	Products repositories.ProductRepository
+	Prices   *services.PriceService
+}
+
+// errGone means Slack no longer accepts posts to the webhook.
+var errGone = errors.New("slack webhook revoked")
+
+// App stores users' webhooks, answers slash commands and implements
+// notify.Channel.
+type App struct {
+	cfg       Config
+	repo      repositories.SlackRepository
+	catalog   Catalog
+	templates *templates.Engine
+	client    *http.Client
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewApp creates an App.
+func NewApp(cfg Config, repo repositories.SlackRepository, catalog Catalog, logger *zap.Logger) *App {
+	def := DefaultConfig()
+	if len(cfg.Hosts) == 0 {
+		cfg.Hosts = def.Hosts
+	}
+	if cfg.MaxRequestAge <= 0 {
+		cfg.MaxRequestAge = def.MaxRequestAge
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	cfg.SiteURL = strings.TrimRight(cfg.SiteURL, "/")
+	return &App{
+		cfg:       cfg,
+		repo:      repo,
+		catalog:   catalog,
+		templates: templates.Default(),
+		client:    &http.Client{Timeout: cfg.Timeout},
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// WithTemplates renders alerts with e rather than the latest built-in
+// templates. It returns a.
+func (a *App) WithTemplates(e *templates.Engine) *App {
+	a.templates = e
+	return a
+}
+
+// CommandsEnabled reports whether the app can verify slash commands.
+func (a *App) CommandsEnabled() bool { return a.cfg.SigningSecret != "" }
+
+// Connect checks rawURL is a live Slack incoming webhook by posting a
+// confirmation to its channel, and saves it as userID's, replacing any
+// other. Bad or revoked webhooks are ErrInvalid.
+func (a *App) Connect(ctx context.Context, userID, rawURL string) (*domain.SlackWebhook, error) {
+	webhookURL, teamID, err := a.checkURL(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", err, domain.ErrInvalid)
+	}
+	err = a.post(ctx, webhookURL, message{
+		Text: "Whey Price Compare is connected. Price alerts will be posted in this channel.",
+	})
+	if errors.Is(err, errGone) {
+		return nil, fmt.Errorf("slack does not accept this webhook: %w", domain.ErrInvalid)
+	}
+	if err != nil {
+		return nil, err
+	}
+	w := domain.SlackWebhook{UserID: userID, URL: webhookURL, TeamID: teamID, CreatedAt: a.now().UTC()}
+	if err := a.repo.SaveSlackWebhook(ctx, w); err != nil {
+		return nil, err
+	}
+	a.logger.Info("Slack webhook connected",
+		zap.String("operation", "SlackConnect"),
+		zap.String("user_id", userID),
+		zap.String("team_id", teamID),
+	)
+	return &w, nil
+}
+
+// Webhook returns userID's webhook, or domain.ErrNotFound.
+func (a *App) Webhook(ctx context.Context, userID string) (*domain.SlackWebhook, error) {
+	return a.repo.SlackWebhook(ctx, userID)
+}
+
+// Disconnect forgets userID's webhook.
+func (a *App) Disconnect(ctx context.Context, userID string) error {
+	return a.repo.DeleteSlackWebhook(ctx, userID)
+}
+
+// Connected reports whether userID has a webhook. It fits
+// alerts.ReachableFunc.
+func (a *App) Connected(ctx context.Context, userID string) (bool, error) {
+	_, err := a.repo.SlackWebhook(ctx, userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// checkURL accepts https incoming webhook URLs on the configured hosts,
+// returning them without query or fragment and with the workspace ID
+// they name.
+func (a *App) checkURL(raw string) (webhookURL, teamID string, err error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return "", "", errors.New("webhook_url must be an https URL")
+	}
+	known := false
+	for _, h := range a.cfg.Hosts {
+		if u.Hostname() == h {
+			known = true
+			break
+		}
+	}
+	// /services/{team}/{bot}/{token}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if !known || len(parts) != 4 || parts[0] != "services" || parts[1] == "" || parts[2] == "" || parts[3] == "" {
+		return "", "", errors.New("webhook_url is not a Slack incoming webhook URL")
+	}
+	return u.Scheme + "://" + u.Host + "/" + strings.Join(parts, "/"), parts[1], nil
+}
+
+// Name implements notify.Channel.
+func (a *App) Name() string { return domain.ChannelSlack }
+
+// Deliver implements notify.Channel. Users without a webhook are
+// unreachable, as are those whose webhook was revoked or whose channel
+// was archived or deleted; those webhooks are forgotten.
+func (a *App) Deliver(ctx context.Context, u domain.User, n domain.Notification) error {
+	var msg message
+	var err error
+	switch n.Type {
+	case domain.NotificationPriceAlert:
+		msg, err = a.alertMessage(n)
+	case domain.NotificationPriceAlertDigest:
+		msg, err = a.digestMessage(n.Items)
+	default:
+		return fmt.Errorf("no slack message for notification type %q", n.Type)
+	}
+	if err != nil {
+		return err
+	}
+	w, err := a.repo.SlackWebhook(ctx, u.ID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return notify.ErrUnreachable
+	}
+	if err != nil {
+		return fmt.Errorf("load slack webhook: %w", err)
+	}
+
+	err = a.post(ctx, w.URL, msg)
+	if errors.Is(err, errGone) {
+		if err := a.repo.DeleteSlackWebhook(ctx, u.ID); err != nil {
+			return fmt.Errorf("forget revoked slack webhook: %w", err)
+		}
+		a.logger.Info("Slack webhook revoked, forgotten",
+			zap.String("operation", "SlackDeliver"),
+			zap.String("user_id", u.ID),
+			zap.String("team_id", w.TeamID),
+		)
+		return notify.ErrUnreachable
+	}
+	return err
+}
+
+// message is a Slack message, posted to a webhook or returned as a slash
+// command response. Text is the notification fallback for Blocks.
+type message struct {
+	ResponseType string  `json:"response_type,omitempty"`
+	Text         string  `json:"text"`
+	Blocks       []block `json:"blocks,omitempty"`
+}
+
+type block struct {
+	Type      string     `json:"type"`
+	Text      *textObj   `json:"text,omitempty"`
+	Accessory *accessory `json:"accessory,omitempty"`
+	Elements  []textObj  `json:"elements,omitempty"`
+}
+
+type textObj struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type accessory struct {
+	Type     string `json:"type"`
+	ImageURL string `json:"image_url"`
+	AltText  string `json:"alt_text"`
+}
+
+func section(mrkdwn string) block {
+	return block{Type: "section", Text: &textObj{Type: "mrkdwn", Text: mrkdwn}}
+}
+
+func contextBlock(mrkdwn string) block {
+	return block{Type: "context", Elements: []textObj{{Type: "mrkdwn", Text: mrkdwn}}}
+}
+
+// maxDigestItems keeps digests well under Slack's 50 block limit.
+const maxDigestItems = 20
+
+func (a *App) alertMessage(n domain.Notification) (message, error) {
+	alert := templates.NewAlert(n, a.cfg.SiteURL)
+	m, err := a.templates.Render(templates.PriceAlert, templates.Slack, alert)
+	if err != nil {
+		return message{}, err
+	}
+	return message{
+		Text:   m.Title + ": " + n.ProductName,
+		Blocks: []block{contextBlock(m.Title), alertSection(m.Body, alert)},
+	}, nil
+}
+
+// alertSection shows an alert's rendered body beside the product image.
+func alertSection(body string, alert templates.Alert) block {
+	b := section(body)
+	if strings.HasPrefix(alert.ImageURL, "https://") {
+		b.Accessory = &accessory{Type: "image", ImageURL: alert.ImageURL, AltText: alert.ProductName}
+	}
+	return b
+}
+
+// digestMessage gives each item its own section, up to maxDigestItems.
+func (a *App) digestMessage(items []domain.Notification) (message, error) {
+	d := templates.NewDigest(items, maxDigestItems, a.cfg.SiteURL)
+	heading, err := a.templates.Render(templates.PriceAlertDigest, templates.Slack, d)
+	if err != nil {
+		return message{}, err
+	}
+	msg := message{Text: strings.ReplaceAll(heading.Title, "*", ""), Blocks: []block{section(heading.Title)}}
+	for _, item := range d.Items {
+		m, err := a.templates.Render(templates.PriceAlert, templates.Slack, item)
+		if err != nil {
+			return message{}, err
+		}
+		msg.Blocks = append(msg.Blocks, alertSection(m.Body, item))
+	}
+	return msg, nil
+}
+
+// mrkdwnLink renders text as a bold link to path.
+func (a *App) mrkdwnLink(path, text string) string {
+	if link := a.link(path); link != "" {
+		return "*<" + link + "|" + escape(text) + ">*"
+	}
+	return "*" + escape(text) + "*"
+}
+
+func (a *App) link(path string) string {
+	if strings.HasPrefix(path, "/") {
+		return a.cfg.SiteURL + path
+	}
+	return path
+}
+
+// escape escapes the characters Slack's mrkdwn treats as control
+// sequences.
+func escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+func (a *App) post(ctx context.Context, webhookURL string, msg message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := a.client.Do(req)
+	if err != nil {
+		// The webhook URL is its own credential; keep it out of errors
+		// and so out of logs.
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return fmt.Errorf("slack: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	// invalid_token, no_service, channel_not_found, channel_is_archived.
+	case resp.StatusCode == http.StatusForbidden, resp.StatusCode == http.StatusNotFound, resp.StatusCode == http.StatusGone:
+		return errGone
+	default:
+		return fmt.Errorf("slack returned %d", resp.StatusCode)
+	}
+}
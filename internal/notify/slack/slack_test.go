@@ -0,0 +1,202 @@
+package slack
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// fakeSlack accepts posts to the webhooks in hooks by token, answering
+// others with 404 no_service, and records the messages posted to them.
+type fakeSlack struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	hooks  map[string]bool
+	posted []message
+}
+
+func newFakeSlack(t *testing.T, tokens ...string) *fakeSlack {
+	t.Helper()
+	f := &fakeSlack{hooks: map[string]bool{}}
+	for _, tok := range tokens {
+		f.hooks[tok] = true
+	}
+	f.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		// /services/{team}/{bot}/{token}
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if r.Method != http.MethodPost || len(parts) != 4 || !f.hooks[parts[3]] {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, "no_service")
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var m message
+		if err := json.Unmarshal(body, &m); err != nil || m.Text == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, "invalid_payload")
+			return
+		}
+		f.posted = append(f.posted, m)
+		_, _ = io.WriteString(w, "ok")
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeSlack) revoke(token string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.hooks, token)
+}
+
+func (f *fakeSlack) hookURL(token string) string {
+	return f.URL + "/services/T0001/B0001/" + token
+}
+
+func newTestApp(t *testing.T, f *fakeSlack) (*App, *memory.Store) {
+	t.Helper()
+	store := memory.NewStore()
+	a := NewApp(Config{SiteURL: "https://whey.example/", Hosts: []string{"127.0.0.1"}}, store.Slack(), Catalog{}, testhelpers.SetupTestLogger(t))
+	if f != nil {
+		a.client = f.Client()
+	}
+	return a, store
+}
+
+func TestApp_Connect(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestApp_Connect", "internal/notify/slack")
+
+	f := newFakeSlack(t, "test-only-token")
+	a, _ := newTestApp(t, f)
+	testCases := []struct {
+		name    string
+		url     string
+		wantErr error
+	}{
+		{"Webhook", f.hookURL("test-only-token"), nil},
+		{"Revoked webhook", f.hookURL("test-only-revoked"), domain.ErrInvalid},
+		{"Not https", strings.Replace(f.hookURL("test-only-token"), "https://", "http://", 1), domain.ErrInvalid},
+		{"Other host", "https://example.com/services/T0001/B0001/test-only-token", domain.ErrInvalid},
+		{"Not a webhook path", f.URL + "/services/T0001", domain.ErrInvalid},
+		{"Empty", "", domain.ErrInvalid},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := a.Connect(t.Context(), "user_1", tc.url)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("Connect error = %v, want %v", err, tc.wantErr)
+				}
+				if err != nil && strings.Contains(err.Error(), "test-only") {
+					t.Errorf("Connect error = %v exposes the webhook token", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Connect: %v", err)
+			}
+			if w.TeamID != "T0001" || w.URL != f.hookURL("test-only-token") {
+				t.Errorf("Webhook = %+v", w)
+			}
+		})
+	}
+	if len(f.posted) != 1 || !strings.Contains(f.posted[0].Text, "connected") {
+		t.Errorf("Posted = %+v, want one confirmation", f.posted)
+	}
+	if ok, err := a.Connected(t.Context(), "user_1"); !ok || err != nil {
+		t.Errorf("Connected = %v, %v; want true", ok, err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestApp_Connect", true)
+}
+
+func TestApp_Deliver(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestApp_Deliver", "internal/notify/slack")
+
+	testhelpers.LogTestStep(logger, "arrange", "A user with a webhook and one without")
+	f := newFakeSlack(t, "test-only-token")
+	a, _ := newTestApp(t, f)
+	ctx := t.Context()
+	if _, err := a.Connect(ctx, "user_1", f.hookURL("test-only-token")); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	n := domain.Notification{
+		Type: domain.NotificationPriceAlert, UserID: "user_1",
+		ProductName: "Gold Standard 100% Whey", ImageURL: "https://cdn.example/gsw.jpg",
+		RetailerName: "Flipkart", Price: 2899, PreviousPrice: 3199, PricePerGram: 1.62,
+		URL: "/go/flipkart/prod_on_gsw/lst_1", CreatedAt: time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC),
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Delivering an alert")
+	if err := a.Deliver(ctx, domain.User{ID: "user_1"}, n); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	m := f.posted[len(f.posted)-1]
+	if len(m.Blocks) != 2 || m.Blocks[1].Text == nil {
+		t.Fatalf("Message = %+v, want a heading and a section", m)
+	}
+	got := m.Blocks[1].Text.Text
+	want := "*<https://whey.example/go/flipkart/prod_on_gsw/lst_1|Gold Standard 100% Whey>*\n~₹3,199~ *₹2,899* at Flipkart · ₹1.62/g protein"
+	testhelpers.LogTestAssertion(logger, "section", want, got)
+	if got != want {
+		t.Errorf("Section = %q, want %q", got, want)
+	}
+	if acc := m.Blocks[1].Accessory; acc == nil || acc.ImageURL != n.ImageURL {
+		t.Errorf("Accessory = %+v, want the product image", acc)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Delivering a digest and to a user without a webhook")
+	digest := domain.Notification{Type: domain.NotificationPriceAlertDigest, UserID: "user_1", Items: []domain.Notification{n, n}}
+	if err := a.Deliver(ctx, domain.User{ID: "user_1"}, digest); err != nil {
+		t.Fatalf("Deliver digest: %v", err)
+	}
+	if got := f.posted[len(f.posted)-1]; len(got.Blocks) != 3 || !strings.Contains(got.Text, "2 price alerts") {
+		t.Errorf("Digest = %+v, want a heading and two sections", got)
+	}
+	if err := a.Deliver(ctx, domain.User{ID: "user_2"}, n); !errors.Is(err, notify.ErrUnreachable) {
+		t.Errorf("Deliver without webhook = %v, want ErrUnreachable", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Delivering after the webhook was revoked in Slack")
+	f.revoke("test-only-token")
+	err := a.Deliver(ctx, domain.User{ID: "user_1"}, n)
+	testhelpers.LogTestAssertion(logger, "revoked webhook", notify.ErrUnreachable, err)
+	if !errors.Is(err, notify.ErrUnreachable) {
+		t.Errorf("Deliver to revoked webhook = %v, want ErrUnreachable", err)
+	}
+	if ok, _ := a.Connected(ctx, "user_1"); ok {
+		t.Error("Revoked webhook was not forgotten")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestApp_Deliver", true)
+}
+
+func TestEscape(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestEscape", "internal/notify/slack")
+
+	got := escape("<!channel> Whey & Co")
+	testhelpers.LogTestAssertion(logger, "escaped", "&lt;!channel&gt; Whey &amp; Co", got)
+	if got != "&lt;!channel&gt; Whey &amp; Co" {
+		t.Errorf("escape = %q", got)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestEscape", true)
+}
@@ -0,0 +1,94 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// MSG91Config configures the MSG91 provider. Indian regulations (DLT)
+// require registered templates, so MSG91 sends a template per message kind
+// with the message's Vars filled in rather than its Body.
+type MSG91Config struct {
+	AuthKey string
+	// AlertTemplateID is a flow with ##product##, ##price##, ##retailer##
+	// and ##url## variables.
+	AlertTemplateID string
+	// CodeTemplateID is a flow with a ##code## variable.
+	CodeTemplateID string
+	// APIURL is the API root, overridable for tests.
+	APIURL  string
+	Timeout time.Duration
+}
+
+// MSG91 sends texts through MSG91's Flow API.
+type MSG91 struct {
+	cfg  MSG91Config
+	http *http.Client
+}
+
+// NewMSG91 creates an MSG91 provider.
+func NewMSG91(cfg MSG91Config) *MSG91 {
+	if cfg.APIURL == "" {
+		cfg.APIURL = "https://control.msg91.com"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	cfg.APIURL = strings.TrimRight(cfg.APIURL, "/")
+	return &MSG91{cfg: cfg, http: &http.Client{Timeout: cfg.Timeout}}
+}
+
+// Name implements Provider.
+func (p *MSG91) Name() string { return "msg91" }
+
+// Send implements Provider.
+func (p *MSG91) Send(ctx context.Context, m Message) error {
+	template := p.cfg.AlertTemplateID
+	if m.Kind == KindCode {
+		template = p.cfg.CodeTemplateID
+	}
+	recipient := map[string]string{"mobiles": strings.TrimPrefix(m.To, "+")}
+	for k, v := range m.Vars {
+		recipient[k] = v
+	}
+	payload, err := json.Marshal(map[string]any{
+		"template_id": template,
+		"short_url":   "0",
+		"recipients":  []map[string]string{recipient},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.APIURL+"/api/v5/flow/", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("authkey", p.cfg.AuthKey)
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return &SendError{Provider: "msg91", Status: http.StatusServiceUnavailable, Message: transportError(err)}
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	var body struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(raw, &body)
+	if resp.StatusCode/100 == 2 && body.Type != "error" {
+		return nil
+	}
+	status := resp.StatusCode
+	if status/100 == 2 {
+		// MSG91 reports some rejections with 200 and type "error".
+		status = http.StatusBadRequest
+	}
+	return &SendError{Provider: "msg91", Status: status, Message: body.Message}
+}
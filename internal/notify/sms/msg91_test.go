@@ -0,0 +1,67 @@
+package sms
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestMSG91_Send(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestMSG91_Send", "internal/notify/sms")
+
+	testCases := []struct {
+		name         string
+		msg          Message
+		status       int
+		response     string
+		wantTemplate string
+		wantErr      bool
+	}{
+		{"Alert", Message{To: "+919812345678", Kind: KindAlert, Vars: map[string]string{"price": "Rs.2,899"}}, http.StatusOK, `{"type":"success","message":"3763646c3058"}`, "tmpl_alert", false},
+		{"Code", Message{To: "+919812345678", Kind: KindCode, Vars: map[string]string{"code": "123456"}}, http.StatusOK, `{"type":"success"}`, "tmpl_code", false},
+		{"Error with 200", Message{To: "+919812345678", Kind: KindAlert}, http.StatusOK, `{"type":"error","message":"Template not approved"}`, "tmpl_alert", true},
+		{"Unauthorized", Message{To: "+919812345678", Kind: KindAlert}, http.StatusUnauthorized, `{"type":"error","message":"Authentication failure"}`, "tmpl_alert", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					TemplateID string              `json:"template_id"`
+					Recipients []map[string]string `json:"recipients"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("Decode: %v", err)
+				}
+				if r.URL.Path != "/api/v5/flow/" || r.Header.Get("authkey") != "test-only-secret" || body.TemplateID != tc.wantTemplate {
+					t.Errorf("Request %s template %q", r.URL.Path, body.TemplateID)
+				}
+				if len(body.Recipients) != 1 || body.Recipients[0]["mobiles"] != "919812345678" {
+					t.Errorf("Recipients = %v", body.Recipients)
+				}
+				for k, v := range tc.msg.Vars {
+					if body.Recipients[0][k] != v {
+						t.Errorf("Var %s = %q, want %q", k, body.Recipients[0][k], v)
+					}
+				}
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.response))
+			}))
+			defer srv.Close()
+
+			p := NewMSG91(MSG91Config{AuthKey: "test-only-secret", AlertTemplateID: "tmpl_alert", CodeTemplateID: "tmpl_code", APIURL: srv.URL})
+			err := p.Send(t.Context(), tc.msg)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err != nil)
+			var serr *SendError
+			if tc.wantErr != errors.As(err, &serr) || tc.wantErr && serr.Retryable() {
+				t.Errorf("Send error = %v, want error %v and not retryable", err, tc.wantErr)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestMSG91_Send", true)
+}
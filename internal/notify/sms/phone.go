@@ -0,0 +1,55 @@
+package sms
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// nationalNumber checks the digits after a country code for countries
+// whose numbering we know; others only get E.164's overall length check.
+var nationalNumber = map[string]func(string) bool{
+	// Indian mobile numbers are ten digits starting 6 to 9.
+	"91": func(n string) bool { return len(n) == 10 && n[0] >= '6' && n[0] <= '9' },
+}
+
+// NormalizePhone returns number in E.164 form, e.g. "+919812345678".
+// Spaces, dashes, dots and parentheses are ignored and a leading 00 reads
+// as +. Numbers without a country code are taken to be in defaultCode,
+// dropping a trunk 0. Only numbers in countries, a list of calling codes
+// such as "91", are accepted.
+func NormalizePhone(number, defaultCode string, countries []string) (string, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(number))
+	if rest, ok := strings.CutPrefix(cleaned, "00"); ok {
+		cleaned = "+" + rest
+	}
+	digits, international := strings.CutPrefix(cleaned, "+")
+	if !international {
+		if defaultCode == "" {
+			return "", fmt.Errorf("phone number needs a country code, e.g. +91: %w", domain.ErrInvalid)
+		}
+		digits = defaultCode + strings.TrimPrefix(digits, "0")
+	}
+	if len(digits) < 8 || len(digits) > 15 || strings.Trim(digits, "0123456789") != "" || digits[0] == '0' {
+		return "", fmt.Errorf("phone number is not valid: %w", domain.ErrInvalid)
+	}
+	for _, code := range countries {
+		national, ok := strings.CutPrefix(digits, code)
+		if !ok {
+			continue
+		}
+		if valid := nationalNumber[code]; valid != nil && !valid(national) {
+			return "", fmt.Errorf("phone number is not a valid +%s mobile number: %w", code, domain.ErrInvalid)
+		}
+		return "+" + digits, nil
+	}
+	return "", fmt.Errorf("SMS alerts are only available for numbers starting +%s: %w",
+		strings.Join(countries, ", +"), domain.ErrInvalid)
+}
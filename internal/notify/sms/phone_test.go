@@ -0,0 +1,50 @@
+package sms
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestNormalizePhone", "internal/notify/sms")
+
+	testCases := []struct {
+		name        string
+		number      string
+		defaultCode string
+		want        string
+		wantErr     error
+	}{
+		{"E.164", "+919812345678", "91", "+919812345678", nil},
+		{"Formatted", " +91 98123-45678 ", "91", "+919812345678", nil},
+		{"International prefix", "00919812345678", "91", "+919812345678", nil},
+		{"National", "98123 45678", "91", "+919812345678", nil},
+		{"Trunk zero", "09812345678", "91", "+919812345678", nil},
+		{"No default code", "9812345678", "", "", domain.ErrInvalid},
+		{"Landline-like", "+912212345678", "91", "", domain.ErrInvalid},
+		{"Too short", "+91981234", "91", "", domain.ErrInvalid},
+		{"Letters", "+91981234567a", "91", "", domain.ErrInvalid},
+		{"Other country", "+14155550100", "91", "", domain.ErrInvalid},
+		{"Empty", "", "91", "", domain.ErrInvalid},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NormalizePhone(tc.number, tc.defaultCode, []string{"91"})
+			testhelpers.LogTestAssertion(logger, tc.name, tc.want, got)
+			if got != tc.want || !errors.Is(err, tc.wantErr) {
+				t.Errorf("NormalizePhone(%q) = %q, %v; want %q, %v", tc.number, got, err, tc.want, tc.wantErr)
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Countries without national rules only get length checks")
+	if got, err := NormalizePhone("+14155550100", "", []string{"91", "1"}); err != nil || got != "+14155550100" {
+		t.Errorf("NormalizePhone with +1 allowed = %q, %v", got, err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestNormalizePhone", true)
+}
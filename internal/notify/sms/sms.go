@@ -0,0 +1,340 @@
+// Package sms delivers price alerts as text messages through Twilio or
+// MSG91, for users who want to hear about a drop sooner than email. Users
+// opt in per number by entering a code texted to it, only numbers in
+// allowed countries are accepted, and per-user and daily budget caps keep
+// the provider bill bounded.
+package sms
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Message kinds, for providers that send registered templates.
+const (
+	KindAlert = "alert"
+	KindCode  = "code"
+)
+
+// Message is a text to send.
+type Message struct {
+	// To is an E.164 number.
+	To   string
+	Kind string
+	Body string
+	// Vars are Body's parts, for template-based providers.
+	Vars map[string]string
+}
+
+// Provider sends texts.
+type Provider interface {
+	Name() string
+	Send(ctx context.Context, m Message) error
+}
+
+// SendError is a provider's refusal or an unreachable provider.
+type SendError struct {
+	Provider string
+	Status   int
+	Message  string
+}
+
+func (e *SendError) Error() string {
+	return fmt.Sprintf("%s: %d %s", e.Provider, e.Status, e.Message)
+}
+
+// Retryable reports whether the send may succeed if repeated.
+func (e *SendError) Retryable() bool {
+	return e.Status == http.StatusTooManyRequests || e.Status >= 500
+}
+
+// ErrCapReached is returned by Deliver and Subscribe when a spending cap
+// would be exceeded. It wraps notify.ErrUnreachable, so the dispatcher
+// skips the text instead of retrying it.
+var ErrCapReached = fmt.Errorf("SMS spending cap reached: %w", notify.ErrUnreachable)
+
+// Config configures the Sender.
+type Config struct {
+	// SiteURL is the public site root that links in messages point at.
+	SiteURL string
+	// Countries are the calling codes numbers may have, e.g. "91".
+	Countries []string
+	// DefaultCountry is assumed for numbers entered without a code.
+	DefaultCountry string
+	// MaxPerUserPerDay bounds the texts, codes included, one user is sent
+	// in any 24 hours.
+	MaxPerUserPerDay int
+	// CostPerMessage is what the provider charges for a text, and
+	// DailyBudget what all texts in any 24 hours may cost, in the same
+	// unit. A zero DailyBudget means no budget.
+	CostPerMessage float64
+	DailyBudget    float64
+	// CodeTTL is how long a verification code can be used, and
+	// MaxCodeAttempts how many wrong guesses void it.
+	CodeTTL         time.Duration
+	MaxCodeAttempts int
+}
+
+// DefaultConfig accepts Indian numbers, sends each user at most five texts
+// a day and counts texts against a budget of a thousand a day.
+func DefaultConfig() Config {
+	return Config{
+		Countries:        []string{"91"},
+		DefaultCountry:   "91",
+		MaxPerUserPerDay: 5,
+		CostPerMessage:   1,
+		DailyBudget:      1000,
+		CodeTTL:          10 * time.Minute,
+		MaxCodeAttempts:  5,
+	}
+}
+
+// capWindow is the period spending caps apply to.
+const capWindow = 24 * time.Hour
+
+// Sender verifies users' numbers and implements notify.Channel.
+type Sender struct {
+	cfg      Config
+	provider Provider
+	repo     repositories.SMSRepository
+	logger   *zap.Logger
+	now      func() time.Time
+	code     func() (string, error)
+}
+
+// NewSender creates a Sender.
+func NewSender(cfg Config, provider Provider, repo repositories.SMSRepository, logger *zap.Logger) *Sender {
+	def := DefaultConfig()
+	if len(cfg.Countries) == 0 {
+		cfg.Countries = def.Countries
+	}
+	if cfg.MaxPerUserPerDay <= 0 {
+		cfg.MaxPerUserPerDay = def.MaxPerUserPerDay
+	}
+	if cfg.CostPerMessage <= 0 {
+		cfg.CostPerMessage = def.CostPerMessage
+	}
+	if cfg.CodeTTL <= 0 {
+		cfg.CodeTTL = def.CodeTTL
+	}
+	if cfg.MaxCodeAttempts <= 0 {
+		cfg.MaxCodeAttempts = def.MaxCodeAttempts
+	}
+	cfg.SiteURL = strings.TrimRight(cfg.SiteURL, "/")
+	return &Sender{
+		cfg:      cfg,
+		provider: provider,
+		repo:     repo,
+		logger:   logger,
+		now:      time.Now,
+		code:     newCode,
+	}
+}
+
+// Subscribe sets userID's number, replacing any other, and texts it a code
+// to confirm with Verify. Alerts start once it is verified.
+func (s *Sender) Subscribe(ctx context.Context, userID, phone string) (*domain.SMSSubscription, error) {
+	phone, err := NormalizePhone(phone, s.cfg.DefaultCountry, s.cfg.Countries)
+	if err != nil {
+		return nil, err
+	}
+	code, err := s.code()
+	if err != nil {
+		return nil, fmt.Errorf("generate code: %w", err)
+	}
+	now := s.now().UTC()
+	sub := domain.SMSSubscription{
+		UserID:        userID,
+		Phone:         phone,
+		CreatedAt:     now,
+		CodeHash:      hashCode(code),
+		CodeExpiresAt: now.Add(s.cfg.CodeTTL),
+	}
+	if err := s.repo.SaveSMSSubscription(ctx, sub); err != nil {
+		return nil, fmt.Errorf("save sms subscription: %w", err)
+	}
+	err = s.send(ctx, userID, Message{
+		To:   phone,
+		Kind: KindCode,
+		Body: code + " is your Whey Price Compare code for SMS alerts. It expires in " + minutes(s.cfg.CodeTTL) + ".",
+		Vars: map[string]string{"code": code},
+	})
+	if errors.Is(err, ErrCapReached) {
+		return nil, fmt.Errorf("too many texts sent, try again tomorrow: %w", domain.ErrConflict)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// Verify confirms userID's number with the code texted to it.
+func (s *Sender) Verify(ctx context.Context, userID, code string) (*domain.SMSSubscription, error) {
+	sub, err := s.repo.SMSSubscription(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sub.Verified {
+		return sub, nil
+	}
+	now := s.now().UTC()
+	if sub.CodeHash == "" || !now.Before(sub.CodeExpiresAt) || sub.CodeAttempts >= s.cfg.MaxCodeAttempts {
+		return nil, fmt.Errorf("code has expired, request a new one: %w", domain.ErrInvalid)
+	}
+	if subtle.ConstantTimeCompare([]byte(hashCode(strings.TrimSpace(code))), []byte(sub.CodeHash)) != 1 {
+		sub.CodeAttempts++
+		if err := s.repo.SaveSMSSubscription(ctx, *sub); err != nil {
+			return nil, fmt.Errorf("save sms subscription: %w", err)
+		}
+		return nil, fmt.Errorf("code is not correct: %w", domain.ErrInvalid)
+	}
+	sub.Verified, sub.VerifiedAt = true, &now
+	sub.CodeHash, sub.CodeExpiresAt, sub.CodeAttempts = "", time.Time{}, 0
+	if err := s.repo.SaveSMSSubscription(ctx, *sub); err != nil {
+		return nil, fmt.Errorf("save sms subscription: %w", err)
+	}
+	s.logger.Info("SMS number verified", zap.String("operation", "VerifySMS"), zap.String("user_id", userID))
+	return sub, nil
+}
+
+// Subscription returns userID's number, or domain.ErrNotFound.
+func (s *Sender) Subscription(ctx context.Context, userID string) (*domain.SMSSubscription, error) {
+	return s.repo.SMSSubscription(ctx, userID)
+}
+
+// Unsubscribe removes userID's number, if any.
+func (s *Sender) Unsubscribe(ctx context.Context, userID string) error {
+	return s.repo.DeleteSMSSubscription(ctx, userID)
+}
+
+// Subscribed reports whether userID has a verified number.
+func (s *Sender) Subscribed(ctx context.Context, userID string) (bool, error) {
+	sub, err := s.repo.SMSSubscription(ctx, userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return sub.Verified, nil
+}
+
+// Name implements notify.Channel.
+func (s *Sender) Name() string { return domain.ChannelSMS }
+
+// Deliver implements notify.Channel. Only single price alerts are texted;
+// digests are not urgent. Users without a verified number, and texts over
+// a spending cap, are unreachable.
+func (s *Sender) Deliver(ctx context.Context, u domain.User, n domain.Notification) error {
+	if n.Type != domain.NotificationPriceAlert {
+		return notify.ErrUnreachable
+	}
+	sub, err := s.repo.SMSSubscription(ctx, u.ID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return notify.ErrUnreachable
+	}
+	if err != nil {
+		return fmt.Errorf("load sms subscription: %w", err)
+	}
+	if !sub.Verified {
+		return notify.ErrUnreachable
+	}
+	return s.send(ctx, u.ID, alertMessage(sub.Phone, n, s.link(n.URL)))
+}
+
+// maxProductName bounds the product name in a text, keeping alerts to one
+// 160 character SMS.
+const maxProductName = 60
+
+// alertMessage words an alert text. Unlike other channels it does not use
+// the notify/templates package: Indian operators only deliver texts that
+// match a template registered with them, so this wording is fixed.
+func alertMessage(to string, n domain.Notification, link string) Message {
+	name := n.ProductName
+	if r := []rune(name); len(r) > maxProductName {
+		name = strings.TrimSpace(string(r[:maxProductName-1])) + "…"
+	}
+	// "Rs." rather than "₹" keeps the text in the GSM alphabet, where one
+	// SMS fits 160 characters rather than 70.
+	price := strings.ReplaceAll(i18n.Default().FormatPrice(domain.DefaultCurrency, n.Price), "₹", "Rs.")
+	title := "Price alert: "
+	if n.Test {
+		title = "Test alert: "
+	}
+	return Message{
+		To:   to,
+		Kind: KindAlert,
+		Body: title + name + " is now " + price + " at " + n.RetailerName + ". " + link,
+		Vars: map[string]string{"product": name, "price": price, "retailer": n.RetailerName, "url": link},
+	}
+}
+
+// send texts m to userID if it fits the caps, and records it.
+func (s *Sender) send(ctx context.Context, userID string, m Message) error {
+	now := s.now().UTC()
+	sent, cost, err := s.repo.SMSSpend(ctx, userID, now.Add(-capWindow))
+	if err != nil {
+		return fmt.Errorf("load sms spend: %w", err)
+	}
+	if sent >= s.cfg.MaxPerUserPerDay || s.cfg.DailyBudget > 0 && cost+s.cfg.CostPerMessage > s.cfg.DailyBudget {
+		s.logger.Warn("SMS not sent, spending cap reached",
+			zap.String("operation", "SendSMS"),
+			zap.String("user_id", userID),
+			zap.String("kind", m.Kind),
+			zap.Int("user_sent", sent),
+			zap.Float64("spend", cost),
+		)
+		return ErrCapReached
+	}
+	if err := s.provider.Send(ctx, m); err != nil {
+		return err
+	}
+	if err := s.repo.RecordSMS(ctx, domain.SMSMessage{UserID: userID, Cost: s.cfg.CostPerMessage, SentAt: now}); err != nil {
+		s.logger.Error("Recording SMS failed", zap.String("operation", "SendSMS"), zap.String("user_id", userID), zap.Error(err))
+	}
+	return nil
+}
+
+func (s *Sender) link(path string) string {
+	if strings.HasPrefix(path, "/") {
+		return s.cfg.SiteURL + path
+	}
+	return path
+}
+
+// newCode returns a random six digit code.
+func newCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+func hashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+func minutes(d time.Duration) string {
+	if m := int(d.Minutes()); m != 1 {
+		return fmt.Sprintf("%d minutes", m)
+	}
+	return "1 minute"
+}
@@ -0,0 +1,258 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+type fakeProvider struct {
+	sent []Message
+	err  error
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) Send(_ context.Context, m Message) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.sent = append(p.sent, m)
+	return nil
+}
+
+func newTestSender(t *testing.T, cfg Config, now *time.Time) (*Sender, *fakeProvider) {
+	t.Helper()
+	provider := &fakeProvider{}
+	s := NewSender(cfg, provider, memory.NewStore().SMS(), testhelpers.SetupTestLogger(t))
+	s.now = func() time.Time { return *now }
+	s.code = func() (string, error) { return "123456", nil }
+	return s, provider
+}
+
+var alert = domain.Notification{
+	Type:         domain.NotificationPriceAlert,
+	ProductName:  "Gold Standard 100% Whey",
+	RetailerName: "Flipkart",
+	Price:        2899,
+	URL:          "/go/listing_1",
+}
+
+func TestSender_SubscribeAndVerify(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSender_SubscribeAndVerify", "internal/notify/sms")
+
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	cfg := DefaultConfig()
+	cfg.SiteURL = "https://wheyprices.example/"
+	s, provider := newTestSender(t, cfg, &now)
+	ctx := t.Context()
+	u := domain.User{ID: "user_1"}
+
+	testhelpers.LogTestStep(logger, "act", "Subscribing texts a code; nothing is delivered until it is entered")
+	sub, err := s.Subscribe(ctx, u.ID, "98123 45678")
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if sub.Phone != "+919812345678" || sub.Verified || len(provider.sent) != 1 || provider.sent[0].Vars["code"] != "123456" {
+		t.Fatalf("Subscription = %+v, sent %+v", sub, provider.sent)
+	}
+	if err := s.Deliver(ctx, u, alert); !errors.Is(err, notify.ErrUnreachable) {
+		t.Errorf("Deliver before verifying error = %v, want ErrUnreachable", err)
+	}
+	if _, err := s.Subscribe(ctx, u.ID, "+14155550100"); !errors.Is(err, domain.ErrInvalid) {
+		t.Errorf("Subscribe outside allowed countries error = %v, want ErrInvalid", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "A wrong code is rejected, the right one verifies")
+	if _, err := s.Verify(ctx, u.ID, "654321"); !errors.Is(err, domain.ErrInvalid) {
+		t.Errorf("Wrong code error = %v, want ErrInvalid", err)
+	}
+	sub, err = s.Verify(ctx, u.ID, " 123456 ")
+	if err != nil || !sub.Verified || sub.VerifiedAt == nil {
+		t.Fatalf("Verify = %+v, %v", sub, err)
+	}
+	if ok, _ := s.Subscribed(ctx, u.ID); !ok {
+		t.Error("Subscribed after verifying = false")
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Price alerts are texted; digests are not")
+	if err := s.Deliver(ctx, u, alert); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	got := provider.sent[len(provider.sent)-1]
+	want := "Price alert: Gold Standard 100% Whey is now Rs.2,899 at Flipkart. https://wheyprices.example/go/listing_1"
+	testhelpers.LogTestAssertion(logger, "body", want, got.Body)
+	if got.To != "+919812345678" || got.Kind != KindAlert || got.Body != want {
+		t.Errorf("Sent %+v, want body %q", got, want)
+	}
+	if err := s.Deliver(ctx, u, domain.Notification{Type: domain.NotificationPriceAlertDigest}); !errors.Is(err, notify.ErrUnreachable) {
+		t.Errorf("Deliver digest error = %v, want ErrUnreachable", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Unsubscribing stops texts")
+	if err := s.Unsubscribe(ctx, u.ID); err != nil {
+		t.Fatalf("Unsubscribe: %v", err)
+	}
+	if err := s.Deliver(ctx, u, alert); !errors.Is(err, notify.ErrUnreachable) {
+		t.Errorf("Deliver after unsubscribing error = %v, want ErrUnreachable", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSender_SubscribeAndVerify", true)
+}
+
+func TestSender_CodeLimits(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSender_CodeLimits", "internal/notify/sms")
+
+	testCases := []struct {
+		name    string
+		wrong   int
+		advance time.Duration
+		wantErr error
+	}{
+		{"Within limits", 4, 9 * time.Minute, nil},
+		{"Too many guesses", 5, 0, domain.ErrInvalid},
+		{"Expired", 0, 10 * time.Minute, domain.ErrInvalid},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+			s, _ := newTestSender(t, Config{}, &now)
+			ctx := t.Context()
+			if _, err := s.Subscribe(ctx, "user_1", "+919812345678"); err != nil {
+				t.Fatalf("Subscribe: %v", err)
+			}
+			for range tc.wrong {
+				_, _ = s.Verify(ctx, "user_1", "000000")
+			}
+			now = now.Add(tc.advance)
+			_, err := s.Verify(ctx, "user_1", "123456")
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Verify error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+	now := time.Now()
+	s, _ := newTestSender(t, Config{}, &now)
+	if _, err := s.Verify(t.Context(), "user_1", "123456"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Verify without a number error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSender_CodeLimits", true)
+}
+
+func TestSender_SpendingCaps(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSender_SpendingCaps", "internal/notify/sms")
+
+	testCases := []struct {
+		name     string
+		cfg      Config
+		users    int
+		wantSent int
+	}{
+		// Each user's code counts as their first text.
+		{"Per-user cap", Config{MaxPerUserPerDay: 3}, 1, 3},
+		{"Daily budget", Config{MaxPerUserPerDay: 10, CostPerMessage: 0.5, DailyBudget: 4}, 2, 8},
+		{"No budget", Config{MaxPerUserPerDay: 4}, 3, 12},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+			s, provider := newTestSender(t, tc.cfg, &now)
+			ctx := t.Context()
+			var users []domain.User
+			for i := range tc.users {
+				u := domain.User{ID: "user_" + string(rune('a'+i))}
+				if _, err := s.Subscribe(ctx, u.ID, "+91981234567"+string(rune('0'+i))); err != nil {
+					t.Fatalf("Subscribe: %v", err)
+				}
+				if _, err := s.Verify(ctx, u.ID, "123456"); err != nil {
+					t.Fatalf("Verify: %v", err)
+				}
+				users = append(users, u)
+			}
+			capped := 0
+			for range 5 {
+				for _, u := range users {
+					if err := s.Deliver(ctx, u, alert); errors.Is(err, ErrCapReached) {
+						capped++
+					} else if err != nil {
+						t.Fatalf("Deliver: %v", err)
+					}
+				}
+			}
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantSent, len(provider.sent))
+			if len(provider.sent) != tc.wantSent || capped == 0 {
+				t.Fatalf("Sent %d, capped %d; want %d sent and some capped", len(provider.sent), capped, tc.wantSent)
+			}
+			if !errors.Is(ErrCapReached, notify.ErrUnreachable) {
+				t.Error("ErrCapReached does not wrap ErrUnreachable")
+			}
+
+			now = now.Add(capWindow + time.Second)
+			if err := s.Deliver(ctx, users[0], alert); err != nil {
+				t.Errorf("Deliver a day later: %v", err)
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "A capped code request is a conflict, not an unreachable channel")
+	now := time.Now()
+	s, _ := newTestSender(t, Config{MaxPerUserPerDay: 1}, &now)
+	_, _ = s.Subscribe(t.Context(), "user_1", "+919812345678")
+	if _, err := s.Subscribe(t.Context(), "user_1", "+919812345678"); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Second code error = %v, want ErrConflict", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSender_SpendingCaps", true)
+}
+
+func TestSender_ProviderFailure(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSender_ProviderFailure", "internal/notify/sms")
+
+	now := time.Now()
+	s, provider := newTestSender(t, Config{MaxPerUserPerDay: 2}, &now)
+	ctx := t.Context()
+	_, _ = s.Subscribe(ctx, "user_1", "+919812345678")
+	_, _ = s.Verify(ctx, "user_1", "123456")
+
+	provider.err = &SendError{Provider: "fake", Status: 503, Message: "unavailable"}
+	err := s.Deliver(ctx, domain.User{ID: "user_1"}, alert)
+	var serr *SendError
+	if !errors.As(err, &serr) || !serr.Retryable() {
+		t.Fatalf("Deliver error = %v, want a retryable SendError", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Failed texts do not count against caps")
+	provider.err = nil
+	if err := s.Deliver(ctx, domain.User{ID: "user_1"}, alert); err != nil {
+		t.Errorf("Deliver after failure: %v", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSender_ProviderFailure", true)
+}
+
+func TestAlertMessage_LongName(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAlertMessage_LongName", "internal/notify/sms")
+
+	n := alert
+	n.ProductName = "Optimum Nutrition Gold Standard 100% Whey Protein Powder Double Rich Chocolate 5 lb"
+	m := alertMessage("+919812345678", n, "https://wheyprices.example/go/listing_1")
+	testhelpers.LogTestAssertion(logger, "length", "<= 160", len([]rune(m.Body)))
+	if len([]rune(m.Body)) > 160 || len([]rune(m.Vars["product"])) != maxProductName {
+		t.Errorf("Body (%d) = %q", len([]rune(m.Body)), m.Body)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAlertMessage_LongName", true)
+}
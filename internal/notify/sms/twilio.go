@@ -0,0 +1,91 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TwilioConfig configures the Twilio provider.
+type TwilioConfig struct {
+	AccountSID string
+	AuthToken  string
+	// From is the sending number, or a Messaging Service SID (MG...).
+	From string
+	// APIURL is the API root, overridable for tests.
+	APIURL  string
+	Timeout time.Duration
+}
+
+// Twilio sends texts through Twilio's Messages API.
+type Twilio struct {
+	cfg  TwilioConfig
+	http *http.Client
+}
+
+// NewTwilio creates a Twilio provider.
+func NewTwilio(cfg TwilioConfig) *Twilio {
+	if cfg.APIURL == "" {
+		cfg.APIURL = "https://api.twilio.com"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	cfg.APIURL = strings.TrimRight(cfg.APIURL, "/")
+	return &Twilio{cfg: cfg, http: &http.Client{Timeout: cfg.Timeout}}
+}
+
+// Name implements Provider.
+func (t *Twilio) Name() string { return "twilio" }
+
+// Send implements Provider. Twilio takes the message's Body as is.
+func (t *Twilio) Send(ctx context.Context, m Message) error {
+	form := url.Values{"To": {m.To}, "Body": {m.Body}}
+	if strings.HasPrefix(t.cfg.From, "MG") {
+		form.Set("MessagingServiceSid", t.cfg.From)
+	} else {
+		form.Set("From", t.cfg.From)
+	}
+	endpoint := t.cfg.APIURL + "/2010-04-01/Accounts/" + url.PathEscape(t.cfg.AccountSID) + "/Messages.json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(t.cfg.AccountSID, t.cfg.AuthToken)
+	resp, err := t.http.Do(req)
+	if err != nil {
+		return &SendError{Provider: "twilio", Status: http.StatusServiceUnavailable, Message: transportError(err)}
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	var body struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(raw, &body)
+	msg := body.Message
+	if body.Code != 0 {
+		msg = fmt.Sprintf("%d %s", body.Code, msg)
+	}
+	return &SendError{Provider: "twilio", Status: resp.StatusCode, Message: msg}
+}
+
+// transportError describes a failed request without its URL, which may
+// identify the account.
+func transportError(err error) string {
+	var uerr *url.Error
+	if errors.As(err, &uerr) {
+		err = uerr.Err
+	}
+	return err.Error()
+}
@@ -0,0 +1,71 @@
+package sms
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestTwilio_Send(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestTwilio_Send", "internal/notify/sms")
+
+	testCases := []struct {
+		name          string
+		from          string
+		status        int
+		response      string
+		wantFromField string
+		wantErr       string
+		wantRetryable bool
+	}{
+		{"Sent from a number", "+15005550006", http.StatusCreated, `{"sid":"SM1"}`, "From", "", false},
+		{"Sent from a messaging service", "MG123", http.StatusCreated, `{"sid":"SM1"}`, "MessagingServiceSid", "", false},
+		{"Rejected number", "+15005550006", http.StatusBadRequest, `{"code":21211,"message":"Invalid 'To' Phone Number"}`, "From", "twilio: 400 21211 Invalid 'To' Phone Number", false},
+		{"Throttled", "+15005550006", http.StatusTooManyRequests, `{"code":20429,"message":"Too Many Requests"}`, "From", "twilio: 429", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				user, pass, _ := r.BasicAuth()
+				if r.URL.Path != "/2010-04-01/Accounts/AC123/Messages.json" || user != "AC123" || pass != "test-only-secret" {
+					t.Errorf("Request %s with auth %q", r.URL.Path, user)
+				}
+				if r.FormValue("To") != "+919812345678" || r.FormValue("Body") != "hello" || r.FormValue(tc.wantFromField) != tc.from {
+					t.Errorf("Form = %v", r.PostForm)
+				}
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.response))
+			}))
+			defer srv.Close()
+
+			p := NewTwilio(TwilioConfig{AccountSID: "AC123", AuthToken: "test-only-secret", From: tc.from, APIURL: srv.URL + "/"})
+			err := p.Send(t.Context(), Message{To: "+919812345678", Kind: KindAlert, Body: "hello"})
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Send: %v", err)
+				}
+				return
+			}
+			var serr *SendError
+			if !errors.As(err, &serr) || !strings.HasPrefix(err.Error(), tc.wantErr) || serr.Retryable() != tc.wantRetryable {
+				t.Errorf("Send error = %v, want %q retryable %v", err, tc.wantErr, tc.wantRetryable)
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Network errors are retryable and leave the URL out")
+	p := NewTwilio(TwilioConfig{AccountSID: "AC123", AuthToken: "test-only-secret", APIURL: "http://127.0.0.1:1"})
+	err := p.Send(t.Context(), Message{To: "+919812345678", Body: "hello"})
+	var serr *SendError
+	if !errors.As(err, &serr) || !serr.Retryable() || strings.Contains(err.Error(), "AC123") {
+		t.Errorf("Network error = %v", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestTwilio_Send", true)
+}
@@ -0,0 +1,375 @@
+// Package telegram is a Telegram bot that delivers price alerts to linked
+// chats and answers price questions. Users link a chat by opening a t.me
+// deep link that carries a one-time token, which the bot redeems when the
+// chat sends /start.
+package telegram
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+	"github.com/yourusername/whey-price-compare/internal/notify/templates"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/services"
+)
+
+// Config configures the Bot.
+type Config struct {
+	// Token is the bot token from @BotFather.
+	Token string
+	// Username is the bot's @username, without the @, for deep links.
+	Username string
+	// SiteURL is the public site root that links in messages point at.
+	SiteURL string
+	// APIURL is the Bot API root, overridable for tests.
+	APIURL string
+	// LinkTTL is how long a deep link can be used.
+	LinkTTL time.Duration
+	// PollTimeout is how long each getUpdates call waits for updates.
+	PollTimeout time.Duration
+	// MaxAttempts bounds tries per message after retryable failures.
+	MaxAttempts int
+}
+
+// DefaultConfig returns links valid for 15 minutes and 30 second long
+// polls.
+func DefaultConfig() Config {
+	return Config{
+		APIURL:      "https://api.telegram.org",
+		LinkTTL:     15 * time.Minute,
+		PollTimeout: 30 * time.Second,
+		MaxAttempts: 3,
+	}
+}
+
+// Catalog is what the bot reads to answer /price.
+type Catalog struct {
+	Products repositories.ProductRepository
+	Prices   *services.PriceService
+}
+
+// Bot links chats, answers commands and implements notify.Channel.
+type Bot struct {
+	cfg       Config
+	api       *client
+	links     repositories.TelegramRepository
+	catalog   Catalog
+	templates *templates.Engine
+	logger    *zap.Logger
+	now       func() time.Time
+	sleep     func(ctx context.Context, d time.Duration) error
+}
+
+// NewBot creates a Bot. Call Run to start answering chats.
+func NewBot(cfg Config, links repositories.TelegramRepository, catalog Catalog, logger *zap.Logger) *Bot {
+	def := DefaultConfig()
+	if cfg.APIURL == "" {
+		cfg.APIURL = def.APIURL
+	}
+	if cfg.LinkTTL <= 0 {
+		cfg.LinkTTL = def.LinkTTL
+	}
+	if cfg.PollTimeout <= 0 {
+		cfg.PollTimeout = def.PollTimeout
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = def.MaxAttempts
+	}
+	cfg.SiteURL = strings.TrimRight(cfg.SiteURL, "/")
+	return &Bot{
+		cfg:       cfg,
+		api:       newClient(cfg.APIURL, cfg.Token, cfg.PollTimeout+10*time.Second),
+		links:     links,
+		catalog:   catalog,
+		templates: templates.Default(),
+		logger:    logger,
+		now:       time.Now,
+		sleep:     sleepCtx,
+	}
+}
+
+// WithTemplates renders alerts with e rather than the latest built-in
+// templates. It returns b.
+func (b *Bot) WithTemplates(e *templates.Engine) *Bot {
+	b.templates = e
+	return b
+}
+
+// LinkURL returns a deep link that connects the chat opening it to userID,
+// and when it expires.
+func (b *Bot) LinkURL(ctx context.Context, userID string) (string, time.Time, error) {
+	token := rand.Text()
+	expires := b.now().UTC().Add(b.cfg.LinkTTL)
+	if err := b.links.CreateLinkToken(ctx, domain.TelegramLinkToken{
+		TokenHash: hashToken(token),
+		UserID:    userID,
+		ExpiresAt: expires,
+	}); err != nil {
+		return "", time.Time{}, fmt.Errorf("create link token: %w", err)
+	}
+	return "https://t.me/" + url.PathEscape(b.cfg.Username) + "?start=" + token, expires, nil
+}
+
+// Linked reports whether userID has a linked chat.
+func (b *Bot) Linked(ctx context.Context, userID string) (bool, error) {
+	_, err := b.links.UserChat(ctx, userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Unlink disconnects userID's chat, if any.
+func (b *Bot) Unlink(ctx context.Context, userID string) error {
+	return b.links.UnlinkUser(ctx, userID)
+}
+
+// Run long-polls for chat messages until ctx is done.
+func (b *Bot) Run(ctx context.Context) {
+	var offset int64
+	for ctx.Err() == nil {
+		updates, err := b.api.getUpdates(ctx, offset, b.cfg.PollTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			b.logger.Error("Telegram getUpdates failed", zap.String("operation", "TelegramPoll"), zap.Error(err))
+			_ = b.sleep(ctx, 5*time.Second)
+			continue
+		}
+		for _, u := range updates {
+			offset = max(offset, u.UpdateID+1)
+			if u.Message != nil {
+				b.handleMessage(ctx, *u.Message)
+			}
+		}
+	}
+}
+
+const helpText = "Send /price followed by a product name to see its best price, e.g. <code>/price gold standard</code>.\n\n" +
+	"To get price alerts here, open the Telegram link from your account page. Send /stop to stop them."
+
+// handleMessage answers one chat message. Replies that fail to send are
+// logged; the user can ask again.
+func (b *Bot) handleMessage(ctx context.Context, m message) {
+	command, arg, _ := strings.Cut(strings.TrimSpace(m.Text), " ")
+	// In groups commands may be addressed as /price@this_bot.
+	command, _, _ = strings.Cut(command, "@")
+	arg = strings.TrimSpace(arg)
+
+	var reply string
+	switch command {
+	case "/start":
+		reply = b.start(ctx, m.Chat, arg)
+	case "/price":
+		reply = b.price(ctx, arg)
+	case "/stop":
+		reply = b.stop(ctx, m.Chat.ID)
+	case "/help":
+		reply = helpText
+	default:
+		if m.Chat.Type != "private" {
+			return
+		}
+		reply = helpText
+	}
+	if err := b.send(ctx, m.Chat.ID, reply); err != nil {
+		b.logger.Warn("Telegram reply failed", zap.String("operation", "TelegramReply"), zap.String("command", command), zap.Error(err))
+	}
+}
+
+// start links the chat when the deep link carried a token.
+func (b *Bot) start(ctx context.Context, c chat, token string) string {
+	if token == "" {
+		return "Hi! " + helpText
+	}
+	if c.Type != "private" {
+		return "Alerts can only be linked to a private chat with me."
+	}
+	t, err := b.links.ConsumeLinkToken(ctx, hashToken(token))
+	if err != nil || b.now().After(t.ExpiresAt) {
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			b.logger.Error("Redeeming Telegram link failed", zap.String("operation", "TelegramLink"), zap.Error(err))
+			return "Something went wrong. Please try again."
+		}
+		return "This link has expired or was already used. Open a new one from your account page."
+	}
+	if err := b.links.LinkChat(ctx, domain.TelegramLink{UserID: t.UserID, ChatID: c.ID, LinkedAt: b.now().UTC()}); err != nil {
+		b.logger.Error("Linking Telegram chat failed", zap.String("operation", "TelegramLink"), zap.Error(err))
+		return "Something went wrong. Please try again."
+	}
+	b.logger.Info("Telegram chat linked", zap.String("operation", "TelegramLink"), zap.String("user_id", t.UserID))
+	return "Linked! Your price alerts will arrive here. Send /stop to stop them."
+}
+
+// stop unlinks the chat.
+func (b *Bot) stop(ctx context.Context, chatID int64) string {
+	l, err := b.links.ChatLink(ctx, chatID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return "This chat is not linked to an account."
+	}
+	if err == nil {
+		err = b.links.UnlinkUser(ctx, l.UserID)
+	}
+	if err != nil {
+		b.logger.Error("Unlinking Telegram chat failed", zap.String("operation", "TelegramLink"), zap.Error(err))
+		return "Something went wrong. Please try again."
+	}
+	return "Unlinked. You won't get price alerts here any more."
+}
+
+// maxMatches bounds the candidates listed when a /price query is
+// ambiguous.
+const maxMatches = 5
+
+// price answers /price with the best in-stock offer for the product the
+// query names: an ID, a slug, or words from its brand and name.
+func (b *Bot) price(ctx context.Context, query string) string {
+	if query == "" {
+		return "Which product? For example: <code>/price gold standard</code>"
+	}
+	products, err := b.catalog.Products.List(ctx, repositories.ProductFilter{})
+	if err != nil {
+		b.logger.Error("Listing products failed", zap.String("operation", "TelegramPrice"), zap.Error(err))
+		return "Something went wrong. Please try again."
+	}
+	matches := services.MatchProducts(products, query)
+	switch {
+	case len(matches) == 0:
+		return "No product matches “" + html.EscapeString(query) + "”."
+	case len(matches) > 1:
+		var b strings.Builder
+		b.WriteString("Which one?\n")
+		for _, p := range matches[:min(len(matches), maxMatches)] {
+			b.WriteString("• " + html.EscapeString(p.Brand+" "+p.Name) + " — <code>/price " + html.EscapeString(p.Slug) + "</code>\n")
+		}
+		return b.String()
+	}
+
+	c, err := b.catalog.Prices.Compare(ctx, matches[0].ID)
+	if err != nil {
+		b.logger.Error("Comparing prices failed", zap.String("operation", "TelegramPrice"), zap.Error(err))
+		return "Something went wrong. Please try again."
+	}
+	title := "<b>" + html.EscapeString(c.Product.Brand+" "+c.Product.Name) + "</b>\n"
+	if len(c.Prices) == 0 || !c.Prices[0].InStock {
+		return title + "Out of stock everywhere right now."
+	}
+	best := c.Prices[0]
+	return title + "Best price: " + b.offerLine(best)
+}
+
+// offerLine renders an offer's price, retailer and link.
+func (b *Bot) offerLine(o domain.Offer) string {
+	loc := i18n.Default()
+	line := "<b>" + loc.FormatPrice(o.Currency, o.Price) + "</b> at " + html.EscapeString(o.RetailerName)
+	if o.PricePerGramProtein > 0 {
+		line += " (" + loc.FormatPrice(o.Currency, o.PricePerGramProtein) + "/g protein)"
+	}
+	return line + "\n<a href=\"" + html.EscapeString(b.link(o.BuyURL)) + "\">View deal</a>"
+}
+
+func (b *Bot) link(path string) string {
+	if strings.HasPrefix(path, "/") {
+		return b.cfg.SiteURL + path
+	}
+	return path
+}
+
+// Name implements notify.Channel.
+func (b *Bot) Name() string { return domain.ChannelTelegram }
+
+// Deliver implements notify.Channel. Users without a linked chat, and chats
+// that have blocked the bot, are unreachable; blocked chats are unlinked.
+func (b *Bot) Deliver(ctx context.Context, u domain.User, n domain.Notification) error {
+	var text string
+	switch n.Type {
+	case domain.NotificationPriceAlert:
+		m, err := b.templates.Render(templates.PriceAlert, templates.Telegram, templates.NewAlert(n, b.cfg.SiteURL))
+		if err != nil {
+			return err
+		}
+		text = "🔔 <b>" + m.Title + "</b>\n" + m.Body
+	case domain.NotificationPriceAlertDigest:
+		m, err := b.templates.Render(templates.PriceAlertDigest, templates.Telegram, templates.NewDigest(n.Items, maxDigestItems, b.cfg.SiteURL))
+		if err != nil {
+			return err
+		}
+		text = "🔔 " + m.Title + "\n\n" + m.Body
+	default:
+		return fmt.Errorf("no telegram message for notification type %q", n.Type)
+	}
+	l, err := b.links.UserChat(ctx, u.ID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return notify.ErrUnreachable
+	}
+	if err != nil {
+		return fmt.Errorf("load telegram link: %w", err)
+	}
+	err = b.send(ctx, l.ChatID, text)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && (apiErr.Code == 403 || apiErr.Code == 400 && strings.Contains(apiErr.Description, "chat not found")) {
+		if err := b.links.UnlinkUser(ctx, u.ID); err != nil {
+			return fmt.Errorf("unlink blocked chat: %w", err)
+		}
+		b.logger.Info("Telegram chat unreachable, unlinked", zap.String("operation", "TelegramDeliver"), zap.String("user_id", u.ID))
+		return notify.ErrUnreachable
+	}
+	return err
+}
+
+// maxDigestItems bounds the alerts listed in one digest message, keeping it
+// well under Telegram's 4096 character limit.
+const maxDigestItems = 10
+
+// send delivers text, retrying throttling and server errors with backoff or
+// after the wait Telegram asks for.
+func (b *Bot) send(ctx context.Context, chatID int64, text string) error {
+	wait := time.Second
+	for attempt := 1; ; attempt++ {
+		err := b.api.sendMessage(ctx, chatID, text)
+		var apiErr *APIError
+		if err == nil || ctx.Err() != nil || !errors.As(err, &apiErr) || !apiErr.Retryable() || attempt >= b.cfg.MaxAttempts {
+			return err
+		}
+		d := wait
+		if apiErr.RetryAfter > 0 {
+			d = min(apiErr.RetryAfter, 30*time.Second)
+		}
+		if err := b.sleep(ctx, d); err != nil {
+			return err
+		}
+		wait *= 2
+	}
+}
+
+// hashToken is how link tokens are stored, so a leaked table cannot link
+// chats.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,353 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+const testToken = "123456:test-only-secret"
+
+// sent is a sendMessage call the fake Bot API received.
+type sent struct {
+	ChatID int64  `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+// fakeAPI serves the Bot API methods the bot uses. Queued updates are
+// returned by the first getUpdates; sendMessage answers with replies in
+// order, then succeeds.
+type fakeAPI struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	updates []update
+	offsets []int64
+	replies []string
+	sent    []sent
+}
+
+func newFakeAPI(t *testing.T) *fakeAPI {
+	t.Helper()
+	f := &fakeAPI{}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeAPI) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	method, ok := strings.CutPrefix(r.URL.Path, "/bot"+testToken+"/")
+	if !ok {
+		http.Error(w, `{"ok":false,"error_code":401,"description":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+	switch method {
+	case "getUpdates":
+		var in struct {
+			Offset int64 `json:"offset"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		f.offsets = append(f.offsets, in.Offset)
+		result, _ := json.Marshal(f.updates)
+		f.updates = nil
+		_, _ = w.Write([]byte(`{"ok":true,"result":` + string(result) + `}`))
+	case "sendMessage":
+		if len(f.replies) > 0 {
+			reply := f.replies[0]
+			f.replies = f.replies[1:]
+			_, _ = w.Write([]byte(reply))
+			return
+		}
+		var s sent
+		_ = json.NewDecoder(r.Body).Decode(&s)
+		f.sent = append(f.sent, s)
+		_, _ = w.Write([]byte(`{"ok":true,"result":{}}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeAPI) messages() []sent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]sent(nil), f.sent...)
+}
+
+func newTestBot(t *testing.T, api *fakeAPI) (*Bot, *memory.Store) {
+	t.Helper()
+	logger := testhelpers.SetupTestLogger(t)
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	prices := services.NewPriceService(services.PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+	b := NewBot(Config{Token: testToken, Username: "whey_test_bot", SiteURL: "https://whey.example/", APIURL: api.URL},
+		store.Telegram(), Catalog{Products: store.Products(), Prices: prices}, logger)
+	b.sleep = func(context.Context, time.Duration) error { return nil }
+	return b, store
+}
+
+func privateMessage(chatID int64, text string) message {
+	return message{Chat: chat{ID: chatID, Type: "private"}, Text: text}
+}
+
+func TestBot_LinkChat(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestBot_LinkChat", "internal/notify/telegram")
+
+	api := newFakeAPI(t)
+	b, store := newTestBot(t, api)
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "arrange", "Creating a deep link for a user")
+	link, expires, err := b.LinkURL(ctx, "user_1")
+	if err != nil {
+		t.Fatalf("LinkURL: %v", err)
+	}
+	token, ok := strings.CutPrefix(link, "https://t.me/whey_test_bot?start=")
+	if !ok || token == "" || !expires.After(time.Now()) {
+		t.Fatalf("LinkURL = %q, %v", link, expires)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Starting the bot from a group, then privately, then reusing the link")
+	b.handleMessage(ctx, message{Chat: chat{ID: -100, Type: "group"}, Text: "/start " + token})
+	b.handleMessage(ctx, privateMessage(42, "/start "+token))
+	b.handleMessage(ctx, privateMessage(43, "/start "+token))
+
+	testhelpers.LogTestStep(logger, "assert", "Only the private chat that used the link first is linked")
+	l, err := store.Telegram().UserChat(ctx, "user_1")
+	testhelpers.LogTestAssertion(logger, "linked chat", int64(42), l.ChatID)
+	if err != nil || l.ChatID != 42 {
+		t.Fatalf("UserChat = %+v, %v, want chat 42", l, err)
+	}
+	msgs := api.messages()
+	if len(msgs) != 3 || !strings.Contains(msgs[1].Text, "Linked") || !strings.Contains(msgs[2].Text, "expired") {
+		t.Errorf("Replies = %+v", msgs)
+	}
+	if linked, _ := b.Linked(ctx, "user_1"); !linked {
+		t.Error("Linked = false after /start")
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Expired links do not link")
+	b.now = func() time.Time { return time.Now().Add(time.Hour) }
+	link, _, _ = b.LinkURL(ctx, "user_2")
+	b.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	b.handleMessage(ctx, privateMessage(44, "/start "+strings.TrimPrefix(link, "https://t.me/whey_test_bot?start=")))
+	if _, err := store.Telegram().UserChat(ctx, "user_2"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expired link linked a chat: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Stopping from the linked chat")
+	b.handleMessage(ctx, privateMessage(42, "/stop"))
+	if linked, _ := b.Linked(ctx, "user_1"); linked {
+		t.Error("Linked = true after /stop")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestBot_LinkChat", true)
+}
+
+func TestBot_Price(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestBot_Price", "internal/notify/telegram")
+
+	testCases := []struct {
+		name  string
+		text  string
+		want  []string
+		avoid []string
+	}{
+		{"By name words", "/price gold STANDARD", []string{"Gold Standard 100% Whey", "₹3,199", "Flipkart", `href="https://whey.example/go/`}, nil},
+		{"By slug, addressed to the bot", "/price@whey_test_bot gold-standard-100-whey", []string{"₹3,199"}, nil},
+		{"Ambiguous", "/price whey", []string{"Which one?", "/price gold-standard-100-whey", "/price biozyme-performance-whey"}, []string{"₹"}},
+		{"No match", "/price <casein>", []string{"&lt;casein&gt;"}, []string{"<casein>"}},
+		{"No query", "/price", []string{"Which product?"}, nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			api := newFakeAPI(t)
+			b, _ := newTestBot(t, api)
+			b.handleMessage(t.Context(), privateMessage(42, tc.text))
+			msgs := api.messages()
+			if len(msgs) != 1 {
+				t.Fatalf("Sent %d messages, want 1", len(msgs))
+			}
+			testhelpers.LogTestAssertion(logger, tc.name, tc.want, msgs[0].Text)
+			for _, w := range tc.want {
+				if !strings.Contains(msgs[0].Text, w) {
+					t.Errorf("Reply %q missing %q", msgs[0].Text, w)
+				}
+			}
+			for _, a := range tc.avoid {
+				if strings.Contains(msgs[0].Text, a) {
+					t.Errorf("Reply %q contains %q", msgs[0].Text, a)
+				}
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestBot_Price", true)
+}
+
+func TestBot_Deliver(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestBot_Deliver", "internal/notify/telegram")
+
+	n := domain.Notification{
+		ID: "ntf_1", Type: domain.NotificationPriceAlert, UserID: "user_1",
+		ProductName: "Gold Standard 100% Whey", RetailerName: "Flipkart",
+		Price: 2899, PricePerGram: 1.62, URL: "/go/flipkart",
+	}
+	throttled := `{"ok":false,"error_code":429,"description":"Too Many Requests","parameters":{"retry_after":3}}`
+	testCases := []struct {
+		name       string
+		linked     bool
+		replies    []string
+		wantErr    error
+		wantSent   int
+		wantLinked bool
+	}{
+		{"Delivered", true, nil, nil, 1, true},
+		{"Retried after throttling", true, []string{throttled}, nil, 1, true},
+		{"Not linked", false, nil, notify.ErrUnreachable, 0, false},
+		{"Blocked by user", true, []string{`{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`}, notify.ErrUnreachable, 0, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			api := newFakeAPI(t)
+			api.replies = tc.replies
+			b, store := newTestBot(t, api)
+			ctx := t.Context()
+			if tc.linked {
+				_ = store.Telegram().LinkChat(ctx, domain.TelegramLink{UserID: "user_1", ChatID: 42})
+			}
+
+			err := b.Deliver(ctx, domain.User{ID: "user_1"}, n)
+
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Deliver error = %v, want %v", err, tc.wantErr)
+			}
+			msgs := api.messages()
+			if len(msgs) != tc.wantSent {
+				t.Fatalf("Sent %d messages, want %d", len(msgs), tc.wantSent)
+			}
+			if tc.wantSent > 0 && (msgs[0].ChatID != 42 || !strings.Contains(msgs[0].Text, "₹2,899")) {
+				t.Errorf("Sent %+v", msgs[0])
+			}
+			if linked, _ := b.Linked(ctx, "user_1"); linked != tc.wantLinked {
+				t.Errorf("Linked = %v, want %v", linked, tc.wantLinked)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestBot_Deliver", true)
+}
+
+func TestBot_Run(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestBot_Run", "internal/notify/telegram")
+
+	testhelpers.LogTestStep(logger, "arrange", "Two pending updates")
+	api := newFakeAPI(t)
+	api.updates = []update{
+		{UpdateID: 7, Message: &message{Chat: chat{ID: 42, Type: "private"}, Text: "/help"}},
+		{UpdateID: 8},
+	}
+	b, _ := newTestBot(t, api)
+	ctx, cancel := context.WithCancel(t.Context())
+	b.sleep = func(ctx context.Context, _ time.Duration) error { return ctx.Err() }
+
+	testhelpers.LogTestStep(logger, "act", "Polling until the next offset is requested")
+	done := make(chan struct{})
+	go func() {
+		b.Run(ctx)
+		close(done)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		api.mu.Lock()
+		polled := len(api.offsets)
+		api.mu.Unlock()
+		if polled >= 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	testhelpers.LogTestStep(logger, "assert", "The help was sent and the offset moved past both updates")
+	api.mu.Lock()
+	offsets := api.offsets
+	api.mu.Unlock()
+	testhelpers.LogTestAssertion(logger, "offsets", "0, 9", offsets)
+	if len(offsets) < 2 || offsets[0] != 0 || offsets[1] != 9 {
+		t.Errorf("Offsets = %v, want 0 then 9", offsets)
+	}
+	if msgs := api.messages(); len(msgs) != 1 || !strings.Contains(msgs[0].Text, "/price") {
+		t.Errorf("Replies = %+v, want the help", msgs)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestBot_Run", true)
+}
+
+func TestBot_DeliverDigest(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestBot_DeliverDigest", "internal/notify/telegram")
+
+	testhelpers.LogTestStep(logger, "arrange", "A linked chat and a digest of twelve alerts")
+	api := newFakeAPI(t)
+	b, store := newTestBot(t, api)
+	ctx := t.Context()
+	_ = store.Telegram().LinkChat(ctx, domain.TelegramLink{UserID: "user_1", ChatID: 42})
+	n := domain.Notification{ID: "ntf_d", Type: domain.NotificationPriceAlertDigest, UserID: "user_1"}
+	for i := range 12 {
+		n.Items = append(n.Items, domain.Notification{
+			Type: domain.NotificationPriceAlert, ProductName: fmt.Sprintf("Whey <%d>", i),
+			RetailerName: "Flipkart", Price: 2000 + float64(i), URL: "/go/flipkart",
+		})
+	}
+
+	if err := b.Deliver(ctx, domain.User{ID: "user_1"}, n); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "One message listing ten alerts and a count of the rest")
+	msgs := api.messages()
+	if len(msgs) != 1 {
+		t.Fatalf("Sent %d messages, want 1", len(msgs))
+	}
+	text := msgs[0].Text
+	testhelpers.LogTestAssertion(logger, "listed alerts", maxDigestItems, strings.Count(text, " at Flipkart"))
+	if got := strings.Count(text, " at Flipkart"); got != maxDigestItems {
+		t.Errorf("Listed %d alerts, want %d:\n%s", got, maxDigestItems, text)
+	}
+	for _, want := range []string{"Whey &lt;0&gt;", "₹2,009", "<b>12 price alerts</b>", "2 more on the site"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Digest lacks %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "Whey &lt;10&gt;") {
+		t.Errorf("Digest lists alerts past the limit:\n%s", text)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestBot_DeliverDigest", true)
+}
@@ -0,0 +1,136 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// APIError is an unsuccessful Bot API call.
+type APIError struct {
+	Code        int
+	Description string
+	// RetryAfter is how long Telegram asks to wait when throttling.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("telegram: %d %s", e.Code, e.Description)
+}
+
+// Retryable reports whether the call may succeed if repeated.
+func (e *APIError) Retryable() bool {
+	return e.Code == http.StatusTooManyRequests || e.Code >= 500
+}
+
+// client calls the Bot API.
+type client struct {
+	baseURL string // ends in /bot<token>
+	http    *http.Client
+}
+
+func newClient(apiURL, token string, timeout time.Duration) *client {
+	return &client{
+		baseURL: strings.TrimRight(apiURL, "/") + "/bot" + token,
+		http:    &http.Client{Timeout: timeout},
+	}
+}
+
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	ErrorCode   int             `json:"error_code"`
+	Description string          `json:"description"`
+	Parameters  struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+// call invokes method with params as JSON and decodes the result into out,
+// if given.
+func (c *client) call(ctx context.Context, method string, params, out any) error {
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/"+method, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// The URL holds the bot token; keep it out of errors and logs.
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return &APIError{Code: http.StatusServiceUnavailable, Description: fmt.Sprintf("%s: %v", method, err)}
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	var parsed apiResponse
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return &APIError{Code: resp.StatusCode, Description: method + ": unreadable response"}
+	}
+	if !parsed.OK {
+		code := parsed.ErrorCode
+		if code == 0 {
+			code = resp.StatusCode
+		}
+		return &APIError{
+			Code:        code,
+			Description: parsed.Description,
+			RetryAfter:  time.Duration(parsed.Parameters.RetryAfter) * time.Second,
+		}
+	}
+	if out != nil {
+		return json.Unmarshal(parsed.Result, out)
+	}
+	return nil
+}
+
+type chat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+}
+
+type message struct {
+	MessageID int64  `json:"message_id"`
+	Chat      chat   `json:"chat"`
+	Text      string `json:"text"`
+}
+
+type update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *message `json:"message"`
+}
+
+// getUpdates long-polls for updates after offset.
+func (c *client) getUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]update, error) {
+	var updates []update
+	err := c.call(ctx, "getUpdates", map[string]any{
+		"offset":          offset,
+		"timeout":         int(timeout.Seconds()),
+		"allowed_updates": []string{"message"},
+	}, &updates)
+	return updates, err
+}
+
+// sendMessage sends HTML-formatted text to a chat.
+func (c *client) sendMessage(ctx context.Context, chatID int64, text string) error {
+	return c.call(ctx, "sendMessage", map[string]any{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	}, nil)
+}
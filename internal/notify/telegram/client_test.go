@@ -0,0 +1,63 @@
+package telegram
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestClient_Errors(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestClient_Errors", "internal/notify/telegram")
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	testCases := []struct {
+		name          string
+		apiURL        string
+		body          string
+		wantCode      int
+		wantRetryable bool
+		wantRetry     time.Duration
+	}{
+		{"Throttled", "", `{"ok":false,"error_code":429,"description":"Too Many Requests","parameters":{"retry_after":5}}`, 429, true, 5 * time.Second},
+		{"Blocked", "", `{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`, 403, false, 0},
+		{"Unreadable", "", `<html>Bad Gateway</html>`, http.StatusBadGateway, true, 0},
+		{"Unreachable", closed.URL, "", http.StatusServiceUnavailable, true, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer srv.Close()
+			apiURL := tc.apiURL
+			if apiURL == "" {
+				apiURL = srv.URL
+			}
+			c := newClient(apiURL, testToken, time.Second)
+
+			err := c.sendMessage(t.Context(), 42, "hi")
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Error = %v, want *APIError", err)
+			}
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantCode, apiErr.Code)
+			if apiErr.Code != tc.wantCode || apiErr.Retryable() != tc.wantRetryable || apiErr.RetryAfter != tc.wantRetry {
+				t.Errorf("APIError = %+v, retryable %v", apiErr, apiErr.Retryable())
+			}
+			if strings.Contains(err.Error(), testToken) {
+				t.Errorf("Error %q leaks the bot token", err)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestClient_Errors", true)
+}
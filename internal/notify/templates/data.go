@@ -0,0 +1,144 @@
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// Message names.
+const (
+	Verification      = "verification"
+	AlertConfirmation = "alert_confirmation"
+	PriceAlert        = "price_alert"
+	PriceAlertDigest  = "price_alert_digest"
+	PriceAlertPush    = "price_alert_push"
+	DigestPush        = "price_alert_digest_push"
+)
+
+// VerificationData is the data for Verification.
+type VerificationData struct {
+	Name string `json:"name"`
+	Link string `json:"link"`
+}
+
+// AlertConfirmationData is the data for AlertConfirmation.
+type AlertConfirmationData struct {
+	Name        string `json:"name"`
+	ProductName string `json:"product_name"`
+	Condition   string `json:"condition"`
+	Link        string `json:"link"`
+}
+
+// Alert is the data for PriceAlert and PriceAlertPush, and one item of a
+// Digest.
+type Alert struct {
+	// Name is the recipient's.
+	Name          string  `json:"name"`
+	ProductName   string  `json:"product_name"`
+	SearchName    string  `json:"search_name"`
+	Test          bool    `json:"test"`
+	RetailerName  string  `json:"retailer_name"`
+	Price         float64 `json:"price"`
+	PreviousPrice float64 `json:"previous_price"`
+	PricePerGram  float64 `json:"price_per_gram"`
+	Link          string  `json:"link"`
+	ImageURL      string  `json:"image_url"`
+	Unsubscribe   string  `json:"unsubscribe"`
+}
+
+// Digest is the data for PriceAlertDigest and DigestPush.
+type Digest struct {
+	Name  string  `json:"name"`
+	Items []Alert `json:"items"`
+	// More counts alerts left out of Items for space.
+	More        int    `json:"more"`
+	Unsubscribe string `json:"unsubscribe"`
+}
+
+// Total counts every alert in the digest, shown or not.
+func (d Digest) Total() int { return len(d.Items) + d.More }
+
+// NewAlert describes n, resolving site-relative links against siteURL.
+func NewAlert(n domain.Notification, siteURL string) Alert {
+	return Alert{
+		ProductName:   n.ProductName,
+		SearchName:    n.SearchName,
+		Test:          n.Test,
+		RetailerName:  n.RetailerName,
+		Price:         n.Price,
+		PreviousPrice: n.PreviousPrice,
+		PricePerGram:  n.PricePerGram,
+		Link:          absolute(siteURL, n.URL),
+		ImageURL:      absolute(siteURL, n.ImageURL),
+	}
+}
+
+// NewDigest describes up to limit of items, counting the rest in More. A
+// limit of zero or less includes them all.
+func NewDigest(items []domain.Notification, limit int, siteURL string) Digest {
+	var d Digest
+	if limit > 0 && len(items) > limit {
+		d.More = len(items) - limit
+		items = items[:limit]
+	}
+	for _, n := range items {
+		d.Items = append(d.Items, NewAlert(n, siteURL))
+	}
+	return d
+}
+
+func absolute(siteURL, path string) string {
+	if strings.HasPrefix(path, "/") {
+		return strings.TrimRight(siteURL, "/") + path
+	}
+	return path
+}
+
+func sampleAlert() Alert {
+	return Alert{
+		Name:          "Asha",
+		ProductName:   "Optimum Nutrition Gold Standard 100% Whey",
+		RetailerName:  "Flipkart",
+		Price:         2899,
+		PreviousPrice: 3199,
+		PricePerGram:  1.62,
+		Link:          "https://example.com/go/flipkart/prod_on_gsw/lst_1",
+		ImageURL:      "https://example.com/images/gsw.jpg",
+		Unsubscribe:   "https://example.com/unsubscribe?token=<YOUR_UNSUBSCRIBE_TOKEN_HERE>",
+	}
+}
+
+// Sample returns example data for message name, for previews, with any
+// fields in override replacing the example's.
+func Sample(name string, override json.RawMessage) (any, error) {
+	var data any
+	switch name {
+	case Verification:
+		data = &VerificationData{Name: "Asha", Link: "https://example.com/api/v1/auth/verify?token=<YOUR_VERIFICATION_TOKEN_HERE>"}
+	case AlertConfirmation:
+		data = &AlertConfirmationData{
+			Name:        "Asha",
+			ProductName: "Optimum Nutrition Gold Standard 100% Whey",
+			Condition:   "drops to ₹2,900 or less",
+			Link:        "https://example.com/api/v1/alerts/confirm?token=<YOUR_CONFIRMATION_TOKEN_HERE>",
+		}
+	case PriceAlert, PriceAlertPush:
+		a := sampleAlert()
+		data = &a
+	case PriceAlertDigest, DigestPush:
+		second := sampleAlert()
+		second.ProductName, second.SearchName, second.Price, second.PreviousPrice = "MuscleBlaze Biozyme Performance Whey", "biozyme", 2099, 0
+		data = &Digest{Name: "Asha", Items: []Alert{sampleAlert(), second}, Unsubscribe: sampleAlert().Unsubscribe}
+	default:
+		return nil, fmt.Errorf("unknown template %q: %w", name, domain.ErrNotFound)
+	}
+	if len(override) > 0 {
+		if err := json.Unmarshal(override, data); err != nil {
+			return nil, fmt.Errorf("data does not fit %s: %w", name, domain.ErrInvalid)
+		}
+	}
+	return data, nil
+}
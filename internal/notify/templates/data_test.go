@@ -0,0 +1,81 @@
+package templates
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestNewDigest(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestNewDigest", "internal/notify/templates")
+
+	items := make([]domain.Notification, 5)
+	for i := range items {
+		items[i] = domain.Notification{ProductName: "Whey", URL: "/go/flipkart", ImageURL: "https://cdn.example/w.jpg"}
+	}
+	testCases := []struct {
+		name      string
+		limit     int
+		wantItems int
+		wantMore  int
+	}{
+		{"Under the limit", 10, 5, 0},
+		{"Over the limit", 3, 3, 2},
+		{"No limit", 0, 5, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewDigest(items, tc.limit, "https://whey.example/")
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantItems, len(d.Items))
+			if len(d.Items) != tc.wantItems || d.More != tc.wantMore || d.Total() != len(items) {
+				t.Errorf("Digest has %d items and %d more, want %d and %d", len(d.Items), d.More, tc.wantItems, tc.wantMore)
+			}
+			if a := d.Items[0]; a.Link != "https://whey.example/go/flipkart" || a.ImageURL != "https://cdn.example/w.jpg" {
+				t.Errorf("Item = %+v, want the link made absolute and the image kept", a)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestNewDigest", true)
+}
+
+func TestSample(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSample", "internal/notify/templates")
+
+	testhelpers.LogTestStep(logger, "act", "Every template renders its sample")
+	for _, tmpl := range Default().Templates() {
+		data, err := Sample(tmpl.Name, nil)
+		if err != nil {
+			t.Fatalf("Sample(%s): %v", tmpl.Name, err)
+		}
+		for _, f := range tmpl.Formats {
+			if _, err := Default().Render(tmpl.Name, f, data); err != nil {
+				t.Errorf("Render(%s, %s): %v", tmpl.Name, f, err)
+			}
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Overriding fields")
+	data, err := Sample(PriceAlert, json.RawMessage(`{"product_name":"Biozyme","test":true}`))
+	if err != nil {
+		t.Fatalf("Sample: %v", err)
+	}
+	a := data.(*Alert)
+	testhelpers.LogTestAssertion(logger, "override", "Biozyme", a.ProductName)
+	if a.ProductName != "Biozyme" || !a.Test || a.RetailerName != "Flipkart" {
+		t.Errorf("Sample = %+v, want the overrides over the example", a)
+	}
+	if _, err := Sample(PriceAlert, json.RawMessage(`{"price":"cheap"}`)); !errors.Is(err, domain.ErrInvalid) {
+		t.Errorf("Ill-typed override error = %v, want ErrInvalid", err)
+	}
+	if _, err := Sample("welcome", nil); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Unknown template error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSample", true)
+}
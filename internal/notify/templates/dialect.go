@@ -0,0 +1,62 @@
+package templates
+
+import (
+	"html"
+	"strings"
+)
+
+// dialect is how a chat service marks up the .md templates' emphasis and
+// links. Bold and Strike wrap markup; Link and Escape escape plain text.
+type dialect struct {
+	Bold   func(s string) string
+	Strike func(s string) string
+	// Link links text, already escaped, to url; without a url it is text.
+	Link   func(url, text string) string
+	Escape func(s string) string
+}
+
+func (d dialect) funcs() map[string]any {
+	return map[string]any{
+		"bold":   d.Bold,
+		"strike": d.Strike,
+		"link": func(url, text string) string {
+			if url == "" {
+				return text
+			}
+			return d.Link(url, text)
+		},
+		"esc": d.Escape,
+	}
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`",
+	"[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, ">", `\>`, "|", `\|`,
+)
+
+var dialects = map[Format]dialect{
+	// Markdown is Discord's flavour of CommonMark.
+	Markdown: {
+		Bold:   func(s string) string { return "**" + s + "**" },
+		Strike: func(s string) string { return "~~" + s + "~~" },
+		Link:   func(url, text string) string { return "[" + text + "](" + url + ")" },
+		Escape: markdownEscaper.Replace,
+	},
+	// Slack's mrkdwn escapes only &, < and >, which delimit links and
+	// mentions.
+	Slack: {
+		Bold:   func(s string) string { return "*" + s + "*" },
+		Strike: func(s string) string { return "~" + s + "~" },
+		Link:   func(url, text string) string { return "<" + url + "|" + text + ">" },
+		Escape: strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace,
+	},
+	// Telegram is the Bot API's HTML parse mode.
+	Telegram: {
+		Bold:   func(s string) string { return "<b>" + s + "</b>" },
+		Strike: func(s string) string { return "<s>" + s + "</s>" },
+		Link:   func(url, text string) string { return `<a href="` + html.EscapeString(url) + `">` + text + "</a>" },
+		Escape: html.EscapeString,
+	},
+}
+
+func (f Format) dialect() dialect { return dialects[f] }
@@ -0,0 +1,271 @@
+// Package templates renders notification messages from versioned
+// templates, so every channel words an alert the same way and copy can be
+// changed without touching channel code. Each message has plain text and
+// HTML forms for email and one markdown form that is rendered in the
+// dialect of each chat service.
+//
+// Templates live in files/{name}/v{N}.{txt,html,md}. Each defines "title"
+// and "body"; HTML bodies are wrapped in files/layout.html. The highest
+// version of each message is used unless Config pins an older one, and
+// any version can be previewed.
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/i18n"
+)
+
+//go:embed files
+var files embed.FS
+
+// Format is the form a message is rendered in.
+type Format string
+
+// Formats. Markdown, Slack and Telegram all render the .md template.
+const (
+	Text     Format = "text"
+	HTML     Format = "html"
+	Markdown Format = "markdown"
+	Slack    Format = "slack"
+	Telegram Format = "telegram"
+)
+
+// Formats lists every format.
+var Formats = []Format{Text, HTML, Markdown, Slack, Telegram}
+
+func (f Format) ext() string {
+	switch f {
+	case Text:
+		return "txt"
+	case HTML:
+		return "html"
+	default:
+		return "md"
+	}
+}
+
+// Message is a rendered message.
+type Message struct {
+	Name    string `json:"name"`
+	Format  Format `json:"format"`
+	Version int    `json:"version"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+}
+
+// Template describes one message and its versions.
+type Template struct {
+	Name     string   `json:"name"`
+	Versions []int    `json:"versions"`
+	Active   int      `json:"active"`
+	Formats  []Format `json:"formats"`
+}
+
+// Config configures the Engine.
+type Config struct {
+	// Versions pins messages, by name, to a version other than the
+	// latest, e.g. to roll back a change in wording.
+	Versions map[string]int
+}
+
+type key struct {
+	name    string
+	version int
+	ext     string
+}
+
+// Engine renders messages.
+type Engine struct {
+	text     map[key]*texttemplate.Template // .txt and .md
+	html     map[key]*htmltemplate.Template
+	versions map[string][]int // ascending
+	active   map[string]int
+}
+
+var funcs = map[string]any{
+	"price": func(v float64) string { return i18n.Default().FormatPrice(domain.DefaultCurrency, v) },
+	"plural": func(n int, word string) string {
+		if n == 1 {
+			return "1 " + word
+		}
+		return strconv.Itoa(n) + " " + word + "s"
+	},
+}
+
+// New parses the built-in templates. Pinning an unknown message or version
+// is an error.
+func New(cfg Config) (*Engine, error) {
+	e := &Engine{
+		text:     make(map[key]*texttemplate.Template),
+		html:     make(map[key]*htmltemplate.Template),
+		versions: make(map[string][]int),
+		active:   make(map[string]int),
+	}
+	dirs, err := fs.ReadDir(files, "files")
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		if err := e.load(dir.Name()); err != nil {
+			return nil, err
+		}
+	}
+	for name, versions := range e.versions {
+		slices.Sort(versions)
+		versions = slices.Compact(versions)
+		e.versions[name] = versions
+		e.active[name] = versions[len(versions)-1]
+	}
+	for name, v := range cfg.Versions {
+		if !slices.Contains(e.versions[name], v) {
+			return nil, fmt.Errorf("template %s has no version %d", name, v)
+		}
+		e.active[name] = v
+	}
+	return e, nil
+}
+
+func (e *Engine) load(name string) error {
+	entries, err := fs.ReadDir(files, path.Join("files", name))
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		base, ext, _ := strings.Cut(entry.Name(), ".")
+		v, err := strconv.Atoi(strings.TrimPrefix(base, "v"))
+		if err != nil || !strings.HasPrefix(base, "v") || v <= 0 {
+			return fmt.Errorf("template file %s/%s is not named v{N}.{ext}", name, entry.Name())
+		}
+		file := path.Join("files", name, entry.Name())
+		k := key{name, v, ext}
+		switch ext {
+		case "txt", "md":
+			t, err := texttemplate.New(name).Funcs(funcs).Funcs(Markdown.dialect().funcs()).ParseFS(files, file)
+			if err != nil {
+				return err
+			}
+			e.text[k] = t
+		case "html":
+			t, err := htmltemplate.New(name).Funcs(funcs).ParseFS(files, "files/layout.html", file)
+			if err != nil {
+				return err
+			}
+			e.html[k] = t
+		default:
+			return fmt.Errorf("template file %s/%s has an unknown extension", name, entry.Name())
+		}
+		e.versions[name] = append(e.versions[name], v)
+	}
+	return nil
+}
+
+var defaultEngine = sync.OnceValue(func() *Engine {
+	e, err := New(Config{})
+	if err != nil {
+		panic(err)
+	}
+	return e
+})
+
+// Default returns an Engine using the latest version of every message.
+func Default() *Engine { return defaultEngine() }
+
+// Render renders the active version of message name in format f.
+func (e *Engine) Render(name string, f Format, data any) (Message, error) {
+	v, ok := e.active[name]
+	if !ok {
+		return Message{}, fmt.Errorf("unknown template %q: %w", name, domain.ErrNotFound)
+	}
+	return e.RenderVersion(name, f, v, data)
+}
+
+// RenderVersion renders version v of message name in format f, for
+// previews.
+func (e *Engine) RenderVersion(name string, f Format, v int, data any) (Message, error) {
+	k := key{name, v, f.ext()}
+	m := Message{Name: name, Format: f, Version: v}
+	var title, body bytes.Buffer
+	switch f {
+	case HTML:
+		t, ok := e.html[k]
+		if !ok {
+			return m, fmt.Errorf("template %s v%d has no %s form: %w", name, v, f, domain.ErrNotFound)
+		}
+		if err := t.ExecuteTemplate(&title, "title", data); err != nil {
+			return m, fmt.Errorf("render %s title: %w", name, err)
+		}
+		if err := t.ExecuteTemplate(&body, "layout", data); err != nil {
+			return m, fmt.Errorf("render %s body: %w", name, err)
+		}
+	case Text, Markdown, Slack, Telegram:
+		t, ok := e.text[k]
+		if !ok {
+			return m, fmt.Errorf("template %s v%d has no %s form: %w", name, v, f, domain.ErrNotFound)
+		}
+		if f != Text && f != Markdown {
+			// Templates are parsed with Markdown's functions; other
+			// dialects swap in their own on a copy.
+			var err error
+			if t, err = t.Clone(); err != nil {
+				return m, err
+			}
+			t.Funcs(f.dialect().funcs())
+		}
+		if err := t.ExecuteTemplate(&title, "title", data); err != nil {
+			return m, fmt.Errorf("render %s title: %w", name, err)
+		}
+		if err := t.ExecuteTemplate(&body, "body", data); err != nil {
+			return m, fmt.Errorf("render %s body: %w", name, err)
+		}
+	default:
+		return m, fmt.Errorf("unknown format %q: %w", f, domain.ErrInvalid)
+	}
+	// Catalog names could carry line breaks; a title must not.
+	m.Title = strings.Join(strings.Fields(title.String()), " ")
+	// Plain text keeps its final newline, as email bodies end with one.
+	if f == Text {
+		m.Body = strings.TrimLeft(body.String(), "\n")
+	} else {
+		m.Body = strings.Trim(body.String(), "\n")
+	}
+	return m, nil
+}
+
+// Templates lists every message, by name, with the formats its active
+// version has.
+func (e *Engine) Templates() []Template {
+	var out []Template
+	for _, name := range slices.Sorted(maps.Keys(e.versions)) {
+		t := Template{Name: name, Versions: e.versions[name], Active: e.active[name]}
+		for _, f := range Formats {
+			k := key{name, t.Active, f.ext()}
+			ok := false
+			if f == HTML {
+				_, ok = e.html[k]
+			} else {
+				_, ok = e.text[k]
+			}
+			if ok {
+				t.Formats = append(t.Formats, f)
+			}
+		}
+		out = append(out, t)
+	}
+	return out
+}
@@ -0,0 +1,116 @@
+package templates
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestEngine_Render(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestEngine_Render", "internal/notify/templates")
+
+	alert := Alert{
+		Name: "Asha", ProductName: "Whey <Gold> & *Co*", SearchName: "", RetailerName: "Flipkart",
+		Price: 2899, PreviousPrice: 3199, PricePerGram: 1.62, Link: "https://whey.example/go/1",
+	}
+	testCases := []struct {
+		name      string
+		template  string
+		format    Format
+		data      any
+		wantTitle string
+		wantBody  []string
+	}{
+		{"Email text", PriceAlert, Text, alert, "Price alert: Whey <Gold> & *Co* is now ₹2,899",
+			[]string{"Hi Asha,", "Flipkart: ₹2,899 (₹1.62 per gram of protein)", "Buy it here: https://whey.example/go/1"}},
+		{"Email HTML, escaped", PriceAlert, HTML, alert, "Price alert: Whey &lt;Gold&gt; &amp; *Co*",
+			[]string{"<!DOCTYPE html>", "<strong>Whey &lt;Gold&gt; &amp; *Co*</strong>", `href="https://whey.example/go/1"`}},
+		{"Discord markdown", PriceAlert, Markdown, alert, "Price alert",
+			[]string{`**[Whey <Gold\> & \*Co\*](https://whey.example/go/1)**`, "~~₹3,199~~ **₹2,899** at Flipkart · ₹1.62/g protein"}},
+		{"Slack mrkdwn", PriceAlert, Slack, alert, "Price alert",
+			[]string{"*<https://whey.example/go/1|Whey &lt;Gold&gt; &amp; *Co*>*", "~₹3,199~ *₹2,899* at Flipkart"}},
+		{"Telegram HTML", PriceAlert, Telegram, alert, "Price alert",
+			[]string{`<b><a href="https://whey.example/go/1">Whey &lt;Gold&gt; &amp; *Co*</a></b>`, "<s>₹3,199</s> <b>₹2,899</b>"}},
+		{"Digest with more", PriceAlertDigest, Slack, Digest{Items: []Alert{alert}, More: 4}, "*5 price alerts* since your last digest (showing 1, 4 more on the site)",
+			[]string{"~₹3,199~ *₹2,899* at Flipkart"}},
+		{"Push", PriceAlertPush, Text, Alert{ProductName: "Biozyme", RetailerName: "Flipkart", Price: 2099, Test: true}, "Test alert: Biozyme",
+			[]string{"Now ₹2,099 at Flipkart"}},
+		{"Verification", Verification, Text, VerificationData{Link: "https://whey.example/verify"}, "Verify your email address",
+			[]string{"Hi,", "https://whey.example/verify"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := Default().Render(tc.template, tc.format, tc.data)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantTitle, m.Title)
+			if m.Title != tc.wantTitle || m.Version != 1 {
+				t.Errorf("Title = %q (v%d), want %q (v1)", m.Title, m.Version, tc.wantTitle)
+			}
+			for _, want := range tc.wantBody {
+				if !strings.Contains(m.Body, want) {
+					t.Errorf("Body = %q, want it to contain %q", m.Body, want)
+				}
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestEngine_Render", true)
+}
+
+func TestEngine_Errors(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestEngine_Errors", "internal/notify/templates")
+
+	e := Default()
+	testCases := []struct {
+		name    string
+		render  func() error
+		wantErr error
+	}{
+		{"Unknown template", func() error { _, err := e.Render("welcome", Text, nil); return err }, domain.ErrNotFound},
+		{"Unknown version", func() error { _, err := e.RenderVersion(PriceAlert, Text, 9, Alert{}); return err }, domain.ErrNotFound},
+		{"No such form", func() error { _, err := e.Render(Verification, Slack, VerificationData{}); return err }, domain.ErrNotFound},
+		{"Unknown format", func() error { _, err := e.Render(PriceAlert, "pdf", Alert{}); return err }, domain.ErrInvalid},
+		{"Pin to a missing version", func() error { _, err := New(Config{Versions: map[string]int{PriceAlert: 9}}); return err }, nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.render()
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if err == nil || tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Errorf("Error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestEngine_Errors", true)
+}
+
+func TestEngine_Templates(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestEngine_Templates", "internal/notify/templates")
+
+	got := map[string]Template{}
+	for _, tmpl := range Default().Templates() {
+		got[tmpl.Name] = tmpl
+	}
+	testhelpers.LogTestAssertion(logger, "templates", 6, len(got))
+	if len(got) != 6 {
+		t.Errorf("Templates = %+v, want 6", got)
+	}
+	alert := got[PriceAlert]
+	if alert.Active != 1 || len(alert.Versions) != 1 || len(alert.Formats) != len(Formats) {
+		t.Errorf("price_alert = %+v, want v1 in every format", alert)
+	}
+	if v := got[Verification].Formats; len(v) != 2 || v[0] != Text || v[1] != HTML {
+		t.Errorf("verification formats = %v, want text and html", v)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestEngine_Templates", true)
+}
@@ -0,0 +1,103 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// recordSize is the aes128gcm record size advertised in the header. Alert
+// payloads fit in a single record.
+const recordSize = 4096
+
+// maxPayload is the largest plaintext a single record carries: the record
+// less the 16 byte tag and the 1 byte padding delimiter.
+const maxPayload = recordSize - 17
+
+// encrypt encrypts payload to a subscription's keys as RFC 8291 describes,
+// producing an aes128gcm body (RFC 8188) with the sender's ephemeral key
+// in the header.
+func encrypt(payload []byte, p256dh, auth string) ([]byte, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return encryptWith(payload, p256dh, auth, key, salt)
+}
+
+// encryptWith is encrypt with the ephemeral key and salt supplied, so the
+// RFC's test vector can be reproduced.
+func encryptWith(payload []byte, p256dh, auth string, key *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	if len(payload) > maxPayload {
+		return nil, fmt.Errorf("payload of %d bytes exceeds %d", len(payload), maxPayload)
+	}
+	uaBytes, err := decodeKey(p256dh)
+	if err != nil {
+		return nil, fmt.Errorf("p256dh: %w", err)
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaBytes)
+	if err != nil {
+		return nil, fmt.Errorf("p256dh: %w", err)
+	}
+	authSecret, err := decodeKey(auth)
+	if err != nil || len(authSecret) != 16 {
+		return nil, errors.New("auth: want a 16 byte secret")
+	}
+	shared, err := key.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := key.PublicKey().Bytes()
+
+	// IKM = HKDF(auth_secret, ecdh_secret, "WebPush: info" || 0 || ua_public || as_public)
+	info := append([]byte("WebPush: info\x00"), uaBytes...)
+	info = append(info, asPublic...)
+	ikm, err := hkdf.Key(sha256.New, shared, authSecret, string(info), 32)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header: salt || record size || key ID length || key ID (as_public).
+	out := make([]byte, 0, 16+4+1+len(asPublic)+len(payload)+17)
+	out = append(out, salt...)
+	out = binary.BigEndian.AppendUint32(out, recordSize)
+	out = append(out, byte(len(asPublic)))
+	out = append(out, asPublic...)
+	// The only record is the last, so its padding delimiter is 2.
+	plaintext := append(append([]byte(nil), payload...), 2)
+	return gcm.Seal(out, nonce, plaintext, nil), nil
+}
+
+// decodeKey decodes base64url with or without padding, which browsers and
+// libraries disagree on.
+func decodeKey(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
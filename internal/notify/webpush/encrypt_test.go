@@ -0,0 +1,136 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// Keys and values from RFC 8291, Appendix A.
+const (
+	rfcPlaintext = "When I grow up, I want to be a watermelon"
+	rfcASPrivate = "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"
+	rfcUAPublic  = "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"
+	rfcUAPrivate = "q1dXpw3UpT5VOmu_cf_v6ih07Aems3njxI-JWgLcM94"
+	rfcAuth      = "BTBZMqHH6r4Tts7J_aSIgg"
+	rfcSalt      = "DGv6ra1nlYgDCS1FRnbzlw"
+	rfcBody      = "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN"
+)
+
+// decrypt is the browser's side of encrypt, for checking what pushes carry.
+func decrypt(t *testing.T, body []byte, uaPrivate *ecdh.PrivateKey, auth string) string {
+	t.Helper()
+	if len(body) < 21 || len(body) < 21+int(body[20]) {
+		t.Fatalf("Body of %d bytes is too short", len(body))
+	}
+	salt, idLen := body[:16], int(body[20])
+	if rs := binary.BigEndian.Uint32(body[16:20]); rs != recordSize {
+		t.Fatalf("Record size = %d", rs)
+	}
+	asPublic, err := ecdh.P256().NewPublicKey(body[21 : 21+idLen])
+	if err != nil {
+		t.Fatalf("Key ID: %v", err)
+	}
+	shared, err := uaPrivate.ECDH(asPublic)
+	if err != nil {
+		t.Fatalf("ECDH: %v", err)
+	}
+	authSecret, _ := decodeKey(auth)
+	info := append([]byte("WebPush: info\x00"), uaPrivate.PublicKey().Bytes()...)
+	info = append(info, asPublic.Bytes()...)
+	ikm, _ := hkdf.Key(sha256.New, shared, authSecret, string(info), 32)
+	cek, _ := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	nonce, _ := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	block, _ := aes.NewCipher(cek)
+	gcm, _ := cipher.NewGCM(block)
+	plain, err := gcm.Open(nil, nonce, body[21+idLen:], nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if plain[len(plain)-1] != 2 {
+		t.Fatalf("Padding delimiter = %d, want 2", plain[len(plain)-1])
+	}
+	return string(plain[:len(plain)-1])
+}
+
+func mustDecode(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := decodeKey(s)
+	if err != nil {
+		t.Fatalf("decode %q: %v", s, err)
+	}
+	return b
+}
+
+func TestEncrypt_RFC8291Vector(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestEncrypt_RFC8291Vector", "internal/notify/webpush")
+
+	asPrivate, err := ecdh.P256().NewPrivateKey(mustDecode(t, rfcASPrivate))
+	if err != nil {
+		t.Fatalf("NewPrivateKey: %v", err)
+	}
+	body, err := encryptWith([]byte(rfcPlaintext), rfcUAPublic, rfcAuth, asPrivate, mustDecode(t, rfcSalt))
+	if err != nil {
+		t.Fatalf("encryptWith: %v", err)
+	}
+	got := base64.RawURLEncoding.EncodeToString(body)
+	testhelpers.LogTestAssertion(logger, "body", rfcBody, got)
+	if got != rfcBody {
+		t.Errorf("Body = %s\nwant   %s", got, rfcBody)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestEncrypt_RFC8291Vector", true)
+}
+
+func TestEncrypt_RoundTrip(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestEncrypt_RoundTrip", "internal/notify/webpush")
+
+	uaPrivate, _ := ecdh.P256().NewPrivateKey(mustDecode(t, rfcUAPrivate))
+	testCases := []struct {
+		name    string
+		payload string
+		p256dh  string
+		auth    string
+		wantErr bool
+	}{
+		{"Unpadded keys", `{"title":"Price alert"}`, rfcUAPublic, rfcAuth, false},
+		{"Padded keys", `{"title":"Price alert"}`, rfcUAPublic + "=", rfcAuth + "==", false},
+		{"Largest payload", strings.Repeat("x", maxPayload), rfcUAPublic, rfcAuth, false},
+		{"Payload too large", strings.Repeat("x", maxPayload+1), rfcUAPublic, rfcAuth, true},
+		{"Key not on the curve", "{}", rfcUAPublic[:len(rfcUAPublic)-4] + "AAAA", rfcAuth, true},
+		{"Short auth secret", "{}", rfcUAPublic, "BTBZMqHH6r4", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, err := encrypt([]byte(tc.payload), tc.p256dh, tc.auth)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err != nil)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("encrypt succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("encrypt: %v", err)
+			}
+			if got := decrypt(t, body, uaPrivate, tc.auth); got != tc.payload {
+				t.Errorf("Decrypted %d bytes, want %d", len(got), len(tc.payload))
+			}
+		})
+	}
+	if _, err := decodeKey("not base64!"); err == nil {
+		t.Error("decodeKey accepted invalid input")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestEncrypt_RoundTrip", true)
+}
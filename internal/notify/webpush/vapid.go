@@ -0,0 +1,99 @@
+package webpush
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"time"
+)
+
+// VAPIDKey identifies this server to push services (RFC 8292). Browsers
+// are given its public key when subscribing and only accept pushes signed
+// by it, so it must stay the same for as long as subscriptions exist.
+type VAPIDKey struct {
+	private *ecdsa.PrivateKey
+	public  []byte // uncompressed P-256 point
+}
+
+// ParseVAPIDKey parses a base64url encoded 32 byte P-256 private key, the
+// format web-push libraries generate.
+func ParseVAPIDKey(s string) (*VAPIDKey, error) {
+	raw, err := decodeKey(s)
+	if err != nil {
+		return nil, fmt.Errorf("vapid key: %w", err)
+	}
+	// crypto/ecdh validates the scalar and derives the public point.
+	k, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("vapid key: %w", err)
+	}
+	return newVAPIDKey(k), nil
+}
+
+// GenerateVAPIDKey returns a new key and its base64url encoding for
+// ParseVAPIDKey.
+func GenerateVAPIDKey() (*VAPIDKey, string, error) {
+	k, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, "", err
+	}
+	return newVAPIDKey(k), base64.RawURLEncoding.EncodeToString(k.Bytes()), nil
+}
+
+func newVAPIDKey(k *ecdh.PrivateKey) *VAPIDKey {
+	pub := k.PublicKey().Bytes()
+	return &VAPIDKey{
+		private: &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(pub[1:33]),
+				Y:     new(big.Int).SetBytes(pub[33:]),
+			},
+			D: new(big.Int).SetBytes(k.Bytes()),
+		},
+		public: pub,
+	}
+}
+
+// PublicKey returns the base64url public key browsers pass to
+// pushManager.subscribe as applicationServerKey.
+func (k *VAPIDKey) PublicKey() string {
+	return base64.RawURLEncoding.EncodeToString(k.public)
+}
+
+// authorization returns the Authorization header for a push to endpoint:
+// an ES256 JWT for the endpoint's origin, valid until exp, naming subject
+// as the contact for the push service.
+func (k *VAPIDKey) authorization(endpoint, subject string, exp time.Time) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": exp.Unix(),
+		"sub": subject,
+	})
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	signingInput := enc.EncodeToString([]byte(`{"typ":"JWT","alg":"ES256"}`)) + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, k.private, digest[:])
+	if err != nil {
+		return "", err
+	}
+	// JWS wants the fixed-width r || s, not ASN.1.
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return "vapid t=" + signingInput + "." + enc.EncodeToString(sig) + ", k=" + k.PublicKey(), nil
+}
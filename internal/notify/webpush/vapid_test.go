@@ -0,0 +1,96 @@
+package webpush
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestParseVAPIDKey(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestParseVAPIDKey", "internal/notify/webpush")
+
+	_, encoded, err := GenerateVAPIDKey()
+	if err != nil {
+		t.Fatalf("GenerateVAPIDKey: %v", err)
+	}
+	testCases := []struct {
+		name    string
+		raw     string
+		wantErr bool
+	}{
+		{"Generated", encoded, false},
+		{"Padded", encoded + "=", false},
+		{"Not base64", "not a key!", true},
+		{"Wrong length", encoded[:20], true},
+		{"Zero scalar", base64.RawURLEncoding.EncodeToString(make([]byte, 32)), true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			k, err := ParseVAPIDKey(tc.raw)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err != nil)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseVAPIDKey error = %v, want error %v", err, tc.wantErr)
+			}
+			if err == nil && len(mustDecode(t, k.PublicKey())) != 65 {
+				t.Errorf("PublicKey = %q, want an uncompressed point", k.PublicKey())
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestParseVAPIDKey", true)
+}
+
+func TestVAPIDKey_Authorization(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestVAPIDKey_Authorization", "internal/notify/webpush")
+
+	testhelpers.LogTestStep(logger, "act", "Signing a push to an FCM endpoint")
+	key, _, _ := GenerateVAPIDKey()
+	exp := time.Date(2026, 10, 16, 21, 0, 0, 0, time.UTC)
+	header, err := key.authorization("https://fcm.googleapis.com/fcm/send/abc:def", "mailto:alerts@example.com", exp)
+	if err != nil {
+		t.Fatalf("authorization: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The header carries a valid ES256 JWT and the public key")
+	token, ok := strings.CutPrefix(header, "vapid t=")
+	token, pub, ok2 := strings.Cut(token, ", k=")
+	if !ok || !ok2 || pub != key.PublicKey() {
+		t.Fatalf("Header = %q", header)
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("JWT has %d parts", len(parts))
+	}
+	var claims struct {
+		Aud string `json:"aud"`
+		Exp int64  `json:"exp"`
+		Sub string `json:"sub"`
+	}
+	if err := json.Unmarshal(mustDecode(t, parts[1]), &claims); err != nil {
+		t.Fatalf("Claims: %v", err)
+	}
+	testhelpers.LogTestAssertion(logger, "aud", "https://fcm.googleapis.com", claims.Aud)
+	if claims.Aud != "https://fcm.googleapis.com" || claims.Exp != exp.Unix() || claims.Sub != "mailto:alerts@example.com" {
+		t.Errorf("Claims = %+v", claims)
+	}
+	sig := mustDecode(t, parts[2])
+	if len(sig) != 64 {
+		t.Fatalf("Signature is %d bytes, want 64", len(sig))
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(&key.private.PublicKey, digest[:], r, s) {
+		t.Error("Signature does not verify")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestVAPIDKey_Authorization", true)
+}
@@ -0,0 +1,382 @@
+// Package webpush delivers price alerts as browser notifications through
+// the Web Push protocol (RFC 8030). Payloads are encrypted to each
+// subscription's keys (RFC 8291) and pushes are signed with the server's
+// VAPID key (RFC 8292), so no third-party account is involved.
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+	"github.com/yourusername/whey-price-compare/internal/notify/templates"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// errGone means the push service no longer knows a subscription.
+var errGone = errors.New("push subscription expired or unsubscribed")
+
+// Config configures the Sender.
+type Config struct {
+	// Subject is a mailto: or https: contact for push service operators.
+	Subject string
+	// BaseURL is the public site root that notification clicks open.
+	BaseURL string
+	// EndpointHosts are the push services subscriptions may point at; a
+	// host matches itself and its subdomains. Pushes are POSTed to
+	// browser-supplied endpoints, so anything else is refused.
+	EndpointHosts []string
+	// TTL is how long a push service holds a push for an offline browser.
+	TTL time.Duration
+	// MaxPerUser bounds subscriptions per user; the oldest are dropped.
+	MaxPerUser int
+	// MaxAttempts bounds tries per push after retryable failures.
+	MaxAttempts int
+	// Backoff is the wait before the first retry; it doubles after each.
+	Backoff time.Duration
+	// Timeout bounds each request to a push service.
+	Timeout time.Duration
+}
+
+// DefaultConfig accepts the push services of Chrome, Firefox, Edge and
+// Safari, and keeps undelivered pushes for a day.
+func DefaultConfig() Config {
+	return Config{
+		EndpointHosts: []string{"fcm.googleapis.com", "push.services.mozilla.com", "notify.windows.com", "push.apple.com"},
+		TTL:           24 * time.Hour,
+		MaxPerUser:    10,
+		MaxAttempts:   3,
+		Backoff:       time.Second,
+		Timeout:       10 * time.Second,
+	}
+}
+
+// Sender stores subscriptions and pushes notifications to them. It
+// implements notify.Channel.
+type Sender struct {
+	cfg       Config
+	key       *VAPIDKey
+	subs      repositories.PushSubscriptionRepository
+	templates *templates.Engine
+	client    *http.Client
+	logger    *zap.Logger
+	now       func() time.Time
+	sleep     func(ctx context.Context, d time.Duration) error
+}
+
+// NewSender creates a Sender signing pushes with key.
+func NewSender(cfg Config, key *VAPIDKey, subs repositories.PushSubscriptionRepository, logger *zap.Logger) *Sender {
+	def := DefaultConfig()
+	if len(cfg.EndpointHosts) == 0 {
+		cfg.EndpointHosts = def.EndpointHosts
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = def.TTL
+	}
+	if cfg.MaxPerUser <= 0 {
+		cfg.MaxPerUser = def.MaxPerUser
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = def.MaxAttempts
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = def.Backoff
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &Sender{
+		cfg:       cfg,
+		key:       key,
+		subs:      subs,
+		templates: templates.Default(),
+		client:    &http.Client{Timeout: cfg.Timeout},
+		logger:    logger,
+		now:       time.Now,
+		sleep:     sleepCtx,
+	}
+}
+
+// WithTemplates words notifications with e rather than the latest
+// built-in templates. It returns s.
+func (s *Sender) WithTemplates(e *templates.Engine) *Sender {
+	s.templates = e
+	return s
+}
+
+// PublicKey returns the VAPID public key browsers subscribe with.
+func (s *Sender) PublicKey() string { return s.key.PublicKey() }
+
+// Subscribe stores a browser's subscription for userID, as reported by
+// PushSubscription.toJSON.
+func (s *Sender) Subscribe(ctx context.Context, userID, endpoint, p256dh, auth string) (domain.PushSubscription, error) {
+	if err := s.checkEndpoint(endpoint); err != nil {
+		return domain.PushSubscription{}, fmt.Errorf("%w: %w", err, domain.ErrInvalid)
+	}
+	// Encrypting a probe checks both keys the way delivery will use them.
+	if _, err := encrypt(nil, p256dh, auth); err != nil {
+		return domain.PushSubscription{}, fmt.Errorf("subscription keys: %w", domain.ErrInvalid)
+	}
+	sub, err := s.subs.SavePushSubscription(ctx, domain.PushSubscription{
+		UserID:    userID,
+		Endpoint:  endpoint,
+		P256DH:    p256dh,
+		Auth:      auth,
+		CreatedAt: s.now().UTC(),
+	})
+	if err != nil {
+		return domain.PushSubscription{}, err
+	}
+	existing, err := s.subs.UserPushSubscriptions(ctx, userID)
+	if err != nil {
+		return domain.PushSubscription{}, err
+	}
+	for _, old := range existing[:max(len(existing)-s.cfg.MaxPerUser, 0)] {
+		if err := s.subs.DeletePushSubscription(ctx, old.ID); err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return domain.PushSubscription{}, err
+		}
+	}
+	return sub, nil
+}
+
+// Unsubscribe removes one of userID's subscriptions.
+func (s *Sender) Unsubscribe(ctx context.Context, userID, id string) error {
+	subs, err := s.subs.UserPushSubscriptions(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		if sub.ID == id {
+			return s.subs.DeletePushSubscription(ctx, id)
+		}
+	}
+	return fmt.Errorf("push subscription %q: %w", id, domain.ErrNotFound)
+}
+
+// Subscribed reports whether userID has any subscriptions. It fits
+// alerts.ReachableFunc.
+func (s *Sender) Subscribed(ctx context.Context, userID string) (bool, error) {
+	subs, err := s.subs.UserPushSubscriptions(ctx, userID)
+	return len(subs) > 0, err
+}
+
+// checkEndpoint accepts https URLs on the configured push services.
+func (s *Sender) checkEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return errors.New("endpoint must be an https URL")
+	}
+	host := u.Hostname()
+	for _, h := range s.cfg.EndpointHosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return nil
+		}
+	}
+	return fmt.Errorf("endpoint host %q is not a known push service", host)
+}
+
+// payload is the JSON the service worker receives and shows.
+type payload struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url"`
+	// Tag makes a newer alert for the same product replace the older one.
+	Tag string `json:"tag"`
+}
+
+// Name implements notify.Channel.
+func (s *Sender) Name() string { return domain.ChannelWebPush }
+
+// Deliver implements notify.Channel. It pushes to every browser the user
+// subscribed and succeeds if any accepts; users without subscriptions are
+// unreachable. Subscriptions the push service has dropped are deleted.
+func (s *Sender) Deliver(ctx context.Context, u domain.User, n domain.Notification) error {
+	var msg payload
+	var err error
+	switch n.Type {
+	case domain.NotificationPriceAlert:
+		msg, err = s.alertPayload(n)
+		msg.Tag = "price-alert-" + n.ProductID
+	case domain.NotificationPriceAlertDigest:
+		msg, err = s.digestPayload(n.Items)
+	default:
+		return fmt.Errorf("no push message for notification type %q", n.Type)
+	}
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	subs, err := s.subs.UserPushSubscriptions(ctx, u.ID)
+	if err != nil {
+		return fmt.Errorf("load push subscriptions: %w", err)
+	}
+
+	delivered := 0
+	var lastErr error
+	for _, sub := range subs {
+		err := s.push(ctx, sub, body)
+		switch {
+		case err == nil:
+			delivered++
+		case errors.Is(err, errGone):
+			if err := s.subs.DeletePushSubscription(ctx, sub.ID); err != nil && !errors.Is(err, domain.ErrNotFound) {
+				lastErr = err
+			}
+			s.logger.Info("Push subscription gone, deleted",
+				zap.String("operation", "WebPush"),
+				zap.String("subscription_id", sub.ID),
+			)
+		default:
+			lastErr = err
+		}
+	}
+	if delivered > 0 {
+		return nil
+	}
+	if lastErr == nil {
+		return notify.ErrUnreachable
+	}
+	return lastErr
+}
+
+// maxDigestLines bounds the alerts a digest notification lists; browsers
+// show only a few lines.
+const maxDigestLines = 3
+
+func (s *Sender) alertPayload(n domain.Notification) (payload, error) {
+	m, err := s.templates.Render(templates.PriceAlertPush, templates.Text, templates.NewAlert(n, s.cfg.BaseURL))
+	if err != nil {
+		return payload{}, err
+	}
+	return payload{Title: m.Title, Body: m.Body, URL: s.link(n.URL)}, nil
+}
+
+// digestPayload summarises items, opening the deal when there is only one.
+func (s *Sender) digestPayload(items []domain.Notification) (payload, error) {
+	if len(items) == 1 {
+		msg, err := s.alertPayload(items[0])
+		msg.Tag = "price-alert-digest"
+		return msg, err
+	}
+	m, err := s.templates.Render(templates.DigestPush, templates.Text, templates.NewDigest(items, maxDigestLines, s.cfg.BaseURL))
+	if err != nil {
+		return payload{}, err
+	}
+	return payload{
+		Title: m.Title,
+		Body:  m.Body,
+		URL:   s.cfg.BaseURL + "/",
+		Tag:   "price-alert-digest",
+	}, nil
+}
+
+func (s *Sender) link(path string) string {
+	if strings.HasPrefix(path, "/") {
+		return s.cfg.BaseURL + path
+	}
+	return path
+}
+
+// push sends msg to one subscription, retrying throttling and server
+// errors.
+func (s *Sender) push(ctx context.Context, sub domain.PushSubscription, msg []byte) error {
+	// Refuse subscriptions stored before an EndpointHosts change.
+	if err := s.checkEndpoint(sub.Endpoint); err != nil {
+		return fmt.Errorf("%w: %w", errGone, err)
+	}
+	body, err := encrypt(msg, sub.P256DH, sub.Auth)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errGone, err)
+	}
+	authz, err := s.key.authorization(sub.Endpoint, s.cfg.Subject, s.now().Add(12*time.Hour))
+	if err != nil {
+		return err
+	}
+
+	wait := s.cfg.Backoff
+	for attempt := 1; ; attempt++ {
+		retryAfter, err := s.post(ctx, sub.Endpoint, authz, body)
+		if err == nil || errors.Is(err, errGone) || ctx.Err() != nil || retryAfter < 0 || attempt >= s.cfg.MaxAttempts {
+			return err
+		}
+		d := wait
+		if retryAfter > 0 {
+			d = min(retryAfter, 30*time.Second)
+		}
+		s.logger.Warn("Push failed, retrying",
+			zap.String("operation", "WebPush"),
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", d),
+			zap.Error(err),
+		)
+		if err := s.sleep(ctx, d); err != nil {
+			return err
+		}
+		wait *= 2
+	}
+}
+
+// post makes one push request. A failure returns how long to wait before
+// retrying: zero for the default backoff, negative if it is permanent.
+func (s *Sender) post(ctx context.Context, endpoint, authz string, body []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Authorization", authz)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(s.cfg.TTL.Seconds())))
+	req.Header.Set("Urgency", "normal")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// The endpoint URL is a capability for the subscription; keep it
+		// out of logs.
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return 0, fmt.Errorf("push: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return 0, nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return -1, errGone
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		secs, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return time.Duration(max(secs, 0)) * time.Second, fmt.Errorf("push service returned %d", resp.StatusCode)
+	default:
+		return -1, fmt.Errorf("push service returned %d", resp.StatusCode)
+	}
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
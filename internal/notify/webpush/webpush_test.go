@@ -0,0 +1,284 @@
+package webpush
+
+import (
+	"context"
+	"crypto/ecdh"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/notify"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// fakePushService answers pushes to each endpoint path with the statuses
+// queued for it, then 201, recording the bodies it accepted.
+type fakePushService struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses map[string][]int
+	accepted map[string][][]byte
+	headers  http.Header
+}
+
+func newFakePushService(t *testing.T) *fakePushService {
+	t.Helper()
+	f := &fakePushService{statuses: map[string][]int{}, accepted: map[string][][]byte{}}
+	f.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if queued := f.statuses[r.URL.Path]; len(queued) > 0 {
+			f.statuses[r.URL.Path] = queued[1:]
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(queued[0])
+			return
+		}
+		f.accepted[r.URL.Path] = append(f.accepted[r.URL.Path], body)
+		f.headers = r.Header.Clone()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// newTestSender returns a Sender trusting svc, and the waits it slept.
+func newTestSender(t *testing.T, svc *fakePushService) (*Sender, *memory.Store, *[]time.Duration) {
+	t.Helper()
+	key, _, err := GenerateVAPIDKey()
+	if err != nil {
+		t.Fatalf("GenerateVAPIDKey: %v", err)
+	}
+	store := memory.NewStore()
+	s := NewSender(Config{
+		Subject:       "mailto:alerts@example.com",
+		BaseURL:       "https://whey.example/",
+		EndpointHosts: []string{"127.0.0.1"},
+		MaxPerUser:    3,
+	}, key, store.PushSubscriptions(), testhelpers.SetupTestLogger(t))
+	s.client = svc.Client()
+	var waits []time.Duration
+	s.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return s, store, &waits
+}
+
+func TestSender_Subscribe(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSender_Subscribe", "internal/notify/webpush")
+
+	svc := newFakePushService(t)
+	testCases := []struct {
+		name     string
+		endpoint string
+		p256dh   string
+		auth     string
+		wantErr  error
+	}{
+		{"Valid", svc.URL + "/push/1", rfcUAPublic, rfcAuth, nil},
+		{"Plain http", strings.Replace(svc.URL, "https", "http", 1) + "/push/1", rfcUAPublic, rfcAuth, domain.ErrInvalid},
+		{"Unknown push service", "https://evil.example/push/1", rfcUAPublic, rfcAuth, domain.ErrInvalid},
+		{"Lookalike host", "https://127.0.0.1.evil.example/push/1", rfcUAPublic, rfcAuth, domain.ErrInvalid},
+		{"Missing keys", svc.URL + "/push/1", "", "", domain.ErrInvalid},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, _, _ := newTestSender(t, svc)
+			sub, err := s.Subscribe(t.Context(), "user_1", tc.endpoint, tc.p256dh, tc.auth)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Subscribe error = %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && (sub.ID == "" || sub.UserID != "user_1") {
+				t.Errorf("Subscribe = %+v", sub)
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Subscribing past the limit, then moving an endpoint to another user")
+	s, store, _ := newTestSender(t, svc)
+	ctx := t.Context()
+	var ids []string
+	for _, path := range []string{"/a", "/b", "/c", "/d"} {
+		sub, err := s.Subscribe(ctx, "user_1", svc.URL+path, rfcUAPublic, rfcAuth)
+		if err != nil {
+			t.Fatalf("Subscribe: %v", err)
+		}
+		ids = append(ids, sub.ID)
+	}
+	if _, err := s.Subscribe(ctx, "user_2", svc.URL+"/d", rfcUAPublic, rfcAuth); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The oldest was dropped and the endpoint left the first user")
+	subs, _ := store.PushSubscriptions().UserPushSubscriptions(ctx, "user_1")
+	testhelpers.LogTestAssertion(logger, "user_1 subscriptions", 2, len(subs))
+	if len(subs) != 2 || subs[0].ID != ids[1] || subs[1].ID != ids[2] {
+		t.Errorf("Subscriptions = %+v, want %v", subs, ids[1:3])
+	}
+	if err := s.Unsubscribe(ctx, "user_2", ids[1]); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Unsubscribing another user's subscription = %v, want ErrNotFound", err)
+	}
+	if err := s.Unsubscribe(ctx, "user_1", ids[1]); err != nil {
+		t.Errorf("Unsubscribe: %v", err)
+	}
+	if ok, _ := s.Subscribed(ctx, "user_2"); !ok {
+		t.Error("Subscribed(user_2) = false")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSender_Subscribe", true)
+}
+
+func TestSender_Deliver(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSender_Deliver", "internal/notify/webpush")
+
+	n := domain.Notification{
+		ID: "ntf_1", Type: domain.NotificationPriceAlert, UserID: "user_1",
+		ProductID: "prod_on_gsw", ProductName: "Gold Standard 100% Whey", RetailerName: "Flipkart",
+		Price: 2899, PricePerGram: 1.62, URL: "/go/flipkart",
+	}
+	uaPrivate, _ := ecdh.P256().NewPrivateKey(mustDecode(t, rfcUAPrivate))
+
+	testCases := []struct {
+		name         string
+		statuses     map[string][]int // by endpoint path
+		wantErr      error
+		wantFailure  bool // an error other than ErrUnreachable
+		wantAccepted []string
+		wantKept     int
+		wantWaits    []time.Duration
+	}{
+		{
+			name:         "Expired, throttled and healthy browsers",
+			statuses:     map[string][]int{"/gone": {http.StatusGone}, "/busy": {http.StatusTooManyRequests}, "/ok": nil},
+			wantAccepted: []string{"/busy", "/ok"},
+			wantKept:     2,
+			wantWaits:    []time.Duration{2 * time.Second},
+		},
+		{name: "No subscriptions", wantErr: notify.ErrUnreachable},
+		{
+			name:     "Only expired browsers",
+			statuses: map[string][]int{"/gone": {http.StatusNotFound}},
+			wantErr:  notify.ErrUnreachable,
+		},
+		{
+			name:        "Rejected push",
+			statuses:    map[string][]int{"/bad": {http.StatusBadRequest}},
+			wantFailure: true,
+			wantKept:    1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := newFakePushService(t)
+			s, store, waits := newTestSender(t, svc)
+			ctx := t.Context()
+			for path, statuses := range tc.statuses {
+				svc.statuses[path] = statuses
+				if _, err := s.Subscribe(ctx, "user_1", svc.URL+path, rfcUAPublic, rfcAuth); err != nil {
+					t.Fatalf("Subscribe: %v", err)
+				}
+			}
+
+			err := s.Deliver(ctx, domain.User{ID: "user_1"}, n)
+
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			switch {
+			case tc.wantFailure:
+				if err == nil || errors.Is(err, notify.ErrUnreachable) {
+					t.Errorf("Deliver error = %v, want a delivery failure", err)
+				}
+			case !errors.Is(err, tc.wantErr):
+				t.Errorf("Deliver error = %v, want %v", err, tc.wantErr)
+			}
+			for _, path := range tc.wantAccepted {
+				bodies := svc.accepted[path]
+				if len(bodies) != 1 {
+					t.Fatalf("%s accepted %d pushes, want 1", path, len(bodies))
+				}
+				var got payload
+				if err := json.Unmarshal([]byte(decrypt(t, bodies[0], uaPrivate, rfcAuth)), &got); err != nil {
+					t.Fatalf("Payload: %v", err)
+				}
+				if got.Title != "Price alert: Gold Standard 100% Whey" || !strings.Contains(got.Body, "₹2,899 at Flipkart") ||
+					got.URL != "https://whey.example/go/flipkart" || got.Tag != "price-alert-prod_on_gsw" {
+					t.Errorf("Payload = %+v", got)
+				}
+			}
+			if len(tc.wantAccepted) > 0 {
+				h := svc.headers
+				if h.Get("Content-Encoding") != "aes128gcm" || h.Get("TTL") != "86400" || !strings.HasPrefix(h.Get("Authorization"), "vapid t=") {
+					t.Errorf("Headers = %v", h)
+				}
+			}
+			kept, _ := store.PushSubscriptions().UserPushSubscriptions(ctx, "user_1")
+			if len(kept) != tc.wantKept {
+				t.Errorf("Kept %d subscriptions, want %d", len(kept), tc.wantKept)
+			}
+			if len(*waits) != len(tc.wantWaits) || len(tc.wantWaits) > 0 && (*waits)[0] != tc.wantWaits[0] {
+				t.Errorf("Waits = %v, want %v", *waits, tc.wantWaits)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSender_Deliver", true)
+}
+
+func TestSender_DigestPayload(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSender_DigestPayload", "internal/notify/webpush")
+
+	svc := newFakePushService(t)
+	s, _, _ := newTestSender(t, svc)
+	item := func(name string, price float64) domain.Notification {
+		return domain.Notification{ProductName: name, RetailerName: "Flipkart", Price: price, URL: "/go/" + name}
+	}
+	testCases := []struct {
+		name      string
+		items     []domain.Notification
+		wantTitle string
+		wantBody  string
+		wantURL   string
+	}{
+		{
+			name:      "One alert opens the deal",
+			items:     []domain.Notification{item("biozyme", 2899)},
+			wantTitle: "Price alert: biozyme",
+			wantBody:  "Now ₹2,899 at Flipkart",
+			wantURL:   "https://whey.example/go/biozyme",
+		},
+		{
+			name:      "Several alerts open the site",
+			items:     []domain.Notification{item("a", 1000), item("b", 2000), item("c", 3000), item("d", 4000), item("e", 5000)},
+			wantTitle: "5 price alerts reached your target",
+			wantBody:  "a: ₹1,000 at Flipkart\nb: ₹2,000 at Flipkart\nc: ₹3,000 at Flipkart\n…and 2 more",
+			wantURL:   "https://whey.example/",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := s.digestPayload(tc.items)
+			if err != nil {
+				t.Fatalf("digestPayload: %v", err)
+			}
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantTitle, got.Title)
+			if got.Title != tc.wantTitle || got.Body != tc.wantBody || got.URL != tc.wantURL || got.Tag != "price-alert-digest" {
+				t.Errorf("digestPayload = %+v, want %q, %q, %q", got, tc.wantTitle, tc.wantBody, tc.wantURL)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSender_DigestPayload", true)
+}
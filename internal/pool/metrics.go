@@ -0,0 +1,35 @@
+package pool
+
+import "github.com/yourusername/whey-price-compare/internal/metrics"
+
+// ExportMetrics registers the pools' statistics with reg, labelled by
+// pool name: tasks by outcome, and the queue a backlog shows up in before
+// Submit starts blocking its callers.
+func ExportMetrics(reg *metrics.Registry, pools ...*Pool) {
+	collect := func(value func(*Pool, Stats) float64) func(emit func(float64, ...string)) {
+		return func(emit func(float64, ...string)) {
+			for _, p := range pools {
+				emit(value(p, p.Stats()), p.cfg.Name)
+			}
+		}
+	}
+	reg.CounterFunc("pool_tasks_submitted_total", "Tasks accepted onto the queue.", []string{"pool"},
+		collect(func(_ *Pool, s Stats) float64 { return float64(s.Submitted) }))
+	reg.CounterFunc("pool_tasks_total", "Tasks finished or turned away, by result: completed, failed or rejected.", []string{"pool", "result"},
+		func(emit func(float64, ...string)) {
+			for _, p := range pools {
+				s := p.Stats()
+				emit(float64(s.Completed), p.cfg.Name, "completed")
+				emit(float64(s.Failed), p.cfg.Name, "failed")
+				emit(float64(s.Rejected), p.cfg.Name, "rejected")
+			}
+		})
+	reg.CounterFunc("pool_task_panics_total", "Tasks that panicked, also counted as failed.", []string{"pool"},
+		collect(func(_ *Pool, s Stats) float64 { return float64(s.Panicked) }))
+	reg.GaugeFunc("pool_queued_tasks", "Tasks waiting for a worker.", []string{"pool"},
+		collect(func(_ *Pool, s Stats) float64 { return float64(s.Queued) }))
+	reg.GaugeFunc("pool_queue_capacity", "Tasks that may wait before Submit blocks.", []string{"pool"},
+		collect(func(p *Pool, _ Stats) float64 { return float64(p.cfg.QueueDepth) }))
+	reg.GaugeFunc("pool_workers", "Workers running tasks.", []string{"pool"},
+		collect(func(p *Pool, _ Stats) float64 { return float64(p.cfg.Size) }))
+}
@@ -0,0 +1,268 @@
+// Package pool runs bulk work on a fixed number of goroutines with a
+// bounded queue, so a large job such as a view rebuild, a scrape ingest or a
+// notification fanout cannot start thousands of goroutines or exhaust the
+// database pool. Panics in tasks are recovered and reported as errors.
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// ErrClosed is returned by Submit after Close.
+var ErrClosed = errors.New("pool closed")
+
+// ErrQueueFull is returned by TrySubmit when the queue has no room.
+var ErrQueueFull = errors.New("pool queue full")
+
+// Task is one unit of work. Its context is cancelled when the submitter's
+// context is, or when Close gives up waiting for the queue to drain.
+type Task func(ctx context.Context) error
+
+// Config sizes a Pool.
+type Config struct {
+	// Name identifies the pool in logs.
+	Name string
+	// Size is the number of workers. Keep it below the database pool size
+	// when tasks query, or the pool just moves the queue into database/sql.
+	Size int
+	// QueueDepth is how many tasks may wait for a worker before Submit
+	// blocks.
+	QueueDepth int
+}
+
+// DefaultConfig runs one worker per CPU with room for 100 waiting tasks.
+func DefaultConfig(name string) Config {
+	return Config{Name: name, Size: runtime.GOMAXPROCS(0), QueueDepth: 100}
+}
+
+// Stats counts a pool's tasks.
+type Stats struct {
+	Submitted int64 `json:"submitted"`
+	Completed int64 `json:"completed"`
+	Failed    int64 `json:"failed"`
+	Panicked  int64 `json:"panicked"`
+	Rejected  int64 `json:"rejected"`
+	Queued    int   `json:"queued"`
+}
+
+// Pool is a fixed set of workers consuming a bounded queue. It is safe for
+// concurrent use.
+type Pool struct {
+	cfg    Config
+	logger *zap.Logger
+	queue  chan job
+	// stop cancels running tasks when Close's deadline passes.
+	stop    context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+
+	// mu guards closed; Submit holds it for reading while it enqueues so
+	// Close never closes the queue under a sender.
+	mu     sync.RWMutex
+	closed bool
+
+	submitted, completed, failed, panicked, rejected atomic.Int64
+}
+
+type job struct {
+	ctx  context.Context
+	task Task
+	done func(error)
+}
+
+// New starts a pool. Sizes below one are raised to one.
+func New(cfg Config, logger *zap.Logger) *Pool {
+	cfg.Size = max(cfg.Size, 1)
+	cfg.QueueDepth = max(cfg.QueueDepth, 0)
+	stop, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		cfg:    cfg,
+		logger: logger.With(zap.String("operation", "WorkerPool"), zap.String("pool", cfg.Name)),
+		queue:  make(chan job, cfg.QueueDepth),
+		stop:   stop,
+		cancel: cancel,
+	}
+	for range cfg.Size {
+		p.workers.Add(1)
+		go p.work()
+	}
+	return p
+}
+
+// Submit queues task, blocking while the queue is full. It returns ErrClosed
+// after Close, or ctx's error if ctx ends first.
+func (p *Pool) Submit(ctx context.Context, task Task) error {
+	return p.submit(ctx, job{ctx: ctx, task: task}, true)
+}
+
+// TrySubmit queues task without blocking, returning ErrQueueFull when there
+// is no room. Use it where shedding work beats stalling the caller.
+func (p *Pool) TrySubmit(ctx context.Context, task Task) error {
+	return p.submit(ctx, job{ctx: ctx, task: task}, false)
+}
+
+func (p *Pool) submit(ctx context.Context, j job, wait bool) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		p.rejected.Add(1)
+		return ErrClosed
+	}
+	if !wait {
+		select {
+		case p.queue <- j:
+			p.submitted.Add(1)
+			return nil
+		default:
+			p.rejected.Add(1)
+			return ErrQueueFull
+		}
+	}
+	select {
+	case p.queue <- j:
+		p.submitted.Add(1)
+		return nil
+	case <-ctx.Done():
+		p.rejected.Add(1)
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) work() {
+	defer p.workers.Done()
+	for j := range p.queue {
+		err := p.run(j)
+		if j.done != nil {
+			j.done(err)
+		}
+	}
+}
+
+// run executes one task, turning a panic into an error so one bad item
+// cannot take down the process.
+func (p *Pool) run(j job) (err error) {
+	ctx, cancel := context.WithCancel(j.ctx)
+	defer cancel()
+	unregister := context.AfterFunc(p.stop, cancel)
+	defer unregister()
+	defer func() {
+		if r := recover(); r != nil {
+			p.panicked.Add(1)
+			err = fmt.Errorf("task panicked: %v", r)
+			p.logger.Error("Task panicked",
+				zap.Any("panic", r),
+				zap.ByteString("stack", debug.Stack()),
+			)
+		}
+		if err != nil {
+			p.failed.Add(1)
+		} else {
+			p.completed.Add(1)
+		}
+	}()
+	if p.stop.Err() != nil {
+		// Close gave up on the drain while the task was queued.
+		return context.Canceled
+	}
+	if err := ctx.Err(); err != nil {
+		// The submitter gave up while the task was queued.
+		return err
+	}
+	return j.task(ctx)
+}
+
+// Close stops accepting tasks and waits for queued and running ones to
+// finish. If ctx ends first, running tasks are cancelled, tasks still queued
+// are skipped with the cancellation error, and ctx's error is returned once
+// the workers exit.
+func (p *Pool) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.queue)
+	p.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		p.workers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		<-drained
+		p.logger.Warn("Pool drain cut short", zap.Error(ctx.Err()))
+		return ctx.Err()
+	}
+}
+
+// Stats returns the pool's counters.
+func (p *Pool) Stats() Stats {
+	return Stats{
+		Submitted: p.submitted.Load(),
+		Completed: p.completed.Load(),
+		Failed:    p.failed.Load(),
+		Panicked:  p.panicked.Load(),
+		Rejected:  p.rejected.Load(),
+		Queued:    len(p.queue),
+	}
+}
+
+// Batch tracks a group of tasks submitted together so the caller can wait
+// for them, like a bounded errgroup sharing the pool's workers.
+type Batch struct {
+	pool *Pool
+	ctx  context.Context
+	wg   sync.WaitGroup
+
+	mu  sync.Mutex
+	err error
+}
+
+// Batch starts a group of tasks that run with ctx.
+func (p *Pool) Batch(ctx context.Context) *Batch {
+	return &Batch{pool: p, ctx: ctx}
+}
+
+// Go submits task, blocking while the queue is full. A task that cannot be
+// submitted counts as failed with the submit error.
+func (b *Batch) Go(task Task) {
+	b.wg.Add(1)
+	j := job{ctx: b.ctx, task: task, done: b.finish}
+	if err := b.pool.submit(b.ctx, j, true); err != nil {
+		b.finish(err)
+	}
+}
+
+func (b *Batch) finish(err error) {
+	if err != nil {
+		b.mu.Lock()
+		if b.err == nil {
+			b.err = err
+		}
+		b.mu.Unlock()
+	}
+	b.wg.Done()
+}
+
+// Wait blocks until every task has finished and returns the first error.
+func (b *Batch) Wait() error {
+	b.wg.Wait()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.err
+}
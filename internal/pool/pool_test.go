@@ -0,0 +1,213 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/metrics"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestPool_BoundsConcurrencyAndDrains(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPool_BoundsConcurrencyAndDrains", "internal/pool")
+
+	testhelpers.LogTestStep(logger, "arrange", "A three-worker pool")
+	p := New(Config{Name: "test", Size: 3, QueueDepth: 2}, zap.NewNop())
+	var running, peak, done atomic.Int64
+
+	testhelpers.LogTestStep(logger, "act", "Submitting 20 slow tasks, then closing")
+	for range 20 {
+		err := p.Submit(t.Context(), func(ctx context.Context) error {
+			n := running.Add(1)
+			for {
+				old := peak.Load()
+				if n <= old || peak.CompareAndSwap(old, n) {
+					break
+				}
+			}
+			time.Sleep(2 * time.Millisecond)
+			running.Add(-1)
+			done.Add(1)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Submit failed: %v", err)
+		}
+	}
+	if err := p.Close(t.Context()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Every task ran, never more than three at once")
+	testhelpers.LogTestAssertion(logger, "completed", 20, done.Load())
+	if done.Load() != 20 {
+		t.Errorf("Completed %d tasks, want all 20 drained", done.Load())
+	}
+	if peak.Load() > 3 {
+		t.Errorf("Peak concurrency %d, want at most 3", peak.Load())
+	}
+	if err := p.Submit(t.Context(), func(context.Context) error { return nil }); !errors.Is(err, ErrClosed) {
+		t.Errorf("Submit after Close = %v, want ErrClosed", err)
+	}
+	if got := p.Stats(); got.Submitted != 20 || got.Completed != 20 || got.Rejected != 1 {
+		t.Errorf("Stats = %+v", got)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPool_BoundsConcurrencyAndDrains", true)
+}
+
+func TestPool_TrySubmitSheds(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPool_TrySubmitSheds", "internal/pool")
+
+	testhelpers.LogTestStep(logger, "arrange", "One busy worker and a one-slot queue")
+	p := New(Config{Name: "test", Size: 1, QueueDepth: 1}, zap.NewNop())
+	release := make(chan struct{})
+	started := make(chan struct{})
+	block := func(context.Context) error {
+		close(started)
+		<-release
+		return nil
+	}
+	if err := p.Submit(t.Context(), block); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	testhelpers.LogTestStep(logger, "act", "Filling the queue, then offering one more")
+	noop := func(context.Context) error { return nil }
+	first := p.TrySubmit(t.Context(), noop)
+	second := p.TrySubmit(t.Context(), noop)
+
+	testhelpers.LogTestAssertion(logger, "second", ErrQueueFull, second)
+	if first != nil || !errors.Is(second, ErrQueueFull) {
+		t.Errorf("TrySubmit = %v, %v, want nil then ErrQueueFull", first, second)
+	}
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.Submit(ctx, noop); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Blocked Submit = %v, want the context deadline", err)
+	}
+	close(release)
+	_ = p.Close(t.Context())
+
+	testhelpers.LogTestComplete(logger, "TestPool_TrySubmitSheds", true)
+}
+
+func TestPool_RecoversPanics(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPool_RecoversPanics", "internal/pool")
+
+	p := New(Config{Name: "test", Size: 2}, zap.NewNop())
+	defer p.Close(t.Context())
+
+	testhelpers.LogTestStep(logger, "act", "Running a batch where one task panics")
+	b := p.Batch(t.Context())
+	var ran atomic.Int64
+	b.Go(func(context.Context) error { panic("bad listing") })
+	b.Go(func(context.Context) error { ran.Add(1); return nil })
+	b.Go(func(context.Context) error { ran.Add(1); return nil })
+	err := b.Wait()
+
+	testhelpers.LogTestStep(logger, "assert", "The panic becomes the batch error and the workers survive")
+	testhelpers.LogTestAssertion(logger, "error", "task panicked: bad listing", err)
+	if err == nil || !strings.Contains(err.Error(), "bad listing") {
+		t.Errorf("Wait = %v, want the recovered panic", err)
+	}
+	if ran.Load() != 2 {
+		t.Errorf("Ran %d other tasks, want 2", ran.Load())
+	}
+	b = p.Batch(t.Context())
+	b.Go(func(context.Context) error { return nil })
+	if err := b.Wait(); err != nil {
+		t.Errorf("Batch after panic = %v, want the pool still working", err)
+	}
+	if got := p.Stats(); got.Panicked != 1 || got.Failed != 1 {
+		t.Errorf("Stats = %+v, want one panicked failure", got)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPool_RecoversPanics", true)
+}
+
+func TestPool_CloseDeadlineCancelsTasks(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPool_CloseDeadlineCancelsTasks", "internal/pool")
+
+	testhelpers.LogTestStep(logger, "arrange", "A task that runs until cancelled, and one queued behind it")
+	p := New(Config{Name: "test", Size: 1, QueueDepth: 1}, zap.NewNop())
+	started := make(chan struct{})
+	b := p.Batch(t.Context())
+	b.Go(func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	var queuedRan atomic.Bool
+	b.Go(func(context.Context) error { queuedRan.Store(true); return nil })
+	<-started
+
+	testhelpers.LogTestStep(logger, "act", "Closing with a short deadline")
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	err := p.Close(ctx)
+
+	testhelpers.LogTestStep(logger, "assert", "The running task is cancelled and the queued one skipped")
+	testhelpers.LogTestAssertion(logger, "close error", context.DeadlineExceeded, err)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close = %v, want the deadline", err)
+	}
+	if err := b.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Batch = %v, want cancellation", err)
+	}
+	if queuedRan.Load() {
+		t.Error("Queued task ran after the drain deadline")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPool_CloseDeadlineCancelsTasks", true)
+}
+
+func TestExportMetrics(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestExportMetrics", "internal/pool")
+
+	testhelpers.LogTestStep(logger, "arrange", "A pool that ran one good, one failing and one panicking task")
+	p := New(Config{Name: "bulk", Size: 1, QueueDepth: 5}, zap.NewNop())
+	reg := metrics.NewRegistry()
+	ExportMetrics(reg, p)
+	b := p.Batch(t.Context())
+	b.Go(func(context.Context) error { return nil })
+	b.Go(func(context.Context) error { return errors.New("listing gone") })
+	b.Go(func(context.Context) error { panic("nil listing") })
+	_ = b.Wait()
+
+	testhelpers.LogTestStep(logger, "act", "Scraping the registry")
+	var out strings.Builder
+	if err := reg.WriteText(&out); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Tasks are counted by result, with the pool's shape")
+	for _, line := range []string{
+		`pool_tasks_submitted_total{pool="bulk"} 3`,
+		`pool_tasks_total{pool="bulk",result="completed"} 1`,
+		`pool_tasks_total{pool="bulk",result="failed"} 2`,
+		`pool_task_panics_total{pool="bulk"} 1`,
+		`pool_queued_tasks{pool="bulk"} 0`,
+		`pool_queue_capacity{pool="bulk"} 5`,
+		`pool_workers{pool="bulk"} 1`,
+	} {
+		testhelpers.LogTestAssertion(logger, "series", line, strings.Contains(out.String(), line))
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("Metrics lack %q:\n%s", line, out.String())
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestExportMetrics", true)
+}
@@ -0,0 +1,366 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Alerts returns the Store as an AlertRepository.
+func (s *Store) Alerts() repositories.AlertRepository { return alertRepo{s} }
+
+// Notifications returns the Store as a NotificationQueue.
+func (s *Store) Notifications() repositories.NotificationQueue { return notificationQueue{s} }
+
+// Deliveries returns the Store as a DeliveryRepository.
+func (s *Store) Deliveries() repositories.DeliveryRepository { return deliveryRepo{s} }
+
+// Preferences returns the Store as a PreferenceRepository.
+func (s *Store) Preferences() repositories.PreferenceRepository { return preferenceRepo{s} }
+
+// Suppressions returns the Store as a SuppressionRepository.
+func (s *Store) Suppressions() repositories.SuppressionRepository { return suppressionRepo{s} }
+
+type alertRepo struct{ s *Store }
+
+func (r alertRepo) CreateAlert(_ context.Context, a domain.PriceAlert) (domain.PriceAlert, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, existing := range r.s.alerts {
+		if existing.DeletedAt == nil && existing.UserID == a.UserID && existing.ProductID == a.ProductID {
+			return domain.PriceAlert{}, fmt.Errorf("an alert for product %q already exists: %w", a.ProductID, domain.ErrConflict)
+		}
+	}
+	r.s.nextID++
+	a.ID = fmt.Sprintf("alert_%d", r.s.nextID)
+	r.s.alerts[a.ID] = a
+	return a, nil
+}
+
+func (r alertRepo) Alert(_ context.Context, id string) (*domain.PriceAlert, error) {
+	a, err := find(r.s, r.s.alerts, id, "alert")
+	if err == nil && a.DeletedAt != nil {
+		return nil, fmt.Errorf("alert %q: %w", id, domain.ErrNotFound)
+	}
+	return a, err
+}
+
+func (r alertRepo) UserAlerts(_ context.Context, userID string) ([]domain.PriceAlert, error) {
+	out := r.filter(func(a domain.PriceAlert) bool { return a.UserID == userID })
+	slices.SortFunc(out, func(a, b domain.PriceAlert) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return compareIDs(b.ID, a.ID)
+	})
+	return out, nil
+}
+
+func (r alertRepo) ProductAlerts(_ context.Context, productID string) ([]domain.PriceAlert, error) {
+	out := r.filter(func(a domain.PriceAlert) bool { return a.ProductID == productID })
+	slices.SortFunc(out, func(a, b domain.PriceAlert) int { return compareIDs(a.ID, b.ID) })
+	return out, nil
+}
+
+func (r alertRepo) filter(keep func(domain.PriceAlert) bool) []domain.PriceAlert {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var out []domain.PriceAlert
+	for _, a := range r.s.alerts {
+		if a.DeletedAt == nil && keep(a) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+func (r alertRepo) SaveAlert(_ context.Context, a domain.PriceAlert) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	current, ok := r.s.alerts[a.ID]
+	if !ok || current.DeletedAt != nil {
+		return fmt.Errorf("alert %q: %w", a.ID, domain.ErrNotFound)
+	}
+	a.DeletedAt = nil
+	r.s.alerts[a.ID] = a
+	return nil
+}
+
+func (r alertRepo) DeleteAlert(_ context.Context, id string, at time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	a, ok := r.s.alerts[id]
+	if !ok || a.DeletedAt != nil {
+		return fmt.Errorf("alert %q: %w", id, domain.ErrNotFound)
+	}
+	a.DeletedAt = &at
+	r.s.alerts[id] = a
+	return nil
+}
+
+func (r alertRepo) DeletedAlerts(_ context.Context, userID string) ([]domain.PriceAlert, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var out []domain.PriceAlert
+	for _, a := range r.s.alerts {
+		if a.UserID == userID && a.DeletedAt != nil {
+			out = append(out, a)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.PriceAlert) int {
+		if c := b.DeletedAt.Compare(*a.DeletedAt); c != 0 {
+			return c
+		}
+		return compareIDs(b.ID, a.ID)
+	})
+	return out, nil
+}
+
+func (r alertRepo) RestoreAlert(_ context.Context, id string) (domain.PriceAlert, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	a, ok := r.s.alerts[id]
+	if !ok || a.DeletedAt == nil {
+		return domain.PriceAlert{}, fmt.Errorf("deleted alert %q: %w", id, domain.ErrNotFound)
+	}
+	for _, other := range r.s.alerts {
+		if other.DeletedAt == nil && other.UserID == a.UserID && other.ProductID == a.ProductID {
+			return domain.PriceAlert{}, fmt.Errorf("the user has another alert for product %q: %w", a.ProductID, domain.ErrConflict)
+		}
+	}
+	a.DeletedAt = nil
+	r.s.alerts[id] = a
+	return a, nil
+}
+
+// compareIDs orders generated IDs by their numeric suffix, i.e. creation
+// order.
+func compareIDs(a, b string) int {
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+type notificationQueue struct{ s *Store }
+
+func (q notificationQueue) Enqueue(_ context.Context, notifications ...domain.Notification) error {
+	q.s.mu.Lock()
+	defer q.s.mu.Unlock()
+
+	for _, n := range notifications {
+		if n.DedupeKey != "" && slices.ContainsFunc(q.s.notifications, func(m domain.Notification) bool {
+			return m.DedupeKey == n.DedupeKey
+		}) {
+			continue
+		}
+		q.s.nextID++
+		n.ID = fmt.Sprintf("notif_%d", q.s.nextID)
+		q.s.notifications = append(q.s.notifications, n)
+	}
+	return nil
+}
+
+func (q notificationQueue) Pending(_ context.Context, limit int) ([]domain.Notification, error) {
+	q.s.mu.RLock()
+	defer q.s.mu.RUnlock()
+
+	var out []domain.Notification
+	for _, n := range q.s.notifications {
+		if limit > 0 && len(out) == limit {
+			break
+		}
+		if n.SentAt == nil && n.HeldUntil == nil && n.DeferredUntil == nil {
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
+
+func (q notificationQueue) MarkSent(_ context.Context, at time.Time, ids ...string) error {
+	q.s.mu.Lock()
+	defer q.s.mu.Unlock()
+
+	for i := range q.s.notifications {
+		if slices.Contains(ids, q.s.notifications[i].ID) {
+			q.s.notifications[i].SentAt = &at
+		}
+	}
+	return nil
+}
+
+func (q notificationQueue) Hold(_ context.Context, until time.Time, ids ...string) error {
+	q.s.mu.Lock()
+	defer q.s.mu.Unlock()
+
+	for i := range q.s.notifications {
+		if slices.Contains(ids, q.s.notifications[i].ID) {
+			q.s.notifications[i].HeldUntil = &until
+		}
+	}
+	return nil
+}
+
+func (q notificationQueue) Due(_ context.Context, now time.Time) ([]domain.Notification, error) {
+	q.s.mu.RLock()
+	defer q.s.mu.RUnlock()
+
+	var out []domain.Notification
+	for _, n := range q.s.notifications {
+		if n.SentAt == nil && n.HeldUntil != nil && !n.HeldUntil.After(now) {
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
+
+func (q notificationQueue) Defer(_ context.Context, until time.Time, ids ...string) error {
+	q.s.mu.Lock()
+	defer q.s.mu.Unlock()
+
+	for i := range q.s.notifications {
+		if slices.Contains(ids, q.s.notifications[i].ID) {
+			q.s.notifications[i].DeferredUntil = &until
+		}
+	}
+	return nil
+}
+
+func (q notificationQueue) Release(_ context.Context, now time.Time) (int, error) {
+	q.s.mu.Lock()
+	defer q.s.mu.Unlock()
+
+	released := 0
+	for i, n := range q.s.notifications {
+		if n.SentAt == nil && n.DeferredUntil != nil && !n.DeferredUntil.After(now) {
+			q.s.notifications[i].DeferredUntil = nil
+			released++
+		}
+	}
+	return released, nil
+}
+
+type deliveryRepo struct{ s *Store }
+
+func (r deliveryRepo) SaveFailedDelivery(_ context.Context, d domain.FailedDelivery) (domain.FailedDelivery, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if d.ID == "" {
+		r.s.nextID++
+		d.ID = fmt.Sprintf("delivery_%d", r.s.nextID)
+	}
+	r.s.deliveries[d.ID] = d
+	return d, nil
+}
+
+func (r deliveryRepo) FailedDelivery(_ context.Context, id string) (*domain.FailedDelivery, error) {
+	return find(r.s, r.s.deliveries, id, "failed delivery")
+}
+
+func (r deliveryRepo) DueDeliveries(_ context.Context, now time.Time, limit int) ([]domain.FailedDelivery, error) {
+	out := r.filter(func(d domain.FailedDelivery) bool {
+		return d.Status == domain.DeliveryRetrying && !d.NextAttemptAt.After(now)
+	})
+	slices.SortFunc(out, func(a, b domain.FailedDelivery) int {
+		if c := a.NextAttemptAt.Compare(b.NextAttemptAt); c != 0 {
+			return c
+		}
+		return compareIDs(a.ID, b.ID)
+	})
+	return truncate(out, limit), nil
+}
+
+func (r deliveryRepo) FailedDeliveries(_ context.Context, status string, limit int) ([]domain.FailedDelivery, error) {
+	out := r.filter(func(d domain.FailedDelivery) bool { return status == "" || d.Status == status })
+	slices.SortFunc(out, func(a, b domain.FailedDelivery) int {
+		if c := b.UpdatedAt.Compare(a.UpdatedAt); c != 0 {
+			return c
+		}
+		return compareIDs(b.ID, a.ID)
+	})
+	return truncate(out, limit), nil
+}
+
+func (r deliveryRepo) filter(keep func(domain.FailedDelivery) bool) []domain.FailedDelivery {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var out []domain.FailedDelivery
+	for _, d := range r.s.deliveries {
+		if keep(d) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func (r deliveryRepo) DeleteFailedDelivery(_ context.Context, id string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.deliveries[id]; !ok {
+		return fmt.Errorf("failed delivery %q: %w", id, domain.ErrNotFound)
+	}
+	delete(r.s.deliveries, id)
+	return nil
+}
+
+func truncate[T any](items []T, limit int) []T {
+	if limit > 0 && len(items) > limit {
+		return items[:limit]
+	}
+	return items
+}
+
+type suppressionRepo struct{ s *Store }
+
+func (r suppressionRepo) Suppress(_ context.Context, sup domain.Suppression) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.suppressions[sup.Email] = sup
+	return nil
+}
+
+func (r suppressionRepo) Suppression(_ context.Context, email string) (*domain.Suppression, error) {
+	return find(r.s, r.s.suppressions, email, "suppression")
+}
+
+type preferenceRepo struct{ s *Store }
+
+func (r preferenceRepo) NotificationPreferences(_ context.Context, userID string) (*domain.NotificationPreferences, error) {
+	p, err := find(r.s, r.s.preferences, userID, "notification preferences")
+	if err != nil {
+		return nil, err
+	}
+	// find copies the struct but not its maps.
+	p.Channels, p.Topics = maps.Clone(p.Channels), maps.Clone(p.Topics)
+	return p, nil
+}
+
+func (r preferenceRepo) SaveNotificationPreferences(_ context.Context, p domain.NotificationPreferences) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	p.Channels, p.Topics = maps.Clone(p.Channels), maps.Clone(p.Topics)
+	r.s.preferences[p.UserID] = p
+	return nil
+}
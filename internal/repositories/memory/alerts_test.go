@@ -0,0 +1,290 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestStore_Alerts(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_Alerts", "internal/repositories/memory")
+
+	store := NewStore()
+	ctx := t.Context()
+	alerts := store.Alerts()
+	now := time.Now()
+
+	testhelpers.LogTestStep(logger, "act", "Creating alerts for two users")
+	var created []domain.PriceAlert
+	for i, a := range []domain.PriceAlert{
+		{UserID: "user_1", ProductID: "prod_a", TargetPrice: 1000},
+		{UserID: "user_1", ProductID: "prod_b", TargetPrice: 2000},
+		{UserID: "user_2", ProductID: "prod_a", TargetPricePerGram: 1.5},
+	} {
+		a.CreatedAt = now.Add(time.Duration(i) * time.Minute)
+		got, err := alerts.CreateAlert(ctx, a)
+		if err != nil || got.ID == "" {
+			t.Fatalf("CreateAlert = %+v, %v", got, err)
+		}
+		created = append(created, got)
+	}
+	if _, err := alerts.CreateAlert(ctx, domain.PriceAlert{UserID: "user_1", ProductID: "prod_a"}); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Duplicate alert error = %v, want ErrConflict", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Listing by user and by product")
+	mine, _ := alerts.UserAlerts(ctx, "user_1")
+	testhelpers.LogTestAssertion(logger, "user alerts", 2, len(mine))
+	if len(mine) != 2 || mine[0].ID != created[1].ID {
+		t.Errorf("UserAlerts = %+v, want newest first", mine)
+	}
+	onA, _ := alerts.ProductAlerts(ctx, "prod_a")
+	if len(onA) != 2 || onA[0].ID != created[0].ID || onA[1].ID != created[2].ID {
+		t.Errorf("ProductAlerts = %+v, want both prod_a alerts in creation order", onA)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Saving and deleting")
+	fired := created[0]
+	fired.TriggeredAt = &now
+	if err := alerts.SaveAlert(ctx, fired); err != nil {
+		t.Fatalf("SaveAlert: %v", err)
+	}
+	if got, _ := alerts.Alert(ctx, fired.ID); got.TriggeredAt == nil {
+		t.Error("SaveAlert did not persist")
+	}
+	if err := alerts.DeleteAlert(ctx, fired.ID, now); err != nil {
+		t.Fatalf("DeleteAlert: %v", err)
+	}
+	if _, err := alerts.Alert(ctx, fired.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Deleted alert error = %v, want ErrNotFound", err)
+	}
+	if err := alerts.SaveAlert(ctx, fired); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Saving a deleted alert error = %v, want ErrNotFound", err)
+	}
+	if onA, _ := alerts.ProductAlerts(ctx, "prod_a"); len(onA) != 1 {
+		t.Errorf("ProductAlerts after delete = %+v, want only user_2's", onA)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Restoring the deleted alert")
+	deleted, _ := alerts.DeletedAlerts(ctx, "user_1")
+	testhelpers.LogTestAssertion(logger, "deleted alerts", 1, len(deleted))
+	if len(deleted) != 1 || deleted[0].ID != fired.ID || deleted[0].DeletedAt == nil {
+		t.Fatalf("DeletedAlerts = %+v, want the deleted alert", deleted)
+	}
+	replacement, err := alerts.CreateAlert(ctx, domain.PriceAlert{UserID: "user_1", ProductID: "prod_a", TargetPrice: 900})
+	if err != nil {
+		t.Fatalf("CreateAlert over a deleted alert: %v", err)
+	}
+	if _, err := alerts.RestoreAlert(ctx, fired.ID); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Restoring over a newer alert error = %v, want ErrConflict", err)
+	}
+	if err := alerts.DeleteAlert(ctx, replacement.ID, now); err != nil {
+		t.Fatalf("DeleteAlert: %v", err)
+	}
+	restored, err := alerts.RestoreAlert(ctx, fired.ID)
+	if err != nil || restored.DeletedAt != nil || restored.TriggeredAt == nil {
+		t.Errorf("RestoreAlert = %+v, %v; want the alert as it was", restored, err)
+	}
+	if _, err := alerts.RestoreAlert(ctx, fired.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Restoring a live alert error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_Alerts", true)
+}
+
+func TestStore_Notifications(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_Notifications", "internal/repositories/memory")
+
+	store := NewStore()
+	ctx := t.Context()
+	queue := store.Notifications()
+
+	testhelpers.LogTestStep(logger, "act", "Queueing three notifications and sending the first")
+	if err := queue.Enqueue(ctx,
+		domain.Notification{UserID: "user_1", ProductID: "prod_a"},
+		domain.Notification{UserID: "user_2", ProductID: "prod_a"},
+		domain.Notification{UserID: "user_1", ProductID: "prod_b"},
+	); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	first, _ := queue.Pending(ctx, 1)
+	if len(first) != 1 || first[0].ID == "" || first[0].UserID != "user_1" || first[0].ProductID != "prod_a" {
+		t.Fatalf("Pending(1) = %+v, want the oldest", first)
+	}
+	if err := queue.MarkSent(ctx, time.Now(), first[0].ID); err != nil {
+		t.Fatalf("MarkSent: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Only unsent notifications are pending")
+	rest, _ := queue.Pending(ctx, 0)
+	testhelpers.LogTestAssertion(logger, "pending", 2, len(rest))
+	if len(rest) != 2 || rest[0].UserID != "user_2" {
+		t.Errorf("Pending = %+v, want the two unsent in order", rest)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Holding the last for a digest")
+	now := time.Now()
+	if err := queue.Hold(ctx, now.Add(time.Hour), rest[1].ID); err != nil {
+		t.Fatalf("Hold: %v", err)
+	}
+	if pending, _ := queue.Pending(ctx, 0); len(pending) != 1 || pending[0].ID != rest[0].ID {
+		t.Errorf("Pending after Hold = %+v, want only %s", pending, rest[0].ID)
+	}
+	if due, _ := queue.Due(ctx, now); len(due) != 0 {
+		t.Errorf("Due before the hold ends = %+v", due)
+	}
+	due, _ := queue.Due(ctx, now.Add(time.Hour))
+	testhelpers.LogTestAssertion(logger, "due", 1, len(due))
+	if len(due) != 1 || due[0].ID != rest[1].ID {
+		t.Errorf("Due = %+v, want %s", due, rest[1].ID)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Deferring the pending one for quiet hours")
+	if err := queue.Defer(ctx, now.Add(time.Hour), rest[0].ID); err != nil {
+		t.Fatalf("Defer: %v", err)
+	}
+	if pending, _ := queue.Pending(ctx, 0); len(pending) != 0 {
+		t.Errorf("Pending after Defer = %+v, want none", pending)
+	}
+	if released, _ := queue.Release(ctx, now); released != 0 {
+		t.Errorf("Release before quiet hours end = %d, want 0", released)
+	}
+	released, _ := queue.Release(ctx, now.Add(time.Hour))
+	testhelpers.LogTestAssertion(logger, "released", 1, released)
+	if pending, _ := queue.Pending(ctx, 0); released != 1 || len(pending) != 1 || pending[0].ID != rest[0].ID || pending[0].DeferredUntil != nil {
+		t.Errorf("Release = %d, Pending = %+v, want %s pending again", released, pending, rest[0].ID)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_Notifications", true)
+}
+
+func TestStore_Preferences(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_Preferences", "internal/repositories/memory")
+
+	store := NewStore()
+	ctx := t.Context()
+	prefs := store.Preferences()
+
+	if _, err := prefs.NotificationPreferences(ctx, "user_1"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Unsaved preferences error = %v, want ErrNotFound", err)
+	}
+	for _, f := range []string{domain.FrequencyDaily, domain.FrequencyWeekly} {
+		if err := prefs.SaveNotificationPreferences(ctx, domain.NotificationPreferences{UserID: "user_1", Frequency: f}); err != nil {
+			t.Fatalf("SaveNotificationPreferences: %v", err)
+		}
+	}
+	got, err := prefs.NotificationPreferences(ctx, "user_1")
+	testhelpers.LogTestAssertion(logger, "frequency", domain.FrequencyWeekly, got)
+	if err != nil || got.Frequency != domain.FrequencyWeekly {
+		t.Errorf("NotificationPreferences = %+v, %v, want weekly", got, err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Changing a loaded copy's channels")
+	channels := map[string]bool{domain.ChannelEmail: false}
+	if err := prefs.SaveNotificationPreferences(ctx, domain.NotificationPreferences{UserID: "user_2", Frequency: domain.FrequencyDaily, Channels: channels}); err != nil {
+		t.Fatalf("SaveNotificationPreferences: %v", err)
+	}
+	channels[domain.ChannelEmail] = true
+	loaded, _ := prefs.NotificationPreferences(ctx, "user_2")
+	loaded.Channels[domain.ChannelTelegram] = false
+	if again, _ := prefs.NotificationPreferences(ctx, "user_2"); len(again.Channels) != 1 || again.Channels[domain.ChannelEmail] {
+		t.Errorf("Stored channels = %v, want only email off", again.Channels)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_Preferences", true)
+}
+
+func TestStore_Suppressions(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_Suppressions", "internal/repositories/memory")
+
+	store := NewStore()
+	ctx := t.Context()
+	suppressions := store.Suppressions()
+
+	if _, err := suppressions.Suppression(ctx, "asha@example.com"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Unknown address error = %v, want ErrNotFound", err)
+	}
+	for _, reason := range []string{domain.SuppressionBounce, domain.SuppressionComplaint} {
+		if err := suppressions.Suppress(ctx, domain.Suppression{Email: "asha@example.com", Reason: reason}); err != nil {
+			t.Fatalf("Suppress: %v", err)
+		}
+	}
+	got, err := suppressions.Suppression(ctx, "asha@example.com")
+	if err != nil {
+		t.Fatalf("Suppression: %v", err)
+	}
+	testhelpers.LogTestAssertion(logger, "reason", domain.SuppressionComplaint, got.Reason)
+	if got.Reason != domain.SuppressionComplaint {
+		t.Errorf("Suppression = %+v, want the latest reason", got)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_Suppressions", true)
+}
+
+func TestStore_Deliveries(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_Deliveries", "internal/repositories/memory")
+
+	store := NewStore()
+	ctx := t.Context()
+	deliveries := store.Deliveries()
+	now := time.Now()
+
+	testhelpers.LogTestStep(logger, "arrange", "Two retrying deliveries, one due, and a dead letter")
+	var ids []string
+	for _, f := range []domain.FailedDelivery{
+		{Status: domain.DeliveryRetrying, NextAttemptAt: now.Add(-time.Minute), UpdatedAt: now.Add(-2 * time.Minute)},
+		{Status: domain.DeliveryRetrying, NextAttemptAt: now.Add(time.Minute), UpdatedAt: now.Add(-time.Minute)},
+		{Status: domain.DeliveryDead, UpdatedAt: now},
+	} {
+		saved, err := deliveries.SaveFailedDelivery(ctx, f)
+		if err != nil || saved.ID == "" {
+			t.Fatalf("SaveFailedDelivery = %+v, %v", saved, err)
+		}
+		ids = append(ids, saved.ID)
+	}
+
+	due, _ := deliveries.DueDeliveries(ctx, now, 0)
+	testhelpers.LogTestAssertion(logger, "due", 1, len(due))
+	if len(due) != 1 || due[0].ID != ids[0] {
+		t.Errorf("Due = %+v, want only %s", due, ids[0])
+	}
+	all, _ := deliveries.FailedDeliveries(ctx, "", 0)
+	if len(all) != 3 || all[0].ID != ids[2] || all[2].ID != ids[0] {
+		t.Errorf("All = %+v, want most recently updated first", all)
+	}
+	if dead, _ := deliveries.FailedDeliveries(ctx, domain.DeliveryDead, 0); len(dead) != 1 || dead[0].ID != ids[2] {
+		t.Errorf("Dead = %+v", dead)
+	}
+	if limited, _ := deliveries.FailedDeliveries(ctx, "", 2); len(limited) != 2 {
+		t.Errorf("Limited to %d, want 2", len(limited))
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Saving with an ID replaces; deleting removes")
+	got, _ := deliveries.FailedDelivery(ctx, ids[1])
+	got.Status = domain.DeliveryDead
+	if saved, err := deliveries.SaveFailedDelivery(ctx, *got); err != nil || saved.ID != ids[1] {
+		t.Fatalf("SaveFailedDelivery replace = %+v, %v", saved, err)
+	}
+	if dead, _ := deliveries.FailedDeliveries(ctx, domain.DeliveryDead, 0); len(dead) != 2 {
+		t.Errorf("Dead after replace = %+v", dead)
+	}
+	if err := deliveries.DeleteFailedDelivery(ctx, ids[0]); err != nil {
+		t.Fatalf("DeleteFailedDelivery: %v", err)
+	}
+	if _, err := deliveries.FailedDelivery(ctx, ids[0]); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Deleted delivery error = %v, want ErrNotFound", err)
+	}
+	if err := deliveries.DeleteFailedDelivery(ctx, ids[0]); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Second delete error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_Deliveries", true)
+}
@@ -0,0 +1,33 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Discord returns the Store as a DiscordRepository.
+func (s *Store) Discord() repositories.DiscordRepository { return discordRepo{s} }
+
+type discordRepo struct{ s *Store }
+
+func (r discordRepo) SaveDiscordWebhook(_ context.Context, w domain.DiscordWebhook) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.discordWebhooks[w.UserID] = w
+	return nil
+}
+
+func (r discordRepo) DiscordWebhook(_ context.Context, userID string) (*domain.DiscordWebhook, error) {
+	return find(r.s, r.s.discordWebhooks, userID, "discord webhook")
+}
+
+func (r discordRepo) DeleteDiscordWebhook(_ context.Context, userID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.discordWebhooks, userID)
+	return nil
+}
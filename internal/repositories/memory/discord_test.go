@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestStore_Discord(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_Discord", "internal/repositories/memory")
+
+	store := NewStore()
+	ctx := t.Context()
+	hooks := store.Discord()
+
+	testhelpers.LogTestStep(logger, "act", "Saving a webhook, then replacing it")
+	for _, id := range []string{"123", "456"} {
+		w := domain.DiscordWebhook{UserID: "user_1", WebhookID: id, URL: "https://discord.com/api/webhooks/" + id + "/test-only-token", CreatedAt: time.Now()}
+		if err := hooks.SaveDiscordWebhook(ctx, w); err != nil {
+			t.Fatalf("SaveDiscordWebhook: %v", err)
+		}
+	}
+	w, err := hooks.DiscordWebhook(ctx, "user_1")
+	testhelpers.LogTestAssertion(logger, "webhook", "456", w.WebhookID)
+	if err != nil || w.WebhookID != "456" {
+		t.Fatalf("DiscordWebhook = %+v, %v; want the replacement", w, err)
+	}
+	if _, err := hooks.DiscordWebhook(ctx, "user_2"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Other user's DiscordWebhook error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Deleting it")
+	if err := hooks.DeleteDiscordWebhook(ctx, "user_1"); err != nil {
+		t.Fatalf("DeleteDiscordWebhook: %v", err)
+	}
+	if _, err := hooks.DiscordWebhook(ctx, "user_1"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("DiscordWebhook after delete error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_Discord", true)
+}
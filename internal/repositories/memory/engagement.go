@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Engagement returns the Store as an EngagementRepository.
+func (s *Store) Engagement() repositories.EngagementRepository { return engagementRepo{s} }
+
+type engagementRepo struct{ s *Store }
+
+func (r engagementRepo) RecordEngagement(_ context.Context, e domain.EngagementEvent) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	key := e.NotificationID + "\x00" + e.Channel + "\x00" + e.Type
+	if r.s.engagementSet[key] {
+		return false, nil
+	}
+	r.s.engagementSet[key] = true
+	r.s.engagement = append(r.s.engagement, e)
+	return true, nil
+}
+
+func (r engagementRepo) EngagementSince(_ context.Context, since time.Time) ([]domain.EngagementEvent, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var out []domain.EngagementEvent
+	for _, e := range r.s.engagement {
+		if !e.CreatedAt.Before(since) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestStore_Engagement(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_Engagement", "internal/repositories/memory")
+
+	store := NewStore()
+	ctx := t.Context()
+	repo := store.Engagement()
+	now := time.Now()
+
+	testhelpers.LogTestStep(logger, "act", "Recording a delivery, two opens of it, and an older delivery")
+	events := []struct {
+		event   domain.EngagementEvent
+		wantNew bool
+	}{
+		{domain.EngagementEvent{NotificationID: "notif_1", Channel: domain.ChannelEmail, Type: domain.EngagementDelivered, CreatedAt: now}, true},
+		{domain.EngagementEvent{NotificationID: "notif_1", Channel: domain.ChannelEmail, Type: domain.EngagementOpened, CreatedAt: now}, true},
+		{domain.EngagementEvent{NotificationID: "notif_1", Channel: domain.ChannelEmail, Type: domain.EngagementOpened, CreatedAt: now.Add(time.Minute)}, false},
+		{domain.EngagementEvent{NotificationID: "notif_1", Channel: domain.ChannelTelegram, Type: domain.EngagementDelivered, CreatedAt: now}, true},
+		{domain.EngagementEvent{NotificationID: "notif_0", Channel: domain.ChannelEmail, Type: domain.EngagementDelivered, CreatedAt: now.AddDate(0, 0, -40)}, true},
+	}
+	for _, e := range events {
+		isNew, err := repo.RecordEngagement(ctx, e.event)
+		if err != nil || isNew != e.wantNew {
+			t.Errorf("RecordEngagement(%+v) = %v, %v; want %v", e.event, isNew, err, e.wantNew)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Only events since the cut-off are returned, each once")
+	got, err := repo.EngagementSince(ctx, now.AddDate(0, 0, -30))
+	testhelpers.LogTestAssertion(logger, "events", 3, len(got))
+	if err != nil || len(got) != 3 {
+		t.Errorf("EngagementSince = %+v, %v; want 3 events", got, err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_Engagement", true)
+}
@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Flags returns the Store as a FlagRepository.
+func (s *Store) Flags() repositories.FlagRepository { return flagRepo{s} }
+
+type flagRepo struct{ s *Store }
+
+func (r flagRepo) Flag(_ context.Context, name string) (*domain.Flag, error) {
+	return find(r.s, r.s.flags, name, "flag")
+}
+
+func (r flagRepo) Flags(_ context.Context) ([]domain.Flag, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := make([]domain.Flag, 0, len(r.s.flags))
+	for _, f := range r.s.flags {
+		out = append(out, f)
+	}
+	slices.SortFunc(out, func(a, b domain.Flag) int { return cmp.Compare(a.Name, b.Name) })
+	return out, nil
+}
+
+func (r flagRepo) SaveFlag(_ context.Context, f domain.Flag, events ...domain.Event) error {
+	return r.s.write(events, func() error {
+		r.s.flags[f.Name] = f
+		return nil
+	})
+}
+
+func (r flagRepo) DeleteFlag(_ context.Context, name string, events ...domain.Event) error {
+	return r.s.write(events, func() error {
+		if _, ok := r.s.flags[name]; !ok {
+			return fmt.Errorf("flag %q: %w", name, domain.ErrNotFound)
+		}
+		delete(r.s.flags, name)
+		return nil
+	})
+}
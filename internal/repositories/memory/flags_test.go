@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestStore_Flags(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_Flags", "internal/repositories/memory")
+
+	store := NewStore()
+	ctx := t.Context()
+	flags := store.Flags()
+
+	testhelpers.LogTestStep(logger, "act", "Saving two flags, then one again")
+	for _, f := range []domain.Flag{
+		{Name: "search.rerank", Enabled: true, Percent: 10},
+		{Name: "chat.fuzzy-match", Enabled: true, Percent: 100},
+		{Name: "search.rerank", Enabled: false, Percent: 10},
+	} {
+		if err := flags.SaveFlag(ctx, f); err != nil {
+			t.Fatalf("SaveFlag: %v", err)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Flags list by name, the last save winning")
+	all, _ := flags.Flags(ctx)
+	testhelpers.LogTestAssertion(logger, "flags", 2, len(all))
+	if len(all) != 2 || all[0].Name != "chat.fuzzy-match" || all[1].Name != "search.rerank" || all[1].Enabled {
+		t.Errorf("Flags = %+v", all)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Deleting")
+	if err := flags.DeleteFlag(ctx, "search.rerank"); err != nil {
+		t.Fatalf("DeleteFlag: %v", err)
+	}
+	if err := flags.DeleteFlag(ctx, "search.rerank"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Second delete err = %v, want ErrNotFound", err)
+	}
+	if _, err := flags.Flag(ctx, "search.rerank"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Deleted flag err = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_Flags", true)
+}
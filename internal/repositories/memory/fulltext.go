@@ -0,0 +1,269 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// ProductSearch returns the Store as a ProductSearchRepository.
+func (s *Store) ProductSearch() repositories.ProductSearchRepository { return productSearchRepo{s} }
+
+// productSearchRepo scans every product, ranking matches the way
+// Postgres' ts_rank weighs them: 1.0 for a term in the brand, 0.4 in the
+// name and 0.2 in the category or description.
+type productSearchRepo struct{ s *Store }
+
+var searchFieldWeights = [...]float64{1.0, 0.4, 0.2}
+
+func (r productSearchRepo) SearchProducts(_ context.Context, q repositories.ProductSearch) (*domain.SearchMatches, error) {
+	matches := &domain.SearchMatches{}
+	if q.Fuzzy {
+		q.Synonyms = nil
+	}
+	clauses := q.Clauses()
+	if len(clauses) == 0 {
+		return matches, nil
+	}
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	weights, retailers := r.offerFacets()
+	f := newFacetFilter(q.Filters)
+	facets := [numFacets]map[facetKey]int{{}, {}, {}, {}, {}}
+	for _, p := range r.s.products {
+		if !p.Live() {
+			continue
+		}
+		fields := [len(searchFieldWeights)][]string{
+			domain.SearchTerms(p.Brand),
+			domain.SearchTerms(p.Name),
+			domain.SearchTerms(p.Category + " " + p.Description),
+		}
+		rank := 0.0
+		for _, c := range clauses {
+			w := clauseWeight(fields[:], c, q.Fuzzy)
+			if w == 0 {
+				rank = 0
+				break
+			}
+			rank += w
+		}
+		if rank == 0 {
+			continue
+		}
+
+		diets := make([]facetKey, len(p.Dietary))
+		for i, d := range p.Dietary {
+			diets[i] = facetKey{d, domain.DietaryLabel(d)}
+		}
+		values := [numFacets][]facetKey{
+			{{p.BrandID, p.Brand}},
+			{{p.CategoryID, p.Category}},
+			weights[p.ID],
+			retailers[p.ID],
+			diets,
+		}
+		var in [numFacets]bool
+		for i := range values {
+			in[i] = f.matches(i, values[i])
+		}
+		for i := range values {
+			if !allExcept(in, i) {
+				continue
+			}
+			for _, v := range values[i] {
+				facets[i][v]++
+			}
+		}
+		if allExcept(in, -1) {
+			matches.Hits = append(matches.Hits, domain.SearchHit{ProductID: p.ID, Rank: rank})
+		}
+	}
+	sort.Slice(matches.Hits, func(i, j int) bool {
+		a, b := matches.Hits[i], matches.Hits[j]
+		if a.Rank != b.Rank {
+			return a.Rank > b.Rank
+		}
+		return a.ProductID < b.ProductID
+	})
+	matches.Total = len(matches.Hits)
+	if q.Limit > 0 && len(matches.Hits) > q.Limit {
+		matches.Hits = matches.Hits[:q.Limit]
+	}
+	matches.Facets = domain.SearchFacets{
+		Brands:     f.values(facetBrand, facets[facetBrand]),
+		Categories: f.values(facetCategory, facets[facetCategory]),
+		Weights:    f.values(facetWeight, facets[facetWeight]),
+		Retailers:  f.values(facetRetailer, facets[facetRetailer]),
+		Dietary:    f.values(facetDietary, facets[facetDietary]),
+	}
+	return matches, nil
+}
+
+// The facets, in the order of domain.SearchFacets.
+const (
+	facetBrand = iota
+	facetCategory
+	facetWeight
+	facetRetailer
+	facetDietary
+	numFacets
+)
+
+// facetKey is a facet value and its label.
+type facetKey struct{ value, label string }
+
+// offerFacets returns the pack sizes of each product's active variants and
+// the retailers with a priced listing of it, keyed by product ID. Weights
+// are labelled by the caller, in the user's units.
+func (r productSearchRepo) offerFacets() (weights, retailers map[string][]facetKey) {
+	weights, retailers = make(map[string][]facetKey), make(map[string][]facetKey)
+	for _, v := range r.s.variants {
+		if v.Live() && v.SizeGrams > 0 {
+			k := facetKey{value: strconv.Itoa(v.SizeGrams)}
+			if !slices.Contains(weights[v.ProductID], k) {
+				weights[v.ProductID] = append(weights[v.ProductID], k)
+			}
+		}
+	}
+	for _, l := range r.s.listings {
+		v, ok := r.s.variants[l.VariantID]
+		if !ok || !v.Live() || !l.IsActive || l.CurrentPrice <= 0 {
+			continue
+		}
+		k := facetKey{value: l.RetailerID, label: l.RetailerID}
+		if rt, ok := r.s.retailers[l.RetailerID]; ok {
+			k.label = rt.Name
+		}
+		if !slices.Contains(retailers[v.ProductID], k) {
+			retailers[v.ProductID] = append(retailers[v.ProductID], k)
+		}
+	}
+	return weights, retailers
+}
+
+// facetFilter holds the selected values of each facet.
+type facetFilter [numFacets]map[string]bool
+
+func newFacetFilter(f domain.SearchFilters) facetFilter {
+	weights := make([]string, len(f.Weights))
+	for i, g := range f.Weights {
+		weights[i] = strconv.Itoa(g)
+	}
+	return facetFilter{set(f.BrandIDs), set(f.CategoryIDs), set(weights), set(f.RetailerIDs), set(f.Dietary)}
+}
+
+// matches reports whether a product with values passes facet i's filter:
+// has any of its values, or every one for dietary attributes.
+func (f facetFilter) matches(i int, values []facetKey) bool {
+	if len(f[i]) == 0 {
+		return true
+	}
+	if i == facetDietary {
+		n := 0
+		for _, v := range values {
+			if f[i][v.value] {
+				n++
+			}
+		}
+		return n == len(f[i])
+	}
+	for _, v := range values {
+		if f[i][v.value] {
+			return true
+		}
+	}
+	return false
+}
+
+// values lists facet i's counts in the order ProductSearchRepository
+// documents.
+func (f facetFilter) values(i int, counts map[facetKey]int) []domain.FacetValue {
+	out := make([]domain.FacetValue, 0, len(counts))
+	for k, n := range counts {
+		out = append(out, domain.FacetValue{Value: k.value, Label: k.label, Count: n, Selected: f[i][k.value]})
+	}
+	sort.Slice(out, func(a, b int) bool {
+		if i == facetDietary {
+			return slices.Index(domain.DietaryAttributes, out[a].Value) < slices.Index(domain.DietaryAttributes, out[b].Value)
+		}
+		if i == facetWeight {
+			x, _ := strconv.Atoi(out[a].Value)
+			y, _ := strconv.Atoi(out[b].Value)
+			return x < y
+		}
+		if out[a].Count != out[b].Count {
+			return out[a].Count > out[b].Count
+		}
+		return out[a].Label < out[b].Label
+	})
+	return out
+}
+
+// allExcept reports whether every facet but skip passes its filter.
+func allExcept(in [numFacets]bool, skip int) bool {
+	for i, ok := range in {
+		if i != skip && !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// clauseWeight returns the weight of the heaviest field containing any of
+// c's alternatives, or 0 if none does. If fuzzy is set, the typed phrase
+// also matches words within domain.MaxTypos edits of its terms, its weight
+// scaled down by how many edits they are away.
+func clauseWeight(fields [][]string, c domain.SearchClause, fuzzy bool) float64 {
+	best := 0.0
+	for i, phrase := range c.Alternatives {
+		typed := i == 0
+		best = max(best, phraseWeight(fields, phrase, c.Prefix && typed, fuzzy && typed))
+	}
+	return best
+}
+
+// phraseWeight returns the weight of the heaviest field containing phrase's
+// terms as consecutive words, the last only starting its word if prefix is
+// set, or 0 if none does.
+func phraseWeight(fields [][]string, phrase []string, prefix, fuzzy bool) float64 {
+	best := 0.0
+	for i, words := range fields {
+	start:
+		for start := 0; start+len(phrase) <= len(words); start++ {
+			edits := 0
+			for k, term := range phrase {
+				w, last := words[start+k], prefix && k == len(phrase)-1
+				if w == term || last && strings.HasPrefix(w, term) {
+					continue
+				}
+				if !fuzzy {
+					continue start
+				}
+				d := typos(w, term, last)
+				if d > domain.MaxTypos(term) {
+					continue start
+				}
+				edits += d
+			}
+			best = max(best, searchFieldWeights[i]/float64(edits+1))
+		}
+	}
+	return best
+}
+
+// typos returns how many edits word is from term, or from its first
+// letters if prefix is set and it is longer.
+func typos(word, term string, prefix bool) int {
+	d := domain.EditDistance(word, term)
+	if r := []rune(word); prefix && len(r) > len([]rune(term)) {
+		d = min(d, domain.EditDistance(string(r[:len([]rune(term))]), term))
+	}
+	return d
+}
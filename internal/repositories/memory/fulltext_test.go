@@ -0,0 +1,191 @@
+package memory
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestStore_ProductSearch(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_ProductSearch", "internal/repositories/memory")
+
+	store := NewStore()
+	for _, p := range []domain.Product{
+		{ID: "prod_on_gsw", Brand: "Optimum Nutrition", Name: "Gold Standard 100% Whey", Category: "whey-protein", IsActive: true},
+		{ID: "prod_on_iso", Brand: "Optimum Nutrition", Name: "Platinum Hydrowhey", Category: "whey-isolate", IsActive: true},
+		{ID: "prod_mb_gold", Brand: "MuscleBlaze", Name: "Biozyme Gold Whey", Category: "whey-protein",
+			Description: "Enhanced absorption formula, as good as Optimum Nutrition's", IsActive: true},
+		{ID: "prod_retired", Brand: "Optimum Nutrition", Name: "Gold Standard Casein", IsActive: false},
+	} {
+		store.PutProduct(p)
+	}
+	search := store.ProductSearch()
+	ctx := t.Context()
+	hits := func(q repositories.ProductSearch) []domain.SearchHit {
+		m, err := search.SearchProducts(ctx, q)
+		if err != nil {
+			t.Fatalf("SearchProducts(%+v): %v", q, err)
+		}
+		return m.Hits
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Searching by brand, name and a partly typed word")
+	brand := hits(repositories.ProductSearch{Text: "optimum nutrition"})
+	gold := hits(repositories.ProductSearch{Text: "GOLD whey"})
+	prefix := hits(repositories.ProductSearch{Text: "hydro"})
+	limited := hits(repositories.ProductSearch{Text: "whey", Limit: 1})
+	none := hits(repositories.ProductSearch{Text: "gold casein"})
+
+	testhelpers.LogTestStep(logger, "assert", "Brand matches outrank description matches; inactive products never match")
+	testhelpers.LogTestAssertion(logger, "brand matches", 3, len(brand))
+	if len(brand) != 3 || brand[2].ProductID != "prod_mb_gold" || brand[0].Rank != 2 || brand[2].Rank >= brand[0].Rank {
+		t.Errorf("Brand search = %+v, want both ON products before the description match", brand)
+	}
+	if len(gold) != 2 || gold[0].ProductID != "prod_mb_gold" || gold[1].ProductID != "prod_on_gsw" {
+		t.Errorf("Name search = %+v", gold)
+	}
+	if len(prefix) != 1 || prefix[0].ProductID != "prod_on_iso" {
+		t.Errorf("Prefix search = %+v, want the Hydrowhey", prefix)
+	}
+	if len(limited) != 1 {
+		t.Errorf("Limited search returned %d hits", len(limited))
+	}
+	if len(none) != 0 {
+		t.Errorf("Search matched an inactive product: %+v", none)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Searching with typos")
+	typo := repositories.ProductSearch{Text: "optmum nutriton gold standrd"}
+	exact := hits(typo)
+	typo.Fuzzy = true
+	fuzzy := hits(typo)
+	short := hits(repositories.ProductSearch{Text: "ob gold", Fuzzy: true})
+
+	testhelpers.LogTestStep(logger, "assert", "Fuzzy search forgives a couple of typos per word, exact search none")
+	if len(exact) != 0 {
+		t.Errorf("Exact search with typos = %+v, want none", exact)
+	}
+	if len(fuzzy) != 1 || fuzzy[0].ProductID != "prod_on_gsw" || fuzzy[0].Rank >= brand[0].Rank {
+		t.Errorf("Fuzzy search = %+v, want the Gold Standard ranked below an exact match", fuzzy)
+	}
+	if len(short) != 0 {
+		t.Errorf("Fuzzy search corrected a two-letter word: %+v", short)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_ProductSearch", true)
+}
+
+func TestStore_ProductSearchFacets(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_ProductSearchFacets", "internal/repositories/memory")
+
+	store := NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	search := store.ProductSearch()
+
+	testhelpers.LogTestStep(logger, "act", "Searching for whey sold at Flipkart")
+	m, err := search.SearchProducts(t.Context(), repositories.ProductSearch{
+		Text:    "whey",
+		Filters: domain.SearchFilters{RetailerIDs: []string{"flipkart"}},
+	})
+	if err != nil {
+		t.Fatalf("SearchProducts: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Hits are filtered; the retailer facet ignores its own filter")
+	testhelpers.LogTestAssertion(logger, "total", 1, m.Total)
+	if m.Total != 1 || len(m.Hits) != 1 || m.Hits[0].ProductID != testhelpers.FixtureProductID {
+		t.Fatalf("Matches = %+v", m)
+	}
+	wantRetailers := []domain.FacetValue{
+		{Value: "amazon", Label: "Amazon India", Count: 2},
+		{Value: "flipkart", Label: "Flipkart", Count: 1, Selected: true},
+		{Value: "healthkart", Label: "HealthKart", Count: 1},
+	}
+	if !slices.Equal(m.Facets.Retailers, wantRetailers) {
+		t.Errorf("Retailer facet = %+v, want %+v", m.Facets.Retailers, wantRetailers)
+	}
+	if want := []domain.FacetValue{{Value: "optimum-nutrition", Label: "Optimum Nutrition", Count: 1}}; !slices.Equal(m.Facets.Brands, want) {
+		t.Errorf("Brand facet = %+v, want %+v", m.Facets.Brands, want)
+	}
+	if len(m.Facets.Weights) != 1 || m.Facets.Weights[0].Value != "2270" {
+		t.Errorf("Weight facet = %+v", m.Facets.Weights)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Adding a pack size no Flipkart product comes in")
+	m, _ = search.SearchProducts(t.Context(), repositories.ProductSearch{
+		Text:    "whey",
+		Filters: domain.SearchFilters{RetailerIDs: []string{"flipkart"}, Weights: []int{1000}},
+	})
+
+	testhelpers.LogTestStep(logger, "assert", "Nothing matches, but each facet shows what relaxing it finds")
+	if m.Total != 0 || len(m.Hits) != 0 || len(m.Facets.Brands) != 0 {
+		t.Errorf("Matches = %+v, want none", m)
+	}
+	if want := []domain.FacetValue{{Value: "2270", Count: 1}}; !slices.Equal(m.Facets.Weights, want) {
+		t.Errorf("Weight facet = %+v, want %+v", m.Facets.Weights, want)
+	}
+	if len(m.Facets.Retailers) != 1 || m.Facets.Retailers[0].Value != "amazon" {
+		t.Errorf("Retailer facet = %+v", m.Facets.Retailers)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_ProductSearchFacets", true)
+}
+
+func TestStore_ProductSearchDietary(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_ProductSearchDietary", "internal/repositories/memory")
+
+	store := NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Describing one product as gluten free and the other as vegan")
+	for id, desc := range map[string]string{
+		testhelpers.FixtureProductID:       "Gluten free, with no added sugar.",
+		testhelpers.FixtureSecondProductID: "Vegan and gluten-free. Not sugar free.",
+	} {
+		p, _ := store.Products().FindByID(ctx, id)
+		p.Description = desc
+		store.PutProduct(*p)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Attributes are derived on write")
+	p, _ := store.Products().FindByID(ctx, testhelpers.FixtureSecondProductID)
+	want := []string{domain.DietVegan, domain.DietLactoseFree, domain.DietGlutenFree}
+	testhelpers.LogTestAssertion(logger, "dietary", want, p.Dietary)
+	if !slices.Equal(p.Dietary, want) {
+		t.Errorf("Dietary = %v, want %v", p.Dietary, want)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Searching for whey that is both gluten-free and sugar-free")
+	m, err := store.ProductSearch().SearchProducts(ctx, repositories.ProductSearch{
+		Text:    "whey",
+		Filters: domain.SearchFilters{Dietary: []string{domain.DietGlutenFree, domain.DietSugarFree}},
+	})
+	if err != nil {
+		t.Fatalf("SearchProducts: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Only the product with every attribute matches; the facet ignores its filter")
+	testhelpers.LogTestAssertion(logger, "total", 1, m.Total)
+	if m.Total != 1 || m.Hits[0].ProductID != testhelpers.FixtureProductID {
+		t.Errorf("Matches = %+v", m)
+	}
+	wantFacet := []domain.FacetValue{
+		{Value: domain.DietVegan, Label: "Vegan", Count: 1},
+		{Value: domain.DietLactoseFree, Label: "Lactose-free", Count: 1},
+		{Value: domain.DietGlutenFree, Label: "Gluten-free", Count: 2, Selected: true},
+		{Value: domain.DietSugarFree, Label: "Sugar-free", Count: 1, Selected: true},
+	}
+	if !slices.Equal(m.Facets.Dietary, wantFacet) {
+		t.Errorf("Dietary facet = %+v, want %+v", m.Facets.Dietary, wantFacet)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_ProductSearchDietary", true)
+}
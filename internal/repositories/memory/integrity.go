@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Integrity returns the Store as an IntegrityRepository.
+func (s *Store) Integrity() repositories.IntegrityRepository { return integrityRepo{s} }
+
+type integrityRepo struct{ s *Store }
+
+func (r integrityRepo) OrphanVariants(_ context.Context, limit int) ([]domain.IntegrityFinding, error) {
+	s := r.s
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []domain.IntegrityFinding
+	for _, v := range s.variants {
+		if v.DeletedAt != nil {
+			continue
+		}
+		if p, ok := s.products[v.ProductID]; ok && p.DeletedAt == nil {
+			continue
+		}
+		out = append(out, domain.IntegrityFinding{Check: domain.IntegrityOrphanVariant, ProductID: v.ProductID, VariantID: v.ID})
+	}
+	slices.SortFunc(out, func(a, b domain.IntegrityFinding) int { return cmp.Compare(a.VariantID, b.VariantID) })
+	return truncate(out, limit), nil
+}
+
+func (r integrityRepo) UnpricedProducts(_ context.Context, since time.Time, limit int) ([]domain.IntegrityFinding, error) {
+	s := r.s
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	priced := make(map[string]bool)
+	for _, l := range s.listings {
+		points := s.prices[l.ID]
+		if len(points) > 0 && !points[len(points)-1].RecordedAt.Before(since) {
+			priced[s.variants[l.VariantID].ProductID] = true
+		}
+	}
+	var out []domain.IntegrityFinding
+	for _, p := range s.products {
+		if p.Live() && !priced[p.ID] {
+			out = append(out, domain.IntegrityFinding{Check: domain.IntegrityUnpricedProduct, ProductID: p.ID})
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.IntegrityFinding) int { return cmp.Compare(a.ProductID, b.ProductID) })
+	return truncate(out, limit), nil
+}
+
+func (r integrityRepo) CurrencyMismatches(_ context.Context, since time.Time, limit int) ([]domain.IntegrityFinding, error) {
+	s := r.s
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []domain.IntegrityFinding
+	for _, l := range s.listings {
+		points := s.prices[l.ID]
+		// Points are in recording order, so the window is a suffix.
+		from, _ := slices.BinarySearchFunc(points, since, func(p domain.PricePoint, t time.Time) int { return p.RecordedAt.Compare(t) })
+		for _, p := range points[from:] {
+			if p.Currency != l.Currency {
+				out = append(out, domain.IntegrityFinding{
+					Check:           domain.IntegrityCurrencyMismatch,
+					ProductID:       s.variants[l.VariantID].ProductID,
+					VariantID:       l.VariantID,
+					ListingID:       l.ID,
+					PriceID:         p.ID,
+					Currency:        p.Currency,
+					ListingCurrency: l.Currency,
+				})
+			}
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.IntegrityFinding) int {
+		return cmp.Or(cmp.Compare(a.ListingID, b.ListingID), cmp.Compare(a.PriceID, b.PriceID))
+	})
+	return truncate(out, limit), nil
+}
@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestStore_Integrity(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_Integrity", "internal/repositories/memory")
+
+	testhelpers.LogTestStep(logger, "arrange", "A catalog with an orphan variant, a stale product and a USD price on an INR listing")
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	deleted := now.Add(-time.Hour)
+	store := NewStore()
+	store.PutProduct(domain.Product{ID: "p_fresh", IsActive: true})
+	store.PutProduct(domain.Product{ID: "p_stale", IsActive: true})
+	store.PutProduct(domain.Product{ID: "p_never", IsActive: true})
+	store.PutProduct(domain.Product{ID: "p_gone", IsActive: true, DeletedAt: &deleted})
+	store.PutProduct(domain.Product{ID: "p_inactive"})
+	for _, v := range []domain.Variant{
+		{ID: "v_fresh", ProductID: "p_fresh", IsActive: true},
+		{ID: "v_stale", ProductID: "p_stale", IsActive: true},
+		{ID: "v_gone", ProductID: "p_gone", IsActive: true},
+		{ID: "v_missing", ProductID: "p_missing", IsActive: true},
+		{ID: "v_deleted", ProductID: "p_missing", DeletedAt: &deleted},
+	} {
+		store.PutVariant(v)
+	}
+	store.PutListing(domain.Listing{ID: "l_fresh", VariantID: "v_fresh", Currency: domain.DefaultCurrency})
+	store.PutListing(domain.Listing{ID: "l_stale", VariantID: "v_stale", Currency: domain.DefaultCurrency})
+	for _, p := range []domain.PricePoint{
+		{ID: "h1", ListingID: "l_stale", Currency: "USD", RecordedAt: now.AddDate(0, 0, -10)},
+		{ID: "h2", ListingID: "l_fresh", Currency: "USD", RecordedAt: now.AddDate(0, 0, -3)},
+		{ID: "h3", ListingID: "l_fresh", Currency: domain.DefaultCurrency, RecordedAt: now.Add(-time.Hour)},
+	} {
+		store.AddPricePoint(p)
+	}
+	repo := store.Integrity()
+	ctx := t.Context()
+	since := now.AddDate(0, 0, -7)
+
+	testhelpers.LogTestStep(logger, "act", "Running each check")
+	orphans, err := repo.OrphanVariants(ctx, 0)
+	if err != nil {
+		t.Fatalf("OrphanVariants: %v", err)
+	}
+	unpriced, err := repo.UnpricedProducts(ctx, since, 0)
+	if err != nil {
+		t.Fatalf("UnpricedProducts: %v", err)
+	}
+	mismatches, err := repo.CurrencyMismatches(ctx, since, 0)
+	if err != nil {
+		t.Fatalf("CurrencyMismatches: %v", err)
+	}
+	limited, _ := repo.OrphanVariants(ctx, 1)
+
+	testhelpers.LogTestStep(logger, "assert", "Each check finds its rows, in ID order, up to the limit")
+	ids := func(fs []domain.IntegrityFinding, id func(domain.IntegrityFinding) string) []string {
+		var out []string
+		for _, f := range fs {
+			out = append(out, f.Check+":"+id(f))
+		}
+		return out
+	}
+	for _, tc := range []struct {
+		name      string
+		got, want []string
+	}{
+		{"orphans", ids(orphans, func(f domain.IntegrityFinding) string { return f.VariantID }),
+			[]string{"orphan_variant:v_gone", "orphan_variant:v_missing"}},
+		{"unpriced", ids(unpriced, func(f domain.IntegrityFinding) string { return f.ProductID }),
+			[]string{"unpriced_product:p_never", "unpriced_product:p_stale"}},
+		{"mismatches", ids(mismatches, func(f domain.IntegrityFinding) string { return f.PriceID }),
+			[]string{"currency_mismatch:h2"}},
+		{"limited", ids(limited, func(f domain.IntegrityFinding) string { return f.VariantID }),
+			[]string{"orphan_variant:v_gone"}},
+	} {
+		testhelpers.LogTestAssertion(logger, tc.name, tc.want, tc.got)
+		if !slices.Equal(tc.got, tc.want) {
+			t.Errorf("%s = %q, want %q", tc.name, tc.got, tc.want)
+		}
+	}
+	if m := mismatches[0]; m.ListingID != "l_fresh" || m.ProductID != "p_fresh" || m.Currency != "USD" || m.ListingCurrency != domain.DefaultCurrency {
+		t.Errorf("mismatch = %+v", m)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_Integrity", true)
+}
@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Outbox returns the Store as an OutboxRepository.
+func (s *Store) Outbox() repositories.OutboxRepository { return outboxRepo{s} }
+
+// write runs change under the write lock and, if it succeeds, stores
+// events in the outbox, and price events in the price change log, under
+// the same lock, so readers see all or none.
+func (s *Store) write(events []domain.Event, change func() error) error {
+	now := time.Now().UTC()
+	messages, err := domain.NewOutboxMessages(now, events...)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := change(); err != nil {
+		return err
+	}
+	for _, m := range messages {
+		s.nextID++
+		m.ID = fmt.Sprintf("outbox_%d", s.nextID)
+		s.outbox = append(s.outbox, m)
+	}
+	for _, e := range domain.NewPriceLogEntries(now, events...) {
+		e.Offset = int64(len(s.priceLog)) + 1
+		s.priceLog = append(s.priceLog, e)
+	}
+	return nil
+}
+
+type outboxRepo struct{ s *Store }
+
+func (r outboxRepo) Pending(_ context.Context, limit int) ([]domain.OutboxMessage, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var out []domain.OutboxMessage
+	for _, m := range r.s.outbox {
+		if limit > 0 && len(out) == limit {
+			break
+		}
+		if m.PublishedAt == nil && m.FailedAt == nil {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+func (r outboxRepo) MarkPublished(_ context.Context, at time.Time, ids ...string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for i := range r.s.outbox {
+		if slices.Contains(ids, r.s.outbox[i].ID) {
+			r.s.outbox[i].PublishedAt = &at
+		}
+	}
+	return nil
+}
+
+func (r outboxRepo) MarkRetry(_ context.Context, id, reason string) error {
+	return r.update(id, func(m *domain.OutboxMessage) {
+		m.Attempts++
+		m.LastError = reason
+	})
+}
+
+func (r outboxRepo) MarkFailed(_ context.Context, at time.Time, id, reason string) error {
+	return r.update(id, func(m *domain.OutboxMessage) {
+		m.Attempts++
+		m.LastError = reason
+		m.FailedAt = &at
+	})
+}
+
+func (r outboxRepo) DeletePublishedBefore(_ context.Context, cutoff time.Time) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	before := len(r.s.outbox)
+	r.s.outbox = slices.DeleteFunc(r.s.outbox, func(m domain.OutboxMessage) bool {
+		return m.PublishedAt != nil && m.PublishedAt.Before(cutoff)
+	})
+	return before - len(r.s.outbox), nil
+}
+
+func (r outboxRepo) update(id string, fn func(*domain.OutboxMessage)) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for i := range r.s.outbox {
+		if r.s.outbox[i].ID == id {
+			fn(&r.s.outbox[i])
+			return nil
+		}
+	}
+	return fmt.Errorf("outbox message %q: %w", id, domain.ErrNotFound)
+}
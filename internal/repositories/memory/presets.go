@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// FilterPresets returns the Store as a FilterPresetRepository.
+func (s *Store) FilterPresets() repositories.FilterPresetRepository { return presetRepo{s} }
+
+type presetRepo struct{ s *Store }
+
+// clonePreset copies p's filter slices, so callers cannot change the
+// stored preset through them.
+func clonePreset(p domain.FilterPreset) domain.FilterPreset {
+	p.Filters = domain.SearchFilters{
+		BrandIDs:    slices.Clone(p.Filters.BrandIDs),
+		CategoryIDs: slices.Clone(p.Filters.CategoryIDs),
+		Weights:     slices.Clone(p.Filters.Weights),
+		RetailerIDs: slices.Clone(p.Filters.RetailerIDs),
+		Dietary:     slices.Clone(p.Filters.Dietary),
+	}
+	return p
+}
+
+func (r presetRepo) CreateFilterPreset(_ context.Context, p domain.FilterPreset) (domain.FilterPreset, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.nextID++
+	p.ID = fmt.Sprintf("preset_%d", r.s.nextID)
+	r.s.presets[p.ID] = clonePreset(p)
+	return p, nil
+}
+
+func (r presetRepo) FilterPreset(_ context.Context, id string) (*domain.FilterPreset, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	p, ok := r.s.presets[id]
+	if !ok {
+		return nil, fmt.Errorf("filter preset %q: %w", id, domain.ErrNotFound)
+	}
+	p = clonePreset(p)
+	return &p, nil
+}
+
+func (r presetRepo) UserFilterPresets(_ context.Context, userID string) ([]domain.FilterPreset, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var out []domain.FilterPreset
+	for _, p := range r.s.presets {
+		if p.UserID == userID {
+			out = append(out, clonePreset(p))
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.FilterPreset) int { return compareIDs(a.ID, b.ID) })
+	return out, nil
+}
+
+func (r presetRepo) SaveFilterPreset(_ context.Context, p domain.FilterPreset) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.presets[p.ID]; !ok {
+		return fmt.Errorf("filter preset %q: %w", p.ID, domain.ErrNotFound)
+	}
+	r.s.presets[p.ID] = clonePreset(p)
+	return nil
+}
+
+func (r presetRepo) DeleteFilterPreset(_ context.Context, id string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.presets[id]; !ok {
+		return fmt.Errorf("filter preset %q: %w", id, domain.ErrNotFound)
+	}
+	delete(r.s.presets, id)
+	return nil
+}
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestStore_FilterPresets(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_FilterPresets", "internal/repositories/memory")
+
+	store := NewStore()
+	ctx := t.Context()
+	presets := store.FilterPresets()
+
+	testhelpers.LogTestStep(logger, "act", "Creating two presets for one user and one for another")
+	var created []domain.FilterPreset
+	for _, p := range []domain.FilterPreset{
+		{UserID: "user_1", Name: "Budget isolate", Filters: domain.SearchFilters{CategoryIDs: []string{"isolate"}}},
+		{UserID: "user_1", Name: "Vegan", Filters: domain.SearchFilters{Dietary: []string{domain.DietVegan}}},
+		{UserID: "user_2", Name: "Cheapest", Sort: domain.SortPricePerProtein},
+	} {
+		p, err := presets.CreateFilterPreset(ctx, p)
+		if err != nil {
+			t.Fatalf("CreateFilterPreset: %v", err)
+		}
+		created = append(created, p)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Presets are listed per user and copied out")
+	mine, _ := presets.UserFilterPresets(ctx, "user_1")
+	testhelpers.LogTestAssertion(logger, "user_1 presets", 2, len(mine))
+	if len(mine) != 2 || mine[0].ID != created[0].ID {
+		t.Fatalf("UserFilterPresets = %+v", mine)
+	}
+	mine[0].Filters.CategoryIDs[0] = "changed"
+	if p, _ := presets.FilterPreset(ctx, created[0].ID); p.Filters.CategoryIDs[0] != "isolate" {
+		t.Errorf("Stored preset changed through a listed copy: %+v", p)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Renaming and deleting")
+	renamed := created[1]
+	renamed.Name = "Plant protein"
+	if err := presets.SaveFilterPreset(ctx, renamed); err != nil {
+		t.Fatalf("SaveFilterPreset: %v", err)
+	}
+	if p, _ := presets.FilterPreset(ctx, renamed.ID); p.Name != "Plant protein" {
+		t.Errorf("Saved preset = %+v", p)
+	}
+	if err := presets.DeleteFilterPreset(ctx, created[2].ID); err != nil {
+		t.Fatalf("DeleteFilterPreset: %v", err)
+	}
+	if _, err := presets.FilterPreset(ctx, created[2].ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Deleted preset error = %v, want ErrNotFound", err)
+	}
+	if err := presets.SaveFilterPreset(ctx, created[2]); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Saving a deleted preset error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_FilterPresets", true)
+}
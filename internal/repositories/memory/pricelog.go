@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// PriceLog returns the Store as a PriceLogRepository. Entries are
+// appended by write.
+func (s *Store) PriceLog() repositories.PriceLogRepository { return priceLogRepo{s} }
+
+type priceLogRepo struct{ s *Store }
+
+func (r priceLogRepo) Read(_ context.Context, filter repositories.PriceLogFilter) ([]domain.PriceLogEntry, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var out []domain.PriceLogEntry
+	for _, e := range r.s.priceLog[min(max(filter.After, 0), int64(len(r.s.priceLog))):] {
+		if filter.Limit > 0 && len(out) == filter.Limit {
+			break
+		}
+		if filter.ProductID == "" || e.ProductID == filter.ProductID {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (r priceLogRepo) Head(context.Context) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return int64(len(r.s.priceLog)), nil
+}
+
+func (r priceLogRepo) OffsetBefore(_ context.Context, t time.Time) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var offset int64
+	for _, e := range r.s.priceLog {
+		if e.RecordedAt.Before(t) {
+			offset = e.Offset
+		}
+	}
+	return offset, nil
+}
+
+func (r priceLogRepo) Cursor(_ context.Context, consumer string) (domain.PriceLogCursor, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	if c, ok := r.s.logCursors[consumer]; ok {
+		return c, nil
+	}
+	return domain.PriceLogCursor{Consumer: consumer}, nil
+}
+
+func (r priceLogRepo) Cursors(context.Context) ([]domain.PriceLogCursor, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := make([]domain.PriceLogCursor, 0, len(r.s.logCursors))
+	for _, c := range r.s.logCursors {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Consumer < out[j].Consumer })
+	return out, nil
+}
+
+func (r priceLogRepo) Commit(_ context.Context, consumer string, from, to int64, at time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if current := r.s.logCursors[consumer].Offset; current != from {
+		return fmt.Errorf("price log consumer %q is at %d, not %d: %w", consumer, current, from, domain.ErrConflict)
+	}
+	r.s.logCursors[consumer] = domain.PriceLogCursor{Consumer: consumer, Offset: to, UpdatedAt: at}
+	return nil
+}
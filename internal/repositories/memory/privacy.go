@@ -0,0 +1,264 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// UserData returns the Store as a UserDataRepository.
+func (s *Store) UserData() repositories.UserDataRepository { return userDataRepo{s: s, accounts: true} }
+
+// UserDataExceptAccounts returns the Store as a UserDataRepository of what
+// it holds when accounts, and the sessions, tokens, links and alerts that
+// hang off them, are stored elsewhere: it neither needs nor exports the
+// account, and leaves those kinds of record alone.
+func (s *Store) UserDataExceptAccounts() repositories.UserDataRepository {
+	return userDataRepo{s: s}
+}
+
+// DataRequests returns the Store as a DataRequestRepository.
+func (s *Store) DataRequests() repositories.DataRequestRepository { return dataRequestRepo{s} }
+
+type userDataRepo struct {
+	s        *Store
+	accounts bool
+}
+
+func (r userDataRepo) ExportUserData(_ context.Context, userID string) (*domain.UserData, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	u, ok := r.s.users[userID]
+	if !ok && r.accounts {
+		return nil, fmt.Errorf("user %s: %w", userID, domain.ErrNotFound)
+	}
+	d := &domain.UserData{
+		Account:           u,
+		LinkedAccounts:    []string{},
+		Alerts:            []domain.PriceAlert{},
+		SavedSearches:     []domain.SavedSearch{},
+		FilterPresets:     []domain.FilterPreset{},
+		Notifications:     []domain.Notification{},
+		Watchlist:         []domain.WatchlistItem{},
+		PushSubscriptions: []domain.PushSubscription{},
+		APITokens:         []domain.APIToken{},
+		Clicks:            []domain.ClickEvent{},
+	}
+	for key, owner := range r.s.identities {
+		if owner == userID {
+			provider, _, _ := strings.Cut(key, "\x00")
+			d.LinkedAccounts = append(d.LinkedAccounts, provider)
+		}
+	}
+	slices.Sort(d.LinkedAccounts)
+	for _, a := range r.s.alerts {
+		if a.UserID == userID {
+			d.Alerts = append(d.Alerts, a)
+		}
+	}
+	slices.SortFunc(d.Alerts, func(a, b domain.PriceAlert) int { return compareIDs(a.ID, b.ID) })
+	for _, search := range r.s.savedSearches {
+		if search.UserID == userID {
+			d.SavedSearches = append(d.SavedSearches, search)
+		}
+	}
+	slices.SortFunc(d.SavedSearches, func(a, b domain.SavedSearch) int { return compareIDs(a.ID, b.ID) })
+	for _, p := range r.s.presets {
+		if p.UserID == userID {
+			d.FilterPresets = append(d.FilterPresets, clonePreset(p))
+		}
+	}
+	slices.SortFunc(d.FilterPresets, func(a, b domain.FilterPreset) int { return compareIDs(a.ID, b.ID) })
+	for _, n := range r.s.notifications {
+		if n.UserID == userID {
+			d.Notifications = append(d.Notifications, n)
+		}
+	}
+	if p, ok := r.s.preferences[userID]; ok {
+		d.Preferences = &p
+	}
+	for _, item := range r.s.watchlist {
+		if item.UserID == userID {
+			d.Watchlist = append(d.Watchlist, item)
+		}
+	}
+	slices.SortFunc(d.Watchlist, func(a, b domain.WatchlistItem) int { return a.AddedAt.Compare(b.AddedAt) })
+	if l, ok := r.s.telegramLinks[userID]; ok {
+		d.TelegramChatID = l.ChatID
+	}
+	for _, sub := range r.s.pushSubscriptions {
+		if sub.UserID == userID {
+			d.PushSubscriptions = append(d.PushSubscriptions, sub)
+		}
+	}
+	slices.SortFunc(d.PushSubscriptions, func(a, b domain.PushSubscription) int { return compareIDs(a.ID, b.ID) })
+	if sub, ok := r.s.smsSubscriptions[userID]; ok {
+		d.SMS = &sub
+	}
+	if w, ok := r.s.discordWebhooks[userID]; ok {
+		d.Discord = &w
+	}
+	if w, ok := r.s.slackWebhooks[userID]; ok {
+		d.Slack = &w
+	}
+	for _, t := range r.s.apiTokens {
+		if t.UserID == userID {
+			d.APITokens = append(d.APITokens, t)
+		}
+	}
+	slices.SortFunc(d.APITokens, func(a, b domain.APIToken) int { return compareIDs(a.ID, b.ID) })
+	for _, c := range r.s.clicks {
+		if c.UserID == userID {
+			d.Clicks = append(d.Clicks, c)
+		}
+	}
+	return d, nil
+}
+
+func (r userDataRepo) DeleteUserData(_ context.Context, userID string) (map[string]int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	removed := make(map[string]int)
+	count := func(kind string, n int) {
+		if n > 0 {
+			removed[kind] += n
+		}
+	}
+	if r.accounts {
+		if _, ok := r.s.users[userID]; !ok {
+			return nil, fmt.Errorf("user %s: %w", userID, domain.ErrNotFound)
+		}
+		delete(r.s.users, userID)
+		count("account", 1)
+		count("sessions", deleteFunc(r.s.sessions, func(s domain.Session) bool { return s.UserID == userID }))
+		count("verifications", deleteFunc(r.s.verifications, func(v domain.EmailVerification) bool { return v.UserID == userID }))
+		count("linked_accounts", deleteFunc(r.s.identities, func(owner string) bool { return owner == userID }))
+		count("api_tokens", deleteFunc(r.s.apiTokens, func(t domain.APIToken) bool { return t.UserID == userID }))
+		count("alerts", deleteFunc(r.s.alerts, func(a domain.PriceAlert) bool { return a.UserID == userID }))
+	}
+	count("saved_searches", deleteFunc(r.s.savedSearches, func(s domain.SavedSearch) bool { return s.UserID == userID }))
+	count("filter_presets", deleteFunc(r.s.presets, func(p domain.FilterPreset) bool { return p.UserID == userID }))
+	count("failed_deliveries", deleteFunc(r.s.deliveries, func(f domain.FailedDelivery) bool { return f.Notification.UserID == userID }))
+	count("preferences", deleteFunc(r.s.preferences, func(p domain.NotificationPreferences) bool { return p.UserID == userID }))
+	count("telegram", deleteFunc(r.s.telegramLinks, func(l domain.TelegramLink) bool { return l.UserID == userID }))
+	count("telegram", deleteFunc(r.s.telegramTokens, func(t domain.TelegramLinkToken) bool { return t.UserID == userID }))
+	count("push_subscriptions", deleteFunc(r.s.pushSubscriptions, func(p domain.PushSubscription) bool { return p.UserID == userID }))
+	count("sms", deleteFunc(r.s.smsSubscriptions, func(s domain.SMSSubscription) bool { return s.UserID == userID }))
+	count("discord", deleteFunc(r.s.discordWebhooks, func(w domain.DiscordWebhook) bool { return w.UserID == userID }))
+	count("slack", deleteFunc(r.s.slackWebhooks, func(w domain.SlackWebhook) bool { return w.UserID == userID }))
+	count("watchlist", deleteFunc(r.s.watchlist, func(w domain.WatchlistItem) bool { return w.UserID == userID }))
+
+	var n int
+	r.s.notifications, n = dropWhere(r.s.notifications, func(n domain.Notification) bool { return n.UserID == userID })
+	count("notifications", n)
+	r.s.smsLog, n = dropWhere(r.s.smsLog, func(m domain.SMSMessage) bool { return m.UserID == userID })
+	count("sms", n)
+	r.s.clicks, n = dropWhere(r.s.clicks, func(c domain.ClickEvent) bool { return c.UserID == userID })
+	count("clicks", n)
+
+	// The requests themselves stay as a record, but not any exported data.
+	for id, req := range r.s.dataRequests {
+		if req.UserID == userID && req.Result != nil {
+			req.Result = nil
+			r.s.dataRequests[id] = req
+			count("exports", 1)
+		}
+	}
+	return removed, nil
+}
+
+// deleteFunc deletes the entries of m that match and returns how many.
+func deleteFunc[K comparable, V any](m map[K]V, match func(V) bool) int {
+	n := 0
+	for k, v := range m {
+		if match(v) {
+			delete(m, k)
+			n++
+		}
+	}
+	return n
+}
+
+// dropWhere returns items without those that match, and how many matched.
+func dropWhere[T any](items []T, match func(T) bool) ([]T, int) {
+	before := len(items)
+	items = slices.DeleteFunc(items, match)
+	return items, before - len(items)
+}
+
+type dataRequestRepo struct{ s *Store }
+
+func (r dataRequestRepo) CreateDataRequest(_ context.Context, req domain.DataRequest) (domain.DataRequest, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.nextID++
+	req.ID = fmt.Sprintf("datareq_%d", r.s.nextID)
+	r.s.dataRequests[req.ID] = req
+	return req, nil
+}
+
+func (r dataRequestRepo) SaveDataRequest(_ context.Context, req domain.DataRequest) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.dataRequests[req.ID]; !ok {
+		return fmt.Errorf("data request %s: %w", req.ID, domain.ErrNotFound)
+	}
+	r.s.dataRequests[req.ID] = req
+	return nil
+}
+
+func (r dataRequestRepo) DataRequest(_ context.Context, id string) (*domain.DataRequest, error) {
+	return find(r.s, r.s.dataRequests, id, "data request")
+}
+
+func (r dataRequestRepo) UserDataRequests(_ context.Context, userID string) ([]domain.DataRequest, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var out []domain.DataRequest
+	for _, req := range r.s.dataRequests {
+		if req.UserID == userID {
+			out = append(out, req)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.DataRequest) int { return compareIDs(b.ID, a.ID) })
+	return out, nil
+}
+
+func (r dataRequestRepo) PendingDataRequests(_ context.Context, limit int) ([]domain.DataRequest, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var out []domain.DataRequest
+	for _, req := range r.s.dataRequests {
+		if req.Status == domain.DataRequestPending {
+			out = append(out, req)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.DataRequest) int { return compareIDs(a.ID, b.ID) })
+	return truncate(out, limit), nil
+}
+
+func (r dataRequestRepo) PurgeExports(_ context.Context, now time.Time) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	n := 0
+	for id, req := range r.s.dataRequests {
+		if req.Result != nil && req.ExpiresAt != nil && req.ExpiresAt.Before(now) {
+			req.Result = nil
+			r.s.dataRequests[id] = req
+			n++
+		}
+	}
+	return n, nil
+}
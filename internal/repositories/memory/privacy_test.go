@@ -0,0 +1,151 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestStore_UserData(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_UserData", "internal/repositories/memory")
+
+	store := NewStore()
+	ctx := t.Context()
+	now := time.Now()
+
+	testhelpers.LogTestStep(logger, "arrange", "Two users with alerts, sessions, clicks and a watchlist")
+	var ids []string
+	for _, email := range []string{"asha@example.com", "ravi@example.com"} {
+		u, err := store.Users().CreateUser(ctx, domain.User{Email: email, CreatedAt: now})
+		if err != nil {
+			t.Fatalf("CreateUser: %v", err)
+		}
+		ids = append(ids, u.ID)
+		if _, err := store.Alerts().CreateAlert(ctx, domain.PriceAlert{UserID: u.ID, ProductID: "prod_1"}); err != nil {
+			t.Fatalf("CreateAlert: %v", err)
+		}
+		if err := store.Sessions().CreateSession(ctx, domain.Session{TokenHash: "hash-" + u.ID, UserID: u.ID, ExpiresAt: now.Add(time.Hour)}); err != nil {
+			t.Fatalf("CreateSession: %v", err)
+		}
+		if err := store.Watchlists().AddWatch(ctx, domain.WatchlistItem{UserID: u.ID, ProductID: "prod_1", AddedAt: now}); err != nil {
+			t.Fatalf("AddWatch: %v", err)
+		}
+	}
+	asha, ravi := ids[0], ids[1]
+	if err := store.Clicks().RecordClicks(ctx, []domain.ClickEvent{
+		{ProductID: "prod_1", UserID: asha, CreatedAt: now},
+		{ProductID: "prod_1", UserID: asha, CreatedAt: now},
+		{ProductID: "prod_1", CreatedAt: now},
+	}); err != nil {
+		t.Fatalf("RecordClicks: %v", err)
+	}
+	export, err := store.DataRequests().CreateDataRequest(ctx, domain.DataRequest{UserID: asha, Kind: domain.DataRequestExport, Result: []byte("{}")})
+	if err != nil {
+		t.Fatalf("CreateDataRequest: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Exporting the first user's data")
+	data, err := store.UserData().ExportUserData(ctx, asha)
+	if err != nil {
+		t.Fatalf("ExportUserData: %v", err)
+	}
+	testhelpers.LogTestAssertion(logger, "exported clicks", 2, len(data.Clicks))
+	if data.Account.ID != asha || len(data.Alerts) != 1 || len(data.Watchlist) != 1 || len(data.Clicks) != 2 {
+		t.Errorf("Export = %+v", data)
+	}
+	if data.LinkedAccounts == nil || data.APITokens == nil {
+		t.Errorf("Empty sections should be empty lists: %+v", data)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Deleting the first user's data")
+	removed, err := store.UserData().DeleteUserData(ctx, asha)
+	if err != nil {
+		t.Fatalf("DeleteUserData: %v", err)
+	}
+	want := map[string]int{"account": 1, "sessions": 1, "alerts": 1, "watchlist": 1, "clicks": 2, "exports": 1}
+	testhelpers.LogTestAssertion(logger, "removed", want, removed)
+	for kind, n := range want {
+		if removed[kind] != n {
+			t.Errorf("Removed %s = %d, want %d (all: %v)", kind, removed[kind], n, removed)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The user's data is gone and the other user's is kept")
+	if _, err := store.UserData().ExportUserData(ctx, asha); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Export after delete error = %v, want ErrNotFound", err)
+	}
+	if _, err := store.UserData().DeleteUserData(ctx, asha); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Second delete error = %v, want ErrNotFound", err)
+	}
+	if req, err := store.DataRequests().DataRequest(ctx, export.ID); err != nil || req.Result != nil {
+		t.Errorf("Export request after delete = %+v, %v; want kept without its result", req, err)
+	}
+	if n, _ := store.Clicks().CountClicks(ctx, repositories.ClickFilter{ProductID: "prod_1"}); n != 1 {
+		t.Errorf("Clicks left = %d, want 1", n)
+	}
+	other, err := store.UserData().ExportUserData(ctx, ravi)
+	if err != nil || len(other.Alerts) != 1 || len(other.Watchlist) != 1 {
+		t.Errorf("Other user's export = %+v, %v", other, err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_UserData", true)
+}
+
+func TestStore_DataRequests(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_DataRequests", "internal/repositories/memory")
+
+	store := NewStore()
+	ctx := t.Context()
+	reqs := store.DataRequests()
+	now := time.Now()
+	expired, later := now.Add(-time.Minute), now.Add(time.Hour)
+
+	testhelpers.LogTestStep(logger, "arrange", "A finished expired export, a finished live one and a pending deletion")
+	var ids []string
+	for _, req := range []domain.DataRequest{
+		{UserID: "user_1", Kind: domain.DataRequestExport, Status: domain.DataRequestDone, Result: []byte("{}"), ExpiresAt: &expired},
+		{UserID: "user_1", Kind: domain.DataRequestExport, Status: domain.DataRequestDone, Result: []byte("{}"), ExpiresAt: &later},
+		{UserID: "user_1", Kind: domain.DataRequestDelete, Status: domain.DataRequestPending},
+		{UserID: "user_2", Kind: domain.DataRequestExport, Status: domain.DataRequestPending},
+	} {
+		created, err := reqs.CreateDataRequest(ctx, req)
+		if err != nil {
+			t.Fatalf("CreateDataRequest: %v", err)
+		}
+		ids = append(ids, created.ID)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Listing orders and pending requests")
+	mine, err := reqs.UserDataRequests(ctx, "user_1")
+	if err != nil || len(mine) != 3 || mine[0].ID != ids[2] {
+		t.Errorf("UserDataRequests = %+v, %v; want 3, newest first", mine, err)
+	}
+	pending, err := reqs.PendingDataRequests(ctx, 1)
+	testhelpers.LogTestAssertion(logger, "oldest pending", ids[2], pending)
+	if err != nil || len(pending) != 1 || pending[0].ID != ids[2] {
+		t.Errorf("PendingDataRequests(1) = %+v, %v; want the oldest", pending, err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Purging expired exports")
+	n, err := reqs.PurgeExports(ctx, now)
+	if err != nil || n != 1 {
+		t.Fatalf("PurgeExports = %d, %v; want 1", n, err)
+	}
+	for i, wantResult := range []bool{false, true} {
+		req, err := reqs.DataRequest(ctx, ids[i])
+		if err != nil || (req.Result != nil) != wantResult {
+			t.Errorf("Export %s after purge = %+v, %v; want result %v", ids[i], req, err, wantResult)
+		}
+	}
+	if err := reqs.SaveDataRequest(ctx, domain.DataRequest{ID: "datareq_missing"}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("SaveDataRequest(missing) error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_DataRequests", true)
+}
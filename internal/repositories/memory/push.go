@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// PushSubscriptions returns the Store as a PushSubscriptionRepository.
+func (s *Store) PushSubscriptions() repositories.PushSubscriptionRepository {
+	return pushRepo{s}
+}
+
+type pushRepo struct{ s *Store }
+
+func (r pushRepo) SavePushSubscription(_ context.Context, sub domain.PushSubscription) (domain.PushSubscription, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for id, existing := range r.s.pushSubscriptions {
+		if existing.Endpoint == sub.Endpoint {
+			delete(r.s.pushSubscriptions, id)
+		}
+	}
+	r.s.nextID++
+	sub.ID = fmt.Sprintf("push_%d", r.s.nextID)
+	r.s.pushSubscriptions[sub.ID] = sub
+	return sub, nil
+}
+
+func (r pushRepo) UserPushSubscriptions(_ context.Context, userID string) ([]domain.PushSubscription, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var out []domain.PushSubscription
+	for _, sub := range r.s.pushSubscriptions {
+		if sub.UserID == userID {
+			out = append(out, sub)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.PushSubscription) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+	return out, nil
+}
+
+func (r pushRepo) DeletePushSubscription(_ context.Context, id string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.pushSubscriptions[id]; !ok {
+		return fmt.Errorf("push subscription %q: %w", id, domain.ErrNotFound)
+	}
+	delete(r.s.pushSubscriptions, id)
+	return nil
+}
@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestStore_PushSubscriptions(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_PushSubscriptions", "internal/repositories/memory")
+
+	store := NewStore()
+	ctx := t.Context()
+	push := store.PushSubscriptions()
+	now := time.Now()
+
+	testhelpers.LogTestStep(logger, "act", "Saving two endpoints, then one again for another user")
+	var saved []domain.PushSubscription
+	for i, endpoint := range []string{"https://push.example/a", "https://push.example/b"} {
+		sub, err := push.SavePushSubscription(ctx, domain.PushSubscription{UserID: "user_1", Endpoint: endpoint, CreatedAt: now.Add(time.Duration(i) * time.Minute)})
+		if err != nil || sub.ID == "" {
+			t.Fatalf("SavePushSubscription = %+v, %v", sub, err)
+		}
+		saved = append(saved, sub)
+	}
+	if _, err := push.SavePushSubscription(ctx, domain.PushSubscription{UserID: "user_2", Endpoint: "https://push.example/a", CreatedAt: now}); err != nil {
+		t.Fatalf("SavePushSubscription: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The endpoint moved to the second user")
+	mine, _ := push.UserPushSubscriptions(ctx, "user_1")
+	testhelpers.LogTestAssertion(logger, "user_1 subscriptions", 1, len(mine))
+	if len(mine) != 1 || mine[0].ID != saved[1].ID {
+		t.Errorf("UserPushSubscriptions = %+v, want only %s", mine, saved[1].ID)
+	}
+	if err := push.DeletePushSubscription(ctx, saved[1].ID); err != nil {
+		t.Fatalf("DeletePushSubscription: %v", err)
+	}
+	if err := push.DeletePushSubscription(ctx, saved[1].ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Second delete error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_PushSubscriptions", true)
+}
@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// SavedSearches returns the Store as a SavedSearchRepository.
+func (s *Store) SavedSearches() repositories.SavedSearchRepository { return savedSearchRepo{s} }
+
+type savedSearchRepo struct{ s *Store }
+
+func (r savedSearchRepo) CreateSavedSearch(_ context.Context, search domain.SavedSearch) (domain.SavedSearch, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.nextID++
+	search.ID = fmt.Sprintf("search_%d", r.s.nextID)
+	search.Notified = maps.Clone(search.Notified)
+	r.s.savedSearches[search.ID] = search
+	return search, nil
+}
+
+func (r savedSearchRepo) SavedSearch(_ context.Context, id string) (*domain.SavedSearch, error) {
+	search, err := find(r.s, r.s.savedSearches, id, "saved search")
+	if err != nil {
+		return nil, err
+	}
+	// find copies the struct but not its map.
+	search.Notified = maps.Clone(search.Notified)
+	return search, nil
+}
+
+func (r savedSearchRepo) UserSavedSearches(_ context.Context, userID string) ([]domain.SavedSearch, error) {
+	out := r.filter(func(s domain.SavedSearch) bool { return s.UserID == userID })
+	slices.SortFunc(out, func(a, b domain.SavedSearch) int { return compareIDs(b.ID, a.ID) })
+	return out, nil
+}
+
+func (r savedSearchRepo) AllSavedSearches(_ context.Context) ([]domain.SavedSearch, error) {
+	out := r.filter(func(domain.SavedSearch) bool { return true })
+	slices.SortFunc(out, func(a, b domain.SavedSearch) int { return compareIDs(a.ID, b.ID) })
+	return out, nil
+}
+
+func (r savedSearchRepo) filter(keep func(domain.SavedSearch) bool) []domain.SavedSearch {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var out []domain.SavedSearch
+	for _, search := range r.s.savedSearches {
+		if keep(search) {
+			search.Notified = maps.Clone(search.Notified)
+			out = append(out, search)
+		}
+	}
+	return out
+}
+
+func (r savedSearchRepo) SaveSavedSearch(_ context.Context, search domain.SavedSearch) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.savedSearches[search.ID]; !ok {
+		return fmt.Errorf("saved search %q: %w", search.ID, domain.ErrNotFound)
+	}
+	search.Notified = maps.Clone(search.Notified)
+	r.s.savedSearches[search.ID] = search
+	return nil
+}
+
+func (r savedSearchRepo) DeleteSavedSearch(_ context.Context, id string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.savedSearches[id]; !ok {
+		return fmt.Errorf("saved search %q: %w", id, domain.ErrNotFound)
+	}
+	delete(r.s.savedSearches, id)
+	return nil
+}
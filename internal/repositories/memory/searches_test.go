@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestStore_SavedSearches(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_SavedSearches", "internal/repositories/memory")
+
+	store := NewStore()
+	ctx := t.Context()
+	searches := store.SavedSearches()
+
+	testhelpers.LogTestStep(logger, "act", "Saving two searches for one user and one for another")
+	var ids []string
+	for _, s := range []domain.SavedSearch{
+		{UserID: "user_1", Name: "Isolate", Query: "isolate"},
+		{UserID: "user_2", Name: "Cheap", MaxPricePerGram: 2},
+		{UserID: "user_1", Name: "Big tubs", MinSizeGrams: 2000, Notified: map[string]float64{"prod_a": 3199}},
+	} {
+		created, err := searches.CreateSavedSearch(ctx, s)
+		if err != nil {
+			t.Fatalf("CreateSavedSearch: %v", err)
+		}
+		ids = append(ids, created.ID)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Users see their own searches newest first; all are oldest first")
+	mine, _ := searches.UserSavedSearches(ctx, "user_1")
+	testhelpers.LogTestAssertion(logger, "user_1 searches", 2, len(mine))
+	if len(mine) != 2 || mine[0].ID != ids[2] || mine[1].ID != ids[0] {
+		t.Errorf("UserSavedSearches = %+v, want %s then %s", mine, ids[2], ids[0])
+	}
+	all, _ := searches.AllSavedSearches(ctx)
+	if len(all) != 3 || all[0].ID != ids[0] {
+		t.Errorf("AllSavedSearches = %+v, want 3 starting with %s", all, ids[0])
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Changing a returned search's map does not change the stored one")
+	got, err := searches.SavedSearch(ctx, ids[2])
+	if err != nil {
+		t.Fatalf("SavedSearch: %v", err)
+	}
+	got.Notified["prod_b"] = 2099
+	if again, _ := searches.SavedSearch(ctx, ids[2]); len(again.Notified) != 1 {
+		t.Errorf("Stored Notified = %v, want only prod_a", again.Notified)
+	}
+	if err := searches.SaveSavedSearch(ctx, *got); err != nil {
+		t.Fatalf("SaveSavedSearch: %v", err)
+	}
+	if again, _ := searches.SavedSearch(ctx, ids[2]); len(again.Notified) != 2 {
+		t.Errorf("Saved Notified = %v, want prod_a and prod_b", again.Notified)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Deleting a search")
+	if err := searches.DeleteSavedSearch(ctx, ids[0]); err != nil {
+		t.Fatalf("DeleteSavedSearch: %v", err)
+	}
+	if _, err := searches.SavedSearch(ctx, ids[0]); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("SavedSearch after delete error = %v, want ErrNotFound", err)
+	}
+	if err := searches.SaveSavedSearch(ctx, domain.SavedSearch{ID: ids[0]}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("SaveSavedSearch after delete error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_SavedSearches", true)
+}
@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// SearchLogs returns the Store as a SearchLogRepository.
+func (s *Store) SearchLogs() repositories.SearchLogRepository { return searchLogRepo{s} }
+
+type searchLogRepo struct{ s *Store }
+
+func (r searchLogRepo) RecordSearches(_ context.Context, logs []domain.SearchLog) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.searchLogs = append(r.s.searchLogs, logs...)
+	return nil
+}
+
+func (r searchLogRepo) RecordSearchClicks(_ context.Context, clicks []domain.SearchClick) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.searchClicks = append(r.s.searchClicks, clicks...)
+	return nil
+}
+
+func (r searchLogRepo) SearchesSince(_ context.Context, since time.Time) ([]domain.SearchLog, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var out []domain.SearchLog
+	for _, l := range r.s.searchLogs {
+		if !l.CreatedAt.Before(since) {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func (r searchLogRepo) SearchClicksSince(_ context.Context, since time.Time) ([]domain.SearchClick, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var out []domain.SearchClick
+	for _, c := range r.s.searchClicks {
+		if !c.CreatedAt.Before(since) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (r searchLogRepo) DeleteSearchLogsBefore(_ context.Context, cutoff time.Time) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	n := len(r.s.searchLogs) + len(r.s.searchClicks)
+	r.s.searchLogs = slices.DeleteFunc(r.s.searchLogs, func(l domain.SearchLog) bool { return l.CreatedAt.Before(cutoff) })
+	r.s.searchClicks = slices.DeleteFunc(r.s.searchClicks, func(c domain.SearchClick) bool { return c.CreatedAt.Before(cutoff) })
+	return n - len(r.s.searchLogs) - len(r.s.searchClicks), nil
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestStore_SearchLogs(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_SearchLogs", "internal/repositories/memory")
+
+	store := NewStore()
+	ctx := t.Context()
+	logs := store.SearchLogs()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	testhelpers.LogTestStep(logger, "act", "Recording an old and a recent search with a click each")
+	_ = logs.RecordSearches(ctx, []domain.SearchLog{
+		{ID: "s1", Query: "whey", Page: 1, Results: 3, CreatedAt: now.AddDate(0, 0, -100)},
+		{ID: "s2", Query: "casein", Page: 1, CreatedAt: now},
+	})
+	_ = logs.RecordSearchClicks(ctx, []domain.SearchClick{
+		{SearchID: "s1", ProductID: "p1", Position: 1, CreatedAt: now.AddDate(0, 0, -100)},
+		{SearchID: "s2", ProductID: "p2", Position: 4, CreatedAt: now},
+	})
+
+	testhelpers.LogTestStep(logger, "assert", "Reads start at since; purges drop what is older than the cutoff")
+	recent, _ := logs.SearchesSince(ctx, now.AddDate(0, 0, -30))
+	clicks, _ := logs.SearchClicksSince(ctx, now.AddDate(0, 0, -30))
+	if len(recent) != 1 || recent[0].ID != "s2" || len(clicks) != 1 || clicks[0].Position != 4 {
+		t.Errorf("Recent = %+v, clicks %+v", recent, clicks)
+	}
+	n, err := logs.DeleteSearchLogsBefore(ctx, now.AddDate(0, 0, -90))
+	testhelpers.LogTestAssertion(logger, "purged", 2, n)
+	if err != nil || n != 2 {
+		t.Errorf("DeleteSearchLogsBefore = %d, %v; want 2", n, err)
+	}
+	if all, _ := logs.SearchesSince(ctx, time.Time{}); len(all) != 1 {
+		t.Errorf("Searches after purge = %+v", all)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_SearchLogs", true)
+}
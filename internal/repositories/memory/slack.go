@@ -0,0 +1,33 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Slack returns the Store as a SlackRepository.
+func (s *Store) Slack() repositories.SlackRepository { return slackRepo{s} }
+
+type slackRepo struct{ s *Store }
+
+func (r slackRepo) SaveSlackWebhook(_ context.Context, w domain.SlackWebhook) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.slackWebhooks[w.UserID] = w
+	return nil
+}
+
+func (r slackRepo) SlackWebhook(_ context.Context, userID string) (*domain.SlackWebhook, error) {
+	return find(r.s, r.s.slackWebhooks, userID, "slack webhook")
+}
+
+func (r slackRepo) DeleteSlackWebhook(_ context.Context, userID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.slackWebhooks, userID)
+	return nil
+}
@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestStore_Slack(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_Slack", "internal/repositories/memory")
+
+	store := NewStore()
+	ctx := t.Context()
+	hooks := store.Slack()
+
+	testhelpers.LogTestStep(logger, "act", "Saving a webhook, then replacing it")
+	for _, team := range []string{"T0001", "T0002"} {
+		w := domain.SlackWebhook{UserID: "user_1", TeamID: team, URL: "https://hooks.slack.com/services/" + team + "/B0001/test-only-token", CreatedAt: time.Now()}
+		if err := hooks.SaveSlackWebhook(ctx, w); err != nil {
+			t.Fatalf("SaveSlackWebhook: %v", err)
+		}
+	}
+	w, err := hooks.SlackWebhook(ctx, "user_1")
+	testhelpers.LogTestAssertion(logger, "webhook", "T0002", w.TeamID)
+	if err != nil || w.TeamID != "T0002" {
+		t.Fatalf("SlackWebhook = %+v, %v; want the replacement", w, err)
+	}
+	if _, err := hooks.SlackWebhook(ctx, "user_2"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Other user's SlackWebhook error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Deleting it")
+	if err := hooks.DeleteSlackWebhook(ctx, "user_1"); err != nil {
+		t.Fatalf("DeleteSlackWebhook: %v", err)
+	}
+	if _, err := hooks.SlackWebhook(ctx, "user_1"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("SlackWebhook after delete error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_Slack", true)
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// SMS returns the Store as an SMSRepository.
+func (s *Store) SMS() repositories.SMSRepository { return smsRepo{s} }
+
+type smsRepo struct{ s *Store }
+
+func (r smsRepo) SaveSMSSubscription(_ context.Context, sub domain.SMSSubscription) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.smsSubscriptions[sub.UserID] = sub
+	return nil
+}
+
+func (r smsRepo) SMSSubscription(_ context.Context, userID string) (*domain.SMSSubscription, error) {
+	return find(r.s, r.s.smsSubscriptions, userID, "sms subscription")
+}
+
+func (r smsRepo) DeleteSMSSubscription(_ context.Context, userID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.smsSubscriptions, userID)
+	return nil
+}
+
+func (r smsRepo) RecordSMS(_ context.Context, m domain.SMSMessage) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.smsLog = append(r.s.smsLog, m)
+	return nil
+}
+
+func (r smsRepo) SMSSpend(_ context.Context, userID string, since time.Time) (int, float64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	sent, cost := 0, 0.0
+	for _, m := range r.s.smsLog {
+		if m.SentAt.Before(since) {
+			continue
+		}
+		cost += m.Cost
+		if m.UserID == userID {
+			sent++
+		}
+	}
+	return sent, cost, nil
+}
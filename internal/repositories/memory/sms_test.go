@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestStore_SMS(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_SMS", "internal/repositories/memory")
+
+	store := NewStore()
+	ctx := t.Context()
+	sms := store.SMS()
+	now := time.Now()
+
+	testhelpers.LogTestStep(logger, "act", "Saving a number, then replacing it")
+	for _, phone := range []string{"+919812345678", "+919876543210"} {
+		if err := sms.SaveSMSSubscription(ctx, domain.SMSSubscription{UserID: "user_1", Phone: phone, CreatedAt: now}); err != nil {
+			t.Fatalf("SaveSMSSubscription: %v", err)
+		}
+	}
+	sub, err := sms.SMSSubscription(ctx, "user_1")
+	if err != nil || sub.Phone != "+919876543210" {
+		t.Fatalf("SMSSubscription = %+v, %v", sub, err)
+	}
+	if err := sms.DeleteSMSSubscription(ctx, "user_1"); err != nil {
+		t.Fatalf("DeleteSMSSubscription: %v", err)
+	}
+	if _, err := sms.SMSSubscription(ctx, "user_1"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("SMSSubscription after delete error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Recording texts over two days")
+	for _, m := range []domain.SMSMessage{
+		{UserID: "user_1", Cost: 0.25, SentAt: now.Add(-30 * time.Hour)},
+		{UserID: "user_1", Cost: 0.25, SentAt: now.Add(-time.Hour)},
+		{UserID: "user_2", Cost: 0.5, SentAt: now.Add(-time.Minute)},
+	} {
+		if err := sms.RecordSMS(ctx, m); err != nil {
+			t.Fatalf("RecordSMS: %v", err)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Spend counts the user's texts and everyone's cost since the cutoff")
+	sent, cost, err := sms.SMSSpend(ctx, "user_1", now.Add(-24*time.Hour))
+	testhelpers.LogTestAssertion(logger, "user_1 sent", 1, sent)
+	if err != nil || sent != 1 || cost != 0.75 {
+		t.Errorf("SMSSpend = %d, %v, %v; want 1, 0.75", sent, cost, err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_SMS", true)
+}
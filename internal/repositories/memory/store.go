@@ -0,0 +1,792 @@
+// Package memory is an in-process implementation of the repository
+// interfaces, used for local development without a database and in tests.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Store holds the whole catalog in memory. It is safe for concurrent use.
+type Store struct {
+	mu        sync.RWMutex
+	txMu      sync.Mutex // serialises units of work, see tx.go
+	products  map[string]domain.Product
+	variants  map[string]domain.Variant
+	retailers map[string]domain.Retailer
+	listings  map[string]domain.Listing
+	prices    map[string][]domain.PricePoint // by listing ID, ascending
+	selectors map[string]domain.SelectorConfig
+	audit     []domain.AuditEntry    // append order
+	outbox    []domain.OutboxMessage // append order, see outbox.go
+	clicks    []domain.ClickEvent
+	nextID    int
+
+	// Accounts, see users.go.
+	users         map[string]domain.User
+	sessions      map[string]domain.Session           // by token hash
+	verifications map[string]domain.EmailVerification // by token hash
+	identities    map[string]string                   // provider + "\x00" + subject -> user ID
+	apiTokens     map[string]domain.APIToken          // by ID
+
+	// Alerts, their notifications and failed deliveries, delivery
+	// preferences and suppressed email addresses, see alerts.go.
+	alerts        map[string]domain.PriceAlert
+	notifications []domain.Notification // enqueue order
+	deliveries    map[string]domain.FailedDelivery
+	suppressions  map[string]domain.Suppression
+	preferences   map[string]domain.NotificationPreferences // by user ID
+
+	// Saved searches, see searches.go.
+	savedSearches map[string]domain.SavedSearch
+
+	// Filter presets, see presets.go.
+	presets map[string]domain.FilterPreset
+
+	// Search synonyms, see synonyms.go.
+	synonyms map[string]domain.Synonym
+
+	// Feature flags by name, see flags.go.
+	flags map[string]domain.Flag
+
+	// Search analytics, see searchlog.go.
+	searchLogs   []domain.SearchLog   // record order
+	searchClicks []domain.SearchClick // record order
+
+	// Telegram chats, see telegram.go.
+	telegramTokens map[string]domain.TelegramLinkToken // by token hash
+	telegramLinks  map[string]domain.TelegramLink      // by user ID
+
+	// Web Push subscriptions, see push.go.
+	pushSubscriptions map[string]domain.PushSubscription
+
+	// SMS numbers and sent texts, see sms.go.
+	smsSubscriptions map[string]domain.SMSSubscription // by user ID
+	smsLog           []domain.SMSMessage
+
+	// Discord webhooks, see discord.go.
+	discordWebhooks map[string]domain.DiscordWebhook // by user ID
+
+	// Slack webhooks, see slack.go.
+	slackWebhooks map[string]domain.SlackWebhook // by user ID
+
+	// Notification engagement, see engagement.go.
+	engagement    []domain.EngagementEvent // record order
+	engagementSet map[string]bool          // notification ID + "\x00" + channel + "\x00" + type
+
+	// Watchlists, see watchlist.go.
+	watchlist map[string]domain.WatchlistItem // user ID + "\x00" + product ID
+
+	// Data export and deletion requests, see privacy.go.
+	dataRequests map[string]domain.DataRequest
+
+	// Price change log and its consumers' cursors, see pricelog.go.
+	priceLog   []domain.PriceLogEntry // offset order, offset = index + 1
+	logCursors map[string]domain.PriceLogCursor
+
+	// Materialized views, see viewRepo.
+	comparisons map[string]domain.Comparison
+	deals       []domain.Deal // rank order
+}
+
+// NewStore creates an empty Store.
+func NewStore() *Store {
+	return &Store{
+		products:  make(map[string]domain.Product),
+		variants:  make(map[string]domain.Variant),
+		retailers: make(map[string]domain.Retailer),
+		listings:  make(map[string]domain.Listing),
+		prices:    make(map[string][]domain.PricePoint),
+		selectors: make(map[string]domain.SelectorConfig),
+
+		users:         make(map[string]domain.User),
+		sessions:      make(map[string]domain.Session),
+		verifications: make(map[string]domain.EmailVerification),
+		identities:    make(map[string]string),
+		apiTokens:     make(map[string]domain.APIToken),
+		alerts:        make(map[string]domain.PriceAlert),
+		savedSearches: make(map[string]domain.SavedSearch),
+		presets:       make(map[string]domain.FilterPreset),
+		synonyms:      make(map[string]domain.Synonym),
+		flags:         make(map[string]domain.Flag),
+		deliveries:    make(map[string]domain.FailedDelivery),
+		suppressions:  make(map[string]domain.Suppression),
+		preferences:   make(map[string]domain.NotificationPreferences),
+
+		telegramTokens: make(map[string]domain.TelegramLinkToken),
+		telegramLinks:  make(map[string]domain.TelegramLink),
+
+		pushSubscriptions: make(map[string]domain.PushSubscription),
+		smsSubscriptions:  make(map[string]domain.SMSSubscription),
+		discordWebhooks:   make(map[string]domain.DiscordWebhook),
+		slackWebhooks:     make(map[string]domain.SlackWebhook),
+		engagementSet:     make(map[string]bool),
+		dataRequests:      make(map[string]domain.DataRequest),
+		watchlist:         make(map[string]domain.WatchlistItem),
+
+		logCursors:  make(map[string]domain.PriceLogCursor),
+		comparisons: make(map[string]domain.Comparison),
+	}
+}
+
+// Products returns the Store as a ProductRepository.
+func (s *Store) Products() repositories.ProductRepository { return productRepo{s} }
+
+// Retailers returns the Store as a RetailerRepository.
+func (s *Store) Retailers() repositories.RetailerRepository { return retailerRepo{s} }
+
+// Listings returns the Store as a ListingRepository.
+func (s *Store) Listings() repositories.ListingRepository { return listingRepo{s} }
+
+// Prices returns the Store as a PriceRepository.
+func (s *Store) Prices() repositories.PriceRepository { return priceRepo{s} }
+
+// DailyPrices returns the Store as a DailyPriceRepository, rolling history
+// up as it is read.
+func (s *Store) DailyPrices() repositories.DailyPriceRepository { return priceRepo{s} }
+
+// CatalogAdmin returns the Store as a CatalogAdminRepository.
+func (s *Store) CatalogAdmin() repositories.CatalogAdminRepository { return adminRepo{s} }
+
+// Selectors returns the Store as a SelectorRepository.
+func (s *Store) Selectors() repositories.SelectorRepository { return selectorRepo{s} }
+
+// PriceWriter returns the Store as a PriceWriter.
+func (s *Store) PriceWriter() repositories.PriceWriter { return priceWriter{s} }
+
+// PriceIngester returns the Store as a PriceIngester.
+func (s *Store) PriceIngester() repositories.PriceIngester { return priceWriter{s} }
+
+// Audit returns the Store as an AuditRepository.
+func (s *Store) Audit() repositories.AuditRepository { return auditRepo{s} }
+
+// Stats returns the Store as a StatsRepository.
+func (s *Store) Stats() repositories.StatsRepository { return statsRepo{s} }
+
+// Clicks returns the Store as a ClickRepository.
+func (s *Store) Clicks() repositories.ClickRepository { return clickRepo{s} }
+
+// Views returns the Store as a ViewRepository.
+func (s *Store) Views() repositories.ViewRepository { return viewRepo{s} }
+
+// Ping reports whether the Store can serve reads. Taking the read lock
+// surfaces a writer stuck holding it as a failed readiness check.
+func (s *Store) Ping(ctx context.Context) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return ctx.Err()
+}
+
+// CountRows counts what the Store holds under the names of the tables that
+// hold it in SQL, leaving out tables it does not have. It satisfies
+// database.RowCounter.
+func (s *Store) CountRows(_ context.Context, tables []string) (map[string]int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]int64, len(tables))
+	for _, t := range tables {
+		switch t {
+		case "products":
+			out[t] = int64(len(s.products))
+		case "product_variants":
+			out[t] = int64(len(s.variants))
+		case "retailers":
+			out[t] = int64(len(s.retailers))
+		case "product_listings":
+			out[t] = int64(len(s.listings))
+		case "price_history":
+			var n int64
+			for _, points := range s.prices {
+				n += int64(len(points))
+			}
+			out[t] = n
+		case "price_change_log":
+			out[t] = int64(len(s.priceLog))
+		case "event_outbox":
+			out[t] = int64(len(s.outbox))
+		case "search_logs":
+			out[t] = int64(len(s.searchLogs))
+		case "audit_logs":
+			out[t] = int64(len(s.audit))
+		}
+	}
+	return out, nil
+}
+
+// PutProduct inserts or replaces a product, deriving its dietary
+// attributes from its name and description.
+func (s *Store) PutProduct(p domain.Product) {
+	p.Dietary = domain.ExtractDietary(p.Name + " " + p.Description)
+	p.Version = max(p.Version, 1)
+	_ = s.write(nil, func() error {
+		s.products[p.ID] = p
+		return nil
+	})
+}
+
+// saveProduct stores p if its version is the stored one; see
+// repositories.CatalogAdminRepository.
+func (s *Store) saveProduct(p domain.Product, events []domain.Event) error {
+	p.Dietary = domain.ExtractDietary(p.Name + " " + p.Description)
+	return s.write(events, func() error {
+		if err := checkVersion("product", p.ID, p.Version, s.products[p.ID].Version); err != nil {
+			return err
+		}
+		p.Version++
+		s.products[p.ID] = p
+		return nil
+	})
+}
+
+// PutVariant inserts or replaces a variant.
+func (s *Store) PutVariant(v domain.Variant) {
+	v.Version = max(v.Version, 1)
+	_ = s.write(nil, func() error {
+		s.variants[v.ID] = v
+		return nil
+	})
+}
+
+// saveVariant stores v if its version is the stored one.
+func (s *Store) saveVariant(v domain.Variant, events []domain.Event) error {
+	return s.write(events, func() error {
+		if err := checkVersion("variant", v.ID, v.Version, s.variants[v.ID].Version); err != nil {
+			return err
+		}
+		v.Version++
+		s.variants[v.ID] = v
+		return nil
+	})
+}
+
+// checkVersion reports a conflict unless a save of version saving replaces
+// version stored, which is 0 for a record not stored yet.
+func checkVersion(kind, id string, saving, stored int) error {
+	if saving != stored {
+		return fmt.Errorf("%s %q is at version %d, not %d: %w", kind, id, stored, saving, domain.ErrConflict)
+	}
+	return nil
+}
+
+// PutRetailer inserts or replaces a retailer.
+func (s *Store) PutRetailer(r domain.Retailer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retailers[r.ID] = r
+}
+
+// PutListing inserts or replaces a listing.
+func (s *Store) PutListing(l domain.Listing) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listings[l.ID] = l
+}
+
+// AddPricePoint appends an observation and updates the listing's current
+// price when the point is the newest one.
+func (s *Store) AddPricePoint(p domain.PricePoint) domain.PricePoint {
+	p, _ = s.addPricePoint(p, nil)
+	return p
+}
+
+func (s *Store) addPricePoint(p domain.PricePoint, events []domain.Event) (domain.PricePoint, error) {
+	err := s.write(events, func() error {
+		p = s.appendPricePoint(p)
+		return nil
+	})
+	return p, err
+}
+
+// appendPricePoint stores p and updates its listing. The caller holds the
+// write lock.
+func (s *Store) appendPricePoint(p domain.PricePoint) domain.PricePoint {
+	if p.ID == "" {
+		s.nextID++
+		p.ID = fmt.Sprintf("ph_%d", s.nextID)
+	}
+	points := append(s.prices[p.ListingID], p)
+	sort.SliceStable(points, func(i, j int) bool { return points[i].RecordedAt.Before(points[j].RecordedAt) })
+	s.prices[p.ListingID] = points
+
+	if l, ok := s.listings[p.ListingID]; ok && !p.RecordedAt.Before(l.LastScrapedAt) {
+		l.CurrentPrice = p.Price
+		l.InStock = p.InStock
+		l.LastScrapedAt = p.RecordedAt
+		if p.Currency != "" {
+			l.Currency = p.Currency
+		}
+		s.listings[l.ID] = l
+	}
+	return p
+}
+
+type productRepo struct{ s *Store }
+
+func (r productRepo) FindByID(_ context.Context, id string) (*domain.Product, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	p, ok := r.s.products[id]
+	if !ok || !p.Live() {
+		return nil, fmt.Errorf("product %q: %w", id, domain.ErrNotFound)
+	}
+	return &p, nil
+}
+
+func (r productRepo) List(_ context.Context, filter repositories.ProductFilter) ([]domain.Product, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := make([]domain.Product, 0, len(r.s.products))
+	for _, p := range r.s.products {
+		if !p.Live() {
+			continue
+		}
+		if filter.BrandID != "" && p.BrandID != filter.BrandID {
+			continue
+		}
+		if filter.CategoryID != "" && p.CategoryID != filter.CategoryID {
+			continue
+		}
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return paginate(out, filter.Offset, filter.Limit), nil
+}
+
+func (r productRepo) Variants(ctx context.Context, productID string) ([]domain.Variant, error) {
+	byProduct, err := r.VariantsByProducts(ctx, []string{productID})
+	return byProduct[productID], err
+}
+
+func (r productRepo) FindByIDs(_ context.Context, ids []string) (map[string]domain.Product, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := make(map[string]domain.Product, len(ids))
+	for _, id := range ids {
+		if p, ok := r.s.products[id]; ok && p.Live() {
+			out[id] = p
+		}
+	}
+	return out, nil
+}
+
+func (r productRepo) VariantsByProducts(_ context.Context, productIDs []string) (map[string][]domain.Variant, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	wanted := set(productIDs)
+	out := make(map[string][]domain.Variant, len(productIDs))
+	for _, v := range r.s.variants {
+		if wanted[v.ProductID] && v.Live() {
+			out[v.ProductID] = append(out[v.ProductID], v)
+		}
+	}
+	for _, variants := range out {
+		sort.Slice(variants, func(i, j int) bool { return variants[i].ID < variants[j].ID })
+	}
+	return out, nil
+}
+
+type retailerRepo struct{ s *Store }
+
+func (r retailerRepo) FindByID(_ context.Context, id string) (*domain.Retailer, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	ret, ok := r.s.retailers[id]
+	if !ok {
+		return nil, fmt.Errorf("retailer %q: %w", id, domain.ErrNotFound)
+	}
+	return &ret, nil
+}
+
+func (r retailerRepo) List(_ context.Context) ([]domain.Retailer, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := make([]domain.Retailer, 0, len(r.s.retailers))
+	for _, ret := range r.s.retailers {
+		out = append(out, ret)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func (r retailerRepo) FindByIDs(_ context.Context, ids []string) (map[string]domain.Retailer, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := make(map[string]domain.Retailer, len(ids))
+	for _, id := range ids {
+		if ret, ok := r.s.retailers[id]; ok {
+			out[id] = ret
+		}
+	}
+	return out, nil
+}
+
+type listingRepo struct{ s *Store }
+
+func (r listingRepo) ByProduct(ctx context.Context, productID string) ([]domain.Listing, error) {
+	byProduct, err := r.ByProducts(ctx, []string{productID})
+	return byProduct[productID], err
+}
+
+func (r listingRepo) ByProducts(_ context.Context, productIDs []string) (map[string][]domain.Listing, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	wanted := set(productIDs)
+	out := make(map[string][]domain.Listing, len(productIDs))
+	for _, l := range r.s.listings {
+		v, ok := r.s.variants[l.VariantID]
+		if !ok || !wanted[v.ProductID] || !v.Live() || !l.IsActive {
+			continue
+		}
+		out[v.ProductID] = append(out[v.ProductID], l)
+	}
+	for _, listings := range out {
+		sort.Slice(listings, func(i, j int) bool { return listings[i].ID < listings[j].ID })
+	}
+	return out, nil
+}
+
+type priceRepo struct{ s *Store }
+
+func (r priceRepo) History(_ context.Context, listingIDs []string, since time.Time) ([]domain.PricePoint, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var out []domain.PricePoint
+	for _, id := range listingIDs {
+		for _, p := range r.s.prices[id] {
+			if !p.RecordedAt.Before(since) {
+				out = append(out, p)
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].RecordedAt.Before(out[j].RecordedAt) })
+	return out, nil
+}
+
+func (r priceRepo) DailyHistory(ctx context.Context, listingIDs []string, since time.Time) ([]domain.DailyPrice, error) {
+	points, err := r.History(ctx, listingIDs, since)
+	if err != nil {
+		return nil, err
+	}
+	return domain.RollUpDaily(points), nil
+}
+
+type adminRepo struct{ s *Store }
+
+func (r adminRepo) Product(_ context.Context, id string) (*domain.Product, error) {
+	return find(r.s, r.s.products, id, "product")
+}
+
+func (r adminRepo) SaveProduct(_ context.Context, p domain.Product, events ...domain.Event) error {
+	return r.s.saveProduct(p, events)
+}
+
+func (r adminRepo) Variant(_ context.Context, id string) (*domain.Variant, error) {
+	return find(r.s, r.s.variants, id, "variant")
+}
+
+func (r adminRepo) SaveVariant(_ context.Context, v domain.Variant, events ...domain.Event) error {
+	return r.s.saveVariant(v, events)
+}
+
+func (r adminRepo) Retailer(_ context.Context, id string) (*domain.Retailer, error) {
+	return find(r.s, r.s.retailers, id, "retailer")
+}
+
+func (r adminRepo) SaveRetailer(_ context.Context, ret domain.Retailer) error {
+	r.s.PutRetailer(ret)
+	return nil
+}
+
+func (r adminRepo) Listing(_ context.Context, id string) (*domain.Listing, error) {
+	return find(r.s, r.s.listings, id, "listing")
+}
+
+func find[T any](s *Store, items map[string]T, id, kind string) (*T, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v, ok := items[id]
+	if !ok {
+		return nil, fmt.Errorf("%s %q: %w", kind, id, domain.ErrNotFound)
+	}
+	return &v, nil
+}
+
+type selectorRepo struct{ s *Store }
+
+func (r selectorRepo) Selectors(_ context.Context, retailerID string) (*domain.SelectorConfig, error) {
+	return find(r.s, r.s.selectors, retailerID, "selector config")
+}
+
+func (r selectorRepo) SaveSelectors(_ context.Context, cfg domain.SelectorConfig) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.selectors[cfg.RetailerID] = cfg
+	return nil
+}
+
+type priceWriter struct{ s *Store }
+
+func (w priceWriter) RecordPrice(_ context.Context, p domain.PricePoint, events ...domain.Event) (domain.PricePoint, error) {
+	return w.s.addPricePoint(p, events)
+}
+
+func (w priceWriter) Listings(_ context.Context, ids []string) (map[string]repositories.IngestListing, error) {
+	w.s.mu.RLock()
+	defer w.s.mu.RUnlock()
+
+	out := make(map[string]repositories.IngestListing, len(ids))
+	for _, id := range ids {
+		if l, ok := w.s.listings[id]; ok {
+			out[id] = repositories.IngestListing{Listing: l, ProductID: w.s.variants[l.VariantID].ProductID}
+		}
+	}
+	return out, nil
+}
+
+func (w priceWriter) IngestPrices(_ context.Context, points []domain.PricePoint, events ...domain.Event) error {
+	return w.s.write(events, func() error {
+		for _, p := range points {
+			w.s.appendPricePoint(p)
+		}
+		return nil
+	})
+}
+
+type auditRepo struct{ s *Store }
+
+func (r auditRepo) Append(_ context.Context, e domain.AuditEntry) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if e.ID == "" {
+		r.s.nextID++
+		e.ID = fmt.Sprintf("audit_%d", r.s.nextID)
+	}
+	r.s.audit = append(r.s.audit, e)
+	return nil
+}
+
+func (r auditRepo) List(_ context.Context, filter repositories.AuditFilter) ([]domain.AuditEntry, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var out []domain.AuditEntry
+	for i := len(r.s.audit) - 1; i >= 0; i-- {
+		e := r.s.audit[i]
+		if filter.ActorID != "" && e.ActorID != filter.ActorID {
+			continue
+		}
+		if filter.Action != "" && e.Action != filter.Action {
+			continue
+		}
+		if filter.ResourceType != "" && e.ResourceType != filter.ResourceType {
+			continue
+		}
+		if filter.ResourceID != "" && e.ResourceID != filter.ResourceID {
+			continue
+		}
+		if !filter.Since.IsZero() && e.CreatedAt.Before(filter.Since) ||
+			!filter.Until.IsZero() && !e.CreatedAt.Before(filter.Until) {
+			continue
+		}
+		out = append(out, e)
+	}
+	return paginate(out, filter.Offset, filter.Limit), nil
+}
+
+type statsRepo struct{ s *Store }
+
+func (r statsRepo) CatalogStats(_ context.Context) (domain.CatalogStats, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var stats domain.CatalogStats
+	for _, p := range r.s.products {
+		if p.Live() {
+			stats.Products++
+		}
+	}
+	for _, ret := range r.s.retailers {
+		if ret.IsActive {
+			stats.Retailers++
+		}
+	}
+	for _, l := range r.s.listings {
+		if l.IsActive {
+			stats.Listings++
+		}
+	}
+	var last time.Time
+	for _, points := range r.s.prices {
+		stats.PricePoints += int64(len(points))
+		if n := len(points); n > 0 && points[n-1].RecordedAt.After(last) {
+			last = points[n-1].RecordedAt
+		}
+	}
+	if !last.IsZero() {
+		stats.LastUpdatedAt = &last
+	}
+	return stats, nil
+}
+
+type clickRepo struct{ s *Store }
+
+func (r clickRepo) RecordClicks(_ context.Context, events []domain.ClickEvent) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, e := range events {
+		if e.ID == "" {
+			r.s.nextID++
+			e.ID = fmt.Sprintf("click_%d", r.s.nextID)
+		}
+		r.s.clicks = append(r.s.clicks, e)
+	}
+	return nil
+}
+
+func (r clickRepo) CountClicks(_ context.Context, filter repositories.ClickFilter) (int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	n := 0
+	for _, e := range r.s.clicks {
+		if (filter.ProductID == "" || e.ProductID == filter.ProductID) &&
+			(filter.RetailerID == "" || e.RetailerID == filter.RetailerID) &&
+			(filter.Referral == "" || e.Referral == filter.Referral) &&
+			!e.CreatedAt.Before(filter.Since) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// viewRepo keeps the deal rows sorted on write, the in-memory equivalent of
+// an index on (score DESC, price_per_gram ASC), so TopDeals is a prefix scan.
+type viewRepo struct{ s *Store }
+
+func (r viewRepo) Comparison(_ context.Context, productID string) (*domain.Comparison, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	c, ok := r.s.comparisons[productID]
+	if !ok {
+		return nil, fmt.Errorf("comparison %q: %w", productID, domain.ErrNotFound)
+	}
+	c = cloneComparison(c)
+	return &c, nil
+}
+
+func (r viewRepo) Comparisons(_ context.Context, productIDs []string) (map[string]domain.Comparison, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := make(map[string]domain.Comparison, len(productIDs))
+	for _, id := range productIDs {
+		if c, ok := r.s.comparisons[id]; ok {
+			out[id] = cloneComparison(c)
+		}
+	}
+	return out, nil
+}
+
+func (r viewRepo) TopDeals(_ context.Context, filter repositories.DealViewFilter) ([]domain.Deal, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := make([]domain.Deal, 0)
+	for _, d := range r.s.deals {
+		if d.Score < filter.MinScore {
+			break
+		}
+		if filter.CategoryID != "" && d.Product.CategoryID != filter.CategoryID {
+			continue
+		}
+		out = append(out, d)
+		if filter.Limit > 0 && len(out) == filter.Limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (r viewRepo) SaveProductView(_ context.Context, c domain.Comparison, deal *domain.Deal) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.comparisons[c.Product.ID] = cloneComparison(c)
+	r.s.removeDeal(c.Product.ID)
+	if deal != nil {
+		d := *deal
+		i := sort.Search(len(r.s.deals), func(i int) bool { return dealRanksBefore(d, r.s.deals[i]) })
+		r.s.deals = slices.Insert(r.s.deals, i, d)
+	}
+	return nil
+}
+
+func (r viewRepo) DeleteProductView(_ context.Context, productID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.comparisons, productID)
+	r.s.removeDeal(productID)
+	return nil
+}
+
+func (s *Store) removeDeal(productID string) {
+	s.deals = slices.DeleteFunc(s.deals, func(d domain.Deal) bool { return d.Product.ID == productID })
+}
+
+func dealRanksBefore(a, b domain.Deal) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	return a.Offer.PricePerGramProtein < b.Offer.PricePerGramProtein
+}
+
+// cloneComparison copies the offers so callers can localize them without
+// touching the stored row.
+func cloneComparison(c domain.Comparison) domain.Comparison {
+	c.Prices = slices.Clone(c.Prices)
+	if c.BestDeal != nil {
+		best := *c.BestDeal
+		c.BestDeal = &best
+	}
+	return c
+}
+
+func set(ids []string) map[string]bool {
+	out := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		out[id] = true
+	}
+	return out
+}
+
+func paginate[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,263 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestStore_Catalog(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_Catalog", "internal/repositories/memory")
+
+	now := time.Now()
+	store := NewStore()
+	testhelpers.SeedCatalog(store, now)
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Reading products")
+	p, err := store.Products().FindByID(ctx, testhelpers.FixtureProductID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if p.Name != "Gold Standard 100% Whey" {
+		t.Errorf("Name = %q", p.Name)
+	}
+	if _, err := store.Products().FindByID(ctx, "missing"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("FindByID(missing) error = %v, want ErrNotFound", err)
+	}
+
+	all, _ := store.Products().List(ctx, repositories.ProductFilter{})
+	if len(all) != 2 {
+		t.Errorf("List returned %d products, want 2", len(all))
+	}
+	page, _ := store.Products().List(ctx, repositories.ProductFilter{Limit: 1, Offset: 1})
+	if len(page) != 1 || page[0].ID != all[1].ID {
+		t.Errorf("Paginated list = %+v", page)
+	}
+	byBrand, _ := store.Products().List(ctx, repositories.ProductFilter{BrandID: "muscleblaze"})
+	if len(byBrand) != 1 || byBrand[0].ID != testhelpers.FixtureSecondProductID {
+		t.Errorf("Brand filter = %+v", byBrand)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Reading listings")
+	listings, _ := store.Listings().ByProduct(ctx, testhelpers.FixtureProductID)
+	testhelpers.LogTestAssertion(logger, "listing count", 3, len(listings))
+	if len(listings) != 3 {
+		t.Fatalf("ByProduct returned %d listings, want 3", len(listings))
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_Catalog", true)
+}
+
+func TestStore_AddPricePointUpdatesListing(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_AddPricePointUpdatesListing", "internal/repositories/memory")
+
+	now := time.Now()
+	store := NewStore()
+	testhelpers.SeedCatalog(store, now)
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Recording a newer and an older observation")
+	store.AddPricePoint(domain.PricePoint{ListingID: testhelpers.FixtureListingAmazon, Price: 2999, InStock: true, RecordedAt: now})
+	store.AddPricePoint(domain.PricePoint{ListingID: testhelpers.FixtureListingAmazon, Price: 9999, InStock: true, RecordedAt: now.AddDate(0, 0, -30)})
+
+	listings, _ := store.Listings().ByProduct(ctx, testhelpers.FixtureProductID)
+	for _, l := range listings {
+		if l.ID == testhelpers.FixtureListingAmazon && l.CurrentPrice != 2999 {
+			t.Errorf("CurrentPrice = %v, want 2999 (older points must not overwrite)", l.CurrentPrice)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "History is ordered and filtered by time")
+	points, _ := store.Prices().History(ctx, []string{testhelpers.FixtureListingAmazon}, now.AddDate(0, 0, -2))
+	if len(points) != 3 {
+		t.Fatalf("History returned %d points, want 3", len(points))
+	}
+	for i := 1; i < len(points); i++ {
+		if points[i].RecordedAt.Before(points[i-1].RecordedAt) {
+			t.Error("History must be ordered by RecordedAt")
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_AddPricePointUpdatesListing", true)
+}
+
+func TestStore_AdminAndAudit(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_AdminAndAudit", "internal/repositories/memory")
+
+	store := NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	ctx := t.Context()
+	admin := store.CatalogAdmin()
+
+	testhelpers.LogTestStep(logger, "act", "Deactivating a product through the admin repository")
+	p, err := admin.Product(ctx, testhelpers.FixtureProductID)
+	if err != nil {
+		t.Fatalf("Product failed: %v", err)
+	}
+	p.IsActive = false
+	if err := admin.SaveProduct(ctx, *p); err != nil {
+		t.Fatalf("SaveProduct failed: %v", err)
+	}
+	if err := admin.SaveProduct(ctx, *p); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Saving version %d again: error = %v, want ErrConflict", p.Version, err)
+	}
+	if saved, _ := admin.Product(ctx, testhelpers.FixtureProductID); saved.Version != p.Version+1 {
+		t.Errorf("Version = %d, want %d", saved.Version, p.Version+1)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Inactive product hidden publicly but visible to admins")
+	if _, err := store.Products().FindByID(ctx, testhelpers.FixtureProductID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Public Get error = %v, want ErrNotFound", err)
+	}
+	if _, err := admin.Product(ctx, testhelpers.FixtureProductID); err != nil {
+		t.Errorf("Admin Product error = %v, want nil", err)
+	}
+	if _, err := admin.Listing(ctx, "lst_missing"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Missing listing error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Appending audit entries")
+	audit := store.Audit()
+	for _, e := range []domain.AuditEntry{
+		{ActorID: "alice", Action: "update_product", ResourceType: "product", ResourceID: "p1"},
+		{ActorID: "bob", Action: "update_retailer", ResourceType: "retailer", ResourceID: "amazon"},
+		{ActorID: "alice", Action: "delete_product", ResourceType: "product", ResourceID: "p1"},
+	} {
+		if err := audit.Append(ctx, e); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Entries are filtered and newest first")
+	entries, _ := audit.List(ctx, repositories.AuditFilter{ActorID: "alice", Limit: 10})
+	testhelpers.LogTestAssertion(logger, "alice entries", 2, len(entries))
+	if len(entries) != 2 || entries[0].Action != "delete_product" || entries[0].ID == "" {
+		t.Errorf("Entries = %+v", entries)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_AdminAndAudit", true)
+}
+
+func TestStore_Views(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_Views", "internal/repositories/memory")
+
+	store := NewStore()
+	views := store.Views()
+	ctx := t.Context()
+	deal := func(id, category string, score, ppg float64) *domain.Deal {
+		return &domain.Deal{
+			Product: domain.Product{ID: id, CategoryID: category},
+			Offer:   domain.Offer{PricePerGramProtein: ppg},
+			Score:   score,
+		}
+	}
+	save := func(d *domain.Deal) {
+		t.Helper()
+		c := domain.Comparison{Product: d.Product, Prices: []domain.Offer{{Price: 100}}}
+		if err := views.SaveProductView(ctx, c, d); err != nil {
+			t.Fatalf("SaveProductView failed: %v", err)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "arrange", "Saving deals out of rank order")
+	save(deal("a", "whey", 20, 2))
+	save(deal("b", "isolate", 40, 3))
+	save(deal("c", "whey", 20, 1))
+	save(deal("d", "whey", 5, 1))
+
+	tests := []struct {
+		name   string
+		filter repositories.DealViewFilter
+		want   string
+	}{
+		{"All in rank order", repositories.DealViewFilter{}, "bcad"},
+		{"Limit", repositories.DealViewFilter{Limit: 2}, "bc"},
+		{"Category", repositories.DealViewFilter{CategoryID: "whey"}, "cad"},
+		{"Minimum score", repositories.DealViewFilter{MinScore: 20}, "bca"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deals, err := views.TopDeals(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("TopDeals failed: %v", err)
+			}
+			got := ""
+			for _, d := range deals {
+				got += d.Product.ID
+			}
+			testhelpers.LogTestAssertion(logger, tt.name, tt.want, got)
+			if got != tt.want {
+				t.Errorf("TopDeals = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Re-scoring one product and dropping another's deal")
+	save(deal("d", "whey", 50, 1))
+	if err := views.SaveProductView(ctx, domain.Comparison{Product: domain.Product{ID: "b"}}, nil); err != nil {
+		t.Fatalf("SaveProductView failed: %v", err)
+	}
+	_ = views.DeleteProductView(ctx, "c")
+	deals, _ := views.TopDeals(ctx, repositories.DealViewFilter{})
+	if len(deals) != 2 || deals[0].Product.ID != "d" || deals[1].Product.ID != "a" {
+		t.Errorf("Deals after updates = %+v", deals)
+	}
+	if _, err := views.Comparison(ctx, "c"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Deleted comparison error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Returned comparisons are copies")
+	c, _ := views.Comparison(ctx, "a")
+	c.Prices[0].Price = 1
+	again, _ := views.Comparison(ctx, "a")
+	if again.Prices[0].Price != 100 {
+		t.Errorf("Stored offer changed through a returned copy")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_Views", true)
+}
+
+func TestStore_BatchLookups(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_BatchLookups", "internal/repositories/memory")
+
+	store := NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	ctx := t.Context()
+	ids := []string{testhelpers.FixtureProductID, testhelpers.FixtureSecondProductID, "missing"}
+
+	testhelpers.LogTestStep(logger, "act", "Looking up two products and an unknown ID at once")
+	products, _ := store.Products().FindByIDs(ctx, ids)
+	variants, _ := store.Products().VariantsByProducts(ctx, ids)
+	listings, _ := store.Listings().ByProducts(ctx, ids)
+	retailers, _ := store.Retailers().FindByIDs(ctx, []string{"amazon", "missing"})
+
+	testhelpers.LogTestStep(logger, "assert", "Results match the single lookups and omit unknown IDs")
+	testhelpers.LogTestAssertion(logger, "products", 2, len(products))
+	if len(products) != 2 || products["missing"].ID != "" {
+		t.Errorf("FindByIDs = %v", products)
+	}
+	for _, id := range ids[:2] {
+		single, _ := store.Products().Variants(ctx, id)
+		if len(variants[id]) != len(single) {
+			t.Errorf("VariantsByProducts[%s] = %d variants, Variants = %d", id, len(variants[id]), len(single))
+		}
+	}
+	if len(listings[testhelpers.FixtureProductID]) != 3 || len(listings["missing"]) != 0 {
+		t.Errorf("ByProducts = %v", listings)
+	}
+	if len(retailers) != 1 || retailers["amazon"].ID != "amazon" {
+		t.Errorf("Retailers FindByIDs = %v", retailers)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_BatchLookups", true)
+}
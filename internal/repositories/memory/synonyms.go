@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Synonyms returns the Store as a SynonymRepository.
+func (s *Store) Synonyms() repositories.SynonymRepository { return synonymRepo{s} }
+
+type synonymRepo struct{ s *Store }
+
+func (r synonymRepo) CreateSynonym(_ context.Context, syn domain.Synonym, events ...domain.Event) (domain.Synonym, error) {
+	syn.Terms = slices.Clone(syn.Terms)
+	err := r.s.write(events, func() error {
+		r.s.nextID++
+		syn.ID = fmt.Sprintf("syn_%d", r.s.nextID)
+		r.s.synonyms[syn.ID] = syn
+		return nil
+	})
+	return syn, err
+}
+
+func (r synonymRepo) Synonym(_ context.Context, id string) (*domain.Synonym, error) {
+	syn, err := find(r.s, r.s.synonyms, id, "synonym")
+	if err != nil {
+		return nil, err
+	}
+	syn.Terms = slices.Clone(syn.Terms)
+	return syn, nil
+}
+
+func (r synonymRepo) Synonyms(_ context.Context) ([]domain.Synonym, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := make([]domain.Synonym, 0, len(r.s.synonyms))
+	for _, syn := range r.s.synonyms {
+		syn.Terms = slices.Clone(syn.Terms)
+		out = append(out, syn)
+	}
+	slices.SortFunc(out, func(a, b domain.Synonym) int { return compareIDs(a.ID, b.ID) })
+	return out, nil
+}
+
+func (r synonymRepo) SaveSynonym(_ context.Context, syn domain.Synonym, events ...domain.Event) error {
+	syn.Terms = slices.Clone(syn.Terms)
+	return r.s.write(events, func() error {
+		if _, ok := r.s.synonyms[syn.ID]; !ok {
+			return fmt.Errorf("synonym %q: %w", syn.ID, domain.ErrNotFound)
+		}
+		r.s.synonyms[syn.ID] = syn
+		return nil
+	})
+}
+
+func (r synonymRepo) DeleteSynonym(_ context.Context, id string, events ...domain.Event) error {
+	return r.s.write(events, func() error {
+		if _, ok := r.s.synonyms[id]; !ok {
+			return fmt.Errorf("synonym %q: %w", id, domain.ErrNotFound)
+		}
+		delete(r.s.synonyms, id)
+		return nil
+	})
+}
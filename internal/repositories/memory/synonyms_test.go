@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestStore_Synonyms(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_Synonyms", "internal/repositories/memory")
+
+	store := NewStore()
+	ctx := t.Context()
+	synonyms := store.Synonyms()
+
+	testhelpers.LogTestStep(logger, "act", "Creating two groups and editing a returned one")
+	on, _ := synonyms.CreateSynonym(ctx, domain.Synonym{Terms: []string{"ON", "Optimum Nutrition"}})
+	wpi, _ := synonyms.CreateSynonym(ctx, domain.Synonym{Terms: []string{"WPI", "whey protein isolate"}})
+	got, err := synonyms.Synonym(ctx, on.ID)
+	if err != nil {
+		t.Fatalf("Synonym: %v", err)
+	}
+	got.Terms[0] = "O.N."
+
+	testhelpers.LogTestStep(logger, "assert", "Groups list oldest first and are copied out")
+	all, _ := synonyms.Synonyms(ctx)
+	testhelpers.LogTestAssertion(logger, "groups", 2, len(all))
+	if len(all) != 2 || all[0].ID != on.ID || all[1].ID != wpi.ID || all[0].Terms[0] != "ON" {
+		t.Errorf("Synonyms = %+v", all)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Saving and deleting")
+	if err := synonyms.SaveSynonym(ctx, *got); err != nil {
+		t.Fatalf("SaveSynonym: %v", err)
+	}
+	if again, _ := synonyms.Synonym(ctx, on.ID); again.Terms[0] != "O.N." {
+		t.Errorf("Saved terms = %q", again.Terms)
+	}
+	if err := synonyms.DeleteSynonym(ctx, wpi.ID); err != nil {
+		t.Fatalf("DeleteSynonym: %v", err)
+	}
+	if err := synonyms.DeleteSynonym(ctx, wpi.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Second delete err = %v, want ErrNotFound", err)
+	}
+	if err := synonyms.SaveSynonym(ctx, domain.Synonym{ID: "syn_missing"}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Save missing err = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_Synonyms", true)
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Telegram returns the Store as a TelegramRepository.
+func (s *Store) Telegram() repositories.TelegramRepository { return telegramRepo{s} }
+
+type telegramRepo struct{ s *Store }
+
+func (r telegramRepo) CreateLinkToken(_ context.Context, t domain.TelegramLinkToken) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.telegramTokens[t.TokenHash] = t
+	return nil
+}
+
+func (r telegramRepo) ConsumeLinkToken(_ context.Context, tokenHash string) (*domain.TelegramLinkToken, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	t, ok := r.s.telegramTokens[tokenHash]
+	if !ok {
+		return nil, fmt.Errorf("telegram link token: %w", domain.ErrNotFound)
+	}
+	delete(r.s.telegramTokens, tokenHash)
+	return &t, nil
+}
+
+func (r telegramRepo) LinkChat(_ context.Context, l domain.TelegramLink) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for userID, existing := range r.s.telegramLinks {
+		if existing.ChatID == l.ChatID {
+			delete(r.s.telegramLinks, userID)
+		}
+	}
+	r.s.telegramLinks[l.UserID] = l
+	return nil
+}
+
+func (r telegramRepo) UserChat(_ context.Context, userID string) (*domain.TelegramLink, error) {
+	return find(r.s, r.s.telegramLinks, userID, "telegram link")
+}
+
+func (r telegramRepo) ChatLink(_ context.Context, chatID int64) (*domain.TelegramLink, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, l := range r.s.telegramLinks {
+		if l.ChatID == chatID {
+			return &l, nil
+		}
+	}
+	return nil, fmt.Errorf("telegram chat %d: %w", chatID, domain.ErrNotFound)
+}
+
+func (r telegramRepo) UnlinkUser(_ context.Context, userID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.telegramLinks, userID)
+	return nil
+}
@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestStore_Telegram(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_Telegram", "internal/repositories/memory")
+
+	store := NewStore()
+	ctx := t.Context()
+	tg := store.Telegram()
+	now := time.Now()
+
+	testhelpers.LogTestStep(logger, "act", "Consuming a link token twice")
+	if err := tg.CreateLinkToken(ctx, domain.TelegramLinkToken{TokenHash: "hash_1", UserID: "user_1", ExpiresAt: now.Add(time.Minute)}); err != nil {
+		t.Fatalf("CreateLinkToken: %v", err)
+	}
+	if tok, err := tg.ConsumeLinkToken(ctx, "hash_1"); err != nil || tok.UserID != "user_1" {
+		t.Fatalf("ConsumeLinkToken = %+v, %v", tok, err)
+	}
+	if _, err := tg.ConsumeLinkToken(ctx, "hash_1"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Second ConsumeLinkToken error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Linking one chat to two users in turn")
+	if err := tg.LinkChat(ctx, domain.TelegramLink{UserID: "user_1", ChatID: 42, LinkedAt: now}); err != nil {
+		t.Fatalf("LinkChat: %v", err)
+	}
+	if err := tg.LinkChat(ctx, domain.TelegramLink{UserID: "user_2", ChatID: 42, LinkedAt: now}); err != nil {
+		t.Fatalf("LinkChat: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The chat belongs to the latest user only")
+	l, err := tg.ChatLink(ctx, 42)
+	testhelpers.LogTestAssertion(logger, "chat owner", "user_2", l.UserID)
+	if err != nil || l.UserID != "user_2" {
+		t.Errorf("ChatLink = %+v, %v, want user_2", l, err)
+	}
+	if _, err := tg.UserChat(ctx, "user_1"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Previous user's link error = %v, want ErrNotFound", err)
+	}
+	if err := tg.UnlinkUser(ctx, "user_2"); err != nil {
+		t.Fatalf("UnlinkUser: %v", err)
+	}
+	if _, err := tg.ChatLink(ctx, 42); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Unlinked chat error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_Telegram", true)
+}
@@ -0,0 +1,26 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Transactor returns a Transactor for the Store. Units of work run one at a
+// time, so a read-check-write sequence in one is not interleaved with
+// another's, but the Store keeps no undo log: writes a failed unit made
+// before it failed stay.
+func (s *Store) Transactor() repositories.Transactor { return transactor{s} }
+
+type unitKey struct{}
+
+type transactor struct{ s *Store }
+
+func (t transactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if owner, _ := ctx.Value(unitKey{}).(*Store); owner == t.s {
+		return fn(ctx)
+	}
+	t.s.txMu.Lock()
+	defer t.s.txMu.Unlock()
+	return fn(context.WithValue(ctx, unitKey{}, t.s))
+}
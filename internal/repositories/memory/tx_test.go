@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestStore_Transactor(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_Transactor", "internal/repositories/memory")
+
+	store := NewStore()
+	tx := store.Transactor()
+	catalog := store.CatalogAdmin()
+	_ = catalog.SaveProduct(t.Context(), domain.Product{ID: "prod_1", Name: "Gold Standard Whey"})
+
+	testhelpers.LogTestStep(logger, "act", "Renaming a product in many units of work at once, each reading then writing")
+	const units = 20
+	var wg sync.WaitGroup
+	for range units {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := tx.InTx(t.Context(), func(ctx context.Context) error {
+				p, err := catalog.Product(ctx, "prod_1")
+				if err != nil {
+					return err
+				}
+				// A nested unit joins this one instead of waiting for it.
+				return tx.InTx(ctx, func(ctx context.Context) error {
+					p.Name += "!"
+					return catalog.SaveProduct(ctx, *p)
+				})
+			})
+			if err != nil {
+				t.Errorf("InTx failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	testhelpers.LogTestStep(logger, "assert", "No unit read a name another was about to replace")
+	p, _ := catalog.Product(t.Context(), "prod_1")
+	want := "Gold Standard Whey" + strings.Repeat("!", units)
+	testhelpers.LogTestAssertion(logger, "name", want, p.Name)
+	if p.Name != want {
+		t.Errorf("Name = %q, want %q", p.Name, want)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_Transactor", true)
+}
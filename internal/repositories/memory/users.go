@@ -0,0 +1,225 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Users returns the Store as a UserRepository.
+func (s *Store) Users() repositories.UserRepository { return userRepo{s} }
+
+// Sessions returns the Store as a SessionRepository.
+func (s *Store) Sessions() repositories.SessionRepository { return sessionRepo{s} }
+
+// Verifications returns the Store as a VerificationRepository.
+func (s *Store) Verifications() repositories.VerificationRepository { return verificationRepo{s} }
+
+// Identities returns the Store as an IdentityRepository.
+func (s *Store) Identities() repositories.IdentityRepository { return identityRepo{s} }
+
+// APITokens returns the Store as an APITokenRepository.
+func (s *Store) APITokens() repositories.APITokenRepository { return apiTokenRepo{s} }
+
+type userRepo struct{ s *Store }
+
+func (r userRepo) CreateUser(_ context.Context, u domain.User) (domain.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, existing := range r.s.users {
+		if existing.Email == u.Email {
+			return domain.User{}, fmt.Errorf("email is already registered: %w", domain.ErrConflict)
+		}
+	}
+	r.s.nextID++
+	u.ID = fmt.Sprintf("user_%d", r.s.nextID)
+	r.s.users[u.ID] = u
+	return u, nil
+}
+
+func (r userRepo) UserByID(_ context.Context, id string) (*domain.User, error) {
+	return find(r.s, r.s.users, id, "user")
+}
+
+func (r userRepo) UserByEmail(_ context.Context, email string) (*domain.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, u := range r.s.users {
+		if u.Email == email {
+			return &u, nil
+		}
+	}
+	// The address is personal data, so it stays out of the error.
+	return nil, fmt.Errorf("user by email: %w", domain.ErrNotFound)
+}
+
+func (r userRepo) SaveUser(_ context.Context, u domain.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.users[u.ID]; !ok {
+		return fmt.Errorf("user %q: %w", u.ID, domain.ErrNotFound)
+	}
+	for _, existing := range r.s.users {
+		if existing.ID != u.ID && existing.Email == u.Email {
+			return fmt.Errorf("email is already registered: %w", domain.ErrConflict)
+		}
+	}
+	r.s.users[u.ID] = u
+	return nil
+}
+
+type sessionRepo struct{ s *Store }
+
+func (r sessionRepo) CreateSession(_ context.Context, sess domain.Session) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.sessions[sess.TokenHash] = sess
+	return nil
+}
+
+func (r sessionRepo) Session(_ context.Context, tokenHash string) (*domain.Session, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	sess, ok := r.s.sessions[tokenHash]
+	if !ok {
+		return nil, fmt.Errorf("session: %w", domain.ErrNotFound)
+	}
+	return &sess, nil
+}
+
+func (r sessionRepo) DeleteSession(_ context.Context, tokenHash string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	delete(r.s.sessions, tokenHash)
+	return nil
+}
+
+func (r sessionRepo) DeleteUserSessions(_ context.Context, userID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for hash, sess := range r.s.sessions {
+		if sess.UserID == userID {
+			delete(r.s.sessions, hash)
+		}
+	}
+	return nil
+}
+
+type verificationRepo struct{ s *Store }
+
+func (r verificationRepo) CreateVerification(_ context.Context, v domain.EmailVerification) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.verifications[v.TokenHash] = v
+	return nil
+}
+
+func (r verificationRepo) ConsumeVerification(_ context.Context, tokenHash string) (*domain.EmailVerification, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	v, ok := r.s.verifications[tokenHash]
+	if !ok {
+		return nil, fmt.Errorf("email verification: %w", domain.ErrNotFound)
+	}
+	delete(r.s.verifications, tokenHash)
+	return &v, nil
+}
+
+type identityRepo struct{ s *Store }
+
+func (r identityRepo) LinkIdentity(_ context.Context, provider, subject, userID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	key := provider + "\x00" + subject
+	if linked, ok := r.s.identities[key]; ok && linked != userID {
+		return fmt.Errorf("%s account is linked to another user: %w", provider, domain.ErrConflict)
+	}
+	r.s.identities[key] = userID
+	return nil
+}
+
+func (r identityRepo) IdentityUser(_ context.Context, provider, subject string) (string, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	userID, ok := r.s.identities[provider+"\x00"+subject]
+	if !ok {
+		return "", fmt.Errorf("%s identity: %w", provider, domain.ErrNotFound)
+	}
+	return userID, nil
+}
+
+type apiTokenRepo struct{ s *Store }
+
+func (r apiTokenRepo) CreateAPIToken(_ context.Context, t domain.APIToken) (domain.APIToken, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.nextID++
+	t.ID = fmt.Sprintf("token_%d", r.s.nextID)
+	t.Scopes = slices.Clone(t.Scopes)
+	r.s.apiTokens[t.ID] = t
+	return t, nil
+}
+
+func (r apiTokenRepo) APITokenByHash(_ context.Context, tokenHash string) (*domain.APIToken, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, t := range r.s.apiTokens {
+		if t.TokenHash == tokenHash {
+			t.Scopes = slices.Clone(t.Scopes)
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("api token: %w", domain.ErrNotFound)
+}
+
+func (r apiTokenRepo) UserAPITokens(_ context.Context, userID string) ([]domain.APIToken, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var out []domain.APIToken
+	for _, t := range r.s.apiTokens {
+		if t.UserID == userID {
+			t.Scopes = slices.Clone(t.Scopes)
+			out = append(out, t)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.APIToken) int { return compareIDs(a.ID, b.ID) })
+	return out, nil
+}
+
+func (r apiTokenRepo) TouchAPIToken(_ context.Context, id string, at time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	t, ok := r.s.apiTokens[id]
+	if !ok {
+		return fmt.Errorf("api token %s: %w", id, domain.ErrNotFound)
+	}
+	t.LastUsedAt = &at
+	r.s.apiTokens[id] = t
+	return nil
+}
+
+func (r apiTokenRepo) DeleteAPIToken(_ context.Context, userID, id string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if t, ok := r.s.apiTokens[id]; !ok || t.UserID != userID {
+		return fmt.Errorf("api token %s: %w", id, domain.ErrNotFound)
+	}
+	delete(r.s.apiTokens, id)
+	return nil
+}
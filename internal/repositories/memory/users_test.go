@@ -0,0 +1,183 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestStore_Users(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_Users", "internal/repositories/memory")
+
+	store := NewStore()
+	ctx := t.Context()
+	users := store.Users()
+
+	testhelpers.LogTestStep(logger, "act", "Creating users")
+	asha, err := users.CreateUser(ctx, domain.User{Email: "asha@example.com"})
+	if err != nil || asha.ID == "" {
+		t.Fatalf("CreateUser = %+v, %v", asha, err)
+	}
+	ravi, _ := users.CreateUser(ctx, domain.User{Email: "ravi@example.com"})
+	if _, err := users.CreateUser(ctx, domain.User{Email: "asha@example.com"}); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Duplicate email error = %v, want ErrConflict", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Looking users up and saving changes")
+	byEmail, err := users.UserByEmail(ctx, "asha@example.com")
+	testhelpers.LogTestAssertion(logger, "by email", asha.ID, byEmail.ID)
+	if err != nil || byEmail.ID != asha.ID {
+		t.Errorf("UserByEmail = %+v, %v", byEmail, err)
+	}
+	if _, err := users.UserByEmail(ctx, "nobody@example.com"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Unknown email error = %v, want ErrNotFound", err)
+	}
+	asha.EmailVerified = true
+	if err := users.SaveUser(ctx, asha); err != nil {
+		t.Fatalf("SaveUser: %v", err)
+	}
+	if got, _ := users.UserByID(ctx, asha.ID); !got.EmailVerified {
+		t.Error("SaveUser did not persist")
+	}
+	ravi.Email = "asha@example.com"
+	if err := users.SaveUser(ctx, ravi); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Saving a taken email error = %v, want ErrConflict", err)
+	}
+	if err := users.SaveUser(ctx, domain.User{ID: "user_missing"}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Saving an unknown user error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_Users", true)
+}
+
+func TestStore_SessionsAndVerifications(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_SessionsAndVerifications", "internal/repositories/memory")
+
+	store := NewStore()
+	ctx := t.Context()
+	sessions := store.Sessions()
+	expires := time.Now().Add(time.Hour)
+	for _, s := range []domain.Session{
+		{TokenHash: "a1", UserID: "user_1", ExpiresAt: expires},
+		{TokenHash: "a2", UserID: "user_1", ExpiresAt: expires},
+		{TokenHash: "b1", UserID: "user_2", ExpiresAt: expires},
+	} {
+		if err := sessions.CreateSession(ctx, s); err != nil {
+			t.Fatalf("CreateSession: %v", err)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Signing one user out everywhere")
+	if err := sessions.DeleteUserSessions(ctx, "user_1"); err != nil {
+		t.Fatalf("DeleteUserSessions: %v", err)
+	}
+	for hash, want := range map[string]bool{"a1": false, "a2": false, "b1": true} {
+		_, err := sessions.Session(ctx, hash)
+		if (err == nil) != want {
+			t.Errorf("Session(%s) error = %v, want present %v", hash, err, want)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Consuming a verification twice")
+	verifications := store.Verifications()
+	if err := verifications.CreateVerification(ctx, domain.EmailVerification{TokenHash: "v1", UserID: "user_2"}); err != nil {
+		t.Fatalf("CreateVerification: %v", err)
+	}
+	v, err := verifications.ConsumeVerification(ctx, "v1")
+	testhelpers.LogTestAssertion(logger, "consumed", "user_2", v.UserID)
+	if err != nil || v.UserID != "user_2" {
+		t.Errorf("ConsumeVerification = %+v, %v", v, err)
+	}
+	if _, err := verifications.ConsumeVerification(ctx, "v1"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Second consume error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_SessionsAndVerifications", true)
+}
+
+func TestStore_Identities(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_Identities", "internal/repositories/memory")
+
+	identities := NewStore().Identities()
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Linking an identity")
+	if err := identities.LinkIdentity(ctx, "google", "123", "user_1"); err != nil {
+		t.Fatalf("LinkIdentity: %v", err)
+	}
+	if err := identities.LinkIdentity(ctx, "google", "123", "user_1"); err != nil {
+		t.Errorf("Relinking to the same user: %v", err)
+	}
+	if err := identities.LinkIdentity(ctx, "google", "123", "user_2"); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Linking to another user error = %v, want ErrConflict", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Subjects are scoped by provider")
+	userID, err := identities.IdentityUser(ctx, "google", "123")
+	testhelpers.LogTestAssertion(logger, "user", "user_1", userID)
+	if err != nil || userID != "user_1" {
+		t.Errorf("IdentityUser = %q, %v", userID, err)
+	}
+	if _, err := identities.IdentityUser(ctx, "github", "123"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Other provider error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_Identities", true)
+}
+
+func TestStore_APITokens(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_APITokens", "internal/repositories/memory")
+
+	store := NewStore()
+	ctx := t.Context()
+	tokens := store.APITokens()
+
+	testhelpers.LogTestStep(logger, "act", "Creating two tokens for a user and one for another")
+	var created []domain.APIToken
+	for _, tc := range []struct{ user, hash string }{{"user_1", "hash_a"}, {"user_1", "hash_b"}, {"user_2", "hash_c"}} {
+		tok, err := tokens.CreateAPIToken(ctx, domain.APIToken{UserID: tc.user, TokenHash: tc.hash, Scopes: []string{domain.ScopeRead}})
+		if err != nil || tok.ID == "" {
+			t.Fatalf("CreateAPIToken = %+v, %v", tok, err)
+		}
+		created = append(created, tok)
+	}
+	mine, _ := tokens.UserAPITokens(ctx, "user_1")
+	testhelpers.LogTestAssertion(logger, "user_1 tokens", 2, len(mine))
+	if len(mine) != 2 || mine[0].ID != created[0].ID {
+		t.Errorf("UserAPITokens = %+v", mine)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Touching and looking up by hash")
+	used := time.Now().UTC()
+	if err := tokens.TouchAPIToken(ctx, created[1].ID, used); err != nil {
+		t.Fatalf("TouchAPIToken: %v", err)
+	}
+	got, err := tokens.APITokenByHash(ctx, "hash_b")
+	if err != nil || got.ID != created[1].ID || got.LastUsedAt == nil || !got.LastUsedAt.Equal(used) {
+		t.Errorf("APITokenByHash = %+v, %v", got, err)
+	}
+	got.Scopes[0] = "changed"
+	if again, _ := tokens.APITokenByHash(ctx, "hash_b"); again.Scopes[0] != domain.ScopeRead {
+		t.Error("Returned scopes alias the stored token")
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Only the owner can delete a token")
+	if err := tokens.DeleteAPIToken(ctx, "user_2", created[0].ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Deleting another user's token error = %v, want ErrNotFound", err)
+	}
+	if err := tokens.DeleteAPIToken(ctx, "user_1", created[0].ID); err != nil {
+		t.Fatalf("DeleteAPIToken: %v", err)
+	}
+	if _, err := tokens.APITokenByHash(ctx, "hash_a"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Deleted token lookup error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_APITokens", true)
+}
@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Watchlists returns the Store as a WatchlistRepository.
+func (s *Store) Watchlists() repositories.WatchlistRepository { return watchlistRepo{s} }
+
+type watchlistRepo struct{ s *Store }
+
+func watchKey(userID, productID string) string { return userID + "\x00" + productID }
+
+func (r watchlistRepo) AddWatch(_ context.Context, item domain.WatchlistItem) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	key := watchKey(item.UserID, item.ProductID)
+	if _, ok := r.s.watchlist[key]; ok {
+		return fmt.Errorf("product %q is already on the watchlist: %w", item.ProductID, domain.ErrConflict)
+	}
+	r.s.watchlist[key] = item
+	return nil
+}
+
+func (r watchlistRepo) RemoveWatch(_ context.Context, userID, productID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	key := watchKey(userID, productID)
+	if _, ok := r.s.watchlist[key]; !ok {
+		return fmt.Errorf("watchlist item %q: %w", productID, domain.ErrNotFound)
+	}
+	delete(r.s.watchlist, key)
+	return nil
+}
+
+func (r watchlistRepo) Watchlist(_ context.Context, userID string) ([]domain.WatchlistItem, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var out []domain.WatchlistItem
+	for _, item := range r.s.watchlist {
+		if item.UserID == userID {
+			out = append(out, item)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.WatchlistItem) int {
+		return cmp.Or(b.AddedAt.Compare(a.AddedAt), cmp.Compare(a.ProductID, b.ProductID))
+	})
+	return out, nil
+}
@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestStore_Watchlists(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStore_Watchlists", "internal/repositories/memory")
+
+	store := NewStore()
+	ctx := t.Context()
+	watchlists := store.Watchlists()
+	now := time.Now()
+
+	testhelpers.LogTestStep(logger, "act", "Watching two products, one twice, and one for another user")
+	for i, item := range []domain.WatchlistItem{
+		{UserID: "user_1", ProductID: "prod_a"},
+		{UserID: "user_1", ProductID: "prod_b"},
+		{UserID: "user_2", ProductID: "prod_a"},
+	} {
+		item.AddedAt = now.Add(time.Duration(i) * time.Minute)
+		if err := watchlists.AddWatch(ctx, item); err != nil {
+			t.Fatalf("AddWatch: %v", err)
+		}
+	}
+	if err := watchlists.AddWatch(ctx, domain.WatchlistItem{UserID: "user_1", ProductID: "prod_a"}); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Duplicate AddWatch error = %v, want ErrConflict", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Each user sees their own items, newest first")
+	mine, _ := watchlists.Watchlist(ctx, "user_1")
+	testhelpers.LogTestAssertion(logger, "user_1 items", 2, len(mine))
+	if len(mine) != 2 || mine[0].ProductID != "prod_b" {
+		t.Errorf("Watchlist = %+v, want prod_b then prod_a", mine)
+	}
+	if err := watchlists.RemoveWatch(ctx, "user_1", "prod_a"); err != nil {
+		t.Fatalf("RemoveWatch: %v", err)
+	}
+	if err := watchlists.RemoveWatch(ctx, "user_1", "prod_a"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Second RemoveWatch error = %v, want ErrNotFound", err)
+	}
+	if theirs, _ := watchlists.Watchlist(ctx, "user_2"); len(theirs) != 1 {
+		t.Errorf("Removing user_1's item changed user_2's watchlist: %+v", theirs)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStore_Watchlists", true)
+}
@@ -0,0 +1,3279 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/yourusername/whey-price-compare/internal/repositories (interfaces: Transactor,ProductRepository,ProductSearchRepository,RetailerRepository,ListingRepository,PriceRepository,DailyPriceRepository,CatalogAdminRepository,SelectorRepository,PriceWriter,PriceIngester,OutboxRepository,PriceLogRepository,AuditRepository,StatsRepository,IntegrityRepository,ClickRepository,UserDataRepository,DataRequestRepository,ViewRepository,UserRepository,SessionRepository,APITokenRepository,VerificationRepository,IdentityRepository,AlertRepository,SavedSearchRepository,FilterPresetRepository,SynonymRepository,FlagRepository,SearchLogRepository,NotificationQueue,EngagementRepository,DeliveryRepository,PreferenceRepository,SuppressionRepository,SMSRepository,DiscordRepository,SlackRepository,TelegramRepository,PushSubscriptionRepository,WatchlistRepository)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mocks/mocks.go -package=mocks . Transactor,ProductRepository,ProductSearchRepository,RetailerRepository,ListingRepository,PriceRepository,DailyPriceRepository,CatalogAdminRepository,SelectorRepository,PriceWriter,PriceIngester,OutboxRepository,PriceLogRepository,AuditRepository,StatsRepository,IntegrityRepository,ClickRepository,UserDataRepository,DataRequestRepository,ViewRepository,UserRepository,SessionRepository,APITokenRepository,VerificationRepository,IdentityRepository,AlertRepository,SavedSearchRepository,FilterPresetRepository,SynonymRepository,FlagRepository,SearchLogRepository,NotificationQueue,EngagementRepository,DeliveryRepository,PreferenceRepository,SuppressionRepository,SMSRepository,DiscordRepository,SlackRepository,TelegramRepository,PushSubscriptionRepository,WatchlistRepository
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/yourusername/whey-price-compare/internal/domain"
+	repositories "github.com/yourusername/whey-price-compare/internal/repositories"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTransactor is a mock of Transactor interface.
+type MockTransactor struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransactorMockRecorder
+	isgomock struct{}
+}
+
+// MockTransactorMockRecorder is the mock recorder for MockTransactor.
+type MockTransactorMockRecorder struct {
+	mock *MockTransactor
+}
+
+// NewMockTransactor creates a new mock instance.
+func NewMockTransactor(ctrl *gomock.Controller) *MockTransactor {
+	mock := &MockTransactor{ctrl: ctrl}
+	mock.recorder = &MockTransactorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransactor) EXPECT() *MockTransactorMockRecorder {
+	return m.recorder
+}
+
+// InTx mocks base method.
+func (m *MockTransactor) InTx(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InTx indicates an expected call of InTx.
+func (mr *MockTransactorMockRecorder) InTx(ctx, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InTx", reflect.TypeOf((*MockTransactor)(nil).InTx), ctx, fn)
+}
+
+// MockProductRepository is a mock of ProductRepository interface.
+type MockProductRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockProductRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockProductRepositoryMockRecorder is the mock recorder for MockProductRepository.
+type MockProductRepositoryMockRecorder struct {
+	mock *MockProductRepository
+}
+
+// NewMockProductRepository creates a new mock instance.
+func NewMockProductRepository(ctrl *gomock.Controller) *MockProductRepository {
+	mock := &MockProductRepository{ctrl: ctrl}
+	mock.recorder = &MockProductRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProductRepository) EXPECT() *MockProductRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindByID mocks base method.
+func (m *MockProductRepository) FindByID(ctx context.Context, id string) (*domain.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", ctx, id)
+	ret0, _ := ret[0].(*domain.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockProductRepositoryMockRecorder) FindByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockProductRepository)(nil).FindByID), ctx, id)
+}
+
+// FindByIDs mocks base method.
+func (m *MockProductRepository) FindByIDs(ctx context.Context, ids []string) (map[string]domain.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIDs", ctx, ids)
+	ret0, _ := ret[0].(map[string]domain.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIDs indicates an expected call of FindByIDs.
+func (mr *MockProductRepositoryMockRecorder) FindByIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIDs", reflect.TypeOf((*MockProductRepository)(nil).FindByIDs), ctx, ids)
+}
+
+// List mocks base method.
+func (m *MockProductRepository) List(ctx context.Context, filter repositories.ProductFilter) ([]domain.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]domain.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockProductRepositoryMockRecorder) List(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockProductRepository)(nil).List), ctx, filter)
+}
+
+// Variants mocks base method.
+func (m *MockProductRepository) Variants(ctx context.Context, productID string) ([]domain.Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Variants", ctx, productID)
+	ret0, _ := ret[0].([]domain.Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Variants indicates an expected call of Variants.
+func (mr *MockProductRepositoryMockRecorder) Variants(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Variants", reflect.TypeOf((*MockProductRepository)(nil).Variants), ctx, productID)
+}
+
+// VariantsByProducts mocks base method.
+func (m *MockProductRepository) VariantsByProducts(ctx context.Context, productIDs []string) (map[string][]domain.Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VariantsByProducts", ctx, productIDs)
+	ret0, _ := ret[0].(map[string][]domain.Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VariantsByProducts indicates an expected call of VariantsByProducts.
+func (mr *MockProductRepositoryMockRecorder) VariantsByProducts(ctx, productIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VariantsByProducts", reflect.TypeOf((*MockProductRepository)(nil).VariantsByProducts), ctx, productIDs)
+}
+
+// MockProductSearchRepository is a mock of ProductSearchRepository interface.
+type MockProductSearchRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockProductSearchRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockProductSearchRepositoryMockRecorder is the mock recorder for MockProductSearchRepository.
+type MockProductSearchRepositoryMockRecorder struct {
+	mock *MockProductSearchRepository
+}
+
+// NewMockProductSearchRepository creates a new mock instance.
+func NewMockProductSearchRepository(ctrl *gomock.Controller) *MockProductSearchRepository {
+	mock := &MockProductSearchRepository{ctrl: ctrl}
+	mock.recorder = &MockProductSearchRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProductSearchRepository) EXPECT() *MockProductSearchRepositoryMockRecorder {
+	return m.recorder
+}
+
+// SearchProducts mocks base method.
+func (m *MockProductSearchRepository) SearchProducts(ctx context.Context, q repositories.ProductSearch) (*domain.SearchMatches, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchProducts", ctx, q)
+	ret0, _ := ret[0].(*domain.SearchMatches)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchProducts indicates an expected call of SearchProducts.
+func (mr *MockProductSearchRepositoryMockRecorder) SearchProducts(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProducts", reflect.TypeOf((*MockProductSearchRepository)(nil).SearchProducts), ctx, q)
+}
+
+// MockRetailerRepository is a mock of RetailerRepository interface.
+type MockRetailerRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRetailerRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockRetailerRepositoryMockRecorder is the mock recorder for MockRetailerRepository.
+type MockRetailerRepositoryMockRecorder struct {
+	mock *MockRetailerRepository
+}
+
+// NewMockRetailerRepository creates a new mock instance.
+func NewMockRetailerRepository(ctrl *gomock.Controller) *MockRetailerRepository {
+	mock := &MockRetailerRepository{ctrl: ctrl}
+	mock.recorder = &MockRetailerRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRetailerRepository) EXPECT() *MockRetailerRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindByID mocks base method.
+func (m *MockRetailerRepository) FindByID(ctx context.Context, id string) (*domain.Retailer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", ctx, id)
+	ret0, _ := ret[0].(*domain.Retailer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockRetailerRepositoryMockRecorder) FindByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockRetailerRepository)(nil).FindByID), ctx, id)
+}
+
+// FindByIDs mocks base method.
+func (m *MockRetailerRepository) FindByIDs(ctx context.Context, ids []string) (map[string]domain.Retailer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIDs", ctx, ids)
+	ret0, _ := ret[0].(map[string]domain.Retailer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIDs indicates an expected call of FindByIDs.
+func (mr *MockRetailerRepositoryMockRecorder) FindByIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIDs", reflect.TypeOf((*MockRetailerRepository)(nil).FindByIDs), ctx, ids)
+}
+
+// List mocks base method.
+func (m *MockRetailerRepository) List(ctx context.Context) ([]domain.Retailer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]domain.Retailer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockRetailerRepositoryMockRecorder) List(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRetailerRepository)(nil).List), ctx)
+}
+
+// MockListingRepository is a mock of ListingRepository interface.
+type MockListingRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockListingRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockListingRepositoryMockRecorder is the mock recorder for MockListingRepository.
+type MockListingRepositoryMockRecorder struct {
+	mock *MockListingRepository
+}
+
+// NewMockListingRepository creates a new mock instance.
+func NewMockListingRepository(ctrl *gomock.Controller) *MockListingRepository {
+	mock := &MockListingRepository{ctrl: ctrl}
+	mock.recorder = &MockListingRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockListingRepository) EXPECT() *MockListingRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ByProduct mocks base method.
+func (m *MockListingRepository) ByProduct(ctx context.Context, productID string) ([]domain.Listing, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ByProduct", ctx, productID)
+	ret0, _ := ret[0].([]domain.Listing)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ByProduct indicates an expected call of ByProduct.
+func (mr *MockListingRepositoryMockRecorder) ByProduct(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ByProduct", reflect.TypeOf((*MockListingRepository)(nil).ByProduct), ctx, productID)
+}
+
+// ByProducts mocks base method.
+func (m *MockListingRepository) ByProducts(ctx context.Context, productIDs []string) (map[string][]domain.Listing, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ByProducts", ctx, productIDs)
+	ret0, _ := ret[0].(map[string][]domain.Listing)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ByProducts indicates an expected call of ByProducts.
+func (mr *MockListingRepositoryMockRecorder) ByProducts(ctx, productIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ByProducts", reflect.TypeOf((*MockListingRepository)(nil).ByProducts), ctx, productIDs)
+}
+
+// MockPriceRepository is a mock of PriceRepository interface.
+type MockPriceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPriceRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPriceRepositoryMockRecorder is the mock recorder for MockPriceRepository.
+type MockPriceRepositoryMockRecorder struct {
+	mock *MockPriceRepository
+}
+
+// NewMockPriceRepository creates a new mock instance.
+func NewMockPriceRepository(ctrl *gomock.Controller) *MockPriceRepository {
+	mock := &MockPriceRepository{ctrl: ctrl}
+	mock.recorder = &MockPriceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPriceRepository) EXPECT() *MockPriceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// History mocks base method.
+func (m *MockPriceRepository) History(ctx context.Context, listingIDs []string, since time.Time) ([]domain.PricePoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", ctx, listingIDs, since)
+	ret0, _ := ret[0].([]domain.PricePoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockPriceRepositoryMockRecorder) History(ctx, listingIDs, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockPriceRepository)(nil).History), ctx, listingIDs, since)
+}
+
+// MockDailyPriceRepository is a mock of DailyPriceRepository interface.
+type MockDailyPriceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDailyPriceRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockDailyPriceRepositoryMockRecorder is the mock recorder for MockDailyPriceRepository.
+type MockDailyPriceRepositoryMockRecorder struct {
+	mock *MockDailyPriceRepository
+}
+
+// NewMockDailyPriceRepository creates a new mock instance.
+func NewMockDailyPriceRepository(ctrl *gomock.Controller) *MockDailyPriceRepository {
+	mock := &MockDailyPriceRepository{ctrl: ctrl}
+	mock.recorder = &MockDailyPriceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDailyPriceRepository) EXPECT() *MockDailyPriceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DailyHistory mocks base method.
+func (m *MockDailyPriceRepository) DailyHistory(ctx context.Context, listingIDs []string, since time.Time) ([]domain.DailyPrice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DailyHistory", ctx, listingIDs, since)
+	ret0, _ := ret[0].([]domain.DailyPrice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DailyHistory indicates an expected call of DailyHistory.
+func (mr *MockDailyPriceRepositoryMockRecorder) DailyHistory(ctx, listingIDs, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DailyHistory", reflect.TypeOf((*MockDailyPriceRepository)(nil).DailyHistory), ctx, listingIDs, since)
+}
+
+// MockCatalogAdminRepository is a mock of CatalogAdminRepository interface.
+type MockCatalogAdminRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCatalogAdminRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockCatalogAdminRepositoryMockRecorder is the mock recorder for MockCatalogAdminRepository.
+type MockCatalogAdminRepositoryMockRecorder struct {
+	mock *MockCatalogAdminRepository
+}
+
+// NewMockCatalogAdminRepository creates a new mock instance.
+func NewMockCatalogAdminRepository(ctrl *gomock.Controller) *MockCatalogAdminRepository {
+	mock := &MockCatalogAdminRepository{ctrl: ctrl}
+	mock.recorder = &MockCatalogAdminRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCatalogAdminRepository) EXPECT() *MockCatalogAdminRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Listing mocks base method.
+func (m *MockCatalogAdminRepository) Listing(ctx context.Context, id string) (*domain.Listing, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Listing", ctx, id)
+	ret0, _ := ret[0].(*domain.Listing)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Listing indicates an expected call of Listing.
+func (mr *MockCatalogAdminRepositoryMockRecorder) Listing(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Listing", reflect.TypeOf((*MockCatalogAdminRepository)(nil).Listing), ctx, id)
+}
+
+// Product mocks base method.
+func (m *MockCatalogAdminRepository) Product(ctx context.Context, id string) (*domain.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Product", ctx, id)
+	ret0, _ := ret[0].(*domain.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Product indicates an expected call of Product.
+func (mr *MockCatalogAdminRepositoryMockRecorder) Product(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Product", reflect.TypeOf((*MockCatalogAdminRepository)(nil).Product), ctx, id)
+}
+
+// Retailer mocks base method.
+func (m *MockCatalogAdminRepository) Retailer(ctx context.Context, id string) (*domain.Retailer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Retailer", ctx, id)
+	ret0, _ := ret[0].(*domain.Retailer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Retailer indicates an expected call of Retailer.
+func (mr *MockCatalogAdminRepositoryMockRecorder) Retailer(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Retailer", reflect.TypeOf((*MockCatalogAdminRepository)(nil).Retailer), ctx, id)
+}
+
+// SaveProduct mocks base method.
+func (m *MockCatalogAdminRepository) SaveProduct(ctx context.Context, p domain.Product, events ...domain.Event) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, p}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SaveProduct", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveProduct indicates an expected call of SaveProduct.
+func (mr *MockCatalogAdminRepositoryMockRecorder) SaveProduct(ctx, p any, events ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, p}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveProduct", reflect.TypeOf((*MockCatalogAdminRepository)(nil).SaveProduct), varargs...)
+}
+
+// SaveRetailer mocks base method.
+func (m *MockCatalogAdminRepository) SaveRetailer(ctx context.Context, r domain.Retailer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRetailer", ctx, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRetailer indicates an expected call of SaveRetailer.
+func (mr *MockCatalogAdminRepositoryMockRecorder) SaveRetailer(ctx, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRetailer", reflect.TypeOf((*MockCatalogAdminRepository)(nil).SaveRetailer), ctx, r)
+}
+
+// SaveVariant mocks base method.
+func (m *MockCatalogAdminRepository) SaveVariant(ctx context.Context, v domain.Variant, events ...domain.Event) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, v}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SaveVariant", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveVariant indicates an expected call of SaveVariant.
+func (mr *MockCatalogAdminRepositoryMockRecorder) SaveVariant(ctx, v any, events ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, v}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveVariant", reflect.TypeOf((*MockCatalogAdminRepository)(nil).SaveVariant), varargs...)
+}
+
+// Variant mocks base method.
+func (m *MockCatalogAdminRepository) Variant(ctx context.Context, id string) (*domain.Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Variant", ctx, id)
+	ret0, _ := ret[0].(*domain.Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Variant indicates an expected call of Variant.
+func (mr *MockCatalogAdminRepositoryMockRecorder) Variant(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Variant", reflect.TypeOf((*MockCatalogAdminRepository)(nil).Variant), ctx, id)
+}
+
+// MockSelectorRepository is a mock of SelectorRepository interface.
+type MockSelectorRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSelectorRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSelectorRepositoryMockRecorder is the mock recorder for MockSelectorRepository.
+type MockSelectorRepositoryMockRecorder struct {
+	mock *MockSelectorRepository
+}
+
+// NewMockSelectorRepository creates a new mock instance.
+func NewMockSelectorRepository(ctrl *gomock.Controller) *MockSelectorRepository {
+	mock := &MockSelectorRepository{ctrl: ctrl}
+	mock.recorder = &MockSelectorRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSelectorRepository) EXPECT() *MockSelectorRepositoryMockRecorder {
+	return m.recorder
+}
+
+// SaveSelectors mocks base method.
+func (m *MockSelectorRepository) SaveSelectors(ctx context.Context, cfg domain.SelectorConfig) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSelectors", ctx, cfg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSelectors indicates an expected call of SaveSelectors.
+func (mr *MockSelectorRepositoryMockRecorder) SaveSelectors(ctx, cfg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSelectors", reflect.TypeOf((*MockSelectorRepository)(nil).SaveSelectors), ctx, cfg)
+}
+
+// Selectors mocks base method.
+func (m *MockSelectorRepository) Selectors(ctx context.Context, retailerID string) (*domain.SelectorConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Selectors", ctx, retailerID)
+	ret0, _ := ret[0].(*domain.SelectorConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Selectors indicates an expected call of Selectors.
+func (mr *MockSelectorRepositoryMockRecorder) Selectors(ctx, retailerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Selectors", reflect.TypeOf((*MockSelectorRepository)(nil).Selectors), ctx, retailerID)
+}
+
+// MockPriceWriter is a mock of PriceWriter interface.
+type MockPriceWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockPriceWriterMockRecorder
+	isgomock struct{}
+}
+
+// MockPriceWriterMockRecorder is the mock recorder for MockPriceWriter.
+type MockPriceWriterMockRecorder struct {
+	mock *MockPriceWriter
+}
+
+// NewMockPriceWriter creates a new mock instance.
+func NewMockPriceWriter(ctrl *gomock.Controller) *MockPriceWriter {
+	mock := &MockPriceWriter{ctrl: ctrl}
+	mock.recorder = &MockPriceWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPriceWriter) EXPECT() *MockPriceWriterMockRecorder {
+	return m.recorder
+}
+
+// RecordPrice mocks base method.
+func (m *MockPriceWriter) RecordPrice(ctx context.Context, p domain.PricePoint, events ...domain.Event) (domain.PricePoint, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, p}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RecordPrice", varargs...)
+	ret0, _ := ret[0].(domain.PricePoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordPrice indicates an expected call of RecordPrice.
+func (mr *MockPriceWriterMockRecorder) RecordPrice(ctx, p any, events ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, p}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordPrice", reflect.TypeOf((*MockPriceWriter)(nil).RecordPrice), varargs...)
+}
+
+// MockPriceIngester is a mock of PriceIngester interface.
+type MockPriceIngester struct {
+	ctrl     *gomock.Controller
+	recorder *MockPriceIngesterMockRecorder
+	isgomock struct{}
+}
+
+// MockPriceIngesterMockRecorder is the mock recorder for MockPriceIngester.
+type MockPriceIngesterMockRecorder struct {
+	mock *MockPriceIngester
+}
+
+// NewMockPriceIngester creates a new mock instance.
+func NewMockPriceIngester(ctrl *gomock.Controller) *MockPriceIngester {
+	mock := &MockPriceIngester{ctrl: ctrl}
+	mock.recorder = &MockPriceIngesterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPriceIngester) EXPECT() *MockPriceIngesterMockRecorder {
+	return m.recorder
+}
+
+// IngestPrices mocks base method.
+func (m *MockPriceIngester) IngestPrices(ctx context.Context, points []domain.PricePoint, events ...domain.Event) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, points}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "IngestPrices", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IngestPrices indicates an expected call of IngestPrices.
+func (mr *MockPriceIngesterMockRecorder) IngestPrices(ctx, points any, events ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, points}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IngestPrices", reflect.TypeOf((*MockPriceIngester)(nil).IngestPrices), varargs...)
+}
+
+// Listings mocks base method.
+func (m *MockPriceIngester) Listings(ctx context.Context, ids []string) (map[string]repositories.IngestListing, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Listings", ctx, ids)
+	ret0, _ := ret[0].(map[string]repositories.IngestListing)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Listings indicates an expected call of Listings.
+func (mr *MockPriceIngesterMockRecorder) Listings(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Listings", reflect.TypeOf((*MockPriceIngester)(nil).Listings), ctx, ids)
+}
+
+// MockOutboxRepository is a mock of OutboxRepository interface.
+type MockOutboxRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockOutboxRepositoryMockRecorder is the mock recorder for MockOutboxRepository.
+type MockOutboxRepositoryMockRecorder struct {
+	mock *MockOutboxRepository
+}
+
+// NewMockOutboxRepository creates a new mock instance.
+func NewMockOutboxRepository(ctrl *gomock.Controller) *MockOutboxRepository {
+	mock := &MockOutboxRepository{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepository) EXPECT() *MockOutboxRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeletePublishedBefore mocks base method.
+func (m *MockOutboxRepository) DeletePublishedBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePublishedBefore", ctx, cutoff)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeletePublishedBefore indicates an expected call of DeletePublishedBefore.
+func (mr *MockOutboxRepositoryMockRecorder) DeletePublishedBefore(ctx, cutoff any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePublishedBefore", reflect.TypeOf((*MockOutboxRepository)(nil).DeletePublishedBefore), ctx, cutoff)
+}
+
+// MarkFailed mocks base method.
+func (m *MockOutboxRepository) MarkFailed(ctx context.Context, at time.Time, id, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkFailed", ctx, at, id, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkFailed indicates an expected call of MarkFailed.
+func (mr *MockOutboxRepositoryMockRecorder) MarkFailed(ctx, at, id, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFailed", reflect.TypeOf((*MockOutboxRepository)(nil).MarkFailed), ctx, at, id, reason)
+}
+
+// MarkPublished mocks base method.
+func (m *MockOutboxRepository) MarkPublished(ctx context.Context, at time.Time, ids ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, at}
+	for _, a := range ids {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "MarkPublished", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkPublished indicates an expected call of MarkPublished.
+func (mr *MockOutboxRepositoryMockRecorder) MarkPublished(ctx, at any, ids ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, at}, ids...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkPublished", reflect.TypeOf((*MockOutboxRepository)(nil).MarkPublished), varargs...)
+}
+
+// MarkRetry mocks base method.
+func (m *MockOutboxRepository) MarkRetry(ctx context.Context, id, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRetry", ctx, id, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkRetry indicates an expected call of MarkRetry.
+func (mr *MockOutboxRepositoryMockRecorder) MarkRetry(ctx, id, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRetry", reflect.TypeOf((*MockOutboxRepository)(nil).MarkRetry), ctx, id, reason)
+}
+
+// Pending mocks base method.
+func (m *MockOutboxRepository) Pending(ctx context.Context, limit int) ([]domain.OutboxMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pending", ctx, limit)
+	ret0, _ := ret[0].([]domain.OutboxMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Pending indicates an expected call of Pending.
+func (mr *MockOutboxRepositoryMockRecorder) Pending(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pending", reflect.TypeOf((*MockOutboxRepository)(nil).Pending), ctx, limit)
+}
+
+// MockPriceLogRepository is a mock of PriceLogRepository interface.
+type MockPriceLogRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPriceLogRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPriceLogRepositoryMockRecorder is the mock recorder for MockPriceLogRepository.
+type MockPriceLogRepositoryMockRecorder struct {
+	mock *MockPriceLogRepository
+}
+
+// NewMockPriceLogRepository creates a new mock instance.
+func NewMockPriceLogRepository(ctrl *gomock.Controller) *MockPriceLogRepository {
+	mock := &MockPriceLogRepository{ctrl: ctrl}
+	mock.recorder = &MockPriceLogRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPriceLogRepository) EXPECT() *MockPriceLogRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Commit mocks base method.
+func (m *MockPriceLogRepository) Commit(ctx context.Context, consumer string, from, to int64, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Commit", ctx, consumer, from, to, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Commit indicates an expected call of Commit.
+func (mr *MockPriceLogRepositoryMockRecorder) Commit(ctx, consumer, from, to, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockPriceLogRepository)(nil).Commit), ctx, consumer, from, to, at)
+}
+
+// Cursor mocks base method.
+func (m *MockPriceLogRepository) Cursor(ctx context.Context, consumer string) (domain.PriceLogCursor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cursor", ctx, consumer)
+	ret0, _ := ret[0].(domain.PriceLogCursor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Cursor indicates an expected call of Cursor.
+func (mr *MockPriceLogRepositoryMockRecorder) Cursor(ctx, consumer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cursor", reflect.TypeOf((*MockPriceLogRepository)(nil).Cursor), ctx, consumer)
+}
+
+// Cursors mocks base method.
+func (m *MockPriceLogRepository) Cursors(ctx context.Context) ([]domain.PriceLogCursor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cursors", ctx)
+	ret0, _ := ret[0].([]domain.PriceLogCursor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Cursors indicates an expected call of Cursors.
+func (mr *MockPriceLogRepositoryMockRecorder) Cursors(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cursors", reflect.TypeOf((*MockPriceLogRepository)(nil).Cursors), ctx)
+}
+
+// Head mocks base method.
+func (m *MockPriceLogRepository) Head(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Head", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Head indicates an expected call of Head.
+func (mr *MockPriceLogRepositoryMockRecorder) Head(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Head", reflect.TypeOf((*MockPriceLogRepository)(nil).Head), ctx)
+}
+
+// OffsetBefore mocks base method.
+func (m *MockPriceLogRepository) OffsetBefore(ctx context.Context, t time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OffsetBefore", ctx, t)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OffsetBefore indicates an expected call of OffsetBefore.
+func (mr *MockPriceLogRepositoryMockRecorder) OffsetBefore(ctx, t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OffsetBefore", reflect.TypeOf((*MockPriceLogRepository)(nil).OffsetBefore), ctx, t)
+}
+
+// Read mocks base method.
+func (m *MockPriceLogRepository) Read(ctx context.Context, filter repositories.PriceLogFilter) ([]domain.PriceLogEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Read", ctx, filter)
+	ret0, _ := ret[0].([]domain.PriceLogEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read.
+func (mr *MockPriceLogRepositoryMockRecorder) Read(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockPriceLogRepository)(nil).Read), ctx, filter)
+}
+
+// MockAuditRepository is a mock of AuditRepository interface.
+type MockAuditRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockAuditRepositoryMockRecorder is the mock recorder for MockAuditRepository.
+type MockAuditRepositoryMockRecorder struct {
+	mock *MockAuditRepository
+}
+
+// NewMockAuditRepository creates a new mock instance.
+func NewMockAuditRepository(ctrl *gomock.Controller) *MockAuditRepository {
+	mock := &MockAuditRepository{ctrl: ctrl}
+	mock.recorder = &MockAuditRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepository) EXPECT() *MockAuditRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Append mocks base method.
+func (m *MockAuditRepository) Append(ctx context.Context, e domain.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Append", ctx, e)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Append indicates an expected call of Append.
+func (mr *MockAuditRepositoryMockRecorder) Append(ctx, e any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Append", reflect.TypeOf((*MockAuditRepository)(nil).Append), ctx, e)
+}
+
+// List mocks base method.
+func (m *MockAuditRepository) List(ctx context.Context, filter repositories.AuditFilter) ([]domain.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]domain.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAuditRepositoryMockRecorder) List(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAuditRepository)(nil).List), ctx, filter)
+}
+
+// MockStatsRepository is a mock of StatsRepository interface.
+type MockStatsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatsRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockStatsRepositoryMockRecorder is the mock recorder for MockStatsRepository.
+type MockStatsRepositoryMockRecorder struct {
+	mock *MockStatsRepository
+}
+
+// NewMockStatsRepository creates a new mock instance.
+func NewMockStatsRepository(ctrl *gomock.Controller) *MockStatsRepository {
+	mock := &MockStatsRepository{ctrl: ctrl}
+	mock.recorder = &MockStatsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatsRepository) EXPECT() *MockStatsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CatalogStats mocks base method.
+func (m *MockStatsRepository) CatalogStats(ctx context.Context) (domain.CatalogStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CatalogStats", ctx)
+	ret0, _ := ret[0].(domain.CatalogStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CatalogStats indicates an expected call of CatalogStats.
+func (mr *MockStatsRepositoryMockRecorder) CatalogStats(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CatalogStats", reflect.TypeOf((*MockStatsRepository)(nil).CatalogStats), ctx)
+}
+
+// MockIntegrityRepository is a mock of IntegrityRepository interface.
+type MockIntegrityRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIntegrityRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockIntegrityRepositoryMockRecorder is the mock recorder for MockIntegrityRepository.
+type MockIntegrityRepositoryMockRecorder struct {
+	mock *MockIntegrityRepository
+}
+
+// NewMockIntegrityRepository creates a new mock instance.
+func NewMockIntegrityRepository(ctrl *gomock.Controller) *MockIntegrityRepository {
+	mock := &MockIntegrityRepository{ctrl: ctrl}
+	mock.recorder = &MockIntegrityRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIntegrityRepository) EXPECT() *MockIntegrityRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CurrencyMismatches mocks base method.
+func (m *MockIntegrityRepository) CurrencyMismatches(ctx context.Context, since time.Time, limit int) ([]domain.IntegrityFinding, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CurrencyMismatches", ctx, since, limit)
+	ret0, _ := ret[0].([]domain.IntegrityFinding)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CurrencyMismatches indicates an expected call of CurrencyMismatches.
+func (mr *MockIntegrityRepositoryMockRecorder) CurrencyMismatches(ctx, since, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CurrencyMismatches", reflect.TypeOf((*MockIntegrityRepository)(nil).CurrencyMismatches), ctx, since, limit)
+}
+
+// OrphanVariants mocks base method.
+func (m *MockIntegrityRepository) OrphanVariants(ctx context.Context, limit int) ([]domain.IntegrityFinding, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OrphanVariants", ctx, limit)
+	ret0, _ := ret[0].([]domain.IntegrityFinding)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OrphanVariants indicates an expected call of OrphanVariants.
+func (mr *MockIntegrityRepositoryMockRecorder) OrphanVariants(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrphanVariants", reflect.TypeOf((*MockIntegrityRepository)(nil).OrphanVariants), ctx, limit)
+}
+
+// UnpricedProducts mocks base method.
+func (m *MockIntegrityRepository) UnpricedProducts(ctx context.Context, since time.Time, limit int) ([]domain.IntegrityFinding, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnpricedProducts", ctx, since, limit)
+	ret0, _ := ret[0].([]domain.IntegrityFinding)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnpricedProducts indicates an expected call of UnpricedProducts.
+func (mr *MockIntegrityRepositoryMockRecorder) UnpricedProducts(ctx, since, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnpricedProducts", reflect.TypeOf((*MockIntegrityRepository)(nil).UnpricedProducts), ctx, since, limit)
+}
+
+// MockClickRepository is a mock of ClickRepository interface.
+type MockClickRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockClickRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockClickRepositoryMockRecorder is the mock recorder for MockClickRepository.
+type MockClickRepositoryMockRecorder struct {
+	mock *MockClickRepository
+}
+
+// NewMockClickRepository creates a new mock instance.
+func NewMockClickRepository(ctrl *gomock.Controller) *MockClickRepository {
+	mock := &MockClickRepository{ctrl: ctrl}
+	mock.recorder = &MockClickRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClickRepository) EXPECT() *MockClickRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountClicks mocks base method.
+func (m *MockClickRepository) CountClicks(ctx context.Context, filter repositories.ClickFilter) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountClicks", ctx, filter)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountClicks indicates an expected call of CountClicks.
+func (mr *MockClickRepositoryMockRecorder) CountClicks(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountClicks", reflect.TypeOf((*MockClickRepository)(nil).CountClicks), ctx, filter)
+}
+
+// RecordClicks mocks base method.
+func (m *MockClickRepository) RecordClicks(ctx context.Context, events []domain.ClickEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordClicks", ctx, events)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordClicks indicates an expected call of RecordClicks.
+func (mr *MockClickRepositoryMockRecorder) RecordClicks(ctx, events any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordClicks", reflect.TypeOf((*MockClickRepository)(nil).RecordClicks), ctx, events)
+}
+
+// MockUserDataRepository is a mock of UserDataRepository interface.
+type MockUserDataRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserDataRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockUserDataRepositoryMockRecorder is the mock recorder for MockUserDataRepository.
+type MockUserDataRepositoryMockRecorder struct {
+	mock *MockUserDataRepository
+}
+
+// NewMockUserDataRepository creates a new mock instance.
+func NewMockUserDataRepository(ctrl *gomock.Controller) *MockUserDataRepository {
+	mock := &MockUserDataRepository{ctrl: ctrl}
+	mock.recorder = &MockUserDataRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserDataRepository) EXPECT() *MockUserDataRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteUserData mocks base method.
+func (m *MockUserDataRepository) DeleteUserData(ctx context.Context, userID string) (map[string]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserData", ctx, userID)
+	ret0, _ := ret[0].(map[string]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUserData indicates an expected call of DeleteUserData.
+func (mr *MockUserDataRepositoryMockRecorder) DeleteUserData(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserData", reflect.TypeOf((*MockUserDataRepository)(nil).DeleteUserData), ctx, userID)
+}
+
+// ExportUserData mocks base method.
+func (m *MockUserDataRepository) ExportUserData(ctx context.Context, userID string) (*domain.UserData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportUserData", ctx, userID)
+	ret0, _ := ret[0].(*domain.UserData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportUserData indicates an expected call of ExportUserData.
+func (mr *MockUserDataRepositoryMockRecorder) ExportUserData(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportUserData", reflect.TypeOf((*MockUserDataRepository)(nil).ExportUserData), ctx, userID)
+}
+
+// MockDataRequestRepository is a mock of DataRequestRepository interface.
+type MockDataRequestRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataRequestRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockDataRequestRepositoryMockRecorder is the mock recorder for MockDataRequestRepository.
+type MockDataRequestRepositoryMockRecorder struct {
+	mock *MockDataRequestRepository
+}
+
+// NewMockDataRequestRepository creates a new mock instance.
+func NewMockDataRequestRepository(ctrl *gomock.Controller) *MockDataRequestRepository {
+	mock := &MockDataRequestRepository{ctrl: ctrl}
+	mock.recorder = &MockDataRequestRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataRequestRepository) EXPECT() *MockDataRequestRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateDataRequest mocks base method.
+func (m *MockDataRequestRepository) CreateDataRequest(ctx context.Context, r domain.DataRequest) (domain.DataRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDataRequest", ctx, r)
+	ret0, _ := ret[0].(domain.DataRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDataRequest indicates an expected call of CreateDataRequest.
+func (mr *MockDataRequestRepositoryMockRecorder) CreateDataRequest(ctx, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDataRequest", reflect.TypeOf((*MockDataRequestRepository)(nil).CreateDataRequest), ctx, r)
+}
+
+// DataRequest mocks base method.
+func (m *MockDataRequestRepository) DataRequest(ctx context.Context, id string) (*domain.DataRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DataRequest", ctx, id)
+	ret0, _ := ret[0].(*domain.DataRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DataRequest indicates an expected call of DataRequest.
+func (mr *MockDataRequestRepositoryMockRecorder) DataRequest(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DataRequest", reflect.TypeOf((*MockDataRequestRepository)(nil).DataRequest), ctx, id)
+}
+
+// PendingDataRequests mocks base method.
+func (m *MockDataRequestRepository) PendingDataRequests(ctx context.Context, limit int) ([]domain.DataRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingDataRequests", ctx, limit)
+	ret0, _ := ret[0].([]domain.DataRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PendingDataRequests indicates an expected call of PendingDataRequests.
+func (mr *MockDataRequestRepositoryMockRecorder) PendingDataRequests(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingDataRequests", reflect.TypeOf((*MockDataRequestRepository)(nil).PendingDataRequests), ctx, limit)
+}
+
+// PurgeExports mocks base method.
+func (m *MockDataRequestRepository) PurgeExports(ctx context.Context, now time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExports", ctx, now)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExports indicates an expected call of PurgeExports.
+func (mr *MockDataRequestRepositoryMockRecorder) PurgeExports(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExports", reflect.TypeOf((*MockDataRequestRepository)(nil).PurgeExports), ctx, now)
+}
+
+// SaveDataRequest mocks base method.
+func (m *MockDataRequestRepository) SaveDataRequest(ctx context.Context, r domain.DataRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDataRequest", ctx, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDataRequest indicates an expected call of SaveDataRequest.
+func (mr *MockDataRequestRepositoryMockRecorder) SaveDataRequest(ctx, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDataRequest", reflect.TypeOf((*MockDataRequestRepository)(nil).SaveDataRequest), ctx, r)
+}
+
+// UserDataRequests mocks base method.
+func (m *MockDataRequestRepository) UserDataRequests(ctx context.Context, userID string) ([]domain.DataRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserDataRequests", ctx, userID)
+	ret0, _ := ret[0].([]domain.DataRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserDataRequests indicates an expected call of UserDataRequests.
+func (mr *MockDataRequestRepositoryMockRecorder) UserDataRequests(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserDataRequests", reflect.TypeOf((*MockDataRequestRepository)(nil).UserDataRequests), ctx, userID)
+}
+
+// MockViewRepository is a mock of ViewRepository interface.
+type MockViewRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockViewRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockViewRepositoryMockRecorder is the mock recorder for MockViewRepository.
+type MockViewRepositoryMockRecorder struct {
+	mock *MockViewRepository
+}
+
+// NewMockViewRepository creates a new mock instance.
+func NewMockViewRepository(ctrl *gomock.Controller) *MockViewRepository {
+	mock := &MockViewRepository{ctrl: ctrl}
+	mock.recorder = &MockViewRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockViewRepository) EXPECT() *MockViewRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Comparison mocks base method.
+func (m *MockViewRepository) Comparison(ctx context.Context, productID string) (*domain.Comparison, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Comparison", ctx, productID)
+	ret0, _ := ret[0].(*domain.Comparison)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Comparison indicates an expected call of Comparison.
+func (mr *MockViewRepositoryMockRecorder) Comparison(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Comparison", reflect.TypeOf((*MockViewRepository)(nil).Comparison), ctx, productID)
+}
+
+// Comparisons mocks base method.
+func (m *MockViewRepository) Comparisons(ctx context.Context, productIDs []string) (map[string]domain.Comparison, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Comparisons", ctx, productIDs)
+	ret0, _ := ret[0].(map[string]domain.Comparison)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Comparisons indicates an expected call of Comparisons.
+func (mr *MockViewRepositoryMockRecorder) Comparisons(ctx, productIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Comparisons", reflect.TypeOf((*MockViewRepository)(nil).Comparisons), ctx, productIDs)
+}
+
+// DeleteProductView mocks base method.
+func (m *MockViewRepository) DeleteProductView(ctx context.Context, productID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProductView", ctx, productID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProductView indicates an expected call of DeleteProductView.
+func (mr *MockViewRepositoryMockRecorder) DeleteProductView(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProductView", reflect.TypeOf((*MockViewRepository)(nil).DeleteProductView), ctx, productID)
+}
+
+// SaveProductView mocks base method.
+func (m *MockViewRepository) SaveProductView(ctx context.Context, c domain.Comparison, deal *domain.Deal) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveProductView", ctx, c, deal)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveProductView indicates an expected call of SaveProductView.
+func (mr *MockViewRepositoryMockRecorder) SaveProductView(ctx, c, deal any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveProductView", reflect.TypeOf((*MockViewRepository)(nil).SaveProductView), ctx, c, deal)
+}
+
+// TopDeals mocks base method.
+func (m *MockViewRepository) TopDeals(ctx context.Context, filter repositories.DealViewFilter) ([]domain.Deal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TopDeals", ctx, filter)
+	ret0, _ := ret[0].([]domain.Deal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TopDeals indicates an expected call of TopDeals.
+func (mr *MockViewRepositoryMockRecorder) TopDeals(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TopDeals", reflect.TypeOf((*MockViewRepository)(nil).TopDeals), ctx, filter)
+}
+
+// MockUserRepository is a mock of UserRepository interface.
+type MockUserRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockUserRepositoryMockRecorder is the mock recorder for MockUserRepository.
+type MockUserRepositoryMockRecorder struct {
+	mock *MockUserRepository
+}
+
+// NewMockUserRepository creates a new mock instance.
+func NewMockUserRepository(ctrl *gomock.Controller) *MockUserRepository {
+	mock := &MockUserRepository{ctrl: ctrl}
+	mock.recorder = &MockUserRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateUser mocks base method.
+func (m *MockUserRepository) CreateUser(ctx context.Context, u domain.User) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, u)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockUserRepositoryMockRecorder) CreateUser(ctx, u any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserRepository)(nil).CreateUser), ctx, u)
+}
+
+// SaveUser mocks base method.
+func (m *MockUserRepository) SaveUser(ctx context.Context, u domain.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveUser", ctx, u)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveUser indicates an expected call of SaveUser.
+func (mr *MockUserRepositoryMockRecorder) SaveUser(ctx, u any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUser", reflect.TypeOf((*MockUserRepository)(nil).SaveUser), ctx, u)
+}
+
+// UserByEmail mocks base method.
+func (m *MockUserRepository) UserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserByEmail", ctx, email)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserByEmail indicates an expected call of UserByEmail.
+func (mr *MockUserRepositoryMockRecorder) UserByEmail(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserByEmail", reflect.TypeOf((*MockUserRepository)(nil).UserByEmail), ctx, email)
+}
+
+// UserByID mocks base method.
+func (m *MockUserRepository) UserByID(ctx context.Context, id string) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserByID", ctx, id)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserByID indicates an expected call of UserByID.
+func (mr *MockUserRepositoryMockRecorder) UserByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserByID", reflect.TypeOf((*MockUserRepository)(nil).UserByID), ctx, id)
+}
+
+// MockSessionRepository is a mock of SessionRepository interface.
+type MockSessionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSessionRepositoryMockRecorder is the mock recorder for MockSessionRepository.
+type MockSessionRepositoryMockRecorder struct {
+	mock *MockSessionRepository
+}
+
+// NewMockSessionRepository creates a new mock instance.
+func NewMockSessionRepository(ctrl *gomock.Controller) *MockSessionRepository {
+	mock := &MockSessionRepository{ctrl: ctrl}
+	mock.recorder = &MockSessionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSessionRepository) EXPECT() *MockSessionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateSession mocks base method.
+func (m *MockSessionRepository) CreateSession(ctx context.Context, s domain.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", ctx, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockSessionRepositoryMockRecorder) CreateSession(ctx, s any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockSessionRepository)(nil).CreateSession), ctx, s)
+}
+
+// DeleteSession mocks base method.
+func (m *MockSessionRepository) DeleteSession(ctx context.Context, tokenHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSession", ctx, tokenHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSession indicates an expected call of DeleteSession.
+func (mr *MockSessionRepositoryMockRecorder) DeleteSession(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockSessionRepository)(nil).DeleteSession), ctx, tokenHash)
+}
+
+// DeleteUserSessions mocks base method.
+func (m *MockSessionRepository) DeleteUserSessions(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserSessions", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserSessions indicates an expected call of DeleteUserSessions.
+func (mr *MockSessionRepositoryMockRecorder) DeleteUserSessions(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserSessions", reflect.TypeOf((*MockSessionRepository)(nil).DeleteUserSessions), ctx, userID)
+}
+
+// Session mocks base method.
+func (m *MockSessionRepository) Session(ctx context.Context, tokenHash string) (*domain.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Session", ctx, tokenHash)
+	ret0, _ := ret[0].(*domain.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Session indicates an expected call of Session.
+func (mr *MockSessionRepositoryMockRecorder) Session(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Session", reflect.TypeOf((*MockSessionRepository)(nil).Session), ctx, tokenHash)
+}
+
+// MockAPITokenRepository is a mock of APITokenRepository interface.
+type MockAPITokenRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPITokenRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockAPITokenRepositoryMockRecorder is the mock recorder for MockAPITokenRepository.
+type MockAPITokenRepositoryMockRecorder struct {
+	mock *MockAPITokenRepository
+}
+
+// NewMockAPITokenRepository creates a new mock instance.
+func NewMockAPITokenRepository(ctrl *gomock.Controller) *MockAPITokenRepository {
+	mock := &MockAPITokenRepository{ctrl: ctrl}
+	mock.recorder = &MockAPITokenRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPITokenRepository) EXPECT() *MockAPITokenRepositoryMockRecorder {
+	return m.recorder
+}
+
+// APITokenByHash mocks base method.
+func (m *MockAPITokenRepository) APITokenByHash(ctx context.Context, tokenHash string) (*domain.APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "APITokenByHash", ctx, tokenHash)
+	ret0, _ := ret[0].(*domain.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// APITokenByHash indicates an expected call of APITokenByHash.
+func (mr *MockAPITokenRepositoryMockRecorder) APITokenByHash(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "APITokenByHash", reflect.TypeOf((*MockAPITokenRepository)(nil).APITokenByHash), ctx, tokenHash)
+}
+
+// CreateAPIToken mocks base method.
+func (m *MockAPITokenRepository) CreateAPIToken(ctx context.Context, t domain.APIToken) (domain.APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIToken", ctx, t)
+	ret0, _ := ret[0].(domain.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIToken indicates an expected call of CreateAPIToken.
+func (mr *MockAPITokenRepositoryMockRecorder) CreateAPIToken(ctx, t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIToken", reflect.TypeOf((*MockAPITokenRepository)(nil).CreateAPIToken), ctx, t)
+}
+
+// DeleteAPIToken mocks base method.
+func (m *MockAPITokenRepository) DeleteAPIToken(ctx context.Context, userID, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAPIToken", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAPIToken indicates an expected call of DeleteAPIToken.
+func (mr *MockAPITokenRepositoryMockRecorder) DeleteAPIToken(ctx, userID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIToken", reflect.TypeOf((*MockAPITokenRepository)(nil).DeleteAPIToken), ctx, userID, id)
+}
+
+// TouchAPIToken mocks base method.
+func (m *MockAPITokenRepository) TouchAPIToken(ctx context.Context, id string, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAPIToken", ctx, id, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAPIToken indicates an expected call of TouchAPIToken.
+func (mr *MockAPITokenRepositoryMockRecorder) TouchAPIToken(ctx, id, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIToken", reflect.TypeOf((*MockAPITokenRepository)(nil).TouchAPIToken), ctx, id, at)
+}
+
+// UserAPITokens mocks base method.
+func (m *MockAPITokenRepository) UserAPITokens(ctx context.Context, userID string) ([]domain.APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserAPITokens", ctx, userID)
+	ret0, _ := ret[0].([]domain.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserAPITokens indicates an expected call of UserAPITokens.
+func (mr *MockAPITokenRepositoryMockRecorder) UserAPITokens(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserAPITokens", reflect.TypeOf((*MockAPITokenRepository)(nil).UserAPITokens), ctx, userID)
+}
+
+// MockVerificationRepository is a mock of VerificationRepository interface.
+type MockVerificationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockVerificationRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockVerificationRepositoryMockRecorder is the mock recorder for MockVerificationRepository.
+type MockVerificationRepositoryMockRecorder struct {
+	mock *MockVerificationRepository
+}
+
+// NewMockVerificationRepository creates a new mock instance.
+func NewMockVerificationRepository(ctrl *gomock.Controller) *MockVerificationRepository {
+	mock := &MockVerificationRepository{ctrl: ctrl}
+	mock.recorder = &MockVerificationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVerificationRepository) EXPECT() *MockVerificationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ConsumeVerification mocks base method.
+func (m *MockVerificationRepository) ConsumeVerification(ctx context.Context, tokenHash string) (*domain.EmailVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeVerification", ctx, tokenHash)
+	ret0, _ := ret[0].(*domain.EmailVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeVerification indicates an expected call of ConsumeVerification.
+func (mr *MockVerificationRepositoryMockRecorder) ConsumeVerification(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeVerification", reflect.TypeOf((*MockVerificationRepository)(nil).ConsumeVerification), ctx, tokenHash)
+}
+
+// CreateVerification mocks base method.
+func (m *MockVerificationRepository) CreateVerification(ctx context.Context, v domain.EmailVerification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVerification", ctx, v)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateVerification indicates an expected call of CreateVerification.
+func (mr *MockVerificationRepositoryMockRecorder) CreateVerification(ctx, v any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVerification", reflect.TypeOf((*MockVerificationRepository)(nil).CreateVerification), ctx, v)
+}
+
+// MockIdentityRepository is a mock of IdentityRepository interface.
+type MockIdentityRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdentityRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockIdentityRepositoryMockRecorder is the mock recorder for MockIdentityRepository.
+type MockIdentityRepositoryMockRecorder struct {
+	mock *MockIdentityRepository
+}
+
+// NewMockIdentityRepository creates a new mock instance.
+func NewMockIdentityRepository(ctrl *gomock.Controller) *MockIdentityRepository {
+	mock := &MockIdentityRepository{ctrl: ctrl}
+	mock.recorder = &MockIdentityRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdentityRepository) EXPECT() *MockIdentityRepositoryMockRecorder {
+	return m.recorder
+}
+
+// IdentityUser mocks base method.
+func (m *MockIdentityRepository) IdentityUser(ctx context.Context, provider, subject string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IdentityUser", ctx, provider, subject)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IdentityUser indicates an expected call of IdentityUser.
+func (mr *MockIdentityRepositoryMockRecorder) IdentityUser(ctx, provider, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IdentityUser", reflect.TypeOf((*MockIdentityRepository)(nil).IdentityUser), ctx, provider, subject)
+}
+
+// LinkIdentity mocks base method.
+func (m *MockIdentityRepository) LinkIdentity(ctx context.Context, provider, subject, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkIdentity", ctx, provider, subject, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkIdentity indicates an expected call of LinkIdentity.
+func (mr *MockIdentityRepositoryMockRecorder) LinkIdentity(ctx, provider, subject, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkIdentity", reflect.TypeOf((*MockIdentityRepository)(nil).LinkIdentity), ctx, provider, subject, userID)
+}
+
+// MockAlertRepository is a mock of AlertRepository interface.
+type MockAlertRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAlertRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockAlertRepositoryMockRecorder is the mock recorder for MockAlertRepository.
+type MockAlertRepositoryMockRecorder struct {
+	mock *MockAlertRepository
+}
+
+// NewMockAlertRepository creates a new mock instance.
+func NewMockAlertRepository(ctrl *gomock.Controller) *MockAlertRepository {
+	mock := &MockAlertRepository{ctrl: ctrl}
+	mock.recorder = &MockAlertRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAlertRepository) EXPECT() *MockAlertRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Alert mocks base method.
+func (m *MockAlertRepository) Alert(ctx context.Context, id string) (*domain.PriceAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Alert", ctx, id)
+	ret0, _ := ret[0].(*domain.PriceAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Alert indicates an expected call of Alert.
+func (mr *MockAlertRepositoryMockRecorder) Alert(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Alert", reflect.TypeOf((*MockAlertRepository)(nil).Alert), ctx, id)
+}
+
+// CreateAlert mocks base method.
+func (m *MockAlertRepository) CreateAlert(ctx context.Context, a domain.PriceAlert) (domain.PriceAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAlert", ctx, a)
+	ret0, _ := ret[0].(domain.PriceAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAlert indicates an expected call of CreateAlert.
+func (mr *MockAlertRepositoryMockRecorder) CreateAlert(ctx, a any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAlert", reflect.TypeOf((*MockAlertRepository)(nil).CreateAlert), ctx, a)
+}
+
+// DeleteAlert mocks base method.
+func (m *MockAlertRepository) DeleteAlert(ctx context.Context, id string, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAlert", ctx, id, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAlert indicates an expected call of DeleteAlert.
+func (mr *MockAlertRepositoryMockRecorder) DeleteAlert(ctx, id, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAlert", reflect.TypeOf((*MockAlertRepository)(nil).DeleteAlert), ctx, id, at)
+}
+
+// DeletedAlerts mocks base method.
+func (m *MockAlertRepository) DeletedAlerts(ctx context.Context, userID string) ([]domain.PriceAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletedAlerts", ctx, userID)
+	ret0, _ := ret[0].([]domain.PriceAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeletedAlerts indicates an expected call of DeletedAlerts.
+func (mr *MockAlertRepositoryMockRecorder) DeletedAlerts(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletedAlerts", reflect.TypeOf((*MockAlertRepository)(nil).DeletedAlerts), ctx, userID)
+}
+
+// ProductAlerts mocks base method.
+func (m *MockAlertRepository) ProductAlerts(ctx context.Context, productID string) ([]domain.PriceAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProductAlerts", ctx, productID)
+	ret0, _ := ret[0].([]domain.PriceAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProductAlerts indicates an expected call of ProductAlerts.
+func (mr *MockAlertRepositoryMockRecorder) ProductAlerts(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProductAlerts", reflect.TypeOf((*MockAlertRepository)(nil).ProductAlerts), ctx, productID)
+}
+
+// RestoreAlert mocks base method.
+func (m *MockAlertRepository) RestoreAlert(ctx context.Context, id string) (domain.PriceAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreAlert", ctx, id)
+	ret0, _ := ret[0].(domain.PriceAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreAlert indicates an expected call of RestoreAlert.
+func (mr *MockAlertRepositoryMockRecorder) RestoreAlert(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreAlert", reflect.TypeOf((*MockAlertRepository)(nil).RestoreAlert), ctx, id)
+}
+
+// SaveAlert mocks base method.
+func (m *MockAlertRepository) SaveAlert(ctx context.Context, a domain.PriceAlert) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAlert", ctx, a)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAlert indicates an expected call of SaveAlert.
+func (mr *MockAlertRepositoryMockRecorder) SaveAlert(ctx, a any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAlert", reflect.TypeOf((*MockAlertRepository)(nil).SaveAlert), ctx, a)
+}
+
+// UserAlerts mocks base method.
+func (m *MockAlertRepository) UserAlerts(ctx context.Context, userID string) ([]domain.PriceAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserAlerts", ctx, userID)
+	ret0, _ := ret[0].([]domain.PriceAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserAlerts indicates an expected call of UserAlerts.
+func (mr *MockAlertRepositoryMockRecorder) UserAlerts(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserAlerts", reflect.TypeOf((*MockAlertRepository)(nil).UserAlerts), ctx, userID)
+}
+
+// MockSavedSearchRepository is a mock of SavedSearchRepository interface.
+type MockSavedSearchRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSavedSearchRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSavedSearchRepositoryMockRecorder is the mock recorder for MockSavedSearchRepository.
+type MockSavedSearchRepositoryMockRecorder struct {
+	mock *MockSavedSearchRepository
+}
+
+// NewMockSavedSearchRepository creates a new mock instance.
+func NewMockSavedSearchRepository(ctrl *gomock.Controller) *MockSavedSearchRepository {
+	mock := &MockSavedSearchRepository{ctrl: ctrl}
+	mock.recorder = &MockSavedSearchRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSavedSearchRepository) EXPECT() *MockSavedSearchRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AllSavedSearches mocks base method.
+func (m *MockSavedSearchRepository) AllSavedSearches(ctx context.Context) ([]domain.SavedSearch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllSavedSearches", ctx)
+	ret0, _ := ret[0].([]domain.SavedSearch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AllSavedSearches indicates an expected call of AllSavedSearches.
+func (mr *MockSavedSearchRepositoryMockRecorder) AllSavedSearches(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllSavedSearches", reflect.TypeOf((*MockSavedSearchRepository)(nil).AllSavedSearches), ctx)
+}
+
+// CreateSavedSearch mocks base method.
+func (m *MockSavedSearchRepository) CreateSavedSearch(ctx context.Context, s domain.SavedSearch) (domain.SavedSearch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSavedSearch", ctx, s)
+	ret0, _ := ret[0].(domain.SavedSearch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSavedSearch indicates an expected call of CreateSavedSearch.
+func (mr *MockSavedSearchRepositoryMockRecorder) CreateSavedSearch(ctx, s any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSavedSearch", reflect.TypeOf((*MockSavedSearchRepository)(nil).CreateSavedSearch), ctx, s)
+}
+
+// DeleteSavedSearch mocks base method.
+func (m *MockSavedSearchRepository) DeleteSavedSearch(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSavedSearch", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSavedSearch indicates an expected call of DeleteSavedSearch.
+func (mr *MockSavedSearchRepositoryMockRecorder) DeleteSavedSearch(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSavedSearch", reflect.TypeOf((*MockSavedSearchRepository)(nil).DeleteSavedSearch), ctx, id)
+}
+
+// SaveSavedSearch mocks base method.
+func (m *MockSavedSearchRepository) SaveSavedSearch(ctx context.Context, s domain.SavedSearch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSavedSearch", ctx, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSavedSearch indicates an expected call of SaveSavedSearch.
+func (mr *MockSavedSearchRepositoryMockRecorder) SaveSavedSearch(ctx, s any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSavedSearch", reflect.TypeOf((*MockSavedSearchRepository)(nil).SaveSavedSearch), ctx, s)
+}
+
+// SavedSearch mocks base method.
+func (m *MockSavedSearchRepository) SavedSearch(ctx context.Context, id string) (*domain.SavedSearch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavedSearch", ctx, id)
+	ret0, _ := ret[0].(*domain.SavedSearch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SavedSearch indicates an expected call of SavedSearch.
+func (mr *MockSavedSearchRepositoryMockRecorder) SavedSearch(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavedSearch", reflect.TypeOf((*MockSavedSearchRepository)(nil).SavedSearch), ctx, id)
+}
+
+// UserSavedSearches mocks base method.
+func (m *MockSavedSearchRepository) UserSavedSearches(ctx context.Context, userID string) ([]domain.SavedSearch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserSavedSearches", ctx, userID)
+	ret0, _ := ret[0].([]domain.SavedSearch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserSavedSearches indicates an expected call of UserSavedSearches.
+func (mr *MockSavedSearchRepositoryMockRecorder) UserSavedSearches(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserSavedSearches", reflect.TypeOf((*MockSavedSearchRepository)(nil).UserSavedSearches), ctx, userID)
+}
+
+// MockFilterPresetRepository is a mock of FilterPresetRepository interface.
+type MockFilterPresetRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFilterPresetRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockFilterPresetRepositoryMockRecorder is the mock recorder for MockFilterPresetRepository.
+type MockFilterPresetRepositoryMockRecorder struct {
+	mock *MockFilterPresetRepository
+}
+
+// NewMockFilterPresetRepository creates a new mock instance.
+func NewMockFilterPresetRepository(ctrl *gomock.Controller) *MockFilterPresetRepository {
+	mock := &MockFilterPresetRepository{ctrl: ctrl}
+	mock.recorder = &MockFilterPresetRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFilterPresetRepository) EXPECT() *MockFilterPresetRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateFilterPreset mocks base method.
+func (m *MockFilterPresetRepository) CreateFilterPreset(ctx context.Context, p domain.FilterPreset) (domain.FilterPreset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFilterPreset", ctx, p)
+	ret0, _ := ret[0].(domain.FilterPreset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFilterPreset indicates an expected call of CreateFilterPreset.
+func (mr *MockFilterPresetRepositoryMockRecorder) CreateFilterPreset(ctx, p any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFilterPreset", reflect.TypeOf((*MockFilterPresetRepository)(nil).CreateFilterPreset), ctx, p)
+}
+
+// DeleteFilterPreset mocks base method.
+func (m *MockFilterPresetRepository) DeleteFilterPreset(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFilterPreset", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFilterPreset indicates an expected call of DeleteFilterPreset.
+func (mr *MockFilterPresetRepositoryMockRecorder) DeleteFilterPreset(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFilterPreset", reflect.TypeOf((*MockFilterPresetRepository)(nil).DeleteFilterPreset), ctx, id)
+}
+
+// FilterPreset mocks base method.
+func (m *MockFilterPresetRepository) FilterPreset(ctx context.Context, id string) (*domain.FilterPreset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterPreset", ctx, id)
+	ret0, _ := ret[0].(*domain.FilterPreset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FilterPreset indicates an expected call of FilterPreset.
+func (mr *MockFilterPresetRepositoryMockRecorder) FilterPreset(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterPreset", reflect.TypeOf((*MockFilterPresetRepository)(nil).FilterPreset), ctx, id)
+}
+
+// SaveFilterPreset mocks base method.
+func (m *MockFilterPresetRepository) SaveFilterPreset(ctx context.Context, p domain.FilterPreset) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveFilterPreset", ctx, p)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveFilterPreset indicates an expected call of SaveFilterPreset.
+func (mr *MockFilterPresetRepositoryMockRecorder) SaveFilterPreset(ctx, p any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFilterPreset", reflect.TypeOf((*MockFilterPresetRepository)(nil).SaveFilterPreset), ctx, p)
+}
+
+// UserFilterPresets mocks base method.
+func (m *MockFilterPresetRepository) UserFilterPresets(ctx context.Context, userID string) ([]domain.FilterPreset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserFilterPresets", ctx, userID)
+	ret0, _ := ret[0].([]domain.FilterPreset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserFilterPresets indicates an expected call of UserFilterPresets.
+func (mr *MockFilterPresetRepositoryMockRecorder) UserFilterPresets(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserFilterPresets", reflect.TypeOf((*MockFilterPresetRepository)(nil).UserFilterPresets), ctx, userID)
+}
+
+// MockSynonymRepository is a mock of SynonymRepository interface.
+type MockSynonymRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSynonymRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSynonymRepositoryMockRecorder is the mock recorder for MockSynonymRepository.
+type MockSynonymRepositoryMockRecorder struct {
+	mock *MockSynonymRepository
+}
+
+// NewMockSynonymRepository creates a new mock instance.
+func NewMockSynonymRepository(ctrl *gomock.Controller) *MockSynonymRepository {
+	mock := &MockSynonymRepository{ctrl: ctrl}
+	mock.recorder = &MockSynonymRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSynonymRepository) EXPECT() *MockSynonymRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateSynonym mocks base method.
+func (m *MockSynonymRepository) CreateSynonym(ctx context.Context, s domain.Synonym, events ...domain.Event) (domain.Synonym, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, s}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateSynonym", varargs...)
+	ret0, _ := ret[0].(domain.Synonym)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSynonym indicates an expected call of CreateSynonym.
+func (mr *MockSynonymRepositoryMockRecorder) CreateSynonym(ctx, s any, events ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, s}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSynonym", reflect.TypeOf((*MockSynonymRepository)(nil).CreateSynonym), varargs...)
+}
+
+// DeleteSynonym mocks base method.
+func (m *MockSynonymRepository) DeleteSynonym(ctx context.Context, id string, events ...domain.Event) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, id}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteSynonym", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSynonym indicates an expected call of DeleteSynonym.
+func (mr *MockSynonymRepositoryMockRecorder) DeleteSynonym(ctx, id any, events ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, id}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSynonym", reflect.TypeOf((*MockSynonymRepository)(nil).DeleteSynonym), varargs...)
+}
+
+// SaveSynonym mocks base method.
+func (m *MockSynonymRepository) SaveSynonym(ctx context.Context, s domain.Synonym, events ...domain.Event) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, s}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SaveSynonym", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSynonym indicates an expected call of SaveSynonym.
+func (mr *MockSynonymRepositoryMockRecorder) SaveSynonym(ctx, s any, events ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, s}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSynonym", reflect.TypeOf((*MockSynonymRepository)(nil).SaveSynonym), varargs...)
+}
+
+// Synonym mocks base method.
+func (m *MockSynonymRepository) Synonym(ctx context.Context, id string) (*domain.Synonym, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Synonym", ctx, id)
+	ret0, _ := ret[0].(*domain.Synonym)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Synonym indicates an expected call of Synonym.
+func (mr *MockSynonymRepositoryMockRecorder) Synonym(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Synonym", reflect.TypeOf((*MockSynonymRepository)(nil).Synonym), ctx, id)
+}
+
+// Synonyms mocks base method.
+func (m *MockSynonymRepository) Synonyms(ctx context.Context) ([]domain.Synonym, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Synonyms", ctx)
+	ret0, _ := ret[0].([]domain.Synonym)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Synonyms indicates an expected call of Synonyms.
+func (mr *MockSynonymRepositoryMockRecorder) Synonyms(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Synonyms", reflect.TypeOf((*MockSynonymRepository)(nil).Synonyms), ctx)
+}
+
+// MockFlagRepository is a mock of FlagRepository interface.
+type MockFlagRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFlagRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockFlagRepositoryMockRecorder is the mock recorder for MockFlagRepository.
+type MockFlagRepositoryMockRecorder struct {
+	mock *MockFlagRepository
+}
+
+// NewMockFlagRepository creates a new mock instance.
+func NewMockFlagRepository(ctrl *gomock.Controller) *MockFlagRepository {
+	mock := &MockFlagRepository{ctrl: ctrl}
+	mock.recorder = &MockFlagRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFlagRepository) EXPECT() *MockFlagRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteFlag mocks base method.
+func (m *MockFlagRepository) DeleteFlag(ctx context.Context, name string, events ...domain.Event) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, name}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteFlag", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFlag indicates an expected call of DeleteFlag.
+func (mr *MockFlagRepositoryMockRecorder) DeleteFlag(ctx, name any, events ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, name}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFlag", reflect.TypeOf((*MockFlagRepository)(nil).DeleteFlag), varargs...)
+}
+
+// Flag mocks base method.
+func (m *MockFlagRepository) Flag(ctx context.Context, name string) (*domain.Flag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Flag", ctx, name)
+	ret0, _ := ret[0].(*domain.Flag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Flag indicates an expected call of Flag.
+func (mr *MockFlagRepositoryMockRecorder) Flag(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flag", reflect.TypeOf((*MockFlagRepository)(nil).Flag), ctx, name)
+}
+
+// Flags mocks base method.
+func (m *MockFlagRepository) Flags(ctx context.Context) ([]domain.Flag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Flags", ctx)
+	ret0, _ := ret[0].([]domain.Flag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Flags indicates an expected call of Flags.
+func (mr *MockFlagRepositoryMockRecorder) Flags(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flags", reflect.TypeOf((*MockFlagRepository)(nil).Flags), ctx)
+}
+
+// SaveFlag mocks base method.
+func (m *MockFlagRepository) SaveFlag(ctx context.Context, f domain.Flag, events ...domain.Event) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, f}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SaveFlag", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveFlag indicates an expected call of SaveFlag.
+func (mr *MockFlagRepositoryMockRecorder) SaveFlag(ctx, f any, events ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, f}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFlag", reflect.TypeOf((*MockFlagRepository)(nil).SaveFlag), varargs...)
+}
+
+// MockSearchLogRepository is a mock of SearchLogRepository interface.
+type MockSearchLogRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSearchLogRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSearchLogRepositoryMockRecorder is the mock recorder for MockSearchLogRepository.
+type MockSearchLogRepositoryMockRecorder struct {
+	mock *MockSearchLogRepository
+}
+
+// NewMockSearchLogRepository creates a new mock instance.
+func NewMockSearchLogRepository(ctrl *gomock.Controller) *MockSearchLogRepository {
+	mock := &MockSearchLogRepository{ctrl: ctrl}
+	mock.recorder = &MockSearchLogRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSearchLogRepository) EXPECT() *MockSearchLogRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteSearchLogsBefore mocks base method.
+func (m *MockSearchLogRepository) DeleteSearchLogsBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSearchLogsBefore", ctx, cutoff)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteSearchLogsBefore indicates an expected call of DeleteSearchLogsBefore.
+func (mr *MockSearchLogRepositoryMockRecorder) DeleteSearchLogsBefore(ctx, cutoff any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSearchLogsBefore", reflect.TypeOf((*MockSearchLogRepository)(nil).DeleteSearchLogsBefore), ctx, cutoff)
+}
+
+// RecordSearchClicks mocks base method.
+func (m *MockSearchLogRepository) RecordSearchClicks(ctx context.Context, clicks []domain.SearchClick) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordSearchClicks", ctx, clicks)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordSearchClicks indicates an expected call of RecordSearchClicks.
+func (mr *MockSearchLogRepositoryMockRecorder) RecordSearchClicks(ctx, clicks any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordSearchClicks", reflect.TypeOf((*MockSearchLogRepository)(nil).RecordSearchClicks), ctx, clicks)
+}
+
+// RecordSearches mocks base method.
+func (m *MockSearchLogRepository) RecordSearches(ctx context.Context, logs []domain.SearchLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordSearches", ctx, logs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordSearches indicates an expected call of RecordSearches.
+func (mr *MockSearchLogRepositoryMockRecorder) RecordSearches(ctx, logs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordSearches", reflect.TypeOf((*MockSearchLogRepository)(nil).RecordSearches), ctx, logs)
+}
+
+// SearchClicksSince mocks base method.
+func (m *MockSearchLogRepository) SearchClicksSince(ctx context.Context, since time.Time) ([]domain.SearchClick, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchClicksSince", ctx, since)
+	ret0, _ := ret[0].([]domain.SearchClick)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchClicksSince indicates an expected call of SearchClicksSince.
+func (mr *MockSearchLogRepositoryMockRecorder) SearchClicksSince(ctx, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchClicksSince", reflect.TypeOf((*MockSearchLogRepository)(nil).SearchClicksSince), ctx, since)
+}
+
+// SearchesSince mocks base method.
+func (m *MockSearchLogRepository) SearchesSince(ctx context.Context, since time.Time) ([]domain.SearchLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchesSince", ctx, since)
+	ret0, _ := ret[0].([]domain.SearchLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchesSince indicates an expected call of SearchesSince.
+func (mr *MockSearchLogRepositoryMockRecorder) SearchesSince(ctx, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchesSince", reflect.TypeOf((*MockSearchLogRepository)(nil).SearchesSince), ctx, since)
+}
+
+// MockNotificationQueue is a mock of NotificationQueue interface.
+type MockNotificationQueue struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationQueueMockRecorder
+	isgomock struct{}
+}
+
+// MockNotificationQueueMockRecorder is the mock recorder for MockNotificationQueue.
+type MockNotificationQueueMockRecorder struct {
+	mock *MockNotificationQueue
+}
+
+// NewMockNotificationQueue creates a new mock instance.
+func NewMockNotificationQueue(ctrl *gomock.Controller) *MockNotificationQueue {
+	mock := &MockNotificationQueue{ctrl: ctrl}
+	mock.recorder = &MockNotificationQueueMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationQueue) EXPECT() *MockNotificationQueueMockRecorder {
+	return m.recorder
+}
+
+// Defer mocks base method.
+func (m *MockNotificationQueue) Defer(ctx context.Context, until time.Time, ids ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, until}
+	for _, a := range ids {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Defer", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Defer indicates an expected call of Defer.
+func (mr *MockNotificationQueueMockRecorder) Defer(ctx, until any, ids ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, until}, ids...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Defer", reflect.TypeOf((*MockNotificationQueue)(nil).Defer), varargs...)
+}
+
+// Due mocks base method.
+func (m *MockNotificationQueue) Due(ctx context.Context, now time.Time) ([]domain.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Due", ctx, now)
+	ret0, _ := ret[0].([]domain.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Due indicates an expected call of Due.
+func (mr *MockNotificationQueueMockRecorder) Due(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Due", reflect.TypeOf((*MockNotificationQueue)(nil).Due), ctx, now)
+}
+
+// Enqueue mocks base method.
+func (m *MockNotificationQueue) Enqueue(ctx context.Context, notifications ...domain.Notification) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range notifications {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Enqueue", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MockNotificationQueueMockRecorder) Enqueue(ctx any, notifications ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, notifications...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockNotificationQueue)(nil).Enqueue), varargs...)
+}
+
+// Hold mocks base method.
+func (m *MockNotificationQueue) Hold(ctx context.Context, until time.Time, ids ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, until}
+	for _, a := range ids {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Hold", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Hold indicates an expected call of Hold.
+func (mr *MockNotificationQueueMockRecorder) Hold(ctx, until any, ids ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, until}, ids...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hold", reflect.TypeOf((*MockNotificationQueue)(nil).Hold), varargs...)
+}
+
+// MarkSent mocks base method.
+func (m *MockNotificationQueue) MarkSent(ctx context.Context, at time.Time, ids ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, at}
+	for _, a := range ids {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "MarkSent", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkSent indicates an expected call of MarkSent.
+func (mr *MockNotificationQueueMockRecorder) MarkSent(ctx, at any, ids ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, at}, ids...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSent", reflect.TypeOf((*MockNotificationQueue)(nil).MarkSent), varargs...)
+}
+
+// Pending mocks base method.
+func (m *MockNotificationQueue) Pending(ctx context.Context, limit int) ([]domain.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pending", ctx, limit)
+	ret0, _ := ret[0].([]domain.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Pending indicates an expected call of Pending.
+func (mr *MockNotificationQueueMockRecorder) Pending(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pending", reflect.TypeOf((*MockNotificationQueue)(nil).Pending), ctx, limit)
+}
+
+// Release mocks base method.
+func (m *MockNotificationQueue) Release(ctx context.Context, now time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, now)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockNotificationQueueMockRecorder) Release(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockNotificationQueue)(nil).Release), ctx, now)
+}
+
+// MockEngagementRepository is a mock of EngagementRepository interface.
+type MockEngagementRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEngagementRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockEngagementRepositoryMockRecorder is the mock recorder for MockEngagementRepository.
+type MockEngagementRepositoryMockRecorder struct {
+	mock *MockEngagementRepository
+}
+
+// NewMockEngagementRepository creates a new mock instance.
+func NewMockEngagementRepository(ctrl *gomock.Controller) *MockEngagementRepository {
+	mock := &MockEngagementRepository{ctrl: ctrl}
+	mock.recorder = &MockEngagementRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEngagementRepository) EXPECT() *MockEngagementRepositoryMockRecorder {
+	return m.recorder
+}
+
+// EngagementSince mocks base method.
+func (m *MockEngagementRepository) EngagementSince(ctx context.Context, since time.Time) ([]domain.EngagementEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EngagementSince", ctx, since)
+	ret0, _ := ret[0].([]domain.EngagementEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EngagementSince indicates an expected call of EngagementSince.
+func (mr *MockEngagementRepositoryMockRecorder) EngagementSince(ctx, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EngagementSince", reflect.TypeOf((*MockEngagementRepository)(nil).EngagementSince), ctx, since)
+}
+
+// RecordEngagement mocks base method.
+func (m *MockEngagementRepository) RecordEngagement(ctx context.Context, e domain.EngagementEvent) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordEngagement", ctx, e)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordEngagement indicates an expected call of RecordEngagement.
+func (mr *MockEngagementRepositoryMockRecorder) RecordEngagement(ctx, e any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordEngagement", reflect.TypeOf((*MockEngagementRepository)(nil).RecordEngagement), ctx, e)
+}
+
+// MockDeliveryRepository is a mock of DeliveryRepository interface.
+type MockDeliveryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeliveryRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockDeliveryRepositoryMockRecorder is the mock recorder for MockDeliveryRepository.
+type MockDeliveryRepositoryMockRecorder struct {
+	mock *MockDeliveryRepository
+}
+
+// NewMockDeliveryRepository creates a new mock instance.
+func NewMockDeliveryRepository(ctrl *gomock.Controller) *MockDeliveryRepository {
+	mock := &MockDeliveryRepository{ctrl: ctrl}
+	mock.recorder = &MockDeliveryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeliveryRepository) EXPECT() *MockDeliveryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteFailedDelivery mocks base method.
+func (m *MockDeliveryRepository) DeleteFailedDelivery(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFailedDelivery", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFailedDelivery indicates an expected call of DeleteFailedDelivery.
+func (mr *MockDeliveryRepositoryMockRecorder) DeleteFailedDelivery(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFailedDelivery", reflect.TypeOf((*MockDeliveryRepository)(nil).DeleteFailedDelivery), ctx, id)
+}
+
+// DueDeliveries mocks base method.
+func (m *MockDeliveryRepository) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]domain.FailedDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DueDeliveries", ctx, now, limit)
+	ret0, _ := ret[0].([]domain.FailedDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DueDeliveries indicates an expected call of DueDeliveries.
+func (mr *MockDeliveryRepositoryMockRecorder) DueDeliveries(ctx, now, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DueDeliveries", reflect.TypeOf((*MockDeliveryRepository)(nil).DueDeliveries), ctx, now, limit)
+}
+
+// FailedDeliveries mocks base method.
+func (m *MockDeliveryRepository) FailedDeliveries(ctx context.Context, status string, limit int) ([]domain.FailedDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailedDeliveries", ctx, status, limit)
+	ret0, _ := ret[0].([]domain.FailedDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailedDeliveries indicates an expected call of FailedDeliveries.
+func (mr *MockDeliveryRepositoryMockRecorder) FailedDeliveries(ctx, status, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailedDeliveries", reflect.TypeOf((*MockDeliveryRepository)(nil).FailedDeliveries), ctx, status, limit)
+}
+
+// FailedDelivery mocks base method.
+func (m *MockDeliveryRepository) FailedDelivery(ctx context.Context, id string) (*domain.FailedDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailedDelivery", ctx, id)
+	ret0, _ := ret[0].(*domain.FailedDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailedDelivery indicates an expected call of FailedDelivery.
+func (mr *MockDeliveryRepositoryMockRecorder) FailedDelivery(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailedDelivery", reflect.TypeOf((*MockDeliveryRepository)(nil).FailedDelivery), ctx, id)
+}
+
+// SaveFailedDelivery mocks base method.
+func (m *MockDeliveryRepository) SaveFailedDelivery(ctx context.Context, d domain.FailedDelivery) (domain.FailedDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveFailedDelivery", ctx, d)
+	ret0, _ := ret[0].(domain.FailedDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveFailedDelivery indicates an expected call of SaveFailedDelivery.
+func (mr *MockDeliveryRepositoryMockRecorder) SaveFailedDelivery(ctx, d any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFailedDelivery", reflect.TypeOf((*MockDeliveryRepository)(nil).SaveFailedDelivery), ctx, d)
+}
+
+// MockPreferenceRepository is a mock of PreferenceRepository interface.
+type MockPreferenceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPreferenceRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPreferenceRepositoryMockRecorder is the mock recorder for MockPreferenceRepository.
+type MockPreferenceRepositoryMockRecorder struct {
+	mock *MockPreferenceRepository
+}
+
+// NewMockPreferenceRepository creates a new mock instance.
+func NewMockPreferenceRepository(ctrl *gomock.Controller) *MockPreferenceRepository {
+	mock := &MockPreferenceRepository{ctrl: ctrl}
+	mock.recorder = &MockPreferenceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPreferenceRepository) EXPECT() *MockPreferenceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// NotificationPreferences mocks base method.
+func (m *MockPreferenceRepository) NotificationPreferences(ctx context.Context, userID string) (*domain.NotificationPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotificationPreferences", ctx, userID)
+	ret0, _ := ret[0].(*domain.NotificationPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NotificationPreferences indicates an expected call of NotificationPreferences.
+func (mr *MockPreferenceRepositoryMockRecorder) NotificationPreferences(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotificationPreferences", reflect.TypeOf((*MockPreferenceRepository)(nil).NotificationPreferences), ctx, userID)
+}
+
+// SaveNotificationPreferences mocks base method.
+func (m *MockPreferenceRepository) SaveNotificationPreferences(ctx context.Context, p domain.NotificationPreferences) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveNotificationPreferences", ctx, p)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveNotificationPreferences indicates an expected call of SaveNotificationPreferences.
+func (mr *MockPreferenceRepositoryMockRecorder) SaveNotificationPreferences(ctx, p any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveNotificationPreferences", reflect.TypeOf((*MockPreferenceRepository)(nil).SaveNotificationPreferences), ctx, p)
+}
+
+// MockSuppressionRepository is a mock of SuppressionRepository interface.
+type MockSuppressionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSuppressionRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSuppressionRepositoryMockRecorder is the mock recorder for MockSuppressionRepository.
+type MockSuppressionRepositoryMockRecorder struct {
+	mock *MockSuppressionRepository
+}
+
+// NewMockSuppressionRepository creates a new mock instance.
+func NewMockSuppressionRepository(ctrl *gomock.Controller) *MockSuppressionRepository {
+	mock := &MockSuppressionRepository{ctrl: ctrl}
+	mock.recorder = &MockSuppressionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSuppressionRepository) EXPECT() *MockSuppressionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Suppress mocks base method.
+func (m *MockSuppressionRepository) Suppress(ctx context.Context, s domain.Suppression) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Suppress", ctx, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Suppress indicates an expected call of Suppress.
+func (mr *MockSuppressionRepositoryMockRecorder) Suppress(ctx, s any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Suppress", reflect.TypeOf((*MockSuppressionRepository)(nil).Suppress), ctx, s)
+}
+
+// Suppression mocks base method.
+func (m *MockSuppressionRepository) Suppression(ctx context.Context, email string) (*domain.Suppression, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Suppression", ctx, email)
+	ret0, _ := ret[0].(*domain.Suppression)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Suppression indicates an expected call of Suppression.
+func (mr *MockSuppressionRepositoryMockRecorder) Suppression(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Suppression", reflect.TypeOf((*MockSuppressionRepository)(nil).Suppression), ctx, email)
+}
+
+// MockSMSRepository is a mock of SMSRepository interface.
+type MockSMSRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSMSRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSMSRepositoryMockRecorder is the mock recorder for MockSMSRepository.
+type MockSMSRepositoryMockRecorder struct {
+	mock *MockSMSRepository
+}
+
+// NewMockSMSRepository creates a new mock instance.
+func NewMockSMSRepository(ctrl *gomock.Controller) *MockSMSRepository {
+	mock := &MockSMSRepository{ctrl: ctrl}
+	mock.recorder = &MockSMSRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSMSRepository) EXPECT() *MockSMSRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteSMSSubscription mocks base method.
+func (m *MockSMSRepository) DeleteSMSSubscription(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSMSSubscription", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSMSSubscription indicates an expected call of DeleteSMSSubscription.
+func (mr *MockSMSRepositoryMockRecorder) DeleteSMSSubscription(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSMSSubscription", reflect.TypeOf((*MockSMSRepository)(nil).DeleteSMSSubscription), ctx, userID)
+}
+
+// RecordSMS mocks base method.
+func (m_2 *MockSMSRepository) RecordSMS(ctx context.Context, m domain.SMSMessage) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecordSMS", ctx, m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordSMS indicates an expected call of RecordSMS.
+func (mr *MockSMSRepositoryMockRecorder) RecordSMS(ctx, m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordSMS", reflect.TypeOf((*MockSMSRepository)(nil).RecordSMS), ctx, m)
+}
+
+// SMSSpend mocks base method.
+func (m *MockSMSRepository) SMSSpend(ctx context.Context, userID string, since time.Time) (int, float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SMSSpend", ctx, userID, since)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(float64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SMSSpend indicates an expected call of SMSSpend.
+func (mr *MockSMSRepositoryMockRecorder) SMSSpend(ctx, userID, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SMSSpend", reflect.TypeOf((*MockSMSRepository)(nil).SMSSpend), ctx, userID, since)
+}
+
+// SMSSubscription mocks base method.
+func (m *MockSMSRepository) SMSSubscription(ctx context.Context, userID string) (*domain.SMSSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SMSSubscription", ctx, userID)
+	ret0, _ := ret[0].(*domain.SMSSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SMSSubscription indicates an expected call of SMSSubscription.
+func (mr *MockSMSRepositoryMockRecorder) SMSSubscription(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SMSSubscription", reflect.TypeOf((*MockSMSRepository)(nil).SMSSubscription), ctx, userID)
+}
+
+// SaveSMSSubscription mocks base method.
+func (m *MockSMSRepository) SaveSMSSubscription(ctx context.Context, s domain.SMSSubscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSMSSubscription", ctx, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSMSSubscription indicates an expected call of SaveSMSSubscription.
+func (mr *MockSMSRepositoryMockRecorder) SaveSMSSubscription(ctx, s any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSMSSubscription", reflect.TypeOf((*MockSMSRepository)(nil).SaveSMSSubscription), ctx, s)
+}
+
+// MockDiscordRepository is a mock of DiscordRepository interface.
+type MockDiscordRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDiscordRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockDiscordRepositoryMockRecorder is the mock recorder for MockDiscordRepository.
+type MockDiscordRepositoryMockRecorder struct {
+	mock *MockDiscordRepository
+}
+
+// NewMockDiscordRepository creates a new mock instance.
+func NewMockDiscordRepository(ctrl *gomock.Controller) *MockDiscordRepository {
+	mock := &MockDiscordRepository{ctrl: ctrl}
+	mock.recorder = &MockDiscordRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDiscordRepository) EXPECT() *MockDiscordRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteDiscordWebhook mocks base method.
+func (m *MockDiscordRepository) DeleteDiscordWebhook(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDiscordWebhook", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDiscordWebhook indicates an expected call of DeleteDiscordWebhook.
+func (mr *MockDiscordRepositoryMockRecorder) DeleteDiscordWebhook(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDiscordWebhook", reflect.TypeOf((*MockDiscordRepository)(nil).DeleteDiscordWebhook), ctx, userID)
+}
+
+// DiscordWebhook mocks base method.
+func (m *MockDiscordRepository) DiscordWebhook(ctx context.Context, userID string) (*domain.DiscordWebhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiscordWebhook", ctx, userID)
+	ret0, _ := ret[0].(*domain.DiscordWebhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiscordWebhook indicates an expected call of DiscordWebhook.
+func (mr *MockDiscordRepositoryMockRecorder) DiscordWebhook(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiscordWebhook", reflect.TypeOf((*MockDiscordRepository)(nil).DiscordWebhook), ctx, userID)
+}
+
+// SaveDiscordWebhook mocks base method.
+func (m *MockDiscordRepository) SaveDiscordWebhook(ctx context.Context, w domain.DiscordWebhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDiscordWebhook", ctx, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDiscordWebhook indicates an expected call of SaveDiscordWebhook.
+func (mr *MockDiscordRepositoryMockRecorder) SaveDiscordWebhook(ctx, w any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDiscordWebhook", reflect.TypeOf((*MockDiscordRepository)(nil).SaveDiscordWebhook), ctx, w)
+}
+
+// MockSlackRepository is a mock of SlackRepository interface.
+type MockSlackRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSlackRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSlackRepositoryMockRecorder is the mock recorder for MockSlackRepository.
+type MockSlackRepositoryMockRecorder struct {
+	mock *MockSlackRepository
+}
+
+// NewMockSlackRepository creates a new mock instance.
+func NewMockSlackRepository(ctrl *gomock.Controller) *MockSlackRepository {
+	mock := &MockSlackRepository{ctrl: ctrl}
+	mock.recorder = &MockSlackRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSlackRepository) EXPECT() *MockSlackRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteSlackWebhook mocks base method.
+func (m *MockSlackRepository) DeleteSlackWebhook(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSlackWebhook", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSlackWebhook indicates an expected call of DeleteSlackWebhook.
+func (mr *MockSlackRepositoryMockRecorder) DeleteSlackWebhook(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSlackWebhook", reflect.TypeOf((*MockSlackRepository)(nil).DeleteSlackWebhook), ctx, userID)
+}
+
+// SaveSlackWebhook mocks base method.
+func (m *MockSlackRepository) SaveSlackWebhook(ctx context.Context, w domain.SlackWebhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSlackWebhook", ctx, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSlackWebhook indicates an expected call of SaveSlackWebhook.
+func (mr *MockSlackRepositoryMockRecorder) SaveSlackWebhook(ctx, w any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSlackWebhook", reflect.TypeOf((*MockSlackRepository)(nil).SaveSlackWebhook), ctx, w)
+}
+
+// SlackWebhook mocks base method.
+func (m *MockSlackRepository) SlackWebhook(ctx context.Context, userID string) (*domain.SlackWebhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SlackWebhook", ctx, userID)
+	ret0, _ := ret[0].(*domain.SlackWebhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SlackWebhook indicates an expected call of SlackWebhook.
+func (mr *MockSlackRepositoryMockRecorder) SlackWebhook(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SlackWebhook", reflect.TypeOf((*MockSlackRepository)(nil).SlackWebhook), ctx, userID)
+}
+
+// MockTelegramRepository is a mock of TelegramRepository interface.
+type MockTelegramRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTelegramRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockTelegramRepositoryMockRecorder is the mock recorder for MockTelegramRepository.
+type MockTelegramRepositoryMockRecorder struct {
+	mock *MockTelegramRepository
+}
+
+// NewMockTelegramRepository creates a new mock instance.
+func NewMockTelegramRepository(ctrl *gomock.Controller) *MockTelegramRepository {
+	mock := &MockTelegramRepository{ctrl: ctrl}
+	mock.recorder = &MockTelegramRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTelegramRepository) EXPECT() *MockTelegramRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ChatLink mocks base method.
+func (m *MockTelegramRepository) ChatLink(ctx context.Context, chatID int64) (*domain.TelegramLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChatLink", ctx, chatID)
+	ret0, _ := ret[0].(*domain.TelegramLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChatLink indicates an expected call of ChatLink.
+func (mr *MockTelegramRepositoryMockRecorder) ChatLink(ctx, chatID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChatLink", reflect.TypeOf((*MockTelegramRepository)(nil).ChatLink), ctx, chatID)
+}
+
+// ConsumeLinkToken mocks base method.
+func (m *MockTelegramRepository) ConsumeLinkToken(ctx context.Context, tokenHash string) (*domain.TelegramLinkToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeLinkToken", ctx, tokenHash)
+	ret0, _ := ret[0].(*domain.TelegramLinkToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeLinkToken indicates an expected call of ConsumeLinkToken.
+func (mr *MockTelegramRepositoryMockRecorder) ConsumeLinkToken(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeLinkToken", reflect.TypeOf((*MockTelegramRepository)(nil).ConsumeLinkToken), ctx, tokenHash)
+}
+
+// CreateLinkToken mocks base method.
+func (m *MockTelegramRepository) CreateLinkToken(ctx context.Context, t domain.TelegramLinkToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLinkToken", ctx, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateLinkToken indicates an expected call of CreateLinkToken.
+func (mr *MockTelegramRepositoryMockRecorder) CreateLinkToken(ctx, t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLinkToken", reflect.TypeOf((*MockTelegramRepository)(nil).CreateLinkToken), ctx, t)
+}
+
+// LinkChat mocks base method.
+func (m *MockTelegramRepository) LinkChat(ctx context.Context, l domain.TelegramLink) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkChat", ctx, l)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkChat indicates an expected call of LinkChat.
+func (mr *MockTelegramRepositoryMockRecorder) LinkChat(ctx, l any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkChat", reflect.TypeOf((*MockTelegramRepository)(nil).LinkChat), ctx, l)
+}
+
+// UnlinkUser mocks base method.
+func (m *MockTelegramRepository) UnlinkUser(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlinkUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlinkUser indicates an expected call of UnlinkUser.
+func (mr *MockTelegramRepositoryMockRecorder) UnlinkUser(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlinkUser", reflect.TypeOf((*MockTelegramRepository)(nil).UnlinkUser), ctx, userID)
+}
+
+// UserChat mocks base method.
+func (m *MockTelegramRepository) UserChat(ctx context.Context, userID string) (*domain.TelegramLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserChat", ctx, userID)
+	ret0, _ := ret[0].(*domain.TelegramLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserChat indicates an expected call of UserChat.
+func (mr *MockTelegramRepositoryMockRecorder) UserChat(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserChat", reflect.TypeOf((*MockTelegramRepository)(nil).UserChat), ctx, userID)
+}
+
+// MockPushSubscriptionRepository is a mock of PushSubscriptionRepository interface.
+type MockPushSubscriptionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPushSubscriptionRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPushSubscriptionRepositoryMockRecorder is the mock recorder for MockPushSubscriptionRepository.
+type MockPushSubscriptionRepositoryMockRecorder struct {
+	mock *MockPushSubscriptionRepository
+}
+
+// NewMockPushSubscriptionRepository creates a new mock instance.
+func NewMockPushSubscriptionRepository(ctrl *gomock.Controller) *MockPushSubscriptionRepository {
+	mock := &MockPushSubscriptionRepository{ctrl: ctrl}
+	mock.recorder = &MockPushSubscriptionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPushSubscriptionRepository) EXPECT() *MockPushSubscriptionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeletePushSubscription mocks base method.
+func (m *MockPushSubscriptionRepository) DeletePushSubscription(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePushSubscription", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePushSubscription indicates an expected call of DeletePushSubscription.
+func (mr *MockPushSubscriptionRepositoryMockRecorder) DeletePushSubscription(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePushSubscription", reflect.TypeOf((*MockPushSubscriptionRepository)(nil).DeletePushSubscription), ctx, id)
+}
+
+// SavePushSubscription mocks base method.
+func (m *MockPushSubscriptionRepository) SavePushSubscription(ctx context.Context, s domain.PushSubscription) (domain.PushSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePushSubscription", ctx, s)
+	ret0, _ := ret[0].(domain.PushSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SavePushSubscription indicates an expected call of SavePushSubscription.
+func (mr *MockPushSubscriptionRepositoryMockRecorder) SavePushSubscription(ctx, s any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePushSubscription", reflect.TypeOf((*MockPushSubscriptionRepository)(nil).SavePushSubscription), ctx, s)
+}
+
+// UserPushSubscriptions mocks base method.
+func (m *MockPushSubscriptionRepository) UserPushSubscriptions(ctx context.Context, userID string) ([]domain.PushSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserPushSubscriptions", ctx, userID)
+	ret0, _ := ret[0].([]domain.PushSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserPushSubscriptions indicates an expected call of UserPushSubscriptions.
+func (mr *MockPushSubscriptionRepositoryMockRecorder) UserPushSubscriptions(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserPushSubscriptions", reflect.TypeOf((*MockPushSubscriptionRepository)(nil).UserPushSubscriptions), ctx, userID)
+}
+
+// MockWatchlistRepository is a mock of WatchlistRepository interface.
+type MockWatchlistRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWatchlistRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockWatchlistRepositoryMockRecorder is the mock recorder for MockWatchlistRepository.
+type MockWatchlistRepositoryMockRecorder struct {
+	mock *MockWatchlistRepository
+}
+
+// NewMockWatchlistRepository creates a new mock instance.
+func NewMockWatchlistRepository(ctrl *gomock.Controller) *MockWatchlistRepository {
+	mock := &MockWatchlistRepository{ctrl: ctrl}
+	mock.recorder = &MockWatchlistRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWatchlistRepository) EXPECT() *MockWatchlistRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddWatch mocks base method.
+func (m *MockWatchlistRepository) AddWatch(ctx context.Context, item domain.WatchlistItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddWatch", ctx, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddWatch indicates an expected call of AddWatch.
+func (mr *MockWatchlistRepositoryMockRecorder) AddWatch(ctx, item any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWatch", reflect.TypeOf((*MockWatchlistRepository)(nil).AddWatch), ctx, item)
+}
+
+// RemoveWatch mocks base method.
+func (m *MockWatchlistRepository) RemoveWatch(ctx context.Context, userID, productID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveWatch", ctx, userID, productID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveWatch indicates an expected call of RemoveWatch.
+func (mr *MockWatchlistRepositoryMockRecorder) RemoveWatch(ctx, userID, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveWatch", reflect.TypeOf((*MockWatchlistRepository)(nil).RemoveWatch), ctx, userID, productID)
+}
+
+// Watchlist mocks base method.
+func (m *MockWatchlistRepository) Watchlist(ctx context.Context, userID string) ([]domain.WatchlistItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watchlist", ctx, userID)
+	ret0, _ := ret[0].([]domain.WatchlistItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Watchlist indicates an expected call of Watchlist.
+func (mr *MockWatchlistRepositoryMockRecorder) Watchlist(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watchlist", reflect.TypeOf((*MockWatchlistRepository)(nil).Watchlist), ctx, userID)
+}
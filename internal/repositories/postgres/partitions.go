@@ -0,0 +1,269 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PartitionConfig configures the PartitionMaintainer.
+type PartitionConfig struct {
+	// Interval is how often partitions are maintained.
+	Interval time.Duration
+	// Ahead is how many months past the current one have partitions ready
+	// before their prices arrive.
+	Ahead int
+	// Retention is how long price history is kept. Months that ended
+	// longer ago are dropped whole; zero keeps all history.
+	Retention time.Duration
+}
+
+// DefaultPartitionConfig maintains partitions every six hours, keeps three
+// months ready and all history.
+func DefaultPartitionConfig() PartitionConfig {
+	return PartitionConfig{Interval: 6 * time.Hour, Ahead: 3}
+}
+
+// partitionName matches the monthly partitions migration 012 creates with
+// price_history_partition.
+var partitionName = regexp.MustCompile(`^price_history_(\d{4})_(\d{2})$`)
+
+// PartitionStats reports what a maintenance pass did.
+type PartitionStats struct {
+	// Created are the monthly partitions created.
+	Created []string
+	// Dropped are the monthly partitions, or TimescaleDB chunks, dropped
+	// past retention.
+	Dropped []string
+	// Deleted counts rows past retention deleted from the default
+	// partition.
+	Deleted int64
+}
+
+// PartitionMaintainer keeps price_history partitioned by month, as
+// migration 012 sets it up: it creates the partitions of the coming months
+// before prices land in the default partition, moves rows that did into
+// partitions of their own, and drops partitions past retention. On
+// TimescaleDB, which chunks price_history itself, it only drops chunks
+// past retention.
+type PartitionMaintainer struct {
+	cfg    PartitionConfig
+	db     *sql.DB
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewPartitionMaintainer creates a PartitionMaintainer on the primary db.
+func NewPartitionMaintainer(cfg PartitionConfig, db *sql.DB, logger *zap.Logger) *PartitionMaintainer {
+	def := DefaultPartitionConfig()
+	if cfg.Interval <= 0 {
+		cfg.Interval = def.Interval
+	}
+	if cfg.Ahead <= 0 {
+		cfg.Ahead = def.Ahead
+	}
+	return &PartitionMaintainer{cfg: cfg, db: db, logger: logger, now: time.Now}
+}
+
+// Run maintains partitions at start and every Interval until ctx is done.
+func (m *PartitionMaintainer) Run(ctx context.Context) {
+	m.maintain(ctx)
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.maintain(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *PartitionMaintainer) maintain(ctx context.Context) {
+	stats, err := m.Maintain(ctx)
+	if err != nil {
+		m.logger.Error("Price history partition maintenance failed", zap.String("operation", "MaintainPartitions"), zap.Error(err))
+		return
+	}
+	if len(stats.Created) > 0 || len(stats.Dropped) > 0 || stats.Deleted > 0 {
+		m.logger.Info("Maintained price history partitions",
+			zap.Strings("created", stats.Created),
+			zap.Strings("dropped", stats.Dropped),
+			zap.Int64("deleted_rows", stats.Deleted),
+		)
+	}
+}
+
+// Maintain runs one maintenance pass.
+func (m *PartitionMaintainer) Maintain(ctx context.Context) (PartitionStats, error) {
+	var (
+		kind      string
+		timescale bool
+	)
+	err := m.db.QueryRowContext(ctx, `
+SELECT c.relkind::text, EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'timescaledb')
+FROM pg_class c WHERE c.oid = 'price_history'::regclass`).Scan(&kind, &timescale)
+	if err != nil {
+		return PartitionStats{}, fmt.Errorf("inspect price_history: %w", err)
+	}
+	now := m.now().UTC()
+	var cutoff time.Time
+	if m.cfg.Retention > 0 {
+		cutoff = now.Add(-m.cfg.Retention)
+	}
+	switch {
+	case kind == "p":
+		return m.maintainPartitions(ctx, now, cutoff)
+	case timescale:
+		return m.dropChunks(ctx, cutoff)
+	default:
+		return PartitionStats{}, errors.New("price_history is neither partitioned nor a hypertable; apply migration 012")
+	}
+}
+
+func (m *PartitionMaintainer) maintainPartitions(ctx context.Context, now, cutoff time.Time) (PartitionStats, error) {
+	var stats PartitionStats
+	existing, err := queryStrings(ctx, m.db, `
+SELECT c.relname::text FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid
+WHERE i.inhparent = 'price_history'::regclass`)
+	if err != nil {
+		return stats, fmt.Errorf("list price_history partitions: %w", err)
+	}
+	var stray []time.Time
+	rows, err := m.db.QueryContext(ctx,
+		`SELECT DISTINCT date_trunc('month', recorded_at AT TIME ZONE 'UTC') FROM price_history_default`)
+	if err != nil {
+		return stats, fmt.Errorf("read price_history_default: %w", err)
+	}
+	for rows.Next() {
+		var month time.Time
+		if err := rows.Scan(&month); err != nil {
+			_ = rows.Close()
+			return stats, fmt.Errorf("read price_history_default: %w", err)
+		}
+		stray = append(stray, month)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return stats, fmt.Errorf("read price_history_default: %w", err)
+	}
+
+	create, drop := planPartitions(now, cutoff, m.cfg.Ahead, existing, stray)
+	for _, month := range create {
+		var name string
+		if err := m.db.QueryRowContext(ctx, `SELECT price_history_partition($1)`, month).Scan(&name); err != nil {
+			return stats, fmt.Errorf("create price_history partition for %s: %w", month.Format("2006-01"), err)
+		}
+		stats.Created = append(stats.Created, name)
+	}
+	for _, name := range drop {
+		// name matched partitionName, so it needs no quoting.
+		if _, err := m.db.ExecContext(ctx, "DROP TABLE "+name); err != nil {
+			return stats, fmt.Errorf("drop %s: %w", name, err)
+		}
+		stats.Dropped = append(stats.Dropped, name)
+	}
+	if !cutoff.IsZero() {
+		res, err := m.db.ExecContext(ctx, `DELETE FROM price_history_default WHERE recorded_at < $1`, cutoff)
+		if err != nil {
+			return stats, fmt.Errorf("prune price_history_default: %w", err)
+		}
+		stats.Deleted, _ = res.RowsAffected()
+	}
+	return stats, nil
+}
+
+func (m *PartitionMaintainer) dropChunks(ctx context.Context, cutoff time.Time) (PartitionStats, error) {
+	var stats PartitionStats
+	if cutoff.IsZero() {
+		return stats, nil
+	}
+	dropped, err := queryStrings(ctx, m.db, `SELECT drop_chunks('price_history', older_than => $1::timestamptz)::text`, cutoff)
+	if err != nil {
+		return stats, fmt.Errorf("drop price_history chunks: %w", err)
+	}
+	stats.Dropped = dropped
+	return stats, nil
+}
+
+// planPartitions returns the months whose partitions are missing, from
+// the current month to ahead months on and those with rows in the default
+// partition, and the partitions whose month ended before cutoff. Months
+// that ended before cutoff are not created.
+func planPartitions(now, cutoff time.Time, ahead int, existing []string, stray []time.Time) (create []time.Time, drop []string) {
+	have := make(map[time.Time]bool)
+	for _, name := range existing {
+		month, ok := partitionMonth(name)
+		if !ok {
+			continue
+		}
+		if !cutoff.IsZero() && !month.AddDate(0, 1, 0).After(cutoff) {
+			drop = append(drop, name)
+			continue
+		}
+		have[month] = true
+	}
+	current := monthOf(now)
+	want := make([]time.Time, 0, ahead+1+len(stray))
+	for i := 0; i <= ahead; i++ {
+		want = append(want, current.AddDate(0, i, 0))
+	}
+	for _, t := range stray {
+		want = append(want, monthOf(t))
+	}
+	for _, month := range want {
+		if have[month] || (!cutoff.IsZero() && !month.AddDate(0, 1, 0).After(cutoff)) {
+			continue
+		}
+		have[month] = true
+		create = append(create, month)
+	}
+	slices.SortFunc(create, func(a, b time.Time) int { return a.Compare(b) })
+	slices.Sort(drop)
+	return create, drop
+}
+
+// partitionMonth returns the month a partition name holds.
+func partitionMonth(name string) (time.Time, bool) {
+	m := partitionName.FindStringSubmatch(name)
+	if m == nil {
+		return time.Time{}, false
+	}
+	year, _ := strconv.Atoi(m[1])
+	month, _ := strconv.Atoi(m[2])
+	if month < 1 || month > 12 {
+		return time.Time{}, false
+	}
+	return time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC), true
+}
+
+// monthOf returns the start of t's month in UTC.
+func monthOf(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func queryStrings(ctx context.Context, db *sql.DB, query string, args ...any) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestPlanPartitions(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPlanPartitions", "internal/repositories/postgres")
+
+	month := func(year int, m time.Month) time.Time { return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC) }
+	now := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	existing := []string{"price_history_default", "price_history_2025_12", "price_history_2026_01", "price_history_2026_10", "price_history_2026_11"}
+	// A backfill landed rows for February and for a month past retention.
+	stray := []time.Time{time.Date(2026, 2, 14, 9, 0, 0, 0, time.UTC), time.Date(2025, 11, 30, 23, 0, 0, 0, time.UTC)}
+
+	for _, tc := range []struct {
+		name       string
+		cutoff     time.Time
+		wantCreate []time.Time
+		wantDrop   []string
+	}{
+		{
+			name:       "keep all",
+			wantCreate: []time.Time{month(2025, 11), month(2026, 2), month(2026, 12), month(2027, 1)},
+		},
+		{
+			name:       "retention ends inside January",
+			cutoff:     time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC),
+			wantCreate: []time.Time{month(2026, 2), month(2026, 12), month(2027, 1)},
+			wantDrop:   []string{"price_history_2025_12"},
+		},
+		{
+			name:       "retention ends with January",
+			cutoff:     month(2026, 2),
+			wantCreate: []time.Time{month(2026, 2), month(2026, 12), month(2027, 1)},
+			wantDrop:   []string{"price_history_2025_12", "price_history_2026_01"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			testhelpers.LogTestStep(logger, "act", "Planning partitions "+tc.name)
+			create, drop := planPartitions(now, tc.cutoff, 3, existing, stray)
+
+			testhelpers.LogTestStep(logger, "assert", "Missing months are created and months past retention dropped")
+			testhelpers.LogTestAssertion(logger, "dropped", tc.wantDrop, drop)
+			if !slices.Equal(create, tc.wantCreate) {
+				t.Errorf("create = %v, want %v", create, tc.wantCreate)
+			}
+			if !slices.Equal(drop, tc.wantDrop) {
+				t.Errorf("drop = %v, want %v", drop, tc.wantDrop)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPlanPartitions", true)
+}
@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/database"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// dailyHistoryQuery reads the price_history_daily view from migration 008:
+// a TimescaleDB continuous aggregate where the extension is loaded, a
+// plain view over price_history elsewhere. $1 is a comma-separated list of
+// listing IDs.
+const dailyHistoryQuery = `
+SELECT d.product_listing_id::text, d.day, d.min_price::float8, d.avg_price::float8, d.max_price::float8,
+    d.close_price::float8, d.in_stock, d.samples::int, d.currency
+FROM price_history_daily d
+WHERE d.product_listing_id = ANY(string_to_array($1, ',')::uuid[]) AND d.day >= $2
+ORDER BY d.day, d.product_listing_id`
+
+// DailyPriceRepository implements repositories.DailyPriceRepository on
+// the price_history_daily view. Reads go to replicas.
+type DailyPriceRepository struct {
+	db    *database.Router
+	stmts *database.StatementCache
+}
+
+// NewDailyPriceRepository creates a DailyPriceRepository.
+func NewDailyPriceRepository(db *database.Router, stmts *database.StatementCache) *DailyPriceRepository {
+	return &DailyPriceRepository{db: db, stmts: stmts}
+}
+
+// DailyHistory implements repositories.DailyPriceRepository.
+func (r *DailyPriceRepository) DailyHistory(ctx context.Context, listingIDs []string, since time.Time) ([]domain.DailyPrice, error) {
+	if len(listingIDs) == 0 {
+		return nil, nil
+	}
+	rows, err := r.stmts.QueryContext(ctx, r.db.Reader(ctx), dailyHistoryQuery, strings.Join(listingIDs, ","), since)
+	if err != nil {
+		return nil, fmt.Errorf("read daily price history: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []domain.DailyPrice
+	for rows.Next() {
+		var d domain.DailyPrice
+		if err := rows.Scan(&d.ListingID, &d.Day, &d.MinPrice, &d.AvgPrice, &d.MaxPrice, &d.ClosePrice, &d.InStock, &d.Samples, &d.Currency); err != nil {
+			return nil, fmt.Errorf("scan daily price: %w", err)
+		}
+		d.AvgPrice = domain.Round2(d.AvgPrice)
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,244 @@
+// Package postgres implements the repository interfaces only PostgreSQL
+// can back, such as full-text search, through database/sql and the schema
+// in deployments/postgres/migrations. Those SQLite can hold too are in
+// package sqlstore.
+package postgres
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yourusername/whey-price-compare/internal/database"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// matchProducts ranks products by the weighted search_vector that
+// migration 003 maintains, using its partial GIN index.
+const matchProducts = `
+SELECT p.id, ts_rank(p.search_vector, q) AS rank
+FROM products p, to_tsquery('simple', $1) q
+WHERE p.is_active AND p.deleted_at IS NULL AND p.search_vector @@ q`
+
+// fuzzyMatchProducts matches each of the space-separated terms in $1
+// against the trigram-indexed search_text from migration 004, so a word
+// only resembling a term (pg_trgm.word_similarity_threshold, 0.6 by
+// default) still matches it. The index is probed with the longest term,
+// $8, which matches the fewest products.
+const fuzzyMatchProducts = `
+SELECT p.id, m.rank
+FROM products p
+CROSS JOIN LATERAL (
+    SELECT sum(word_similarity(t, p.search_text)) AS rank, bool_and(t <% p.search_text) AS all_terms
+    FROM unnest(string_to_array($1, ' ')) t
+) m
+WHERE p.is_active AND p.deleted_at IS NULL AND $8 <% p.search_text AND m.all_terms`
+
+// searchProductsQuery filters the products the matching query (%s) finds
+// by the comma-separated facet values in $3 to $7, an empty list matching
+// everything, and returns in one read the best $2 of them as 'hit' rows,
+// their number as a 'total' row, and one row per facet value with its
+// count. Each facet is counted with the other facets' filters only. A
+// product must have any of the values listed for a facet, but every one
+// of the dietary attributes in $7.
+const searchProductsQuery = `
+WITH matched AS (%s),
+attrs AS (
+    SELECT m.id, m.rank, p.brand_id::text AS brand, b.name AS brand_name,
+        p.category_id::text AS category, c.name AS category_name,
+        ARRAY(SELECT DISTINCT v.size_normalized_grams::text FROM product_variants v
+              WHERE v.product_id = p.id AND v.is_active AND v.deleted_at IS NULL AND v.size_normalized_grams > 0) AS weights,
+        ARRAY(SELECT DISTINCT l.retailer_id::text FROM product_variants v
+              JOIN product_listings l ON l.product_variant_id = v.id
+              WHERE v.product_id = p.id AND v.is_active AND v.deleted_at IS NULL AND l.is_active AND l.current_price > 0) AS retailers,
+        p.dietary
+    FROM matched m
+    JOIN products p ON p.id = m.id
+    JOIN brands b ON b.id = p.brand_id
+    JOIN categories c ON c.id = p.category_id
+),
+f AS (
+    SELECT a.*,
+        ($3 = '' OR a.brand = ANY(string_to_array($3, ','))) AS by_brand,
+        ($4 = '' OR a.category = ANY(string_to_array($4, ','))) AS by_category,
+        ($5 = '' OR a.weights && string_to_array($5, ',')) AS by_weight,
+        ($6 = '' OR a.retailers && string_to_array($6, ',')) AS by_retailer,
+        ($7 = '' OR a.dietary @> string_to_array($7, ',')) AS by_dietary
+    FROM attrs a
+)
+(SELECT 'hit', id::text, '', rank::float8 FROM f
+ WHERE by_brand AND by_category AND by_weight AND by_retailer AND by_dietary
+ ORDER BY rank DESC, id LIMIT $2)
+UNION ALL
+SELECT 'total', '', '', count(*)::float8 FROM f
+WHERE by_brand AND by_category AND by_weight AND by_retailer AND by_dietary
+UNION ALL
+SELECT 'brand', brand, min(brand_name), count(*)::float8 FROM f
+WHERE by_category AND by_weight AND by_retailer AND by_dietary GROUP BY brand
+UNION ALL
+SELECT 'category', category, min(category_name), count(*)::float8 FROM f
+WHERE by_brand AND by_weight AND by_retailer AND by_dietary GROUP BY category
+UNION ALL
+SELECT 'weight', w, '', count(*)::float8 FROM f, unnest(f.weights) w
+WHERE by_brand AND by_category AND by_retailer AND by_dietary GROUP BY w
+UNION ALL
+SELECT 'retailer', r.id::text, min(r.name), count(*)::float8 FROM f, unnest(f.retailers) rid
+JOIN retailers r ON r.id::text = rid
+WHERE by_brand AND by_category AND by_weight AND by_dietary GROUP BY r.id
+UNION ALL
+SELECT 'dietary', d, '', count(*)::float8 FROM f, unnest(f.dietary) d
+WHERE by_brand AND by_category AND by_weight AND by_retailer GROUP BY d`
+
+var (
+	exactSearchQuery = fmt.Sprintf(searchProductsQuery, matchProducts)
+	fuzzySearchQuery = fmt.Sprintf(searchProductsQuery, fuzzyMatchProducts)
+)
+
+// SearchRepository implements repositories.ProductSearchRepository with
+// Postgres full-text search. Searches read from replicas.
+type SearchRepository struct {
+	db    *database.Router
+	stmts *database.StatementCache
+}
+
+// NewSearchRepository creates a SearchRepository.
+func NewSearchRepository(db *database.Router, stmts *database.StatementCache) *SearchRepository {
+	return &SearchRepository{db: db, stmts: stmts}
+}
+
+// SearchProducts implements repositories.ProductSearchRepository.
+func (r *SearchRepository) SearchProducts(ctx context.Context, q repositories.ProductSearch) (*domain.SearchMatches, error) {
+	terms := domain.SearchTerms(q.Text)
+	if len(terms) == 0 {
+		return &domain.SearchMatches{}, nil
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 1000
+	}
+	weights := make([]string, len(q.Filters.Weights))
+	for i, g := range q.Filters.Weights {
+		weights[i] = strconv.Itoa(g)
+	}
+	query, args := exactSearchQuery, []any{toTSQuery(q.Clauses()), limit,
+		strings.Join(q.Filters.BrandIDs, ","), strings.Join(q.Filters.CategoryIDs, ","),
+		strings.Join(weights, ","), strings.Join(q.Filters.RetailerIDs, ","), strings.Join(q.Filters.Dietary, ",")}
+	if q.Fuzzy {
+		query = fuzzySearchQuery
+		args[0] = strings.Join(terms, " ")
+		args = append(args, longest(terms))
+	}
+	rows, err := r.stmts.QueryContext(ctx, r.db.Reader(ctx), query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search products: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	matches := &domain.SearchMatches{Facets: domain.SearchFacets{
+		Brands:     []domain.FacetValue{},
+		Categories: []domain.FacetValue{},
+		Weights:    []domain.FacetValue{},
+		Retailers:  []domain.FacetValue{},
+		Dietary:    []domain.FacetValue{},
+	}}
+	for rows.Next() {
+		var kind, value, label string
+		var score float64
+		if err := rows.Scan(&kind, &value, &label, &score); err != nil {
+			return nil, fmt.Errorf("scan search row: %w", err)
+		}
+		facet := domain.FacetValue{Value: value, Label: label, Count: int(score)}
+		switch kind {
+		case "hit":
+			matches.Hits = append(matches.Hits, domain.SearchHit{ProductID: value, Rank: score})
+		case "total":
+			matches.Total = int(score)
+		case "brand":
+			facet.Selected = slices.Contains(q.Filters.BrandIDs, value)
+			matches.Facets.Brands = append(matches.Facets.Brands, facet)
+		case "category":
+			facet.Selected = slices.Contains(q.Filters.CategoryIDs, value)
+			matches.Facets.Categories = append(matches.Facets.Categories, facet)
+		case "weight":
+			facet.Selected = slices.Contains(weights, value)
+			matches.Facets.Weights = append(matches.Facets.Weights, facet)
+		case "retailer":
+			facet.Selected = slices.Contains(q.Filters.RetailerIDs, value)
+			matches.Facets.Retailers = append(matches.Facets.Retailers, facet)
+		case "dietary":
+			facet.Label = domain.DietaryLabel(value)
+			facet.Selected = slices.Contains(q.Filters.Dietary, value)
+			matches.Facets.Dietary = append(matches.Facets.Dietary, facet)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sortMatches(matches)
+	return matches, nil
+}
+
+// sortMatches puts hits and facet values in the documented order; rows of a
+// UNION ALL come in no particular one.
+func sortMatches(m *domain.SearchMatches) {
+	slices.SortFunc(m.Hits, func(a, b domain.SearchHit) int {
+		if c := cmp.Compare(b.Rank, a.Rank); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ProductID, b.ProductID)
+	})
+	byCount := func(a, b domain.FacetValue) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Label, b.Label)
+	}
+	slices.SortFunc(m.Facets.Brands, byCount)
+	slices.SortFunc(m.Facets.Categories, byCount)
+	slices.SortFunc(m.Facets.Retailers, byCount)
+	slices.SortFunc(m.Facets.Weights, func(a, b domain.FacetValue) int {
+		x, _ := strconv.Atoi(a.Value)
+		y, _ := strconv.Atoi(b.Value)
+		return cmp.Compare(x, y)
+	})
+	slices.SortFunc(m.Facets.Dietary, func(a, b domain.FacetValue) int {
+		return cmp.Compare(slices.Index(domain.DietaryAttributes, a.Value), slices.Index(domain.DietaryAttributes, b.Value))
+	})
+}
+
+// toTSQuery ANDs clauses together, ORing each one's alternatives and
+// joining the words of a phrase with the followed-by operator. Terms hold
+// only letters, digits and marks, so none needs quoting.
+func toTSQuery(clauses []domain.SearchClause) string {
+	parts := make([]string, len(clauses))
+	for i, c := range clauses {
+		alts := make([]string, len(c.Alternatives))
+		for j, phrase := range c.Alternatives {
+			alts[j] = strings.Join(phrase, " <-> ")
+			if j == 0 && c.Prefix {
+				alts[j] += ":*"
+			}
+		}
+		parts[i] = strings.Join(alts, " | ")
+		if len(alts) > 1 {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	return strings.Join(parts, " & ")
+}
+
+// longest returns the longest of terms, the first if several tie.
+func longest(terms []string) string {
+	var out string
+	for _, t := range terms {
+		if utf8.RuneCountInString(t) > utf8.RuneCountInString(out) {
+			out = t
+		}
+	}
+	return out
+}
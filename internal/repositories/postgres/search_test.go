@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestToTSQuery(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestToTSQuery", "internal/repositories/postgres")
+
+	synonyms := domain.NewSynonyms([]domain.Synonym{
+		{Terms: []string{"ON", "Optimum Nutrition"}},
+		{Terms: []string{"WPI", "whey protein isolate"}},
+		{Terms: []string{"5lb", "2.27kg"}},
+	})
+	for _, tc := range []struct {
+		text, want string
+	}{
+		{"", ""},
+		{"  !! ", ""},
+		{"Gold", "gold:*"},
+		{"ON Gold-Standard 100%", "(on | optimum <-> nutrition) & gold & standard & 100:*"},
+		{"isolate'); DROP TABLE products; --", "isolate & drop & table & products:*"},
+		{"ON wpi 5lb", "(on | optimum <-> nutrition) & (wpi | whey <-> protein <-> isolate) & (5lb:* | 2 <-> 27kg)"},
+	} {
+		got := toTSQuery(repositories.ProductSearch{Text: tc.text, Synonyms: synonyms}.Clauses())
+		testhelpers.LogTestAssertion(logger, tc.text, tc.want, got)
+		if got != tc.want {
+			t.Errorf("toTSQuery(%q) = %q, want %q", tc.text, got, tc.want)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestToTSQuery", true)
+}
+
+func TestSortMatches(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSortMatches", "internal/repositories/postgres")
+
+	m := &domain.SearchMatches{
+		Hits: []domain.SearchHit{{ProductID: "b", Rank: 0.1}, {ProductID: "c", Rank: 0.5}, {ProductID: "a", Rank: 0.1}},
+		Facets: domain.SearchFacets{
+			Brands:  []domain.FacetValue{{Value: "mb", Label: "MuscleBlaze", Count: 1}, {Value: "on", Label: "Optimum Nutrition", Count: 3}, {Value: "as", Label: "AS-IT-IS", Count: 1}},
+			Weights: []domain.FacetValue{{Value: "2270", Count: 5}, {Value: "907", Count: 1}},
+			Dietary: []domain.FacetValue{{Value: domain.DietSugarFree, Count: 4}, {Value: domain.DietVegan, Count: 1}},
+		},
+	}
+	sortMatches(m)
+
+	var got []string
+	for _, h := range m.Hits {
+		got = append(got, h.ProductID)
+	}
+	for _, f := range slices.Concat(m.Facets.Brands, m.Facets.Weights, m.Facets.Dietary) {
+		got = append(got, f.Value)
+	}
+	want := []string{"c", "a", "b", "on", "as", "mb", "907", "2270", "vegan", "sugar-free"}
+	testhelpers.LogTestAssertion(logger, "order", want, got)
+	if !slices.Equal(got, want) {
+		t.Errorf("Order = %q, want %q", got, want)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSortMatches", true)
+}
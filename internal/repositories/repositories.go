@@ -0,0 +1,622 @@
+// Package repositories defines the data-access interfaces used by services.
+// Implementations live in subpackages (memory for development and tests).
+// The mocks subpackage has a gomock mock of every interface, for service
+// tests that script a repository's answers or errors; run make mocks after
+// adding or changing an interface, and add new ones to the list below.
+package repositories
+
+//go:generate go tool mockgen -destination=mocks/mocks.go -package=mocks . Transactor,ProductRepository,ProductSearchRepository,RetailerRepository,ListingRepository,PriceRepository,DailyPriceRepository,CatalogAdminRepository,SelectorRepository,PriceWriter,PriceIngester,OutboxRepository,PriceLogRepository,AuditRepository,StatsRepository,IntegrityRepository,ClickRepository,UserDataRepository,DataRequestRepository,ViewRepository,UserRepository,SessionRepository,APITokenRepository,VerificationRepository,IdentityRepository,AlertRepository,SavedSearchRepository,FilterPresetRepository,SynonymRepository,FlagRepository,SearchLogRepository,NotificationQueue,EngagementRepository,DeliveryRepository,PreferenceRepository,SuppressionRepository,SMSRepository,DiscordRepository,SlackRepository,TelegramRepository,PushSubscriptionRepository,WatchlistRepository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// Transactor runs a unit of work: repository calls made with the context
+// InTx passes to fn are committed together if fn returns nil and not at all
+// otherwise. Calls to InTx within fn join the outer unit. A repository
+// joins only a Transactor of its own store.
+type Transactor interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// ProductFilter narrows ProductRepository.List.
+type ProductFilter struct {
+	BrandID    string
+	CategoryID string
+	Limit      int
+	Offset     int
+}
+
+// ProductRepository reads the product catalog.
+type ProductRepository interface {
+	FindByID(ctx context.Context, id string) (*domain.Product, error)
+	List(ctx context.Context, filter ProductFilter) ([]domain.Product, error)
+	Variants(ctx context.Context, productID string) ([]domain.Variant, error)
+	// FindByIDs returns the active products among ids, keyed by ID; unknown
+	// and inactive IDs are absent.
+	FindByIDs(ctx context.Context, ids []string) (map[string]domain.Product, error)
+	// VariantsByProducts returns the active variants of each product, keyed
+	// by product ID.
+	VariantsByProducts(ctx context.Context, productIDs []string) (map[string][]domain.Variant, error)
+}
+
+// ProductSearch is a full-text query over the catalog. Every term must
+// match; the last also matches as a prefix, as it may still be being typed.
+type ProductSearch struct {
+	Text  string
+	Limit int
+	// Fuzzy also matches words a few typos away from a term (see
+	// domain.MaxTypos), ranking them below exact matches. It is slower, so
+	// it is for when an exact search finds nothing.
+	Fuzzy bool
+	// Filters narrow the hits, and all but their own facet's the counts.
+	Filters domain.SearchFilters
+	// Synonyms, if set, let each term or phrase of Text also match its
+	// synonyms. Fuzzy searches match Text as typed.
+	Synonyms *domain.Synonyms
+}
+
+// Clauses returns the parts of q.Text every match must contain.
+func (q ProductSearch) Clauses() []domain.SearchClause {
+	return q.Synonyms.Expand(domain.SearchTerms(q.Text))
+}
+
+// ProductSearchRepository finds active products by their brand, name,
+// category and description.
+type ProductSearchRepository interface {
+	// SearchProducts returns up to Limit matches, most relevant first, and
+	// the facet counts of all of them, in one read: matches in the brand
+	// outrank the name, which outranks the rest. Facet values are ordered
+	// by count, then label, except weights, which are ordered by size, and
+	// dietary attributes, which are in the order of
+	// domain.DietaryAttributes and labelled by domain.DietaryLabel.
+	SearchProducts(ctx context.Context, q ProductSearch) (*domain.SearchMatches, error)
+}
+
+// RetailerRepository reads retailers.
+type RetailerRepository interface {
+	FindByID(ctx context.Context, id string) (*domain.Retailer, error)
+	List(ctx context.Context) ([]domain.Retailer, error)
+	// FindByIDs returns the retailers among ids, keyed by ID.
+	FindByIDs(ctx context.Context, ids []string) (map[string]domain.Retailer, error)
+}
+
+// ListingRepository reads retailer listings.
+type ListingRepository interface {
+	// ByProduct returns the active listings for every variant of a product.
+	ByProduct(ctx context.Context, productID string) ([]domain.Listing, error)
+	// ByProducts is ByProduct for several products at once, keyed by product
+	// ID.
+	ByProducts(ctx context.Context, productIDs []string) (map[string][]domain.Listing, error)
+}
+
+// PriceRepository reads price observations.
+type PriceRepository interface {
+	// History returns the points recorded for listingIDs since the given
+	// time, ordered by RecordedAt ascending.
+	History(ctx context.Context, listingIDs []string, since time.Time) ([]domain.PricePoint, error)
+}
+
+// DailyPriceRepository reads price observations rolled up by listing and
+// UTC day, which stays fast over windows of months where History would
+// return every scrape.
+type DailyPriceRepository interface {
+	// DailyHistory returns the days of listingIDs starting at or after
+	// since, ordered by day then listing.
+	DailyHistory(ctx context.Context, listingIDs []string, since time.Time) ([]domain.DailyPrice, error)
+}
+
+// CatalogAdminRepository reads and writes catalog records for the admin API.
+// Unlike the read repositories it returns inactive and deleted records;
+// deleting is done by saving a record with DeletedAt set so price history
+// stays intact. Saves write the events they are given to the outbox in the
+// same transaction; see OutboxRepository.
+//
+// SaveProduct and SaveVariant are conditional: the record's Version must be
+// the stored one, or 0 for a record not stored yet, and the stored Version
+// becomes one more. Otherwise they save nothing and return an error
+// wrapping domain.ErrConflict, so of two edits of the same version only the
+// first is kept.
+type CatalogAdminRepository interface {
+	Product(ctx context.Context, id string) (*domain.Product, error)
+	SaveProduct(ctx context.Context, p domain.Product, events ...domain.Event) error
+	Variant(ctx context.Context, id string) (*domain.Variant, error)
+	SaveVariant(ctx context.Context, v domain.Variant, events ...domain.Event) error
+	Retailer(ctx context.Context, id string) (*domain.Retailer, error)
+	SaveRetailer(ctx context.Context, r domain.Retailer) error
+	Listing(ctx context.Context, id string) (*domain.Listing, error)
+}
+
+// SelectorRepository stores per-retailer scraper selector configs.
+type SelectorRepository interface {
+	Selectors(ctx context.Context, retailerID string) (*domain.SelectorConfig, error)
+	SaveSelectors(ctx context.Context, cfg domain.SelectorConfig) error
+}
+
+// PriceWriter records price observations.
+type PriceWriter interface {
+	// RecordPrice stores p, assigning an ID, and updates the listing's
+	// current price if p is its newest observation. events are written to
+	// the outbox in the same transaction.
+	RecordPrice(ctx context.Context, p domain.PricePoint, events ...domain.Event) (domain.PricePoint, error)
+}
+
+// IngestListing is a listing as price ingestion needs it: its current
+// state and the product its variant belongs to.
+type IngestListing struct {
+	domain.Listing
+	ProductID string
+}
+
+// PriceIngester writes a scrape's price observations in bulk, for
+// services.IngestService.
+type PriceIngester interface {
+	// Listings returns the listings with the given IDs, active or not,
+	// keyed by ID. Unknown IDs are left out.
+	Listings(ctx context.Context, ids []string) (map[string]IngestListing, error)
+	// IngestPrices stores points, many rows a statement, and moves the
+	// current price of each listing they name to its newest observation.
+	// events are written to the outbox in the same transaction. Unlike
+	// RecordPrice it assigns no IDs the caller sees.
+	IngestPrices(ctx context.Context, points []domain.PricePoint, events ...domain.Event) error
+}
+
+// OutboxRepository holds the events that writes stored with their changes
+// until the relay publishes them. Writes add to it through the events
+// argument of the repository method making the change, never directly, so
+// an event is stored if and only if its change is.
+type OutboxRepository interface {
+	// Pending returns up to limit messages neither published nor failed,
+	// oldest first.
+	Pending(ctx context.Context, limit int) ([]domain.OutboxMessage, error)
+	// MarkPublished records that messages were delivered.
+	MarkPublished(ctx context.Context, at time.Time, ids ...string) error
+	// MarkRetry records a failed delivery of a message that stays pending.
+	MarkRetry(ctx context.Context, id, reason string) error
+	// MarkFailed records a failed delivery and gives up on the message,
+	// which stays in the outbox for inspection.
+	MarkFailed(ctx context.Context, at time.Time, id, reason string) error
+	// DeletePublishedBefore removes messages published before cutoff,
+	// returning how many it removed.
+	DeletePublishedBefore(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// PriceLogFilter narrows PriceLogRepository.Read.
+type PriceLogFilter struct {
+	// After is the offset to read past; entries after it are returned.
+	After int64
+	// ProductID, if set, narrows the log to one product's changes.
+	ProductID string
+	Limit     int
+}
+
+// PriceLogRepository is the price change log (see domain.PriceLogEntry).
+// Like the outbox it is written through the events argument of the
+// repository method making a price change, so an entry is stored if and
+// only if its change is, and never directly. Entries are never changed
+// or removed.
+type PriceLogRepository interface {
+	// Read returns up to filter.Limit entries after filter.After, in
+	// offset order.
+	Read(ctx context.Context, filter PriceLogFilter) ([]domain.PriceLogEntry, error)
+	// Head returns the offset of the newest entry, or 0 for an empty log.
+	Head(ctx context.Context) (int64, error)
+	// OffsetBefore returns the offset of the newest entry recorded before
+	// t, or 0 if there is none: the offset to rewind to in order to replay
+	// everything from t.
+	OffsetBefore(ctx context.Context, t time.Time) (int64, error)
+	// Cursor returns where consumer has read up to, at offset 0 for a
+	// consumer that has never committed.
+	Cursor(ctx context.Context, consumer string) (domain.PriceLogCursor, error)
+	// Cursors returns every consumer's cursor, by name.
+	Cursors(ctx context.Context) ([]domain.PriceLogCursor, error)
+	// Commit moves consumer's cursor from offset from to offset to, which
+	// may be behind it. If the cursor is no longer at from, because
+	// another commit or a rewind came first, it moves nothing and returns
+	// an error wrapping domain.ErrConflict.
+	Commit(ctx context.Context, consumer string, from, to int64, at time.Time) error
+}
+
+// AuditFilter narrows AuditRepository.List. Zero fields match anything.
+type AuditFilter struct {
+	ActorID      string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	// Since and Until bound CreatedAt, Since inclusive and Until exclusive.
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+	Offset int
+}
+
+// AuditRepository is the append-only audit log.
+type AuditRepository interface {
+	Append(ctx context.Context, e domain.AuditEntry) error
+	// List returns matching entries, newest first.
+	List(ctx context.Context, filter AuditFilter) ([]domain.AuditEntry, error)
+}
+
+// StatsRepository computes catalog-wide counts.
+type StatsRepository interface {
+	// CatalogStats counts active products, retailers and listings, and all
+	// recorded price points. GeneratedAt is left for the caller to set.
+	CatalogStats(ctx context.Context) (domain.CatalogStats, error)
+}
+
+// IntegrityRepository finds rows failing the integrity checks, up to
+// limit per check, with Check set on each finding.
+type IntegrityRepository interface {
+	// OrphanVariants returns live variants whose product is missing or
+	// deleted.
+	OrphanVariants(ctx context.Context, limit int) ([]domain.IntegrityFinding, error)
+	// UnpricedProducts returns live products with no price recorded since
+	// since, including those never priced.
+	UnpricedProducts(ctx context.Context, since time.Time, limit int) ([]domain.IntegrityFinding, error)
+	// CurrencyMismatches returns prices recorded since since in a currency
+	// other than their listing's. The window keeps the scan to recent
+	// partitions; older rows were checked when they were recent.
+	CurrencyMismatches(ctx context.Context, since time.Time, limit int) ([]domain.IntegrityFinding, error)
+}
+
+// ClickFilter narrows ClickRepository.CountClicks.
+type ClickFilter struct {
+	ProductID  string
+	RetailerID string
+	Referral   string
+	Since      time.Time
+}
+
+// ClickRepository stores outbound click events.
+type ClickRepository interface {
+	// RecordClicks stores a batch of events, assigning IDs.
+	RecordClicks(ctx context.Context, events []domain.ClickEvent) error
+	CountClicks(ctx context.Context, filter ClickFilter) (int, error)
+}
+
+// UserDataRepository reads and erases everything stored about a user, for
+// data-rights requests. Suppressed addresses and the audit log are kept:
+// the first so erased users are not mailed again, the second as the record
+// of the erasure.
+type UserDataRepository interface {
+	// ExportUserData returns userID's data, or domain.ErrNotFound if there
+	// is no such user. ExportedAt is left for the caller to set.
+	ExportUserData(ctx context.Context, userID string) (*domain.UserData, error)
+	// DeleteUserData removes userID and everything tied to them in one
+	// step, returning how many records of each kind were removed.
+	DeleteUserData(ctx context.Context, userID string) (map[string]int, error)
+}
+
+// DataRequestRepository queues users' export and deletion requests.
+type DataRequestRepository interface {
+	// CreateDataRequest stores r, assigning its ID.
+	CreateDataRequest(ctx context.Context, r domain.DataRequest) (domain.DataRequest, error)
+	SaveDataRequest(ctx context.Context, r domain.DataRequest) error
+	// DataRequest returns the request with id, or domain.ErrNotFound.
+	DataRequest(ctx context.Context, id string) (*domain.DataRequest, error)
+	// UserDataRequests returns userID's requests, newest first.
+	UserDataRequests(ctx context.Context, userID string) ([]domain.DataRequest, error)
+	// PendingDataRequests returns up to limit pending requests, oldest
+	// first.
+	PendingDataRequests(ctx context.Context, limit int) ([]domain.DataRequest, error)
+	// PurgeExports drops the results of exports that expired before now,
+	// returning how many were dropped.
+	PurgeExports(ctx context.Context, now time.Time) (int, error)
+}
+
+// DealViewFilter narrows ViewRepository.TopDeals.
+type DealViewFilter struct {
+	CategoryID string
+	MinScore   float64
+	Limit      int
+}
+
+// ViewRepository stores precomputed read models: one comparison row and at
+// most one deal row per product. Rows are derived data, rebuilt from the
+// catalog and price history whenever either changes.
+type ViewRepository interface {
+	// Comparison returns the stored comparison for a product, or
+	// domain.ErrNotFound if none has been built.
+	Comparison(ctx context.Context, productID string) (*domain.Comparison, error)
+	// Comparisons returns the stored comparisons among productIDs, keyed by
+	// product ID.
+	Comparisons(ctx context.Context, productIDs []string) (map[string]domain.Comparison, error)
+	// TopDeals returns stored deals in rank order: highest score first, then
+	// cheapest protein.
+	TopDeals(ctx context.Context, filter DealViewFilter) ([]domain.Deal, error)
+	// SaveProductView replaces a product's rows. A nil deal removes its deal
+	// row.
+	SaveProductView(ctx context.Context, c domain.Comparison, deal *domain.Deal) error
+	// DeleteProductView removes a product's rows.
+	DeleteProductView(ctx context.Context, productID string) error
+}
+
+// UserRepository stores accounts. Emails are unique and matched exactly;
+// callers normalise them first.
+type UserRepository interface {
+	// CreateUser stores u, assigning an ID. A taken email returns
+	// domain.ErrConflict.
+	CreateUser(ctx context.Context, u domain.User) (domain.User, error)
+	UserByID(ctx context.Context, id string) (*domain.User, error)
+	UserByEmail(ctx context.Context, email string) (*domain.User, error)
+	// SaveUser replaces an existing user.
+	SaveUser(ctx context.Context, u domain.User) error
+}
+
+// SessionRepository stores signed-in sessions by token hash.
+type SessionRepository interface {
+	CreateSession(ctx context.Context, s domain.Session) error
+	// Session returns the session with tokenHash, expired or not, or
+	// domain.ErrNotFound.
+	Session(ctx context.Context, tokenHash string) (*domain.Session, error)
+	DeleteSession(ctx context.Context, tokenHash string) error
+	// DeleteUserSessions signs a user out everywhere.
+	DeleteUserSessions(ctx context.Context, userID string) error
+}
+
+// APITokenRepository stores personal access tokens by token hash.
+type APITokenRepository interface {
+	// CreateAPIToken stores t, assigning its ID.
+	CreateAPIToken(ctx context.Context, t domain.APIToken) (domain.APIToken, error)
+	// APITokenByHash returns the token with tokenHash, expired or not, or
+	// domain.ErrNotFound.
+	APITokenByHash(ctx context.Context, tokenHash string) (*domain.APIToken, error)
+	// UserAPITokens returns userID's tokens, oldest first.
+	UserAPITokens(ctx context.Context, userID string) ([]domain.APIToken, error)
+	// TouchAPIToken records that the token with id was used at.
+	TouchAPIToken(ctx context.Context, id string, at time.Time) error
+	// DeleteAPIToken removes userID's token with id, or returns
+	// domain.ErrNotFound.
+	DeleteAPIToken(ctx context.Context, userID, id string) error
+}
+
+// VerificationRepository stores pending email verifications by token hash.
+type VerificationRepository interface {
+	CreateVerification(ctx context.Context, v domain.EmailVerification) error
+	// ConsumeVerification removes and returns the verification with
+	// tokenHash, so each token works once. Unknown or already used tokens
+	// return domain.ErrNotFound.
+	ConsumeVerification(ctx context.Context, tokenHash string) (*domain.EmailVerification, error)
+}
+
+// IdentityRepository links accounts at external sign-in providers, such as
+// Google, to users.
+type IdentityRepository interface {
+	// LinkIdentity links the provider's subject to userID. Relinking to the
+	// same user is a no-op; a subject linked to someone else returns
+	// domain.ErrConflict.
+	LinkIdentity(ctx context.Context, provider, subject, userID string) error
+	// IdentityUser returns the ID of the user the subject is linked to, or
+	// domain.ErrNotFound.
+	IdentityUser(ctx context.Context, provider, subject string) (string, error)
+}
+
+// AlertRepository stores users' price alerts. Deleting an alert only marks
+// it deleted; every read but DeletedAlerts skips deleted alerts.
+type AlertRepository interface {
+	// CreateAlert stores a, assigning an ID. A second alert by the same
+	// user for the same product returns domain.ErrConflict.
+	CreateAlert(ctx context.Context, a domain.PriceAlert) (domain.PriceAlert, error)
+	Alert(ctx context.Context, id string) (*domain.PriceAlert, error)
+	// UserAlerts returns a user's alerts, newest first.
+	UserAlerts(ctx context.Context, userID string) ([]domain.PriceAlert, error)
+	// ProductAlerts returns every alert on a product.
+	ProductAlerts(ctx context.Context, productID string) ([]domain.PriceAlert, error)
+	SaveAlert(ctx context.Context, a domain.PriceAlert) error
+	// DeleteAlert marks an alert deleted at at.
+	DeleteAlert(ctx context.Context, id string, at time.Time) error
+	// DeletedAlerts returns a user's deleted alerts, most recently
+	// deleted first.
+	DeletedAlerts(ctx context.Context, userID string) ([]domain.PriceAlert, error)
+	// RestoreAlert undoes DeleteAlert. Restoring an alert on a product
+	// its user has set another alert on since returns domain.ErrConflict.
+	RestoreAlert(ctx context.Context, id string) (domain.PriceAlert, error)
+}
+
+// SavedSearchRepository stores users' saved searches.
+type SavedSearchRepository interface {
+	// CreateSavedSearch stores s, assigning an ID.
+	CreateSavedSearch(ctx context.Context, s domain.SavedSearch) (domain.SavedSearch, error)
+	SavedSearch(ctx context.Context, id string) (*domain.SavedSearch, error)
+	// UserSavedSearches returns a user's saved searches, newest first.
+	UserSavedSearches(ctx context.Context, userID string) ([]domain.SavedSearch, error)
+	// AllSavedSearches returns every saved search, oldest first.
+	AllSavedSearches(ctx context.Context) ([]domain.SavedSearch, error)
+	SaveSavedSearch(ctx context.Context, s domain.SavedSearch) error
+	DeleteSavedSearch(ctx context.Context, id string) error
+}
+
+// FilterPresetRepository stores users' filter presets.
+type FilterPresetRepository interface {
+	// CreateFilterPreset stores p, assigning an ID.
+	CreateFilterPreset(ctx context.Context, p domain.FilterPreset) (domain.FilterPreset, error)
+	// FilterPreset returns a preset, or domain.ErrNotFound.
+	FilterPreset(ctx context.Context, id string) (*domain.FilterPreset, error)
+	// UserFilterPresets returns a user's presets, oldest first.
+	UserFilterPresets(ctx context.Context, userID string) ([]domain.FilterPreset, error)
+	// SaveFilterPreset replaces a stored preset, or returns
+	// domain.ErrNotFound.
+	SaveFilterPreset(ctx context.Context, p domain.FilterPreset) error
+	// DeleteFilterPreset removes a preset, or returns domain.ErrNotFound.
+	DeleteFilterPreset(ctx context.Context, id string) error
+}
+
+// SynonymRepository stores search synonym groups. Writes store the events
+// they are given in the outbox in the same transaction.
+type SynonymRepository interface {
+	// CreateSynonym stores s, assigning an ID.
+	CreateSynonym(ctx context.Context, s domain.Synonym, events ...domain.Event) (domain.Synonym, error)
+	Synonym(ctx context.Context, id string) (*domain.Synonym, error)
+	// Synonyms returns every group, oldest first.
+	Synonyms(ctx context.Context) ([]domain.Synonym, error)
+	SaveSynonym(ctx context.Context, s domain.Synonym, events ...domain.Event) error
+	DeleteSynonym(ctx context.Context, id string, events ...domain.Event) error
+}
+
+// FlagRepository stores feature flags by name. Writes store the events
+// they are given in the outbox in the same transaction.
+type FlagRepository interface {
+	Flag(ctx context.Context, name string) (*domain.Flag, error)
+	// Flags returns every flag, by name.
+	Flags(ctx context.Context) ([]domain.Flag, error)
+	// SaveFlag creates or replaces the flag named f.Name.
+	SaveFlag(ctx context.Context, f domain.Flag, events ...domain.Event) error
+	DeleteFlag(ctx context.Context, name string, events ...domain.Event) error
+}
+
+// SearchLogRepository stores anonymized searches and the clicks on their
+// results.
+type SearchLogRepository interface {
+	RecordSearches(ctx context.Context, logs []domain.SearchLog) error
+	RecordSearchClicks(ctx context.Context, clicks []domain.SearchClick) error
+	// SearchesSince and SearchClicksSince return what was recorded at or
+	// after since.
+	SearchesSince(ctx context.Context, since time.Time) ([]domain.SearchLog, error)
+	SearchClicksSince(ctx context.Context, since time.Time) ([]domain.SearchClick, error)
+	// DeleteSearchLogsBefore removes searches and clicks recorded before
+	// cutoff, returning how many were removed.
+	DeleteSearchLogsBefore(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// NotificationQueue holds notifications until a channel delivers them.
+type NotificationQueue interface {
+	// Enqueue stores notifications, assigning IDs. A notification whose
+	// DedupeKey is already queued is skipped.
+	Enqueue(ctx context.Context, notifications ...domain.Notification) error
+	// Pending returns up to limit undelivered notifications, oldest first.
+	Pending(ctx context.Context, limit int) ([]domain.Notification, error)
+	// MarkSent records delivery; sent notifications are not pending again.
+	MarkSent(ctx context.Context, at time.Time, ids ...string) error
+	// Hold sets HeldUntil, keeping notifications out of Pending for a
+	// digest.
+	Hold(ctx context.Context, until time.Time, ids ...string) error
+	// Due returns unsent held notifications whose HeldUntil is at or
+	// before now, oldest first.
+	Due(ctx context.Context, now time.Time) ([]domain.Notification, error)
+	// Defer sets DeferredUntil, keeping notifications out of Pending
+	// until Release.
+	Defer(ctx context.Context, until time.Time, ids ...string) error
+	// Release clears DeferredUntil where it is at or before now, so those
+	// notifications are pending again in their original order, and
+	// returns how many it released.
+	Release(ctx context.Context, now time.Time) (int, error)
+}
+
+// EngagementRepository stores what happened to delivered notifications.
+type EngagementRepository interface {
+	// RecordEngagement stores e and reports true, or reports false if an
+	// event of its type was already recorded for its notification and
+	// channel, so reopening a message counts once.
+	RecordEngagement(ctx context.Context, e domain.EngagementEvent) (bool, error)
+	// EngagementSince returns events recorded at or after since.
+	EngagementSince(ctx context.Context, since time.Time) ([]domain.EngagementEvent, error)
+}
+
+// DeliveryRepository stores notifications a channel failed to deliver,
+// while they are retried and once they are dead-lettered.
+type DeliveryRepository interface {
+	// SaveFailedDelivery adds or replaces d, assigning an ID if it has
+	// none.
+	SaveFailedDelivery(ctx context.Context, d domain.FailedDelivery) (domain.FailedDelivery, error)
+	// FailedDelivery returns the delivery with id, or domain.ErrNotFound.
+	FailedDelivery(ctx context.Context, id string) (*domain.FailedDelivery, error)
+	// DueDeliveries returns up to limit retrying deliveries whose
+	// NextAttemptAt is at or before now, oldest first.
+	DueDeliveries(ctx context.Context, now time.Time, limit int) ([]domain.FailedDelivery, error)
+	// FailedDeliveries returns up to limit deliveries with status, or with
+	// any status if it is empty, most recently updated first.
+	FailedDeliveries(ctx context.Context, status string, limit int) ([]domain.FailedDelivery, error)
+	DeleteFailedDelivery(ctx context.Context, id string) error
+}
+
+// PreferenceRepository stores users' notification preferences.
+type PreferenceRepository interface {
+	// NotificationPreferences returns a user's preferences, or
+	// domain.ErrNotFound if they have not saved any.
+	NotificationPreferences(ctx context.Context, userID string) (*domain.NotificationPreferences, error)
+	SaveNotificationPreferences(ctx context.Context, p domain.NotificationPreferences) error
+}
+
+// SuppressionRepository stores addresses email must not be sent to.
+type SuppressionRepository interface {
+	// Suppress adds or replaces the suppression for s.Email.
+	Suppress(ctx context.Context, s domain.Suppression) error
+	// Suppression returns the suppression for email, or domain.ErrNotFound.
+	Suppression(ctx context.Context, email string) (*domain.Suppression, error)
+}
+
+// SMSRepository stores the numbers users receive SMS alerts on, and a log
+// of texts sent for spending caps.
+type SMSRepository interface {
+	// SaveSMSSubscription adds or replaces the user's number.
+	SaveSMSSubscription(ctx context.Context, s domain.SMSSubscription) error
+	// SMSSubscription returns userID's number, or domain.ErrNotFound.
+	SMSSubscription(ctx context.Context, userID string) (*domain.SMSSubscription, error)
+	DeleteSMSSubscription(ctx context.Context, userID string) error
+	RecordSMS(ctx context.Context, m domain.SMSMessage) error
+	// SMSSpend returns how many texts userID was sent since, and what every
+	// text sent since cost in total.
+	SMSSpend(ctx context.Context, userID string, since time.Time) (sent int, cost float64, err error)
+}
+
+// DiscordRepository stores the Discord webhooks users' alerts are posted
+// to, one per user.
+type DiscordRepository interface {
+	// SaveDiscordWebhook adds or replaces the user's webhook.
+	SaveDiscordWebhook(ctx context.Context, w domain.DiscordWebhook) error
+	// DiscordWebhook returns userID's webhook, or domain.ErrNotFound.
+	DiscordWebhook(ctx context.Context, userID string) (*domain.DiscordWebhook, error)
+	DeleteDiscordWebhook(ctx context.Context, userID string) error
+}
+
+// SlackRepository stores the Slack incoming webhooks users' alerts are
+// posted to, one per user.
+type SlackRepository interface {
+	// SaveSlackWebhook adds or replaces the user's webhook.
+	SaveSlackWebhook(ctx context.Context, w domain.SlackWebhook) error
+	// SlackWebhook returns userID's webhook, or domain.ErrNotFound.
+	SlackWebhook(ctx context.Context, userID string) (*domain.SlackWebhook, error)
+	DeleteSlackWebhook(ctx context.Context, userID string) error
+}
+
+// TelegramRepository links users to Telegram chats.
+type TelegramRepository interface {
+	CreateLinkToken(ctx context.Context, t domain.TelegramLinkToken) error
+	// ConsumeLinkToken deletes and returns a token, or domain.ErrNotFound;
+	// each token links at most one chat.
+	ConsumeLinkToken(ctx context.Context, tokenHash string) (*domain.TelegramLinkToken, error)
+	// LinkChat stores l, replacing any previous link of the user or chat.
+	LinkChat(ctx context.Context, l domain.TelegramLink) error
+	// UserChat returns a user's link, or domain.ErrNotFound.
+	UserChat(ctx context.Context, userID string) (*domain.TelegramLink, error)
+	// ChatLink returns a chat's link, or domain.ErrNotFound.
+	ChatLink(ctx context.Context, chatID int64) (*domain.TelegramLink, error)
+	// UnlinkUser removes a user's link, if any.
+	UnlinkUser(ctx context.Context, userID string) error
+}
+
+// PushSubscriptionRepository stores browsers' Web Push subscriptions.
+type PushSubscriptionRepository interface {
+	// SavePushSubscription stores s, assigning an ID. An endpoint belongs
+	// to one user: saving it again replaces the previous subscription.
+	SavePushSubscription(ctx context.Context, s domain.PushSubscription) (domain.PushSubscription, error)
+	// UserPushSubscriptions returns a user's subscriptions, oldest first.
+	UserPushSubscriptions(ctx context.Context, userID string) ([]domain.PushSubscription, error)
+	// DeletePushSubscription removes a subscription, or returns
+	// domain.ErrNotFound.
+	DeletePushSubscription(ctx context.Context, id string) error
+}
+
+// WatchlistRepository stores the products users follow.
+type WatchlistRepository interface {
+	// AddWatch saves item, or returns domain.ErrConflict if the user
+	// already watches the product.
+	AddWatch(ctx context.Context, item domain.WatchlistItem) error
+	// RemoveWatch deletes a user's item, or returns domain.ErrNotFound.
+	RemoveWatch(ctx context.Context, userID, productID string) error
+	// Watchlist returns a user's items, newest first.
+	Watchlist(ctx context.Context, userID string) ([]domain.WatchlistItem, error)
+}
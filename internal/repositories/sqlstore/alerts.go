@@ -0,0 +1,229 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/database"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// alertQueries are the price_alerts statements in one dialect.
+type alertQueries struct {
+	insert, byID, anyByID        string
+	byUser, byProduct, deleted   string
+	allByUser                    string
+	update, markDeleted, restore string
+}
+
+func newAlertQueries(d database.Dialect) alertQueries {
+	alert := `
+SELECT ` + d.Text("id") + `, ` + d.Text("user_id") + `, product_id, condition, coalesce(target_price, 0),
+    coalesce(target_price_per_gram, 0), coalesce(drop_percent, 0), coalesce(base_price, 0), coalesce(low_days, 0),
+    pending, created_at, last_triggered_at, deleted_at
+FROM price_alerts`
+	return alertQueries{
+		// The partial unique index of migration 016 turns a second live
+		// alert by the user on the product into no row inserted.
+		insert: d.Rebind(`
+INSERT INTO price_alerts (user_id, product_id, condition, target_price, target_price_per_gram, drop_percent,
+    base_price, low_days, pending, created_at, last_triggered_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT DO NOTHING
+RETURNING ` + d.Text("id")),
+		byID:      d.Rebind(alert + ` WHERE id = $1 AND deleted_at IS NULL`),
+		anyByID:   d.Rebind(alert + ` WHERE id = $1`),
+		byUser:    d.Rebind(alert + ` WHERE user_id = $1 AND deleted_at IS NULL ORDER BY created_at DESC, id DESC`),
+		byProduct: d.Rebind(alert + ` WHERE product_id = $1 AND deleted_at IS NULL ORDER BY created_at, id`),
+		deleted:   d.Rebind(alert + ` WHERE user_id = $1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC, id DESC`),
+		allByUser: d.Rebind(alert + ` WHERE user_id = $1 ORDER BY created_at, id`),
+		update: d.Rebind(`
+UPDATE price_alerts SET condition = $2, target_price = $3, target_price_per_gram = $4, drop_percent = $5,
+    base_price = $6, low_days = $7, pending = $8, last_triggered_at = $9, updated_at = ` + d.Now() + `
+WHERE id = $1 AND deleted_at IS NULL`),
+		markDeleted: d.Rebind(`
+UPDATE price_alerts SET deleted_at = $2, updated_at = ` + d.Now() + ` WHERE id = $1 AND deleted_at IS NULL`),
+		restore: d.Rebind(`
+UPDATE price_alerts SET deleted_at = NULL, updated_at = ` + d.Now() + `
+WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM price_alerts o
+    WHERE o.user_id = price_alerts.user_id AND o.product_id = price_alerts.product_id AND o.deleted_at IS NULL)`),
+	}
+}
+
+// AlertRepository implements repositories.AlertRepository on the
+// price_alerts table, with the columns migration 016 adds for each alert
+// condition. Reads go to the primary: the alert evaluator acts on what it
+// reads, and a replica behind it could fire an alert just deleted.
+type AlertRepository struct {
+	d     database.Dialect
+	db    *database.Router
+	stmts *database.StatementCache
+	q     alertQueries
+}
+
+// NewAlertRepository creates an AlertRepository for a database of dialect
+// d.
+func NewAlertRepository(d database.Dialect, db *database.Router, stmts *database.StatementCache) *AlertRepository {
+	return &AlertRepository{d: d, db: db, stmts: stmts, q: newAlertQueries(d)}
+}
+
+// CreateAlert implements repositories.AlertRepository.
+func (r *AlertRepository) CreateAlert(ctx context.Context, a domain.PriceAlert) (domain.PriceAlert, error) {
+	if !validKey(r.d, a.UserID) {
+		return domain.PriceAlert{}, fmt.Errorf("user %q: %w", a.UserID, domain.ErrNotFound)
+	}
+	err := r.stmts.QueryRowContext(ctx, r.db.Writer(), r.q.insert, r.d.Args(a.UserID, a.ProductID, a.Condition,
+		zeroOrNull(a.TargetPrice), zeroOrNull(a.TargetPricePerGram), zeroOrNull(a.DropPercent), zeroOrNull(a.BasePrice),
+		zeroOrNull(a.LowDays), a.Pending, a.CreatedAt, timeOrNull(a.TriggeredAt))...).Scan(&a.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.PriceAlert{}, fmt.Errorf("an alert for product %q already exists: %w", a.ProductID, domain.ErrConflict)
+	}
+	if err != nil {
+		return domain.PriceAlert{}, fmt.Errorf("insert alert: %w", err)
+	}
+	a.DeletedAt = nil
+	return a, nil
+}
+
+// Alert implements repositories.AlertRepository.
+func (r *AlertRepository) Alert(ctx context.Context, id string) (*domain.PriceAlert, error) {
+	return r.alert(ctx, r.q.byID, "alert", id)
+}
+
+func (r *AlertRepository) alert(ctx context.Context, query, kind, id string) (*domain.PriceAlert, error) {
+	if !validKey(r.d, id) {
+		return nil, fmt.Errorf("%s %q: %w", kind, id, domain.ErrNotFound)
+	}
+	a, err := scanAlert(r.stmts.QueryRowContext(ctx, r.db.Writer(), query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%s %q: %w", kind, id, domain.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read alert: %w", err)
+	}
+	return &a, nil
+}
+
+// UserAlerts implements repositories.AlertRepository.
+func (r *AlertRepository) UserAlerts(ctx context.Context, userID string) ([]domain.PriceAlert, error) {
+	if !validKey(r.d, userID) {
+		return nil, nil
+	}
+	return r.list(ctx, r.q.byUser, userID)
+}
+
+// ProductAlerts implements repositories.AlertRepository.
+func (r *AlertRepository) ProductAlerts(ctx context.Context, productID string) ([]domain.PriceAlert, error) {
+	return r.list(ctx, r.q.byProduct, productID)
+}
+
+// DeletedAlerts implements repositories.AlertRepository.
+func (r *AlertRepository) DeletedAlerts(ctx context.Context, userID string) ([]domain.PriceAlert, error) {
+	if !validKey(r.d, userID) {
+		return nil, nil
+	}
+	return r.list(ctx, r.q.deleted, userID)
+}
+
+func (r *AlertRepository) list(ctx context.Context, query, key string) ([]domain.PriceAlert, error) {
+	rows, err := r.stmts.QueryContext(ctx, r.db.Writer(), query, key)
+	if err != nil {
+		return nil, fmt.Errorf("list alerts: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []domain.PriceAlert
+	for rows.Next() {
+		a, err := scanAlert(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan alert: %w", err)
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// SaveAlert implements repositories.AlertRepository. An alert's user and
+// product are fixed when it is created.
+func (r *AlertRepository) SaveAlert(ctx context.Context, a domain.PriceAlert) error {
+	if !validKey(r.d, a.ID) {
+		return fmt.Errorf("alert %q: %w", a.ID, domain.ErrNotFound)
+	}
+	res, err := r.stmts.ExecContext(ctx, r.db.Writer(), r.q.update, r.d.Args(a.ID, a.Condition, zeroOrNull(a.TargetPrice),
+		zeroOrNull(a.TargetPricePerGram), zeroOrNull(a.DropPercent), zeroOrNull(a.BasePrice), zeroOrNull(a.LowDays),
+		a.Pending, timeOrNull(a.TriggeredAt))...)
+	if err != nil {
+		return fmt.Errorf("save alert: %w", err)
+	}
+	return requireRow(res, "alert", a.ID)
+}
+
+// DeleteAlert implements repositories.AlertRepository.
+func (r *AlertRepository) DeleteAlert(ctx context.Context, id string, at time.Time) error {
+	if !validKey(r.d, id) {
+		return fmt.Errorf("alert %q: %w", id, domain.ErrNotFound)
+	}
+	res, err := r.stmts.ExecContext(ctx, r.db.Writer(), r.q.markDeleted, r.d.Args(id, at)...)
+	if err != nil {
+		return fmt.Errorf("delete alert: %w", err)
+	}
+	return requireRow(res, "alert", id)
+}
+
+// RestoreAlert implements repositories.AlertRepository.
+func (r *AlertRepository) RestoreAlert(ctx context.Context, id string) (domain.PriceAlert, error) {
+	var restored domain.PriceAlert
+	db := r.db.Writer()
+	err := database.InTx(ctx, db, nil, func(ctx context.Context) error {
+		a, err := r.alert(ctx, r.q.anyByID, "deleted alert", id)
+		if err != nil {
+			return err
+		}
+		if a.DeletedAt == nil {
+			return fmt.Errorf("deleted alert %q: %w", id, domain.ErrNotFound)
+		}
+		res, err := r.stmts.ExecContext(ctx, db, r.q.restore, id)
+		if err != nil {
+			return fmt.Errorf("restore alert: %w", err)
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return fmt.Errorf("restore alert: %w", err)
+		} else if n == 0 {
+			return fmt.Errorf("the user has another alert for product %q: %w", a.ProductID, domain.ErrConflict)
+		}
+		a.DeletedAt = nil
+		restored = *a
+		return nil
+	})
+	return restored, err
+}
+
+// zeroOrNull binds v, or NULL for the parameters of other conditions,
+// which are zero.
+func zeroOrNull[T int | float64](v T) any {
+	if v == 0 {
+		return nil
+	}
+	return v
+}
+
+func scanAlert(row scanner) (domain.PriceAlert, error) {
+	var (
+		a                  domain.PriceAlert
+		triggered, deleted sql.NullTime
+	)
+	if err := row.Scan(&a.ID, &a.UserID, &a.ProductID, &a.Condition, &a.TargetPrice, &a.TargetPricePerGram,
+		&a.DropPercent, &a.BasePrice, &a.LowDays, &a.Pending, &a.CreatedAt, &triggered, &deleted); err != nil {
+		return a, err
+	}
+	if triggered.Valid {
+		a.TriggeredAt = &triggered.Time
+	}
+	if deleted.Valid {
+		a.DeletedAt = &deleted.Time
+	}
+	return a, nil
+}
@@ -0,0 +1,100 @@
+package sqlstore
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/seed"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestAlertRepository(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAlertRepository", "internal/repositories/sqlstore")
+
+	key, err := NewPIIKey(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatalf("NewPIIKey failed: %v", err)
+	}
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	c := seed.Generate(seed.Config{Products: 2, Days: 1, Seed: 4, Now: now})
+	for _, db := range testDatabases(t, logger) {
+		t.Run(db.dialect.String(), func(t *testing.T) {
+			ctx := t.Context()
+			testhelpers.LogTestStep(logger, "arrange", "A user and a two-product catalog")
+			if err := c.Insert(ctx, db.router.Writer(), db.dialect, true); err != nil {
+				t.Fatalf("Seed failed: %v", err)
+			}
+			u, err := NewUserRepository(db.dialect, db.router, db.stmts, key).CreateUser(ctx, domain.User{Email: "meera@example.com", CreatedAt: now})
+			if err != nil {
+				t.Fatalf("CreateUser failed: %v", err)
+			}
+			repo := NewAlertRepository(db.dialect, db.router, db.stmts)
+			first, second := c.Products[0].ID, c.Products[1].ID
+
+			testhelpers.LogTestStep(logger, "act", "Creating alerts on both products, then a second on the first")
+			a, err := repo.CreateAlert(ctx, domain.PriceAlert{UserID: u.ID, ProductID: first, Condition: domain.ConditionPrice, TargetPrice: 1999, CreatedAt: now})
+			if err != nil || a.ID == "" {
+				t.Fatalf("CreateAlert = %+v, %v; want an ID", a, err)
+			}
+			b, err := repo.CreateAlert(ctx, domain.PriceAlert{UserID: u.ID, ProductID: second, Condition: domain.ConditionLow, LowDays: 30, CreatedAt: now.Add(time.Minute)})
+			if err != nil {
+				t.Fatalf("CreateAlert failed: %v", err)
+			}
+			_, err = repo.CreateAlert(ctx, domain.PriceAlert{UserID: u.ID, ProductID: first, Condition: domain.ConditionPercentDrop, DropPercent: 10, CreatedAt: now})
+
+			testhelpers.LogTestStep(logger, "assert", "The duplicate is refused; the user's alerts are newest first")
+			testhelpers.LogTestAssertion(logger, "duplicate", domain.ErrConflict, err)
+			if !errors.Is(err, domain.ErrConflict) {
+				t.Errorf("Second CreateAlert on a product = %v, want ErrConflict", err)
+			}
+			alerts, err := repo.UserAlerts(ctx, u.ID)
+			if err != nil || len(alerts) != 2 || alerts[0].ID != b.ID || alerts[1].LowDays != 0 || alerts[0].LowDays != 30 {
+				t.Errorf("UserAlerts = %+v, %v; want the low alert, then the price alert", alerts, err)
+			}
+
+			testhelpers.LogTestStep(logger, "act", "Triggering the first alert")
+			a.TriggeredAt, a.Pending = &now, true
+			if err := repo.SaveAlert(ctx, a); err != nil {
+				t.Fatalf("SaveAlert failed: %v", err)
+			}
+			if got, err := repo.Alert(ctx, a.ID); err != nil || got.TriggeredAt == nil || !got.Pending || got.TargetPrice != 1999 {
+				t.Errorf("Alert = %+v, %v; want it triggered and pending", got, err)
+			}
+
+			testhelpers.LogTestStep(logger, "act", "Deleting the first alert, replacing it, then restoring it")
+			if err := repo.DeleteAlert(ctx, a.ID, now); err != nil {
+				t.Fatalf("DeleteAlert failed: %v", err)
+			}
+			if _, err := repo.Alert(ctx, a.ID); !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("Alert after DeleteAlert = %v, want ErrNotFound", err)
+			}
+			if deleted, err := repo.DeletedAlerts(ctx, u.ID); err != nil || len(deleted) != 1 || deleted[0].ID != a.ID {
+				t.Errorf("DeletedAlerts = %+v, %v; want the deleted alert", deleted, err)
+			}
+			replacement, err := repo.CreateAlert(ctx, domain.PriceAlert{UserID: u.ID, ProductID: first, Condition: domain.ConditionPrice, TargetPrice: 1799, CreatedAt: now})
+			if err != nil {
+				t.Fatalf("CreateAlert after DeleteAlert failed: %v", err)
+			}
+			if _, err := repo.RestoreAlert(ctx, a.ID); !errors.Is(err, domain.ErrConflict) {
+				t.Errorf("RestoreAlert over a live alert = %v, want ErrConflict", err)
+			}
+			if err := repo.DeleteAlert(ctx, replacement.ID, now); err != nil {
+				t.Fatalf("DeleteAlert failed: %v", err)
+			}
+			restored, err := repo.RestoreAlert(ctx, a.ID)
+			testhelpers.LogTestAssertion(logger, "restored", a.ID, restored.ID)
+			if err != nil || restored.ID != a.ID || restored.DeletedAt != nil {
+				t.Errorf("RestoreAlert = %+v, %v; want the alert live again", restored, err)
+			}
+			if got, err := repo.ProductAlerts(ctx, first); err != nil || len(got) != 1 || got[0].ID != a.ID {
+				t.Errorf("ProductAlerts = %+v, %v; want the restored alert", got, err)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAlertRepository", true)
+}
@@ -0,0 +1,155 @@
+package sqlstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/database"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+const appendAuditQuery = `
+INSERT INTO audit_logs (actor_id, action, resource_type, resource_id, reason, changes, ip_address,
+    user_agent, http_method, endpoint, request_id, success, error_message, metadata, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+
+// AuditRepository implements repositories.AuditRepository on the
+// audit_logs table from migrations 001 and 010. Entries are written with
+// the actor in actor_id and user_id left empty, since admin principals
+// need not be users. The log is read from replicas.
+type AuditRepository struct {
+	d     database.Dialect
+	db    *database.Router
+	stmts *database.StatementCache
+}
+
+// NewAuditRepository creates an AuditRepository for a database of dialect
+// d.
+func NewAuditRepository(d database.Dialect, db *database.Router, stmts *database.StatementCache) *AuditRepository {
+	return &AuditRepository{d: d, db: db, stmts: stmts}
+}
+
+// Append implements repositories.AuditRepository. The database assigns
+// the entry's ID.
+func (r *AuditRepository) Append(ctx context.Context, e domain.AuditEntry) error {
+	changes, err := jsonOrNull(e.Changes, len(e.Changes) > 0)
+	if err != nil {
+		return fmt.Errorf("encode audit changes: %w", err)
+	}
+	metadata, err := jsonOrNull(e.Metadata, len(e.Metadata) > 0)
+	if err != nil {
+		return fmt.Errorf("encode audit metadata: %w", err)
+	}
+	_, err = r.stmts.ExecContext(ctx, r.db.Writer(), r.d.Rebind(appendAuditQuery), r.d.Args(
+		nullIfEmpty(e.ActorID), e.Action, nullIfEmpty(e.ResourceType), nullIfEmpty(e.ResourceID), nullIfEmpty(e.Reason),
+		changes, nullIfEmpty(e.IPAddress), nullIfEmpty(e.UserAgent), nullIfEmpty(e.HTTPMethod), nullIfEmpty(e.Endpoint),
+		nullIfEmpty(e.RequestID), e.Success, nullIfEmpty(e.ErrorMessage), metadata, e.CreatedAt,
+	)...)
+	if err != nil {
+		return fmt.Errorf("append audit entry: %w", err)
+	}
+	return nil
+}
+
+// List implements repositories.AuditRepository.
+func (r *AuditRepository) List(ctx context.Context, filter repositories.AuditFilter) ([]domain.AuditEntry, error) {
+	// Postgres keeps the address as INET, whose text form carries a mask.
+	ip := "ip_address"
+	if r.d == database.Postgres {
+		ip = "host(ip_address)"
+	}
+	b := r.d.Build().Append(`
+SELECT ` + r.d.Text("id") + `, coalesce(actor_id, ''), action, coalesce(resource_type, ''), coalesce(resource_id, ''),
+    coalesce(reason, ''), changes, coalesce(` + ip + `, ''), coalesce(user_agent, ''), coalesce(http_method, ''),
+    coalesce(endpoint, ''), coalesce(request_id, ''), success, coalesce(error_message, ''), metadata, created_at
+FROM audit_logs WHERE 1 = 1`)
+	for _, f := range []struct{ column, value string }{
+		{"actor_id", filter.ActorID},
+		{"action", filter.Action},
+		{"resource_type", filter.ResourceType},
+		{"resource_id", filter.ResourceID},
+	} {
+		if f.value != "" {
+			b.Append(" AND "+f.column+" = ?", f.value)
+		}
+	}
+	if !filter.Since.IsZero() {
+		b.Append(" AND created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		b.Append(" AND created_at < ?", filter.Until)
+	}
+	b.Append(" ORDER BY created_at DESC, id DESC")
+	if filter.Limit > 0 || filter.Offset > 0 {
+		// SQLite takes a negative limit as none; OFFSET needs a LIMIT.
+		limit := filter.Limit
+		if limit <= 0 {
+			limit = -1
+			if r.d == database.Postgres {
+				limit = 1 << 62
+			}
+		}
+		b.Append(" LIMIT ? OFFSET ?", limit, filter.Offset)
+	}
+	query, args := b.Query()
+
+	rows, err := r.db.Reader(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list audit log: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []domain.AuditEntry
+	for rows.Next() {
+		var e domain.AuditEntry
+		var changes, metadata []byte
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.Action, &e.ResourceType, &e.ResourceID, &e.Reason, &changes,
+			&e.IPAddress, &e.UserAgent, &e.HTTPMethod, &e.Endpoint, &e.RequestID, &e.Success, &e.ErrorMessage,
+			&metadata, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan audit entry: %w", err)
+		}
+		if len(changes) > 0 {
+			if err := json.Unmarshal(changes, &e.Changes); err != nil {
+				return nil, fmt.Errorf("decode audit entry %s changes: %w", e.ID, err)
+			}
+		}
+		if len(metadata) > 0 {
+			if err := json.Unmarshal(metadata, &e.Metadata); err != nil {
+				return nil, fmt.Errorf("decode audit entry %s metadata: %w", e.ID, err)
+			}
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// nullIfEmpty binds s, or NULL for "".
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// timeOrNull binds *t, or NULL if t is nil.
+func timeOrNull(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+// jsonOrNull binds v as JSON text if present, or NULL.
+func jsonOrNull(v any, present bool) (any, error) {
+	if !present {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
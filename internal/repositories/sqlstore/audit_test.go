@@ -0,0 +1,94 @@
+package sqlstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestAuditRepository(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAuditRepository", "internal/repositories/sqlstore")
+
+	at := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	for _, db := range testDatabases(t, logger) {
+		t.Run(db.dialect.String(), func(t *testing.T) {
+			ctx := t.Context()
+			audit := NewAuditRepository(db.dialect, db.router, db.stmts)
+
+			testhelpers.LogTestStep(logger, "act", "Appending an update, a correction and a failed delete")
+			entries := []domain.AuditEntry{
+				{
+					ActorID: "ops", Action: "update_product", ResourceType: "product", ResourceID: "prod_1",
+					Reason: "Label reprint", IPAddress: "203.0.113.7", Success: true, CreatedAt: at,
+					Changes:  []domain.AuditChange{{Field: "name", Old: "Gold Whey", New: "Gold Standard Whey"}},
+					Metadata: map[string]any{"before": map[string]any{"name": "Gold Whey"}},
+				},
+				{
+					ActorID: "ops", Action: "correct_price", ResourceType: "listing", ResourceID: "lst_1",
+					Reason: "Coupon price scraped as list price", Success: true, CreatedAt: at.Add(time.Minute),
+				},
+				{
+					ActorID: "intern", Action: "delete_product", ResourceType: "product", ResourceID: "prod_2",
+					Success: false, ErrorMessage: "not found", CreatedAt: at.Add(2 * time.Minute),
+				},
+			}
+			for _, e := range entries {
+				if err := audit.Append(ctx, e); err != nil {
+					t.Fatalf("Append: %v", err)
+				}
+			}
+
+			testhelpers.LogTestStep(logger, "assert", "Entries list newest first with their changes")
+			all, err := audit.List(ctx, repositories.AuditFilter{})
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			testhelpers.LogTestAssertion(logger, "entries", 3, len(all))
+			if len(all) != 3 || all[0].Action != "delete_product" || all[2].Action != "update_product" {
+				t.Fatalf("List = %+v", all)
+			}
+			update := all[2]
+			if update.ID == "" || update.Reason != "Label reprint" || update.IPAddress != "203.0.113.7" || !update.CreatedAt.Equal(at) ||
+				len(update.Changes) != 1 || update.Changes[0].New != "Gold Standard Whey" || update.Metadata["before"] == nil {
+				t.Errorf("Update entry = %+v", update)
+			}
+			if all[0].Success || all[0].ErrorMessage != "not found" || all[0].Changes != nil {
+				t.Errorf("Failed entry = %+v", all[0])
+			}
+
+			testhelpers.LogTestStep(logger, "assert", "Filters narrow and pages slice")
+			for _, tc := range []struct {
+				name   string
+				filter repositories.AuditFilter
+				want   []string
+			}{
+				{"actor", repositories.AuditFilter{ActorID: "ops"}, []string{"correct_price", "update_product"}},
+				{"action", repositories.AuditFilter{Action: "correct_price"}, []string{"correct_price"}},
+				{"resource", repositories.AuditFilter{ResourceType: "product", ResourceID: "prod_1"}, []string{"update_product"}},
+				{"window", repositories.AuditFilter{Since: at.Add(time.Minute), Until: at.Add(2 * time.Minute)}, []string{"correct_price"}},
+				{"page", repositories.AuditFilter{Limit: 1, Offset: 1}, []string{"correct_price"}},
+				{"offset", repositories.AuditFilter{Offset: 2}, []string{"update_product"}},
+			} {
+				got, err := audit.List(ctx, tc.filter)
+				if err != nil {
+					t.Fatalf("List %s: %v", tc.name, err)
+				}
+				var actions []string
+				for _, e := range got {
+					actions = append(actions, e.Action)
+				}
+				testhelpers.LogTestAssertion(logger, tc.name, tc.want, actions)
+				if len(actions) != len(tc.want) || len(actions) > 0 && actions[0] != tc.want[0] ||
+					len(actions) > 1 && actions[1] != tc.want[1] {
+					t.Errorf("List %s = %v, want %v", tc.name, actions, tc.want)
+				}
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAuditRepository", true)
+}
@@ -0,0 +1,690 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/database"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Conditions of the catalog reads. Products and variants are shown while
+// active and not deleted; listings while active and their variant is.
+const (
+	liveProduct = `p.is_active AND p.deleted_at IS NULL`
+	liveVariant = `v.is_active AND v.deleted_at IS NULL`
+)
+
+// catalogQueries are the catalog statements in one dialect. Keys are read
+// as text; see database.Dialect.Text.
+type catalogQueries struct {
+	product, products, productsByIDs           string
+	productByID, productAnyByID                string
+	variantsByProducts, variantAnyByID         string
+	retailer, retailers, retailersByIDs        string
+	listingsByProducts, listingAnyByID         string
+	history                                    string
+	insertProduct, updateProduct               string
+	insertVariant, updateVariant, saveRetailer string
+}
+
+func newCatalogQueries(d database.Dialect) catalogQueries {
+	product := `
+SELECT ` + d.Text("p.id") + `, ` + d.Text("p.brand_id") + `, coalesce(b.name, ''), ` + d.Text("p.category_id") + `, coalesce(c.name, ''),
+    p.name, p.slug, coalesce(p.description, ''), coalesce(p.protein_per_serving, 0), coalesce(p.servings_per_container, 0),
+    coalesce(p.serving_size, ''), coalesce(p.image_url, ''), coalesce(p.is_active, TRUE), p.created_at, p.updated_at,
+    p.deleted_at, p.version
+FROM products p
+LEFT JOIN brands b ON b.id = p.brand_id
+LEFT JOIN categories c ON c.id = p.category_id`
+	variant := `
+SELECT ` + d.Text("v.id") + `, ` + d.Text("v.product_id") + `, coalesce(v.flavor, ''), coalesce(v.size, ''),
+    coalesce(v.size_normalized_grams, 0), coalesce(v.sku, ''), coalesce(v.is_active, TRUE), v.deleted_at, v.version
+FROM product_variants v`
+	retailer := `
+SELECT ` + d.Text("id") + `, name, slug, website_url, coalesce(logo_url, ''), coalesce(requests_per_minute, 10),
+    coalesce(is_active, TRUE)
+FROM retailers`
+	listing := `
+SELECT ` + d.Text("l.id") + `, ` + d.Text("l.product_variant_id") + `, ` + d.Text("l.retailer_id") + `,
+    coalesce(l.retailer_product_id, ''), l.retailer_url, coalesce(l.current_price, 0), coalesce(l.original_price, 0),
+    coalesce(l.currency, 'INR'), coalesce(l.is_available, TRUE), l.last_scraped_at, coalesce(l.is_active, TRUE),
+    ` + d.Text("v.product_id") + `
+FROM product_listings l JOIN product_variants v ON v.id = l.product_variant_id`
+	return catalogQueries{
+		product:        product,
+		productByID:    d.Rebind(product + ` WHERE p.id = $1 AND ` + liveProduct),
+		productAnyByID: d.Rebind(product + ` WHERE p.id = $1`),
+		products: d.Rebind(product + `
+WHERE ` + liveProduct + ` AND ($1 = '' OR ` + d.Text("p.brand_id") + ` = $1) AND ($2 = '' OR ` + d.Text("p.category_id") + ` = $2)
+ORDER BY p.id LIMIT $3 OFFSET $4`),
+		productsByIDs:      d.Rebind(product + ` WHERE ` + liveProduct + ` AND ` + keyIn(d, "p.id", 1)),
+		variantsByProducts: d.Rebind(variant + ` WHERE ` + liveVariant + ` AND ` + keyIn(d, "v.product_id", 1) + ` ORDER BY v.id`),
+		variantAnyByID:     d.Rebind(variant + ` WHERE v.id = $1`),
+		retailer:           d.Rebind(retailer + ` WHERE id = $1`),
+		retailers:          retailer + ` ORDER BY id`,
+		retailersByIDs:     d.Rebind(retailer + ` WHERE ` + keyIn(d, "id", 1)),
+		listingsByProducts: d.Rebind(listing + ` WHERE l.is_active AND ` + liveVariant + ` AND ` + keyIn(d, "v.product_id", 1) + ` ORDER BY l.id`),
+		listingAnyByID:     d.Rebind(listing + ` WHERE l.id = $1`),
+		history: d.Rebind(`
+SELECT ` + d.Text("id") + `, ` + d.Text("product_listing_id") + `, price, coalesce(previous_price, 0),
+    coalesce(currency, ''), in_stock, recorded_at, coalesce(source, '')
+FROM price_history WHERE ` + keyIn(d, "product_listing_id", 1) + ` AND recorded_at >= $2 ORDER BY recorded_at`),
+		// A save names the version it read and bumps it in the same
+		// statement (migration 013); a new record is saved at version 0.
+		insertProduct: d.Rebind(`
+INSERT INTO products (id, brand_id, category_id, name, slug, description, protein_per_serving,
+    servings_per_container, serving_size, image_url, is_active, created_at, updated_at, deleted_at, version)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, 1)
+ON CONFLICT (id) DO NOTHING`),
+		updateProduct: d.Rebind(`
+UPDATE products SET brand_id = $2, category_id = $3, name = $4, slug = $5, description = $6, protein_per_serving = $7,
+    servings_per_container = $8, serving_size = $9, image_url = $10, is_active = $11, updated_at = $12,
+    deleted_at = $13, version = version + 1
+WHERE id = $1 AND version = $14`),
+		insertVariant: d.Rebind(`
+INSERT INTO product_variants (id, product_id, flavor, size, size_normalized_grams, sku, is_active, deleted_at, version)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 1)
+ON CONFLICT (id) DO NOTHING`),
+		updateVariant: d.Rebind(`
+UPDATE product_variants SET product_id = $2, flavor = $3, size = $4, size_normalized_grams = $5, sku = $6,
+    is_active = $7, deleted_at = $8, updated_at = ` + d.Now() + `, version = version + 1
+WHERE id = $1 AND version = $9`),
+		saveRetailer: d.Rebind(`
+INSERT INTO retailers (id, name, slug, website_url, logo_url, requests_per_minute, is_active)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (id) DO UPDATE SET name = excluded.name, slug = excluded.slug, website_url = excluded.website_url,
+    logo_url = excluded.logo_url, requests_per_minute = excluded.requests_per_minute, is_active = excluded.is_active,
+    updated_at = ` + d.Now()),
+	}
+}
+
+// ProductRepository implements repositories.ProductRepository on the
+// products and product_variants tables. Reads go to replicas.
+type ProductRepository struct {
+	d     database.Dialect
+	db    *database.Router
+	stmts *database.StatementCache
+	q     catalogQueries
+}
+
+// NewProductRepository creates a ProductRepository for a database of
+// dialect d.
+func NewProductRepository(d database.Dialect, db *database.Router, stmts *database.StatementCache) *ProductRepository {
+	return &ProductRepository{d: d, db: db, stmts: stmts, q: newCatalogQueries(d)}
+}
+
+// FindByID implements repositories.ProductRepository.
+func (r *ProductRepository) FindByID(ctx context.Context, id string) (*domain.Product, error) {
+	return findProduct(ctx, r.d, r.stmts, r.db.Reader(ctx), r.q.productByID, id)
+}
+
+// List implements repositories.ProductRepository.
+func (r *ProductRepository) List(ctx context.Context, filter repositories.ProductFilter) ([]domain.Product, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = noLimit(r.d)
+	}
+	rows, err := r.stmts.QueryContext(ctx, r.db.Reader(ctx), r.q.products, filter.BrandID, filter.CategoryID, limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("list products: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []domain.Product
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan product: %w", err)
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// Variants implements repositories.ProductRepository.
+func (r *ProductRepository) Variants(ctx context.Context, productID string) ([]domain.Variant, error) {
+	byProduct, err := r.VariantsByProducts(ctx, []string{productID})
+	return byProduct[productID], err
+}
+
+// FindByIDs implements repositories.ProductRepository.
+func (r *ProductRepository) FindByIDs(ctx context.Context, ids []string) (map[string]domain.Product, error) {
+	out := make(map[string]domain.Product, len(ids))
+	err := queryByKeys(ctx, r.stmts, r.db.Reader(ctx), r.d, r.q.productsByIDs, validKeys(r.d, ids), nil, func(row scanner) error {
+		p, err := scanProduct(row)
+		out[p.ID] = p
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read products: %w", err)
+	}
+	return out, nil
+}
+
+// VariantsByProducts implements repositories.ProductRepository.
+func (r *ProductRepository) VariantsByProducts(ctx context.Context, productIDs []string) (map[string][]domain.Variant, error) {
+	out := make(map[string][]domain.Variant, len(productIDs))
+	err := queryByKeys(ctx, r.stmts, r.db.Reader(ctx), r.d, r.q.variantsByProducts, validKeys(r.d, productIDs), nil, func(row scanner) error {
+		v, err := scanVariant(row)
+		out[v.ProductID] = append(out[v.ProductID], v)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read variants: %w", err)
+	}
+	return out, nil
+}
+
+// RetailerRepository implements repositories.RetailerRepository on the
+// retailers table. Reads go to replicas.
+type RetailerRepository struct {
+	d     database.Dialect
+	db    *database.Router
+	stmts *database.StatementCache
+	q     catalogQueries
+}
+
+// NewRetailerRepository creates a RetailerRepository for a database of
+// dialect d.
+func NewRetailerRepository(d database.Dialect, db *database.Router, stmts *database.StatementCache) *RetailerRepository {
+	return &RetailerRepository{d: d, db: db, stmts: stmts, q: newCatalogQueries(d)}
+}
+
+// FindByID implements repositories.RetailerRepository.
+func (r *RetailerRepository) FindByID(ctx context.Context, id string) (*domain.Retailer, error) {
+	return findRetailer(ctx, r.d, r.stmts, r.db.Reader(ctx), r.q.retailer, id)
+}
+
+// List implements repositories.RetailerRepository.
+func (r *RetailerRepository) List(ctx context.Context) ([]domain.Retailer, error) {
+	rows, err := r.stmts.QueryContext(ctx, r.db.Reader(ctx), r.q.retailers)
+	if err != nil {
+		return nil, fmt.Errorf("list retailers: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []domain.Retailer
+	for rows.Next() {
+		ret, err := scanRetailer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan retailer: %w", err)
+		}
+		out = append(out, ret)
+	}
+	return out, rows.Err()
+}
+
+// FindByIDs implements repositories.RetailerRepository.
+func (r *RetailerRepository) FindByIDs(ctx context.Context, ids []string) (map[string]domain.Retailer, error) {
+	out := make(map[string]domain.Retailer, len(ids))
+	err := queryByKeys(ctx, r.stmts, r.db.Reader(ctx), r.d, r.q.retailersByIDs, validKeys(r.d, ids), nil, func(row scanner) error {
+		ret, err := scanRetailer(row)
+		out[ret.ID] = ret
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read retailers: %w", err)
+	}
+	return out, nil
+}
+
+// ListingRepository implements repositories.ListingRepository on the
+// product_listings table. Reads go to replicas.
+type ListingRepository struct {
+	d     database.Dialect
+	db    *database.Router
+	stmts *database.StatementCache
+	q     catalogQueries
+}
+
+// NewListingRepository creates a ListingRepository for a database of
+// dialect d.
+func NewListingRepository(d database.Dialect, db *database.Router, stmts *database.StatementCache) *ListingRepository {
+	return &ListingRepository{d: d, db: db, stmts: stmts, q: newCatalogQueries(d)}
+}
+
+// ByProduct implements repositories.ListingRepository.
+func (r *ListingRepository) ByProduct(ctx context.Context, productID string) ([]domain.Listing, error) {
+	byProduct, err := r.ByProducts(ctx, []string{productID})
+	return byProduct[productID], err
+}
+
+// ByProducts implements repositories.ListingRepository.
+func (r *ListingRepository) ByProducts(ctx context.Context, productIDs []string) (map[string][]domain.Listing, error) {
+	out := make(map[string][]domain.Listing, len(productIDs))
+	err := queryByKeys(ctx, r.stmts, r.db.Reader(ctx), r.d, r.q.listingsByProducts, validKeys(r.d, productIDs), nil, func(row scanner) error {
+		l, productID, err := scanListing(row)
+		out[productID] = append(out[productID], l)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read listings: %w", err)
+	}
+	return out, nil
+}
+
+// PriceRepository implements repositories.PriceRepository and
+// repositories.DailyPriceRepository on the price_history table, rolling
+// history up by day as it is read. Reads go to replicas.
+type PriceRepository struct {
+	d     database.Dialect
+	db    *database.Router
+	stmts *database.StatementCache
+	q     catalogQueries
+}
+
+// NewPriceRepository creates a PriceRepository for a database of dialect
+// d.
+func NewPriceRepository(d database.Dialect, db *database.Router, stmts *database.StatementCache) *PriceRepository {
+	return &PriceRepository{d: d, db: db, stmts: stmts, q: newCatalogQueries(d)}
+}
+
+// History implements repositories.PriceRepository.
+func (r *PriceRepository) History(ctx context.Context, listingIDs []string, since time.Time) ([]domain.PricePoint, error) {
+	var out []domain.PricePoint
+	err := queryByKeys(ctx, r.stmts, r.db.Reader(ctx), r.d, r.q.history, validKeys(r.d, listingIDs), r.d.Args(since), func(row scanner) error {
+		var p domain.PricePoint
+		if err := row.Scan(&p.ID, &p.ListingID, &p.Price, &p.PreviousPrice, &p.Currency, &p.InStock, &p.RecordedAt, &p.Source); err != nil {
+			return err
+		}
+		out = append(out, p)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read price history: %w", err)
+	}
+	return out, nil
+}
+
+// DailyHistory implements repositories.DailyPriceRepository.
+func (r *PriceRepository) DailyHistory(ctx context.Context, listingIDs []string, since time.Time) ([]domain.DailyPrice, error) {
+	points, err := r.History(ctx, listingIDs, since)
+	if err != nil {
+		return nil, err
+	}
+	return domain.RollUpDaily(points), nil
+}
+
+// CatalogAdminRepository implements repositories.CatalogAdminRepository
+// on the catalog tables. It reads the primary, so that a unit of work
+// sees what it wrote and a save is checked against the current version.
+type CatalogAdminRepository struct {
+	d     database.Dialect
+	db    *database.Router
+	stmts *database.StatementCache
+	q     catalogQueries
+}
+
+// NewCatalogAdminRepository creates a CatalogAdminRepository for a
+// database of dialect d.
+func NewCatalogAdminRepository(d database.Dialect, db *database.Router, stmts *database.StatementCache) *CatalogAdminRepository {
+	return &CatalogAdminRepository{d: d, db: db, stmts: stmts, q: newCatalogQueries(d)}
+}
+
+// Product implements repositories.CatalogAdminRepository.
+func (r *CatalogAdminRepository) Product(ctx context.Context, id string) (*domain.Product, error) {
+	return findProduct(ctx, r.d, r.stmts, r.db.Writer(), r.q.productAnyByID, id)
+}
+
+// SaveProduct implements repositories.CatalogAdminRepository.
+func (r *CatalogAdminRepository) SaveProduct(ctx context.Context, p domain.Product, events ...domain.Event) error {
+	if !validKey(r.d, p.ID) {
+		return fmt.Errorf("product ID %q is not a UUID: %w", p.ID, domain.ErrInvalid)
+	}
+	var serving any
+	if p.ServingSizeGrams > 0 {
+		serving = fmt.Sprintf("%gg", p.ServingSizeGrams)
+	}
+	return withEvents(ctx, r.db.Writer(), r.d, events, func(tx database.Querier) error {
+		args := r.d.Args(p.ID, p.BrandID, p.CategoryID, p.Name, p.Slug, nullIfEmpty(p.Description), p.ProteinPerServing,
+			p.ServingsPerContainer, serving, nullIfEmpty(p.ImageURL), p.IsActive)
+		query := r.q.insertProduct
+		if p.Version > 0 {
+			query = r.q.updateProduct
+			args = append(args, r.d.Args(p.UpdatedAt, timeOrNull(p.DeletedAt), p.Version)...)
+		} else {
+			args = append(args, r.d.Args(p.CreatedAt, p.UpdatedAt, timeOrNull(p.DeletedAt))...)
+		}
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("save product: %w", err)
+		}
+		return requireVersion(res, "product", p.ID, p.Version)
+	})
+}
+
+// Variant implements repositories.CatalogAdminRepository.
+func (r *CatalogAdminRepository) Variant(ctx context.Context, id string) (*domain.Variant, error) {
+	if !validKey(r.d, id) {
+		return nil, fmt.Errorf("variant %q: %w", id, domain.ErrNotFound)
+	}
+	v, err := scanVariant(r.stmts.QueryRowContext(ctx, r.db.Writer(), r.q.variantAnyByID, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("variant %q: %w", id, domain.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read variant: %w", err)
+	}
+	return &v, nil
+}
+
+// SaveVariant implements repositories.CatalogAdminRepository.
+func (r *CatalogAdminRepository) SaveVariant(ctx context.Context, v domain.Variant, events ...domain.Event) error {
+	if !validKey(r.d, v.ID) {
+		return fmt.Errorf("variant ID %q is not a UUID: %w", v.ID, domain.ErrInvalid)
+	}
+	return withEvents(ctx, r.db.Writer(), r.d, events, func(tx database.Querier) error {
+		args := r.d.Args(v.ID, v.ProductID, nullIfEmpty(v.Flavor), nullIfEmpty(v.Size), v.SizeGrams, nullIfEmpty(v.SKU),
+			v.IsActive, timeOrNull(v.DeletedAt))
+		query := r.q.insertVariant
+		if v.Version > 0 {
+			query = r.q.updateVariant
+			args = append(args, v.Version)
+		}
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("save variant: %w", err)
+		}
+		return requireVersion(res, "variant", v.ID, v.Version)
+	})
+}
+
+// Retailer implements repositories.CatalogAdminRepository.
+func (r *CatalogAdminRepository) Retailer(ctx context.Context, id string) (*domain.Retailer, error) {
+	return findRetailer(ctx, r.d, r.stmts, r.db.Writer(), r.q.retailer, id)
+}
+
+// SaveRetailer implements repositories.CatalogAdminRepository.
+func (r *CatalogAdminRepository) SaveRetailer(ctx context.Context, ret domain.Retailer) error {
+	if !validKey(r.d, ret.ID) {
+		return fmt.Errorf("retailer ID %q is not a UUID: %w", ret.ID, domain.ErrInvalid)
+	}
+	_, err := r.stmts.ExecContext(ctx, r.db.Writer(), r.q.saveRetailer,
+		ret.ID, ret.Name, ret.Slug, ret.WebsiteURL, nullIfEmpty(ret.LogoURL), ret.RequestsPerMinute, ret.IsActive)
+	if err != nil {
+		return fmt.Errorf("save retailer: %w", err)
+	}
+	return nil
+}
+
+// Listing implements repositories.CatalogAdminRepository.
+func (r *CatalogAdminRepository) Listing(ctx context.Context, id string) (*domain.Listing, error) {
+	if !validKey(r.d, id) {
+		return nil, fmt.Errorf("listing %q: %w", id, domain.ErrNotFound)
+	}
+	l, _, err := scanListing(r.stmts.QueryRowContext(ctx, r.db.Writer(), r.q.listingAnyByID, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("listing %q: %w", id, domain.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read listing: %w", err)
+	}
+	return &l, nil
+}
+
+// requireVersion turns a conditional save that touched no row into
+// domain.ErrConflict: the record is at another version, or, saving
+// version 0, already stored.
+func requireVersion(res sql.Result, kind, id string, version int) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s %q: %w", kind, id, err)
+	}
+	if n > 0 {
+		return nil
+	}
+	if version == 0 {
+		return fmt.Errorf("%s %q is already stored: %w", kind, id, domain.ErrConflict)
+	}
+	return fmt.Errorf("%s %q is not at version %d: %w", kind, id, version, domain.ErrConflict)
+}
+
+func findProduct(ctx context.Context, d database.Dialect, stmts *database.StatementCache, db *sql.DB, query, id string) (*domain.Product, error) {
+	if !validKey(d, id) {
+		return nil, fmt.Errorf("product %q: %w", id, domain.ErrNotFound)
+	}
+	p, err := scanProduct(stmts.QueryRowContext(ctx, db, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("product %q: %w", id, domain.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read product: %w", err)
+	}
+	return &p, nil
+}
+
+func findRetailer(ctx context.Context, d database.Dialect, stmts *database.StatementCache, db *sql.DB, query, id string) (*domain.Retailer, error) {
+	if !validKey(d, id) {
+		return nil, fmt.Errorf("retailer %q: %w", id, domain.ErrNotFound)
+	}
+	ret, err := scanRetailer(stmts.QueryRowContext(ctx, db, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("retailer %q: %w", id, domain.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read retailer: %w", err)
+	}
+	return &ret, nil
+}
+
+func scanProduct(row scanner) (domain.Product, error) {
+	var (
+		p                domain.Product
+		serving          string
+		created, updated sql.NullTime
+		deleted          sql.NullTime
+	)
+	if err := row.Scan(&p.ID, &p.BrandID, &p.Brand, &p.CategoryID, &p.Category, &p.Name, &p.Slug, &p.Description,
+		&p.ProteinPerServing, &p.ServingsPerContainer, &serving, &p.ImageURL, &p.IsActive, &created, &updated,
+		&deleted, &p.Version); err != nil {
+		return p, err
+	}
+	p.ServingSizeGrams = servingGrams(serving)
+	p.CreatedAt, p.UpdatedAt = created.Time, updated.Time
+	if deleted.Valid {
+		p.DeletedAt = &deleted.Time
+	}
+	// Postgres derives products.dietary too (migration 007), SQLite does
+	// not; deriving it here keeps the two alike.
+	p.Dietary = domain.ExtractDietary(p.Name + " " + p.Description)
+	return p, nil
+}
+
+// servingGrams reads a serving size stored as "30g"; sizes in other units,
+// such as "1 scoop", are unknown.
+func servingGrams(s string) float64 {
+	g, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "g"), 64)
+	if err != nil || g < 0 {
+		return 0
+	}
+	return g
+}
+
+func scanVariant(row scanner) (domain.Variant, error) {
+	var v domain.Variant
+	var deleted sql.NullTime
+	if err := row.Scan(&v.ID, &v.ProductID, &v.Flavor, &v.Size, &v.SizeGrams, &v.SKU, &v.IsActive, &deleted, &v.Version); err != nil {
+		return v, err
+	}
+	if deleted.Valid {
+		v.DeletedAt = &deleted.Time
+	}
+	return v, nil
+}
+
+func scanRetailer(row scanner) (domain.Retailer, error) {
+	var r domain.Retailer
+	err := row.Scan(&r.ID, &r.Name, &r.Slug, &r.WebsiteURL, &r.LogoURL, &r.RequestsPerMinute, &r.IsActive)
+	return r, err
+}
+
+// scanListing scans a listing and the product its variant belongs to.
+func scanListing(row scanner) (domain.Listing, string, error) {
+	var l domain.Listing
+	var productID string
+	var scraped sql.NullTime
+	if err := row.Scan(&l.ID, &l.VariantID, &l.RetailerID, &l.RetailerProductID, &l.URL, &l.CurrentPrice, &l.OriginalPrice,
+		&l.Currency, &l.InStock, &scraped, &l.IsActive, &productID); err != nil {
+		return l, "", err
+	}
+	l.LastScrapedAt = scraped.Time
+	return l, productID, nil
+}
+
+const (
+	getSelectorsQuery = `
+SELECT config, updated_at, coalesce(updated_by, '') FROM retailer_selectors WHERE retailer_id = $1`
+	saveSelectorsQuery = `
+INSERT INTO retailer_selectors (retailer_id, config, updated_at, updated_by) VALUES ($1, $2, $3, $4)
+ON CONFLICT (retailer_id) DO UPDATE SET config = excluded.config, updated_at = excluded.updated_at,
+    updated_by = excluded.updated_by`
+)
+
+// SelectorRepository implements repositories.SelectorRepository on the
+// retailer_selectors table from migration 016, which holds each config as
+// the JSON the admin API takes. Reads go to the primary, so a scrape after
+// a save uses the saved selectors.
+type SelectorRepository struct {
+	d     database.Dialect
+	db    *database.Router
+	stmts *database.StatementCache
+}
+
+// NewSelectorRepository creates a SelectorRepository for a database of
+// dialect d.
+func NewSelectorRepository(d database.Dialect, db *database.Router, stmts *database.StatementCache) *SelectorRepository {
+	return &SelectorRepository{d: d, db: db, stmts: stmts}
+}
+
+// Selectors implements repositories.SelectorRepository.
+func (r *SelectorRepository) Selectors(ctx context.Context, retailerID string) (*domain.SelectorConfig, error) {
+	if !validKey(r.d, retailerID) {
+		return nil, fmt.Errorf("selector config %q: %w", retailerID, domain.ErrNotFound)
+	}
+	var (
+		config    string
+		updatedAt time.Time
+		updatedBy string
+	)
+	err := r.stmts.QueryRowContext(ctx, r.db.Writer(), r.d.Rebind(getSelectorsQuery), retailerID).Scan(&config, &updatedAt, &updatedBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("selector config %q: %w", retailerID, domain.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read selector config: %w", err)
+	}
+	var cfg domain.SelectorConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, fmt.Errorf("decode selector config %q: %w", retailerID, err)
+	}
+	cfg.RetailerID, cfg.UpdatedAt, cfg.UpdatedBy = retailerID, updatedAt, updatedBy
+	return &cfg, nil
+}
+
+// SaveSelectors implements repositories.SelectorRepository.
+func (r *SelectorRepository) SaveSelectors(ctx context.Context, cfg domain.SelectorConfig) error {
+	if !validKey(r.d, cfg.RetailerID) {
+		return fmt.Errorf("retailer ID %q is not a UUID: %w", cfg.RetailerID, domain.ErrInvalid)
+	}
+	config, err := json.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("encode selector config: %w", err)
+	}
+	if _, err := r.stmts.ExecContext(ctx, r.db.Writer(), r.d.Rebind(saveSelectorsQuery),
+		r.d.Args(cfg.RetailerID, string(config), cfg.UpdatedAt, nullIfEmpty(cfg.UpdatedBy))...); err != nil {
+		return fmt.Errorf("save selector config: %w", err)
+	}
+	return nil
+}
+
+const (
+	recordPriceQuery = `
+INSERT INTO price_history (product_listing_id, price, previous_price, currency, recorded_at, source, in_stock)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING %s`
+	// moveListingQuery moves a listing to a price unless a newer one is
+	// already recorded; an empty currency leaves the listing's.
+	moveListingQuery = `
+UPDATE product_listings SET current_price = $2, is_available = $3, last_scraped_at = $4,
+    currency = coalesce($5, currency), updated_at = %s
+WHERE id = $1 AND (last_scraped_at IS NULL OR last_scraped_at <= $4)`
+)
+
+// RecordPrice implements repositories.PriceWriter.
+func (r *PriceIngestRepository) RecordPrice(ctx context.Context, p domain.PricePoint, events ...domain.Event) (domain.PricePoint, error) {
+	if !validKey(r.d, p.ListingID) {
+		return p, fmt.Errorf("listing %q: %w", p.ListingID, domain.ErrNotFound)
+	}
+	err := withEvents(ctx, r.db.Writer(), r.d, events, func(tx database.Querier) error {
+		var previous any
+		if p.PreviousPrice > 0 {
+			previous = p.PreviousPrice
+		}
+		err := tx.QueryRowContext(ctx, r.d.Rebind(fmt.Sprintf(recordPriceQuery, r.d.Text("id"))),
+			r.d.Args(p.ListingID, p.Price, previous, p.Currency, p.RecordedAt, p.Source, p.InStock)...).Scan(&p.ID)
+		if err != nil {
+			return fmt.Errorf("insert price: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, r.d.Rebind(fmt.Sprintf(moveListingQuery, r.d.Now())),
+			r.d.Args(p.ListingID, p.Price, p.InStock, p.RecordedAt, nullIfEmpty(p.Currency))...); err != nil {
+			return fmt.Errorf("update listing: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return p, fmt.Errorf("record price: %w", err)
+	}
+	return p, nil
+}
+
+const (
+	catalogCountsQuery = `
+SELECT
+    (SELECT count(*) FROM products p WHERE ` + liveProduct + `),
+    (SELECT count(*) FROM retailers WHERE is_active),
+    (SELECT count(*) FROM product_listings WHERE is_active),
+    (SELECT count(*) FROM price_history)`
+	// The newest time is read on its own: SQLite only scans a column as a
+	// time when it is one, not the result of max().
+	lastPriceQuery = `SELECT recorded_at FROM price_history ORDER BY recorded_at DESC LIMIT 1`
+)
+
+// StatsRepository implements repositories.StatsRepository on the catalog
+// tables. Reads go to replicas.
+type StatsRepository struct {
+	db    *database.Router
+	stmts *database.StatementCache
+}
+
+// NewStatsRepository creates a StatsRepository.
+func NewStatsRepository(db *database.Router, stmts *database.StatementCache) *StatsRepository {
+	return &StatsRepository{db: db, stmts: stmts}
+}
+
+// CatalogStats implements repositories.StatsRepository.
+func (r *StatsRepository) CatalogStats(ctx context.Context) (domain.CatalogStats, error) {
+	var stats domain.CatalogStats
+	db := r.db.Reader(ctx)
+	if err := r.stmts.QueryRowContext(ctx, db, catalogCountsQuery).
+		Scan(&stats.Products, &stats.Retailers, &stats.Listings, &stats.PricePoints); err != nil {
+		return stats, fmt.Errorf("count catalog: %w", err)
+	}
+	var last time.Time
+	err := r.stmts.QueryRowContext(ctx, db, lastPriceQuery).Scan(&last)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return stats, fmt.Errorf("read last price: %w", err)
+	default:
+		stats.LastUpdatedAt = &last
+	}
+	return stats, nil
+}
@@ -0,0 +1,377 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/database"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/seed"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestCatalogRepositories(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCatalogRepositories", "internal/repositories/sqlstore")
+
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	c := seed.Generate(seed.Config{Products: 3, Days: 3, Seed: 1, Now: now})
+	for _, db := range testDatabases(t, logger) {
+		t.Run(db.dialect.String(), func(t *testing.T) {
+			ctx := t.Context()
+			testhelpers.LogTestStep(logger, "arrange", "A three-product catalog with three days of prices")
+			if err := c.Insert(ctx, db.router.Writer(), db.dialect, true); err != nil {
+				t.Fatalf("Seed failed: %v", err)
+			}
+			products := NewProductRepository(db.dialect, db.router, db.stmts)
+			listings := NewListingRepository(db.dialect, db.router, db.stmts)
+			prices := NewPriceRepository(db.dialect, db.router, db.stmts)
+			want := c.Products[0]
+
+			testhelpers.LogTestStep(logger, "act", "Reading a product, its variants, listings and history")
+			p, err := products.FindByID(ctx, want.ID)
+			if err != nil {
+				t.Fatalf("FindByID failed: %v", err)
+			}
+			testhelpers.LogTestAssertion(logger, "product", want.Name, p.Name)
+			if p.Name != want.Name || p.Brand != want.Brand || p.Category != want.Category ||
+				p.ServingSizeGrams != want.ServingSizeGrams || p.Version != 1 || !p.Live() {
+				t.Errorf("FindByID = %+v, want %+v", p, want)
+			}
+			if _, err := products.FindByID(ctx, "not-a-product"); !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("FindByID of an unknown ID = %v, want ErrNotFound", err)
+			}
+			page, err := products.List(ctx, repositories.ProductFilter{Limit: 2, Offset: 1})
+			if err != nil || len(page) != 2 || page[0].ID >= page[1].ID {
+				t.Errorf("List = %d products, %v; want the second page of two by ID", len(page), err)
+			}
+			byID, err := products.FindByIDs(ctx, []string{want.ID, want.ID, "not-a-product"})
+			if err != nil || len(byID) != 1 {
+				t.Errorf("FindByIDs = %v, %v; want the one product", byID, err)
+			}
+
+			var variants, active []string
+			for _, v := range c.Variants {
+				if v.ProductID == want.ID {
+					variants = append(variants, v.ID)
+				}
+			}
+			for _, l := range c.Listings {
+				for _, v := range variants {
+					if l.VariantID == v && l.IsActive {
+						active = append(active, l.ID)
+					}
+				}
+			}
+			got, err := products.Variants(ctx, want.ID)
+			if err != nil || len(got) != len(variants) {
+				t.Errorf("Variants = %d, %v; want %d", len(got), err, len(variants))
+			}
+			offered, err := listings.ByProduct(ctx, want.ID)
+			if err != nil || len(offered) != len(active) {
+				t.Errorf("ByProduct = %d, %v; want %d", len(offered), err, len(active))
+			}
+			var seeded int
+			for _, pt := range c.Prices {
+				if pt.ListingID == active[0] {
+					seeded++
+				}
+			}
+			history, err := prices.History(ctx, active[:1], now.AddDate(0, 0, -30))
+			if err != nil || len(history) != seeded {
+				t.Fatalf("History = %d points, %v; want %d", len(history), err, seeded)
+			}
+			for i := 1; i < len(history); i++ {
+				if history[i].RecordedAt.Before(history[i-1].RecordedAt) {
+					t.Errorf("History is not in time order at %d", i)
+				}
+			}
+			days, err := prices.DailyHistory(ctx, active[:1], now.AddDate(0, 0, -30))
+			if err != nil || len(days) == 0 {
+				t.Errorf("DailyHistory = %v, %v; want the seeded days", days, err)
+			}
+
+			stats, err := NewStatsRepository(db.router, db.stmts).CatalogStats(ctx)
+			if err != nil {
+				t.Fatalf("CatalogStats failed: %v", err)
+			}
+			testhelpers.LogTestAssertion(logger, "price points", int64(len(c.Prices)), stats.PricePoints)
+			if stats.Products != 3 || stats.PricePoints != int64(len(c.Prices)) || stats.LastUpdatedAt == nil {
+				t.Errorf("CatalogStats = %+v", stats)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCatalogRepositories", true)
+}
+
+func TestCatalogBatchReads_OneStatement(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCatalogBatchReads_OneStatement", "internal/repositories/sqlstore")
+
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	c := seed.Generate(seed.Config{Products: 6, Days: 2, Seed: 3, Now: now})
+	for _, db := range testDatabases(t, logger) {
+		t.Run(db.dialect.String(), func(t *testing.T) {
+			ctx := t.Context()
+			testhelpers.LogTestStep(logger, "arrange", "A six-product catalog")
+			if err := c.Insert(ctx, db.router.Writer(), db.dialect, true); err != nil {
+				t.Fatalf("Seed failed: %v", err)
+			}
+			products := NewProductRepository(db.dialect, db.router, db.stmts)
+			listings := NewListingRepository(db.dialect, db.router, db.stmts)
+			prices := NewPriceRepository(db.dialect, db.router, db.stmts)
+			ids := make([]string, len(c.Products))
+			for i, p := range c.Products {
+				ids[i] = p.ID
+			}
+			read := func(n int) {
+				t.Helper()
+				found, err := products.FindByIDs(ctx, ids[:n])
+				if err != nil || len(found) != n {
+					t.Fatalf("FindByIDs of %d = %d products, %v", n, len(found), err)
+				}
+				if _, err := products.VariantsByProducts(ctx, ids[:n]); err != nil {
+					t.Fatalf("VariantsByProducts failed: %v", err)
+				}
+				byProduct, err := listings.ByProducts(ctx, ids[:n])
+				if err != nil {
+					t.Fatalf("ByProducts failed: %v", err)
+				}
+				var listingIDs []string
+				for _, ls := range byProduct {
+					for _, l := range ls {
+						listingIDs = append(listingIDs, l.ID)
+					}
+				}
+				if _, err := prices.History(ctx, listingIDs, now.AddDate(0, 0, -7)); err != nil {
+					t.Fatalf("History failed: %v", err)
+				}
+			}
+
+			testhelpers.LogTestStep(logger, "act", "Reading batches of one, then of three and six keys")
+			read(1)
+			first := db.stmts.Stats()
+			read(3)
+			read(len(ids))
+
+			testhelpers.LogTestStep(logger, "assert", "The larger batches reuse the statements the first prepared")
+			got := db.stmts.Stats()
+			testhelpers.LogTestAssertion(logger, "prepared", first.Prepared, got.Prepared)
+			if got.Misses != first.Misses || got.Prepared != first.Prepared || got.Hits != first.Hits+8 {
+				t.Errorf("Statement cache went from %+v to %+v; want 8 hits and nothing prepared", first, got)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCatalogBatchReads_OneStatement", true)
+}
+
+func TestCatalogAdminRepository(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCatalogAdminRepository", "internal/repositories/sqlstore")
+
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	c := seed.Generate(seed.Config{Products: 1, Days: 1, Seed: 2, Now: now})
+	for _, db := range testDatabases(t, logger) {
+		t.Run(db.dialect.String(), func(t *testing.T) {
+			ctx := t.Context()
+			testhelpers.LogTestStep(logger, "arrange", "A one-product catalog")
+			if err := c.Insert(ctx, db.router.Writer(), db.dialect, true); err != nil {
+				t.Fatalf("Seed failed: %v", err)
+			}
+			admin := NewCatalogAdminRepository(db.dialect, db.router, db.stmts)
+			products := NewProductRepository(db.dialect, db.router, db.stmts)
+			stored, err := admin.Product(ctx, c.Products[0].ID)
+			if err != nil {
+				t.Fatalf("Product failed: %v", err)
+			}
+
+			testhelpers.LogTestStep(logger, "act", "Saving a new product, then two edits of the same version")
+			created := *stored
+			created.ID, created.Slug, created.Name, created.Version = "7d3f1c8e-2a4b-4c6d-8e0f-1a2b3c4d5e6f", "vegan-pea", "Vegan Pea Protein", 0
+			if err := admin.SaveProduct(ctx, created); err != nil {
+				t.Fatalf("SaveProduct of a new product failed: %v", err)
+			}
+			if err := admin.SaveProduct(ctx, created); !errors.Is(err, domain.ErrConflict) {
+				t.Errorf("Second SaveProduct at version 0 = %v, want ErrConflict", err)
+			}
+			edit := *stored
+			edit.Name = "Renamed"
+			event := domain.ProductUpdated{ProductID: edit.ID, OccurredAt: now}
+			if err := admin.SaveProduct(ctx, edit, event); err != nil {
+				t.Fatalf("SaveProduct failed: %v", err)
+			}
+			stale := *stored
+			stale.Name = "Lost update"
+			err = admin.SaveProduct(ctx, stale, event)
+
+			testhelpers.LogTestStep(logger, "assert", "The first edit wins, with its event; the stale one is refused")
+			testhelpers.LogTestAssertion(logger, "stale save", domain.ErrConflict, err)
+			if !errors.Is(err, domain.ErrConflict) {
+				t.Errorf("Stale SaveProduct = %v, want ErrConflict", err)
+			}
+			got, err := products.FindByID(ctx, edit.ID)
+			if err != nil || got.Name != "Renamed" || got.Version != 2 {
+				t.Errorf("FindByID = %+v, %v; want the first edit at version 2", got, err)
+			}
+			pending, err := NewOutboxRepository(db.dialect, db.router, db.stmts).Pending(ctx, 10)
+			if err != nil || len(pending) != 1 {
+				t.Errorf("Outbox = %v, %v; want the one saved edit's event", pending, err)
+			}
+			if got, err := products.FindByID(ctx, created.ID); err != nil || !slices.Contains(got.Dietary, domain.DietVegan) {
+				t.Errorf("New product = %+v, %v; want it live and vegan", got, err)
+			}
+
+			testhelpers.LogTestStep(logger, "act", "Deleting the product")
+			got.DeletedAt = &now
+			if err := admin.SaveProduct(ctx, *got); err != nil {
+				t.Fatalf("SaveProduct failed: %v", err)
+			}
+			if _, err := products.FindByID(ctx, got.ID); !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("FindByID of a deleted product = %v, want ErrNotFound", err)
+			}
+			if deleted, err := admin.Product(ctx, got.ID); err != nil || deleted.DeletedAt == nil {
+				t.Errorf("Admin Product = %+v, %v; want it deleted", deleted, err)
+			}
+
+			testhelpers.LogTestStep(logger, "act", "Saving a variant, a retailer and its selectors")
+			v, err := admin.Variant(ctx, c.Variants[0].ID)
+			if err != nil {
+				t.Fatalf("Variant failed: %v", err)
+			}
+			v.Flavor = "Mango"
+			if err := admin.SaveVariant(ctx, *v); err != nil {
+				t.Fatalf("SaveVariant failed: %v", err)
+			}
+			if err := admin.SaveVariant(ctx, *v); !errors.Is(err, domain.ErrConflict) {
+				t.Errorf("Stale SaveVariant = %v, want ErrConflict", err)
+			}
+			l, err := admin.Listing(ctx, c.Listings[0].ID)
+			if err != nil {
+				t.Fatalf("Listing failed: %v", err)
+			}
+			ret, err := admin.Retailer(ctx, l.RetailerID)
+			if err != nil {
+				t.Fatalf("Retailer failed: %v", err)
+			}
+			ret.RequestsPerMinute = 3
+			if err := admin.SaveRetailer(ctx, *ret); err != nil {
+				t.Fatalf("SaveRetailer failed: %v", err)
+			}
+			if got, err := NewRetailerRepository(db.dialect, db.router, db.stmts).FindByID(ctx, ret.ID); err != nil || got.RequestsPerMinute != 3 {
+				t.Errorf("FindByID = %+v, %v; want the saved rate", got, err)
+			}
+			selectors := NewSelectorRepository(db.dialect, db.router, db.stmts)
+			cfg := domain.SelectorConfig{RetailerID: ret.ID, PriceSelectors: []string{".price"}, UpdatedAt: now, UpdatedBy: "admin"}
+			if err := selectors.SaveSelectors(ctx, cfg); err != nil {
+				t.Fatalf("SaveSelectors failed: %v", err)
+			}
+			if got, err := selectors.Selectors(ctx, ret.ID); err != nil || !slices.Equal(got.PriceSelectors, cfg.PriceSelectors) || got.UpdatedBy != "admin" {
+				t.Errorf("Selectors = %+v, %v; want %+v", got, err, cfg)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCatalogAdminRepository", true)
+}
+
+func TestCatalogAdminRepository_Tx(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCatalogAdminRepository_Tx", "internal/repositories/sqlstore")
+
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	c := seed.Generate(seed.Config{Products: 1, Days: 1, Seed: 5, Now: now})
+	for _, db := range testDatabases(t, logger) {
+		t.Run(db.dialect.String(), func(t *testing.T) {
+			ctx := t.Context()
+			if err := c.Insert(ctx, db.router.Writer(), db.dialect, true); err != nil {
+				t.Fatalf("Seed failed: %v", err)
+			}
+			admin := NewCatalogAdminRepository(db.dialect, db.router, db.stmts)
+			outbox := NewOutboxRepository(db.dialect, db.router, db.stmts)
+			tx := database.NewTxManager(db.router.Writer())
+			id := c.Products[0].ID
+			rename := func(ctx context.Context, name string) error {
+				p, err := admin.Product(ctx, id)
+				if err != nil {
+					return err
+				}
+				p.Name = name
+				return admin.SaveProduct(ctx, *p, domain.ProductUpdated{ProductID: id, OccurredAt: now})
+			}
+
+			testhelpers.LogTestStep(logger, "act", "Saving an edit in a unit of work that then fails")
+			failed := errors.New("later step failed")
+			err := tx.InTx(ctx, func(ctx context.Context) error {
+				if err := rename(ctx, "Rolled back"); err != nil {
+					return err
+				}
+				return failed
+			})
+
+			testhelpers.LogTestStep(logger, "assert", "Neither the edit nor its event was kept")
+			testhelpers.LogTestAssertion(logger, "error", failed, err)
+			if !errors.Is(err, failed) {
+				t.Fatalf("InTx = %v, want %v", err, failed)
+			}
+			if p, err := admin.Product(ctx, id); err != nil || p.Name == "Rolled back" || p.Version != 1 {
+				t.Errorf("Product = %+v, %v; want it unchanged", p, err)
+			}
+			if pending, err := outbox.Pending(ctx, 10); err != nil || len(pending) != 0 {
+				t.Errorf("Outbox = %v, %v; want no event", pending, err)
+			}
+
+			testhelpers.LogTestStep(logger, "act", "Saving the edit in a unit of work that succeeds")
+			if err := tx.InTx(ctx, func(ctx context.Context) error { return rename(ctx, "Committed") }); err != nil {
+				t.Fatalf("InTx failed: %v", err)
+			}
+			if pending, err := outbox.Pending(ctx, 10); err != nil || len(pending) != 1 {
+				t.Errorf("Outbox = %v, %v; want the edit's event", pending, err)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCatalogAdminRepository_Tx", true)
+}
+
+func TestRecordPrice(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestRecordPrice", "internal/repositories/sqlstore")
+
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	c := seed.Generate(seed.Config{Products: 1, Days: 1, Seed: 3, Now: now.Add(-time.Hour)})
+	for _, db := range testDatabases(t, logger) {
+		t.Run(db.dialect.String(), func(t *testing.T) {
+			ctx := t.Context()
+			if err := c.Insert(ctx, db.router.Writer(), db.dialect, true); err != nil {
+				t.Fatalf("Seed failed: %v", err)
+			}
+			repo := NewPriceIngestRepository(db.dialect, db.router)
+			admin := NewCatalogAdminRepository(db.dialect, db.router, db.stmts)
+			id := c.Listings[0].ID
+
+			testhelpers.LogTestStep(logger, "act", "Recording a new price, then an older one")
+			p, err := repo.RecordPrice(ctx, domain.PricePoint{ListingID: id, Price: 1499, Currency: "INR", InStock: true, RecordedAt: now, Source: "admin"})
+			if err != nil || p.ID == "" {
+				t.Fatalf("RecordPrice = %+v, %v; want an ID", p, err)
+			}
+			if _, err := repo.RecordPrice(ctx, domain.PricePoint{ListingID: id, Price: 999, InStock: true, RecordedAt: now.AddDate(0, 0, -1), Source: "admin"}); err != nil {
+				t.Fatalf("RecordPrice failed: %v", err)
+			}
+
+			testhelpers.LogTestStep(logger, "assert", "The listing is at the newer price")
+			l, err := admin.Listing(ctx, id)
+			testhelpers.LogTestAssertion(logger, "current price", 1499.0, l.CurrentPrice)
+			if err != nil || l.CurrentPrice != 1499 || !l.LastScrapedAt.Equal(now) {
+				t.Errorf("Listing = %+v, %v; want 1499 at %v", l, err, now)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestRecordPrice", true)
+}
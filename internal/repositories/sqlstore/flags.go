@@ -0,0 +1,97 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/whey-price-compare/internal/database"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+const (
+	flagColumns  = `name, description, enabled, percent, updated_at`
+	getFlagQuery = `
+SELECT ` + flagColumns + ` FROM feature_flags WHERE name = $1`
+	listFlagsQuery = `
+SELECT ` + flagColumns + ` FROM feature_flags ORDER BY name`
+	saveFlagQuery = `
+INSERT INTO feature_flags (` + flagColumns + `) VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (name) DO UPDATE SET description = excluded.description, enabled = excluded.enabled,
+    percent = excluded.percent, updated_at = excluded.updated_at`
+	deleteFlagQuery = `DELETE FROM feature_flags WHERE name = $1`
+)
+
+// FlagRepository implements repositories.FlagRepository on the
+// feature_flags table from migration 015. Every read goes to the primary,
+// so a flag turned off is read as off on the next refresh.
+type FlagRepository struct {
+	d     database.Dialect
+	db    *database.Router
+	stmts *database.StatementCache
+}
+
+// NewFlagRepository creates a FlagRepository for a database of dialect d.
+func NewFlagRepository(d database.Dialect, db *database.Router, stmts *database.StatementCache) *FlagRepository {
+	return &FlagRepository{d: d, db: db, stmts: stmts}
+}
+
+// Flag implements repositories.FlagRepository.
+func (r *FlagRepository) Flag(ctx context.Context, name string) (*domain.Flag, error) {
+	f, err := scanFlag(r.stmts.QueryRowContext(ctx, r.db.Writer(), r.d.Rebind(getFlagQuery), name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("flag %q: %w", name, domain.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read flag: %w", err)
+	}
+	return &f, nil
+}
+
+// Flags implements repositories.FlagRepository.
+func (r *FlagRepository) Flags(ctx context.Context) ([]domain.Flag, error) {
+	rows, err := r.stmts.QueryContext(ctx, r.db.Writer(), listFlagsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("list flags: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []domain.Flag
+	for rows.Next() {
+		f, err := scanFlag(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan flag: %w", err)
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// SaveFlag implements repositories.FlagRepository.
+func (r *FlagRepository) SaveFlag(ctx context.Context, f domain.Flag, events ...domain.Event) error {
+	return withEvents(ctx, r.db.Writer(), r.d, events, func(tx database.Querier) error {
+		if _, err := tx.ExecContext(ctx, r.d.Rebind(saveFlagQuery),
+			r.d.Args(f.Name, f.Description, f.Enabled, f.Percent, f.UpdatedAt)...); err != nil {
+			return fmt.Errorf("save flag: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeleteFlag implements repositories.FlagRepository.
+func (r *FlagRepository) DeleteFlag(ctx context.Context, name string, events ...domain.Event) error {
+	return withEvents(ctx, r.db.Writer(), r.d, events, func(tx database.Querier) error {
+		res, err := tx.ExecContext(ctx, r.d.Rebind(deleteFlagQuery), name)
+		if err != nil {
+			return fmt.Errorf("delete flag: %w", err)
+		}
+		return requireRow(res, "flag", name)
+	})
+}
+
+func scanFlag(row scanner) (domain.Flag, error) {
+	var f domain.Flag
+	err := row.Scan(&f.Name, &f.Description, &f.Enabled, &f.Percent, &f.UpdatedAt)
+	return f, err
+}
@@ -0,0 +1,59 @@
+package sqlstore
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestFlagRepository(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestFlagRepository", "internal/repositories/sqlstore")
+
+	at := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	for _, db := range testDatabases(t, logger) {
+		t.Run(db.dialect.String(), func(t *testing.T) {
+			ctx := t.Context()
+			flags := NewFlagRepository(db.dialect, db.router, db.stmts)
+
+			testhelpers.LogTestStep(logger, "act", "Saving two flags, then killing one")
+			rerank := domain.Flag{Name: "search.rerank", Description: "Blended ranking", Enabled: true, Percent: 25, UpdatedAt: at}
+			for _, f := range []domain.Flag{rerank, {Name: "chat.fuzzy-match", Enabled: true, Percent: 100, UpdatedAt: at}} {
+				if err := flags.SaveFlag(ctx, f, domain.FlagsChanged{OccurredAt: at}); err != nil {
+					t.Fatalf("SaveFlag: %v", err)
+				}
+			}
+			rerank.Enabled, rerank.UpdatedAt = false, at.Add(time.Minute)
+			if err := flags.SaveFlag(ctx, rerank); err != nil {
+				t.Fatalf("SaveFlag: %v", err)
+			}
+
+			testhelpers.LogTestStep(logger, "assert", "Flags list by name with the last save")
+			all, err := flags.Flags(ctx)
+			if err != nil {
+				t.Fatalf("Flags: %v", err)
+			}
+			testhelpers.LogTestAssertion(logger, "flags", 2, len(all))
+			if len(all) != 2 || all[0].Name != "chat.fuzzy-match" || all[1].Enabled || all[1].Percent != 25 ||
+				all[1].Description != "Blended ranking" || !all[1].UpdatedAt.Equal(at.Add(time.Minute)) {
+				t.Errorf("Flags = %+v", all)
+			}
+
+			testhelpers.LogTestStep(logger, "act", "Deleting")
+			if err := flags.DeleteFlag(ctx, "search.rerank"); err != nil {
+				t.Fatalf("DeleteFlag: %v", err)
+			}
+			if err := flags.DeleteFlag(ctx, "search.rerank"); !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("Second delete err = %v, want ErrNotFound", err)
+			}
+			if _, err := flags.Flag(ctx, "search.rerank"); !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("Deleted flag err = %v, want ErrNotFound", err)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestFlagRepository", true)
+}
@@ -0,0 +1,94 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/database"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// The integrity queries. Foreign keys keep variants from outliving their
+// product in Postgres, but SQLite only enforces them with PRAGMA
+// foreign_keys on, and soft deletes are never enforced.
+const (
+	orphanVariantsQuery = `
+SELECT %s, %s FROM product_variants v
+LEFT JOIN products p ON p.id = v.product_id
+WHERE v.deleted_at IS NULL AND (p.id IS NULL OR p.deleted_at IS NOT NULL)
+ORDER BY v.id LIMIT $1`
+	unpricedProductsQuery = `
+SELECT %s FROM products p
+WHERE p.is_active AND p.deleted_at IS NULL AND NOT EXISTS (
+    SELECT 1 FROM product_variants v
+    JOIN product_listings l ON l.product_variant_id = v.id
+    JOIN price_history h ON h.product_listing_id = l.id
+    WHERE v.product_id = p.id AND h.recorded_at >= $1)
+ORDER BY p.id LIMIT $2`
+	currencyMismatchesQuery = `
+SELECT %s, %s, %s, %s, COALESCE(h.currency, ''), COALESCE(l.currency, '')
+FROM price_history h
+JOIN product_listings l ON l.id = h.product_listing_id
+JOIN product_variants v ON v.id = l.product_variant_id
+WHERE h.recorded_at >= $1 AND COALESCE(h.currency, '') <> COALESCE(l.currency, '')
+ORDER BY l.id, h.id LIMIT $2`
+)
+
+// IntegrityRepository implements repositories.IntegrityRepository on the
+// catalog and price_history tables. Checks read from replicas: a finding a
+// replica is behind on is found on the next run.
+type IntegrityRepository struct {
+	d  database.Dialect
+	db *database.Router
+}
+
+// NewIntegrityRepository creates an IntegrityRepository for a database of
+// dialect d.
+func NewIntegrityRepository(d database.Dialect, db *database.Router) *IntegrityRepository {
+	return &IntegrityRepository{d: d, db: db}
+}
+
+// OrphanVariants implements repositories.IntegrityRepository.
+func (r *IntegrityRepository) OrphanVariants(ctx context.Context, limit int) ([]domain.IntegrityFinding, error) {
+	query := fmt.Sprintf(orphanVariantsQuery, r.d.Text("v.product_id"), r.d.Text("v.id"))
+	return r.find(ctx, domain.IntegrityOrphanVariant, query, []any{limit}, func(f *domain.IntegrityFinding) []any {
+		return []any{&f.ProductID, &f.VariantID}
+	})
+}
+
+// UnpricedProducts implements repositories.IntegrityRepository.
+func (r *IntegrityRepository) UnpricedProducts(ctx context.Context, since time.Time, limit int) ([]domain.IntegrityFinding, error) {
+	query := fmt.Sprintf(unpricedProductsQuery, r.d.Text("p.id"))
+	return r.find(ctx, domain.IntegrityUnpricedProduct, query, r.d.Args(since, limit), func(f *domain.IntegrityFinding) []any {
+		return []any{&f.ProductID}
+	})
+}
+
+// CurrencyMismatches implements repositories.IntegrityRepository.
+func (r *IntegrityRepository) CurrencyMismatches(ctx context.Context, since time.Time, limit int) ([]domain.IntegrityFinding, error) {
+	query := fmt.Sprintf(currencyMismatchesQuery, r.d.Text("v.product_id"), r.d.Text("v.id"), r.d.Text("l.id"), r.d.Text("h.id"))
+	return r.find(ctx, domain.IntegrityCurrencyMismatch, query, r.d.Args(since, limit), func(f *domain.IntegrityFinding) []any {
+		return []any{&f.ProductID, &f.VariantID, &f.ListingID, &f.PriceID, &f.Currency, &f.ListingCurrency}
+	})
+}
+
+// find runs one check's query, scanning each row into the fields dest
+// returns.
+func (r *IntegrityRepository) find(ctx context.Context, check, query string, args []any, dest func(*domain.IntegrityFinding) []any) ([]domain.IntegrityFinding, error) {
+	rows, err := r.db.Reader(ctx).QueryContext(ctx, r.d.Rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("check %s: %w", check, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []domain.IntegrityFinding
+	for rows.Next() {
+		f := domain.IntegrityFinding{Check: check}
+		if err := rows.Scan(dest(&f)...); err != nil {
+			return nil, fmt.Errorf("scan %s: %w", check, err)
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,82 @@
+package sqlstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/seed"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestIntegrityRepository(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestIntegrityRepository", "internal/repositories/sqlstore")
+
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	c := seed.Generate(seed.Config{Products: 2, Days: 2, Seed: 1, Now: now.Add(-time.Hour)})
+	kept, gone := c.Products[0].ID, c.Products[1].ID
+	var mismatched domain.PricePoint
+	for _, p := range c.Prices {
+		if p.ListingID == c.Listings[0].ID {
+			mismatched = p
+		}
+	}
+	for _, db := range testDatabases(t, logger) {
+		t.Run(db.dialect.String(), func(t *testing.T) {
+			ctx := t.Context()
+			testhelpers.LogTestStep(logger, "arrange", "A two-product catalog with one product deleted and one price in USD")
+			if err := c.Insert(ctx, db.router.Writer(), db.dialect, true); err != nil {
+				t.Fatalf("Seed failed: %v", err)
+			}
+			for query, args := range map[string][]any{
+				"UPDATE products SET deleted_at = $1 WHERE id = $2":       db.dialect.Args(now, gone),
+				"UPDATE price_history SET currency = 'USD' WHERE id = $1": {mismatched.ID},
+			} {
+				if _, err := db.router.Writer().ExecContext(ctx, db.dialect.Rebind(query), args...); err != nil {
+					t.Fatalf("Arrange failed: %v", err)
+				}
+			}
+			repo := NewIntegrityRepository(db.dialect, db.router)
+
+			testhelpers.LogTestStep(logger, "act", "Running each check")
+			orphans, err := repo.OrphanVariants(ctx, 100)
+			if err != nil {
+				t.Fatalf("OrphanVariants failed: %v", err)
+			}
+			unpriced, err := repo.UnpricedProducts(ctx, now, 100)
+			if err != nil {
+				t.Fatalf("UnpricedProducts failed: %v", err)
+			}
+			priced, err := repo.UnpricedProducts(ctx, now.AddDate(0, 0, -2), 100)
+			if err != nil {
+				t.Fatalf("UnpricedProducts failed: %v", err)
+			}
+			mismatches, err := repo.CurrencyMismatches(ctx, now.AddDate(0, 0, -3), 100)
+			if err != nil {
+				t.Fatalf("CurrencyMismatches failed: %v", err)
+			}
+
+			testhelpers.LogTestStep(logger, "assert", "The deleted product's variants, the unpriced product and the USD price are found")
+			testhelpers.LogTestAssertion(logger, "orphans", "variants of "+gone, orphans)
+			if len(orphans) == 0 {
+				t.Error("No orphan variants found")
+			}
+			for _, f := range orphans {
+				if f.Check != domain.IntegrityOrphanVariant || f.ProductID != gone {
+					t.Errorf("Orphan = %+v, want a variant of %s", f, gone)
+				}
+			}
+			if len(unpriced) != 1 || unpriced[0].ProductID != kept || len(priced) != 0 {
+				t.Errorf("Unpriced = %+v since now and %+v since two days ago, want only %s since now", unpriced, priced, kept)
+			}
+			testhelpers.LogTestAssertion(logger, "mismatched price", mismatched.ID, mismatches)
+			if len(mismatches) != 1 || mismatches[0].PriceID != mismatched.ID || mismatches[0].Currency != "USD" ||
+				mismatches[0].ListingCurrency != domain.DefaultCurrency || mismatches[0].ProductID != kept {
+				t.Errorf("Mismatches = %+v, want %s", mismatches, mismatched.ID)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestIntegrityRepository", true)
+}
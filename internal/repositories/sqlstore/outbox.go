@@ -0,0 +1,176 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/database"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+const (
+	pendingOutboxQuery = `
+SELECT id, event_type, payload, created_at, attempts, coalesce(last_error, '') FROM event_outbox
+WHERE published_at IS NULL AND failed_at IS NULL ORDER BY id LIMIT $1`
+	retryOutboxQuery = `
+UPDATE event_outbox SET attempts = attempts + 1, last_error = $2 WHERE id = $1`
+	failOutboxQuery = `
+UPDATE event_outbox SET attempts = attempts + 1, last_error = $2, failed_at = $3 WHERE id = $1`
+	deletePublishedOutboxQuery = `DELETE FROM event_outbox WHERE published_at < $1`
+)
+
+// OutboxRepository implements repositories.OutboxRepository on the
+// event_outbox table from migration 011. The repositories of this package
+// write to it through withEvents.
+type OutboxRepository struct {
+	d     database.Dialect
+	db    *database.Router
+	stmts *database.StatementCache
+}
+
+// NewOutboxRepository creates an OutboxRepository for a database of
+// dialect d.
+func NewOutboxRepository(d database.Dialect, db *database.Router, stmts *database.StatementCache) *OutboxRepository {
+	return &OutboxRepository{d: d, db: db, stmts: stmts}
+}
+
+// Pending implements repositories.OutboxRepository. It reads the primary:
+// a replica could return messages already published.
+func (r *OutboxRepository) Pending(ctx context.Context, limit int) ([]domain.OutboxMessage, error) {
+	if limit <= 0 {
+		limit = -1
+		if r.d == database.Postgres {
+			limit = 1 << 62
+		}
+	}
+	rows, err := r.stmts.QueryContext(ctx, r.db.Writer(), r.d.Rebind(pendingOutboxQuery), limit)
+	if err != nil {
+		return nil, fmt.Errorf("read outbox: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []domain.OutboxMessage
+	for rows.Next() {
+		var m domain.OutboxMessage
+		var id int64
+		var payload []byte
+		if err := rows.Scan(&id, &m.EventType, &payload, &m.CreatedAt, &m.Attempts, &m.LastError); err != nil {
+			return nil, fmt.Errorf("scan outbox message: %w", err)
+		}
+		m.ID = strconv.FormatInt(id, 10)
+		m.Payload = payload
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+// MarkPublished implements repositories.OutboxRepository.
+func (r *OutboxRepository) MarkPublished(ctx context.Context, at time.Time, ids ...string) error {
+	keys, err := outboxKeys(ids...)
+	if err != nil {
+		return err
+	}
+	for batch := range slices.Chunk(keys, r.d.MaxArgs()-1) {
+		query, args := r.d.Build().
+			Append("UPDATE event_outbox SET published_at = ? WHERE id IN ", at).
+			Values(1, batch...).
+			Query()
+		if _, err := r.db.Writer().ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("mark outbox published: %w", err)
+		}
+	}
+	return nil
+}
+
+// MarkRetry implements repositories.OutboxRepository.
+func (r *OutboxRepository) MarkRetry(ctx context.Context, id, reason string) error {
+	key, err := outboxKeys(id)
+	if err != nil {
+		return err
+	}
+	res, err := r.stmts.ExecContext(ctx, r.db.Writer(), r.d.Rebind(retryOutboxQuery), key[0], reason)
+	if err != nil {
+		return fmt.Errorf("mark outbox retry: %w", err)
+	}
+	return requireRow(res, "outbox message", id)
+}
+
+// MarkFailed implements repositories.OutboxRepository.
+func (r *OutboxRepository) MarkFailed(ctx context.Context, at time.Time, id, reason string) error {
+	key, err := outboxKeys(id)
+	if err != nil {
+		return err
+	}
+	res, err := r.stmts.ExecContext(ctx, r.db.Writer(), r.d.Rebind(failOutboxQuery), r.d.Args(key[0], reason, at)...)
+	if err != nil {
+		return fmt.Errorf("mark outbox failed: %w", err)
+	}
+	return requireRow(res, "outbox message", id)
+}
+
+// DeletePublishedBefore implements repositories.OutboxRepository.
+func (r *OutboxRepository) DeletePublishedBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	res, err := r.stmts.ExecContext(ctx, r.db.Writer(), r.d.Rebind(deletePublishedOutboxQuery), r.d.Arg(cutoff))
+	if err != nil {
+		return 0, fmt.Errorf("purge outbox: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("purge outbox: %w", err)
+	}
+	return int(n), nil
+}
+
+// outboxKeys parses message IDs, which are the table's integer keys.
+func outboxKeys(ids ...string) ([]any, error) {
+	keys := make([]any, 0, len(ids))
+	for _, id := range ids {
+		n, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("outbox message %q: %w", id, domain.ErrNotFound)
+		}
+		keys = append(keys, n)
+	}
+	return keys, nil
+}
+
+// withEvents runs change in a transaction on db and stores events in the
+// outbox, and price events in the price change log, in the same
+// transaction, so all are committed or none is. If ctx carries a unit of
+// work on db, change and the events join it.
+func withEvents(ctx context.Context, db *sql.DB, d database.Dialect, events []domain.Event, change func(tx database.Querier) error) error {
+	now := time.Now().UTC()
+	messages, err := domain.NewOutboxMessages(now, events...)
+	if err != nil {
+		return err
+	}
+	return database.InTx(ctx, db, nil, func(ctx context.Context) error {
+		tx := database.Conn(ctx, db)
+		if err := change(tx); err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+		if err := appendPriceLog(ctx, tx, d, domain.NewPriceLogEntries(now, events...)); err != nil {
+			return err
+		}
+		const columns = 3
+		args := make([]any, 0, len(messages)*columns)
+		for _, m := range messages {
+			args = append(args, m.EventType, string(m.Payload), m.CreatedAt)
+		}
+		query, args := d.Build().
+			Append("INSERT INTO event_outbox (event_type, payload, created_at) VALUES ").
+			Values(len(messages), args...).
+			Query()
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("write outbox: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,86 @@
+package sqlstore
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestOutboxRepository(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestOutboxRepository", "internal/repositories/sqlstore")
+
+	at := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	for _, db := range testDatabases(t, logger) {
+		t.Run(db.dialect.String(), func(t *testing.T) {
+			ctx := t.Context()
+			synonyms := NewSynonymRepository(db.dialect, db.router, db.stmts)
+			outbox := NewOutboxRepository(db.dialect, db.router, db.stmts)
+
+			testhelpers.LogTestStep(logger, "act", "Writing synonyms with events, and a failed write")
+			changed := domain.SynonymsChanged{OccurredAt: at}
+			on, err := synonyms.CreateSynonym(ctx, domain.Synonym{Terms: []string{"ON", "Optimum Nutrition"}, CreatedAt: at, UpdatedAt: at}, changed)
+			if err != nil {
+				t.Fatalf("CreateSynonym: %v", err)
+			}
+			if err := synonyms.DeleteSynonym(ctx, on.ID, changed); err != nil {
+				t.Fatalf("DeleteSynonym: %v", err)
+			}
+			if err := synonyms.DeleteSynonym(ctx, on.ID, changed); !errors.Is(err, domain.ErrNotFound) {
+				t.Fatalf("Second delete err = %v, want ErrNotFound", err)
+			}
+
+			testhelpers.LogTestStep(logger, "assert", "Only committed writes left events, in order")
+			pending, err := outbox.Pending(ctx, 0)
+			if err != nil {
+				t.Fatalf("Pending: %v", err)
+			}
+			testhelpers.LogTestAssertion(logger, "pending", 2, len(pending))
+			if len(pending) != 2 || pending[0].EventType != domain.EventSynonymsChanged || pending[0].ID == pending[1].ID {
+				t.Fatalf("Pending = %+v", pending)
+			}
+			if e, err := pending[0].Event(); err != nil || !e.(domain.SynonymsChanged).OccurredAt.Equal(at) {
+				t.Errorf("Event() = %+v, %v", e, err)
+			}
+
+			testhelpers.LogTestStep(logger, "act", "Retrying one message and publishing the other")
+			if err := outbox.MarkRetry(ctx, pending[0].ID, "search index down"); err != nil {
+				t.Fatalf("MarkRetry: %v", err)
+			}
+			if err := outbox.MarkPublished(ctx, at, pending[1].ID); err != nil {
+				t.Fatalf("MarkPublished: %v", err)
+			}
+			pending, err = outbox.Pending(ctx, 10)
+			if err != nil {
+				t.Fatalf("Pending: %v", err)
+			}
+			if len(pending) != 1 || pending[0].Attempts != 1 || pending[0].LastError != "search index down" {
+				t.Fatalf("Pending after retry = %+v", pending)
+			}
+
+			testhelpers.LogTestStep(logger, "act", "Failing the retried message and purging published ones")
+			if err := outbox.MarkFailed(ctx, at, pending[0].ID, "gave up"); err != nil {
+				t.Fatalf("MarkFailed: %v", err)
+			}
+			if err := outbox.MarkFailed(ctx, at, "999999", "gave up"); !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("MarkFailed unknown err = %v, want ErrNotFound", err)
+			}
+			n, err := outbox.DeletePublishedBefore(ctx, at.Add(time.Second))
+			if err != nil {
+				t.Fatalf("DeletePublishedBefore: %v", err)
+			}
+			testhelpers.LogTestAssertion(logger, "purged", 1, n)
+			if n != 1 {
+				t.Errorf("DeletePublishedBefore = %d, want 1", n)
+			}
+			if pending, err := outbox.Pending(ctx, 10); err != nil || len(pending) != 0 {
+				t.Errorf("Pending after fail = %+v, %v", pending, err)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestOutboxRepository", true)
+}
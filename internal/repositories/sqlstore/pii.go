@@ -0,0 +1,89 @@
+package sqlstore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// PIIKeySize is the length, in bytes, of the key NewPIIKey takes.
+const PIIKeySize = 32
+
+// PIIKey seals the personal data account rows hold, so a copy of the
+// database alone does not say who its users are: emails and names are
+// encrypted with AES-256-GCM, and emails are found by a keyed hash rather
+// than by their text. Both keys are derived from one secret; losing it
+// loses every address.
+type PIIKey struct {
+	aead cipher.AEAD
+	mac  []byte
+}
+
+// NewPIIKey creates a PIIKey from PIIKeySize random bytes.
+func NewPIIKey(secret []byte) (*PIIKey, error) {
+	if len(secret) != PIIKeySize {
+		return nil, fmt.Errorf("PII key is %d bytes, want %d", len(secret), PIIKeySize)
+	}
+	enc, err := hkdf.Key(sha256.New, secret, nil, "whey-price-compare pii seal", 32)
+	if err != nil {
+		return nil, err
+	}
+	mac, err := hkdf.Key(sha256.New, secret, nil, "whey-price-compare pii email hash", 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(enc)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &PIIKey{aead: aead, mac: mac}, nil
+}
+
+// ParsePIIKey creates a PIIKey from its standard base64 encoding, the form
+// PII_ENCRYPTION_KEY holds it in.
+func ParsePIIKey(encoded string) (*PIIKey, error) {
+	secret, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("PII key is not base64: %w", err)
+	}
+	return NewPIIKey(secret)
+}
+
+// emailHash returns the column users are looked up by email with.
+func (k *PIIKey) emailHash(email string) string {
+	h := hmac.New(sha256.New, k.mac)
+	h.Write([]byte(email))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// seal encrypts plain for the column named by field, which is bound into
+// the ciphertext so a value copied to another column does not open.
+func (k *PIIKey) seal(field, plain string) []byte {
+	nonce := make([]byte, k.aead.NonceSize(), k.aead.NonceSize()+len(plain)+k.aead.Overhead())
+	_, _ = rand.Read(nonce)
+	return k.aead.Seal(nonce, nonce, []byte(plain), []byte(field))
+}
+
+// open decrypts what seal returned for field.
+func (k *PIIKey) open(field string, sealed []byte) (string, error) {
+	n := k.aead.NonceSize()
+	if len(sealed) < n {
+		return "", errors.New("sealed " + field + " is truncated")
+	}
+	plain, err := k.aead.Open(nil, sealed[:n], sealed[n:], []byte(field))
+	if err != nil {
+		return "", fmt.Errorf("open %s: %w", field, err)
+	}
+	return string(plain), nil
+}
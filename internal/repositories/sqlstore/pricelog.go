@@ -0,0 +1,203 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/database"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+const (
+	priceLogColumns = `seq, event_type, product_id, variant_id, listing_id, retailer_id,
+old_price, new_price, currency, in_stock, occurred_at, recorded_at`
+	readPriceLogQuery = `
+SELECT ` + priceLogColumns + ` FROM price_change_log
+WHERE seq > $1 ORDER BY seq LIMIT $2`
+	readProductPriceLogQuery = `
+SELECT ` + priceLogColumns + ` FROM price_change_log
+WHERE product_id = $1 AND seq > $2 ORDER BY seq LIMIT $3`
+	headPriceLogQuery         = `SELECT coalesce(max(seq), 0) FROM price_change_log`
+	offsetBeforePriceLogQuery = `SELECT coalesce(max(seq), 0) FROM price_change_log WHERE recorded_at < $1`
+	cursorQuery               = `SELECT consumer, position, updated_at FROM price_log_cursors WHERE consumer = $1`
+	cursorsQuery              = `SELECT consumer, position, updated_at FROM price_log_cursors ORDER BY consumer`
+	moveCursorQuery           = `
+UPDATE price_log_cursors SET position = $3, updated_at = $4 WHERE consumer = $1 AND position = $2`
+	// A consumer's first commit creates its row, unless a concurrent first
+	// commit already has and moved it on.
+	startCursorQuery = `
+INSERT INTO price_log_cursors (consumer, position, updated_at) VALUES ($1, $2, $3)
+ON CONFLICT (consumer) DO UPDATE SET position = excluded.position, updated_at = excluded.updated_at
+WHERE price_log_cursors.position = 0`
+	// priceLogLock serialises appends on Postgres, where offsets come from
+	// a sequence: without it a transaction could commit offset 6 while one
+	// holding 5 is still open, and a consumer reading 6 and committing
+	// would never see 5. SQLite already runs one writer at a time.
+	priceLogLock = `SELECT pg_advisory_xact_lock(7341209)`
+)
+
+// PriceLogRepository implements repositories.PriceLogRepository on the
+// price_change_log and price_log_cursors tables from migration 014. The
+// repositories of this package append to the log through withEvents.
+type PriceLogRepository struct {
+	d     database.Dialect
+	db    *database.Router
+	stmts *database.StatementCache
+}
+
+// NewPriceLogRepository creates a PriceLogRepository for a database of
+// dialect d.
+func NewPriceLogRepository(d database.Dialect, db *database.Router, stmts *database.StatementCache) *PriceLogRepository {
+	return &PriceLogRepository{d: d, db: db, stmts: stmts}
+}
+
+// Read implements repositories.PriceLogRepository. It may read a replica:
+// the log is only appended to, in offset order, so a replica behind the
+// primary returns fewer entries but never skips one.
+func (r *PriceLogRepository) Read(ctx context.Context, filter repositories.PriceLogFilter) ([]domain.PriceLogEntry, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1
+		if r.d == database.Postgres {
+			limit = 1 << 62
+		}
+	}
+	var rows *sql.Rows
+	var err error
+	if filter.ProductID != "" {
+		rows, err = r.stmts.QueryContext(ctx, r.db.Reader(ctx), r.d.Rebind(readProductPriceLogQuery), filter.ProductID, filter.After, limit)
+	} else {
+		rows, err = r.stmts.QueryContext(ctx, r.db.Reader(ctx), r.d.Rebind(readPriceLogQuery), filter.After, limit)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read price log: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []domain.PriceLogEntry
+	for rows.Next() {
+		var e domain.PriceLogEntry
+		var oldPrice sql.NullFloat64
+		if err := rows.Scan(&e.Offset, &e.EventType, &e.ProductID, &e.VariantID, &e.ListingID, &e.RetailerID,
+			&oldPrice, &e.NewPrice, &e.Currency, &e.InStock, &e.OccurredAt, &e.RecordedAt); err != nil {
+			return nil, fmt.Errorf("scan price log entry: %w", err)
+		}
+		e.OldPrice = oldPrice.Float64
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// Head implements repositories.PriceLogRepository.
+func (r *PriceLogRepository) Head(ctx context.Context) (int64, error) {
+	var head int64
+	if err := r.stmts.QueryRowContext(ctx, r.db.Writer(), r.d.Rebind(headPriceLogQuery)).Scan(&head); err != nil {
+		return 0, fmt.Errorf("read price log head: %w", err)
+	}
+	return head, nil
+}
+
+// OffsetBefore implements repositories.PriceLogRepository.
+func (r *PriceLogRepository) OffsetBefore(ctx context.Context, t time.Time) (int64, error) {
+	var offset int64
+	if err := r.stmts.QueryRowContext(ctx, r.db.Writer(), r.d.Rebind(offsetBeforePriceLogQuery), r.d.Arg(t)).Scan(&offset); err != nil {
+		return 0, fmt.Errorf("find price log offset: %w", err)
+	}
+	return offset, nil
+}
+
+// Cursor implements repositories.PriceLogRepository.
+func (r *PriceLogRepository) Cursor(ctx context.Context, consumer string) (domain.PriceLogCursor, error) {
+	c, err := scanCursor(r.stmts.QueryRowContext(ctx, r.db.Writer(), r.d.Rebind(cursorQuery), consumer))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.PriceLogCursor{Consumer: consumer}, nil
+	}
+	if err != nil {
+		return domain.PriceLogCursor{}, fmt.Errorf("read price log cursor %q: %w", consumer, err)
+	}
+	return c, nil
+}
+
+// Cursors implements repositories.PriceLogRepository.
+func (r *PriceLogRepository) Cursors(ctx context.Context) ([]domain.PriceLogCursor, error) {
+	rows, err := r.stmts.QueryContext(ctx, r.db.Writer(), r.d.Rebind(cursorsQuery))
+	if err != nil {
+		return nil, fmt.Errorf("read price log cursors: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []domain.PriceLogCursor
+	for rows.Next() {
+		c, err := scanCursor(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan price log cursor: %w", err)
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// Commit implements repositories.PriceLogRepository.
+func (r *PriceLogRepository) Commit(ctx context.Context, consumer string, from, to int64, at time.Time) error {
+	query, args := moveCursorQuery, r.d.Args(consumer, from, to, at)
+	if from == 0 {
+		query, args = startCursorQuery, r.d.Args(consumer, to, at)
+	}
+	res, err := r.stmts.ExecContext(ctx, r.db.Writer(), r.d.Rebind(query), args...)
+	if err != nil {
+		return fmt.Errorf("commit price log cursor %q: %w", consumer, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("commit price log cursor %q: %w", consumer, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("price log consumer %q is not at %d: %w", consumer, from, domain.ErrConflict)
+	}
+	return nil
+}
+
+func scanCursor(row scanner) (domain.PriceLogCursor, error) {
+	var c domain.PriceLogCursor
+	err := row.Scan(&c.Consumer, &c.Offset, &c.UpdatedAt)
+	return c, err
+}
+
+// appendPriceLog writes entries to the price change log on tx, which is
+// the transaction of the change they record.
+func appendPriceLog(ctx context.Context, tx database.Querier, d database.Dialect, entries []domain.PriceLogEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if d == database.Postgres {
+		if _, err := tx.ExecContext(ctx, priceLogLock); err != nil {
+			return fmt.Errorf("lock price log: %w", err)
+		}
+	}
+	const columns = 11
+	for batch := range slices.Chunk(entries, d.MaxArgs()/columns) {
+		args := make([]any, 0, len(batch)*columns)
+		for _, e := range batch {
+			var oldPrice any
+			if e.OldPrice > 0 {
+				oldPrice = e.OldPrice
+			}
+			args = append(args, e.EventType, e.ProductID, e.VariantID, e.ListingID, e.RetailerID,
+				oldPrice, e.NewPrice, e.Currency, e.InStock, e.OccurredAt, e.RecordedAt)
+		}
+		query, args := d.Build().
+			Append(`INSERT INTO price_change_log (event_type, product_id, variant_id, listing_id, retailer_id,
+old_price, new_price, currency, in_stock, occurred_at, recorded_at) VALUES `).
+			Values(len(batch), args...).
+			Query()
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("write price log: %w", err)
+		}
+	}
+	return nil
+}
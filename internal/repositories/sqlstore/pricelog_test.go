@@ -0,0 +1,89 @@
+package sqlstore
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestPriceLogRepository(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPriceLogRepository", "internal/repositories/sqlstore")
+
+	at := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	for _, db := range testDatabases(t, logger) {
+		t.Run(db.dialect.String(), func(t *testing.T) {
+			ctx := t.Context()
+			ingest := NewPriceIngestRepository(db.dialect, db.router)
+			log := NewPriceLogRepository(db.dialect, db.router, db.stmts)
+
+			testhelpers.LogTestStep(logger, "act", "Writing price changes for two products alongside a product edit")
+			drop := domain.NewPriceEvent(domain.PriceChange{ProductID: "prod_on_gsw", VariantID: "var_on_gsw_2lb", ListingID: "lst_amazon",
+				RetailerID: "amazon", OldPrice: 3299, NewPrice: 3199, Currency: "INR", InStock: true, OccurredAt: at})
+			first := domain.NewPriceEvent(domain.PriceChange{ProductID: "prod_mb_biozyme", VariantID: "var_mb_1kg", ListingID: "lst_hk",
+				RetailerID: "healthkart", NewPrice: 2499, Currency: "INR", OccurredAt: at})
+			if err := ingest.IngestPrices(ctx, nil, drop, domain.ProductUpdated{ProductID: "prod_on_gsw"}, first); err != nil {
+				t.Fatalf("IngestPrices: %v", err)
+			}
+
+			testhelpers.LogTestStep(logger, "assert", "Only the price events are logged, in order, whole")
+			entries, err := log.Read(ctx, repositories.PriceLogFilter{})
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+			testhelpers.LogTestAssertion(logger, "entries", 2, len(entries))
+			if len(entries) != 2 || entries[1].Offset <= entries[0].Offset {
+				t.Fatalf("Read = %+v", entries)
+			}
+			e := entries[0]
+			if e.EventType != domain.EventPriceDropped || e.ListingID != "lst_amazon" || e.OldPrice != 3299 || e.NewPrice != 3199 ||
+				!e.InStock || !e.OccurredAt.Equal(at) || entries[1].OldPrice != 0 {
+				t.Errorf("Entries = %+v", entries)
+			}
+			byProduct, err := log.Read(ctx, repositories.PriceLogFilter{ProductID: "prod_mb_biozyme"})
+			if err != nil || len(byProduct) != 1 || byProduct[0].Offset != entries[1].Offset {
+				t.Errorf("Read product = %+v, %v", byProduct, err)
+			}
+			head, err := log.Head(ctx)
+			if err != nil || head != entries[1].Offset {
+				t.Errorf("Head = %d, %v, want %d", head, err, entries[1].Offset)
+			}
+			before, err := log.OffsetBefore(ctx, e.RecordedAt.Add(-time.Hour))
+			if err != nil || before >= e.Offset {
+				t.Errorf("OffsetBefore = %d, %v, want before %d", before, err, e.Offset)
+			}
+
+			testhelpers.LogTestStep(logger, "act", "Committing a consumer's progress, then committing from a stale offset")
+			if err := log.Commit(ctx, "alerts", 0, e.Offset, at); err != nil {
+				t.Fatalf("Commit: %v", err)
+			}
+			if err := log.Commit(ctx, "alerts", e.Offset, head, at); err != nil {
+				t.Fatalf("Commit: %v", err)
+			}
+			stale := log.Commit(ctx, "alerts", e.Offset, 0, at)
+			startedTwice := log.Commit(ctx, "alerts", 0, 0, at)
+
+			testhelpers.LogTestStep(logger, "assert", "Stale commits conflict and leave the cursor alone")
+			if !errors.Is(stale, domain.ErrConflict) || !errors.Is(startedTwice, domain.ErrConflict) {
+				t.Errorf("Stale commits = %v, %v, want ErrConflict", stale, startedTwice)
+			}
+			cursor, err := log.Cursor(ctx, "alerts")
+			testhelpers.LogTestAssertion(logger, "offset", head, cursor.Offset)
+			if err != nil || cursor.Offset != head {
+				t.Errorf("Cursor = %+v, %v", cursor, err)
+			}
+			if c, err := log.Cursor(ctx, "analytics"); err != nil || c.Offset != 0 {
+				t.Errorf("Unknown cursor = %+v, %v", c, err)
+			}
+			if cursors, err := log.Cursors(ctx); err != nil || len(cursors) != 1 || cursors[0].Consumer != "alerts" {
+				t.Errorf("Cursors = %+v, %v", cursors, err)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPriceLogRepository", true)
+}
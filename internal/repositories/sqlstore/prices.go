@@ -0,0 +1,148 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"github.com/yourusername/whey-price-compare/internal/database"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// refreshListingsQuery moves listings to their newest observation. It is
+// completed with the time function and the listing IDs.
+const refreshListingsQuery = `
+UPDATE product_listings SET
+    current_price = (SELECT h.price FROM price_history h
+        WHERE h.product_listing_id = product_listings.id ORDER BY h.recorded_at DESC LIMIT 1),
+    is_available = (SELECT h.in_stock FROM price_history h
+        WHERE h.product_listing_id = product_listings.id ORDER BY h.recorded_at DESC LIMIT 1),
+    last_scraped_at = (SELECT max(h.recorded_at) FROM price_history h
+        WHERE h.product_listing_id = product_listings.id),
+    updated_at = %s
+WHERE id IN (`
+
+// PriceIngestRepository implements repositories.PriceIngester on the
+// product_listings and price_history tables. A scrape's prices are written
+// as multi-row inserts, as many rows a statement as the database binds,
+// and each listing they touch is then brought up to date in one update per
+// batch of listings, not one per price.
+type PriceIngestRepository struct {
+	d  database.Dialect
+	db *database.Router
+}
+
+// NewPriceIngestRepository creates a PriceIngestRepository for a database
+// of dialect d.
+func NewPriceIngestRepository(d database.Dialect, db *database.Router) *PriceIngestRepository {
+	return &PriceIngestRepository{d: d, db: db}
+}
+
+// Listings implements repositories.PriceIngester. It reads the primary, so
+// that in a unit of work it sees what the unit wrote.
+func (r *PriceIngestRepository) Listings(ctx context.Context, ids []string) (map[string]repositories.IngestListing, error) {
+	db := r.db.Writer()
+	out := make(map[string]repositories.IngestListing, len(ids))
+	for batch := range slices.Chunk(ids, r.d.MaxArgs()) {
+		b := r.d.Build().Append("SELECT " + r.d.Text("l.id") + ", " + r.d.Text("l.product_variant_id") + ", " +
+			r.d.Text("l.retailer_id") + ", " + r.d.Text("v.product_id") + ", " +
+			"l.current_price, l.currency, l.is_available, l.last_scraped_at, l.is_active " +
+			"FROM product_listings l JOIN product_variants v ON v.id = l.product_variant_id WHERE l.id IN (")
+		for i, id := range batch {
+			if i > 0 {
+				b.Append(", ")
+			}
+			b.Append("?", id)
+		}
+		query, args := b.Append(")").Query()
+		if err := r.scanListings(ctx, database.Conn(ctx, db), query, args, out); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (r *PriceIngestRepository) scanListings(ctx context.Context, db database.Querier, query string, args []any, out map[string]repositories.IngestListing) error {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("read listings: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var (
+			l         repositories.IngestListing
+			price     sql.NullFloat64
+			currency  sql.NullString
+			available sql.NullBool
+			scraped   sql.NullTime
+			active    sql.NullBool
+		)
+		if err := rows.Scan(&l.ID, &l.VariantID, &l.RetailerID, &l.ProductID, &price, &currency, &available, &scraped, &active); err != nil {
+			return fmt.Errorf("scan listing: %w", err)
+		}
+		l.CurrentPrice, l.Currency = price.Float64, currency.String
+		// Both columns default to true.
+		l.InStock, l.IsActive = !available.Valid || available.Bool, !active.Valid || active.Bool
+		if scraped.Valid {
+			l.LastScrapedAt = scraped.Time
+		}
+		out[l.ID] = l
+	}
+	return rows.Err()
+}
+
+// IngestPrices implements repositories.PriceIngester.
+func (r *PriceIngestRepository) IngestPrices(ctx context.Context, points []domain.PricePoint, events ...domain.Event) error {
+	if len(points) == 0 && len(events) == 0 {
+		return nil
+	}
+	err := withEvents(ctx, r.db.Writer(), r.d, events, func(tx database.Querier) error {
+		const columns = 7
+		for batch := range slices.Chunk(points, r.d.MaxArgs()/columns) {
+			args := make([]any, 0, len(batch)*columns)
+			for _, p := range batch {
+				var previous any
+				if p.PreviousPrice > 0 {
+					previous = p.PreviousPrice
+				}
+				args = append(args, p.ListingID, p.Price, previous, p.Currency, p.RecordedAt, p.Source, p.InStock)
+			}
+			query, args := r.d.Build().
+				Append("INSERT INTO price_history (product_listing_id, price, previous_price, currency, recorded_at, source, in_stock) VALUES ").
+				Values(len(batch), args...).
+				Query()
+			if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+				return fmt.Errorf("insert prices: %w", err)
+			}
+		}
+
+		var listings []string
+		seen := make(map[string]bool)
+		for _, p := range points {
+			if !seen[p.ListingID] {
+				seen[p.ListingID] = true
+				listings = append(listings, p.ListingID)
+			}
+		}
+		for batch := range slices.Chunk(listings, r.d.MaxArgs()) {
+			b := r.d.Build().Append(fmt.Sprintf(refreshListingsQuery, r.d.Now()))
+			for i, id := range batch {
+				if i > 0 {
+					b.Append(", ")
+				}
+				b.Append("?", id)
+			}
+			query, args := b.Append(")").Query()
+			if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+				return fmt.Errorf("update listings: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ingest prices: %w", err)
+	}
+	return nil
+}
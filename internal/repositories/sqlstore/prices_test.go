@@ -0,0 +1,146 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/database"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/seed"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestPriceIngestRepository(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPriceIngestRepository", "internal/repositories/sqlstore")
+
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	c := seed.Generate(seed.Config{Products: 1, Days: 2, Seed: 1, Now: now.Add(-time.Hour)})
+	for _, db := range testDatabases(t, logger) {
+		t.Run(db.dialect.String(), func(t *testing.T) {
+			ctx := t.Context()
+			testhelpers.LogTestStep(logger, "arrange", "A one-product catalog with two days of prices")
+			if err := c.Insert(ctx, db.router.Writer(), db.dialect, true); err != nil {
+				t.Fatalf("Seed failed: %v", err)
+			}
+			repo := NewPriceIngestRepository(db.dialect, db.router)
+			ids := []string{c.Listings[0].ID, c.Listings[1].ID, "00000000-0000-0000-0000-000000000000"}
+			listings, err := repo.Listings(ctx, ids)
+			if err != nil {
+				t.Fatalf("Listings failed: %v", err)
+			}
+			first := listings[c.Listings[0].ID]
+			if len(listings) != 2 || first.ProductID != c.Products[0].ID || first.CurrentPrice != c.Listings[0].CurrentPrice {
+				t.Fatalf("Listings = %+v", listings)
+			}
+
+			testhelpers.LogTestStep(logger, "act", "Ingesting a new price for the first listing and an old one for the second")
+			points := []domain.PricePoint{
+				{ListingID: c.Listings[0].ID, Price: 1999, PreviousPrice: first.CurrentPrice, Currency: "INR", InStock: false, RecordedAt: now, Source: "scraper"},
+				{ListingID: c.Listings[1].ID, Price: 2999, Currency: "INR", InStock: true, RecordedAt: now.AddDate(0, 0, -30), Source: "scraper"},
+			}
+			change := domain.NewPriceEvent(domain.PriceChange{ListingID: c.Listings[0].ID, OldPrice: first.CurrentPrice, NewPrice: 1999, OccurredAt: now})
+			if err := repo.IngestPrices(ctx, points, change); err != nil {
+				t.Fatalf("IngestPrices failed: %v", err)
+			}
+
+			testhelpers.LogTestStep(logger, "assert", "The newer price is current; the older one only joins history")
+			listings, err = repo.Listings(ctx, ids)
+			if err != nil {
+				t.Fatalf("Listings failed: %v", err)
+			}
+			got, second := listings[c.Listings[0].ID], listings[c.Listings[1].ID]
+			testhelpers.LogTestAssertion(logger, "current price", 1999.0, got.CurrentPrice)
+			if got.CurrentPrice != 1999 || got.InStock || !got.LastScrapedAt.Equal(now) {
+				t.Errorf("First listing = %+v, want 1999 out of stock at %v", got.Listing, now)
+			}
+			if second.CurrentPrice != c.Listings[1].CurrentPrice || !second.LastScrapedAt.Equal(c.Listings[1].LastScrapedAt) {
+				t.Errorf("Second listing = %+v, want it unchanged", second.Listing)
+			}
+			pending, err := NewOutboxRepository(db.dialect, db.router, db.stmts).Pending(ctx, 10)
+			if err != nil || len(pending) != 1 {
+				t.Errorf("Outbox = %v, %v; want the change event", pending, err)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPriceIngestRepository", true)
+}
+
+func TestCatalogReads_Replica(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCatalogReads_Replica", "internal/repositories/sqlstore")
+
+	ctx := t.Context()
+	open := func(name string) *sql.DB {
+		cfg, err := database.ParseURL(filepath.Join(t.TempDir(), name), database.DefaultPoolConfig())
+		if err != nil {
+			t.Fatalf("ParseURL failed: %v", err)
+		}
+		db, err := database.Open(cfg.Dialect.Driver, cfg)
+		if err != nil {
+			t.Fatalf("Open %s failed: %v", name, err)
+		}
+		t.Cleanup(func() { _ = db.Close() })
+		prepareSchema(t, database.SQLite, db, logger)
+		return db
+	}
+	primary, replica := open("primary.db"), open("replica.db")
+
+	testhelpers.LogTestStep(logger, "arrange", "A catalog only the replica holds, and the replica caught up")
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	c := seed.Generate(seed.Config{Products: 1, Days: 3, Seed: 2, Now: now})
+	if err := c.Insert(ctx, replica, database.SQLite, true); err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+	cfg := database.DefaultRouterConfig()
+	cfg.Probe = func(context.Context, *sql.DB) (time.Duration, error) { return 0, nil }
+	router := database.NewRouter(cfg, primary, []*sql.DB{replica}, logger)
+	if err := router.CheckReplicas(ctx); err != nil {
+		t.Fatalf("CheckReplicas failed: %v", err)
+	}
+	stmts := database.NewStatementCache(database.DefaultPoolConfig(), 0)
+	t.Cleanup(func() { _ = stmts.Close() })
+	products := NewProductRepository(database.SQLite, router, stmts)
+	listings := NewListingRepository(database.SQLite, router, stmts)
+	prices := NewPriceRepository(database.SQLite, router, stmts)
+	productID, listingID := c.Products[0].ID, c.Listings[0].ID
+
+	testhelpers.LogTestStep(logger, "act", "Reading what a comparison and a price chart read")
+	found, err := products.FindByIDs(ctx, []string{productID})
+	if err != nil {
+		t.Fatalf("FindByIDs failed: %v", err)
+	}
+	byProduct, err := listings.ByProducts(ctx, []string{productID})
+	if err != nil {
+		t.Fatalf("ByProducts failed: %v", err)
+	}
+	history, err := prices.History(ctx, []string{listingID}, now.AddDate(0, 0, -7))
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	daily, err := prices.DailyHistory(ctx, []string{listingID}, now.AddDate(0, 0, -7))
+	if err != nil {
+		t.Fatalf("DailyHistory failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The replica answered; reads pinned to the primary find nothing")
+	testhelpers.LogTestAssertion(logger, "history points", "some", len(history))
+	if len(found) != 1 || len(byProduct[productID]) == 0 || len(history) == 0 || len(daily) == 0 {
+		t.Errorf("Replica reads = %d products, %d listings, %d points, %d days; want the seeded catalog",
+			len(found), len(byProduct[productID]), len(history), len(daily))
+	}
+	pinned := database.WithPrimary(ctx)
+	if got, err := prices.History(pinned, []string{listingID}, now.AddDate(0, 0, -7)); err != nil || len(got) != 0 {
+		t.Errorf("History on the primary = %d points, %v; want none", len(got), err)
+	}
+	if _, err := products.FindByID(pinned, productID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("FindByID on the primary = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCatalogReads_Replica", true)
+}
@@ -0,0 +1,140 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/database"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+const (
+	searchesSinceQuery = `
+SELECT id, query, page, results, fuzzy, created_at FROM search_logs
+WHERE created_at >= $1 ORDER BY created_at, id`
+	searchClicksSinceQuery = `
+SELECT search_id, product_id, position, created_at FROM search_clicks
+WHERE created_at >= $1 ORDER BY created_at, search_id, position`
+	deleteSearchLogsQuery   = `DELETE FROM search_logs WHERE created_at < $1`
+	deleteSearchClicksQuery = `DELETE FROM search_clicks WHERE created_at < $1`
+)
+
+// SearchLogRepository implements repositories.SearchLogRepository on the
+// search_logs and search_clicks tables from migration 006. Reports read
+// from replicas.
+type SearchLogRepository struct {
+	d     database.Dialect
+	db    *database.Router
+	stmts *database.StatementCache
+}
+
+// NewSearchLogRepository creates a SearchLogRepository for a database of
+// dialect d.
+func NewSearchLogRepository(d database.Dialect, db *database.Router, stmts *database.StatementCache) *SearchLogRepository {
+	return &SearchLogRepository{d: d, db: db, stmts: stmts}
+}
+
+// RecordSearches implements repositories.SearchLogRepository. A search
+// already recorded, as when a batch is retried, is kept as it was.
+func (r *SearchLogRepository) RecordSearches(ctx context.Context, logs []domain.SearchLog) error {
+	const columns = 6
+	for batch := range slices.Chunk(logs, r.d.MaxArgs()/columns) {
+		args := make([]any, 0, len(batch)*columns)
+		for _, l := range batch {
+			args = append(args, l.ID, l.Query, l.Page, l.Results, l.Fuzzy, l.CreatedAt)
+		}
+		query, args := r.d.Build().
+			Append("INSERT INTO search_logs (id, query, page, results, fuzzy, created_at) VALUES ").
+			Values(len(batch), args...).
+			Append(" ON CONFLICT (id) DO NOTHING").
+			Query()
+		if _, err := r.db.Writer().ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("record searches: %w", err)
+		}
+	}
+	return nil
+}
+
+// RecordSearchClicks implements repositories.SearchLogRepository.
+func (r *SearchLogRepository) RecordSearchClicks(ctx context.Context, clicks []domain.SearchClick) error {
+	const columns = 4
+	for batch := range slices.Chunk(clicks, r.d.MaxArgs()/columns) {
+		args := make([]any, 0, len(batch)*columns)
+		for _, c := range batch {
+			args = append(args, c.SearchID, c.ProductID, c.Position, c.CreatedAt)
+		}
+		query, args := r.d.Build().
+			Append("INSERT INTO search_clicks (search_id, product_id, position, created_at) VALUES ").
+			Values(len(batch), args...).
+			Query()
+		if _, err := r.db.Writer().ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("record search clicks: %w", err)
+		}
+	}
+	return nil
+}
+
+// SearchesSince implements repositories.SearchLogRepository.
+func (r *SearchLogRepository) SearchesSince(ctx context.Context, since time.Time) ([]domain.SearchLog, error) {
+	rows, err := r.stmts.QueryContext(ctx, r.db.Reader(ctx), r.d.Rebind(searchesSinceQuery), r.d.Arg(since))
+	if err != nil {
+		return nil, fmt.Errorf("read searches: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []domain.SearchLog
+	for rows.Next() {
+		var l domain.SearchLog
+		if err := rows.Scan(&l.ID, &l.Query, &l.Page, &l.Results, &l.Fuzzy, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan search: %w", err)
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+// SearchClicksSince implements repositories.SearchLogRepository.
+func (r *SearchLogRepository) SearchClicksSince(ctx context.Context, since time.Time) ([]domain.SearchClick, error) {
+	rows, err := r.stmts.QueryContext(ctx, r.db.Reader(ctx), r.d.Rebind(searchClicksSinceQuery), r.d.Arg(since))
+	if err != nil {
+		return nil, fmt.Errorf("read search clicks: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []domain.SearchClick
+	for rows.Next() {
+		var c domain.SearchClick
+		if err := rows.Scan(&c.SearchID, &c.ProductID, &c.Position, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan search click: %w", err)
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// DeleteSearchLogsBefore implements repositories.SearchLogRepository,
+// removing searches and clicks together.
+func (r *SearchLogRepository) DeleteSearchLogsBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	db := r.db.Writer()
+	var total int64
+	err := database.InTx(ctx, db, nil, func(ctx context.Context) error {
+		for _, query := range []string{deleteSearchLogsQuery, deleteSearchClicksQuery} {
+			res, err := database.Conn(ctx, db).ExecContext(ctx, r.d.Rebind(query), r.d.Arg(cutoff))
+			if err != nil {
+				return err
+			}
+			n, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			total += n
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("purge search logs: %w", err)
+	}
+	return int(total), nil
+}
@@ -0,0 +1,78 @@
+package sqlstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestSearchLogRepository(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSearchLogRepository", "internal/repositories/sqlstore")
+
+	// Times in another zone check that SQLite, storing them as text,
+	// still compares them in time order.
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	old := time.Date(2026, 7, 1, 10, 0, 0, 0, ist)
+	recent := time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC)
+	for _, db := range testDatabases(t, logger) {
+		t.Run(db.dialect.String(), func(t *testing.T) {
+			ctx := t.Context()
+			logs := NewSearchLogRepository(db.dialect, db.router, db.stmts)
+
+			testhelpers.LogTestStep(logger, "arrange", "An old and a recent search, each clicked")
+			searches := []domain.SearchLog{
+				{ID: "srch_old", Query: "whey isolate", Page: 1, Results: 12, CreatedAt: old},
+				{ID: "srch_new", Query: "creatine", Page: 1, Results: 0, Fuzzy: true, CreatedAt: recent},
+			}
+			if err := logs.RecordSearches(ctx, searches); err != nil {
+				t.Fatalf("RecordSearches: %v", err)
+			}
+			if err := logs.RecordSearches(ctx, searches[1:]); err != nil {
+				t.Fatalf("RecordSearches retried: %v", err)
+			}
+			clicks := []domain.SearchClick{
+				{SearchID: "srch_old", ProductID: "prod_on_gold", Position: 1, CreatedAt: old},
+				{SearchID: "srch_new", ProductID: "prod_mb_creatine", Position: 3, CreatedAt: recent},
+			}
+			if err := logs.RecordSearchClicks(ctx, clicks); err != nil {
+				t.Fatalf("RecordSearchClicks: %v", err)
+			}
+
+			testhelpers.LogTestStep(logger, "assert", "Reads since a time see only the recent rows")
+			since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+			got, err := logs.SearchesSince(ctx, since)
+			if err != nil {
+				t.Fatalf("SearchesSince: %v", err)
+			}
+			testhelpers.LogTestAssertion(logger, "recent searches", 1, len(got))
+			if len(got) != 1 || got[0].ID != "srch_new" || !got[0].Fuzzy || got[0].Results != 0 || !got[0].CreatedAt.Equal(recent) {
+				t.Errorf("SearchesSince = %+v", got)
+			}
+			gotClicks, err := logs.SearchClicksSince(ctx, since)
+			if err != nil {
+				t.Fatalf("SearchClicksSince: %v", err)
+			}
+			if len(gotClicks) != 1 || gotClicks[0].Position != 3 {
+				t.Errorf("SearchClicksSince = %+v", gotClicks)
+			}
+
+			testhelpers.LogTestStep(logger, "act", "Purging before the cutoff")
+			n, err := logs.DeleteSearchLogsBefore(ctx, since)
+			if err != nil {
+				t.Fatalf("DeleteSearchLogsBefore: %v", err)
+			}
+			testhelpers.LogTestAssertion(logger, "purged", 2, n)
+			if n != 2 {
+				t.Errorf("Purged %d rows, want the old search and click", n)
+			}
+			if all, _ := logs.SearchesSince(ctx, time.Time{}); len(all) != 1 {
+				t.Errorf("After purge %d searches left, want 1", len(all))
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSearchLogRepository", true)
+}
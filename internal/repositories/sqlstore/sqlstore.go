@@ -0,0 +1,153 @@
+// Package sqlstore implements, through database/sql, the repository
+// interfaces that Postgres in production and SQLite in development both
+// hold. Queries are written once in the SQL the two share and rewritten
+// per database.Dialect where they differ. Repositories only Postgres can
+// back, such as full-text search, are in package postgres.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/whey-price-compare/internal/database"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// scanner is a *sql.Row or *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// requireRow turns an update or delete that touched no row into
+// domain.ErrNotFound.
+func requireRow(res sql.Result, kind, id string) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s %q: %w", kind, id, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s %q: %w", kind, id, domain.ErrNotFound)
+	}
+	return nil
+}
+
+// validKey reports whether id can be a key of a table in d. Postgres keys
+// are UUIDs, and comparing one with other text is an error rather than a
+// miss, so such IDs are turned away before they reach a query.
+func validKey(d database.Dialect, id string) bool {
+	return d == database.SQLite || uuid.Validate(id) == nil
+}
+
+// validKeys returns the ids that are validKeys, once each.
+func validKeys(d database.Dialect, ids []string) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if validKey(d, id) && !slices.Contains(out, id) {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// noLimit is the LIMIT that returns every row: SQLite takes a negative
+// limit as none, Postgres needs a number.
+func noLimit(d database.Dialect) int {
+	if d == database.Postgres {
+		return 1 << 62
+	}
+	return -1
+}
+
+// keyIn returns the condition that col is one of the keys bound as
+// argument n by keyList. The keys are one argument, however many there
+// are, so the statement has one shape and is prepared once.
+func keyIn(d database.Dialect, col string, n int) string {
+	if d == database.Postgres {
+		return fmt.Sprintf("%s = ANY(string_to_array($%d, ',')::uuid[])", col, n)
+	}
+	return fmt.Sprintf("%s IN (SELECT value FROM json_each($%d))", col, n)
+}
+
+// keyList binds keys for keyIn: comma-separated on Postgres, where they
+// are UUIDs, and as a JSON array on SQLite, where they may be any text.
+func keyList(d database.Dialect, keys []string) (string, error) {
+	if d == database.Postgres {
+		return strings.Join(keys, ","), nil
+	}
+	list, err := json.Marshal(keys)
+	return string(list), err
+}
+
+// queryByKeys runs query, whose first argument is the keyIn list, with
+// keys and then args, through stmts, passing every row to scan.
+func queryByKeys(ctx context.Context, stmts *database.StatementCache, db *sql.DB, d database.Dialect, query string, keys []string, args []any, scan func(scanner) error) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	list, err := keyList(d, keys)
+	if err != nil {
+		return err
+	}
+	rows, err := stmts.QueryContext(ctx, db, query, append([]any{list}, args...)...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// scanRows runs query, passing every row to scan.
+func scanRows(ctx context.Context, db database.Querier, query string, args []any, scan func(scanner) error) error {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Compile-time checks that the repositories satisfy their interfaces.
+var (
+	_ repositories.SynonymRepository      = (*SynonymRepository)(nil)
+	_ repositories.FlagRepository         = (*FlagRepository)(nil)
+	_ repositories.SearchLogRepository    = (*SearchLogRepository)(nil)
+	_ repositories.IntegrityRepository    = (*IntegrityRepository)(nil)
+	_ repositories.AuditRepository        = (*AuditRepository)(nil)
+	_ repositories.OutboxRepository       = (*OutboxRepository)(nil)
+	_ repositories.PriceLogRepository     = (*PriceLogRepository)(nil)
+	_ repositories.PriceIngester          = (*PriceIngestRepository)(nil)
+	_ repositories.PriceWriter            = (*PriceIngestRepository)(nil)
+	_ repositories.ProductRepository      = (*ProductRepository)(nil)
+	_ repositories.RetailerRepository     = (*RetailerRepository)(nil)
+	_ repositories.ListingRepository      = (*ListingRepository)(nil)
+	_ repositories.PriceRepository        = (*PriceRepository)(nil)
+	_ repositories.DailyPriceRepository   = (*PriceRepository)(nil)
+	_ repositories.CatalogAdminRepository = (*CatalogAdminRepository)(nil)
+	_ repositories.SelectorRepository     = (*SelectorRepository)(nil)
+	_ repositories.StatsRepository        = (*StatsRepository)(nil)
+	_ repositories.UserRepository         = (*UserRepository)(nil)
+	_ repositories.SessionRepository      = (*SessionRepository)(nil)
+	_ repositories.APITokenRepository     = (*APITokenRepository)(nil)
+	_ repositories.VerificationRepository = (*VerificationRepository)(nil)
+	_ repositories.IdentityRepository     = (*IdentityRepository)(nil)
+	_ repositories.AlertRepository        = (*AlertRepository)(nil)
+	_ repositories.UserDataRepository     = (*UserDataRepository)(nil)
+	_ repositories.Transactor             = (*database.TxManager)(nil)
+)
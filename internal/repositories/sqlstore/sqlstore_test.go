@@ -0,0 +1,91 @@
+package sqlstore
+
+import (
+	"cmp"
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite"
+
+	"github.com/yourusername/whey-price-compare/deployments/postgres/migrations"
+	"github.com/yourusername/whey-price-compare/deployments/sqlite"
+	"github.com/yourusername/whey-price-compare/internal/database"
+)
+
+// testDB is a database the repository tests run against.
+type testDB struct {
+	dialect database.Dialect
+	router  *database.Router
+	stmts   *database.StatementCache
+}
+
+// testDatabases returns every database the tests can reach, each with the
+// current schema and empty search tables: SQLite at TEST_SQLITE_URL, in
+// memory by default, and Postgres at TEST_POSTGRES_URL if set.
+func testDatabases(t *testing.T, logger *zap.Logger) []testDB {
+	t.Helper()
+	urls := []string{cmp.Or(os.Getenv("TEST_SQLITE_URL"), "sqlite::memory:"), os.Getenv("TEST_POSTGRES_URL")}
+	var out []testDB
+	for _, raw := range urls {
+		if raw == "" {
+			continue
+		}
+		cfg, err := database.ParseURL(raw, database.DefaultPoolConfig())
+		if err != nil {
+			t.Fatalf("Invalid test database URL: %v", err)
+		}
+		db, err := database.Open(cfg.Dialect.Driver, cfg)
+		if err != nil {
+			t.Fatalf("Open %s failed: %v", cfg.Dialect, err)
+		}
+		t.Cleanup(func() { _ = db.Close() })
+		prepareSchema(t, cfg.Dialect, db, logger)
+		stmts := database.NewStatementCache(cfg.Pool, 0)
+		t.Cleanup(func() { _ = stmts.Close() })
+		out = append(out, testDB{
+			dialect: cfg.Dialect,
+			router:  database.NewRouter(database.DefaultRouterConfig(), db, nil, logger),
+			stmts:   stmts,
+		})
+	}
+	return out
+}
+
+// prepareSchema brings db to the current schema and empties the tables the
+// tests write.
+func prepareSchema(t *testing.T, d database.Dialect, db *sql.DB, logger *zap.Logger) {
+	t.Helper()
+	ctx := t.Context()
+	switch d {
+	case database.SQLite:
+		var exists int
+		if err := db.QueryRowContext(ctx, `SELECT count(*) FROM sqlite_master WHERE name = 'search_synonyms'`).Scan(&exists); err != nil {
+			t.Fatalf("Inspect SQLite schema failed: %v", err)
+		}
+		if exists == 0 {
+			for _, stmt := range database.SplitStatements(sqlite.Schema) {
+				if _, err := db.ExecContext(ctx, stmt); err != nil {
+					t.Fatalf("Create SQLite schema failed: %v\n%s", err, stmt)
+				}
+			}
+		}
+	case database.Postgres:
+		all, err := database.LoadMigrations(migrations.FS)
+		if err != nil {
+			t.Fatalf("LoadMigrations failed: %v", err)
+		}
+		if _, err := database.NewMigrator(db, all, logger).Up(ctx, 0); err != nil {
+			t.Fatalf("Migrate Postgres failed: %v", err)
+		}
+	}
+	for _, table := range []string{"search_synonyms", "search_logs", "search_clicks", "audit_logs", "event_outbox", "price_change_log", "price_log_cursors", "feature_flags",
+		"retailer_selectors", "price_alerts", "api_keys", "user_sessions", "email_verification_tokens", "user_oauth_providers",
+		"user_passwords", "users"} {
+		if _, err := db.ExecContext(ctx, "DELETE FROM "+table); err != nil {
+			t.Fatalf("Empty %s failed: %v", table, err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/whey-price-compare/internal/database"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// synonymQueries are the search_synonyms statements in one dialect.
+type synonymQueries struct {
+	create, get, list, save, remove string
+}
+
+func newSynonymQueries(d database.Dialect) synonymQueries {
+	id := d.Text("id")
+	return synonymQueries{
+		create: d.Rebind(`
+INSERT INTO search_synonyms (terms, created_at, updated_at) VALUES ($1, $2, $3)
+RETURNING ` + id),
+		get: d.Rebind(`
+SELECT ` + id + `, terms, created_at, updated_at FROM search_synonyms WHERE ` + id + ` = $1`),
+		list: `
+SELECT ` + id + `, terms, created_at, updated_at FROM search_synonyms ORDER BY created_at, id`,
+		save: d.Rebind(`
+UPDATE search_synonyms SET terms = $2, updated_at = $3 WHERE ` + id + ` = $1`),
+		remove: d.Rebind(`
+DELETE FROM search_synonyms WHERE ` + id + ` = $1`),
+	}
+}
+
+// SynonymRepository implements repositories.SynonymRepository on the
+// search_synonyms table from migration 005. Every read goes to the
+// primary: synonyms are read rarely, right after an admin changes them.
+type SynonymRepository struct {
+	d     database.Dialect
+	db    *database.Router
+	stmts *database.StatementCache
+	q     synonymQueries
+}
+
+// NewSynonymRepository creates a SynonymRepository for a database of
+// dialect d.
+func NewSynonymRepository(d database.Dialect, db *database.Router, stmts *database.StatementCache) *SynonymRepository {
+	return &SynonymRepository{d: d, db: db, stmts: stmts, q: newSynonymQueries(d)}
+}
+
+// CreateSynonym implements repositories.SynonymRepository.
+func (r *SynonymRepository) CreateSynonym(ctx context.Context, s domain.Synonym, events ...domain.Event) (domain.Synonym, error) {
+	terms, err := json.Marshal(s.Terms)
+	if err != nil {
+		return s, fmt.Errorf("encode synonym terms: %w", err)
+	}
+	err = withEvents(ctx, r.db.Writer(), r.d, events, func(tx database.Querier) error {
+		row := tx.QueryRowContext(ctx, r.q.create, r.d.Args(string(terms), s.CreatedAt, s.UpdatedAt)...)
+		return row.Scan(&s.ID)
+	})
+	if err != nil {
+		return s, fmt.Errorf("create synonym: %w", err)
+	}
+	return s, nil
+}
+
+// Synonym implements repositories.SynonymRepository.
+func (r *SynonymRepository) Synonym(ctx context.Context, id string) (*domain.Synonym, error) {
+	s, err := scanSynonym(r.stmts.QueryRowContext(ctx, r.db.Writer(), r.q.get, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("synonym %q: %w", id, domain.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read synonym: %w", err)
+	}
+	return &s, nil
+}
+
+// Synonyms implements repositories.SynonymRepository.
+func (r *SynonymRepository) Synonyms(ctx context.Context) ([]domain.Synonym, error) {
+	rows, err := r.stmts.QueryContext(ctx, r.db.Writer(), r.q.list)
+	if err != nil {
+		return nil, fmt.Errorf("list synonyms: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []domain.Synonym
+	for rows.Next() {
+		s, err := scanSynonym(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan synonym: %w", err)
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// SaveSynonym implements repositories.SynonymRepository.
+func (r *SynonymRepository) SaveSynonym(ctx context.Context, s domain.Synonym, events ...domain.Event) error {
+	terms, err := json.Marshal(s.Terms)
+	if err != nil {
+		return fmt.Errorf("encode synonym terms: %w", err)
+	}
+	return withEvents(ctx, r.db.Writer(), r.d, events, func(tx database.Querier) error {
+		res, err := tx.ExecContext(ctx, r.q.save, r.d.Args(s.ID, string(terms), s.UpdatedAt)...)
+		if err != nil {
+			return fmt.Errorf("save synonym: %w", err)
+		}
+		return requireRow(res, "synonym", s.ID)
+	})
+}
+
+// DeleteSynonym implements repositories.SynonymRepository.
+func (r *SynonymRepository) DeleteSynonym(ctx context.Context, id string, events ...domain.Event) error {
+	return withEvents(ctx, r.db.Writer(), r.d, events, func(tx database.Querier) error {
+		res, err := tx.ExecContext(ctx, r.q.remove, id)
+		if err != nil {
+			return fmt.Errorf("delete synonym: %w", err)
+		}
+		return requireRow(res, "synonym", id)
+	})
+}
+
+func scanSynonym(row scanner) (domain.Synonym, error) {
+	var s domain.Synonym
+	var terms []byte
+	if err := row.Scan(&s.ID, &terms, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(terms, &s.Terms); err != nil {
+		return s, fmt.Errorf("decode synonym %s terms: %w", s.ID, err)
+	}
+	return s, nil
+}
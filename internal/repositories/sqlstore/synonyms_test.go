@@ -0,0 +1,63 @@
+package sqlstore
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestSynonymRepository(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSynonymRepository", "internal/repositories/sqlstore")
+
+	at := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	for _, db := range testDatabases(t, logger) {
+		t.Run(db.dialect.String(), func(t *testing.T) {
+			ctx := t.Context()
+			synonyms := NewSynonymRepository(db.dialect, db.router, db.stmts)
+
+			testhelpers.LogTestStep(logger, "act", "Creating two groups")
+			on, err := synonyms.CreateSynonym(ctx, domain.Synonym{Terms: []string{"ON", "Optimum Nutrition"}, CreatedAt: at, UpdatedAt: at})
+			if err != nil {
+				t.Fatalf("CreateSynonym: %v", err)
+			}
+			wpi, err := synonyms.CreateSynonym(ctx, domain.Synonym{Terms: []string{"WPI", "whey protein isolate"}, CreatedAt: at.Add(time.Minute), UpdatedAt: at.Add(time.Minute)})
+			if err != nil {
+				t.Fatalf("CreateSynonym: %v", err)
+			}
+
+			testhelpers.LogTestStep(logger, "assert", "Groups list oldest first")
+			all, err := synonyms.Synonyms(ctx)
+			if err != nil {
+				t.Fatalf("Synonyms: %v", err)
+			}
+			testhelpers.LogTestAssertion(logger, "groups", 2, len(all))
+			if len(all) != 2 || all[0].ID != on.ID || all[1].ID != wpi.ID || all[0].Terms[1] != "Optimum Nutrition" || !all[0].CreatedAt.Equal(at) {
+				t.Errorf("Synonyms = %+v", all)
+			}
+
+			testhelpers.LogTestStep(logger, "act", "Saving and deleting")
+			on.Terms = []string{"O.N.", "Optimum Nutrition"}
+			if err := synonyms.SaveSynonym(ctx, on); err != nil {
+				t.Fatalf("SaveSynonym: %v", err)
+			}
+			if again, err := synonyms.Synonym(ctx, on.ID); err != nil || again.Terms[0] != "O.N." {
+				t.Errorf("Saved synonym = %+v, %v", again, err)
+			}
+			if err := synonyms.DeleteSynonym(ctx, wpi.ID); err != nil {
+				t.Fatalf("DeleteSynonym: %v", err)
+			}
+			if err := synonyms.DeleteSynonym(ctx, wpi.ID); !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("Second delete err = %v, want ErrNotFound", err)
+			}
+			if _, err := synonyms.Synonym(ctx, wpi.ID); !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("Deleted synonym err = %v, want ErrNotFound", err)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSynonymRepository", true)
+}
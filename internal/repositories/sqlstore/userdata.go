@@ -0,0 +1,132 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/whey-price-compare/internal/database"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// userDataTables are the tables holding a user's records, child tables
+// first, each with the UserData kind its rows are counted as.
+var userDataTables = []struct{ table, kind string }{
+	{"price_alerts", "alerts"},
+	{"api_keys", "api_tokens"},
+	{"user_sessions", "sessions"},
+	{"email_verification_tokens", "verifications"},
+	{"user_oauth_providers", "linked_accounts"},
+	{"user_passwords", ""},
+	{"users", "account"},
+}
+
+const linkedAccountsQuery = `SELECT provider FROM user_oauth_providers WHERE user_id = $1 ORDER BY provider`
+
+// UserDataRepository implements repositories.UserDataRepository for
+// accounts stored here and the rest of a user's data stored by another
+// repository, rest, which must not require the account. Erasing has rest
+// erase its records, then deletes the rows here in one transaction, the
+// account last; an erasure that fails part way is finished by running it
+// again.
+type UserDataRepository struct {
+	d      database.Dialect
+	db     *database.Router
+	stmts  *database.StatementCache
+	users  *UserRepository
+	tokens *APITokenRepository
+	rest   repositories.UserDataRepository
+	alerts alertQueries
+}
+
+// NewUserDataRepository creates a UserDataRepository for a database of
+// dialect d, reading accounts through users.
+func NewUserDataRepository(d database.Dialect, db *database.Router, stmts *database.StatementCache, users *UserRepository, rest repositories.UserDataRepository) *UserDataRepository {
+	return &UserDataRepository{
+		d: d, db: db, stmts: stmts, users: users, tokens: NewAPITokenRepository(d, db, stmts), rest: rest,
+		alerts: newAlertQueries(d),
+	}
+}
+
+// ExportUserData implements repositories.UserDataRepository.
+func (r *UserDataRepository) ExportUserData(ctx context.Context, userID string) (*domain.UserData, error) {
+	u, err := r.users.UserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := r.rest.ExportUserData(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	data.Account = *u
+	db := r.db.Writer()
+
+	data.LinkedAccounts = []string{}
+	err = scanRows(ctx, database.Conn(ctx, db), r.d.Rebind(linkedAccountsQuery), []any{userID}, func(row scanner) error {
+		var provider string
+		err := row.Scan(&provider)
+		data.LinkedAccounts = append(data.LinkedAccounts, provider)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read linked accounts: %w", err)
+	}
+
+	// Deleted alerts are the user's data until they are erased too.
+	data.Alerts = []domain.PriceAlert{}
+	err = scanRows(ctx, database.Conn(ctx, db), r.alerts.allByUser, []any{userID}, func(row scanner) error {
+		a, err := scanAlert(row)
+		data.Alerts = append(data.Alerts, a)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read alerts: %w", err)
+	}
+
+	tokens, err := r.tokens.UserAPITokens(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	data.APITokens = append([]domain.APIToken{}, tokens...)
+	return data, nil
+}
+
+// DeleteUserData implements repositories.UserDataRepository.
+func (r *UserDataRepository) DeleteUserData(ctx context.Context, userID string) (map[string]int, error) {
+	if _, err := r.users.UserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	removed, err := r.rest.DeleteUserData(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	db := r.db.Writer()
+	err = database.InTx(ctx, db, nil, func(ctx context.Context) error {
+		for _, t := range userDataTables {
+			res, err := database.Conn(ctx, db).ExecContext(ctx, r.d.Rebind(`DELETE FROM `+t.table+` WHERE `+userColumn(t.table)+` = $1`), userID)
+			if err != nil {
+				return fmt.Errorf("delete %s: %w", t.table, err)
+			}
+			n, err := res.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("delete %s: %w", t.table, err)
+			}
+			if t.kind != "" && n > 0 {
+				removed[t.kind] += int(n)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// userColumn names the column of table that holds the user's ID.
+func userColumn(table string) string {
+	if table == "users" {
+		return "id"
+	}
+	return "user_id"
+}
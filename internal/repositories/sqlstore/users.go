@@ -0,0 +1,501 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/database"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// Columns sealed with a PIIKey, named as the field each is sealed for.
+const (
+	emailField  = "users.email"
+	nameField   = "users.name"
+	verifyField = "email_verification_tokens.email_to_verify"
+)
+
+// userQueries are the account statements in one dialect.
+type userQueries struct {
+	byID, byEmail                        string
+	insert, update, exists               string
+	savePassword, deletePassword         string
+	insertSession, session               string
+	deleteSession, deleteUserSessions    string
+	insertToken, tokenByHash, userTokens string
+	touchToken, deleteToken              string
+	insertVerification, consume          string
+	link, identity                       string
+}
+
+func newUserQueries(d database.Dialect) userQueries {
+	user := `
+SELECT ` + d.Text("u.id") + `, u.email_encrypted, u.name_encrypted, coalesce(u.email_verified, FALSE), u.created_at,
+    u.last_login_at, coalesce(pw.password_hash, '')
+FROM users u LEFT JOIN user_passwords pw ON pw.user_id = u.id`
+	token := `
+SELECT ` + d.Text("id") + `, ` + d.Text("user_id") + `, name, prefix, key_hash, scopes, created_at, expires_at, last_used_at
+FROM api_keys`
+	return userQueries{
+		byID:    d.Rebind(user + ` WHERE u.id = $1`),
+		byEmail: d.Rebind(user + ` WHERE u.email_hash = $1`),
+		insert: d.Rebind(`
+INSERT INTO users (email_hash, email_encrypted, name_encrypted, email_verified, created_at, last_login_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (email_hash) DO NOTHING
+RETURNING ` + d.Text("id")),
+		// The update skips a user whose new address another user has;
+		// exists then tells that from a missing user.
+		update: d.Rebind(`
+UPDATE users SET email_hash = $2, email_encrypted = $3, name_encrypted = $4, email_verified = $5,
+    last_login_at = $6, updated_at = ` + d.Now() + `
+WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM users o WHERE o.email_hash = $2 AND o.id <> $1)`),
+		exists: d.Rebind(`SELECT count(*) FROM users WHERE id = $1`),
+		savePassword: d.Rebind(`
+INSERT INTO user_passwords (user_id, password_hash, last_changed_at) VALUES ($1, $2, ` + d.Now() + `)
+ON CONFLICT (user_id) DO UPDATE SET password_hash = excluded.password_hash, last_changed_at = excluded.last_changed_at
+WHERE user_passwords.password_hash <> excluded.password_hash`),
+		deletePassword: d.Rebind(`DELETE FROM user_passwords WHERE user_id = $1`),
+		insertSession: d.Rebind(`
+INSERT INTO user_sessions (session_token_hash, user_id, user_agent, created_at, expires_at)
+VALUES ($1, $2, $3, $4, $5)`),
+		session: d.Rebind(`
+SELECT ` + d.Text("user_id") + `, coalesce(user_agent, ''), created_at, expires_at
+FROM user_sessions WHERE session_token_hash = $1`),
+		deleteSession:      d.Rebind(`DELETE FROM user_sessions WHERE session_token_hash = $1`),
+		deleteUserSessions: d.Rebind(`DELETE FROM user_sessions WHERE user_id = $1`),
+		insertToken: d.Rebind(`
+INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, created_at, expires_at, last_used_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING ` + d.Text("id")),
+		tokenByHash: d.Rebind(token + ` WHERE key_hash = $1`),
+		userTokens:  d.Rebind(token + ` WHERE user_id = $1 ORDER BY created_at, id`),
+		touchToken:  d.Rebind(`UPDATE api_keys SET last_used_at = $2 WHERE id = $1`),
+		deleteToken: d.Rebind(`DELETE FROM api_keys WHERE id = $1 AND user_id = $2`),
+		insertVerification: d.Rebind(`
+INSERT INTO email_verification_tokens (token_hash, user_id, email_to_verify, created_at, expires_at)
+VALUES ($1, $2, $3, $4, $5)`),
+		consume: d.Rebind(`
+DELETE FROM email_verification_tokens WHERE token_hash = $1
+RETURNING ` + d.Text("user_id") + `, email_to_verify, created_at, expires_at`),
+		link: d.Rebind(`
+INSERT INTO user_oauth_providers (provider, provider_user_id, user_id) VALUES ($1, $2, $3)
+ON CONFLICT (provider, provider_user_id) DO NOTHING`),
+		identity: d.Rebind(`
+SELECT ` + d.Text("user_id") + ` FROM user_oauth_providers WHERE provider = $1 AND provider_user_id = $2`),
+	}
+}
+
+// UserRepository implements repositories.UserRepository on the users and
+// user_passwords tables of migration 001. Addresses and names are sealed
+// with a PIIKey, and addresses looked up by its keyed hash. Reads go to
+// the primary: an account is read right after it is written, as when
+// signing up signs in.
+type UserRepository struct {
+	d     database.Dialect
+	db    *database.Router
+	stmts *database.StatementCache
+	key   *PIIKey
+	q     userQueries
+}
+
+// NewUserRepository creates a UserRepository for a database of dialect d
+// that seals personal data with key.
+func NewUserRepository(d database.Dialect, db *database.Router, stmts *database.StatementCache, key *PIIKey) *UserRepository {
+	return &UserRepository{d: d, db: db, stmts: stmts, key: key, q: newUserQueries(d)}
+}
+
+// CreateUser implements repositories.UserRepository.
+func (r *UserRepository) CreateUser(ctx context.Context, u domain.User) (domain.User, error) {
+	db := r.db.Writer()
+	err := database.InTx(ctx, db, nil, func(ctx context.Context) error {
+		err := r.stmts.QueryRowContext(ctx, db, r.q.insert, r.d.Args(r.key.emailHash(u.Email), r.key.seal(emailField, u.Email),
+			r.sealName(u.Name), u.EmailVerified, u.CreatedAt, timeOrNull(u.LastLoginAt))...).Scan(&u.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("email is already registered: %w", domain.ErrConflict)
+		}
+		if err != nil {
+			return fmt.Errorf("insert user: %w", err)
+		}
+		return r.savePassword(ctx, db, u)
+	})
+	if err != nil {
+		return domain.User{}, err
+	}
+	return u, nil
+}
+
+// UserByID implements repositories.UserRepository.
+func (r *UserRepository) UserByID(ctx context.Context, id string) (*domain.User, error) {
+	if !validKey(r.d, id) {
+		return nil, fmt.Errorf("user %q: %w", id, domain.ErrNotFound)
+	}
+	u, err := r.scanUser(r.stmts.QueryRowContext(ctx, r.db.Writer(), r.q.byID, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("user %q: %w", id, domain.ErrNotFound)
+	}
+	return u, err
+}
+
+// UserByEmail implements repositories.UserRepository.
+func (r *UserRepository) UserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	u, err := r.scanUser(r.stmts.QueryRowContext(ctx, r.db.Writer(), r.q.byEmail, r.key.emailHash(email)))
+	if errors.Is(err, sql.ErrNoRows) {
+		// The address is personal data, so it stays out of the error.
+		return nil, fmt.Errorf("user by email: %w", domain.ErrNotFound)
+	}
+	return u, err
+}
+
+// SaveUser implements repositories.UserRepository.
+func (r *UserRepository) SaveUser(ctx context.Context, u domain.User) error {
+	if !validKey(r.d, u.ID) {
+		return fmt.Errorf("user %q: %w", u.ID, domain.ErrNotFound)
+	}
+	db := r.db.Writer()
+	return database.InTx(ctx, db, nil, func(ctx context.Context) error {
+		res, err := r.stmts.ExecContext(ctx, db, r.q.update, r.d.Args(u.ID, r.key.emailHash(u.Email),
+			r.key.seal(emailField, u.Email), r.sealName(u.Name), u.EmailVerified, timeOrNull(u.LastLoginAt))...)
+		if err != nil {
+			return fmt.Errorf("update user: %w", err)
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return fmt.Errorf("update user: %w", err)
+		} else if n == 0 {
+			var exists int
+			if err := r.stmts.QueryRowContext(ctx, db, r.q.exists, u.ID).Scan(&exists); err != nil {
+				return fmt.Errorf("read user: %w", err)
+			}
+			if exists == 0 {
+				return fmt.Errorf("user %q: %w", u.ID, domain.ErrNotFound)
+			}
+			return fmt.Errorf("email is already registered: %w", domain.ErrConflict)
+		}
+		return r.savePassword(ctx, db, u)
+	})
+}
+
+// savePassword stores u's password hash, or removes the one stored if it
+// has none.
+func (r *UserRepository) savePassword(ctx context.Context, db *sql.DB, u domain.User) error {
+	var err error
+	if u.PasswordHash == "" {
+		_, err = r.stmts.ExecContext(ctx, db, r.q.deletePassword, u.ID)
+	} else {
+		_, err = r.stmts.ExecContext(ctx, db, r.q.savePassword, u.ID, u.PasswordHash)
+	}
+	if err != nil {
+		return fmt.Errorf("save password: %w", err)
+	}
+	return nil
+}
+
+func (r *UserRepository) sealName(name string) any {
+	if name == "" {
+		return nil
+	}
+	return r.key.seal(nameField, name)
+}
+
+func (r *UserRepository) scanUser(row scanner) (*domain.User, error) {
+	var (
+		u           domain.User
+		email, name []byte
+		created     sql.NullTime
+		lastLogin   sql.NullTime
+	)
+	if err := row.Scan(&u.ID, &email, &name, &u.EmailVerified, &created, &lastLogin, &u.PasswordHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("read user: %w", err)
+	}
+	var err error
+	if u.Email, err = r.key.open(emailField, email); err != nil {
+		return nil, fmt.Errorf("user %q: %w", u.ID, err)
+	}
+	if len(name) > 0 {
+		if u.Name, err = r.key.open(nameField, name); err != nil {
+			return nil, fmt.Errorf("user %q: %w", u.ID, err)
+		}
+	}
+	u.CreatedAt = created.Time
+	if lastLogin.Valid {
+		u.LastLoginAt = &lastLogin.Time
+	}
+	return &u, nil
+}
+
+// SessionRepository implements repositories.SessionRepository on the
+// user_sessions table. Reads go to the primary: a session is read on the
+// request after the one that signed in.
+type SessionRepository struct {
+	d     database.Dialect
+	db    *database.Router
+	stmts *database.StatementCache
+	q     userQueries
+}
+
+// NewSessionRepository creates a SessionRepository for a database of
+// dialect d.
+func NewSessionRepository(d database.Dialect, db *database.Router, stmts *database.StatementCache) *SessionRepository {
+	return &SessionRepository{d: d, db: db, stmts: stmts, q: newUserQueries(d)}
+}
+
+// CreateSession implements repositories.SessionRepository.
+func (r *SessionRepository) CreateSession(ctx context.Context, s domain.Session) error {
+	if !validKey(r.d, s.UserID) {
+		return fmt.Errorf("user %q: %w", s.UserID, domain.ErrNotFound)
+	}
+	if _, err := r.stmts.ExecContext(ctx, r.db.Writer(), r.q.insertSession,
+		r.d.Args(s.TokenHash, s.UserID, nullIfEmpty(s.UserAgent), s.CreatedAt, s.ExpiresAt)...); err != nil {
+		return fmt.Errorf("insert session: %w", err)
+	}
+	return nil
+}
+
+// Session implements repositories.SessionRepository.
+func (r *SessionRepository) Session(ctx context.Context, tokenHash string) (*domain.Session, error) {
+	s := domain.Session{TokenHash: tokenHash}
+	err := r.stmts.QueryRowContext(ctx, r.db.Writer(), r.q.session, tokenHash).Scan(&s.UserID, &s.UserAgent, &s.CreatedAt, &s.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("session: %w", domain.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read session: %w", err)
+	}
+	return &s, nil
+}
+
+// DeleteSession implements repositories.SessionRepository.
+func (r *SessionRepository) DeleteSession(ctx context.Context, tokenHash string) error {
+	if _, err := r.stmts.ExecContext(ctx, r.db.Writer(), r.q.deleteSession, tokenHash); err != nil {
+		return fmt.Errorf("delete session: %w", err)
+	}
+	return nil
+}
+
+// DeleteUserSessions implements repositories.SessionRepository.
+func (r *SessionRepository) DeleteUserSessions(ctx context.Context, userID string) error {
+	if !validKey(r.d, userID) {
+		return nil
+	}
+	if _, err := r.stmts.ExecContext(ctx, r.db.Writer(), r.q.deleteUserSessions, userID); err != nil {
+		return fmt.Errorf("delete sessions: %w", err)
+	}
+	return nil
+}
+
+// APITokenRepository implements repositories.APITokenRepository on the
+// api_keys table. Reads go to the primary, as for sessions.
+type APITokenRepository struct {
+	d     database.Dialect
+	db    *database.Router
+	stmts *database.StatementCache
+	q     userQueries
+}
+
+// NewAPITokenRepository creates an APITokenRepository for a database of
+// dialect d.
+func NewAPITokenRepository(d database.Dialect, db *database.Router, stmts *database.StatementCache) *APITokenRepository {
+	return &APITokenRepository{d: d, db: db, stmts: stmts, q: newUserQueries(d)}
+}
+
+// CreateAPIToken implements repositories.APITokenRepository.
+func (r *APITokenRepository) CreateAPIToken(ctx context.Context, t domain.APIToken) (domain.APIToken, error) {
+	if !validKey(r.d, t.UserID) {
+		return t, fmt.Errorf("user %q: %w", t.UserID, domain.ErrNotFound)
+	}
+	scopes, err := json.Marshal(t.Scopes)
+	if err != nil {
+		return t, fmt.Errorf("encode api token scopes: %w", err)
+	}
+	err = r.stmts.QueryRowContext(ctx, r.db.Writer(), r.q.insertToken, r.d.Args(t.UserID, t.Name, t.Prefix, t.TokenHash,
+		string(scopes), t.CreatedAt, timeOrNull(t.ExpiresAt), timeOrNull(t.LastUsedAt))...).Scan(&t.ID)
+	if err != nil {
+		return t, fmt.Errorf("insert api token: %w", err)
+	}
+	return t, nil
+}
+
+// APITokenByHash implements repositories.APITokenRepository.
+func (r *APITokenRepository) APITokenByHash(ctx context.Context, tokenHash string) (*domain.APIToken, error) {
+	t, err := scanAPIToken(r.stmts.QueryRowContext(ctx, r.db.Writer(), r.q.tokenByHash, tokenHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("api token: %w", domain.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read api token: %w", err)
+	}
+	return &t, nil
+}
+
+// UserAPITokens implements repositories.APITokenRepository.
+func (r *APITokenRepository) UserAPITokens(ctx context.Context, userID string) ([]domain.APIToken, error) {
+	if !validKey(r.d, userID) {
+		return nil, nil
+	}
+	rows, err := r.stmts.QueryContext(ctx, r.db.Writer(), r.q.userTokens, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list api tokens: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []domain.APIToken
+	for rows.Next() {
+		t, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan api token: %w", err)
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// TouchAPIToken implements repositories.APITokenRepository.
+func (r *APITokenRepository) TouchAPIToken(ctx context.Context, id string, at time.Time) error {
+	if !validKey(r.d, id) {
+		return fmt.Errorf("api token %s: %w", id, domain.ErrNotFound)
+	}
+	res, err := r.stmts.ExecContext(ctx, r.db.Writer(), r.q.touchToken, r.d.Args(id, at)...)
+	if err != nil {
+		return fmt.Errorf("touch api token: %w", err)
+	}
+	return requireRow(res, "api token", id)
+}
+
+// DeleteAPIToken implements repositories.APITokenRepository.
+func (r *APITokenRepository) DeleteAPIToken(ctx context.Context, userID, id string) error {
+	if !validKey(r.d, id) || !validKey(r.d, userID) {
+		return fmt.Errorf("api token %s: %w", id, domain.ErrNotFound)
+	}
+	res, err := r.stmts.ExecContext(ctx, r.db.Writer(), r.q.deleteToken, id, userID)
+	if err != nil {
+		return fmt.Errorf("delete api token: %w", err)
+	}
+	return requireRow(res, "api token", id)
+}
+
+func scanAPIToken(row scanner) (domain.APIToken, error) {
+	var (
+		t                domain.APIToken
+		scopes           []byte
+		expires, lastUse sql.NullTime
+	)
+	if err := row.Scan(&t.ID, &t.UserID, &t.Name, &t.Prefix, &t.TokenHash, &scopes, &t.CreatedAt, &expires, &lastUse); err != nil {
+		return t, err
+	}
+	if err := json.Unmarshal(scopes, &t.Scopes); err != nil {
+		return t, fmt.Errorf("decode api token %s scopes: %w", t.ID, err)
+	}
+	if expires.Valid {
+		t.ExpiresAt = &expires.Time
+	}
+	if lastUse.Valid {
+		t.LastUsedAt = &lastUse.Time
+	}
+	return t, nil
+}
+
+// VerificationRepository implements repositories.VerificationRepository
+// on the email_verification_tokens table, sealing the address each token
+// was sent to like the account's own.
+type VerificationRepository struct {
+	d     database.Dialect
+	db    *database.Router
+	stmts *database.StatementCache
+	key   *PIIKey
+	q     userQueries
+}
+
+// NewVerificationRepository creates a VerificationRepository for a
+// database of dialect d that seals addresses with key.
+func NewVerificationRepository(d database.Dialect, db *database.Router, stmts *database.StatementCache, key *PIIKey) *VerificationRepository {
+	return &VerificationRepository{d: d, db: db, stmts: stmts, key: key, q: newUserQueries(d)}
+}
+
+// CreateVerification implements repositories.VerificationRepository.
+func (r *VerificationRepository) CreateVerification(ctx context.Context, v domain.EmailVerification) error {
+	if !validKey(r.d, v.UserID) {
+		return fmt.Errorf("user %q: %w", v.UserID, domain.ErrNotFound)
+	}
+	if _, err := r.stmts.ExecContext(ctx, r.db.Writer(), r.q.insertVerification,
+		r.d.Args(v.TokenHash, v.UserID, r.key.seal(verifyField, v.Email), v.CreatedAt, v.ExpiresAt)...); err != nil {
+		return fmt.Errorf("insert email verification: %w", err)
+	}
+	return nil
+}
+
+// ConsumeVerification implements repositories.VerificationRepository.
+func (r *VerificationRepository) ConsumeVerification(ctx context.Context, tokenHash string) (*domain.EmailVerification, error) {
+	v := domain.EmailVerification{TokenHash: tokenHash}
+	var email []byte
+	err := r.stmts.QueryRowContext(ctx, r.db.Writer(), r.q.consume, tokenHash).Scan(&v.UserID, &email, &v.CreatedAt, &v.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("email verification: %w", domain.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("consume email verification: %w", err)
+	}
+	if v.Email, err = r.key.open(verifyField, email); err != nil {
+		return nil, fmt.Errorf("email verification: %w", err)
+	}
+	return &v, nil
+}
+
+// IdentityRepository implements repositories.IdentityRepository on the
+// user_oauth_providers table.
+type IdentityRepository struct {
+	d     database.Dialect
+	db    *database.Router
+	stmts *database.StatementCache
+	q     userQueries
+}
+
+// NewIdentityRepository creates an IdentityRepository for a database of
+// dialect d.
+func NewIdentityRepository(d database.Dialect, db *database.Router, stmts *database.StatementCache) *IdentityRepository {
+	return &IdentityRepository{d: d, db: db, stmts: stmts, q: newUserQueries(d)}
+}
+
+// LinkIdentity implements repositories.IdentityRepository.
+func (r *IdentityRepository) LinkIdentity(ctx context.Context, provider, subject, userID string) error {
+	if !validKey(r.d, userID) {
+		return fmt.Errorf("user %q: %w", userID, domain.ErrNotFound)
+	}
+	db := r.db.Writer()
+	return database.InTx(ctx, db, nil, func(ctx context.Context) error {
+		if _, err := r.stmts.ExecContext(ctx, db, r.q.link, provider, subject, userID); err != nil {
+			return fmt.Errorf("link %s identity: %w", provider, err)
+		}
+		linked, err := r.identityUser(ctx, db, provider, subject)
+		if err != nil {
+			return err
+		}
+		if linked != userID {
+			return fmt.Errorf("%s account is linked to another user: %w", provider, domain.ErrConflict)
+		}
+		return nil
+	})
+}
+
+// IdentityUser implements repositories.IdentityRepository.
+func (r *IdentityRepository) IdentityUser(ctx context.Context, provider, subject string) (string, error) {
+	return r.identityUser(ctx, r.db.Writer(), provider, subject)
+}
+
+func (r *IdentityRepository) identityUser(ctx context.Context, db *sql.DB, provider, subject string) (string, error) {
+	var userID string
+	err := r.stmts.QueryRowContext(ctx, db, r.q.identity, provider, subject).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%s identity: %w", provider, domain.ErrNotFound)
+	}
+	if err != nil {
+		return "", fmt.Errorf("read %s identity: %w", provider, err)
+	}
+	return userID, nil
+}
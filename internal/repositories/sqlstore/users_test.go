@@ -0,0 +1,157 @@
+package sqlstore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// tokenHash hashes token the way the auth service does.
+func tokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestUserRepositories(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestUserRepositories", "internal/repositories/sqlstore")
+
+	key, err := NewPIIKey(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatalf("NewPIIKey failed: %v", err)
+	}
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	for _, db := range testDatabases(t, logger) {
+		t.Run(db.dialect.String(), func(t *testing.T) {
+			ctx := t.Context()
+			users := NewUserRepository(db.dialect, db.router, db.stmts, key)
+			sessions := NewSessionRepository(db.dialect, db.router, db.stmts)
+			tokens := NewAPITokenRepository(db.dialect, db.router, db.stmts)
+			verifications := NewVerificationRepository(db.dialect, db.router, db.stmts, key)
+			identities := NewIdentityRepository(db.dialect, db.router, db.stmts)
+
+			testhelpers.LogTestStep(logger, "act", "Creating a user, then another with the same email")
+			// The auth service normalizes emails before they get here.
+			u, err := users.CreateUser(ctx, domain.User{Email: "asha@example.com", Name: "Asha", CreatedAt: now, PasswordHash: "argon2id$hash"})
+			if err != nil || u.ID == "" {
+				t.Fatalf("CreateUser = %+v, %v; want an ID", u, err)
+			}
+			if _, err := users.CreateUser(ctx, domain.User{Email: "asha@example.com", CreatedAt: now}); !errors.Is(err, domain.ErrConflict) {
+				t.Errorf("CreateUser of a taken email = %v, want ErrConflict", err)
+			}
+
+			testhelpers.LogTestStep(logger, "assert", "The user reads back by ID and email; the stored email is sealed")
+			got, err := users.UserByEmail(ctx, "asha@example.com")
+			testhelpers.LogTestAssertion(logger, "user", u.ID, got)
+			if err != nil || got.ID != u.ID || got.Name != "Asha" || got.PasswordHash != "argon2id$hash" {
+				t.Fatalf("UserByEmail = %+v, %v; want %+v", got, err, u)
+			}
+			var stored []byte
+			if err := db.router.Writer().QueryRowContext(ctx, db.dialect.Rebind(`SELECT email_encrypted FROM users WHERE email_hash = $1`), key.emailHash(u.Email)).Scan(&stored); err != nil {
+				t.Fatalf("Read sealed email failed: %v", err)
+			}
+			if bytes.Contains(stored, []byte("example.com")) {
+				t.Errorf("Stored email %q is in the clear", stored)
+			}
+
+			testhelpers.LogTestStep(logger, "act", "Saving the user with a new email and no password")
+			got.Email, got.PasswordHash, got.EmailVerified, got.LastLoginAt = "asha@example.org", "", true, &now
+			if err := users.SaveUser(ctx, *got); err != nil {
+				t.Fatalf("SaveUser failed: %v", err)
+			}
+			if saved, err := users.UserByID(ctx, u.ID); err != nil || saved.Email != "asha@example.org" || saved.PasswordHash != "" || !saved.EmailVerified {
+				t.Errorf("UserByID = %+v, %v; want the saved user", saved, err)
+			}
+			if _, err := users.UserByEmail(ctx, "asha@example.com"); !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("UserByEmail of the old email = %v, want ErrNotFound", err)
+			}
+			other, err := users.CreateUser(ctx, domain.User{Email: "ravi@example.com", CreatedAt: now})
+			if err != nil {
+				t.Fatalf("CreateUser failed: %v", err)
+			}
+			other.Email = "asha@example.org"
+			if err := users.SaveUser(ctx, other); !errors.Is(err, domain.ErrConflict) {
+				t.Errorf("SaveUser with a taken email = %v, want ErrConflict", err)
+			}
+
+			testhelpers.LogTestStep(logger, "act", "Signing in, issuing a token, verifying and linking")
+			session := domain.Session{TokenHash: tokenHash("session"), UserID: u.ID, UserAgent: "test", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+			if err := sessions.CreateSession(ctx, session); err != nil {
+				t.Fatalf("CreateSession failed: %v", err)
+			}
+			if s, err := sessions.Session(ctx, session.TokenHash); err != nil || s.UserID != u.ID || !s.ExpiresAt.Equal(session.ExpiresAt) {
+				t.Errorf("Session = %+v, %v; want %+v", s, err, session)
+			}
+			token, err := tokens.CreateAPIToken(ctx, domain.APIToken{UserID: u.ID, Name: "cli", Prefix: "wpc_abcd", TokenHash: tokenHash("token"), Scopes: []string{domain.ScopeRead}, CreatedAt: now})
+			if err != nil || token.ID == "" {
+				t.Fatalf("CreateAPIToken = %+v, %v; want an ID", token, err)
+			}
+			if err := tokens.TouchAPIToken(ctx, token.ID, now); err != nil {
+				t.Fatalf("TouchAPIToken failed: %v", err)
+			}
+			if got, err := tokens.APITokenByHash(ctx, token.TokenHash); err != nil || !got.HasScope(domain.ScopeRead) || got.LastUsedAt == nil {
+				t.Errorf("APITokenByHash = %+v, %v; want the used read token", got, err)
+			}
+			if err := tokens.DeleteAPIToken(ctx, other.ID, token.ID); !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("DeleteAPIToken by another user = %v, want ErrNotFound", err)
+			}
+			verification := domain.EmailVerification{TokenHash: tokenHash("verify"), UserID: u.ID, Email: "asha@example.net", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+			if err := verifications.CreateVerification(ctx, verification); err != nil {
+				t.Fatalf("CreateVerification failed: %v", err)
+			}
+			if v, err := verifications.ConsumeVerification(ctx, verification.TokenHash); err != nil || v.Email != verification.Email {
+				t.Errorf("ConsumeVerification = %+v, %v; want %+v", v, err, verification)
+			}
+			if _, err := verifications.ConsumeVerification(ctx, verification.TokenHash); !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("Second ConsumeVerification = %v, want ErrNotFound", err)
+			}
+			if err := identities.LinkIdentity(ctx, "google", "sub-1", u.ID); err != nil {
+				t.Fatalf("LinkIdentity failed: %v", err)
+			}
+			if err := identities.LinkIdentity(ctx, "google", "sub-1", u.ID); err != nil {
+				t.Errorf("Relinking to the same user = %v, want nil", err)
+			}
+			if err := identities.LinkIdentity(ctx, "google", "sub-1", other.ID); !errors.Is(err, domain.ErrConflict) {
+				t.Errorf("Linking to another user = %v, want ErrConflict", err)
+			}
+
+			testhelpers.LogTestStep(logger, "act", "Exporting, then erasing the user's data")
+			data := NewUserDataRepository(db.dialect, db.router, db.stmts, users, memory.NewStore().UserDataExceptAccounts())
+			export, err := data.ExportUserData(ctx, u.ID)
+			if err != nil {
+				t.Fatalf("ExportUserData failed: %v", err)
+			}
+			if export.Account.ID != u.ID || len(export.LinkedAccounts) != 1 || len(export.APITokens) != 1 {
+				t.Errorf("ExportUserData = %+v", export)
+			}
+			removed, err := data.DeleteUserData(ctx, u.ID)
+			if err != nil {
+				t.Fatalf("DeleteUserData failed: %v", err)
+			}
+
+			testhelpers.LogTestStep(logger, "assert", "The account and everything hanging off it are gone")
+			testhelpers.LogTestAssertion(logger, "removed", "account, session, token and link", removed)
+			if removed["account"] != 1 || removed["sessions"] != 1 || removed["api_tokens"] != 1 || removed["linked_accounts"] != 1 {
+				t.Errorf("DeleteUserData removed %v", removed)
+			}
+			if _, err := users.UserByID(ctx, u.ID); !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("UserByID after erasure = %v, want ErrNotFound", err)
+			}
+			if _, err := sessions.Session(ctx, session.TokenHash); !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("Session after erasure = %v, want ErrNotFound", err)
+			}
+			if _, err := identities.IdentityUser(ctx, "google", "sub-1"); !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("IdentityUser after erasure = %v, want ErrNotFound", err)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestUserRepositories", true)
+}
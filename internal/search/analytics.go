@@ -0,0 +1,303 @@
+package search
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Report limits.
+const (
+	DefaultReportQueries = 20
+	MaxReportQueries     = 100
+)
+
+// AnalyticsConfig configures the asynchronous search log writer.
+type AnalyticsConfig struct {
+	// Buffer is how many records may wait to be written. Records beyond it
+	// are dropped rather than slowing searches down.
+	Buffer int
+	// BatchSize and FlushInterval bound how long a record waits in memory.
+	BatchSize     int
+	FlushInterval time.Duration
+	// Retention is how long searches and clicks are kept.
+	Retention time.Duration
+}
+
+// DefaultAnalyticsConfig returns settings that keep three months of
+// searches.
+func DefaultAnalyticsConfig() AnalyticsConfig {
+	return AnalyticsConfig{Buffer: 4096, BatchSize: 200, FlushInterval: 5 * time.Second, Retention: 90 * 24 * time.Hour}
+}
+
+// Analytics records searches and the positions of the results clicked,
+// and reports the most frequent queries and those finding nothing.
+// Searched and Clicked never block; Run writes queued records in batches
+// until its context ends.
+type Analytics struct {
+	cfg     AnalyticsConfig
+	repo    repositories.SearchLogRepository
+	logger  *zap.Logger
+	records chan analyticsRecord
+	dropped atomic.Int64
+	now     func() time.Time
+}
+
+// analyticsRecord is a queued search or click.
+type analyticsRecord struct {
+	search *domain.SearchLog
+	click  *domain.SearchClick
+}
+
+// NewAnalytics creates an Analytics. Call Run to start writing.
+func NewAnalytics(cfg AnalyticsConfig, repo repositories.SearchLogRepository, logger *zap.Logger) *Analytics {
+	def := DefaultAnalyticsConfig()
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = def.Buffer
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = def.BatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = def.FlushInterval
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = def.Retention
+	}
+	return &Analytics{
+		cfg:     cfg,
+		repo:    repo,
+		logger:  logger,
+		records: make(chan analyticsRecord, cfg.Buffer),
+		now:     time.Now,
+	}
+}
+
+// Searched queues a log of a page of results for text and returns the ID
+// clicks on them should quote. The query is anonymized and the time
+// truncated to the hour before anything is stored.
+func (a *Analytics) Searched(text string, page, results int, fuzzy bool) string {
+	l := domain.SearchLog{
+		ID:        rand.Text(),
+		Query:     domain.AnonymizeQuery(text),
+		Page:      page,
+		Results:   results,
+		Fuzzy:     fuzzy,
+		CreatedAt: a.now().UTC().Truncate(time.Hour),
+	}
+	a.queue(analyticsRecord{search: &l})
+	return l.ID
+}
+
+// Clicked queues a click on the result at position, counted from 1 across
+// pages, of the search with searchID.
+func (a *Analytics) Clicked(searchID, productID string, position int) error {
+	switch {
+	case searchID == "" || productID == "":
+		return fmt.Errorf("search_id and product_id are required: %w", domain.ErrInvalid)
+	case position < 1 || position > MaxCandidates:
+		return fmt.Errorf("position must be between 1 and %d: %w", MaxCandidates, domain.ErrInvalid)
+	}
+	a.queue(analyticsRecord{click: &domain.SearchClick{
+		SearchID:  searchID,
+		ProductID: productID,
+		Position:  position,
+		CreatedAt: a.now().UTC().Truncate(time.Hour),
+	}})
+	return nil
+}
+
+func (a *Analytics) queue(r analyticsRecord) {
+	select {
+	case a.records <- r:
+	default:
+		if a.dropped.Add(1)%100 == 1 {
+			a.logger.Warn("Search analytics buffer full, dropping records",
+				zap.String("operation", "TrackSearch"),
+				zap.Int64("dropped_total", a.dropped.Load()),
+			)
+		}
+	}
+}
+
+// Dropped returns how many records were discarded because the buffer was
+// full.
+func (a *Analytics) Dropped() int64 { return a.dropped.Load() }
+
+// Run writes queued records until ctx is cancelled, then flushes whatever
+// is still buffered. Once a day it deletes records older than the
+// retention period.
+func (a *Analytics) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.FlushInterval)
+	defer ticker.Stop()
+	purge := time.NewTicker(24 * time.Hour)
+	defer purge.Stop()
+	a.purge(ctx)
+
+	var b analyticsBatch
+	for {
+		select {
+		case r := <-a.records:
+			b.add(r)
+			if b.len() >= a.cfg.BatchSize {
+				a.flush(ctx, &b)
+			}
+		case <-ticker.C:
+			a.flush(ctx, &b)
+		case <-purge.C:
+			a.purge(ctx)
+		case <-ctx.Done():
+			for {
+				select {
+				case r := <-a.records:
+					b.add(r)
+				default:
+					a.flush(context.WithoutCancel(ctx), &b)
+					return
+				}
+			}
+		}
+	}
+}
+
+// analyticsBatch holds records waiting to be written.
+type analyticsBatch struct {
+	searches []domain.SearchLog
+	clicks   []domain.SearchClick
+}
+
+func (b *analyticsBatch) add(r analyticsRecord) {
+	if r.search != nil {
+		b.searches = append(b.searches, *r.search)
+	}
+	if r.click != nil {
+		b.clicks = append(b.clicks, *r.click)
+	}
+}
+
+func (b *analyticsBatch) len() int { return len(b.searches) + len(b.clicks) }
+
+func (a *Analytics) flush(ctx context.Context, b *analyticsBatch) {
+	if len(b.searches) > 0 {
+		if err := a.repo.RecordSearches(ctx, b.searches); err != nil {
+			a.logger.Error("Failed to record searches",
+				zap.String("operation", "FlushSearches"),
+				zap.Int("searches", len(b.searches)),
+				zap.Error(err),
+			)
+		}
+		b.searches = b.searches[:0]
+	}
+	if len(b.clicks) > 0 {
+		if err := a.repo.RecordSearchClicks(ctx, b.clicks); err != nil {
+			a.logger.Error("Failed to record search clicks",
+				zap.String("operation", "FlushSearches"),
+				zap.Int("clicks", len(b.clicks)),
+				zap.Error(err),
+			)
+		}
+		b.clicks = b.clicks[:0]
+	}
+}
+
+func (a *Analytics) purge(ctx context.Context) {
+	n, err := a.repo.DeleteSearchLogsBefore(ctx, a.now().Add(-a.cfg.Retention))
+	if err != nil {
+		a.logger.Error("Failed to purge search logs", zap.String("operation", "PurgeSearchLogs"), zap.Error(err))
+		return
+	}
+	if n > 0 {
+		a.logger.Info("Purged search logs", zap.String("operation", "PurgeSearchLogs"), zap.Int("records", n))
+	}
+}
+
+// Report sums up the searches recorded since, listing up to limit of the
+// most frequent queries and of the most frequent finding nothing. Only
+// first pages count as searches; clicks on any page count.
+func (a *Analytics) Report(ctx context.Context, since time.Time, limit int) (*domain.SearchReport, error) {
+	if limit <= 0 || limit > MaxReportQueries {
+		limit = DefaultReportQueries
+	}
+	logs, err := a.repo.SearchesSince(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("load searches: %w", err)
+	}
+	clicks, err := a.repo.SearchClicksSince(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("load search clicks: %w", err)
+	}
+
+	report := &domain.SearchReport{Since: since, TopQueries: []domain.QueryStats{}, ZeroQueries: []domain.QueryStats{}}
+	byQuery := make(map[string]*domain.QueryStats)
+	queryOf := make(map[string]string, len(logs))
+	for _, l := range logs {
+		queryOf[l.ID] = l.Query
+		if l.Page != 1 {
+			continue
+		}
+		qs := byQuery[l.Query]
+		if qs == nil {
+			qs = &domain.QueryStats{Query: l.Query}
+			byQuery[l.Query] = qs
+		}
+		qs.Searches++
+		report.Searches++
+		if l.Results == 0 {
+			qs.ZeroResults++
+			report.ZeroResults++
+		}
+		if l.CreatedAt.After(qs.LastSearchedAt) {
+			qs.LastSearchedAt = l.CreatedAt
+		}
+	}
+	for _, c := range clicks {
+		qs := byQuery[queryOf[c.SearchID]]
+		if qs == nil {
+			continue
+		}
+		qs.Clicks++
+		qs.MeanClickPosition += float64(c.Position)
+		report.Clicks++
+	}
+	if report.Searches > 0 {
+		report.ZeroResultRate = float64(report.ZeroResults) / float64(report.Searches)
+	}
+
+	all := make([]domain.QueryStats, 0, len(byQuery))
+	for _, qs := range byQuery {
+		if qs.Clicks > 0 {
+			qs.MeanClickPosition /= float64(qs.Clicks)
+		}
+		qs.ClickRate = float64(qs.Clicks) / float64(qs.Searches)
+		all = append(all, *qs)
+	}
+	report.TopQueries = topQueries(all, limit, func(qs domain.QueryStats) int { return qs.Searches })
+	report.ZeroQueries = topQueries(all, limit, func(qs domain.QueryStats) int { return qs.ZeroResults })
+	return report, nil
+}
+
+// topQueries returns up to limit of stats with a positive count, highest
+// first, ties in query order.
+func topQueries(stats []domain.QueryStats, limit int, count func(domain.QueryStats) int) []domain.QueryStats {
+	out := []domain.QueryStats{}
+	for _, qs := range stats {
+		if count(qs) > 0 {
+			out = append(out, qs)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if a, b := count(out[i]), count(out[j]); a != b {
+			return a > b
+		}
+		return out[i].Query < out[j].Query
+	})
+	return out[:min(limit, len(out))]
+}
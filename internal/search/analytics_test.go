@@ -0,0 +1,68 @@
+package search
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestAnalytics_Report(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAnalytics_Report", "internal/search")
+
+	svc, store := newTestService(t)
+	a := NewAnalytics(AnalyticsConfig{}, store.SearchLogs(), logger)
+	svc.WithAnalytics(a)
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Searching, paging, clicking and finding nothing")
+	first, err := svc.Search(ctx, Query{Text: "Whey", PerPage: 1})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	second, _ := svc.Search(ctx, Query{Text: "whey", Page: 2, PerPage: 1})
+	if _, err := svc.Search(ctx, Query{Text: "whey"}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	for _, q := range []string{"casein", "casein", "mass gainer 9812345678"} {
+		if _, err := svc.Search(ctx, Query{Text: q}); err != nil {
+			t.Fatalf("Search %q: %v", q, err)
+		}
+	}
+	if first.SearchID == "" || second.SearchID == first.SearchID {
+		t.Fatalf("Search IDs %q, %q", first.SearchID, second.SearchID)
+	}
+	_ = a.Clicked(first.SearchID, testhelpers.FixtureProductID, 1)
+	_ = a.Clicked(second.SearchID, testhelpers.FixtureSecondProductID, 2)
+	if err := a.Clicked(first.SearchID, testhelpers.FixtureProductID, 0); err == nil {
+		t.Error("Click at position 0 accepted")
+	}
+	done, cancel := context.WithCancel(ctx)
+	cancel()
+	a.Run(done)
+
+	report, err := a.Report(ctx, time.Now().Add(-time.Hour*2), 0)
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "First pages count as searches; clicks on any page count")
+	testhelpers.LogTestAssertion(logger, "searches", 5, report.Searches)
+	if report.Searches != 5 || report.ZeroResults != 3 || report.ZeroResultRate != 0.6 || report.Clicks != 2 {
+		t.Errorf("Report totals = %+v", report)
+	}
+	top := report.TopQueries
+	if len(top) != 3 || top[0].Query != "casein" || top[1].Query != "whey" || top[2].Query != "mass gainer #" {
+		t.Fatalf("Top queries = %+v", top)
+	}
+	if w := top[1]; w.Searches != 2 || w.Clicks != 2 || w.ClickRate != 1 || w.MeanClickPosition != 1.5 {
+		t.Errorf("whey stats = %+v", w)
+	}
+	if z := report.ZeroQueries; len(z) != 2 || z[0].Query != "casein" || z[0].ZeroResults != 2 {
+		t.Errorf("Zero-result queries = %+v", z)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAnalytics_Report", true)
+}
@@ -0,0 +1,171 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/cache"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// DefaultCachePolicy keeps matches for half a minute and serves them for
+// another half while a popular query is refreshed. Prices are not cached
+// with them, so scrapes never make a cached search wrong.
+func DefaultCachePolicy() cache.Policy {
+	return cache.Policy{TTL: 30 * time.Second, StaleWhileRevalidate: 30 * time.Second}
+}
+
+// CachedIndex caches what an Index finds, so a popular query costs one
+// lookup per TTL however often it is searched, and concurrent misses on the
+// same query share that lookup when the cache coalesces them.
+//
+// An entry remembers the brands it matched or filtered by. When a product
+// of one of them changes in a way searches see, the entry is dropped on its
+// next read; brand moves, unnamed brands and synonym changes drop every
+// entry. A new product of a brand a query did not match yet shows up within
+// the TTL.
+//
+// The generations that decide this are kept in process, so the cache must
+// be too: a shared cache would mix entries judged by other processes.
+type CachedIndex struct {
+	index  Index
+	cache  cache.Cache
+	policy cache.Policy
+	logger *zap.Logger
+
+	mu       sync.Mutex
+	epoch    uint64            // part of every key; bumping it drops everything
+	brands   map[string]uint64 // brand ID to generation
+	synonyms *domain.Synonyms  // the groups the epoch was taken with
+}
+
+// cachedMatches is a cache entry.
+type cachedMatches struct {
+	Matches *domain.SearchMatches `json:"matches"`
+	// Brands holds the generation, when the search ran, of every brand
+	// whose products could change its matches.
+	Brands map[string]uint64 `json:"brands"`
+}
+
+// NewCachedIndex creates a CachedIndex keeping index's matches in c under
+// p. Subscribe its Handle to ProductUpdated events.
+func NewCachedIndex(index Index, c cache.Cache, p cache.Policy, logger *zap.Logger) *CachedIndex {
+	return &CachedIndex{index: index, cache: c, policy: p, logger: logger, brands: make(map[string]uint64)}
+}
+
+// SearchProducts implements Index.
+func (x *CachedIndex) SearchProducts(ctx context.Context, q repositories.ProductSearch) (*domain.SearchMatches, error) {
+	key := x.key(q)
+	load := func(ctx context.Context) (cachedMatches, error) {
+		// Generations are taken before the search, so a change landing
+		// during it leaves the entry already stale.
+		x.mu.Lock()
+		gens := maps.Clone(x.brands)
+		x.mu.Unlock()
+		m, err := x.index.SearchProducts(ctx, q)
+		if err != nil {
+			return cachedMatches{}, err
+		}
+		entry := cachedMatches{Matches: m, Brands: make(map[string]uint64)}
+		for _, id := range matchedBrands(q, m) {
+			entry.Brands[id] = gens[id]
+		}
+		return entry, nil
+	}
+
+	entry, err := cache.Fetch(ctx, x.cache, key, x.policy, x.logger, load)
+	if err == nil && !x.current(entry) {
+		x.logger.Debug("Dropping search matches of a changed brand",
+			zap.String("operation", "CachedSearch"),
+			zap.String("key", key),
+		)
+		if err := x.cache.Delete(ctx, key); err != nil {
+			x.logger.Warn("Failed to drop stale search matches", zap.String("operation", "CachedSearch"), zap.Error(err))
+		}
+		entry, err = cache.Fetch(ctx, x.cache, key, x.policy, x.logger, load)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entry.Matches, nil
+}
+
+// current reports whether none of entry's brands changed since it was
+// found.
+func (x *CachedIndex) current(entry cachedMatches) bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for id, gen := range entry.Brands {
+		if x.brands[id] != gen {
+			return false
+		}
+	}
+	return true
+}
+
+// key identifies q within the current epoch. Queries differing only in
+// case, punctuation or filter order share a key.
+func (x *CachedIndex) key(q repositories.ProductSearch) string {
+	x.mu.Lock()
+	if q.Synonyms != x.synonyms {
+		x.synonyms = q.Synonyms
+		x.epoch++
+	}
+	epoch := x.epoch
+	x.mu.Unlock()
+
+	brands := slices.Sorted(slices.Values(q.Filters.BrandIDs))
+	categories := slices.Sorted(slices.Values(q.Filters.CategoryIDs))
+	weights := slices.Sorted(slices.Values(q.Filters.Weights))
+	retailers := slices.Sorted(slices.Values(q.Filters.RetailerIDs))
+	dietary := slices.Sorted(slices.Values(q.Filters.Dietary))
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%q|%d|%t|%q|%q|%v|%q|%q",
+		strings.Join(domain.SearchTerms(q.Text), " "), q.Limit, q.Fuzzy, brands, categories, weights, retailers, dietary)
+	return "v1:search:" + strconv.FormatUint(epoch, 36) + ":" + strconv.FormatUint(h.Sum64(), 36)
+}
+
+// matchedBrands returns the brands whose products could change m: those
+// counted in its brand facet, which ignores the brand filter, and those
+// the query filters by, which may have matched nothing yet.
+func matchedBrands(q repositories.ProductSearch, m *domain.SearchMatches) []string {
+	ids := slices.Clone(q.Filters.BrandIDs)
+	for _, f := range m.Facets.Brands {
+		ids = append(ids, f.Value)
+	}
+	return ids
+}
+
+// EventTypes lists the events Handle reacts to.
+func (x *CachedIndex) EventTypes() []string {
+	return []string{domain.EventProductUpdated}
+}
+
+// Handle marks the searches matching e's brand stale if the product's
+// text, brand, variants or status changed. Price changes leave them alone.
+func (x *CachedIndex) Handle(_ context.Context, e domain.Event) error {
+	ev, ok := e.(domain.ProductUpdated)
+	if !ok || !ev.Changed(domain.ProductChangeText, domain.ProductChangeBrand, domain.ProductChangeVariants, domain.ProductChangeStatus) {
+		return nil
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	// A product leaving a brand is not named by the event, so every
+	// search it may have matched goes.
+	if ev.BrandID == "" || ev.Changed(domain.ProductChangeBrand) {
+		x.epoch++
+		return nil
+	}
+	x.brands[ev.BrandID]++
+	return nil
+}
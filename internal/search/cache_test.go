@@ -0,0 +1,95 @@
+package search
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/cache"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// countingIndex counts the searches reaching an Index.
+type countingIndex struct {
+	Index
+	searches int
+}
+
+func (c *countingIndex) SearchProducts(ctx context.Context, q repositories.ProductSearch) (*domain.SearchMatches, error) {
+	c.searches++
+	return c.Index.SearchProducts(ctx, q)
+}
+
+func TestCachedIndex(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCachedIndex", "internal/search")
+
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	inner := &countingIndex{Index: store.ProductSearch()}
+	idx := NewCachedIndex(inner, cache.NewTiered(cache.NewLRU(cache.DefaultLRUConfig()), nil), DefaultCachePolicy(), logger)
+	ctx := t.Context()
+	search := func(text string) *domain.SearchMatches {
+		t.Helper()
+		m, err := idx.SearchProducts(ctx, repositories.ProductSearch{Text: text, Limit: MaxCandidates})
+		if err != nil {
+			t.Fatalf("SearchProducts(%q): %v", text, err)
+		}
+		return m
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Repeating a query in another case")
+	first := search("gold standard")
+	again := search("Gold  STANDARD")
+
+	testhelpers.LogTestStep(logger, "assert", "The repeat is served from the cache")
+	testhelpers.LogTestAssertion(logger, "lookups", 1, inner.searches)
+	if inner.searches != 1 || again.Total != first.Total || first.Total != 1 {
+		t.Fatalf("Lookups = %d, totals %d and %d", inner.searches, first.Total, again.Total)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "A price drop, another brand's rename and a nutrition edit")
+	for _, e := range []domain.Event{
+		domain.PriceDropped{PriceChange: domain.PriceChange{ProductID: testhelpers.FixtureProductID}},
+		domain.ProductUpdated{ProductID: testhelpers.FixtureSecondProductID, BrandID: "muscleblaze", Changes: []string{domain.ProductChangeText}},
+		domain.ProductUpdated{ProductID: testhelpers.FixtureProductID, BrandID: "optimum-nutrition", Changes: []string{domain.ProductChangeNutrition}},
+	} {
+		_ = idx.Handle(ctx, e)
+	}
+	search("gold standard")
+
+	testhelpers.LogTestStep(logger, "assert", "None of them drops the matches")
+	testhelpers.LogTestAssertion(logger, "lookups", 1, inner.searches)
+	if inner.searches != 1 {
+		t.Errorf("Lookups = %d, want 1", inner.searches)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Deactivating a product of the matched brand")
+	p, _ := store.Products().FindByID(ctx, testhelpers.FixtureProductID)
+	p.IsActive = false
+	store.PutProduct(*p)
+	_ = idx.Handle(ctx, domain.ProductUpdated{ProductID: p.ID, BrandID: p.BrandID, Changes: []string{domain.ProductChangeStatus}})
+	gone := search("gold standard")
+
+	testhelpers.LogTestStep(logger, "assert", "The query is searched again and finds nothing")
+	testhelpers.LogTestAssertion(logger, "lookups", 2, inner.searches)
+	if inner.searches != 2 || gone.Total != 0 {
+		t.Errorf("Lookups = %d, total %d; want 2 and 0", inner.searches, gone.Total)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "A product moving brand")
+	search("whey")
+	_ = idx.Handle(ctx, domain.ProductUpdated{ProductID: testhelpers.FixtureSecondProductID, BrandID: "muscleblaze", Changes: []string{domain.ProductChangeBrand}})
+	search("whey")
+
+	testhelpers.LogTestStep(logger, "assert", "Every query is searched again")
+	testhelpers.LogTestAssertion(logger, "lookups", 4, inner.searches)
+	if inner.searches != 4 {
+		t.Errorf("Lookups = %d, want 4", inner.searches)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCachedIndex", true)
+}
@@ -0,0 +1,302 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Index finds the products matching a search. The catalog's own
+// ProductSearchRepository is the default; a dedicated search engine fed by
+// an IndexSync can stand in for it where the catalog outgrows searching
+// the database.
+type Index interface {
+	SearchProducts(ctx context.Context, q repositories.ProductSearch) (*domain.SearchMatches, error)
+}
+
+// Indexer is an Index kept apart from the catalog, holding a Document per
+// active product.
+type Indexer interface {
+	Index
+	// Upsert adds docs, replacing those with the same product IDs.
+	Upsert(ctx context.Context, docs []Document) error
+	// Delete removes the documents of productIDs; unknown IDs are ignored.
+	Delete(ctx context.Context, productIDs []string) error
+	// DocumentIDs returns the product ID of every document held.
+	DocumentIDs(ctx context.Context) ([]string, error)
+}
+
+// Document is what an Indexer searches and facets for one product.
+type Document struct {
+	ProductID   string
+	BrandID     string
+	Brand       string
+	CategoryID  string
+	Category    string
+	Name        string
+	Description string
+	// ProteinPerServing is in grams.
+	ProteinPerServing float64
+	// Weights are the pack sizes of the product's active variants, in
+	// grams, ascending.
+	Weights []int
+	// Retailers have a priced listing of the product.
+	Retailers []DocumentRetailer
+	// Dietary lists the product's domain.DietaryAttributes.
+	Dietary []string
+}
+
+// DocumentRetailer is a retailer selling a Document's product.
+type DocumentRetailer struct {
+	ID   string
+	Name string
+}
+
+// IndexRepos groups the repositories IndexSync builds documents from.
+type IndexRepos struct {
+	Products  repositories.ProductRepository
+	Listings  repositories.ListingRepository
+	Retailers repositories.RetailerRepository
+}
+
+// DefaultIndexFlushInterval is how long product changes are gathered
+// before their documents are rewritten, so a burst of edits costs one
+// write.
+const DefaultIndexFlushInterval = time.Second
+
+// IndexSync copies the catalog into an Indexer: every product on Reindex,
+// and in between only the products that ProductUpdated events report
+// changed in a way their documents show.
+type IndexSync struct {
+	index  Indexer
+	repos  IndexRepos
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	pending map[string]bool // product IDs
+}
+
+// NewIndexSync creates an IndexSync feeding index from repos.
+func NewIndexSync(index Indexer, repos IndexRepos, logger *zap.Logger) *IndexSync {
+	return &IndexSync{index: index, repos: repos, logger: logger, pending: make(map[string]bool)}
+}
+
+// EventTypes lists the events Handle reacts to.
+func (x *IndexSync) EventTypes() []string {
+	return []string{domain.EventProductUpdated}
+}
+
+// Handle queues the product e concerns for Flush if its text, nutrition,
+// brand, variants or status changed; price changes leave documents alone.
+func (x *IndexSync) Handle(_ context.Context, e domain.Event) error {
+	ev, ok := e.(domain.ProductUpdated)
+	if !ok || !ev.Changed(domain.ProductChangeText, domain.ProductChangeNutrition, domain.ProductChangeBrand,
+		domain.ProductChangeVariants, domain.ProductChangeStatus) {
+		return nil
+	}
+	x.mu.Lock()
+	x.pending[ev.ProductID] = true
+	x.mu.Unlock()
+	return nil
+}
+
+// Flush rewrites the documents of the products queued by Handle, deleting
+// those no longer active. Products it fails to write stay queued.
+func (x *IndexSync) Flush(ctx context.Context) error {
+	x.mu.Lock()
+	ids := make([]string, 0, len(x.pending))
+	for id := range x.pending {
+		ids = append(ids, id)
+	}
+	clear(x.pending)
+	x.mu.Unlock()
+	if len(ids) == 0 {
+		return nil
+	}
+	slices.Sort(ids)
+
+	err := x.update(ctx, ids)
+	if err != nil {
+		x.mu.Lock()
+		for _, id := range ids {
+			x.pending[id] = true
+		}
+		x.mu.Unlock()
+		x.logger.Error("Failed to update search documents",
+			zap.String("operation", "FlushIndex"),
+			zap.Int("products", len(ids)),
+			zap.Error(err),
+		)
+		return err
+	}
+	x.logger.Debug("Search documents updated", zap.String("operation", "FlushIndex"), zap.Int("products", len(ids)))
+	return nil
+}
+
+// update rewrites the documents of ids.
+func (x *IndexSync) update(ctx context.Context, ids []string) error {
+	found, err := x.repos.Products.FindByIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("load products: %w", err)
+	}
+	var active []domain.Product
+	var gone []string
+	for _, id := range ids {
+		if p, ok := found[id]; ok {
+			active = append(active, p)
+		} else {
+			gone = append(gone, id)
+		}
+	}
+	docs, err := x.Documents(ctx, active)
+	if err != nil {
+		return err
+	}
+	if err := x.index.Upsert(ctx, docs); err != nil {
+		return fmt.Errorf("upsert documents: %w", err)
+	}
+	if err := x.index.Delete(ctx, gone); err != nil {
+		return fmt.Errorf("delete documents: %w", err)
+	}
+	return nil
+}
+
+// Reindex upserts a document for every active product, a page at a time,
+// then deletes the documents of products no longer active. Searches keep
+// working throughout, against a mix of old and new documents.
+func (x *IndexSync) Reindex(ctx context.Context) error {
+	logger := x.logger.With(zap.String("operation", "Reindex"))
+	live := make(map[string]bool)
+	for offset := 0; ; offset += catalogPageSize {
+		page, err := x.repos.Products.List(ctx, repositories.ProductFilter{Limit: catalogPageSize, Offset: offset})
+		if err != nil {
+			logger.Error("Failed to list products", zap.Error(err))
+			return fmt.Errorf("list products: %w", err)
+		}
+		docs, err := x.Documents(ctx, page)
+		if err != nil {
+			logger.Error("Failed to build documents", zap.Error(err))
+			return err
+		}
+		if err := x.index.Upsert(ctx, docs); err != nil {
+			logger.Error("Failed to upsert documents", zap.Int("offset", offset), zap.Error(err))
+			return fmt.Errorf("upsert documents: %w", err)
+		}
+		for _, p := range page {
+			live[p.ID] = true
+		}
+		if len(page) < catalogPageSize {
+			break
+		}
+	}
+
+	held, err := x.index.DocumentIDs(ctx)
+	if err != nil {
+		logger.Error("Failed to list indexed documents", zap.Error(err))
+		return fmt.Errorf("list documents: %w", err)
+	}
+	var stale []string
+	for _, id := range held {
+		if !live[id] {
+			stale = append(stale, id)
+		}
+	}
+	if len(stale) > 0 {
+		if err := x.index.Delete(ctx, stale); err != nil {
+			logger.Error("Failed to delete stale documents", zap.Error(err))
+			return fmt.Errorf("delete documents: %w", err)
+		}
+	}
+	logger.Info("Search index rebuilt", zap.Int("products", len(live)), zap.Int("deleted", len(stale)))
+	return nil
+}
+
+// Documents builds the documents of products, reading their variants and
+// listings in one batch each.
+func (x *IndexSync) Documents(ctx context.Context, products []domain.Product) ([]Document, error) {
+	if len(products) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	variants, err := x.repos.Products.VariantsByProducts(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("load variants: %w", err)
+	}
+	listings, err := x.repos.Listings.ByProducts(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("load listings: %w", err)
+	}
+	retailers, err := x.repos.Retailers.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list retailers: %w", err)
+	}
+	names := make(map[string]string, len(retailers))
+	for _, r := range retailers {
+		names[r.ID] = r.Name
+	}
+
+	docs := make([]Document, 0, len(products))
+	for _, p := range products {
+		d := Document{
+			ProductID:         p.ID,
+			BrandID:           p.BrandID,
+			Brand:             p.Brand,
+			CategoryID:        p.CategoryID,
+			Category:          p.Category,
+			Name:              p.Name,
+			Description:       p.Description,
+			ProteinPerServing: p.ProteinPerServing,
+			Dietary:           p.Dietary,
+		}
+		for _, v := range variants[p.ID] {
+			if v.SizeGrams > 0 && !slices.Contains(d.Weights, v.SizeGrams) {
+				d.Weights = append(d.Weights, v.SizeGrams)
+			}
+		}
+		slices.Sort(d.Weights)
+		seen := make(map[string]bool)
+		for _, l := range listings[p.ID] {
+			if l.CurrentPrice <= 0 || seen[l.RetailerID] {
+				continue
+			}
+			seen[l.RetailerID] = true
+			name := names[l.RetailerID]
+			if name == "" {
+				name = l.RetailerID
+			}
+			d.Retailers = append(d.Retailers, DocumentRetailer{ID: l.RetailerID, Name: name})
+		}
+		docs = append(docs, d)
+	}
+	return docs, nil
+}
+
+// Run flushes queued changes every flush interval and reindexes every
+// product every reindex interval, as a backstop for changes no event
+// reported, until ctx is done.
+func (x *IndexSync) Run(ctx context.Context, flush, reindex time.Duration) {
+	flushes := time.NewTicker(flush)
+	defer flushes.Stop()
+	reindexes := time.NewTicker(reindex)
+	defer reindexes.Stop()
+	for {
+		select {
+		case <-flushes.C:
+			_ = x.Flush(ctx)
+		case <-reindexes.C:
+			_ = x.Reindex(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package search
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// fakeIndexer holds documents by product ID.
+type fakeIndexer struct{ docs map[string]Document }
+
+func (f *fakeIndexer) SearchProducts(context.Context, repositories.ProductSearch) (*domain.SearchMatches, error) {
+	return &domain.SearchMatches{}, nil
+}
+
+func (f *fakeIndexer) Upsert(_ context.Context, docs []Document) error {
+	for _, d := range docs {
+		f.docs[d.ProductID] = d
+	}
+	return nil
+}
+
+func (f *fakeIndexer) Delete(_ context.Context, ids []string) error {
+	for _, id := range ids {
+		delete(f.docs, id)
+	}
+	return nil
+}
+
+func (f *fakeIndexer) DocumentIDs(context.Context) ([]string, error) {
+	var ids []string
+	for id := range f.docs {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+func TestIndexSync_Reindex(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestIndexSync_Reindex", "internal/search")
+
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	idx := &fakeIndexer{docs: map[string]Document{"prod_gone": {ProductID: "prod_gone"}}}
+	sync := NewIndexSync(idx, IndexRepos{Products: store.Products(), Listings: store.Listings(), Retailers: store.Retailers()}, logger)
+
+	testhelpers.LogTestStep(logger, "act", "Reindexing the seeded catalog")
+	if err := sync.Reindex(t.Context()); err != nil {
+		t.Fatalf("Reindex: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Active products are indexed with their facets; stale documents go")
+	ids, _ := idx.DocumentIDs(t.Context())
+	testhelpers.LogTestAssertion(logger, "documents", 2, len(ids))
+	if !slices.Equal(ids, []string{testhelpers.FixtureSecondProductID, testhelpers.FixtureProductID}) {
+		t.Errorf("Documents = %v", ids)
+	}
+	d := idx.docs[testhelpers.FixtureProductID]
+	if d.Brand != "Optimum Nutrition" || !slices.Equal(d.Weights, []int{2270}) || len(d.Retailers) != 3 {
+		t.Errorf("Document = %+v", d)
+	}
+	for _, r := range d.Retailers {
+		if r.Name == "" || r.Name == r.ID {
+			t.Errorf("Retailer %+v is not named", r)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestIndexSync_Reindex", true)
+}
+
+func TestIndexSync_Flush(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestIndexSync_Flush", "internal/search")
+
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	idx := &fakeIndexer{docs: map[string]Document{}}
+	sync := NewIndexSync(idx, IndexRepos{Products: store.Products(), Listings: store.Listings(), Retailers: store.Retailers()}, logger)
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "A rename, a price drop and a deactivation")
+	p, _ := store.Products().FindByID(ctx, testhelpers.FixtureProductID)
+	p.Name = "Gold Standard Whey"
+	store.PutProduct(*p)
+	mb, _ := store.Products().FindByID(ctx, testhelpers.FixtureSecondProductID)
+	mb.IsActive = false
+	store.PutProduct(*mb)
+	idx.docs[mb.ID] = Document{ProductID: mb.ID}
+	events := []domain.Event{
+		domain.ProductUpdated{ProductID: p.ID, Changes: []string{domain.ProductChangeText}},
+		domain.PriceDropped{PriceChange: domain.PriceChange{ProductID: "prod_elsewhere"}},
+		domain.ProductUpdated{ProductID: mb.ID, Changes: []string{domain.ProductChangeStatus}},
+	}
+	for _, e := range events {
+		_ = sync.Handle(ctx, e)
+	}
+	if err := sync.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Only the changed products' documents are rewritten or deleted")
+	ids, _ := idx.DocumentIDs(ctx)
+	testhelpers.LogTestAssertion(logger, "documents", 1, len(ids))
+	if len(ids) != 1 || idx.docs[p.ID].Name != "Gold Standard Whey" {
+		t.Errorf("Documents = %+v", idx.docs)
+	}
+	idx.docs = map[string]Document{}
+	if err := sync.Flush(ctx); err != nil || len(idx.docs) != 0 {
+		t.Errorf("Second flush wrote %+v, %v; want nothing", idx.docs, err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestIndexSync_Flush", true)
+}
@@ -0,0 +1,389 @@
+// Package meilisearch is a search.Indexer backed by a Meilisearch
+// instance, for deployments whose catalogs outgrow searching the database.
+package meilisearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/search"
+)
+
+// Config configures the Meilisearch client.
+type Config struct {
+	// URL is the instance's root, e.g. http://localhost:7700.
+	URL string
+	// APIKey authorizes searching and writing documents; empty for an
+	// instance without a master key.
+	APIKey string
+	// Index is the UID of the products index.
+	Index   string
+	Timeout time.Duration
+}
+
+// The filterable attributes of a document, in the order of
+// domain.SearchFacets. Facets are counted on companion attributes holding
+// "value|label", so the counts carry their labels.
+var (
+	filterAttrs = [...]string{"brand_id", "category_id", "weights", "retailer_ids", "dietary"}
+	facetAttrs  = [...]string{"brand_facet", "category_facet", "weights", "retailer_facets", "dietary"}
+)
+
+// The facets labelled and ordered other than by their counts.
+const (
+	facetWeights = 2
+	facetDietary = 4
+)
+
+// facetSep separates a facet value from its label; IDs never contain it.
+const facetSep = "|"
+
+// documentPageSize is how many IDs DocumentIDs reads per request.
+const documentPageSize = 1000
+
+// Client searches and maintains a Meilisearch products index. Meilisearch
+// applies writes asynchronously, so they show in searches a moment after
+// they return.
+type Client struct {
+	cfg  Config
+	http *http.Client
+}
+
+// New creates a Client.
+func New(cfg Config) *Client {
+	if cfg.Index == "" {
+		cfg.Index = "products"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+	return &Client{cfg: cfg, http: &http.Client{Timeout: cfg.Timeout}}
+}
+
+// document is a search.Document as Meilisearch stores it.
+type document struct {
+	ID             string   `json:"id"`
+	Brand          string   `json:"brand"`
+	Name           string   `json:"name"`
+	Category       string   `json:"category"`
+	Description    string   `json:"description"`
+	Protein        float64  `json:"protein_per_serving"`
+	BrandID        string   `json:"brand_id"`
+	BrandFacet     string   `json:"brand_facet"`
+	CategoryID     string   `json:"category_id"`
+	CategoryFacet  string   `json:"category_facet"`
+	Weights        []int    `json:"weights"`
+	RetailerIDs    []string `json:"retailer_ids"`
+	RetailerFacets []string `json:"retailer_facets"`
+	Dietary        []string `json:"dietary"`
+}
+
+// Configure creates or updates the index's settings: matches in the brand
+// outrank the name, which outranks the category and description, as in
+// the database search, and every filter and facet is declared.
+func (c *Client) Configure(ctx context.Context) error {
+	settings := map[string]any{
+		"searchableAttributes": []string{"brand", "name", "category", "description"},
+		"filterableAttributes": append(filterAttrs[:], facetAttrs[:]...),
+		"displayedAttributes":  []string{"id"},
+		"faceting":             map[string]any{"maxValuesPerFacet": 500},
+	}
+	return c.do(ctx, http.MethodPatch, c.indexPath("/settings"), settings, nil)
+}
+
+// SearchProducts implements search.Index. Meilisearch forgives typos in
+// every search and applies its own synonyms setting, so q.Fuzzy and
+// q.Synonyms are ignored. The hits and each facet's counts, taken with
+// every filter but its own, are read in one multi-search request.
+func (c *Client) SearchProducts(ctx context.Context, q repositories.ProductSearch) (*domain.SearchMatches, error) {
+	matches := &domain.SearchMatches{}
+	if len(domain.SearchTerms(q.Text)) == 0 {
+		return matches, nil
+	}
+	filters := filterExprs(q.Filters)
+	queries := []map[string]any{{
+		"indexUid":             c.cfg.Index,
+		"q":                    q.Text,
+		"matchingStrategy":     "all",
+		"page":                 1,
+		"hitsPerPage":          max(q.Limit, 1),
+		"filter":               allExcept(filters, -1),
+		"attributesToRetrieve": []string{"id"},
+		"showRankingScore":     true,
+	}}
+	for i, attr := range facetAttrs {
+		queries = append(queries, map[string]any{
+			"indexUid":         c.cfg.Index,
+			"q":                q.Text,
+			"matchingStrategy": "all",
+			"limit":            0,
+			"filter":           allExcept(filters, i),
+			"facets":           []string{attr},
+		})
+	}
+	var resp struct {
+		Results []struct {
+			Hits []struct {
+				ID    string  `json:"id"`
+				Score float64 `json:"_rankingScore"`
+			} `json:"hits"`
+			TotalHits         int                       `json:"totalHits"`
+			FacetDistribution map[string]map[string]int `json:"facetDistribution"`
+		} `json:"results"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/multi-search", map[string]any{"queries": queries}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Results) != len(queries) {
+		return nil, fmt.Errorf("meilisearch returned %d results for %d queries", len(resp.Results), len(queries))
+	}
+
+	first := resp.Results[0]
+	for _, h := range first.Hits {
+		matches.Hits = append(matches.Hits, domain.SearchHit{ProductID: h.ID, Rank: h.Score})
+	}
+	matches.Total = first.TotalHits
+	selected := selectedValues(q.Filters)
+	var facets [len(facetAttrs)][]domain.FacetValue
+	for i, attr := range facetAttrs {
+		facets[i] = facetValues(resp.Results[i+1].FacetDistribution[attr], selected[i], i)
+	}
+	matches.Facets = domain.SearchFacets{
+		Brands:     facets[0],
+		Categories: facets[1],
+		Weights:    facets[facetWeights],
+		Retailers:  facets[3],
+		Dietary:    facets[facetDietary],
+	}
+	return matches, nil
+}
+
+// filterExprs returns a Meilisearch filter per filtered facet, in the
+// order of filterAttrs; unfiltered facets are empty. Dietary attributes
+// must all be present, other facets' values any one.
+func filterExprs(f domain.SearchFilters) [len(filterAttrs)]string {
+	weights := make([]string, len(f.Weights))
+	for i, g := range f.Weights {
+		weights[i] = strconv.Itoa(g)
+	}
+	var exprs [len(filterAttrs)]string
+	for i, values := range [][]string{quoteAll(f.BrandIDs), quoteAll(f.CategoryIDs), weights, quoteAll(f.RetailerIDs), quoteAll(f.Dietary)} {
+		switch {
+		case len(values) == 0:
+		case i == facetDietary:
+			for j, v := range values {
+				values[j] = filterAttrs[i] + " = " + v
+			}
+			exprs[i] = strings.Join(values, " AND ")
+		default:
+			exprs[i] = filterAttrs[i] + " IN [" + strings.Join(values, ", ") + "]"
+		}
+	}
+	return exprs
+}
+
+func quoteAll(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = strconv.Quote(v)
+	}
+	return out
+}
+
+// allExcept returns the filters but the one at skip, which may be -1, for
+// Meilisearch to AND together.
+func allExcept(exprs [len(filterAttrs)]string, skip int) []string {
+	out := []string{}
+	for i, e := range exprs {
+		if i != skip && e != "" {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// selectedValues returns the set of values filtered by, per facet.
+func selectedValues(f domain.SearchFilters) [len(facetAttrs)]map[string]bool {
+	set := func(values []string) map[string]bool {
+		m := make(map[string]bool, len(values))
+		for _, v := range values {
+			m[v] = true
+		}
+		return m
+	}
+	weights := make([]string, len(f.Weights))
+	for i, g := range f.Weights {
+		weights[i] = strconv.Itoa(g)
+	}
+	return [...]map[string]bool{set(f.BrandIDs), set(f.CategoryIDs), set(weights), set(f.RetailerIDs), set(f.Dietary)}
+}
+
+// facetValues turns the distribution of facet i into values ordered as
+// repositories.ProductSearchRepository documents: by count, then label, or
+// by size for weights, which the caller labels, or as
+// domain.DietaryAttributes for dietary attributes.
+func facetValues(dist map[string]int, selected map[string]bool, facet int) []domain.FacetValue {
+	out := []domain.FacetValue{}
+	for key, n := range dist {
+		value, label, _ := strings.Cut(key, facetSep)
+		switch facet {
+		case facetWeights:
+			label = ""
+		case facetDietary:
+			label = domain.DietaryLabel(value)
+		}
+		out = append(out, domain.FacetValue{Value: value, Label: label, Count: n, Selected: selected[value]})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if facet == facetDietary {
+			return slices.Index(domain.DietaryAttributes, a.Value) < slices.Index(domain.DietaryAttributes, b.Value)
+		}
+		if facet == facetWeights {
+			x, _ := strconv.Atoi(a.Value)
+			y, _ := strconv.Atoi(b.Value)
+			return x < y
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Label != b.Label {
+			return a.Label < b.Label
+		}
+		return a.Value < b.Value
+	})
+	return out
+}
+
+// Upsert implements search.Indexer.
+func (c *Client) Upsert(ctx context.Context, docs []search.Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	body := make([]document, len(docs))
+	for i, d := range docs {
+		md := document{
+			ID:             d.ProductID,
+			Brand:          d.Brand,
+			Name:           d.Name,
+			Category:       d.Category,
+			Description:    d.Description,
+			Protein:        d.ProteinPerServing,
+			BrandID:        d.BrandID,
+			BrandFacet:     d.BrandID + facetSep + d.Brand,
+			CategoryID:     d.CategoryID,
+			CategoryFacet:  d.CategoryID + facetSep + d.Category,
+			Weights:        d.Weights,
+			RetailerIDs:    []string{},
+			RetailerFacets: []string{},
+			Dietary:        d.Dietary,
+		}
+		if md.Weights == nil {
+			md.Weights = []int{}
+		}
+		if md.Dietary == nil {
+			md.Dietary = []string{}
+		}
+		for _, r := range d.Retailers {
+			md.RetailerIDs = append(md.RetailerIDs, r.ID)
+			md.RetailerFacets = append(md.RetailerFacets, r.ID+facetSep+r.Name)
+		}
+		body[i] = md
+	}
+	return c.do(ctx, http.MethodPost, c.indexPath("/documents?primaryKey=id"), body, nil)
+}
+
+// Delete implements search.Indexer.
+func (c *Client) Delete(ctx context.Context, productIDs []string) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+	return c.do(ctx, http.MethodPost, c.indexPath("/documents/delete-batch"), productIDs, nil)
+}
+
+// DocumentIDs implements search.Indexer.
+func (c *Client) DocumentIDs(ctx context.Context) ([]string, error) {
+	var ids []string
+	for offset := 0; ; offset += documentPageSize {
+		var page struct {
+			Results []struct {
+				ID string `json:"id"`
+			} `json:"results"`
+			Total int `json:"total"`
+		}
+		path := c.indexPath(fmt.Sprintf("/documents?fields=id&limit=%d&offset=%d", documentPageSize, offset))
+		if err := c.do(ctx, http.MethodGet, path, nil, &page); err != nil {
+			return nil, err
+		}
+		for _, r := range page.Results {
+			ids = append(ids, r.ID)
+		}
+		if len(page.Results) < documentPageSize {
+			return ids, nil
+		}
+	}
+}
+
+func (c *Client) indexPath(suffix string) string {
+	return "/indexes/" + url.PathEscape(c.cfg.Index) + suffix
+}
+
+// do sends a JSON request and decodes a JSON response into out, if set.
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.cfg.URL+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("meilisearch %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return fmt.Errorf("meilisearch %s %s: read response: %w", method, path, err)
+	}
+	if resp.StatusCode/100 != 2 {
+		var e struct {
+			Message string `json:"message"`
+			Code    string `json:"code"`
+		}
+		_ = json.Unmarshal(raw, &e)
+		return fmt.Errorf("meilisearch %s %s: status %d: %s (%s)", method, path, resp.StatusCode, e.Message, e.Code)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("meilisearch %s %s: decode response: %w", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package meilisearch
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/search"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestClient_SearchProducts(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestClient_SearchProducts", "internal/search/meilisearch")
+
+	var queries []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/multi-search" || r.Header.Get("Authorization") != "Bearer test-only-secret" {
+			t.Errorf("Request %s %s", r.Method, r.URL.Path)
+		}
+		var body struct {
+			Queries []map[string]any `json:"queries"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Decode: %v", err)
+		}
+		queries = body.Queries
+		_, _ = w.Write([]byte(`{"results":[
+			{"hits":[{"id":"prod_on_gsw","_rankingScore":0.95}],"totalHits":1},
+			{"hits":[],"facetDistribution":{"brand_facet":{"muscleblaze|MuscleBlaze":1,"optimum-nutrition|Optimum Nutrition":1}}},
+			{"hits":[],"facetDistribution":{"category_facet":{"whey|Whey Protein":1}}},
+			{"hits":[],"facetDistribution":{"weights":{"2270":1,"1000":2}}},
+			{"hits":[],"facetDistribution":{"retailer_facets":{"amazon|Amazon":1}}},
+			{"hits":[],"facetDistribution":{"dietary":{"sugar-free":1,"vegan":1}}}
+		]}`))
+	}))
+	defer srv.Close()
+
+	c := New(Config{URL: srv.URL + "/", APIKey: "test-only-secret"})
+	matches, err := c.SearchProducts(t.Context(), repositories.ProductSearch{
+		Text:    "whey",
+		Limit:   50,
+		Filters: domain.SearchFilters{BrandIDs: []string{"optimum-nutrition"}, Weights: []int{2270}, Dietary: []string{"vegan", "sugar-free"}},
+	})
+	if err != nil {
+		t.Fatalf("SearchProducts: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Hits and each facet are read in one request, facets without their own filter")
+	testhelpers.LogTestAssertion(logger, "queries", 6, len(queries))
+	if len(queries) != 6 {
+		t.Fatalf("Queries = %d, want 6", len(queries))
+	}
+	filter := func(i int) string {
+		var parts []string
+		for _, f := range queries[i]["filter"].([]any) {
+			parts = append(parts, f.(string))
+		}
+		return strings.Join(parts, " AND ")
+	}
+	diets := `dietary = "vegan" AND dietary = "sugar-free"`
+	if got := filter(0); got != `brand_id IN ["optimum-nutrition"] AND weights IN [2270] AND `+diets {
+		t.Errorf("Hits filter = %q", got)
+	}
+	if got := filter(1); got != `weights IN [2270] AND `+diets {
+		t.Errorf("Brand facet filter = %q", got)
+	}
+	if got := filter(3); got != `brand_id IN ["optimum-nutrition"] AND `+diets {
+		t.Errorf("Weight facet filter = %q", got)
+	}
+	if got := filter(5); got != `brand_id IN ["optimum-nutrition"] AND weights IN [2270]` {
+		t.Errorf("Dietary facet filter = %q", got)
+	}
+	if queries[0]["indexUid"] != "products" || queries[0]["hitsPerPage"] != float64(50) {
+		t.Errorf("Hits query = %v", queries[0])
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Responses map to matches and ordered, labelled facets")
+	if len(matches.Hits) != 1 || matches.Hits[0] != (domain.SearchHit{ProductID: "prod_on_gsw", Rank: 0.95}) || matches.Total != 1 {
+		t.Errorf("Hits = %+v, total %d", matches.Hits, matches.Total)
+	}
+	brands := matches.Facets.Brands
+	if len(brands) != 2 || brands[0].Label != "MuscleBlaze" || brands[1] != (domain.FacetValue{Value: "optimum-nutrition", Label: "Optimum Nutrition", Count: 1, Selected: true}) {
+		t.Errorf("Brand facets = %+v", brands)
+	}
+	weights := matches.Facets.Weights
+	if len(weights) != 2 || weights[0].Value != "1000" || weights[1] != (domain.FacetValue{Value: "2270", Count: 1, Selected: true}) {
+		t.Errorf("Weight facets = %+v", weights)
+	}
+	if r := matches.Facets.Retailers; len(r) != 1 || r[0].Label != "Amazon" {
+		t.Errorf("Retailer facets = %+v", r)
+	}
+	if d := matches.Facets.Dietary; len(d) != 2 || d[0] != (domain.FacetValue{Value: "vegan", Label: "Vegan", Count: 1, Selected: true}) || d[1].Value != "sugar-free" {
+		t.Errorf("Dietary facets = %+v", d)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestClient_SearchProducts", true)
+}
+
+func TestClient_Documents(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestClient_Documents", "internal/search/meilisearch")
+
+	var upserted []document
+	var deleted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/indexes/catalog/documents":
+			if r.URL.Query().Get("primaryKey") != "id" {
+				t.Errorf("Upsert query = %q", r.URL.RawQuery)
+			}
+			_ = json.NewDecoder(r.Body).Decode(&upserted)
+		case r.Method == http.MethodPost && r.URL.Path == "/indexes/catalog/documents/delete-batch":
+			_ = json.NewDecoder(r.Body).Decode(&deleted)
+		case r.Method == http.MethodGet && r.URL.Path == "/indexes/catalog/documents":
+			_, _ = w.Write([]byte(`{"results":[{"id":"prod_on_gsw"},{"id":"prod_gone"}],"total":2}`))
+			return
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Index not found","code":"index_not_found"}`))
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"taskUid":1,"status":"enqueued"}`))
+	}))
+	defer srv.Close()
+	c := New(Config{URL: srv.URL, Index: "catalog"})
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Upserting, listing and deleting documents")
+	err := c.Upsert(ctx, []search.Document{{
+		ProductID: "prod_on_gsw", BrandID: "optimum-nutrition", Brand: "Optimum Nutrition",
+		CategoryID: "whey", Category: "Whey Protein", Name: "Gold Standard 100% Whey",
+		Weights:   []int{2270},
+		Retailers: []search.DocumentRetailer{{ID: "amazon", Name: "Amazon"}},
+	}})
+	if err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	ids, err := c.DocumentIDs(ctx)
+	if err != nil {
+		t.Fatalf("DocumentIDs: %v", err)
+	}
+	if err := c.Delete(ctx, []string{"prod_gone"}); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Documents carry their facet labels")
+	if len(upserted) != 1 || upserted[0].BrandFacet != "optimum-nutrition|Optimum Nutrition" || upserted[0].RetailerFacets[0] != "amazon|Amazon" {
+		t.Errorf("Upserted = %+v", upserted)
+	}
+	if len(ids) != 2 || ids[1] != "prod_gone" || len(deleted) != 1 || deleted[0] != "prod_gone" {
+		t.Errorf("IDs = %v, deleted %v", ids, deleted)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Errors carry Meilisearch's message")
+	err = New(Config{URL: srv.URL, Index: "missing"}).Configure(ctx)
+	testhelpers.LogTestAssertion(logger, "configure error", true, err != nil)
+	if err == nil || !strings.Contains(err.Error(), "Index not found") {
+		t.Errorf("Configure error = %v", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestClient_Documents", true)
+}
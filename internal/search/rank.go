@@ -0,0 +1,115 @@
+package search
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/services"
+)
+
+// RerankDepth is how many of the best text matches ranking reorders; the
+// rest follow in text order. Reranking prices every one of them.
+const RerankDepth = 100
+
+// RankWeights blends the signals ranking orders results by. Each signal is
+// scaled to 0..1 across the candidates, so the weights compare directly.
+type RankWeights struct {
+	// Relevance weighs text relevance, relative to the best match.
+	Relevance float64
+	// Value weighs the percentile of the best price per gram of protein,
+	// the cheapest scoring 1.
+	Value float64
+	// Stock weighs being in stock at any retailer.
+	Stock float64
+	// Popularity weighs recent views, relative to the most viewed.
+	Popularity float64
+}
+
+// DefaultRankWeights keeps relevance first, so a query naming a product
+// finds it, while letting value and stock reorder generic queries whose
+// matches are about equally relevant.
+func DefaultRankWeights() RankWeights {
+	return RankWeights{Relevance: 2, Value: 0.6, Stock: 0.4, Popularity: 0.2}
+}
+
+// ParseRankWeights overrides w from a comma-separated list of name=weight
+// pairs, such as "value=1,popularity=0". Names are relevance, value, stock
+// and popularity; weights must not be negative.
+func ParseRankWeights(raw string, w RankWeights) (RankWeights, error) {
+	fields := map[string]*float64{
+		"relevance":  &w.Relevance,
+		"value":      &w.Value,
+		"stock":      &w.Stock,
+		"popularity": &w.Popularity,
+	}
+	for _, entry := range strings.Split(raw, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(entry, "=")
+		dst, ok := fields[strings.TrimSpace(name)]
+		if !ok {
+			return w, fmt.Errorf("rank weights: unknown signal %q", strings.TrimSpace(name))
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || f < 0 {
+			return w, fmt.Errorf("rank weights: %s must be a non-negative number, got %q", strings.TrimSpace(name), value)
+		}
+		*dst = f
+	}
+	return w, nil
+}
+
+// rerank orders comparisons, the first of hits in the same order, by
+// their blended score, most relevant first on ties.
+func rerank(hits []domain.SearchHit, comparisons map[string]*domain.Comparison, w RankWeights, pop *services.Popularity) []domain.SearchHit {
+	maxRank, maxViews := 0.0, 0.0
+	var perGram []float64
+	views := make(map[string]float64, len(hits))
+	for _, h := range hits {
+		maxRank = max(maxRank, h.Rank)
+		if pop != nil {
+			views[h.ProductID] = pop.Views(h.ProductID)
+			maxViews = max(maxViews, views[h.ProductID])
+		}
+		if c := comparisons[h.ProductID]; c != nil && c.BestDeal != nil && c.Stats.BestPricePerGram > 0 {
+			perGram = append(perGram, c.Stats.BestPricePerGram)
+		}
+	}
+	slices.Sort(perGram)
+
+	scores := make(map[string]float64, len(hits))
+	for _, h := range hits {
+		score := 0.0
+		if maxRank > 0 {
+			score += w.Relevance * h.Rank / maxRank
+		}
+		if maxViews > 0 {
+			score += w.Popularity * views[h.ProductID] / maxViews
+		}
+		if c := comparisons[h.ProductID]; c != nil && c.BestDeal != nil {
+			score += w.Stock
+			score += w.Value * valuePercentile(perGram, c.Stats.BestPricePerGram)
+		}
+		scores[h.ProductID] = score
+	}
+	out := slices.Clone(hits)
+	sort.SliceStable(out, func(i, j int) bool {
+		return scores[out[i].ProductID] > scores[out[j].ProductID]
+	})
+	return out
+}
+
+// valuePercentile returns the share of sorted that costs more per gram
+// than perGram: 1 for the cheapest, 0 for the dearest or an unknown price.
+func valuePercentile(sorted []float64, perGram float64) float64 {
+	if perGram <= 0 || len(sorted) < 2 {
+		return 0
+	}
+	dearer := len(sorted) - sort.SearchFloat64s(sorted, perGram+1e-9)
+	return float64(dearer) / float64(len(sorted)-1)
+}
@@ -0,0 +1,76 @@
+package search
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestParseRankWeights(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestParseRankWeights", "internal/search")
+
+	w, err := ParseRankWeights(" value=2, popularity=0 ", DefaultRankWeights())
+	want := DefaultRankWeights()
+	want.Value, want.Popularity = 2, 0
+	testhelpers.LogTestAssertion(logger, "weights", want, w)
+	if err != nil || w != want {
+		t.Errorf("ParseRankWeights = %+v, %v; want %+v", w, err, want)
+	}
+	for _, raw := range []string{"freshness=1", "value=-1", "stock=lots"} {
+		if _, err := ParseRankWeights(raw, DefaultRankWeights()); err == nil {
+			t.Errorf("ParseRankWeights(%q) accepted", raw)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestParseRankWeights", true)
+}
+
+func TestRerank(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestRerank", "internal/search")
+
+	inStock := func(id string, perGram float64) *domain.Comparison {
+		return &domain.Comparison{
+			Product:  domain.Product{ID: id},
+			BestDeal: &domain.Offer{InStock: true},
+			Stats:    domain.PriceStats{BestPricePerGram: perGram},
+		}
+	}
+	hits := []domain.SearchHit{{ProductID: "exact", Rank: 2}, {ProductID: "cheap", Rank: 1}, {ProductID: "dear", Rank: 1}, {ProductID: "gone", Rank: 1}}
+	comparisons := map[string]*domain.Comparison{
+		"exact": inStock("exact", 3),
+		"cheap": inStock("cheap", 1),
+		"dear":  inStock("dear", 2),
+		"gone":  {Product: domain.Product{ID: "gone"}},
+	}
+	pop := services.NewPopularity()
+	pop.Record("gone")
+
+	testhelpers.LogTestStep(logger, "assert", "Relevance leads; value, stock and views break near-ties")
+	order := func(hits []domain.SearchHit) []string {
+		var ids []string
+		for _, h := range hits {
+			ids = append(ids, h.ProductID)
+		}
+		return ids
+	}
+	got := order(rerank(hits, comparisons, DefaultRankWeights(), pop))
+	want := []string{"exact", "cheap", "dear", "gone"}
+	testhelpers.LogTestAssertion(logger, "default order", want, got)
+	if !slices.Equal(got, want) {
+		t.Errorf("Default order = %v, want %v", got, want)
+	}
+	got = order(rerank(hits, comparisons, RankWeights{Relevance: 1, Value: 2}, pop))
+	if want := []string{"cheap", "dear", "exact", "gone"}; !slices.Equal(got, want) {
+		t.Errorf("Value-heavy order = %v, want %v", got, want)
+	}
+	if hits[0].ProductID != "exact" {
+		t.Error("rerank reordered its input")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestRerank", true)
+}
@@ -0,0 +1,320 @@
+// Package search finds products by text and prices the matches for the
+// search endpoint.
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/flags"
+	"github.com/yourusername/whey-price-compare/internal/logctx"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/services"
+)
+
+// Paging limits.
+const (
+	DefaultPerPage = 20
+	MaxPerPage     = 100
+	// MaxCandidates bounds how many matches are paged through; a query
+	// matching more should be narrowed.
+	MaxCandidates = 500
+	// MaxFilterValues bounds how many values of one facet a search may
+	// select.
+	MaxFilterValues = domain.MaxFilterValues
+	// FewResults is how many exact matches a query may find and still be
+	// offered a correction finding more than twice as many.
+	FewResults = 3
+)
+
+// Query is one page of a search.
+type Query struct {
+	Text    string
+	Filters domain.SearchFilters
+	Page    int
+	PerPage int
+	// Sort is a domain result order; the default is relevance.
+	Sort string
+}
+
+// Service searches the catalog.
+type Service struct {
+	index      Index
+	prices     *services.PriceService
+	weights    *RankWeights
+	popularity *services.Popularity
+	flags      *flags.Flags
+	synonyms   *Synonyms
+	analytics  *Analytics
+	speller    *Suggester
+	corrector  *Suggester
+	logger     *zap.Logger
+}
+
+// NewService creates a Service reading matches from index and their
+// current offers from prices.
+func NewService(index Index, prices *services.PriceService, logger *zap.Logger) *Service {
+	return &Service{index: index, prices: prices, logger: logger}
+}
+
+// WithRanking orders the best RerankDepth matches by w's blend of
+// relevance, value for money, stock and popularity in p, which may be nil,
+// rather than by text relevance alone. It returns s.
+func (s *Service) WithRanking(w RankWeights, p *services.Popularity) *Service {
+	s.weights, s.popularity = &w, p
+	return s
+}
+
+// WithFlags rolls ranking out by the flags.SearchRerank flag in f. Queries
+// are bucketed by their text, so every page of a query, and everyone
+// searching it, is ranked alike. It returns s.
+func (s *Service) WithFlags(f *flags.Flags) *Service {
+	s.flags = f
+	return s
+}
+
+// WithSynonyms lets queries match the synonyms of their terms in syn. It
+// returns s.
+func (s *Service) WithSynonyms(syn *Synonyms) *Service {
+	s.synonyms = syn
+	return s
+}
+
+// WithAnalytics logs every search served in a, and gives each results
+// page the ID its clicks are logged under. It returns s.
+func (s *Service) WithAnalytics(a *Analytics) *Service {
+	s.analytics = a
+	return s
+}
+
+// WithRespelling searches again in the catalog's spelling, as sp.Respell
+// rewrites them, the queries that find nothing as typed, such as those in
+// Devanagari or romanized Hindi. It returns s.
+func (s *Service) WithRespelling(sp *Suggester) *Service {
+	s.speller = sp
+	return s
+}
+
+// WithCorrections suggests, for queries matching fewer than FewResults
+// products exactly, the query as sp.Correct fixes its typos, if that finds
+// many more. It returns s.
+func (s *Service) WithCorrections(sp *Suggester) *Service {
+	s.corrector = sp
+	return s
+}
+
+// Search returns the page of products matching q, most relevant first
+// unless q sorts them otherwise, and facet counts for narrowing it. If
+// nothing matches exactly, it searches again respelled, then forgiving
+// typos.
+//
+// Other orders are applied to every match before paging, so each page
+// continues the last. Matches with nothing in stock come after the rest.
+func (s *Service) Search(ctx context.Context, q Query) (*domain.SearchResults, error) {
+	q.Text = strings.TrimSpace(q.Text)
+	switch {
+	case len(domain.SearchTerms(q.Text)) == 0:
+		return nil, fmt.Errorf("q must contain at least one word: %w", domain.ErrInvalid)
+	case utf8.RuneCountInString(q.Text) > domain.MaxSearchQuery:
+		return nil, fmt.Errorf("q must be at most %d characters: %w", domain.MaxSearchQuery, domain.ErrInvalid)
+	case q.Page < 0 || q.PerPage < 0 || q.PerPage > MaxPerPage:
+		return nil, fmt.Errorf("page must be positive and per_page at most %d: %w", MaxPerPage, domain.ErrInvalid)
+	}
+	if err := q.Filters.Validate(); err != nil {
+		return nil, err
+	}
+	if q.Sort == "" {
+		q.Sort = domain.SortRelevance
+	}
+	if !domain.IsResultSort(q.Sort) {
+		return nil, fmt.Errorf("sort must be one of %s: %w", strings.Join(domain.ResultSorts, ", "), domain.ErrInvalid)
+	}
+	if q.Page == 0 {
+		q.Page = 1
+	}
+	if q.PerPage == 0 {
+		q.PerPage = DefaultPerPage
+	}
+	logger := s.logger.With(zap.String("operation", "Search"), zap.String("query", q.Text))
+
+	rq := repositories.ProductSearch{Text: q.Text, Limit: MaxCandidates, Filters: q.Filters}
+	if s.synonyms != nil {
+		rq.Synonyms = s.synonyms.Current()
+	}
+	matches, err := s.index.SearchProducts(ctx, rq)
+	if err != nil {
+		return nil, fmt.Errorf("search products: %w", err)
+	}
+	if !textMatched(matches) && s.speller != nil {
+		if text, ok := s.speller.Respell(q.Text); ok {
+			rq.Text = text
+			if matches, err = s.index.SearchProducts(ctx, rq); err != nil {
+				return nil, fmt.Errorf("search respelled products: %w", err)
+			}
+		}
+	}
+	exact := matches.Total
+	if !textMatched(matches) {
+		rq.Fuzzy = true
+		if matches, err = s.index.SearchProducts(ctx, rq); err != nil {
+			return nil, fmt.Errorf("fuzzy search products: %w", err)
+		}
+	}
+	hits := matches.Hits
+	results := &domain.SearchResults{
+		Fuzzy:      rq.Fuzzy && textMatched(matches),
+		Query:      q.Text,
+		Products:   []domain.SearchResult{},
+		TotalCount: matches.Total,
+		Page:       q.Page,
+		PerPage:    q.PerPage,
+		TotalPages: (len(hits) + q.PerPage - 1) / q.PerPage,
+		Filters:    q.Filters,
+		Facets:     matches.Facets,
+		Sort:       q.Sort,
+	}
+	if rq.Text != q.Text && textMatched(matches) {
+		results.SearchedAs = rq.Text
+	}
+	if s.corrector != nil && exact < FewResults {
+		results.DidYouMean = s.didYouMean(ctx, rq, exact)
+	}
+	if s.analytics != nil {
+		results.SearchID = s.analytics.Searched(q.Text, q.Page, results.TotalCount, results.Fuzzy)
+	}
+	start := (q.Page - 1) * q.PerPage
+	if start >= len(hits) {
+		return results, nil
+	}
+	comparisons := make(map[string]*domain.Comparison)
+	switch {
+	case q.Sort != domain.SortRelevance:
+		if err := s.peek(ctx, hits, comparisons); err != nil {
+			return nil, err
+		}
+		if hits, err = s.sortHits(ctx, hits, comparisons, q.Sort); err != nil {
+			return nil, err
+		}
+	case s.weights != nil && (s.flags == nil || s.flags.On(flags.SearchRerank, strings.ToLower(q.Text))):
+		head := hits[:min(RerankDepth, len(hits))]
+		if err := s.peek(ctx, head, comparisons); err != nil {
+			return nil, err
+		}
+		hits = append(rerank(head, comparisons, *s.weights, s.popularity), hits[len(head):]...)
+	}
+	page := hits[start:min(start+q.PerPage, len(hits))]
+	if err := s.peek(ctx, page, comparisons); err != nil {
+		return nil, err
+	}
+	for _, h := range page {
+		if c := comparisons[h.ProductID]; c != nil {
+			results.Products = append(results.Products, domain.NewSearchResult(*c))
+		}
+	}
+	logger.Debug("Search completed",
+		zap.Int("matches", len(hits)),
+		zap.Int("page", q.Page),
+		zap.String("sort", q.Sort),
+		zap.Bool("fuzzy", results.Fuzzy),
+	)
+	return results, nil
+}
+
+// sortHits orders hits by their best offers in comparisons, keeping
+// relevance order between equals and putting those without an offer last.
+func (s *Service) sortHits(ctx context.Context, hits []domain.SearchHit, comparisons map[string]*domain.Comparison, by string) ([]domain.SearchHit, error) {
+	var (
+		offered []*domain.Comparison
+		rest    []domain.SearchHit
+	)
+	byID := make(map[string]domain.SearchHit, len(hits))
+	for _, h := range hits {
+		byID[h.ProductID] = h
+		if c := comparisons[h.ProductID]; c != nil && c.BestDeal != nil {
+			offered = append(offered, c)
+		} else {
+			rest = append(rest, h)
+		}
+	}
+	deals := make([]domain.Deal, len(offered))
+	for i, c := range offered {
+		deals[i] = domain.Deal{Product: c.Product, Offer: *c.BestDeal}
+	}
+	if by == domain.SortDealScore {
+		scored, err := s.prices.ScoreDeals(ctx, offered)
+		if err != nil {
+			return nil, fmt.Errorf("score deals: %w", err)
+		}
+		for i, d := range scored {
+			if d != nil {
+				deals[i] = *d
+			}
+		}
+	}
+	domain.SortDeals(deals, by)
+	sorted := make([]domain.SearchHit, 0, len(hits))
+	for _, d := range deals {
+		sorted = append(sorted, byID[d.Product.ID])
+	}
+	return append(sorted, rest...), nil
+}
+
+// didYouMean returns rq's text with its typos corrected if that matches
+// more than twice the exact matches rq found, or "". Failures only cost
+// the suggestion.
+func (s *Service) didYouMean(ctx context.Context, rq repositories.ProductSearch, exact int) string {
+	text, ok := s.corrector.Correct(rq.Text)
+	if !ok {
+		return ""
+	}
+	rq.Text, rq.Fuzzy = text, false
+	m, err := s.index.SearchProducts(ctx, rq)
+	if err != nil {
+		logctx.Logger(ctx, s.logger).Warn("Failed to check a query correction",
+			zap.String("operation", "DidYouMean"),
+			zap.String("query", rq.Text),
+			zap.Error(err),
+		)
+		return ""
+	}
+	if m.Total <= 2*exact {
+		return ""
+	}
+	return text
+}
+
+// peek adds to comparisons those of hits it lacks; products no longer
+// listed stay absent.
+func (s *Service) peek(ctx context.Context, hits []domain.SearchHit, comparisons map[string]*domain.Comparison) error {
+	var ids []string
+	for _, h := range hits {
+		if _, ok := comparisons[h.ProductID]; !ok {
+			ids = append(ids, h.ProductID)
+			comparisons[h.ProductID] = nil
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	found, err := s.prices.Peek(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, c := range found {
+		comparisons[c.Product.ID] = c
+	}
+	return nil
+}
+
+// textMatched reports whether the text of a search matched any product,
+// whether or not the filters kept it: every match is counted under some
+// facet unless the filters of two facets both exclude it.
+func textMatched(m *domain.SearchMatches) bool {
+	f := m.Facets
+	return m.Total > 0 || len(f.Brands)+len(f.Categories)+len(f.Weights)+len(f.Retailers)+len(f.Dietary) > 0
+}
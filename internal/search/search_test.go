@@ -0,0 +1,186 @@
+package search
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/flags"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/services"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func newTestService(t *testing.T) (*Service, *memory.Store) {
+	t.Helper()
+	logger := testhelpers.SetupTestLogger(t)
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	prices := services.NewPriceService(services.PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+	return NewService(store.ProductSearch(), prices, logger), store
+}
+
+func TestService_Search(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_Search", "internal/search")
+
+	svc, _ := newTestService(t)
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Searching for whey, one result per page")
+	first, err := svc.Search(ctx, Query{Text: " whey ", PerPage: 1})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	second, err := svc.Search(ctx, Query{Text: "whey", Page: 2, PerPage: 1})
+	if err != nil {
+		t.Fatalf("Search page 2: %v", err)
+	}
+	past, _ := svc.Search(ctx, Query{Text: "whey", Page: 3, PerPage: 1})
+
+	testhelpers.LogTestStep(logger, "assert", "Pages carry priced products and totals")
+	testhelpers.LogTestAssertion(logger, "total", 2, first.TotalCount)
+	if first.TotalCount != 2 || first.TotalPages != 2 || first.Query != "whey" || len(first.Products) != 1 {
+		t.Fatalf("First page = %+v", first)
+	}
+	if len(second.Products) != 1 || second.Products[0].Product.ID == first.Products[0].Product.ID {
+		t.Errorf("Second page = %+v", second.Products)
+	}
+	for _, r := range append(first.Products, second.Products...) {
+		if r.Product.ID == testhelpers.FixtureProductID && (r.BestDeal == nil || r.BestDeal.Price != 3199 || r.RetailerCount != 3 || r.MaxPrice != 3499) {
+			t.Errorf("Fixture result = %+v", r)
+		}
+	}
+	if len(past.Products) != 0 || past.TotalCount != 2 {
+		t.Errorf("Page past the end = %+v", past)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Searching with typos")
+	typo, err := svc.Search(ctx, Query{Text: "optmum nutriton gold standrd"})
+	if err != nil {
+		t.Fatalf("Search with typos: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Typos fall back to a fuzzy match, flagged as such")
+	if !typo.Fuzzy || len(typo.Products) != 1 || typo.Products[0].Product.ID != testhelpers.FixtureProductID {
+		t.Errorf("Search with typos = %+v", typo)
+	}
+	if first.Fuzzy {
+		t.Error("Exact search flagged as fuzzy")
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Filtering by brand")
+	filtered, err := svc.Search(ctx, Query{Text: "whey", Filters: domain.SearchFilters{BrandIDs: []string{"muscleblaze"}}})
+	if err != nil {
+		t.Fatalf("Search with filters: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Filtered results keep the facet counts of the other brands")
+	if filtered.TotalCount != 1 || filtered.Products[0].Product.ID != testhelpers.FixtureSecondProductID ||
+		len(filtered.Facets.Brands) != 2 || filtered.Filters.BrandIDs[0] != "muscleblaze" || filtered.Fuzzy {
+		t.Errorf("Filtered search = %+v", filtered)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Empty, oversized and badly paged queries are rejected")
+	for _, q := range []Query{
+		{Text: " ?! "},
+		{Text: strings.Repeat("a", domain.MaxSearchQuery+1)},
+		{Text: "whey", PerPage: MaxPerPage + 1},
+		{Text: "whey", Page: -1},
+		{Text: "whey", Filters: domain.SearchFilters{Weights: []int{0}}},
+		{Text: "whey", Filters: domain.SearchFilters{BrandIDs: make([]string, MaxFilterValues+1)}},
+	} {
+		if _, err := svc.Search(ctx, q); !errors.Is(err, domain.ErrInvalid) {
+			t.Errorf("Search(%+v) err = %v, want ErrInvalid", q, err)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_Search", true)
+}
+
+func TestService_SearchRanking(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_SearchRanking", "internal/search")
+
+	svc, _ := newTestService(t)
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Searching a generic term with and without ranking")
+	plain, err := svc.Search(ctx, Query{Text: "whey"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	ranked, err := svc.WithRanking(DefaultRankWeights(), nil).Search(ctx, Query{Text: "whey"})
+	if err != nil {
+		t.Fatalf("Ranked search: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Equally relevant matches are ordered by protein per rupee")
+	if len(plain.Products) != 2 || plain.Products[0].Product.ID != testhelpers.FixtureSecondProductID {
+		t.Fatalf("Plain results = %+v, want text-rank ties in ID order", plain.Products)
+	}
+	testhelpers.LogTestAssertion(logger, "first ranked", testhelpers.FixtureProductID, ranked.Products[0].Product.ID)
+	if len(ranked.Products) != 2 || ranked.Products[0].Product.ID != testhelpers.FixtureProductID {
+		t.Errorf("Ranked results = %+v, want the cheaper protein first", ranked.Products)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Searching again with the ranking flag killed")
+	killed := flags.New(flags.Config{Defaults: []domain.Flag{{Name: flags.SearchRerank, Percent: 100}}}, nil, logger)
+	unranked, err := svc.WithFlags(killed).Search(ctx, Query{Text: "whey"})
+	if err != nil {
+		t.Fatalf("Flagged search: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Results are back in text order")
+	if len(unranked.Products) != 2 || unranked.Products[0].Product.ID != testhelpers.FixtureSecondProductID {
+		t.Errorf("Results with ranking off = %+v, want text order", unranked.Products)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_SearchRanking", true)
+}
+
+func TestService_SearchSorted(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_SearchSorted", "internal/search")
+
+	svc, _ := newTestService(t)
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Paging through whey by price per gram of protein")
+	first, err := svc.Search(ctx, Query{Text: "whey", PerPage: 1, Sort: domain.SortPricePerProtein})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	second, err := svc.Search(ctx, Query{Text: "whey", Page: 2, PerPage: 1, Sort: domain.SortPricePerProtein})
+	if err != nil {
+		t.Fatalf("Search page 2: %v", err)
+	}
+	scored, err := svc.Search(ctx, Query{Text: "whey", Sort: domain.SortDealScore})
+	if err != nil {
+		t.Fatalf("Search by deal score: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Every match is sorted before paging")
+	testhelpers.LogTestAssertion(logger, "first", testhelpers.FixtureProductID, first.Products[0].Product.ID)
+	if first.Sort != domain.SortPricePerProtein || first.Products[0].Product.ID != testhelpers.FixtureProductID {
+		t.Errorf("First page = %+v, want the cheaper protein", first)
+	}
+	if len(second.Products) != 1 || second.Products[0].Product.ID != testhelpers.FixtureSecondProductID {
+		t.Errorf("Second page = %+v", second.Products)
+	}
+	if len(scored.Products) != 2 || scored.Products[0].Product.ID != testhelpers.FixtureProductID {
+		t.Errorf("By deal score = %+v, want the fresh price drop first", scored.Products)
+	}
+	if _, err := svc.Search(ctx, Query{Text: "whey", Sort: "cheapest"}); !errors.Is(err, domain.ErrInvalid) {
+		t.Errorf("Unknown sort error = %v, want ErrInvalid", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_SearchSorted", true)
+}
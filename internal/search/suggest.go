@@ -0,0 +1,313 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Suggestion limits.
+const (
+	DefaultSuggestions = 8
+	MaxSuggestions     = 20
+	// SuggestBudget is how long a suggestion may take; type-ahead that
+	// lags the typing is worse than none.
+	SuggestBudget = 20 * time.Millisecond
+	// maxSuggestScan bounds the index entries one lookup reads, so a one
+	// letter prefix costs no more than a long one.
+	maxSuggestScan = 2000
+	// maxCachedPrefixes bounds the lookups an index remembers.
+	maxCachedPrefixes = 10000
+	catalogPageSize   = 500
+)
+
+// Suggester completes search queries from a prefix index of brand and
+// product names held in memory, so a lookup never waits on the database,
+// and respells queries in the catalog's words. Until the first Rebuild it
+// suggests nothing.
+type Suggester struct {
+	products repositories.ProductRepository
+	logger   *zap.Logger
+	index    atomic.Pointer[suggestIndex]
+	// stale is set by Handle when a product's suggestions change.
+	stale atomic.Bool
+}
+
+// NewSuggester creates an empty Suggester; call Rebuild to fill it.
+func NewSuggester(products repositories.ProductRepository, logger *zap.Logger) *Suggester {
+	return &Suggester{products: products, logger: logger}
+}
+
+// suggestEntry indexes a suggestion under one of its words and the words
+// after it, so "gold" completes "Optimum Nutrition Gold Standard".
+type suggestEntry struct {
+	key string
+	s   domain.Suggestion
+}
+
+// suggestIndex is a catalog's entries sorted by key, and the lookups
+// answered from it. Rebuilding replaces the whole index, cache included.
+type suggestIndex struct {
+	entries []suggestEntry
+	mu      sync.RWMutex
+	cached  map[string][]domain.Suggestion
+	// words counts the words of brands, names and categories, and
+	// spellings maps a phonetic key to the most used word with it.
+	words     map[string]int
+	spellings map[string]string
+}
+
+// Suggest returns up to limit completions of prefix, brands before
+// products, each in alphabetical order. Once ctx is done it returns what
+// it has found so far.
+func (s *Suggester) Suggest(ctx context.Context, prefix string, limit int) []domain.Suggestion {
+	if limit <= 0 || limit > MaxSuggestions {
+		limit = DefaultSuggestions
+	}
+	key := strings.Join(domain.SearchTerms(prefix), " ")
+	idx := s.index.Load()
+	if key == "" || idx == nil {
+		return []domain.Suggestion{}
+	}
+	cacheKey := fmt.Sprintf("%d\x00%s", limit, key)
+	idx.mu.RLock()
+	out, ok := idx.cached[cacheKey]
+	idx.mu.RUnlock()
+	if ok {
+		return out
+	}
+
+	var brands, products []domain.Suggestion
+	seen := make(map[domain.Suggestion]bool)
+	i := sort.Search(len(idx.entries), func(i int) bool { return idx.entries[i].key >= key })
+	for n := 0; i < len(idx.entries) && n < maxSuggestScan && len(brands) < limit; i, n = i+1, n+1 {
+		e := idx.entries[i]
+		if !strings.HasPrefix(e.key, key) {
+			break
+		}
+		if n%64 == 63 && ctx.Err() != nil {
+			return merge(brands, products, limit)
+		}
+		if seen[e.s] {
+			continue
+		}
+		seen[e.s] = true
+		if e.s.Kind == domain.SuggestBrand {
+			brands = append(brands, e.s)
+		} else {
+			products = append(products, e.s)
+		}
+	}
+	out = merge(brands, products, limit)
+
+	idx.mu.Lock()
+	if len(idx.cached) < maxCachedPrefixes {
+		idx.cached[cacheKey] = out
+	}
+	idx.mu.Unlock()
+	return out
+}
+
+// merge lists brands, then products, alphabetically, up to limit in all.
+func merge(brands, products []domain.Suggestion, limit int) []domain.Suggestion {
+	byText := func(s []domain.Suggestion) {
+		sort.Slice(s, func(i, j int) bool { return s[i].Text < s[j].Text })
+	}
+	byText(brands)
+	byText(products)
+	out := append(brands, products...)
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	if out == nil {
+		out = []domain.Suggestion{}
+	}
+	return out
+}
+
+// Rebuild indexes every active product and its brand, and swaps the new
+// index in.
+func (s *Suggester) Rebuild(ctx context.Context) error {
+	idx := &suggestIndex{cached: make(map[string][]domain.Suggestion), words: make(map[string]int)}
+	brands := make(map[string]bool)
+	count := 0
+	for offset := 0; ; offset += catalogPageSize {
+		page, err := s.products.List(ctx, repositories.ProductFilter{Limit: catalogPageSize, Offset: offset})
+		if err != nil {
+			s.logger.Error("Failed to rebuild suggestions",
+				zap.String("operation", "RebuildSuggestions"),
+				zap.Error(err),
+			)
+			return fmt.Errorf("list products: %w", err)
+		}
+		for _, p := range page {
+			if p.Brand != "" && !brands[p.BrandID] {
+				brands[p.BrandID] = true
+				idx.add(domain.Suggestion{Kind: domain.SuggestBrand, Text: p.Brand, ID: p.BrandID})
+			}
+			idx.add(domain.Suggestion{
+				Kind: domain.SuggestProduct,
+				Text: strings.TrimSpace(p.Brand + " " + p.Name),
+				ID:   p.ID,
+				Slug: p.Slug,
+			})
+			for _, w := range domain.SearchTerms(p.Brand + " " + p.Name + " " + p.Category) {
+				idx.words[w]++
+			}
+		}
+		count += len(page)
+		if len(page) < catalogPageSize {
+			break
+		}
+	}
+	sort.Slice(idx.entries, func(i, j int) bool { return idx.entries[i].key < idx.entries[j].key })
+	idx.spell()
+	s.index.Store(idx)
+	s.logger.Info("Suggestions rebuilt",
+		zap.String("operation", "RebuildSuggestions"),
+		zap.Int("products", count),
+		zap.Int("brands", len(brands)),
+		zap.Int("entries", len(idx.entries)),
+	)
+	return nil
+}
+
+// add indexes sg under each of its words with the words that follow.
+func (idx *suggestIndex) add(sg domain.Suggestion) {
+	terms := domain.SearchTerms(sg.Text)
+	for i := range terms {
+		idx.entries = append(idx.entries, suggestEntry{key: strings.Join(terms[i:], " "), s: sg})
+	}
+}
+
+// spell maps each phonetic key to its most used word, the shorter and
+// then the alphabetically first on a tie.
+func (idx *suggestIndex) spell() {
+	idx.spellings = make(map[string]string)
+	for w, n := range idx.words {
+		key := domain.PhoneticKey(w)
+		if key == "" {
+			continue
+		}
+		cur, ok := idx.spellings[key]
+		if !ok || n > idx.words[cur] || n == idx.words[cur] && (len(w) < len(cur) || len(w) == len(cur) && w < cur) {
+			idx.spellings[key] = w
+		}
+	}
+}
+
+// Respell rewrites text in the catalog's spelling, so queries typed in
+// Devanagari or romanized Hindi find what the English catalog calls the
+// same thing: each word the catalog does not use becomes the catalog word
+// sounding like it, or is transliterated if none does. It reports whether
+// any word changed.
+func (s *Suggester) Respell(text string) (string, bool) {
+	idx := s.index.Load()
+	if idx == nil {
+		return text, false
+	}
+	terms := domain.SearchTerms(text)
+	changed := false
+	for i, t := range terms {
+		if idx.words[t] > 0 {
+			continue
+		}
+		if w, ok := idx.spellings[domain.PhoneticKey(t)]; ok {
+			terms[i] = w
+		} else {
+			terms[i] = domain.Transliterate(t)
+		}
+		changed = changed || terms[i] != t
+	}
+	if !changed {
+		return text, false
+	}
+	return strings.Join(terms, " "), true
+}
+
+// Correct rewrites each word of text that is a typo of a catalog word,
+// such as "biozime" or "isolat", as that word: the closest one within
+// domain.MaxTypos edits that the catalog uses more often, the most used on
+// a tie. It reports whether any word changed.
+func (s *Suggester) Correct(text string) (string, bool) {
+	idx := s.index.Load()
+	if idx == nil {
+		return text, false
+	}
+	terms := domain.SearchTerms(text)
+	changed := false
+	for i, t := range terms {
+		maxTypos, runes := domain.MaxTypos(t), utf8.RuneCountInString(t)
+		best, bestDist := "", maxTypos+1
+		for w, n := range idx.words {
+			if n <= idx.words[t] || abs(utf8.RuneCountInString(w)-runes) > maxTypos {
+				continue
+			}
+			d := domain.EditDistance(w, t)
+			if d < bestDist || d == bestDist && best != "" && (n > idx.words[best] || n == idx.words[best] && w < best) {
+				best, bestDist = w, d
+			}
+		}
+		if best != "" {
+			terms[i], changed = best, true
+		}
+	}
+	if !changed {
+		return text, false
+	}
+	return strings.Join(terms, " "), true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// EventTypes lists the events Handle reacts to.
+func (s *Suggester) EventTypes() []string {
+	return []string{domain.EventProductUpdated}
+}
+
+// Handle marks the index stale when a product's name, brand or status
+// changed, so Run rebuilds it within DefaultIndexFlushInterval.
+func (s *Suggester) Handle(_ context.Context, e domain.Event) error {
+	if ev, ok := e.(domain.ProductUpdated); ok &&
+		ev.Changed(domain.ProductChangeText, domain.ProductChangeBrand, domain.ProductChangeStatus) {
+		s.stale.Store(true)
+	}
+	return nil
+}
+
+// Run rebuilds the index every interval, and soon after Handle marks it
+// stale, until ctx is done.
+func (s *Suggester) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	changes := time.NewTicker(DefaultIndexFlushInterval)
+	defer changes.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.stale.Store(false)
+			_ = s.Rebuild(ctx)
+		case <-changes.C:
+			if s.stale.Swap(false) {
+				_ = s.Rebuild(ctx)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package search
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestSuggester_Suggest(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSuggester_Suggest", "internal/search")
+
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	s := NewSuggester(store.Products(), logger)
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "assert", "Nothing is suggested before the index is built")
+	if got := s.Suggest(ctx, "opt", 0); len(got) != 0 {
+		t.Errorf("Suggestions before Rebuild = %+v", got)
+	}
+	if err := s.Rebuild(ctx); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Completing a brand, a word inside a name and a phrase")
+	brand := s.Suggest(ctx, "Opt", 0)
+	inner := s.Suggest(ctx, "gold st", 0)
+	whey := s.Suggest(ctx, "whe", 1)
+
+	testhelpers.LogTestStep(logger, "assert", "Brands come first, then products; the limit holds")
+	testhelpers.LogTestAssertion(logger, "brand suggestions", 2, len(brand))
+	if len(brand) != 2 || brand[0] != (domain.Suggestion{Kind: domain.SuggestBrand, Text: "Optimum Nutrition", ID: "optimum-nutrition"}) ||
+		brand[1].ID != testhelpers.FixtureProductID || brand[1].Text != "Optimum Nutrition Gold Standard 100% Whey" {
+		t.Errorf("Suggest(opt) = %+v", brand)
+	}
+	if len(inner) != 1 || inner[0].ID != testhelpers.FixtureProductID || inner[0].Slug != "gold-standard-100-whey" {
+		t.Errorf("Suggest(gold st) = %+v", inner)
+	}
+	if len(whey) != 1 || whey[0].Kind != domain.SuggestProduct || whey[0].Text != "MuscleBlaze Biozyme Performance Whey" {
+		t.Errorf("Suggest(whe, 1) = %+v", whey)
+	}
+	if got := s.Suggest(ctx, "casein", 0); len(got) != 0 {
+		t.Errorf("Suggest(casein) = %+v, want none", got)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Cached answers are served even past the budget")
+	done, cancel := context.WithCancel(ctx)
+	cancel()
+	if got := s.Suggest(done, "Opt", 0); len(got) != 2 {
+		t.Errorf("Cached Suggest(opt) = %+v", got)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSuggester_Suggest", true)
+}
+
+func TestSuggester_Handle(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSuggester_Handle", "internal/search")
+
+	s := NewSuggester(memory.NewStore().Products(), logger)
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Price and nutrition changes, then a rename")
+	_ = s.Handle(ctx, domain.PriceDropped{PriceChange: domain.PriceChange{ProductID: testhelpers.FixtureProductID}})
+	_ = s.Handle(ctx, domain.ProductUpdated{ProductID: testhelpers.FixtureProductID, Changes: []string{domain.ProductChangeNutrition}})
+	quiet := s.stale.Load()
+	_ = s.Handle(ctx, domain.ProductUpdated{ProductID: testhelpers.FixtureProductID, Changes: []string{domain.ProductChangeText}})
+
+	testhelpers.LogTestStep(logger, "assert", "Only the rename marks the index stale")
+	testhelpers.LogTestAssertion(logger, "stale", true, s.stale.Load())
+	if quiet || !s.stale.Load() {
+		t.Errorf("stale after unrelated changes = %v, after rename = %v", quiet, s.stale.Load())
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSuggester_Handle", true)
+}
+
+func TestService_SearchRespelled(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_SearchRespelled", "internal/search")
+
+	svc, store := newTestService(t)
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "assert", "A Hindi query finds nothing without respelling")
+	if res, err := svc.Search(ctx, Query{Text: "वे प्रोटीन"}); err != nil || res.TotalCount != 0 {
+		t.Fatalf("Search before respelling = %+v, %v", res, err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Respelling from the catalog's vocabulary")
+	sp := NewSuggester(store.Products(), logger)
+	if err := sp.Rebuild(ctx); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+	svc.WithRespelling(sp)
+
+	testhelpers.LogTestStep(logger, "assert", "Devanagari and romanized queries find the English catalog")
+	testCases := []struct {
+		query      string
+		searchedAs string
+		total      int
+	}{
+		{"वे प्रोटीन", "whey protein", 2},
+		{"vhey protein", "whey protein", 2},
+		{"ऑप्टिमम गोल्ड", "optimum gold", 1},
+		{"whey", "", 2},
+	}
+	for _, tc := range testCases {
+		res, err := svc.Search(ctx, Query{Text: tc.query})
+		if err != nil {
+			t.Fatalf("Search(%q): %v", tc.query, err)
+		}
+		testhelpers.LogTestAssertion(logger, tc.query, tc.searchedAs, res.SearchedAs)
+		if res.SearchedAs != tc.searchedAs || res.TotalCount != tc.total || res.Query != tc.query || res.Fuzzy {
+			t.Errorf("Search(%q) = searched as %q, %d matches, fuzzy %t; want %q and %d",
+				tc.query, res.SearchedAs, res.TotalCount, res.Fuzzy, tc.searchedAs, tc.total)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_SearchRespelled", true)
+}
+
+func TestService_SearchDidYouMean(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_SearchDidYouMean", "internal/search")
+
+	svc, store := newTestService(t)
+	ctx := t.Context()
+	sp := NewSuggester(store.Products(), logger)
+	if err := sp.Rebuild(ctx); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+	svc.WithCorrections(sp)
+
+	testhelpers.LogTestStep(logger, "assert", "Typos are corrected to catalog words; known words are kept")
+	if got, ok := sp.Correct("Gold Standerd biozime"); !ok || got != "gold standard biozyme" {
+		t.Errorf("Correct = %q, %t", got, ok)
+	}
+	if got, ok := sp.Correct("whey protein"); ok {
+		t.Errorf("Correct(whey protein) = %q, want it kept", got)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Queries finding few products are offered a correction finding more")
+	testCases := []struct {
+		query      string
+		didYouMean string
+	}{
+		{"biozime", "biozyme"},
+		{"gold standerd", "gold standard"},
+		{"whey", ""},
+		{"casein", ""},
+	}
+	for _, tc := range testCases {
+		res, err := svc.Search(ctx, Query{Text: tc.query})
+		if err != nil {
+			t.Fatalf("Search(%q): %v", tc.query, err)
+		}
+		testhelpers.LogTestAssertion(logger, tc.query, tc.didYouMean, res.DidYouMean)
+		if res.DidYouMean != tc.didYouMean {
+			t.Errorf("Search(%q) did you mean = %q, want %q", tc.query, res.DidYouMean, tc.didYouMean)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_SearchDidYouMean", true)
+}
@@ -0,0 +1,61 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Synonyms holds the synonym groups searches expand queries with, reloading
+// them when an admin changes one. Until the first Reload nothing is
+// expanded.
+type Synonyms struct {
+	repo    repositories.SynonymRepository
+	logger  *zap.Logger
+	current atomic.Pointer[domain.Synonyms]
+}
+
+// NewSynonyms creates an empty Synonyms; call Reload to fill it.
+func NewSynonyms(repo repositories.SynonymRepository, logger *zap.Logger) *Synonyms {
+	return &Synonyms{repo: repo, logger: logger}
+}
+
+// Current returns the groups to expand queries with, nil before the first
+// Reload.
+func (s *Synonyms) Current() *domain.Synonyms { return s.current.Load() }
+
+// Reload reads every group and swaps them in.
+func (s *Synonyms) Reload(ctx context.Context) error {
+	groups, err := s.repo.Synonyms(ctx)
+	if err != nil {
+		s.logger.Error("Failed to reload search synonyms",
+			zap.String("operation", "ReloadSynonyms"),
+			zap.Error(err),
+		)
+		return fmt.Errorf("list synonyms: %w", err)
+	}
+	s.current.Store(domain.NewSynonyms(groups))
+	s.logger.Info("Search synonyms reloaded",
+		zap.String("operation", "ReloadSynonyms"),
+		zap.Int("groups", len(groups)),
+	)
+	return nil
+}
+
+// EventTypes lists the events Handle understands, for subscribing.
+func (s *Synonyms) EventTypes() []string {
+	return []string{domain.EventSynonymsChanged}
+}
+
+// Handle reloads the groups after a change.
+func (s *Synonyms) Handle(ctx context.Context, e domain.Event) error {
+	if _, ok := e.(domain.SynonymsChanged); !ok {
+		return nil
+	}
+	return s.Reload(ctx)
+}
@@ -0,0 +1,44 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestService_SearchSynonyms(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestService_SearchSynonyms", "internal/search")
+
+	svc, store := newTestService(t)
+	ctx := t.Context()
+	synonyms := NewSynonyms(store.Synonyms(), logger)
+	svc.WithSynonyms(synonyms)
+
+	testhelpers.LogTestStep(logger, "assert", "An abbreviation finds nothing without synonyms")
+	if res, err := svc.Search(ctx, Query{Text: "ON gold"}); err != nil || res.TotalCount != 0 {
+		t.Fatalf("Search before synonyms = %+v, %v", res, err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Adding ON = Optimum Nutrition and announcing it")
+	if _, err := store.Synonyms().CreateSynonym(ctx, domain.Synonym{Terms: []string{"ON", "Optimum Nutrition"}}); err != nil {
+		t.Fatalf("CreateSynonym: %v", err)
+	}
+	if err := synonyms.Handle(ctx, domain.SynonymsChanged{OccurredAt: time.Now()}); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The abbreviation now finds the brand's product")
+	res, err := svc.Search(ctx, Query{Text: "ON gold"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	testhelpers.LogTestAssertion(logger, "matches", 1, res.TotalCount)
+	if res.TotalCount != 1 || res.Products[0].Product.ID != testhelpers.FixtureProductID || res.Fuzzy {
+		t.Errorf("Search with synonyms = %+v", res)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestService_SearchSynonyms", true)
+}
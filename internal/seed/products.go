@@ -0,0 +1,152 @@
+package seed
+
+// brand is a manufacturer in the demo catalog.
+type brand struct {
+	slug, name, country string
+}
+
+var brands = []brand{
+	{"optimum-nutrition", "Optimum Nutrition", "USA"},
+	{"muscleblaze", "MuscleBlaze", "India"},
+	{"myprotein", "MyProtein", "UK"},
+	{"dymatize", "Dymatize", "USA"},
+	{"the-whole-truth", "The Whole Truth", "India"},
+	{"as-it-is", "AS-IT-IS Nutrition", "India"},
+	{"avvatar", "Avvatar", "India"},
+	{"nakpro", "Nakpro", "India"},
+	{"isopure", "Isopure", "USA"},
+	{"bigmuscles", "Bigmuscles Nutrition", "India"},
+	{"oziva", "OZiva", "India"},
+	{"gnc", "GNC", "USA"},
+}
+
+// category is a product category; the slugs are those of the SQLite
+// schema's starter rows.
+type category struct {
+	slug, name string
+}
+
+var categories = []category{
+	{"whey-protein", "Whey Protein"},
+	{"whey-isolate", "Whey Isolate"},
+	{"whey-concentrate", "Whey Concentrate"},
+	{"casein-protein", "Casein Protein"},
+	{"plant-protein", "Plant Protein"},
+}
+
+// retailer is a store the demo catalog lists products at. Its url builds a
+// listing's product page from the product slug and the retailer's own
+// product ID.
+type retailer struct {
+	slug, name, website string
+	requestsPerMinute   int
+	url                 func(slug, id string) string
+	// id makes the retailer's product ID from a random number.
+	id func(n uint64) string
+}
+
+var retailers = []retailer{
+	{
+		slug: "amazon", name: "Amazon India", website: "https://www.amazon.in", requestsPerMinute: 15,
+		url: func(_, id string) string { return "https://www.amazon.in/dp/" + id },
+		id:  func(n uint64) string { return "B0" + base36(n, 8) },
+	},
+	{
+		slug: "flipkart", name: "Flipkart", website: "https://www.flipkart.com", requestsPerMinute: 12,
+		url: func(slug, id string) string { return "https://www.flipkart.com/" + slug + "/p/" + id },
+		id:  func(n uint64) string { return "itm" + base36(n, 13) },
+	},
+	{
+		slug: "healthkart", name: "HealthKart", website: "https://www.healthkart.com", requestsPerMinute: 10,
+		url: func(slug, id string) string { return "https://www.healthkart.com/sv/" + slug + "/" + id },
+		id:  func(n uint64) string { return "SP-" + decimal(n, 5) },
+	},
+	{
+		slug: "nutrabay", name: "Nutrabay", website: "https://nutrabay.com", requestsPerMinute: 8,
+		url: func(slug, _ string) string { return "https://nutrabay.com/product/" + slug },
+		id:  func(n uint64) string { return "NB" + decimal(n, 6) },
+	},
+	{
+		slug: "tata-1mg", name: "Tata 1mg", website: "https://www.1mg.com", requestsPerMinute: 8,
+		url: func(slug, id string) string { return "https://www.1mg.com/otc/" + slug + "-" + id },
+		id:  func(n uint64) string { return "otc" + decimal(n, 6) },
+	},
+}
+
+// pack is a pack size as retailers label it.
+type pack struct {
+	grams int
+	label string
+}
+
+var (
+	g500  = pack{500, "500 g"}
+	kg1   = pack{1000, "1 kg"}
+	kg2   = pack{2000, "2 kg"}
+	kg2_5 = pack{2500, "2.5 kg"}
+	kg4   = pack{4000, "4 kg"}
+	lb1_6 = pack{726, "1.6 lb"}
+	lb2   = pack{907, "2 lb"}
+	lb3   = pack{1360, "3 lb"}
+	lb4   = pack{1814, "4 lb"}
+	lb5   = pack{2270, "5 lb"}
+)
+
+// product is a product of the demo catalog. mrpPerKg is the MRP of a 1 kg
+// pack; larger packs cost less per kilogram.
+type product struct {
+	brand, category, name, description string
+	protein, serving                   float64
+	mrpPerKg                           float64
+	flavors                            []string
+	packs                              []pack
+}
+
+// products are protein powders sold in India, with their label claims and
+// roughly their MRPs.
+var products = []product{
+	{"optimum-nutrition", "whey-protein", "Gold Standard 100% Whey", "Whey isolate led blend with 5.5 g BCAAs per serving. Gluten free.",
+		24, 30.4, 3500, []string{"Double Rich Chocolate", "Vanilla Ice Cream", "Mocha Cappuccino"}, []pack{lb2, lb5}},
+	{"optimum-nutrition", "whey-isolate", "Gold Standard 100% Isolate", "Hydrolysed and isolated whey, under 1 g sugar and fat per serving.",
+		25, 31, 4800, []string{"Chocolate Bliss", "Vanilla"}, []pack{lb1_6, lb5}},
+	{"optimum-nutrition", "casein-protein", "Gold Standard 100% Casein", "Slow digesting micellar casein for overnight recovery.",
+		24, 34, 4000, []string{"Chocolate Supreme"}, []pack{lb2, lb4}},
+	{"muscleblaze", "whey-protein", "Biozyme Performance Whey", "Clinically tested enzyme blend for better protein absorption. Labdoor certified.",
+		25, 33, 3000, []string{"Rich Milk Chocolate", "Magical Mango", "Ice Cream Chocolate"}, []pack{kg1, kg2, kg4}},
+	{"muscleblaze", "whey-concentrate", "Raw Whey Protein Concentrate 80%", "Unflavoured whey concentrate with no added sugar.",
+		24, 30, 1800, []string{"Unflavoured"}, []pack{kg1, kg2}},
+	{"muscleblaze", "whey-isolate", "Biozyme Iso-Zero", "Zero sugar whey isolate with enhanced absorption.",
+		27, 34, 4000, []string{"Chocolate Hazelnut", "Cafe Mocha"}, []pack{kg1, kg2}},
+	{"myprotein", "whey-protein", "Impact Whey Protein", "Everyday whey with 21 g protein per serving.",
+		21, 25, 3000, []string{"Chocolate Smooth", "Vanilla", "Salted Caramel"}, []pack{kg1, kg2_5}},
+	{"myprotein", "whey-isolate", "Impact Whey Isolate", "90% protein isolate, low in fat and sugar.",
+		23, 25, 4200, []string{"Chocolate Smooth", "Strawberry Cream"}, []pack{kg1, kg2_5}},
+	{"myprotein", "plant-protein", "Vegan Protein Blend", "Plant based blend of pea, fava bean and pumpkin protein.",
+		22, 30, 2800, []string{"Chocolate", "Coffee Walnut"}, []pack{kg1, kg2_5}},
+	{"dymatize", "whey-isolate", "ISO100 Hydrolyzed", "Hydrolysed whey isolate, gluten free and lactose free.",
+		25, 30, 5500, []string{"Gourmet Chocolate", "Fudge Brownie"}, []pack{lb1_6, lb5}},
+	{"dymatize", "whey-protein", "Elite 100% Whey", "Whey blend with 25 g protein and 5 g BCAAs.",
+		25, 36, 3200, []string{"Rich Chocolate", "Gourmet Vanilla"}, []pack{lb2, lb5}},
+	{"dymatize", "casein-protein", "Elite Casein", "Micellar casein released over hours.",
+		25, 34, 3600, []string{"Rich Chocolate"}, []pack{lb2}},
+	{"the-whole-truth", "whey-protein", "Whey Protein", "Whey with no added sugar and no artificial sweeteners.",
+		24, 35, 3800, []string{"Coffee Cocoa", "Dark Chocolate"}, []pack{g500, kg1}},
+	{"as-it-is", "whey-concentrate", "Whey Protein Concentrate 80%", "Unflavoured raw whey, sugar free.",
+		24, 30, 1600, []string{"Unflavoured"}, []pack{kg1, kg2}},
+	{"as-it-is", "whey-isolate", "Whey Protein Isolate 90%", "Unflavoured raw whey isolate.",
+		27, 30, 2600, []string{"Unflavoured"}, []pack{kg1}},
+	{"avvatar", "whey-protein", "Whey Protein", "Made from fresh cow's milk in India.",
+		24, 32, 2800, []string{"Malai Kulfi", "Belgian Chocolate"}, []pack{kg1, kg2}},
+	{"avvatar", "whey-isolate", "Isorich Whey", "Isolate rich blend from fresh Indian milk.",
+		27, 32, 3400, []string{"Belgian Chocolate", "Coffee"}, []pack{kg1, kg2}},
+	{"nakpro", "whey-protein", "Perform Whey Protein", "Whey concentrate and isolate blend with digestive enzymes.",
+		24, 33, 1900, []string{"Chocolate", "Mango"}, []pack{kg1, kg2}},
+	{"isopure", "whey-isolate", "Zero Carb Protein", "100% whey isolate, zero carb and lactose free.",
+		25, 29, 5600, []string{"Dutch Chocolate", "Creamy Vanilla"}, []pack{lb3, lb5}},
+	{"bigmuscles", "whey-protein", "Premium Gold Whey", "Whey blend with added digestive enzymes.",
+		24, 33, 2000, []string{"Rich Chocolate", "Cafe Mocha"}, []pack{kg1, kg2}},
+	{"oziva", "plant-protein", "Protein & Herbs", "Vegan plant protein with ayurvedic herbs.",
+		23, 33, 3000, []string{"Chocolate"}, []pack{g500, kg1}},
+	{"gnc", "whey-protein", "Pro Performance 100% Whey", "Whey blend with 24 g protein per serving.",
+		24, 33, 3000, []string{"Double Rich Chocolate", "Mocha"}, []pack{lb2, lb4}},
+}
@@ -0,0 +1,58 @@
+package seed
+
+import "time"
+
+// sale is a run of days on which a store, or a brand at every store,
+// takes an extra cut off its everyday prices.
+type sale struct {
+	name string
+	// retailer and brand are slugs; empty matches every one.
+	retailer, brand string
+	// from and to are the first and last day, counted from the first day
+	// of history.
+	from, to int
+	cut      float64
+}
+
+// plan lays out the sales of the history's days: a festive sale at Amazon
+// and Flipkart about every two months, running together as they do, and a
+// brand day for most brands. HealthKart's weekend flash sales and
+// Nutrabay's payday sales follow the calendar and are applied by cut.
+func (g *generator) plan() []sale {
+	var sales []sale
+	for start := 10 + g.rng.IntN(30); start < g.days; start += 55 + g.rng.IntN(20) {
+		length := 4 + g.rng.IntN(3)
+		sales = append(sales,
+			sale{name: "Great Indian Festival", retailer: "amazon", from: start, to: start + length, cut: 0.12 + 0.1*g.rng.Float64()},
+			sale{name: "Big Billion Days", retailer: "flipkart", from: start + g.rng.IntN(3) - 1, to: start + length, cut: 0.12 + 0.1*g.rng.Float64()},
+		)
+	}
+	for _, b := range brands {
+		if g.rng.Float64() < 0.6 {
+			start := g.rng.IntN(g.days)
+			sales = append(sales, sale{name: b.name + " Day", brand: b.slug, from: start, to: start + 2, cut: 0.1})
+		}
+	}
+	return sales
+}
+
+// cut returns the fraction taken off plan's everyday price on date, the
+// day'th of the history, and whether any sale is on.
+func (g *generator) cut(plan listingPlan, date time.Time, day int) (float64, bool) {
+	keep, on := 1.0, false
+	for _, s := range g.sales {
+		if day < s.from || day > s.to ||
+			s.retailer != "" && s.retailer != plan.retailer ||
+			s.brand != "" && s.brand != plan.brand {
+			continue
+		}
+		keep, on = keep*(1-s.cut), true
+	}
+	switch {
+	case plan.retailer == "healthkart" && (date.Weekday() == time.Saturday || date.Weekday() == time.Sunday):
+		keep, on = keep*0.94, true
+	case plan.retailer == "nutrabay" && date.Day() <= 3:
+		keep, on = keep*0.92, true
+	}
+	return 1 - keep, on
+}
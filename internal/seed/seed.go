@@ -0,0 +1,343 @@
+// Package seed generates a realistic demo catalog for local development and
+// demo environments: the protein powders Indian retailers sell, in the
+// flavours and pack sizes they come in, listed at the big online stores,
+// with a price history that follows the sales those stores run.
+//
+// Generation is deterministic: the same Config gives the same catalog, IDs
+// included. IDs are UUIDs, so a catalog loads into the memory store and
+// into either SQL database alike.
+package seed
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// Config sizes a generated catalog.
+type Config struct {
+	// Products limits the catalog to the first this many of the built-in
+	// products; 0 keeps them all.
+	Products int
+	// Days is how many days of price history each listing gets, ending
+	// today.
+	Days int
+	// Seed picks the catalog; the same seed gives the same one.
+	Seed uint64
+	// Now is when the price history ends; zero means the current time.
+	Now time.Time
+}
+
+// DefaultConfig returns every built-in product with 90 days of history.
+func DefaultConfig() Config {
+	return Config{Days: 90, Seed: 1}
+}
+
+// Catalog is a generated catalog.
+type Catalog struct {
+	Brands     []domain.Brand
+	Categories []domain.Category
+	Retailers  []domain.Retailer
+	Products   []domain.Product
+	Variants   []domain.Variant
+	// Listings carry the price, stock and scrape time of their latest
+	// price point.
+	Listings []domain.Listing
+	// Prices holds every listing's history, oldest first within a listing.
+	Prices []domain.PricePoint
+}
+
+// CatalogWriter accepts a catalog; the memory store is one.
+type CatalogWriter interface {
+	PutProduct(p domain.Product)
+	PutVariant(v domain.Variant)
+	PutRetailer(r domain.Retailer)
+	PutListing(l domain.Listing)
+	AddPricePoint(p domain.PricePoint) domain.PricePoint
+}
+
+// Load writes c to w.
+func (c *Catalog) Load(w CatalogWriter) {
+	for _, r := range c.Retailers {
+		w.PutRetailer(r)
+	}
+	for _, p := range c.Products {
+		w.PutProduct(p)
+	}
+	for _, v := range c.Variants {
+		w.PutVariant(v)
+	}
+	for _, l := range c.Listings {
+		w.PutListing(l)
+	}
+	for _, p := range c.Prices {
+		w.AddPricePoint(p)
+	}
+}
+
+// Generate builds the catalog cfg describes.
+func Generate(cfg Config) *Catalog {
+	if cfg.Now.IsZero() {
+		cfg.Now = time.Now()
+	}
+	if cfg.Days <= 0 {
+		cfg.Days = DefaultConfig().Days
+	}
+	g := &generator{
+		rng:   rand.New(rand.NewPCG(cfg.Seed, 0x5eed)),
+		days:  cfg.Days,
+		now:   cfg.Now.UTC(),
+		first: cfg.Now.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1-cfg.Days),
+		c:     &Catalog{},
+	}
+	chosen := products
+	if cfg.Products > 0 && cfg.Products < len(chosen) {
+		chosen = chosen[:cfg.Products]
+	}
+	g.catalog(chosen)
+	g.sales = g.plan()
+	for i := range g.c.Listings {
+		g.history(&g.c.Listings[i])
+	}
+	return g.c
+}
+
+type generator struct {
+	rng   *rand.Rand
+	days  int
+	now   time.Time
+	first time.Time // midnight UTC of the first day of history
+	c     *Catalog
+	sales []sale
+
+	brandIDs    map[string]string // slug to ID
+	retailerIDs map[string]string
+	// listings remembers what history needs of each listing by ID.
+	listings map[string]listingPlan
+}
+
+// listingPlan is how a listing's price starts out.
+type listingPlan struct {
+	brand, retailer string
+	mrp, price      float64
+}
+
+// catalog adds the brands, categories and retailers, and chosen with their
+// variants and listings.
+func (g *generator) catalog(chosen []product) {
+	g.brandIDs = make(map[string]string)
+	g.retailerIDs = make(map[string]string)
+	g.listings = make(map[string]listingPlan)
+	brandNames := make(map[string]string)
+	for _, b := range brands {
+		id := g.uuid()
+		g.brandIDs[b.slug] = id
+		brandNames[b.slug] = b.name
+		g.c.Brands = append(g.c.Brands, domain.Brand{ID: id, Name: b.name, Slug: b.slug, Country: b.country, IsActive: true})
+	}
+	categoryIDs, categoryNames := make(map[string]string), make(map[string]string)
+	for _, c := range categories {
+		id := g.uuid()
+		categoryIDs[c.slug], categoryNames[c.slug] = id, c.name
+		g.c.Categories = append(g.c.Categories, domain.Category{ID: id, Name: c.name, Slug: c.slug})
+	}
+	for _, r := range retailers {
+		id := g.uuid()
+		g.retailerIDs[r.slug] = id
+		g.c.Retailers = append(g.c.Retailers, domain.Retailer{
+			ID: id, Name: r.name, Slug: r.slug, WebsiteURL: r.website, RequestsPerMinute: r.requestsPerMinute, IsActive: true,
+		})
+	}
+
+	for _, tmpl := range chosen {
+		created := g.first.AddDate(0, 0, -30-g.rng.IntN(335))
+		p := domain.Product{
+			ID:                   g.uuid(),
+			BrandID:              g.brandIDs[tmpl.brand],
+			Brand:                brandNames[tmpl.brand],
+			CategoryID:           categoryIDs[tmpl.category],
+			Category:             categoryNames[tmpl.category],
+			Name:                 tmpl.name,
+			Slug:                 slugify(tmpl.name),
+			Description:          tmpl.description,
+			ProteinPerServing:    tmpl.protein,
+			ServingsPerContainer: int(math.Round(float64(tmpl.packs[0].grams) / tmpl.serving)),
+			ServingSizeGrams:     tmpl.serving,
+			IsActive:             true,
+			CreatedAt:            created,
+			UpdatedAt:            created,
+		}
+		g.c.Products = append(g.c.Products, p)
+
+		for _, flavor := range tmpl.flavors {
+			for _, pk := range tmpl.packs {
+				v := domain.Variant{
+					ID:        g.uuid(),
+					ProductID: p.ID,
+					Flavor:    flavor,
+					Size:      pk.label,
+					SizeGrams: pk.grams,
+					SKU:       strings.ToUpper(tmpl.brand[:3]) + "-" + strings.ToUpper(slugify(flavor)) + "-" + strconv.Itoa(pk.grams),
+					IsActive:  true,
+				}
+				g.c.Variants = append(g.c.Variants, v)
+				g.list(tmpl, p, v)
+			}
+		}
+	}
+}
+
+// list lists v at two to four retailers, each discounting MRP its own way.
+func (g *generator) list(tmpl product, p domain.Product, v domain.Variant) {
+	kg := float64(v.SizeGrams) / 1000
+	// Bigger packs cost less per kilogram.
+	mrp := roundPrice(tmpl.mrpPerKg * kg * math.Pow(kg, -0.12))
+	slug := slugify(p.Brand + " " + p.Name + " " + v.Flavor + " " + v.Size)
+
+	order := g.rng.Perm(len(retailers))
+	for _, i := range order[:2+g.rng.IntN(3)] {
+		r := retailers[i]
+		rid := r.id(g.rng.Uint64())
+		l := domain.Listing{
+			ID:                g.uuid(),
+			VariantID:         v.ID,
+			RetailerID:        g.retailerIDs[r.slug],
+			RetailerProductID: rid,
+			URL:               r.url(slug, rid),
+			OriginalPrice:     mrp,
+			Currency:          domain.DefaultCurrency,
+			IsActive:          true,
+		}
+		g.c.Listings = append(g.c.Listings, l)
+		// Online stores sell protein 12-32% under MRP every day.
+		g.listings[l.ID] = listingPlan{
+			brand:    tmpl.brand,
+			retailer: r.slug,
+			mrp:      mrp,
+			price:    mrp * (1 - 0.12 - 0.2*g.rng.Float64()),
+		}
+	}
+}
+
+// history records l's prices for every day, scraping it once a day, or
+// every six hours on sale days, and sets its latest price from them.
+func (g *generator) history(l *domain.Listing) {
+	plan := g.listings[l.ID]
+	mrp, base := plan.mrp, plan.price
+	slot := time.Duration(g.rng.IntN(24*60)) * time.Minute
+
+	// A third of listings see the brand raise its MRP once, and the
+	// stores follow.
+	hike := -1
+	if g.rng.Float64() < 0.35 {
+		hike = g.rng.IntN(g.days)
+	}
+	var jitter, previous float64
+	outUntil := -1
+	for day := range g.days {
+		date := g.first.AddDate(0, 0, day)
+		if day == hike {
+			rise := 1.03 + 0.05*g.rng.Float64()
+			mrp, base = roundPrice(mrp*rise), base*rise
+		}
+		// Now and then a store matches a competitor, or stops matching.
+		switch r := g.rng.Float64(); {
+		case r < 0.06:
+			jitter = -0.03 + 0.05*g.rng.Float64()
+		case r < 0.10:
+			jitter = 0
+		}
+		cut, onSale := g.cut(plan, date, day)
+		// Deals sell out more often than everyday prices.
+		outOdds := 0.012
+		if onSale {
+			outOdds = 0.03
+		}
+		if day > outUntil && g.rng.Float64() < outOdds {
+			outUntil = day + g.rng.IntN(5)
+		}
+
+		price := min(roundPrice(base*(1+jitter)*(1-cut)), mrp)
+		scrapes, first := 1, slot
+		if onSale {
+			scrapes, first = 4, slot%(6*time.Hour)
+		}
+		for s := range scrapes {
+			at := date.Add(first + time.Duration(s)*6*time.Hour)
+			if at.After(g.now) {
+				continue
+			}
+			p := domain.PricePoint{
+				ID:            g.uuid(),
+				ListingID:     l.ID,
+				Price:         price,
+				PreviousPrice: previous,
+				Currency:      domain.DefaultCurrency,
+				InStock:       day > outUntil,
+				RecordedAt:    at,
+				Source:        "scraper",
+			}
+			g.c.Prices = append(g.c.Prices, p)
+			previous = price
+			if at.After(l.LastScrapedAt) {
+				l.CurrentPrice, l.InStock, l.LastScrapedAt = p.Price, p.InStock, at
+			}
+		}
+		l.OriginalPrice = mrp
+	}
+}
+
+// uuid returns a random version 4 UUID from the generator's source.
+func (g *generator) uuid() string {
+	hi, lo := g.rng.Uint64(), g.rng.Uint64()
+	hi = hi&^0xf000 | 0x4000
+	lo = lo&^(0xc<<60) | 0x8<<60
+	return fmt.Sprintf("%08x-%04x-%04x-%04x-%012x", hi>>32, hi>>16&0xffff, hi&0xffff, lo>>48, lo&0xffffffffffff)
+}
+
+// roundPrice rounds p to the nearest price ending in 49 or 99, the way
+// Indian stores price.
+func roundPrice(p float64) float64 {
+	return max(math.Round(p/50)*50-1, 99)
+}
+
+// slugify lowercases s and joins its words with hyphens.
+func slugify(s string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(s) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	return b.String()
+}
+
+// base36 formats n as width upper-case base-36 digits.
+func base36(n uint64, width int) string {
+	return strings.ToUpper(digits(strconv.FormatUint(n, 36), width))
+}
+
+// decimal formats n as width decimal digits.
+func decimal(n uint64, width int) string {
+	return digits(strconv.FormatUint(n, 10), width)
+}
+
+// digits keeps the last width characters of s, left-padding it with zeros.
+func digits(s string, width int) string {
+	if len(s) >= width {
+		return s[len(s)-width:]
+	}
+	return strings.Repeat("0", width-len(s)) + s
+}
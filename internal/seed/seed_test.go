@@ -0,0 +1,190 @@
+package seed_test
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/seed"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestGenerate(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestGenerate", "internal/seed")
+
+	now := time.Date(2026, 10, 16, 15, 30, 0, 0, time.UTC)
+	cfg := seed.Config{Days: 90, Seed: 7, Now: now}
+	c := seed.Generate(cfg)
+
+	testhelpers.LogTestStep(logger, "assert", "The same config gives the same catalog")
+	if !reflect.DeepEqual(c, seed.Generate(cfg)) {
+		t.Error("two catalogs generated from the same config differ")
+	}
+	cfg.Seed = 8
+	if reflect.DeepEqual(c.Prices, seed.Generate(cfg).Prices) {
+		t.Error("a different seed gave the same prices")
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Every reference resolves and every ID is a unique UUID")
+	ids := make(map[string]bool)
+	check := func(id string) {
+		if !uuidPattern.MatchString(id) || ids[id] {
+			t.Errorf("ID %q is not a UUID or is used twice", id)
+		}
+		ids[id] = true
+	}
+	for _, b := range c.Brands {
+		check(b.ID)
+	}
+	for _, r := range c.Retailers {
+		check(r.ID)
+	}
+	for _, p := range c.Products {
+		check(p.ID)
+		if !ids[p.BrandID] {
+			t.Errorf("product %s has unknown brand %s", p.Name, p.BrandID)
+		}
+	}
+	for _, v := range c.Variants {
+		check(v.ID)
+		if !ids[v.ProductID] {
+			t.Errorf("variant %s has unknown product %s", v.ID, v.ProductID)
+		}
+	}
+	for _, l := range c.Listings {
+		check(l.ID)
+		if !ids[l.VariantID] || !ids[l.RetailerID] {
+			t.Errorf("listing %s has an unknown variant or retailer", l.ID)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Listings end at their latest price, under MRP")
+	latest := make(map[string]time.Time)
+	for _, p := range c.Prices {
+		check(p.ID)
+		if p.RecordedAt.After(now) || p.RecordedAt.Before(now.AddDate(0, 0, -90)) {
+			t.Errorf("price point at %v is outside the 90 days before %v", p.RecordedAt, now)
+		}
+		if p.RecordedAt.Before(latest[p.ListingID]) {
+			t.Errorf("listing %s history is out of order", p.ListingID)
+		}
+		latest[p.ListingID] = p.RecordedAt
+	}
+	for _, l := range c.Listings {
+		if l.CurrentPrice <= 0 || l.CurrentPrice > l.OriginalPrice || !l.LastScrapedAt.Equal(latest[l.ID]) {
+			t.Errorf("listing %s: price %v, MRP %v, scraped %v; want its latest point under MRP",
+				l.ID, l.CurrentPrice, l.OriginalPrice, l.LastScrapedAt)
+		}
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Products limits the catalog")
+	small := seed.Generate(seed.Config{Products: 3, Days: 7, Seed: 7, Now: now})
+	testhelpers.LogTestAssertion(logger, "Products", 3, len(small.Products))
+	if len(small.Products) != 3 || len(small.Brands) != len(c.Brands) {
+		t.Errorf("Products: 3 gave %d products and %d brands", len(small.Products), len(small.Brands))
+	}
+
+	testhelpers.LogTestComplete(logger, "TestGenerate", true)
+}
+
+func TestGenerate_SalePatterns(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestGenerate_SalePatterns", "internal/seed")
+
+	now := time.Date(2026, 10, 16, 23, 59, 0, 0, time.UTC)
+	c := seed.Generate(seed.Config{Days: 90, Seed: 1, Now: now})
+
+	retailer := make(map[string]string)
+	for _, r := range c.Retailers {
+		retailer[r.ID] = r.Slug
+	}
+	listings := make(map[string]string)
+	mrp := make(map[string]float64)
+	for _, l := range c.Listings {
+		listings[l.ID] = retailer[l.RetailerID]
+		mrp[l.ID] = l.OriginalPrice
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "HealthKart is cheaper at weekends")
+	var weekend, weekday, weekendN, weekdayN float64
+	samples := make(map[string]int)
+	outOfStock := 0
+	for _, p := range c.Prices {
+		samples[p.ListingID+p.RecordedAt.Format(time.DateOnly)]++
+		if !p.InStock {
+			outOfStock++
+		}
+		if listings[p.ListingID] != "healthkart" {
+			continue
+		}
+		share := p.Price / mrp[p.ListingID]
+		if d := p.RecordedAt.Weekday(); d == time.Saturday || d == time.Sunday {
+			weekend, weekendN = weekend+share, weekendN+1
+		} else {
+			weekday, weekdayN = weekday+share, weekdayN+1
+		}
+	}
+	weekend, weekday = weekend/weekendN, weekday/weekdayN
+	testhelpers.LogTestAssertion(logger, "weekend below weekday", weekday, weekend)
+	if weekend >= weekday-0.02 {
+		t.Errorf("HealthKart weekend prices average %.3f of MRP, weekday %.3f; want weekends cheaper", weekend, weekday)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Sale days are scraped every six hours and deals sell out")
+	most := 0
+	for _, n := range samples {
+		most = max(most, n)
+	}
+	testhelpers.LogTestAssertion(logger, "most scrapes in a day", 4, most)
+	if most != 4 {
+		t.Errorf("most scrapes of a listing in a day = %d, want 4", most)
+	}
+	if outOfStock == 0 {
+		t.Error("no listing ever went out of stock")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestGenerate_SalePatterns", true)
+}
+
+func TestCatalog_Load(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCatalog_Load", "internal/seed")
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	c := seed.Generate(seed.Config{Products: 2, Days: 30, Seed: 3, Now: now})
+	store := memory.NewStore()
+	c.Load(store)
+
+	product := c.Products[0]
+	listings, err := store.Listings().ByProduct(t.Context(), product.ID)
+	if err != nil {
+		t.Fatalf("ByProduct: %v", err)
+	}
+	want := make(map[string]float64)
+	for _, l := range c.Listings {
+		want[l.ID] = l.CurrentPrice
+	}
+	testhelpers.LogTestAssertion(logger, "listings of the first product", "> 0", len(listings))
+	if len(listings) == 0 {
+		t.Fatal("the first product has no listings in the store")
+	}
+	for _, l := range listings {
+		if l.CurrentPrice != want[l.ID] {
+			t.Errorf("listing %s price = %v, want %v", l.ID, l.CurrentPrice, want[l.ID])
+		}
+	}
+	history, err := store.Prices().History(t.Context(), []string{listings[0].ID}, now.AddDate(0, 0, -30))
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(history) < 29 {
+		t.Errorf("listing history has %d points, want one a day for 30 days", len(history))
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCatalog_Load", true)
+}
@@ -0,0 +1,188 @@
+package seed
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/yourusername/whey-price-compare/internal/database"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// ErrCatalogNotEmpty is returned by Insert when the database already has
+// products and it was not asked to replace them.
+var ErrCatalogNotEmpty = errors.New("catalog already has products")
+
+// Insert writes c to db, a database of dialect d, in one transaction.
+// Brands, categories and retailers already there are kept and matched by
+// slug. With reset, the products, variants, listings and price history
+// already there are deleted first; without it, Insert refuses to add to
+// a catalog that has products.
+func (c *Catalog) Insert(ctx context.Context, db *sql.DB, d database.Dialect, reset bool) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("seed catalog: %w", err)
+	}
+	// Rolling back after Commit does nothing.
+	defer func() { _ = tx.Rollback() }()
+
+	if reset {
+		for _, table := range []string{"price_history", "product_listings", "product_variants", "products"} {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM "+table); err != nil {
+				return fmt.Errorf("seed catalog: clear %s: %w", table, err)
+			}
+		}
+	} else {
+		var n int
+		if err := tx.QueryRowContext(ctx, "SELECT count(*) FROM products").Scan(&n); err != nil {
+			return fmt.Errorf("seed catalog: %w", err)
+		}
+		if n > 0 {
+			return fmt.Errorf("seed catalog: %w (%d)", ErrCatalogNotEmpty, n)
+		}
+	}
+
+	// ids maps the catalog's brand, category and retailer IDs to those of
+	// rows already in the database.
+	ids := make(map[string]string)
+	id := func(v string) string { return cmp.Or(ids[v], v) }
+
+	brands, err := existing(ctx, tx, d, "brands", "")
+	if err != nil {
+		return err
+	}
+	var newBrands []domain.Brand
+	for _, b := range c.Brands {
+		if have, ok := brands[b.Slug]; ok {
+			ids[b.ID] = have
+			continue
+		}
+		newBrands = append(newBrands, b)
+	}
+	categories, err := existing(ctx, tx, d, "categories", " WHERE parent_id IS NULL")
+	if err != nil {
+		return err
+	}
+	var newCategories []domain.Category
+	for _, cat := range c.Categories {
+		if have, ok := categories[cat.Slug]; ok {
+			ids[cat.ID] = have
+			continue
+		}
+		newCategories = append(newCategories, cat)
+	}
+	retailers, err := existing(ctx, tx, d, "retailers", "")
+	if err != nil {
+		return err
+	}
+	var newRetailers []domain.Retailer
+	for _, r := range c.Retailers {
+		if have, ok := retailers[r.Slug]; ok {
+			ids[r.ID] = have
+			continue
+		}
+		newRetailers = append(newRetailers, r)
+	}
+
+	err = insert(ctx, tx, d, "brands (id, name, slug, country_origin, is_active)", newBrands,
+		func(b domain.Brand) []any { return []any{b.ID, b.Name, b.Slug, b.Country, b.IsActive} })
+	if err == nil {
+		err = insert(ctx, tx, d, "categories (id, name, slug)", newCategories,
+			func(c domain.Category) []any { return []any{c.ID, c.Name, c.Slug} })
+	}
+	if err == nil {
+		err = insert(ctx, tx, d, "retailers (id, name, slug, website_url, requests_per_minute, is_active)", newRetailers,
+			func(r domain.Retailer) []any {
+				return []any{r.ID, r.Name, r.Slug, r.WebsiteURL, r.RequestsPerMinute, r.IsActive}
+			})
+	}
+	if err == nil {
+		err = insert(ctx, tx, d, "products (id, brand_id, category_id, name, slug, description, "+
+			"protein_per_serving, servings_per_container, serving_size, manufacturer, is_active, created_at, updated_at)", c.Products,
+			func(p domain.Product) []any {
+				return []any{p.ID, id(p.BrandID), id(p.CategoryID), p.Name, p.Slug, p.Description,
+					p.ProteinPerServing, p.ServingsPerContainer, fmt.Sprintf("%gg", p.ServingSizeGrams), p.Brand, p.IsActive, p.CreatedAt, p.UpdatedAt}
+			})
+	}
+	if err == nil {
+		err = insert(ctx, tx, d, "product_variants (id, product_id, flavor, size, size_normalized_grams, sku, is_active)", c.Variants,
+			func(v domain.Variant) []any {
+				return []any{v.ID, v.ProductID, v.Flavor, v.Size, v.SizeGrams, v.SKU, v.IsActive}
+			})
+	}
+	if err == nil {
+		err = insert(ctx, tx, d, "product_listings (id, product_variant_id, retailer_id, retailer_product_id, retailer_url, "+
+			"current_price, currency, is_available, stock_status, last_scraped_at, is_active)", c.Listings,
+			func(l domain.Listing) []any {
+				status := "in_stock"
+				if !l.InStock {
+					status = "out_of_stock"
+				}
+				return []any{l.ID, l.VariantID, id(l.RetailerID), l.RetailerProductID, l.URL,
+					l.CurrentPrice, l.Currency, l.InStock, status, l.LastScrapedAt, l.IsActive}
+			})
+	}
+	if err == nil {
+		err = insert(ctx, tx, d, "price_history (id, product_listing_id, price, previous_price, currency, recorded_at, source, in_stock)", c.Prices,
+			func(p domain.PricePoint) []any {
+				var previous any
+				if p.PreviousPrice > 0 {
+					previous = p.PreviousPrice
+				}
+				return []any{p.ID, p.ListingID, p.Price, previous, p.Currency, p.RecordedAt, p.Source, p.InStock}
+			})
+	}
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("seed catalog: %w", err)
+	}
+	return nil
+}
+
+// existing returns the IDs of table's rows matching where by slug.
+func existing(ctx context.Context, tx *sql.Tx, d database.Dialect, table, where string) (map[string]string, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT "+d.Text("id")+", slug FROM "+table+where)
+	if err != nil {
+		return nil, fmt.Errorf("seed catalog: read %s: %w", table, err)
+	}
+	defer func() { _ = rows.Close() }()
+	out := make(map[string]string)
+	for rows.Next() {
+		var id, slug string
+		if err := rows.Scan(&id, &slug); err != nil {
+			return nil, fmt.Errorf("seed catalog: scan %s: %w", table, err)
+		}
+		out[slug] = id
+	}
+	return out, rows.Err()
+}
+
+// insert adds items to into, "table (columns)", as many rows a statement
+// at a time as d binds.
+func insert[T any](ctx context.Context, tx *sql.Tx, d database.Dialect, into string, items []T, row func(T) []any) error {
+	if len(items) == 0 {
+		return nil
+	}
+	columns := strings.Count(into, ",") + 1
+	for batch := range slices.Chunk(items, d.MaxArgs()/columns) {
+		args := make([]any, 0, len(batch)*columns)
+		for _, item := range batch {
+			args = append(args, row(item)...)
+		}
+		query, args := d.Build().
+			Append("INSERT INTO "+into+" VALUES ").
+			Values(len(batch), args...).
+			Query()
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			table, _, _ := strings.Cut(into, " ")
+			return fmt.Errorf("seed catalog: insert %s: %w", table, err)
+		}
+	}
+	return nil
+}
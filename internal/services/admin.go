@@ -0,0 +1,1028 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/events"
+	"github.com/yourusername/whey-price-compare/internal/images"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// MaxAuditPage caps how many audit entries one request may read.
+const MaxAuditPage = 200
+
+// ErrVersionRequired is returned for an edit that does not name the
+// version it was made to, which could silently overwrite a newer one.
+var ErrVersionRequired = errors.New("the edit must name the version it was made to")
+
+// AdminRepos groups the repositories AdminService writes to.
+type AdminRepos struct {
+	Catalog   repositories.CatalogAdminRepository
+	Selectors repositories.SelectorRepository
+	Prices    repositories.PriceWriter
+	Audit     repositories.AuditRepository
+	Synonyms  repositories.SynonymRepository
+	Flags     repositories.FlagRepository
+	Alerts    repositories.AlertRepository
+	// Tx runs each change as a unit of work with the repositories above.
+	// Without it their calls are not grouped.
+	Tx repositories.Transactor
+}
+
+// AdminProduct is the admin view of a product, exposing its active flag.
+type AdminProduct struct {
+	domain.Product
+	Active *bool `json:"active,omitempty"`
+}
+
+// AdminVariant is the admin view of a variant.
+type AdminVariant struct {
+	domain.Variant
+	Active *bool `json:"active,omitempty"`
+}
+
+// AdminRetailer is the admin view of a retailer, including scraper limits.
+type AdminRetailer struct {
+	domain.Retailer
+	RequestsPerMinute int `json:"requests_per_minute"`
+}
+
+// PriceCorrection manually overrides a listing's price.
+type PriceCorrection struct {
+	Price      float64    `json:"price"`
+	InStock    *bool      `json:"in_stock,omitempty"`
+	RecordedAt *time.Time `json:"recorded_at,omitempty"`
+	Reason     string     `json:"reason"`
+}
+
+// AdminService performs catalog changes on behalf of administrators. Every
+// attempt, successful or not, is written to the audit log.
+type AdminService struct {
+	repos    AdminRepos
+	relays   []*events.Relay
+	images   *images.Pipeline
+	priceLog repositories.PriceLogRepository
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewAdminService creates an AdminService.
+func NewAdminService(repos AdminRepos, logger *zap.Logger) *AdminService {
+	return &AdminService{repos: repos, logger: logger, now: time.Now}
+}
+
+// WithRelay publishes the events of successful changes through r as soon
+// as they are saved, so subscribers such as cache eviction have run by the
+// time a change is acknowledged. Without it they are left in the outbox
+// for r.Run. Call it once for each outbox the repositories write to. It
+// returns s.
+func (s *AdminService) WithRelay(r *events.Relay) *AdminService {
+	s.relays = append(s.relays, r)
+	return s
+}
+
+// WithImages lets IngestProductImage store product images through p. It
+// returns s.
+func (s *AdminService) WithImages(p *images.Pipeline) *AdminService {
+	s.images = p
+	return s
+}
+
+// ImagesEnabled reports whether product images can be ingested.
+func (s *AdminService) ImagesEnabled() bool {
+	return s.images != nil
+}
+
+// Product returns any product, active or not.
+func (s *AdminService) Product(ctx context.Context, id string) (*AdminProduct, error) {
+	p, err := s.repos.Catalog.Product(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return adminProduct(*p), nil
+}
+
+// CreateProduct adds a product. ID and slug are derived when omitted.
+func (s *AdminService) CreateProduct(ctx context.Context, actor domain.Actor, in AdminProduct) (out *AdminProduct, err error) {
+	p := in.Product
+	defer func() { s.audit(ctx, actor, "create_product", "product", p.ID, nil, out, err, nil) }()
+
+	if p.ID == "" {
+		p.ID = uuid.NewString()
+	}
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		if _, err := s.repos.Catalog.Product(ctx, p.ID); err == nil {
+			return fmt.Errorf("product %q already exists: %w", p.ID, domain.ErrConflict)
+		}
+		if p.Slug == "" {
+			p.Slug = slugify(p.Name)
+		}
+		if err := validateProduct(p); err != nil {
+			return err
+		}
+		p.IsActive = in.Active == nil || *in.Active
+		p.CreatedAt = s.now().UTC()
+		p.UpdatedAt = p.CreatedAt
+		p.Version = 0
+		if err := s.repos.Catalog.SaveProduct(ctx, p, s.productUpdated(p, domain.ProductChangeStatus)); err != nil {
+			return fmt.Errorf("save product: %w", err)
+		}
+		p.Version++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx)
+	return adminProduct(p), nil
+}
+
+// UpdateProduct replaces a product's editable fields. in.Version must be
+// the version edited: the save fails with domain.ErrConflict if another
+// came first.
+func (s *AdminService) UpdateProduct(ctx context.Context, actor domain.Actor, id string, in AdminProduct) (out *AdminProduct, err error) {
+	var before *AdminProduct
+	defer func() { s.audit(ctx, actor, "update_product", "product", id, before, out, err, nil) }()
+
+	if in.Version == 0 {
+		return nil, fmt.Errorf("product %q: %w", id, ErrVersionRequired)
+	}
+	p := in.Product
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		current, err := s.repos.Catalog.Product(ctx, id)
+		if err != nil {
+			return err
+		}
+		before = adminProduct(*current)
+
+		p.ID = id
+		p.CreatedAt = current.CreatedAt
+		p.DeletedAt = current.DeletedAt
+		p.IsActive = current.IsActive
+		if in.Active != nil {
+			p.IsActive = *in.Active
+		}
+		if p.Slug == "" {
+			p.Slug = current.Slug
+		}
+		if err := validateProduct(p); err != nil {
+			return err
+		}
+		p.UpdatedAt = s.now().UTC()
+		var updated []domain.Event
+		if changes := productChanges(*current, p); len(changes) > 0 {
+			updated = append(updated, s.productUpdated(p, changes...))
+		}
+		if err := s.repos.Catalog.SaveProduct(ctx, p, updated...); err != nil {
+			return fmt.Errorf("save product: %w", err)
+		}
+		p.Version++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx)
+	return adminProduct(p), nil
+}
+
+// IngestProductImage stores the image at sourceURL in every size and
+// points the product at it. The image is fetched before the product is
+// locked for the change, so a slow retailer holds no unit of work open.
+func (s *AdminService) IngestProductImage(ctx context.Context, actor domain.Actor, id, sourceURL string) (out *AdminProduct, err error) {
+	var before *AdminProduct
+	defer func() {
+		s.audit(ctx, actor, "ingest_product_image", "product", id, before, out, err, map[string]any{"source_url": sourceURL})
+	}()
+
+	if s.images == nil {
+		return nil, fmt.Errorf("product images are not configured: %w", domain.ErrInvalid)
+	}
+	if _, err := s.repos.Catalog.Product(ctx, id); err != nil {
+		return nil, err
+	}
+	img, err := s.images.Ingest(ctx, sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	var p domain.Product
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		current, err := s.repos.Catalog.Product(ctx, id)
+		if err != nil {
+			return err
+		}
+		before = adminProduct(*current)
+		p = *current
+		if p.ImageURL == img.URL {
+			return nil
+		}
+		p.ImageURL = img.URL
+		p.UpdatedAt = s.now().UTC()
+		if err := s.repos.Catalog.SaveProduct(ctx, p, s.productUpdated(p, domain.ProductChangeText)); err != nil {
+			return fmt.Errorf("save product: %w", err)
+		}
+		p.Version++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx)
+	return adminProduct(p), nil
+}
+
+// DeleteProduct soft-deletes a product: it leaves the catalog, variants
+// and listings with it, until RestoreProduct. Its history is kept.
+func (s *AdminService) DeleteProduct(ctx context.Context, actor domain.Actor, id string) (err error) {
+	var before, after *AdminProduct
+	defer func() { s.audit(ctx, actor, "delete_product", "product", id, before, after, err, nil) }()
+
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		p, err := s.repos.Catalog.Product(ctx, id)
+		if err != nil {
+			return err
+		}
+		if p.DeletedAt != nil {
+			return fmt.Errorf("product %q is already deleted: %w", id, domain.ErrNotFound)
+		}
+		before = adminProduct(*p)
+		now := s.now().UTC()
+		p.DeletedAt, p.UpdatedAt = &now, now
+		if err := s.repos.Catalog.SaveProduct(ctx, *p, s.productUpdated(*p, domain.ProductChangeStatus)); err != nil {
+			return err
+		}
+		p.Version++
+		after = adminProduct(*p)
+		return nil
+	})
+	if err != nil {
+		after = nil
+		return err
+	}
+	s.publish(ctx)
+	return nil
+}
+
+// RestoreProduct undoes DeleteProduct, returning the product to the
+// catalog as it was, active or not.
+func (s *AdminService) RestoreProduct(ctx context.Context, actor domain.Actor, id string) (out *AdminProduct, err error) {
+	var before *AdminProduct
+	defer func() { s.audit(ctx, actor, "restore_product", "product", id, before, out, err, nil) }()
+
+	var p *domain.Product
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		p, err = s.repos.Catalog.Product(ctx, id)
+		if err != nil {
+			return err
+		}
+		if p.DeletedAt == nil {
+			return fmt.Errorf("product %q is not deleted: %w", id, domain.ErrConflict)
+		}
+		before = adminProduct(*p)
+		p.DeletedAt, p.UpdatedAt = nil, s.now().UTC()
+		if err := s.repos.Catalog.SaveProduct(ctx, *p, s.productUpdated(*p, domain.ProductChangeStatus)); err != nil {
+			return err
+		}
+		p.Version++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx)
+	return adminProduct(*p), nil
+}
+
+// CreateVariant adds a variant to an existing product.
+func (s *AdminService) CreateVariant(ctx context.Context, actor domain.Actor, productID string, in AdminVariant) (out *AdminVariant, err error) {
+	v := in.Variant
+	defer func() { s.audit(ctx, actor, "create_variant", "variant", v.ID, nil, out, err, nil) }()
+
+	if v.ID == "" {
+		v.ID = uuid.NewString()
+	}
+	v.ProductID = productID
+	v.IsActive = in.Active == nil || *in.Active
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		p, err := s.repos.Catalog.Product(ctx, productID)
+		if err != nil {
+			return err
+		}
+		if p.DeletedAt != nil {
+			return fmt.Errorf("product %q is deleted: %w", productID, domain.ErrNotFound)
+		}
+		if _, err := s.repos.Catalog.Variant(ctx, v.ID); err == nil {
+			return fmt.Errorf("variant %q already exists: %w", v.ID, domain.ErrConflict)
+		}
+		if err := validateVariant(v); err != nil {
+			return err
+		}
+		v.Version = 0
+		if err := s.repos.Catalog.SaveVariant(ctx, v, s.productUpdated(*p, domain.ProductChangeVariants)); err != nil {
+			return fmt.Errorf("save variant: %w", err)
+		}
+		v.Version++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx)
+	return adminVariant(v), nil
+}
+
+// UpdateVariant replaces a variant's editable fields. The parent product
+// cannot be changed. in.Version must be the version edited, as for
+// UpdateProduct.
+func (s *AdminService) UpdateVariant(ctx context.Context, actor domain.Actor, id string, in AdminVariant) (out *AdminVariant, err error) {
+	var before *AdminVariant
+	defer func() { s.audit(ctx, actor, "update_variant", "variant", id, before, out, err, nil) }()
+
+	if in.Version == 0 {
+		return nil, fmt.Errorf("variant %q: %w", id, ErrVersionRequired)
+	}
+	v := in.Variant
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		current, err := s.repos.Catalog.Variant(ctx, id)
+		if err != nil {
+			return err
+		}
+		before = adminVariant(*current)
+
+		v.ID = id
+		v.ProductID = current.ProductID
+		v.IsActive = current.IsActive
+		v.DeletedAt = current.DeletedAt
+		if in.Active != nil {
+			v.IsActive = *in.Active
+		}
+		if err := validateVariant(v); err != nil {
+			return err
+		}
+		if err := s.repos.Catalog.SaveVariant(ctx, v, s.variantsUpdated(ctx, v.ProductID)); err != nil {
+			return fmt.Errorf("save variant: %w", err)
+		}
+		v.Version++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx)
+	return adminVariant(v), nil
+}
+
+// DeleteVariant soft-deletes a variant, and its listings with it, until
+// RestoreVariant.
+func (s *AdminService) DeleteVariant(ctx context.Context, actor domain.Actor, id string) (err error) {
+	var before, after *AdminVariant
+	defer func() { s.audit(ctx, actor, "delete_variant", "variant", id, before, after, err, nil) }()
+
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		v, err := s.repos.Catalog.Variant(ctx, id)
+		if err != nil {
+			return err
+		}
+		if v.DeletedAt != nil {
+			return fmt.Errorf("variant %q is already deleted: %w", id, domain.ErrNotFound)
+		}
+		before = adminVariant(*v)
+		now := s.now().UTC()
+		v.DeletedAt = &now
+		if err := s.repos.Catalog.SaveVariant(ctx, *v, s.variantsUpdated(ctx, v.ProductID)); err != nil {
+			return err
+		}
+		v.Version++
+		after = adminVariant(*v)
+		return nil
+	})
+	if err != nil {
+		after = nil
+		return err
+	}
+	s.publish(ctx)
+	return nil
+}
+
+// RestoreVariant undoes DeleteVariant. A variant of a deleted product
+// stays hidden until the product is restored too.
+func (s *AdminService) RestoreVariant(ctx context.Context, actor domain.Actor, id string) (out *AdminVariant, err error) {
+	var before *AdminVariant
+	defer func() { s.audit(ctx, actor, "restore_variant", "variant", id, before, out, err, nil) }()
+
+	var v *domain.Variant
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		v, err = s.repos.Catalog.Variant(ctx, id)
+		if err != nil {
+			return err
+		}
+		if v.DeletedAt == nil {
+			return fmt.Errorf("variant %q is not deleted: %w", id, domain.ErrConflict)
+		}
+		before = adminVariant(*v)
+		v.DeletedAt = nil
+		if err := s.repos.Catalog.SaveVariant(ctx, *v, s.variantsUpdated(ctx, v.ProductID)); err != nil {
+			return err
+		}
+		v.Version++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx)
+	return adminVariant(*v), nil
+}
+
+// DeletedAlerts returns the alerts userID deleted, most recently deleted
+// first, so one can be restored for them.
+func (s *AdminService) DeletedAlerts(ctx context.Context, userID string) ([]domain.PriceAlert, error) {
+	return s.repos.Alerts.DeletedAlerts(ctx, userID)
+}
+
+// RestoreAlert undoes the deletion of a user's alert, which comes back as
+// it was, fired or not.
+func (s *AdminService) RestoreAlert(ctx context.Context, actor domain.Actor, id string) (out *domain.PriceAlert, err error) {
+	defer func() { s.audit(ctx, actor, "restore_alert", "alert", id, nil, out, err, nil) }()
+
+	a, err := s.repos.Alerts.RestoreAlert(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Retailer returns any retailer, active or not.
+func (s *AdminService) Retailer(ctx context.Context, id string) (*AdminRetailer, error) {
+	r, err := s.repos.Catalog.Retailer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return adminRetailer(*r), nil
+}
+
+// CreateRetailer adds a retailer. IDs are UUIDs, as the Postgres schema
+// keys retailers by UUID; the slug is derived when omitted.
+func (s *AdminService) CreateRetailer(ctx context.Context, actor domain.Actor, in AdminRetailer) (out *AdminRetailer, err error) {
+	r := in.Retailer
+	defer func() { s.audit(ctx, actor, "create_retailer", "retailer", r.ID, nil, out, err, nil) }()
+
+	if r.Slug == "" {
+		r.Slug = slugify(r.Name)
+	}
+	if r.ID == "" {
+		r.ID = uuid.NewString()
+	}
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		if _, err := s.repos.Catalog.Retailer(ctx, r.ID); err == nil {
+			return fmt.Errorf("retailer %q already exists: %w", r.ID, domain.ErrConflict)
+		}
+		r.RequestsPerMinute = in.RequestsPerMinute
+		if r.RequestsPerMinute == 0 {
+			r.RequestsPerMinute = 10
+		}
+		if err := validateRetailer(r); err != nil {
+			return err
+		}
+		if err := s.repos.Catalog.SaveRetailer(ctx, r); err != nil {
+			return fmt.Errorf("save retailer: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return adminRetailer(r), nil
+}
+
+// UpdateRetailer replaces a retailer's editable fields.
+func (s *AdminService) UpdateRetailer(ctx context.Context, actor domain.Actor, id string, in AdminRetailer) (out *AdminRetailer, err error) {
+	var before *AdminRetailer
+	defer func() { s.audit(ctx, actor, "update_retailer", "retailer", id, before, out, err, nil) }()
+
+	r := in.Retailer
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		current, err := s.repos.Catalog.Retailer(ctx, id)
+		if err != nil {
+			return err
+		}
+		before = adminRetailer(*current)
+
+		r.ID = id
+		r.RequestsPerMinute = in.RequestsPerMinute
+		if r.RequestsPerMinute == 0 {
+			r.RequestsPerMinute = current.RequestsPerMinute
+		}
+		if r.Slug == "" {
+			r.Slug = current.Slug
+		}
+		if err := validateRetailer(r); err != nil {
+			return err
+		}
+		if err := s.repos.Catalog.SaveRetailer(ctx, r); err != nil {
+			return fmt.Errorf("save retailer: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return adminRetailer(r), nil
+}
+
+// DeleteRetailer deactivates a retailer so it is no longer scraped or shown.
+func (s *AdminService) DeleteRetailer(ctx context.Context, actor domain.Actor, id string) (err error) {
+	var before, after *AdminRetailer
+	defer func() { s.audit(ctx, actor, "delete_retailer", "retailer", id, before, after, err, nil) }()
+
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		r, err := s.repos.Catalog.Retailer(ctx, id)
+		if err != nil {
+			return err
+		}
+		before = adminRetailer(*r)
+		r.IsActive = false
+		if err := s.repos.Catalog.SaveRetailer(ctx, *r); err != nil {
+			return err
+		}
+		after = adminRetailer(*r)
+		return nil
+	})
+	if err != nil {
+		after = nil
+	}
+	return err
+}
+
+// Selectors returns a retailer's scraper selector config.
+func (s *AdminService) Selectors(ctx context.Context, retailerID string) (*domain.SelectorConfig, error) {
+	if _, err := s.repos.Catalog.Retailer(ctx, retailerID); err != nil {
+		return nil, err
+	}
+	return s.repos.Selectors.Selectors(ctx, retailerID)
+}
+
+// SaveSelectors replaces a retailer's scraper selector config.
+func (s *AdminService) SaveSelectors(ctx context.Context, actor domain.Actor, retailerID string, cfg domain.SelectorConfig) (out *domain.SelectorConfig, err error) {
+	var before *domain.SelectorConfig
+	defer func() { s.audit(ctx, actor, "update_selectors", "selector_config", retailerID, before, out, err, nil) }()
+
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		if _, err := s.repos.Catalog.Retailer(ctx, retailerID); err != nil {
+			return err
+		}
+		if current, err := s.repos.Selectors.Selectors(ctx, retailerID); err == nil {
+			before = current
+		} else if !errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("load selectors: %w", err)
+		}
+
+		cfg.RetailerID = retailerID
+		cfg.PriceSelectors = compact(cfg.PriceSelectors)
+		cfg.TitleSelectors = compact(cfg.TitleSelectors)
+		cfg.OriginalPriceSelectors = compact(cfg.OriginalPriceSelectors)
+		cfg.StockSelectors = compact(cfg.StockSelectors)
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		cfg.UpdatedAt = s.now().UTC()
+		cfg.UpdatedBy = actor.ID
+		if err := s.repos.Selectors.SaveSelectors(ctx, cfg); err != nil {
+			return fmt.Errorf("save selectors: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// ApplySelectors saves the selector configs in byRetailer, keyed by
+// retailer ID, that differ from those stored, such as the configuration
+// file's. A config that cannot be saved does not stop the others.
+func (s *AdminService) ApplySelectors(ctx context.Context, actor domain.Actor, byRetailer map[string]domain.SelectorConfig) error {
+	var errs []error
+	for _, id := range slices.Sorted(maps.Keys(byRetailer)) {
+		cfg := byRetailer[id]
+		if current, err := s.repos.Selectors.Selectors(ctx, id); err == nil && sameSelectors(*current, cfg) {
+			continue
+		}
+		if _, err := s.SaveSelectors(ctx, actor, id, cfg); err != nil {
+			errs = append(errs, fmt.Errorf("retailer %s: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sameSelectors reports whether a and b read pages alike.
+func sameSelectors(a, b domain.SelectorConfig) bool {
+	return slices.Equal(compact(a.TitleSelectors), compact(b.TitleSelectors)) &&
+		slices.Equal(compact(a.PriceSelectors), compact(b.PriceSelectors)) &&
+		slices.Equal(compact(a.OriginalPriceSelectors), compact(b.OriginalPriceSelectors)) &&
+		slices.Equal(compact(a.StockSelectors), compact(b.StockSelectors)) &&
+		a.SearchURLTemplate == b.SearchURLTemplate
+}
+
+// CorrectPrice records a manual price for a listing, e.g. after the scraper
+// read a wrong value. The correction becomes the current price when it is
+// the newest observation. The audit entry records the listing before and
+// after, with the correction's reason.
+func (s *AdminService) CorrectPrice(ctx context.Context, actor domain.Actor, listingID string, c PriceCorrection) (out *domain.PricePoint, err error) {
+	var before, after *domain.Listing
+	defer func() {
+		var extra map[string]any
+		if out != nil {
+			extra = map[string]any{"price_point": out}
+		}
+		actor.Reason = c.Reason
+		s.audit(ctx, actor, "correct_price", "listing", listingID, before, after, err, extra)
+	}()
+
+	var point domain.PricePoint
+	var backdated bool
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		listing, err := s.repos.Catalog.Listing(ctx, listingID)
+		if err != nil {
+			return err
+		}
+		before = listing
+		if c.Price <= 0 {
+			return fmt.Errorf("price must be positive: %w", domain.ErrInvalid)
+		}
+		if strings.TrimSpace(c.Reason) == "" {
+			return fmt.Errorf("a reason is required for manual corrections: %w", domain.ErrInvalid)
+		}
+
+		recordedAt := s.now().UTC()
+		if c.RecordedAt != nil {
+			if c.RecordedAt.After(recordedAt) {
+				return fmt.Errorf("recorded_at cannot be in the future: %w", domain.ErrInvalid)
+			}
+			recordedAt = c.RecordedAt.UTC()
+		}
+		inStock := listing.InStock
+		if c.InStock != nil {
+			inStock = *c.InStock
+		}
+		// A backdated correction leaves the current price alone, so it is no
+		// price change.
+		backdated = recordedAt.Before(listing.LastScrapedAt)
+		var changed []domain.Event
+		if !backdated {
+			change := domain.PriceChange{
+				VariantID:  listing.VariantID,
+				ListingID:  listing.ID,
+				RetailerID: listing.RetailerID,
+				OldPrice:   listing.CurrentPrice,
+				NewPrice:   c.Price,
+				Currency:   listing.Currency,
+				InStock:    inStock,
+				OccurredAt: recordedAt,
+			}
+			if v, err := s.repos.Catalog.Variant(ctx, listing.VariantID); err == nil {
+				change.ProductID = v.ProductID
+			}
+			changed = append(changed, domain.NewPriceEvent(change))
+		}
+		point, err = s.repos.Prices.RecordPrice(ctx, domain.PricePoint{
+			ListingID:     listingID,
+			Price:         c.Price,
+			PreviousPrice: listing.CurrentPrice,
+			Currency:      listing.Currency,
+			InStock:       inStock,
+			RecordedAt:    recordedAt,
+			Source:        "manual",
+		}, changed...)
+		if err != nil {
+			return fmt.Errorf("record price: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if backdated {
+		return &point, nil
+	}
+	current := *before
+	current.CurrentPrice, current.InStock, current.LastScrapedAt = point.Price, point.InStock, point.RecordedAt
+	after = &current
+	s.publish(ctx)
+	return &point, nil
+}
+
+// AuditLog returns audit entries, newest first.
+func (s *AdminService) AuditLog(ctx context.Context, filter repositories.AuditFilter) ([]domain.AuditEntry, error) {
+	if filter.Limit == 0 {
+		filter.Limit = 50
+	}
+	if filter.Limit < 1 || filter.Limit > MaxAuditPage {
+		return nil, fmt.Errorf("limit must be between 1 and %d: %w", MaxAuditPage, domain.ErrInvalid)
+	}
+	entries, err := s.repos.Audit.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("list audit log: %w", err)
+	}
+	if entries == nil {
+		entries = []domain.AuditEntry{}
+	}
+	return entries, nil
+}
+
+// productUpdated announces a change to the given parts of p or its
+// variants.
+func (s *AdminService) productUpdated(p domain.Product, changes ...string) domain.ProductUpdated {
+	return domain.ProductUpdated{ProductID: p.ID, BrandID: p.BrandID, Changes: changes, OccurredAt: s.now().UTC()}
+}
+
+// variantsUpdated announces a change to the variants of a product.
+func (s *AdminService) variantsUpdated(ctx context.Context, productID string) domain.ProductUpdated {
+	p, err := s.repos.Catalog.Product(ctx, productID)
+	if err != nil {
+		return domain.ProductUpdated{ProductID: productID, Changes: []string{domain.ProductChangeVariants}, OccurredAt: s.now().UTC()}
+	}
+	return s.productUpdated(*p, domain.ProductChangeVariants)
+}
+
+// inTx runs fn as one unit of work, so a change is checked against what
+// it replaces and saved without another change in between.
+func (s *AdminService) inTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return inTx(ctx, s.repos.Tx, fn)
+}
+
+// inTx runs fn as a unit of work of tx, or directly without one.
+func inTx(ctx context.Context, tx repositories.Transactor, fn func(ctx context.Context) error) error {
+	if tx == nil {
+		return fn(ctx)
+	}
+	return tx.InTx(ctx, fn)
+}
+
+// publish delivers the events just written to the outboxes, if there are
+// relays to do it now.
+func (s *AdminService) publish(ctx context.Context) {
+	for _, r := range s.relays {
+		r.Flush(ctx)
+	}
+}
+
+// productChanges lists the parts of a product that differ between before
+// and after.
+func productChanges(before, after domain.Product) []string {
+	var changes []string
+	if before.Name != after.Name || before.Slug != after.Slug || before.Description != after.Description || before.ImageURL != after.ImageURL {
+		changes = append(changes, domain.ProductChangeText)
+	}
+	if before.ProteinPerServing != after.ProteinPerServing || before.ServingsPerContainer != after.ServingsPerContainer ||
+		before.ServingSizeGrams != after.ServingSizeGrams {
+		changes = append(changes, domain.ProductChangeNutrition)
+	}
+	if before.BrandID != after.BrandID || before.CategoryID != after.CategoryID {
+		changes = append(changes, domain.ProductChangeBrand)
+	}
+	if before.IsActive != after.IsActive || (before.DeletedAt == nil) != (after.DeletedAt == nil) {
+		changes = append(changes, domain.ProductChangeStatus)
+	}
+	return changes
+}
+
+// audit records one admin action: who took it and why, and for an update
+// the fields it changed. A failing audit write does not undo the change,
+// so it is logged loudly instead.
+func (s *AdminService) audit(ctx context.Context, actor domain.Actor, action, resourceType, resourceID string, before, after any, err error, extra map[string]any) {
+	metadata := make(map[string]any, len(extra)+2)
+	for k, v := range extra {
+		metadata[k] = v
+	}
+	if !isNil(before) {
+		metadata["before"] = before
+	}
+	if !isNil(after) {
+		metadata["after"] = after
+	}
+
+	entry := domain.AuditEntry{
+		ActorID:      actor.ID,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Reason:       strings.TrimSpace(actor.Reason),
+		Changes:      auditChanges(before, after),
+		IPAddress:    actor.IPAddress,
+		UserAgent:    actor.UserAgent,
+		HTTPMethod:   actor.Method,
+		Endpoint:     actor.Endpoint,
+		RequestID:    actor.RequestID,
+		Success:      err == nil,
+		Metadata:     metadata,
+		CreatedAt:    s.now().UTC(),
+	}
+	if err != nil {
+		entry.ErrorMessage = err.Error()
+	}
+
+	// Record even if the request was cancelled mid-way.
+	if auditErr := s.repos.Audit.Append(context.WithoutCancel(ctx), entry); auditErr != nil {
+		s.logger.Error("Failed to write audit log entry",
+			zap.String("operation", "Audit"),
+			zap.String("action", action),
+			zap.String("resource_id", resourceID),
+			zap.String("actor_id", actor.ID),
+			zap.Error(auditErr),
+		)
+	}
+}
+
+// isNil reports whether v is nil or a typed nil pointer; deferred audit
+// calls pass typed nils when an operation fails early.
+func isNil(v any) bool {
+	rv := reflect.ValueOf(v)
+	return v == nil || rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+// auditChanges lists the JSON fields that differ between before and
+// after, two versions of one record. It returns nil unless both are
+// present and of the same type, as for creations and removals.
+func auditChanges(before, after any) []domain.AuditChange {
+	if isNil(before) || isNil(after) || reflect.TypeOf(before) != reflect.TypeOf(after) {
+		return nil
+	}
+	old, oldErr := jsonFields(before)
+	updated, newErr := jsonFields(after)
+	if oldErr != nil || newErr != nil {
+		return nil
+	}
+	fields := make(map[string]bool, len(updated))
+	for k := range old {
+		fields[k] = true
+	}
+	for k := range updated {
+		fields[k] = true
+	}
+	var changes []domain.AuditChange
+	for _, field := range slices.Sorted(maps.Keys(fields)) {
+		if !reflect.DeepEqual(old[field], updated[field]) {
+			changes = append(changes, domain.AuditChange{Field: field, Old: old[field], New: updated[field]})
+		}
+	}
+	return changes
+}
+
+// jsonFields decodes v's JSON object form into its fields.
+func jsonFields(v any) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	return fields, json.Unmarshal(b, &fields)
+}
+
+func adminProduct(p domain.Product) *AdminProduct {
+	active := p.IsActive
+	return &AdminProduct{Product: p, Active: &active}
+}
+
+func adminVariant(v domain.Variant) *AdminVariant {
+	active := v.IsActive
+	return &AdminVariant{Variant: v, Active: &active}
+}
+
+func adminRetailer(r domain.Retailer) *AdminRetailer {
+	return &AdminRetailer{Retailer: r, RequestsPerMinute: r.RequestsPerMinute}
+}
+
+func validateProduct(p domain.Product) error {
+	var problems []string
+	if strings.TrimSpace(p.Name) == "" {
+		problems = append(problems, "name is required")
+	}
+	if p.BrandID == "" {
+		problems = append(problems, "brand_id is required")
+	}
+	if p.CategoryID == "" {
+		problems = append(problems, "category_id is required")
+	}
+	if p.ProteinPerServing < 0 || p.ServingSizeGrams < 0 || p.ServingsPerContainer < 0 {
+		problems = append(problems, "nutrition figures cannot be negative")
+	}
+	if p.ServingSizeGrams > 0 && p.ProteinPerServing > p.ServingSizeGrams {
+		problems = append(problems, "protein_per_serving cannot exceed serving_size_grams")
+	}
+	if p.ImageURL != "" && !isHTTPURL(p.ImageURL) {
+		problems = append(problems, "image_url must be an http(s) URL")
+	}
+	return invalid(problems)
+}
+
+func validateVariant(v domain.Variant) error {
+	var problems []string
+	if v.SizeGrams <= 0 {
+		problems = append(problems, "weight_grams must be positive")
+	}
+	if strings.TrimSpace(v.Flavor) == "" {
+		problems = append(problems, "flavor is required")
+	}
+	return invalid(problems)
+}
+
+func validateRetailer(r domain.Retailer) error {
+	var problems []string
+	if strings.TrimSpace(r.Name) == "" {
+		problems = append(problems, "name is required")
+	}
+	if !isHTTPURL(r.WebsiteURL) {
+		problems = append(problems, "website_url must be an http(s) URL")
+	}
+	if r.RequestsPerMinute < 1 || r.RequestsPerMinute > 120 {
+		problems = append(problems, "requests_per_minute must be between 1 and 120")
+	}
+	return invalid(problems)
+}
+
+// CheckRetailers returns why the active retailers' scraping settings or
+// selector configs are invalid, if any is: settings written before a
+// validation rule existed, or straight to the database. It runs at
+// startup, so a bad config is fixed before the scraper rate limits or
+// reads pages by it.
+func CheckRetailers(ctx context.Context, retailers repositories.RetailerRepository, selectors repositories.SelectorRepository) error {
+	all, err := retailers.List(ctx)
+	if err != nil {
+		return fmt.Errorf("list retailers: %w", err)
+	}
+	var errs []error
+	for _, r := range all {
+		if !r.IsActive {
+			continue
+		}
+		if err := validateRetailer(r); err != nil {
+			errs = append(errs, fmt.Errorf("retailer %s: %w", r.Slug, err))
+		}
+		cfg, err := selectors.Selectors(ctx, r.ID)
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+		case err != nil:
+			return fmt.Errorf("load selectors of %s: %w", r.Slug, err)
+		default:
+			if err := cfg.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("retailer %s selectors: %w", r.Slug, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func invalid(problems []string) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", strings.Join(problems, "; "), domain.ErrInvalid)
+}
+
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// slugify lower-cases s and joins its alphanumeric runs with hyphens.
+func slugify(s string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(s) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	return b.String()
+}
+
+func compact(values []string) []string {
+	out := values[:0:0]
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
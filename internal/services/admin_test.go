@@ -0,0 +1,595 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/events"
+	"github.com/yourusername/whey-price-compare/internal/images"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/repositories/mocks"
+	"github.com/yourusername/whey-price-compare/internal/storage/blob"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func newTestAdminService(t *testing.T) (*AdminService, *memory.Store) {
+	t.Helper()
+	logger := testhelpers.SetupTestLogger(t)
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	svc := NewAdminService(AdminRepos{
+		Catalog:   store.CatalogAdmin(),
+		Selectors: store.Selectors(),
+		Prices:    store.PriceWriter(),
+		Audit:     store.Audit(),
+		Synonyms:  store.Synonyms(),
+		Flags:     store.Flags(),
+		Alerts:    store.Alerts(),
+		Tx:        store.Transactor(),
+	}, logger)
+	return svc, store
+}
+
+func TestAdminService_ProductLifecycle(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAdminService_ProductLifecycle", "internal/services")
+
+	svc, store := newTestAdminService(t)
+	ctx := t.Context()
+	actor := domain.Actor{ID: "alice", IPAddress: "10.0.0.1", RequestID: "req-1", Reason: " New listing "}
+
+	testhelpers.LogTestStep(logger, "act", "Creating a product")
+	created, err := svc.CreateProduct(ctx, actor, AdminProduct{Product: domain.Product{
+		Name: "Iso 100 Hydrolyzed", BrandID: "dymatize", CategoryID: "whey-isolate",
+		ProteinPerServing: 25, ServingSizeGrams: 32,
+	}})
+	if err != nil {
+		t.Fatalf("CreateProduct failed: %v", err)
+	}
+	if created.Slug != "iso-100-hydrolyzed" || created.Active == nil || !*created.Active {
+		t.Errorf("Created = %+v", created)
+	}
+	if _, err := store.Products().FindByID(ctx, created.ID); err != nil {
+		t.Errorf("Created product not visible publicly: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Updating and deleting the product")
+	updated, err := svc.UpdateProduct(ctx, actor, created.ID, AdminProduct{Product: domain.Product{
+		Name: "ISO100", BrandID: "dymatize", CategoryID: "whey-isolate", ProteinPerServing: 25, ServingSizeGrams: 32,
+		Version: created.Version,
+	}})
+	if err != nil {
+		t.Fatalf("UpdateProduct failed: %v", err)
+	}
+	if updated.Slug != created.Slug || !updated.CreatedAt.Equal(created.CreatedAt) {
+		t.Errorf("Update must keep slug and creation time, got %+v", updated)
+	}
+	if err := svc.DeleteProduct(ctx, actor, created.ID); err != nil {
+		t.Fatalf("DeleteProduct failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Deleted product is hidden but retained")
+	if _, err := store.Products().FindByID(ctx, created.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Public lookup error = %v, want ErrNotFound", err)
+	}
+	kept, err := svc.Product(ctx, created.ID)
+	if err != nil || kept.DeletedAt == nil || !*kept.Active {
+		t.Errorf("Admin lookup = %+v, %v; want the deleted product, still active", kept, err)
+	}
+	if err := svc.DeleteProduct(ctx, actor, created.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Deleting twice error = %v, want ErrNotFound", err)
+	}
+
+	entries, _ := svc.AuditLog(ctx, repositories.AuditFilter{ResourceID: created.ID})
+	testhelpers.LogTestAssertion(logger, "audit entries", 4, len(entries))
+	if len(entries) != 4 || entries[1].Action != "delete_product" || entries[3].Action != "create_product" {
+		t.Fatalf("Audit entries = %+v", entries)
+	}
+	if entries[2].Metadata["before"] == nil || entries[2].Metadata["after"] == nil {
+		t.Errorf("Update entry must carry before/after, got %v", entries[2].Metadata)
+	}
+	changed := make(map[string]domain.AuditChange)
+	for _, c := range entries[2].Changes {
+		changed[c.Field] = c
+	}
+	if c := changed["name"]; c.Old != "Iso 100 Hydrolyzed" || c.New != "ISO100" || changed["slug"].Field != "" {
+		t.Errorf("Update entry changes = %+v, want the name only among catalog fields", entries[2].Changes)
+	}
+	if len(entries[1].Changes) == 0 || entries[1].Changes[0].Field != "deleted_at" || entries[1].Changes[0].Old != nil {
+		t.Errorf("Delete entry changes = %+v, want deleted_at set", entries[1].Changes)
+	}
+	if entries[3].Changes != nil {
+		t.Errorf("Create entry changes = %+v, want none", entries[3].Changes)
+	}
+	if entries[1].ActorID != "alice" || entries[1].IPAddress != "10.0.0.1" || entries[1].Reason != "New listing" || !entries[1].Success {
+		t.Errorf("Audit entry = %+v", entries[1])
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Restoring the product")
+	restored, err := svc.RestoreProduct(ctx, actor, created.ID)
+	if err != nil || restored.DeletedAt != nil {
+		t.Fatalf("RestoreProduct = %+v, %v", restored, err)
+	}
+	if _, err := store.Products().FindByID(ctx, created.ID); err != nil {
+		t.Errorf("Restored product not visible publicly: %v", err)
+	}
+	if _, err := svc.RestoreProduct(ctx, actor, created.ID); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Restoring a live product error = %v, want ErrConflict", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAdminService_ProductLifecycle", true)
+}
+
+func TestAdminService_RestoreVariantAndAlert(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAdminService_RestoreVariantAndAlert", "internal/services")
+
+	svc, store := newTestAdminService(t)
+	ctx := t.Context()
+	actor := domain.Actor{ID: "alice"}
+
+	testhelpers.LogTestStep(logger, "act", "Deleting a variant takes its listings with it")
+	if err := svc.DeleteVariant(ctx, actor, testhelpers.FixtureVariantID); err != nil {
+		t.Fatalf("DeleteVariant: %v", err)
+	}
+	listings, _ := store.Listings().ByProduct(ctx, testhelpers.FixtureProductID)
+	testhelpers.LogTestAssertion(logger, "listings of the deleted variant", 0, len(listings))
+	if len(listings) != 0 {
+		t.Errorf("Listings after delete = %d, want 0", len(listings))
+	}
+	if _, err := svc.RestoreVariant(ctx, actor, testhelpers.FixtureVariantID); err != nil {
+		t.Fatalf("RestoreVariant: %v", err)
+	}
+	if listings, _ := store.Listings().ByProduct(ctx, testhelpers.FixtureProductID); len(listings) != 3 {
+		t.Errorf("Listings after restore = %d, want 3", len(listings))
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Restoring an alert its user deleted")
+	alert, err := store.Alerts().CreateAlert(ctx, domain.PriceAlert{
+		UserID: "user_1", ProductID: testhelpers.FixtureProductID, Condition: domain.ConditionPrice, TargetPrice: 3000,
+	})
+	if err != nil {
+		t.Fatalf("CreateAlert: %v", err)
+	}
+	if err := store.Alerts().DeleteAlert(ctx, alert.ID, time.Now()); err != nil {
+		t.Fatalf("DeleteAlert: %v", err)
+	}
+	deleted, _ := svc.DeletedAlerts(ctx, "user_1")
+	if len(deleted) != 1 || deleted[0].ID != alert.ID {
+		t.Fatalf("DeletedAlerts = %+v", deleted)
+	}
+	restored, err := svc.RestoreAlert(ctx, actor, alert.ID)
+	if err != nil || restored.DeletedAt != nil {
+		t.Fatalf("RestoreAlert = %+v, %v", restored, err)
+	}
+	if mine, _ := store.Alerts().UserAlerts(ctx, "user_1"); len(mine) != 1 {
+		t.Errorf("UserAlerts after restore = %+v", mine)
+	}
+	entries, _ := svc.AuditLog(ctx, repositories.AuditFilter{ResourceType: "alert"})
+	if len(entries) != 1 || entries[0].Action != "restore_alert" || !entries[0].Success {
+		t.Errorf("Alert audit entries = %+v", entries)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAdminService_RestoreVariantAndAlert", true)
+}
+
+func TestAdminService_Errors(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAdminService_Errors", "internal/services")
+
+	svc, _ := newTestAdminService(t)
+	ctx := t.Context()
+	actor := domain.Actor{ID: "alice"}
+
+	testCases := []struct {
+		name    string
+		call    func() error
+		wantErr error
+	}{
+		{"Duplicate product", func() error {
+			_, err := svc.CreateProduct(ctx, actor, AdminProduct{Product: domain.Product{ID: testhelpers.FixtureProductID, Name: "x", BrandID: "b", CategoryID: "c"}})
+			return err
+		}, domain.ErrConflict},
+		{"Product without name", func() error {
+			_, err := svc.CreateProduct(ctx, actor, AdminProduct{Product: domain.Product{BrandID: "b", CategoryID: "c"}})
+			return err
+		}, domain.ErrInvalid},
+		{"Variant for missing product", func() error {
+			_, err := svc.CreateVariant(ctx, actor, "prod_missing", AdminVariant{Variant: domain.Variant{Flavor: "Vanilla", SizeGrams: 1000}})
+			return err
+		}, domain.ErrNotFound},
+		{"Retailer with bad URL", func() error {
+			_, err := svc.CreateRetailer(ctx, actor, AdminRetailer{Retailer: domain.Retailer{Name: "Nutrabay", WebsiteURL: "nutrabay.com"}})
+			return err
+		}, domain.ErrInvalid},
+		{"Selectors without price selector", func() error {
+			_, err := svc.SaveSelectors(ctx, actor, "amazon", domain.SelectorConfig{TitleSelectors: []string{"#title"}})
+			return err
+		}, domain.ErrInvalid},
+		{"Correction without reason", func() error {
+			_, err := svc.CorrectPrice(ctx, actor, testhelpers.FixtureListingAmazon, PriceCorrection{Price: 3100})
+			return err
+		}, domain.ErrInvalid},
+		{"Audit page too large", func() error {
+			_, err := svc.AuditLog(ctx, repositories.AuditFilter{Limit: MaxAuditPage + 1})
+			return err
+		}, domain.ErrInvalid},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.call()
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Failed attempts are audited")
+	entries, _ := svc.AuditLog(ctx, repositories.AuditFilter{ActorID: "alice"})
+	if len(entries) != 6 {
+		t.Fatalf("Got %d audit entries, want 6", len(entries))
+	}
+	for _, e := range entries {
+		if e.Success || e.ErrorMessage == "" {
+			t.Errorf("Entry %s must record the failure, got %+v", e.Action, e)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAdminService_Errors", true)
+}
+
+func TestAdminService_IngestProductImage(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAdminService_IngestProductImage", "internal/services")
+
+	testhelpers.LogTestStep(logger, "arrange", "A retailer serving a product photo, and local image storage")
+	photo := image.NewNRGBA(image.Rect(0, 0, 500, 500))
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, photo); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+	local, err := blob.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	cfg := images.DefaultConfig()
+	cfg.AllowPrivate = true
+	svc, store := newTestAdminService(t)
+	svc.WithImages(images.NewPipeline(cfg, local, logger))
+	ctx := t.Context()
+	actor := domain.Actor{ID: "alice"}
+
+	testhelpers.LogTestStep(logger, "act", "Ingesting the photo for a product")
+	p, err := svc.IngestProductImage(ctx, actor, testhelpers.FixtureProductID, srv.URL+"/gsw.png")
+	if err != nil {
+		t.Fatalf("IngestProductImage: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The product links to the stored card size and the change is audited")
+	testhelpers.LogTestAssertion(logger, "image_url", "/media/images/…/card.webp", p.ImageURL)
+	if !strings.HasPrefix(p.ImageURL, "/media/images/") || !strings.HasSuffix(p.ImageURL, "/card.webp") {
+		t.Errorf("ImageURL = %q", p.ImageURL)
+	}
+	if stored, _ := store.Products().FindByID(ctx, testhelpers.FixtureProductID); stored == nil || stored.ImageURL != p.ImageURL {
+		t.Errorf("stored product = %+v", stored)
+	}
+	entries, _ := svc.AuditLog(ctx, repositories.AuditFilter{ActorID: "alice"})
+	if len(entries) != 1 || entries[0].Action != "ingest_product_image" || !entries[0].Success {
+		t.Errorf("audit = %+v", entries)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Ingesting for a missing product")
+	_, err = svc.IngestProductImage(ctx, actor, "prod_missing", srv.URL+"/gsw.png")
+
+	testhelpers.LogTestStep(logger, "assert", "It is not found")
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAdminService_IngestProductImage", true)
+}
+
+func TestAdminService_CorrectPrice(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAdminService_CorrectPrice", "internal/services")
+
+	svc, store := newTestAdminService(t)
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Correcting a mis-scraped price")
+	point, err := svc.CorrectPrice(ctx, domain.Actor{ID: "alice"}, testhelpers.FixtureListingAmazon,
+		PriceCorrection{Price: 3199, Reason: "Scraper read the combo price"})
+	if err != nil {
+		t.Fatalf("CorrectPrice failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Correction is a manual point and becomes current")
+	if point.Source != "manual" || point.PreviousPrice != 3299 || !point.InStock {
+		t.Errorf("Point = %+v", point)
+	}
+	listings, _ := store.Listings().ByProduct(ctx, testhelpers.FixtureProductID)
+	for _, l := range listings {
+		if l.ID == testhelpers.FixtureListingAmazon && l.CurrentPrice != 3199 {
+			t.Errorf("CurrentPrice = %v, want 3199", l.CurrentPrice)
+		}
+	}
+	entries, _ := svc.AuditLog(ctx, repositories.AuditFilter{ResourceType: "listing"})
+	if len(entries) != 1 || entries[0].Reason != "Scraper read the combo price" || entries[0].Metadata["price_point"] != point {
+		t.Fatalf("Audit entries = %+v", entries)
+	}
+	testhelpers.LogTestAssertion(logger, "first change", "current_price", entries[0].Changes[0].Field)
+	if c := entries[0].Changes; len(c) != 2 || c[0].Field != "current_price" || c[0].Old != 3299.0 || c[0].New != 3199.0 ||
+		c[1].Field != "last_scraped_at" {
+		t.Errorf("Correction changes = %+v, want the current price and scrape time", c)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAdminService_CorrectPrice", true)
+}
+
+type recordingPublisher struct{ events []domain.Event }
+
+// recordEvents relays the events svc writes to store's outbox to the
+// returned recorder.
+func recordEvents(t *testing.T, svc *AdminService, store *memory.Store) *recordingPublisher {
+	t.Helper()
+	logger := testhelpers.SetupTestLogger(t)
+	pub := &recordingPublisher{}
+	bus := events.NewBus(logger)
+	bus.Subscribe(func(_ context.Context, e domain.Event) error {
+		pub.events = append(pub.events, e)
+		return nil
+	}, domain.EventPriceDropped, domain.EventPriceChanged, domain.EventProductUpdated, domain.EventSynonymsChanged, domain.EventFlagsChanged)
+	svc.WithRelay(events.NewRelay(events.DefaultRelayConfig(), store.Outbox(), bus, logger))
+	return pub
+}
+
+func TestAdminService_PublishesEvents(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAdminService_PublishesEvents", "internal/services")
+
+	svc, store := newTestAdminService(t)
+	pub := recordEvents(t, svc, store)
+	ctx := t.Context()
+	actor := domain.Actor{ID: "alice"}
+	now := time.Now().UTC()
+	old := now.Add(-30 * 24 * time.Hour)
+
+	testhelpers.LogTestStep(logger, "act", "A drop, a rise, a backdated correction, a variant edit and a failed edit")
+	corrections := []PriceCorrection{
+		{Price: 2999, Reason: "flash sale"},
+		{Price: 3099, Reason: "sale over"},
+		{Price: 1999, Reason: "backfill", RecordedAt: &old},
+	}
+	for _, c := range corrections {
+		if _, err := svc.CorrectPrice(ctx, actor, testhelpers.FixtureListingAmazon, c); err != nil {
+			t.Fatalf("CorrectPrice failed: %v", err)
+		}
+	}
+	if err := svc.DeleteVariant(ctx, actor, testhelpers.FixtureVariantID); err != nil {
+		t.Fatalf("DeleteVariant failed: %v", err)
+	}
+	_, _ = svc.UpdateProduct(ctx, actor, testhelpers.FixtureProductID, AdminProduct{})
+
+	testhelpers.LogTestStep(logger, "assert", "Only changes to current data are announced")
+	want := []string{domain.EventPriceDropped, domain.EventPriceChanged, domain.EventProductUpdated}
+	var got []string
+	for _, e := range pub.events {
+		got = append(got, e.EventType())
+	}
+	testhelpers.LogTestAssertion(logger, "events", want, got)
+	if len(got) != len(want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("events[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+	drop := pub.events[0].(domain.PriceDropped)
+	if drop.ProductID != testhelpers.FixtureProductID || drop.RetailerID != "amazon" || drop.OldPrice != 3299 || drop.NewPrice != 2999 {
+		t.Errorf("PriceDropped = %+v", drop)
+	}
+	if upd := pub.events[2].(domain.ProductUpdated); upd.ProductID != testhelpers.FixtureProductID || upd.BrandID != "optimum-nutrition" ||
+		!slices.Equal(upd.Changes, []string{domain.ProductChangeVariants}) {
+		t.Errorf("ProductUpdated = %+v", upd)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Editing only the nutrition figures, then saving without changes")
+	pub.events = nil
+	current, _ := svc.Product(ctx, testhelpers.FixtureProductID)
+	edit := *current
+	edit.ProteinPerServing++
+	updated, err := svc.UpdateProduct(ctx, actor, testhelpers.FixtureProductID, edit)
+	if err != nil {
+		t.Fatalf("UpdateProduct failed: %v", err)
+	}
+	if _, err := svc.UpdateProduct(ctx, actor, testhelpers.FixtureProductID, *updated); err != nil {
+		t.Fatalf("UpdateProduct failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The event names the part changed; a no-op edit announces nothing")
+	if len(pub.events) != 1 || !slices.Equal(pub.events[0].(domain.ProductUpdated).Changes, []string{domain.ProductChangeNutrition}) {
+		t.Errorf("events = %+v, want one nutrition change", pub.events)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAdminService_PublishesEvents", true)
+}
+
+func TestAdminService_UnitOfWork(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAdminService_UnitOfWork", "internal/services")
+
+	testhelpers.LogTestStep(logger, "arrange", "An admin service whose unit of work fails to commit")
+	svc, store := newTestAdminService(t)
+	ctx := t.Context()
+	errCommit := errors.New("could not serialize access")
+	tx := mocks.NewMockTransactor(gomock.NewController(t))
+	tx.EXPECT().InTx(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		if err := fn(ctx); err != nil {
+			return err
+		}
+		return errCommit
+	})
+	svc.repos.Tx = tx
+	published := recordEvents(t, svc, store)
+
+	testhelpers.LogTestStep(logger, "act", "Deleting a product")
+	err := svc.DeleteProduct(ctx, domain.Actor{ID: "alice"}, testhelpers.FixtureProductID)
+
+	testhelpers.LogTestStep(logger, "assert", "The failure is returned and audited, and nothing is announced")
+	testhelpers.LogTestAssertion(logger, "error", errCommit, err)
+	if !errors.Is(err, errCommit) {
+		t.Errorf("Error = %v, want %v", err, errCommit)
+	}
+	entries, _ := svc.AuditLog(ctx, repositories.AuditFilter{Action: "delete_product"})
+	if len(entries) != 1 || entries[0].Success || entries[0].ErrorMessage == "" {
+		t.Errorf("Audit entries = %+v, want one failure", entries)
+	}
+	if n := len(published.events); n != 0 {
+		t.Errorf("Published %d events for an uncommitted change", n)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAdminService_UnitOfWork", true)
+}
+
+func TestAdminService_ConcurrentEdits(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAdminService_ConcurrentEdits", "internal/services")
+
+	svc, _ := newTestAdminService(t)
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "arrange", "Two admins open the same product and variant")
+	product, _ := svc.Product(ctx, testhelpers.FixtureProductID)
+	variant, err := svc.repos.Catalog.Variant(ctx, testhelpers.FixtureVariantID)
+	if err != nil {
+		t.Fatalf("Variant failed: %v", err)
+	}
+	alice, bob := *product, *product
+	alice.Name, bob.Name = "Gold Standard 100% Whey (2 lb)", "Gold Standard Whey"
+	aliceVariant, bobVariant := AdminVariant{Variant: *variant}, AdminVariant{Variant: *variant}
+	aliceVariant.SKU, bobVariant.SKU = "ON-GSW-2LB", "ON-GSW-907"
+
+	testhelpers.LogTestStep(logger, "act", "Both save their edits, Alice first")
+	saved, err := svc.UpdateProduct(ctx, domain.Actor{ID: "alice"}, product.ID, alice)
+	if err != nil {
+		t.Fatalf("Alice's UpdateProduct failed: %v", err)
+	}
+	_, bobErr := svc.UpdateProduct(ctx, domain.Actor{ID: "bob"}, product.ID, bob)
+	if _, err := svc.UpdateVariant(ctx, domain.Actor{ID: "alice"}, variant.ID, aliceVariant); err != nil {
+		t.Fatalf("Alice's UpdateVariant failed: %v", err)
+	}
+	_, bobVariantErr := svc.UpdateVariant(ctx, domain.Actor{ID: "bob"}, variant.ID, bobVariant)
+
+	unversioned := alice
+	unversioned.Version = 0
+	_, unversionedErr := svc.UpdateProduct(ctx, domain.Actor{ID: "carol"}, product.ID, unversioned)
+
+	testhelpers.LogTestStep(logger, "assert", "Bob's edits of the old versions fail and Alice's are kept")
+	if !errors.Is(unversionedErr, ErrVersionRequired) {
+		t.Errorf("Unversioned UpdateProduct = %v, want ErrVersionRequired", unversionedErr)
+	}
+	testhelpers.LogTestAssertion(logger, "bob's product edit", domain.ErrConflict, bobErr)
+	if !errors.Is(bobErr, domain.ErrConflict) || !errors.Is(bobVariantErr, domain.ErrConflict) {
+		t.Errorf("Bob's errors = %v, %v; want ErrConflict", bobErr, bobVariantErr)
+	}
+	if saved.Version != product.Version+1 {
+		t.Errorf("Saved version = %d, want %d", saved.Version, product.Version+1)
+	}
+	current, _ := svc.Product(ctx, product.ID)
+	currentVariant, _ := svc.repos.Catalog.Variant(ctx, variant.ID)
+	if current.Name != alice.Name || currentVariant.SKU != aliceVariant.SKU {
+		t.Errorf("Current = %q, %q; want Alice's edits", current.Name, currentVariant.SKU)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAdminService_ConcurrentEdits", true)
+}
+
+func TestAdminService_ApplySelectors(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAdminService_ApplySelectors", "internal/services")
+
+	svc, store := newTestAdminService(t)
+	ctx := t.Context()
+	actor := domain.Actor{ID: "config", Reason: "Configuration file"}
+	byRetailer := map[string]domain.SelectorConfig{
+		"amazon":  {PriceSelectors: []string{".a-price-whole"}, SearchURLTemplate: "https://www.amazon.in/s?k={query}"},
+		"missing": {PriceSelectors: []string{".price"}},
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Applying the same configs twice")
+	first := svc.ApplySelectors(ctx, actor, byRetailer)
+	second := svc.ApplySelectors(ctx, actor, byRetailer)
+
+	testhelpers.LogTestStep(logger, "assert", "Known retailers are saved once; unknown ones are reported")
+	testhelpers.LogTestAssertion(logger, "error", "retailer missing", first)
+	if !errors.Is(first, domain.ErrNotFound) || !strings.Contains(first.Error(), "retailer missing") {
+		t.Errorf("First apply = %v, want the missing retailer reported", first)
+	}
+	if got, err := store.Selectors().Selectors(ctx, "amazon"); err != nil || got.PriceSelectors[0] != ".a-price-whole" || got.UpdatedBy != "config" {
+		t.Errorf("Stored selectors = %+v, %v", got, err)
+	}
+	entries, _ := svc.AuditLog(ctx, repositories.AuditFilter{ActorID: "config", ResourceID: "amazon"})
+	if len(entries) != 1 || second == nil {
+		t.Errorf("Audit entries = %d, second apply = %v; want 1 and the missing retailer again", len(entries), second)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAdminService_ApplySelectors", true)
+}
+
+func TestCheckRetailers(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCheckRetailers", "internal/services")
+
+	_, store := newTestAdminService(t)
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "assert", "The seeded retailers pass")
+	if err := CheckRetailers(ctx, store.Retailers(), store.Selectors()); err != nil {
+		t.Fatalf("CheckRetailers on the fixtures failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "arrange", "Configs written around the admin API")
+	store.PutRetailer(domain.Retailer{ID: "muscleblaze", Name: "MuscleBlaze", Slug: "muscleblaze", WebsiteURL: "https://www.muscleblaze.com", IsActive: true})
+	store.PutRetailer(domain.Retailer{ID: "closed", Name: "Closed", Slug: "closed", IsActive: false})
+	if err := store.Selectors().SaveSelectors(ctx, domain.SelectorConfig{RetailerID: "amazon", PriceSelectors: []string{" "}, SearchURLTemplate: "https://www.amazon.in/s"}); err != nil {
+		t.Fatalf("SaveSelectors failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Checking retailers")
+	err := CheckRetailers(ctx, store.Retailers(), store.Selectors())
+
+	testhelpers.LogTestStep(logger, "assert", "Each active retailer's problem is named; inactive ones are skipped")
+	testhelpers.LogTestAssertion(logger, "error", "muscleblaze rate and amazon selectors", err)
+	if !errors.Is(err, domain.ErrInvalid) {
+		t.Fatalf("err = %v, want ErrInvalid", err)
+	}
+	for _, want := range []string{"retailer muscleblaze: requests_per_minute", "retailer amazon selectors: at least one price selector", "{query}"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %v, want it to say %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "closed") {
+		t.Errorf("err = %v, want inactive retailers skipped", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCheckRetailers", true)
+}
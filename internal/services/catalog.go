@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/cache"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// CatalogRepos groups the repositories CatalogService reads from.
+type CatalogRepos struct {
+	Products  repositories.ProductRepository
+	Retailers repositories.RetailerRepository
+	Listings  repositories.ListingRepository
+}
+
+// CatalogService serves product and retailer reference data.
+type CatalogService struct {
+	repos      CatalogRepos
+	cache      cache.Cache
+	policy     cache.Policy
+	known      *KnownProducts
+	popularity *Popularity
+	logger     *zap.Logger
+}
+
+// NewCatalogService creates a CatalogService.
+func NewCatalogService(repos CatalogRepos, logger *zap.Logger) *CatalogService {
+	return &CatalogService{repos: repos, logger: logger}
+}
+
+// WithCache serves product details through c under policy p. It returns s.
+func (s *CatalogService) WithCache(c cache.Cache, p cache.Policy) *CatalogService {
+	s.cache, s.policy = c, p
+	return s
+}
+
+// WithKnownProducts answers lookups of products k rules out as not found
+// without reading anything. It returns s.
+func (s *CatalogService) WithKnownProducts(k *KnownProducts) *CatalogService {
+	s.known = k
+	return s
+}
+
+// WithPopularity counts every product detail served in p. It returns s.
+func (s *CatalogService) WithPopularity(p *Popularity) *CatalogService {
+	s.popularity = p
+	return s
+}
+
+// Product returns a product with its variants and the retailers carrying it.
+func (s *CatalogService) Product(ctx context.Context, productID string) (*domain.ProductDetail, error) {
+	if s.known != nil && !s.known.MayExist(productID) {
+		return nil, unknownProduct(productID)
+	}
+	detail, err := s.cachedProduct(ctx, productID)
+	if err == nil && s.popularity != nil {
+		s.popularity.Record(productID)
+	}
+	return detail, err
+}
+
+// cachedProduct reads product details through the cache without counting
+// them as a view, for the cache warmer.
+func (s *CatalogService) cachedProduct(ctx context.Context, productID string) (*domain.ProductDetail, error) {
+	return cache.Fetch(ctx, s.cache, cache.ProductKey(productID), s.policy, s.logger, func(ctx context.Context) (*domain.ProductDetail, error) {
+		return s.product(ctx, productID)
+	})
+}
+
+func (s *CatalogService) product(ctx context.Context, productID string) (*domain.ProductDetail, error) {
+	product, err := s.repos.Products.FindByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	variants, err := s.repos.Products.Variants(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("load variants: %w", err)
+	}
+	listings, err := s.repos.Listings.ByProduct(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("load listings: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	retailers := make([]string, 0, len(listings))
+	for _, l := range listings {
+		if !seen[l.RetailerID] {
+			seen[l.RetailerID] = true
+			retailers = append(retailers, l.RetailerID)
+		}
+	}
+	sort.Strings(retailers)
+
+	if variants == nil {
+		variants = []domain.Variant{}
+	}
+	return &domain.ProductDetail{Product: *product, Variants: variants, RetailerIDs: retailers}, nil
+}
+
+// Retailers lists the active retailers.
+func (s *CatalogService) Retailers(ctx context.Context) ([]domain.Retailer, error) {
+	retailers, err := s.repos.Retailers.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list retailers: %w", err)
+	}
+	active := make([]domain.Retailer, 0, len(retailers))
+	for _, r := range retailers {
+		if r.IsActive {
+			active = append(active, r)
+		}
+	}
+	return active, nil
+}
+
+// Retailer returns one retailer.
+func (s *CatalogService) Retailer(ctx context.Context, retailerID string) (*domain.Retailer, error) {
+	return s.repos.Retailers.FindByID(ctx, retailerID)
+}
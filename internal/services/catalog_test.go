@@ -0,0 +1,50 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestCatalogService(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCatalogService", "internal/services")
+
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	store.PutRetailer(domain.Retailer{ID: "defunct", Name: "Defunct"})
+	svc := NewCatalogService(CatalogRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+	}, logger)
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Loading product detail")
+	d, err := svc.Product(ctx, testhelpers.FixtureProductID)
+	if err != nil {
+		t.Fatalf("Product failed: %v", err)
+	}
+	testhelpers.LogTestAssertion(logger, "retailers carrying product", 3, len(d.RetailerIDs))
+	if len(d.Variants) != 1 || len(d.RetailerIDs) != 3 || d.RetailerIDs[0] != "amazon" {
+		t.Errorf("Detail = %d variants, retailers %v", len(d.Variants), d.RetailerIDs)
+	}
+	if _, err := svc.Product(ctx, "prod_missing"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Missing product error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Listing retailers")
+	retailers, err := svc.Retailers(ctx)
+	if err != nil {
+		t.Fatalf("Retailers failed: %v", err)
+	}
+	if len(retailers) != 3 {
+		t.Errorf("Got %d retailers, want 3 active", len(retailers))
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCatalogService", true)
+}
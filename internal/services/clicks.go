@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// ClickTrackerConfig configures the asynchronous click writer.
+type ClickTrackerConfig struct {
+	// Buffer is how many events may wait to be written. Events beyond it
+	// are dropped rather than slowing redirects down.
+	Buffer int
+	// BatchSize and FlushInterval bound how long an event waits in memory.
+	BatchSize     int
+	FlushInterval time.Duration
+}
+
+// DefaultClickTrackerConfig returns settings that tolerate short database
+// stalls at a few hundred clicks per second.
+func DefaultClickTrackerConfig() ClickTrackerConfig {
+	return ClickTrackerConfig{Buffer: 4096, BatchSize: 100, FlushInterval: 2 * time.Second}
+}
+
+// ClickTracker records click events off the request path. Track never
+// blocks; Run writes queued events in batches until its context ends.
+type ClickTracker struct {
+	cfg     ClickTrackerConfig
+	repo    repositories.ClickRepository
+	logger  *zap.Logger
+	events  chan domain.ClickEvent
+	dropped atomic.Int64
+}
+
+// NewClickTracker creates a ClickTracker. Call Run to start writing.
+func NewClickTracker(cfg ClickTrackerConfig, repo repositories.ClickRepository, logger *zap.Logger) *ClickTracker {
+	def := DefaultClickTrackerConfig()
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = def.Buffer
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = def.BatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = def.FlushInterval
+	}
+	return &ClickTracker{cfg: cfg, repo: repo, logger: logger, events: make(chan domain.ClickEvent, cfg.Buffer)}
+}
+
+// Track queues e. It reports false if the buffer was full and the event was
+// dropped.
+func (t *ClickTracker) Track(e domain.ClickEvent) bool {
+	select {
+	case t.events <- e:
+		return true
+	default:
+		if t.dropped.Add(1)%100 == 1 {
+			t.logger.Warn("Click buffer full, dropping events",
+				zap.String("operation", "TrackClick"),
+				zap.Int64("dropped_total", t.dropped.Load()),
+			)
+		}
+		return false
+	}
+}
+
+// Dropped returns how many events were discarded because the buffer was
+// full.
+func (t *ClickTracker) Dropped() int64 { return t.dropped.Load() }
+
+// Run writes queued events until ctx is cancelled, then flushes whatever is
+// still buffered.
+func (t *ClickTracker) Run(ctx context.Context) {
+	ticker := time.NewTicker(t.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]domain.ClickEvent, 0, t.cfg.BatchSize)
+	for {
+		select {
+		case e := <-t.events:
+			batch = append(batch, e)
+			if len(batch) >= t.cfg.BatchSize {
+				batch = t.flush(ctx, batch)
+			}
+		case <-ticker.C:
+			batch = t.flush(ctx, batch)
+		case <-ctx.Done():
+			for {
+				select {
+				case e := <-t.events:
+					batch = append(batch, e)
+				default:
+					t.flush(context.WithoutCancel(ctx), batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+func (t *ClickTracker) flush(ctx context.Context, batch []domain.ClickEvent) []domain.ClickEvent {
+	if len(batch) == 0 {
+		return batch
+	}
+	if err := t.repo.RecordClicks(ctx, batch); err != nil {
+		t.logger.Error("Failed to record clicks",
+			zap.String("operation", "FlushClicks"),
+			zap.Int("events", len(batch)),
+			zap.Error(err),
+		)
+	}
+	return batch[:0]
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestClickTracker(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestClickTracker", "internal/services")
+
+	store := memory.NewStore()
+	tracker := NewClickTracker(ClickTrackerConfig{Buffer: 3, BatchSize: 2, FlushInterval: time.Hour}, store.Clicks(), logger)
+
+	testhelpers.LogTestStep(logger, "act", "Queueing more clicks than the buffer holds")
+	accepted := 0
+	for range 5 {
+		if tracker.Track(domain.ClickEvent{ProductID: testhelpers.FixtureProductID, RetailerID: "amazon", CreatedAt: time.Now()}) {
+			accepted++
+		}
+	}
+	if accepted != 3 || tracker.Dropped() != 2 {
+		t.Errorf("accepted=%d dropped=%d, want 3 and 2", accepted, tracker.Dropped())
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Stopping the writer flushes the queue")
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tracker.Run(ctx)
+	}()
+	cancel()
+	<-done
+
+	n, _ := store.Clicks().CountClicks(t.Context(), repositories.ClickFilter{ProductID: testhelpers.FixtureProductID})
+	testhelpers.LogTestAssertion(logger, "clicks stored", 3, n)
+	if n != 3 {
+		t.Errorf("Stored %d clicks, want 3", n)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestClickTracker", true)
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Deal listing limits.
+const (
+	DefaultDealLimit = 20
+	MaxDealLimit     = 100
+	dealWindowDays   = 30
+	dealScanPageSize = 100
+)
+
+// DealQuery selects the deals returned by TopDeals.
+type DealQuery struct {
+	CategoryID string
+	Limit      int
+	MinScore   float64
+	// Filters keeps deals whose product and offer pass them, as a
+	// filter preset selects them.
+	Filters domain.SearchFilters
+	// Sort is a domain result order other than relevance; the default is
+	// domain.SortDealScore.
+	Sort string
+}
+
+// TopDeals scores the best in-stock offer of every active product against
+// its 30-day history and returns the first of those scoring above zero in
+// q's order, the highest scoring by default.
+func (s *PriceService) TopDeals(ctx context.Context, q DealQuery) ([]domain.Deal, error) {
+	if q.Limit == 0 {
+		q.Limit = DefaultDealLimit
+	}
+	if q.Sort == "" {
+		q.Sort = domain.SortDealScore
+	}
+	if q.Limit < 1 || q.Limit > MaxDealLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d: %w", MaxDealLimit, domain.ErrInvalid)
+	}
+	if !domain.IsResultSort(q.Sort) || q.Sort == domain.SortRelevance {
+		return nil, fmt.Errorf("sort must be one of %s: %w", strings.Join(domain.ResultSorts[1:], ", "), domain.ErrInvalid)
+	}
+	if err := q.Filters.Validate(); err != nil {
+		return nil, err
+	}
+
+	logger := s.logger.With(
+		zap.String("operation", "TopDeals"),
+		zap.String("category_id", q.CategoryID),
+		zap.Int("limit", q.Limit),
+		zap.String("sort", q.Sort),
+	)
+	if s.views != nil {
+		// The view only holds deals scoring above zero, in score order, so
+		// any other order, or filters it cannot apply, read all of them.
+		filter := repositories.DealViewFilter{CategoryID: q.CategoryID, MinScore: q.MinScore, Limit: q.Limit}
+		if q.Sort != domain.SortDealScore || !q.Filters.Empty() {
+			filter.Limit = 0
+		}
+		deals, err := s.views.TopDeals(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("read deal view: %w", err)
+		}
+		deals = slices.DeleteFunc(deals, func(d domain.Deal) bool { return !q.Filters.MatchesOffer(d.Product, d.Offer) })
+		domain.SortDeals(deals, q.Sort)
+		if len(deals) > q.Limit {
+			deals = deals[:q.Limit]
+		}
+		logger.Debug("Deals read from view", zap.Int("deals", len(deals)))
+		return deals, nil
+	}
+	logger.Debug("Scoring deals")
+
+	since := s.now().AddDate(0, 0, -dealWindowDays)
+	deals := make([]domain.Deal, 0)
+	for offset := 0; ; offset += dealScanPageSize {
+		products, err := s.repos.Products.List(ctx, repositories.ProductFilter{
+			CategoryID: q.CategoryID,
+			Limit:      dealScanPageSize,
+			Offset:     offset,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list products: %w", err)
+		}
+		scored, err := s.scoreProducts(ctx, products, since)
+		if err != nil {
+			return nil, err
+		}
+		for _, deal := range scored {
+			if deal.Score > 0 && deal.Score >= q.MinScore && q.Filters.MatchesOffer(deal.Product, deal.Offer) {
+				deals = append(deals, *deal)
+			}
+		}
+		if len(products) < dealScanPageSize {
+			break
+		}
+	}
+
+	sort.SliceStable(deals, func(i, j int) bool {
+		if deals[i].Score != deals[j].Score {
+			return deals[i].Score > deals[j].Score
+		}
+		return deals[i].Offer.PricePerGramProtein < deals[j].Offer.PricePerGramProtein
+	})
+	domain.SortDeals(deals, q.Sort)
+	if len(deals) > q.Limit {
+		deals = deals[:q.Limit]
+	}
+
+	logger.Debug("Deals scored", zap.Int("deals", len(deals)))
+	return deals, nil
+}
+
+// scoreProducts compares and scores a page of products, returning a deal for
+// each one with a product in stock. Lookups for the whole page are batched.
+func (s *PriceService) scoreProducts(ctx context.Context, products []domain.Product, since time.Time) ([]*domain.Deal, error) {
+	comparisons, err := s.compareProducts(ctx, products)
+	if err != nil {
+		return nil, err
+	}
+	deals, err := s.scoreComparisons(ctx, comparisons, since)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*domain.Deal, 0, len(deals))
+	for _, d := range deals {
+		if d != nil {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+// compareProducts compares products already read by a list query, skipping
+// any that disappear meanwhile.
+func (s *PriceService) compareProducts(ctx context.Context, products []domain.Product) ([]*domain.Comparison, error) {
+	ctx, ld := s.withLoaders(ctx)
+	ids := make([]string, 0, len(products))
+	for _, p := range products {
+		ld.products.Prime(p.ID, p)
+		ids = append(ids, p.ID)
+	}
+	ld.prefetch(ids...)
+
+	out := make([]*domain.Comparison, 0, len(products))
+	for _, id := range ids {
+		c, err := s.Compare(ctx, id)
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// ScoreDeals scores each comparison's best offer against its 30-day
+// history. The result is aligned with comparisons; entries with nothing in
+// stock are nil.
+func (s *PriceService) ScoreDeals(ctx context.Context, comparisons []*domain.Comparison) ([]*domain.Deal, error) {
+	return s.scoreComparisons(ctx, comparisons, s.now().AddDate(0, 0, -dealWindowDays))
+}
+
+// scoreComparisons scores each comparison's best offer against its history,
+// read in one query. The result is aligned with comparisons; entries with
+// nothing in stock are nil.
+func (s *PriceService) scoreComparisons(ctx context.Context, comparisons []*domain.Comparison, since time.Time) ([]*domain.Deal, error) {
+	listingIDs := make([]string, 0, len(comparisons))
+	for _, c := range comparisons {
+		if c.BestDeal != nil {
+			listingIDs = append(listingIDs, c.BestDeal.ListingID)
+		}
+	}
+	byListing := make(map[string][]domain.PricePoint, len(listingIDs))
+	if len(listingIDs) > 0 {
+		points, err := s.repos.Prices.History(ctx, listingIDs, since)
+		if err != nil {
+			return nil, fmt.Errorf("load price history: %w", err)
+		}
+		for _, p := range points {
+			byListing[p.ListingID] = append(byListing[p.ListingID], p)
+		}
+	}
+
+	deals := make([]*domain.Deal, len(comparisons))
+	for i, c := range comparisons {
+		if c.BestDeal != nil {
+			deals[i] = scoreOffer(c, byListing[c.BestDeal.ListingID])
+		}
+	}
+	return deals, nil
+}
+
+func scoreOffer(c *domain.Comparison, points []domain.PricePoint) *domain.Deal {
+	deal := &domain.Deal{Product: c.Product, Offer: *c.BestDeal, PricePerServing: domain.PricePerServing(c.Product, *c.BestDeal)}
+	if len(points) > 0 {
+		low, high, sum := math.Inf(1), 0.0, 0.0
+		for _, p := range points {
+			low = math.Min(low, p.Price)
+			high = math.Max(high, p.Price)
+			sum += p.Price
+		}
+		deal.Avg30d = domain.Round2(sum / float64(len(points)))
+		deal.Low30d = low
+		deal.High30d = high
+		deal.BelowAvg = domain.BelowAveragePercent(deal.Offer.Price, deal.Avg30d)
+	}
+	deal.Score = domain.DealScore(deal.Offer, deal.Avg30d, deal.Low30d)
+	return deal
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestPriceService_TopDeals(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPriceService_TopDeals", "internal/services")
+
+	svc := newTestPriceService(t, logger, time.Now())
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Scoring all fixture products")
+	deals, err := svc.TopDeals(ctx, DealQuery{})
+	if err != nil {
+		t.Fatalf("TopDeals failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Flipkart's fresh drop outranks Biozyme")
+	if len(deals) != 2 {
+		t.Fatalf("Got %d deals, want 2", len(deals))
+	}
+	top := deals[0]
+	if top.Product.ID != testhelpers.FixtureProductID || top.Offer.RetailerID != "flipkart" {
+		t.Errorf("Top deal = %s at %s", top.Product.ID, top.Offer.RetailerID)
+	}
+	if top.Low30d != 3199 || top.High30d != 3449 {
+		t.Errorf("30-day range = %v-%v, want 3199-3449", top.Low30d, top.High30d)
+	}
+	if top.Score <= deals[1].Score {
+		t.Errorf("Deals not sorted by score: %v <= %v", top.Score, deals[1].Score)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Applying limit and minimum score")
+	limited, _ := svc.TopDeals(ctx, DealQuery{Limit: 1})
+	if len(limited) != 1 {
+		t.Errorf("Limit 1 returned %d deals", len(limited))
+	}
+	none, _ := svc.TopDeals(ctx, DealQuery{MinScore: 99})
+	if len(none) != 0 {
+		t.Errorf("MinScore 99 returned %d deals, want 0", len(none))
+	}
+	if _, err := svc.TopDeals(ctx, DealQuery{Limit: MaxDealLimit + 1}); !errors.Is(err, domain.ErrInvalid) {
+		t.Errorf("Oversized limit error = %v, want ErrInvalid", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Ordering by discount, by an unknown sort and filtering by brand")
+	byDiscount, err := svc.TopDeals(ctx, DealQuery{Sort: domain.SortDiscount})
+	if err != nil {
+		t.Fatalf("TopDeals by discount: %v", err)
+	}
+	for i := 1; i < len(byDiscount); i++ {
+		if domain.DiscountAmount(byDiscount[i].Offer) > domain.DiscountAmount(byDiscount[i-1].Offer) {
+			t.Errorf("Deals not sorted by discount: %+v", byDiscount)
+		}
+	}
+	if _, err := svc.TopDeals(ctx, DealQuery{Sort: domain.SortRelevance}); !errors.Is(err, domain.ErrInvalid) {
+		t.Errorf("Relevance sort error = %v, want ErrInvalid", err)
+	}
+	brand, err := svc.TopDeals(ctx, DealQuery{Filters: domain.SearchFilters{BrandIDs: []string{"muscleblaze"}}})
+	if err != nil || len(brand) != 1 || brand[0].Product.ID != testhelpers.FixtureSecondProductID {
+		t.Errorf("Brand filtered deals = %+v, %v", brand, err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPriceService_TopDeals", true)
+}
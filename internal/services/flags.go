@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// Flags returns every stored feature flag, by name. Flags in force only
+// as defaults are not listed.
+func (s *AdminService) Flags(ctx context.Context) ([]domain.Flag, error) {
+	if s.repos.Flags == nil {
+		return nil, fmt.Errorf("feature flags: %w", domain.ErrNotFound)
+	}
+	out, err := s.repos.Flags.Flags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list flags: %w", err)
+	}
+	if out == nil {
+		out = []domain.Flag{}
+	}
+	return out, nil
+}
+
+// SaveFlag creates or replaces the flag called name, overriding its
+// default on every instance.
+func (s *AdminService) SaveFlag(ctx context.Context, actor domain.Actor, name string, in domain.Flag) (out *domain.Flag, err error) {
+	var before *domain.Flag
+	defer func() { s.audit(ctx, actor, "save_flag", "flag", name, before, out, err, nil) }()
+
+	if s.repos.Flags == nil {
+		return nil, fmt.Errorf("feature flags: %w", domain.ErrNotFound)
+	}
+	in.Name = name
+	if err := in.Validate(); err != nil {
+		return nil, err
+	}
+	// A flag saved for the first time has nothing to compare against.
+	if before, err = s.repos.Flags.Flag(ctx, name); err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	in.UpdatedAt = s.now().UTC()
+	if err := s.repos.Flags.SaveFlag(ctx, in, s.flagsChanged()); err != nil {
+		return nil, fmt.Errorf("save flag: %w", err)
+	}
+	s.publish(ctx)
+	return &in, nil
+}
+
+// DeleteFlag removes a stored flag, returning it to its default.
+func (s *AdminService) DeleteFlag(ctx context.Context, actor domain.Actor, name string) (err error) {
+	var before *domain.Flag
+	defer func() { s.audit(ctx, actor, "delete_flag", "flag", name, before, nil, err, nil) }()
+
+	if s.repos.Flags == nil {
+		return fmt.Errorf("feature flags: %w", domain.ErrNotFound)
+	}
+	if before, err = s.repos.Flags.Flag(ctx, name); err != nil {
+		return err
+	}
+	if err := s.repos.Flags.DeleteFlag(ctx, name, s.flagsChanged()); err != nil {
+		return fmt.Errorf("delete flag: %w", err)
+	}
+	s.publish(ctx)
+	return nil
+}
+
+// flagsChanged tells every instance to reload its flags.
+func (s *AdminService) flagsChanged() domain.FlagsChanged {
+	return domain.FlagsChanged{OccurredAt: s.now().UTC()}
+}
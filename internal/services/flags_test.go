@@ -0,0 +1,55 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestAdminService_Flags(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAdminService_Flags", "internal/services")
+
+	svc, store := newTestAdminService(t)
+	pub := recordEvents(t, svc, store)
+	ctx := t.Context()
+	actor := domain.Actor{ID: "alice"}
+
+	testhelpers.LogTestStep(logger, "act", "Rolling a flag out, killing it, an invalid save and a delete")
+	if _, err := svc.SaveFlag(ctx, actor, "search.rerank", domain.Flag{Enabled: true, Percent: 10}); err != nil {
+		t.Fatalf("SaveFlag: %v", err)
+	}
+	killed, err := svc.SaveFlag(ctx, actor, "search.rerank", domain.Flag{Enabled: false, Percent: 10})
+	if err != nil {
+		t.Fatalf("SaveFlag: %v", err)
+	}
+	if _, err := svc.SaveFlag(ctx, actor, "search.rerank", domain.Flag{Enabled: true, Percent: 150}); !errors.Is(err, domain.ErrInvalid) {
+		t.Errorf("Invalid save err = %v, want ErrInvalid", err)
+	}
+	list, _ := svc.Flags(ctx)
+	if err := svc.DeleteFlag(ctx, actor, "search.rerank"); err != nil {
+		t.Fatalf("DeleteFlag: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Changes are kept, announced and audited")
+	if len(list) != 1 || list[0].Enabled || killed.Name != "search.rerank" || killed.UpdatedAt.IsZero() {
+		t.Errorf("Flags = %+v, killed %+v", list, killed)
+	}
+	testhelpers.LogTestAssertion(logger, "events", 3, len(pub.events))
+	if len(pub.events) != 3 || pub.events[0].EventType() != domain.EventFlagsChanged {
+		t.Errorf("Events = %+v, want 3 FlagsChanged", pub.events)
+	}
+	entries, _ := svc.AuditLog(ctx, repositories.AuditFilter{ResourceType: "flag"})
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Action)
+	}
+	if len(entries) != 4 || entries[0].Action != "delete_flag" || entries[1].Success || entries[3].Metadata["before"] != nil {
+		t.Errorf("Audit actions = %v", actions)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAdminService_Flags", true)
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/events"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// IngestConfig configures the IngestService.
+type IngestConfig struct {
+	// BatchSize is how many prices are written in one unit of work. A
+	// failed batch writes nothing; the batches before it stay written.
+	BatchSize int
+}
+
+// DefaultIngestConfig writes a few thousand prices a unit of work, which a
+// handful of statements hold.
+func DefaultIngestConfig() IngestConfig {
+	return IngestConfig{BatchSize: 5000}
+}
+
+// IngestStats reports what an ingest did.
+type IngestStats struct {
+	Received int `json:"received"`
+	Stored   int `json:"stored"`
+	// Rejected counts prices for unknown listings or without a positive
+	// price.
+	Rejected int `json:"rejected"`
+	// Changed counts the prices that changed their listing's current price
+	// or stock, each announced as a price event.
+	Changed int `json:"changed"`
+}
+
+// IngestService writes scraped prices in bulk: a full retailer scrape
+// takes a few statements per batch rather than one write per price.
+type IngestService struct {
+	cfg    IngestConfig
+	prices repositories.PriceIngester
+	tx     repositories.Transactor
+	relay  *events.Relay
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewIngestService creates an IngestService. tx runs each batch as a unit
+// of work with prices; without it the listings a batch reads may change
+// before its prices are written.
+func NewIngestService(cfg IngestConfig, prices repositories.PriceIngester, tx repositories.Transactor, logger *zap.Logger) *IngestService {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultIngestConfig().BatchSize
+	}
+	return &IngestService{cfg: cfg, prices: prices, tx: tx, logger: logger, now: time.Now}
+}
+
+// WithRelay publishes the price events of each ingest through r as soon as
+// it is written, as AdminService.WithRelay does. It returns s.
+func (s *IngestService) WithRelay(r *events.Relay) *IngestService {
+	s.relay = r
+	return s
+}
+
+// Ingest stores points, prices observed by a scrape. A point without a
+// time is taken as observed now, without a source as from the scraper and
+// without a currency as in its listing's. A point newer than its listing's
+// last scrape takes the listing's price as its previous price and becomes
+// the current one, announced with a price event if it changed the price or
+// stock; an older one only fills in history. Ingest stops at the first
+// batch that fails, returning the stats of the batches written before it.
+func (s *IngestService) Ingest(ctx context.Context, points []domain.PricePoint) (IngestStats, error) {
+	stats := IngestStats{Received: len(points)}
+	// In listing and time order, a listing's points chain their previous
+	// prices, and most listings fall in one batch.
+	points = slices.Clone(points)
+	slices.SortStableFunc(points, func(a, b domain.PricePoint) int {
+		return cmp.Or(strings.Compare(a.ListingID, b.ListingID), a.RecordedAt.Compare(b.RecordedAt))
+	})
+	for batch := range slices.Chunk(points, s.cfg.BatchSize) {
+		if err := s.ingestBatch(ctx, batch, &stats); err != nil {
+			s.logger.Error("Price ingest failed",
+				zap.String("operation", "IngestPrices"),
+				zap.Int("stored", stats.Stored),
+				zap.Int("received", stats.Received),
+				zap.Error(err),
+			)
+			s.publish(ctx, stats)
+			return stats, fmt.Errorf("ingest prices: %w", err)
+		}
+	}
+	s.publish(ctx, stats)
+	s.logger.Info("Ingested prices",
+		zap.Int("received", stats.Received),
+		zap.Int("stored", stats.Stored),
+		zap.Int("rejected", stats.Rejected),
+		zap.Int("changed", stats.Changed),
+	)
+	return stats, nil
+}
+
+// ingestBatch writes one batch as a unit of work, adding to stats only if
+// it is written.
+func (s *IngestService) ingestBatch(ctx context.Context, batch []domain.PricePoint, stats *IngestStats) error {
+	var stored, rejected, changed int
+	err := s.inTx(ctx, func(ctx context.Context) error {
+		stored, rejected, changed = 0, 0, 0
+		var ids []string
+		for i, p := range batch {
+			if i == 0 || p.ListingID != batch[i-1].ListingID {
+				ids = append(ids, p.ListingID)
+			}
+		}
+		listings, err := s.prices.Listings(ctx, ids)
+		if err != nil {
+			return err
+		}
+		now := s.now().UTC()
+		points := make([]domain.PricePoint, 0, len(batch))
+		var events []domain.Event
+		for _, p := range batch {
+			l, ok := listings[p.ListingID]
+			if !ok || p.Price <= 0 {
+				rejected++
+				continue
+			}
+			if p.RecordedAt.IsZero() {
+				p.RecordedAt = now
+			}
+			p.Source = cmp.Or(p.Source, "scraper")
+			p.Currency = cmp.Or(p.Currency, l.Currency, domain.DefaultCurrency)
+			points = append(points, p)
+			// A point older than the listing's last scrape fills in
+			// history and leaves the current price alone.
+			if p.RecordedAt.Before(l.LastScrapedAt) {
+				continue
+			}
+			points[len(points)-1].PreviousPrice = l.CurrentPrice
+			if p.Price != l.CurrentPrice || p.InStock != l.InStock {
+				changed++
+				events = append(events, domain.NewPriceEvent(domain.PriceChange{
+					ProductID:  l.ProductID,
+					VariantID:  l.VariantID,
+					ListingID:  l.ID,
+					RetailerID: l.RetailerID,
+					OldPrice:   l.CurrentPrice,
+					NewPrice:   p.Price,
+					Currency:   p.Currency,
+					InStock:    p.InStock,
+					OccurredAt: p.RecordedAt,
+				}))
+			}
+			l.CurrentPrice, l.InStock, l.LastScrapedAt = p.Price, p.InStock, p.RecordedAt
+			listings[l.ID] = l
+		}
+		stored = len(points)
+		if len(points) == 0 {
+			return nil
+		}
+		return s.prices.IngestPrices(ctx, points, events...)
+	})
+	if err != nil {
+		return err
+	}
+	stats.Stored += stored
+	stats.Rejected += rejected
+	stats.Changed += changed
+	return nil
+}
+
+func (s *IngestService) inTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return inTx(ctx, s.tx, fn)
+}
+
+// publish delivers the events of the batches written, if any changed a
+// price and there is a relay to do it now.
+func (s *IngestService) publish(ctx context.Context, stats IngestStats) {
+	if s.relay != nil && stats.Changed > 0 {
+		s.relay.Flush(ctx)
+	}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/events"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestIngestService_Ingest(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestIngestService_Ingest", "internal/services")
+
+	testhelpers.LogTestStep(logger, "arrange", "A seeded store and an ingest service writing two prices a batch")
+	store := memory.NewStore()
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	testhelpers.SeedCatalog(store, now.Add(-time.Hour))
+	before := listing(t, store, testhelpers.FixtureListingAmazon)
+	svc := NewIngestService(IngestConfig{BatchSize: 2}, store.PriceIngester(), store.Transactor(), logger)
+	svc.now = func() time.Time { return now }
+	pub := &recordingPublisher{}
+	bus := events.NewBus(logger)
+	bus.Subscribe(func(_ context.Context, e domain.Event) error {
+		pub.events = append(pub.events, e)
+		return nil
+	}, domain.EventPriceDropped, domain.EventPriceChanged)
+	svc.WithRelay(events.NewRelay(events.DefaultRelayConfig(), store.Outbox(), bus, logger))
+
+	testhelpers.LogTestStep(logger, "act", "Ingesting a scrape with a drop, an unchanged price, a backfill and two bad rows")
+	stats, err := svc.Ingest(t.Context(), []domain.PricePoint{
+		{ListingID: testhelpers.FixtureListingAmazon, Price: before.CurrentPrice - 200, InStock: true},
+		{ListingID: testhelpers.FixtureListingFlipkart, Price: listing(t, store, testhelpers.FixtureListingFlipkart).CurrentPrice, InStock: true},
+		{ListingID: testhelpers.FixtureListingAmazon, Price: 2500, InStock: true, RecordedAt: now.AddDate(0, 0, -40)},
+		{ListingID: "lst_unknown", Price: 999, InStock: true},
+		{ListingID: testhelpers.FixtureListingHK, Price: 0},
+	})
+	if err != nil {
+		t.Fatalf("Ingest failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Good rows are stored, listings move to their newest price and only the drop is announced")
+	testhelpers.LogTestAssertion(logger, "stats", IngestStats{Received: 5, Stored: 3, Rejected: 2, Changed: 1}, stats)
+	if stats != (IngestStats{Received: 5, Stored: 3, Rejected: 2, Changed: 1}) {
+		t.Errorf("Stats = %+v", stats)
+	}
+	after := listing(t, store, testhelpers.FixtureListingAmazon)
+	if after.CurrentPrice != before.CurrentPrice-200 || !after.LastScrapedAt.Equal(now) {
+		t.Errorf("Listing = %+v, want the new price at %v", after, now)
+	}
+	history, _ := store.Prices().History(t.Context(), []string{testhelpers.FixtureListingAmazon}, now.AddDate(0, 0, -41))
+	var scraped, backfilled bool
+	for _, p := range history {
+		switch {
+		case p.RecordedAt.Equal(now):
+			scraped = p.Source == "scraper" && p.PreviousPrice == before.CurrentPrice && p.Currency == before.Currency
+		case p.Price == 2500:
+			backfilled = p.PreviousPrice == 0
+		}
+	}
+	if !scraped || !backfilled {
+		t.Errorf("History = %+v, want the scraped and backfilled points", history)
+	}
+	if len(pub.events) != 1 {
+		t.Fatalf("Published %d events, want 1", len(pub.events))
+	}
+	if drop, ok := pub.events[0].(domain.PriceDropped); !ok || drop.ProductID != testhelpers.FixtureProductID || drop.OldPrice != before.CurrentPrice {
+		t.Errorf("Event = %+v, want a drop on the fixture product", pub.events[0])
+	}
+
+	testhelpers.LogTestComplete(logger, "TestIngestService_Ingest", true)
+}
+
+func listing(t *testing.T, store *memory.Store, id string) domain.Listing {
+	t.Helper()
+	l, err := store.CatalogAdmin().Listing(t.Context(), id)
+	if err != nil {
+		t.Fatalf("Listing %s: %v", id, err)
+	}
+	return *l
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/metrics"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// IntegrityConfig configures the IntegrityChecker.
+type IntegrityConfig struct {
+	// Interval is how often Run checks.
+	Interval time.Duration
+	// StaleAfter is how long a live product may go without a recorded
+	// price before it is reported. It also bounds the prices checked for
+	// currency mismatches.
+	StaleAfter time.Duration
+	// Limit caps the findings kept per check.
+	Limit int
+}
+
+// DefaultIntegrityConfig checks every six hours and reports products
+// unpriced for three days: a product every retailer failed to scrape for
+// that long is either delisted or broken.
+func DefaultIntegrityConfig() IntegrityConfig {
+	return IntegrityConfig{Interval: 6 * time.Hour, StaleAfter: 3 * 24 * time.Hour, Limit: 100}
+}
+
+// IntegrityChecker looks for data the schema lets through but nothing
+// should have written: variants without a product, products no retailer
+// prices anymore, and prices in the wrong currency. Findings are logged,
+// kept for the admin API and, with WithMetrics, exported.
+type IntegrityChecker struct {
+	cfg      IntegrityConfig
+	repo     repositories.IntegrityRepository
+	logger   *zap.Logger
+	now      func() time.Time
+	findings *metrics.Gauge
+
+	mu   sync.Mutex
+	last *domain.IntegrityReport
+}
+
+// NewIntegrityChecker creates an IntegrityChecker. Call Run to check on a
+// schedule.
+func NewIntegrityChecker(cfg IntegrityConfig, repo repositories.IntegrityRepository, logger *zap.Logger) *IntegrityChecker {
+	def := DefaultIntegrityConfig()
+	if cfg.Interval <= 0 {
+		cfg.Interval = def.Interval
+	}
+	if cfg.StaleAfter <= 0 {
+		cfg.StaleAfter = def.StaleAfter
+	}
+	if cfg.Limit <= 0 {
+		cfg.Limit = def.Limit
+	}
+	return &IntegrityChecker{cfg: cfg, repo: repo, logger: logger, now: time.Now}
+}
+
+// WithMetrics exports the findings of the last run per check as
+// integrity_findings to reg. It returns c.
+func (c *IntegrityChecker) WithMetrics(reg *metrics.Registry) *IntegrityChecker {
+	c.findings = reg.Gauge("integrity_findings", "Rows failing each integrity check at the last run, capped at the finding limit.", "check")
+	return c
+}
+
+// Run checks at start and every Interval until ctx is done.
+func (c *IntegrityChecker) Run(ctx context.Context) {
+	c.check(ctx)
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.check(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *IntegrityChecker) check(ctx context.Context) {
+	if _, err := c.Check(ctx); err != nil && ctx.Err() == nil {
+		c.logger.Error("Integrity check failed", zap.String("operation", "Check"), zap.Error(err))
+	}
+}
+
+// Check runs every check now and keeps the report as the last one. A check
+// that fails leaves the others' findings in the report.
+func (c *IntegrityChecker) Check(ctx context.Context) (domain.IntegrityReport, error) {
+	now := c.now().UTC()
+	since := now.Add(-c.cfg.StaleAfter)
+	report := domain.IntegrityReport{CheckedAt: now, Counts: make(map[string]int), Findings: []domain.IntegrityFinding{}}
+	var errs []error
+	for _, check := range domain.IntegrityChecks {
+		var (
+			found []domain.IntegrityFinding
+			err   error
+		)
+		switch check {
+		case domain.IntegrityOrphanVariant:
+			found, err = c.repo.OrphanVariants(ctx, c.cfg.Limit)
+		case domain.IntegrityUnpricedProduct:
+			found, err = c.repo.UnpricedProducts(ctx, since, c.cfg.Limit)
+		case domain.IntegrityCurrencyMismatch:
+			found, err = c.repo.CurrencyMismatches(ctx, since, c.cfg.Limit)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", check, err))
+			continue
+		}
+		report.Counts[check] = len(found)
+		report.Findings = append(report.Findings, found...)
+		if len(found) >= c.cfg.Limit {
+			report.Truncated = append(report.Truncated, check)
+		}
+		if c.findings != nil {
+			c.findings.Set(float64(len(found)), check)
+		}
+	}
+
+	c.mu.Lock()
+	c.last = &report
+	c.mu.Unlock()
+
+	if report.Clean() {
+		c.logger.Debug("Integrity check found nothing", zap.String("operation", "Check"))
+	} else {
+		c.logger.Warn("Integrity check found inconsistent rows",
+			zap.String("operation", "Check"),
+			zap.Any("counts", report.Counts),
+			zap.Strings("truncated", report.Truncated))
+	}
+	return report, errors.Join(errs...)
+}
+
+// Last returns the report of the last run, or domain.ErrNotFound if none
+// has finished.
+func (c *IntegrityChecker) Last() (domain.IntegrityReport, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.last == nil {
+		return domain.IntegrityReport{}, fmt.Errorf("integrity report: %w", domain.ErrNotFound)
+	}
+	// Reports are not changed once kept, so callers can share one.
+	return *c.last, nil
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/metrics"
+	"github.com/yourusername/whey-price-compare/internal/repositories/mocks"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestIntegrityChecker_Check(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestIntegrityChecker_Check", "internal/services")
+
+	testhelpers.LogTestStep(logger, "arrange", "Two orphan variants at a limit of two, and a failing currency check")
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	repo := mocks.NewMockIntegrityRepository(gomock.NewController(t))
+	repo.EXPECT().OrphanVariants(gomock.Any(), 2).Return([]domain.IntegrityFinding{
+		{Check: domain.IntegrityOrphanVariant, VariantID: "v1", ProductID: "p_gone"},
+		{Check: domain.IntegrityOrphanVariant, VariantID: "v2", ProductID: "p_gone"},
+	}, nil)
+	repo.EXPECT().UnpricedProducts(gomock.Any(), now.Add(-48*time.Hour), 2).Return([]domain.IntegrityFinding{
+		{Check: domain.IntegrityUnpricedProduct, ProductID: "p_stale"},
+	}, nil)
+	repo.EXPECT().CurrencyMismatches(gomock.Any(), now.Add(-48*time.Hour), 2).Return(nil, errors.New("replica unavailable"))
+	reg := metrics.NewRegistry()
+	checker := NewIntegrityChecker(IntegrityConfig{StaleAfter: 48 * time.Hour, Limit: 2}, repo, logger).WithMetrics(reg)
+	checker.now = func() time.Time { return now }
+	if _, err := checker.Last(); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Last before a run = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Checking")
+	report, err := checker.Check(t.Context())
+
+	testhelpers.LogTestStep(logger, "assert", "The failure is returned, the other findings are kept, counted and exported")
+	if err == nil || !strings.Contains(err.Error(), "currency_mismatch: replica unavailable") {
+		t.Errorf("err = %v, want the currency check's failure", err)
+	}
+	testhelpers.LogTestAssertion(logger, "counts", map[string]int{"orphan_variant": 2, "unpriced_product": 1}, report.Counts)
+	if len(report.Findings) != 3 || report.Counts[domain.IntegrityOrphanVariant] != 2 || report.Counts[domain.IntegrityUnpricedProduct] != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	if _, ok := report.Counts[domain.IntegrityCurrencyMismatch]; ok {
+		t.Errorf("The failed check has a count: %+v", report.Counts)
+	}
+	if len(report.Truncated) != 1 || report.Truncated[0] != domain.IntegrityOrphanVariant || !report.CheckedAt.Equal(now) {
+		t.Errorf("Truncated = %v at %v, want the orphan check at %v", report.Truncated, report.CheckedAt, now)
+	}
+	if last, err := checker.Last(); err != nil || len(last.Findings) != 3 {
+		t.Errorf("Last = %+v, %v; want the report", last, err)
+	}
+	var b strings.Builder
+	if err := reg.WriteText(&b); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	for _, line := range []string{`integrity_findings{check="orphan_variant"} 2`, `integrity_findings{check="unpriced_product"} 1`} {
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("Metrics lack %q:\n%s", line, b.String())
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestIntegrityChecker_Check", true)
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/bloom"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// knownProductsFPRate is the share of unknown IDs the filter lets through to
+// the repositories.
+const knownProductsFPRate = 0.01
+
+// KnownProducts is a Bloom filter of every active product's ID and slug.
+// Lookups it rules out are answered as not found without touching the cache
+// or the database, which keeps bots probing made-up IDs and mistyped URLs
+// off the database.
+//
+// Until the first Rebuild, and whenever one fails, every ID may exist.
+// Deleted products stay in the filter until the next Rebuild, which only
+// costs the lookup it would have cost anyway.
+type KnownProducts struct {
+	products repositories.ProductRepository
+	logger   *zap.Logger
+	filter   atomic.Pointer[bloom.Filter]
+	rejected atomic.Int64
+
+	// rebuildMu serialises Rebuild. mu guards pending, the keys Handle
+	// added while a rebuild was listing products; they are replayed into
+	// the new filter before it is swapped in, so no event is lost.
+	rebuildMu  sync.Mutex
+	mu         sync.Mutex
+	rebuilding bool
+	pending    []string
+}
+
+// NewKnownProducts creates an empty KnownProducts; call Rebuild to fill it.
+func NewKnownProducts(products repositories.ProductRepository, logger *zap.Logger) *KnownProducts {
+	return &KnownProducts{products: products, logger: logger}
+}
+
+// MayExist reports whether key, a product ID or slug, could be a product.
+func (k *KnownProducts) MayExist(key string) bool {
+	f := k.filter.Load()
+	if f == nil || f.Test(key) {
+		return true
+	}
+	k.rejected.Add(1)
+	return false
+}
+
+// Rejected returns how many lookups were ruled out.
+func (k *KnownProducts) Rejected() int64 { return k.rejected.Load() }
+
+// Rebuild loads every active product's ID and slug into a fresh filter,
+// sized with room for the catalog to double before the next rebuild, and
+// swaps it in.
+func (k *KnownProducts) Rebuild(ctx context.Context) error {
+	k.rebuildMu.Lock()
+	defer k.rebuildMu.Unlock()
+	k.mu.Lock()
+	k.rebuilding, k.pending = true, nil
+	k.mu.Unlock()
+	defer func() {
+		k.mu.Lock()
+		k.rebuilding, k.pending = false, nil
+		k.mu.Unlock()
+	}()
+
+	var products []domain.Product
+	for offset := 0; ; offset += dealScanPageSize {
+		page, err := k.products.List(ctx, repositories.ProductFilter{Limit: dealScanPageSize, Offset: offset})
+		if err != nil {
+			k.logger.Error("Failed to rebuild known products",
+				zap.String("operation", "RebuildKnownProducts"),
+				zap.Error(err),
+			)
+			return fmt.Errorf("list products: %w", err)
+		}
+		products = append(products, page...)
+		if len(page) < dealScanPageSize {
+			break
+		}
+	}
+
+	f := bloom.New(max(4*len(products), 1024), knownProductsFPRate)
+	for _, p := range products {
+		f.Add(p.ID)
+		if p.Slug != "" {
+			f.Add(p.Slug)
+		}
+	}
+	k.mu.Lock()
+	for _, key := range k.pending {
+		f.Add(key)
+	}
+	k.filter.Store(f)
+	k.mu.Unlock()
+	k.logger.Info("Known products rebuilt",
+		zap.String("operation", "RebuildKnownProducts"),
+		zap.Int("products", len(products)),
+	)
+	return nil
+}
+
+// EventTypes lists the events Handle understands, for subscribing.
+func (k *KnownProducts) EventTypes() []string {
+	return []string{domain.EventProductUpdated}
+}
+
+// Handle adds the product e concerns, so a product created after the last
+// Rebuild is served at once. Subscribe it before anything that reads the
+// product back.
+func (k *KnownProducts) Handle(ctx context.Context, e domain.Event) error {
+	ev, ok := e.(domain.ProductUpdated)
+	if !ok {
+		return nil
+	}
+	keys := []string{ev.ProductID}
+	if p, err := k.products.FindByID(ctx, ev.ProductID); err == nil && p.Slug != "" {
+		keys = append(keys, p.Slug)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.rebuilding {
+		k.pending = append(k.pending, keys...)
+	}
+	if f := k.filter.Load(); f != nil {
+		for _, key := range keys {
+			f.Add(key)
+		}
+	}
+	return nil
+}
+
+// Run rebuilds the filter every interval until ctx is done, dropping
+// deleted products and resizing for growth.
+func (k *KnownProducts) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = k.Rebuild(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// unknownProduct is the error for a lookup KnownProducts ruled out; it
+// matches what the repositories return for a missing product.
+func unknownProduct(productID string) error {
+	return fmt.Errorf("product %q: %w", productID, domain.ErrNotFound)
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestKnownProducts_ShortCircuitsUnknownIDs(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestKnownProducts_ShortCircuitsUnknownIDs", "internal/services")
+
+	testhelpers.LogTestStep(logger, "arrange", "Services filtered by known products over counting repositories")
+	now := time.Now()
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, now)
+	known := NewKnownProducts(store.Products(), logger)
+	if !known.MayExist("prod_anything") {
+		t.Error("Empty filter ruled out a product before its first rebuild")
+	}
+	if err := known.Rebuild(t.Context()); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+	counter := &queryCounter{}
+	prices := NewPriceService(PriceRepos{
+		Products:  countingProducts{store.Products(), counter},
+		Retailers: countingRetailers{store.Retailers(), counter},
+		Listings:  countingListings{store.Listings(), counter},
+		Prices:    countingPrices{store.Prices(), counter},
+	}, logger).WithKnownProducts(known)
+	catalog := NewCatalogService(CatalogRepos{
+		Products:  countingProducts{store.Products(), counter},
+		Retailers: countingRetailers{store.Retailers(), counter},
+		Listings:  countingListings{store.Listings(), counter},
+	}, logger).WithKnownProducts(known)
+	ctx := t.Context()
+
+	tests := []struct {
+		name   string
+		lookup func(id string) error
+	}{
+		{"Compare", func(id string) error { _, err := prices.Compare(ctx, id); return err }},
+		{"History", func(id string) error { _, err := prices.History(ctx, id, HistoryQuery{}); return err }},
+		{"Product", func(id string) error { _, err := catalog.Product(ctx, id); return err }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testhelpers.LogTestStep(logger, "act", tt.name+" of a made-up and a real product")
+			before := counter.n.Load()
+			err := tt.lookup("wp-login.php")
+
+			testhelpers.LogTestAssertion(logger, "queries for unknown ID", int64(0), counter.n.Load()-before)
+			if !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("%s(unknown) = %v, want ErrNotFound", tt.name, err)
+			}
+			if got := counter.n.Load() - before; got != 0 {
+				t.Errorf("%s(unknown) ran %d queries, want 0", tt.name, got)
+			}
+			if err := tt.lookup(testhelpers.FixtureProductID); err != nil {
+				t.Errorf("%s(known) = %v", tt.name, err)
+			}
+		})
+	}
+	if known.Rejected() != 3 {
+		t.Errorf("Rejected = %d, want 3", known.Rejected())
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Creating a product after the rebuild")
+	store.PutProduct(domain.Product{ID: "prod_new_isolate", Slug: "new-isolate", Name: "New Isolate", IsActive: true})
+	if err := known.Handle(ctx, domain.ProductUpdated{ProductID: "prod_new_isolate", OccurredAt: now}); err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	if !known.MayExist("prod_new_isolate") || !known.MayExist("new-isolate") {
+		t.Error("New product's ID or slug still ruled out after its event")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestKnownProducts_ShortCircuitsUnknownIDs", true)
+}
+
+// midListProducts runs onList once, after the first List has read its page
+// but before it returns, as an event arriving mid-rebuild would.
+type midListProducts struct {
+	repositories.ProductRepository
+	onList func()
+}
+
+func (r *midListProducts) List(ctx context.Context, filter repositories.ProductFilter) ([]domain.Product, error) {
+	page, err := r.ProductRepository.List(ctx, filter)
+	if r.onList != nil {
+		hook := r.onList
+		r.onList = nil
+		hook()
+	}
+	return page, err
+}
+
+func TestKnownProducts_EventDuringRebuild(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestKnownProducts_EventDuringRebuild", "internal/services")
+
+	testhelpers.LogTestStep(logger, "arrange", "Known products over a catalog with one product")
+	now := time.Now()
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, now)
+	products := &midListProducts{ProductRepository: store.Products()}
+	known := NewKnownProducts(products, logger)
+	ctx := t.Context()
+	if err := known.Rebuild(ctx); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Creating a product after the rebuild has listed the catalog")
+	products.onList = func() {
+		store.PutProduct(domain.Product{ID: "prod_mid_rebuild", Slug: "mid-rebuild", Name: "Mid Rebuild", IsActive: true})
+		if err := known.Handle(ctx, domain.ProductUpdated{ProductID: "prod_mid_rebuild", OccurredAt: now}); err != nil {
+			t.Errorf("Handle failed: %v", err)
+		}
+	}
+	if err := known.Rebuild(ctx); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+
+	testhelpers.LogTestAssertion(logger, "new product known", true, known.MayExist("prod_mid_rebuild"))
+	if !known.MayExist("prod_mid_rebuild") || !known.MayExist("mid-rebuild") {
+		t.Error("Product created mid-rebuild was dropped by the swapped-in filter")
+	}
+	if !known.MayExist(testhelpers.FixtureProductID) {
+		t.Error("Seeded product missing after rebuild")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestKnownProducts_EventDuringRebuild", true)
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+
+	"github.com/yourusername/whey-price-compare/internal/dataloader"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/tracing"
+)
+
+// loaders batches the lookups behind price comparisons for one request.
+// Operations spanning several products (CompareMany, TopDeals, view
+// rebuilds) prefetch every product's keys, so building N comparisons costs a
+// fixed number of queries instead of several per product.
+type loaders struct {
+	products  *dataloader.Loader[string, domain.Product]
+	variants  *dataloader.Loader[string, []domain.Variant]
+	listings  *dataloader.Loader[string, []domain.Listing]
+	retailers *dataloader.Loader[string, domain.Retailer]
+	views     *dataloader.Loader[string, domain.Comparison] // nil without views
+}
+
+type loadersKey struct{}
+
+// withLoaders returns ctx carrying loaders for s's repositories, reusing any
+// ctx already carries so nested calls share one batch.
+func (s *PriceService) withLoaders(ctx context.Context) (context.Context, *loaders) {
+	if l, ok := ctx.Value(loadersKey{}).(*loaders); ok {
+		return ctx, l
+	}
+	l := newLoaders(s.repos, s.views)
+	return context.WithValue(ctx, loadersKey{}, l), l
+}
+
+func newLoaders(repos PriceRepos, views repositories.ViewRepository) *loaders {
+	l := &loaders{
+		products:  dataloader.New(traced("ProductRepository.FindByIDs", repos.Products.FindByIDs)),
+		variants:  dataloader.New(traced("ProductRepository.VariantsByProducts", withEmpty(repos.Products.VariantsByProducts))),
+		retailers: dataloader.New(traced("RetailerRepository.FindByIDs", repos.Retailers.FindByIDs)),
+	}
+	l.listings = dataloader.New(traced("ListingRepository.ByProducts", withEmpty(func(ctx context.Context, productIDs []string) (map[string][]domain.Listing, error) {
+		byProduct, err := repos.Listings.ByProducts(ctx, productIDs)
+		// Every offer needs its retailer's name; queue them all now so the
+		// first name lookup fetches the lot.
+		for _, listings := range byProduct {
+			for _, listing := range listings {
+				l.retailers.Prefetch(listing.RetailerID)
+			}
+		}
+		return byProduct, err
+	})))
+	if views != nil {
+		l.views = dataloader.New(traced("ViewRepository.Comparisons", views.Comparisons))
+	}
+	return l
+}
+
+// prefetch queues everything a comparison of productIDs reads.
+func (l *loaders) prefetch(productIDs ...string) {
+	l.products.Prefetch(productIDs...)
+	l.variants.Prefetch(productIDs...)
+	l.listings.Prefetch(productIDs...)
+	if l.views != nil {
+		l.views.Prefetch(productIDs...)
+	}
+}
+
+// withEmpty adapts a one-to-many batch query so keys with no rows load as an
+// empty slice rather than as not found.
+func withEmpty[V any](fetch dataloader.BatchFunc[string, []V]) dataloader.BatchFunc[string, []V] {
+	return func(ctx context.Context, keys []string) (map[string][]V, error) {
+		out, err := fetch(ctx, keys)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			if _, ok := out[k]; !ok {
+				out[k] = nil
+			}
+		}
+		return out, nil
+	}
+}
+
+// traced records each batch fetch as a span named after the repository
+// method, so a trace shows which queries a comparison waited on.
+func traced[V any](method string, fetch dataloader.BatchFunc[string, V]) dataloader.BatchFunc[string, V] {
+	return func(ctx context.Context, keys []string) (map[string]V, error) {
+		ctx, span := tracing.Start(ctx, method, tracing.Int("batch.keys", len(keys)))
+		defer span.End()
+		out, err := fetch(ctx, keys)
+		span.RecordError(err)
+		return out, err
+	}
+}
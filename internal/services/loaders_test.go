@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// queryCounter counts every repository call made through its wrappers.
+type queryCounter struct{ n atomic.Int64 }
+
+type countingProducts struct {
+	repositories.ProductRepository
+	c *queryCounter
+}
+
+func (r countingProducts) FindByID(ctx context.Context, id string) (*domain.Product, error) {
+	r.c.n.Add(1)
+	return r.ProductRepository.FindByID(ctx, id)
+}
+
+func (r countingProducts) FindByIDs(ctx context.Context, ids []string) (map[string]domain.Product, error) {
+	r.c.n.Add(1)
+	return r.ProductRepository.FindByIDs(ctx, ids)
+}
+
+func (r countingProducts) Variants(ctx context.Context, productID string) ([]domain.Variant, error) {
+	r.c.n.Add(1)
+	return r.ProductRepository.Variants(ctx, productID)
+}
+
+func (r countingProducts) VariantsByProducts(ctx context.Context, ids []string) (map[string][]domain.Variant, error) {
+	r.c.n.Add(1)
+	return r.ProductRepository.VariantsByProducts(ctx, ids)
+}
+
+type countingRetailers struct {
+	repositories.RetailerRepository
+	c *queryCounter
+}
+
+func (r countingRetailers) FindByID(ctx context.Context, id string) (*domain.Retailer, error) {
+	r.c.n.Add(1)
+	return r.RetailerRepository.FindByID(ctx, id)
+}
+
+func (r countingRetailers) FindByIDs(ctx context.Context, ids []string) (map[string]domain.Retailer, error) {
+	r.c.n.Add(1)
+	return r.RetailerRepository.FindByIDs(ctx, ids)
+}
+
+type countingListings struct {
+	repositories.ListingRepository
+	c *queryCounter
+}
+
+func (r countingListings) ByProduct(ctx context.Context, productID string) ([]domain.Listing, error) {
+	r.c.n.Add(1)
+	return r.ListingRepository.ByProduct(ctx, productID)
+}
+
+func (r countingListings) ByProducts(ctx context.Context, ids []string) (map[string][]domain.Listing, error) {
+	r.c.n.Add(1)
+	return r.ListingRepository.ByProducts(ctx, ids)
+}
+
+type countingPrices struct {
+	repositories.PriceRepository
+	c *queryCounter
+}
+
+func (r countingPrices) History(ctx context.Context, ids []string, since time.Time) ([]domain.PricePoint, error) {
+	r.c.n.Add(1)
+	return r.PriceRepository.History(ctx, ids, since)
+}
+
+func TestPriceService_BatchesLookups(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPriceService_BatchesLookups", "internal/services")
+
+	testhelpers.LogTestStep(logger, "arrange", "A catalog of 12 products behind counting repositories")
+	now := time.Now()
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, now)
+	ids := []string{testhelpers.FixtureProductID, testhelpers.FixtureSecondProductID}
+	for i := range 10 {
+		p := domain.Product{ID: "prod_extra_" + string(rune('a'+i)), Name: "Extra", ProteinPerServing: 24, ServingsPerContainer: 30, IsActive: true}
+		store.PutProduct(p)
+		store.PutVariant(domain.Variant{ID: p.ID + "_v", ProductID: p.ID, SizeGrams: 1000, IsActive: true})
+		store.PutListing(domain.Listing{ID: p.ID + "_l", VariantID: p.ID + "_v", RetailerID: "amazon", CurrentPrice: 1999, OriginalPrice: 2999, InStock: true, IsActive: true, LastScrapedAt: now})
+		store.AddPricePoint(domain.PricePoint{ListingID: p.ID + "_l", Price: 2199, InStock: true, RecordedAt: now.Add(-24 * time.Hour)})
+		ids = append(ids, p.ID)
+	}
+	counter := &queryCounter{}
+	svc := NewPriceService(PriceRepos{
+		Products:  countingProducts{store.Products(), counter},
+		Retailers: countingRetailers{store.Retailers(), counter},
+		Listings:  countingListings{store.Listings(), counter},
+		Prices:    countingPrices{store.Prices(), counter},
+	}, logger)
+	svc.now = func() time.Time { return now }
+	ctx := t.Context()
+
+	tests := []struct {
+		name    string
+		run     func() (int, error)
+		want    int
+		maxRuns int64
+	}{
+		{
+			name: "CompareMany",
+			run: func() (int, error) {
+				c, err := svc.CompareMany(ctx, ids)
+				return len(c), err
+			},
+			want: len(ids),
+			// products, variants, listings, retailers
+			maxRuns: 4,
+		},
+		{
+			name: "TopDeals",
+			run: func() (int, error) {
+				d, err := svc.TopDeals(ctx, DealQuery{Limit: MaxDealLimit})
+				return len(d), err
+			},
+			want: len(ids),
+			// variants, listings, retailers, history; the products come
+			// from the list query, which is not counted
+			maxRuns: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter.n.Store(0)
+			got, err := tt.run()
+			if err != nil {
+				t.Fatalf("%s failed: %v", tt.name, err)
+			}
+			queries := counter.n.Load()
+			testhelpers.LogTestAssertion(logger, tt.name+" queries", tt.maxRuns, queries)
+			if got != tt.want {
+				t.Errorf("%s returned %d results, want %d", tt.name, got, tt.want)
+			}
+			if queries > tt.maxRuns {
+				t.Errorf("%s made %d queries for %d products, want at most %d", tt.name, queries, len(ids), tt.maxRuns)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPriceService_BatchesLookups", true)
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// MatchProducts finds the products a free-text query such as a chat
+// command names: one matching its ID or slug exactly, or else those whose
+// brand and name contain every word of query, ignoring case.
+func MatchProducts(products []domain.Product, query string) []domain.Product {
+	q := strings.ToLower(query)
+	for _, p := range products {
+		if p.ID == q || p.Slug == q {
+			return []domain.Product{p}
+		}
+	}
+	words := strings.Fields(q)
+	var out []domain.Product
+	for _, p := range products {
+		haystack := strings.ToLower(p.Brand + " " + p.Name + " " + p.Slug)
+		matched := true
+		for _, w := range words {
+			if !strings.Contains(haystack, w) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			out = append(out, p)
+		}
+	}
+	return out
+}
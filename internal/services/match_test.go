@@ -0,0 +1,51 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestMatchProducts(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestMatchProducts", "internal/services")
+
+	products := []domain.Product{
+		{ID: "prod_on_gsw", Brand: "Optimum Nutrition", Name: "Gold Standard 100% Whey", Slug: "gold-standard-100-whey"},
+		{ID: "prod_mb_biozyme", Brand: "MuscleBlaze", Name: "Biozyme Performance Whey", Slug: "biozyme-performance-whey"},
+		{ID: "prod_on_casein", Brand: "Optimum Nutrition", Name: "Gold Standard 100% Casein", Slug: "gold-standard-100-casein"},
+	}
+	testCases := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"By ID", "prod_mb_biozyme", []string{"prod_mb_biozyme"}},
+		{"By slug", "gold-standard-100-whey", []string{"prod_on_gsw"}},
+		{"Every word, any case", "GOLD whey", []string{"prod_on_gsw"}},
+		{"Brand words", "optimum nutrition", []string{"prod_on_gsw", "prod_on_casein"}},
+		{"Several", "whey", []string{"prod_on_gsw", "prod_mb_biozyme"}},
+		{"No match", "creatine", nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := MatchProducts(products, tc.query)
+			var ids []string
+			for _, p := range got {
+				ids = append(ids, p.ID)
+			}
+			testhelpers.LogTestAssertion(logger, tc.name, tc.want, ids)
+			if len(ids) != len(tc.want) {
+				t.Fatalf("MatchProducts(%q) = %v, want %v", tc.query, ids, tc.want)
+			}
+			for i := range ids {
+				if ids[i] != tc.want[i] {
+					t.Errorf("MatchProducts(%q) = %v, want %v", tc.query, ids, tc.want)
+				}
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestMatchProducts", true)
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// DefaultMaxPresets bounds how many filter presets one user may hold.
+const DefaultMaxPresets = 20
+
+// PresetService manages users' filter presets.
+type PresetService struct {
+	repo       repositories.FilterPresetRepository
+	maxPresets int
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewPresetService creates a PresetService.
+func NewPresetService(repo repositories.FilterPresetRepository, logger *zap.Logger) *PresetService {
+	return &PresetService{repo: repo, maxPresets: DefaultMaxPresets, logger: logger, now: time.Now}
+}
+
+// WithMaxPresets overrides DefaultMaxPresets. It returns s.
+func (s *PresetService) WithMaxPresets(n int) *PresetService {
+	s.maxPresets = n
+	return s
+}
+
+// List returns userID's presets, oldest first.
+func (s *PresetService) List(ctx context.Context, userID string) ([]domain.FilterPreset, error) {
+	return s.repo.UserFilterPresets(ctx, userID)
+}
+
+// Get returns userID's preset id. Other users' presets are reported as not
+// found, so their IDs cannot be probed.
+func (s *PresetService) Get(ctx context.Context, userID, id string) (*domain.FilterPreset, error) {
+	p, err := s.repo.FilterPreset(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.UserID != userID {
+		return nil, fmt.Errorf("filter preset %q: %w", id, domain.ErrNotFound)
+	}
+	return p, nil
+}
+
+// Create saves spec's name, filters and order as a new preset of userID.
+// Names are unique per user, ignoring case.
+func (s *PresetService) Create(ctx context.Context, userID string, spec domain.FilterPreset) (*domain.FilterPreset, error) {
+	now := s.now().UTC()
+	p := domain.FilterPreset{
+		UserID:    userID,
+		Name:      strings.TrimSpace(spec.Name),
+		Filters:   spec.Filters,
+		Sort:      spec.Sort,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.UserFilterPresets(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("load filter presets: %w", err)
+	}
+	if len(existing) >= s.maxPresets {
+		return nil, fmt.Errorf("you can have at most %d filter presets: %w", s.maxPresets, domain.ErrConflict)
+	}
+	if err := checkPresetName(existing, p); err != nil {
+		return nil, err
+	}
+	p, err = s.repo.CreateFilterPreset(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("Filter preset created",
+		zap.String("operation", "CreateFilterPreset"),
+		zap.String("user_id", userID),
+		zap.String("preset_id", p.ID),
+	)
+	return &p, nil
+}
+
+// Update replaces the name, filters and order of userID's preset id with
+// spec's.
+func (s *PresetService) Update(ctx context.Context, userID, id string, spec domain.FilterPreset) (*domain.FilterPreset, error) {
+	p, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	p.Name, p.Filters, p.Sort = strings.TrimSpace(spec.Name), spec.Filters, spec.Sort
+	p.UpdatedAt = s.now().UTC()
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.UserFilterPresets(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("load filter presets: %w", err)
+	}
+	if err := checkPresetName(existing, *p); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveFilterPreset(ctx, *p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Delete removes userID's preset id.
+func (s *PresetService) Delete(ctx context.Context, userID, id string) error {
+	if _, err := s.Get(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteFilterPreset(ctx, id)
+}
+
+// checkPresetName returns domain.ErrConflict if another of existing has
+// p's name.
+func checkPresetName(existing []domain.FilterPreset, p domain.FilterPreset) error {
+	for _, e := range existing {
+		if e.ID != p.ID && strings.EqualFold(e.Name, p.Name) {
+			return fmt.Errorf("a filter preset named %q already exists: %w", p.Name, domain.ErrConflict)
+		}
+	}
+	return nil
+}
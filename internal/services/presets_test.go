@@ -0,0 +1,77 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestPresetService(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPresetService", "internal/services")
+
+	store := memory.NewStore()
+	svc := NewPresetService(store.FilterPresets(), logger).WithMaxPresets(2)
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Creating presets, one invalid and one with a taken name")
+	budget, err := svc.Create(ctx, "user_1", domain.FilterPreset{
+		Name:    " Budget isolate 2kg+ ",
+		Filters: domain.SearchFilters{CategoryIDs: []string{"isolate"}, Weights: []int{2000, 2270}},
+		Sort:    domain.SortPricePerProtein,
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := svc.Create(ctx, "user_1", domain.FilterPreset{Name: "Nothing"}); !errors.Is(err, domain.ErrInvalid) {
+		t.Errorf("Empty preset error = %v, want ErrInvalid", err)
+	}
+	if _, err := svc.Create(ctx, "user_1", domain.FilterPreset{Name: "Vegan", Filters: domain.SearchFilters{Dietary: []string{"keto"}}}); !errors.Is(err, domain.ErrInvalid) {
+		t.Errorf("Unknown dietary error = %v, want ErrInvalid", err)
+	}
+	if _, err := svc.Create(ctx, "user_1", domain.FilterPreset{Name: "budget isolate 2kg+", Sort: domain.SortDiscount}); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Duplicate name error = %v, want ErrConflict", err)
+	}
+	vegan, err := svc.Create(ctx, "user_1", domain.FilterPreset{Name: "Vegan", Filters: domain.SearchFilters{Dietary: []string{domain.DietVegan}}})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := svc.Create(ctx, "user_1", domain.FilterPreset{Name: "Third", Sort: domain.SortDiscount}); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Over the limit error = %v, want ErrConflict", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Presets are trimmed and private to their owner")
+	testhelpers.LogTestAssertion(logger, "name", "Budget isolate 2kg+", budget.Name)
+	if budget.Name != "Budget isolate 2kg+" || budget.CreatedAt.IsZero() {
+		t.Errorf("Created preset = %+v", budget)
+	}
+	if _, err := svc.Get(ctx, "user_2", budget.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Another user's Get error = %v, want ErrNotFound", err)
+	}
+	if err := svc.Delete(ctx, "user_2", budget.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Another user's Delete error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Updating one preset and deleting the other")
+	updated, err := svc.Update(ctx, "user_1", vegan.ID, domain.FilterPreset{Name: "Vegan, cheapest serving", Filters: vegan.Filters, Sort: domain.SortPricePerServing})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Sort != domain.SortPricePerServing || updated.CreatedAt != vegan.CreatedAt {
+		t.Errorf("Updated preset = %+v", updated)
+	}
+	if _, err := svc.Update(ctx, "user_1", vegan.ID, domain.FilterPreset{Name: "BUDGET ISOLATE 2KG+", Sort: domain.SortDiscount}); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Renaming onto a taken name error = %v, want ErrConflict", err)
+	}
+	if err := svc.Delete(ctx, "user_1", budget.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if list, _ := svc.List(ctx, "user_1"); len(list) != 1 || list[0].ID != vegan.ID {
+		t.Errorf("List = %+v, want only the vegan preset", list)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPresetService", true)
+}
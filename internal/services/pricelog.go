@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// MaxPriceLogPage caps how many price log entries one request may read.
+const MaxPriceLogPage = 500
+
+// PriceLogConsumer is a consumer of the price change log and how far it
+// is behind the newest entry.
+type PriceLogConsumer struct {
+	domain.PriceLogCursor
+	Lag int64 `json:"lag"`
+}
+
+// PriceLogStatus is the newest offset of the price change log and where
+// each consumer has read up to.
+type PriceLogStatus struct {
+	Head      int64              `json:"head"`
+	Consumers []PriceLogConsumer `json:"consumers"`
+}
+
+// PriceLogSeek moves a consumer of the price change log. Exactly one of
+// Offset and Since is set: Since replays every entry recorded from then
+// on, as after a bug in the consumer first deployed at Since.
+type PriceLogSeek struct {
+	Offset *int64     `json:"offset,omitempty"`
+	Since  *time.Time `json:"since,omitempty"`
+}
+
+// WithPriceLog lets administrators read the price change log and move its
+// consumers through log. It returns s.
+func (s *AdminService) WithPriceLog(log repositories.PriceLogRepository) *AdminService {
+	s.priceLog = log
+	return s
+}
+
+// PriceLogEnabled reports whether the price change log can be read.
+func (s *AdminService) PriceLogEnabled() bool {
+	return s.priceLog != nil
+}
+
+// PriceLog returns price log entries after filter.After, oldest first.
+func (s *AdminService) PriceLog(ctx context.Context, filter repositories.PriceLogFilter) ([]domain.PriceLogEntry, error) {
+	if filter.Limit == 0 {
+		filter.Limit = 100
+	}
+	if filter.Limit < 1 || filter.Limit > MaxPriceLogPage {
+		return nil, fmt.Errorf("limit must be between 1 and %d: %w", MaxPriceLogPage, domain.ErrInvalid)
+	}
+	if filter.After < 0 {
+		return nil, fmt.Errorf("after must not be negative: %w", domain.ErrInvalid)
+	}
+	entries, err := s.priceLog.Read(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("read price log: %w", err)
+	}
+	if entries == nil {
+		entries = []domain.PriceLogEntry{}
+	}
+	return entries, nil
+}
+
+// PriceLogStatus returns the newest offset and every consumer's cursor.
+func (s *AdminService) PriceLogStatus(ctx context.Context) (*PriceLogStatus, error) {
+	head, err := s.priceLog.Head(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cursors, err := s.priceLog.Cursors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := &PriceLogStatus{Head: head, Consumers: make([]PriceLogConsumer, 0, len(cursors))}
+	for _, c := range cursors {
+		out.Consumers = append(out.Consumers, PriceLogConsumer{PriceLogCursor: c, Lag: max(head-c.Offset, 0)})
+	}
+	return out, nil
+}
+
+// SeekPriceLogConsumer moves a consumer's cursor, back to replay entries
+// it has handled or forward to skip some. The consumer delivers from the
+// new offset on its next poll; a poll in progress commits nothing.
+func (s *AdminService) SeekPriceLogConsumer(ctx context.Context, actor domain.Actor, consumer string, seek PriceLogSeek) (out *domain.PriceLogCursor, err error) {
+	var before *domain.PriceLogCursor
+	defer func() {
+		s.audit(ctx, actor, "seek_price_log_consumer", "price_log_consumer", consumer, before, out, err, nil)
+	}()
+
+	if (seek.Offset == nil) == (seek.Since == nil) {
+		return nil, fmt.Errorf("exactly one of offset and since is required: %w", domain.ErrInvalid)
+	}
+	cursors, err := s.priceLog.Cursors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cursors {
+		if c.Consumer == consumer {
+			before = &c
+		}
+	}
+	if before == nil {
+		return nil, fmt.Errorf("price log consumer %q: %w", consumer, domain.ErrNotFound)
+	}
+	head, err := s.priceLog.Head(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var to int64
+	if seek.Since != nil {
+		if to, err = s.priceLog.OffsetBefore(ctx, *seek.Since); err != nil {
+			return nil, err
+		}
+	} else {
+		to = *seek.Offset
+		if to < 0 || to > head {
+			return nil, fmt.Errorf("offset must be between 0 and %d: %w", head, domain.ErrInvalid)
+		}
+	}
+	at := s.now().UTC()
+	if err := s.priceLog.Commit(ctx, consumer, before.Offset, to, at); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Price log consumer moved",
+		zap.String("operation", "SeekPriceLogConsumer"),
+		zap.String("consumer", consumer),
+		zap.Int64("from", before.Offset),
+		zap.Int64("to", to),
+	)
+	return &domain.PriceLogCursor{Consumer: consumer, Offset: to, UpdatedAt: at}, nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestAdminService_SeekPriceLogConsumer(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAdminService_SeekPriceLogConsumer", "internal/services")
+
+	testhelpers.LogTestStep(logger, "arrange", "Two price corrections, and an alerts consumer that has read the first")
+	svc, store := newTestAdminService(t)
+	svc.WithPriceLog(store.PriceLog())
+	ctx := t.Context()
+	actor := domain.Actor{ID: "alice"}
+	for _, price := range []float64{3100, 3000} {
+		if _, err := svc.CorrectPrice(ctx, actor, testhelpers.FixtureListingAmazon, PriceCorrection{Price: price, Reason: "scraper misread"}); err != nil {
+			t.Fatalf("CorrectPrice: %v", err)
+		}
+	}
+	entries, err := svc.PriceLog(ctx, repositories.PriceLogFilter{ProductID: testhelpers.FixtureProductID})
+	if err != nil || len(entries) != 2 || entries[1].NewPrice != 3000 {
+		t.Fatalf("PriceLog = %+v, %v", entries, err)
+	}
+	if err := store.PriceLog().Commit(ctx, "alerts", 0, entries[0].Offset, time.Now()); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The consumer is one entry behind")
+	status, err := svc.PriceLogStatus(ctx)
+	if err != nil || len(status.Consumers) != 1 {
+		t.Fatalf("PriceLogStatus = %+v, %v", status, err)
+	}
+	testhelpers.LogTestAssertion(logger, "lag", int64(1), status.Consumers[0].Lag)
+	if status.Head != entries[1].Offset || status.Consumers[0].Lag != 1 {
+		t.Errorf("PriceLogStatus = %+v", status)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Rewinding the consumer to before the first correction")
+	since := entries[0].RecordedAt
+	cursor, err := svc.SeekPriceLogConsumer(ctx, actor, "alerts", PriceLogSeek{Since: &since})
+
+	testhelpers.LogTestStep(logger, "assert", "The consumer will replay both corrections, and the move is audited")
+	testhelpers.LogTestAssertion(logger, "offset", int64(0), cursor.Offset)
+	if err != nil || cursor.Offset != 0 {
+		t.Fatalf("SeekPriceLogConsumer = %+v, %v", cursor, err)
+	}
+	audit, _ := svc.AuditLog(ctx, repositories.AuditFilter{Action: "seek_price_log_consumer"})
+	if len(audit) != 1 || !audit[0].Success || audit[0].ResourceID != "alerts" {
+		t.Errorf("audit = %+v", audit)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Unknown consumers, and offsets past the head, are refused")
+	zero, past := int64(0), status.Head+1
+	if _, err := svc.SeekPriceLogConsumer(ctx, actor, "analytics", PriceLogSeek{Offset: &zero}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("unknown consumer err = %v, want ErrNotFound", err)
+	}
+	if _, err := svc.SeekPriceLogConsumer(ctx, actor, "alerts", PriceLogSeek{Offset: &past}); !errors.Is(err, domain.ErrInvalid) {
+		t.Errorf("past head err = %v, want ErrInvalid", err)
+	}
+	if _, err := svc.SeekPriceLogConsumer(ctx, actor, "alerts", PriceLogSeek{Offset: &zero, Since: &since}); !errors.Is(err, domain.ErrInvalid) {
+		t.Errorf("offset and since err = %v, want ErrInvalid", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAdminService_SeekPriceLogConsumer", true)
+}
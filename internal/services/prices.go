@@ -0,0 +1,358 @@
+// Package services implements the business operations behind the API.
+package services
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/cache"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/logctx"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// History query limits from the API specification.
+const (
+	DefaultHistoryDays = 30
+	MaxHistoryDays     = 365
+	// DefaultDailyHistoryAfter is the longest window served point by
+	// point once daily rollups are available.
+	DefaultDailyHistoryAfter = 90
+)
+
+// PriceRepos groups the repositories PriceService reads from.
+type PriceRepos struct {
+	Products  repositories.ProductRepository
+	Retailers repositories.RetailerRepository
+	Listings  repositories.ListingRepository
+	Prices    repositories.PriceRepository
+}
+
+// HistoryQuery selects a price history window.
+type HistoryQuery struct {
+	RetailerID string
+	Days       int
+}
+
+// PriceService builds price comparisons and histories.
+type PriceService struct {
+	repos      PriceRepos
+	cache      cache.Cache
+	policy     cache.Policy
+	views      repositories.ViewRepository
+	known      *KnownProducts
+	popularity *Popularity
+	daily      repositories.DailyPriceRepository
+	dailyAfter int
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewPriceService creates a PriceService.
+func NewPriceService(repos PriceRepos, logger *zap.Logger) *PriceService {
+	return &PriceService{repos: repos, logger: logger, now: time.Now}
+}
+
+// WithCache serves comparisons through c under policy p; changes made
+// through AdminService evict them sooner. It returns s.
+func (s *PriceService) WithCache(c cache.Cache, p cache.Policy) *PriceService {
+	s.cache, s.policy = c, p
+	return s
+}
+
+// WithViews reads comparisons and deals from precomputed rows kept current
+// by a ViewMaintainer, instead of aggregating listings on every request. It
+// returns s.
+func (s *PriceService) WithViews(v repositories.ViewRepository) *PriceService {
+	s.views = v
+	return s
+}
+
+// WithKnownProducts answers lookups of products k rules out as not found
+// without reading anything. It returns s.
+func (s *PriceService) WithKnownProducts(k *KnownProducts) *PriceService {
+	s.known = k
+	return s
+}
+
+// WithPopularity counts every comparison served in p, which picks the
+// products the cache warmer keeps hot. It returns s.
+func (s *PriceService) WithPopularity(p *Popularity) *PriceService {
+	s.popularity = p
+	return s
+}
+
+// WithDailyHistory serves history windows longer than afterDays from
+// daily rollups, one point per listing and day, instead of every
+// observation. It returns s.
+func (s *PriceService) WithDailyHistory(daily repositories.DailyPriceRepository, afterDays int) *PriceService {
+	s.daily, s.dailyAfter = daily, afterDays
+	return s
+}
+
+// Compare returns the current offers for a product across all retailers.
+func (s *PriceService) Compare(ctx context.Context, productID string) (*domain.Comparison, error) {
+	if s.known != nil && !s.known.MayExist(productID) {
+		return nil, unknownProduct(productID)
+	}
+	c, err := s.cachedCompare(ctx, productID)
+	if err == nil && s.popularity != nil {
+		s.popularity.Record(productID)
+	}
+	return c, err
+}
+
+// cachedCompare reads a comparison through the cache without counting it as
+// a view, for the cache warmer.
+func (s *PriceService) cachedCompare(ctx context.Context, productID string) (*domain.Comparison, error) {
+	return cache.Fetch(ctx, s.cache, cache.ComparisonKey(productID), s.policy, s.logger, func(ctx context.Context) (*domain.Comparison, error) {
+		ctx, ld := s.withLoaders(ctx)
+		if ld.views != nil {
+			c, err := ld.views.Load(ctx, productID)
+			if err == nil {
+				// Loaded values are shared; handlers localize offers in place.
+				c.Prices = slices.Clone(c.Prices)
+				return &c, nil
+			}
+			if !errors.Is(err, domain.ErrNotFound) {
+				logctx.Logger(ctx, s.logger).Warn("Comparison view read failed, aggregating instead",
+					zap.String("operation", "Compare"),
+					zap.String("product_id", productID),
+					zap.Error(err),
+				)
+			}
+		}
+		return s.compare(ctx, productID)
+	})
+}
+
+// CompareMany returns comparisons for several products, in order, loading
+// whatever the cache cannot answer in one batch per table.
+func (s *PriceService) CompareMany(ctx context.Context, productIDs []string) ([]*domain.Comparison, error) {
+	ctx, ld := s.withLoaders(ctx)
+	ld.prefetch(productIDs...)
+	out := make([]*domain.Comparison, 0, len(productIDs))
+	for _, id := range productIDs {
+		c, err := s.Compare(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// Peek is CompareMany for lists such as search results: showing a product
+// among many does not count as a view of it, and products that are no
+// longer listed are left out rather than failing the list.
+func (s *PriceService) Peek(ctx context.Context, productIDs []string) ([]*domain.Comparison, error) {
+	ctx, ld := s.withLoaders(ctx)
+	ld.prefetch(productIDs...)
+	out := make([]*domain.Comparison, 0, len(productIDs))
+	for _, id := range productIDs {
+		c, err := s.cachedCompare(ctx, id)
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// EachComparison calls fn with the comparison of every active product that
+// filter's brand and category select, reading a page of products at a
+// time. The filter's Limit and Offset are ignored.
+func (s *PriceService) EachComparison(ctx context.Context, filter repositories.ProductFilter, fn func(*domain.Comparison)) error {
+	filter.Limit = dealScanPageSize
+	for filter.Offset = 0; ; filter.Offset += dealScanPageSize {
+		products, err := s.repos.Products.List(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("list products: %w", err)
+		}
+		comparisons, err := s.compareProducts(ctx, products)
+		if err != nil {
+			return err
+		}
+		for _, c := range comparisons {
+			fn(c)
+		}
+		if len(products) < dealScanPageSize {
+			return nil
+		}
+	}
+}
+
+func (s *PriceService) compare(ctx context.Context, productID string) (*domain.Comparison, error) {
+	logger := s.logger.With(
+		zap.String("operation", "Compare"),
+		zap.String("product_id", productID),
+	)
+	logger.Debug("Building price comparison")
+
+	ctx, ld := s.withLoaders(ctx)
+	product, err := ld.products.Load(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	variantList, err := ld.variants.Load(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("load variants: %w", err)
+	}
+	variants := make(map[string]domain.Variant, len(variantList))
+	for _, v := range variantList {
+		variants[v.ID] = v
+	}
+	listings, err := ld.listings.Load(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("load listings: %w", err)
+	}
+
+	offers := make([]domain.Offer, 0, len(listings))
+	for _, l := range listings {
+		if l.CurrentPrice <= 0 {
+			continue
+		}
+		name := l.RetailerID
+		if r, err := ld.retailers.Load(ctx, l.RetailerID); err == nil {
+			name = r.Name
+		}
+		v := variants[l.VariantID]
+		currency := l.Currency
+		if currency == "" {
+			currency = domain.DefaultCurrency
+		}
+		offers = append(offers, domain.Offer{
+			ListingID:           l.ID,
+			RetailerID:          l.RetailerID,
+			RetailerName:        name,
+			VariantID:           l.VariantID,
+			Flavor:              v.Flavor,
+			SizeGrams:           v.SizeGrams,
+			Price:               l.CurrentPrice,
+			OriginalPrice:       l.OriginalPrice,
+			DiscountPercent:     domain.DiscountPercent(l.CurrentPrice, l.OriginalPrice),
+			Currency:            currency,
+			InStock:             l.InStock,
+			URL:                 l.URL,
+			BuyURL:              httpx.OutboundPath(l.RetailerID, productID, l.ID),
+			PricePerGramProtein: domain.PricePerGramProtein(l.CurrentPrice, product.ProteinGrams(v.SizeGrams)),
+			LastUpdated:         l.LastScrapedAt,
+		})
+	}
+
+	comparison := domain.NewComparison(product, offers)
+	logger.Debug("Price comparison built",
+		zap.Int("offers", len(comparison.Prices)),
+		zap.Float64("lowest_price", comparison.Stats.LowestPrice),
+	)
+	return comparison, nil
+}
+
+// History returns the price series for a product over the requested window.
+func (s *PriceService) History(ctx context.Context, productID string, q HistoryQuery) (*domain.PriceHistory, error) {
+	if q.Days == 0 {
+		q.Days = DefaultHistoryDays
+	}
+	if q.Days < 1 || q.Days > MaxHistoryDays {
+		return nil, fmt.Errorf("days must be between 1 and %d: %w", MaxHistoryDays, domain.ErrInvalid)
+	}
+	if s.known != nil && !s.known.MayExist(productID) {
+		return nil, unknownProduct(productID)
+	}
+
+	logger := s.logger.With(
+		zap.String("operation", "History"),
+		zap.String("product_id", productID),
+		zap.String("retailer_id", q.RetailerID),
+		zap.Int("days", q.Days),
+	)
+	logger.Debug("Loading price history")
+
+	if _, err := s.repos.Products.FindByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	listings, err := s.repos.Listings.ByProduct(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("load listings: %w", err)
+	}
+
+	byID := make(map[string]domain.Listing, len(listings))
+	ids := make([]string, 0, len(listings))
+	for _, l := range listings {
+		if q.RetailerID != "" && l.RetailerID != q.RetailerID {
+			continue
+		}
+		byID[l.ID] = l
+		ids = append(ids, l.ID)
+	}
+
+	history := &domain.PriceHistory{
+		ProductID:  productID,
+		RetailerID: q.RetailerID,
+		Days:       q.Days,
+		Interval:   domain.IntervalRaw,
+	}
+	since := s.now().AddDate(0, 0, -q.Days)
+	if s.daily != nil && q.Days > s.dailyAfter {
+		// The first day is whole, so its range is not cut short.
+		days, err := s.daily.DailyHistory(ctx, ids, since.UTC().Truncate(24*time.Hour))
+		if err != nil {
+			return nil, fmt.Errorf("load daily price history: %w", err)
+		}
+		history.Interval = domain.IntervalDay
+		history.Points = make([]domain.HistoryPoint, 0, len(days))
+		for _, d := range days {
+			l := byID[d.ListingID]
+			history.Points = append(history.Points, domain.HistoryPoint{
+				RecordedAt: d.Day,
+				RetailerID: l.RetailerID,
+				VariantID:  l.VariantID,
+				ListingID:  d.ListingID,
+				Price:      d.ClosePrice,
+				Low:        d.MinPrice,
+				High:       d.MaxPrice,
+				Currency:   cmp.Or(d.Currency, domain.DefaultCurrency),
+				InStock:    d.InStock,
+			})
+		}
+		history.Stats = domain.NewHistoryStats(history.Points)
+		logger.Debug("Daily price history loaded", zap.Int("points", len(history.Points)))
+		return history, nil
+	}
+
+	points, err := s.repos.Prices.History(ctx, ids, since)
+	if err != nil {
+		return nil, fmt.Errorf("load price history: %w", err)
+	}
+	history.Points = make([]domain.HistoryPoint, 0, len(points))
+	for _, p := range points {
+		l := byID[p.ListingID]
+		currency := p.Currency
+		if currency == "" {
+			currency = domain.DefaultCurrency
+		}
+		history.Points = append(history.Points, domain.HistoryPoint{
+			RecordedAt: p.RecordedAt,
+			RetailerID: l.RetailerID,
+			VariantID:  l.VariantID,
+			ListingID:  p.ListingID,
+			Price:      p.Price,
+			Currency:   currency,
+			InStock:    p.InStock,
+		})
+	}
+	history.Stats = domain.NewHistoryStats(history.Points)
+
+	logger.Debug("Price history loaded", zap.Int("points", len(history.Points)))
+	return history, nil
+}
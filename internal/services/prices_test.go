@@ -0,0 +1,346 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/cache"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/events"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/repositories/mocks"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func newTestPriceService(t *testing.T, logger *zap.Logger, now time.Time) *PriceService {
+	t.Helper()
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, now)
+	svc := NewPriceService(PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+	svc.now = func() time.Time { return now }
+	return svc
+}
+
+func TestPriceService_Compare(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPriceService_Compare", "internal/services")
+
+	svc := newTestPriceService(t, logger, time.Now())
+
+	testhelpers.LogTestStep(logger, "act", "Comparing fixture product")
+	c, err := svc.Compare(t.Context(), testhelpers.FixtureProductID)
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Flipkart is cheapest, HealthKart out of stock last")
+	if len(c.Prices) != 3 {
+		t.Fatalf("Got %d offers, want 3", len(c.Prices))
+	}
+	if c.BestDeal == nil || c.BestDeal.RetailerID != "flipkart" || c.BestDeal.Price != 3199 {
+		t.Errorf("BestDeal = %+v", c.BestDeal)
+	}
+	if c.BestDeal.RetailerName != "Flipkart" || c.BestDeal.Flavor != "Double Rich Chocolate" {
+		t.Errorf("Offer not enriched: %+v", c.BestDeal)
+	}
+	if c.BestDeal.DiscountPercent != 20.01 {
+		t.Errorf("DiscountPercent = %v, want 20.01", c.BestDeal.DiscountPercent)
+	}
+	if c.BestDeal.PricePerGramProtein <= 0 {
+		t.Error("PricePerGramProtein must be computed")
+	}
+	if last := c.Prices[len(c.Prices)-1]; last.RetailerID != "healthkart" || last.InStock {
+		t.Errorf("Last offer = %+v, want out-of-stock healthkart", last)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPriceService_Compare", true)
+}
+
+// mapCache is an in-memory cache.Cache for exercising cached reads.
+type mapCache struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func newMapCache() *mapCache { return &mapCache{data: map[string][]byte{}} }
+
+func (m *mapCache) Get(_ context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if v, ok := m.data[key]; ok {
+		return v, nil
+	}
+	return nil, cache.ErrMiss
+}
+
+func (m *mapCache) Set(_ context.Context, key string, value []byte, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = value
+	return nil
+}
+
+func (m *mapCache) Delete(_ context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, k := range keys {
+		delete(m.data, k)
+	}
+	return nil
+}
+
+func TestPriceService_CompareCachedUntilPriceEvent(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPriceService_CompareCachedUntilPriceEvent", "internal/services")
+
+	testhelpers.LogTestStep(logger, "arrange", "Price and admin services sharing one cache")
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	c := newMapCache()
+	bus := events.NewBus(logger)
+	invalidator := cache.NewInvalidator(c)
+	bus.Subscribe(invalidator.Handle, invalidator.EventTypes()...)
+	prices := NewPriceService(PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger).WithCache(c, cache.Policy{TTL: time.Minute})
+	admin := NewAdminService(AdminRepos{
+		Catalog:   store.CatalogAdmin(),
+		Selectors: store.Selectors(),
+		Prices:    store.PriceWriter(),
+		Audit:     store.Audit(),
+	}, logger).WithRelay(events.NewRelay(events.DefaultRelayConfig(), store.Outbox(), bus, logger))
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Warming the cache, then changing the store behind it")
+	if _, err := prices.Compare(ctx, testhelpers.FixtureProductID); err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+	store.AddPricePoint(domain.PricePoint{ListingID: testhelpers.FixtureListingAmazon, Price: 2999, InStock: true, RecordedAt: time.Now()})
+	cached, err := prices.Compare(ctx, testhelpers.FixtureProductID)
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The cached comparison is served until a correction evicts it")
+	testhelpers.LogTestAssertion(logger, "cached best price", 3199.0, cached.BestDeal.Price)
+	if cached.BestDeal.Price != 3199 {
+		t.Errorf("Cached best price = %v, want 3199", cached.BestDeal.Price)
+	}
+	if _, err := admin.CorrectPrice(ctx, domain.Actor{ID: "alice"}, testhelpers.FixtureListingFlipkart, PriceCorrection{Price: 2899, Reason: "scraper misread"}); err != nil {
+		t.Fatalf("CorrectPrice failed: %v", err)
+	}
+	fresh, err := prices.Compare(ctx, testhelpers.FixtureProductID)
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+	testhelpers.LogTestAssertion(logger, "fresh best price", 2899.0, fresh.BestDeal.Price)
+	if fresh.BestDeal.Price != 2899 || fresh.BestDeal.RetailerID != "flipkart" {
+		t.Errorf("Best deal after correction = %+v", fresh.BestDeal)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPriceService_CompareCachedUntilPriceEvent", true)
+}
+
+func TestPriceService_CompareNotFound(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPriceService_CompareNotFound", "internal/services")
+
+	svc := newTestPriceService(t, logger, time.Now())
+	_, err := svc.Compare(t.Context(), "prod_missing")
+	testhelpers.LogTestAssertion(logger, "not found error", domain.ErrNotFound, err)
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("error = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPriceService_CompareNotFound", true)
+}
+
+func TestPriceService_EachComparison(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPriceService_EachComparison", "internal/services")
+
+	svc := newTestPriceService(t, logger, time.Now())
+	testCases := []struct {
+		name   string
+		filter repositories.ProductFilter
+		want   []string
+	}{
+		{"Whole catalog", repositories.ProductFilter{Limit: 1}, []string{testhelpers.FixtureSecondProductID, testhelpers.FixtureProductID}},
+		{"Brand", repositories.ProductFilter{BrandID: "muscleblaze"}, []string{testhelpers.FixtureSecondProductID}},
+		{"Unknown category", repositories.ProductFilter{CategoryID: "casein"}, nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			err := svc.EachComparison(t.Context(), tc.filter, func(c *domain.Comparison) { got = append(got, c.Product.ID) })
+			slices.Sort(got)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.want, got)
+			if err != nil || !slices.Equal(got, tc.want) {
+				t.Errorf("EachComparison = %v, %v; want %v", got, err, tc.want)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPriceService_EachComparison", true)
+}
+
+func TestPriceService_History(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPriceService_History", "internal/services")
+
+	svc := newTestPriceService(t, logger, time.Now())
+	ctx := t.Context()
+
+	testCases := []struct {
+		name       string
+		query      HistoryQuery
+		wantPoints int
+		wantErr    error
+	}{
+		{"Default window, all retailers", HistoryQuery{}, 21, nil},
+		{"Single retailer", HistoryQuery{RetailerID: "amazon"}, 7, nil},
+		{"Three days", HistoryQuery{Days: 3}, 9, nil},
+		{"Window too large", HistoryQuery{Days: 400}, 0, domain.ErrInvalid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := svc.History(ctx, testhelpers.FixtureProductID, tc.query)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("History failed: %v", err)
+			}
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantPoints, len(h.Points))
+			if len(h.Points) != tc.wantPoints {
+				t.Errorf("Got %d points, want %d", len(h.Points), tc.wantPoints)
+			}
+			for _, p := range h.Points {
+				if tc.query.RetailerID != "" && p.RetailerID != tc.query.RetailerID {
+					t.Errorf("Point from %s leaked into %s history", p.RetailerID, tc.query.RetailerID)
+				}
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPriceService_History", true)
+}
+
+func TestPriceService_HistoryDaily(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPriceService_HistoryDaily", "internal/services")
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, now)
+	store.AddPricePoint(domain.PricePoint{ListingID: testhelpers.FixtureListingAmazon, Price: 3199, InStock: true, RecordedAt: now.Add(-30 * time.Minute)})
+	svc := NewPriceService(PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger).WithDailyHistory(store.DailyPrices(), 5)
+	svc.now = func() time.Time { return now }
+
+	testhelpers.LogTestStep(logger, "act", "A window within and one beyond the daily threshold")
+	short, err := svc.History(t.Context(), testhelpers.FixtureProductID, HistoryQuery{Days: 3})
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	long, err := svc.History(t.Context(), testhelpers.FixtureProductID, HistoryQuery{Days: 7})
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Only the long window is rolled up by day")
+	testhelpers.LogTestAssertion(logger, "intervals", domain.IntervalRaw+","+domain.IntervalDay, short.Interval+","+long.Interval)
+	if short.Interval != domain.IntervalRaw || len(short.Points) != 10 {
+		t.Errorf("Short window = %s with %d points, want raw with 10", short.Interval, len(short.Points))
+	}
+	if long.Interval != domain.IntervalDay || len(long.Points) != 21 {
+		t.Fatalf("Long window = %s with %d points, want day with 21", long.Interval, len(long.Points))
+	}
+	last := long.Points[len(long.Points)-3]
+	if last.ListingID != testhelpers.FixtureListingAmazon || !last.RecordedAt.Equal(now.Truncate(24*time.Hour)) ||
+		last.Price != 3199 || last.Low != 3199 || last.High != 3299 || last.RetailerID != "amazon" {
+		t.Errorf("Today's Amazon point = %+v, want close 3199 in 3199-3299", last)
+	}
+	if long.Stats.MinPrice != 3199 || long.Stats.MaxPrice != 3499 {
+		t.Errorf("Stats range = %v-%v, want 3199-3499", long.Stats.MinPrice, long.Stats.MaxPrice)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPriceService_HistoryDaily", true)
+}
+
+func TestPriceService_HistoryRepositoryCalls(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPriceService_HistoryRepositoryCalls", "internal/services")
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	newService := func(t *testing.T) (*PriceService, *mocks.MockProductRepository, *mocks.MockListingRepository, *mocks.MockPriceRepository) {
+		ctrl := gomock.NewController(t)
+		products := mocks.NewMockProductRepository(ctrl)
+		listings := mocks.NewMockListingRepository(ctrl)
+		prices := mocks.NewMockPriceRepository(ctrl)
+		svc := NewPriceService(PriceRepos{Products: products, Listings: listings, Prices: prices}, logger)
+		svc.now = func() time.Time { return now }
+		return svc, products, listings, prices
+	}
+
+	t.Run("Unknown product reads nothing else", func(t *testing.T) {
+		testhelpers.LogTestStep(logger, "arrange", "A product repository that does not have the product")
+		svc, products, _, _ := newService(t)
+		products.EXPECT().FindByID(gomock.Any(), "prod_gone").Return(nil, domain.ErrNotFound)
+
+		testhelpers.LogTestStep(logger, "act", "Reading its history")
+		_, err := svc.History(t.Context(), "prod_gone", HistoryQuery{})
+
+		testhelpers.LogTestStep(logger, "assert", "Not found, without listings or prices read")
+		if !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("History = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("Price read failure", func(t *testing.T) {
+		testhelpers.LogTestStep(logger, "arrange", "Two listings and a price repository that fails")
+		svc, products, listings, prices := newService(t)
+		products.EXPECT().FindByID(gomock.Any(), "prod_1").Return(&domain.Product{ID: "prod_1"}, nil)
+		listings.EXPECT().ByProduct(gomock.Any(), "prod_1").Return([]domain.Listing{
+			{ID: "listing_amazon", RetailerID: "amazon"},
+			{ID: "listing_flipkart", RetailerID: "flipkart"},
+		}, nil)
+		down := errors.New("connection reset")
+		prices.EXPECT().History(gomock.Any(), []string{"listing_amazon"}, now.AddDate(0, 0, -3)).Return(nil, down)
+
+		testhelpers.LogTestStep(logger, "act", "Reading three days of one retailer's history")
+		_, err := svc.History(t.Context(), "prod_1", HistoryQuery{Days: 3, RetailerID: "amazon"})
+
+		testhelpers.LogTestStep(logger, "assert", "Only that retailer's listing is read, and the failure is returned")
+		testhelpers.LogTestAssertion(logger, "error", down, err)
+		if !errors.Is(err, down) {
+			t.Errorf("History = %v, want the repository's error", err)
+		}
+	})
+
+	testhelpers.LogTestComplete(logger, "TestPriceService_HistoryRepositoryCalls", true)
+}
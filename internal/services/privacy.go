@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// ErrExportExpired is returned for a finished export past its download
+// window.
+var ErrExportExpired = fmt.Errorf("export has expired, request a new one: %w", domain.ErrNotFound)
+
+// PrivacyConfig configures the PrivacyService.
+type PrivacyConfig struct {
+	// PollInterval is how often Run looks for queued requests it was not
+	// woken for, e.g. after a restart.
+	PollInterval time.Duration
+	// ExportTTL is how long a finished export can be downloaded.
+	ExportTTL time.Duration
+	// BatchSize bounds the requests handled per pass.
+	BatchSize int
+}
+
+// DefaultPrivacyConfig keeps exports downloadable for a week.
+func DefaultPrivacyConfig() PrivacyConfig {
+	return PrivacyConfig{PollInterval: time.Minute, ExportTTL: 7 * 24 * time.Hour, BatchSize: 10}
+}
+
+// PrivacyRepos groups the repositories the PrivacyService uses.
+type PrivacyRepos struct {
+	Data     repositories.UserDataRepository
+	Requests repositories.DataRequestRepository
+	Audit    repositories.AuditRepository
+}
+
+// PrivacyService carries out users' data-rights requests: exporting
+// everything stored about them as JSON, and erasing it. Requests are
+// queued and handled in the background by Run, and each request and its
+// outcome is written to the audit log.
+type PrivacyService struct {
+	cfg    PrivacyConfig
+	repos  PrivacyRepos
+	logger *zap.Logger
+	now    func() time.Time
+	wake   chan struct{}
+}
+
+// NewPrivacyService creates a PrivacyService. Call Run to handle requests.
+func NewPrivacyService(cfg PrivacyConfig, repos PrivacyRepos, logger *zap.Logger) *PrivacyService {
+	def := DefaultPrivacyConfig()
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = def.PollInterval
+	}
+	if cfg.ExportTTL <= 0 {
+		cfg.ExportTTL = def.ExportTTL
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = def.BatchSize
+	}
+	return &PrivacyService{cfg: cfg, repos: repos, logger: logger, now: time.Now, wake: make(chan struct{}, 1)}
+}
+
+// RequestExport queues an export of the actor's data.
+func (s *PrivacyService) RequestExport(ctx context.Context, actor domain.Actor) (*domain.DataRequest, error) {
+	return s.request(ctx, actor, domain.DataRequestExport)
+}
+
+// RequestDeletion queues the erasure of the actor's account and data.
+func (s *PrivacyService) RequestDeletion(ctx context.Context, actor domain.Actor) (*domain.DataRequest, error) {
+	return s.request(ctx, actor, domain.DataRequestDelete)
+}
+
+func (s *PrivacyService) request(ctx context.Context, actor domain.Actor, kind string) (*domain.DataRequest, error) {
+	existing, err := s.repos.Requests.UserDataRequests(ctx, actor.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list data requests: %w", err)
+	}
+	for _, r := range existing {
+		if r.Status == domain.DataRequestPending {
+			return nil, fmt.Errorf("a %s request is already in progress: %w", r.Kind, domain.ErrConflict)
+		}
+	}
+	req, err := s.repos.Requests.CreateDataRequest(ctx, domain.DataRequest{
+		UserID:    actor.ID,
+		Kind:      kind,
+		Status:    domain.DataRequestPending,
+		CreatedAt: s.now().UTC(),
+	})
+	s.audit(ctx, actor, "request_"+kind, req.ID, err, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create data request: %w", err)
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return &req, nil
+}
+
+// Requests returns userID's requests, newest first.
+func (s *PrivacyService) Requests(ctx context.Context, userID string) ([]domain.DataRequest, error) {
+	return s.repos.Requests.UserDataRequests(ctx, userID)
+}
+
+// Export returns userID's finished export with id, as JSON.
+func (s *PrivacyService) Export(ctx context.Context, userID, id string) ([]byte, error) {
+	req, err := s.repos.Requests.DataRequest(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserID != userID || req.Kind != domain.DataRequestExport {
+		return nil, fmt.Errorf("export %s: %w", id, domain.ErrNotFound)
+	}
+	switch {
+	case req.Status == domain.DataRequestPending:
+		return nil, fmt.Errorf("export is not ready yet: %w", domain.ErrConflict)
+	case req.Status == domain.DataRequestFailed:
+		return nil, fmt.Errorf("export failed, request a new one: %w", domain.ErrNotFound)
+	case req.Result == nil || req.ExpiresAt != nil && !s.now().Before(*req.ExpiresAt):
+		return nil, ErrExportExpired
+	}
+	return req.Result, nil
+}
+
+// Run handles queued requests as they arrive, and at least every
+// PollInterval, until ctx is done.
+func (s *PrivacyService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.wake:
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		s.Process(ctx)
+	}
+}
+
+// Process handles the pending requests and drops expired exports. It
+// returns how many requests it handled.
+func (s *PrivacyService) Process(ctx context.Context) int {
+	if n, err := s.repos.Requests.PurgeExports(ctx, s.now().UTC()); err != nil {
+		s.logger.Error("Purging expired exports failed", zap.String("operation", "ProcessDataRequests"), zap.Error(err))
+	} else if n > 0 {
+		s.logger.Info("Expired exports purged", zap.String("operation", "ProcessDataRequests"), zap.Int("count", n))
+	}
+	handled := 0
+	for ctx.Err() == nil {
+		pending, err := s.repos.Requests.PendingDataRequests(ctx, s.cfg.BatchSize)
+		if err != nil {
+			s.logger.Error("Loading data requests failed", zap.String("operation", "ProcessDataRequests"), zap.Error(err))
+			return handled
+		}
+		if len(pending) == 0 {
+			return handled
+		}
+		for _, req := range pending {
+			handled++
+			if !s.handle(ctx, req) {
+				// It is still pending; stop rather than loop on it.
+				return handled
+			}
+		}
+	}
+	return handled
+}
+
+// handle carries out req and reports whether its outcome was saved.
+func (s *PrivacyService) handle(ctx context.Context, req domain.DataRequest) bool {
+	var (
+		extra map[string]any
+		err   error
+	)
+	switch req.Kind {
+	case domain.DataRequestExport:
+		err = s.export(ctx, &req)
+	case domain.DataRequestDelete:
+		var removed map[string]int
+		removed, err = s.repos.Data.DeleteUserData(ctx, req.UserID)
+		if errors.Is(err, domain.ErrNotFound) {
+			// Already gone, e.g. a retry after a crash mid-way.
+			err = nil
+		}
+		extra = map[string]any{"removed": removed}
+	default:
+		err = fmt.Errorf("unknown data request kind %q", req.Kind)
+	}
+
+	now := s.now().UTC()
+	req.CompletedAt = &now
+	req.Status = domain.DataRequestDone
+	if err != nil {
+		req.Status, req.Error = domain.DataRequestFailed, "could not be completed, please try again"
+		s.logger.Error("Data request failed",
+			zap.String("operation", "ProcessDataRequests"),
+			zap.String("request_id", req.ID),
+			zap.String("kind", req.Kind),
+			zap.Error(err),
+		)
+	}
+	s.audit(ctx, domain.Actor{ID: req.UserID}, req.Kind+"_user_data", req.ID, err, extra)
+	if err := s.repos.Requests.SaveDataRequest(ctx, req); err != nil {
+		s.logger.Error("Saving data request failed",
+			zap.String("operation", "ProcessDataRequests"),
+			zap.String("request_id", req.ID),
+			zap.Error(err),
+		)
+		return false
+	}
+	s.logger.Info("Data request handled",
+		zap.String("operation", "ProcessDataRequests"),
+		zap.String("request_id", req.ID),
+		zap.String("kind", req.Kind),
+		zap.String("status", req.Status),
+	)
+	return true
+}
+
+func (s *PrivacyService) export(ctx context.Context, req *domain.DataRequest) error {
+	data, err := s.repos.Data.ExportUserData(ctx, req.UserID)
+	if err != nil {
+		return fmt.Errorf("export user data: %w", err)
+	}
+	now := s.now().UTC()
+	data.ExportedAt = now
+	req.Result, err = json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode user data: %w", err)
+	}
+	expires := now.Add(s.cfg.ExportTTL)
+	req.ExpiresAt = &expires
+	return nil
+}
+
+// audit records a data-rights action on the user the request is for. It
+// never records the data itself.
+func (s *PrivacyService) audit(ctx context.Context, actor domain.Actor, action, requestID string, err error, extra map[string]any) {
+	metadata := map[string]any{"data_request_id": requestID}
+	for k, v := range extra {
+		metadata[k] = v
+	}
+	entry := domain.AuditEntry{
+		ActorID:      actor.ID,
+		Action:       action,
+		ResourceType: "user",
+		ResourceID:   actor.ID,
+		IPAddress:    actor.IPAddress,
+		UserAgent:    actor.UserAgent,
+		HTTPMethod:   actor.Method,
+		Endpoint:     actor.Endpoint,
+		RequestID:    actor.RequestID,
+		Success:      err == nil,
+		Metadata:     metadata,
+		CreatedAt:    s.now().UTC(),
+	}
+	if err != nil {
+		entry.ErrorMessage = err.Error()
+	}
+	// Record even if the request was cancelled mid-way.
+	if auditErr := s.repos.Audit.Append(context.WithoutCancel(ctx), entry); auditErr != nil {
+		s.logger.Error("Failed to write audit log entry",
+			zap.String("operation", "Audit"),
+			zap.String("action", action),
+			zap.String("actor_id", actor.ID),
+			zap.Error(auditErr),
+		)
+	}
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func newTestPrivacyService(t *testing.T) (*PrivacyService, *memory.Store, string) {
+	t.Helper()
+	store := memory.NewStore()
+	u, err := store.Users().CreateUser(t.Context(), domain.User{Email: "asha@example.com", CreatedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if _, err := store.Alerts().CreateAlert(t.Context(), domain.PriceAlert{UserID: u.ID, ProductID: "prod_1"}); err != nil {
+		t.Fatalf("CreateAlert: %v", err)
+	}
+	svc := NewPrivacyService(DefaultPrivacyConfig(), PrivacyRepos{
+		Data:     store.UserData(),
+		Requests: store.DataRequests(),
+		Audit:    store.Audit(),
+	}, testhelpers.SetupTestLogger(t))
+	return svc, store, u.ID
+}
+
+func TestPrivacyService_Export(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPrivacyService_Export", "internal/services")
+
+	svc, _, userID := newTestPrivacyService(t)
+	now := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := t.Context()
+	actor := domain.Actor{ID: userID, IPAddress: "203.0.113.7"}
+
+	testhelpers.LogTestStep(logger, "act", "Requesting an export twice before it is handled")
+	req, err := svc.RequestExport(ctx, actor)
+	if err != nil || req.Status != domain.DataRequestPending {
+		t.Fatalf("RequestExport = %+v, %v", req, err)
+	}
+	if _, err := svc.RequestDeletion(ctx, actor); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Second request error = %v, want ErrConflict", err)
+	}
+	if _, err := svc.Export(ctx, userID, req.ID); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Export while pending error = %v, want ErrConflict", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Processing the queue")
+	handled := svc.Process(ctx)
+	testhelpers.LogTestAssertion(logger, "handled", 1, handled)
+	if handled != 1 {
+		t.Fatalf("Process handled %d, want 1", handled)
+	}
+	raw, err := svc.Export(ctx, userID, req.ID)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	var data domain.UserData
+	if err := json.Unmarshal(raw, &data); err != nil || data.Account.ID != userID || len(data.Alerts) != 1 || !data.ExportedAt.Equal(now) {
+		t.Errorf("Export = %s, %v", raw, err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Only the owner can download it, and only until it expires")
+	testCases := []struct {
+		name    string
+		userID  string
+		id      string
+		after   time.Duration
+		wantErr error
+	}{
+		{"Other user", "user_other", req.ID, 0, domain.ErrNotFound},
+		{"Unknown request", userID, "datareq_missing", 0, domain.ErrNotFound},
+		{"Expired", userID, req.ID, svc.cfg.ExportTTL, ErrExportExpired},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc.now = func() time.Time { return now.Add(tc.after) }
+			_, err := svc.Export(ctx, tc.userID, tc.id)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Export error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPrivacyService_Export", true)
+}
+
+func TestPrivacyService_Deletion(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPrivacyService_Deletion", "internal/services")
+
+	svc, store, userID := newTestPrivacyService(t)
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Requesting and processing a deletion")
+	req, err := svc.RequestDeletion(ctx, domain.Actor{ID: userID, RequestID: "req-1"})
+	if err != nil {
+		t.Fatalf("RequestDeletion: %v", err)
+	}
+	if handled := svc.Process(ctx); handled != 1 {
+		t.Fatalf("Process handled %d, want 1", handled)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The account is gone and the request and its outcome are audited")
+	if _, err := store.Users().UserByID(ctx, userID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("UserByID after deletion error = %v, want ErrNotFound", err)
+	}
+	reqs, _ := svc.Requests(ctx, userID)
+	if len(reqs) != 1 || reqs[0].Status != domain.DataRequestDone || reqs[0].CompletedAt == nil {
+		t.Errorf("Requests = %+v, want the deletion done", reqs)
+	}
+	entries, _ := store.Audit().List(ctx, repositories.AuditFilter{ResourceID: userID})
+	testhelpers.LogTestAssertion(logger, "audit entries", 2, len(entries))
+	if len(entries) != 2 || entries[0].Action != "delete_user_data" || entries[1].Action != "request_delete" || entries[1].RequestID != "req-1" {
+		t.Fatalf("Audit entries = %+v", entries)
+	}
+	removed, _ := entries[0].Metadata["removed"].(map[string]int)
+	if !entries[0].Success || removed["account"] != 1 || removed["alerts"] != 1 || entries[0].Metadata["data_request_id"] != req.ID {
+		t.Errorf("Deletion audit entry = %+v", entries[0])
+	}
+	if handled := svc.Process(ctx); handled != 0 {
+		t.Errorf("Second Process handled %d, want 0", handled)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPrivacyService_Deletion", true)
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// AffiliateTag is the query parameter a retailer's affiliate programme uses
+// to attribute a visit, e.g. tag=<YOUR_AMAZON_TAG_HERE> for Amazon.
+type AffiliateTag struct {
+	Param string
+	Value string
+}
+
+// ParseAffiliateTags reads retailer:param=value pairs separated by commas,
+// the format of the AFFILIATE_TAGS environment variable.
+func ParseAffiliateTags(s string) (map[string]AffiliateTag, error) {
+	tags := make(map[string]AffiliateTag)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		retailer, pair, ok := strings.Cut(entry, ":")
+		param, value, ok2 := strings.Cut(pair, "=")
+		if !ok || !ok2 || retailer == "" || param == "" || value == "" {
+			return nil, fmt.Errorf("affiliate tag %q: want retailer:param=value", entry)
+		}
+		tags[retailer] = AffiliateTag{Param: param, Value: value}
+	}
+	return tags, nil
+}
+
+// RedirectRepos groups the repositories RedirectService reads from.
+type RedirectRepos struct {
+	Retailers repositories.RetailerRepository
+	Listings  repositories.ListingRepository
+}
+
+// Outbound is a resolved retailer link.
+type Outbound struct {
+	URL     string
+	Listing domain.Listing
+}
+
+// RedirectService turns a retailer and product into the retailer's product
+// page with our affiliate tag applied.
+type RedirectService struct {
+	repos  RedirectRepos
+	tags   map[string]AffiliateTag
+	logger *zap.Logger
+}
+
+// NewRedirectService creates a RedirectService. tags maps retailer IDs to
+// their affiliate parameter; retailers without one get an untagged link.
+func NewRedirectService(repos RedirectRepos, tags map[string]AffiliateTag, logger *zap.Logger) *RedirectService {
+	return &RedirectService{repos: repos, tags: tags, logger: logger}
+}
+
+// Resolve picks the listing to send the visitor to. With listingID set that
+// listing is used; otherwise the cheapest in-stock listing at the retailer,
+// falling back to the cheapest overall.
+func (s *RedirectService) Resolve(ctx context.Context, retailerID, productID, listingID string) (*Outbound, error) {
+	if _, err := s.repos.Retailers.FindByID(ctx, retailerID); err != nil {
+		return nil, err
+	}
+	listings, err := s.repos.Listings.ByProduct(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("load listings: %w", err)
+	}
+
+	var best *domain.Listing
+	for i := range listings {
+		l := &listings[i]
+		if l.RetailerID != retailerID || l.URL == "" {
+			continue
+		}
+		if listingID != "" {
+			if l.ID == listingID {
+				best = l
+				break
+			}
+			continue
+		}
+		if best == nil || betterListing(l, best) {
+			best = l
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no %s listing for product %q: %w", retailerID, productID, domain.ErrNotFound)
+	}
+
+	target, err := s.tag(retailerID, best.URL)
+	if err != nil {
+		return nil, err
+	}
+	return &Outbound{URL: target, Listing: *best}, nil
+}
+
+// betterListing prefers in-stock listings, then lower prices.
+func betterListing(a, b *domain.Listing) bool {
+	if a.InStock != b.InStock {
+		return a.InStock
+	}
+	return a.CurrentPrice > 0 && (b.CurrentPrice <= 0 || a.CurrentPrice < b.CurrentPrice)
+}
+
+// tag adds the retailer's affiliate parameter, replacing any tag already in
+// the scraped URL so attribution always goes to us.
+func (s *RedirectService) tag(retailerID, raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		// Listing URLs come from scrapers; never redirect anywhere else.
+		return "", fmt.Errorf("listing URL %q is not an absolute http(s) URL", raw)
+	}
+	if t, ok := s.tags[retailerID]; ok {
+		q := u.Query()
+		q.Set(t.Param, t.Value)
+		u.RawQuery = q.Encode()
+	}
+	return u.String(), nil
+}
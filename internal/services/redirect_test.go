@@ -0,0 +1,87 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestRedirectService_Resolve(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestRedirectService_Resolve", "internal/services")
+
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	store.PutListing(domain.Listing{ID: "lst_amazon_gsw_tagged", VariantID: testhelpers.FixtureVariantID, RetailerID: "amazon",
+		URL: "https://www.amazon.in/dp/B0TAGGED?tag=someone-else-21&th=1", CurrentPrice: 3599, InStock: true, IsActive: true})
+	svc := NewRedirectService(RedirectRepos{Retailers: store.Retailers(), Listings: store.Listings()},
+		map[string]AffiliateTag{"amazon": {Param: "tag", Value: "wheycompare-21"}}, logger)
+
+	testCases := []struct {
+		name        string
+		retailer    string
+		product     string
+		listing     string
+		wantListing string
+		wantTag     string
+		wantErr     error
+	}{
+		{"Cheapest listing is tagged", "amazon", testhelpers.FixtureProductID, "", testhelpers.FixtureListingAmazon, "wheycompare-21", nil},
+		{"Existing tag is replaced", "amazon", testhelpers.FixtureProductID, "lst_amazon_gsw_tagged", "lst_amazon_gsw_tagged", "wheycompare-21", nil},
+		{"Retailer without programme", "flipkart", testhelpers.FixtureProductID, "", testhelpers.FixtureListingFlipkart, "", nil},
+		{"Out of stock still resolves", "healthkart", testhelpers.FixtureProductID, "", testhelpers.FixtureListingHK, "", nil},
+		{"Unknown retailer", "nowhere", testhelpers.FixtureProductID, "", "", "", domain.ErrNotFound},
+		{"Retailer does not sell product", "flipkart", testhelpers.FixtureSecondProductID, "", "", "", domain.ErrNotFound},
+		{"Listing from another retailer", "amazon", testhelpers.FixtureProductID, testhelpers.FixtureListingFlipkart, "", "", domain.ErrNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := svc.Resolve(t.Context(), tc.retailer, tc.product, tc.listing)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("Error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve failed: %v", err)
+			}
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantListing, out.Listing.ID)
+			if out.Listing.ID != tc.wantListing {
+				t.Errorf("Listing = %s, want %s", out.Listing.ID, tc.wantListing)
+			}
+			u, _ := url.Parse(out.URL)
+			if got := u.Query().Get("tag"); got != tc.wantTag {
+				t.Errorf("tag = %q, want %q (url %s)", got, tc.wantTag, out.URL)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestRedirectService_Resolve", true)
+}
+
+func TestParseAffiliateTags(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestParseAffiliateTags", "internal/services")
+
+	tags, err := ParseAffiliateTags("amazon:tag=wheycompare-21, flipkart:affid=wheycmp")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	testhelpers.LogTestAssertion(logger, "tags parsed", 2, len(tags))
+	if tags["flipkart"] != (AffiliateTag{Param: "affid", Value: "wheycmp"}) || len(tags) != 2 {
+		t.Errorf("Tags = %+v", tags)
+	}
+	for _, bad := range []string{"amazon", "amazon:tag", "amazon:=x"} {
+		if _, err := ParseAffiliateTags(bad); err == nil {
+			t.Errorf("ParseAffiliateTags(%q) should fail", bad)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestParseAffiliateTags", true)
+}
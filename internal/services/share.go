@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// ErrBadShareToken is returned for share tokens that were not signed with
+// the current key.
+var ErrBadShareToken = fmt.Errorf("invalid share link: %w", domain.ErrNotFound)
+
+// ShareService creates signed links to a deal as it stood when shared, and
+// opens them against today's prices.
+type ShareService struct {
+	key    []byte
+	prices *PriceService
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewShareService creates a ShareService signing links with key, which
+// must not be empty.
+func NewShareService(key []byte, prices *PriceService, logger *zap.Logger) *ShareService {
+	return &ShareService{key: key, prices: prices, logger: logger, now: time.Now}
+}
+
+// Share snapshots productID's cheapest offer, at retailerID if it is set,
+// preferring offers in stock. It returns the snapshot and its token.
+func (s *ShareService) Share(ctx context.Context, productID, retailerID string) (*domain.DealShare, string, error) {
+	if productID == "" {
+		return nil, "", fmt.Errorf("product_id is required: %w", domain.ErrInvalid)
+	}
+	c, err := s.prices.Compare(ctx, productID)
+	if err != nil {
+		return nil, "", err
+	}
+	// Prices are sorted cheapest first, in-stock offers before the rest.
+	var offer *domain.Offer
+	for i := range c.Prices {
+		if retailerID == "" || c.Prices[i].RetailerID == retailerID {
+			offer = &c.Prices[i]
+			break
+		}
+	}
+	if offer == nil {
+		return nil, "", fmt.Errorf("no offer for product %q at %q: %w", productID, retailerID, domain.ErrNotFound)
+	}
+	share := domain.DealShare{
+		ProductID:  productID,
+		RetailerID: offer.RetailerID,
+		ListingID:  offer.ListingID,
+		Price:      offer.Price,
+		Currency:   offer.Currency,
+		SharedAt:   s.now().UTC().Truncate(time.Second),
+	}
+	return &share, s.token(share), nil
+}
+
+// Open reads a share token and prices its product now. Tokens that do not
+// verify return ErrBadShareToken.
+func (s *ShareService) Open(ctx context.Context, token string) (*domain.SharedDeal, error) {
+	share, err := s.parse(token)
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.prices.Compare(ctx, share.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	deal := &domain.SharedDeal{Share: *share, Comparison: c}
+	for i := range c.Prices {
+		if c.Prices[i].ListingID == share.ListingID {
+			deal.Current = &c.Prices[i]
+			break
+		}
+	}
+	return deal, nil
+}
+
+// token signs the snapshot's fields. It does not expire: the page always
+// shows today's price beside the shared one.
+func (s *ShareService) token(d domain.DealShare) string {
+	fields := []string{
+		d.ProductID, d.RetailerID, d.ListingID,
+		strconv.FormatFloat(d.Price, 'f', -1, 64), d.Currency,
+		strconv.FormatInt(d.SharedAt.Unix(), 10),
+	}
+	payload := base64.RawURLEncoding.EncodeToString([]byte(strings.Join(fields, "\x00")))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.sign(payload))
+}
+
+func (s *ShareService) parse(token string) (*domain.DealShare, error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok || len(s.key) == 0 {
+		return nil, ErrBadShareToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, s.sign(payload)) {
+		return nil, ErrBadShareToken
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrBadShareToken
+	}
+	fields := strings.Split(string(raw), "\x00")
+	if len(fields) != 6 {
+		return nil, ErrBadShareToken
+	}
+	price, err := strconv.ParseFloat(fields[3], 64)
+	if err != nil {
+		return nil, ErrBadShareToken
+	}
+	at, err := strconv.ParseInt(fields[5], 10, 64)
+	if err != nil {
+		return nil, ErrBadShareToken
+	}
+	return &domain.DealShare{
+		ProductID:  fields[0],
+		RetailerID: fields[1],
+		ListingID:  fields[2],
+		Price:      price,
+		Currency:   fields[4],
+		SharedAt:   time.Unix(at, 0).UTC(),
+	}, nil
+}
+
+func (s *ShareService) sign(payload string) []byte {
+	h := hmac.New(sha256.New, s.key)
+	h.Write([]byte("share\x00" + payload))
+	return h.Sum(nil)
+}
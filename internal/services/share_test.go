@@ -0,0 +1,87 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestShareService(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestShareService", "internal/services")
+
+	testhelpers.LogTestStep(logger, "arrange", "A seeded catalog")
+	now := time.Now()
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, now)
+	prices := NewPriceService(PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+	svc := NewShareService([]byte("test-key"), prices, logger)
+	svc.now = func() time.Time { return now }
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Sharing the best deal and Amazon's")
+	best, token, err := svc.Share(ctx, testhelpers.FixtureProductID, "")
+	if err != nil {
+		t.Fatalf("Share: %v", err)
+	}
+	amazon, _, err := svc.Share(ctx, testhelpers.FixtureProductID, "amazon")
+	if err != nil {
+		t.Fatalf("Share amazon: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Snapshots pick the cheapest offer")
+	testhelpers.LogTestAssertion(logger, "best listing", testhelpers.FixtureListingFlipkart, best.ListingID)
+	if best.ListingID != testhelpers.FixtureListingFlipkart || best.Price != 3199 || best.RetailerID != "flipkart" {
+		t.Errorf("Best share = %+v", best)
+	}
+	if amazon.ListingID != testhelpers.FixtureListingAmazon || amazon.Price != 3299 {
+		t.Errorf("Amazon share = %+v", amazon)
+	}
+	if _, _, err := svc.Share(ctx, testhelpers.FixtureProductID, "nykaa"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Share at an unlisted retailer err = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "The price drops, then the link is opened")
+	store.AddPricePoint(domain.PricePoint{
+		ListingID:  testhelpers.FixtureListingFlipkart,
+		Price:      2999,
+		Currency:   domain.DefaultCurrency,
+		InStock:    true,
+		RecordedAt: now.Add(time.Second),
+		Source:     "scraper",
+	})
+	deal, err := svc.Open(ctx, token)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The snapshot round-trips beside today's offer")
+	if deal.Share != *best {
+		t.Errorf("Opened share = %+v, want %+v", deal.Share, *best)
+	}
+	if deal.Current == nil || deal.Current.Price != 2999 {
+		t.Errorf("Current = %+v, want the Flipkart listing at 2999", deal.Current)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Edited and foreign tokens are rejected")
+	for _, bad := range []string{"", "garbage", token[:len(token)-2] + "AA", "x" + token} {
+		if _, err := svc.Open(ctx, bad); !errors.Is(err, ErrBadShareToken) {
+			t.Errorf("Open(%q) err = %v, want ErrBadShareToken", bad, err)
+		}
+	}
+	other := NewShareService([]byte("other-key"), prices, logger)
+	if _, err := other.Open(ctx, token); !errors.Is(err, ErrBadShareToken) {
+		t.Errorf("Open with another key err = %v, want ErrBadShareToken", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestShareService", true)
+}
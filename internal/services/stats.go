@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// DefaultStatsTTL is how long computed stats are reused.
+const DefaultStatsTTL = time.Minute
+
+// StatsService serves catalog-wide counts. Counting touches every price
+// point, so results are cached for ttl and shared by all callers.
+type StatsService struct {
+	repo   repositories.StatsRepository
+	ttl    time.Duration
+	logger *zap.Logger
+	now    func() time.Time
+
+	mu     sync.Mutex
+	cached *domain.CatalogStats
+}
+
+// NewStatsService creates a StatsService. A non-positive ttl uses
+// DefaultStatsTTL.
+func NewStatsService(repo repositories.StatsRepository, ttl time.Duration, logger *zap.Logger) *StatsService {
+	if ttl <= 0 {
+		ttl = DefaultStatsTTL
+	}
+	return &StatsService{repo: repo, ttl: ttl, logger: logger, now: time.Now}
+}
+
+// TTL returns how long a result is reused.
+func (s *StatsService) TTL() time.Duration { return s.ttl }
+
+// Stats returns the cached stats, recomputing them once they are older than
+// the TTL. If recomputing fails the stale copy is returned.
+func (s *StatsService) Stats(ctx context.Context) (domain.CatalogStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.cached != nil && now.Sub(s.cached.GeneratedAt) < s.ttl {
+		return *s.cached, nil
+	}
+
+	stats, err := s.repo.CatalogStats(ctx)
+	if err != nil {
+		if s.cached != nil {
+			s.logger.Warn("Serving stale stats",
+				zap.String("operation", "Stats"),
+				zap.Time("generated_at", s.cached.GeneratedAt),
+				zap.Error(err),
+			)
+			return *s.cached, nil
+		}
+		return domain.CatalogStats{}, fmt.Errorf("compute stats: %w", err)
+	}
+	stats.GeneratedAt = now.UTC()
+	s.cached = &stats
+	return stats, nil
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+type flakyStatsRepo struct {
+	calls int
+	fail  bool
+}
+
+func (r *flakyStatsRepo) CatalogStats(context.Context) (domain.CatalogStats, error) {
+	r.calls++
+	if r.fail {
+		return domain.CatalogStats{}, errors.New("database unavailable")
+	}
+	return domain.CatalogStats{Products: r.calls}, nil
+}
+
+func TestStatsService_Counts(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStatsService_Counts", "internal/services")
+
+	now := time.Now()
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, now)
+	store.PutRetailer(domain.Retailer{ID: "defunct", Name: "Defunct"})
+	svc := NewStatsService(store.Stats(), 0, logger)
+
+	testhelpers.LogTestStep(logger, "act", "Computing stats over the fixture catalog")
+	stats, err := svc.Stats(t.Context())
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Only active records are counted")
+	testhelpers.LogTestAssertion(logger, "price points", int64(28), stats.PricePoints)
+	if stats.Products != 2 || stats.Retailers != 3 || stats.Listings != 4 || stats.PricePoints != 28 {
+		t.Errorf("Stats = %+v", stats)
+	}
+	if stats.LastUpdatedAt == nil || stats.GeneratedAt.IsZero() {
+		t.Errorf("Timestamps missing: %+v", stats)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStatsService_Counts", true)
+}
+
+func TestStatsService_Caching(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestStatsService_Caching", "internal/services")
+
+	repo := &flakyStatsRepo{}
+	svc := NewStatsService(repo, time.Minute, logger)
+	clock := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return clock }
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Reading stats repeatedly inside and after the TTL")
+	first, _ := svc.Stats(ctx)
+	clock = clock.Add(59 * time.Second)
+	second, _ := svc.Stats(ctx)
+	clock = clock.Add(2 * time.Second)
+	third, _ := svc.Stats(ctx)
+
+	testhelpers.LogTestAssertion(logger, "repository calls", 2, repo.calls)
+	if repo.calls != 2 || first.Products != 1 || second.Products != 1 || third.Products != 2 {
+		t.Errorf("calls=%d first=%d second=%d third=%d", repo.calls, first.Products, second.Products, third.Products)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Failing refresh after expiry")
+	repo.fail = true
+	clock = clock.Add(time.Hour)
+	stale, err := svc.Stats(ctx)
+	if err != nil || stale.Products != 2 {
+		t.Errorf("Stale stats = %+v, %v; want previous result", stale, err)
+	}
+
+	empty := NewStatsService(&flakyStatsRepo{fail: true}, time.Minute, logger)
+	if _, err := empty.Stats(ctx); err == nil {
+		t.Error("Expected error when nothing is cached")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestStatsService_Caching", true)
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// Synonyms returns every search synonym group, oldest first.
+func (s *AdminService) Synonyms(ctx context.Context) ([]domain.Synonym, error) {
+	if s.repos.Synonyms == nil {
+		return nil, fmt.Errorf("search synonyms: %w", domain.ErrNotFound)
+	}
+	out, err := s.repos.Synonyms.Synonyms(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list synonyms: %w", err)
+	}
+	if out == nil {
+		out = []domain.Synonym{}
+	}
+	return out, nil
+}
+
+// CreateSynonym adds a synonym group.
+func (s *AdminService) CreateSynonym(ctx context.Context, actor domain.Actor, in domain.Synonym) (out *domain.Synonym, err error) {
+	defer func() { s.audit(ctx, actor, "create_synonym", "synonym", resourceID(out), nil, out, err, nil) }()
+
+	if s.repos.Synonyms == nil {
+		return nil, fmt.Errorf("search synonyms: %w", domain.ErrNotFound)
+	}
+	if err := in.Validate(); err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	in.CreatedAt, in.UpdatedAt = now, now
+	created, err := s.repos.Synonyms.CreateSynonym(ctx, in, s.synonymsChanged())
+	if err != nil {
+		return nil, fmt.Errorf("create synonym: %w", err)
+	}
+	s.publish(ctx)
+	return &created, nil
+}
+
+// UpdateSynonym replaces a synonym group's terms.
+func (s *AdminService) UpdateSynonym(ctx context.Context, actor domain.Actor, id string, in domain.Synonym) (out *domain.Synonym, err error) {
+	var before *domain.Synonym
+	defer func() { s.audit(ctx, actor, "update_synonym", "synonym", id, before, out, err, nil) }()
+
+	if s.repos.Synonyms == nil {
+		return nil, fmt.Errorf("search synonyms: %w", domain.ErrNotFound)
+	}
+	if before, err = s.repos.Synonyms.Synonym(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := in.Validate(); err != nil {
+		return nil, err
+	}
+	updated := *before
+	updated.Terms = in.Terms
+	updated.UpdatedAt = s.now().UTC()
+	if err := s.repos.Synonyms.SaveSynonym(ctx, updated, s.synonymsChanged()); err != nil {
+		return nil, fmt.Errorf("save synonym: %w", err)
+	}
+	s.publish(ctx)
+	return &updated, nil
+}
+
+// DeleteSynonym removes a synonym group.
+func (s *AdminService) DeleteSynonym(ctx context.Context, actor domain.Actor, id string) (err error) {
+	var before *domain.Synonym
+	defer func() { s.audit(ctx, actor, "delete_synonym", "synonym", id, before, nil, err, nil) }()
+
+	if s.repos.Synonyms == nil {
+		return fmt.Errorf("search synonyms: %w", domain.ErrNotFound)
+	}
+	if before, err = s.repos.Synonyms.Synonym(ctx, id); err != nil {
+		return err
+	}
+	if err := s.repos.Synonyms.DeleteSynonym(ctx, id, s.synonymsChanged()); err != nil {
+		return fmt.Errorf("delete synonym: %w", err)
+	}
+	s.publish(ctx)
+	return nil
+}
+
+// synonymsChanged tells searches to reload their synonyms.
+func (s *AdminService) synonymsChanged() domain.SynonymsChanged {
+	return domain.SynonymsChanged{OccurredAt: s.now().UTC()}
+}
+
+// resourceID returns the ID of a created synonym for its audit entry, or
+// "" if creating it failed.
+func resourceID(s *domain.Synonym) string {
+	if s == nil {
+		return ""
+	}
+	return s.ID
+}
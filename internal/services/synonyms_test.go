@@ -0,0 +1,56 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestAdminService_Synonyms(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestAdminService_Synonyms", "internal/services")
+
+	svc, store := newTestAdminService(t)
+	pub := recordEvents(t, svc, store)
+	ctx := t.Context()
+	actor := domain.Actor{ID: "alice"}
+
+	testhelpers.LogTestStep(logger, "act", "Creating, updating and deleting a group, and one invalid create")
+	created, err := svc.CreateSynonym(ctx, actor, domain.Synonym{Terms: []string{"ON", "Optimum Nutrition"}})
+	if err != nil {
+		t.Fatalf("CreateSynonym: %v", err)
+	}
+	updated, err := svc.UpdateSynonym(ctx, actor, created.ID, domain.Synonym{Terms: []string{"ON", "O.N.", "Optimum Nutrition"}})
+	if err != nil {
+		t.Fatalf("UpdateSynonym: %v", err)
+	}
+	if _, err := svc.CreateSynonym(ctx, actor, domain.Synonym{Terms: []string{"ON"}}); !errors.Is(err, domain.ErrInvalid) {
+		t.Errorf("Invalid create err = %v, want ErrInvalid", err)
+	}
+	list, _ := svc.Synonyms(ctx)
+	if err := svc.DeleteSynonym(ctx, actor, created.ID); err != nil {
+		t.Fatalf("DeleteSynonym: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Changes are kept, announced and audited")
+	if len(list) != 1 || len(list[0].Terms) != 3 || !updated.CreatedAt.Equal(created.CreatedAt) {
+		t.Errorf("Synonyms = %+v, updated %+v", list, updated)
+	}
+	testhelpers.LogTestAssertion(logger, "events", 3, len(pub.events))
+	if len(pub.events) != 3 || pub.events[0].EventType() != domain.EventSynonymsChanged {
+		t.Errorf("Events = %+v, want 3 SynonymsChanged", pub.events)
+	}
+	entries, _ := svc.AuditLog(ctx, repositories.AuditFilter{ResourceType: "synonym"})
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Action)
+	}
+	if len(entries) != 4 || entries[0].Action != "delete_synonym" || entries[3].ResourceID != created.ID || entries[1].Success {
+		t.Errorf("Audit actions = %v", actions)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestAdminService_Synonyms", true)
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/pool"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// ViewMaintainer keeps the comparison and deal views current. Handle
+// refreshes one product's rows as its prices or catalog data change; Rebuild
+// recomputes every product, which is needed at startup and periodically
+// because 30-day deal statistics drift as old prices leave the window.
+type ViewMaintainer struct {
+	prices *PriceService
+	views  repositories.ViewRepository
+	logger *zap.Logger
+	pool   *pool.Pool
+}
+
+// NewViewMaintainer creates a ViewMaintainer that computes rows with prices'
+// aggregation, bypassing its cache and views.
+func NewViewMaintainer(prices *PriceService, views repositories.ViewRepository, logger *zap.Logger) *ViewMaintainer {
+	return &ViewMaintainer{prices: prices, views: views, logger: logger}
+}
+
+// WithPool makes Rebuild refresh pages concurrently on p's workers. Without
+// a pool pages are refreshed one after another.
+func (m *ViewMaintainer) WithPool(p *pool.Pool) *ViewMaintainer {
+	m.pool = p
+	return m
+}
+
+// EventTypes lists the events Handle understands, for subscribing.
+func (m *ViewMaintainer) EventTypes() []string {
+	return []string{domain.EventPriceDropped, domain.EventPriceChanged, domain.EventProductUpdated}
+}
+
+// Handle refreshes the rows of the product e concerns.
+func (m *ViewMaintainer) Handle(ctx context.Context, e domain.Event) error {
+	var productID string
+	switch ev := e.(type) {
+	case domain.PriceDropped:
+		productID = ev.ProductID
+	case domain.PriceChanged:
+		productID = ev.ProductID
+	case domain.ProductUpdated:
+		productID = ev.ProductID
+	}
+	if productID == "" {
+		return nil
+	}
+	return m.Refresh(ctx, productID)
+}
+
+// Refresh recomputes one product's rows, removing them if the product is
+// gone or inactive.
+func (m *ViewMaintainer) Refresh(ctx context.Context, productID string) error {
+	return m.refresh(ctx, []string{productID})
+}
+
+// refresh recomputes the rows of several products with batched reads. It
+// aggregates from the repositories directly, with loaders of its own: the
+// cache, the views and anything the caller already loaded are what it is
+// refreshing.
+func (m *ViewMaintainer) refresh(ctx context.Context, productIDs []string) error {
+	ld := newLoaders(m.prices.repos, nil)
+	ctx = context.WithValue(ctx, loadersKey{}, ld)
+	ld.products.Prefetch(productIDs...)
+	ld.variants.Prefetch(productIDs...)
+	ld.listings.Prefetch(productIDs...)
+
+	comparisons := make([]*domain.Comparison, 0, len(productIDs))
+	for _, id := range productIDs {
+		c, err := m.prices.compare(ctx, id)
+		if errors.Is(err, domain.ErrNotFound) {
+			if err := m.views.DeleteProductView(ctx, id); err != nil {
+				return fmt.Errorf("delete product view: %w", err)
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		comparisons = append(comparisons, c)
+	}
+
+	since := m.prices.now().AddDate(0, 0, -dealWindowDays)
+	deals, err := m.prices.scoreComparisons(ctx, comparisons, since)
+	if err != nil {
+		return err
+	}
+	for i, c := range comparisons {
+		deal := deals[i]
+		if deal != nil && deal.Score <= 0 {
+			deal = nil
+		}
+		if err := m.views.SaveProductView(ctx, *c, deal); err != nil {
+			return fmt.Errorf("save product view: %w", err)
+		}
+	}
+	return nil
+}
+
+// Rebuild refreshes every active product a page at a time, pages running
+// concurrently when a pool is set. A page that fails is logged and skipped
+// so one bad row cannot block the rest; the first such error is returned
+// once the pass completes.
+func (m *ViewMaintainer) Rebuild(ctx context.Context) error {
+	logger := m.logger.With(zap.String("operation", "RebuildViews"))
+	start := time.Now()
+	var refreshed atomic.Int64
+	refreshPage := func(ctx context.Context, offset int, ids []string) error {
+		if err := m.refresh(ctx, ids); err != nil {
+			logger.Error("Failed to refresh product views", zap.Int("offset", offset), zap.Error(err))
+			return err
+		}
+		refreshed.Add(int64(len(ids)))
+		return nil
+	}
+
+	var batch *pool.Batch
+	if m.pool != nil {
+		batch = m.pool.Batch(ctx)
+	}
+	var firstErr error
+	for offset := 0; ; offset += dealScanPageSize {
+		products, err := m.prices.repos.Products.List(ctx, repositories.ProductFilter{
+			Limit:  dealScanPageSize,
+			Offset: offset,
+		})
+		if err != nil {
+			firstErr = fmt.Errorf("list products: %w", err)
+			break
+		}
+		ids := make([]string, 0, len(products))
+		for _, p := range products {
+			ids = append(ids, p.ID)
+		}
+		if batch != nil {
+			batch.Go(func(ctx context.Context) error { return refreshPage(ctx, offset, ids) })
+		} else if err := refreshPage(ctx, offset, ids); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if len(products) < dealScanPageSize {
+			break
+		}
+	}
+	if batch != nil {
+		// Wait even after a listing failure so no page outlives the pass.
+		if err := batch.Wait(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	logger.Info("Views rebuilt",
+		zap.Int64("products", refreshed.Load()),
+		zap.Duration("duration", time.Since(start)),
+	)
+	return firstErr
+}
+
+// Run rebuilds the views every interval until ctx is done.
+func (m *ViewMaintainer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = m.Rebuild(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
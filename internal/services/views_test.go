@@ -0,0 +1,179 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/events"
+	"github.com/yourusername/whey-price-compare/internal/pool"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestViewMaintainer_MatchesAggregation(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestViewMaintainer_MatchesAggregation", "internal/services")
+
+	testhelpers.LogTestStep(logger, "arrange", "One service aggregating, one reading rebuilt views")
+	now := time.Now()
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, now)
+	repos := PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}
+	direct := NewPriceService(repos, logger)
+	viewed := NewPriceService(repos, logger).WithViews(store.Views())
+	direct.now = func() time.Time { return now }
+	viewed.now = direct.now
+	ctx := t.Context()
+
+	if err := NewViewMaintainer(viewed, store.Views(), logger).Rebuild(ctx); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Reading deals and a comparison both ways")
+	want, err := direct.TopDeals(ctx, DealQuery{})
+	if err != nil {
+		t.Fatalf("TopDeals (aggregated) failed: %v", err)
+	}
+	got, err := viewed.TopDeals(ctx, DealQuery{})
+	if err != nil {
+		t.Fatalf("TopDeals (view) failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Views return the same deals in the same order")
+	testhelpers.LogTestAssertion(logger, "deal count", len(want), len(got))
+	if len(got) != len(want) {
+		t.Fatalf("View returned %d deals, aggregation %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Product.ID != want[i].Product.ID || got[i].Score != want[i].Score || got[i].Avg30d != want[i].Avg30d {
+			t.Errorf("Deal %d = %s (%v), want %s (%v)", i, got[i].Product.ID, got[i].Score, want[i].Product.ID, want[i].Score)
+		}
+	}
+	if limited, _ := viewed.TopDeals(ctx, DealQuery{Limit: 1}); len(limited) != 1 || limited[0].Product.ID != want[0].Product.ID {
+		t.Errorf("Limit 1 from view = %+v", limited)
+	}
+	if none, _ := viewed.TopDeals(ctx, DealQuery{MinScore: 99}); len(none) != 0 {
+		t.Errorf("MinScore 99 from view returned %d deals, want 0", len(none))
+	}
+	c, err := viewed.Compare(ctx, testhelpers.FixtureProductID)
+	if err != nil {
+		t.Fatalf("Compare (view) failed: %v", err)
+	}
+	if c.BestDeal == nil || c.BestDeal.ListingID != testhelpers.FixtureListingFlipkart || len(c.Prices) != 3 {
+		t.Errorf("Viewed comparison = %+v", c)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestViewMaintainer_MatchesAggregation", true)
+}
+
+func TestViewMaintainer_RefreshesOnEvents(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestViewMaintainer_RefreshesOnEvents", "internal/services")
+
+	testhelpers.LogTestStep(logger, "arrange", "Views maintained from admin events")
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	prices := NewPriceService(PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger).WithViews(store.Views())
+	views := NewViewMaintainer(prices, store.Views(), logger)
+	bus := events.NewBus(logger)
+	bus.Subscribe(views.Handle, views.EventTypes()...)
+	admin := NewAdminService(AdminRepos{
+		Catalog:   store.CatalogAdmin(),
+		Selectors: store.Selectors(),
+		Prices:    store.PriceWriter(),
+		Audit:     store.Audit(),
+	}, logger).WithRelay(events.NewRelay(events.DefaultRelayConfig(), store.Outbox(), bus, logger))
+	ctx := t.Context()
+	if err := views.Rebuild(ctx); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Changing the store without an event")
+	store.AddPricePoint(domain.PricePoint{ListingID: testhelpers.FixtureListingAmazon, Price: 2999, InStock: true, RecordedAt: time.Now()})
+	stale, _ := prices.Compare(ctx, testhelpers.FixtureProductID)
+	if stale.BestDeal.Price != 3199 {
+		t.Errorf("Best price before refresh = %v, want the stored 3199", stale.BestDeal.Price)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Correcting a price through the admin API")
+	if _, err := admin.CorrectPrice(ctx, domain.Actor{ID: "alice"}, testhelpers.FixtureListingFlipkart, PriceCorrection{Price: 2899, Reason: "scraper misread"}); err != nil {
+		t.Fatalf("CorrectPrice failed: %v", err)
+	}
+	fresh, _ := prices.Compare(ctx, testhelpers.FixtureProductID)
+	testhelpers.LogTestAssertion(logger, "best price after event", 2899.0, fresh.BestDeal.Price)
+	if fresh.BestDeal.Price != 2899 {
+		t.Errorf("Best price after correction = %v, want 2899", fresh.BestDeal.Price)
+	}
+	deals, _ := prices.TopDeals(ctx, DealQuery{})
+	if len(deals) == 0 || deals[0].Product.ID != testhelpers.FixtureProductID || deals[0].Offer.Price != 2899 {
+		t.Errorf("Top deal after correction = %+v", deals)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Deleting the product")
+	if err := admin.DeleteProduct(ctx, domain.Actor{ID: "alice"}, testhelpers.FixtureProductID); err != nil {
+		t.Fatalf("DeleteProduct failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Its rows are gone")
+	if _, err := prices.Compare(ctx, testhelpers.FixtureProductID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Compare after delete error = %v, want ErrNotFound", err)
+	}
+	deals, _ = prices.TopDeals(ctx, DealQuery{})
+	for _, d := range deals {
+		if d.Product.ID == testhelpers.FixtureProductID {
+			t.Errorf("Deleted product still listed as a deal")
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestViewMaintainer_RefreshesOnEvents", true)
+}
+
+func TestViewMaintainer_RebuildsOnPool(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestViewMaintainer_RebuildsOnPool", "internal/services")
+
+	testhelpers.LogTestStep(logger, "arrange", "A maintainer sharing a two-worker bulk pool")
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	prices := NewPriceService(PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+	bulk := pool.New(pool.Config{Name: "bulk", Size: 2, QueueDepth: 1}, logger)
+	defer bulk.Close(t.Context())
+	m := NewViewMaintainer(prices, store.Views(), logger).WithPool(bulk)
+
+	testhelpers.LogTestStep(logger, "act", "Rebuilding through the pool")
+	if err := m.Rebuild(t.Context()); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Every product has a view and the pool ran the pages")
+	views, err := store.Views().Comparisons(t.Context(), []string{testhelpers.FixtureProductID, testhelpers.FixtureSecondProductID})
+	if err != nil {
+		t.Fatalf("Comparisons failed: %v", err)
+	}
+	testhelpers.LogTestAssertion(logger, "views", 2, len(views))
+	if len(views) != 2 {
+		t.Errorf("Rebuilt %d views, want 2", len(views))
+	}
+	if stats := bulk.Stats(); stats.Completed != 1 || stats.Failed != 0 {
+		t.Errorf("Pool stats = %+v, want one page completed", stats)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestViewMaintainer_RebuildsOnPool", true)
+}
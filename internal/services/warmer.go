@@ -0,0 +1,229 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/pool"
+)
+
+// maxTrackedProducts bounds Popularity's memory. Products first seen once
+// it is full are not counted until decay frees room.
+const maxTrackedProducts = 10000
+
+// Popularity counts product views with exponential decay, so the hottest
+// products follow recent traffic rather than all-time totals. It is safe for
+// concurrent use.
+type Popularity struct {
+	mu     sync.Mutex
+	counts map[string]float64
+}
+
+// NewPopularity creates an empty Popularity.
+func NewPopularity() *Popularity {
+	return &Popularity{counts: make(map[string]float64)}
+}
+
+// Record counts one view of productID.
+func (p *Popularity) Record(productID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.counts[productID]; !ok && len(p.counts) >= maxTrackedProducts {
+		return
+	}
+	p.counts[productID]++
+}
+
+// Views returns productID's decayed view count.
+func (p *Popularity) Views(productID string) float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.counts[productID]
+}
+
+// Top returns up to n product IDs, most viewed first.
+func (p *Popularity) Top(n int) []string {
+	p.mu.Lock()
+	counts := maps.Clone(p.counts)
+	p.mu.Unlock()
+
+	ids := slices.Collect(maps.Keys(counts))
+	slices.SortFunc(ids, func(a, b string) int {
+		if c := cmp.Compare(counts[b], counts[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+	return ids[:min(n, len(ids))]
+}
+
+// Decay halves every count and forgets products that fall below one view.
+func (p *Popularity) Decay() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for id, n := range p.counts {
+		if n /= 2; n < 1 {
+			delete(p.counts, id)
+		} else {
+			p.counts[id] = n
+		}
+	}
+}
+
+// CacheWarmerConfig configures a CacheWarmer.
+type CacheWarmerConfig struct {
+	// TopN is how many of the most-viewed products are warmed.
+	TopN int
+	// Settle is how long price events must stop before warming, so a scrape
+	// cycle publishing thousands of changes warms once, after it ends.
+	Settle time.Duration
+	// DecayEvery halves view counts at most this often.
+	DecayEvery time.Duration
+}
+
+// DefaultCacheWarmerConfig warms the top 50 products ten seconds after a
+// scrape cycle goes quiet.
+func DefaultCacheWarmerConfig() CacheWarmerConfig {
+	return CacheWarmerConfig{TopN: 50, Settle: 10 * time.Second, DecayEvery: time.Hour}
+}
+
+// CacheWarmer reloads the comparisons and product details of the most-viewed
+// products into the cache once price changes have evicted them, so the first
+// visitor after a scrape cycle is not the one who pays for the rebuild.
+type CacheWarmer struct {
+	cfg        CacheWarmerConfig
+	prices     *PriceService
+	catalog    *CatalogService
+	popularity *Popularity
+	logger     *zap.Logger
+	pool       *pool.Pool
+	now        func() time.Time
+
+	pending   atomic.Bool
+	lastEvent atomic.Int64 // unix nanoseconds
+	lastDecay time.Time
+}
+
+// NewCacheWarmer creates a CacheWarmer ranking products by popularity. The
+// services should count views into the same Popularity.
+func NewCacheWarmer(cfg CacheWarmerConfig, prices *PriceService, catalog *CatalogService, popularity *Popularity, logger *zap.Logger) *CacheWarmer {
+	def := DefaultCacheWarmerConfig()
+	if cfg.Settle <= 0 {
+		cfg.Settle = def.Settle
+	}
+	if cfg.DecayEvery <= 0 {
+		cfg.DecayEvery = def.DecayEvery
+	}
+	return &CacheWarmer{
+		cfg:        cfg,
+		prices:     prices,
+		catalog:    catalog,
+		popularity: popularity,
+		logger:     logger,
+		now:        time.Now,
+		lastDecay:  time.Now(),
+	}
+}
+
+// WithPool warms products concurrently on p's workers. It returns w.
+func (w *CacheWarmer) WithPool(p *pool.Pool) *CacheWarmer {
+	w.pool = p
+	return w
+}
+
+// EventTypes lists the events that schedule a warm, for subscribing.
+func (w *CacheWarmer) EventTypes() []string {
+	return []string{domain.EventPriceDropped, domain.EventPriceChanged, domain.EventProductUpdated}
+}
+
+// Handle schedules a warm once events settle. Subscribe it after the cache
+// invalidator so the entries it would warm are already gone.
+func (w *CacheWarmer) Handle(_ context.Context, _ domain.Event) error {
+	w.lastEvent.Store(w.now().UnixNano())
+	w.pending.Store(true)
+	return nil
+}
+
+// Warm loads the top products' comparisons and details through the cache,
+// filling whatever is missing or stale. Products that fail are logged and
+// skipped. It returns how many products were warmed. Run calls it; it is not
+// safe to call concurrently with itself.
+func (w *CacheWarmer) Warm(ctx context.Context) int {
+	if w.cfg.TopN <= 0 {
+		return 0
+	}
+	logger := w.logger.With(zap.String("operation", "WarmCache"))
+	start := w.now()
+	ids := w.popularity.Top(w.cfg.TopN)
+	var warmed atomic.Int64
+	warm := func(ctx context.Context, id string) error {
+		if _, err := w.prices.cachedCompare(ctx, id); err != nil {
+			logger.Warn("Failed to warm comparison", zap.String("product_id", id), zap.Error(err))
+			return err
+		}
+		if w.catalog != nil {
+			if _, err := w.catalog.cachedProduct(ctx, id); err != nil {
+				logger.Warn("Failed to warm product", zap.String("product_id", id), zap.Error(err))
+				return err
+			}
+		}
+		warmed.Add(1)
+		return nil
+	}
+
+	if w.pool != nil {
+		batch := w.pool.Batch(ctx)
+		for _, id := range ids {
+			batch.Go(func(ctx context.Context) error { return warm(ctx, id) })
+		}
+		_ = batch.Wait()
+	} else {
+		for _, id := range ids {
+			_ = warm(ctx, id)
+		}
+	}
+
+	if now := w.now(); now.Sub(w.lastDecay) >= w.cfg.DecayEvery {
+		w.popularity.Decay()
+		w.lastDecay = now
+	}
+	logger.Info("Cache warmed",
+		zap.Int("products", int(warmed.Load())),
+		zap.Int("candidates", len(ids)),
+		zap.Duration("duration", w.now().Sub(start)),
+	)
+	return int(warmed.Load())
+}
+
+// due reports whether events are pending and have been quiet for Settle.
+func (w *CacheWarmer) due() bool {
+	if !w.pending.Load() {
+		return false
+	}
+	return w.now().Sub(time.Unix(0, w.lastEvent.Load())) >= w.cfg.Settle
+}
+
+// Run warms the cache each time events settle, until ctx is done.
+func (w *CacheWarmer) Run(ctx context.Context) {
+	ticker := time.NewTicker(max(w.cfg.Settle/4, 100*time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if w.due() {
+				w.pending.Store(false)
+				w.Warm(ctx)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/cache"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestPopularity_TopAndDecay(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestPopularity_TopAndDecay", "internal/services")
+
+	testhelpers.LogTestStep(logger, "arrange", "Views of three products")
+	p := NewPopularity()
+	for range 5 {
+		p.Record("prod_a")
+	}
+	for range 3 {
+		p.Record("prod_b")
+	}
+	p.Record("prod_c")
+
+	testhelpers.LogTestStep(logger, "assert", "Top ranks by views and decay forgets the cold tail")
+	if got := p.Top(2); !slices.Equal(got, []string{"prod_a", "prod_b"}) {
+		t.Errorf("Top(2) = %v, want [prod_a prod_b]", got)
+	}
+	p.Decay()
+	got := p.Top(10)
+	testhelpers.LogTestAssertion(logger, "after decay", []string{"prod_a", "prod_b"}, got)
+	if !slices.Equal(got, []string{"prod_a", "prod_b"}) {
+		t.Errorf("Top after decay = %v, want prod_c forgotten", got)
+	}
+	if v := p.Views("prod_a"); v != 2.5 {
+		t.Errorf("Views(prod_a) = %v, want 2.5", v)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestPopularity_TopAndDecay", true)
+}
+
+func TestCacheWarmer_RefillsHotProducts(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestCacheWarmer_RefillsHotProducts", "internal/services")
+
+	testhelpers.LogTestStep(logger, "arrange", "Cached services counting views, one product viewed")
+	now := time.Now()
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, now)
+	c := newMapCache()
+	popularity := NewPopularity()
+	prices := NewPriceService(PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger).WithCache(c, cache.Policy{TTL: time.Minute}).WithPopularity(popularity)
+	catalog := NewCatalogService(CatalogRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+	}, logger).WithCache(c, cache.Policy{TTL: time.Minute}).WithPopularity(popularity)
+	ctx := t.Context()
+	if _, err := prices.Compare(ctx, testhelpers.FixtureProductID); err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+	w := NewCacheWarmer(CacheWarmerConfig{TopN: 10, Settle: 10 * time.Second}, prices, catalog, popularity, logger)
+	w.now = func() time.Time { return now }
+
+	testhelpers.LogTestStep(logger, "act", "A price event evicts the product, then events settle")
+	if err := cache.NewInvalidator(c).Handle(ctx, domain.PriceChanged{PriceChange: domain.PriceChange{ProductID: testhelpers.FixtureProductID}}); err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+	if err := w.Handle(ctx, domain.PriceChanged{PriceChange: domain.PriceChange{ProductID: testhelpers.FixtureProductID}}); err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	if w.due() {
+		t.Error("Warm due before events settled")
+	}
+	now = now.Add(10 * time.Second)
+	if !w.due() {
+		t.Fatal("Warm not due after events settled")
+	}
+	warmed := w.Warm(ctx)
+
+	testhelpers.LogTestStep(logger, "assert", "The product's entries are back and warming did not count as views")
+	testhelpers.LogTestAssertion(logger, "warmed", 1, warmed)
+	if warmed != 1 {
+		t.Errorf("Warmed %d products, want 1", warmed)
+	}
+	for _, key := range []string{cache.ComparisonKey(testhelpers.FixtureProductID), cache.ProductKey(testhelpers.FixtureProductID)} {
+		if _, err := c.Get(ctx, key); err != nil {
+			t.Errorf("%s not cached after warming: %v", key, err)
+		}
+	}
+	if n := popularity.counts[testhelpers.FixtureProductID]; n != 1 {
+		t.Errorf("View count = %v after warming, want the 1 real view", n)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestCacheWarmer_RefillsHotProducts", true)
+}
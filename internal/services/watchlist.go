@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// DefaultMaxWatchlist bounds how many products one user may watch.
+const DefaultMaxWatchlist = 100
+
+// WatchlistService manages users' watchlists and prices them as a
+// portfolio.
+type WatchlistService struct {
+	repo     repositories.WatchlistRepository
+	prices   *PriceService
+	maxItems int
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewWatchlistService creates a WatchlistService reading current offers
+// from prices.
+func NewWatchlistService(repo repositories.WatchlistRepository, prices *PriceService, logger *zap.Logger) *WatchlistService {
+	return &WatchlistService{repo: repo, prices: prices, maxItems: DefaultMaxWatchlist, logger: logger, now: time.Now}
+}
+
+// WithMaxItems overrides DefaultMaxWatchlist. It returns s.
+func (s *WatchlistService) WithMaxItems(n int) *WatchlistService {
+	s.maxItems = n
+	return s
+}
+
+// Add saves productID to userID's watchlist with its current best price,
+// so the portfolio can show the change since.
+func (s *WatchlistService) Add(ctx context.Context, userID, productID string) (*domain.WatchlistItem, error) {
+	if productID == "" {
+		return nil, fmt.Errorf("product_id is required: %w", domain.ErrInvalid)
+	}
+	items, err := s.repo.Watchlist(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) >= s.maxItems {
+		return nil, fmt.Errorf("watchlist is limited to %d products: %w", s.maxItems, domain.ErrConflict)
+	}
+	// Also rejects unknown and inactive products. Reading through the
+	// cache without counting a view keeps watchlists out of popularity.
+	c, err := s.compare(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	item := domain.WatchlistItem{UserID: userID, ProductID: productID, AddedAt: s.now().UTC()}
+	if len(c.Prices) > 0 && c.Prices[0].InStock {
+		item.AddedPrice = c.Prices[0].Price
+	}
+	if err := s.repo.AddWatch(ctx, item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Remove deletes productID from userID's watchlist.
+func (s *WatchlistService) Remove(ctx context.Context, userID, productID string) error {
+	return s.repo.RemoveWatch(ctx, userID, productID)
+}
+
+// Portfolio prices userID's watchlist now. Products that are no longer
+// listed stay on it, unpriced, until removed.
+func (s *WatchlistService) Portfolio(ctx context.Context, userID string) (*domain.Portfolio, error) {
+	items, err := s.repo.Watchlist(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ProductID
+	}
+	ctx, ld := s.prices.withLoaders(ctx)
+	ld.prefetch(ids...)
+
+	comparisons := make(map[string]*domain.Comparison, len(ids))
+	for _, id := range ids {
+		c, err := s.compare(ctx, id)
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		comparisons[id] = c
+	}
+	return domain.NewPortfolio(items, comparisons), nil
+}
+
+func (s *WatchlistService) compare(ctx context.Context, productID string) (*domain.Comparison, error) {
+	if s.prices.known != nil && !s.prices.known.MayExist(productID) {
+		return nil, unknownProduct(productID)
+	}
+	return s.prices.cachedCompare(ctx, productID)
+}
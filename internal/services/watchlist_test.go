@@ -0,0 +1,120 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestWatchlistService_Portfolio(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestWatchlistService_Portfolio", "internal/services")
+
+	testhelpers.LogTestStep(logger, "arrange", "A seeded catalog and a watchlist with both fixtures and an unlisted product")
+	now := time.Now()
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, now)
+	prices := NewPriceService(PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+	svc := NewWatchlistService(store.Watchlists(), prices, logger)
+	svc.now = func() time.Time { return now }
+	ctx := t.Context()
+
+	added, err := svc.Add(ctx, "user_1", testhelpers.FixtureProductID)
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	testhelpers.LogTestAssertion(logger, "added price", 3199.0, added.AddedPrice)
+	if added.AddedPrice != 3199 {
+		t.Errorf("AddedPrice = %v, want the best in-stock price 3199", added.AddedPrice)
+	}
+	svc.now = func() time.Time { return now.Add(time.Minute) }
+	if _, err := svc.Add(ctx, "user_1", testhelpers.FixtureSecondProductID); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	_ = store.Watchlists().AddWatch(ctx, domain.WatchlistItem{UserID: "user_1", ProductID: "prod_delisted", AddedAt: now.Add(-time.Hour), AddedPrice: 1999})
+
+	testhelpers.LogTestStep(logger, "act", "Flipkart drops the price, then the portfolio is read")
+	store.AddPricePoint(domain.PricePoint{
+		ListingID:  testhelpers.FixtureListingFlipkart,
+		Price:      2999,
+		Currency:   domain.DefaultCurrency,
+		InStock:    true,
+		RecordedAt: now.Add(time.Second),
+		Source:     "scraper",
+	})
+	p, err := svc.Portfolio(ctx, "user_1")
+	if err != nil {
+		t.Fatalf("Portfolio: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Items are newest first with changes and a basket total")
+	if len(p.Items) != 3 || p.Items[0].ProductID != testhelpers.FixtureSecondProductID || p.Items[2].ProductID != "prod_delisted" {
+		t.Fatalf("Items = %+v", p.Items)
+	}
+	gsw := p.Items[1]
+	testhelpers.LogTestAssertion(logger, "change", -200.0, gsw.Change)
+	if gsw.Best == nil || gsw.Best.Price != 2999 || gsw.Change != -200 || gsw.ChangePercent != -6.25 {
+		t.Errorf("Fixture entry = %+v", gsw)
+	}
+	if p.Items[2].Product != nil || p.Items[2].Best != nil || p.Unavailable != 1 {
+		t.Errorf("Delisted entry = %+v, unavailable %d", p.Items[2], p.Unavailable)
+	}
+	second := p.Items[0]
+	if second.Best == nil || p.BasketTotal != domain.Round2(2999+second.Best.Price) || p.BasketChange != -200 {
+		t.Errorf("Basket = %v (change %v), second best %+v", p.BasketTotal, p.BasketChange, second.Best)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestWatchlistService_Portfolio", true)
+}
+
+func TestWatchlistService_AddValidation(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestWatchlistService_AddValidation", "internal/services")
+
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+	prices := NewPriceService(PriceRepos{
+		Products:  store.Products(),
+		Retailers: store.Retailers(),
+		Listings:  store.Listings(),
+		Prices:    store.Prices(),
+	}, logger)
+	svc := NewWatchlistService(store.Watchlists(), prices, logger).WithMaxItems(2)
+
+	testCases := []struct {
+		name      string
+		userID    string
+		productID string
+		wantErr   error
+	}{
+		{"First product", "user_1", testhelpers.FixtureProductID, nil},
+		{"Same product again", "user_1", testhelpers.FixtureProductID, domain.ErrConflict},
+		{"Second product", "user_1", testhelpers.FixtureSecondProductID, nil},
+		{"Over the limit", "user_1", "prod_missing", domain.ErrConflict},
+		{"Unknown product", "user_2", "prod_missing", domain.ErrNotFound},
+		{"No product", "user_2", "", domain.ErrInvalid},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := svc.Add(t.Context(), tc.userID, tc.productID)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantErr, err)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Add error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+	if err := svc.Remove(t.Context(), "user_2", testhelpers.FixtureProductID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Removing an unwatched product = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestWatchlistService_AddValidation", true)
+}
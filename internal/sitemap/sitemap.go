@@ -0,0 +1,279 @@
+// Package sitemap generates the XML sitemaps search engines use to discover
+// product and comparison pages. Sitemaps are rendered in the background on a
+// schedule and served from memory.
+package sitemap
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+	"github.com/yourusername/whey-price-compare/internal/logctx"
+	"github.com/yourusername/whey-price-compare/internal/repositories"
+)
+
+// Paths served by the Generator.
+const (
+	IndexPath  = "/sitemap.xml"
+	PagePrefix = "/sitemaps/"
+)
+
+// MaxURLsPerSitemap is the protocol limit for a single sitemap file.
+const MaxURLsPerSitemap = 50000
+
+const scanPageSize = 500
+
+// Config configures sitemap generation.
+type Config struct {
+	// BaseURL is the public site origin the sitemap URLs are built on.
+	BaseURL string
+	// PageSize is the number of URLs per sitemap file.
+	PageSize int
+	// Interval is how often the sitemaps are regenerated by Run.
+	Interval time.Duration
+}
+
+// DefaultConfig returns sensible defaults: 10k URLs per file, rebuilt every
+// six hours.
+func DefaultConfig() Config {
+	return Config{
+		BaseURL:  "http://localhost:8080",
+		PageSize: 10000,
+		Interval: 6 * time.Hour,
+	}
+}
+
+// Source groups the repositories the generator reads from.
+type Source struct {
+	Products repositories.ProductRepository
+	Listings repositories.ListingRepository
+}
+
+// Generator renders and serves the sitemap index and its pages.
+type Generator struct {
+	cfg    Config
+	src    Source
+	logger *zap.Logger
+	now    func() time.Time
+
+	buildMu sync.Mutex
+	mu      sync.RWMutex
+	files   map[string][]byte
+	builtAt time.Time
+}
+
+// NewGenerator creates a Generator. Nothing is rendered until Generate or Run
+// is called, or the first request arrives.
+func NewGenerator(cfg Config, src Source, logger *zap.Logger) *Generator {
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	if cfg.PageSize <= 0 || cfg.PageSize > MaxURLsPerSitemap {
+		cfg.PageSize = DefaultConfig().PageSize
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultConfig().Interval
+	}
+	return &Generator{cfg: cfg, src: src, logger: logger, now: time.Now}
+}
+
+// Run generates the sitemaps immediately and then every Interval until ctx
+// is cancelled. Failures are logged and the previous sitemaps kept.
+func (g *Generator) Run(ctx context.Context) {
+	ticker := time.NewTicker(g.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if err := g.Generate(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			g.logger.Error("Sitemap generation failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+type entry struct {
+	loc        string
+	lastMod    time.Time
+	changeFreq string
+	priority   string
+}
+
+// Generate renders all sitemap files and swaps them in atomically.
+func (g *Generator) Generate(ctx context.Context) error {
+	g.buildMu.Lock()
+	defer g.buildMu.Unlock()
+
+	logger := g.logger.With(zap.String("operation", "GenerateSitemap"))
+	start := g.now()
+
+	var products, comparisons []entry
+	for offset := 0; ; offset += scanPageSize {
+		page, err := g.src.Products.List(ctx, repositories.ProductFilter{Limit: scanPageSize, Offset: offset})
+		if err != nil {
+			return fmt.Errorf("list products: %w", err)
+		}
+		for _, p := range page {
+			lastMod := p.UpdatedAt
+			listings, err := g.src.Listings.ByProduct(ctx, p.ID)
+			if err != nil {
+				return fmt.Errorf("load listings for %s: %w", p.ID, err)
+			}
+			for _, l := range listings {
+				if l.LastScrapedAt.After(lastMod) {
+					lastMod = l.LastScrapedAt
+				}
+			}
+			products = append(products, entry{
+				loc: g.cfg.BaseURL + httpx.ProductPagePath(p.ID), lastMod: lastMod, changeFreq: "daily", priority: "0.8",
+			})
+			if len(listings) > 0 {
+				comparisons = append(comparisons, entry{
+					loc: g.cfg.BaseURL + httpx.ComparePagePath(p.ID), lastMod: lastMod, changeFreq: "hourly", priority: "0.6",
+				})
+			}
+		}
+		if len(page) < scanPageSize {
+			break
+		}
+	}
+
+	files := make(map[string][]byte)
+	var index []sitemapRef
+	for _, set := range []struct {
+		name    string
+		entries []entry
+	}{{"products", products}, {"compare", comparisons}} {
+		for i := 0; i < len(set.entries); i += g.cfg.PageSize {
+			chunk := set.entries[i:min(i+g.cfg.PageSize, len(set.entries))]
+			name := fmt.Sprintf("%s-%d.xml", set.name, i/g.cfg.PageSize+1)
+			body, lastMod, err := renderURLSet(chunk)
+			if err != nil {
+				return err
+			}
+			files[name] = body
+			index = append(index, sitemapRef{Loc: g.cfg.BaseURL + PagePrefix + name, LastMod: formatLastMod(lastMod)})
+		}
+	}
+	body, err := encode(sitemapIndex{XMLNS: xmlns, Sitemaps: index})
+	if err != nil {
+		return err
+	}
+	files[""] = body
+
+	g.mu.Lock()
+	g.files, g.builtAt = files, start.UTC().Truncate(time.Second)
+	g.mu.Unlock()
+
+	logger.Info("Sitemaps generated",
+		zap.Int("product_urls", len(products)),
+		zap.Int("compare_urls", len(comparisons)),
+		zap.Int("files", len(index)),
+		zap.Duration("duration", g.now().Sub(start)),
+	)
+	return nil
+}
+
+// Register mounts the sitemap routes on mux.
+func (g *Generator) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+IndexPath, g.serve)
+	mux.HandleFunc("GET "+PagePrefix+"{name}", g.serve)
+}
+
+func (g *Generator) serve(w http.ResponseWriter, r *http.Request) {
+	g.mu.RLock()
+	ready := g.files != nil
+	g.mu.RUnlock()
+	if !ready {
+		// First request before the scheduler has run.
+		if err := g.Generate(r.Context()); err != nil {
+			logctx.Logger(r.Context(), g.logger).Error("Sitemap generation failed", zap.Error(err))
+			httpx.WriteError(w, r, http.StatusServiceUnavailable, httpx.CodeServiceUnavailable, "Sitemap not available yet", nil)
+			return
+		}
+	}
+
+	g.mu.RLock()
+	body, ok := g.files[r.PathValue("name")]
+	builtAt := g.builtAt
+	g.mu.RUnlock()
+	if !ok {
+		httpx.WriteError(w, r, http.StatusNotFound, httpx.CodeNotFound, "Sitemap not found", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	http.ServeContent(w, r, "", builtAt, bytes.NewReader(body))
+}
+
+const xmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+type urlSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	XMLNS   string   `xml:"xmlns,attr"`
+	URLs    []urlRef `xml:"url"`
+}
+
+type urlRef struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	XMLNS    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapRef `xml:"sitemap"`
+}
+
+type sitemapRef struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// renderURLSet encodes one sitemap file and reports its newest lastmod.
+func renderURLSet(entries []entry) ([]byte, time.Time, error) {
+	sorted := append([]entry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].loc < sorted[j].loc })
+
+	var newest time.Time
+	set := urlSet{XMLNS: xmlns, URLs: make([]urlRef, 0, len(sorted))}
+	for _, e := range sorted {
+		if e.lastMod.After(newest) {
+			newest = e.lastMod
+		}
+		set.URLs = append(set.URLs, urlRef{
+			Loc: e.loc, LastMod: formatLastMod(e.lastMod), ChangeFreq: e.changeFreq, Priority: e.priority,
+		})
+	}
+	body, err := encode(set)
+	return body, newest, err
+}
+
+func encode(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, fmt.Errorf("encode sitemap: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func formatLastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,119 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/repositories/memory"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func newTestGenerator(t *testing.T, pageSize int) (*Generator, *memory.Store, *http.ServeMux) {
+	t.Helper()
+	logger := testhelpers.SetupTestLogger(t)
+	store := memory.NewStore()
+	testhelpers.SeedCatalog(store, time.Now())
+
+	cfg := DefaultConfig()
+	cfg.BaseURL = "https://deals.example/"
+	cfg.PageSize = pageSize
+	g := NewGenerator(cfg, Source{Products: store.Products(), Listings: store.Listings()}, logger)
+	mux := http.NewServeMux()
+	g.Register(mux)
+	return g, store, mux
+}
+
+func fetch(h http.Handler, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestGenerator_Pagination(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestGenerator_Pagination", "internal/sitemap")
+
+	_, _, mux := newTestGenerator(t, 1)
+
+	testhelpers.LogTestStep(logger, "act", "Fetching the index lazily")
+	rec := fetch(mux, IndexPath)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var index sitemapIndex
+	if err := xml.Unmarshal(rec.Body.Bytes(), &index); err != nil {
+		t.Fatalf("Invalid sitemap index: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "One file per product per page type")
+	want := []string{"products-1.xml", "products-2.xml", "compare-1.xml", "compare-2.xml"}
+	testhelpers.LogTestAssertion(logger, "sitemap files", len(want), len(index.Sitemaps))
+	if len(index.Sitemaps) != len(want) {
+		t.Fatalf("Index lists %d sitemaps, want %d", len(index.Sitemaps), len(want))
+	}
+	for i, name := range want {
+		if index.Sitemaps[i].Loc != "https://deals.example"+PagePrefix+name {
+			t.Errorf("Sitemaps[%d] = %q, want %s", i, index.Sitemaps[i].Loc, name)
+		}
+		if index.Sitemaps[i].LastMod == "" {
+			t.Errorf("Sitemaps[%d] has no lastmod", i)
+		}
+
+		page := fetch(mux, PagePrefix+name)
+		var set urlSet
+		if err := xml.Unmarshal(page.Body.Bytes(), &set); err != nil {
+			t.Fatalf("Invalid sitemap %s: %v", name, err)
+		}
+		if len(set.URLs) != 1 {
+			t.Errorf("%s has %d URLs, want 1", name, len(set.URLs))
+		}
+	}
+
+	if rec := fetch(mux, PagePrefix+"products-9.xml"); rec.Code != http.StatusNotFound {
+		t.Errorf("Unknown sitemap status = %d, want 404", rec.Code)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestGenerator_Pagination", true)
+}
+
+func TestGenerator_LastModFromPrices(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestGenerator_LastModFromPrices", "internal/sitemap")
+
+	g, store, mux := newTestGenerator(t, DefaultConfig().PageSize)
+	ctx := t.Context()
+
+	testhelpers.LogTestStep(logger, "act", "Recording a fresh price and regenerating")
+	scraped := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	store.AddPricePoint(domain.PricePoint{ListingID: testhelpers.FixtureListingAmazon, Price: 3099, InStock: true, RecordedAt: scraped})
+	if err := g.Generate(ctx); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	var set urlSet
+	if err := xml.Unmarshal(fetch(mux, PagePrefix+"products-1.xml").Body.Bytes(), &set); err != nil {
+		t.Fatalf("Invalid sitemap: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Product lastmod follows its newest listing")
+	found := false
+	for _, u := range set.URLs {
+		if strings.HasSuffix(u.Loc, "/products/"+testhelpers.FixtureProductID) {
+			found = true
+			testhelpers.LogTestAssertion(logger, "lastmod", scraped.Format(time.RFC3339), u.LastMod)
+			if u.LastMod != scraped.Format(time.RFC3339) {
+				t.Errorf("lastmod = %q, want %q", u.LastMod, scraped.Format(time.RFC3339))
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Product URL missing from sitemap: %+v", set.URLs)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestGenerator_LastModFromPrices", true)
+}
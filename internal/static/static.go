@@ -0,0 +1,177 @@
+// Package static serves the frontend's built assets. The build writes
+// Brotli and gzip copies next to each asset (app.js.br, app.js.gz), and the
+// handler sends the smallest one the client accepts, so assets go out
+// compressed at the highest level without compressing per request.
+package static
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/httpx"
+)
+
+// Prefix is the URL path the assets are served under.
+const Prefix = "/static/"
+
+// variants lists the precompressed encodings in server preference order;
+// Brotli is smaller than gzip for the same asset.
+var variants = []struct {
+	encoding string
+	ext      string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// fingerprinted matches asset names carrying a content hash, such as
+// app.3f9a2c1d.js. Their content never changes under the same name.
+var fingerprinted = regexp.MustCompile(`\.[0-9a-f]{8,}\.[a-z0-9]+$`)
+
+// Config configures the asset handler.
+type Config struct {
+	// MaxAge is how long browsers may reuse assets without a content hash
+	// in their name. Fingerprinted assets are cached for a year.
+	MaxAge time.Duration
+}
+
+// DefaultConfig lets browsers reuse unfingerprinted assets for an hour.
+func DefaultConfig() Config {
+	return Config{MaxAge: time.Hour}
+}
+
+// Handler serves assets from a file system, preferring precompressed
+// variants.
+type Handler struct {
+	cfg    Config
+	fsys   fs.FS
+	logger *zap.Logger
+}
+
+// NewHandler creates a Handler serving the assets in fsys, typically
+// os.DirFS of the frontend's dist directory.
+func NewHandler(cfg Config, fsys fs.FS, logger *zap.Logger) *Handler {
+	return &Handler{cfg: cfg, fsys: fsys, logger: logger}
+}
+
+// Register mounts the assets under Prefix.
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.Handle("GET "+Prefix, http.StripPrefix(strings.TrimSuffix(Prefix, "/"), h))
+}
+
+// ServeHTTP serves the asset at the request path, relative to the file
+// system root. Directories and dotfiles are not served.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if !fs.ValidPath(name) || name == "." || hidden(name) {
+		http.NotFound(w, r)
+		return
+	}
+	info, err := fs.Stat(h.fsys, name)
+	if err != nil || !info.Mode().IsRegular() {
+		http.NotFound(w, r)
+		return
+	}
+
+	hdr := w.Header()
+	httpx.AddVary(hdr, "Accept-Encoding")
+	servedName, encoding := name, ""
+	if enc, ext, vinfo := h.variant(name, info, r.Header.Get("Accept-Encoding")); enc != "" {
+		servedName, encoding, info = name+ext, enc, vinfo
+		hdr.Set("Content-Encoding", encoding)
+	}
+
+	f, err := h.fsys.Open(servedName)
+	if err != nil {
+		h.fail(w, servedName, err)
+		return
+	}
+	defer f.Close()
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		h.fail(w, servedName, errors.New("file does not support seeking"))
+		return
+	}
+
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		hdr.Set("Content-Type", ct)
+	}
+	// Each variant is a different representation, so it needs its own
+	// validator.
+	hdr.Set("ETag", fmt.Sprintf(`"%x-%x%s"`, info.ModTime().UnixNano(), info.Size(), encoding))
+	if fingerprinted.MatchString(name) {
+		hdr.Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else if h.cfg.MaxAge > 0 {
+		hdr.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.cfg.MaxAge.Seconds())))
+	}
+	http.ServeContent(w, r, name, info.ModTime(), content)
+}
+
+// variant picks the precompressed variant of name the Accept-Encoding header
+// rates highest, returning its encoding, file extension and info, or "" to
+// serve the asset as is. Variants older than the asset are left over from an
+// earlier build and are ignored.
+func (h *Handler) variant(name string, orig fs.FileInfo, acceptEncoding string) (string, string, fs.FileInfo) {
+	if acceptEncoding == "" {
+		return "", "", nil
+	}
+	qualities := make(map[string]float64)
+	wildcard := 0.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		enc, q := httpx.ParseQuality(part)
+		if enc == "*" {
+			wildcard = q
+		} else if enc != "" {
+			qualities[enc] = q
+		}
+	}
+
+	var best, bestExt string
+	var bestInfo fs.FileInfo
+	bestQ := 0.0
+	for _, v := range variants {
+		q, ok := qualities[v.encoding]
+		if !ok {
+			q = wildcard
+		}
+		if q <= bestQ {
+			continue
+		}
+		info, err := fs.Stat(h.fsys, name+v.ext)
+		if err != nil || !info.Mode().IsRegular() || info.ModTime().Before(orig.ModTime()) {
+			continue
+		}
+		best, bestExt, bestInfo, bestQ = v.encoding, v.ext, info, q
+	}
+	return best, bestExt, bestInfo
+}
+
+func (h *Handler) fail(w http.ResponseWriter, name string, err error) {
+	h.logger.Error("Failed to serve static asset",
+		zap.String("operation", "ServeStatic"),
+		zap.String("asset", name),
+		zap.Error(err),
+	)
+	w.Header().Del("Content-Encoding")
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+// hidden reports whether any element of name starts with a dot.
+func hidden(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	return false
+}
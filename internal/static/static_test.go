@@ -0,0 +1,131 @@
+package static
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func assetFS(built time.Time) fstest.MapFS {
+	file := func(data string, mod time.Time) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(data), ModTime: mod}
+	}
+	return fstest.MapFS{
+		"app.js":              file("plain js", built),
+		"app.js.br":           file("brotli js", built),
+		"app.js.gz":           file("gzip js", built),
+		"app.3f9a2c1d.css":    file("plain css", built),
+		"app.3f9a2c1d.css.gz": file("gzip css", built),
+		"stale.js":            file("new js", built),
+		"stale.js.br":         file("old brotli js", built.Add(-time.Hour)),
+		"logo.svg":            file("<svg/>", built),
+		".env":                file("SECRET=<YOUR_SECRET_HERE>", built),
+		"img/icon.png":        file("png", built),
+	}
+}
+
+func TestHandler_ServesPrecompressedVariants(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestHandler_ServesPrecompressedVariants", "internal/static")
+
+	built := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	mux := http.NewServeMux()
+	NewHandler(DefaultConfig(), assetFS(built), logger).Register(mux)
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		wantStatus     int
+		wantBody       string
+		wantEncoding   string
+		wantType       string
+		wantCache      string
+	}{
+		{"brotli preferred", "/static/app.js", "gzip, deflate, br", http.StatusOK, "brotli js", "br", "text/javascript; charset=utf-8", "public, max-age=3600"},
+		{"gzip by quality", "/static/app.js", "br;q=0.5, gzip", http.StatusOK, "gzip js", "gzip", "text/javascript; charset=utf-8", "public, max-age=3600"},
+		{"wildcard", "/static/app.js", "*", http.StatusOK, "brotli js", "br", "text/javascript; charset=utf-8", "public, max-age=3600"},
+		{"encoding refused", "/static/app.js", "br;q=0, gzip;q=0", http.StatusOK, "plain js", "", "text/javascript; charset=utf-8", "public, max-age=3600"},
+		{"no accept-encoding", "/static/app.js", "", http.StatusOK, "plain js", "", "text/javascript; charset=utf-8", "public, max-age=3600"},
+		{"only some variants built", "/static/app.3f9a2c1d.css", "br, gzip", http.StatusOK, "gzip css", "gzip", "text/css; charset=utf-8", "public, max-age=31536000, immutable"},
+		{"stale variant ignored", "/static/stale.js", "br", http.StatusOK, "new js", "", "text/javascript; charset=utf-8", "public, max-age=3600"},
+		{"no variants", "/static/img/icon.png", "br, gzip", http.StatusOK, "png", "", "image/png", "public, max-age=3600"},
+		{"missing", "/static/missing.js", "br", http.StatusNotFound, "", "", "", ""},
+		{"directory", "/static/img/", "", http.StatusNotFound, "", "", "", ""},
+		{"dotfile", "/static/.env", "", http.StatusNotFound, "", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			testhelpers.LogTestAssertion(logger, tt.name, tt.wantEncoding, rec.Header().Get("Content-Encoding"))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if rec.Body.String() != tt.wantBody {
+				t.Errorf("Body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+			if got := rec.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+			if got := rec.Header().Get("Cache-Control"); got != tt.wantCache {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.wantCache)
+			}
+			if got := rec.Header().Get("Vary"); got != "Accept-Encoding" {
+				t.Errorf("Vary = %q, want Accept-Encoding", got)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestHandler_ServesPrecompressedVariants", true)
+}
+
+func TestHandler_ValidatorsPerVariant(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestHandler_ValidatorsPerVariant", "internal/static")
+
+	built := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	h := NewHandler(DefaultConfig(), assetFS(built), logger)
+	get := func(acceptEncoding, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/app.js", nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Fetching the brotli and gzip variants")
+	brTag := get("br", "").Header().Get("ETag")
+	gzTag := get("gzip", "").Header().Get("ETag")
+
+	testhelpers.LogTestStep(logger, "assert", "Variants have distinct ETags that revalidate")
+	if brTag == "" || brTag == gzTag {
+		t.Fatalf("ETags br=%q gzip=%q, want distinct", brTag, gzTag)
+	}
+	if rec := get("br", brTag); rec.Code != http.StatusNotModified {
+		t.Errorf("Revalidating brotli: status = %d, want %d", rec.Code, http.StatusNotModified)
+	}
+	if rec := get("gzip", brTag); rec.Code != http.StatusOK {
+		t.Errorf("Brotli ETag on gzip request: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestHandler_ValidatorsPerVariant", true)
+}
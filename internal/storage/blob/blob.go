@@ -0,0 +1,186 @@
+// Package blob stores objects, such as product images and retailer page
+// snapshots, by key in S3, Google Cloud Storage or a local directory. A
+// store is named by a URL, as a database is by DATABASE_URL, so switching
+// backends is configuration only.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/awsv4"
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// ErrNotFound is returned by Get for a key with no object.
+var ErrNotFound = fmt.Errorf("blob: %w", domain.ErrNotFound)
+
+// ErrInvalidKey is returned for a key that is empty, absolute, or climbs
+// out of the store with "..".
+var ErrInvalidKey = errors.New("blob: invalid key")
+
+// Attrs are the headers an object is served with.
+type Attrs struct {
+	ContentType  string
+	CacheControl string
+}
+
+// Store keeps objects by key. Keys are slash-separated paths such as
+// "images/3f2a…/card.webp". Implementations are safe for concurrent use.
+type Store interface {
+	// Put stores data as key, replacing any object there.
+	Put(ctx context.Context, key string, data []byte, attrs Attrs) error
+	// Get returns key's content, or ErrNotFound. The caller closes it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	// URL returns where browsers fetch key from.
+	URL(key string) string
+}
+
+// Config configures Open.
+type Config struct {
+	// URL names the store: file:///var/lib/whey/blobs for a directory,
+	// s3://bucket/prefix or gs://bucket/prefix for a bucket, the prefix
+	// being optional.
+	URL string
+	// PublicURL, if set, is the root objects are served from, such as a
+	// CDN in front of the bucket; URL(key) is PublicURL/key. Otherwise a
+	// bucket's own URL is used, and a directory's objects are served by
+	// the API under /media/.
+	PublicURL string
+	// S3 signs requests to S3 buckets.
+	S3 S3Config
+	// GCS authorises requests to Cloud Storage buckets.
+	GCS GCSConfig
+	// Timeout bounds each request to a bucket.
+	Timeout time.Duration
+}
+
+// DefaultConfig returns a one minute timeout; URL must be set.
+func DefaultConfig() Config {
+	return Config{Timeout: time.Minute}
+}
+
+// ConfigFromEnv reads BLOB_URL and BLOB_PUBLIC_URL, the AWS_* variables
+// and S3_ENDPOINT for S3, and GOOGLE_APPLICATION_CREDENTIALS for Cloud
+// Storage, through getenv.
+func ConfigFromEnv(getenv func(string) string) Config {
+	cfg := DefaultConfig()
+	cfg.URL = getenv("BLOB_URL")
+	cfg.PublicURL = getenv("BLOB_PUBLIC_URL")
+	cfg.S3 = S3Config{
+		Region:   getenv("AWS_REGION"),
+		Endpoint: getenv("S3_ENDPOINT"),
+		Credentials: awsv4.Credentials{
+			AccessKeyID:     getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    getenv("AWS_SESSION_TOKEN"),
+		},
+	}
+	cfg.GCS = GCSConfig{CredentialsFile: getenv("GOOGLE_APPLICATION_CREDENTIALS")}
+	return cfg
+}
+
+// Open returns the store cfg.URL names. It checks the configuration, not
+// that the store is reachable.
+func Open(cfg Config) (Store, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("blob: invalid URL: %w", err)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultConfig().Timeout
+	}
+	prefix := strings.Trim(u.Path, "/")
+	var store Store
+	switch u.Scheme {
+	case "file":
+		if u.Path == "" || u.Host != "" {
+			return nil, fmt.Errorf("blob: invalid URL %q: want file:///absolute/dir", cfg.URL)
+		}
+		store, err = NewLocal(u.Path)
+	case "s3":
+		if u.Host == "" {
+			return nil, fmt.Errorf("blob: invalid URL %q: want s3://bucket/prefix", cfg.URL)
+		}
+		s3 := cfg.S3
+		s3.Bucket, s3.Prefix, s3.Timeout = u.Host, prefix, cfg.Timeout
+		store, err = NewS3(s3)
+	case "gs":
+		if u.Host == "" {
+			return nil, fmt.Errorf("blob: invalid URL %q: want gs://bucket/prefix", cfg.URL)
+		}
+		gcs := cfg.GCS
+		gcs.Bucket, gcs.Prefix, gcs.Timeout = u.Host, prefix, cfg.Timeout
+		store, err = NewGCS(gcs)
+	default:
+		return nil, fmt.Errorf("blob: unsupported URL %q: want file://, s3:// or gs://", cfg.URL)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cfg.PublicURL != "" {
+		store = publicStore{Store: store, root: strings.TrimRight(cfg.PublicURL, "/")}
+	}
+	return store, nil
+}
+
+// publicStore serves a store's objects from another root.
+type publicStore struct {
+	Store
+	root string
+}
+
+func (s publicStore) URL(key string) string { return s.root + "/" + escapeKey(key) }
+
+// CheckKey returns ErrInvalidKey if key cannot name an object.
+func CheckKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.ContainsAny(key, "\\\x00") {
+		return fmt.Errorf("%w %q", ErrInvalidKey, key)
+	}
+	for _, seg := range strings.Split(key, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return fmt.Errorf("%w %q", ErrInvalidKey, key)
+		}
+	}
+	return nil
+}
+
+// join prefixes key with prefix, if any.
+func join(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "/" + key
+}
+
+// escapeKey escapes each segment of key for a URL path.
+func escapeKey(key string) string {
+	segs := strings.Split(key, "/")
+	for i, s := range segs {
+		segs[i] = url.PathEscape(s)
+	}
+	return strings.Join(segs, "/")
+}
+
+// ImagePrefix holds product images. Their keys name their content, so
+// an object under it never changes.
+const ImagePrefix = "images/"
+
+// ImageKey is where the file name, such as "thumb.webp", of the image
+// whose source has the given content hash is stored.
+func ImageKey(hash, name string) string {
+	return ImagePrefix + hash + "/" + name
+}
+
+// SnapshotKey is where the page a listing was scraped from at is kept,
+// for debugging a selector against what the retailer served.
+func SnapshotKey(retailerID, listingID string, at time.Time) string {
+	return "snapshots/" + retailerID + "/" + listingID + "/" + at.UTC().Format("20060102T150405Z") + ".html"
+}
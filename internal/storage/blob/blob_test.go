@@ -0,0 +1,177 @@
+package blob
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestLocal_RoundTrip(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestLocal_RoundTrip", "internal/storage/blob")
+
+	testhelpers.LogTestStep(logger, "arrange", "A local store in a temporary directory")
+	store, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	ctx := t.Context()
+	key := ImageKey("3f2a", "card.webp")
+
+	testhelpers.LogTestStep(logger, "act", "Storing, replacing and reading back an image")
+	if err := store.Put(ctx, key, []byte("old"), Attrs{ContentType: "image/webp"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := store.Put(ctx, key, []byte("new"), Attrs{ContentType: "image/webp"}); err != nil {
+		t.Fatalf("Put again: %v", err)
+	}
+	body, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, _ := io.ReadAll(body)
+	_ = body.Close()
+
+	testhelpers.LogTestStep(logger, "assert", "The latest object comes back and is served under /media/")
+	testhelpers.LogTestAssertion(logger, "object", "new", string(got))
+	if string(got) != "new" {
+		t.Errorf("Get = %q, want %q", got, "new")
+	}
+	if u := store.URL(key); u != "/media/images/3f2a/card.webp" {
+		t.Errorf("URL = %q", u)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Deleting the image twice")
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("Delete of a missing key = %v, want nil", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "The image is gone and bad keys are refused")
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) || !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Get after delete = %v, want ErrNotFound", err)
+	}
+	for _, bad := range []string{"", "/etc/passwd", "images/../../x", "a//b", `a\b`} {
+		if err := store.Put(ctx, bad, nil, Attrs{}); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) = %v, want ErrInvalidKey", bad, err)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestLocal_RoundTrip", true)
+}
+
+func TestOpen(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestOpen", "internal/storage/blob")
+
+	dir := t.TempDir()
+	s3 := S3Config{Region: "ap-south-1"}
+	s3.Credentials.AccessKeyID, s3.Credentials.SecretAccessKey = "AKIDTEST", "test-only-secret"
+	testCases := []struct {
+		name, url, public string
+		wantURL           string
+		wantErr           bool
+	}{
+		{"directory", "file://" + dir, "", "/media/images/a.png", false},
+		{"directory behind a CDN", "file://" + dir, "https://cdn.example.com/", "https://cdn.example.com/images/a.png", false},
+		{"s3 bucket", "s3://media/whey", "", "https://media.s3.ap-south-1.amazonaws.com/whey/images/a.png", false},
+		{"gcs bucket", "gs://media", "", "https://storage.googleapis.com/media/images/a.png", false},
+		{"relative directory", "file://blobs", "", "", true},
+		{"s3 without a bucket", "s3:///whey", "", "", true},
+		{"unknown scheme", "ftp://media", "", "", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.URL, cfg.PublicURL, cfg.S3 = tc.url, tc.public, s3
+			store, err := Open(cfg)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Open(%q) error = %v, wantErr %v", tc.url, err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := store.URL("images/a.png")
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantURL, got)
+			if got != tc.wantURL {
+				t.Errorf("URL = %q, want %q", got, tc.wantURL)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestOpen", true)
+}
+
+func TestSnapshotKey(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestSnapshotKey", "internal/storage/blob")
+
+	at := time.Date(2025, 3, 4, 10, 30, 0, 0, time.FixedZone("IST", 5*3600+1800))
+	got := SnapshotKey("r_1", "l_2", at)
+	want := "snapshots/r_1/l_2/20250304T050000Z.html"
+	testhelpers.LogTestAssertion(logger, "key", want, got)
+	if got != want {
+		t.Errorf("SnapshotKey = %q, want %q", got, want)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestSnapshotKey", true)
+}
+
+func TestHandler(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestHandler", "internal/storage/blob")
+
+	testhelpers.LogTestStep(logger, "arrange", "A local store with an image and a snapshot, mounted on a mux")
+	store, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	if err := store.Put(t.Context(), ImageKey("3f2a", "card.png"), []byte("png"), Attrs{ContentType: "image/png"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := store.Put(t.Context(), "snapshots/r_1/page.html", []byte("<p>"), Attrs{ContentType: "text/html"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	mux := http.NewServeMux()
+	NewHandler(store, logger).Register(mux)
+
+	testCases := []struct {
+		path       string
+		wantStatus int
+		wantType   string
+		wantCache  string
+	}{
+		{"/media/images/3f2a/card.png", http.StatusOK, "image/png", ImmutableCacheControl},
+		{"/media/snapshots/r_1/page.html", http.StatusNotFound, "", ""},
+		{"/media/images/3f2a/thumb.png", http.StatusNotFound, "", ""},
+		{"/media/images/3f2a/..%2f..%2fsecret", http.StatusNotFound, "", ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			testhelpers.LogTestStep(logger, "act", "GET "+tc.path)
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			testhelpers.LogTestAssertion(logger, "status", tc.wantStatus, rec.Code)
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			if tc.wantType != "" && rec.Header().Get("Content-Type") != tc.wantType {
+				t.Errorf("Content-Type = %q, want %q", rec.Header().Get("Content-Type"), tc.wantType)
+			}
+			if tc.wantCache != "" && rec.Header().Get("Cache-Control") != tc.wantCache {
+				t.Errorf("Cache-Control = %q, want %q", rec.Header().Get("Cache-Control"), tc.wantCache)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestHandler", true)
+}
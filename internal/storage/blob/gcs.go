@@ -0,0 +1,271 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gcsScope is the OAuth scope of tokens for the GCS store.
+const gcsScope = "https://www.googleapis.com/auth/devstorage.read_write"
+
+// metadataTokenURL is where a Google Cloud instance gets its service
+// account's tokens.
+var metadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// GCSConfig configures the Cloud Storage store. Bucket and Prefix come
+// from the store's URL.
+type GCSConfig struct {
+	Bucket string
+	Prefix string
+	// CredentialsFile is a service account key in JSON. Empty uses the
+	// service account of the instance the API runs on, from the metadata
+	// server, as on GKE or Cloud Run.
+	CredentialsFile string
+	// Endpoint is the API root; empty means https://storage.googleapis.com.
+	Endpoint string
+	Timeout  time.Duration
+}
+
+// GCS stores objects in a Cloud Storage bucket through its XML API, with
+// OAuth tokens from a service account.
+type GCS struct {
+	cfg    GCSConfig
+	client *http.Client
+	tokens *tokenSource
+}
+
+// NewGCS creates a GCS store. A credentials file is read and its key
+// parsed now; tokens are fetched on first use.
+func NewGCS(cfg GCSConfig) (*GCS, error) {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultConfig().Timeout
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://storage.googleapis.com"
+	}
+	client := &http.Client{Timeout: cfg.Timeout}
+	tokens := &tokenSource{client: client, now: time.Now}
+	if cfg.CredentialsFile != "" {
+		sa, err := readServiceAccount(cfg.CredentialsFile)
+		if err != nil {
+			return nil, err
+		}
+		tokens.account = sa
+	}
+	return &GCS{cfg: cfg, client: client, tokens: tokens}, nil
+}
+
+func (g *GCS) objectURL(key string) string {
+	return g.cfg.Endpoint + "/" + url.PathEscape(g.cfg.Bucket) + "/" + escapeKey(join(g.cfg.Prefix, key))
+}
+
+// Put implements Store.
+func (g *GCS) Put(ctx context.Context, key string, data []byte, attrs Attrs) error {
+	if err := CheckKey(key); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, g.objectURL(key), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	setAttrs(req, attrs)
+	resp, err := g.do(req)
+	if err != nil {
+		return fmt.Errorf("gcs put %s: %w", key, err)
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+// Get implements Store.
+func (g *GCS) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := CheckKey(key); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.objectURL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := g.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gcs get %s: %w", key, err)
+	}
+	return resp.Body, nil
+}
+
+// Delete implements Store.
+func (g *GCS) Delete(ctx context.Context, key string) error {
+	if err := CheckKey(key); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, g.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := g.do(req)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("gcs delete %s: %w", key, err)
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+// URL implements Store. The bucket must allow public reads.
+func (g *GCS) URL(key string) string {
+	return g.objectURL(key)
+}
+
+// do authorises and sends req, returning the response if it succeeded.
+func (g *GCS) do(req *http.Request) (*http.Response, error) {
+	token, err := g.tokens.token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	return checkResponse(resp)
+}
+
+// serviceAccount is the part of a service account key file used to sign
+// token requests.
+type serviceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+	key         *rsa.PrivateKey
+}
+
+func readServiceAccount(path string) (*serviceAccount, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("blob: GCS credentials: %w", err)
+	}
+	var sa serviceAccount
+	if err := json.Unmarshal(raw, &sa); err != nil {
+		return nil, fmt.Errorf("blob: GCS credentials: %w", err)
+	}
+	if sa.ClientEmail == "" || sa.TokenURI == "" {
+		return nil, errors.New("blob: GCS credentials: want a service account key with client_email and token_uri")
+	}
+	block, _ := pem.Decode([]byte(sa.PrivateKey))
+	if block == nil {
+		return nil, errors.New("blob: GCS credentials: private_key is not PEM")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if err != nil || !ok {
+		return nil, errors.New("blob: GCS credentials: private_key is not an RSA key")
+	}
+	sa.key = key
+	return &sa, nil
+}
+
+// tokenSource fetches OAuth access tokens and reuses each until a minute
+// before it expires: by the JWT bearer grant with a service account's key,
+// or from the metadata server without one.
+type tokenSource struct {
+	account *serviceAccount
+	client  *http.Client
+	now     func() time.Time
+
+	mu      sync.Mutex
+	current string
+	expires time.Time
+}
+
+func (t *tokenSource) token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current != "" && t.now().Before(t.expires.Add(-time.Minute)) {
+		return t.current, nil
+	}
+	var (
+		req *http.Request
+		err error
+	)
+	if t.account != nil {
+		req, err = t.grantRequest(ctx)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, metadataTokenURL, nil)
+		if err == nil {
+			req.Header.Set("Metadata-Flavor", "Google")
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("gcs token: %w", err)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("gcs token: %w", err)
+	}
+	if resp, err = checkResponse(resp); err != nil {
+		return "", fmt.Errorf("gcs token: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var out struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&out); err != nil || out.AccessToken == "" {
+		return "", fmt.Errorf("gcs token: malformed response: %v", err)
+	}
+	t.current, t.expires = out.AccessToken, t.now().Add(time.Duration(out.ExpiresIn)*time.Second)
+	return t.current, nil
+}
+
+// grantRequest asks the service account's token URI for a token with an
+// assertion signed by its key (RFC 7523).
+func (t *tokenSource) grantRequest(ctx context.Context) (*http.Request, error) {
+	now := t.now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]any{
+		"iss":   t.account.ClientEmail,
+		"scope": gcsScope,
+		"aud":   t.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, t.account.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {unsigned + "." + base64.RawURLEncoding.EncodeToString(sig)},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req, nil
+}
@@ -0,0 +1,118 @@
+package blob
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+// writeServiceAccount writes a service account key file whose token URI
+// is tokenURI.
+func writeServiceAccount(t *testing.T, tokenURI string) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey: %v", err)
+	}
+	raw, _ := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "whey@test-project.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    tokenURI,
+	})
+	path := filepath.Join(t.TempDir(), "sa.json")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+func TestGCS_PutGet(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestGCS_PutGet", "internal/storage/blob")
+
+	testhelpers.LogTestStep(logger, "arrange", "A token endpoint and a bucket that wants its token")
+	var mu sync.Mutex
+	grants := 0
+	objects := make(map[string][]byte)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || r.FormValue("assertion") == "" {
+			http.Error(w, "invalid_grant", http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		grants++
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"access_token":"test-token","expires_in":3600}`))
+	})
+	mux.HandleFunc("/media/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			objects[r.URL.Path], _ = io.ReadAll(r.Body)
+		case http.MethodGet:
+			obj, ok := objects[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write(obj)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	store, err := NewGCS(GCSConfig{
+		Bucket:          "media",
+		CredentialsFile: writeServiceAccount(t, srv.URL+"/token"),
+		Endpoint:        srv.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewGCS: %v", err)
+	}
+	ctx := t.Context()
+	key := SnapshotKey("r_1", "l_1", time.Date(2025, 3, 4, 5, 0, 0, 0, time.UTC))
+
+	testhelpers.LogTestStep(logger, "act", "Uploading a snapshot and downloading it again")
+	if err := store.Put(ctx, key, []byte("<html></html>"), Attrs{ContentType: "text/html"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	body, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, _ := io.ReadAll(body)
+	_ = body.Close()
+
+	testhelpers.LogTestStep(logger, "assert", "The snapshot comes back and one token served both requests")
+	testhelpers.LogTestAssertion(logger, "grants", 1, grants)
+	if string(got) != "<html></html>" {
+		t.Errorf("Get = %q", got)
+	}
+	if grants != 1 {
+		t.Errorf("token grants = %d, want 1", grants)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestGCS_PutGet", true)
+}
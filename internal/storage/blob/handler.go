@@ -0,0 +1,63 @@
+package blob
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// ImmutableCacheControl lets browsers and CDNs keep an object for a year
+// without revalidating it, for objects whose key names their content.
+const ImmutableCacheControl = "public, max-age=31536000, immutable"
+
+// Handler serves a store's images under MediaPrefix, for a local store
+// that has no server of its own. Buckets serve their objects directly.
+// Page snapshots are retailers' HTML, so they are never served from the
+// site's origin.
+type Handler struct {
+	store  Store
+	logger *zap.Logger
+}
+
+// NewHandler creates a Handler for store.
+func NewHandler(store Store, logger *zap.Logger) *Handler {
+	return &Handler{store: store, logger: logger}
+}
+
+// Register mounts the handler on mux.
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.Handle("GET "+MediaPrefix+ImagePrefix, h)
+}
+
+// ServeHTTP serves the image keyed by the path after MediaPrefix, typed by
+// its extension and cached for good.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, MediaPrefix)
+	if CheckKey(key) != nil || !strings.HasPrefix(key, ImagePrefix) {
+		http.NotFound(w, r)
+		return
+	}
+	body, err := h.store.Get(r.Context(), key)
+	if errors.Is(err, ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to read stored object", zap.String("operation", "Get"), zap.String("key", key), zap.Error(err))
+		http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		return
+	}
+	defer func() { _ = body.Close() }()
+	if ct := mime.TypeByExtension(path.Ext(key)); ct != "" {
+		w.Header().Set("Content-Type", ct)
+	}
+	w.Header().Set("Cache-Control", ImmutableCacheControl)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// A failed copy is the client hanging up.
+	_, _ = io.Copy(w, body)
+}
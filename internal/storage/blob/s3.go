@@ -0,0 +1,152 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/awsv4"
+)
+
+// S3Config configures the S3 store. Bucket and Prefix come from the
+// store's URL.
+type S3Config struct {
+	Bucket      string
+	Prefix      string
+	Region      string
+	Credentials awsv4.Credentials
+	// Endpoint is the API root of an S3-compatible store, such as MinIO or
+	// Cloudflare R2, addressed path-style. Empty means AWS, addressed
+	// virtual-hosted style.
+	Endpoint string
+	Timeout  time.Duration
+}
+
+// S3 stores objects in an S3 bucket, signing requests with SigV4.
+type S3 struct {
+	cfg    S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3 creates an S3 store.
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Region == "" || cfg.Credentials.AccessKeyID == "" || cfg.Credentials.SecretAccessKey == "" {
+		return nil, errors.New("blob: AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required for S3")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultConfig().Timeout
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	return &S3{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}, now: time.Now}, nil
+}
+
+// objectURL returns where key is stored.
+func (s *S3) objectURL(key string) string {
+	path := escapeKey(join(s.cfg.Prefix, key))
+	if s.cfg.Endpoint != "" {
+		return s.cfg.Endpoint + "/" + url.PathEscape(s.cfg.Bucket) + "/" + path
+	}
+	return "https://" + s.cfg.Bucket + ".s3." + s.cfg.Region + ".amazonaws.com/" + path
+}
+
+// Put implements Store.
+func (s *S3) Put(ctx context.Context, key string, data []byte, attrs Attrs) error {
+	if err := CheckKey(key); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	setAttrs(req, attrs)
+	resp, err := s.do(req, awsv4.PayloadHash(data))
+	if err != nil {
+		return fmt.Errorf("s3 put %s: %w", key, err)
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+// Get implements Store.
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := CheckKey(key); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req, awsv4.PayloadHash(nil))
+	if err != nil {
+		return nil, fmt.Errorf("s3 get %s: %w", key, err)
+	}
+	return resp.Body, nil
+}
+
+// Delete implements Store. S3 answers 204 whether or not key existed.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	if err := CheckKey(key); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req, awsv4.PayloadHash(nil))
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("s3 delete %s: %w", key, err)
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+// URL implements Store. The bucket must allow public reads of the prefix.
+func (s *S3) URL(key string) string {
+	return s.objectURL(key)
+}
+
+// do signs and sends req, returning the response if it succeeded.
+func (s *S3) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	awsv4.Sign(req, payloadHash, s.cfg.Credentials, s.cfg.Region, "s3", s.now())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	return checkResponse(resp)
+}
+
+// setAttrs sets the headers a bucket keeps and serves the object with.
+func setAttrs(req *http.Request, attrs Attrs) {
+	if attrs.ContentType != "" {
+		req.Header.Set("Content-Type", attrs.ContentType)
+	}
+	if attrs.CacheControl != "" {
+		req.Header.Set("Cache-Control", attrs.CacheControl)
+	}
+}
+
+// checkResponse returns resp if it succeeded, and otherwise closes it and
+// returns its status and the start of its body as an error, ErrNotFound
+// for a 404.
+func checkResponse(resp *http.Response) (*http.Response, error) {
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+}
@@ -0,0 +1,111 @@
+package blob
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/whey-price-compare/internal/awsv4"
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestS3_PutGetDelete(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestS3_PutGetDelete", "internal/storage/blob")
+
+	testhelpers.LogTestStep(logger, "arrange", "An S3-compatible store that checks signing")
+	var mu sync.Mutex
+	objects := make(map[string][]byte)
+	headers := make(map[string]http.Header)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDTEST/") ||
+			r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+			http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			objects[r.URL.Path] = body
+			headers[r.URL.Path] = r.Header.Clone()
+		case http.MethodGet:
+			obj, ok := objects[r.URL.Path]
+			if !ok {
+				http.Error(w, "NoSuchKey", http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(obj)
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+	store, err := NewS3(S3Config{
+		Bucket:      "media",
+		Prefix:      "whey",
+		Region:      "ap-south-1",
+		Endpoint:    srv.URL + "/",
+		Credentials: awsv4.Credentials{AccessKeyID: "AKIDTEST", SecretAccessKey: "test-only-secret"},
+	})
+	if err != nil {
+		t.Fatalf("NewS3: %v", err)
+	}
+	ctx := t.Context()
+	key := ImageKey("3f2a", "card.webp")
+
+	testhelpers.LogTestStep(logger, "act", "Uploading an image and downloading it again")
+	if err := store.Put(ctx, key, []byte("webp bytes"), Attrs{ContentType: "image/webp", CacheControl: ImmutableCacheControl}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	body, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, _ := io.ReadAll(body)
+	_ = body.Close()
+
+	testhelpers.LogTestStep(logger, "assert", "The object is stored path-style under the prefix with its headers")
+	testhelpers.LogTestAssertion(logger, "object", "webp bytes", string(got))
+	if string(got) != "webp bytes" {
+		t.Errorf("Get = %q", got)
+	}
+	stored := headers["/media/whey/images/3f2a/card.webp"]
+	if stored.Get("Content-Type") != "image/webp" || stored.Get("Cache-Control") != ImmutableCacheControl {
+		t.Errorf("stored %v, want image/webp and immutable at /media/whey/images/3f2a/card.webp", headers)
+	}
+
+	testhelpers.LogTestStep(logger, "act", "Deleting the image")
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "A missing object is ErrNotFound")
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after delete = %v, want ErrNotFound", err)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestS3_PutGetDelete", true)
+}
+
+func TestNewS3_RequiresCredentials(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestNewS3_RequiresCredentials", "internal/storage/blob")
+
+	_, err := NewS3(S3Config{Bucket: "media", Region: "ap-south-1"})
+	testhelpers.LogTestAssertion(logger, "error", true, err != nil)
+	if err == nil {
+		t.Error("NewS3 without keys succeeded, want an error")
+	}
+
+	testhelpers.LogTestComplete(logger, "TestNewS3_RequiresCredentials", true)
+}
@@ -0,0 +1,89 @@
+package testhelpers
+
+import (
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/domain"
+)
+
+// CatalogWriter is the subset of the in-memory store used to load fixtures.
+type CatalogWriter interface {
+	PutProduct(p domain.Product)
+	PutVariant(v domain.Variant)
+	PutRetailer(r domain.Retailer)
+	PutListing(l domain.Listing)
+	AddPricePoint(p domain.PricePoint) domain.PricePoint
+}
+
+// Fixture identifiers loaded by SeedCatalog.
+const (
+	FixtureProductID       = "prod_on_gsw"
+	FixtureSecondProductID = "prod_mb_biozyme"
+	FixtureVariantID       = "var_on_gsw_2270_choc"
+	FixtureListingAmazon   = "lst_amazon_gsw_2270"
+	FixtureListingFlipkart = "lst_flipkart_gsw_2270"
+	FixtureListingHK       = "lst_healthkart_gsw_2270"
+)
+
+// SeedCatalog loads a small, realistic catalog: two products, three
+// retailers and a week of price history ending at now.
+func SeedCatalog(w CatalogWriter, now time.Time) {
+	for _, r := range []domain.Retailer{
+		{ID: "amazon", Name: "Amazon India", Slug: "amazon", WebsiteURL: "https://www.amazon.in", RequestsPerMinute: 15, IsActive: true},
+		{ID: "flipkart", Name: "Flipkart", Slug: "flipkart", WebsiteURL: "https://www.flipkart.com", RequestsPerMinute: 12, IsActive: true},
+		{ID: "healthkart", Name: "HealthKart", Slug: "healthkart", WebsiteURL: "https://www.healthkart.com", RequestsPerMinute: 10, IsActive: true},
+	} {
+		w.PutRetailer(r)
+	}
+
+	created := now.AddDate(0, -3, 0)
+	w.PutProduct(domain.Product{
+		ID: FixtureProductID, BrandID: "optimum-nutrition", Brand: "Optimum Nutrition",
+		CategoryID: "whey-protein", Category: "whey-protein",
+		Name: "Gold Standard 100% Whey", Slug: "gold-standard-100-whey",
+		ProteinPerServing: 24, ServingsPerContainer: 74, ServingSizeGrams: 30.4,
+		IsActive: true, CreatedAt: created, UpdatedAt: created,
+	})
+	w.PutProduct(domain.Product{
+		ID: FixtureSecondProductID, BrandID: "muscleblaze", Brand: "MuscleBlaze",
+		CategoryID: "whey-protein", Category: "whey-protein",
+		Name: "Biozyme Performance Whey", Slug: "biozyme-performance-whey",
+		ProteinPerServing: 25, ServingsPerContainer: 44, ServingSizeGrams: 33,
+		IsActive: true, CreatedAt: created, UpdatedAt: created,
+	})
+
+	w.PutVariant(domain.Variant{ID: FixtureVariantID, ProductID: FixtureProductID, Flavor: "Double Rich Chocolate", Size: "5 lb", SizeGrams: 2270, IsActive: true})
+	w.PutVariant(domain.Variant{ID: "var_mb_biozyme_1000_choc", ProductID: FixtureSecondProductID, Flavor: "Rich Milk Chocolate", Size: "1 kg", SizeGrams: 1000, IsActive: true})
+
+	listings := []domain.Listing{
+		{ID: FixtureListingAmazon, VariantID: FixtureVariantID, RetailerID: "amazon", URL: "https://www.amazon.in/dp/B000QSNYGI", OriginalPrice: 3999, Currency: domain.DefaultCurrency, IsActive: true},
+		{ID: FixtureListingFlipkart, VariantID: FixtureVariantID, RetailerID: "flipkart", URL: "https://www.flipkart.com/p/itm123", OriginalPrice: 3999, Currency: domain.DefaultCurrency, IsActive: true},
+		{ID: FixtureListingHK, VariantID: FixtureVariantID, RetailerID: "healthkart", URL: "https://www.healthkart.com/sv/on-gsw", OriginalPrice: 3999, Currency: domain.DefaultCurrency, IsActive: true},
+		{ID: "lst_amazon_biozyme_1000", VariantID: "var_mb_biozyme_1000_choc", RetailerID: "amazon", URL: "https://www.amazon.in/dp/B08BIOZYME", OriginalPrice: 2399, Currency: domain.DefaultCurrency, IsActive: true},
+	}
+	for _, l := range listings {
+		w.PutListing(l)
+	}
+
+	// A week of daily observations; Flipkart drops below Amazon on day 5 and
+	// HealthKart goes out of stock.
+	series := map[string][]float64{
+		FixtureListingAmazon:      {3399, 3399, 3349, 3349, 3299, 3299, 3299},
+		FixtureListingFlipkart:    {3449, 3449, 3449, 3399, 3199, 3199, 3199},
+		FixtureListingHK:          {3499, 3499, 3499, 3499, 3499, 3499, 3499},
+		"lst_amazon_biozyme_1000": {2199, 2199, 2149, 2149, 2149, 2099, 2099},
+	}
+	for listingID, prices := range series {
+		for i, price := range prices {
+			day := len(prices) - 1 - i
+			w.AddPricePoint(domain.PricePoint{
+				ListingID:  listingID,
+				Price:      price,
+				Currency:   domain.DefaultCurrency,
+				InStock:    !(listingID == FixtureListingHK && day == 0),
+				RecordedAt: now.AddDate(0, 0, -day).Add(-time.Hour),
+				Source:     "scraper",
+			})
+		}
+	}
+}
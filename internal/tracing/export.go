@@ -0,0 +1,352 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// scopeName names the instrumentation in exported spans.
+const scopeName = "github.com/yourusername/whey-price-compare/internal/tracing"
+
+// Config configures a Tracer.
+type Config struct {
+	// ServiceName is the service.name resource attribute spans are
+	// grouped by.
+	ServiceName string
+	// Endpoint is the collector's OTLP/HTTP traces URL, such as
+	// http://otel-collector:4318/v1/traces.
+	Endpoint string
+	// Headers are sent with every export, such as a vendor's API key.
+	Headers map[string]string
+	// SampleRatio is the share of new traces recorded, from 0 to 1.
+	// Traces begun by a caller follow the caller's decision.
+	SampleRatio float64
+	// Buffer is how many ended spans may wait for export. Spans beyond it
+	// are dropped rather than slowing requests down.
+	Buffer int
+	// BatchSize and FlushInterval bound how long a span waits in memory.
+	BatchSize     int
+	FlushInterval time.Duration
+	// Timeout bounds one export.
+	Timeout time.Duration
+}
+
+// DefaultConfig records one new trace in ten and exports every five
+// seconds.
+func DefaultConfig() Config {
+	return Config{
+		ServiceName:   "whey-api",
+		SampleRatio:   0.1,
+		Buffer:        4096,
+		BatchSize:     512,
+		FlushInterval: 5 * time.Second,
+		Timeout:       10 * time.Second,
+	}
+}
+
+// ParseHeaders reads headers in the OTEL_EXPORTER_OTLP_HEADERS format, a
+// comma-separated list of key=value pairs with URL-encoded values.
+func ParseHeaders(raw string) (map[string]string, error) {
+	out := make(map[string]string)
+	var err error
+	for _, entry := range strings.Split(raw, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(entry, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			// The entry may hold a credential; name only its position.
+			return nil, fmt.Errorf("header %d: want key=value", len(out)+1)
+		}
+		if out[key], err = url.QueryUnescape(strings.TrimSpace(value)); err != nil {
+			return nil, fmt.Errorf("header %s: value is not URL-encoded", key)
+		}
+	}
+	return out, nil
+}
+
+// Tracer starts root spans and exports every span of its traces. Ended
+// spans are queued without blocking; Run exports them in batches until its
+// context ends.
+type Tracer struct {
+	cfg     Config
+	client  *http.Client
+	logger  *zap.Logger
+	spans   chan spanData
+	dropped atomic.Int64
+}
+
+// NewTracer creates a Tracer. Call Run to start exporting.
+func NewTracer(cfg Config, logger *zap.Logger) *Tracer {
+	def := DefaultConfig()
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = def.ServiceName
+	}
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = def.Buffer
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = def.BatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = def.FlushInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	cfg.SampleRatio = min(max(cfg.SampleRatio, 0), 1)
+	return &Tracer{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		logger: logger,
+		spans:  make(chan spanData, cfg.Buffer),
+	}
+}
+
+// Start starts a span of kind: a child of the span in ctx, local or
+// remote, or else the root of a new trace, sampled at SampleRatio. A
+// trace not sampled returns a nil span, and a context whose children are
+// not recorded either.
+func (t *Tracer) Start(ctx context.Context, kind Kind, name string, attrs ...Attr) (context.Context, *Span) {
+	if parent := FromContext(ctx); parent != nil {
+		if !parent.sc.Sampled {
+			return ctx, nil
+		}
+		return t.start(ctx, parent.sc.TraceID, parent.sc.SpanID, kind, name, attrs)
+	}
+	traceID := newTraceID()
+	if !t.sample(traceID) {
+		return context.WithValue(ctx, spanKey{}, &Span{sc: SpanContext{TraceID: traceID, SpanID: newSpanID()}}), nil
+	}
+	return t.start(ctx, traceID, SpanID{}, kind, name, attrs)
+}
+
+// sample decides by the trace ID, as OpenTelemetry's TraceIdRatioBased
+// sampler does, so every service sampling at one ratio agrees.
+func (t *Tracer) sample(id TraceID) bool {
+	bound := uint64(t.cfg.SampleRatio * (1 << 63))
+	return binary.BigEndian.Uint64(id[8:])>>1 < bound
+}
+
+func (t *Tracer) start(ctx context.Context, traceID TraceID, parent SpanID, kind Kind, name string, attrs []Attr) (context.Context, *Span) {
+	s := &Span{
+		tracer: t,
+		sc:     SpanContext{TraceID: traceID, SpanID: newSpanID(), Sampled: true},
+		parent: parent,
+		kind:   kind,
+		start:  time.Now(),
+		name:   name,
+		attrs:  attrs,
+	}
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func (t *Tracer) enqueue(s spanData) {
+	select {
+	case t.spans <- s:
+	default:
+		if t.dropped.Add(1)%1000 == 1 {
+			t.logger.Warn("Span buffer full, dropping spans",
+				zap.String("operation", "ExportSpans"),
+				zap.Int64("dropped_total", t.dropped.Load()),
+			)
+		}
+	}
+}
+
+// Dropped returns how many spans were discarded because the buffer was
+// full.
+func (t *Tracer) Dropped() int64 { return t.dropped.Load() }
+
+// Run exports queued spans until ctx is cancelled, then exports whatever
+// is still buffered.
+func (t *Tracer) Run(ctx context.Context) {
+	ticker := time.NewTicker(t.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]spanData, 0, t.cfg.BatchSize)
+	for {
+		select {
+		case s := <-t.spans:
+			batch = append(batch, s)
+			if len(batch) >= t.cfg.BatchSize {
+				batch = t.flush(ctx, batch)
+			}
+		case <-ticker.C:
+			batch = t.flush(ctx, batch)
+		case <-ctx.Done():
+			for {
+				select {
+				case s := <-t.spans:
+					batch = append(batch, s)
+				default:
+					t.flush(context.WithoutCancel(ctx), batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+func (t *Tracer) flush(ctx context.Context, batch []spanData) []spanData {
+	if len(batch) == 0 {
+		return batch
+	}
+	if err := t.export(ctx, batch); err != nil {
+		t.logger.Warn("Span export failed",
+			zap.String("operation", "ExportSpans"),
+			zap.Int("spans", len(batch)),
+			zap.Error(err),
+		)
+	}
+	return batch[:0]
+}
+
+// export sends spans to the collector in one OTLP/HTTP request, encoded as
+// JSON.
+func (t *Tracer) export(ctx context.Context, spans []spanData) error {
+	body, err := json.Marshal(t.encode(spans))
+	if err != nil {
+		return fmt.Errorf("encode spans: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.cfg.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("export spans: %w", err)
+	}
+	for k, v := range t.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("export spans: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("export spans: collector answered %s", resp.Status)
+	}
+	return nil
+}
+
+// spanData is an ended span, as exported.
+type spanData struct {
+	sc         SpanContext
+	parent     SpanID
+	kind       Kind
+	name       string
+	start, end time.Time
+	attrs      []Attr
+	errMsg     string
+}
+
+// The OTLP/HTTP JSON encoding: IDs in hex, 64-bit integers as strings.
+type (
+	otlpExport struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttr `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope struct {
+			Name string `json:"name"`
+		} `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpSpan struct {
+		TraceID           string     `json:"traceId"`
+		SpanID            string     `json:"spanId"`
+		ParentSpanID      string     `json:"parentSpanId,omitempty"`
+		Name              string     `json:"name"`
+		Kind              Kind       `json:"kind"`
+		StartTimeUnixNano string     `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+		Attributes        []otlpAttr `json:"attributes,omitempty"`
+		Status            otlpStatus `json:"status"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code,omitempty"` // 2 is error
+		Message string `json:"message,omitempty"`
+	}
+	otlpAttr struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+	}
+)
+
+func (t *Tracer) encode(spans []spanData) otlpExport {
+	scope := otlpScopeSpans{Spans: make([]otlpSpan, 0, len(spans))}
+	scope.Scope.Name = scopeName
+	for _, s := range spans {
+		out := otlpSpan{
+			TraceID:           s.sc.TraceID.String(),
+			SpanID:            s.sc.SpanID.String(),
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        encodeAttrs(s.attrs),
+		}
+		if s.parent != (SpanID{}) {
+			out.ParentSpanID = s.parent.String()
+		}
+		if s.errMsg != "" {
+			out.Status = otlpStatus{Code: 2, Message: s.errMsg}
+		}
+		scope.Spans = append(scope.Spans, out)
+	}
+	return otlpExport{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: encodeAttrs([]Attr{String("service.name", t.cfg.ServiceName)})},
+		ScopeSpans: []otlpScopeSpans{scope},
+	}}}
+}
+
+func encodeAttrs(attrs []Attr) []otlpAttr {
+	if len(attrs) == 0 {
+		return nil
+	}
+	out := make([]otlpAttr, 0, len(attrs))
+	for _, a := range attrs {
+		var v otlpValue
+		switch x := a.Value.(type) {
+		case string:
+			v.StringValue = &x
+		case int64:
+			s := strconv.FormatInt(x, 10)
+			v.IntValue = &s
+		case float64:
+			v.DoubleValue = &x
+		case bool:
+			v.BoolValue = &x
+		default:
+			s := fmt.Sprint(x)
+			v.StringValue = &s
+		}
+		out = append(out, otlpAttr{Key: a.Key, Value: v})
+	}
+	return out
+}
@@ -0,0 +1,238 @@
+// Package tracing records spans of the work behind a request, from the
+// HTTP handler through cache and repository calls, and exports them to an
+// OpenTelemetry collector over OTLP/HTTP. Like internal/metrics it covers
+// what the services need without the SDK's dependency tree: spans,
+// ratio sampling that follows the caller's decision, and W3C traceparent
+// propagation.
+//
+// Code below the HTTP layer calls Start, which records a child of the span
+// in its context and does nothing outside a sampled trace:
+//
+//	ctx, span := tracing.Start(ctx, "cache.get", tracing.String("cache.name", name))
+//	defer span.End()
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceID identifies a trace across services.
+type TraceID [16]byte
+
+// String returns the ID in lowercase hex, as traceparent and OTLP carry it.
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// String returns the ID in lowercase hex.
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+// SpanContext is the part of a span that crosses process boundaries.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid reports whether both IDs are set; the zero IDs are invalid.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+// Traceparent formats sc as a W3C traceparent header value.
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceparent reads a W3C traceparent header value. Versions after 00
+// are read by their version 00 prefix, as the specification asks.
+func ParseTraceparent(s string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return SpanContext{}, false
+	}
+	var sc SpanContext
+	var flags [1]byte
+	if !decodeHex(sc.TraceID[:], parts[1]) || !decodeHex(sc.SpanID[:], parts[2]) || !decodeHex(flags[:], parts[3]) {
+		return SpanContext{}, false
+	}
+	if !sc.IsValid() {
+		return SpanContext{}, false
+	}
+	sc.Sampled = flags[0]&1 == 1
+	return sc, true
+}
+
+// decodeHex decodes lowercase hex s into dst, which it must fill exactly.
+func decodeHex(dst []byte, s string) bool {
+	if len(s) != 2*len(dst) || strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
+
+// Kind says which side of a call a span is on, with OTLP's numbering.
+type Kind int
+
+// Span kinds.
+const (
+	KindInternal Kind = 1
+	KindServer   Kind = 2
+	KindClient   Kind = 3
+)
+
+// Attr is a span attribute. Values are strings, int64s, float64s or bools.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// String returns a string attribute.
+func String(key, value string) Attr { return Attr{key, value} }
+
+// Int returns an integer attribute.
+func Int(key string, value int) Attr { return Attr{key, int64(value)} }
+
+// Bool returns a boolean attribute.
+func Bool(key string, value bool) Attr { return Attr{key, value} }
+
+// Span is one timed operation in a trace. A nil *Span, which Start returns
+// outside sampled traces, ignores every call, so callers never check.
+type Span struct {
+	tracer *Tracer // nil for a remote parent
+	sc     SpanContext
+	parent SpanID
+	kind   Kind
+	start  time.Time
+
+	mu     sync.Mutex
+	name   string
+	attrs  []Attr
+	errMsg string
+	ended  bool
+}
+
+// SpanContext returns the span's identity, for logging or propagation.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// SetName renames the span, as to its route once the request is routed.
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.name = name
+	s.mu.Unlock()
+}
+
+// SetAttributes adds attributes to the span.
+func (s *Span) SetAttributes(attrs ...Attr) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.attrs = append(s.attrs, attrs...)
+	s.mu.Unlock()
+}
+
+// RecordError marks the span failed with err. A nil err is ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.errMsg = err.Error()
+	s.mu.Unlock()
+}
+
+// End finishes the span and queues it for export. Later calls do nothing.
+func (s *Span) End() {
+	if s == nil || s.tracer == nil {
+		return
+	}
+	end := time.Now()
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	data := spanData{
+		sc: s.sc, parent: s.parent, kind: s.kind, name: s.name,
+		start: s.start, end: end, attrs: s.attrs, errMsg: s.errMsg,
+	}
+	s.mu.Unlock()
+	s.tracer.enqueue(data)
+}
+
+type spanKey struct{}
+
+// FromContext returns the span ctx carries, or nil.
+func FromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// ContextWithRemoteParent returns a copy of ctx in which the next span a
+// Tracer starts continues the trace of sc, such as one read from an
+// incoming traceparent header.
+func ContextWithRemoteParent(ctx context.Context, sc SpanContext) context.Context {
+	if !sc.IsValid() {
+		return ctx
+	}
+	return context.WithValue(ctx, spanKey{}, &Span{sc: sc})
+}
+
+// Start starts a span as a child of the span in ctx, returning a context
+// carrying it. Outside a sampled trace it returns ctx and a nil span.
+func Start(ctx context.Context, name string, attrs ...Attr) (context.Context, *Span) {
+	return startChild(ctx, KindInternal, name, attrs)
+}
+
+// StartClient is Start for a call out of the process, such as a fetch.
+func StartClient(ctx context.Context, name string, attrs ...Attr) (context.Context, *Span) {
+	return startChild(ctx, KindClient, name, attrs)
+}
+
+func startChild(ctx context.Context, kind Kind, name string, attrs []Attr) (context.Context, *Span) {
+	parent := FromContext(ctx)
+	if parent == nil || parent.tracer == nil || !parent.sc.Sampled {
+		return ctx, nil
+	}
+	return parent.tracer.start(ctx, parent.sc.TraceID, parent.sc.SpanID, kind, name, attrs)
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for id == (SpanID{}) {
+		binary.BigEndian.PutUint64(id[:], rand.Uint64())
+	}
+	return id
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for id == (TraceID{}) {
+		binary.BigEndian.PutUint64(id[:8], rand.Uint64())
+		binary.BigEndian.PutUint64(id[8:], rand.Uint64())
+	}
+	return id
+}
@@ -0,0 +1,158 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestParseTraceparent", "internal/tracing")
+
+	testCases := []struct {
+		name        string
+		header      string
+		wantOK      bool
+		wantSampled bool
+	}{
+		{"Sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true, true},
+		{"Not sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", true, false},
+		{"Later version with more fields", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true, true},
+		{"Version 00 with more fields", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false, false},
+		{"Invalid version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, false},
+		{"Zero trace ID", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", false, false},
+		{"Uppercase", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false, false},
+		{"Short span ID", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa-01", false, false},
+		{"Empty", "", false, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sc, ok := ParseTraceparent(tc.header)
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantOK, ok)
+			if ok != tc.wantOK || sc.Sampled != tc.wantSampled {
+				t.Errorf("ParseTraceparent(%q) = %+v, %v", tc.header, sc, ok)
+			}
+			if ok && tc.header[:2] == "00" && sc.Traceparent() != tc.header {
+				t.Errorf("Traceparent() = %q, want %q", sc.Traceparent(), tc.header)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestParseTraceparent", true)
+}
+
+// collector is an OTLP/HTTP endpoint keeping the spans it receives.
+type collector struct {
+	mu    sync.Mutex
+	spans []otlpSpan
+	auth  []string
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body otlpExport
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.auth = append(c.auth, r.Header.Get("Authorization"))
+	for _, rs := range body.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			c.spans = append(c.spans, ss.Spans...)
+		}
+	}
+}
+
+func TestTracer_ExportsTraces(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestTracer_ExportsTraces", "internal/tracing")
+
+	testhelpers.LogTestStep(logger, "arrange", "A tracer sampling every trace, exporting to a collector")
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+	tracer := NewTracer(Config{Endpoint: srv.URL, SampleRatio: 1, Headers: map[string]string{"Authorization": "Bearer test"}}, zap.NewNop())
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tracer.Run(ctx)
+	}()
+
+	testhelpers.LogTestStep(logger, "act", "Recording a request continuing a caller's trace, with a failing child")
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	reqCtx, root := tracer.Start(ContextWithRemoteParent(t.Context(), remote), KindServer, "GET")
+	root.SetName("GET /api/v1/compare")
+	_, child := Start(reqCtx, "ListingRepository.ByProducts", Int("batch.keys", 3))
+	child.RecordError(errors.New("connection refused"))
+	child.End()
+	root.End()
+	root.End()
+	cancel()
+	<-done
+
+	testhelpers.LogTestStep(logger, "assert", "Both spans are exported in the caller's trace, the child under the root")
+	testhelpers.LogTestAssertion(logger, "spans", 2, len(c.spans))
+	if len(c.spans) != 2 {
+		t.Fatalf("Exported %d spans, want 2: %+v", len(c.spans), c.spans)
+	}
+	gotChild, gotRoot := c.spans[0], c.spans[1]
+	if gotRoot.TraceID != remote.TraceID.String() || gotChild.TraceID != gotRoot.TraceID {
+		t.Errorf("Trace IDs = %s, %s, want %s", gotRoot.TraceID, gotChild.TraceID, remote.TraceID)
+	}
+	if gotRoot.ParentSpanID != remote.SpanID.String() || gotChild.ParentSpanID != gotRoot.SpanID {
+		t.Errorf("Parents = %s, %s", gotRoot.ParentSpanID, gotChild.ParentSpanID)
+	}
+	if gotRoot.Name != "GET /api/v1/compare" || gotRoot.Kind != KindServer || gotRoot.Status.Code != 0 {
+		t.Errorf("Root = %+v", gotRoot)
+	}
+	if gotChild.Status.Code != 2 || gotChild.Status.Message != "connection refused" ||
+		len(gotChild.Attributes) != 1 || *gotChild.Attributes[0].Value.IntValue != "3" {
+		t.Errorf("Child = %+v", gotChild)
+	}
+	if c.auth[0] != "Bearer test" {
+		t.Errorf("Authorization = %q", c.auth[0])
+	}
+
+	testhelpers.LogTestComplete(logger, "TestTracer_ExportsTraces", true)
+}
+
+func TestTracer_FollowsSamplingDecision(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestTracer_FollowsSamplingDecision", "internal/tracing")
+
+	testhelpers.LogTestStep(logger, "arrange", "A tracer sampling no new traces")
+	tracer := NewTracer(Config{Endpoint: "http://collector.invalid", SampleRatio: 0}, zap.NewNop())
+
+	testhelpers.LogTestStep(logger, "act", "Starting a new trace, one a caller did not sample, and one a caller did")
+	ctx, fresh := tracer.Start(t.Context(), KindServer, "GET")
+	_, child := Start(ctx, "cache.get")
+	unsampled, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	_, declined := tracer.Start(ContextWithRemoteParent(t.Context(), unsampled), KindServer, "GET")
+	sampled, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, continued := tracer.Start(ContextWithRemoteParent(t.Context(), sampled), KindServer, "GET")
+
+	testhelpers.LogTestStep(logger, "assert", "Only the caller's sampled trace is recorded; untraced code gets nil spans")
+	testhelpers.LogTestAssertion(logger, "continued", true, continued != nil)
+	if fresh != nil || child != nil || declined != nil || continued == nil {
+		t.Errorf("Spans = %v, %v, %v, %v; want only the last", fresh, child, declined, continued)
+	}
+	if FromContext(ctx).SpanContext().TraceID == (TraceID{}) {
+		t.Error("An unsampled trace keeps no trace ID to propagate")
+	}
+	if _, s := Start(t.Context(), "cache.get"); s != nil {
+		t.Errorf("Start outside a trace = %v, want nil", s)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestTracer_FollowsSamplingDecision", true)
+}
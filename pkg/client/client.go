@@ -0,0 +1,302 @@
+// Package client is a Go client for the whey price comparison API. It reads
+// the X-RateLimit-* headers on every response, waits out Retry-After on 429
+// and 503 responses, and optionally pauses before a request that would
+// certainly be rejected, so integrators back off the way the server expects.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config configures a Client.
+type Config struct {
+	// BaseURL is the API origin, e.g. https://api.example.com.
+	BaseURL string
+	// APIKey is sent as X-API-Key when set.
+	APIKey    string
+	UserAgent string
+	// HTTPClient defaults to a client with a 30 second timeout.
+	HTTPClient *http.Client
+	// MaxRetries bounds how often a rate-limited request is retried.
+	MaxRetries int
+	// MaxWait caps any single back-off. Waits longer than this are not
+	// attempted and the 429 is returned to the caller instead.
+	MaxWait time.Duration
+	// WaitForReset delays requests while the last response reported no
+	// remaining budget, instead of spending a request on a certain 429.
+	WaitForReset bool
+}
+
+// DefaultConfig returns a Config with conservative retry settings.
+func DefaultConfig(baseURL string) Config {
+	return Config{
+		BaseURL:      baseURL,
+		UserAgent:    "whey-price-compare-go-client/1",
+		MaxRetries:   3,
+		MaxWait:      2 * time.Minute,
+		WaitForReset: true,
+	}
+}
+
+// RateLimit is the budget the server reported on the latest response.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+	Window    time.Duration
+}
+
+// ParseRateLimit reads the X-RateLimit-* headers. ok is false when the
+// response was not rate limited.
+func ParseRateLimit(h http.Header) (rl RateLimit, ok bool) {
+	limit, err := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	rl.Limit = limit
+	rl.Remaining, _ = strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rl.Reset = time.Unix(reset, 0)
+	}
+	if window, err := strconv.Atoi(h.Get("X-RateLimit-Window")); err == nil {
+		rl.Window = time.Duration(window) * time.Second
+	}
+	return rl, true
+}
+
+// ParseRetryAfter reads Retry-After in either delta-seconds or HTTP-date
+// form.
+func ParseRetryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return max(0, time.Duration(secs)*time.Second), true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return max(0, at.Sub(now)), true
+	}
+	return 0, false
+}
+
+// Error is an API error envelope returned with a non-2xx status.
+type Error struct {
+	Status    int            `json:"-"`
+	Code      string         `json:"code"`
+	Message   string         `json:"message"`
+	Details   map[string]any `json:"details,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+	// RetryAfter is set on rate-limited responses.
+	RetryAfter time.Duration `json:"-"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("api: HTTP %d", e.Status)
+	}
+	return fmt.Sprintf("api: HTTP %d %s: %s", e.Status, e.Code, e.Message)
+}
+
+// IsRateLimited reports whether err is a 429 from the API.
+func IsRateLimited(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusTooManyRequests
+}
+
+// Client calls the API.
+type Client struct {
+	cfg   Config
+	http  *http.Client
+	sleep func(ctx context.Context, d time.Duration) error
+	now   func() time.Time
+
+	mu   sync.Mutex
+	last RateLimit
+	seen bool
+}
+
+// New creates a Client.
+func New(cfg Config) (*Client, error) {
+	if _, err := url.ParseRequestURI(cfg.BaseURL); err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	hc := cfg.HTTPClient
+	if hc == nil {
+		hc = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Client{cfg: cfg, http: hc, sleep: sleepContext, now: time.Now}, nil
+}
+
+// RateLimit returns the budget reported by the most recent response.
+func (c *Client) RateLimit() (RateLimit, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.last, c.seen
+}
+
+// GetJSON fetches path with the given query and decodes the body into out.
+// Non-2xx responses are returned as *Error.
+func (c *Client) GetJSON(ctx context.Context, path string, query url.Values, out any) error {
+	target := c.cfg.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return c.decodeError(resp)
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// Do sends req, honouring the server's rate limits. Requests with a body are
+// only retried when req.GetBody is set. The final response is returned even
+// if it is a 429, so callers can inspect it.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if c.cfg.UserAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.cfg.UserAgent)
+	}
+	if c.cfg.APIKey != "" {
+		req.Header.Set("X-API-Key", c.cfg.APIKey)
+	}
+
+	if err := c.waitForBudget(req.Context()); err != nil {
+		return nil, err
+	}
+	for attempt := 0; ; attempt++ {
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		c.record(resp.Header)
+
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+			return resp, nil
+		}
+		wait, ok := c.backoff(resp.Header)
+		if !ok || attempt >= c.cfg.MaxRetries || wait > c.cfg.MaxWait || !rewind(req) {
+			return resp, nil
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if err := c.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// backoff decides how long to wait before retrying. Retry-After wins; a 429
+// without it falls back to the reset time.
+func (c *Client) backoff(h http.Header) (time.Duration, bool) {
+	now := c.now()
+	if d, ok := ParseRetryAfter(h, now); ok {
+		return d, true
+	}
+	if rl, ok := ParseRateLimit(h); ok && !rl.Reset.IsZero() {
+		return max(0, rl.Reset.Sub(now)), true
+	}
+	return 0, false
+}
+
+func (c *Client) record(h http.Header) {
+	rl, ok := ParseRateLimit(h)
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	c.last, c.seen = rl, true
+	c.mu.Unlock()
+}
+
+// waitForBudget pauses while the last response reported an exhausted budget
+// that has not reset yet. Retries skip it: Retry-After is more precise.
+func (c *Client) waitForBudget(ctx context.Context) error {
+	if !c.cfg.WaitForReset {
+		return nil
+	}
+	rl, ok := c.RateLimit()
+	if !ok || rl.Remaining > 0 || rl.Reset.IsZero() {
+		return nil
+	}
+	wait := rl.Reset.Sub(c.now())
+	if wait <= 0 || wait > c.cfg.MaxWait {
+		return nil
+	}
+	return c.sleep(ctx, wait)
+}
+
+func (c *Client) decodeError(resp *http.Response) error {
+	apiErr := &Error{Status: resp.StatusCode}
+	var envelope struct {
+		Error *Error `json:"error"`
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if json.Unmarshal(body, &envelope) == nil && envelope.Error != nil {
+		apiErr = envelope.Error
+		apiErr.Status = resp.StatusCode
+	}
+	if d, ok := ParseRetryAfter(resp.Header, c.now()); ok {
+		apiErr.RetryAfter = d
+	}
+	return apiErr
+}
+
+// rewind resets the request body for a retry.
+func rewind(req *http.Request) bool {
+	if req.Body == nil || req.Body == http.NoBody {
+		return true
+	}
+	if req.GetBody == nil {
+		return false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return false
+	}
+	req.Body = body
+	return true
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
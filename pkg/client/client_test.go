@@ -0,0 +1,175 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func newTestClient(t *testing.T, srv *httptest.Server, slept *[]time.Duration) *Client {
+	t.Helper()
+	c, err := New(DefaultConfig(srv.URL))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	c.sleep = func(_ context.Context, d time.Duration) error {
+		*slept = append(*slept, d)
+		return nil
+	}
+	return c
+}
+
+func TestClient_RetriesAfterRateLimit(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestClient_RetriesAfterRateLimit", "pkg/client")
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "150")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10))
+		if calls.Add(1) == 1 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"code":"RATE_LIMIT_EXCEEDED","message":"slow down"}}`))
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "149")
+		_, _ = w.Write([]byte(`{"count":2}`))
+	}))
+	defer srv.Close()
+
+	var slept []time.Duration
+	c := newTestClient(t, srv, &slept)
+
+	testhelpers.LogTestStep(logger, "act", "Fetching through a 429")
+	var out struct {
+		Count int `json:"count"`
+	}
+	if err := c.GetJSON(t.Context(), "/api/v1/deals", nil, &out); err != nil {
+		t.Fatalf("GetJSON failed: %v", err)
+	}
+
+	testhelpers.LogTestStep(logger, "assert", "Waited for Retry-After, not the reset")
+	testhelpers.LogTestAssertion(logger, "sleeps", []time.Duration{7 * time.Second}, slept)
+	if calls.Load() != 2 || out.Count != 2 {
+		t.Errorf("calls = %d, count = %d", calls.Load(), out.Count)
+	}
+	if len(slept) != 1 || slept[0] != 7*time.Second {
+		t.Errorf("Slept %v, want [7s]", slept)
+	}
+	if rl, ok := c.RateLimit(); !ok || rl.Limit != 150 || rl.Remaining != 149 {
+		t.Errorf("RateLimit = %+v, %v", rl, ok)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestClient_RetriesAfterRateLimit", true)
+}
+
+func TestClient_GivesUp(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestClient_GivesUp", "pkg/client")
+
+	testCases := []struct {
+		name       string
+		retryAfter string
+		wantCalls  int32
+	}{
+		{"Retries exhausted", "1", 4},
+		{"Wait longer than MaxWait", "3600", 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.Header().Set("Retry-After", tc.retryAfter)
+				w.WriteHeader(http.StatusTooManyRequests)
+				_, _ = w.Write([]byte(`{"error":{"code":"RATE_LIMIT_EXCEEDED","message":"slow down","request_id":"req_1"}}`))
+			}))
+			defer srv.Close()
+
+			var slept []time.Duration
+			c := newTestClient(t, srv, &slept)
+			err := c.GetJSON(t.Context(), "/api/v1/deals", nil, nil)
+
+			testhelpers.LogTestAssertion(logger, tc.name, tc.wantCalls, calls.Load())
+			if calls.Load() != tc.wantCalls {
+				t.Errorf("Server called %d times, want %d", calls.Load(), tc.wantCalls)
+			}
+			var apiErr *Error
+			if !IsRateLimited(err) || !errors.As(err, &apiErr) {
+				t.Fatalf("error = %v, want rate limited *Error", err)
+			}
+			if apiErr.Code != "RATE_LIMIT_EXCEEDED" || apiErr.RequestID != "req_1" || apiErr.RetryAfter == 0 {
+				t.Errorf("Error = %+v", apiErr)
+			}
+		})
+	}
+
+	testhelpers.LogTestComplete(logger, "TestClient_GivesUp", true)
+}
+
+func TestClient_WaitsForReset(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestClient_WaitsForReset", "pkg/client")
+
+	reset := time.Now().Add(30 * time.Second).Truncate(time.Second)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "10")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	var slept []time.Duration
+	c := newTestClient(t, srv, &slept)
+	c.now = func() time.Time { return reset.Add(-20 * time.Second) }
+
+	testhelpers.LogTestStep(logger, "act", "Second request after the budget ran out")
+	_ = c.GetJSON(t.Context(), "/api/v1/deals", nil, nil)
+	_ = c.GetJSON(t.Context(), "/api/v1/deals", nil, nil)
+
+	testhelpers.LogTestAssertion(logger, "sleeps", []time.Duration{20 * time.Second}, slept)
+	if len(slept) != 1 || slept[0] != 20*time.Second {
+		t.Errorf("Slept %v, want [20s] before the second request", slept)
+	}
+
+	testhelpers.LogTestComplete(logger, "TestClient_WaitsForReset", true)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	logger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(logger, "TestParseRetryAfter", "pkg/client")
+
+	now := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	testCases := []struct {
+		value  string
+		expect time.Duration
+		ok     bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{now.Add(45 * time.Second).Format(http.TimeFormat), 45 * time.Second, true},
+		{"", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tc := range testCases {
+		h := http.Header{}
+		h.Set("Retry-After", tc.value)
+		got, ok := ParseRetryAfter(h, now)
+		testhelpers.LogTestAssertion(logger, tc.value, tc.expect, got)
+		if got != tc.expect || ok != tc.ok {
+			t.Errorf("ParseRetryAfter(%q) = %v, %v", tc.value, got, ok)
+		}
+	}
+
+	testhelpers.LogTestComplete(logger, "TestParseRetryAfter", true)
+}
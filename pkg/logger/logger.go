@@ -0,0 +1,73 @@
+// Package logger builds the zap loggers used by every service.
+//
+// Production emits JSON for log aggregation; every other environment uses the
+// human-readable console encoder.
+package logger
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Config selects the logger flavour.
+type Config struct {
+	// Environment is "production", "staging" or "development".
+	Environment string
+	// Level is a zap level name ("debug", "info", ...). Empty uses the
+	// environment default.
+	Level string
+	// Service is attached to every line as service_name.
+	Service string
+	// AtomicLevel, when set, is made the logger's level and set to Level,
+	// so the level can be changed while the service runs.
+	AtomicLevel *zap.AtomicLevel
+}
+
+// DefaultLevel is the level of environment's loggers when Config.Level is
+// empty: info in production and staging, debug elsewhere.
+func DefaultLevel(environment string) zapcore.Level {
+	switch strings.ToLower(environment) {
+	case "production", "staging":
+		return zapcore.InfoLevel
+	default:
+		return zapcore.DebugLevel
+	}
+}
+
+// New creates a logger for cfg.
+func New(cfg Config) (*zap.Logger, error) {
+	var zcfg zap.Config
+	switch strings.ToLower(cfg.Environment) {
+	case "production", "staging":
+		zcfg = zap.NewProductionConfig()
+		zcfg.EncoderConfig.TimeKey = "timestamp"
+		zcfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	default:
+		zcfg = zap.NewDevelopmentConfig()
+	}
+
+	level := DefaultLevel(cfg.Environment)
+	if cfg.Level != "" {
+		var err error
+		if level, err = zapcore.ParseLevel(cfg.Level); err != nil {
+			return nil, fmt.Errorf("parse log level %q: %w", cfg.Level, err)
+		}
+	}
+	zcfg.Level = zap.NewAtomicLevelAt(level)
+	if cfg.AtomicLevel != nil {
+		cfg.AtomicLevel.SetLevel(level)
+		zcfg.Level = *cfg.AtomicLevel
+	}
+
+	logger, err := zcfg.Build()
+	if err != nil {
+		return nil, fmt.Errorf("build logger: %w", err)
+	}
+	if cfg.Service != "" {
+		logger = logger.With(zap.String("service_name", cfg.Service))
+	}
+	return logger, nil
+}
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/yourusername/whey-price-compare/internal/testhelpers"
+)
+
+func TestNew(t *testing.T) {
+	testLogger := testhelpers.SetupTestLogger(t)
+	testhelpers.LogTestStart(testLogger, "TestNew", "pkg/logger")
+
+	testCases := []struct {
+		name      string
+		cfg       Config
+		wantLevel zapcore.Level
+		wantErr   bool
+	}{
+		{"Development defaults to debug", Config{Environment: "development"}, zapcore.DebugLevel, false},
+		{"Production defaults to info", Config{Environment: "production"}, zapcore.InfoLevel, false},
+		{"Explicit level", Config{Environment: "production", Level: "warn"}, zapcore.WarnLevel, false},
+		{"Invalid level", Config{Level: "loud"}, zapcore.InfoLevel, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logger, err := New(tc.cfg)
+			testhelpers.LogTestAssertion(testLogger, tc.name, tc.wantErr, err != nil)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("Expected error for invalid config")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if !logger.Core().Enabled(tc.wantLevel) {
+				t.Errorf("Level %s should be enabled", tc.wantLevel)
+			}
+			if tc.wantLevel > zapcore.DebugLevel && logger.Core().Enabled(tc.wantLevel-1) {
+				t.Errorf("Level %s should be disabled", tc.wantLevel-1)
+			}
+		})
+	}
+
+	t.Run("Level changed while running", func(t *testing.T) {
+		level := zap.NewAtomicLevel()
+		logger, err := New(Config{Environment: "production", AtomicLevel: &level})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		level.SetLevel(zapcore.ErrorLevel)
+		testhelpers.LogTestAssertion(testLogger, "warn enabled", false, logger.Core().Enabled(zapcore.WarnLevel))
+		if logger.Core().Enabled(zapcore.WarnLevel) || !logger.Core().Enabled(zapcore.ErrorLevel) {
+			t.Error("Logger should follow its atomic level")
+		}
+	})
+
+	testhelpers.LogTestComplete(testLogger, "TestNew", true)
+}